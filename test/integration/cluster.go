@@ -0,0 +1,447 @@
+// +build integration
+
+// Package integration runs the weed servers as a cluster of multiple masters, volume servers and filers,
+// to check the invariants of the cluster through the failures: the leader killed, a rack partitioned, the disks filled up.
+//
+// The scenarios are only built with the "integration" tag:
+//
+//	go test -tags integration -v ./test/integration/
+//
+// The weed binary is built from the source tree, or taken from $WEED_BINARY.
+// With $SEAWEEDFS_IT_DOCKER_IMAGE, e.g. "chrislusf/seaweedfs:local", the servers run as docker containers on the host network instead.
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/operation"
+)
+
+// Topology describes the cluster to start
+type Topology struct {
+	Masters int
+	Racks   []RackTopology
+	Filers  int
+
+	VolumeSizeLimitMB  int
+	MaxVolumes         int
+	DefaultReplication string
+}
+
+type RackTopology struct {
+	DataCenter    string
+	Rack          string
+	VolumeServers int
+}
+
+type Cluster struct {
+	t   *testing.T
+	dir string
+
+	Masters       []*Process
+	VolumeServers []*Process
+	Filers        []*Process
+	// the "dataCenter/rack" of each volume server
+	racks map[*Process]string
+	ports map[int]bool
+}
+
+// the servers heartbeat every second, to notice the failures quickly
+const pulseSeconds = "1"
+
+var httpClient = &http.Client{Timeout: 5 * time.Second}
+
+// StartCluster starts the servers and waits for the leader to see all volume servers.
+// The cluster should be stopped by the end of the test.
+func StartCluster(t *testing.T, topology Topology) *Cluster {
+	dir, err := ioutil.TempDir("", "seaweedfs-it-")
+	if err != nil {
+		t.Fatalf("create cluster dir: %v", err)
+	}
+	c := &Cluster{t: t, dir: dir, racks: make(map[*Process]string), ports: make(map[int]bool)}
+	// the servers already started are stopped if the cluster fails to start
+	defer func() {
+		if t.Failed() {
+			c.Stop()
+		}
+	}()
+
+	binary, dockerImage := os.Getenv("WEED_BINARY"), os.Getenv("SEAWEEDFS_IT_DOCKER_IMAGE")
+	if binary == "" && dockerImage == "" {
+		binary = filepath.Join(dir, "weed")
+		if out, err := exec.Command("go", "build", "-o", binary, "github.com/chrislusf/seaweedfs/weed").CombinedOutput(); err != nil {
+			t.Fatalf("build weed: %v %s", err, string(out))
+		}
+	}
+	newProcess := func(name string, args ...string) *Process {
+		p := &Process{Name: name, Port: c.freePort(), Dir: filepath.Join(dir, name), binary: binary, dockerImage: dockerImage}
+		if err := os.MkdirAll(p.Dir, 0755); err != nil {
+			t.Fatalf("create %s: %v", p.Dir, err)
+		}
+		p.Args = append([]string{strings.SplitN(name, "-", 2)[0], "-ip=127.0.0.1", fmt.Sprintf("-port=%d", p.Port)}, args...)
+		return p
+	}
+
+	if topology.Masters <= 0 {
+		topology.Masters = 1
+	}
+	if topology.VolumeSizeLimitMB <= 0 {
+		topology.VolumeSizeLimitMB = 100
+	}
+	if topology.MaxVolumes <= 0 {
+		topology.MaxVolumes = 7
+	}
+	if topology.DefaultReplication == "" {
+		topology.DefaultReplication = "000"
+	}
+
+	for i := 0; i < topology.Masters; i++ {
+		c.Masters = append(c.Masters, newProcess(fmt.Sprintf("master-%d", i)))
+	}
+	masters := c.masterAddresses()
+	for _, m := range c.Masters {
+		m.Args = append(m.Args, "-mdir="+m.Dir, "-peers="+masters, "-pulseSeconds="+pulseSeconds,
+			fmt.Sprintf("-volumeSizeLimitMB=%d", topology.VolumeSizeLimitMB), "-defaultReplication="+topology.DefaultReplication)
+	}
+	for _, rack := range topology.Racks {
+		for i := 0; i < rack.VolumeServers; i++ {
+			v := newProcess(fmt.Sprintf("volume-%s-%s-%d", rack.DataCenter, rack.Rack, i))
+			v.Args = append(v.Args, "-dir="+v.Dir, "-mserver="+masters, "-pulseSeconds="+pulseSeconds,
+				"-dataCenter="+rack.DataCenter, "-rack="+rack.Rack, fmt.Sprintf("-max=%d", topology.MaxVolumes))
+			c.VolumeServers = append(c.VolumeServers, v)
+			c.racks[v] = rack.DataCenter + "/" + rack.Rack
+		}
+	}
+	for i := 0; i < topology.Filers; i++ {
+		f := newProcess(fmt.Sprintf("filer-%d", i), "-master="+masters)
+		filerToml := fmt.Sprintf("[leveldb2]\nenabled = true\ndir = %q\n", filepath.Join(f.Dir, "filerldb2"))
+		if err := ioutil.WriteFile(filepath.Join(f.Dir, "filer.toml"), []byte(filerToml), 0644); err != nil {
+			t.Fatalf("write filer.toml: %v", err)
+		}
+		c.Filers = append(c.Filers, f)
+	}
+
+	for _, p := range c.all() {
+		if err := p.Start(); err != nil {
+			t.Fatalf("%v", err)
+		}
+	}
+	c.WaitForLeader(60 * time.Second)
+	c.WaitForVolumeServers(len(c.VolumeServers), 60*time.Second)
+	for _, f := range c.Filers {
+		c.Eventually(30*time.Second, "filer "+f.Name+" up", func() error {
+			_, err := c.FilerGet(f, "/")
+			return err
+		})
+	}
+	return c
+}
+
+// Stop kills all servers, and removes the data unless the test failed
+func (c *Cluster) Stop() {
+	for _, p := range c.all() {
+		p.Resume()
+		p.Kill()
+	}
+	if c.t.Failed() {
+		c.t.Logf("the logs and data of the cluster are kept in %s", c.dir)
+		return
+	}
+	os.RemoveAll(c.dir)
+}
+
+// RackVolumeServers lists the volume servers in the rack
+func (c *Cluster) RackVolumeServers(dataCenter, rack string) (servers []*Process) {
+	for _, v := range c.VolumeServers {
+		if c.racks[v] == dataCenter+"/"+rack {
+			servers = append(servers, v)
+		}
+	}
+	return
+}
+
+// Leader asks the running masters for the leader, which all of them should agree on
+func (c *Cluster) Leader() (*Process, error) {
+	var leader *Process
+	for _, m := range c.Masters {
+		if !m.IsRunning() {
+			continue
+		}
+		var status struct {
+			IsLeader bool
+			Leader   string
+		}
+		if err := c.getJson("http://"+m.Address()+"/cluster/status", &status); err != nil {
+			continue
+		}
+		if status.IsLeader {
+			if leader != nil {
+				return nil, fmt.Errorf("both %s and %s are leaders", leader.Name, m.Name)
+			}
+			leader = m
+		}
+	}
+	if leader == nil {
+		return nil, fmt.Errorf("no leader")
+	}
+	return leader, nil
+}
+
+func (c *Cluster) WaitForLeader(timeout time.Duration) (leader *Process) {
+	c.Eventually(timeout, "a leader elected", func() (err error) {
+		leader, err = c.Leader()
+		return err
+	})
+	return
+}
+
+// WaitForVolumeServers waits for the leader to see the number of volume servers
+func (c *Cluster) WaitForVolumeServers(count int, timeout time.Duration) {
+	c.Eventually(timeout, fmt.Sprintf("%d volume servers", count), func() error {
+		nodes, err := c.DataNodes()
+		if err != nil {
+			return err
+		}
+		if len(nodes) != count {
+			return fmt.Errorf("%d volume servers", len(nodes))
+		}
+		return nil
+	})
+}
+
+// DataNode is a volume server as seen by the leader
+type DataNode struct {
+	Url     string
+	Volumes int
+	Max     int
+	Free    int
+}
+
+func (c *Cluster) DataNodes() (nodes []DataNode, err error) {
+	leader, err := c.Leader()
+	if err != nil {
+		return nil, err
+	}
+	var status struct {
+		Topology struct {
+			Free        int
+			DataCenters []struct {
+				Racks []struct {
+					DataNodes []DataNode
+				}
+			}
+		}
+	}
+	if err = c.getJson("http://"+leader.Address()+"/dir/status", &status); err != nil {
+		return nil, err
+	}
+	for _, dc := range status.Topology.DataCenters {
+		for _, rack := range dc.Racks {
+			nodes = append(nodes, rack.DataNodes...)
+		}
+	}
+	return nodes, nil
+}
+
+// Upload writes the data as a new file by the master assignment
+func (c *Cluster) Upload(replication string, data []byte) (fid string, err error) {
+	leader, err := c.Leader()
+	if err != nil {
+		return "", err
+	}
+	var assign operation.AssignResult
+	if err = c.getJson("http://"+leader.Address()+"/dir/assign?replication="+url.QueryEscape(replication), &assign); err != nil {
+		return "", err
+	}
+	if assign.Error != "" {
+		return "", fmt.Errorf("assign: %s", assign.Error)
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", assign.Fid)
+	if err != nil {
+		return "", err
+	}
+	part.Write(data)
+	writer.Close()
+
+	resp, err := httpClient.Post("http://"+assign.Url+"/"+assign.Fid, writer.FormDataContentType(), &body)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		message, _ := ioutil.ReadAll(resp.Body)
+		return "", fmt.Errorf("upload %s to %s: %s %s", assign.Fid, assign.Url, resp.Status, string(message))
+	}
+	return assign.Fid, nil
+}
+
+// Lookup asks the leader for the volume servers of the file
+func (c *Cluster) Lookup(fid string) ([]operation.Location, error) {
+	leader, err := c.Leader()
+	if err != nil {
+		return nil, err
+	}
+	var lookup operation.LookupResult
+	volumeId := strings.SplitN(fid, ",", 2)[0]
+	if err = c.getJson("http://"+leader.Address()+"/dir/lookup?volumeId="+volumeId, &lookup); err != nil {
+		return nil, err
+	}
+	if lookup.Error != "" {
+		return nil, fmt.Errorf("lookup %s: %s", fid, lookup.Error)
+	}
+	return lookup.Locations, nil
+}
+
+// Read looks up the file locations, and reads from any location that answers
+func (c *Cluster) Read(fid string) ([]byte, error) {
+	locations, err := c.Lookup(fid)
+	if err != nil {
+		return nil, err
+	}
+	err = fmt.Errorf("file %s has no locations", fid)
+	for _, location := range locations {
+		var data []byte
+		if data, err = c.get("http://" + location.Url + "/" + fid); err == nil {
+			return data, nil
+		}
+	}
+	return nil, err
+}
+
+// ReadReplicas reads the file from every location, which should all have the same data
+func (c *Cluster) ReadReplicas(fid string) (replicas [][]byte, err error) {
+	locations, err := c.Lookup(fid)
+	if err != nil {
+		return nil, err
+	}
+	for _, location := range locations {
+		data, err := c.get("http://" + location.Url + "/" + fid)
+		if err != nil {
+			return nil, fmt.Errorf("read %s from %s: %v", fid, location.Url, err)
+		}
+		replicas = append(replicas, data)
+	}
+	return replicas, nil
+}
+
+// FilerPut writes the file through the filer
+func (c *Cluster) FilerPut(filer *Process, path string, data []byte) error {
+	req, err := http.NewRequest("PUT", "http://"+filer.Address()+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		message, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("put %s: %s %s", path, resp.Status, string(message))
+	}
+	return nil
+}
+
+func (c *Cluster) FilerGet(filer *Process, path string) ([]byte, error) {
+	return c.get("http://" + filer.Address() + path)
+}
+
+// Eventually retries the check until it passes, or fails the test after the timeout
+func (c *Cluster) Eventually(timeout time.Duration, description string, check func() error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		err := check()
+		if err == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			c.t.Fatalf("waiting for %s: %v", description, err)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+func (c *Cluster) get(url string) ([]byte, error) {
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get %s: %s %s", url, resp.Status, string(data))
+	}
+	return data, nil
+}
+
+func (c *Cluster) getJson(url string, v interface{}) error {
+	data, err := c.get(url)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (c *Cluster) masterAddresses() string {
+	var addresses []string
+	for _, m := range c.Masters {
+		addresses = append(addresses, m.Address())
+	}
+	return strings.Join(addresses, ",")
+}
+
+func (c *Cluster) all() (processes []*Process) {
+	processes = append(processes, c.Masters...)
+	processes = append(processes, c.VolumeServers...)
+	return append(processes, c.Filers...)
+}
+
+// freePort finds a port free for both the http and the grpc listeners
+func (c *Cluster) freePort() int {
+	for i := 0; i < 100; i++ {
+		port := 20000 + rand.Intn(30000)
+		if !c.ports[port] && !c.ports[port+10000] && isPortFree(port) && isPortFree(port+10000) {
+			c.ports[port], c.ports[port+10000] = true, true
+			return port
+		}
+	}
+	c.t.Fatalf("no free ports")
+	return 0
+}
+
+func isPortFree(port int) bool {
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		return false
+	}
+	listener.Close()
+	return true
+}
+
+// randomData makes the file content, to be compared when read back
+func randomData(size int) []byte {
+	data := make([]byte, size)
+	io.ReadFull(rand.New(rand.NewSource(time.Now().UnixNano())), data)
+	return data
+}
@@ -0,0 +1,152 @@
+// +build integration
+
+package integration
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Process is one weed server of the cluster, run as a local process,
+// or as a docker container on the host network if the cluster has a docker image.
+type Process struct {
+	Name string
+	// the http port, with the grpc port at 10000 more
+	Port int
+	Dir  string
+	Args []string
+
+	binary      string
+	dockerImage string
+
+	sync.Mutex
+	cmd     *exec.Cmd
+	exited  chan struct{}
+	paused  bool
+	running bool
+}
+
+func (p *Process) Address() string {
+	return fmt.Sprintf("127.0.0.1:%d", p.Port)
+}
+
+// Start runs the server, with the output appended to the log file in its directory
+func (p *Process) Start() error {
+	p.Lock()
+	defer p.Unlock()
+	if p.running {
+		return nil
+	}
+
+	logFile, err := os.OpenFile(filepath.Join(p.Dir, "weed.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	if p.dockerImage == "" {
+		p.cmd = exec.Command(p.binary, p.Args...)
+	} else {
+		args := []string{"run", "--rm", "--name", p.containerName(), "--network", "host",
+			"-v", p.Dir + ":" + p.Dir, "-w", p.Dir, "--entrypoint", "/usr/bin/weed", p.dockerImage}
+		p.cmd = exec.Command("docker", append(args, p.Args...)...)
+	}
+	// the filer finds its filer.toml in the working directory
+	p.cmd.Dir = p.Dir
+	p.cmd.Stdout = logFile
+	p.cmd.Stderr = logFile
+	if err = p.cmd.Start(); err != nil {
+		logFile.Close()
+		return fmt.Errorf("start %s: %v", p.Name, err)
+	}
+
+	exited := make(chan struct{})
+	go func(cmd *exec.Cmd) {
+		cmd.Wait()
+		logFile.Close()
+		close(exited)
+	}(p.cmd)
+	p.exited, p.running, p.paused = exited, true, false
+	return nil
+}
+
+// Kill stops the server at once, as a crash without any cleanup
+func (p *Process) Kill() error {
+	p.Lock()
+	defer p.Unlock()
+	if !p.running {
+		return nil
+	}
+	if p.dockerImage != "" {
+		exec.Command("docker", "kill", p.containerName()).Run()
+	} else {
+		p.cmd.Process.Kill()
+	}
+	select {
+	case <-p.exited:
+	case <-time.After(30 * time.Second):
+		return fmt.Errorf("kill %s: not exited", p.Name)
+	}
+	p.running, p.paused = false, false
+	return nil
+}
+
+// Pause freezes the server with its connections left open, which the other servers see as a network partition
+func (p *Process) Pause() error {
+	p.Lock()
+	defer p.Unlock()
+	if !p.running || p.paused {
+		return nil
+	}
+	if err := p.signal("pause", syscall.SIGSTOP); err != nil {
+		return err
+	}
+	p.paused = true
+	return nil
+}
+
+// Resume continues the paused server, healing the partition
+func (p *Process) Resume() error {
+	p.Lock()
+	defer p.Unlock()
+	if !p.running || !p.paused {
+		return nil
+	}
+	if err := p.signal("unpause", syscall.SIGCONT); err != nil {
+		return err
+	}
+	p.paused = false
+	return nil
+}
+
+// Restart kills and starts the server again, with the same data directory
+func (p *Process) Restart() error {
+	if err := p.Kill(); err != nil {
+		return err
+	}
+	return p.Start()
+}
+
+func (p *Process) IsRunning() bool {
+	p.Lock()
+	defer p.Unlock()
+	return p.running && !p.paused
+}
+
+func (p *Process) signal(dockerCommand string, sig syscall.Signal) error {
+	if p.dockerImage != "" {
+		if out, err := exec.Command("docker", dockerCommand, p.containerName()).CombinedOutput(); err != nil {
+			return fmt.Errorf("docker %s %s: %v %s", dockerCommand, p.Name, err, string(out))
+		}
+		return nil
+	}
+	return p.cmd.Process.Signal(sig)
+}
+
+func (p *Process) containerName() string {
+	return fmt.Sprintf("seaweedfs-it-%d-%s", p.Port, p.Name)
+}
@@ -0,0 +1,197 @@
+// +build integration
+
+package integration
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestKillLeader kills the leader master, and checks a new leader takes over
+// without losing any files or assigning any file id twice.
+func TestKillLeader(t *testing.T) {
+	c := StartCluster(t, Topology{
+		Masters: 3,
+		Racks: []RackTopology{
+			{DataCenter: "dc1", Rack: "rack1", VolumeServers: 2},
+			{DataCenter: "dc1", Rack: "rack2", VolumeServers: 2},
+		},
+		Filers: 1,
+	})
+	defer c.Stop()
+
+	files := make(map[string][]byte)
+	uploadFiles(t, c, "000", 20, files)
+	filer := c.Filers[0]
+	for i := 0; i < 5; i++ {
+		if err := c.FilerPut(filer, fmt.Sprintf("/it/before/%d.txt", i), []byte(fmt.Sprintf("before %d", i))); err != nil {
+			t.Fatalf("filer put: %v", err)
+		}
+	}
+
+	oldLeader, err := c.Leader()
+	if err != nil {
+		t.Fatalf("leader: %v", err)
+	}
+	if err = oldLeader.Kill(); err != nil {
+		t.Fatalf("kill leader: %v", err)
+	}
+	newLeader := c.WaitForLeader(60 * time.Second)
+	if newLeader == oldLeader {
+		t.Fatalf("killed leader %s is still the leader", oldLeader.Name)
+	}
+	c.WaitForVolumeServers(len(c.VolumeServers), 60*time.Second)
+
+	checkFiles(t, c, files)
+	c.Eventually(30*time.Second, "assignments by the new leader", func() error {
+		_, err := c.Upload("000", randomData(1024))
+		return err
+	})
+	uploadFiles(t, c, "000", 20, files)
+	checkFiles(t, c, files)
+
+	for i := 0; i < 5; i++ {
+		path := fmt.Sprintf("/it/before/%d.txt", i)
+		if data, err := c.FilerGet(filer, path); err != nil || string(data) != fmt.Sprintf("before %d", i) {
+			t.Errorf("filer get %s: %q %v", path, data, err)
+		}
+	}
+	c.Eventually(30*time.Second, "filer writes by the new leader", func() error {
+		return c.FilerPut(filer, "/it/after.txt", []byte("after"))
+	})
+
+	// the old leader comes back as a follower
+	if err = oldLeader.Start(); err != nil {
+		t.Fatalf("restart old leader: %v", err)
+	}
+	time.Sleep(5 * time.Second)
+	if leader := c.WaitForLeader(30 * time.Second); leader == oldLeader {
+		t.Errorf("restarted %s took over the leadership from %s", oldLeader.Name, newLeader.Name)
+	}
+	checkFiles(t, c, files)
+}
+
+// TestPartitionRack cuts off one rack, and checks the replicated files stay readable from the other rack,
+// and the replicas agree after the partition heals.
+func TestPartitionRack(t *testing.T) {
+	c := StartCluster(t, Topology{
+		Masters: 1,
+		Racks: []RackTopology{
+			{DataCenter: "dc1", Rack: "rack1", VolumeServers: 2},
+			{DataCenter: "dc1", Rack: "rack2", VolumeServers: 2},
+		},
+		DefaultReplication: "010",
+	})
+	defer c.Stop()
+
+	files := make(map[string][]byte)
+	uploadFiles(t, c, "010", 20, files)
+
+	partitioned := c.RackVolumeServers("dc1", "rack2")
+	for _, v := range partitioned {
+		if err := v.Pause(); err != nil {
+			t.Fatalf("pause %s: %v", v.Name, err)
+		}
+	}
+	checkFiles(t, c, files)
+
+	for _, v := range partitioned {
+		if err := v.Resume(); err != nil {
+			t.Fatalf("resume %s: %v", v.Name, err)
+		}
+	}
+	c.WaitForVolumeServers(len(c.VolumeServers), 60*time.Second)
+	c.Eventually(60*time.Second, "replicated writes after the partition healed", func() error {
+		_, err := c.Upload("010", randomData(1024))
+		return err
+	})
+	uploadFiles(t, c, "010", 20, files)
+
+	for fid, data := range files {
+		replicas, err := c.ReadReplicas(fid)
+		if err != nil {
+			t.Errorf("read replicas: %v", err)
+			continue
+		}
+		if len(replicas) != 2 {
+			t.Errorf("file %s has %d replicas", fid, len(replicas))
+		}
+		for _, replica := range replicas {
+			if !bytes.Equal(replica, data) {
+				t.Errorf("file %s has a different replica", fid)
+			}
+		}
+	}
+}
+
+// TestFillDisk writes until the volume servers are full, and checks the writes are refused
+// with all written files still readable.
+func TestFillDisk(t *testing.T) {
+	c := StartCluster(t, Topology{
+		Masters:           1,
+		Racks:             []RackTopology{{DataCenter: "dc1", Rack: "rack1", VolumeServers: 1}},
+		VolumeSizeLimitMB: 1,
+		MaxVolumes:        2,
+	})
+	defer c.Stop()
+
+	files := make(map[string][]byte)
+	var uploadErr error
+	deadline := time.Now().Add(2 * time.Minute)
+	for uploadErr == nil && time.Now().Before(deadline) {
+		data := randomData(100 * 1024)
+		var fid string
+		if fid, uploadErr = c.Upload("000", data); uploadErr == nil {
+			files[fid] = data
+		}
+	}
+	if uploadErr == nil {
+		t.Fatalf("still writable after %d files", len(files))
+	}
+	t.Logf("full after %d files: %v", len(files), uploadErr)
+	// the sizes reach the master by the heartbeats, so the volumes may overflow a little
+	if len(files) < 10 || len(files) > 40 {
+		t.Errorf("%d files of 100KB in 2 volumes of 1MB", len(files))
+	}
+
+	checkFiles(t, c, files)
+	nodes, err := c.DataNodes()
+	if err != nil {
+		t.Fatalf("data nodes: %v", err)
+	}
+	if nodes[0].Free != 0 {
+		t.Errorf("full volume server has %d free volume slots", nodes[0].Free)
+	}
+	if _, err = c.Upload("000", randomData(1024)); err == nil {
+		t.Errorf("upload to the full cluster succeeded")
+	}
+}
+
+func uploadFiles(t *testing.T, c *Cluster, replication string, count int, files map[string][]byte) {
+	for i := 0; i < count; i++ {
+		data := randomData(1024 + i)
+		fid, err := c.Upload(replication, data)
+		if err != nil {
+			t.Fatalf("upload: %v", err)
+		}
+		if _, found := files[fid]; found {
+			t.Fatalf("file id %s assigned twice", fid)
+		}
+		files[fid] = data
+	}
+}
+
+func checkFiles(t *testing.T, c *Cluster, files map[string][]byte) {
+	for fid, data := range files {
+		read, err := c.Read(fid)
+		if err != nil {
+			t.Errorf("read %s: %v", fid, err)
+			continue
+		}
+		if !bytes.Equal(read, data) {
+			t.Errorf("read %s: %d bytes different from the %d bytes written", fid, len(read), len(data))
+		}
+	}
+}
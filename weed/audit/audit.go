@@ -0,0 +1,117 @@
+package audit
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/util"
+	"github.com/spf13/viper"
+)
+
+// Event is one audited request to the filer or the s3 gateway
+type Event struct {
+	Time      time.Time `json:"time"`
+	Server    string    `json:"server"` // "filer" or "s3"
+	Who       string    `json:"who"`    // the s3 access key or "anonymous", or the remote host of the filer requests
+	RemoteIp  string    `json:"remote_ip"`
+	Action    string    `json:"action"` // the filer http method, or the s3 operation like REST.PUT.OBJECT
+	Path      string    `json:"path,omitempty"`
+	NewPath   string    `json:"new_path,omitempty"` // the renamed path
+	Bucket    string    `json:"bucket,omitempty"`
+	Key       string    `json:"key,omitempty"`
+	BytesIn   int64     `json:"bytes_in"`
+	BytesOut  int64     `json:"bytes_out"`
+	Status    int       `json:"status"`
+	LatencyMs int64     `json:"latency_ms"`
+}
+
+type Sink interface {
+	// GetName gets the name to locate the configuration in audit.toml file
+	GetName() string
+	// Initialize initializes the sink
+	Initialize(configuration util.Configuration) error
+	// Write writes one event, encoded as one json line without the line break
+	Write(event *Event, line []byte) error
+}
+
+var (
+	Sinks []Sink
+
+	enabledSinks []Sink
+	loadOnce     sync.Once
+)
+
+// LoadConfiguration initializes the enabled sinks, only once for the filer and the s3 gateway in the same process
+func LoadConfiguration(config *viper.Viper) {
+
+	if config == nil {
+		return
+	}
+
+	loadOnce.Do(func() {
+		for _, sink := range Sinks {
+			if config.GetBool(sink.GetName() + ".enabled") {
+				viperSub := config.Sub(sink.GetName())
+				if err := sink.Initialize(viperSub); err != nil {
+					glog.Fatalf("Failed to initialize audit log for %s: %+v", sink.GetName(), err)
+				}
+				enabledSinks = append(enabledSinks, sink)
+				glog.V(0).Infof("Configure audit log sink for %s", sink.GetName())
+			}
+		}
+	})
+
+}
+
+// IsEnabled tells whether any sink is enabled, to skip collecting the events otherwise
+func IsEnabled() bool {
+	return len(enabledSinks) > 0
+}
+
+// Log writes the event to all the enabled sinks. The failures are logged, and do not fail the request.
+func Log(event *Event) {
+	if !IsEnabled() {
+		return
+	}
+	line, err := json.Marshal(event)
+	if err != nil {
+		glog.Errorf("encode audit event %+v: %v", event, err)
+		return
+	}
+	for _, sink := range enabledSinks {
+		if err := sink.Write(event, line); err != nil {
+			glog.Errorf("audit log %s by %s: %v", line, sink.GetName(), err)
+		}
+	}
+}
+
+// ResponseWriter records the status and the bytes sent of the response
+type ResponseWriter struct {
+	http.ResponseWriter
+	Status    int
+	BytesSent int64
+}
+
+func NewResponseWriter(w http.ResponseWriter) *ResponseWriter {
+	return &ResponseWriter{ResponseWriter: w, Status: http.StatusOK}
+}
+
+func (w *ResponseWriter) WriteHeader(status int) {
+	w.Status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *ResponseWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.BytesSent += int64(n)
+	return n, err
+}
+
+func (w *ResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,101 @@
+package file
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/chrislusf/seaweedfs/weed/audit"
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/util"
+)
+
+func init() {
+	audit.Sinks = append(audit.Sinks, &FileSink{})
+}
+
+// FileSink appends the events as json lines to the file, rotated as "<filename>.1", "<filename>.2", ... by the size
+type FileSink struct {
+	sync.Mutex
+	filename   string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+func (s *FileSink) GetName() string {
+	return "file"
+}
+
+func (s *FileSink) Initialize(configuration util.Configuration) (err error) {
+	glog.V(0).Infof("audit.file.filename: %v", configuration.GetString("filename"))
+	maxSizeMb := configuration.GetInt("max_size_mb")
+	if maxSizeMb <= 0 {
+		maxSizeMb = 100
+	}
+	return s.initialize(configuration.GetString("filename"), int64(maxSizeMb)*1024*1024, configuration.GetInt("max_backups"))
+}
+
+func (s *FileSink) initialize(filename string, maxSize int64, maxBackups int) (err error) {
+	if filename == "" {
+		return fmt.Errorf("audit log filename is empty")
+	}
+	s.filename, s.maxSize, s.maxBackups = filename, maxSize, maxBackups
+	return s.open()
+}
+
+func (s *FileSink) open() error {
+	file, err := os.OpenFile(s.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	s.file, s.size = file, stat.Size()
+	return nil
+}
+
+func (s *FileSink) Write(event *audit.Event, line []byte) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.size > 0 && s.size+int64(len(line))+1 > s.maxSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := s.file.Write(append(line, '\n'))
+	s.size += int64(n)
+	return err
+}
+
+// rotate renames the file to "<filename>.1" after shifting the older backups, and keeps at most maxBackups of them
+func (s *FileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	if s.maxBackups <= 0 {
+		if err := os.Remove(s.filename); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return s.open()
+	}
+	os.Remove(backupName(s.filename, s.maxBackups))
+	for i := s.maxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(backupName(s.filename, i), backupName(s.filename, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(s.filename, backupName(s.filename, 1)); err != nil {
+		return err
+	}
+	return s.open()
+}
+
+func backupName(filename string, i int) string {
+	return fmt.Sprintf("%s.%d", filename, i)
+}
@@ -0,0 +1,46 @@
+package file
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chrislusf/seaweedfs/weed/audit"
+)
+
+func TestFileSinkRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "audit.log")
+	s := &FileSink{}
+	if err = s.initialize(filename, 100, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	line := []byte(strings.Repeat("x", 39))
+	for i := 0; i < 10; i++ {
+		if err = s.Write(&audit.Event{}, line); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 2 lines of 40 bytes in each file, and the older files are dropped
+	for _, name := range []string{filename, filename + ".1", filename + ".2"} {
+		data, err := ioutil.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) != 80 {
+			t.Errorf("%s has %d bytes, expecting 80", name, len(data))
+		}
+	}
+	if _, err = os.Stat(filename + ".3"); !os.IsNotExist(err) {
+		t.Errorf("%s.3 should not be kept: %v", filename, err)
+	}
+}
@@ -0,0 +1,67 @@
+package kafka
+
+import (
+	"github.com/Shopify/sarama"
+	"github.com/chrislusf/seaweedfs/weed/audit"
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/util"
+)
+
+func init() {
+	audit.Sinks = append(audit.Sinks, &KafkaSink{})
+}
+
+// KafkaSink sends the events as json messages, keyed by the bucket or the path
+type KafkaSink struct {
+	topic    string
+	producer sarama.AsyncProducer
+}
+
+func (k *KafkaSink) GetName() string {
+	return "kafka"
+}
+
+func (k *KafkaSink) Initialize(configuration util.Configuration) (err error) {
+	glog.V(0).Infof("audit.kafka.hosts: %v", configuration.GetStringSlice("hosts"))
+	glog.V(0).Infof("audit.kafka.topic: %v", configuration.GetString("topic"))
+	return k.initialize(
+		configuration.GetStringSlice("hosts"),
+		configuration.GetString("topic"),
+	)
+}
+
+func (k *KafkaSink) initialize(hosts []string, topic string) (err error) {
+	config := sarama.NewConfig()
+	config.Producer.RequiredAcks = sarama.WaitForLocal
+	config.Producer.Partitioner = sarama.NewHashPartitioner
+	config.Producer.Return.Errors = true
+	k.producer, err = sarama.NewAsyncProducer(hosts, config)
+	if err != nil {
+		return err
+	}
+	k.topic = topic
+	go k.handleError()
+	return nil
+}
+
+func (k *KafkaSink) Write(event *audit.Event, line []byte) error {
+	key := event.Path
+	if event.Bucket != "" {
+		key = event.Bucket
+	}
+	k.producer.Input() <- &sarama.ProducerMessage{
+		Topic: k.topic,
+		Key:   sarama.StringEncoder(key),
+		Value: sarama.ByteEncoder(line),
+	}
+	return nil
+}
+
+func (k *KafkaSink) handleError() {
+	for {
+		err := <-k.producer.Errors()
+		if err != nil {
+			glog.Errorf("audit message error, partition:%d offset:%d key:%v error(%v) topic:%s", err.Msg.Partition, err.Msg.Offset, err.Msg.Key, err.Err, k.topic)
+		}
+	}
+}
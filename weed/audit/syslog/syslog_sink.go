@@ -0,0 +1,44 @@
+// +build !windows,!plan9
+
+package syslog
+
+import (
+	"log/syslog"
+
+	"github.com/chrislusf/seaweedfs/weed/audit"
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/util"
+)
+
+func init() {
+	audit.Sinks = append(audit.Sinks, &SyslogSink{})
+}
+
+// SyslogSink sends the events as json messages to the local or remote syslog
+type SyslogSink struct {
+	writer *syslog.Writer
+}
+
+func (s *SyslogSink) GetName() string {
+	return "syslog"
+}
+
+func (s *SyslogSink) Initialize(configuration util.Configuration) (err error) {
+	glog.V(0).Infof("audit.syslog.network: %v", configuration.GetString("network"))
+	glog.V(0).Infof("audit.syslog.address: %v", configuration.GetString("address"))
+	tag := configuration.GetString("tag")
+	if tag == "" {
+		tag = "seaweedfs-audit"
+	}
+	// the network and the address are empty for the local syslog
+	s.writer, err = syslog.Dial(configuration.GetString("network"), configuration.GetString("address"), syslog.LOG_INFO|syslog.LOG_AUTHPRIV, tag)
+	return err
+}
+
+func (s *SyslogSink) Write(event *audit.Event, line []byte) error {
+	// the failed requests are logged as warnings
+	if event.Status >= 400 {
+		return s.writer.Warning(string(line))
+	}
+	return s.writer.Info(string(line))
+}
@@ -0,0 +1,5 @@
+// +build windows plan9
+
+package syslog
+
+// the syslog is not available on windows and plan9, so the audit log has no syslog sink there
@@ -0,0 +1,253 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/util"
+)
+
+// fileReader reads the file through the block cache, for http.ServeContent
+type fileReader struct {
+	s      *Server
+	f      *file
+	offset int64
+	// the block being read, since the reads are much smaller than the blocks
+	block    []byte
+	blockKey string
+	err      error
+}
+
+// blockFetch is one block being read from the volume servers, shared by the readers of the same block
+type blockFetch struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
+
+func (r *fileReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.f.size
+	default:
+		return 0, fmt.Errorf("invalid whence %d", whence)
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("negative position %d", offset)
+	}
+	r.offset = offset
+	return offset, nil
+}
+
+func (r *fileReader) Read(p []byte) (n int, err error) {
+	if r.offset >= r.f.size {
+		return 0, io.EOF
+	}
+	if remaining := r.f.size - r.offset; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+
+	views := filer2.ViewFromVisibleIntervals(r.f.visibles, r.offset, len(p))
+	if len(views) == 0 || views[0].LogicOffset > r.offset {
+		// the holes read as zeros
+		if len(views) > 0 {
+			p = p[:views[0].LogicOffset-r.offset]
+		}
+		for i := range p {
+			p[i] = 0
+		}
+		r.offset += int64(len(p))
+		return len(p), nil
+	}
+
+	view := views[0]
+	blockSize := r.s.option.BlockSize
+	index := view.Offset / blockSize
+	if key := blockKey(view.FileId, index); key != r.blockKey {
+		if r.block, err = r.s.readBlock(view, index); err != nil {
+			r.block, r.blockKey, r.err = nil, "", err
+			return 0, err
+		}
+		r.blockKey = key
+		r.s.readAhead(r.f, view.LogicOffset-view.Offset+(index+1)*blockSize)
+	}
+
+	start := view.Offset - index*blockSize
+	if start >= int64(len(r.block)) {
+		r.err = fmt.Errorf("block %s has %d bytes, shorter than the view at %d", r.blockKey, len(r.block), start)
+		return 0, r.err
+	}
+	n = copy(p[:view.Size], r.block[start:])
+	r.offset += int64(n)
+	return n, nil
+}
+
+// the block index of a file id "3,01637037d6" is cached as "3,01637037d6@2"
+func blockKey(fileId string, index int64) string {
+	return fmt.Sprintf("%s@%d", fileId, index)
+}
+
+func (s *Server) readBlock(view *filer2.ChunkView, index int64) ([]byte, error) {
+	if data := s.blockCache.GetChunk(blockKey(view.FileId, index)); data != nil {
+		return data, nil
+	}
+	return s.fetchBlock(view, index)
+}
+
+// readAhead fetches the blocks from the file offset into the block cache in the background
+func (s *Server) readAhead(f *file, offset int64) {
+	if s.option.ReadAheadBlocks <= 0 || offset >= f.size {
+		return
+	}
+
+	blockSize := s.option.BlockSize
+	count := 0
+	for _, view := range filer2.ViewFromVisibleIntervals(f.visibles, offset, int(blockSize)*s.option.ReadAheadBlocks) {
+		for index := view.Offset / blockSize; index*blockSize < view.Offset+int64(view.Size); index++ {
+			if count >= s.option.ReadAheadBlocks {
+				return
+			}
+			count++
+			key := blockKey(view.FileId, index)
+			if s.blockCache.HasChunk(key) || s.isFetching(key) {
+				continue
+			}
+			go func(view *filer2.ChunkView, index int64) {
+				if _, err := s.fetchBlock(view, index); err != nil {
+					glog.V(1).Infof("read ahead %s: %v", blockKey(view.FileId, index), err)
+				}
+			}(view, index)
+		}
+	}
+}
+
+// fetchBlock reads the block once for all the concurrent readers, and caches it
+func (s *Server) fetchBlock(view *filer2.ChunkView, index int64) ([]byte, error) {
+	key := blockKey(view.FileId, index)
+
+	s.fetchingLock.Lock()
+	fetch, found := s.fetching[key]
+	if !found {
+		fetch = &blockFetch{done: make(chan struct{})}
+		s.fetching[key] = fetch
+	}
+	s.fetchingLock.Unlock()
+
+	if found {
+		<-fetch.done
+		return fetch.data, fetch.err
+	}
+
+	fetch.data, fetch.err = s.fetchFromVolume(view, index)
+
+	s.fetchingLock.Lock()
+	delete(s.fetching, key)
+	s.fetchingLock.Unlock()
+	close(fetch.done)
+
+	return fetch.data, fetch.err
+}
+
+func (s *Server) isFetching(key string) bool {
+	s.fetchingLock.Lock()
+	defer s.fetchingLock.Unlock()
+	_, found := s.fetching[key]
+	return found
+}
+
+// fetchFromVolume reads the block by range, or the whole chunk if compressed or encrypted,
+// trying the volume servers one by one
+func (s *Server) fetchFromVolume(view *filer2.ChunkView, index int64) (data []byte, err error) {
+	locations, err := s.lookupLocations(context.Background(), filer2.VolumeId(view.FileId))
+	if err != nil {
+		return nil, err
+	}
+	err = fmt.Errorf("failed to locate %s", view.FileId)
+
+	blockSize := s.option.BlockSize
+	for _, location := range locations {
+		fileUrl := fmt.Sprintf("http://%s/%s", location.Url, view.FileId)
+		if view.Compression != "" || len(view.CipherKey) > 0 {
+			data, err = s.fetchWholeChunk(fileUrl, view, index)
+		} else if data, err = fetchRange(fileUrl, index*blockSize, blockSize); err == nil {
+			s.blockCache.SetChunk(blockKey(view.FileId, index), data)
+		}
+		if err == nil {
+			return data, nil
+		}
+		glog.V(1).Infof("read %s: %v", fileUrl, err)
+	}
+	return nil, err
+}
+
+// fetchWholeChunk reads and caches all the blocks of the chunk, since the ranges are of the original content
+func (s *Server) fetchWholeChunk(fileUrl string, view *filer2.ChunkView, index int64) ([]byte, error) {
+	data, err := filer2.FetchWholeChunk(fileUrl, view)
+	if err != nil {
+		return nil, err
+	}
+	blockSize := s.option.BlockSize
+	for i := int64(0); i*blockSize < int64(len(data)); i++ {
+		stop := (i + 1) * blockSize
+		if stop > int64(len(data)) {
+			stop = int64(len(data))
+		}
+		s.blockCache.SetChunk(blockKey(view.FileId, i), data[i*blockSize:stop])
+	}
+	if index*blockSize >= int64(len(data)) {
+		return nil, fmt.Errorf("chunk %s has %d bytes, shorter than the block %d", view.FileId, len(data), index)
+	}
+	stop := (index + 1) * blockSize
+	if stop > int64(len(data)) {
+		stop = int64(len(data))
+	}
+	return data[index*blockSize : stop], nil
+}
+
+// fetchRange reads the bytes [offset, offset+size) of the url, fewer at the end of the content
+func fetchRange(fileUrl string, offset, size int64) ([]byte, error) {
+	req, err := http.NewRequest("GET", fileUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+size-1))
+	resp, err := util.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	expected := resp.ContentLength
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusOK:
+		// the whole content, if the range is ignored
+		if _, err = io.CopyN(ioutil.Discard, resp.Body, offset); err != nil {
+			return nil, err
+		}
+		if expected >= 0 {
+			if expected -= offset; expected > size {
+				expected = size
+			}
+		}
+	default:
+		return nil, fmt.Errorf("%s: %s", fileUrl, resp.Status)
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, size))
+	if err != nil {
+		return nil, err
+	}
+	if expected >= 0 && int64(len(data)) != expected {
+		return nil, fmt.Errorf("%s: read %d bytes, expected %d", fileUrl, len(data), expected)
+	}
+	return data, nil
+}
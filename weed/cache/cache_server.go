@@ -0,0 +1,273 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+	"github.com/chrislusf/seaweedfs/weed/util"
+	"github.com/chrislusf/seaweedfs/weed/util/chunk_cache"
+	"google.golang.org/grpc"
+)
+
+// the metadata cache is cleared when it grows over this number of files
+const maxCachedFiles = 100000
+
+type Option struct {
+	FilerGrpcAddress string
+	GrpcDialOption   grpc.DialOption
+	// the local directory of the cached blocks, better on a fast disk
+	CacheDir      string
+	CacheCapacity int64 // in bytes
+	// the chunks are read and cached in blocks of this size, so the ranges in the same block are read from the origin once
+	BlockSize int64
+	// the blocks after the ones read are fetched in the background, for the sequential reads like the video segments
+	ReadAheadBlocks int
+	// how long the file metadata and the volume locations are used before asking the filer again
+	MetaTtl time.Duration
+}
+
+// Server is a read only http server in front of the filer and the volume servers,
+// caching the hot blocks of the file chunks on the local disk.
+//
+// The paths are read as the filer paths, except the file ids like /3,01637037d6.jpg, which are read
+// from the volume servers as the volume server urls. The range requests are served from the blocks covering them,
+// and the concurrent requests of the same block wait for one read from the origin.
+// The chunks never change once written, so the cached blocks are never stale, only the file metadata
+// may be stale for up to the MetaTtl.
+type Server struct {
+	option      *Option
+	filerClient filer2.FilerClient
+	blockCache  *chunk_cache.ChunkCache
+
+	fetchingLock sync.Mutex
+	fetching     map[string]*blockFetch
+
+	metaLock  sync.Mutex
+	files     map[string]*cachedFile
+	locations map[string]*cachedLocations
+}
+
+// file is a filer entry or a volume server file id to serve, nil if not found
+type file struct {
+	name        string
+	isDirectory bool
+	size        int64
+	mime        string
+	mtime       time.Time
+	etag        string
+	visibles    []filer2.VisibleInterval
+}
+
+type cachedFile struct {
+	file     *file
+	expireAt time.Time
+}
+
+type cachedLocations struct {
+	locations []*filer_pb.Location
+	expireAt  time.Time
+}
+
+func NewServer(option *Option) (*Server, error) {
+	blockCache, err := chunk_cache.NewChunkCache(option.CacheDir, option.CacheCapacity)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		option:     option,
+		blockCache: blockCache,
+		fetching:   make(map[string]*blockFetch),
+		files:      make(map[string]*cachedFile),
+		locations:  make(map[string]*cachedLocations),
+	}
+	s.filerClient = s
+	return s, nil
+}
+
+func (s *Server) WithFilerClient(ctx context.Context, fn func(filer_pb.SeaweedFilerClient) error) error {
+	return util.WithCachedGrpcClient(ctx, func(grpcConnection *grpc.ClientConn) error {
+		return fn(filer_pb.NewSeaweedFilerClient(grpcConnection))
+	}, s.option.FilerGrpcAddress, s.option.GrpcDialOption)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "only GET and HEAD are supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	urlPath := path.Clean("/" + r.URL.Path)
+	f, err := s.lookupFile(r.Context(), urlPath)
+	if err != nil {
+		glog.V(0).Infof("cache lookup %s: %v", urlPath, err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	if f == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if f.isDirectory {
+		http.Error(w, "listing directories is not supported", http.StatusForbidden)
+		return
+	}
+
+	if f.mime != "" {
+		w.Header().Set("Content-Type", f.mime)
+	}
+	if f.etag != "" {
+		w.Header().Set("ETag", f.etag)
+	}
+	reader := &fileReader{s: s, f: f}
+	http.ServeContent(w, r, f.name, f.mtime, reader)
+	if reader.err != nil {
+		glog.V(0).Infof("cache read %s: %v", urlPath, reader.err)
+	}
+}
+
+// lookupFile finds the file of the path, from the metadata cached within the MetaTtl
+func (s *Server) lookupFile(ctx context.Context, urlPath string) (f *file, err error) {
+	s.metaLock.Lock()
+	cached, found := s.files[urlPath]
+	s.metaLock.Unlock()
+	if found && time.Now().Before(cached.expireAt) {
+		return cached.file, nil
+	}
+
+	if fileId, isFileId := parseFileId(urlPath); isFileId {
+		f, err = s.headFileId(ctx, fileId, path.Base(urlPath))
+	} else {
+		f, err = s.getEntry(ctx, urlPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	s.metaLock.Lock()
+	if len(s.files) >= maxCachedFiles {
+		for key, c := range s.files {
+			if now.After(c.expireAt) {
+				delete(s.files, key)
+			}
+		}
+		if len(s.files) >= maxCachedFiles {
+			s.files = make(map[string]*cachedFile)
+		}
+	}
+	s.files[urlPath] = &cachedFile{file: f, expireAt: now.Add(s.option.MetaTtl)}
+	s.metaLock.Unlock()
+
+	return f, nil
+}
+
+func (s *Server) getEntry(ctx context.Context, fullpath string) (*file, error) {
+	entry, err := filer2.GetEntry(ctx, s.filerClient, fullpath)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+	f := &file{
+		name:        entry.Name,
+		isDirectory: entry.IsDirectory,
+		size:        int64(filer2.FileSize(entry)),
+		etag:        "\"" + filer2.ETag(entry.Chunks) + "\"",
+		visibles:    filer2.NonOverlappingVisibleIntervals(entry.Chunks),
+	}
+	if entry.Attributes != nil {
+		f.mime = entry.Attributes.Mime
+		f.mtime = time.Unix(entry.Attributes.Mtime, 0)
+	}
+	return f, nil
+}
+
+// headFileId asks the volume servers for the size and the headers of the file id
+func (s *Server) headFileId(ctx context.Context, fileId string, name string) (*file, error) {
+	locations, err := s.lookupLocations(ctx, filer2.VolumeId(fileId))
+	if err != nil || len(locations) == 0 {
+		return nil, err
+	}
+
+	for _, location := range locations {
+		fileUrl := fmt.Sprintf("http://%s/%s", location.Url, fileId)
+		req, _ := http.NewRequest("HEAD", fileUrl, nil)
+		resp, headErr := util.Do(req)
+		if headErr != nil {
+			err = headErr
+			glog.V(1).Infof("head %s: %v", fileUrl, err)
+			continue
+		}
+		resp.Body.Close()
+		switch {
+		case resp.StatusCode == http.StatusNotFound:
+			return nil, nil
+		case resp.StatusCode >= 400:
+			err = fmt.Errorf("%s: %s", fileUrl, resp.Status)
+			continue
+		case resp.ContentLength < 0:
+			return nil, fmt.Errorf("%s: unknown size", fileUrl)
+		}
+		mtime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+		return &file{
+			name:  name,
+			size:  resp.ContentLength,
+			mime:  resp.Header.Get("Content-Type"),
+			mtime: mtime,
+			etag:  resp.Header.Get("ETag"),
+			visibles: filer2.NonOverlappingVisibleIntervals([]*filer_pb.FileChunk{{
+				FileId: fileId,
+				Size:   uint64(resp.ContentLength),
+			}}),
+		}, nil
+	}
+	return nil, err
+}
+
+// lookupLocations finds the volume servers of the volume, from the locations cached within the MetaTtl
+func (s *Server) lookupLocations(ctx context.Context, vid string) ([]*filer_pb.Location, error) {
+	s.metaLock.Lock()
+	cached, found := s.locations[vid]
+	s.metaLock.Unlock()
+	if found && time.Now().Before(cached.expireAt) {
+		return cached.locations, nil
+	}
+
+	vid2Locations, err := filer2.LookupVolumeLocations(ctx, s.filerClient, []string{vid})
+	if err != nil {
+		return nil, err
+	}
+	var locations []*filer_pb.Location
+	if vid2Locations[vid] != nil {
+		locations = vid2Locations[vid].Locations
+	}
+
+	s.metaLock.Lock()
+	s.locations[vid] = &cachedLocations{locations: locations, expireAt: time.Now().Add(s.option.MetaTtl)}
+	s.metaLock.Unlock()
+
+	return locations, nil
+}
+
+// parseFileId tells the paths like /3,01637037d6 or /3,01637037d6.jpg, which are read from the volume servers
+func parseFileId(urlPath string) (fileId string, isFileId bool) {
+	name := strings.TrimPrefix(urlPath, "/")
+	if dot := strings.LastIndex(name, "."); dot > 0 {
+		name = name[:dot]
+	}
+	if _, err := needle.ParseFileIdFromString(name); err != nil {
+		return "", false
+	}
+	return name, true
+}
@@ -0,0 +1,153 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+	"github.com/chrislusf/seaweedfs/weed/util"
+	"google.golang.org/grpc"
+)
+
+type fakeFiler struct {
+	filer_pb.SeaweedFilerClient
+	entries   map[string]*filer_pb.Entry
+	volumeUrl string
+}
+
+func (f *fakeFiler) WithFilerClient(ctx context.Context, fn func(filer_pb.SeaweedFilerClient) error) error {
+	return fn(f)
+}
+
+func (f *fakeFiler) LookupDirectoryEntry(ctx context.Context, in *filer_pb.LookupDirectoryEntryRequest, opts ...grpc.CallOption) (*filer_pb.LookupDirectoryEntryResponse, error) {
+	entry, found := f.entries[string(filer2.NewFullPath(in.Directory, in.Name))]
+	if !found {
+		return nil, filer2.ErrNotFound
+	}
+	return &filer_pb.LookupDirectoryEntryResponse{Entry: entry}, nil
+}
+
+func (f *fakeFiler) LookupVolume(ctx context.Context, in *filer_pb.LookupVolumeRequest, opts ...grpc.CallOption) (*filer_pb.LookupVolumeResponse, error) {
+	resp := &filer_pb.LookupVolumeResponse{LocationsMap: make(map[string]*filer_pb.Locations)}
+	for _, vid := range in.VolumeIds {
+		if vid == "3" {
+			resp.LocationsMap[vid] = &filer_pb.Locations{Locations: []*filer_pb.Location{{Url: f.volumeUrl}}}
+		}
+	}
+	return resp, nil
+}
+
+func TestCacheServer(t *testing.T) {
+	plain := []byte("0123456789abcdefghijklmnopqrstuvwxyz")
+	original := []byte("the content of the compressed chunk")
+	gzipped, _ := util.GzipData(original)
+	stored := map[string][]byte{"3,01637037d6": plain, "3,02637037d6": gzipped}
+
+	var reads int32
+	volume := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, found := stored[strings.TrimPrefix(r.URL.Path, "/")]
+		if !found {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method == "GET" {
+			atomic.AddInt32(&reads, 1)
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		http.ServeContent(w, r, "", time.Unix(1500000000, 0), bytes.NewReader(data))
+	}))
+	defer volume.Close()
+
+	dir, err := ioutil.TempDir("", "cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := NewServer(&Option{CacheDir: dir, CacheCapacity: 1024, BlockSize: 8, MetaTtl: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.filerClient = &fakeFiler{
+		volumeUrl: strings.TrimPrefix(volume.URL, "http://"),
+		entries: map[string]*filer_pb.Entry{
+			"/videos": {Name: "videos", IsDirectory: true},
+			"/videos/a.mp4": {
+				Name: "a.mp4",
+				Chunks: []*filer_pb.FileChunk{
+					{FileId: "3,01637037d6", Offset: 0, Size: uint64(len(plain)), Mtime: 1},
+					{FileId: "3,02637037d6", Offset: 40, Size: uint64(len(original)), Mtime: 2, Compression: filer2.CompressionGzip},
+				},
+				Attributes: &filer_pb.FuseAttributes{Mime: "video/mp4", Mtime: 1500000000},
+			},
+		},
+	}
+
+	// the hole between the chunks reads as zeros
+	content := append(append(append([]byte{}, plain...), 0, 0, 0, 0), original...)
+
+	get := func(method, path, byteRange string) (int, []byte) {
+		req := httptest.NewRequest(method, path, nil)
+		if byteRange != "" {
+			req.Header.Set("Range", byteRange)
+		}
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		return w.Code, w.Body.Bytes()
+	}
+
+	for round := 0; round < 2; round++ {
+		for _, c := range []struct {
+			byteRange string
+			status    int
+			expected  []byte
+		}{
+			{"", http.StatusOK, content},
+			{"bytes=5-20", http.StatusPartialContent, content[5:21]},
+			{"bytes=30-45", http.StatusPartialContent, content[30:46]},
+			{"bytes=-3", http.StatusPartialContent, content[len(content)-3:]},
+		} {
+			status, body := get("GET", "/videos/a.mp4", c.byteRange)
+			if status != c.status || !bytes.Equal(body, c.expected) {
+				t.Errorf("round %d range %q: %d %q, expected %d %q", round, c.byteRange, status, body, c.status, c.expected)
+			}
+		}
+		// the plain chunk is read by the blocks of 8 bytes, and the compressed chunk in whole
+		if n := atomic.LoadInt32(&reads); n != 6 {
+			t.Errorf("round %d: %d reads from the volume server, expected 6", round, n)
+		}
+	}
+
+	// the file ids share the cached blocks with the filer files
+	if status, body := get("GET", "/3,01637037d6.txt", "bytes=10-12"); status != http.StatusPartialContent || string(body) != "abc" {
+		t.Errorf("file id range: %d %q", status, body)
+	}
+	if n := atomic.LoadInt32(&reads); n != 6 {
+		t.Errorf("%d reads from the volume server after reading the file id, expected 6", n)
+	}
+
+	for _, c := range []struct {
+		method, path string
+		status       int
+	}{
+		{"HEAD", "/videos/a.mp4", http.StatusOK},
+		{"GET", "/videos/missing.mp4", http.StatusNotFound},
+		{"GET", "/3,09637037d6", http.StatusNotFound},
+		{"GET", "/4,01637037d6", http.StatusNotFound},
+		{"GET", "/videos", http.StatusForbidden},
+		{"PUT", "/videos/a.mp4", http.StatusMethodNotAllowed},
+	} {
+		if status, _ := get(c.method, c.path, ""); status != c.status {
+			t.Errorf("%s %s: %d, expected %d", c.method, c.path, status, c.status)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package command
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+
+	"github.com/chrislusf/seaweedfs/weed/operation"
+	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
+	"github.com/chrislusf/seaweedfs/weed/security"
+	"github.com/chrislusf/seaweedfs/weed/storage/cloud_backup"
+	"github.com/chrislusf/seaweedfs/weed/util"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+)
+
+var (
+	bc BackupCloudOptions
+)
+
+type BackupCloudOptions struct {
+	master     *string
+	collection *string
+	volumeId   *int
+	store      cloudStoreOptions
+}
+
+// cloudStoreOptions locates the backups, shared by backup.cloud and restore.cloud
+type cloudStoreOptions struct {
+	storageType *string
+	bucket      *string
+	prefix      *string
+	region      *string
+	endpoint    *string
+}
+
+func newCloudStoreOptions(flagSet *flag.FlagSet) cloudStoreOptions {
+	return cloudStoreOptions{
+		storageType: flagSet.String("storage", "s3", "the cloud storage, s3 or gcs"),
+		bucket:      flagSet.String("bucket", "", "the bucket to keep the backups"),
+		prefix:      flagSet.String("prefix", "", "the folder in the bucket to keep the backups"),
+		region:      flagSet.String("region", "us-east-1", "the s3 region"),
+		endpoint:    flagSet.String("endpoint", "", "the endpoint of the s3 compatible storage other than aws"),
+	}
+}
+
+func (o cloudStoreOptions) connect() (cloud_backup.ObjectStore, error) {
+	if *o.bucket == "" {
+		return nil, fmt.Errorf("missing -bucket")
+	}
+	return cloud_backup.NewObjectStore(*o.storageType, *o.bucket, *o.region, *o.endpoint)
+}
+
+func init() {
+	cmdBackupCloud.Run = runBackupCloud // break init cycle
+	bc.master = cmdBackupCloud.Flag.String("master", "localhost:9333", "SeaweedFS master location")
+	bc.collection = cmdBackupCloud.Flag.String("collection", "", "only backup the volumes in this collection")
+	bc.volumeId = cmdBackupCloud.Flag.Int("volumeId", -1, "only backup this volume, instead of all the volumes")
+	bc.store = newCloudStoreOptions(&cmdBackupCloud.Flag)
+}
+
+var cmdBackupCloud = &Command{
+	UsageLine: "backup.cloud -master=localhost:9333 -storage=s3 -bucket=<bucket> -prefix=<folder>",
+	Short:     "incrementally backup the volumes to s3 or google cloud storage",
+	Long: `Incrementally backup the volume .dat and .idx files to s3 or google cloud storage.
+
+	Each volume is kept under <prefix>/<collection>_<volumeId>/ in the bucket, with a manifest.json
+	listing the segments of the current backup generation. The first backup of a generation uploads
+	the full volume files, and each later backup only uploads the data appended since the last backup.
+	A new generation starts when the volume is compacted, or moved to another volume server,
+	and the earlier generation is deleted once the new one is backed up.
+
+	Run it periodically, e.g. in a cron job. The erasure coded volumes are not backed up.
+
+	For s3, the credentials are read from the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY env variables,
+	or the default aws credential files. For gcs, set the GOOGLE_APPLICATION_CREDENTIALS env variable.
+
+	Use "weed restore.cloud" to restore the volumes.
+  `,
+}
+
+func runBackupCloud(cmd *Command, args []string) bool {
+
+	util.LoadConfiguration("security", false)
+	grpcDialOption := security.LoadClientTLS(viper.Sub("grpc"), "client")
+
+	store, err := bc.store.connect()
+	if err != nil {
+		fmt.Printf("Error connecting to the cloud storage: %v\n", err)
+		return false
+	}
+
+	volumes, err := collectVolumeLocations(*bc.master, grpcDialOption)
+	if err != nil {
+		fmt.Printf("Error listing volumes from %s: %v\n", *bc.master, err)
+		return true
+	}
+
+	var vids []uint32
+	for vid, volume := range volumes {
+		if *bc.volumeId >= 0 && vid != uint32(*bc.volumeId) {
+			continue
+		}
+		if *bc.collection != "" && volume.collection != *bc.collection {
+			continue
+		}
+		vids = append(vids, vid)
+	}
+	sort.Slice(vids, func(i, j int) bool { return vids[i] < vids[j] })
+
+	ctx := context.Background()
+	for _, vid := range vids {
+		volume := volumes[vid]
+		m, segment, err := cloud_backup.BackupVolume(ctx, store, *bc.store.prefix, volume.servers, grpcDialOption, volume.collection, vid)
+		if err != nil {
+			fmt.Printf("Error backing up volume %d: %v\n", vid, err)
+			continue
+		}
+		if segment == nil {
+			fmt.Printf("volume %d unchanged\n", vid)
+			continue
+		}
+		fmt.Printf("volume %d backed up %d bytes from %s, generation %d segment %d\n",
+			vid, segment.DatSize+segment.IdxSize, m.Server, m.Generation, len(m.Segments)-1)
+	}
+
+	return true
+}
+
+type volumeLocations struct {
+	collection string
+	servers    []string
+}
+
+func collectVolumeLocations(master string, grpcDialOption grpc.DialOption) (map[uint32]*volumeLocations, error) {
+	var resp *master_pb.VolumeListResponse
+	err := operation.WithMasterServerClient(master, grpcDialOption, func(client master_pb.SeaweedClient) (err error) {
+		resp, err = client.VolumeList(context.Background(), &master_pb.VolumeListRequest{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	volumes := make(map[uint32]*volumeLocations)
+	for _, dc := range resp.TopologyInfo.DataCenterInfos {
+		for _, rack := range dc.RackInfos {
+			for _, dn := range rack.DataNodeInfos {
+				for _, v := range dn.VolumeInfos {
+					volume, found := volumes[v.Id]
+					if !found {
+						volume = &volumeLocations{collection: v.Collection}
+						volumes[v.Id] = volume
+					}
+					volume.servers = append(volume.servers, dn.Id)
+				}
+			}
+		}
+	}
+	return volumes, nil
+}
@@ -39,6 +39,12 @@ type BenchmarkOptions struct {
 	replication      *string
 	cpuprofile       *string
 	maxCpu           *int
+	mode             *string
+	filer            *string
+	s3Endpoint       *string
+	bucket           *string
+	sizes            *string
+	readPercent      *int
 	grpcDialOption   grpc.DialOption
 	masterClient     *wdclient.MasterClient
 }
@@ -65,6 +71,12 @@ func init() {
 	b.replication = cmdBenchmark.Flag.String("replication", "000", "replication type")
 	b.cpuprofile = cmdBenchmark.Flag.String("cpuprofile", "", "cpu profile output file")
 	b.maxCpu = cmdBenchmark.Flag.Int("maxCpu", 0, "maximum number of CPUs. 0 means all available CPUs")
+	b.mode = cmdBenchmark.Flag.String("mode", "volume", "[volume|filer|s3] write to the volume servers directly, or through the filer http api, or the s3 gateway")
+	b.filer = cmdBenchmark.Flag.String("filer", "localhost:8888", "the filer address for -mode=filer")
+	b.s3Endpoint = cmdBenchmark.Flag.String("s3", "http://localhost:8333", "the s3 endpoint for -mode=s3, with the credentials in AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	b.bucket = cmdBenchmark.Flag.String("bucket", "benchmark", "the s3 bucket for -mode=s3, or the filer directory for -mode=filer")
+	b.sizes = cmdBenchmark.Flag.String("sizes", "", "the object size distribution for -mode=filer or s3, e.g. 4k:50,64k:30,4m:20 for weighted sizes. Empty for -size")
+	b.readPercent = cmdBenchmark.Flag.Int("readPercent", 0, "for -mode=filer or s3, run one mixed test with this percent of reads of the objects already written, instead of the write and read tests")
 	sharedBytes = make([]byte, 1024)
 }
 
@@ -97,6 +109,12 @@ var cmdBenchmark = &Command{
   After benchmarking, you can clean up the written data by deleting the benchmark collection
     http://localhost:9333/col/delete?collection=benchmark
 
+  With -mode=filer or -mode=s3, the objects are written and read through the filer http api or
+  the s3 gateway, to include the metadata path in the numbers. The object sizes can follow a
+  distribution by -sizes, and -readPercent runs the reads and the writes mixed together:
+    weed benchmark -mode=s3 -s3=http://localhost:8333 -bucket=benchmark -sizes=4k:50,1m:50 -readPercent=80
+  The object keys are stored in the "-list" specified file, instead of the file ids.
+
   `,
 }
 
@@ -125,6 +143,10 @@ func runBenchmark(cmd *Command, args []string) bool {
 		defer pprof.StopCPUProfile()
 	}
 
+	if *b.mode != "volume" {
+		return runObjectBenchmark()
+	}
+
 	b.masterClient = wdclient.NewMasterClient(context.Background(), b.grpcDialOption, "client", strings.Split(*b.masters, ","))
 	go b.masterClient.KeepConnectedToMaster()
 	b.masterClient.WaitUntilConnected()
@@ -420,6 +442,13 @@ func (s *stats) checkProgress(testName string, finishChan chan bool) {
 	}
 }
 
+func (s *stats) countCompleted() (completed int) {
+	for _, localStat := range s.localStats {
+		completed += localStat.completed
+	}
+	return
+}
+
 func (s *stats) printStats() {
 	completed, failed, transferred, total := 0, 0, int64(0), s.total
 	for _, localStat := range s.localStats {
@@ -429,7 +458,7 @@ func (s *stats) printStats() {
 		total += localStat.total
 	}
 	timeTaken := float64(int64(s.end.Sub(s.start))) / 1000000000
-	fmt.Printf("\nConcurrency Level:      %d\n", *b.concurrency)
+	fmt.Printf("\nConcurrency Level:      %d\n", len(s.localStats))
 	fmt.Printf("Time taken for tests:   %.3f seconds\n", timeTaken)
 	fmt.Printf("Complete requests:      %d\n", completed)
 	fmt.Printf("Failed requests:        %d\n", failed)
@@ -0,0 +1,474 @@
+package command
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+)
+
+// objectClient writes and reads the benchmark objects through the filer or the s3 gateway
+type objectClient interface {
+	put(key string, data []byte) error
+	get(key string) (int64, error)
+}
+
+// objectSize is one weighted size of the -sizes distribution
+type objectSize struct {
+	size   int64
+	weight int
+}
+
+var (
+	// guards the addSample of writeStats and readStats from the concurrent workers
+	objectStatsLock sync.Mutex
+	// the shared content of all the objects, sliced to each object size
+	objectPayload []byte
+)
+
+// the object sizes vary a little around the distribution, and the objects start at varying payload offsets
+const (
+	objectSizeJitter   = 64
+	objectOffsetJitter = 64
+)
+
+func runObjectBenchmark() bool {
+	sizes, err := parseObjectSizes(*b.sizes, int64(*b.fileSize))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "-sizes: %v\n", err)
+		return false
+	}
+	var maxSize int64
+	for _, s := range sizes {
+		if s.size > maxSize {
+			maxSize = s.size
+		}
+	}
+	objectPayload = make([]byte, maxSize+objectSizeJitter+objectOffsetJitter)
+	rand.Read(objectPayload) // not compressible
+
+	var client objectClient
+	switch *b.mode {
+	case "filer":
+		client = newFilerObjectClient(*b.filer, *b.bucket, *b.concurrency)
+	case "s3":
+		if client, err = newS3ObjectClient(*b.s3Endpoint, *b.bucket); err != nil {
+			fmt.Fprintf(os.Stderr, "s3: %v\n", err)
+			return false
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown -mode=%s, expecting volume, filer, or s3\n", *b.mode)
+		return false
+	}
+
+	if *b.readPercent > 0 {
+		benchMixedObjects(client, sizes)
+		return true
+	}
+	if *b.write {
+		benchWriteObjects(client, sizes)
+	}
+	if *b.read {
+		benchReadObjects(client)
+	}
+	return true
+}
+
+// parseObjectSizes parses the weighted sizes like "4k:50,64k:30,4m:20"
+func parseObjectSizes(s string, defaultSize int64) (sizes []objectSize, err error) {
+	if s == "" {
+		return []objectSize{{size: defaultSize, weight: 1}}, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		sizeString, weight := part, 1
+		if i := strings.Index(part, ":"); i >= 0 {
+			sizeString = part[:i]
+			if weight, err = strconv.Atoi(part[i+1:]); err != nil || weight < 0 {
+				return nil, fmt.Errorf("invalid weight in %s", part)
+			}
+		}
+		size, err := parseObjectSize(sizeString)
+		if err != nil {
+			return nil, err
+		}
+		sizes = append(sizes, objectSize{size: size, weight: weight})
+	}
+	total := 0
+	for _, s := range sizes {
+		total += s.weight
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("no size has a positive weight")
+	}
+	return sizes, nil
+}
+
+func parseObjectSize(s string) (int64, error) {
+	if s == "" {
+		return 0, fmt.Errorf("empty size")
+	}
+	multiplier := int64(1)
+	switch strings.ToLower(s[len(s)-1:]) {
+	case "k":
+		multiplier = 1024
+	case "m":
+		multiplier = 1024 * 1024
+	case "g":
+		multiplier = 1024 * 1024 * 1024
+	}
+	if multiplier > 1 {
+		s = s[:len(s)-1]
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %s", s)
+	}
+	return n * multiplier, nil
+}
+
+func pickObjectSize(random *rand.Rand, sizes []objectSize) int64 {
+	total := 0
+	for _, s := range sizes {
+		total += s.weight
+	}
+	n := random.Intn(total)
+	for _, s := range sizes {
+		if n < s.weight {
+			return s.size + int64(random.Intn(objectSizeJitter))
+		}
+		n -= s.weight
+	}
+	return sizes[len(sizes)-1].size
+}
+
+// sliceObjectPayload slices the object content at a random offset, within the payload
+func sliceObjectPayload(payload []byte, random *rand.Rand, size int64) []byte {
+	if size > int64(len(payload)) {
+		size = int64(len(payload))
+	}
+	offset := int64(random.Intn(objectOffsetJitter))
+	if offset+size > int64(len(payload)) {
+		offset = int64(len(payload)) - size
+	}
+	return payload[offset : offset+size]
+}
+
+func benchWriteObjects(client objectClient, sizes []objectSize) {
+	keyLineChan := make(chan string)
+	finishChan := make(chan bool)
+	writeStats = newStats(*b.concurrency)
+	idChan := make(chan int)
+	go writeFileIds(*b.idListFile, keyLineChan, finishChan)
+	for i := 0; i < *b.concurrency; i++ {
+		wait.Add(1)
+		go writeObjects(client, sizes, idChan, keyLineChan, &writeStats.localStats[i])
+	}
+	writeStats.start = time.Now()
+	writeStats.total = *b.numberOfFiles
+	go writeStats.checkProgress("Writing Benchmark through "+*b.mode, finishChan)
+	for i := 0; i < *b.numberOfFiles; i++ {
+		idChan <- i
+	}
+	close(idChan)
+	wait.Wait()
+	writeStats.end = time.Now()
+	wait.Add(2)
+	finishChan <- true
+	finishChan <- true
+	wait.Wait()
+	close(finishChan)
+	writeStats.printStats()
+}
+
+func writeObjects(client objectClient, sizes []objectSize, idChan chan int, keyLineChan chan string, s *stat) {
+	defer wait.Done()
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for id := range idChan {
+		key := fmt.Sprintf("%08d", id)
+		if writeObject(client, random, sizes, key, writeStats, s) {
+			keyLineChan <- key
+		}
+	}
+}
+
+func writeObject(client objectClient, random *rand.Rand, sizes []objectSize, key string, st *stats, s *stat) bool {
+	size := pickObjectSize(random, sizes)
+	start := time.Now()
+	if err := client.put(key, sliceObjectPayload(objectPayload, random, size)); err != nil {
+		s.failed++
+		fmt.Printf("Failed to write %s: %v\n", key, err)
+		return false
+	}
+	s.completed++
+	s.transferred += size
+	objectStatsLock.Lock()
+	st.addSample(time.Since(start))
+	objectStatsLock.Unlock()
+	if *cmdBenchmark.IsDebug {
+		fmt.Printf("writing %s of %d bytes\n", key, size)
+	}
+	return true
+}
+
+func benchReadObjects(client objectClient) {
+	keyLineChan := make(chan string)
+	finishChan := make(chan bool)
+	readStats = newStats(*b.concurrency)
+	go readFileIds(*b.idListFile, keyLineChan)
+	readStats.start = time.Now()
+	readStats.total = *b.numberOfFiles
+	go readStats.checkProgress("Randomly Reading Benchmark through "+*b.mode, finishChan)
+	for i := 0; i < *b.concurrency; i++ {
+		wait.Add(1)
+		go readObjects(client, keyLineChan, &readStats.localStats[i])
+	}
+	wait.Wait()
+	wait.Add(1)
+	finishChan <- true
+	wait.Wait()
+	close(finishChan)
+	readStats.end = time.Now()
+	readStats.printStats()
+}
+
+func readObjects(client objectClient, keyLineChan chan string, s *stat) {
+	defer wait.Done()
+	for key := range keyLineChan {
+		if len(key) == 0 || key[0] == '#' {
+			continue
+		}
+		readObject(client, key, readStats, s)
+	}
+}
+
+func readObject(client objectClient, key string, st *stats, s *stat) {
+	if *cmdBenchmark.IsDebug {
+		fmt.Printf("reading %s\n", key)
+	}
+	start := time.Now()
+	n, err := client.get(key)
+	if err != nil {
+		s.failed++
+		fmt.Printf("Failed to read %s: %v\n", key, err)
+		return
+	}
+	s.completed++
+	s.transferred += n
+	objectStatsLock.Lock()
+	st.addSample(time.Since(start))
+	objectStatsLock.Unlock()
+}
+
+// benchMixedObjects reads the objects of the -list file and the ones written in this test,
+// mixed with the writes by -readPercent
+func benchMixedObjects(client objectClient, sizes []objectSize) {
+	var keysLock sync.Mutex
+	keys := readObjectKeys(*b.idListFile)
+	if len(keys) == 0 {
+		fmt.Printf("No objects in %s to read yet, the objects written first are read\n", *b.idListFile)
+	}
+
+	writeStats = newStats(*b.concurrency)
+	readStats = newStats(*b.concurrency)
+	mixedStats := newStats(*b.concurrency)
+	mixedStats.total = *b.numberOfFiles
+
+	opChan := make(chan int)
+	finishChan := make(chan bool)
+	mixedStats.start = time.Now()
+	writeStats.start, readStats.start = mixedStats.start, mixedStats.start
+	wait.Add(1)
+	go mixedStats.checkProgress(fmt.Sprintf("Mixed Benchmark through %s with %d%% reads", *b.mode, *b.readPercent), finishChan)
+
+	var workers sync.WaitGroup
+	for i := 0; i < *b.concurrency; i++ {
+		workers.Add(1)
+		go func(i int) {
+			defer workers.Done()
+			random := rand.New(rand.NewSource(time.Now().UnixNano() + int64(i)))
+			for id := range opChan {
+				keysLock.Lock()
+				keyCount := len(keys)
+				var key string
+				if keyCount > 0 {
+					key = keys[random.Intn(keyCount)]
+				}
+				keysLock.Unlock()
+
+				if keyCount > 0 && random.Intn(100) < *b.readPercent {
+					before := readStats.localStats[i]
+					readObject(client, key, readStats, &readStats.localStats[i])
+					addStatDelta(&mixedStats.localStats[i], before, readStats.localStats[i])
+					continue
+				}
+				key = fmt.Sprintf("mixed_%d_%08d", mixedStats.start.Unix(), id)
+				before := writeStats.localStats[i]
+				if writeObject(client, random, sizes, key, writeStats, &writeStats.localStats[i]) {
+					keysLock.Lock()
+					keys = append(keys, key)
+					keysLock.Unlock()
+				}
+				addStatDelta(&mixedStats.localStats[i], before, writeStats.localStats[i])
+			}
+		}(i)
+	}
+	for i := 0; i < *b.numberOfFiles; i++ {
+		opChan <- i
+	}
+	close(opChan)
+	workers.Wait()
+
+	mixedStats.end = time.Now()
+	writeStats.end, readStats.end = mixedStats.end, mixedStats.end
+	finishChan <- true
+	wait.Wait()
+	close(finishChan)
+
+	fmt.Printf("\n------------ writes ----------\n")
+	writeStats.printStats()
+	fmt.Printf("\n------------ reads ----------\n")
+	readStats.printStats()
+}
+
+func addStatDelta(total *stat, before, after stat) {
+	total.completed += after.completed - before.completed
+	total.failed += after.failed - before.failed
+	total.transferred += after.transferred - before.transferred
+}
+
+func readObjectKeys(fileName string) (keys []string) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+	r := bufio.NewReader(file)
+	for {
+		line, err := Readln(r)
+		if err != nil {
+			break
+		}
+		if len(line) > 0 && line[0] != '#' {
+			keys = append(keys, string(line))
+		}
+	}
+	return
+}
+
+type filerObjectClient struct {
+	urlPrefix string
+	client    *http.Client
+}
+
+func newFilerObjectClient(filer, dir string, concurrency int) *filerObjectClient {
+	return &filerObjectClient{
+		urlPrefix: fmt.Sprintf("http://%s/%s/", filer, strings.Trim(dir, "/")),
+		client: &http.Client{Transport: &http.Transport{
+			MaxIdleConnsPerHost: concurrency,
+		}},
+	}
+}
+
+func (c *filerObjectClient) put(key string, data []byte) error {
+	req, err := http.NewRequest("PUT", c.urlPrefix+key, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "image/bench") // prevent gzip benchmark content
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: %s", c.urlPrefix+key, resp.Status)
+	}
+	return nil
+}
+
+func (c *filerObjectClient) get(key string) (int64, error) {
+	resp, err := c.client.Get(c.urlPrefix + key)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	n, err := io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return n, fmt.Errorf("%s: %s", c.urlPrefix+key, resp.Status)
+	}
+	return n, err
+}
+
+type s3ObjectClient struct {
+	bucket string
+	conn   *s3.S3
+}
+
+func newS3ObjectClient(endpoint, bucket string) (*s3ObjectClient, error) {
+	config := &aws.Config{
+		Endpoint:         aws.String(endpoint),
+		Region:           aws.String("us-east-1"),
+		S3ForcePathStyle: aws.Bool(true),
+	}
+	if accessKey, secretKey := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); accessKey != "" && secretKey != "" {
+		config.Credentials = credentials.NewStaticCredentials(accessKey, secretKey, "")
+	} else {
+		config.Credentials = credentials.AnonymousCredentials
+	}
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, fmt.Errorf("create aws session: %v", err)
+	}
+	c := &s3ObjectClient{bucket: bucket, conn: s3.New(sess)}
+
+	if _, err = c.conn.CreateBucket(&s3.CreateBucketInput{Bucket: aws.String(bucket)}); err != nil {
+		if aerr, ok := err.(awserr.Error); !ok || (aerr.Code() != s3.ErrCodeBucketAlreadyOwnedByYou && aerr.Code() != s3.ErrCodeBucketAlreadyExists) {
+			return nil, fmt.Errorf("create bucket %s: %v", bucket, err)
+		}
+	}
+	glog.V(1).Infof("benchmark s3 bucket %s at %s", bucket, endpoint)
+	return c, nil
+}
+
+func (c *s3ObjectClient) put(key string, data []byte) error {
+	_, err := c.conn.PutObject(&s3.PutObjectInput{
+		Bucket:      aws.String(c.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("image/bench"),
+	})
+	return err
+}
+
+func (c *s3ObjectClient) get(key string) (int64, error) {
+	out, err := c.conn.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer out.Body.Close()
+	return io.Copy(ioutil.Discard, out.Body)
+}
@@ -0,0 +1,30 @@
+package command
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestObjectSizeAndPayload(t *testing.T) {
+	sizes := []objectSize{{size: 1024, weight: 3}, {size: 4096, weight: 1}}
+	payload := make([]byte, 4096+objectSizeJitter+objectOffsetJitter)
+	random := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 10000; i++ {
+		size := pickObjectSize(random, sizes)
+		if (size < 1024 || size >= 1024+objectSizeJitter) && (size < 4096 || size >= 4096+objectSizeJitter) {
+			t.Fatalf("picked size %d out of the distribution", size)
+		}
+		if data := sliceObjectPayload(payload, random, size); int64(len(data)) != size {
+			t.Fatalf("sliced %d bytes for size %d", len(data), size)
+		}
+	}
+
+	// the largest size with the largest offset still fits in a smaller payload
+	small := make([]byte, 4096)
+	for i := 0; i < 100; i++ {
+		if data := sliceObjectPayload(small, random, 4096+objectSizeJitter-1); len(data) != len(small) {
+			t.Fatalf("sliced %d bytes out of %d", len(data), len(small))
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package command
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/cache"
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/security"
+	"github.com/chrislusf/seaweedfs/weed/util"
+	"github.com/spf13/viper"
+)
+
+var (
+	cacheOptions CacheOptions
+)
+
+type CacheOptions struct {
+	filer           *string
+	port            *int
+	dir             *string
+	sizeMB          *int64
+	blockSizeMB     *int
+	readAheadBlocks *int
+	metaTtl         *time.Duration
+	tlsPrivateKey   *string
+	tlsCertificate  *string
+}
+
+func init() {
+	cmdCache.Run = runCache // break init cycle
+	cacheOptions.filer = cmdCache.Flag.String("filer", "localhost:8888", "filer server address")
+	cacheOptions.port = cmdCache.Flag.Int("port", 8900, "cache server http listen port")
+	cacheOptions.dir = cmdCache.Flag.String("dir", os.TempDir(), "local directory for the cached blocks, better on a fast disk")
+	cacheOptions.sizeMB = cmdCache.Flag.Int64("sizeMB", 10240, "the cached blocks are evicted least recently used first over this size")
+	cacheOptions.blockSizeMB = cmdCache.Flag.Int("blockSizeMB", 4, "the chunks are read from the volume servers and cached in blocks of this size")
+	cacheOptions.readAheadBlocks = cmdCache.Flag.Int("readAhead", 2, "number of the blocks after the ones read to fetch in the background")
+	cacheOptions.metaTtl = cmdCache.Flag.Duration("metaTtl", 10*time.Second, "how long the file metadata and the volume locations are cached")
+	cacheOptions.tlsPrivateKey = cmdCache.Flag.String("key.file", "", "path to the TLS private key file")
+	cacheOptions.tlsCertificate = cmdCache.Flag.String("cert.file", "", "path to the TLS certificate file")
+}
+
+var cmdCache = &Command{
+	UsageLine: "cache -port=8900 -filer=<ip:port> -dir=/nvme/cache -sizeMB=102400",
+	Short:     "<unstable> start a caching http server in front of the filer and the volume servers",
+	Long: `start a read only http server caching the hot blocks of the files on the local disk.
+
+	The files are read by the filer paths, or by the file ids as from the volume servers:
+
+	  curl -H "Range: bytes=0-1048575" http://localhost:8900/videos/a/segment_001.ts
+	  curl http://localhost:8900/3,01637037d6.jpg
+
+	The chunks are read from the volume servers in blocks of -blockSizeMB, and the blocks are cached
+	under -dir, so the range requests within the same blocks are read from the volume servers once,
+	and the concurrent requests of the same block wait for one read.
+	The next -readAhead blocks are fetched in the background, for the sequential reads like the video segments.
+
+	The chunks never change, so the cached blocks are never stale, but the changed files may be served
+	as before for up to -metaTtl.
+
+`,
+}
+
+func runCache(cmd *Command, args []string) bool {
+
+	util.LoadConfiguration("security", false)
+
+	filerGrpcAddress, err := parseFilerGrpcAddress(*cacheOptions.filer)
+	if err != nil {
+		glog.Fatal(err)
+		return false
+	}
+
+	cacheServer, err := cache.NewServer(&cache.Option{
+		FilerGrpcAddress: filerGrpcAddress,
+		GrpcDialOption:   security.LoadClientTLS(viper.Sub("grpc"), "client"),
+		CacheDir:         filepath.Join(*cacheOptions.dir, "cache_"+strings.Replace(filerGrpcAddress, ":", "_", -1)),
+		CacheCapacity:    *cacheOptions.sizeMB * 1024 * 1024,
+		BlockSize:        int64(*cacheOptions.blockSizeMB) * 1024 * 1024,
+		ReadAheadBlocks:  *cacheOptions.readAheadBlocks,
+		MetaTtl:          *cacheOptions.metaTtl,
+	})
+	if err != nil {
+		glog.Fatalf("Cache Server startup error: %v", err)
+	}
+
+	httpS := &http.Server{Handler: cacheServer}
+
+	listenAddress := fmt.Sprintf(":%d", *cacheOptions.port)
+	cacheListener, err := util.NewListener(listenAddress, time.Duration(10)*time.Second)
+	if err != nil {
+		glog.Fatalf("Cache Server listener on %s error: %v", listenAddress, err)
+	}
+
+	if *cacheOptions.tlsPrivateKey != "" {
+		glog.V(0).Infof("Start Seaweed Cache Server %s at https port %d", util.VERSION, *cacheOptions.port)
+		if err = httpS.ServeTLS(cacheListener, *cacheOptions.tlsCertificate, *cacheOptions.tlsPrivateKey); err != nil {
+			glog.Fatalf("Cache Server Fail to serve: %v", err)
+		}
+	} else {
+		glog.V(0).Infof("Start Seaweed Cache Server %s at http port %d", util.VERSION, *cacheOptions.port)
+		if err = httpS.Serve(cacheListener); err != nil {
+			glog.Fatalf("Cache Server Fail to serve: %v", err)
+		}
+	}
+
+	return true
+
+}
@@ -10,10 +10,14 @@ import (
 var Commands = []*Command{
 	cmdBenchmark,
 	cmdBackup,
+	cmdBackupCloud,
+	cmdRestoreCloud,
 	cmdCompact,
 	cmdCopy,
 	cmdFix,
+	cmdFilerBenchmark,
 	cmdFilerReplicate,
+	cmdFilerSearch,
 	cmdServer,
 	cmdMaster,
 	cmdFiler,
@@ -27,6 +31,11 @@ var Commands = []*Command{
 	cmdExport,
 	cmdMount,
 	cmdWebDav,
+	cmdSmb,
+	cmdNfs,
+	cmdFtp,
+	cmdKeyMap,
+	cmdCache,
 }
 
 type Command struct {
@@ -10,9 +10,12 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/operation"
 	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
 	"github.com/chrislusf/seaweedfs/weed/server"
+	"github.com/chrislusf/seaweedfs/weed/stats"
 	"github.com/chrislusf/seaweedfs/weed/util"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc/reflection"
 )
 
@@ -25,6 +28,7 @@ type FilerOptions struct {
 	ip                      *string
 	port                    *int
 	publicPort              *int
+	metricsPort             *int
 	collection              *string
 	defaultReplicaPlacement *string
 	redirectOnRead          *bool
@@ -34,6 +38,25 @@ type FilerOptions struct {
 	dataCenter              *string
 	enableNotification      *bool
 	disableHttp             *bool
+	volumeServers           *string
+	dedup                   *bool
+	concurrentRequests      *int
+	concurrentPerClient     *int
+	requestQueueSize        *int
+	clientIdHeader          *string
+	rateLimitRequests       *float64
+	rateLimitMBps           *float64
+	rateLimitPerClientReqs  *float64
+	rateLimitPerClientMBps  *float64
+	enforcePermissions      *bool
+	readFallback            *bool
+	ingestFlushInterval     *time.Duration
+	ingestMaxBufferMB       *int
+	imageCacheCollection    *string
+	imageCacheTtl           *string
+	volumeConcurrency       *int
+	volumeFailureThreshold  *int
+	clusterId               *string
 
 	// default leveldb directory, used in "weed server" mode
 	defaultLevelDbDirectory *string
@@ -43,16 +66,36 @@ func init() {
 	cmdFiler.Run = runFiler // break init cycle
 	f.masters = cmdFiler.Flag.String("master", "localhost:9333", "comma-separated master servers")
 	f.collection = cmdFiler.Flag.String("collection", "", "all data will be stored in this collection")
+	f.volumeServers = cmdFiler.Flag.String("volumeServers", "", "comma-separated volume servers to find the volumes for reads when the masters are unavailable")
 	f.ip = cmdFiler.Flag.String("ip", "", "filer server http listen ip address")
 	f.port = cmdFiler.Flag.Int("port", 8888, "filer server http listen port")
 	f.publicPort = cmdFiler.Flag.Int("port.readonly", 0, "readonly port opened to public")
-	f.defaultReplicaPlacement = cmdFiler.Flag.String("defaultReplicaPlacement", "000", "default replication type if not specified")
+	f.metricsPort = cmdFiler.Flag.Int("metricsPort", 0, "serve the prometheus metrics at /metrics on this port, apart from the files, not served if 0")
+	f.defaultReplicaPlacement = cmdFiler.Flag.String("defaultReplicaPlacement", "", "default replication type if not specified, empty to use the collection or the master default")
 	f.redirectOnRead = cmdFiler.Flag.Bool("redirectOnRead", false, "whether proxy or redirect to volume server during file GET request")
 	f.disableDirListing = cmdFiler.Flag.Bool("disableDirListing", false, "turn off directory listing")
 	f.maxMB = cmdFiler.Flag.Int("maxMB", 32, "split files larger than the limit")
 	f.dirListingLimit = cmdFiler.Flag.Int("dirListLimit", 100000, "limit sub dir listing size")
-	f.dataCenter = cmdFiler.Flag.String("dataCenter", "", "prefer to write to volumes in this data center")
+	f.dataCenter = cmdFiler.Flag.String("dataCenter", "", "prefer to write to and read from volumes in this data center")
 	f.disableHttp = cmdFiler.Flag.Bool("disableHttp", false, "disable http request, only gRpc operations are allowed")
+	f.dedup = cmdFiler.Flag.Bool("dedup", false, "reuse the existing chunks with the same content for the http uploads")
+	f.concurrentRequests = cmdFiler.Flag.Int("concurrentRequests", 0, "limit the http requests served at the same time, 0 for no limit")
+	f.concurrentPerClient = cmdFiler.Flag.Int("concurrentRequestsPerClient", 0, "limit the http requests served at the same time for each client, 0 for no limit")
+	f.requestQueueSize = cmdFiler.Flag.Int("requestQueueSize", 1024, "the http requests waiting for the concurrency limits, beyond which the requests are rejected")
+	f.clientIdHeader = cmdFiler.Flag.String("clientIdHeader", "", "the header set by a trusted proxy to identify the clients for the concurrency limits, instead of the remote ip")
+	f.rateLimitRequests = cmdFiler.Flag.Float64("rateLimit.requestsPerSecond", 0, "limit the http requests per second in total, rejecting the others with 429, 0 for no limit")
+	f.rateLimitMBps = cmdFiler.Flag.Float64("rateLimit.MBps", 0, "limit the http request and response bodies in total to mega bytes per second, 0 for no limit")
+	f.rateLimitPerClientReqs = cmdFiler.Flag.Float64("rateLimit.perClient.requestsPerSecond", 0, "limit the http requests per second of each client, rejecting the others with 429, 0 for no limit")
+	f.rateLimitPerClientMBps = cmdFiler.Flag.Float64("rateLimit.perClient.MBps", 0, "limit the http request and response bodies of each client to mega bytes per second, 0 for no limit")
+	f.enforcePermissions = cmdFiler.Flag.Bool("enforcePermissions", false, "check the caller uid and gids against the owner, group and mode of the entries, trusting the callers configured in security.toml")
+	f.readFallback = cmdFiler.Flag.Bool("readFallback", true, "read from the other volume replicas, or the ec shards, when the volume server read first fails")
+	f.ingestFlushInterval = cmdFiler.Flag.Duration("ingest.flushInterval", time.Second, "append the records ingested with op=ingest to their files on this interval")
+	f.ingestMaxBufferMB = cmdFiler.Flag.Int("ingest.maxBufferMB", 256, "the ingested records waiting for the flush, beyond which the ingestion requests are rejected")
+	f.imageCacheCollection = cmdFiler.Flag.String("image.cacheCollection", "image_cache", "cache the images transformed by ?width=&height=&fit=&format= in this collection, empty to not cache")
+	f.imageCacheTtl = cmdFiler.Flag.String("image.cacheTtl", "", "the time to keep the cached transformed images, e.g. 7d, forever if empty")
+	f.volumeConcurrency = cmdFiler.Flag.Int("volumeClient.concurrentPerServer", 64, "limit the http and grpc requests in flight to each volume server, sharing their connections, 0 for no limit")
+	f.volumeFailureThreshold = cmdFiler.Flag.Int("volumeClient.failureThreshold", 5, "fail fast the requests to a volume server for 10 seconds after this many failures in a row, 0 to never fail fast")
+	f.clusterId = cmdFiler.Flag.String("clusterId", "", "stamp the entries written on this filer with the cluster id, to resolve the conflicts when replicating both ways with another cluster by filer.replicate")
 }
 
 var cmdFiler = &Command{
@@ -68,6 +111,8 @@ var cmdFiler = &Command{
 	POST /path/to/
 	//return a json format subdirectory and files listing
 	GET /path/to/
+	//get the image resized and converted, cached in the -image.cacheCollection
+	GET /path/to/image.jpg?width=200&height=200&fit=crop&format=webp
 
 	The configuration file "filer.toml" is read from ".", "$HOME/.seaweedfs/", or "/etc/seaweedfs/", in that order.
 
@@ -99,6 +144,17 @@ func (fo *FilerOptions) startFiler() {
 		defaultLevelDbDirectory = *fo.defaultLevelDbDirectory + "/filerldb2"
 	}
 
+	var volumeServers []string
+	if *fo.volumeServers != "" {
+		volumeServers = strings.Split(*fo.volumeServers, ",")
+	}
+
+	operation.ConfigureClientPool(operation.ClientPoolOption{
+		MaxConcurrentPerServer: *fo.volumeConcurrency,
+		FailureThreshold:       *fo.volumeFailureThreshold,
+		OpenDuration:           10 * time.Second,
+	})
+
 	fs, nfs_err := weed_server.NewFilerServer(defaultMux, publicVolumeMux, &weed_server.FilerOption{
 		Masters:            strings.Split(*fo.masters, ","),
 		Collection:         *fo.collection,
@@ -111,6 +167,22 @@ func (fo *FilerOptions) startFiler() {
 		DefaultLevelDbDir:  defaultLevelDbDirectory,
 		DisableHttp:        *fo.disableHttp,
 		Port:               *fo.port,
+		VolumeServers:      volumeServers,
+		Dedup:              *fo.dedup,
+
+		ConcurrentRequests:          *fo.concurrentRequests,
+		ConcurrentRequestsPerClient: *fo.concurrentPerClient,
+		RequestQueueSize:            *fo.requestQueueSize,
+		ClientIdHeader:              *fo.clientIdHeader,
+		RateLimit:                   util.RateLimit{RequestsPerSecond: *fo.rateLimitRequests, BytesPerSecond: *fo.rateLimitMBps * 1024 * 1024},
+		RateLimitPerClient:          util.RateLimit{RequestsPerSecond: *fo.rateLimitPerClientReqs, BytesPerSecond: *fo.rateLimitPerClientMBps * 1024 * 1024},
+		EnforcePermissions:          *fo.enforcePermissions,
+		ReadFallback:                *fo.readFallback,
+		IngestFlushInterval:         *fo.ingestFlushInterval,
+		IngestMaxBufferMB:           *fo.ingestMaxBufferMB,
+		ImageCacheCollection:        *fo.imageCacheCollection,
+		ImageCacheTtl:               *fo.imageCacheTtl,
+		ClusterId:                   *fo.clusterId,
 	})
 	if nfs_err != nil {
 		glog.Fatalf("Filer startup error: %v", nfs_err)
@@ -130,6 +202,22 @@ func (fo *FilerOptions) startFiler() {
 		}()
 	}
 
+	if *fo.metricsPort != 0 {
+		metricsListeningAddress := *fo.ip + ":" + strconv.Itoa(*fo.metricsPort)
+		glog.V(0).Infoln("Start Seaweed filer server", util.VERSION, "metrics at", metricsListeningAddress)
+		metricsListener, e := util.NewListener(metricsListeningAddress, 0)
+		if e != nil {
+			glog.Fatalf("Filer server metrics listener error on port %d:%v", *fo.metricsPort, e)
+		}
+		metricsMux := http.NewServeMux()
+		metricsMux.Handle("/metrics", promhttp.HandlerFor(stats.FilerGather, promhttp.HandlerOpts{}))
+		go func() {
+			if e := http.Serve(metricsListener, metricsMux); e != nil {
+				glog.Fatalf("Filer server fail to serve metrics: %v", e)
+			}
+		}()
+	}
+
 	glog.V(0).Infof("Start Seaweed Filer %s at %s:%d", util.VERSION, *fo.ip, *fo.port)
 	filerListener, e := util.NewListener(
 		*fo.ip+":"+strconv.Itoa(*fo.port),
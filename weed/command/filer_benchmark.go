@@ -0,0 +1,386 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+	"github.com/chrislusf/seaweedfs/weed/util"
+	"github.com/spf13/viper"
+)
+
+var (
+	filerBenchmarkOptions FilerBenchmarkOptions
+)
+
+type FilerBenchmarkOptions struct {
+	store        *string
+	concurrency  *int
+	numberOfOps  *int
+	dir          *string
+	dirCount     *int
+	mix          *string
+	listLimit    *int
+	preloadCount *int
+	cleanup      *bool
+}
+
+func init() {
+	cmdFilerBenchmark.Run = runFilerBenchmark // break init cycle
+	filerBenchmarkOptions.store = cmdFilerBenchmark.Flag.String("store", "", "the filer store to benchmark, configured in filer.toml even if not enabled. Empty for the enabled one")
+	filerBenchmarkOptions.concurrency = cmdFilerBenchmark.Flag.Int("c", 16, "number of concurrent workers")
+	filerBenchmarkOptions.numberOfOps = cmdFilerBenchmark.Flag.Int("n", 10000, "number of operations of each worker")
+	filerBenchmarkOptions.dir = cmdFilerBenchmark.Flag.String("dir", "/benchmark", "the directory of the entries created by the benchmark")
+	filerBenchmarkOptions.dirCount = cmdFilerBenchmark.Flag.Int("dirs", 100, "the number of sub directories to spread the entries into")
+	filerBenchmarkOptions.mix = cmdFilerBenchmark.Flag.String("mix", "create=40,lookup=40,list=10,delete=10", "the weights of the operations: create, lookup, list, delete")
+	filerBenchmarkOptions.listLimit = cmdFilerBenchmark.Flag.Int("listLimit", 100, "the number of entries of each list operation")
+	filerBenchmarkOptions.preloadCount = cmdFilerBenchmark.Flag.Int("preload", 0, "the number of entries of each worker to create before the measured operations")
+	filerBenchmarkOptions.cleanup = cmdFilerBenchmark.Flag.Bool("cleanup", true, "delete the remaining entries created by the benchmark when done")
+}
+
+var cmdFilerBenchmark = &Command{
+	UsageLine: "filer.benchmark [-store=leveldb2] -c=16 -n=10000 -mix=create=40,lookup=40,list=10,delete=10",
+	Short:     "benchmark a filer store directly with a mix of metadata operations",
+	Long: `benchmark a filer store directly with a mix of metadata operations
+
+	filer.benchmark opens the filer store configured in filer.toml, without running a filer, and runs
+	the weighted mix of creating, looking up, listing, and deleting the entries from concurrent workers.
+
+	To compare the stores before choosing one, configure all of them in filer.toml, and run with
+	-store=leveldb2, -store=mysql, -store=redis, etc. The store configured with "enabled = true"
+	is used by default.
+
+	The entries are created under -dir, spread into -dirs sub directories. Each worker looks up and
+	deletes only its own entries, and lists any of the sub directories. Use -preload to start with
+	some entries in the store.
+
+	The latency percentiles are printed for each operation.
+
+	Do not run it against the store of a running filer, unless the store supports multiple clients,
+	and -dir is not used by anything else.
+
+`,
+}
+
+const (
+	filerBenchmarkCreate = "create"
+	filerBenchmarkLookup = "lookup"
+	filerBenchmarkList   = "list"
+	filerBenchmarkDelete = "delete"
+)
+
+var filerBenchmarkOperations = []string{filerBenchmarkCreate, filerBenchmarkLookup, filerBenchmarkList, filerBenchmarkDelete}
+
+type filerBenchmark struct {
+	store     filer2.FilerStore
+	dirs      []filer2.FullPath
+	listLimit int
+	weights   []int
+	// the latency stats of each operation, guarded by statsLock
+	statsLock  sync.Mutex
+	opStats    map[string]*stats
+	totalStats *stats
+}
+
+type filerBenchmarkWorker struct {
+	id      int
+	random  *rand.Rand
+	created []filer2.FullPath
+	nextId  int
+}
+
+func runFilerBenchmark(cmd *Command, args []string) bool {
+
+	weights, err := parseFilerBenchmarkMix(*filerBenchmarkOptions.mix)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "-mix: %v\n", err)
+		return false
+	}
+
+	util.LoadConfiguration("filer", true)
+	store, err := loadFilerBenchmarkStore(viper.GetViper(), *filerBenchmarkOptions.store)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return false
+	}
+	fmt.Printf("Benchmarking filer store %s\n", store.GetName())
+
+	concurrency := *filerBenchmarkOptions.concurrency
+	fb := &filerBenchmark{
+		store:      store,
+		listLimit:  *filerBenchmarkOptions.listLimit,
+		weights:    weights,
+		opStats:    make(map[string]*stats),
+		totalStats: newStats(concurrency),
+	}
+	for _, op := range filerBenchmarkOperations {
+		fb.opStats[op] = newStats(concurrency)
+	}
+
+	ctx := context.Background()
+	baseDir := filer2.FullPath(strings.TrimSuffix(*filerBenchmarkOptions.dir, "/"))
+	if baseDir == "" {
+		baseDir = "/"
+	}
+	if err = fb.createDirectory(ctx, baseDir); err != nil {
+		fmt.Fprintf(os.Stderr, "create %s: %v\n", baseDir, err)
+		return false
+	}
+	for i := 0; i < *filerBenchmarkOptions.dirCount; i++ {
+		dir := baseDir.Child(fmt.Sprintf("d%04d", i))
+		if err = fb.createDirectory(ctx, dir); err != nil {
+			fmt.Fprintf(os.Stderr, "create %s: %v\n", dir, err)
+			return false
+		}
+		fb.dirs = append(fb.dirs, dir)
+	}
+	if len(fb.dirs) == 0 {
+		fb.dirs = append(fb.dirs, baseDir)
+	}
+
+	workers := make([]*filerBenchmarkWorker, concurrency)
+	for i := range workers {
+		workers[i] = &filerBenchmarkWorker{id: i, random: rand.New(rand.NewSource(time.Now().UnixNano() + int64(i)))}
+	}
+
+	if *filerBenchmarkOptions.preloadCount > 0 {
+		fmt.Printf("Preloading %d entries\n", *filerBenchmarkOptions.preloadCount*concurrency)
+		fb.runWorkers(workers, func(w *filerBenchmarkWorker) {
+			for i := 0; i < *filerBenchmarkOptions.preloadCount; i++ {
+				if _, err := fb.create(ctx, w); err != nil {
+					glog.V(0).Infof("preload: %v", err)
+				}
+			}
+		})
+	}
+
+	finishChan := make(chan bool)
+	wait.Add(1)
+	go fb.totalStats.checkProgress("Filer Store "+store.GetName(), finishChan)
+	fb.totalStats.total = concurrency * *filerBenchmarkOptions.numberOfOps
+	fb.totalStats.start = time.Now()
+	for _, s := range fb.opStats {
+		s.start = fb.totalStats.start
+	}
+	fb.runWorkers(workers, func(w *filerBenchmarkWorker) {
+		for i := 0; i < *filerBenchmarkOptions.numberOfOps; i++ {
+			fb.runOperation(ctx, w)
+		}
+	})
+	fb.totalStats.end = time.Now()
+	finishChan <- true
+	wait.Wait()
+
+	for _, op := range filerBenchmarkOperations {
+		s := fb.opStats[op]
+		if s.countCompleted() == 0 {
+			continue
+		}
+		s.end = fb.totalStats.end
+		fmt.Printf("\n------------ %s ----------\n", op)
+		s.printStats()
+	}
+	fmt.Printf("\n------------ all operations ----------\n")
+	fb.totalStats.printStats()
+
+	if *filerBenchmarkOptions.cleanup {
+		fb.runWorkers(workers, func(w *filerBenchmarkWorker) {
+			for _, fullpath := range w.created {
+				if err := store.DeleteEntry(ctx, fullpath); err != nil {
+					glog.V(0).Infof("cleanup %s: %v", fullpath, err)
+				}
+			}
+		})
+		for _, dir := range fb.dirs {
+			store.DeleteEntry(ctx, dir)
+		}
+		if baseDir != "/" {
+			store.DeleteEntry(ctx, baseDir)
+		}
+	}
+
+	return true
+}
+
+// loadFilerBenchmarkStore initializes the named store, or the enabled store if no name is given
+func loadFilerBenchmarkStore(config *viper.Viper, name string) (filer2.FilerStore, error) {
+	if name == "" {
+		return filer2.LoadStore(config), nil
+	}
+	for _, store := range filer2.Stores {
+		if store.GetName() != name {
+			continue
+		}
+		storeConfig := config.Sub(name)
+		if storeConfig == nil {
+			return nil, fmt.Errorf("filer store %s is not configured in filer.toml", name)
+		}
+		if err := store.Initialize(storeConfig); err != nil {
+			return nil, fmt.Errorf("initialize filer store %s: %v", name, err)
+		}
+		return store, nil
+	}
+	var names []string
+	for _, store := range filer2.Stores {
+		names = append(names, store.GetName())
+	}
+	return nil, fmt.Errorf("unknown filer store %s, supported filer stores are: %s", name, strings.Join(names, ", "))
+}
+
+// parseFilerBenchmarkMix parses the weights like "create=40,lookup=40,list=10,delete=10", in the order of filerBenchmarkOperations
+func parseFilerBenchmarkMix(mix string) ([]int, error) {
+	weights := make([]int, len(filerBenchmarkOperations))
+	total := 0
+	for _, part := range strings.Split(mix, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("expecting operation=weight: %s", part)
+		}
+		weight, err := strconv.Atoi(kv[1])
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("invalid weight of %s: %s", kv[0], kv[1])
+		}
+		found := false
+		for i, op := range filerBenchmarkOperations {
+			if op == kv[0] {
+				weights[i], found = weight, true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown operation %s, expecting one of %v", kv[0], filerBenchmarkOperations)
+		}
+		total += weight
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("no operation has a positive weight")
+	}
+	return weights, nil
+}
+
+func (fb *filerBenchmark) runWorkers(workers []*filerBenchmarkWorker, fn func(w *filerBenchmarkWorker)) {
+	var wg sync.WaitGroup
+	for _, w := range workers {
+		wg.Add(1)
+		go func(w *filerBenchmarkWorker) {
+			defer wg.Done()
+			fn(w)
+		}(w)
+	}
+	wg.Wait()
+}
+
+func (fb *filerBenchmark) pickOperation(w *filerBenchmarkWorker) string {
+	total := 0
+	for _, weight := range fb.weights {
+		total += weight
+	}
+	n := w.random.Intn(total)
+	for i, weight := range fb.weights {
+		if n < weight {
+			return filerBenchmarkOperations[i]
+		}
+		n -= weight
+	}
+	return filerBenchmarkOperations[len(filerBenchmarkOperations)-1]
+}
+
+func (fb *filerBenchmark) runOperation(ctx context.Context, w *filerBenchmarkWorker) {
+	op := fb.pickOperation(w)
+	if (op == filerBenchmarkLookup || op == filerBenchmarkDelete) && len(w.created) == 0 {
+		// nothing to look up or delete yet
+		op = filerBenchmarkCreate
+	}
+
+	start := time.Now()
+	var err error
+	switch op {
+	case filerBenchmarkCreate:
+		_, err = fb.create(ctx, w)
+	case filerBenchmarkLookup:
+		fullpath := w.created[w.random.Intn(len(w.created))]
+		_, err = fb.store.FindEntry(ctx, fullpath)
+	case filerBenchmarkList:
+		dir := fb.dirs[w.random.Intn(len(fb.dirs))]
+		_, err = fb.store.ListDirectoryEntries(ctx, dir, "", false, fb.listLimit)
+	case filerBenchmarkDelete:
+		i := w.random.Intn(len(w.created))
+		fullpath := w.created[i]
+		if err = fb.store.DeleteEntry(ctx, fullpath); err == nil {
+			w.created[i] = w.created[len(w.created)-1]
+			w.created = w.created[:len(w.created)-1]
+		}
+	}
+	fb.record(op, w.id, time.Since(start), err)
+}
+
+func (fb *filerBenchmark) create(ctx context.Context, w *filerBenchmarkWorker) (filer2.FullPath, error) {
+	dir := fb.dirs[w.random.Intn(len(fb.dirs))]
+	fullpath := dir.Child(fmt.Sprintf("w%03d_%09d", w.id, w.nextId))
+	w.nextId++
+	now := time.Now()
+	entry := &filer2.Entry{
+		FullPath: fullpath,
+		Attr: filer2.Attr{
+			Mtime:  now,
+			Crtime: now,
+			Mode:   0644,
+			Mime:   "application/octet-stream",
+		},
+		Chunks: []*filer_pb.FileChunk{{
+			FileId: fmt.Sprintf("%d,%x%08x", w.id+1, w.nextId, w.random.Uint32()),
+			Size:   uint64(1024 + w.random.Intn(64)),
+			Mtime:  now.UnixNano(),
+		}},
+	}
+	if err := fb.store.InsertEntry(ctx, entry); err != nil {
+		return fullpath, err
+	}
+	w.created = append(w.created, fullpath)
+	return fullpath, nil
+}
+
+func (fb *filerBenchmark) createDirectory(ctx context.Context, dir filer2.FullPath) error {
+	if dir == "/" || dir == "" {
+		return nil
+	}
+	if _, err := fb.store.FindEntry(ctx, dir); err == nil {
+		return nil
+	}
+	now := time.Now()
+	return fb.store.InsertEntry(ctx, &filer2.Entry{
+		FullPath: dir,
+		Attr: filer2.Attr{
+			Mtime:  now,
+			Crtime: now,
+			Mode:   os.ModeDir | 0755,
+		},
+	})
+}
+
+func (fb *filerBenchmark) record(op string, workerId int, d time.Duration, err error) {
+	fb.statsLock.Lock()
+	defer fb.statsLock.Unlock()
+	for _, s := range []*stats{fb.opStats[op], fb.totalStats} {
+		if err != nil {
+			s.localStats[workerId].failed++
+			continue
+		}
+		s.localStats[workerId].completed++
+		s.addSample(d)
+	}
+	if err != nil {
+		glog.V(1).Infof("%s: %v", op, err)
+	}
+}
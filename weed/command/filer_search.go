@@ -0,0 +1,105 @@
+package command
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/search"
+	"github.com/chrislusf/seaweedfs/weed/security"
+	"github.com/chrislusf/seaweedfs/weed/util"
+	"github.com/spf13/viper"
+)
+
+var (
+	filerSearchOptions FilerSearchOptions
+)
+
+type FilerSearchOptions struct {
+	filer         *string
+	path          *string
+	elastic       *string
+	index         *string
+	crawl         *bool
+	batchSize     *int
+	flushInterval *time.Duration
+	port          *int
+}
+
+func init() {
+	cmdFilerSearch.Run = runFilerSearch // break init cycle
+	filerSearchOptions.filer = cmdFilerSearch.Flag.String("filer", "localhost:8888", "filer server address")
+	filerSearchOptions.path = cmdFilerSearch.Flag.String("path", "/", "the directory on the filer to index")
+	filerSearchOptions.elastic = cmdFilerSearch.Flag.String("es", "http://localhost:9200", "comma separated elasticsearch servers")
+	filerSearchOptions.index = cmdFilerSearch.Flag.String("index", "seaweedfs", "the elasticsearch index of the entries")
+	filerSearchOptions.crawl = cmdFilerSearch.Flag.Bool("crawl", true, "index all the existing entries when subscribed to the filer")
+	filerSearchOptions.batchSize = cmdFilerSearch.Flag.Int("batchSize", 500, "the number of changes indexed in one bulk request")
+	filerSearchOptions.flushInterval = cmdFilerSearch.Flag.Duration("flushInterval", time.Second, "the longest delay before the changes are indexed")
+	filerSearchOptions.port = cmdFilerSearch.Flag.Int("port", 8890, "the port of the http search endpoint, 0 to disable")
+}
+
+var cmdFilerSearch = &Command{
+	UsageLine: "filer.search -filer=<ip:port> -es=http://localhost:9200 -index=seaweedfs",
+	Short:     "<unstable> mirror the filer entries into elasticsearch for searching",
+	Long: `mirror the filer entries into elasticsearch for searching
+
+	filer.search subscribes to the metadata changes of the filer, and indexes the path, size, mime type,
+	times, owner and the text extended attributes of each entry into elasticsearch 7.
+
+	The entries are searched by "fs.search" in "weed shell", or by the http endpoint:
+
+	  curl "http://localhost:8890/search?q=report&path=/docs&mime=application/pdf&minSize=1024&attr=project=apollo"
+
+	The other parameters are maxSize, files=true for the files only, from and limit for paging, and pretty.
+
+	The changes are missed while not subscribed, so the existing entries are indexed again when re-subscribed,
+	unless -crawl=false. The entries deleted while not subscribed stay in the index, until the index is rebuilt.
+
+	The elasticsearch credentials are read from the environment variables ES_USERNAME and ES_PASSWORD.
+
+`,
+}
+
+func runFilerSearch(cmd *Command, args []string) bool {
+
+	util.LoadConfiguration("security", false)
+
+	filerGrpcAddress, err := parseFilerGrpcAddress(*filerSearchOptions.filer)
+	if err != nil {
+		glog.Fatal(err)
+		return false
+	}
+	grpcDialOption := security.LoadClientTLS(viper.Sub("grpc"), "client")
+
+	elastic := search.NewElasticClient(strings.Split(*filerSearchOptions.elastic, ","), *filerSearchOptions.index,
+		os.Getenv("ES_USERNAME"), os.Getenv("ES_PASSWORD"))
+
+	if *filerSearchOptions.port > 0 {
+		http.HandleFunc("/search", search.SearchHandler(elastic))
+		listenAddress := fmt.Sprintf(":%d", *filerSearchOptions.port)
+		go func() {
+			glog.V(0).Infof("Start Seaweed filer search endpoint %s at port %d", util.VERSION, *filerSearchOptions.port)
+			if err := http.ListenAndServe(listenAddress, nil); err != nil {
+				glog.Fatalf("filer search endpoint on %s: %v", listenAddress, err)
+			}
+		}()
+	}
+
+	syncer := search.NewSyncer(&search.SyncOption{
+		FilerGrpcAddress: filerGrpcAddress,
+		GrpcDialOption:   grpcDialOption,
+		Path:             *filerSearchOptions.path,
+		Crawl:            *filerSearchOptions.crawl,
+		BatchSize:        *filerSearchOptions.batchSize,
+		FlushInterval:    *filerSearchOptions.flushInterval,
+	}, elastic)
+
+	if err = syncer.Run(); err != nil {
+		glog.Fatalf("filer search sync: %v", err)
+	}
+
+	return true
+}
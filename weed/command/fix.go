@@ -1,13 +1,23 @@
 package command
 
 import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
 	"os"
 	"path"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/chrislusf/seaweedfs/weed/glog"
 	"github.com/chrislusf/seaweedfs/weed/storage"
+	"github.com/chrislusf/seaweedfs/weed/storage/idx"
 	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle_map"
 	"github.com/chrislusf/seaweedfs/weed/storage/types"
 )
 
@@ -20,22 +30,103 @@ var cmdFix = &Command{
 	Short:     "run weed tool fix on index file if corrupted",
 	Long: `Fix runs the SeaweedFS fix command to re-create the index .idx file.
 
+  The index is rebuilt from the needles in the .dat file, and replaces the existing .idx file.
+  With -all, all the volumes in the dir, or only the ones of the collection, are fixed in parallel.
+
+  The anomalies found are reported for each volume: the duplicated keys in the .dat file,
+  the unreadable data at its end, and the entries of the existing .idx file
+  overlapping each other or beyond the end of the .dat file.
+
+  If the .dat file is unreadable from some offset, the needles before it are indexed again,
+  and the entries of the existing .idx file from that offset on are kept as they are.
+
+  With -dryRun, the volumes are only checked, reporting how the .idx files would change.
+
   `,
 }
 
 var (
 	fixVolumePath       = cmdFix.Flag.String("dir", ".", "data directory to store files")
 	fixVolumeCollection = cmdFix.Flag.String("collection", "", "the volume collection name")
-	fixVolumeId         = cmdFix.Flag.Int("volumeId", -1, "a volume id. The volume should already exist in the dir. The volume index file should not exist.")
+	fixVolumeId         = cmdFix.Flag.Int("volumeId", -1, "a volume id. The volume should already exist in the dir.")
+	fixAllVolumes       = cmdFix.Flag.Bool("all", false, "fix all the volumes in the dir, or the ones of the collection if specified")
+	fixConcurrency      = cmdFix.Flag.Int("concurrency", 4, "the number of volumes fixed at the same time")
+	fixDryRun           = cmdFix.Flag.Bool("dryRun", false, "only report the changes to the index files, without writing them")
+	fixProgressInterval = cmdFix.Flag.Duration("progressInterval", 10*time.Second, "how often to report the progress")
 )
 
+type fixVolume struct {
+	collection string
+	id         needle.VolumeId
+	datSize    int64
+}
+
+func (fv fixVolume) baseFileName() string {
+	if fv.collection == "" {
+		return strconv.Itoa(int(fv.id))
+	}
+	return fv.collection + "_" + strconv.Itoa(int(fv.id))
+}
+
+// fixIndexEntry is one entry of the index file, with types.TombstoneFileSize for the deletions
+type fixIndexEntry struct {
+	key    types.NeedleId
+	offset types.Offset
+	size   uint32
+}
+
+// fixReport is what is found, and what would change, in one volume
+type fixReport struct {
+	needles       int
+	deletions     int
+	duplicateKeys int
+	// the data after the scan error is not scanned, and the existing index entries of it are kept
+	scanError error
+	dataEnd   int64
+	kept      int
+
+	// compared with the existing index file
+	hasIndex    bool
+	added       int
+	removed     int
+	changed     int
+	overlapping int
+	beyondEnd   int
+}
+
+func (r *fixReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d needles, %d deletions, %d duplicated keys", r.needles, r.deletions, r.duplicateKeys)
+	if r.scanError != nil {
+		fmt.Fprintf(&b, ", unreadable after offset %d: %v", r.dataEnd, r.scanError)
+		if r.hasIndex {
+			fmt.Fprintf(&b, ", %d index entries kept after it", r.kept)
+		}
+	}
+	if !r.hasIndex {
+		b.WriteString("; no existing index")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "; index entries %d added, %d removed, %d changed", r.added, r.removed, r.changed)
+	if r.overlapping > 0 || r.beyondEnd > 0 {
+		fmt.Fprintf(&b, ", %d overlapping, %d beyond the data end", r.overlapping, r.beyondEnd)
+	}
+	return b.String()
+}
+
 type VolumeFileScanner4Fix struct {
 	version needle.Version
-	nm      *storage.NeedleMap
+	// the index entries in the order of the needles, including the deletions
+	entries []fixIndexEntry
+	live    map[types.NeedleId]fixIndexEntry
+	report  *fixReport
+	// the bytes scanned by all the volumes
+	scanned *int64
 }
 
 func (scanner *VolumeFileScanner4Fix) VisitSuperBlock(superBlock storage.SuperBlock) error {
 	scanner.version = superBlock.Version()
+	scanner.report.dataEnd = int64(superBlock.BlockSize())
 	return nil
 
 }
@@ -45,46 +136,290 @@ func (scanner *VolumeFileScanner4Fix) ReadNeedleBody() bool {
 
 func (scanner *VolumeFileScanner4Fix) VisitNeedle(n *needle.Needle, offset int64) error {
 	glog.V(2).Infof("key %d offset %d size %d disk_size %d gzip %v", n.Id, offset, n.Size, n.DiskSize(scanner.version), n.IsGzipped())
+	diskSize := n.DiskSize(scanner.version)
+	scanner.report.dataEnd = offset + diskSize
+	atomic.AddInt64(scanner.scanned, diskSize)
+
+	entry := fixIndexEntry{key: n.Id, offset: types.ToOffset(offset), size: n.Size}
 	if n.Size > 0 && n.Size != types.TombstoneFileSize {
-		pe := scanner.nm.Put(n.Id, types.ToOffset(offset), n.Size)
-		glog.V(2).Infof("saved %d with error %v", n.Size, pe)
+		scanner.report.needles++
+		if _, found := scanner.live[n.Id]; found {
+			scanner.report.duplicateKeys++
+		}
+		scanner.live[n.Id] = entry
 	} else {
 		glog.V(2).Infof("skipping deleted file ...")
-		return scanner.nm.Delete(n.Id, types.ToOffset(offset))
+		scanner.report.deletions++
+		entry.size = types.TombstoneFileSize
+		delete(scanner.live, n.Id)
 	}
+	scanner.entries = append(scanner.entries, entry)
 	return nil
 }
 
 func runFix(cmd *Command, args []string) bool {
 
-	if *fixVolumeId == -1 {
+	var volumes []fixVolume
+	if *fixAllVolumes {
+		found, err := findFixVolumes(*fixVolumePath, *fixVolumeCollection)
+		if err != nil {
+			glog.Fatalf("find volumes in %s: %v", *fixVolumePath, err)
+		}
+		volumes = found
+	} else if *fixVolumeId != -1 {
+		fv := fixVolume{collection: *fixVolumeCollection, id: needle.VolumeId(*fixVolumeId)}
+		if stat, err := os.Stat(path.Join(*fixVolumePath, fv.baseFileName()+".dat")); err == nil {
+			fv.datSize = stat.Size()
+		}
+		volumes = append(volumes, fv)
+	} else {
 		return false
 	}
 
-	baseFileName := strconv.Itoa(*fixVolumeId)
-	if *fixVolumeCollection != "" {
-		baseFileName = *fixVolumeCollection + "_" + baseFileName
+	var totalSize, scanned, fixedCount, failedCount int64
+	for _, fv := range volumes {
+		totalSize += fv.datSize
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		if *fixProgressInterval <= 0 {
+			return
+		}
+		ticker := time.NewTicker(*fixProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				fmt.Printf("progress: %d/%d volumes, %d/%d MB scanned\n",
+					atomic.LoadInt64(&fixedCount)+atomic.LoadInt64(&failedCount), len(volumes),
+					atomic.LoadInt64(&scanned)/1024/1024, totalSize/1024/1024)
+			}
+		}
+	}()
+
+	concurrency := *fixConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, fv := range volumes {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(fv fixVolume) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			report, err := fixOneVolume(*fixVolumePath, fv, *fixDryRun, &scanned)
+			if err != nil {
+				atomic.AddInt64(&failedCount, 1)
+				fmt.Printf("volume %s: %v\n", fv.baseFileName(), err)
+				return
+			}
+			atomic.AddInt64(&fixedCount, 1)
+			fmt.Printf("volume %s: %v\n", fv.baseFileName(), report)
+		}(fv)
+	}
+	wg.Wait()
+
+	if *fixDryRun {
+		fmt.Printf("checked %d volumes, %d failed, no index file is changed\n", fixedCount, failedCount)
+	} else {
+		fmt.Printf("fixed %d volumes, %d failed\n", fixedCount, failedCount)
 	}
-	indexFileName := path.Join(*fixVolumePath, baseFileName+".idx")
-	indexFile, err := os.OpenFile(indexFileName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+
+	return true
+}
+
+// findFixVolumes lists the volumes in the dir, of the collection if not empty
+func findFixVolumes(dir, collection string) (volumes []fixVolume, err error) {
+	fileInfos, err := ioutil.ReadDir(dir)
 	if err != nil {
-		glog.Fatalf("Create Volume Index [ERROR] %s\n", err)
+		return nil, err
 	}
-	defer indexFile.Close()
+	for _, fileInfo := range fileInfos {
+		name := fileInfo.Name()
+		if fileInfo.IsDir() || !strings.HasSuffix(name, ".dat") {
+			continue
+		}
+		volumeCollection, vid, parseErr := storage.ParseCollectionVolumeId(strings.TrimSuffix(name, ".dat"))
+		if parseErr != nil {
+			glog.V(0).Infof("skip %s: %v", name, parseErr)
+			continue
+		}
+		if collection != "" && volumeCollection != collection {
+			continue
+		}
+		volumes = append(volumes, fixVolume{collection: volumeCollection, id: vid, datSize: fileInfo.Size()})
+	}
+	return volumes, nil
+}
 
-	nm := storage.NewBtreeNeedleMap(indexFile)
-	defer nm.Close()
+// fixOneVolume rebuilds the index of the volume from its .dat file, compares it with the existing index,
+// and replaces the existing index unless in the dry run
+func fixOneVolume(dir string, fv fixVolume, dryRun bool, scanned *int64) (*fixReport, error) {
 
-	vid := needle.VolumeId(*fixVolumeId)
+	report := &fixReport{}
 	scanner := &VolumeFileScanner4Fix{
-		nm: nm,
+		live:    make(map[types.NeedleId]fixIndexEntry),
+		report:  report,
+		scanned: scanned,
 	}
 
-	err = storage.ScanVolumeFile(*fixVolumePath, *fixVolumeCollection, vid, storage.NeedleMapInMemory, scanner)
+	err := storage.ScanVolumeFile(dir, fv.collection, fv.id, storage.NeedleMapInMemory, scanner)
 	if err != nil {
-		glog.Fatalf("Export Volume File [ERROR] %s\n", err)
-		os.Remove(indexFileName)
+		if scanner.version == 0 {
+			// the volume is not loaded at all
+			return nil, err
+		}
+		// the needles before the unreadable data are still indexed
+		report.scanError = err
 	}
 
-	return true
+	indexFileName := path.Join(dir, fv.baseFileName()+".idx")
+	if report.scanError != nil {
+		if err = keepFixIndexEntries(indexFileName, scanner, report); err != nil {
+			return nil, fmt.Errorf("keep the entries of %s after the unreadable data: %v", indexFileName, err)
+		}
+	}
+	if err = compareFixIndex(indexFileName, scanner, report); err != nil {
+		return nil, fmt.Errorf("compare with %s: %v", indexFileName, err)
+	}
+
+	if dryRun {
+		return report, nil
+	}
+
+	if err = writeFixIndex(indexFileName, scanner.entries); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// keepFixIndexEntries appends the entries of the existing index from the unreadable data on, in their order,
+// so the needles after the scan error are not lost by the rebuilt index
+func keepFixIndexEntries(indexFileName string, scanner *VolumeFileScanner4Fix, report *fixReport) error {
+	indexFile, err := os.Open(indexFileName)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer indexFile.Close()
+
+	isAfterScanError := false
+	return idx.WalkIndexFile(indexFile, func(key types.NeedleId, offset types.Offset, size uint32) error {
+		if !offset.IsZero() {
+			isAfterScanError = offset.ToAcutalOffset() >= report.dataEnd
+		}
+		if !isAfterScanError {
+			return nil
+		}
+		entry := fixIndexEntry{key: key, offset: offset, size: size}
+		if offset.IsZero() || size == types.TombstoneFileSize {
+			delete(scanner.live, key)
+		} else {
+			scanner.live[key] = entry
+		}
+		scanner.entries = append(scanner.entries, entry)
+		report.kept++
+		return nil
+	})
+}
+
+// compareFixIndex counts the changes from the existing index to the rebuilt one,
+// and the anomalies of the existing index
+func compareFixIndex(indexFileName string, scanner *VolumeFileScanner4Fix, report *fixReport) error {
+	indexFile, err := os.Open(indexFileName)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer indexFile.Close()
+	report.hasIndex = true
+
+	existing := make(map[types.NeedleId]fixIndexEntry)
+	err = idx.WalkIndexFile(indexFile, func(key types.NeedleId, offset types.Offset, size uint32) error {
+		if offset.IsZero() || size == types.TombstoneFileSize {
+			delete(existing, key)
+		} else {
+			existing[key] = fixIndexEntry{key: key, offset: offset, size: size}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var sorted []fixIndexEntry
+	for key, old := range existing {
+		sorted = append(sorted, old)
+		rebuilt, found := scanner.live[key]
+		if !found {
+			report.removed++
+		} else if rebuilt.offset != old.offset || rebuilt.size != old.size {
+			report.changed++
+		}
+	}
+	for key := range scanner.live {
+		if _, found := existing[key]; !found {
+			report.added++
+		}
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].offset.ToAcutalOffset() < sorted[j].offset.ToAcutalOffset()
+	})
+	for i, entry := range sorted {
+		stop := entry.offset.ToAcutalOffset() + needle.GetActualSize(entry.size, scanner.version)
+		// the end of the data is unknown after the scan error
+		if stop > report.dataEnd && report.scanError == nil {
+			report.beyondEnd++
+		}
+		if i+1 < len(sorted) && stop > sorted[i+1].offset.ToAcutalOffset() {
+			report.overlapping++
+		}
+	}
+	return nil
+}
+
+// writeFixIndex writes the rebuilt index to a temporary file first, so the existing index is kept if it fails
+func writeFixIndex(indexFileName string, entries []fixIndexEntry) error {
+	tmpFileName := indexFileName + ".tmp"
+	tmpFile, err := os.OpenFile(tmpFileName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("create %s: %v", tmpFileName, err)
+	}
+	w := bufio.NewWriterSize(tmpFile, types.NeedleMapEntrySize*1024)
+	for _, entry := range entries {
+		if _, err = w.Write(needle_map.ToBytes(entry.key, entry.offset, entry.size)); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = tmpFile.Sync()
+	}
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpFileName)
+		return fmt.Errorf("write %s: %v", tmpFileName, err)
+	}
+	if err = os.Rename(tmpFileName, indexFileName); err != nil {
+		os.Remove(tmpFileName)
+		return fmt.Errorf("replace %s: %v", indexFileName, err)
+	}
+	return nil
 }
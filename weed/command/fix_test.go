@@ -0,0 +1,84 @@
+package command
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+	"github.com/chrislusf/seaweedfs/weed/storage/types"
+)
+
+func TestKeepFixIndexEntries(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	indexFileName := path.Join(dir, "1.idx")
+
+	// the existing index, with needle 2 overwritten and needle 3 deleted after offset 800
+	existing := []fixIndexEntry{
+		{key: 1, offset: types.ToOffset(8), size: 100},
+		{key: 2, offset: types.ToOffset(400), size: 100},
+		{key: 3, offset: types.ToOffset(600), size: 100},
+		{key: 2, offset: types.ToOffset(800), size: 50},
+		{key: 3, offset: types.ToOffset(1000), size: types.TombstoneFileSize},
+		{key: 4, offset: types.ToOffset(1200), size: 100},
+	}
+	if err = writeFixIndex(indexFileName, existing); err != nil {
+		t.Fatalf("write the existing index: %v", err)
+	}
+
+	// the scan stopped at offset 800, after indexing the needles 1, 2 and 3 again
+	report := &fixReport{scanError: errors.New("read error"), dataEnd: 800}
+	scanner := &VolumeFileScanner4Fix{
+		version: needle.CurrentVersion,
+		entries: existing[:3],
+		live: map[types.NeedleId]fixIndexEntry{
+			1: existing[0],
+			2: existing[1],
+			3: existing[2],
+		},
+		report: report,
+	}
+
+	if err = keepFixIndexEntries(indexFileName, scanner, report); err != nil {
+		t.Fatalf("keep the index entries: %v", err)
+	}
+	if report.kept != 3 || len(scanner.entries) != len(existing) {
+		t.Fatalf("kept %d entries, %d in total", report.kept, len(scanner.entries))
+	}
+	if _, found := scanner.live[3]; found {
+		t.Errorf("the deletion after the unreadable data is not kept")
+	}
+	if entry := scanner.live[2]; entry.offset != existing[3].offset {
+		t.Errorf("needle 2 at %d, expected the overwrite after the unreadable data", entry.offset.ToAcutalOffset())
+	}
+	if _, found := scanner.live[4]; !found {
+		t.Errorf("the needle after the unreadable data is lost")
+	}
+
+	if err = compareFixIndex(indexFileName, scanner, report); err != nil {
+		t.Fatalf("compare: %v", err)
+	}
+	if report.added != 0 || report.removed != 0 || report.changed != 0 || report.beyondEnd != 0 {
+		t.Errorf("unexpected changes: %v", report)
+	}
+}
+
+func TestKeepFixIndexEntriesWithoutIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	report := &fixReport{scanError: errors.New("read error"), dataEnd: 800}
+	scanner := &VolumeFileScanner4Fix{live: make(map[types.NeedleId]fixIndexEntry), report: report}
+	if err = keepFixIndexEntries(path.Join(dir, "1.idx"), scanner, report); err != nil || report.kept != 0 {
+		t.Errorf("kept %d entries: %v", report.kept, err)
+	}
+}
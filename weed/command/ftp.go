@@ -0,0 +1,213 @@
+package command
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/chrislusf/seaweedfs/weed/ftp"
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/security"
+	"github.com/chrislusf/seaweedfs/weed/server"
+	"github.com/chrislusf/seaweedfs/weed/util"
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/net/webdav"
+)
+
+var (
+	ftpStandaloneOptions FtpOption
+)
+
+type FtpOption struct {
+	filer            *string
+	port             *int
+	sftpPort         *int
+	collection       *string
+	publicIp         *string
+	passivePortStart *int
+	passivePortEnd   *int
+	tlsPrivateKey    *string
+	tlsCertificate   *string
+	hostKey          *string
+}
+
+func init() {
+	cmdFtp.Run = runFtp // break init cycle
+	ftpStandaloneOptions.filer = cmdFtp.Flag.String("filer", "localhost:8888", "filer server address")
+	ftpStandaloneOptions.port = cmdFtp.Flag.Int("port", 21, "ftp server listen port, 0 to disable FTP")
+	ftpStandaloneOptions.sftpPort = cmdFtp.Flag.Int("sftp.port", 2022, "sftp server listen port, 0 to disable SFTP")
+	ftpStandaloneOptions.collection = cmdFtp.Flag.String("collection", "", "collection to create the files")
+	ftpStandaloneOptions.publicIp = cmdFtp.Flag.String("publicIp", "", "the address replied to PASV, if the server is behind NAT")
+	ftpStandaloneOptions.passivePortStart = cmdFtp.Flag.Int("passivePortStart", 0, "the first port of the passive data connections, 0 for any port")
+	ftpStandaloneOptions.passivePortEnd = cmdFtp.Flag.Int("passivePortEnd", 0, "the last port of the passive data connections")
+	ftpStandaloneOptions.tlsPrivateKey = cmdFtp.Flag.String("key.file", "", "path to the TLS private key file, enabling FTPS by AUTH TLS")
+	ftpStandaloneOptions.tlsCertificate = cmdFtp.Flag.String("cert.file", "", "path to the TLS certificate file")
+	ftpStandaloneOptions.hostKey = cmdFtp.Flag.String("sftp.hostKey", "", "path to the SSH host private key file, generated on each start if empty")
+}
+
+var cmdFtp = &Command{
+	UsageLine: "ftp -port=21 -sftp.port=2022 -filer=<ip:port>",
+	Short:     "<unstable> start a FTP/FTPS and SFTP server that is backed by a filer",
+	Long: `start a FTP/FTPS and SFTP server that is backed by a filer, for the uploads from the cameras, the scanners and the vendors.
+
+	The users are defined in ftp.toml, which can be generated by "weed scaffold -config=ftp".
+	Each user is limited to the home directory on the filer, with an optional quota of the total file size under it.
+	The users log in to SFTP by the password or by the public keys.
+
+	FTPS is the explicit mode by AUTH TLS, enabled by -cert.file and -key.file.
+	Set tls_required in ftp.toml to reject the logins and the data connections without TLS.
+	Set -publicIp and the passive port range when the server is behind NAT or a firewall.
+
+	Symbolic links and truncating to a non-zero size are not supported.
+
+`,
+}
+
+func runFtp(cmd *Command, args []string) bool {
+
+	util.LoadConfiguration("security", false)
+	util.LoadConfiguration("ftp", true)
+
+	glog.V(0).Infof("Starting Seaweed FTP Server %s at port %d, SFTP at port %d", util.VERSION, *ftpStandaloneOptions.port, *ftpStandaloneOptions.sftpPort)
+
+	return ftpStandaloneOptions.startFtp()
+
+}
+
+func (fo *FtpOption) startFtp() bool {
+
+	filerGrpcAddress, err := parseFilerGrpcAddress(*fo.filer)
+	if err != nil {
+		glog.Fatal(err)
+		return false
+	}
+	grpcDialOption := security.LoadClientTLS(viper.Sub("grpc"), "client")
+
+	users := loadFtpUsers(viper.GetViper())
+	if len(users) == 0 {
+		glog.Fatalf("no users are defined in ftp.toml")
+	}
+
+	option := &ftp.Option{
+		Users:            users,
+		TLSRequired:      viper.GetBool("ftp.tls_required"),
+		PublicIp:         *fo.publicIp,
+		PassivePortStart: *fo.passivePortStart,
+		PassivePortEnd:   *fo.passivePortEnd,
+		NewFileSystem: func(user *ftp.User) webdav.FileSystem {
+			fs, err := weed_server.NewWebDavFileSystem(&weed_server.WebDavOption{
+				Filer:            *fo.filer,
+				FilerGrpcAddress: filerGrpcAddress,
+				GrpcDialOption:   grpcDialOption,
+				Collection:       *fo.collection,
+				Uid:              user.Uid,
+				Gid:              user.Gid,
+			})
+			if err != nil {
+				glog.Fatalf("FTP Server file system for %s: %v", user.Name, err)
+			}
+			return fs
+		},
+	}
+
+	if *fo.tlsCertificate != "" {
+		certificate, err := tls.LoadX509KeyPair(*fo.tlsCertificate, *fo.tlsPrivateKey)
+		if err != nil {
+			glog.Fatalf("FTP Server TLS certificate: %v", err)
+		}
+		option.TLSConfig = &tls.Config{Certificates: []tls.Certificate{certificate}}
+	} else if option.TLSRequired {
+		glog.Fatalf("tls_required in ftp.toml needs -cert.file and -key.file")
+	}
+
+	if *fo.sftpPort != 0 {
+		if option.HostKey, err = loadSshHostKey(*fo.hostKey); err != nil {
+			glog.Fatalf("SFTP Server host key: %v", err)
+		}
+	}
+
+	ftpServer := ftp.NewServer(option)
+
+	if *fo.sftpPort != 0 {
+		listenAddress := fmt.Sprintf(":%d", *fo.sftpPort)
+		sftpListener, err := util.NewListener(listenAddress, 0)
+		if err != nil {
+			glog.Fatalf("SFTP Server listener on %s error: %v", listenAddress, err)
+		}
+		glog.V(0).Infof("Start Seaweed SFTP Server %s at port %d", util.VERSION, *fo.sftpPort)
+		if *fo.port == 0 {
+			if err = ftpServer.ServeSftp(sftpListener); err != nil {
+				glog.Fatalf("SFTP Server Fail to serve: %v", err)
+			}
+			return true
+		}
+		go func() {
+			if err := ftpServer.ServeSftp(sftpListener); err != nil {
+				glog.Fatalf("SFTP Server Fail to serve: %v", err)
+			}
+		}()
+	}
+
+	if *fo.port != 0 {
+		listenAddress := fmt.Sprintf(":%d", *fo.port)
+		ftpListener, err := util.NewListener(listenAddress, 0)
+		if err != nil {
+			glog.Fatalf("FTP Server listener on %s error: %v", listenAddress, err)
+		}
+		glog.V(0).Infof("Start Seaweed FTP Server %s at port %d", util.VERSION, *fo.port)
+		if err = ftpServer.Serve(ftpListener); err != nil {
+			glog.Fatalf("FTP Server Fail to serve: %v", err)
+		}
+	}
+
+	return true
+
+}
+
+func loadFtpUsers(v *viper.Viper) (users []*ftp.User) {
+
+	var userNames []string
+	for name := range v.GetStringMap("ftp.users") {
+		userNames = append(userNames, name)
+	}
+	sort.Strings(userNames)
+
+	for _, name := range userNames {
+		sub := v.Sub("ftp.users." + name)
+		sub.SetDefault("home", "/home/"+name)
+		users = append(users, &ftp.User{
+			Name:       name,
+			Password:   sub.GetString("password"),
+			PublicKeys: sub.GetStringSlice("public_keys"),
+			Uid:        uint32(sub.GetInt("uid")),
+			Gid:        uint32(sub.GetInt("gid")),
+			HomeDir:    sub.GetString("home"),
+			QuotaBytes: sub.GetInt64("quota_mb") * 1024 * 1024,
+			ReadOnly:   sub.GetBool("read_only"),
+		})
+		glog.V(0).Infof("ftp user %s => %s", name, sub.GetString("home"))
+	}
+
+	return
+}
+
+// loadSshHostKey reads the host key, or generates one that changes on every start
+func loadSshHostKey(keyFile string) (ssh.Signer, error) {
+	if keyFile != "" {
+		pemBytes, err := ioutil.ReadFile(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		return ssh.ParsePrivateKey(pemBytes)
+	}
+	glog.Warningf("SFTP Server host key is generated, and the clients will see it changed after restarts, set -sftp.hostKey to keep it")
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	return ssh.NewSignerFromKey(key)
+}
@@ -0,0 +1,89 @@
+package command
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/server"
+	"github.com/chrislusf/seaweedfs/weed/util"
+)
+
+var (
+	km KeyMapOptions
+)
+
+type KeyMapOptions struct {
+	masters        *string
+	ip             *string
+	port           *int
+	collection     *string
+	replication    *string
+	dataCenter     *string
+	redirectOnRead *bool
+}
+
+func init() {
+	cmdKeyMap.Run = runKeyMap // break init cycle
+	km.masters = cmdKeyMap.Flag.String("master", "localhost:9333", "comma-separated master servers")
+	km.ip = cmdKeyMap.Flag.String("ip", "", "key map server http listen ip address")
+	km.port = cmdKeyMap.Flag.Int("port", 8555, "key map server http listen port")
+	km.collection = cmdKeyMap.Flag.String("collection", "keymap", "the collection of the blobs, not to be used by the filer")
+	km.replication = cmdKeyMap.Flag.String("replication", "", "the replication of the uploaded blobs, the master default if empty")
+	km.dataCenter = cmdKeyMap.Flag.String("dataCenter", "", "prefer to write to volumes in this data center")
+	km.redirectOnRead = cmdKeyMap.Flag.Bool("redirectOnRead", false, "whether proxy or redirect to volume server during GET request")
+}
+
+var cmdKeyMap = &Command{
+	UsageLine: "keymap -port=8555 -master=<ip:port>[,<ip:port>]*",
+	Short:     "start a server mapping the keys to the blobs on the volume servers",
+	Long: `start a server which maps the keys to the file ids of the blobs, without the directories of the filer.
+
+	//upload the blob, and map the key to it, deleting the blob mapped before
+	PUT /path/to/key
+	//map the key to a blob already uploaded to the volume servers, in the collection of the key map server
+	PUT /path/to/key?fid=3,01637037d6
+	//get the blob, or the mapping with ?meta=true
+	GET /path/to/key
+	//delete the mapping and the blob
+	DELETE /path/to/key
+
+	The keys are kept in the filer store configured by "keymap.toml", which is in the same format as "filer.toml",
+	or else in the leveldb directory "./keymapldb2". In a store shared with a filer, the keys are kept under "/.keymap".
+	The blobs are kept in the -collection, apart from the blobs of the filer,
+	so do not run "volume.fsck -collection" with -purge on it, since the filer does not reference the blobs.
+
+`,
+}
+
+func runKeyMap(cmd *Command, args []string) bool {
+
+	util.LoadConfiguration("security", false)
+
+	mux := http.NewServeMux()
+	_, err := weed_server.NewKeyMapServer(mux, &weed_server.KeyMapOption{
+		Masters:           strings.Split(*km.masters, ","),
+		Collection:        *km.collection,
+		Replication:       *km.replication,
+		DataCenter:        *km.dataCenter,
+		RedirectOnRead:    *km.redirectOnRead,
+		DefaultLevelDbDir: "./keymapldb2",
+	})
+	if err != nil {
+		glog.Fatalf("KeyMap startup error: %v", err)
+	}
+
+	listenAddress := *km.ip + ":" + strconv.Itoa(*km.port)
+	glog.V(0).Infof("Start Seaweed KeyMap Server %s at %s", util.VERSION, listenAddress)
+	listener, err := util.NewListener(listenAddress, time.Duration(10)*time.Second)
+	if err != nil {
+		glog.Fatalf("KeyMap listener error: %v", err)
+	}
+	if err = http.Serve(listener, mux); err != nil {
+		glog.Fatalf("KeyMap Fail to serve: %v", err)
+	}
+
+	return true
+}
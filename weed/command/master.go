@@ -6,6 +6,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/chrislusf/raft/protobuf"
 	"github.com/chrislusf/seaweedfs/weed/glog"
@@ -37,6 +38,15 @@ type MasterOptions struct {
 	disableHttp        *bool
 	metricsAddress     *string
 	metricsIntervalSec *int
+	flappingWindow     *time.Duration
+	flappingThreshold  *int
+	forecastInterval   *time.Duration
+	forecastRetention  *time.Duration
+	lookupCacheTtl     *time.Duration
+	standbyStaleness   *time.Duration
+	heartbeatMaxRate   *int
+	snapshotInterval   *time.Duration
+	snapshotThreshold  *uint64
 }
 
 func init() {
@@ -55,6 +65,15 @@ func init() {
 	m.disableHttp = cmdMaster.Flag.Bool("disableHttp", false, "disable http requests, only gRPC operations are allowed.")
 	m.metricsAddress = cmdMaster.Flag.String("metrics.address", "", "Prometheus gateway address")
 	m.metricsIntervalSec = cmdMaster.Flag.Int("metrics.intervalSeconds", 15, "Prometheus push interval in seconds")
+	m.flappingWindow = cmdMaster.Flag.Duration("flapping.window", 10*time.Minute, "window to count the disconnections of each volume server")
+	m.flappingThreshold = cmdMaster.Flag.Int("flapping.threshold", 5, "quarantine the volume server disconnected this many times within the window, until cleared by /vol/quarantine?clear=<ip:port>. 0 to disable")
+	m.forecastInterval = cmdMaster.Flag.Duration("forecast.sampleInterval", 10*time.Minute, "interval to sample the utilization of each volume server, to forecast the days until full by /vol/forecast")
+	m.forecastRetention = cmdMaster.Flag.Duration("forecast.retention", 7*24*time.Hour, "forecast by the utilization samples within this duration")
+	m.snapshotInterval = cmdMaster.Flag.Duration("raft.snapshotInterval", 10*time.Minute, "interval to snapshot the master state and compact the raft log, 0 to disable")
+	m.snapshotThreshold = cmdMaster.Flag.Uint64("raft.snapshotThreshold", 1000, "snapshot only after this many raft log entries since the last snapshot")
+	m.standbyStaleness = cmdMaster.Flag.Duration("standby.maxStaleness", 10*time.Second, "non-leader masters answer the lookups by the volume locations followed from the leader, if updated within this duration. 0 to always ask the leader")
+	m.lookupCacheTtl = cmdMaster.Flag.Duration("lookup.cacheTtl", 10*time.Minute, "clients may cache the looked up volume locations for this long, unless the topology version changes")
+	m.heartbeatMaxRate = cmdMaster.Flag.Int("heartbeat.maxPerSecond", 200, "slow down the volume server heartbeats to at most this many per second in total. 0 to always use the pulseSeconds")
 }
 
 var cmdMaster = &Command{
@@ -110,12 +129,12 @@ func runMaster(cmd *Command, args []string) bool {
 		// start raftServer
 		myMasterAddress, peers := checkPeers(*m.ip, *m.port, *m.peers)
 		raftServer := weed_server.NewRaftServer(security.LoadClientTLS(viper.Sub("grpc"), "master"),
-			peers, myMasterAddress, *m.metaFolder, ms.Topo, *m.pulseSeconds)
+			peers, myMasterAddress, *m.metaFolder, ms.Topo, *m.pulseSeconds, *m.snapshotInterval, *m.snapshotThreshold)
 		if raftServer == nil {
 			glog.Fatalf("please verify %s is writable, see https://github.com/chrislusf/seaweedfs/issues/717", *m.metaFolder)
 		}
 		ms.SetRaftServer(raftServer)
-		r.HandleFunc("/cluster/status", raftServer.StatusHandler).Methods("GET")
+		r.HandleFunc("/cluster/status", ms.ClusterStatusHandler).Methods("GET")
 
 		// starting grpc server
 		grpcPort := *m.port + 10000
@@ -179,5 +198,12 @@ func (m *MasterOptions) toMasterOption(whiteList []string) *weed_server.MasterOp
 		DisableHttp:             *m.disableHttp,
 		MetricsAddress:          *m.metricsAddress,
 		MetricsIntervalSec:      *m.metricsIntervalSec,
+		FlappingWindow:          *m.flappingWindow,
+		FlappingThreshold:       *m.flappingThreshold,
+		ForecastInterval:        *m.forecastInterval,
+		ForecastRetention:       *m.forecastRetention,
+		LookupCacheTtl:          *m.lookupCacheTtl,
+		StandbyMaxStaleness:     *m.standbyStaleness,
+		HeartbeatMaxRate:        *m.heartbeatMaxRate,
 	}
 }
@@ -2,6 +2,7 @@ package command
 
 import (
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
 )
@@ -17,6 +18,13 @@ type MountOptions struct {
 	chunkSizeLimitMB   *int
 	dataCenter         *string
 	allowOthers        *bool
+	caseInsensitive    *bool
+	chunkLocality      *bool
+	cacheDir           *string
+	cacheCapacityMB    *int64
+	readAheadChunks    *int
+	streamChunkSizeMB  *int
+	streamConcurrency  *int
 }
 
 var (
@@ -37,6 +45,13 @@ func init() {
 	mountOptions.chunkSizeLimitMB = cmdMount.Flag.Int("chunkSizeLimitMB", 4, "local write buffer size, also chunk large files")
 	mountOptions.dataCenter = cmdMount.Flag.String("dataCenter", "", "prefer to write to the data center")
 	mountOptions.allowOthers = cmdMount.Flag.Bool("allowOthers", true, "allows other users to access the file system")
+	mountOptions.caseInsensitive = cmdMount.Flag.Bool("caseInsensitive", false, "look up names case-insensitively while preserving their case, for Windows or macOS clients")
+	mountOptions.chunkLocality = cmdMount.Flag.Bool("chunkLocality", false, "prefer to write all chunks of a file to the same volume, for faster sequential reads, e.g., video streaming")
+	mountOptions.cacheDir = cmdMount.Flag.String("cacheDir", os.TempDir(), "local cache directory for the file chunks")
+	mountOptions.cacheCapacityMB = cmdMount.Flag.Int64("cacheCapacityMB", 0, "local disk cache capacity in MB for the recently read file chunks, 0 to disable the cache")
+	mountOptions.readAheadChunks = cmdMount.Flag.Int("readAheadChunks", 2, "with the cache, fetch this many chunks ahead of the sequential reads")
+	mountOptions.streamChunkSizeMB = cmdMount.Flag.Int("streamChunkSizeMB", 0, "upload the sequential writes in chunks of this size in parallel, 0 to buffer all writes by chunkSizeLimitMB")
+	mountOptions.streamConcurrency = cmdMount.Flag.Int("streamConcurrency", 4, "the chunks uploaded in parallel for each file written sequentially")
 	mountCpuProfile = cmdMount.Flag.String("cpuprofile", "", "cpu profile output file")
 	mountMemProfile = cmdMount.Flag.String("memprofile", "", "memory profile output file")
 }
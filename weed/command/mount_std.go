@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/user"
 	"path"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
@@ -38,11 +39,19 @@ func runMount(cmd *Command, args []string) bool {
 		*mountOptions.allowOthers,
 		*mountOptions.ttlSec,
 		*mountOptions.dirListingLimit,
+		*mountOptions.caseInsensitive,
+		*mountOptions.chunkLocality,
+		*mountOptions.cacheDir,
+		*mountOptions.cacheCapacityMB,
+		*mountOptions.readAheadChunks,
+		*mountOptions.streamChunkSizeMB,
+		*mountOptions.streamConcurrency,
 	)
 }
 
 func RunMount(filer, filerMountRootPath, dir, collection, replication, dataCenter string, chunkSizeLimitMB int,
-	allowOthers bool, ttlSec int, dirListingLimit int) bool {
+	allowOthers bool, ttlSec int, dirListingLimit int, caseInsensitive bool, chunkLocality bool,
+	cacheDir string, cacheCapacityMB int64, readAheadChunks int, streamChunkSizeMB int, streamConcurrency int) bool {
 
 	util.LoadConfiguration("security", false)
 
@@ -130,7 +139,7 @@ func RunMount(filer, filerMountRootPath, dir, collection, replication, dataCente
 
 	daemonize.SignalOutcome(nil)
 
-	err = fs.Serve(c, filesys.NewSeaweedFileSystem(&filesys.Option{
+	wfs := filesys.NewSeaweedFileSystem(&filesys.Option{
 		FilerGrpcAddress:   filerGrpcAddress,
 		GrpcDialOption:     security.LoadClientTLS(viper.Sub("grpc"), "client"),
 		FilerMountRootPath: mountRoot,
@@ -141,12 +150,22 @@ func RunMount(filer, filerMountRootPath, dir, collection, replication, dataCente
 		DataCenter:         dataCenter,
 		DirListingLimit:    dirListingLimit,
 		EntryCacheTtl:      3 * time.Second,
+		CaseInsensitive:    caseInsensitive,
+		ChunkLocality:      chunkLocality,
+		ChunkCacheDir:      filepath.Join(cacheDir, "chunks_"+strings.Replace(filerGrpcAddress, ":", "_", -1)),
+		ChunkCacheCapacity: cacheCapacityMB * 1024 * 1024,
+		ReadAheadChunks:    readAheadChunks,
+		StreamChunkSize:    int64(streamChunkSizeMB) * 1024 * 1024,
+		StreamConcurrency:  streamConcurrency,
 		MountUid:           uid,
 		MountGid:           gid,
 		MountMode:          mountMode,
 		MountCtime:         fileInfo.ModTime(),
 		MountMtime:         time.Now(),
-	}))
+	})
+	fuseServer := fs.New(c, nil)
+	wfs.StartCacheInvalidation(fuseServer)
+	err = fuseServer.Serve(wfs)
 	if err != nil {
 		fuse.Unmount(dir)
 	}
@@ -0,0 +1,118 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/nfs"
+	"github.com/chrislusf/seaweedfs/weed/security"
+	"github.com/chrislusf/seaweedfs/weed/server"
+	"github.com/chrislusf/seaweedfs/weed/util"
+	"github.com/spf13/viper"
+	"golang.org/x/net/webdav"
+)
+
+var (
+	nfsStandaloneOptions NfsOption
+)
+
+type NfsOption struct {
+	filer      *string
+	filerPath  *string
+	port       *int
+	collection *string
+	readOnly   *bool
+	whiteList  *string
+}
+
+func init() {
+	cmdNfs.Run = runNfs // break init cycle
+	nfsStandaloneOptions.filer = cmdNfs.Flag.String("filer", "localhost:8888", "filer server address")
+	nfsStandaloneOptions.filerPath = cmdNfs.Flag.String("filer.path", "/", "the directory on the filer to export")
+	nfsStandaloneOptions.port = cmdNfs.Flag.Int("port", 2049, "nfs server listen port, also serving the mount protocol")
+	nfsStandaloneOptions.collection = cmdNfs.Flag.String("collection", "", "collection to create the files")
+	nfsStandaloneOptions.readOnly = cmdNfs.Flag.Bool("readOnly", false, "export the directory read only")
+	nfsStandaloneOptions.whiteList = cmdNfs.Flag.String("whiteList", "", "comma separated Ip addresses or CIDR ranges of the clients allowed to mount. No limit if empty.")
+}
+
+var cmdNfs = &Command{
+	UsageLine: "nfs -port=2049 -filer=<ip:port> -filer.path=/",
+	Short:     "<unstable> start a NFS v3 server that is backed by a filer",
+	Long: `start a NFS v3 server that is backed by a filer, for the clients that can not run FUSE or S3.
+
+	The NFS and the MOUNT protocols are served on the same TCP port, without registering to the portmapper,
+	so the clients should be given the ports, and mount without the network lock manager:
+
+	  mount -t nfs -o vers=3,proto=tcp,port=2049,mountport=2049,mountproto=tcp,nolock <host>:/ /mnt
+
+	The files are created as the uid and gid of the AUTH_UNIX credential of the client, and the owners are reported as the client.
+	The file handles are the paths on the filer, so the handles of the renamed files and directories become stale.
+	The handles of the long paths have the hashes of the last names, and are found again by listing the directories after a restart.
+	Use -whiteList to limit the clients, since the AUTH_UNIX credentials are not verified.
+	Symbolic links, hard links, special files and truncating to a non-zero size are not supported.
+
+`,
+}
+
+func runNfs(cmd *Command, args []string) bool {
+
+	util.LoadConfiguration("security", false)
+
+	glog.V(0).Infof("Starting Seaweed NFS Server %s at port %d", util.VERSION, *nfsStandaloneOptions.port)
+
+	return nfsStandaloneOptions.startNfs()
+
+}
+
+func (no *NfsOption) startNfs() bool {
+
+	filerGrpcAddress, err := parseFilerGrpcAddress(*no.filer)
+	if err != nil {
+		glog.Fatal(err)
+		return false
+	}
+	grpcDialOption := security.LoadClientTLS(viper.Sub("grpc"), "client")
+
+	var whiteList []string
+	if *no.whiteList != "" {
+		whiteList = strings.Split(*no.whiteList, ",")
+	}
+
+	nfsServer := nfs.NewServer(&nfs.Option{
+		Path:     *no.filerPath,
+		ReadOnly: *no.readOnly,
+		NewFileSystem: func(uid, gid uint32) webdav.FileSystem {
+			fs, err := weed_server.NewWebDavFileSystem(&weed_server.WebDavOption{
+				Filer:            *no.filer,
+				FilerGrpcAddress: filerGrpcAddress,
+				GrpcDialOption:   grpcDialOption,
+				Collection:       *no.collection,
+				Uid:              uid,
+				Gid:              gid,
+			})
+			if err != nil {
+				glog.Fatalf("NFS Server file system for uid %d: %v", uid, err)
+			}
+			return fs
+		},
+		DiskUsage: func() (total, free uint64, err error) {
+			return filerDiskUsage(filerGrpcAddress, grpcDialOption, *no.collection)
+		},
+		WhiteList: whiteList,
+	})
+
+	listenAddress := fmt.Sprintf(":%d", *no.port)
+	nfsListener, err := util.NewListener(listenAddress, 0)
+	if err != nil {
+		glog.Fatalf("NFS Server listener on %s error: %v", listenAddress, err)
+	}
+
+	glog.V(0).Infof("Start Seaweed NFS Server %s at port %d", util.VERSION, *no.port)
+	if err = nfsServer.Serve(nfsListener); err != nil {
+		glog.Fatalf("NFS Server Fail to serve: %v", err)
+	}
+
+	return true
+
+}
@@ -0,0 +1,84 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chrislusf/seaweedfs/weed/storage/cloud_backup"
+)
+
+var (
+	rc RestoreCloudOptions
+)
+
+type RestoreCloudOptions struct {
+	dir        *string
+	server     *string
+	collection *string
+	volumeId   *int
+	store      cloudStoreOptions
+}
+
+func init() {
+	cmdRestoreCloud.Run = runRestoreCloud // break init cycle
+	rc.dir = cmdRestoreCloud.Flag.String("dir", ".", "directory to restore the volume data files")
+	rc.server = cmdRestoreCloud.Flag.String("server", "", "only restore the volumes backed up from this volume server <ip>:<port>")
+	rc.collection = cmdRestoreCloud.Flag.String("collection", "", "only restore the volumes in this collection")
+	rc.volumeId = cmdRestoreCloud.Flag.Int("volumeId", -1, "only restore this volume")
+	rc.store = newCloudStoreOptions(&cmdRestoreCloud.Flag)
+}
+
+var cmdRestoreCloud = &Command{
+	UsageLine: "restore.cloud -dir=. -server=<ip>:<port> -storage=s3 -bucket=<bucket> -prefix=<folder>",
+	Short:     "restore the volumes backed up by backup.cloud",
+	Long: `Restore the volume .dat and .idx files backed up by "weed backup.cloud".
+
+	The volumes are rebuilt in the directory from the latest backup generation of each volume.
+	The existing volume files are not overwritten.
+
+	To rebuild a lost volume server, restore the volumes backed up from it into an empty directory,
+	and start the volume server on the directory:
+
+		weed restore.cloud -server=<ip>:<port> -dir=/data -bucket=<bucket> -prefix=<folder>
+		weed volume -dir=/data ...
+
+	The cloud storage flags and credentials are the same as "weed backup.cloud".
+  `,
+}
+
+func runRestoreCloud(cmd *Command, args []string) bool {
+
+	store, err := rc.store.connect()
+	if err != nil {
+		fmt.Printf("Error connecting to the cloud storage: %v\n", err)
+		return false
+	}
+
+	ctx := context.Background()
+	var restoredCount int
+	err = cloud_backup.ListManifests(ctx, store, *rc.store.prefix, func(m *cloud_backup.Manifest) error {
+		if *rc.volumeId >= 0 && m.VolumeId != uint32(*rc.volumeId) {
+			return nil
+		}
+		if *rc.collection != "" && m.Collection != *rc.collection {
+			return nil
+		}
+		if *rc.server != "" && m.Server != *rc.server {
+			return nil
+		}
+		if err := cloud_backup.RestoreVolume(ctx, store, *rc.store.prefix, m, *rc.dir); err != nil {
+			fmt.Printf("Error restoring volume %d: %v\n", m.VolumeId, err)
+			return nil
+		}
+		restoredCount++
+		fmt.Printf("volume %d restored %d bytes, backed up from %s at %v\n",
+			m.VolumeId, m.DatFileSize()+m.IdxFileSize(), m.Server, m.Segments[len(m.Segments)-1].BackedUpAt)
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("Error listing the backups: %v\n", err)
+	}
+	fmt.Printf("restored %d volumes\n", restoredCount)
+
+	return true
+}
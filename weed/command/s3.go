@@ -1,7 +1,10 @@
 package command
 
 import (
+	"flag"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/chrislusf/seaweedfs/weed/security"
@@ -9,6 +12,7 @@ import (
 
 	"fmt"
 
+	"github.com/chrislusf/seaweedfs/weed/audit"
 	"github.com/chrislusf/seaweedfs/weed/glog"
 	"github.com/chrislusf/seaweedfs/weed/s3api"
 	"github.com/chrislusf/seaweedfs/weed/util"
@@ -26,6 +30,27 @@ type S3Options struct {
 	domainName       *string
 	tlsPrivateKey    *string
 	tlsCertificate   *string
+	accessLogFlush   *time.Duration
+	websiteDomain    *string
+	rateLimit        rateLimitOptions
+}
+
+type rateLimitOptions struct {
+	requests             *float64
+	mbps                 *float64
+	perClientRequests    *float64
+	perClientMbps        *float64
+	perAccessKeyRequests *float64
+	perAccessKeyMbps     *float64
+}
+
+func (o *rateLimitOptions) init(flags *flag.FlagSet, prefix string) {
+	o.requests = flags.Float64(prefix+"rateLimit.requestsPerSecond", 0, "limit the requests per second in total, rejecting the others with SlowDown, 0 for no limit")
+	o.mbps = flags.Float64(prefix+"rateLimit.MBps", 0, "limit the request and response bodies in total to mega bytes per second, 0 for no limit")
+	o.perClientRequests = flags.Float64(prefix+"rateLimit.perClient.requestsPerSecond", 0, "limit the requests per second of each client ip, rejecting the others with SlowDown, 0 for no limit")
+	o.perClientMbps = flags.Float64(prefix+"rateLimit.perClient.MBps", 0, "limit the request and response bodies of each client ip to mega bytes per second, 0 for no limit")
+	o.perAccessKeyRequests = flags.Float64(prefix+"rateLimit.perAccessKey.requestsPerSecond", 0, "limit the requests per second of each access key, verified by the request signatures, rejecting the others with SlowDown, 0 for no limit")
+	o.perAccessKeyMbps = flags.Float64(prefix+"rateLimit.perAccessKey.MBps", 0, "limit the request and response bodies of each access key to mega bytes per second, 0 for no limit")
 }
 
 func init() {
@@ -36,6 +61,9 @@ func init() {
 	s3StandaloneOptions.domainName = cmdS3.Flag.String("domainName", "", "suffix of the host name, {bucket}.{domainName}")
 	s3StandaloneOptions.tlsPrivateKey = cmdS3.Flag.String("key.file", "", "path to the TLS private key file")
 	s3StandaloneOptions.tlsCertificate = cmdS3.Flag.String("cert.file", "", "path to the TLS certificate file")
+	s3StandaloneOptions.accessLogFlush = cmdS3.Flag.Duration("accessLog.flushInterval", 5*time.Minute, "interval to write the server access logs into the target buckets")
+	s3StandaloneOptions.websiteDomain = cmdS3.Flag.String("website.domainName", "", "suffix of the host name to serve the buckets as static websites, {bucket}.{website.domainName}")
+	s3StandaloneOptions.rateLimit.init(&cmdS3.Flag, "")
 }
 
 var cmdS3 = &Command{
@@ -43,6 +71,12 @@ var cmdS3 = &Command{
 	Short:     "start a s3 API compatible server that is backed by a filer",
 	Long: `start a s3 API compatible server that is backed by a filer.
 
+	The requests are signed by the access keys of "s3.credentials" in "security.toml", if any.
+	Then the anonymous requests can only read the objects allowed by the bucket policies.
+
+	The access keys in "s3.rate_limits" of "security.toml" have their own limits,
+	instead of the -rateLimit.perAccessKey.* ones.
+
 `,
 }
 
@@ -62,6 +96,32 @@ func (s3opt *S3Options) startS3Server() bool {
 		return false
 	}
 
+	credentials := make(map[string]string)
+	for _, credential := range viper.GetStringSlice("s3.credentials") {
+		parts := strings.SplitN(credential, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			glog.Fatalf("invalid s3 credential %q, expecting access_key:secret_key", credential)
+		}
+		credentials[parts[0]] = parts[1]
+	}
+
+	accessKeyRateLimits := make(map[string]util.RateLimit)
+	for _, rateLimit := range viper.GetStringSlice("s3.rate_limits") {
+		parts := strings.Split(rateLimit, ":")
+		if len(parts) != 3 || parts[0] == "" {
+			glog.Fatalf("invalid s3 rate limit %q, expecting access_key:requests_per_second:MBps", rateLimit)
+		}
+		requests, requestsErr := strconv.ParseFloat(parts[1], 64)
+		mbps, mbpsErr := strconv.ParseFloat(parts[2], 64)
+		if requestsErr != nil || mbpsErr != nil {
+			glog.Fatalf("invalid s3 rate limit %q, expecting access_key:requests_per_second:MBps", rateLimit)
+		}
+		accessKeyRateLimits[parts[0]] = util.RateLimit{RequestsPerSecond: requests, BytesPerSecond: mbps * 1024 * 1024}
+	}
+
+	util.LoadConfiguration("audit", false)
+	audit.LoadConfiguration(viper.Sub("audit"))
+
 	router := mux.NewRouter().SkipClean(true)
 
 	_, s3ApiServer_err := s3api.NewS3ApiServer(router, &s3api.S3ApiServerOption{
@@ -70,6 +130,15 @@ func (s3opt *S3Options) startS3Server() bool {
 		DomainName:       *s3opt.domainName,
 		BucketsPath:      *s3opt.filerBucketsPath,
 		GrpcDialOption:   security.LoadClientTLS(viper.Sub("grpc"), "client"),
+		Credentials:      credentials,
+
+		AccessLogFlushInterval: *s3opt.accessLogFlush,
+		WebsiteDomainName:      *s3opt.websiteDomain,
+
+		RateLimit:             util.RateLimit{RequestsPerSecond: *s3opt.rateLimit.requests, BytesPerSecond: *s3opt.rateLimit.mbps * 1024 * 1024},
+		RateLimitPerClient:    util.RateLimit{RequestsPerSecond: *s3opt.rateLimit.perClientRequests, BytesPerSecond: *s3opt.rateLimit.perClientMbps * 1024 * 1024},
+		RateLimitPerAccessKey: util.RateLimit{RequestsPerSecond: *s3opt.rateLimit.perAccessKeyRequests, BytesPerSecond: *s3opt.rateLimit.perAccessKeyMbps * 1024 * 1024},
+		AccessKeyRateLimits:   accessKeyRateLimits,
 	})
 	if s3ApiServer_err != nil {
 		glog.Fatalf("S3 API Server startup error: %v", s3ApiServer_err)
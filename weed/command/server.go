@@ -84,29 +84,66 @@ func init() {
 	masterOptions.garbageThreshold = cmdServer.Flag.Float64("garbageThreshold", 0.3, "threshold to vacuum and reclaim spaces")
 	masterOptions.metricsAddress = cmdServer.Flag.String("metrics.address", "", "Prometheus gateway address")
 	masterOptions.metricsIntervalSec = cmdServer.Flag.Int("metrics.intervalSeconds", 15, "Prometheus push interval in seconds")
+	masterOptions.flappingWindow = cmdServer.Flag.Duration("master.flapping.window", 10*time.Minute, "window to count the disconnections of each volume server")
+	masterOptions.flappingThreshold = cmdServer.Flag.Int("master.flapping.threshold", 5, "quarantine the volume server disconnected this many times within the window. 0 to disable")
+	masterOptions.forecastInterval = cmdServer.Flag.Duration("master.forecast.sampleInterval", 10*time.Minute, "interval to sample the utilization of each volume server")
+	masterOptions.forecastRetention = cmdServer.Flag.Duration("master.forecast.retention", 7*24*time.Hour, "forecast by the utilization samples within this duration")
+	masterOptions.snapshotInterval = cmdServer.Flag.Duration("master.raft.snapshotInterval", 10*time.Minute, "interval to snapshot the master state and compact the raft log, 0 to disable")
+	masterOptions.snapshotThreshold = cmdServer.Flag.Uint64("master.raft.snapshotThreshold", 1000, "snapshot only after this many raft log entries since the last snapshot")
+	masterOptions.standbyStaleness = cmdServer.Flag.Duration("master.standby.maxStaleness", 10*time.Second, "non-leader masters answer the lookups by the volume locations followed from the leader, if updated within this duration. 0 to always ask the leader")
+	masterOptions.lookupCacheTtl = cmdServer.Flag.Duration("master.lookup.cacheTtl", 10*time.Minute, "clients may cache the looked up volume locations for this long, unless the topology version changes")
+	masterOptions.heartbeatMaxRate = cmdServer.Flag.Int("master.heartbeat.maxPerSecond", 200, "slow down the volume server heartbeats to at most this many per second in total. 0 to always use the pulseSeconds")
 
 	filerOptions.collection = cmdServer.Flag.String("filer.collection", "", "all data will be stored in this collection")
 	filerOptions.port = cmdServer.Flag.Int("filer.port", 8888, "filer server http listen port")
 	filerOptions.publicPort = cmdServer.Flag.Int("filer.port.public", 0, "filer server public http listen port")
-	filerOptions.defaultReplicaPlacement = cmdServer.Flag.String("filer.defaultReplicaPlacement", "", "Default replication type if not specified during runtime.")
+	filerOptions.metricsPort = cmdServer.Flag.Int("filer.metricsPort", 0, "serve the filer prometheus metrics at /metrics on this port, apart from the files, not served if 0")
+	filerOptions.defaultReplicaPlacement = cmdServer.Flag.String("filer.defaultReplicaPlacement", "", "Default replication type if not specified during runtime. Empty to use the collection or the master default.")
 	filerOptions.redirectOnRead = cmdServer.Flag.Bool("filer.redirectOnRead", false, "whether proxy or redirect to volume server during file GET request")
 	filerOptions.disableDirListing = cmdServer.Flag.Bool("filer.disableDirListing", false, "turn off directory listing")
 	filerOptions.maxMB = cmdServer.Flag.Int("filer.maxMB", 32, "split files larger than the limit")
 	filerOptions.dirListingLimit = cmdServer.Flag.Int("filer.dirListLimit", 1000, "limit sub dir listing size")
+	filerOptions.dedup = cmdServer.Flag.Bool("filer.dedup", false, "reuse the existing chunks with the same content for the http uploads")
+	filerOptions.concurrentRequests = cmdServer.Flag.Int("filer.concurrentRequests", 0, "limit the http requests served at the same time, 0 for no limit")
+	filerOptions.concurrentPerClient = cmdServer.Flag.Int("filer.concurrentRequestsPerClient", 0, "limit the http requests served at the same time for each client, 0 for no limit")
+	filerOptions.requestQueueSize = cmdServer.Flag.Int("filer.requestQueueSize", 1024, "the http requests waiting for the concurrency limits, beyond which the requests are rejected")
+	filerOptions.clientIdHeader = cmdServer.Flag.String("filer.clientIdHeader", "", "the header set by a trusted proxy to identify the clients for the concurrency limits, instead of the remote ip")
+	filerOptions.rateLimitRequests = cmdServer.Flag.Float64("filer.rateLimit.requestsPerSecond", 0, "limit the http requests per second in total, rejecting the others with 429, 0 for no limit")
+	filerOptions.rateLimitMBps = cmdServer.Flag.Float64("filer.rateLimit.MBps", 0, "limit the http request and response bodies in total to mega bytes per second, 0 for no limit")
+	filerOptions.rateLimitPerClientReqs = cmdServer.Flag.Float64("filer.rateLimit.perClient.requestsPerSecond", 0, "limit the http requests per second of each client, rejecting the others with 429, 0 for no limit")
+	filerOptions.rateLimitPerClientMBps = cmdServer.Flag.Float64("filer.rateLimit.perClient.MBps", 0, "limit the http request and response bodies of each client to mega bytes per second, 0 for no limit")
+	filerOptions.enforcePermissions = cmdServer.Flag.Bool("filer.enforcePermissions", false, "check the caller uid and gids against the owner, group and mode of the entries, trusting the callers configured in security.toml")
+	filerOptions.readFallback = cmdServer.Flag.Bool("filer.readFallback", true, "read from the other volume replicas, or the ec shards, when the volume server read first fails")
+	filerOptions.ingestFlushInterval = cmdServer.Flag.Duration("filer.ingest.flushInterval", time.Second, "append the records ingested with op=ingest to their files on this interval")
+	filerOptions.ingestMaxBufferMB = cmdServer.Flag.Int("filer.ingest.maxBufferMB", 256, "the ingested records waiting for the flush, beyond which the ingestion requests are rejected")
+	filerOptions.imageCacheCollection = cmdServer.Flag.String("filer.image.cacheCollection", "image_cache", "cache the images transformed by ?width=&height=&fit=&format= in this collection, empty to not cache")
+	filerOptions.imageCacheTtl = cmdServer.Flag.String("filer.image.cacheTtl", "", "the time to keep the cached transformed images, e.g. 7d, forever if empty")
+	filerOptions.volumeConcurrency = cmdServer.Flag.Int("filer.volumeClient.concurrentPerServer", 64, "limit the http and grpc requests in flight to each volume server, sharing their connections, 0 for no limit")
+	filerOptions.volumeFailureThreshold = cmdServer.Flag.Int("filer.volumeClient.failureThreshold", 5, "fail fast the requests to a volume server for 10 seconds after this many failures in a row, 0 to never fail fast")
+	filerOptions.clusterId = cmdServer.Flag.String("filer.clusterId", "", "stamp the entries written on this filer with the cluster id, to resolve the conflicts when replicating both ways with another cluster by filer.replicate")
 
 	serverOptions.v.port = cmdServer.Flag.Int("volume.port", 8080, "volume server http listen port")
 	serverOptions.v.publicPort = cmdServer.Flag.Int("volume.port.public", 0, "volume server public port")
-	serverOptions.v.indexType = cmdServer.Flag.String("volume.index", "memory", "Choose [memory|leveldb|leveldbMedium|leveldbLarge] mode for memory~performance balance.")
+	serverOptions.v.indexType = cmdServer.Flag.String("volume.index", "memory", "Choose [memory|leveldb|leveldbMedium|leveldbLarge|mmap] mode for memory~performance balance. mmap starts fast and keeps only the hot index pages in memory.")
 	serverOptions.v.fixJpgOrientation = cmdServer.Flag.Bool("volume.images.fix.orientation", false, "Adjust jpg orientation when uploading.")
 	serverOptions.v.readRedirect = cmdServer.Flag.Bool("volume.read.redirect", true, "Redirect moved or non-local volumes.")
 	serverOptions.v.compactionMBPerSecond = cmdServer.Flag.Int("volume.compactionMBps", 0, "limit compaction speed in mega bytes per second")
+	serverOptions.v.maintenanceMBPerSecond = cmdServer.Flag.Int("volume.maintenanceMBps", 0, "limit the replica repairs, volume moves, and ec encoding and rebuilding of the volume server to mega bytes per second in total, 0 for no limit")
+	serverOptions.v.readOnly = cmdServer.Flag.Bool("volume.readOnly", false, "only serve the reads of the existing volumes, disabling all writes and volume changes")
+	serverOptions.v.labels = cmdServer.Flag.String("volume.labels", "", "comma separated labels for the volume placement, e.g. zone=eu,class=archive")
+	serverOptions.v.fileSizeLimitMB = cmdServer.Flag.Int("volume.fileSizeLimitMB", 0, "reject the uploads of larger files, to be split into chunks by the filer. 0 for the limit of a single needle, about 4GB")
+	serverOptions.v.fsync = cmdServer.Flag.String("volume.fsync", "never", "when to fsync the writes: [never|per-write|interval=100ms|group=2ms/64], optionally per collection")
 	serverOptions.v.publicUrl = cmdServer.Flag.String("volume.publicUrl", "", "publicly accessible address")
+	serverOptions.v.shutdownTimeout = cmdServer.Flag.Int("volume.shutdownTimeout", 30, "seconds for the volume server to finish the requests in flight when stopped")
 
 	s3Options.filerBucketsPath = cmdServer.Flag.String("s3.filer.dir.buckets", "/buckets", "folder on filer to store all buckets")
 	s3Options.port = cmdServer.Flag.Int("s3.port", 8333, "s3 server http listen port")
 	s3Options.domainName = cmdServer.Flag.String("s3.domainName", "", "suffix of the host name, {bucket}.{domainName}")
 	s3Options.tlsPrivateKey = cmdServer.Flag.String("s3.key.file", "", "path to the TLS private key file")
 	s3Options.tlsCertificate = cmdServer.Flag.String("s3.cert.file", "", "path to the TLS certificate file")
+	s3Options.accessLogFlush = cmdServer.Flag.Duration("s3.accessLog.flushInterval", 5*time.Minute, "interval to write the server access logs into the target buckets")
+	s3Options.websiteDomain = cmdServer.Flag.String("s3.website.domainName", "", "suffix of the host name to serve the buckets as static websites, {bucket}.{website.domainName}")
+	s3Options.rateLimit.init(&cmdServer.Flag, "s3.")
 
 }
 
@@ -154,12 +191,10 @@ func runServer(cmd *Command, args []string) bool {
 	masterOptions.disableHttp = serverDisableHttp
 
 	filerAddress := fmt.Sprintf("%s:%d", *serverIp, *filerOptions.port)
+	volumeServerAddress := fmt.Sprintf("%s:%d", *serverIp, *serverOptions.v.port)
+	filerOptions.volumeServers = &volumeServerAddress
 	s3Options.filer = &filerAddress
 
-	if *filerOptions.defaultReplicaPlacement == "" {
-		*filerOptions.defaultReplicaPlacement = *masterOptions.defaultReplication
-	}
-
 	runtime.GOMAXPROCS(runtime.NumCPU())
 
 	folders := strings.Split(*volumeDataFolders, ",")
@@ -216,9 +251,10 @@ func runServer(cmd *Command, args []string) bool {
 			// start raftServer
 			myMasterAddress, peers := checkPeers(*serverIp, *masterOptions.port, *masterOptions.peers)
 			raftServer := weed_server.NewRaftServer(security.LoadClientTLS(viper.Sub("grpc"), "master"),
-				peers, myMasterAddress, *masterOptions.metaFolder, ms.Topo, *masterOptions.pulseSeconds)
+				peers, myMasterAddress, *masterOptions.metaFolder, ms.Topo, *masterOptions.pulseSeconds,
+				*masterOptions.snapshotInterval, *masterOptions.snapshotThreshold)
 			ms.SetRaftServer(raftServer)
-			r.HandleFunc("/cluster/status", raftServer.StatusHandler).Methods("GET")
+			r.HandleFunc("/cluster/status", ms.ClusterStatusHandler).Methods("GET")
 
 			// starting grpc server
 			grpcPort := *masterOptions.port + 10000
@@ -1,6 +1,9 @@
 package command
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/chrislusf/seaweedfs/weed/security"
 	"github.com/chrislusf/seaweedfs/weed/shell"
 	"github.com/chrislusf/seaweedfs/weed/util"
@@ -8,24 +11,33 @@ import (
 )
 
 var (
-	shellOptions shell.ShellOptions
+	shellOptions      shell.ShellOptions
+	shellCommands     *string
+	shellOutputFormat *string
 )
 
 func init() {
 	cmdShell.Run = runShell // break init cycle
 	shellOptions.Masters = cmdShell.Flag.String("master", "localhost:9333", "comma-separated master servers")
+	shellCommands = cmdShell.Flag.String("c", "", "run the semicolon-separated commands and exit, instead of the interactive prompt")
+	shellOutputFormat = cmdShell.Flag.String("o", shell.OutputText, "output format of the commands, text or json")
 }
 
 var cmdShell = &Command{
-	UsageLine: "shell",
+	UsageLine: "shell [-c \"volume.list\"] [-o json]",
 	Short:     "run interactive administrative commands",
 	Long: `run interactive administrative commands.
 
+  With -c, the commands are run without the interactive prompt, e.g. from cron,
+  and the shell exits with a non-zero status if any of them fails.
+  With -o json, each command prints one json object per line, as
+    {"command":"volume.list","result":{...},"error":"..."}
+  where the "result" is the structured result of the commands having one,
+  otherwise the "output" has the text lines printed by the command.
+
   `,
 }
 
-var ()
-
 func runShell(command *Command, args []string) bool {
 
 	util.LoadConfiguration("security", false)
@@ -35,6 +47,20 @@ func runShell(command *Command, args []string) bool {
 	shellOptions.FilerPort = 8888
 	shellOptions.Directory = "/"
 
+	if err := shell.CheckOutputFormat(*shellOutputFormat); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return false
+	}
+	shellOptions.OutputFormat = *shellOutputFormat
+
+	if *shellCommands != "" {
+		if err := shell.RunShellCommands(shellOptions, *shellCommands); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		return true
+	}
+
 	shell.RunShell(shellOptions)
 
 	return true
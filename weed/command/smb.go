@@ -0,0 +1,178 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+	"github.com/chrislusf/seaweedfs/weed/security"
+	"github.com/chrislusf/seaweedfs/weed/server"
+	"github.com/chrislusf/seaweedfs/weed/smb"
+	"github.com/chrislusf/seaweedfs/weed/util"
+	"github.com/spf13/viper"
+	"golang.org/x/net/webdav"
+	"google.golang.org/grpc"
+)
+
+var (
+	smbStandaloneOptions SmbOption
+)
+
+type SmbOption struct {
+	filer      *string
+	port       *int
+	collection *string
+}
+
+func init() {
+	cmdSmb.Run = runSmb // break init cycle
+	smbStandaloneOptions.filer = cmdSmb.Flag.String("filer", "localhost:8888", "filer server address")
+	smbStandaloneOptions.port = cmdSmb.Flag.Int("port", 445, "smb server listen port")
+	smbStandaloneOptions.collection = cmdSmb.Flag.String("collection", "", "collection to create the files")
+}
+
+var cmdSmb = &Command{
+	UsageLine: "smb -port=445 -filer=<ip:port>",
+	Short:     "<unstable> start a SMB/CIFS server that is backed by a filer",
+	Long: `start a SMB/CIFS server that is backed by a filer, so that Windows can use the shares natively.
+
+	The users and the shares are defined in smb.toml, which can be generated by "weed scaffold -config=smb".
+	Each user is mapped to a uid and gid for the files created by the user.
+	Each share is a directory on the filer, optionally read only or limited to some users.
+
+	The SMB 2.0.2 and 2.1 dialects are supported, with NTLMv2 authentication and signing.
+	SMB 3 encryption, oplocks, alternate data streams and byte range locks are not supported.
+	The file names are case sensitive as on the filer.
+
+`,
+}
+
+func runSmb(cmd *Command, args []string) bool {
+
+	util.LoadConfiguration("security", false)
+	util.LoadConfiguration("smb", true)
+
+	glog.V(0).Infof("Starting Seaweed SMB Server %s at port %d", util.VERSION, *smbStandaloneOptions.port)
+
+	return smbStandaloneOptions.startSmb()
+
+}
+
+func (so *SmbOption) startSmb() bool {
+
+	filerGrpcAddress, err := parseFilerGrpcAddress(*so.filer)
+	if err != nil {
+		glog.Fatal(err)
+		return false
+	}
+	grpcDialOption := security.LoadClientTLS(viper.Sub("grpc"), "client")
+
+	users, shares := loadSmbUsersAndShares(viper.GetViper())
+	if len(shares) == 0 {
+		glog.Fatalf("no shares are defined in smb.toml")
+	}
+
+	v := viper.GetViper()
+	v.SetDefault("smb.server_name", "SEAWEEDFS")
+	v.SetDefault("smb.domain", "WORKGROUP")
+
+	smbServer := smb.NewServer(&smb.Option{
+		ServerName: v.GetString("smb.server_name"),
+		Domain:     v.GetString("smb.domain"),
+		Users:      users,
+		Shares:     shares,
+		NewFileSystem: func(user *smb.User) webdav.FileSystem {
+			fs, err := weed_server.NewWebDavFileSystem(&weed_server.WebDavOption{
+				Filer:            *so.filer,
+				FilerGrpcAddress: filerGrpcAddress,
+				GrpcDialOption:   grpcDialOption,
+				Collection:       *so.collection,
+				Uid:              user.Uid,
+				Gid:              user.Gid,
+			})
+			if err != nil {
+				glog.Fatalf("SMB Server file system for %s: %v", user.Name, err)
+			}
+			return fs
+		},
+		DiskUsage: func() (total, free uint64, err error) {
+			return filerDiskUsage(filerGrpcAddress, grpcDialOption, *so.collection)
+		},
+	})
+
+	listenAddress := fmt.Sprintf(":%d", *so.port)
+	smbListener, err := util.NewListener(listenAddress, 0)
+	if err != nil {
+		glog.Fatalf("SMB Server listener on %s error: %v", listenAddress, err)
+	}
+
+	glog.V(0).Infof("Start Seaweed SMB Server %s at port %d", util.VERSION, *so.port)
+	if err = smbServer.Serve(smbListener); err != nil {
+		glog.Fatalf("SMB Server Fail to serve: %v", err)
+	}
+
+	return true
+
+}
+
+func loadSmbUsersAndShares(v *viper.Viper) (users []*smb.User, shares []*smb.Share) {
+
+	var userNames, shareNames []string
+	for name := range v.GetStringMap("smb.users") {
+		userNames = append(userNames, name)
+	}
+	for name := range v.GetStringMap("smb.shares") {
+		shareNames = append(shareNames, name)
+	}
+	sort.Strings(userNames)
+	sort.Strings(shareNames)
+
+	for _, name := range userNames {
+		sub := v.Sub("smb.users." + name)
+		users = append(users, &smb.User{
+			Name:     name,
+			Password: sub.GetString("password"),
+			Uid:      uint32(sub.GetInt("uid")),
+			Gid:      uint32(sub.GetInt("gid")),
+		})
+	}
+	for _, name := range shareNames {
+		sub := v.Sub("smb.shares." + name)
+		sub.SetDefault("path", "/")
+		shares = append(shares, &smb.Share{
+			Name:     name,
+			Path:     sub.GetString("path"),
+			ReadOnly: sub.GetBool("read_only"),
+			Users:    sub.GetStringSlice("users"),
+		})
+		glog.V(0).Infof("smb share %s => %s", name, sub.GetString("path"))
+	}
+
+	return
+}
+
+func filerDiskUsage(filerGrpcAddress string, grpcDialOption grpc.DialOption, collection string) (total, free uint64, err error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err = util.WithCachedGrpcClient(ctx, func(grpcConnection *grpc.ClientConn) error {
+		client := filer_pb.NewSeaweedFilerClient(grpcConnection)
+		resp, err := client.Statistics(ctx, &filer_pb.StatisticsRequest{
+			Collection: collection,
+		})
+		if err != nil {
+			return err
+		}
+		total = resp.TotalSize
+		if resp.TotalSize > resp.UsedSize {
+			free = resp.TotalSize - resp.UsedSize
+		}
+		return nil
+	}, filerGrpcAddress, grpcDialOption)
+
+	return
+}
@@ -1,12 +1,14 @@
 package command
 
 import (
+	"context"
 	"net/http"
 	"os"
 	"runtime"
 	"runtime/pprof"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/chrislusf/seaweedfs/weed/security"
@@ -17,6 +19,7 @@ import (
 	"github.com/chrislusf/seaweedfs/weed/server"
 	"github.com/chrislusf/seaweedfs/weed/storage"
 	"github.com/chrislusf/seaweedfs/weed/util"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 )
 
@@ -25,25 +28,33 @@ var (
 )
 
 type VolumeServerOptions struct {
-	port                  *int
-	publicPort            *int
-	folders               []string
-	folderMaxLimits       []int
-	ip                    *string
-	publicUrl             *string
-	bindIp                *string
-	masters               *string
-	pulseSeconds          *int
-	idleConnectionTimeout *int
-	dataCenter            *string
-	rack                  *string
-	whiteList             []string
-	indexType             *string
-	fixJpgOrientation     *bool
-	readRedirect          *bool
-	cpuProfile            *string
-	memProfile            *string
-	compactionMBPerSecond *int
+	port                   *int
+	publicPort             *int
+	folders                []string
+	folderMaxLimits        []int
+	ip                     *string
+	publicUrl              *string
+	bindIp                 *string
+	masters                *string
+	pulseSeconds           *int
+	idleConnectionTimeout  *int
+	dataCenter             *string
+	rack                   *string
+	whiteList              []string
+	indexType              *string
+	fixJpgOrientation      *bool
+	readRedirect           *bool
+	cpuProfile             *string
+	memProfile             *string
+	compactionMBPerSecond  *int
+	maintenanceMBPerSecond *int
+	fsync                  *string
+	readOnly               *bool
+	fileSizeLimitMB        *int
+	labels                 *string
+	shutdownTimeout        *int
+	// hands off the listeners to a new process on SIGUSR2, only for the standalone volume server
+	handoff bool
 }
 
 func init() {
@@ -58,12 +69,19 @@ func init() {
 	v.idleConnectionTimeout = cmdVolume.Flag.Int("idleTimeout", 30, "connection idle seconds")
 	v.dataCenter = cmdVolume.Flag.String("dataCenter", "", "current volume server's data center name")
 	v.rack = cmdVolume.Flag.String("rack", "", "current volume server's rack name")
-	v.indexType = cmdVolume.Flag.String("index", "memory", "Choose [memory|leveldb|leveldbMedium|leveldbLarge] mode for memory~performance balance.")
+	v.indexType = cmdVolume.Flag.String("index", "memory", "Choose [memory|leveldb|leveldbMedium|leveldbLarge|mmap] mode for memory~performance balance. mmap starts fast and keeps only the hot index pages in memory.")
 	v.fixJpgOrientation = cmdVolume.Flag.Bool("images.fix.orientation", false, "Adjust jpg orientation when uploading.")
 	v.readRedirect = cmdVolume.Flag.Bool("read.redirect", true, "Redirect moved or non-local volumes.")
 	v.cpuProfile = cmdVolume.Flag.String("cpuprofile", "", "cpu profile output file")
 	v.memProfile = cmdVolume.Flag.String("memprofile", "", "memory profile output file")
 	v.compactionMBPerSecond = cmdVolume.Flag.Int("compactionMBps", 0, "limit background compaction or copying speed in mega bytes per second")
+	v.maintenanceMBPerSecond = cmdVolume.Flag.Int("maintenanceMBps", 0, "limit the replica repairs, volume moves, and ec encoding and rebuilding of this volume server to mega bytes per second in total, 0 for no limit")
+	v.fsync = cmdVolume.Flag.String("fsync", "never", "when to fsync the writes: [never|per-write|interval=100ms|group=2ms/64], optionally per collection, e.g. interval=100ms,logs:never")
+	v.readOnly = cmdVolume.Flag.Bool("readOnly", false, "only serve the reads of the existing volumes, as an archival volume server, disabling all writes and volume changes")
+	v.labels = cmdVolume.Flag.String("labels", "", "comma separated labels for the volume placement, e.g. zone=eu,class=archive, selected by the labelSelector of the assign and the volume growth")
+	v.fileSizeLimitMB = cmdVolume.Flag.Int("fileSizeLimitMB", 0, "reject the uploads of larger files, to be split into chunks by the filer. 0 for the limit of a single needle, about 4GB")
+	v.shutdownTimeout = cmdVolume.Flag.Int("shutdownTimeout", 30, "seconds to finish the requests in flight when stopped by SIGTERM, or restarted by SIGUSR2")
+	v.handoff = true
 }
 
 var cmdVolume = &Command{
@@ -71,6 +89,14 @@ var cmdVolume = &Command{
 	Short:     "start a volume server",
 	Long: `start a volume server to provide storage spaces
 
+  On SIGTERM, the volume server stops taking new writes, unregisters from the master,
+  finishes the requests in flight, and syncs the volumes before exiting.
+
+  On SIGUSR2, the volume server starts a new process of the same command line, e.g. after
+  the weed binary is upgraded, and hands off the listening ports to it before shutting down
+  the same way. The new process loads the volumes after the old process exits, while the new
+  connections wait to be accepted.
+
   `,
 }
 
@@ -144,10 +170,25 @@ func (v VolumeServerOptions) startVolumeServer(volumeFolders, maxVolumeCounts, v
 		volumeNeedleMapKind = storage.NeedleMapLevelDbMedium
 	case "leveldbLarge":
 		volumeNeedleMapKind = storage.NeedleMapLevelDbLarge
+	case "mmap":
+		volumeNeedleMapKind = storage.NeedleMapMmap
+	}
+
+	fsyncPolicies, err := storage.ParseFsyncPolicies(*v.fsync)
+	if err != nil {
+		glog.Fatalf("-fsync: %v", err)
+	}
+
+	labels, err := storage.ParseLabels(*v.labels)
+	if err != nil {
+		glog.Fatalf("-labels: %v", err)
 	}
 
 	masters := *v.masters
 
+	// the volumes are loaded only after the old process handing off the listeners stops changing them
+	util.WaitForHandoff()
+
 	volumeServer := weed_server.NewVolumeServer(volumeMux, publicVolumeMux,
 		*v.ip, *v.port, *v.publicUrl,
 		v.folders, v.folderMaxLimits,
@@ -156,53 +197,115 @@ func (v VolumeServerOptions) startVolumeServer(volumeFolders, maxVolumeCounts, v
 		v.whiteList,
 		*v.fixJpgOrientation, *v.readRedirect,
 		*v.compactionMBPerSecond,
+		*v.maintenanceMBPerSecond,
+		fsyncPolicies,
+		*v.readOnly,
+		*v.fileSizeLimitMB,
+		labels,
 	)
 
 	listeningAddress := *v.bindIp + ":" + strconv.Itoa(*v.port)
 	glog.V(0).Infof("Start Seaweed volume server %s at %s", util.VERSION, listeningAddress)
-	listener, e := util.NewListener(listeningAddress, time.Duration(*v.idleConnectionTimeout)*time.Second)
+	listener, e := util.NewHandoffListener("volume.http", listeningAddress, time.Duration(*v.idleConnectionTimeout)*time.Second)
 	if e != nil {
 		glog.Fatalf("Volume server listener error:%v", e)
 	}
+	httpS := &http.Server{Handler: volumeMux}
+	httpServers := []*http.Server{httpS}
 	if isSeperatedPublicPort {
 		publicListeningAddress := *v.bindIp + ":" + strconv.Itoa(*v.publicPort)
 		glog.V(0).Infoln("Start Seaweed volume server", util.VERSION, "public at", publicListeningAddress)
-		publicListener, e := util.NewListener(publicListeningAddress, time.Duration(*v.idleConnectionTimeout)*time.Second)
+		publicListener, e := util.NewHandoffListener("volume.public", publicListeningAddress, time.Duration(*v.idleConnectionTimeout)*time.Second)
 		if e != nil {
 			glog.Fatalf("Volume server listener error:%v", e)
 		}
+		publicHttpS := &http.Server{Handler: publicVolumeMux}
+		httpServers = append(httpServers, publicHttpS)
 		go func() {
-			if e := http.Serve(publicListener, publicVolumeMux); e != nil {
+			if e := publicHttpS.Serve(publicListener); e != nil && e != http.ErrServerClosed {
 				glog.Fatalf("Volume server fail to serve public: %v", e)
 			}
 		}()
 	}
 
-	util.OnInterrupt(func() {
-		volumeServer.Shutdown()
-		pprof.StopCPUProfile()
-	})
-
 	// starting grpc server
 	grpcPort := *v.port + 10000
-	grpcL, err := util.NewListener(*v.bindIp+":"+strconv.Itoa(grpcPort), 0)
+	grpcL, err := util.NewHandoffListener("volume.grpc", *v.bindIp+":"+strconv.Itoa(grpcPort), 0)
 	if err != nil {
 		glog.Fatalf("failed to listen on grpc port %d: %v", grpcPort, err)
 	}
-	grpcS := util.NewGrpcServer(security.LoadServerTLS(viper.Sub("grpc"), "volume"))
+	grpcOptions := []grpc.ServerOption{security.LoadServerTLS(viper.Sub("grpc"), "volume")}
+	if *v.readOnly {
+		grpcOptions = append(grpcOptions, weed_server.ReadOnlyGrpcOptions()...)
+	}
+	grpcS := util.NewGrpcServer(grpcOptions...)
 	volume_server_pb.RegisterVolumeServerServer(grpcS, volumeServer)
 	reflection.Register(grpcS)
 	go grpcS.Serve(grpcL)
 
+	var shutdownOnce sync.Once
+	shutdown := func() {
+		shutdownOnce.Do(func() {
+			volumeServer.StopServing()
+			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(*v.shutdownTimeout)*time.Second)
+			defer cancel()
+			stopServers(ctx, httpServers, grpcS)
+			volumeServer.Shutdown()
+			pprof.StopCPUProfile()
+		})
+	}
+	util.OnInterrupt(shutdown)
+	if v.handoff {
+		util.OnHandoff(func() (keepServing bool) {
+			if volumeNeedleMapKind != storage.NeedleMapInMemory {
+				// the needle maps on disk can not be opened by both processes
+				shutdown()
+				return false
+			}
+			// keep serving the reads while the new process loads the volumes
+			volumeServer.ReleaseVolumes()
+			return true
+		}, shutdown)
+	}
+	// the old process shuts down after the listeners are taken over
+	util.HandoffReady()
+
 	if viper.GetString("https.volume.key") != "" {
-		if e := http.ServeTLS(listener, volumeMux,
-			viper.GetString("https.volume.cert"), viper.GetString("https.volume.key")); e != nil {
-			glog.Fatalf("Volume server fail to serve: %v", e)
-		}
+		e = httpS.ServeTLS(listener, viper.GetString("https.volume.cert"), viper.GetString("https.volume.key"))
 	} else {
-		if e := http.Serve(listener, volumeMux); e != nil {
-			glog.Fatalf("Volume server fail to serve: %v", e)
-		}
+		e = httpS.Serve(listener)
+	}
+	if e != nil && e != http.ErrServerClosed {
+		glog.Fatalf("Volume server fail to serve: %v", e)
 	}
+	// the shutdown exits the process
+	select {}
 
 }
+
+// stopServers waits for the requests in flight until the context is done, and then drops them
+func stopServers(ctx context.Context, httpServers []*http.Server, grpcS *grpc.Server) {
+	var wg sync.WaitGroup
+	for _, httpS := range httpServers {
+		wg.Add(1)
+		go func(httpS *http.Server) {
+			defer wg.Done()
+			if err := httpS.Shutdown(ctx); err != nil {
+				glog.V(0).Infof("stop the http server: %v", err)
+				httpS.Close()
+			}
+		}(httpS)
+	}
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcS.GracefulStop()
+		close(grpcStopped)
+	}()
+	select {
+	case <-grpcStopped:
+	case <-ctx.Done():
+		glog.V(0).Infof("stop the grpc server: %v", ctx.Err())
+		grpcS.Stop()
+	}
+	wg.Wait()
+}
@@ -44,11 +44,17 @@ func main() {
 	fmt.Printf("target: %v\n", mountPoint)
 
 	nouser := true
+	caseInsensitive := false
+	chunkLocality := false
 	for _, option := range strings.Split(optionsString, ",") {
 		fmt.Printf("option: %v\n", option)
 		switch option {
 		case "user":
 			nouser = false
+		case "caseinsensitive":
+			caseInsensitive = true
+		case "chunklocality":
+			chunkLocality = true
 		}
 	}
 
@@ -64,7 +70,8 @@ func main() {
 
 	command.RunMount(
 		filer, "/"+filerPath, mountPoint, "", "000", "",
-		4, !nouser, 0, 1000000)
+		4, !nouser, 0, 1000000, caseInsensitive, chunkLocality,
+		os.TempDir(), 0, 0, 0, 4)
 
 }
 
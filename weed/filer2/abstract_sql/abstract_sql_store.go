@@ -166,3 +166,16 @@ func (store *AbstractSqlStore) ListDirectoryEntries(ctx context.Context, fullpat
 
 	return entries, nil
 }
+
+// Stats reports the usage of the connection pool
+func (store *AbstractSqlStore) Stats() map[string]float64 {
+	s := store.DB.Stats()
+	return map[string]float64{
+		"max_open_connections": float64(s.MaxOpenConnections),
+		"open_connections":     float64(s.OpenConnections),
+		"in_use_connections":   float64(s.InUse),
+		"idle_connections":     float64(s.Idle),
+		"wait_total":           float64(s.WaitCount),
+		"wait_seconds":         s.WaitDuration.Seconds(),
+	}
+}
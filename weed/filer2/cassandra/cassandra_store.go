@@ -3,6 +3,11 @@ package cassandra
 import (
 	"context"
 	"fmt"
+	"hash/crc32"
+	"sort"
+	"sync"
+	"time"
+
 	"github.com/chrislusf/seaweedfs/weed/filer2"
 	"github.com/chrislusf/seaweedfs/weed/glog"
 	"github.com/chrislusf/seaweedfs/weed/util"
@@ -13,9 +18,16 @@ func init() {
 	filer2.Stores = append(filer2.Stores, &CassandraStore{})
 }
 
+// the statements in one unlogged batch, all for the same partition
+const maxBatchStatements = 32
+
 type CassandraStore struct {
-	cluster *gocql.ClusterConfig
-	session *gocql.Session
+	cluster           *gocql.ClusterConfig
+	session           *gocql.Session
+	strongConsistency gocql.Consistency
+	// directoryBuckets splits the entries of one directory into multiple partitions, by the hash of the name.
+	// 0 is for the table without the bucket column.
+	directoryBuckets int
 }
 
 func (store *CassandraStore) GetName() string {
@@ -26,13 +38,32 @@ func (store *CassandraStore) Initialize(configuration util.Configuration) (err e
 	return store.initialize(
 		configuration.GetString("keyspace"),
 		configuration.GetStringSlice("hosts"),
+		configuration.GetString("local_dc"),
+		configuration.GetString("strong_read_consistency"),
+		configuration.GetInt("directory_buckets"),
 	)
 }
 
-func (store *CassandraStore) initialize(keyspace string, hosts []string) (err error) {
+func (store *CassandraStore) initialize(keyspace string, hosts []string, localDC string, strongReadConsistency string, directoryBuckets int) (err error) {
+	store.strongConsistency = gocql.LocalQuorum
+	if strongReadConsistency != "" {
+		if store.strongConsistency, err = gocql.ParseConsistencyWrapper(strongReadConsistency); err != nil {
+			return fmt.Errorf("cassandra strong_read_consistency: %v", err)
+		}
+	}
+	if directoryBuckets < 0 {
+		return fmt.Errorf("cassandra directory_buckets %d should not be negative", directoryBuckets)
+	}
+	store.directoryBuckets = directoryBuckets
 	store.cluster = gocql.NewCluster(hosts...)
 	store.cluster.Keyspace = keyspace
 	store.cluster.Consistency = gocql.LocalQuorum
+	// send each query to a replica of its partition, saving one hop
+	fallback := gocql.RoundRobinHostPolicy()
+	if localDC != "" {
+		fallback = gocql.DCAwareRoundRobinPolicy(localDC)
+	}
+	store.cluster.PoolConfig.HostSelectionPolicy = gocql.TokenAwareHostPolicy(fallback)
 	store.session, err = store.cluster.CreateSession()
 	if err != nil {
 		glog.V(0).Infof("Failed to open cassandra store, hosts %v, keyspace %s", hosts, keyspace)
@@ -40,6 +71,38 @@ func (store *CassandraStore) initialize(keyspace string, hosts []string) (err er
 	return
 }
 
+// readConsistency is the consistency level asked by the request, or the default one.
+// The writes are at LOCAL_QUORUM, so the strong reads at LOCAL_QUORUM or above see the writes from any filer
+// in the same data center, and the stale replicas are repaired by the read.
+func (store *CassandraStore) readConsistency(ctx context.Context, defaultConsistency gocql.Consistency) gocql.Consistency {
+	switch filer2.ReadConsistencyFromContext(ctx) {
+	case filer2.ReadConsistencyStrong:
+		return store.strongConsistency
+	case filer2.ReadConsistencyEventual:
+		return gocql.One
+	}
+	return defaultConsistency
+}
+
+func (store *CassandraStore) bucket(name string) int {
+	return int(crc32.ChecksumIEEE([]byte(name)) % uint32(store.directoryBuckets))
+}
+
+// partitionKey is the key columns besides the name
+func (store *CassandraStore) partitionKey(dir, name string) []interface{} {
+	if store.directoryBuckets == 0 {
+		return []interface{}{dir}
+	}
+	return []interface{}{dir, store.bucket(name)}
+}
+
+func (store *CassandraStore) keyColumns() string {
+	if store.directoryBuckets == 0 {
+		return "directory=?"
+	}
+	return "directory=? AND bucket=?"
+}
+
 func (store *CassandraStore) BeginTransaction(ctx context.Context) (context.Context, error) {
 	return ctx, nil
 }
@@ -50,23 +113,99 @@ func (store *CassandraStore) RollbackTransaction(ctx context.Context) error {
 	return nil
 }
 
-func (store *CassandraStore) InsertEntry(ctx context.Context, entry *filer2.Entry) (err error) {
+// insertStatement returns the cql and the values to insert the entry
+func (store *CassandraStore) insertStatement(entry *filer2.Entry, now time.Time) (string, []interface{}, error) {
 
 	dir, name := entry.FullPath.DirAndName()
 	meta, err := entry.EncodeAttributesAndChunks()
 	if err != nil {
-		return fmt.Errorf("encode %s: %s", entry.FullPath, err)
+		return "", nil, fmt.Errorf("encode %s: %s", entry.FullPath, err)
 	}
 
-	if err := store.session.Query(
-		"INSERT INTO filemeta (directory,name,meta) VALUES(?,?,?) USING TTL ? ",
-		dir, name, meta, entry.TtlSec).Exec(); err != nil {
+	if store.directoryBuckets == 0 {
+		return "INSERT INTO filemeta (directory,name,meta) VALUES(?,?,?) USING TTL ? ",
+			[]interface{}{dir, name, meta, rowTtl(entry, now)}, nil
+	}
+	return "INSERT INTO filemeta (directory,bucket,name,meta) VALUES(?,?,?,?) USING TTL ? ",
+		[]interface{}{dir, store.bucket(name), name, meta, rowTtl(entry, now)}, nil
+}
+
+// rowTtl is the remaining filer TTL, so that updating an entry does not extend its expiry
+func rowTtl(entry *filer2.Entry, now time.Time) int32 {
+	if entry.TtlSec <= 0 {
+		return 0
+	}
+	if entry.Crtime.Unix() <= 0 {
+		return entry.TtlSec
+	}
+	remaining := int64(entry.TtlSec) - int64(now.Sub(entry.Crtime)/time.Second)
+	if remaining < 1 {
+		// 0 would be no expiry
+		return 1
+	}
+	if remaining > int64(entry.TtlSec) {
+		return entry.TtlSec
+	}
+	return int32(remaining)
+}
+
+func (store *CassandraStore) InsertEntry(ctx context.Context, entry *filer2.Entry) (err error) {
+
+	cql, values, err := store.insertStatement(entry, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if err := store.session.Query(cql, values...).Exec(); err != nil {
 		return fmt.Errorf("insert %s: %s", entry.FullPath, err)
 	}
 
 	return nil
 }
 
+// InsertEntries inserts the entries of each partition in unlogged batches,
+// which are sent to a replica of the partition.
+func (store *CassandraStore) InsertEntries(ctx context.Context, entries []*filer2.Entry) (err error) {
+
+	now := time.Now()
+	partitions := make(map[string][]*filer2.Entry)
+	var keys []string
+	for _, entry := range entries {
+		dir, name := entry.FullPath.DirAndName()
+		key := dir
+		if store.directoryBuckets > 0 {
+			key = fmt.Sprintf("%s\x00%d", dir, store.bucket(name))
+		}
+		if _, found := partitions[key]; !found {
+			keys = append(keys, key)
+		}
+		partitions[key] = append(partitions[key], entry)
+	}
+
+	for _, key := range keys {
+		partition := partitions[key]
+		for start := 0; start < len(partition); start += maxBatchStatements {
+			stop := start + maxBatchStatements
+			if stop > len(partition) {
+				stop = len(partition)
+			}
+			batch := store.session.NewBatch(gocql.UnloggedBatch)
+			for _, entry := range partition[start:stop] {
+				cql, values, err := store.insertStatement(entry, now)
+				if err != nil {
+					return err
+				}
+				batch.Query(cql, values...)
+			}
+			if err := store.session.ExecuteBatch(batch); err != nil {
+				return fmt.Errorf("insert %d entries under %s: %v", stop-start, partition[start].FullPath, err)
+			}
+		}
+	}
+
+	return nil
+}
+
 func (store *CassandraStore) UpdateEntry(ctx context.Context, entry *filer2.Entry) (err error) {
 
 	return store.InsertEntry(ctx, entry)
@@ -77,8 +216,8 @@ func (store *CassandraStore) FindEntry(ctx context.Context, fullpath filer2.Full
 	dir, name := fullpath.DirAndName()
 	var data []byte
 	if err := store.session.Query(
-		"SELECT meta FROM filemeta WHERE directory=? AND name=?",
-		dir, name).Consistency(gocql.One).Scan(&data); err != nil {
+		"SELECT meta FROM filemeta WHERE "+store.keyColumns()+" AND name=?",
+		append(store.partitionKey(dir, name), name)...).Consistency(store.readConsistency(ctx, gocql.One)).Scan(&data); err != nil {
 		if err != gocql.ErrNotFound {
 			return nil, filer2.ErrNotFound
 		}
@@ -104,8 +243,8 @@ func (store *CassandraStore) DeleteEntry(ctx context.Context, fullpath filer2.Fu
 	dir, name := fullpath.DirAndName()
 
 	if err := store.session.Query(
-		"DELETE FROM filemeta WHERE directory=? AND name=?",
-		dir, name).Exec(); err != nil {
+		"DELETE FROM filemeta WHERE "+store.keyColumns()+" AND name=?",
+		append(store.partitionKey(dir, name), name)...).Exec(); err != nil {
 		return fmt.Errorf("delete %s : %v", fullpath, err)
 	}
 
@@ -115,14 +254,53 @@ func (store *CassandraStore) DeleteEntry(ctx context.Context, fullpath filer2.Fu
 func (store *CassandraStore) ListDirectoryEntries(ctx context.Context, fullpath filer2.FullPath, startFileName string, inclusive bool,
 	limit int) (entries []*filer2.Entry, err error) {
 
-	cqlStr := "SELECT NAME, meta FROM filemeta WHERE directory=? AND name>? ORDER BY NAME ASC LIMIT ?"
+	if store.directoryBuckets == 0 {
+		return store.listPartition(ctx, fullpath, []interface{}{string(fullpath)}, startFileName, inclusive, limit)
+	}
+
+	// each bucket is sorted by name, so the first entries of the directory are among the first entries of the buckets
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	for b := 0; b < store.directoryBuckets; b++ {
+		wg.Add(1)
+		go func(b int) {
+			defer wg.Done()
+			bucketEntries, listErr := store.listPartition(ctx, fullpath, []interface{}{string(fullpath), b}, startFileName, inclusive, limit)
+			lock.Lock()
+			defer lock.Unlock()
+			if listErr != nil {
+				err = listErr
+			}
+			entries = append(entries, bucketEntries...)
+		}(b)
+	}
+	wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].FullPath < entries[j].FullPath
+	})
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	return entries, nil
+}
+
+func (store *CassandraStore) listPartition(ctx context.Context, fullpath filer2.FullPath, partitionKey []interface{}, startFileName string, inclusive bool,
+	limit int) (entries []*filer2.Entry, err error) {
+
+	cqlStr := "SELECT NAME, meta FROM filemeta WHERE " + store.keyColumns() + " AND name>? ORDER BY NAME ASC LIMIT ?"
 	if inclusive {
-		cqlStr = "SELECT NAME, meta FROM filemeta WHERE directory=? AND name>=? ORDER BY NAME ASC LIMIT ?"
+		cqlStr = "SELECT NAME, meta FROM filemeta WHERE " + store.keyColumns() + " AND name>=? ORDER BY NAME ASC LIMIT ?"
 	}
 
 	var data []byte
 	var name string
-	iter := store.session.Query(cqlStr, string(fullpath), startFileName, limit).Iter()
+	iter := store.session.Query(cqlStr, append(partitionKey, startFileName, limit)...).
+		Consistency(store.readConsistency(ctx, store.cluster.Consistency)).Iter()
 	for iter.Scan(&name, &data) {
 		entry := &filer2.Entry{
 			FullPath: filer2.NewFullPath(string(fullpath), name),
@@ -12,6 +12,11 @@ var (
 )
 
 func (f *Filer) LoadConfiguration(config *viper.Viper) {
+	f.SetStore(LoadStore(config))
+}
+
+// LoadStore initializes the one store enabled in the configuration, and exits if none is enabled
+func LoadStore(config *viper.Viper) FilerStore {
 
 	validateOneEnabledStore(config)
 
@@ -22,9 +27,8 @@ func (f *Filer) LoadConfiguration(config *viper.Viper) {
 				glog.Fatalf("Failed to initialize store for %s: %+v",
 					store.GetName(), err)
 			}
-			f.SetStore(store)
 			glog.V(0).Infof("Configure filer for %s", store.GetName())
-			return
+			return store
 		}
 	}
 
@@ -35,6 +39,7 @@ func (f *Filer) LoadConfiguration(config *viper.Viper) {
 	}
 
 	os.Exit(-1)
+	return nil
 }
 
 func validateOneEnabledStore(config *viper.Viper) {
@@ -20,6 +20,11 @@ type Attr struct {
 	UserName      string
 	GroupNames    []string
 	SymlinkTarget string
+	RetentionMode string    // RetentionModeGovernance or RetentionModeCompliance
+	RetainUntil   time.Time // can not be deleted or overwritten until this time
+	LegalHold     bool      // can not be deleted or overwritten until the legal hold is removed
+	FileSize      uint64    // the size set by truncating, may be beyond the chunks
+	ExpireAt      time.Time // the entry is deleted when expired, independent from the TtlSec of the chunks
 }
 
 func (attr Attr) IsDirectory() bool {
@@ -33,10 +38,17 @@ type Entry struct {
 
 	// the following is for files
 	Chunks []*filer_pb.FileChunk `json:"chunks,omitempty"`
+
+	// the extended attributes set by the gateways, like the s3 bucket policy
+	Extended map[string][]byte `json:"extended,omitempty"`
 }
 
 func (entry *Entry) Size() uint64 {
-	return TotalSize(entry.Chunks)
+	size := TotalSize(entry.Chunks)
+	if entry.FileSize > size {
+		return entry.FileSize
+	}
+	return size
 }
 
 func (entry *Entry) Timestamp() time.Time {
@@ -56,6 +68,7 @@ func (entry *Entry) ToProtoEntry() *filer_pb.Entry {
 		IsDirectory: entry.IsDirectory(),
 		Attributes:  EntryAttributeToPb(entry),
 		Chunks:      entry.Chunks,
+		Extended:    entry.Extended,
 	}
 }
 
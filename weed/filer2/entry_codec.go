@@ -13,6 +13,7 @@ func (entry *Entry) EncodeAttributesAndChunks() ([]byte, error) {
 	message := &filer_pb.Entry{
 		Attributes: EntryAttributeToPb(entry),
 		Chunks:     entry.Chunks,
+		Extended:   entry.Extended,
 	}
 	return proto.Marshal(message)
 }
@@ -28,12 +29,22 @@ func (entry *Entry) DecodeAttributesAndChunks(blob []byte) error {
 	entry.Attr = PbToEntryAttribute(message.Attributes)
 
 	entry.Chunks = message.Chunks
+	entry.Extended = message.Extended
 
 	return nil
 }
 
 func EntryAttributeToPb(entry *Entry) *filer_pb.FuseAttributes {
 
+	var retainUntil int64
+	if !entry.Attr.RetainUntil.IsZero() {
+		retainUntil = entry.Attr.RetainUntil.Unix()
+	}
+	var expireAt int64
+	if !entry.Attr.ExpireAt.IsZero() {
+		expireAt = entry.Attr.ExpireAt.Unix()
+	}
+
 	return &filer_pb.FuseAttributes{
 		Crtime:        entry.Attr.Crtime.Unix(),
 		Mtime:         entry.Attr.Mtime.Unix(),
@@ -47,6 +58,11 @@ func EntryAttributeToPb(entry *Entry) *filer_pb.FuseAttributes {
 		UserName:      entry.Attr.UserName,
 		GroupName:     entry.Attr.GroupNames,
 		SymlinkTarget: entry.Attr.SymlinkTarget,
+		RetentionMode: entry.Attr.RetentionMode,
+		RetainUntil:   retainUntil,
+		LegalHold:     entry.Attr.LegalHold,
+		FileSize:      entry.Attr.FileSize,
+		ExpireAt:      expireAt,
 	}
 }
 
@@ -66,6 +82,15 @@ func PbToEntryAttribute(attr *filer_pb.FuseAttributes) Attr {
 	t.UserName = attr.UserName
 	t.GroupNames = attr.GroupName
 	t.SymlinkTarget = attr.SymlinkTarget
+	t.RetentionMode = attr.RetentionMode
+	if attr.RetainUntil > 0 {
+		t.RetainUntil = time.Unix(attr.RetainUntil, 0)
+	}
+	t.LegalHold = attr.LegalHold
+	t.FileSize = attr.FileSize
+	if attr.ExpireAt > 0 {
+		t.ExpireAt = time.Unix(attr.ExpireAt, 0)
+	}
 
 	return t
 }
@@ -1,8 +1,8 @@
 package filer2
 
 import (
+	"crypto/md5"
 	"fmt"
-	"hash/fnv"
 	"sort"
 	"sync"
 
@@ -19,16 +19,43 @@ func TotalSize(chunks []*filer_pb.FileChunk) (size uint64) {
 	return
 }
 
+// ETag identifies the content of the file chunks.
+// A whole single chunk keeps its own etag. Otherwise the chunk etags are hashed in the order of the file offsets,
+// with the ranges, and the file ids for the chunks without etags, like the ones cut by truncating.
+// Like the s3 multipart uploads, the number of chunks follows the hash.
 func ETag(chunks []*filer_pb.FileChunk) (etag string) {
-	if len(chunks) == 1 {
+	return chunksETag(chunks, true)
+}
+
+// ContentETag is like ETag, but leaves out the file ids of the chunks without etags.
+// It compares the copies of the entry on other clusters, where the same content is stored in other file ids.
+func ContentETag(chunks []*filer_pb.FileChunk) (etag string) {
+	return chunksETag(chunks, false)
+}
+
+func chunksETag(chunks []*filer_pb.FileChunk, withFileIds bool) (etag string) {
+	if len(chunks) == 1 && chunks[0].ETag != "" && chunks[0].Offset == 0 {
 		return chunks[0].ETag
 	}
 
-	h := fnv.New32a()
-	for _, c := range chunks {
-		h.Write([]byte(c.ETag))
+	sorted := make([]*filer_pb.FileChunk, len(chunks))
+	copy(sorted, chunks)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Offset != sorted[j].Offset {
+			return sorted[i].Offset < sorted[j].Offset
+		}
+		return sorted[i].Mtime < sorted[j].Mtime
+	})
+
+	h := md5.New()
+	for _, c := range sorted {
+		chunkTag := c.ETag
+		if chunkTag == "" && withFileIds {
+			chunkTag = c.GetFileIdString()
+		}
+		fmt.Fprintf(h, "%s:%d:%d\n", chunkTag, c.Offset, c.Size)
 	}
-	return fmt.Sprintf("%x", h.Sum32())
+	return fmt.Sprintf("%x-%d", h.Sum(nil), len(chunks))
 }
 
 func CompactFileChunks(chunks []*filer_pb.FileChunk) (compacted, garbage []*filer_pb.FileChunk) {
@@ -71,6 +98,8 @@ type ChunkView struct {
 	Size        uint64
 	LogicOffset int64
 	IsFullChunk bool
+	Compression string
+	CipherKey   []byte
 }
 
 func ViewFromChunks(chunks []*filer_pb.FileChunk, offset int64, size int) (views []*ChunkView) {
@@ -86,14 +115,20 @@ func ViewFromVisibleIntervals(visibles []VisibleInterval, offset int64, size int
 	stop := offset + int64(size)
 
 	for _, chunk := range visibles {
-		if chunk.start <= offset && offset < chunk.stop && offset < stop {
+		// the ranges not covered by the visible intervals are holes, and skipped
+		if offset < chunk.stop && chunk.start < stop {
+			if offset < chunk.start {
+				offset = chunk.start
+			}
 			isFullChunk := chunk.isFullChunk && chunk.start == offset && chunk.stop <= stop
 			views = append(views, &ChunkView{
 				FileId:      chunk.fileId,
-				Offset:      offset - chunk.start, // offset is the data starting location in this file id
+				Offset:      offset - chunk.chunkOffset, // offset is the data starting location in this file id
 				Size:        uint64(min(chunk.stop, stop) - offset),
 				LogicOffset: offset,
 				IsFullChunk: isFullChunk,
+				Compression: chunk.compression,
+				CipherKey:   chunk.cipherKey,
 			})
 			offset = min(chunk.stop, stop)
 		}
@@ -126,6 +161,9 @@ func MergeIntoVisibles(visibles, newVisibles []VisibleInterval, chunk *filer_pb.
 		chunk.GetFileIdString(),
 		chunk.Mtime,
 		true,
+		chunk.Compression,
+		chunk.CipherKey,
+		chunk.Offset,
 	)
 
 	length := len(visibles)
@@ -146,6 +184,9 @@ func MergeIntoVisibles(visibles, newVisibles []VisibleInterval, chunk *filer_pb.
 				v.fileId,
 				v.modifiedTime,
 				false,
+				v.compression,
+				v.cipherKey,
+				v.chunkOffset,
 			))
 		}
 		chunkStop := chunk.Offset + int64(chunk.Size)
@@ -156,6 +197,9 @@ func MergeIntoVisibles(visibles, newVisibles []VisibleInterval, chunk *filer_pb.
 				v.fileId,
 				v.modifiedTime,
 				false,
+				v.compression,
+				v.cipherKey,
+				v.chunkOffset,
 			))
 		}
 		if chunkStop <= v.start || v.stop <= chunk.Offset {
@@ -208,15 +252,22 @@ type VisibleInterval struct {
 	modifiedTime int64
 	fileId       string
 	isFullChunk  bool
+	compression  string
+	cipherKey    []byte
+	// chunkOffset is where the chunk starts in the file, before the chunk is split by the newer chunks
+	chunkOffset int64
 }
 
-func newVisibleInterval(start, stop int64, fileId string, modifiedTime int64, isFullChunk bool) VisibleInterval {
+func newVisibleInterval(start, stop int64, fileId string, modifiedTime int64, isFullChunk bool, compression string, cipherKey []byte, chunkOffset int64) VisibleInterval {
 	return VisibleInterval{
 		start:        start,
 		stop:         stop,
 		fileId:       fileId,
 		modifiedTime: modifiedTime,
 		isFullChunk:  isFullChunk,
+		compression:  compression,
+		cipherKey:    cipherKey,
+		chunkOffset:  chunkOffset,
 	}
 }
 
@@ -258,7 +258,8 @@ func TestChunksReading(t *testing.T) {
 			Size:   50,
 			Expected: []*ChunkView{
 				{Offset: 25, Size: 25, FileId: "asdf", LogicOffset: 25},
-				{Offset: 0, Size: 25, FileId: "abc", LogicOffset: 50},
+				// abc is read from its byte 50, not its start
+				{Offset: 50, Size: 25, FileId: "abc", LogicOffset: 50},
 			},
 		},
 		// case 3: updates overwrite full chunks
@@ -286,7 +287,8 @@ func TestChunksReading(t *testing.T) {
 			Size:   400,
 			Expected: []*ChunkView{
 				{Offset: 0, Size: 200, FileId: "asdf", LogicOffset: 0},
-				// {Offset: 0, Size: 150, FileId: "xxxx"}, // missing intervals should not happen
+				// the hole in [200,250) is skipped
+				{Offset: 0, Size: 150, FileId: "xxxx", LogicOffset: 250},
 			},
 		},
 		// case 5: updates overwrite full chunks
@@ -301,7 +303,8 @@ func TestChunksReading(t *testing.T) {
 			Size:   220,
 			Expected: []*ChunkView{
 				{Offset: 0, Size: 200, FileId: "asdf", LogicOffset: 0},
-				{Offset: 0, Size: 20, FileId: "abc", LogicOffset: 200},
+				// the abc chunk starting at 70 is read from its byte 130
+				{Offset: 130, Size: 20, FileId: "abc", LogicOffset: 200},
 			},
 		},
 		// case 6: same updates
@@ -331,6 +334,20 @@ func TestChunksReading(t *testing.T) {
 				{Offset: 0, Size: 100, FileId: "asdf", LogicOffset: 100},
 			},
 		},
+		// case 9: a patch in the middle splits the chunk
+		{
+			Chunks: []*filer_pb.FileChunk{
+				{Offset: 0, Size: 300, FileId: "abc", Mtime: 123},
+				{Offset: 100, Size: 50, FileId: "patch", Mtime: 134},
+			},
+			Offset: 0,
+			Size:   300,
+			Expected: []*ChunkView{
+				{Offset: 0, Size: 100, FileId: "abc", LogicOffset: 0},
+				{Offset: 0, Size: 50, FileId: "patch", LogicOffset: 100},
+				{Offset: 150, Size: 150, FileId: "abc", LogicOffset: 150},
+			},
+		},
 	}
 
 	for i, testcase := range testcases {
@@ -382,3 +399,46 @@ func BenchmarkCompactFileChunks(b *testing.B) {
 		CompactFileChunks(chunks)
 	}
 }
+
+func TestETag(t *testing.T) {
+
+	whole := &filer_pb.FileChunk{FileId: "1,01", Offset: 0, Size: 100, ETag: "abc"}
+	if etag := ETag([]*filer_pb.FileChunk{whole}); etag != "abc" {
+		t.Errorf("single chunk etag %s", etag)
+	}
+
+	a := &filer_pb.FileChunk{FileId: "1,01", Offset: 0, Size: 100, ETag: "abc", Mtime: 1}
+	b := &filer_pb.FileChunk{FileId: "1,02", Offset: 100, Size: 100, ETag: "def", Mtime: 2}
+	if ETag([]*filer_pb.FileChunk{a, b}) != ETag([]*filer_pb.FileChunk{b, a}) {
+		t.Errorf("etag should follow the file offsets, not the order of the chunks")
+	}
+
+	truncated := &filer_pb.FileChunk{FileId: "1,02", Offset: 100, Size: 50, Mtime: 2}
+	if ETag([]*filer_pb.FileChunk{a, b}) == ETag([]*filer_pb.FileChunk{a, truncated}) {
+		t.Errorf("etag should change after truncating")
+	}
+}
+
+func TestContentETag(t *testing.T) {
+
+	// the copy on another cluster has the same content in other file ids
+	a := &filer_pb.FileChunk{FileId: "1,01", Offset: 0, Size: 100, Mtime: 1}
+	b := &filer_pb.FileChunk{FileId: "1,02", Offset: 100, Size: 100, ETag: "def", Mtime: 2}
+	replicatedA := &filer_pb.FileChunk{FileId: "7,0a", Offset: 0, Size: 100, Mtime: 1}
+	replicatedB := &filer_pb.FileChunk{FileId: "7,0b", Offset: 100, Size: 100, ETag: "def", Mtime: 2}
+
+	if ETag([]*filer_pb.FileChunk{a, b}) == ETag([]*filer_pb.FileChunk{replicatedA, replicatedB}) {
+		t.Errorf("etag should follow the file ids of the chunks without etags")
+	}
+	if ContentETag([]*filer_pb.FileChunk{a, b}) != ContentETag([]*filer_pb.FileChunk{replicatedA, replicatedB}) {
+		t.Errorf("content etag should not follow the file ids")
+	}
+	if ContentETag([]*filer_pb.FileChunk{a}) != ContentETag([]*filer_pb.FileChunk{replicatedA}) {
+		t.Errorf("content etag of the single chunk should not follow the file id")
+	}
+
+	truncated := &filer_pb.FileChunk{FileId: "1,02", Offset: 100, Size: 50, Mtime: 2}
+	if ContentETag([]*filer_pb.FileChunk{a, b}) == ContentETag([]*filer_pb.FileChunk{a, truncated}) {
+		t.Errorf("content etag should change after truncating")
+	}
+}
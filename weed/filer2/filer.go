@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/chrislusf/seaweedfs/weed/glog"
@@ -21,11 +22,22 @@ var (
 )
 
 type Filer struct {
-	store              *FilerStoreWrapper
-	directoryCache     *ccache.Cache
-	MasterClient       *wdclient.MasterClient
-	fileIdDeletionChan chan string
-	GrpcDialOption     grpc.DialOption
+	store               *FilerStoreWrapper
+	directoryCache      *ccache.Cache
+	MasterClient        *wdclient.MasterClient
+	fileIdDeletionChan  chan string
+	GrpcDialOption      grpc.DialOption
+	snapshotChunks      *snapshotChunks
+	dedup               dedupIndex
+	compression         *CompressionPolicy
+	encryption          *EncryptionPolicy
+	patchLocks          entryLocks // serializes the appends and patches of each file
+	metadataSubscribers metadataSubscribers
+	expiring            sync.Map // the expired entries queued to be deleted
+	expiredEntries      chan FullPath
+	sweep               expirySweep
+	clusterId           string // to stamp the entries written locally
+	leases              fileLeases
 }
 
 func NewFiler(masters []string, grpcDialOption grpc.DialOption) *Filer {
@@ -33,10 +45,19 @@ func NewFiler(masters []string, grpcDialOption grpc.DialOption) *Filer {
 		directoryCache:     ccache.New(ccache.Configure().MaxSize(1000).ItemsToPrune(100)),
 		MasterClient:       wdclient.NewMasterClient(context.Background(), grpcDialOption, "filer", masters),
 		fileIdDeletionChan: make(chan string, 4096),
+		expiredEntries:     make(chan FullPath, expiryDeletionQueueSize),
 		GrpcDialOption:     grpcDialOption,
+		snapshotChunks:     &snapshotChunks{refs: make(map[string]int)},
+		metadataSubscribers: metadataSubscribers{
+			subscribers: make(map[string]*metadataSubscriber),
+		},
 	}
 
 	go f.loopProcessingDeletion()
+	for i := 0; i < expiryDeletionWorkers; i++ {
+		go f.loopDeletingExpiredEntries()
+	}
+	go f.loopSweepingExpiredEntries()
 
 	return f
 }
@@ -75,6 +96,13 @@ func (f *Filer) CreateEntry(ctx context.Context, entry *Entry) error {
 		return nil
 	}
 
+	if err := f.checkSnapshotWrite(ctx, entry.FullPath); err != nil {
+		return err
+	}
+	if err := f.CheckLease(ctx, entry.FullPath); err != nil {
+		return err
+	}
+
 	dirParts := strings.Split(string(entry.FullPath), "/")
 
 	// fmt.Printf("directory parts: %+v\n", dirParts)
@@ -151,7 +179,14 @@ func (f *Filer) CreateEntry(ctx context.Context, entry *Entry) error {
 
 	oldEntry, _ := f.FindEntry(ctx, entry.FullPath)
 
+	if oldEntry != nil {
+		if err := f.checkUpdate(ctx, oldEntry, entry); err != nil {
+			return err
+		}
+	}
+
 	if oldEntry == nil {
+		f.stampWrite(ctx, entry)
 		if err := f.store.InsertEntry(ctx, entry); err != nil {
 			glog.Errorf("insert entry %s: %v", entry.FullPath, err)
 			return fmt.Errorf("insert entry %s: %v", entry.FullPath, err)
@@ -166,11 +201,18 @@ func (f *Filer) CreateEntry(ctx context.Context, entry *Entry) error {
 	f.NotifyUpdateEvent(oldEntry, entry, true)
 
 	f.deleteChunksIfNotNew(oldEntry, entry)
+	f.releaseKeptDedupChunks(ctx, oldEntry, entry)
 
 	return nil
 }
 
 func (f *Filer) UpdateEntry(ctx context.Context, oldEntry, entry *Entry) (err error) {
+	if err = f.checkSnapshotWrite(ctx, entry.FullPath); err != nil {
+		return err
+	}
+	if err = f.CheckLease(ctx, entry.FullPath); err != nil {
+		return err
+	}
 	if oldEntry != nil {
 		if oldEntry.IsDirectory() && !entry.IsDirectory() {
 			glog.Errorf("existing %s is a directory", entry.FullPath)
@@ -180,7 +222,11 @@ func (f *Filer) UpdateEntry(ctx context.Context, oldEntry, entry *Entry) (err er
 			glog.Errorf("existing %s is a file", entry.FullPath)
 			return fmt.Errorf("existing %s is a file", entry.FullPath)
 		}
+		if err = f.checkUpdate(ctx, oldEntry, entry); err != nil {
+			return err
+		}
 	}
+	f.stampWrite(ctx, entry)
 	return f.store.UpdateEntry(ctx, entry)
 }
 
@@ -200,15 +246,31 @@ func (f *Filer) FindEntry(ctx context.Context, p FullPath) (entry *Entry, err er
 			},
 		}, nil
 	}
-	return f.store.FindEntry(ctx, p)
+	entry, err = f.store.FindEntry(ctx, p)
+	if err == nil && f.isExpired(ctx, entry) {
+		f.deleteExpiredEntry(p)
+		return nil, ErrNotFound
+	}
+	return entry, err
 }
 
 func (f *Filer) DeleteEntryMetaAndData(ctx context.Context, p FullPath, isRecursive bool, shouldDeleteChunks bool) (err error) {
+	if err = f.checkSnapshotWrite(ctx, p); err != nil {
+		return err
+	}
+	if err = f.CheckLease(ctx, p); err != nil {
+		return err
+	}
+
 	entry, err := f.FindEntry(ctx, p)
 	if err != nil {
 		return err
 	}
 
+	if err = f.CheckDeletion(ctx, entry); err != nil {
+		return err
+	}
+
 	if entry.IsDirectory() {
 		limit := int(1)
 		if isRecursive {
@@ -230,6 +292,10 @@ func (f *Filer) DeleteEntryMetaAndData(ctx context.Context, p FullPath, isRecurs
 			if isRecursive {
 				for _, sub := range entries {
 					lastFileName = sub.Name()
+					if f.checkSnapshotWrite(ctx, sub.FullPath) != nil || IsDedupPath(sub.FullPath) {
+						// the snapshots are only deleted by DeleteSnapshot, and the dedup index is kept
+						continue
+					}
 					err = f.DeleteEntryMetaAndData(ctx, sub.FullPath, isRecursive, shouldDeleteChunks)
 					if err != nil {
 						return err
@@ -261,14 +327,42 @@ func (f *Filer) DeleteEntryMetaAndData(ctx context.Context, p FullPath, isRecurs
 
 	f.NotifyUpdateEvent(entry, nil, shouldDeleteChunks)
 
-	return f.store.DeleteEntry(ctx, p)
+	if err = f.store.DeleteEntry(ctx, p); err != nil {
+		return err
+	}
+	f.dropLease(p)
+	return nil
 }
 
 func (f *Filer) ListDirectoryEntries(ctx context.Context, p FullPath, startFileName string, inclusive bool, limit int) ([]*Entry, error) {
 	if strings.HasSuffix(string(p), "/") && len(p) > 1 {
 		p = p[0 : len(p)-1]
 	}
-	return f.store.ListDirectoryEntries(ctx, p, startFileName, inclusive, limit)
+	entries, err := f.store.ListDirectoryEntries(ctx, p, startFileName, inclusive, limit)
+	if err != nil || len(entries) < limit {
+		entries, _ = f.removeExpiredEntries(ctx, entries)
+		return entries, err
+	}
+	// list more after the expired entries to fill the limit
+	lastFileName := entries[len(entries)-1].Name()
+	entries, expiredCount := f.removeExpiredEntries(ctx, entries)
+	for expiredCount > 0 {
+		more, err := f.store.ListDirectoryEntries(ctx, p, lastFileName, false, expiredCount)
+		if err != nil {
+			return nil, err
+		}
+		listedCount := len(more)
+		if listedCount > 0 {
+			lastFileName = more[listedCount-1].Name()
+		}
+		wanted := expiredCount
+		more, expiredCount = f.removeExpiredEntries(ctx, more)
+		entries = append(entries, more...)
+		if listedCount < wanted {
+			break
+		}
+	}
+	return entries, nil
 }
 
 func (f *Filer) cacheDelDirectory(dirpath string) {
@@ -0,0 +1,71 @@
+package filer2
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+)
+
+// AppendEntry appends the new chunks, with the offsets relative to the appended data, to the end of the file,
+// creating the file from the template entry if missing. It returns the file offset the data is appended at.
+// The appends and patches of each file on this filer are serialized, so the concurrent appends do not overlap.
+func (f *Filer) AppendEntry(ctx context.Context, template *Entry, chunks []*filer_pb.FileChunk) (offset int64, err error) {
+
+	p := template.FullPath
+	defer f.patchLocks.lock(p)()
+
+	oldEntry, err := f.FindEntry(ctx, p)
+	if err == ErrNotFound {
+		newEntry := &Entry{
+			FullPath: p,
+			Attr:     template.Attr,
+			Chunks:   chunks,
+		}
+		if err = f.CreateEntry(ctx, newEntry); err != nil {
+			return 0, err
+		}
+		glog.V(3).Infof("appended %s with %d chunks at 0", p, len(chunks))
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("find %s: %v", p, err)
+	}
+	if oldEntry.IsDirectory() {
+		return 0, fmt.Errorf("existing %s is a directory", p)
+	}
+
+	// the appended chunks are newer than all existing chunks
+	offset = int64(oldEntry.Size())
+	mtime := time.Now().UnixNano()
+	for _, chunk := range oldEntry.Chunks {
+		if chunk.Mtime >= mtime {
+			mtime = chunk.Mtime + 1
+		}
+	}
+	allChunks := append([]*filer_pb.FileChunk{}, oldEntry.Chunks...)
+	for i, chunk := range chunks {
+		chunk.Offset += offset
+		chunk.Mtime = mtime + int64(i)
+		allChunks = append(allChunks, chunk)
+	}
+
+	newEntry := &Entry{
+		FullPath: p,
+		Attr:     oldEntry.Attr,
+		Chunks:   allChunks,
+	}
+	newEntry.Attr.Mtime = time.Now()
+
+	if err = f.UpdateEntry(ctx, oldEntry, newEntry); err != nil {
+		return 0, err
+	}
+
+	glog.V(3).Infof("appended %s with %d chunks at %d", p, len(chunks), offset)
+
+	f.NotifyUpdateEvent(oldEntry, newEntry, true)
+
+	return offset, nil
+}
@@ -0,0 +1,84 @@
+package filer2
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/operation"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+)
+
+// ChunkWriter splits the written content into fixed size chunks
+// and uploads each chunk to the volume servers.
+// It is used by the filer to store files generated by itself.
+type ChunkWriter struct {
+	filer       *Filer
+	name        string
+	collection  string
+	replication string
+	chunkSize   int
+	buffer      bytes.Buffer
+	offset      int64
+	Chunks      []*filer_pb.FileChunk
+}
+
+func (f *Filer) NewChunkWriter(name, collection, replication string, chunkSize int) *ChunkWriter {
+	return &ChunkWriter{
+		filer:       f,
+		name:        name,
+		collection:  collection,
+		replication: replication,
+		chunkSize:   chunkSize,
+	}
+}
+
+func (w *ChunkWriter) Write(p []byte) (n int, err error) {
+	n, _ = w.buffer.Write(p)
+	for w.buffer.Len() >= w.chunkSize {
+		if err = w.uploadChunk(w.buffer.Next(w.chunkSize)); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Close uploads any remaining buffered data
+func (w *ChunkWriter) Close() error {
+	if w.buffer.Len() == 0 {
+		return nil
+	}
+	return w.uploadChunk(w.buffer.Next(w.buffer.Len()))
+}
+
+func (w *ChunkWriter) uploadChunk(data []byte) error {
+
+	assignResult, err := operation.Assign(w.filer.GetMaster(), w.filer.GrpcDialOption, &operation.VolumeAssignRequest{
+		Count:       1,
+		Collection:  w.collection,
+		Replication: w.replication,
+	})
+	if err != nil {
+		return fmt.Errorf("assign volume for %s: %v", w.name, err)
+	}
+
+	uploadUrl := fmt.Sprintf("http://%s/%s", assignResult.Url, assignResult.Fid)
+	uploadResult, err := operation.Upload(uploadUrl, w.name, bytes.NewReader(data), false, "application/octet-stream", nil, assignResult.Auth)
+	if err != nil {
+		return fmt.Errorf("upload %s to %s: %v", w.name, uploadUrl, err)
+	}
+	if uploadResult.Error != "" {
+		return fmt.Errorf("upload %s to %s: %v", w.name, uploadUrl, uploadResult.Error)
+	}
+
+	w.Chunks = append(w.Chunks, &filer_pb.FileChunk{
+		FileId: assignResult.Fid,
+		Offset: w.offset,
+		Size:   uint64(len(data)),
+		Mtime:  time.Now().UnixNano(),
+		ETag:   uploadResult.ETag,
+	})
+	w.offset += int64(len(data))
+
+	return nil
+}
@@ -29,25 +29,9 @@ func ReadIntoBuffer(ctx context.Context, filerClient FilerClient, fullFilePath s
 		vids = append(vids, VolumeId(chunkView.FileId))
 	}
 
-	vid2Locations := make(map[string]*filer_pb.Locations)
-
-	err = filerClient.WithFilerClient(ctx, func(client filer_pb.SeaweedFilerClient) error {
-
-		glog.V(4).Infof("read fh lookup volume id locations: %v", vids)
-		resp, err := client.LookupVolume(ctx, &filer_pb.LookupVolumeRequest{
-			VolumeIds: vids,
-		})
-		if err != nil {
-			return err
-		}
-
-		vid2Locations = resp.LocationsMap
-
-		return nil
-	})
-
+	vid2Locations, err := LookupVolumeLocations(ctx, filerClient, vids)
 	if err != nil {
-		return 0, fmt.Errorf("failed to lookup volume ids %v: %v", vids, err)
+		return 0, err
 	}
 
 	var wg sync.WaitGroup
@@ -65,20 +49,27 @@ func ReadIntoBuffer(ctx context.Context, filerClient FilerClient, fullFilePath s
 				return
 			}
 
+			// the locations are in the order to read, so the next one is tried if the read fails
 			var n int64
-			n, err = util.ReadUrl(
-				fmt.Sprintf("http://%s/%s", locations.Locations[0].Url, chunkView.FileId),
-				chunkView.Offset,
-				int(chunkView.Size),
-				buff[chunkView.LogicOffset-baseOffset:chunkView.LogicOffset-baseOffset+int64(chunkView.Size)],
-				!chunkView.IsFullChunk)
-
-			if err != nil {
-
-				glog.V(0).Infof("%v read http://%s/%v %v bytes: %v", fullFilePath, locations.Locations[0].Url, chunkView.FileId, n, err)
+			var readErr error
+			chunkBuff := buff[chunkView.LogicOffset-baseOffset : chunkView.LogicOffset-baseOffset+int64(chunkView.Size)]
+			for _, location := range locations.Locations {
+				fileUrl := fmt.Sprintf("http://%s/%s", location.Url, chunkView.FileId)
+				if chunkView.Compression != "" || len(chunkView.CipherKey) > 0 {
+					readErr = ReadChunkView(fileUrl, chunkView, func(data []byte) {
+						n = int64(copy(chunkBuff, data))
+					})
+				} else {
+					n, readErr = util.ReadUrl(fileUrl, chunkView.Offset, int(chunkView.Size), chunkBuff, !chunkView.IsFullChunk)
+				}
+				if readErr == nil {
+					break
+				}
+				glog.V(0).Infof("%v read %s %v bytes: %v", fullFilePath, fileUrl, n, readErr)
+			}
 
-				err = fmt.Errorf("failed to read http://%s/%s: %v",
-					locations.Locations[0].Url, chunkView.FileId, err)
+			if readErr != nil {
+				err = fmt.Errorf("failed to read %s: %v", chunkView.FileId, readErr)
 				return
 			}
 
@@ -91,6 +82,31 @@ func ReadIntoBuffer(ctx context.Context, filerClient FilerClient, fullFilePath s
 	return
 }
 
+// LookupVolumeLocations asks the filer for the locations of the volumes
+func LookupVolumeLocations(ctx context.Context, filerClient FilerClient, vids []string) (vid2Locations map[string]*filer_pb.Locations, err error) {
+
+	err = filerClient.WithFilerClient(ctx, func(client filer_pb.SeaweedFilerClient) error {
+
+		glog.V(4).Infof("read fh lookup volume id locations: %v", vids)
+		resp, err := client.LookupVolume(ctx, &filer_pb.LookupVolumeRequest{
+			VolumeIds: vids,
+		})
+		if err != nil {
+			return err
+		}
+
+		vid2Locations = resp.LocationsMap
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup volume ids %v: %v", vids, err)
+	}
+
+	return vid2Locations, nil
+}
+
 func GetEntry(ctx context.Context, filerClient FilerClient, fullFilePath string) (entry *filer_pb.Entry, err error) {
 
 	dir, name := FullPath(fullFilePath).DirAndName()
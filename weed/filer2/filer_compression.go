@@ -0,0 +1,140 @@
+package filer2
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/util"
+	"github.com/spf13/viper"
+)
+
+// the codecs compressing the chunks
+const (
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+// CompressionPolicy chooses the uploads compressed by the filer
+type CompressionPolicy struct {
+	Codec string
+	// empty for all collections
+	Collections []string
+	// the mime type prefixes, empty for all mime types
+	MimeTypes []string
+}
+
+func (f *Filer) LoadCompressionConfiguration(config *viper.Viper) {
+
+	if config == nil || !config.GetBool("enabled") {
+		return
+	}
+
+	policy := &CompressionPolicy{
+		Codec:       config.GetString("codec"),
+		Collections: config.GetStringSlice("collections"),
+		MimeTypes:   config.GetStringSlice("mime_types"),
+	}
+	if policy.Codec == "" {
+		policy.Codec = CompressionZstd
+	}
+	if policy.Codec != CompressionGzip && policy.Codec != CompressionZstd {
+		glog.Fatalf("compression codec %s is not supported, only %s or %s", policy.Codec, CompressionGzip, CompressionZstd)
+	}
+
+	glog.V(0).Infof("compress the chunks with %s, collections %v, mime types %v", policy.Codec, policy.Collections, policy.MimeTypes)
+
+	f.compression = policy
+}
+
+// ChunkCompression returns the codec to compress the chunks of the upload, or "" to store them as they are
+func (f *Filer) ChunkCompression(collection, mimeType string) string {
+	policy := f.compression
+	if policy == nil {
+		return ""
+	}
+	if len(policy.Collections) > 0 && !containsString(policy.Collections, collection) {
+		return ""
+	}
+	if len(policy.MimeTypes) == 0 {
+		return policy.Codec
+	}
+	for _, prefix := range policy.MimeTypes {
+		if strings.HasPrefix(mimeType, prefix) {
+			return policy.Codec
+		}
+	}
+	return ""
+}
+
+func containsString(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+func CompressChunk(codec string, data []byte) ([]byte, error) {
+	switch codec {
+	case CompressionGzip:
+		return util.GzipData(data)
+	case CompressionZstd:
+		return util.ZstdData(data)
+	}
+	return nil, fmt.Errorf("unknown chunk compression %q", codec)
+}
+
+func DecompressChunk(codec string, data []byte) ([]byte, error) {
+	switch codec {
+	case CompressionGzip:
+		return util.UnGzipData(data)
+	case CompressionZstd:
+		return util.UnZstdData(data)
+	}
+	return nil, fmt.Errorf("unknown chunk compression %q", codec)
+}
+
+// ReadChunkView reads the part of the chunk in the view.
+// The compressed or encrypted chunks are read in whole, since the ranges are of the original content.
+// The holes are read as zeros, without the file url.
+func ReadChunkView(fileUrl string, chunkView *ChunkView, fn func(data []byte)) error {
+	if chunkView.IsHole() {
+		readHole(chunkView, fn)
+		return nil
+	}
+	if chunkView.Compression == "" && len(chunkView.CipherKey) == 0 {
+		_, err := util.ReadUrlAsStream(fileUrl, chunkView.Offset, int(chunkView.Size), fn)
+		return err
+	}
+	data, err := FetchWholeChunk(fileUrl, chunkView)
+	if err != nil {
+		return err
+	}
+	stop := chunkView.Offset + int64(chunkView.Size)
+	if chunkView.Offset < 0 || stop > int64(len(data)) {
+		return fmt.Errorf("chunk %s has %d bytes, shorter than the view [%d,%d)", chunkView.FileId, len(data), chunkView.Offset, stop)
+	}
+	fn(data[chunkView.Offset:stop])
+	return nil
+}
+
+// FetchWholeChunk reads the original content of the whole chunk, decrypted and decompressed
+func FetchWholeChunk(fileUrl string, chunkView *ChunkView) ([]byte, error) {
+	data, err := util.Get(fileUrl)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunkView.CipherKey) > 0 {
+		if data, err = DecryptChunk(chunkView.CipherKey, data); err != nil {
+			return nil, fmt.Errorf("decrypt %s: %v", chunkView.FileId, err)
+		}
+	}
+	if chunkView.Compression != "" {
+		if data, err = DecompressChunk(chunkView.Compression, data); err != nil {
+			return nil, fmt.Errorf("decompress %s: %v", chunkView.FileId, err)
+		}
+	}
+	return data, nil
+}
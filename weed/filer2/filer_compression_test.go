@@ -0,0 +1,58 @@
+package filer2
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestChunkCompression(t *testing.T) {
+	f := &Filer{compression: &CompressionPolicy{
+		Codec:       CompressionGzip,
+		Collections: []string{"logs"},
+		MimeTypes:   []string{"text/", "application/json"},
+	}}
+
+	testcases := []struct {
+		collection, mimeType, expected string
+	}{
+		{"logs", "text/plain; charset=utf-8", CompressionGzip},
+		{"logs", "application/json", CompressionGzip},
+		{"logs", "image/png", ""},
+		{"", "text/plain", ""},
+	}
+	for _, tc := range testcases {
+		if codec := f.ChunkCompression(tc.collection, tc.mimeType); codec != tc.expected {
+			t.Errorf("collection %q mime type %q: codec %q, expected %q", tc.collection, tc.mimeType, codec, tc.expected)
+		}
+	}
+
+	if codec := (&Filer{}).ChunkCompression("logs", "text/plain"); codec != "" {
+		t.Errorf("compressed without the policy: %q", codec)
+	}
+}
+
+func TestCompressChunk(t *testing.T) {
+	data := bytes.Repeat([]byte("some text to compress, "), 100)
+
+	compressed, err := CompressChunk(CompressionGzip, data)
+	if err != nil {
+		t.Fatalf("compress: %v", err)
+	}
+	if len(compressed) >= len(data) {
+		t.Errorf("compressed %d bytes into %d bytes", len(data), len(compressed))
+	}
+	decompressed, err := DecompressChunk(CompressionGzip, compressed)
+	if err != nil {
+		t.Fatalf("decompress: %v", err)
+	}
+	if !bytes.Equal(decompressed, data) {
+		t.Errorf("decompressed data differs")
+	}
+
+	if _, err = DecompressChunk(CompressionGzip, data); err == nil {
+		t.Errorf("decompressed the uncompressed data")
+	}
+	if _, err = CompressChunk("lz4", data); err == nil {
+		t.Errorf("compressed with an unknown codec")
+	}
+}
@@ -0,0 +1,43 @@
+package filer2
+
+import (
+	"context"
+	"fmt"
+)
+
+// ReadConsistency asks the filer store how hard to try to see the latest writes, possibly from the other filers.
+// It only matters for the eventually consistent stores shared by multiple filers, e.g. Cassandra.
+// The other stores always read the latest writes.
+type ReadConsistency string
+
+const (
+	ReadConsistencyDefault  ReadConsistency = ""
+	ReadConsistencyEventual ReadConsistency = "eventual"
+	// ReadConsistencyStrong reads the writes acknowledged by any filer, and repairs the stale replicas
+	ReadConsistencyStrong ReadConsistency = "strong"
+)
+
+func ParseReadConsistency(s string) (ReadConsistency, error) {
+	switch c := ReadConsistency(s); c {
+	case ReadConsistencyDefault, ReadConsistencyEventual, ReadConsistencyStrong:
+		return c, nil
+	}
+	return ReadConsistencyDefault, fmt.Errorf("unknown read consistency %q, expecting %q or %q", s, ReadConsistencyEventual, ReadConsistencyStrong)
+}
+
+type readConsistencyKey struct{}
+
+func WithReadConsistency(ctx context.Context, c ReadConsistency) context.Context {
+	if c == ReadConsistencyDefault {
+		return ctx
+	}
+	return context.WithValue(ctx, readConsistencyKey{}, c)
+}
+
+// ReadConsistencyFromContext is for the filer stores to find the read consistency of the request
+func ReadConsistencyFromContext(ctx context.Context) ReadConsistency {
+	if c, ok := ctx.Value(readConsistencyKey{}).(ReadConsistency); ok {
+		return c
+	}
+	return ReadConsistencyDefault
+}
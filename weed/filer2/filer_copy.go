@@ -0,0 +1,45 @@
+package filer2
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+)
+
+// CanShareChunks tells whether a copy can reference the same chunks as the copied file,
+// which needs the chunk references counted in the dedup index.
+func (f *Filer) CanShareChunks() bool {
+	return f.dedup.counting
+}
+
+// ReferenceChunks counts one more reference to each chunk, for a copy sharing the chunks.
+// The chunks not in the dedup index yet are added with one reference for the copied file first.
+// The copy releases its references as usual when it is overwritten or deleted.
+func (f *Filer) ReferenceChunks(ctx context.Context, chunks []*filer_pb.FileChunk) error {
+	if !f.dedup.counting {
+		return fmt.Errorf("the chunk references are not counted")
+	}
+
+	f.dedup.Lock()
+	defer f.dedup.Unlock()
+
+	for _, chunk := range chunks {
+		fileId := chunk.GetFileIdString()
+		fidDir := dedupFidsDir.Child(fileId)
+		if _, err := f.store.FindEntry(ctx, fidDir); err == ErrNotFound {
+			if err = f.store.InsertEntry(ctx, newDedupDirEntry(fidDir)); err != nil {
+				return fmt.Errorf("add dedup file id %s: %v", fileId, err)
+			}
+			if err = f.addDedupReference(ctx, fileId); err != nil {
+				return err
+			}
+		} else if err != nil {
+			return fmt.Errorf("find dedup file id %s: %v", fileId, err)
+		}
+		if err := f.addDedupReference(ctx, fileId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,282 @@
+package filer2
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"math/rand"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+)
+
+// DedupDir is the index of the deduplicated chunks, stored as entries:
+//
+//	/.dedup/hashes/<sha256>      a file entry with the one chunk having the content hash
+//	/.dedup/fids/<file id>/<ref> one entry for each file chunk referencing the file id
+//
+// A chunk is only deleted when its last reference is released.
+// The hash entries of the deleted chunks are removed when they are looked up next time.
+const DedupDir = FullPath("/.dedup")
+
+var (
+	dedupHashesDir = DedupDir.Child("hashes")
+	dedupFidsDir   = DedupDir.Child("fids")
+)
+
+func IsDedupPath(p FullPath) bool {
+	return p == DedupDir || strings.HasPrefix(string(p), string(DedupDir)+"/")
+}
+
+// dedupIndex serializes the reference counting of this filer.
+// The filers sharing one store should not enable the dedup mode at the same time.
+type dedupIndex struct {
+	sync.Mutex
+	// enabled deduplicates the uploads
+	enabled bool
+	// counting is on as long as the index exists, even if the uploads are not deduplicated any more
+	counting bool
+}
+
+// NewDedupHash starts the content hash of a chunk.
+// The chunks are only shared when stored in the same way, so the storage options are hashed first.
+func NewDedupHash(collection, replication, ttl, contentType, contentEncoding string) hash.Hash {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s\x00%s\x00", collection, replication, ttl, contentType, contentEncoding)
+	return h
+}
+
+func DedupHashString(h hash.Hash) string {
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// EnableDedup creates the index directories, and turns on the reference counting of the chunks
+func (f *Filer) EnableDedup(ctx context.Context) error {
+	if err := f.createDedupIndex(ctx); err != nil {
+		return err
+	}
+	f.dedup.enabled = true
+	glog.V(0).Infof("filer dedup is enabled")
+	return nil
+}
+
+// StartDedupCounting creates the index for the uploads asking for the dedup when the dedup mode is off,
+// so the chunks shared from then on are counted.
+func (f *Filer) StartDedupCounting(ctx context.Context) error {
+	f.dedup.Lock()
+	counting := f.dedup.counting
+	f.dedup.Unlock()
+	if counting {
+		return nil
+	}
+	if err := f.createDedupIndex(ctx); err != nil {
+		return err
+	}
+	glog.V(0).Infof("filer dedup index is created")
+	return nil
+}
+
+func (f *Filer) createDedupIndex(ctx context.Context) error {
+	for _, dir := range []FullPath{dedupHashesDir, dedupFidsDir} {
+		if err := f.CreateEntry(ctx, newDedupDirEntry(dir)); err != nil {
+			return fmt.Errorf("create %s: %v", dir, err)
+		}
+	}
+	f.dedup.Lock()
+	f.dedup.counting = true
+	f.dedup.Unlock()
+	return nil
+}
+
+// LoadDedup keeps counting the references if the dedup index exists,
+// and should be done before any chunk is deleted.
+func (f *Filer) LoadDedup(ctx context.Context) error {
+	if _, err := f.FindEntry(ctx, DedupDir); err == ErrNotFound {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("find %s: %v", DedupDir, err)
+	}
+	f.dedup.counting = true
+	return nil
+}
+
+func (f *Filer) IsDedupEnabled() bool {
+	return f.dedup.enabled
+}
+
+// ReferenceDedupChunk returns the existing chunk with the content hash, referenced once more.
+// It returns nil if the content is not known.
+func (f *Filer) ReferenceDedupChunk(ctx context.Context, contentHash string) (*filer_pb.FileChunk, error) {
+	f.dedup.Lock()
+	defer f.dedup.Unlock()
+
+	hashEntry, err := f.store.FindEntry(ctx, dedupHashesDir.Child(contentHash))
+	if err == ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find dedup hash %s: %v", contentHash, err)
+	}
+	if len(hashEntry.Chunks) != 1 {
+		return nil, nil
+	}
+	chunk := hashEntry.Chunks[0]
+	fileId := chunk.GetFileIdString()
+	if _, err = f.store.FindEntry(ctx, dedupFidsDir.Child(fileId)); err == ErrNotFound {
+		// the chunk has been deleted
+		if err = f.store.DeleteEntry(ctx, hashEntry.FullPath); err != nil {
+			glog.V(0).Infof("delete stale dedup hash %s: %v", contentHash, err)
+		}
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("find dedup file id %s: %v", fileId, err)
+	}
+
+	if err = f.addDedupReference(ctx, fileId); err != nil {
+		return nil, err
+	}
+
+	return &filer_pb.FileChunk{
+		FileId:      fileId,
+		Size:        chunk.Size,
+		ETag:        chunk.ETag,
+		Compression: chunk.Compression,
+		CipherKey:   chunk.CipherKey,
+	}, nil
+}
+
+// AddDedupChunk records the newly uploaded chunk with the content hash, referenced once
+func (f *Filer) AddDedupChunk(ctx context.Context, contentHash string, chunk *filer_pb.FileChunk) error {
+	f.dedup.Lock()
+	defer f.dedup.Unlock()
+
+	fileId := chunk.GetFileIdString()
+	now := time.Now()
+	fidDir := newDedupDirEntry(dedupFidsDir.Child(fileId))
+	if err := f.store.InsertEntry(ctx, fidDir); err != nil {
+		return fmt.Errorf("add dedup file id %s: %v", fileId, err)
+	}
+	if err := f.addDedupReference(ctx, fileId); err != nil {
+		return err
+	}
+	hashEntry := &Entry{
+		FullPath: dedupHashesDir.Child(contentHash),
+		Attr: Attr{
+			Mtime:  now,
+			Crtime: now,
+			Mode:   0644,
+			Uid:    OS_UID,
+			Gid:    OS_GID,
+		},
+		Chunks: []*filer_pb.FileChunk{{
+			FileId:      fileId,
+			Size:        chunk.Size,
+			Mtime:       now.UnixNano(),
+			ETag:        chunk.ETag,
+			Compression: chunk.Compression,
+			CipherKey:   chunk.CipherKey,
+		}},
+	}
+	if err := f.store.InsertEntry(ctx, hashEntry); err != nil {
+		return fmt.Errorf("add dedup hash %s: %v", contentHash, err)
+	}
+	return nil
+}
+
+func (f *Filer) addDedupReference(ctx context.Context, fileId string) error {
+	ref := &Entry{
+		FullPath: dedupFidsDir.Child(fileId).Child(fmt.Sprintf("%x-%x", time.Now().UnixNano(), rand.Int63())),
+		Attr: Attr{
+			Mtime:  time.Now(),
+			Crtime: time.Now(),
+			Mode:   0644,
+			Uid:    OS_UID,
+			Gid:    OS_GID,
+		},
+	}
+	if err := f.store.InsertEntry(ctx, ref); err != nil {
+		return fmt.Errorf("add dedup reference to %s: %v", fileId, err)
+	}
+	return nil
+}
+
+type dedupUploadKey struct{}
+
+// WithDedupUpload tells CreateEntry that the chunks of the new entry are referenced by the upload,
+// so the references of the overwritten entry to the same chunks are released.
+func WithDedupUpload(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dedupUploadKey{}, true)
+}
+
+// releaseKeptDedupChunks releases the references of the old entry to the chunks kept by the new entry
+func (f *Filer) releaseKeptDedupChunks(ctx context.Context, oldEntry, newEntry *Entry) {
+	if oldEntry == nil || newEntry == nil || !f.dedup.counting || ctx.Value(dedupUploadKey{}) == nil {
+		return
+	}
+	newChunkIds := make(map[string]bool)
+	for _, newChunk := range newEntry.Chunks {
+		newChunkIds[newChunk.GetFileIdString()] = true
+	}
+	for _, oldChunk := range oldEntry.Chunks {
+		if fileId := oldChunk.GetFileIdString(); newChunkIds[fileId] {
+			f.releaseDedupChunk(ctx, fileId)
+		}
+	}
+}
+
+// releaseDedupChunk removes one reference to the chunk,
+// and returns true if the chunk is still referenced and should not be deleted.
+// The chunks not in the index are not deduplicated, and can be deleted.
+func (f *Filer) releaseDedupChunk(ctx context.Context, fileId string) bool {
+	f.dedup.Lock()
+	defer f.dedup.Unlock()
+
+	fidDir := dedupFidsDir.Child(fileId)
+	if _, err := f.store.FindEntry(ctx, fidDir); err == ErrNotFound {
+		return false
+	} else if err != nil {
+		glog.Errorf("find dedup file id %s: %v", fileId, err)
+		return true
+	}
+
+	refs, err := f.store.ListDirectoryEntries(ctx, fidDir, "", false, 2)
+	if err != nil {
+		// keeping some garbage is better than deleting the chunks in use
+		glog.Errorf("list dedup references to %s: %v", fileId, err)
+		return true
+	}
+	if len(refs) > 0 {
+		if err = f.store.DeleteEntry(ctx, refs[0].FullPath); err != nil {
+			glog.Errorf("delete dedup reference %s: %v", refs[0].FullPath, err)
+			return true
+		}
+	}
+	if len(refs) > 1 {
+		return true
+	}
+
+	if err = f.store.DeleteEntry(ctx, fidDir); err != nil {
+		glog.Errorf("delete dedup file id %s: %v", fileId, err)
+		return true
+	}
+	return false
+}
+
+func newDedupDirEntry(p FullPath) *Entry {
+	return &Entry{
+		FullPath: p,
+		Attr: Attr{
+			Mtime:  time.Now(),
+			Crtime: time.Now(),
+			Mode:   os.ModeDir | 0700,
+			Uid:    OS_UID,
+			Gid:    OS_GID,
+		},
+	}
+}
@@ -1,6 +1,8 @@
 package filer2
 
 import (
+	"context"
+	"net/http"
 	"time"
 
 	"github.com/chrislusf/seaweedfs/weed/glog"
@@ -38,28 +40,62 @@ func (f *Filer) loopProcessingDeletion() {
 			fileIds = append(fileIds, fid)
 			if len(fileIds) >= 4096 {
 				glog.V(1).Infof("deleting fileIds len=%d", len(fileIds))
-				operation.DeleteFilesWithLookupVolumeId(f.GrpcDialOption, fileIds, lookupFunc)
+				f.deleteFileIds(fileIds, lookupFunc)
 				fileIds = fileIds[:0]
 			}
 		case <-ticker.C:
 			if len(fileIds) > 0 {
 				glog.V(1).Infof("timed deletion fileIds len=%d", len(fileIds))
-				operation.DeleteFilesWithLookupVolumeId(f.GrpcDialOption, fileIds, lookupFunc)
+				f.deleteFileIds(fileIds, lookupFunc)
 				fileIds = fileIds[:0]
 			}
 		}
 	}
 }
 
+// deleteFileIds batch deletes the file ids, in one gRPC call per volume server, and logs the failed ones
+func (f *Filer) deleteFileIds(fileIds []string, lookupFunc func(vids []string) (map[string]operation.LookupResult, error)) {
+	fileIds = f.skipSnapshotChunks(fileIds)
+	if len(fileIds) == 0 {
+		return
+	}
+	results, err := operation.DeleteFilesWithLookupVolumeId(f.GrpcDialOption, fileIds, lookupFunc)
+	if err != nil {
+		glog.V(0).Infof("deleting fileIds len=%d: %v", len(fileIds), err)
+	}
+	for _, result := range results {
+		if result.Error != "" && result.Status != http.StatusNotFound {
+			glog.V(0).Infof("delete fileId %s: status %d %s", result.FileId, result.Status, result.Error)
+		}
+	}
+}
+
+// skipSnapshotChunks checks right before the deletion, since a snapshot may be created after the chunks are queued
+func (f *Filer) skipSnapshotChunks(fileIds []string) (toDelete []string) {
+	for _, fileId := range fileIds {
+		if f.snapshotChunks.isReferenced(fileId) {
+			glog.V(3).Infof("keep fileId %s referenced by snapshots", fileId)
+			continue
+		}
+		toDelete = append(toDelete, fileId)
+	}
+	return
+}
+
+// DeleteChunks deletes the chunks, except the deduplicated ones still referenced by other files
 func (f *Filer) DeleteChunks(fullpath FullPath, chunks []*filer_pb.FileChunk) {
 	for _, chunk := range chunks {
+		if f.dedup.counting && f.releaseDedupChunk(context.Background(), chunk.GetFileIdString()) {
+			glog.V(3).Infof("keep %s chunk %s referenced by other files", fullpath, chunk.String())
+			continue
+		}
 		glog.V(3).Infof("deleting %s chunk %s", fullpath, chunk.String())
 		f.fileIdDeletionChan <- chunk.GetFileIdString()
 	}
 }
 
 // DeleteFileByFileId direct delete by file id.
-// Only used when the fileId is not being managed by snapshots.
+// The fileId is still kept if referenced by any snapshot.
 func (f *Filer) DeleteFileByFileId(fileId string) {
 	f.fileIdDeletionChan <- fileId
 }
@@ -0,0 +1,173 @@
+package filer2
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+	"github.com/chrislusf/seaweedfs/weed/util"
+	"github.com/spf13/viper"
+)
+
+// chunkKeyWrappingKey wraps the random data key of each encrypted chunk.
+// It is only known by the filer, which keeps the data keys wrapped in the store,
+// and unwraps them for the clients, e.g. "weed mount" and the replication.
+// The plain data keys from the clients are wrapped again before saved.
+var chunkKeyWrappingKey []byte
+
+var ErrChunkKeyUnknown = errors.New("filer: the chunk is encrypted, but the key wrapping key is not configured")
+
+// EncryptionPolicy chooses the uploads encrypted by the filer
+type EncryptionPolicy struct {
+	// empty for all collections
+	Collections []string
+}
+
+func (f *Filer) LoadEncryptionConfiguration(config *viper.Viper) {
+
+	if config == nil {
+		return
+	}
+
+	// the key is still needed to read the encrypted chunks after the encryption is disabled
+	if encodedKey := config.GetString("key"); encodedKey != "" {
+		key, err := base64.StdEncoding.DecodeString(encodedKey)
+		if err != nil || len(key) != util.CipherKeySize {
+			glog.Fatalf("encryption key should be %d bytes in base64", util.CipherKeySize)
+		}
+		chunkKeyWrappingKey = key
+	}
+
+	if !config.GetBool("enabled") {
+		return
+	}
+	if chunkKeyWrappingKey == nil {
+		glog.Fatalf("encryption requires the key")
+	}
+
+	policy := &EncryptionPolicy{
+		Collections: config.GetStringSlice("collections"),
+	}
+
+	glog.V(0).Infof("encrypt the chunks of collections %v", policy.Collections)
+
+	f.encryption = policy
+}
+
+// IsChunkEncrypted tells whether to encrypt the chunks of the upload
+func (f *Filer) IsChunkEncrypted(collection string) bool {
+	policy := f.encryption
+	if policy == nil {
+		return false
+	}
+	return len(policy.Collections) == 0 || containsString(policy.Collections, collection)
+}
+
+// EncryptChunk encrypts the data with a random data key, returned wrapped by the filer key
+func EncryptChunk(data []byte) (ciphertext, wrappedKey []byte, err error) {
+	if chunkKeyWrappingKey == nil {
+		return nil, nil, ErrChunkKeyUnknown
+	}
+	key, err := util.GenCipherKey()
+	if err != nil {
+		return nil, nil, fmt.Errorf("generate data key: %v", err)
+	}
+	if ciphertext, err = util.Encrypt(data, key); err != nil {
+		return nil, nil, fmt.Errorf("encrypt chunk: %v", err)
+	}
+	if wrappedKey, err = util.Encrypt(key, chunkKeyWrappingKey); err != nil {
+		return nil, nil, fmt.Errorf("wrap data key: %v", err)
+	}
+	return ciphertext, wrappedKey, nil
+}
+
+// DecryptChunk decrypts the chunk with the data key, either plain or wrapped
+func DecryptChunk(cipherKey, ciphertext []byte) ([]byte, error) {
+	key, err := unwrapChunkKey(cipherKey)
+	if err != nil {
+		return nil, err
+	}
+	return util.Decrypt(ciphertext, key)
+}
+
+// isWrappedChunkKey tells the wrapped data key, longer than the plain one with the nonce and the tag
+func isWrappedChunkKey(cipherKey []byte) bool {
+	return len(cipherKey) > util.CipherKeySize
+}
+
+func unwrapChunkKey(cipherKey []byte) ([]byte, error) {
+	if !isWrappedChunkKey(cipherKey) {
+		return cipherKey, nil
+	}
+	if chunkKeyWrappingKey == nil {
+		return nil, ErrChunkKeyUnknown
+	}
+	key, err := util.Decrypt(cipherKey, chunkKeyWrappingKey)
+	if err != nil {
+		return nil, fmt.Errorf("unwrap data key: %v", err)
+	}
+	return key, nil
+}
+
+// UnwrapChunkKeys returns the chunks with the plain data keys, for the clients without the key wrapping key.
+// The encrypted chunks are copied, so the stored entry is not changed.
+func UnwrapChunkKeys(chunks []*filer_pb.FileChunk) []*filer_pb.FileChunk {
+	var unwrapped []*filer_pb.FileChunk
+	for i, chunk := range chunks {
+		if !isWrappedChunkKey(chunk.CipherKey) {
+			if unwrapped != nil {
+				unwrapped[i] = chunk
+			}
+			continue
+		}
+		if unwrapped == nil {
+			unwrapped = make([]*filer_pb.FileChunk, len(chunks))
+			copy(unwrapped, chunks[:i])
+		}
+		unwrapped[i] = chunk
+		key, err := unwrapChunkKey(chunk.CipherKey)
+		if err != nil {
+			glog.V(0).Infof("chunk %s: %v", chunk.GetFileIdString(), err)
+			continue
+		}
+		plain := *chunk
+		plain.CipherKey = key
+		unwrapped[i] = &plain
+	}
+	if unwrapped == nil {
+		return chunks
+	}
+	return unwrapped
+}
+
+// WrapChunkKeys wraps the plain data keys from the clients in place, reusing the wrapped keys of the existing chunks.
+// The plain keys are kept if the filer has no key wrapping key.
+func WrapChunkKeys(chunks []*filer_pb.FileChunk, existingChunks []*filer_pb.FileChunk) error {
+	var wrappedKeys map[string][]byte
+	for _, chunk := range chunks {
+		if len(chunk.CipherKey) != util.CipherKeySize || chunkKeyWrappingKey == nil {
+			continue
+		}
+		if wrappedKeys == nil {
+			wrappedKeys = make(map[string][]byte)
+			for _, existing := range existingChunks {
+				if isWrappedChunkKey(existing.CipherKey) {
+					wrappedKeys[existing.GetFileIdString()] = existing.CipherKey
+				}
+			}
+		}
+		// the data key of the same chunk does not change, so the entry stays equal
+		if wrappedKey, found := wrappedKeys[chunk.GetFileIdString()]; found {
+			chunk.CipherKey = wrappedKey
+			continue
+		}
+		wrappedKey, err := util.Encrypt(chunk.CipherKey, chunkKeyWrappingKey)
+		if err != nil {
+			return fmt.Errorf("wrap the data key of %s: %v", chunk.GetFileIdString(), err)
+		}
+		chunk.CipherKey = wrappedKey
+	}
+	return nil
+}
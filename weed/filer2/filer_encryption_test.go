@@ -0,0 +1,107 @@
+package filer2
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+	"github.com/chrislusf/seaweedfs/weed/util"
+)
+
+func TestEncryptChunk(t *testing.T) {
+	defer func(key []byte) { chunkKeyWrappingKey = key }(chunkKeyWrappingKey)
+
+	data := []byte("some secret content")
+
+	chunkKeyWrappingKey = nil
+	if _, _, err := EncryptChunk(data); err != ErrChunkKeyUnknown {
+		t.Errorf("encrypt without the key: %v", err)
+	}
+
+	chunkKeyWrappingKey = bytes.Repeat([]byte{7}, 32)
+	ciphertext, wrappedKey, err := EncryptChunk(data)
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	if bytes.Contains(ciphertext, data) || len(wrappedKey) == 0 {
+		t.Errorf("not encrypted")
+	}
+	plaintext, err := DecryptChunk(wrappedKey, ciphertext)
+	if err != nil || !bytes.Equal(plaintext, data) {
+		t.Errorf("decrypt: %q %v", plaintext, err)
+	}
+
+	chunkKeyWrappingKey = bytes.Repeat([]byte{8}, 32)
+	if _, err = DecryptChunk(wrappedKey, ciphertext); err == nil {
+		t.Errorf("decrypted with another key")
+	}
+}
+
+type volumeLookupClient struct {
+	filer_pb.SeaweedFilerClient
+	volumeUrl string
+}
+
+func (c *volumeLookupClient) WithFilerClient(ctx context.Context, fn func(filer_pb.SeaweedFilerClient) error) error {
+	return fn(c)
+}
+
+func (c *volumeLookupClient) LookupVolume(ctx context.Context, in *filer_pb.LookupVolumeRequest, opts ...grpc.CallOption) (*filer_pb.LookupVolumeResponse, error) {
+	resp := &filer_pb.LookupVolumeResponse{LocationsMap: make(map[string]*filer_pb.Locations)}
+	for _, vid := range in.VolumeIds {
+		resp.LocationsMap[vid] = &filer_pb.Locations{Locations: []*filer_pb.Location{{Url: c.volumeUrl}}}
+	}
+	return resp, nil
+}
+
+func TestReadEncryptedChunkInMount(t *testing.T) {
+	defer func(key []byte) { chunkKeyWrappingKey = key }(chunkKeyWrappingKey)
+
+	// the filer encrypts the chunk, and serves the entry with the plain data key
+	chunkKeyWrappingKey = bytes.Repeat([]byte{7}, 32)
+	data := []byte("some secret content read by weed mount")
+	ciphertext, wrappedKey, err := EncryptChunk(data)
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	stored := []*filer_pb.FileChunk{{FileId: "3,01637037d6", Size: uint64(len(data)), Mtime: 1, CipherKey: wrappedKey}}
+	served := UnwrapChunkKeys(stored)
+	if len(served[0].CipherKey) != util.CipherKeySize || !bytes.Equal(stored[0].CipherKey, wrappedKey) {
+		t.Fatalf("served key %d bytes, stored key changed %v", len(served[0].CipherKey), !bytes.Equal(stored[0].CipherKey, wrappedKey))
+	}
+
+	// the mount reads the chunk without the key wrapping key
+	chunkKeyWrappingKey = nil
+	volume := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(ciphertext)
+	}))
+	defer volume.Close()
+	client := &volumeLookupClient{volumeUrl: strings.TrimPrefix(volume.URL, "http://")}
+	buff := make([]byte, len(data))
+	n, err := ReadIntoBuffer(context.Background(), client, "/secret.txt", buff, ViewFromChunks(served, 0, len(data)), 0)
+	if err != nil || n != int64(len(data)) || !bytes.Equal(buff, data) {
+		t.Fatalf("read %d bytes %q: %v", n, buff, err)
+	}
+
+	// the entry saved back by the mount keeps the stored wrapped key
+	chunkKeyWrappingKey = bytes.Repeat([]byte{7}, 32)
+	saved := []*filer_pb.FileChunk{served[0], {FileId: "3,02637037d6", Size: 10, CipherKey: bytes.Repeat([]byte{9}, 32)}}
+	if err = WrapChunkKeys(saved, stored); err != nil {
+		t.Fatalf("wrap: %v", err)
+	}
+	if !bytes.Equal(saved[0].CipherKey, wrappedKey) {
+		t.Errorf("the wrapped key of the existing chunk is not kept")
+	}
+	if !isWrappedChunkKey(saved[1].CipherKey) {
+		t.Errorf("the new data key is not wrapped")
+	}
+	if key, err := unwrapChunkKey(saved[1].CipherKey); err != nil || !bytes.Equal(key, bytes.Repeat([]byte{9}, 32)) {
+		t.Errorf("unwrap the new data key: %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package filer2
+
+import "sync"
+
+// entryLocks serializes the read-modify-write of each entry, like the appends and the patches,
+// while the different entries are changed concurrently.
+type entryLocks struct {
+	sync.Mutex
+	locks map[FullPath]*entryLock
+}
+
+type entryLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock locks the entry, and returns the func to unlock it
+func (l *entryLocks) lock(p FullPath) (unlock func()) {
+	l.Lock()
+	if l.locks == nil {
+		l.locks = make(map[FullPath]*entryLock)
+	}
+	el, found := l.locks[p]
+	if !found {
+		el = &entryLock{}
+		l.locks[p] = el
+	}
+	el.refs++
+	l.Unlock()
+
+	el.Lock()
+	return func() {
+		el.Unlock()
+		l.Lock()
+		el.refs--
+		if el.refs == 0 {
+			delete(l.locks, p)
+		}
+		l.Unlock()
+	}
+}
@@ -0,0 +1,35 @@
+package filer2
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEntryLocks(t *testing.T) {
+	var l entryLocks
+
+	unlockA := l.lock("/a")
+	// another entry is not blocked
+	l.lock("/b")()
+
+	locked, unlocked := make(chan struct{}), make(chan struct{})
+	go func() {
+		unlock := l.lock("/a")
+		close(locked)
+		unlock()
+		close(unlocked)
+	}()
+	select {
+	case <-locked:
+		t.Fatalf("locked the entry twice")
+	case <-time.After(20 * time.Millisecond):
+	}
+	unlockA()
+	<-unlocked
+
+	l.Lock()
+	defer l.Unlock()
+	if len(l.locks) != 0 {
+		t.Errorf("kept %d unused locks", len(l.locks))
+	}
+}
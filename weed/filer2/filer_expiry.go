@@ -0,0 +1,135 @@
+package filer2
+
+import (
+	"context"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+)
+
+// The entries with ExpireAt are hidden once expired, and deleted with their chunks
+// when looked up or listed, or found by the background sweep. The expired directories are deleted recursively.
+// The entries under retention or legal hold are kept until they can be deleted.
+
+const (
+	expiryDeletionWorkers   = 4
+	expiryDeletionQueueSize = 1024
+	// the sweep lists at most expirySweepBatch entries every expirySweepInterval, continuing from where it stopped
+	expirySweepInterval = time.Minute
+	expirySweepBatch    = 10000
+	expirySweepPageSize = 1000
+)
+
+type expiryDeletionKey struct{}
+
+// expirySweep is the position of the background sweep through all directories
+type expirySweep struct {
+	dirs         []FullPath // the directories to visit
+	dir          FullPath   // the directory being visited
+	lastFileName string
+}
+
+func (entry *Entry) IsExpired(now time.Time) bool {
+	return !entry.ExpireAt.IsZero() && !now.Before(entry.ExpireAt)
+}
+
+// isExpired tells whether the entry should be hidden and deleted
+func (f *Filer) isExpired(ctx context.Context, entry *Entry) bool {
+	if entry == nil || !entry.IsExpired(time.Now()) || ctx.Value(expiryDeletionKey{}) != nil {
+		return false
+	}
+	return f.CheckDeletion(ctx, entry) == nil
+}
+
+// deleteExpiredEntry queues the entry to be deleted in the background, unless it is already queued.
+// When the queue is full, the entry is left to be found again later.
+func (f *Filer) deleteExpiredEntry(p FullPath) {
+	if _, loaded := f.expiring.LoadOrStore(p, true); loaded {
+		return
+	}
+	select {
+	case f.expiredEntries <- p:
+	default:
+		f.expiring.Delete(p)
+	}
+}
+
+func (f *Filer) loopDeletingExpiredEntries() {
+	for p := range f.expiredEntries {
+		f.doDeleteExpiredEntry(p)
+		f.expiring.Delete(p)
+	}
+}
+
+func (f *Filer) doDeleteExpiredEntry(p FullPath) {
+	ctx := context.WithValue(context.Background(), expiryDeletionKey{}, true)
+	// the entry could be written again since found expired
+	entry, err := f.store.FindEntry(ctx, p)
+	if err != nil || !entry.IsExpired(time.Now()) {
+		return
+	}
+	glog.V(2).Infof("delete %s expired at %v", p, entry.ExpireAt)
+	if err = f.DeleteEntryMetaAndData(ctx, p, true, true); err != nil && err != ErrNotFound {
+		glog.V(0).Infof("delete expired %s: %v", p, err)
+	}
+}
+
+// loopSweepingExpiredEntries finds the expired entries never looked up or listed
+func (f *Filer) loopSweepingExpiredEntries() {
+	ticker := time.NewTicker(expirySweepInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if f.store == nil {
+			continue
+		}
+		f.sweepExpiredEntries(context.Background(), expirySweepBatch)
+	}
+}
+
+// sweepExpiredEntries lists up to the limit of entries, which queues the expired ones to be deleted.
+// A new round from the root starts after the previous round has visited all directories.
+func (f *Filer) sweepExpiredEntries(ctx context.Context, limit int) (isRoundDone bool) {
+	s := &f.sweep
+	for listed := 0; listed < limit; {
+		if s.dir == "" {
+			if len(s.dirs) == 0 {
+				s.dirs = []FullPath{"/"}
+				if isRoundDone {
+					return
+				}
+			}
+			s.dir, s.dirs = s.dirs[len(s.dirs)-1], s.dirs[:len(s.dirs)-1]
+			s.lastFileName = ""
+		}
+		entries, err := f.ListDirectoryEntries(ctx, s.dir, s.lastFileName, false, expirySweepPageSize)
+		if err != nil {
+			glog.V(1).Infof("sweep expired entries in %s: %v", s.dir, err)
+		}
+		for _, entry := range entries {
+			if entry.IsDirectory() {
+				s.dirs = append(s.dirs, entry.FullPath)
+			}
+			s.lastFileName = entry.Name()
+		}
+		listed += len(entries)
+		if err != nil || len(entries) < expirySweepPageSize {
+			s.dir = ""
+			isRoundDone = len(s.dirs) == 0
+		}
+	}
+	return
+}
+
+// removeExpiredEntries filters out the expired entries, and deletes them
+func (f *Filer) removeExpiredEntries(ctx context.Context, entries []*Entry) (live []*Entry, expiredCount int) {
+	live = entries[:0]
+	for _, entry := range entries {
+		if f.isExpired(ctx, entry) {
+			f.deleteExpiredEntry(entry.FullPath)
+			expiredCount++
+			continue
+		}
+		live = append(live, entry)
+	}
+	return
+}
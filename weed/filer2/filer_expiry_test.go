@@ -0,0 +1,67 @@
+package filer2
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// listingStore lists the entries kept in memory
+type listingStore struct {
+	FilerStore
+	entries []*Entry
+}
+
+func (s *listingStore) GetName() string {
+	return "listing"
+}
+
+func (s *listingStore) ListDirectoryEntries(ctx context.Context, dirPath FullPath, startFileName string, includeStartFile bool, limit int) (entries []*Entry, err error) {
+	for _, entry := range s.entries {
+		dir, name := entry.FullPath.DirAndName()
+		if dir != string(dirPath) || name < startFileName || name == startFileName && !includeStartFile {
+			continue
+		}
+		if len(entries) >= limit {
+			break
+		}
+		entries = append(entries, entry)
+	}
+	return
+}
+
+func TestSweepExpiredEntries(t *testing.T) {
+	now := time.Now()
+	store := &listingStore{}
+	for _, p := range []string{"/a", "/a/x", "/a/y", "/b", "/b/z", "/c"} {
+		entry := &Entry{FullPath: FullPath(p), Attr: Attr{Mode: 0644}}
+		if p == "/a" || p == "/b" {
+			entry.Mode |= os.ModeDir
+		}
+		if strings.HasSuffix(p, "x") || strings.HasSuffix(p, "z") {
+			entry.ExpireAt = now.Add(-time.Minute)
+		}
+		store.entries = append(store.entries, entry)
+	}
+	f := &Filer{expiredEntries: make(chan FullPath, 10)}
+	f.SetStore(store)
+
+	// the batch is smaller than all entries, so the round is done by the second sweep
+	if isRoundDone := f.sweepExpiredEntries(context.Background(), 2); isRoundDone {
+		t.Errorf("round done after listing 2 entries")
+	}
+	for i := 0; i < 3 && !f.sweepExpiredEntries(context.Background(), 2); i++ {
+	}
+	close(f.expiredEntries)
+	var expired []string
+	for p := range f.expiredEntries {
+		expired = append(expired, string(p))
+	}
+	sort.Strings(expired)
+	if strings.Join(expired, ",") != "/a/x,/b/z" {
+		t.Errorf("expired entries %v", expired)
+	}
+}
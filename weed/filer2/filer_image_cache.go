@@ -0,0 +1,93 @@
+package filer2
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+)
+
+// ImageCacheDir keeps the transformed images, as the file entries named by the hash of the original content
+// and the transform:
+//
+//	/.image_cache/<sha256>
+//
+// The entries are written to the store directly, without the metadata events.
+// The originals are identified by the content, so the changed files just miss the cache,
+// and the same images at different paths share the cached results.
+const ImageCacheDir = FullPath("/.image_cache")
+
+func ImageCacheKey(entry *Entry, transform string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%d\x00%s", ETag(entry.Chunks), entry.Size(), transform)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// FindImageCache returns the cached image of the key, or nil if not cached or expired
+func (f *Filer) FindImageCache(ctx context.Context, key string) (*Entry, error) {
+	entry, err := f.store.FindEntry(ctx, ImageCacheDir.Child(key))
+	if err == ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find image cache %s: %v", key, err)
+	}
+	if entry.TtlSec > 0 && time.Since(entry.Crtime) > time.Duration(entry.TtlSec)*time.Second {
+		f.DeleteImageCache(ctx, entry)
+		return nil, nil
+	}
+	return entry, nil
+}
+
+// SaveImageCache records the chunk of the transformed image
+func (f *Filer) SaveImageCache(ctx context.Context, key string, mimeType string, ttlSec int32, chunk *filer_pb.FileChunk) error {
+	if _, err := f.store.FindEntry(ctx, ImageCacheDir); err == ErrNotFound {
+		now := time.Now()
+		dir := &Entry{
+			FullPath: ImageCacheDir,
+			Attr: Attr{
+				Mtime:  now,
+				Crtime: now,
+				Mode:   os.ModeDir | 0700,
+				Uid:    OS_UID,
+				Gid:    OS_GID,
+			},
+		}
+		if err = f.store.InsertEntry(ctx, dir); err != nil {
+			return fmt.Errorf("create %s: %v", ImageCacheDir, err)
+		}
+	}
+
+	now := time.Now()
+	entry := &Entry{
+		FullPath: ImageCacheDir.Child(key),
+		Attr: Attr{
+			Mtime:  now,
+			Crtime: now,
+			Mode:   0644,
+			Uid:    OS_UID,
+			Gid:    OS_GID,
+			Mime:   mimeType,
+			TtlSec: ttlSec,
+		},
+		Chunks: []*filer_pb.FileChunk{chunk},
+	}
+	if err := f.store.InsertEntry(ctx, entry); err != nil {
+		return fmt.Errorf("save image cache %s: %v", key, err)
+	}
+	return nil
+}
+
+// DeleteImageCache deletes the cached image with its chunks, when expired or not readable
+func (f *Filer) DeleteImageCache(ctx context.Context, entry *Entry) {
+	if err := f.store.DeleteEntry(ctx, entry.FullPath); err != nil {
+		glog.V(0).Infof("delete image cache %s: %v", entry.FullPath, err)
+		return
+	}
+	f.DeleteChunks(entry.FullPath, entry.Chunks)
+}
@@ -0,0 +1,343 @@
+package filer2
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/spf13/viper"
+)
+
+const inventoryChunkSize = 8 * 1024 * 1024
+
+const (
+	InventoryFormatCsv     = "csv"
+	InventoryFormatParquet = "parquet"
+)
+
+// the storage class of the entries without one, as listed by S3
+const defaultStorageClass = "STANDARD"
+
+// ExtendedStorageClass is the extended attribute of the storage class requested by the S3 clients
+const ExtendedStorageClass = "x-amz-storage-class"
+
+var inventorySchema = []string{"Bucket", "Key", "Size", "LastModifiedDate", "ETag", "StorageClass", "Collection", "Replication", "TtlSec"}
+
+type InventoryOption struct {
+	SourceDirectory string // each sub directory, e.g. a bucket, gets its own inventory
+	TargetDirectory string
+	Interval        time.Duration
+	Format          string
+	Collection      string
+	Replication     string
+}
+
+// InventoryManifest is saved as manifest.json next to the generated data files, similar to S3 Inventory.
+type InventoryManifest struct {
+	SourceBucket      string                  `json:"sourceBucket"`
+	DestinationPath   string                  `json:"destinationPath"`
+	FileFormat        string                  `json:"fileFormat"`
+	FileSchema        string                  `json:"fileSchema"`
+	CreationTimestamp int64                   `json:"creationTimestamp"`
+	ObjectCount       int64                   `json:"objectCount"`
+	TotalSize         uint64                  `json:"totalSize"`
+	Files             []InventoryManifestFile `json:"files"`
+}
+
+type InventoryManifestFile struct {
+	Key  string `json:"key"`
+	Size uint64 `json:"size"`
+	ETag string `json:"eTag"`
+}
+
+type inventoryRow struct {
+	Bucket       string
+	Key          string
+	Size         uint64
+	LastModified time.Time
+	ETag         string
+	StorageClass string
+	Collection   string
+	Replication  string
+	TtlSec       int32
+}
+
+// inventoryWriter writes the rows of the inventory data file in one format
+type inventoryWriter interface {
+	write(row *inventoryRow) error
+	Close() error
+}
+
+// LoadInventoryConfiguration starts generating the inventory now, and then periodically
+func (f *Filer) LoadInventoryConfiguration(config *viper.Viper) error {
+
+	if config == nil || !config.GetBool("enabled") {
+		return nil
+	}
+
+	option := &InventoryOption{
+		SourceDirectory: config.GetString("source"),
+		TargetDirectory: config.GetString("target"),
+		Interval:        time.Duration(config.GetInt("interval_hours")) * time.Hour,
+		Format:          config.GetString("format"),
+		Collection:      config.GetString("collection"),
+		Replication:     config.GetString("replication"),
+	}
+	if option.SourceDirectory == "" || option.TargetDirectory == "" {
+		return fmt.Errorf("inventory requires both source and target directories")
+	}
+	if option.Interval <= 0 {
+		option.Interval = 24 * time.Hour
+	}
+	switch option.Format {
+	case "":
+		option.Format = InventoryFormatCsv
+	case InventoryFormatCsv, InventoryFormatParquet:
+	default:
+		return fmt.Errorf("inventory format %s is not supported, only %s or %s", option.Format, InventoryFormatCsv, InventoryFormatParquet)
+	}
+
+	glog.V(0).Infof("generate %s inventory of %s into %s every %v", option.Format, option.SourceDirectory, option.TargetDirectory, option.Interval)
+
+	go f.loopGeneratingInventory(option)
+
+	return nil
+}
+
+func (f *Filer) loopGeneratingInventory(option *InventoryOption) {
+	for {
+		if err := f.GenerateInventory(context.Background(), option); err != nil {
+			glog.Errorf("generate inventory of %s: %v", option.SourceDirectory, err)
+		}
+		time.Sleep(option.Interval)
+	}
+}
+
+// GenerateInventory writes one listing and manifest for each sub directory of the source directory
+func (f *Filer) GenerateInventory(ctx context.Context, option *InventoryOption) error {
+
+	now := time.Now()
+
+	return f.listAllEntries(ctx, FullPath(option.SourceDirectory), false, func(entry *Entry) error {
+		if !entry.IsDirectory() {
+			return nil
+		}
+		bucket := entry.Name()
+		if FullPath(option.TargetDirectory) == entry.FullPath {
+			return nil
+		}
+		if err := f.generateBucketInventory(ctx, option, bucket, entry.FullPath, now); err != nil {
+			return fmt.Errorf("inventory %s: %v", entry.FullPath, err)
+		}
+		return nil
+	})
+
+}
+
+func (f *Filer) generateBucketInventory(ctx context.Context, option *InventoryOption, bucket string, bucketPath FullPath, now time.Time) error {
+
+	destination := FullPath(option.TargetDirectory).Child(bucket).Child(now.UTC().Format("2006-01-02T15-04Z"))
+	dataPath := destination.Child("data." + option.Format)
+
+	chunkWriter := f.NewChunkWriter(string(dataPath), option.Collection, option.Replication, inventoryChunkSize)
+	dataWriter, fileFormat, mime, err := newInventoryWriter(chunkWriter, option.Format)
+	if err != nil {
+		return err
+	}
+
+	manifest := &InventoryManifest{
+		SourceBucket:      bucket,
+		DestinationPath:   string(destination),
+		FileFormat:        fileFormat,
+		FileSchema:        strings.Join(inventorySchema, ", "),
+		CreationTimestamp: now.UnixNano() / int64(time.Millisecond),
+	}
+
+	err = f.listAllEntries(ctx, bucketPath, true, func(entry *Entry) error {
+		if entry.IsDirectory() {
+			return nil
+		}
+		manifest.ObjectCount++
+		manifest.TotalSize += entry.Size()
+		return dataWriter.write(newInventoryRow(bucket, bucketPath, entry))
+	})
+	if err == nil {
+		err = dataWriter.Close()
+	}
+	if err == nil {
+		err = chunkWriter.Close()
+	}
+	if err != nil {
+		f.DeleteChunks(dataPath, chunkWriter.Chunks)
+		return err
+	}
+
+	dataEntry := f.newGeneratedEntry(dataPath, option, mime)
+	dataEntry.Chunks = chunkWriter.Chunks
+	if err = f.CreateEntry(ctx, dataEntry); err != nil {
+		f.DeleteChunks(dataPath, chunkWriter.Chunks)
+		return err
+	}
+	manifest.Files = append(manifest.Files, InventoryManifestFile{
+		Key:  string(dataPath),
+		Size: dataEntry.Size(),
+		ETag: ETag(dataEntry.Chunks),
+	})
+
+	manifestPath := destination.Child("manifest.json")
+	manifestBytes, _ := json.MarshalIndent(manifest, "", "  ")
+	manifestWriter := f.NewChunkWriter(string(manifestPath), option.Collection, option.Replication, inventoryChunkSize)
+	manifestWriter.Write(manifestBytes)
+	if err = manifestWriter.Close(); err != nil {
+		return err
+	}
+	manifestEntry := f.newGeneratedEntry(manifestPath, option, "application/json")
+	manifestEntry.Chunks = manifestWriter.Chunks
+	if err = f.CreateEntry(ctx, manifestEntry); err != nil {
+		f.DeleteChunks(manifestPath, manifestWriter.Chunks)
+		return err
+	}
+
+	glog.V(1).Infof("inventory of %s: %d objects, %d bytes, saved to %s", bucketPath, manifest.ObjectCount, manifest.TotalSize, destination)
+
+	return nil
+}
+
+func newInventoryRow(bucket string, bucketPath FullPath, entry *Entry) *inventoryRow {
+	storageClass := defaultStorageClass
+	if value, found := entry.Extended[ExtendedStorageClass]; found && len(value) > 0 {
+		storageClass = string(value)
+	}
+	return &inventoryRow{
+		Bucket:       bucket,
+		Key:          string(entry.FullPath[len(bucketPath)+1:]),
+		Size:         entry.Size(),
+		LastModified: entry.Mtime,
+		ETag:         ETag(entry.Chunks),
+		StorageClass: storageClass,
+		Collection:   entry.Collection,
+		Replication:  entry.Replication,
+		TtlSec:       entry.TtlSec,
+	}
+}
+
+// newInventoryWriter returns the writer of the format, with the format name in the manifest and the mime type
+func newInventoryWriter(w io.Writer, format string) (dataWriter inventoryWriter, fileFormat, mime string, err error) {
+	switch format {
+	case InventoryFormatCsv:
+		csvWriter := csv.NewWriter(w)
+		if err = csvWriter.Write(inventorySchema); err != nil {
+			return nil, "", "", err
+		}
+		return &csvInventoryWriter{csvWriter}, "CSV", "text/csv", nil
+	case InventoryFormatParquet:
+		var columns []*parquetColumn
+		for _, name := range inventorySchema {
+			switch name {
+			case "Size", "TtlSec":
+				columns = append(columns, newParquetInt64Column(name, parquetNoConvertedType))
+			case "LastModifiedDate":
+				columns = append(columns, newParquetInt64Column(name, parquetTimestampMillis))
+			default:
+				columns = append(columns, newParquetStringColumn(name))
+			}
+		}
+		parquetWriter, err := newParquetWriter(w, columns)
+		if err != nil {
+			return nil, "", "", err
+		}
+		return &parquetInventoryWriter{parquetWriter}, "Parquet", "application/octet-stream", nil
+	}
+	return nil, "", "", fmt.Errorf("inventory format %s is not supported", format)
+}
+
+type csvInventoryWriter struct {
+	*csv.Writer
+}
+
+func (w *csvInventoryWriter) write(row *inventoryRow) error {
+	w.Writer.Write([]string{
+		row.Bucket,
+		row.Key,
+		strconv.FormatUint(row.Size, 10),
+		row.LastModified.UTC().Format(time.RFC3339),
+		row.ETag,
+		row.StorageClass,
+		row.Collection,
+		row.Replication,
+		strconv.Itoa(int(row.TtlSec)),
+	})
+	w.Flush()
+	return w.Error()
+}
+
+func (w *csvInventoryWriter) Close() error {
+	w.Flush()
+	return w.Error()
+}
+
+type parquetInventoryWriter struct {
+	*parquetWriter
+}
+
+func (w *parquetInventoryWriter) write(row *inventoryRow) error {
+	return w.writeRow(
+		row.Bucket,
+		row.Key,
+		int64(row.Size),
+		row.LastModified.UnixNano()/int64(time.Millisecond),
+		row.ETag,
+		row.StorageClass,
+		row.Collection,
+		row.Replication,
+		int64(row.TtlSec),
+	)
+}
+
+func (f *Filer) newGeneratedEntry(fullpath FullPath, option *InventoryOption, mime string) *Entry {
+	now := time.Now()
+	return &Entry{
+		FullPath: fullpath,
+		Attr: Attr{
+			Mtime:       now,
+			Crtime:      now,
+			Mode:        0644,
+			Uid:         OS_UID,
+			Gid:         OS_GID,
+			Mime:        mime,
+			Collection:  option.Collection,
+			Replication: option.Replication,
+		},
+	}
+}
+
+// listAllEntries visits every entry under the directory, optionally recursively
+func (f *Filer) listAllEntries(ctx context.Context, dir FullPath, recursive bool, fn func(entry *Entry) error) error {
+	lastFileName := ""
+	for {
+		entries, err := f.ListDirectoryEntries(ctx, dir, lastFileName, false, 1024)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err = fn(entry); err != nil {
+				return err
+			}
+			if recursive && entry.IsDirectory() {
+				if err = f.listAllEntries(ctx, entry.FullPath, recursive, fn); err != nil {
+					return err
+				}
+			}
+			lastFileName = entry.Name()
+		}
+		if len(entries) < 1024 {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package filer2
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// parquetWriter writes a parquet file of flat required columns, plain encoded and uncompressed,
+// buffering the rows of one row group in memory, with one data page per column chunk.
+// https://github.com/apache/parquet-format
+type parquetWriter struct {
+	w         io.Writer
+	offset    int64
+	columns   []*parquetColumn
+	rows      int // in the buffered row group
+	totalRows int64
+	rowGroups []parquetRowGroup
+}
+
+const (
+	parquetMagic        = "PAR1"
+	parquetRowGroupRows = 64 * 1024
+	parquetCreatedBy    = "seaweedfs"
+)
+
+// the physical types
+const (
+	parquetInt64     = 2
+	parquetByteArray = 6
+)
+
+// the converted types, or parquetNoConvertedType
+const (
+	parquetNoConvertedType = -1
+	parquetUtf8            = 0
+	parquetTimestampMillis = 9
+)
+
+// the thrift compact protocol types
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+type parquetColumn struct {
+	name          string
+	physicalType  int32
+	convertedType int32
+	values        bytes.Buffer // plain encoded values of the buffered row group
+}
+
+type parquetColumnChunk struct {
+	dataPageOffset int64
+	size           int64
+}
+
+type parquetRowGroup struct {
+	columns []parquetColumnChunk
+	size    int64
+	rows    int64
+}
+
+func newParquetWriter(w io.Writer, columns []*parquetColumn) (*parquetWriter, error) {
+	p := &parquetWriter{w: w, columns: columns}
+	return p, p.write([]byte(parquetMagic))
+}
+
+func newParquetStringColumn(name string) *parquetColumn {
+	return &parquetColumn{name: name, physicalType: parquetByteArray, convertedType: parquetUtf8}
+}
+
+func newParquetInt64Column(name string, convertedType int32) *parquetColumn {
+	return &parquetColumn{name: name, physicalType: parquetInt64, convertedType: convertedType}
+}
+
+// writeRow appends one value for each column, a string for the byte array columns, or an int64
+func (p *parquetWriter) writeRow(values ...interface{}) error {
+	if len(values) != len(p.columns) {
+		return fmt.Errorf("parquet row has %d values for %d columns", len(values), len(p.columns))
+	}
+	for i, value := range values {
+		column := p.columns[i]
+		switch v := value.(type) {
+		case string:
+			if column.physicalType != parquetByteArray {
+				return fmt.Errorf("parquet column %s expects int64, got string", column.name)
+			}
+			var length [4]byte
+			binary.LittleEndian.PutUint32(length[:], uint32(len(v)))
+			column.values.Write(length[:])
+			column.values.WriteString(v)
+		case int64:
+			if column.physicalType != parquetInt64 {
+				return fmt.Errorf("parquet column %s expects string, got int64", column.name)
+			}
+			var buf [8]byte
+			binary.LittleEndian.PutUint64(buf[:], uint64(v))
+			column.values.Write(buf[:])
+		default:
+			return fmt.Errorf("parquet column %s: unsupported value %T", column.name, value)
+		}
+	}
+	p.rows++
+	if p.rows >= parquetRowGroupRows {
+		return p.flushRowGroup()
+	}
+	return nil
+}
+
+func (p *parquetWriter) flushRowGroup() error {
+	if p.rows == 0 {
+		return nil
+	}
+	rowGroup := parquetRowGroup{rows: int64(p.rows)}
+	for _, column := range p.columns {
+		header := &thriftWriter{}
+		header.i32(1, 0) // DATA_PAGE
+		header.i32(2, int32(column.values.Len()))
+		header.i32(3, int32(column.values.Len()))
+		header.beginStruct(5)
+		header.i32(1, int32(p.rows))
+		header.i32(2, 0) // PLAIN
+		header.i32(3, 3) // RLE, no definition levels for the required columns
+		header.i32(4, 3) // RLE, no repetition levels for the flat columns
+		header.endStruct()
+		header.stop()
+
+		chunk := parquetColumnChunk{
+			dataPageOffset: p.offset,
+			size:           int64(header.Len() + column.values.Len()),
+		}
+		if err := p.write(header.Bytes()); err != nil {
+			return err
+		}
+		if err := p.write(column.values.Bytes()); err != nil {
+			return err
+		}
+		column.values.Reset()
+		rowGroup.columns = append(rowGroup.columns, chunk)
+		rowGroup.size += chunk.size
+	}
+	p.rowGroups = append(p.rowGroups, rowGroup)
+	p.totalRows += int64(p.rows)
+	p.rows = 0
+	return nil
+}
+
+// Close writes the buffered rows and the file metadata
+func (p *parquetWriter) Close() error {
+	if err := p.flushRowGroup(); err != nil {
+		return err
+	}
+
+	meta := &thriftWriter{}
+	meta.i32(1, 1) // version
+	meta.beginList(2, thriftStruct, len(p.columns)+1)
+	meta.beginElement()
+	meta.binary(4, "schema")
+	meta.i32(5, int32(len(p.columns)))
+	meta.endStruct()
+	for _, column := range p.columns {
+		meta.beginElement()
+		meta.i32(1, column.physicalType)
+		meta.i32(3, 0) // REQUIRED
+		meta.binary(4, column.name)
+		if column.convertedType != parquetNoConvertedType {
+			meta.i32(6, column.convertedType)
+		}
+		meta.endStruct()
+	}
+	meta.i64(3, p.totalRows)
+	meta.beginList(4, thriftStruct, len(p.rowGroups))
+	for _, rowGroup := range p.rowGroups {
+		meta.beginElement()
+		meta.beginList(1, thriftStruct, len(rowGroup.columns))
+		for i, chunk := range rowGroup.columns {
+			column := p.columns[i]
+			meta.beginElement()
+			meta.i64(2, chunk.dataPageOffset)
+			meta.beginStruct(3)
+			meta.i32(1, column.physicalType)
+			meta.beginList(2, thriftI32, 1)
+			meta.varint(0) // PLAIN
+			meta.beginList(3, thriftBinary, 1)
+			meta.lengthPrefixed(column.name)
+			meta.i32(4, 0) // UNCOMPRESSED
+			meta.i64(5, rowGroup.rows)
+			meta.i64(6, chunk.size)
+			meta.i64(7, chunk.size)
+			meta.i64(9, chunk.dataPageOffset)
+			meta.endStruct()
+			meta.endStruct()
+		}
+		meta.i64(2, rowGroup.size)
+		meta.i64(3, rowGroup.rows)
+		meta.endStruct()
+	}
+	meta.binary(6, parquetCreatedBy)
+	meta.stop()
+
+	var footer [8]byte
+	binary.LittleEndian.PutUint32(footer[:4], uint32(meta.Len()))
+	copy(footer[4:], parquetMagic)
+	if err := p.write(meta.Bytes()); err != nil {
+		return err
+	}
+	return p.write(footer[:])
+}
+
+func (p *parquetWriter) write(data []byte) error {
+	n, err := p.w.Write(data)
+	p.offset += int64(n)
+	return err
+}
+
+// thriftWriter encodes the parquet metadata in the thrift compact protocol
+type thriftWriter struct {
+	bytes.Buffer
+	lastFieldId  int16
+	parentFields []int16
+}
+
+func (t *thriftWriter) fieldHeader(id int16, fieldType byte) {
+	if delta := id - t.lastFieldId; delta > 0 && delta <= 15 {
+		t.WriteByte(byte(delta)<<4 | fieldType)
+	} else {
+		t.WriteByte(fieldType)
+		t.varint(int64(id))
+	}
+	t.lastFieldId = id
+}
+
+// varint writes the zigzag encoded integer
+func (t *thriftWriter) varint(v int64) {
+	var buf [binary.MaxVarintLen64]byte
+	t.Write(buf[:binary.PutVarint(buf[:], v)])
+}
+
+func (t *thriftWriter) lengthPrefixed(s string) {
+	var buf [binary.MaxVarintLen64]byte
+	t.Write(buf[:binary.PutUvarint(buf[:], uint64(len(s)))])
+	t.WriteString(s)
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.fieldHeader(id, thriftI32)
+	t.varint(int64(v))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.fieldHeader(id, thriftI64)
+	t.varint(v)
+}
+
+func (t *thriftWriter) binary(id int16, s string) {
+	t.fieldHeader(id, thriftBinary)
+	t.lengthPrefixed(s)
+}
+
+func (t *thriftWriter) beginList(id int16, elementType byte, size int) {
+	t.fieldHeader(id, thriftList)
+	if size < 15 {
+		t.WriteByte(byte(size)<<4 | elementType)
+		return
+	}
+	t.WriteByte(0xf0 | elementType)
+	var buf [binary.MaxVarintLen64]byte
+	t.Write(buf[:binary.PutUvarint(buf[:], uint64(size))])
+}
+
+func (t *thriftWriter) beginStruct(id int16) {
+	t.fieldHeader(id, thriftStruct)
+	t.beginElement()
+}
+
+// beginElement starts a struct in a list
+func (t *thriftWriter) beginElement() {
+	t.parentFields = append(t.parentFields, t.lastFieldId)
+	t.lastFieldId = 0
+}
+
+func (t *thriftWriter) endStruct() {
+	t.stop()
+	t.lastFieldId = t.parentFields[len(t.parentFields)-1]
+	t.parentFields = t.parentFields[:len(t.parentFields)-1]
+}
+
+func (t *thriftWriter) stop() {
+	t.WriteByte(0)
+}
@@ -0,0 +1,214 @@
+package filer2
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/csv"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestLoadInventoryConfiguration(t *testing.T) {
+	f := &Filer{}
+
+	config := viper.New()
+	if err := f.LoadInventoryConfiguration(config); err != nil {
+		t.Errorf("disabled inventory: %v", err)
+	}
+
+	config.Set("enabled", true)
+	config.Set("source", "/buckets")
+	if err := f.LoadInventoryConfiguration(config); err == nil {
+		t.Errorf("loaded without the target directory")
+	}
+
+	config.Set("target", "/inventory")
+	config.Set("format", "orc")
+	if err := f.LoadInventoryConfiguration(config); err == nil {
+		t.Errorf("loaded the unsupported format")
+	}
+}
+
+var testInventoryRows = []*inventoryRow{
+	{Bucket: "b1", Key: "a/1.txt", Size: 3, LastModified: time.Unix(1500000000, 0), ETag: "etag1", StorageClass: defaultStorageClass, Collection: "b1", TtlSec: 60},
+	{Bucket: "b1", Key: "b.txt", Size: 1 << 40, LastModified: time.Unix(1600000000, 0), ETag: "etag2", StorageClass: "GLACIER", Replication: "001"},
+}
+
+func TestCsvInventory(t *testing.T) {
+	var buf bytes.Buffer
+	w, fileFormat, _, err := newInventoryWriter(&buf, InventoryFormatCsv)
+	if err != nil {
+		t.Fatalf("new writer: %v", err)
+	}
+	for _, row := range testInventoryRows {
+		if err = w.write(row); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if err = w.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if fileFormat != "CSV" {
+		t.Errorf("file format %s", fileFormat)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	if len(records) != 3 || records[0][5] != "StorageClass" {
+		t.Fatalf("unexpected csv %v", records)
+	}
+	if records[2][1] != "b.txt" || records[2][2] != "1099511627776" || records[2][5] != "GLACIER" || records[2][7] != "001" {
+		t.Errorf("unexpected row %v", records[2])
+	}
+}
+
+func TestParquetInventory(t *testing.T) {
+	var buf bytes.Buffer
+	w, fileFormat, _, err := newInventoryWriter(&buf, InventoryFormatParquet)
+	if err != nil {
+		t.Fatalf("new writer: %v", err)
+	}
+	for _, row := range testInventoryRows {
+		if err = w.write(row); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if err = w.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if fileFormat != "Parquet" {
+		t.Errorf("file format %s", fileFormat)
+	}
+
+	data := buf.Bytes()
+	if string(data[:4]) != parquetMagic || string(data[len(data)-4:]) != parquetMagic {
+		t.Fatalf("missing the parquet magic")
+	}
+	metaSize := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	meta := newThriftReader(data[len(data)-8-metaSize : len(data)-8]).readStruct()
+
+	if numRows := meta[3].(int64); numRows != int64(len(testInventoryRows)) {
+		t.Errorf("num rows %d", numRows)
+	}
+	schema := meta[2].([]interface{})
+	if len(schema) != len(inventorySchema)+1 {
+		t.Fatalf("schema has %d elements", len(schema))
+	}
+	for i, name := range inventorySchema {
+		if element := schema[i+1].(map[int16]interface{}); string(element[4].([]byte)) != name {
+			t.Errorf("column %d is %s, expected %s", i, element[4], name)
+		}
+	}
+
+	rowGroups := meta[4].([]interface{})
+	if len(rowGroups) != 1 {
+		t.Fatalf("%d row groups", len(rowGroups))
+	}
+	chunks := rowGroups[0].(map[int16]interface{})[1].([]interface{})
+	readColumn := func(i int) *bytes.Reader {
+		columnMeta := chunks[i].(map[int16]interface{})[3].(map[int16]interface{})
+		r := newThriftReader(data[columnMeta[9].(int64):])
+		pageHeader := r.readStruct()
+		if numValues := pageHeader[5].(map[int16]interface{})[1].(int64); numValues != int64(len(testInventoryRows)) {
+			t.Errorf("column %d has %d values", i, numValues)
+		}
+		return bytes.NewReader(r.data[r.pos : r.pos+int(pageHeader[2].(int64))])
+	}
+
+	keys := readColumn(1)
+	for _, row := range testInventoryRows {
+		var length uint32
+		binary.Read(keys, binary.LittleEndian, &length)
+		key := make([]byte, length)
+		keys.Read(key)
+		if string(key) != row.Key {
+			t.Errorf("key %s, expected %s", key, row.Key)
+		}
+	}
+	sizes := readColumn(2)
+	for _, row := range testInventoryRows {
+		var size int64
+		binary.Read(sizes, binary.LittleEndian, &size)
+		if uint64(size) != row.Size {
+			t.Errorf("size %d, expected %d", size, row.Size)
+		}
+	}
+	dates := readColumn(3)
+	for _, row := range testInventoryRows {
+		var millis int64
+		binary.Read(dates, binary.LittleEndian, &millis)
+		if millis != row.LastModified.Unix()*1000 {
+			t.Errorf("last modified %d, expected %v", millis, row.LastModified)
+		}
+	}
+}
+
+// thriftReader decodes the thrift compact protocol, into the field id to value maps for the structs
+type thriftReader struct {
+	data []byte
+	pos  int
+}
+
+func newThriftReader(data []byte) *thriftReader {
+	return &thriftReader{data: data}
+}
+
+func (r *thriftReader) readStruct() map[int16]interface{} {
+	fields := make(map[int16]interface{})
+	var lastFieldId int16
+	for {
+		header := r.data[r.pos]
+		r.pos++
+		if header == 0 {
+			return fields
+		}
+		fieldType := header & 0x0f
+		if delta := int16(header >> 4); delta != 0 {
+			lastFieldId += delta
+		} else {
+			lastFieldId = int16(r.readVarint())
+		}
+		fields[lastFieldId] = r.readValue(fieldType)
+	}
+}
+
+func (r *thriftReader) readValue(valueType byte) interface{} {
+	switch valueType {
+	case thriftI32, thriftI64:
+		return r.readVarint()
+	case thriftBinary:
+		length, n := binary.Uvarint(r.data[r.pos:])
+		r.pos += n
+		value := r.data[r.pos : r.pos+int(length)]
+		r.pos += int(length)
+		return value
+	case thriftList:
+		header := r.data[r.pos]
+		r.pos++
+		size, elementType := int(header>>4), header&0x0f
+		if size == 15 {
+			length, n := binary.Uvarint(r.data[r.pos:])
+			r.pos += n
+			size = int(length)
+		}
+		var list []interface{}
+		for i := 0; i < size; i++ {
+			list = append(list, r.readValue(elementType))
+		}
+		return list
+	case thriftStruct:
+		return r.readStruct()
+	}
+	panic(fmt.Sprintf("unexpected thrift type %d", valueType))
+}
+
+func (r *thriftReader) readVarint() int64 {
+	value, n := binary.Varint(r.data[r.pos:])
+	r.pos += n
+	return value
+}
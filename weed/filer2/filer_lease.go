@@ -0,0 +1,116 @@
+package filer2
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// LeasedError is returned when writing, deleting or renaming the file leased by another holder
+type LeasedError struct {
+	FullPath FullPath
+	Holder   string
+}
+
+func (e *LeasedError) Error() string {
+	return fmt.Sprintf("%s is leased by %s", e.FullPath, e.Holder)
+}
+
+func IsLeased(err error) bool {
+	_, ok := err.(*LeasedError)
+	return ok
+}
+
+type leaseHolderKey struct{}
+
+// WithLeaseHolder writes as the holder of the leases, e.g. the hdfs client appending to the file
+func WithLeaseHolder(ctx context.Context, holder string) context.Context {
+	if holder == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, leaseHolderKey{}, holder)
+}
+
+func leaseHolder(ctx context.Context) string {
+	holder, _ := ctx.Value(leaseHolderKey{}).(string)
+	return holder
+}
+
+type fileLease struct {
+	holder   string
+	expireAt time.Time
+}
+
+// fileLeases are the leases of the files being written.
+// The leases are kept in the memory of this filer, so all writers of a file should go through the same filer.
+type fileLeases struct {
+	sync.Mutex
+	leases map[FullPath]fileLease
+}
+
+// AcquireLease lets only the holder write, delete or rename the file until the lease is released or expired.
+// The holder renews the lease by acquiring it again.
+func (f *Filer) AcquireLease(p FullPath, holder string, period time.Duration) (expireAt time.Time, err error) {
+	l := &f.leases
+	l.Lock()
+	defer l.Unlock()
+
+	now := time.Now()
+	if lease, found := l.leases[p]; found && lease.holder != holder && now.Before(lease.expireAt) {
+		return time.Time{}, &LeasedError{p, lease.holder}
+	}
+	if l.leases == nil {
+		l.leases = make(map[FullPath]fileLease)
+	}
+	for path, lease := range l.leases {
+		if !now.Before(lease.expireAt) {
+			delete(l.leases, path)
+		}
+	}
+	expireAt = now.Add(period)
+	l.leases[p] = fileLease{holder: holder, expireAt: expireAt}
+	return expireAt, nil
+}
+
+func (f *Filer) ReleaseLease(p FullPath, holder string) error {
+	l := &f.leases
+	l.Lock()
+	defer l.Unlock()
+
+	if err := l.checkLocked(p, holder); err != nil {
+		return err
+	}
+	delete(l.leases, p)
+	return nil
+}
+
+// CheckLease fails with LeasedError if the file is leased by another holder than the one of the context.
+// The writes replicated from another cluster do not know the local leases, and are not checked.
+func (f *Filer) CheckLease(ctx context.Context, p FullPath) error {
+	if isReplicatedWrite(ctx) {
+		return nil
+	}
+	l := &f.leases
+	l.Lock()
+	defer l.Unlock()
+
+	return l.checkLocked(p, leaseHolder(ctx))
+}
+
+// dropLease forgets the lease of the deleted file
+func (f *Filer) dropLease(p FullPath) {
+	l := &f.leases
+	l.Lock()
+	defer l.Unlock()
+
+	delete(l.leases, p)
+}
+
+func (l *fileLeases) checkLocked(p FullPath, holder string) error {
+	lease, found := l.leases[p]
+	if !found || lease.holder == holder || !time.Now().Before(lease.expireAt) {
+		return nil
+	}
+	return &LeasedError{p, lease.holder}
+}
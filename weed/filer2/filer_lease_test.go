@@ -0,0 +1,49 @@
+package filer2
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFileLeases(t *testing.T) {
+	f := &Filer{}
+
+	if _, err := f.AcquireLease("/a/b", "client1", time.Minute); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	if _, err := f.AcquireLease("/a/b", "client2", time.Minute); !IsLeased(err) {
+		t.Errorf("acquired the lease held by client1: %v", err)
+	}
+	holder1 := WithLeaseHolder(context.Background(), "client1")
+	if err := f.CheckLease(holder1, "/a/b"); err != nil {
+		t.Errorf("check by the holder: %v", err)
+	}
+	if err := f.CheckLease(context.Background(), "/a/b"); !IsLeased(err) {
+		t.Errorf("written without the lease: %v", err)
+	}
+	if err := f.CheckLease(WithReplicatedWrite(context.Background()), "/a/b"); err != nil {
+		t.Errorf("replicated write checked: %v", err)
+	}
+	if err := f.CheckLease(context.Background(), "/a/c"); err != nil {
+		t.Errorf("check the file not leased: %v", err)
+	}
+	if err := f.ReleaseLease("/a/b", "client2"); !IsLeased(err) {
+		t.Errorf("released the lease held by client1: %v", err)
+	}
+	if err := f.ReleaseLease("/a/b", "client1"); err != nil {
+		t.Errorf("release: %v", err)
+	}
+	if _, err := f.AcquireLease("/a/b", "client2", -time.Second); err != nil {
+		t.Errorf("acquire the released lease: %v", err)
+	}
+	// the expired lease is taken over
+	if _, err := f.AcquireLease("/a/b", "client1", time.Minute); err != nil {
+		t.Errorf("acquire the expired lease: %v", err)
+	}
+
+	f.dropLease("/a/b")
+	if err := f.CheckLease(context.Background(), "/a/b"); err != nil {
+		t.Errorf("lease of the deleted file kept: %v", err)
+	}
+}
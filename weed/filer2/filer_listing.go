@@ -0,0 +1,163 @@
+package filer2
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+)
+
+const (
+	SortByName  = "name"
+	SortByMtime = "mtime"
+	SortBySize  = "size"
+)
+
+// ListingOrder is the order of the listed entries, by the name ascending by default
+type ListingOrder struct {
+	SortBy string
+	Desc   bool
+}
+
+func ParseListingOrder(sortBy string, desc bool) (order ListingOrder, err error) {
+	switch sortBy {
+	case "", SortByName, SortByMtime, SortBySize:
+	default:
+		return order, fmt.Errorf("unknown sort %s, expecting %s, %s or %s", sortBy, SortByName, SortByMtime, SortBySize)
+	}
+	return ListingOrder{SortBy: sortBy, Desc: desc}, nil
+}
+
+// IsNameOrder is the order of the filer stores, listed page by page without loading the whole directory
+func (o ListingOrder) IsNameOrder() bool {
+	return (o.SortBy == "" || o.SortBy == SortByName) && !o.Desc
+}
+
+func (o ListingOrder) less(a, b *Entry) bool {
+	switch o.SortBy {
+	case SortByMtime:
+		if !a.Mtime.Equal(b.Mtime) {
+			return a.Mtime.Before(b.Mtime) != o.Desc
+		}
+	case SortBySize:
+		if a.Size() != b.Size() {
+			return a.Size() < b.Size() != o.Desc
+		}
+	}
+	// the name breaks the ties, so the listing can continue after the last file name
+	return a.Name() < b.Name() != o.Desc
+}
+
+// ListDirectorySortedEntries lists the entries in the order, continuing after startFileName in the same order.
+// Except for the name ascending order, the whole directory is loaded and sorted, up to maxEntries.
+func (f *Filer) ListDirectorySortedEntries(ctx context.Context, p FullPath, startFileName string, inclusive bool, limit int, order ListingOrder, maxEntries int) ([]*Entry, error) {
+	if order.IsNameOrder() {
+		return f.ListDirectoryEntries(ctx, p, startFileName, inclusive, limit)
+	}
+
+	var all []*Entry
+	lastFileName, includeLastFile := "", false
+	for {
+		entries, err := f.ListDirectoryEntries(ctx, p, lastFileName, includeLastFile, 1024)
+		if err != nil {
+			return nil, err
+		}
+		if len(entries) == 0 {
+			break
+		}
+		all = append(all, entries...)
+		if len(all) > maxEntries {
+			return nil, fmt.Errorf("%s has more than %d entries to sort by %s", p, maxEntries, order.SortBy)
+		}
+		lastFileName, includeLastFile = entries[len(entries)-1].Name(), false
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		return order.less(all[i], all[j])
+	})
+
+	start := 0
+	if startFileName != "" {
+		for i, entry := range all {
+			if entry.Name() == startFileName {
+				start = i
+				if !inclusive {
+					start++
+				}
+				break
+			}
+		}
+	}
+	all = all[start:]
+	if len(all) > limit {
+		all = all[:limit]
+	}
+	return all, nil
+}
+
+// EntryFields selects the fields of the listed entries, besides the names and whether they are directories,
+// so listing the huge directories does not need to send the chunks just to show the names.
+type EntryFields struct {
+	Attributes bool
+	Chunks     bool
+	Extended   bool
+}
+
+var AllEntryFields = EntryFields{Attributes: true, Chunks: true, Extended: true}
+
+// ParseEntryFields parses the field names "attributes", "chunks" and "extended", all the fields if empty
+func ParseEntryFields(fields []string) (ef EntryFields, err error) {
+	if len(fields) == 0 {
+		return AllEntryFields, nil
+	}
+	for _, field := range fields {
+		switch field {
+		case "", "name", "is_directory":
+		case "attributes":
+			ef.Attributes = true
+		case "chunks":
+			ef.Chunks = true
+		case "extended":
+			ef.Extended = true
+		default:
+			return ef, fmt.Errorf("unknown entry field %s, expecting attributes, chunks or extended", field)
+		}
+	}
+	return ef, nil
+}
+
+// Project copies the entry with only the selected fields. The mode is kept to tell the directories.
+func (ef EntryFields) Project(entry *Entry) *Entry {
+	if ef == AllEntryFields {
+		return entry
+	}
+	projected := &Entry{FullPath: entry.FullPath}
+	if ef.Attributes {
+		projected.Attr = entry.Attr
+	} else {
+		projected.Attr.Mode = entry.Attr.Mode
+	}
+	if ef.Chunks {
+		projected.Chunks = entry.Chunks
+	}
+	if ef.Extended {
+		projected.Extended = entry.Extended
+	}
+	return projected
+}
+
+// ToProtoEntry converts the entry with only the selected fields
+func (ef EntryFields) ToProtoEntry(entry *Entry) *filer_pb.Entry {
+	pbEntry := entry.ToProtoEntry()
+	if !ef.Attributes {
+		pbEntry.Attributes = nil
+	}
+	if !ef.Chunks {
+		pbEntry.Chunks = nil
+	}
+	if !ef.Extended {
+		pbEntry.Extended = nil
+	}
+	return pbEntry
+}
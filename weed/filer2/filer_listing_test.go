@@ -0,0 +1,64 @@
+package filer2
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestListingOrder(t *testing.T) {
+	now := time.Now()
+	entries := []*Entry{
+		{FullPath: "/dir/a", Attr: Attr{Mtime: now, FileSize: 300}},
+		{FullPath: "/dir/b", Attr: Attr{Mtime: now.Add(-time.Hour), FileSize: 100}},
+		{FullPath: "/dir/c", Attr: Attr{Mtime: now, FileSize: 200}},
+	}
+	for _, tc := range []struct {
+		sortBy   string
+		desc     bool
+		expected string
+	}{
+		{"", false, "abc"},
+		{SortByName, true, "cba"},
+		{SortByMtime, false, "bac"},
+		{SortByMtime, true, "cab"},
+		{SortBySize, false, "bca"},
+		{SortBySize, true, "acb"},
+	} {
+		order, err := ParseListingOrder(tc.sortBy, tc.desc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return order.less(entries[i], entries[j])
+		})
+		var names string
+		for _, entry := range entries {
+			names += entry.Name()
+		}
+		if names != tc.expected {
+			t.Errorf("sort by %s desc %v: %s, expected %s", tc.sortBy, tc.desc, names, tc.expected)
+		}
+	}
+	if _, err := ParseListingOrder("color", false); err == nil {
+		t.Errorf("parsed an unknown sort")
+	}
+}
+
+func TestEntryFields(t *testing.T) {
+	ef, err := ParseEntryFields([]string{"name", "attributes"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := &Entry{FullPath: "/dir/a", Attr: Attr{Mime: "text/plain"}, Extended: map[string][]byte{"k": []byte("v")}}
+	projected := ef.Project(entry)
+	if projected.Mime != "text/plain" || projected.Extended != nil {
+		t.Errorf("projected %+v", projected)
+	}
+	if pbEntry := ef.ToProtoEntry(entry); pbEntry.Attributes == nil || pbEntry.Extended != nil || pbEntry.Name != "a" {
+		t.Errorf("projected %+v", pbEntry)
+	}
+	if _, err = ParseEntryFields([]string{"color"}); err == nil {
+		t.Errorf("parsed an unknown field")
+	}
+}
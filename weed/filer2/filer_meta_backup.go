@@ -0,0 +1,307 @@
+package filer2
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/operation"
+	"github.com/chrislusf/seaweedfs/weed/util"
+	"github.com/golang/protobuf/proto"
+	"github.com/klauspost/reedsolomon"
+	"github.com/spf13/viper"
+)
+
+const (
+	metaBackupBlockSize     = 4 * 1024 * 1024 // the shard size of a full stripe
+	metaBackupAssignRetries = 8
+)
+
+// MetaBackupOption configures the periodic metadata snapshot, erasure coded across the volume servers.
+type MetaBackupOption struct {
+	Interval     time.Duration
+	Collection   string
+	Ttl          string
+	DataShards   int
+	ParityShards int
+}
+
+// MetaBackupManifest describes one metadata snapshot.
+// The snapshot is in the same format as saved by "fs.meta.save",
+// split into stripes of DataShards blocks, each stripe with ParityShards more blocks of Reed-Solomon codes.
+// Each block is a blob in the backup collection, and a stripe tolerates losing up to ParityShards blocks.
+// The manifest itself is saved ParityShards+1 times, as a blob named by MetaBackupManifestName.
+type MetaBackupManifest struct {
+	CreatedAt    int64      `json:"createdAt"`
+	Size         int64      `json:"size"`
+	DirCount     int64      `json:"dirCount"`
+	FileCount    int64      `json:"fileCount"`
+	DataShards   int        `json:"dataShards"`
+	ParityShards int        `json:"parityShards"`
+	BlockSize    int        `json:"blockSize"`
+	Stripes      [][]string `json:"stripes"` // the file ids of the data and parity blocks of each stripe
+}
+
+const MetaBackupManifestSuffix = ".manifest"
+
+func metaBackupName(createdAt time.Time) string {
+	return fmt.Sprintf("filer-meta-%d", createdAt.Unix())
+}
+
+func MetaBackupManifestName(createdAt time.Time) string {
+	return metaBackupName(createdAt) + MetaBackupManifestSuffix
+}
+
+func (f *Filer) LoadMetaBackupConfiguration(config *viper.Viper) {
+
+	if config == nil || !config.GetBool("enabled") {
+		return
+	}
+
+	config.SetDefault("collection", "filer_meta_backup")
+	config.SetDefault("data_shards", 4)
+	config.SetDefault("parity_shards", 2)
+
+	option := &MetaBackupOption{
+		Interval:     time.Duration(config.GetInt("interval_minutes")) * time.Minute,
+		Collection:   config.GetString("collection"),
+		Ttl:          config.GetString("ttl"),
+		DataShards:   config.GetInt("data_shards"),
+		ParityShards: config.GetInt("parity_shards"),
+	}
+	if option.Interval <= 0 {
+		option.Interval = time.Hour
+	}
+	if _, err := reedsolomon.New(option.DataShards, option.ParityShards); err != nil {
+		glog.Fatalf("meta backup with %d data shards and %d parity shards: %v", option.DataShards, option.ParityShards, err)
+	}
+
+	glog.V(0).Infof("backup metadata to collection %s every %v, with %d+%d shards",
+		option.Collection, option.Interval, option.DataShards, option.ParityShards)
+
+	go f.loopBackingUpMetadata(option)
+}
+
+func (f *Filer) loopBackingUpMetadata(option *MetaBackupOption) {
+	for {
+		time.Sleep(option.Interval)
+		if manifest, err := f.BackupMetadata(context.Background(), option); err != nil {
+			glog.Errorf("backup metadata: %v", err)
+		} else {
+			glog.V(0).Infof("backup metadata: %d directories, %d files, %d bytes in %d stripes",
+				manifest.DirCount, manifest.FileCount, manifest.Size, len(manifest.Stripes))
+		}
+	}
+}
+
+// BackupMetadata saves a snapshot of all entries, erasure coded into the backup collection
+func (f *Filer) BackupMetadata(ctx context.Context, option *MetaBackupOption) (*MetaBackupManifest, error) {
+
+	now := time.Now()
+	enc, err := reedsolomon.New(option.DataShards, option.ParityShards)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &metaBackupWriter{
+		filer:  f,
+		option: option,
+		enc:    enc,
+		name:   metaBackupName(now),
+		manifest: &MetaBackupManifest{
+			CreatedAt:    now.UnixNano(),
+			DataShards:   option.DataShards,
+			ParityShards: option.ParityShards,
+			BlockSize:    metaBackupBlockSize,
+		},
+	}
+
+	sizeBuf := make([]byte, 4)
+	err = f.listAllEntries(ctx, FullPath("/"), true, func(entry *Entry) error {
+		data, err := proto.Marshal(entry.ToProtoFullEntry())
+		if err != nil {
+			return fmt.Errorf("marshal %s: %v", entry.FullPath, err)
+		}
+		util.Uint32toBytes(sizeBuf, uint32(len(data)))
+		if _, err = w.Write(sizeBuf); err != nil {
+			return err
+		}
+		if _, err = w.Write(data); err != nil {
+			return err
+		}
+		if entry.IsDirectory() {
+			w.manifest.DirCount++
+		} else {
+			w.manifest.FileCount++
+		}
+		return nil
+	})
+	if err == nil {
+		err = w.Close()
+	}
+	if err != nil {
+		w.deleteUploaded()
+		return nil, err
+	}
+
+	manifestBytes, _ := json.Marshal(w.manifest)
+	copies := make([][]byte, option.ParityShards+1)
+	for i := range copies {
+		copies[i] = manifestBytes
+	}
+	manifestName := MetaBackupManifestName(now)
+	if _, err = w.uploadSpread(func(i int) string { return manifestName }, copies); err != nil {
+		w.deleteUploaded()
+		return nil, fmt.Errorf("save manifest %s: %v", manifestName, err)
+	}
+
+	return w.manifest, nil
+}
+
+// metaBackupWriter erasure codes each full stripe of the written data, and uploads the blocks
+type metaBackupWriter struct {
+	filer    *Filer
+	option   *MetaBackupOption
+	enc      reedsolomon.Encoder
+	name     string
+	buffer   bytes.Buffer
+	manifest *MetaBackupManifest
+	uploaded []string
+}
+
+func (w *metaBackupWriter) Write(p []byte) (n int, err error) {
+	n, _ = w.buffer.Write(p)
+	stripeSize := w.option.DataShards * metaBackupBlockSize
+	for w.buffer.Len() >= stripeSize {
+		if err = w.writeStripe(w.buffer.Next(stripeSize)); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Close writes the last partial stripe, with smaller blocks
+func (w *metaBackupWriter) Close() error {
+	if w.buffer.Len() == 0 {
+		return nil
+	}
+	return w.writeStripe(w.buffer.Next(w.buffer.Len()))
+}
+
+func (w *metaBackupWriter) writeStripe(data []byte) error {
+	shards, err := w.enc.Split(data)
+	if err != nil {
+		return err
+	}
+	if err = w.enc.Encode(shards); err != nil {
+		return err
+	}
+	fileIds, err := w.uploadSpread(func(i int) string {
+		return fmt.Sprintf("%s.%d.%d", w.name, len(w.manifest.Stripes), i)
+	}, shards)
+	if err != nil {
+		return err
+	}
+	w.manifest.Stripes = append(w.manifest.Stripes, fileIds)
+	w.manifest.Size += int64(len(data))
+	return nil
+}
+
+// uploadSpread uploads the blobs, trying to place each blob on a different volume server
+func (w *metaBackupWriter) uploadSpread(blobName func(i int) string, blobs [][]byte) (fileIds []string, err error) {
+
+	usedServers := make(map[string]bool)
+	for i, blob := range blobs {
+
+		name := blobName(i)
+		var assignResult *operation.AssignResult
+		for retry := 0; retry < metaBackupAssignRetries; retry++ {
+			assignResult, err = operation.Assign(w.filer.GetMaster(), w.filer.GrpcDialOption, &operation.VolumeAssignRequest{
+				Count:      1,
+				Collection: w.option.Collection,
+				Ttl:        w.option.Ttl,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("assign volume for %s: %v", name, err)
+			}
+			if !usedServers[assignResult.Url] {
+				break
+			}
+		}
+		if usedServers[assignResult.Url] {
+			glog.V(1).Infof("meta backup %s shares the volume server %s", name, assignResult.Url)
+		}
+		usedServers[assignResult.Url] = true
+
+		uploadUrl := fmt.Sprintf("http://%s/%s", assignResult.Url, assignResult.Fid)
+		uploadResult, err := operation.Upload(uploadUrl, name, bytes.NewReader(blob), false, "application/octet-stream", nil, assignResult.Auth)
+		if err != nil {
+			return nil, fmt.Errorf("upload %s to %s: %v", name, uploadUrl, err)
+		}
+		if uploadResult.Error != "" {
+			return nil, fmt.Errorf("upload %s to %s: %v", name, uploadUrl, uploadResult.Error)
+		}
+		w.uploaded = append(w.uploaded, assignResult.Fid)
+		fileIds = append(fileIds, assignResult.Fid)
+	}
+
+	return fileIds, nil
+}
+
+func (w *metaBackupWriter) deleteUploaded() {
+	for _, fileId := range w.uploaded {
+		w.filer.DeleteFileByFileId(fileId)
+	}
+}
+
+// RestoreMetaBackup writes the snapshot in the manifest, in the same format as saved by "fs.meta.save".
+// The missing blocks are reconstructed, as long as each stripe has at least DataShards blocks.
+func RestoreMetaBackup(manifest *MetaBackupManifest, readBlob func(fileId string) ([]byte, error), w io.Writer) error {
+
+	enc, err := reedsolomon.New(manifest.DataShards, manifest.ParityShards)
+	if err != nil {
+		return err
+	}
+
+	remaining := manifest.Size
+	for s, fileIds := range manifest.Stripes {
+		shards := make([][]byte, len(fileIds))
+		found := 0
+		for i, fileId := range fileIds {
+			if found >= manifest.DataShards {
+				break
+			}
+			data, readErr := readBlob(fileId)
+			if readErr != nil {
+				glog.V(0).Infof("read stripe %d block %d %s: %v", s, i, fileId, readErr)
+				continue
+			}
+			shards[i] = data
+			found++
+		}
+		if found < manifest.DataShards {
+			return fmt.Errorf("stripe %d has only %d blocks, requires %d", s, found, manifest.DataShards)
+		}
+		if err = enc.ReconstructData(shards); err != nil {
+			return fmt.Errorf("reconstruct stripe %d: %v", s, err)
+		}
+
+		stripeSize := int64(manifest.DataShards * manifest.BlockSize)
+		if remaining < stripeSize {
+			stripeSize = remaining
+		}
+		if err = enc.Join(w, shards, int(stripeSize)); err != nil {
+			return fmt.Errorf("join stripe %d: %v", s, err)
+		}
+		remaining -= stripeSize
+	}
+
+	if remaining != 0 {
+		return fmt.Errorf("snapshot is %d bytes short", remaining)
+	}
+	return nil
+}
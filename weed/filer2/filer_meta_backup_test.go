@@ -0,0 +1,72 @@
+package filer2
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/klauspost/reedsolomon"
+)
+
+func TestRestoreMetaBackup(t *testing.T) {
+
+	manifest := &MetaBackupManifest{
+		DataShards:   4,
+		ParityShards: 2,
+		BlockSize:    1024,
+	}
+	enc, _ := reedsolomon.New(manifest.DataShards, manifest.ParityShards)
+
+	data := make([]byte, 3*manifest.DataShards*manifest.BlockSize+777)
+	rand.Read(data)
+
+	blobs := make(map[string][]byte)
+	stripeSize := manifest.DataShards * manifest.BlockSize
+	for start := 0; start < len(data); start += stripeSize {
+		end := start + stripeSize
+		if end > len(data) {
+			end = len(data)
+		}
+		shards, _ := enc.Split(data[start:end])
+		enc.Encode(shards)
+		var fileIds []string
+		for i, shard := range shards {
+			fileId := fmt.Sprintf("%d,%d", len(manifest.Stripes), i)
+			blobs[fileId] = shard
+			fileIds = append(fileIds, fileId)
+		}
+		manifest.Stripes = append(manifest.Stripes, fileIds)
+		manifest.Size += int64(end - start)
+	}
+
+	// lose up to ParityShards blocks in each stripe
+	delete(blobs, "0,0")
+	delete(blobs, "0,5")
+	delete(blobs, "1,1")
+	delete(blobs, "1,2")
+	delete(blobs, "3,3")
+
+	readBlob := func(fileId string) ([]byte, error) {
+		if blob, found := blobs[fileId]; found {
+			return blob, nil
+		}
+		return nil, fmt.Errorf("%s not found", fileId)
+	}
+
+	var restored bytes.Buffer
+	if err := RestoreMetaBackup(manifest, readBlob, &restored); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if !bytes.Equal(restored.Bytes(), data) {
+		t.Errorf("restored %d bytes differ from the %d bytes snapshot", restored.Len(), len(data))
+	}
+
+	delete(blobs, "2,0")
+	delete(blobs, "2,1")
+	delete(blobs, "2,4")
+	if err := RestoreMetaBackup(manifest, readBlob, &bytes.Buffer{}); err == nil {
+		t.Errorf("restore a stripe with too many lost blocks")
+	}
+
+}
@@ -16,24 +16,31 @@ func (f *Filer) NotifyUpdateEvent(oldEntry, newEntry *Entry, deleteChunks bool)
 		return
 	}
 
+	newParentPath := ""
+	if newEntry != nil {
+		newParentPath, _ = newEntry.FullPath.DirAndName()
+	}
+	eventNotification := &filer_pb.EventNotification{
+		OldEntry:      oldEntry.ToProtoEntry(),
+		NewEntry:      newEntry.ToProtoEntry(),
+		DeleteChunks:  deleteChunks,
+		NewParentPath: newParentPath,
+	}
+	// the subscribers and the replication read the encrypted chunks without the key wrapping key
+	for _, entry := range []*filer_pb.Entry{eventNotification.OldEntry, eventNotification.NewEntry} {
+		if entry != nil {
+			entry.Chunks = UnwrapChunkKeys(entry.Chunks)
+		}
+	}
+
+	dir, _ := FullPath(key).DirAndName()
+	f.notifyMetadataSubscribers(dir, eventNotification)
+
 	if notification.Queue != nil {
 
 		glog.V(3).Infof("notifying entry update %v", key)
 
-		newParentPath := ""
-		if newEntry != nil {
-			newParentPath, _ = newEntry.FullPath.DirAndName()
-		}
-
-		notification.Queue.SendMessage(
-			key,
-			&filer_pb.EventNotification{
-				OldEntry:      oldEntry.ToProtoEntry(),
-				NewEntry:      newEntry.ToProtoEntry(),
-				DeleteChunks:  deleteChunks,
-				NewParentPath: newParentPath,
-			},
-		)
+		notification.Queue.SendMessage(key, eventNotification)
 
 	}
 }
@@ -0,0 +1,62 @@
+package filer2
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+)
+
+// PatchEntry overwrites the byte ranges of an existing file with the new chunks,
+// without rewriting the rest of the file.
+// The new chunks take precedence over the existing ones, in their order, and the chunks fully overwritten are deleted.
+// The patches of each file on this filer are serialized, but a concurrent full update of the file may still win.
+func (f *Filer) PatchEntry(ctx context.Context, p FullPath, chunks []*filer_pb.FileChunk) error {
+
+	defer f.patchLocks.lock(p)()
+
+	oldEntry, err := f.FindEntry(ctx, p)
+	if err != nil {
+		return fmt.Errorf("find %s: %v", p, err)
+	}
+	if oldEntry.IsDirectory() {
+		return fmt.Errorf("existing %s is a directory", p)
+	}
+
+	// the visible intervals are ordered by the chunk mtime
+	mtime := time.Now().UnixNano()
+	for _, chunk := range oldEntry.Chunks {
+		if chunk.Mtime >= mtime {
+			mtime = chunk.Mtime + 1
+		}
+	}
+	allChunks := append([]*filer_pb.FileChunk{}, oldEntry.Chunks...)
+	for i, chunk := range chunks {
+		if chunk.Offset < 0 {
+			return fmt.Errorf("patch %s: negative offset %d", p, chunk.Offset)
+		}
+		chunk.Mtime = mtime + int64(i)
+		allChunks = append(allChunks, chunk)
+	}
+	compacted, garbage := CompactFileChunks(allChunks)
+
+	newEntry := &Entry{
+		FullPath: p,
+		Attr:     oldEntry.Attr,
+		Chunks:   compacted,
+	}
+	newEntry.Attr.Mtime = time.Now()
+
+	if err = f.UpdateEntry(ctx, oldEntry, newEntry); err != nil {
+		return err
+	}
+
+	glog.V(3).Infof("patched %s with %d chunks, %d chunks overwritten", p, len(chunks), len(garbage))
+
+	f.NotifyUpdateEvent(oldEntry, newEntry, true)
+	f.DeleteChunks(p, garbage)
+
+	return nil
+}
@@ -0,0 +1,267 @@
+package filer2
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// the identity of the caller, checked against the owner, group and mode of the entries
+// when the filer enforces the permissions. The headers are for http, and lower cased for grpc metadata.
+// The uid and gids headers are trusted only from the trusted proxies or the mutual tls grpc clients,
+// and the other callers prove their identity by a jwt signed with the uid and gids claims.
+const (
+	IdentityUidHeader  = "Seaweed-Uid"
+	IdentityGidsHeader = "Seaweed-Gids" // comma separated, the first one for the new entries
+	IdentityJwtHeader  = "Seaweed-Identity"
+)
+
+// the permission bits of the entry mode
+const (
+	PermissionRead    = 04
+	PermissionWrite   = 02
+	PermissionExecute = 01
+)
+
+// NobodyId is the identity of the callers not telling who they are
+const NobodyId = 65534
+
+// PermissionDeniedError is returned when the identity is not allowed to access or change an entry
+type PermissionDeniedError struct {
+	FullPath FullPath
+	Reason   string
+}
+
+func (e *PermissionDeniedError) Error() string {
+	return fmt.Sprintf("%s: permission denied: %s", e.FullPath, e.Reason)
+}
+
+func IsPermissionDenied(err error) bool {
+	_, ok := err.(*PermissionDeniedError)
+	return ok
+}
+
+// Identity is the user of a request. A nil identity is not checked.
+type Identity struct {
+	Uid  uint32
+	Gids []uint32
+}
+
+var nobody = &Identity{Uid: NobodyId, Gids: []uint32{NobodyId}}
+
+// NewIdentity is the identity with the gids, or in the nobody group without any gids
+func NewIdentity(uid uint32, gids []uint32) *Identity {
+	if len(gids) == 0 {
+		gids = []uint32{NobodyId}
+	}
+	return &Identity{Uid: uid, Gids: gids}
+}
+
+// Nobody is the identity of the callers not telling who they are
+func Nobody() *Identity {
+	return nobody
+}
+
+// ParseIdentity reads the identity headers, or returns the nobody identity if the uid is missing
+func ParseIdentity(uid, gids string) (*Identity, error) {
+	if uid == "" {
+		return nobody, nil
+	}
+	id := &Identity{}
+	parsed, err := strconv.ParseUint(uid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %v", IdentityUidHeader, uid, err)
+	}
+	id.Uid = uint32(parsed)
+	for _, gid := range strings.Split(gids, ",") {
+		if gid = strings.TrimSpace(gid); gid == "" {
+			continue
+		}
+		if parsed, err = strconv.ParseUint(gid, 10, 32); err != nil {
+			return nil, fmt.Errorf("%s %s: %v", IdentityGidsHeader, gids, err)
+		}
+		id.Gids = append(id.Gids, uint32(parsed))
+	}
+	if len(id.Gids) == 0 {
+		id.Gids = []uint32{NobodyId}
+	}
+	return id, nil
+}
+
+// Gid is the group of the entries created by the identity
+func (id *Identity) Gid() uint32 {
+	return id.Gids[0]
+}
+
+func (id *Identity) isRoot() bool {
+	return id.Uid == 0
+}
+
+func (id *Identity) inGroup(gid uint32) bool {
+	for _, g := range id.Gids {
+		if g == gid {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPermission checks the permission bits of the owner, the group, or the others, whichever the identity is.
+// The root is granted everything.
+func (id *Identity) HasPermission(attr Attr, permission uint32) bool {
+	if id == nil || id.isRoot() {
+		return true
+	}
+	mode := uint32(attr.Mode.Perm())
+	switch {
+	case id.Uid == attr.Uid:
+		mode >>= 6
+	case id.inGroup(attr.Gid):
+		mode >>= 3
+	}
+	return mode&permission == permission
+}
+
+// CheckAccess checks the search permission of the ancestor directories, and then the permission of the entry.
+// The missing entries are not denied here, so the callers can tell they are not found.
+func (f *Filer) CheckAccess(ctx context.Context, id *Identity, p FullPath, permission uint32) error {
+	if id == nil {
+		return nil
+	}
+	entry, err := f.searchAncestors(ctx, id, p)
+	if err != nil || entry == nil || permission == 0 {
+		return err
+	}
+	if !id.HasPermission(entry.Attr, permission) {
+		return &PermissionDeniedError{FullPath: p, Reason: fmt.Sprintf("no %s permission", permissionName(permission))}
+	}
+	return nil
+}
+
+// CheckModify checks the permission to create, delete or rename the entry,
+// which is the write and search permission of the parent directory.
+// The missing parent directories would be created, so the nearest existing ancestor is checked instead.
+func (f *Filer) CheckModify(ctx context.Context, id *Identity, p FullPath) error {
+	if id == nil || p == "/" {
+		return nil
+	}
+	dir, _ := p.DirAndName()
+	parent := FullPath(dir)
+	for {
+		_, err := f.FindEntry(ctx, parent)
+		if err == ErrNotFound {
+			dir, _ = parent.DirAndName()
+			parent = FullPath(dir)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("find %s: %v", parent, err)
+		}
+		return f.CheckAccess(ctx, id, parent, PermissionWrite|PermissionExecute)
+	}
+}
+
+// CheckWrite checks the permission to write the existing entry, or to create the new entry.
+// The existing entry is returned, if any.
+func (f *Filer) CheckWrite(ctx context.Context, id *Identity, p FullPath) (existingEntry *Entry, err error) {
+	if id == nil {
+		return nil, nil
+	}
+	existingEntry, err = f.FindEntry(ctx, p)
+	if err == ErrNotFound {
+		return nil, f.CheckModify(ctx, id, p)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find %s: %v", p, err)
+	}
+	return existingEntry, f.CheckAccess(ctx, id, p, PermissionWrite)
+}
+
+// CheckUpdate checks the write permission to change the content of the entry,
+// and the ownership to change its attributes.
+func (f *Filer) CheckUpdate(ctx context.Context, id *Identity, oldEntry, newEntry *Entry) error {
+	if id == nil {
+		return nil
+	}
+	if err := CheckAttributeChange(id, oldEntry.FullPath, oldEntry.Attr, newEntry.Attr); err != nil {
+		return err
+	}
+	contentChanged := len(oldEntry.Chunks) != len(newEntry.Chunks) || len(MinusChunks(oldEntry.Chunks, newEntry.Chunks)) > 0 ||
+		oldEntry.FileSize != newEntry.FileSize
+	if !contentChanged && id.Uid == oldEntry.Uid {
+		// the owner can change the mode and the times without the write permission
+		return f.CheckAccess(ctx, id, oldEntry.FullPath, 0)
+	}
+	return f.CheckAccess(ctx, id, oldEntry.FullPath, PermissionWrite)
+}
+
+// CheckAttributeChange allows only the owner to change the mode, and the group to one of the owner's groups,
+// and only the root to change the owner.
+func CheckAttributeChange(id *Identity, p FullPath, oldAttr, newAttr Attr) error {
+	if id == nil || id.isRoot() {
+		return nil
+	}
+	if newAttr.Uid != oldAttr.Uid {
+		return &PermissionDeniedError{FullPath: p, Reason: "only the root can change the owner"}
+	}
+	if newAttr.Mode != oldAttr.Mode && id.Uid != oldAttr.Uid {
+		return &PermissionDeniedError{FullPath: p, Reason: "only the owner can change the mode"}
+	}
+	if newAttr.Gid != oldAttr.Gid && (id.Uid != oldAttr.Uid || !id.inGroup(newAttr.Gid)) {
+		return &PermissionDeniedError{FullPath: p, Reason: fmt.Sprintf("can not change the group to %d", newAttr.Gid)}
+	}
+	return nil
+}
+
+// CheckNewOwner allows the new entries to be owned by the identity and its groups only, unless by the root
+func CheckNewOwner(id *Identity, p FullPath, attr Attr) error {
+	if id == nil || id.isRoot() {
+		return nil
+	}
+	if attr.Uid != id.Uid || !id.inGroup(attr.Gid) {
+		return &PermissionDeniedError{FullPath: p, Reason: fmt.Sprintf("can not create the entry owned by %d:%d", attr.Uid, attr.Gid)}
+	}
+	return nil
+}
+
+// searchAncestors checks the search permission from the root down to the parent directory,
+// and returns the entry, or nil if it or any ancestor is not found.
+func (f *Filer) searchAncestors(ctx context.Context, id *Identity, p FullPath) (*Entry, error) {
+	current := FullPath("/")
+	entry, err := f.FindEntry(ctx, current)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range strings.Split(strings.Trim(string(p), "/"), "/") {
+		if name == "" {
+			continue
+		}
+		if !entry.IsDirectory() {
+			return nil, nil
+		}
+		if !id.HasPermission(entry.Attr, PermissionExecute) {
+			return nil, &PermissionDeniedError{FullPath: current, Reason: "no search permission"}
+		}
+		current = current.Child(name)
+		if entry, err = f.FindEntry(ctx, current); err == ErrNotFound {
+			return nil, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("find %s: %v", current, err)
+		}
+	}
+	return entry, nil
+}
+
+func permissionName(permission uint32) string {
+	var names []string
+	for _, p := range []struct {
+		bit  uint32
+		name string
+	}{{PermissionRead, "read"}, {PermissionWrite, "write"}, {PermissionExecute, "execute"}} {
+		if permission&p.bit != 0 {
+			names = append(names, p.name)
+		}
+	}
+	return strings.Join(names, " and ")
+}
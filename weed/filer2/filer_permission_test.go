@@ -0,0 +1,72 @@
+package filer2
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParseIdentity(t *testing.T) {
+	id, err := ParseIdentity("", "")
+	if err != nil || id.Uid != NobodyId || id.Gid() != NobodyId {
+		t.Fatalf("missing uid should be nobody: %+v %v", id, err)
+	}
+	id, err = ParseIdentity("1000", "100, 20")
+	if err != nil || id.Uid != 1000 || id.Gid() != 100 || !id.inGroup(20) {
+		t.Fatalf("parse identity: %+v %v", id, err)
+	}
+	if _, err = ParseIdentity("1000", "x"); err == nil {
+		t.Fatalf("bad gids should fail")
+	}
+}
+
+func TestHasPermission(t *testing.T) {
+	attr := Attr{Uid: 1000, Gid: 100, Mode: os.ModeDir | 0750}
+	owner := &Identity{Uid: 1000, Gids: []uint32{1000}}
+	member := &Identity{Uid: 1001, Gids: []uint32{1001, 100}}
+	other := &Identity{Uid: 1002, Gids: []uint32{1002}}
+	root := &Identity{Uid: 0, Gids: []uint32{0}}
+
+	tests := []struct {
+		id         *Identity
+		permission uint32
+		expected   bool
+	}{
+		{owner, PermissionRead | PermissionWrite | PermissionExecute, true},
+		{member, PermissionRead | PermissionExecute, true},
+		{member, PermissionWrite, false},
+		{other, PermissionRead, false},
+		{other, 0, true},
+		{root, PermissionWrite, true},
+		{nil, PermissionWrite, true},
+	}
+	for i, tt := range tests {
+		if actual := tt.id.HasPermission(attr, tt.permission); actual != tt.expected {
+			t.Errorf("case %d: permission %o expected %v, actual %v", i, tt.permission, tt.expected, actual)
+		}
+	}
+}
+
+func TestCheckAttributeChange(t *testing.T) {
+	old := Attr{Uid: 1000, Gid: 100, Mode: 0644}
+	owner := &Identity{Uid: 1000, Gids: []uint32{1000, 100, 200}}
+	other := &Identity{Uid: 1001, Gids: []uint32{100}}
+
+	if err := CheckAttributeChange(owner, "/a", old, Attr{Uid: 1000, Gid: 200, Mode: 0600}); err != nil {
+		t.Errorf("owner should change the mode and group: %v", err)
+	}
+	if err := CheckAttributeChange(owner, "/a", old, Attr{Uid: 1000, Gid: 300, Mode: 0644}); !IsPermissionDenied(err) {
+		t.Errorf("owner should not change the group to others: %v", err)
+	}
+	if err := CheckAttributeChange(owner, "/a", old, Attr{Uid: 1001, Gid: 100, Mode: 0644}); !IsPermissionDenied(err) {
+		t.Errorf("owner should not give the entry away: %v", err)
+	}
+	if err := CheckAttributeChange(other, "/a", old, Attr{Uid: 1000, Gid: 100, Mode: 0666}); !IsPermissionDenied(err) {
+		t.Errorf("others should not change the mode: %v", err)
+	}
+	if err := CheckNewOwner(other, "/b", Attr{Uid: 1001, Gid: 100}); err != nil {
+		t.Errorf("new entry owned by the caller: %v", err)
+	}
+	if err := CheckNewOwner(other, "/b", Attr{Uid: 0, Gid: 0}); !IsPermissionDenied(err) {
+		t.Errorf("new entry owned by root: %v", err)
+	}
+}
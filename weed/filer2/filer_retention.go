@@ -0,0 +1,106 @@
+package filer2
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// the retention modes, same as S3 Object Lock
+const (
+	// RetentionModeGovernance can be shortened or removed, and the entry deleted, with the governance bypass
+	RetentionModeGovernance = "GOVERNANCE"
+	// RetentionModeCompliance can only be extended, and the entry can not be deleted or overwritten by anyone
+	RetentionModeCompliance = "COMPLIANCE"
+)
+
+// EntryLockedError is returned when deleting or overwriting an entry under retention or legal hold
+type EntryLockedError struct {
+	FullPath FullPath
+	Reason   string
+}
+
+func (e *EntryLockedError) Error() string {
+	return fmt.Sprintf("%s is locked: %s", e.FullPath, e.Reason)
+}
+
+func IsEntryLocked(err error) bool {
+	_, ok := err.(*EntryLockedError)
+	return ok
+}
+
+func ValidRetentionMode(mode string) bool {
+	return mode == "" || mode == RetentionModeGovernance || mode == RetentionModeCompliance
+}
+
+type governanceBypassKey struct{}
+
+// WithGovernanceBypass allows to delete or overwrite the entries under governance retention
+func WithGovernanceBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, governanceBypassKey{}, true)
+}
+
+func (entry *Entry) IsUnderRetention(now time.Time) bool {
+	return entry.RetentionMode != "" && now.Before(entry.RetainUntil)
+}
+
+// isRetentionEnforced tells whether the retention of the entry stands for this request
+func isRetentionEnforced(ctx context.Context, entry *Entry, now time.Time) bool {
+	if !entry.IsUnderRetention(now) {
+		return false
+	}
+	return entry.RetentionMode == RetentionModeCompliance || ctx.Value(governanceBypassKey{}) == nil
+}
+
+// CheckDeletion returns EntryLockedError if the entry can not be deleted
+func (f *Filer) CheckDeletion(ctx context.Context, entry *Entry) error {
+	if ctx.Value(snapshotWriteKey{}) != nil {
+		// the snapshots copy the retention, but are not the retained entries
+		return nil
+	}
+	if entry.LegalHold {
+		return &EntryLockedError{entry.FullPath, "under legal hold"}
+	}
+	if isRetentionEnforced(ctx, entry, time.Now()) {
+		return &EntryLockedError{entry.FullPath, fmt.Sprintf("%s retention until %v", entry.RetentionMode, entry.RetainUntil)}
+	}
+	return nil
+}
+
+// checkUpdate allows to change the legal hold and to extend the retention of a locked entry,
+// but not to change the content.
+func (f *Filer) checkUpdate(ctx context.Context, oldEntry, entry *Entry) error {
+	if ctx.Value(snapshotWriteKey{}) != nil {
+		return nil
+	}
+	now := time.Now()
+	retentionEnforced := isRetentionEnforced(ctx, oldEntry, now)
+	if !oldEntry.LegalHold && !retentionEnforced {
+		return nil
+	}
+	if !equalChunks(oldEntry, entry) {
+		return &EntryLockedError{oldEntry.FullPath, "can not be overwritten under retention or legal hold"}
+	}
+	if retentionEnforced {
+		shortened := entry.RetentionMode == "" || entry.RetainUntil.Before(oldEntry.RetainUntil) ||
+			oldEntry.RetentionMode == RetentionModeCompliance && entry.RetentionMode != RetentionModeCompliance
+		if shortened {
+			return &EntryLockedError{oldEntry.FullPath, fmt.Sprintf("%s retention until %v can not be shortened", oldEntry.RetentionMode, oldEntry.RetainUntil)}
+		}
+	}
+	return nil
+}
+
+// equalChunks compares the content, since the file ids may be either in FileId or in Fid
+func equalChunks(a, b *Entry) bool {
+	if len(a.Chunks) != len(b.Chunks) {
+		return false
+	}
+	for i, x := range a.Chunks {
+		y := b.Chunks[i]
+		if x.GetFileIdString() != y.GetFileIdString() || x.Offset != y.Offset || x.Size != y.Size {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,249 @@
+package filer2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+)
+
+// SnapshotsDir has one read only directory for each snapshot.
+// A snapshot only copies the entries, and shares the chunks with the snapshotted directory.
+// The chunks referenced by any snapshot are not deleted, until the last snapshot referencing them is deleted.
+const SnapshotsDir = FullPath("/.snapshots")
+
+var ErrSnapshotReadOnly = errors.New("filer: snapshots are read only")
+
+type snapshotWriteKey struct{}
+
+func IsSnapshotPath(p FullPath) bool {
+	return p == SnapshotsDir || strings.HasPrefix(string(p), string(SnapshotsDir)+"/")
+}
+
+func withSnapshotWrite(ctx context.Context) context.Context {
+	return context.WithValue(ctx, snapshotWriteKey{}, true)
+}
+
+func (f *Filer) checkSnapshotWrite(ctx context.Context, p FullPath) error {
+	if IsSnapshotPath(p) && ctx.Value(snapshotWriteKey{}) == nil {
+		return fmt.Errorf("%s: %v", p, ErrSnapshotReadOnly)
+	}
+	return nil
+}
+
+// snapshotChunks counts the snapshots referencing each chunk file id
+type snapshotChunks struct {
+	sync.RWMutex
+	refs map[string]int
+	// serializes creating and deleting snapshots
+	changeLock sync.Mutex
+}
+
+func (sc *snapshotChunks) add(entry *Entry) (count int) {
+	sc.Lock()
+	defer sc.Unlock()
+	for _, chunk := range entry.Chunks {
+		sc.refs[chunk.GetFileIdString()]++
+		count++
+	}
+	return
+}
+
+// remove returns the file ids not referenced by any snapshot any more
+func (sc *snapshotChunks) remove(fileIds []string) (unreferenced []string) {
+	sc.Lock()
+	defer sc.Unlock()
+	for _, fileId := range fileIds {
+		if sc.refs[fileId] <= 1 {
+			delete(sc.refs, fileId)
+			unreferenced = append(unreferenced, fileId)
+			continue
+		}
+		sc.refs[fileId]--
+	}
+	return
+}
+
+func (sc *snapshotChunks) isReferenced(fileId string) bool {
+	sc.RLock()
+	defer sc.RUnlock()
+	return sc.refs[fileId] > 0
+}
+
+// LoadSnapshots counts the chunks referenced by the existing snapshots,
+// and should be done before any chunk is deleted.
+func (f *Filer) LoadSnapshots(ctx context.Context) error {
+	if _, err := f.FindEntry(ctx, SnapshotsDir); err == ErrNotFound {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("find %s: %v", SnapshotsDir, err)
+	}
+	snapshotCount, chunkCount := 0, 0
+	err := f.listAllEntries(ctx, SnapshotsDir, true, func(entry *Entry) error {
+		if dir, _ := entry.FullPath.DirAndName(); FullPath(dir) == SnapshotsDir {
+			snapshotCount++
+		}
+		chunkCount += f.snapshotChunks.add(entry)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("load snapshots: %v", err)
+	}
+	glog.V(0).Infof("loaded %d snapshots with %d chunks", snapshotCount, chunkCount)
+	return nil
+}
+
+// CreateSnapshot copies the entries under the directory to SnapshotsDir/name
+func (f *Filer) CreateSnapshot(ctx context.Context, dir FullPath, name string) (entryCount, chunkCount int, err error) {
+
+	if name == "" || name == "." || name == ".." || strings.Contains(name, "/") {
+		return 0, 0, fmt.Errorf("invalid snapshot name %q", name)
+	}
+	if IsSnapshotPath(dir) {
+		return 0, 0, fmt.Errorf("can not snapshot %s: %v", dir, ErrSnapshotReadOnly)
+	}
+	dirEntry, err := f.FindEntry(ctx, dir)
+	if err != nil {
+		return 0, 0, fmt.Errorf("find %s: %v", dir, err)
+	}
+	if !dirEntry.IsDirectory() {
+		return 0, 0, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	f.snapshotChunks.changeLock.Lock()
+	defer f.snapshotChunks.changeLock.Unlock()
+
+	snapshotRoot := SnapshotsDir.Child(name)
+	if _, err := f.FindEntry(ctx, snapshotRoot); err == nil {
+		return 0, 0, fmt.Errorf("snapshot %s already exists", name)
+	}
+
+	snapshotCtx := withSnapshotWrite(ctx)
+	if err = f.CreateEntry(snapshotCtx, &Entry{FullPath: snapshotRoot, Attr: dirEntry.Attr}); err != nil {
+		return 0, 0, fmt.Errorf("create snapshot %s: %v", name, err)
+	}
+
+	prefix := strings.TrimSuffix(string(dir), "/")
+	var batch []*Entry
+	flush := func() error {
+		if err := f.store.InsertEntries(snapshotCtx, batch); err != nil {
+			return fmt.Errorf("copy %d entries: %v", len(batch), err)
+		}
+		for _, snapshotEntry := range batch {
+			f.NotifyUpdateEvent(nil, snapshotEntry, false)
+		}
+		entryCount += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+	err = f.listLiveEntries(ctx, dir, func(entry *Entry) error {
+		// count the chunks first, in case the entry is changed while being copied
+		chunkCount += f.snapshotChunks.add(entry)
+		batch = append(batch, &Entry{
+			FullPath: FullPath(string(snapshotRoot) + strings.TrimPrefix(string(entry.FullPath), prefix)),
+			Attr:     entry.Attr,
+			Chunks:   entry.Chunks,
+			Extended: entry.Extended,
+		})
+		if len(batch) >= 1024 {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		glog.Errorf("create snapshot %s of %s: %v", name, dir, err)
+		if _, deleteErr := f.deleteSnapshot(ctx, name); deleteErr != nil {
+			glog.Errorf("delete incomplete snapshot %s: %v", name, deleteErr)
+		}
+		return 0, 0, fmt.Errorf("create snapshot %s: %v", name, err)
+	}
+
+	glog.V(0).Infof("created snapshot %s of %s: %d entries, %d chunks", name, dir, entryCount, chunkCount)
+	return entryCount, chunkCount, nil
+}
+
+// DeleteSnapshot deletes the snapshot entries,
+// and the chunks referenced by neither the other snapshots nor the live entries.
+func (f *Filer) DeleteSnapshot(ctx context.Context, name string) (deletedChunkCount int, err error) {
+	if name == "" || name == "." || name == ".." || strings.Contains(name, "/") {
+		return 0, fmt.Errorf("invalid snapshot name %q", name)
+	}
+
+	f.snapshotChunks.changeLock.Lock()
+	defer f.snapshotChunks.changeLock.Unlock()
+
+	return f.deleteSnapshot(ctx, name)
+}
+
+func (f *Filer) deleteSnapshot(ctx context.Context, name string) (deletedChunkCount int, err error) {
+
+	snapshotRoot := SnapshotsDir.Child(name)
+	if _, err = f.FindEntry(ctx, snapshotRoot); err != nil {
+		return 0, fmt.Errorf("find snapshot %s: %v", name, err)
+	}
+
+	var fileIds []string
+	err = f.listAllEntries(ctx, snapshotRoot, true, func(entry *Entry) error {
+		for _, chunk := range entry.Chunks {
+			fileIds = append(fileIds, chunk.GetFileIdString())
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("list snapshot %s: %v", name, err)
+	}
+
+	if err = f.DeleteEntryMetaAndData(withSnapshotWrite(ctx), snapshotRoot, true, false); err != nil {
+		return 0, fmt.Errorf("delete snapshot %s: %v", name, err)
+	}
+
+	unreferenced := f.snapshotChunks.remove(fileIds)
+	if len(unreferenced) == 0 {
+		return 0, nil
+	}
+
+	// the chunks may still be used by the live entries
+	candidates := make(map[string]bool)
+	for _, fileId := range unreferenced {
+		candidates[fileId] = true
+	}
+	err = f.listLiveEntries(ctx, "/", func(entry *Entry) error {
+		for _, chunk := range entry.Chunks {
+			delete(candidates, chunk.GetFileIdString())
+		}
+		return nil
+	})
+	if err != nil {
+		// keeping some garbage is better than deleting the live chunks
+		return 0, fmt.Errorf("snapshot %s is deleted, but failed to find its unused chunks: %v", name, err)
+	}
+
+	for fileId := range candidates {
+		f.DeleteFileByFileId(fileId)
+	}
+
+	glog.V(0).Infof("deleted snapshot %s, and %d of its %d chunks", name, len(candidates), len(fileIds))
+	return len(candidates), nil
+}
+
+// listLiveEntries visits every entry under the directory recursively, skipping the snapshots and the dedup index
+func (f *Filer) listLiveEntries(ctx context.Context, dir FullPath, fn func(entry *Entry) error) error {
+	return f.listAllEntries(ctx, dir, false, func(entry *Entry) error {
+		if IsSnapshotPath(entry.FullPath) || IsDedupPath(entry.FullPath) {
+			return nil
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+		if entry.IsDirectory() {
+			return f.listLiveEntries(ctx, entry.FullPath, fn)
+		}
+		return nil
+	})
+}
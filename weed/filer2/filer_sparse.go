@@ -0,0 +1,76 @@
+package filer2
+
+import (
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+)
+
+// the zeros to read from the holes, in pieces
+var zeroBlock = make([]byte, 64*1024)
+
+// FileSize is the size of the file, which may end with a hole after truncating up
+func FileSize(entry *filer_pb.Entry) uint64 {
+	size := TotalSize(entry.Chunks)
+	if entry.Attributes != nil && entry.Attributes.FileSize > size {
+		return entry.Attributes.FileSize
+	}
+	return size
+}
+
+// AllocatedSize is the bytes stored for the file, not counting the holes and the overwritten ranges
+func AllocatedSize(visibles []VisibleInterval) (size uint64) {
+	for _, v := range visibles {
+		size += uint64(v.stop - v.start)
+	}
+	return
+}
+
+// IsHole tells the view is of a range not written by any chunk, and reads as zeros
+func (cv *ChunkView) IsHole() bool {
+	return cv.FileId == ""
+}
+
+// FillHoles adds the views of the holes between the chunk views, to cover [offset, offset+size)
+func FillHoles(views []*ChunkView, offset int64, size int64) (filled []*ChunkView) {
+	stop := offset + size
+	for _, view := range views {
+		if offset < view.LogicOffset {
+			filled = append(filled, &ChunkView{
+				Size:        uint64(view.LogicOffset - offset),
+				LogicOffset: offset,
+			})
+		}
+		filled = append(filled, view)
+		offset = view.LogicOffset + int64(view.Size)
+	}
+	if offset < stop {
+		filled = append(filled, &ChunkView{
+			Size:        uint64(stop - offset),
+			LogicOffset: offset,
+		})
+	}
+	return
+}
+
+// readHole passes the zeros of the hole to fn in pieces
+func readHole(chunkView *ChunkView, fn func(data []byte)) {
+	for remaining := int64(chunkView.Size); remaining > 0; remaining -= int64(len(zeroBlock)) {
+		fn(zeroBlock[:min(remaining, int64(len(zeroBlock)))])
+	}
+}
+
+// TruncateChunks cuts the chunks at the size. The chunks starting beyond the size are garbage.
+func TruncateChunks(chunks []*filer_pb.FileChunk, size uint64) (kept, garbage []*filer_pb.FileChunk) {
+	for _, chunk := range chunks {
+		if uint64(chunk.Offset) >= size {
+			garbage = append(garbage, chunk)
+			continue
+		}
+		if uint64(chunk.Offset)+chunk.Size > size {
+			chunk.Size = size - uint64(chunk.Offset)
+			// the cut chunk does not have the content of the whole needle any more
+			chunk.ETag = ""
+		}
+		kept = append(kept, chunk)
+	}
+	return
+}
@@ -0,0 +1,70 @@
+package filer2
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+)
+
+func TestFillHoles(t *testing.T) {
+	chunks := []*filer_pb.FileChunk{
+		{Offset: 100, Size: 100, FileId: "abc", Mtime: 123},
+		{Offset: 300, Size: 100, FileId: "def", Mtime: 134},
+	}
+
+	views := FillHoles(ViewFromChunks(chunks, 50, 400), 50, 400)
+
+	expected := []*ChunkView{
+		{Size: 50, LogicOffset: 50},
+		{FileId: "abc", Offset: 0, Size: 100, LogicOffset: 100},
+		{Size: 100, LogicOffset: 200},
+		{FileId: "def", Offset: 0, Size: 100, LogicOffset: 300},
+		{Size: 50, LogicOffset: 400},
+	}
+	if len(views) != len(expected) {
+		t.Fatalf("%d views, expected %d", len(views), len(expected))
+	}
+	for i, view := range views {
+		if view.FileId != expected[i].FileId || view.Offset != expected[i].Offset ||
+			view.Size != expected[i].Size || view.LogicOffset != expected[i].LogicOffset {
+			t.Errorf("view %d: %+v, expected %+v", i, view, expected[i])
+		}
+	}
+
+	var data []byte
+	if err := ReadChunkView("", views[0], func(d []byte) { data = append(data, d...) }); err != nil {
+		t.Fatalf("read hole: %v", err)
+	}
+	if !bytes.Equal(data, make([]byte, 50)) {
+		t.Errorf("hole is read as %v", data)
+	}
+}
+
+func TestTruncateChunks(t *testing.T) {
+	chunks := []*filer_pb.FileChunk{
+		{Offset: 0, Size: 100, FileId: "abc", ETag: "1"},
+		{Offset: 100, Size: 100, FileId: "def", ETag: "2"},
+		{Offset: 200, Size: 100, FileId: "ghi", ETag: "3"},
+	}
+
+	kept, garbage := TruncateChunks(chunks, 150)
+
+	if len(kept) != 2 || kept[1].Size != 50 || kept[1].ETag != "" || kept[0].Size != 100 || kept[0].ETag != "1" {
+		t.Errorf("kept %v", kept)
+	}
+	if len(garbage) != 1 || garbage[0].FileId != "ghi" {
+		t.Errorf("garbage %v", garbage)
+	}
+
+	entry := &filer_pb.Entry{
+		Chunks:     kept,
+		Attributes: &filer_pb.FuseAttributes{FileSize: 1000},
+	}
+	if FileSize(entry) != 1000 {
+		t.Errorf("file size %d", FileSize(entry))
+	}
+	if size := AllocatedSize(NonOverlappingVisibleIntervals(entry.Chunks)); size != 150 {
+		t.Errorf("allocated size %d", size)
+	}
+}
@@ -0,0 +1,88 @@
+package filer2
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+)
+
+// the events buffered for each subscriber, before it is dropped as too slow
+const metadataSubscriberBufferSize = 1024
+
+type metadataSubscriber struct {
+	pathPrefix string
+	events     chan *filer_pb.SubscribeMetadataResponse
+}
+
+// metadataSubscribers are the clients following the metadata changes on this filer
+type metadataSubscribers struct {
+	sync.Mutex
+	subscribers map[string]*metadataSubscriber
+}
+
+// SubscribeMetadata returns the changes of the entries under the path prefix, from now on.
+// The channel is closed if the subscriber falls too far behind, and the client should assume it missed some changes.
+func (f *Filer) SubscribeMetadata(clientName, pathPrefix string) (events <-chan *filer_pb.SubscribeMetadataResponse, unsubscribe func()) {
+	subscriber := &metadataSubscriber{
+		pathPrefix: pathPrefix,
+		events:     make(chan *filer_pb.SubscribeMetadataResponse, metadataSubscriberBufferSize),
+	}
+
+	f.metadataSubscribers.Lock()
+	if existing, found := f.metadataSubscribers.subscribers[clientName]; found {
+		close(existing.events)
+	}
+	f.metadataSubscribers.subscribers[clientName] = subscriber
+	f.metadataSubscribers.Unlock()
+
+	glog.V(0).Infof("+ metadata subscriber %s on %s", clientName, pathPrefix)
+
+	return subscriber.events, func() {
+		f.metadataSubscribers.Lock()
+		defer f.metadataSubscribers.Unlock()
+		if f.metadataSubscribers.subscribers[clientName] == subscriber {
+			delete(f.metadataSubscribers.subscribers, clientName)
+			close(subscriber.events)
+		}
+		glog.V(0).Infof("- metadata subscriber %s", clientName)
+	}
+}
+
+func (f *Filer) notifyMetadataSubscribers(directory string, eventNotification *filer_pb.EventNotification) {
+	f.metadataSubscribers.Lock()
+	defer f.metadataSubscribers.Unlock()
+
+	if len(f.metadataSubscribers.subscribers) == 0 {
+		return
+	}
+
+	event := &filer_pb.SubscribeMetadataResponse{
+		Directory:         directory,
+		EventNotification: eventNotification,
+		TsNs:              time.Now().UnixNano(),
+	}
+	for clientName, subscriber := range f.metadataSubscribers.subscribers {
+		if !hasPathPrefix(directory, subscriber.pathPrefix) && !hasPathPrefix(eventNotification.NewParentPath, subscriber.pathPrefix) {
+			continue
+		}
+		select {
+		case subscriber.events <- event:
+		default:
+			glog.V(0).Infof("drop metadata subscriber %s falling behind", clientName)
+			delete(f.metadataSubscribers.subscribers, clientName)
+			close(subscriber.events)
+		}
+	}
+}
+
+// hasPathPrefix tells the directory is the path prefix or under it
+func hasPathPrefix(dir, pathPrefix string) bool {
+	if pathPrefix == "" || pathPrefix == "/" {
+		return dir != ""
+	}
+	prefix := strings.TrimSuffix(pathPrefix, "/")
+	return dir == prefix || strings.HasPrefix(dir, prefix+"/")
+}
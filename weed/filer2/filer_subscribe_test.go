@@ -0,0 +1,37 @@
+package filer2
+
+import (
+	"testing"
+)
+
+func TestSubscribeMetadata(t *testing.T) {
+	f := NewFiler(nil, nil)
+
+	events, unsubscribe := f.SubscribeMetadata("test", "/home/chris")
+	defer unsubscribe()
+
+	f.NotifyUpdateEvent(nil, &Entry{FullPath: "/home/chris/file1"}, false)
+	f.NotifyUpdateEvent(nil, &Entry{FullPath: "/home/christina/file2"}, false)
+	f.NotifyUpdateEvent(&Entry{FullPath: "/tmp/file3"}, &Entry{FullPath: "/home/chris/sub/file3"}, false)
+
+	event := <-events
+	if event.Directory != "/home/chris" || event.EventNotification.NewEntry.Name != "file1" {
+		t.Errorf("unexpected event %+v", event)
+	}
+	event = <-events
+	if event.Directory != "/tmp" || event.EventNotification.NewParentPath != "/home/chris/sub" {
+		t.Errorf("unexpected event %+v", event)
+	}
+	select {
+	case event = <-events:
+		t.Errorf("unexpected event %+v", event)
+	default:
+	}
+
+	// the subscriber falling behind is dropped
+	for i := 0; i <= metadataSubscriberBufferSize; i++ {
+		f.NotifyUpdateEvent(nil, &Entry{FullPath: "/home/chris/file1"}, false)
+	}
+	for range events {
+	}
+}
@@ -0,0 +1,97 @@
+package filer2
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The entries written on a filer with a cluster id are stamped with the write time and the cluster id,
+// and the stamps are kept as they are when replicated to the other clusters.
+// Replicating in both directions, the sinks keep the last written entry, and skip the entries
+// with the same stamp, so that the replicated writes do not bounce back and forth between the clusters.
+
+const (
+	ExtendedWriteStamp = "seaweedfs.write"
+	// the concurrent write lost to the last writer, kept until the entry is written again locally
+	ExtendedWriteConflict = "seaweedfs.conflict"
+)
+
+type WriteStamp struct {
+	TsNs      int64
+	ClusterId string
+}
+
+func (s WriteStamp) IsZero() bool {
+	return s.TsNs == 0 && s.ClusterId == ""
+}
+
+func (s WriteStamp) String() string {
+	return fmt.Sprintf("%d@%s", s.TsNs, s.ClusterId)
+}
+
+// After orders the writes by the time, and then by the cluster id,
+// so that all the clusters pick the same last writer
+func (s WriteStamp) After(other WriteStamp) bool {
+	if s.TsNs != other.TsNs {
+		return s.TsNs > other.TsNs
+	}
+	return s.ClusterId > other.ClusterId
+}
+
+func ParseWriteStamp(value string) (s WriteStamp, err error) {
+	at := strings.Index(value, "@")
+	if at < 0 {
+		return s, fmt.Errorf("invalid write stamp %q", value)
+	}
+	if s.TsNs, err = strconv.ParseInt(value[:at], 10, 64); err != nil {
+		return s, fmt.Errorf("invalid write stamp %q: %v", value, err)
+	}
+	s.ClusterId = value[at+1:]
+	return s, nil
+}
+
+// GetWriteStamp returns the zero stamp for the entries written without any cluster id
+func GetWriteStamp(extended map[string][]byte) WriteStamp {
+	value, found := extended[ExtendedWriteStamp]
+	if !found {
+		return WriteStamp{}
+	}
+	s, _ := ParseWriteStamp(string(value))
+	return s
+}
+
+func SetWriteStamp(extended map[string][]byte, s WriteStamp) map[string][]byte {
+	if extended == nil {
+		extended = make(map[string][]byte)
+	}
+	extended[ExtendedWriteStamp] = []byte(s.String())
+	return extended
+}
+
+type replicatedWriteKey struct{}
+
+// WithReplicatedWrite keeps the write stamps of the entries replicated from the other clusters
+func WithReplicatedWrite(ctx context.Context) context.Context {
+	return context.WithValue(ctx, replicatedWriteKey{}, true)
+}
+
+func isReplicatedWrite(ctx context.Context) bool {
+	return ctx.Value(replicatedWriteKey{}) != nil
+}
+
+// SetClusterId lets the filer stamp the entries written locally
+func (f *Filer) SetClusterId(clusterId string) {
+	f.clusterId = clusterId
+}
+
+// stampWrite stamps the entry written locally, which also resolves the earlier conflict
+func (f *Filer) stampWrite(ctx context.Context, entry *Entry) {
+	if f.clusterId == "" || isReplicatedWrite(ctx) {
+		return
+	}
+	entry.Extended = SetWriteStamp(entry.Extended, WriteStamp{TsNs: time.Now().UnixNano(), ClusterId: f.clusterId})
+	delete(entry.Extended, ExtendedWriteConflict)
+}
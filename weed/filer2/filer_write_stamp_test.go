@@ -0,0 +1,55 @@
+package filer2
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWriteStamp(t *testing.T) {
+	s, err := ParseWriteStamp("1500@east")
+	if err != nil || s.TsNs != 1500 || s.ClusterId != "east" {
+		t.Fatalf("parse write stamp: %+v %v", s, err)
+	}
+	if _, err = ParseWriteStamp("1500"); err == nil {
+		t.Fatalf("missing cluster id should fail")
+	}
+	if !s.After(WriteStamp{TsNs: 1400, ClusterId: "west"}) {
+		t.Errorf("later write should win")
+	}
+	if !(WriteStamp{TsNs: 1500, ClusterId: "west"}).After(s) || s.After(WriteStamp{TsNs: 1500, ClusterId: "west"}) {
+		t.Errorf("writes at the same time should be ordered by the cluster id")
+	}
+	if !GetWriteStamp(nil).IsZero() {
+		t.Errorf("unstamped entry should have the zero stamp")
+	}
+	if GetWriteStamp(SetWriteStamp(nil, s)) != s {
+		t.Errorf("write stamp not kept in the extended attributes")
+	}
+}
+
+func TestStampWrite(t *testing.T) {
+	f := &Filer{}
+	entry := &Entry{}
+	f.stampWrite(context.Background(), entry)
+	if entry.Extended != nil {
+		t.Fatalf("filer without cluster id should not stamp: %v", entry.Extended)
+	}
+
+	f.SetClusterId("east")
+	replicated := WriteStamp{TsNs: 100, ClusterId: "west"}
+	entry.Extended = SetWriteStamp(nil, replicated)
+	entry.Extended[ExtendedWriteConflict] = []byte("write 90@east")
+	f.stampWrite(WithReplicatedWrite(context.Background()), entry)
+	if GetWriteStamp(entry.Extended) != replicated || entry.Extended[ExtendedWriteConflict] == nil {
+		t.Fatalf("replicated write should keep its stamp and conflict: %v", entry.Extended)
+	}
+
+	f.stampWrite(context.Background(), entry)
+	stamp := GetWriteStamp(entry.Extended)
+	if stamp.ClusterId != "east" || !stamp.After(replicated) {
+		t.Errorf("local write stamped %+v", stamp)
+	}
+	if _, found := entry.Extended[ExtendedWriteConflict]; found {
+		t.Errorf("local write should clear the conflict")
+	}
+}
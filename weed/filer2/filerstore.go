@@ -27,16 +27,33 @@ type FilerStore interface {
 	RollbackTransaction(ctx context.Context) error
 }
 
+// BatchInsertStore is optionally implemented by the filer stores, to insert many entries efficiently
+type BatchInsertStore interface {
+	InsertEntries(context.Context, []*Entry) error
+}
+
+// StatsStore is optionally implemented by the filer stores, to report the backend specific stats
+type StatsStore interface {
+	Stats() map[string]float64
+}
+
 var ErrNotFound = errors.New("filer: no entry is found in filer store")
 
+// the interval to report the backend specific stats
+const storeStatsInterval = 15 * time.Second
+
 type FilerStoreWrapper struct {
 	actualStore FilerStore
 }
 
 func NewFilerStoreWrapper(store FilerStore) *FilerStoreWrapper {
-	return &FilerStoreWrapper{
+	fsw := &FilerStoreWrapper{
 		actualStore: store,
 	}
+	if statsStore, ok := store.(StatsStore); ok {
+		go fsw.loopReportingStats(statsStore)
+	}
+	return fsw
 }
 
 func (fsw *FilerStoreWrapper) GetName() string {
@@ -47,34 +64,59 @@ func (fsw *FilerStoreWrapper) Initialize(configuration util.Configuration) error
 	return fsw.actualStore.Initialize(configuration)
 }
 
-func (fsw *FilerStoreWrapper) InsertEntry(ctx context.Context, entry *Entry) error {
-	stats.FilerStoreCounter.WithLabelValues(fsw.actualStore.GetName(), "insert").Inc()
-	start := time.Now()
-	defer func() {
-		stats.FilerStoreHistogram.WithLabelValues(fsw.actualStore.GetName(), "insert").Observe(time.Since(start).Seconds())
-	}()
+// observe counts the request, its latency, and its error
+func (fsw *FilerStoreWrapper) observe(requestType string, start time.Time, err *error) {
+	storeName := fsw.actualStore.GetName()
+	stats.FilerStoreCounter.WithLabelValues(storeName, requestType).Inc()
+	stats.FilerStoreHistogram.WithLabelValues(storeName, requestType).Observe(time.Since(start).Seconds())
+	if *err != nil && *err != ErrNotFound {
+		stats.FilerStoreErrorCounter.WithLabelValues(storeName, requestType).Inc()
+	}
+}
+
+func (fsw *FilerStoreWrapper) loopReportingStats(statsStore StatsStore) {
+	storeName := fsw.actualStore.GetName()
+	for {
+		for name, value := range statsStore.Stats() {
+			stats.FilerStoreBackendGauge.WithLabelValues(storeName, name).Set(value)
+		}
+		time.Sleep(storeStatsInterval)
+	}
+}
+
+func (fsw *FilerStoreWrapper) InsertEntry(ctx context.Context, entry *Entry) (err error) {
+	defer fsw.observe("insert", time.Now(), &err)
 
 	filer_pb.BeforeEntrySerialization(entry.Chunks)
 	return fsw.actualStore.InsertEntry(ctx, entry)
 }
 
-func (fsw *FilerStoreWrapper) UpdateEntry(ctx context.Context, entry *Entry) error {
-	stats.FilerStoreCounter.WithLabelValues(fsw.actualStore.GetName(), "update").Inc()
-	start := time.Now()
-	defer func() {
-		stats.FilerStoreHistogram.WithLabelValues(fsw.actualStore.GetName(), "update").Observe(time.Since(start).Seconds())
-	}()
+func (fsw *FilerStoreWrapper) InsertEntries(ctx context.Context, entries []*Entry) (err error) {
+	defer fsw.observe("insertBatch", time.Now(), &err)
+
+	for _, entry := range entries {
+		filer_pb.BeforeEntrySerialization(entry.Chunks)
+	}
+	if batchStore, ok := fsw.actualStore.(BatchInsertStore); ok {
+		return batchStore.InsertEntries(ctx, entries)
+	}
+	for _, entry := range entries {
+		if err := fsw.actualStore.InsertEntry(ctx, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (fsw *FilerStoreWrapper) UpdateEntry(ctx context.Context, entry *Entry) (err error) {
+	defer fsw.observe("update", time.Now(), &err)
 
 	filer_pb.BeforeEntrySerialization(entry.Chunks)
 	return fsw.actualStore.UpdateEntry(ctx, entry)
 }
 
 func (fsw *FilerStoreWrapper) FindEntry(ctx context.Context, fp FullPath) (entry *Entry, err error) {
-	stats.FilerStoreCounter.WithLabelValues(fsw.actualStore.GetName(), "find").Inc()
-	start := time.Now()
-	defer func() {
-		stats.FilerStoreHistogram.WithLabelValues(fsw.actualStore.GetName(), "find").Observe(time.Since(start).Seconds())
-	}()
+	defer fsw.observe("find", time.Now(), &err)
 
 	entry, err = fsw.actualStore.FindEntry(ctx, fp)
 	if err != nil {
@@ -85,23 +127,15 @@ func (fsw *FilerStoreWrapper) FindEntry(ctx context.Context, fp FullPath) (entry
 }
 
 func (fsw *FilerStoreWrapper) DeleteEntry(ctx context.Context, fp FullPath) (err error) {
-	stats.FilerStoreCounter.WithLabelValues(fsw.actualStore.GetName(), "delete").Inc()
-	start := time.Now()
-	defer func() {
-		stats.FilerStoreHistogram.WithLabelValues(fsw.actualStore.GetName(), "delete").Observe(time.Since(start).Seconds())
-	}()
+	defer fsw.observe("delete", time.Now(), &err)
 
 	return fsw.actualStore.DeleteEntry(ctx, fp)
 }
 
-func (fsw *FilerStoreWrapper) ListDirectoryEntries(ctx context.Context, dirPath FullPath, startFileName string, includeStartFile bool, limit int) ([]*Entry, error) {
-	stats.FilerStoreCounter.WithLabelValues(fsw.actualStore.GetName(), "list").Inc()
-	start := time.Now()
-	defer func() {
-		stats.FilerStoreHistogram.WithLabelValues(fsw.actualStore.GetName(), "list").Observe(time.Since(start).Seconds())
-	}()
+func (fsw *FilerStoreWrapper) ListDirectoryEntries(ctx context.Context, dirPath FullPath, startFileName string, includeStartFile bool, limit int) (entries []*Entry, err error) {
+	defer fsw.observe("list", time.Now(), &err)
 
-	entries, err := fsw.actualStore.ListDirectoryEntries(ctx, dirPath, startFileName, includeStartFile, limit)
+	entries, err = fsw.actualStore.ListDirectoryEntries(ctx, dirPath, startFileName, includeStartFile, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -111,14 +145,20 @@ func (fsw *FilerStoreWrapper) ListDirectoryEntries(ctx context.Context, dirPath
 	return entries, err
 }
 
-func (fsw *FilerStoreWrapper) BeginTransaction(ctx context.Context) (context.Context, error) {
+func (fsw *FilerStoreWrapper) BeginTransaction(ctx context.Context) (txCtx context.Context, err error) {
+	defer fsw.observe("beginTransaction", time.Now(), &err)
+
 	return fsw.actualStore.BeginTransaction(ctx)
 }
 
-func (fsw *FilerStoreWrapper) CommitTransaction(ctx context.Context) error {
+func (fsw *FilerStoreWrapper) CommitTransaction(ctx context.Context) (err error) {
+	defer fsw.observe("commitTransaction", time.Now(), &err)
+
 	return fsw.actualStore.CommitTransaction(ctx)
 }
 
-func (fsw *FilerStoreWrapper) RollbackTransaction(ctx context.Context) error {
+func (fsw *FilerStoreWrapper) RollbackTransaction(ctx context.Context) (err error) {
+	defer fsw.observe("rollbackTransaction", time.Now(), &err)
+
 	return fsw.actualStore.RollbackTransaction(ctx)
 }
@@ -0,0 +1,70 @@
+package leveldb
+
+import (
+	"fmt"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// the default compaction triggers of goleveldb
+const (
+	level0CompactionTables   = 4
+	level1CompactionBytes    = 10 * 1024 * 1024
+	compactionSizeMultiplier = 10
+)
+
+func (store *LevelDBStore) Stats() map[string]float64 {
+	stats := make(map[string]float64)
+	if err := AddDbStats(stats, store.db); err != nil {
+		glog.V(1).Infof("leveldb stats: %v", err)
+	}
+	return stats
+}
+
+// AddDbStats adds the stats of the leveldb to the stats.
+// goleveldb does not report the bytes pending compaction, so they are estimated
+// by the bytes over the compaction trigger of each level.
+func AddDbStats(stats map[string]float64, db *leveldb.DB) error {
+	var s leveldb.DBStats
+	if err := db.Stats(&s); err != nil {
+		return err
+	}
+
+	var totalBytes, pendingBytes int64
+	levelTarget := int64(level1CompactionBytes)
+	for level, size := range s.LevelSizes {
+		stats[fmt.Sprintf("level%d_bytes", level)] += float64(size)
+		totalBytes += size
+		if level == 0 {
+			if s.LevelTablesCounts[0] >= level0CompactionTables {
+				pendingBytes += size
+			}
+			continue
+		}
+		if size > levelTarget {
+			pendingBytes += size - levelTarget
+		}
+		levelTarget *= compactionSizeMultiplier
+	}
+	if len(s.LevelTablesCounts) > 0 {
+		stats["level0_tables"] += float64(s.LevelTablesCounts[0])
+	}
+	stats["size_bytes"] += float64(totalBytes)
+	stats["compaction_pending_bytes"] += float64(pendingBytes)
+	stats["opened_tables"] += float64(s.OpenedTablesCount)
+	stats["block_cache_bytes"] += float64(s.BlockCacheSize)
+	stats["io_read_bytes"] += float64(s.IORead)
+	stats["io_write_bytes"] += float64(s.IOWrite)
+	stats["write_delay_total"] += float64(s.WriteDelayCount)
+	stats["write_delay_seconds"] += s.WriteDelayDuration.Seconds()
+	writePaused := 0.0
+	if s.WritePaused {
+		writePaused = 1
+	}
+	stats["write_paused"] += writePaused
+	stats["alive_iterators"] += float64(s.AliveIterators)
+	stats["alive_snapshots"] += float64(s.AliveSnapshots)
+
+	return nil
+}
@@ -0,0 +1,18 @@
+package leveldb
+
+import (
+	weed_leveldb "github.com/chrislusf/seaweedfs/weed/filer2/leveldb"
+	"github.com/chrislusf/seaweedfs/weed/glog"
+)
+
+// Stats sums up the stats of all the leveldb instances.
+// "write_paused" is the number of the paused instances.
+func (store *LevelDB2Store) Stats() map[string]float64 {
+	stats := make(map[string]float64)
+	for d, db := range store.dbs {
+		if err := weed_leveldb.AddDbStats(stats, db); err != nil {
+			glog.V(1).Infof("leveldb2 %02d stats: %v", d, err)
+		}
+	}
+	return stats
+}
@@ -2,8 +2,12 @@ package memdb
 
 import (
 	"context"
+	"fmt"
 	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestCreateAndFind(t *testing.T) {
@@ -147,3 +151,434 @@ func TestCreateFileAndList(t *testing.T) {
 	}
 
 }
+
+func TestSnapshot(t *testing.T) {
+	filer := filer2.NewFiler(nil, nil)
+	store := &MemDbStore{}
+	store.Initialize(nil)
+	filer.SetStore(store)
+	filer.DisableDirectoryCache()
+
+	ctx := context.Background()
+
+	for _, name := range []string{"file1.jpg", "file2.jpg"} {
+		entry := &filer2.Entry{
+			FullPath: filer2.FullPath("/home/chris/" + name),
+			Attr:     filer2.Attr{Mode: 0440},
+			Chunks:   []*filer_pb.FileChunk{{FileId: "3,01637037d6" + name[4:5], Size: 100}},
+		}
+		if err := filer.CreateEntry(ctx, entry); err != nil {
+			t.Fatalf("create entry %v: %v", entry.FullPath, err)
+		}
+	}
+
+	entryCount, chunkCount, err := filer.CreateSnapshot(ctx, "/home", "s1")
+	if err != nil {
+		t.Fatalf("create snapshot: %v", err)
+	}
+	if entryCount != 3 || chunkCount != 2 {
+		t.Errorf("snapshot has %d entries and %d chunks", entryCount, chunkCount)
+	}
+
+	entry, err := filer.FindEntry(ctx, "/.snapshots/s1/chris/file1.jpg")
+	if err != nil || len(entry.Chunks) != 1 {
+		t.Fatalf("find snapshot entry: %v", err)
+	}
+
+	if err = filer.CreateEntry(ctx, &filer2.Entry{FullPath: "/.snapshots/s1/chris/file3.jpg"}); err == nil {
+		t.Errorf("created an entry in the snapshot")
+	}
+	if err = filer.DeleteEntryMetaAndData(ctx, "/.snapshots/s1", true, true); err == nil {
+		t.Errorf("deleted the snapshot directly")
+	}
+	if err = filer.DeleteEntryMetaAndData(ctx, "/", true, true); err != nil {
+		t.Fatalf("delete all: %v", err)
+	}
+	if _, err = filer.FindEntry(ctx, "/.snapshots/s1/chris/file2.jpg"); err != nil {
+		t.Errorf("snapshot entry is deleted: %v", err)
+	}
+
+	deletedChunkCount, err := filer.DeleteSnapshot(ctx, "s1")
+	if err != nil {
+		t.Fatalf("delete snapshot: %v", err)
+	}
+	if deletedChunkCount != 2 {
+		t.Errorf("deleted %d chunks", deletedChunkCount)
+	}
+	if _, err = filer.FindEntry(ctx, "/.snapshots/s1"); err != filer2.ErrNotFound {
+		t.Errorf("snapshot is not deleted: %v", err)
+	}
+
+}
+
+func TestRetention(t *testing.T) {
+	filer := filer2.NewFiler(nil, nil)
+	store := &MemDbStore{}
+	store.Initialize(nil)
+	filer.SetStore(store)
+	filer.DisableDirectoryCache()
+
+	ctx := context.Background()
+	retainUntil := time.Now().Add(time.Hour)
+
+	newEntry := func(name, fileId, mode string, retainUntil time.Time, legalHold bool) *filer2.Entry {
+		return &filer2.Entry{
+			FullPath: filer2.FullPath("/buckets/b1/" + name),
+			Attr:     filer2.Attr{Mode: 0660, RetentionMode: mode, RetainUntil: retainUntil, LegalHold: legalHold},
+			Chunks:   []*filer_pb.FileChunk{{FileId: fileId, Size: 100}},
+		}
+	}
+
+	compliance := newEntry("compliance", "3,01637037d6", filer2.RetentionModeCompliance, retainUntil, false)
+	if err := filer.CreateEntry(ctx, compliance); err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+	if err := filer.CreateEntry(ctx, newEntry("compliance", "3,01637037d7", "", time.Time{}, false)); !filer2.IsEntryLocked(err) {
+		t.Errorf("overwrite under compliance retention: %v", err)
+	}
+	if err := filer.CreateEntry(ctx, newEntry("compliance", "3,01637037d6", filer2.RetentionModeCompliance, retainUntil.Add(-time.Minute), false)); !filer2.IsEntryLocked(err) {
+		t.Errorf("shorten compliance retention: %v", err)
+	}
+	if err := filer.CreateEntry(ctx, newEntry("compliance", "3,01637037d6", filer2.RetentionModeCompliance, retainUntil.Add(time.Hour), false)); err != nil {
+		t.Errorf("extend compliance retention: %v", err)
+	}
+	if err := filer.DeleteEntryMetaAndData(filer2.WithGovernanceBypass(ctx), compliance.FullPath, false, true); !filer2.IsEntryLocked(err) {
+		t.Errorf("delete under compliance retention: %v", err)
+	}
+
+	governance := newEntry("governance", "3,01637037d8", filer2.RetentionModeGovernance, retainUntil, false)
+	if err := filer.CreateEntry(ctx, governance); err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+	if err := filer.DeleteEntryMetaAndData(ctx, governance.FullPath, false, true); !filer2.IsEntryLocked(err) {
+		t.Errorf("delete under governance retention: %v", err)
+	}
+	if err := filer.DeleteEntryMetaAndData(filer2.WithGovernanceBypass(ctx), governance.FullPath, false, true); err != nil {
+		t.Errorf("delete under governance retention with bypass: %v", err)
+	}
+
+	legalHold := newEntry("legal_hold", "3,01637037d9", "", time.Time{}, true)
+	if err := filer.CreateEntry(ctx, legalHold); err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+	if err := filer.DeleteEntryMetaAndData(ctx, "/buckets/b1", true, true); !filer2.IsEntryLocked(err) {
+		t.Errorf("delete directory with locked entries: %v", err)
+	}
+	if err := filer.CreateEntry(ctx, newEntry("legal_hold", "3,01637037d9", "", time.Time{}, false)); err != nil {
+		t.Errorf("remove legal hold: %v", err)
+	}
+	if err := filer.DeleteEntryMetaAndData(ctx, legalHold.FullPath, false, true); err != nil {
+		t.Errorf("delete after removing legal hold: %v", err)
+	}
+
+}
+
+func TestDedup(t *testing.T) {
+	filer := filer2.NewFiler(nil, nil)
+	store := &MemDbStore{}
+	store.Initialize(nil)
+	filer.SetStore(store)
+	filer.DisableDirectoryCache()
+
+	ctx := context.Background()
+	if err := filer.EnableDedup(ctx); err != nil {
+		t.Fatalf("enable dedup: %v", err)
+	}
+
+	h := filer2.NewDedupHash("", "000", "", "application/octet-stream", "")
+	h.Write([]byte("some content"))
+	contentHash := filer2.DedupHashString(h)
+
+	if chunk, err := filer.ReferenceDedupChunk(ctx, contentHash); err != nil || chunk != nil {
+		t.Fatalf("reference unknown content: %v %v", chunk, err)
+	}
+	if err := filer.AddDedupChunk(ctx, contentHash, &filer_pb.FileChunk{FileId: "3,01637037d6", Size: 12}); err != nil {
+		t.Fatalf("add dedup chunk: %v", err)
+	}
+	chunk, err := filer.ReferenceDedupChunk(ctx, contentHash)
+	if err != nil || chunk == nil || chunk.FileId != "3,01637037d6" || chunk.Size != 12 {
+		t.Fatalf("reference known content: %v %v", chunk, err)
+	}
+
+	for _, name := range []string{"a", "b"} {
+		entry := &filer2.Entry{
+			FullPath: filer2.FullPath("/home/chris/" + name),
+			Attr:     filer2.Attr{Mode: 0660},
+			Chunks:   []*filer_pb.FileChunk{{FileId: "3,01637037d6", Size: 12}},
+		}
+		if err := filer.CreateEntry(filer2.WithDedupUpload(ctx), entry); err != nil {
+			t.Fatalf("create entry: %v", err)
+		}
+	}
+
+	fidDir := filer2.FullPath("/.dedup/fids/3,01637037d6")
+	if err := filer.DeleteEntryMetaAndData(ctx, "/home/chris/a", false, true); err != nil {
+		t.Fatalf("delete a: %v", err)
+	}
+	if _, err := filer.FindEntry(ctx, fidDir); err != nil {
+		t.Errorf("chunk still referenced by b is released: %v", err)
+	}
+
+	// deleting everything keeps the dedup index
+	if err := filer.DeleteEntryMetaAndData(ctx, "/", true, true); err != nil {
+		t.Fatalf("delete all: %v", err)
+	}
+	if _, err := filer.FindEntry(ctx, fidDir); err != filer2.ErrNotFound {
+		t.Errorf("chunk without references is kept: %v", err)
+	}
+	if chunk, err := filer.ReferenceDedupChunk(ctx, contentHash); err != nil || chunk != nil {
+		t.Errorf("reference deleted content: %v %v", chunk, err)
+	}
+}
+
+func TestStartDedupCounting(t *testing.T) {
+	filer := filer2.NewFiler(nil, nil)
+	store := &MemDbStore{}
+	store.Initialize(nil)
+	filer.SetStore(store)
+	filer.DisableDirectoryCache()
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := filer.StartDedupCounting(ctx); err != nil {
+			t.Fatalf("start dedup counting: %v", err)
+		}
+	}
+	if filer.IsDedupEnabled() {
+		t.Errorf("dedup mode is enabled by the counting")
+	}
+	if _, err := filer.FindEntry(ctx, "/.dedup/hashes"); err != nil {
+		t.Errorf("dedup index is not created: %v", err)
+	}
+
+	// the chunks shared by the dedup uploads are counted
+	if err := filer.AddDedupChunk(ctx, "h", &filer_pb.FileChunk{FileId: "3,01637037d6", Size: 12}); err != nil {
+		t.Fatalf("add dedup chunk: %v", err)
+	}
+	if chunk, err := filer.ReferenceDedupChunk(ctx, "h"); err != nil || chunk == nil {
+		t.Fatalf("reference known content: %v %v", chunk, err)
+	}
+	for _, name := range []string{"a", "b"} {
+		if err := filer.CreateEntry(filer2.WithDedupUpload(ctx), &filer2.Entry{
+			FullPath: filer2.FullPath("/home/chris/" + name),
+			Attr:     filer2.Attr{Mode: 0660},
+			Chunks:   []*filer_pb.FileChunk{{FileId: "3,01637037d6", Size: 12}},
+		}); err != nil {
+			t.Fatalf("create entry: %v", err)
+		}
+	}
+	if err := filer.DeleteEntryMetaAndData(ctx, "/home/chris/a", false, true); err != nil {
+		t.Fatalf("delete a: %v", err)
+	}
+	if _, err := filer.FindEntry(ctx, "/.dedup/fids/3,01637037d6"); err != nil {
+		t.Errorf("chunk still referenced by b is released: %v", err)
+	}
+}
+
+func TestPatchEntry(t *testing.T) {
+	filer := filer2.NewFiler(nil, nil)
+	store := &MemDbStore{}
+	store.Initialize(nil)
+	filer.SetStore(store)
+	filer.DisableDirectoryCache()
+
+	ctx := context.Background()
+
+	fullpath := filer2.FullPath("/home/chris/db")
+	if err := filer.CreateEntry(ctx, &filer2.Entry{
+		FullPath: fullpath,
+		Attr:     filer2.Attr{Mode: 0660},
+		Chunks: []*filer_pb.FileChunk{
+			{FileId: "1,01", Offset: 0, Size: 100, Mtime: time.Now().UnixNano()},
+		},
+	}); err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+
+	if err := filer.PatchEntry(ctx, fullpath, []*filer_pb.FileChunk{
+		{FileId: "2,02", Offset: 10, Size: 20},
+		{FileId: "3,03", Offset: 90, Size: 20},
+	}); err != nil {
+		t.Fatalf("patch entry: %v", err)
+	}
+	// a later patch wins over the earlier ones
+	if err := filer.PatchEntry(ctx, fullpath, []*filer_pb.FileChunk{
+		{FileId: "4,04", Offset: 10, Size: 20},
+	}); err != nil {
+		t.Fatalf("patch entry again: %v", err)
+	}
+
+	entry, err := filer.FindEntry(ctx, fullpath)
+	if err != nil {
+		t.Fatalf("find entry: %v", err)
+	}
+	if filer2.TotalSize(entry.Chunks) != 110 {
+		t.Errorf("patched size %d", filer2.TotalSize(entry.Chunks))
+	}
+	var fileIds []string
+	for _, view := range filer2.ViewFromChunks(entry.Chunks, 0, 110) {
+		fileIds = append(fileIds, fmt.Sprintf("%s@%d", view.FileId, view.Offset))
+	}
+	if got := strings.Join(fileIds, " "); got != "1,01@0 4,04@0 1,01@30 3,03@0" {
+		t.Errorf("patched views: %s", got)
+	}
+	for _, chunk := range entry.Chunks {
+		if chunk.FileId == "2,02" {
+			t.Errorf("the overwritten chunk is kept")
+		}
+	}
+
+	if err := filer.PatchEntry(ctx, "/home/chris", nil); err == nil {
+		t.Errorf("patch a directory")
+	}
+}
+
+func TestAppendEntry(t *testing.T) {
+	filer := filer2.NewFiler(nil, nil)
+	store := &MemDbStore{}
+	store.Initialize(nil)
+	filer.SetStore(store)
+	filer.DisableDirectoryCache()
+
+	ctx := context.Background()
+
+	template := &filer2.Entry{
+		FullPath: filer2.FullPath("/home/chris/app.log"),
+		Attr:     filer2.Attr{Mode: 0660},
+	}
+
+	// the first append creates the file
+	offset, err := filer.AppendEntry(ctx, template, []*filer_pb.FileChunk{
+		{FileId: "1,01", Offset: 0, Size: 100},
+	})
+	if err != nil || offset != 0 {
+		t.Fatalf("append to new file at %d: %v", offset, err)
+	}
+	offset, err = filer.AppendEntry(ctx, template, []*filer_pb.FileChunk{
+		{FileId: "2,02", Offset: 0, Size: 50},
+		{FileId: "3,03", Offset: 50, Size: 10},
+	})
+	if err != nil || offset != 100 {
+		t.Fatalf("append at %d: %v", offset, err)
+	}
+
+	entry, err := filer.FindEntry(ctx, template.FullPath)
+	if err != nil {
+		t.Fatalf("find entry: %v", err)
+	}
+	var fileIds []string
+	for _, view := range filer2.ViewFromChunks(entry.Chunks, 0, 160) {
+		fileIds = append(fileIds, fmt.Sprintf("%s@%d", view.FileId, view.LogicOffset))
+	}
+	if got := strings.Join(fileIds, " "); got != "1,01@0 2,02@100 3,03@150" {
+		t.Errorf("appended views: %s", got)
+	}
+
+	if _, err := filer.AppendEntry(ctx, &filer2.Entry{FullPath: "/home/chris"}, nil); err == nil {
+		t.Errorf("append to a directory")
+	}
+}
+
+func TestReferenceChunks(t *testing.T) {
+	filer := filer2.NewFiler(nil, nil)
+	store := &MemDbStore{}
+	store.Initialize(nil)
+	filer.SetStore(store)
+	filer.DisableDirectoryCache()
+
+	ctx := context.Background()
+	if err := filer.ReferenceChunks(ctx, nil); err == nil {
+		t.Fatalf("reference chunks without counting")
+	}
+
+	// the file is created before the dedup index
+	src := &filer2.Entry{
+		FullPath: filer2.FullPath("/home/chris/src"),
+		Attr:     filer2.Attr{Mode: 0660},
+		Chunks:   []*filer_pb.FileChunk{{FileId: "4,01637037d6", Size: 12}},
+	}
+	if err := filer.CreateEntry(ctx, src); err != nil {
+		t.Fatalf("create src: %v", err)
+	}
+	if err := filer.EnableDedup(ctx); err != nil {
+		t.Fatalf("enable dedup: %v", err)
+	}
+
+	dst := &filer2.Entry{
+		FullPath: filer2.FullPath("/home/chris/dst"),
+		Attr:     filer2.Attr{Mode: 0660},
+		Chunks:   []*filer_pb.FileChunk{{FileId: "4,01637037d6", Size: 12}},
+	}
+	if err := filer.ReferenceChunks(ctx, dst.Chunks); err != nil {
+		t.Fatalf("reference chunks: %v", err)
+	}
+	if err := filer.CreateEntry(ctx, dst); err != nil {
+		t.Fatalf("create dst: %v", err)
+	}
+
+	fidDir := filer2.FullPath("/.dedup/fids/4,01637037d6")
+	if err := filer.DeleteEntryMetaAndData(ctx, src.FullPath, false, true); err != nil {
+		t.Fatalf("delete src: %v", err)
+	}
+	if _, err := filer.FindEntry(ctx, fidDir); err != nil {
+		t.Errorf("chunk still referenced by the copy is released: %v", err)
+	}
+	if err := filer.DeleteEntryMetaAndData(ctx, dst.FullPath, false, true); err != nil {
+		t.Fatalf("delete dst: %v", err)
+	}
+	if _, err := filer.FindEntry(ctx, fidDir); err != filer2.ErrNotFound {
+		t.Errorf("chunk without references is kept: %v", err)
+	}
+}
+
+func TestExpiredEntries(t *testing.T) {
+	filer := filer2.NewFiler(nil, nil)
+	store := &MemDbStore{}
+	store.Initialize(nil)
+	filer.SetStore(store)
+	filer.DisableDirectoryCache()
+
+	ctx := context.Background()
+	now := time.Now()
+
+	for i := 0; i < 6; i++ {
+		entry := &filer2.Entry{
+			FullPath: filer2.FullPath(fmt.Sprintf("/tmp/file%d", i)),
+			Attr:     filer2.Attr{Mode: 0644, Mtime: now, Crtime: now},
+		}
+		if i%2 == 0 {
+			entry.ExpireAt = now.Add(-time.Minute)
+		} else {
+			entry.ExpireAt = now.Add(time.Hour)
+		}
+		if err := filer.CreateEntry(ctx, entry); err != nil {
+			t.Fatalf("create entry %v: %v", entry.FullPath, err)
+		}
+	}
+
+	if _, err := filer.FindEntry(ctx, "/tmp/file0"); err != filer2.ErrNotFound {
+		t.Errorf("expired entry should not be found: %v", err)
+	}
+	if _, err := filer.FindEntry(ctx, "/tmp/file1"); err != nil {
+		t.Errorf("find entry not expired yet: %v", err)
+	}
+
+	// the expired entries are skipped, and the listing is filled up to the limit
+	entries, _ := filer.ListDirectoryEntries(ctx, "/tmp", "", false, 2)
+	if len(entries) != 2 || entries[0].Name() != "file1" || entries[1].Name() != "file3" {
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		t.Errorf("unexpected listing: %v", names)
+	}
+
+	// the expired entries are deleted in the background
+	for i := 0; i < 100; i++ {
+		if _, err := store.FindEntry(ctx, "/tmp/file2"); err == filer2.ErrNotFound {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("the expired entry is not deleted")
+}
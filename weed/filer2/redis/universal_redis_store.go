@@ -151,3 +151,22 @@ func (store *UniversalRedisStore) ListDirectoryEntries(ctx context.Context, full
 func genDirectoryListKey(dir string) (dirList string) {
 	return dir + DIR_LIST_MARKER
 }
+
+// Stats reports the usage of the connection pool, of all the nodes for the redis cluster
+func (store *UniversalRedisStore) Stats() map[string]float64 {
+	poolStatsClient, ok := store.Client.(interface {
+		PoolStats() *redis.PoolStats
+	})
+	if !ok {
+		return nil
+	}
+	s := poolStatsClient.PoolStats()
+	return map[string]float64{
+		"pool_hits_total":     float64(s.Hits),
+		"pool_misses_total":   float64(s.Misses),
+		"pool_timeouts_total": float64(s.Timeouts),
+		"total_connections":   float64(s.TotalConns),
+		"idle_connections":    float64(s.IdleConns),
+		"stale_connections":   float64(s.StaleConns),
+	}
+}
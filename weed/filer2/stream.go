@@ -1,33 +1,35 @@
 package filer2
 
 import (
+	"fmt"
 	"io"
 
 	"github.com/chrislusf/seaweedfs/weed/glog"
 	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
-	"github.com/chrislusf/seaweedfs/weed/util"
 	"github.com/chrislusf/seaweedfs/weed/wdclient"
 )
 
 func StreamContent(masterClient *wdclient.MasterClient, w io.Writer, chunks []*filer_pb.FileChunk, offset int64, size int) error {
 
-	chunkViews := ViewFromChunks(chunks, offset, size)
+	chunkViews := FillHoles(ViewFromChunks(chunks, offset, size), offset, int64(size))
 
-	fileId2Url := make(map[string]string)
+	fileId2Urls := make(map[string][]string)
 
 	for _, chunkView := range chunkViews {
+		if chunkView.IsHole() {
+			continue
+		}
 
-		urlString, err := masterClient.LookupFileId(chunkView.FileId)
+		urlStrings, err := masterClient.LookupFileIdUrls(chunkView.FileId)
 		if err != nil {
 			glog.V(1).Infof("operation LookupFileId %s failed, err: %v", chunkView.FileId, err)
 			return err
 		}
-		fileId2Url[chunkView.FileId] = urlString
+		fileId2Urls[chunkView.FileId] = urlStrings
 	}
 
 	for _, chunkView := range chunkViews {
-		urlString := fileId2Url[chunkView.FileId]
-		_, err := util.ReadUrlAsStream(urlString, chunkView.Offset, int(chunkView.Size), func(data []byte) {
+		err := ReadChunkViewFromUrls(fileId2Urls[chunkView.FileId], chunkView, func(data []byte) {
 			w.Write(data)
 		})
 		if err != nil {
@@ -39,3 +41,24 @@ func StreamContent(masterClient *wdclient.MasterClient, w io.Writer, chunks []*f
 	return nil
 
 }
+
+// ReadChunkViewFromUrls reads the chunk view from the first url that works.
+// The next url is tried only if nothing is read yet, so the data is not repeated.
+func ReadChunkViewFromUrls(urlStrings []string, chunkView *ChunkView, fn func(data []byte)) (err error) {
+	if chunkView.IsHole() {
+		return ReadChunkView("", chunkView, fn)
+	}
+	err = fmt.Errorf("no location to read %s", chunkView.FileId)
+	for _, urlString := range urlStrings {
+		read := false
+		err = ReadChunkView(urlString, chunkView, func(data []byte) {
+			read = true
+			fn(data)
+		})
+		if err == nil || read {
+			return err
+		}
+		glog.V(1).Infof("read %s from %s failed, err: %v", chunkView.FileId, urlString, err)
+	}
+	return err
+}
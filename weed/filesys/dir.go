@@ -93,6 +93,11 @@ func (dir *Dir) newFile(name string, entry *filer_pb.Entry) *File {
 func (dir *Dir) Create(ctx context.Context, req *fuse.CreateRequest,
 	resp *fuse.CreateResponse) (fs.Node, fs.Handle, error) {
 
+	ctx = withCaller(ctx, req.Header)
+	if dir.hasNameConflict(ctx, req.Name) {
+		return nil, nil, fuse.EEXIST
+	}
+
 	request := &filer_pb.CreateEntryRequest{
 		Directory: dir.Path,
 		Entry: &filer_pb.Entry{
@@ -116,7 +121,7 @@ func (dir *Dir) Create(ctx context.Context, req *fuse.CreateRequest,
 		if err := dir.wfs.WithFilerClient(ctx, func(client filer_pb.SeaweedFilerClient) error {
 			if _, err := client.CreateEntry(ctx, request); err != nil {
 				glog.V(0).Infof("create %s/%s: %v", dir.Path, req.Name, err)
-				return fuse.EIO
+				return toFuseError(err, fuse.EIO)
 			}
 			return nil
 		}); err != nil {
@@ -124,10 +129,13 @@ func (dir *Dir) Create(ctx context.Context, req *fuse.CreateRequest,
 		}
 	}
 
+	dir.wfs.addToNameIndex(ctx, dir.Path, req.Name)
+
 	file := dir.newFile(req.Name, request.Entry)
 	if !request.Entry.IsDirectory {
 		file.isOpen = true
 	}
+	dir.wfs.rememberNode(file.fullpath(), file)
 	fh := dir.wfs.AcquireHandle(file, req.Uid, req.Gid)
 	fh.dirtyMetadata = true
 	return file, fh, nil
@@ -136,6 +144,11 @@ func (dir *Dir) Create(ctx context.Context, req *fuse.CreateRequest,
 
 func (dir *Dir) Mkdir(ctx context.Context, req *fuse.MkdirRequest) (fs.Node, error) {
 
+	ctx = withCaller(ctx, req.Header)
+	if dir.hasNameConflict(ctx, req.Name) {
+		return nil, fuse.EEXIST
+	}
+
 	err := dir.wfs.WithFilerClient(ctx, func(client filer_pb.SeaweedFilerClient) error {
 
 		request := &filer_pb.CreateEntryRequest{
@@ -156,14 +169,16 @@ func (dir *Dir) Mkdir(ctx context.Context, req *fuse.MkdirRequest) (fs.Node, err
 		glog.V(1).Infof("mkdir: %v", request)
 		if _, err := client.CreateEntry(ctx, request); err != nil {
 			glog.V(0).Infof("mkdir %s/%s: %v", dir.Path, req.Name, err)
-			return fuse.EIO
+			return toFuseError(err, fuse.EIO)
 		}
 
 		return nil
 	})
 
 	if err == nil {
+		dir.wfs.addToNameIndex(ctx, dir.Path, req.Name)
 		node := &Dir{Path: path.Join(dir.Path, req.Name), wfs: dir.wfs}
+		dir.wfs.rememberNode(node.Path, node)
 		return node, nil
 	}
 
@@ -172,6 +187,7 @@ func (dir *Dir) Mkdir(ctx context.Context, req *fuse.MkdirRequest) (fs.Node, err
 
 func (dir *Dir) Lookup(ctx context.Context, req *fuse.LookupRequest, resp *fuse.LookupResponse) (node fs.Node, err error) {
 
+	ctx = withCaller(ctx, req.Header)
 	var entry *filer_pb.Entry
 	fullFilePath := path.Join(dir.Path, req.Name)
 
@@ -187,12 +203,23 @@ func (dir *Dir) Lookup(ctx context.Context, req *fuse.LookupRequest, resp *fuse.
 		}
 	}
 
+	name := req.Name
+	if entry == nil && dir.wfs.option.CaseInsensitive {
+		if name = dir.wfs.resolveName(ctx, dir.Path, req.Name); name != req.Name {
+			entry, err = filer2.GetEntry(ctx, dir.wfs, path.Join(dir.Path, name))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	if entry != nil {
 		if entry.IsDirectory {
-			node = &Dir{Path: path.Join(dir.Path, req.Name), wfs: dir.wfs, attributes: entry.Attributes}
+			node = &Dir{Path: path.Join(dir.Path, name), wfs: dir.wfs, attributes: entry.Attributes}
 		} else {
-			node = dir.newFile(req.Name, entry)
+			node = dir.newFile(name, entry)
 		}
+		dir.wfs.rememberNode(path.Join(dir.Path, name), node)
 
 		resp.EntryValid = time.Duration(0)
 		resp.Attr.Mtime = time.Unix(entry.Attributes.Mtime, 0)
@@ -216,6 +243,11 @@ func (dir *Dir) ReadDirAll(ctx context.Context) (ret []fuse.Dirent, err error) {
 
 		lastEntryName := ""
 
+		var nameIndex *dirNameIndex
+		if dir.wfs.option.CaseInsensitive {
+			nameIndex = newDirNameIndex()
+		}
+
 		for remaining >= 0 {
 
 			request := &filer_pb.ListEntriesRequest{
@@ -242,12 +274,18 @@ func (dir *Dir) ReadDirAll(ctx context.Context) (ret []fuse.Dirent, err error) {
 					ret = append(ret, dirent)
 				}
 				dir.wfs.listDirectoryEntriesCache.Set(path.Join(dir.Path, entry.Name), entry, cacheTtl)
+				if nameIndex != nil {
+					nameIndex.names[normalizeName(entry.Name)] = entry.Name
+				}
 				lastEntryName = entry.Name
 			}
 
 			remaining -= len(resp.Entries)
 
 			if len(resp.Entries) < paginationLimit {
+				if nameIndex != nil {
+					dir.wfs.nameIndexCache.Set(dir.Path, nameIndex, nameIndexCacheTtl)
+				}
 				break
 			}
 
@@ -261,6 +299,9 @@ func (dir *Dir) ReadDirAll(ctx context.Context) (ret []fuse.Dirent, err error) {
 
 func (dir *Dir) Remove(ctx context.Context, req *fuse.RemoveRequest) error {
 
+	ctx = withCaller(ctx, req.Header)
+	req.Name = dir.wfs.resolveName(ctx, dir.Path, req.Name)
+
 	if !req.Dir {
 		return dir.removeOneFile(ctx, req)
 	}
@@ -271,29 +312,24 @@ func (dir *Dir) Remove(ctx context.Context, req *fuse.RemoveRequest) error {
 
 func (dir *Dir) removeOneFile(ctx context.Context, req *fuse.RemoveRequest) error {
 
-	entry, err := filer2.GetEntry(ctx, dir.wfs, path.Join(dir.Path, req.Name))
-	if err != nil {
-		return err
-	}
-
-	dir.wfs.deleteFileChunks(ctx, entry.Chunks)
-
 	return dir.wfs.WithFilerClient(ctx, func(client filer_pb.SeaweedFilerClient) error {
 
+		// the filer deletes the chunks, keeping the ones referenced by the snapshots or the deduplicated files
 		request := &filer_pb.DeleteEntryRequest{
 			Directory:    dir.Path,
 			Name:         req.Name,
-			IsDeleteData: false,
+			IsDeleteData: true,
 		}
 
 		glog.V(3).Infof("remove file: %v", request)
 		_, err := client.DeleteEntry(ctx, request)
 		if err != nil {
 			glog.V(3).Infof("remove file %s/%s: %v", dir.Path, req.Name, err)
-			return fuse.ENOENT
+			return toFuseError(err, fuse.ENOENT)
 		}
 
 		dir.wfs.listDirectoryEntriesCache.Delete(path.Join(dir.Path, req.Name))
+		dir.wfs.removeFromNameIndex(ctx, dir.Path, req.Name)
 
 		return nil
 	})
@@ -314,10 +350,11 @@ func (dir *Dir) removeFolder(ctx context.Context, req *fuse.RemoveRequest) error
 		_, err := client.DeleteEntry(ctx, request)
 		if err != nil {
 			glog.V(3).Infof("remove %s/%s: %v", dir.Path, req.Name, err)
-			return fuse.ENOENT
+			return toFuseError(err, fuse.ENOENT)
 		}
 
 		dir.wfs.listDirectoryEntriesCache.Delete(path.Join(dir.Path, req.Name))
+		dir.wfs.removeFromNameIndex(ctx, dir.Path, req.Name)
 
 		return nil
 	})
@@ -330,6 +367,8 @@ func (dir *Dir) Setattr(ctx context.Context, req *fuse.SetattrRequest, resp *fus
 		return nil
 	}
 
+	ctx = withCaller(ctx, req.Header)
+
 	glog.V(3).Infof("%v dir setattr %+v, fh=%d", dir.Path, req, req.Handle)
 	if req.Valid.Mode() {
 		dir.attributes.FileMode = uint32(req.Mode)
@@ -347,6 +386,16 @@ func (dir *Dir) Setattr(ctx context.Context, req *fuse.SetattrRequest, resp *fus
 		dir.attributes.Mtime = req.Mtime.Unix()
 	}
 
+	// keep the extended attributes, like the name index
+	entry, err := filer2.GetEntry(ctx, dir.wfs, dir.Path)
+	if err != nil {
+		return toFuseError(err, fuse.EIO)
+	}
+	var extended map[string][]byte
+	if entry != nil {
+		extended = entry.Extended
+	}
+
 	parentDir, name := filer2.FullPath(dir.Path).DirAndName()
 	return dir.wfs.WithFilerClient(ctx, func(client filer_pb.SeaweedFilerClient) error {
 
@@ -355,6 +404,7 @@ func (dir *Dir) Setattr(ctx context.Context, req *fuse.SetattrRequest, resp *fus
 			Entry: &filer_pb.Entry{
 				Name:       name,
 				Attributes: dir.attributes,
+				Extended:   extended,
 			},
 		}
 
@@ -362,7 +412,7 @@ func (dir *Dir) Setattr(ctx context.Context, req *fuse.SetattrRequest, resp *fus
 		_, err := client.UpdateEntry(ctx, request)
 		if err != nil {
 			glog.V(0).Infof("UpdateEntry %s: %v", dir.Path, err)
-			return fuse.EIO
+			return toFuseError(err, fuse.EIO)
 		}
 
 		dir.wfs.listDirectoryEntriesCache.Delete(dir.Path)
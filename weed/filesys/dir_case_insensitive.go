@@ -0,0 +1,247 @@
+package filesys
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+)
+
+// With Option.CaseInsensitive, names are matched case-insensitively but stored with the case they were created with.
+// Each directory keeps an index from the normalized name to the stored name, in the extended attributes of the directory entry.
+// The index is built by listing the directory when not stored yet, and updated on the creations, removals and renames by the mounts.
+// The changes by the other clients are applied to the cached index from the metadata events.
+// The root directory has no entry to store it, so its index is only cached.
+
+const (
+	nameIndexCacheTtl = time.Minute
+	nameIndexKey      = "seaweedfs-name-index"
+)
+
+type dirNameIndex struct {
+	sync.RWMutex
+	names map[string]string // normalized name => stored name
+}
+
+func newDirNameIndex() *dirNameIndex {
+	return &dirNameIndex{names: make(map[string]string)}
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(name)
+}
+
+func (index *dirNameIndex) find(name string) (storedName string, found bool) {
+	index.RLock()
+	defer index.RUnlock()
+	storedName, found = index.names[normalizeName(name)]
+	return
+}
+
+func (index *dirNameIndex) add(name string) {
+	index.Lock()
+	defer index.Unlock()
+	index.names[normalizeName(name)] = name
+}
+
+func (index *dirNameIndex) remove(name string) {
+	index.Lock()
+	defer index.Unlock()
+	if storedName, found := index.names[normalizeName(name)]; found && storedName == name {
+		delete(index.names, normalizeName(name))
+	}
+}
+
+func (index *dirNameIndex) encode() ([]byte, error) {
+	index.RLock()
+	defer index.RUnlock()
+	return json.Marshal(index.names)
+}
+
+func decodeNameIndex(data []byte) (*dirNameIndex, error) {
+	index := newDirNameIndex()
+	if err := json.Unmarshal(data, &index.names); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+func (wfs *WFS) loadNameIndex(ctx context.Context, dirPath string) (index *dirNameIndex, err error) {
+
+	item := wfs.nameIndexCache.Get(dirPath)
+	if item != nil && !item.Expired() {
+		return item.Value().(*dirNameIndex), nil
+	}
+
+	if dirPath == "/" {
+		index, err = wfs.listNameIndex(ctx, dirPath)
+	} else {
+		index, err = wfs.updateStoredNameIndex(ctx, dirPath, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	wfs.nameIndexCache.Set(dirPath, index, nameIndexCacheTtl)
+
+	return index, nil
+}
+
+func (wfs *WFS) listNameIndex(ctx context.Context, dirPath string) (*dirNameIndex, error) {
+	index := newDirNameIndex()
+	if err := filer2.ReadDirAllEntries(ctx, wfs, dirPath, func(entry *filer_pb.Entry) {
+		index.names[normalizeName(entry.Name)] = entry.Name
+	}); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+// updateStoredNameIndex reads the index stored in the directory entry, or lists the directory if not stored yet,
+// and saves it back with the change applied.
+func (wfs *WFS) updateStoredNameIndex(ctx context.Context, dirPath string, change func(index *dirNameIndex)) (index *dirNameIndex, err error) {
+
+	entry, err := filer2.GetEntry(ctx, wfs, dirPath)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("directory %s not found", dirPath)
+	}
+
+	data, isStored := entry.Extended[nameIndexKey]
+	if isStored {
+		if index, err = decodeNameIndex(data); err != nil {
+			glog.V(0).Infof("rebuild the name index of %s: %v", dirPath, err)
+			isStored = false
+		}
+	}
+	if !isStored {
+		if index, err = wfs.listNameIndex(ctx, dirPath); err != nil {
+			return nil, err
+		}
+	}
+	if isStored && change == nil {
+		return index, nil
+	}
+	if change != nil {
+		change(index)
+	}
+
+	if data, err = index.encode(); err != nil {
+		return nil, err
+	}
+	if entry.Extended == nil {
+		entry.Extended = make(map[string][]byte)
+	}
+	entry.Extended[nameIndexKey] = data
+
+	parentDir, _ := filer2.FullPath(dirPath).DirAndName()
+	err = wfs.WithFilerClient(ctx, func(client filer_pb.SeaweedFilerClient) error {
+		_, err := client.UpdateEntry(ctx, &filer_pb.UpdateEntryRequest{
+			Directory: parentDir,
+			Entry:     entry,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("save the name index of %s: %v", dirPath, err)
+	}
+
+	return index, nil
+}
+
+// resolveName returns the stored name matching the name case-insensitively, or the name itself if not found.
+func (wfs *WFS) resolveName(ctx context.Context, dirPath, name string) string {
+
+	if !wfs.option.CaseInsensitive {
+		return name
+	}
+
+	index, err := wfs.loadNameIndex(ctx, dirPath)
+	if err != nil {
+		glog.V(0).Infof("load name index %s: %v", dirPath, err)
+		return name
+	}
+
+	if storedName, found := index.find(name); found {
+		return storedName
+	}
+	return name
+}
+
+// changeNameIndex applies the change to the cached and the stored index of the directory
+func (wfs *WFS) changeNameIndex(ctx context.Context, dirPath string, change func(index *dirNameIndex)) {
+	if !wfs.option.CaseInsensitive {
+		return
+	}
+	if item := wfs.nameIndexCache.Get(dirPath); item != nil {
+		change(item.Value().(*dirNameIndex))
+	}
+	if dirPath == "/" {
+		return
+	}
+	if _, err := wfs.updateStoredNameIndex(ctx, dirPath, change); err != nil {
+		glog.V(0).Infof("update name index %s: %v", dirPath, err)
+	}
+}
+
+func (wfs *WFS) addToNameIndex(ctx context.Context, dirPath, name string) {
+	wfs.changeNameIndex(ctx, dirPath, func(index *dirNameIndex) {
+		index.add(name)
+	})
+}
+
+func (wfs *WFS) removeFromNameIndex(ctx context.Context, dirPath, name string) {
+	wfs.changeNameIndex(ctx, dirPath, func(index *dirNameIndex) {
+		index.remove(name)
+	})
+}
+
+// renameInNameIndex moves the name in the index of the same directory in one update
+func (wfs *WFS) renameInNameIndex(ctx context.Context, oldDirPath, oldName, newDirPath, newName string) {
+	if oldDirPath != newDirPath {
+		wfs.removeFromNameIndex(ctx, oldDirPath, oldName)
+		wfs.addToNameIndex(ctx, newDirPath, newName)
+		return
+	}
+	wfs.changeNameIndex(ctx, oldDirPath, func(index *dirNameIndex) {
+		index.remove(oldName)
+		index.add(newName)
+	})
+}
+
+// applyToCachedNameIndex follows the changes by the other clients, without saving them
+func (wfs *WFS) applyToCachedNameIndex(dirPath, oldName, newName string) {
+	item := wfs.nameIndexCache.Get(dirPath)
+	if item == nil {
+		return
+	}
+	index := item.Value().(*dirNameIndex)
+	if oldName != "" {
+		index.remove(oldName)
+	}
+	if newName != "" {
+		index.add(newName)
+	}
+}
+
+// hasNameConflict checks whether an entry with a different case of the same name already exists.
+func (dir *Dir) hasNameConflict(ctx context.Context, name string) bool {
+	storedName := dir.wfs.resolveName(ctx, dir.Path, name)
+	if storedName == name {
+		return false
+	}
+	// the index may still have the names removed by the other clients
+	if entry, err := filer2.GetEntry(ctx, dir.wfs, path.Join(dir.Path, storedName)); err == nil && entry == nil {
+		dir.wfs.removeFromNameIndex(ctx, dir.Path, storedName)
+		return false
+	}
+	return true
+}
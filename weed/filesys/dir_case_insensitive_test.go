@@ -0,0 +1,79 @@
+package filesys
+
+import (
+	"context"
+	"testing"
+
+	"github.com/karlseguin/ccache"
+)
+
+func TestDirNameIndex(t *testing.T) {
+	index := newDirNameIndex()
+	index.add("ReadMe.txt")
+
+	if storedName, found := index.find("README.TXT"); !found || storedName != "ReadMe.txt" {
+		t.Errorf("find %s %v", storedName, found)
+	}
+
+	// only the stored case is removed
+	index.remove("readme.txt")
+	if _, found := index.find("readme.txt"); !found {
+		t.Errorf("removed by another case")
+	}
+	index.remove("ReadMe.txt")
+	if _, found := index.find("readme.txt"); found {
+		t.Errorf("not removed")
+	}
+
+	index.add("Photos")
+	data, err := index.encode()
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	decoded, err := decodeNameIndex(data)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if storedName, found := decoded.find("photos"); !found || storedName != "Photos" {
+		t.Errorf("decoded %s %v", storedName, found)
+	}
+	if _, err = decodeNameIndex([]byte("{")); err == nil {
+		t.Errorf("decoded the broken index")
+	}
+}
+
+func TestNameIndexRename(t *testing.T) {
+	wfs := &WFS{
+		option:         &Option{CaseInsensitive: true},
+		nameIndexCache: ccache.New(ccache.Configure()),
+	}
+	// the root directory index is only cached
+	wfs.nameIndexCache.Set("/", newDirNameIndex(), nameIndexCacheTtl)
+	ctx := context.Background()
+
+	wfs.addToNameIndex(ctx, "/", "Report.doc")
+	if name := wfs.resolveName(ctx, "/", "REPORT.DOC"); name != "Report.doc" {
+		t.Errorf("resolved %s", name)
+	}
+
+	// changing the case of the name
+	wfs.renameInNameIndex(ctx, "/", "Report.doc", "/", "report.DOC")
+	if name := wfs.resolveName(ctx, "/", "Report.doc"); name != "report.DOC" {
+		t.Errorf("resolved %s after the rename", name)
+	}
+
+	// the changes by the other clients
+	wfs.applyToCachedNameIndex("/", "report.DOC", "")
+	wfs.applyToCachedNameIndex("/", "", "Notes.TXT")
+	if name := wfs.resolveName(ctx, "/", "report.doc"); name != "report.doc" {
+		t.Errorf("resolved the removed name to %s", name)
+	}
+	if name := wfs.resolveName(ctx, "/", "notes.txt"); name != "Notes.TXT" {
+		t.Errorf("resolved %s", name)
+	}
+
+	wfs.removeFromNameIndex(ctx, "/", "Notes.TXT")
+	if name := wfs.resolveName(ctx, "/", "notes.txt"); name != "notes.txt" {
+		t.Errorf("resolved the removed name to %s", name)
+	}
+}
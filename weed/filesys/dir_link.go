@@ -18,6 +18,11 @@ var _ = fs.NodeReadlinker(&File{})
 func (dir *Dir) Symlink(ctx context.Context, req *fuse.SymlinkRequest) (fs.Node, error) {
 
 	glog.V(3).Infof("Symlink: %v/%v to %v", dir.Path, req.NewName, req.Target)
+	ctx = withCaller(ctx, req.Header)
+
+	if dir.hasNameConflict(ctx, req.NewName) {
+		return nil, fuse.EEXIST
+	}
 
 	request := &filer_pb.CreateEntryRequest{
 		Directory: dir.Path,
@@ -38,12 +43,17 @@ func (dir *Dir) Symlink(ctx context.Context, req *fuse.SymlinkRequest) (fs.Node,
 	err := dir.wfs.WithFilerClient(ctx, func(client filer_pb.SeaweedFilerClient) error {
 		if _, err := client.CreateEntry(ctx, request); err != nil {
 			glog.V(0).Infof("symlink %s/%s: %v", dir.Path, req.NewName, err)
-			return fuse.EIO
+			return toFuseError(err, fuse.EIO)
 		}
 		return nil
 	})
 
+	if err == nil {
+		dir.wfs.addToNameIndex(ctx, dir.Path, req.NewName)
+	}
+
 	symlink := dir.newFile(req.NewName, request.Entry)
+	dir.wfs.rememberNode(symlink.fullpath(), symlink)
 
 	return symlink, err
 
@@ -2,7 +2,9 @@ package filesys
 
 import (
 	"context"
-	"fmt"
+	"path"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
 	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
 	"github.com/seaweedfs/fuse"
 	"github.com/seaweedfs/fuse/fs"
@@ -11,23 +13,51 @@ import (
 func (dir *Dir) Rename(ctx context.Context, req *fuse.RenameRequest, newDirectory fs.Node) error {
 
 	newDir := newDirectory.(*Dir)
+	ctx = withCaller(ctx, req.Header)
+
+	oldName := dir.wfs.resolveName(ctx, dir.Path, req.OldName)
+	existingName := newDir.wfs.resolveName(ctx, newDir.Path, req.NewName)
 
 	return dir.wfs.WithFilerClient(ctx, func(client filer_pb.SeaweedFilerClient) error {
 
-		request := &filer_pb.AtomicRenameEntryRequest{
-			OldDirectory: dir.Path,
-			OldName:      req.OldName,
-			NewDirectory: newDir.Path,
-			NewName:      req.NewName,
+		if existingName != req.NewName && (existingName != oldName || newDir.Path != dir.Path) {
+			// replace the existing entry of another case, and then change to the new case
+			if err := dir.renameEntry(ctx, client, oldName, newDir, existingName); err != nil {
+				return err
+			}
+			if err := newDir.renameEntry(ctx, client, existingName, newDir, req.NewName); err != nil {
+				return err
+			}
+		} else if err := dir.renameEntry(ctx, client, oldName, newDir, req.NewName); err != nil {
+			return err
 		}
 
-		_, err := client.AtomicRenameEntry(ctx, request)
-		if err != nil {
-			return fmt.Errorf("renaming %s/%s => %s/%s: %v", dir.Path, req.OldName, newDir.Path, req.NewName, err)
-		}
+		dir.wfs.listDirectoryEntriesCache.Delete(path.Join(newDir.Path, existingName))
+		dir.wfs.renameInNameIndex(ctx, dir.Path, oldName, newDir.Path, req.NewName)
 
 		return nil
 
 	})
 
 }
+
+func (dir *Dir) renameEntry(ctx context.Context, client filer_pb.SeaweedFilerClient, oldName string, newDir *Dir, newName string) error {
+
+	request := &filer_pb.AtomicRenameEntryRequest{
+		OldDirectory: dir.Path,
+		OldName:      oldName,
+		NewDirectory: newDir.Path,
+		NewName:      newName,
+	}
+
+	_, err := client.AtomicRenameEntry(ctx, request)
+	if err != nil {
+		glog.V(0).Infof("renaming %s/%s => %s/%s: %v", dir.Path, oldName, newDir.Path, newName, err)
+		return toFuseError(err, fuse.EIO)
+	}
+
+	dir.wfs.listDirectoryEntriesCache.Delete(path.Join(dir.Path, oldName))
+	dir.wfs.listDirectoryEntriesCache.Delete(path.Join(newDir.Path, newName))
+
+	return nil
+}
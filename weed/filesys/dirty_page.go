@@ -163,18 +163,26 @@ func (pages *ContinuousDirtyPages) saveExistingPagesToStorage(ctx context.Contex
 }
 
 func (pages *ContinuousDirtyPages) saveToStorage(ctx context.Context, buf []byte, offset int64) (*filer_pb.FileChunk, error) {
+	return pages.f.saveToStorage(ctx, buf, offset)
+}
+
+// saveToStorage uploads the data as a new chunk of the file at the offset
+func (file *File) saveToStorage(ctx context.Context, buf []byte, offset int64) (*filer_pb.FileChunk, error) {
 
 	var fileId, host string
 	var auth security.EncodedJwt
 
-	if err := pages.f.wfs.WithFilerClient(ctx, func(client filer_pb.SeaweedFilerClient) error {
+	if err := file.wfs.WithFilerClient(ctx, func(client filer_pb.SeaweedFilerClient) error {
 
 		request := &filer_pb.AssignVolumeRequest{
 			Count:       1,
-			Replication: pages.f.wfs.option.Replication,
-			Collection:  pages.f.wfs.option.Collection,
-			TtlSec:      pages.f.wfs.option.TtlSec,
-			DataCenter:  pages.f.wfs.option.DataCenter,
+			Replication: file.wfs.option.Replication,
+			Collection:  file.wfs.option.Collection,
+			TtlSec:      file.wfs.option.TtlSec,
+			DataCenter:  file.wfs.option.DataCenter,
+		}
+		if file.wfs.option.ChunkLocality {
+			request.LocalityGroup = file.fullpath()
 		}
 
 		resp, err := client.AssignVolume(ctx, request)
@@ -192,13 +200,13 @@ func (pages *ContinuousDirtyPages) saveToStorage(ctx context.Context, buf []byte
 
 	fileUrl := fmt.Sprintf("http://%s/%s", host, fileId)
 	bufReader := bytes.NewReader(buf)
-	uploadResult, err := operation.Upload(fileUrl, pages.f.Name, bufReader, false, "application/octet-stream", nil, auth)
+	uploadResult, err := operation.Upload(fileUrl, file.Name, bufReader, false, "application/octet-stream", nil, auth)
 	if err != nil {
-		glog.V(0).Infof("upload data %v to %s: %v", pages.f.Name, fileUrl, err)
+		glog.V(0).Infof("upload data %v to %s: %v", file.Name, fileUrl, err)
 		return nil, fmt.Errorf("upload data: %v", err)
 	}
 	if uploadResult.Error != "" {
-		glog.V(0).Infof("upload failure %v to %s: %v", pages.f.Name, fileUrl, err)
+		glog.V(0).Infof("upload failure %v to %s: %v", file.Name, fileUrl, err)
 		return nil, fmt.Errorf("upload result: %v", uploadResult.Error)
 	}
 
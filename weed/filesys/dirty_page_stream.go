@@ -0,0 +1,254 @@
+package filesys
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+)
+
+// StreamingPages uploads the sequential writes in fixed size chunks, several chunks in parallel,
+// without waiting for each upload to finish, for the large files written in one pass.
+// Once a write is not sequential, the rest of the writes go to the ContinuousDirtyPages.
+// The data not yet added to the file as chunks is read from the buffer and the pending uploads.
+type StreamingPages struct {
+	f         *File
+	chunkSize int64
+	// limits the uploads in flight, and the memory they hold
+	uploadSlots chan struct{}
+	// uploads the data as a new chunk of the file at the offset
+	saveToStorage func(ctx context.Context, buf []byte, offset int64) (*filer_pb.FileChunk, error)
+
+	lock       sync.Mutex
+	isActive   bool
+	hasWritten bool
+	buf        []byte
+	bufOffset  int64
+	uploads    sync.WaitGroup
+
+	// the uploads not yet taken as chunks, in the order of the offsets
+	resultLock sync.Mutex
+	pending    []*pendingUpload
+	err        error
+}
+
+type pendingUpload struct {
+	offset int64
+	data   []byte
+	// the file truncated to this size during the upload
+	truncatedAt int64
+	isDone      bool
+	chunk       *filer_pb.FileChunk
+}
+
+func newStreamingPages(file *File) *StreamingPages {
+	option := file.wfs.option
+	if option.StreamChunkSize <= 0 {
+		return nil
+	}
+	concurrency := option.StreamConcurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	return &StreamingPages{
+		f:             file,
+		chunkSize:     option.StreamChunkSize,
+		uploadSlots:   make(chan struct{}, concurrency),
+		saveToStorage: file.saveToStorage,
+		isActive:      true,
+	}
+}
+
+// AddPage takes the write if it continues the previous writes, and returns false otherwise.
+func (pages *StreamingPages) AddPage(offset int64, data []byte) (accepted bool, err error) {
+
+	pages.lock.Lock()
+	defer pages.lock.Unlock()
+
+	if !pages.isActive {
+		return false, nil
+	}
+	if err = pages.takeError(); err != nil {
+		return true, err
+	}
+
+	if !pages.hasWritten {
+		pages.hasWritten = true
+		pages.bufOffset = offset
+	}
+	if offset != pages.bufOffset+int64(len(pages.buf)) {
+		glog.V(2).Infof("%s stop streaming: write at %d, expecting %d", pages.f.fullpath(), offset, pages.bufOffset+int64(len(pages.buf)))
+		pages.isActive = false
+		// the later writes are not read over the data still uploading
+		pages.uploadBuffer()
+		pages.uploads.Wait()
+		return false, nil
+	}
+
+	for len(data) > 0 {
+		n := int(pages.chunkSize) - len(pages.buf)
+		if n > len(data) {
+			n = len(data)
+		}
+		pages.buf = append(pages.buf, data[:n]...)
+		data = data[n:]
+		if int64(len(pages.buf)) >= pages.chunkSize {
+			pages.uploadBuffer()
+		}
+	}
+
+	return true, nil
+}
+
+// FlushToStorage uploads the buffered data, and returns the chunks of all the finished uploads
+func (pages *StreamingPages) FlushToStorage() (chunks []*filer_pb.FileChunk, err error) {
+
+	pages.lock.Lock()
+	defer pages.lock.Unlock()
+
+	pages.uploadBuffer()
+	pages.uploads.Wait()
+
+	chunks = pages.TakeChunks()
+	err = pages.takeError()
+	return
+}
+
+// TakeChunks returns the chunks of the uploads finished so far, cut to the size the file is truncated to meanwhile
+func (pages *StreamingPages) TakeChunks() (chunks []*filer_pb.FileChunk) {
+	pages.resultLock.Lock()
+	defer pages.resultLock.Unlock()
+
+	pending := pages.pending[:0]
+	for _, p := range pages.pending {
+		if !p.isDone {
+			pending = append(pending, p)
+			continue
+		}
+		if p.chunk == nil {
+			continue
+		}
+		kept, garbage := filer2.TruncateChunks([]*filer_pb.FileChunk{p.chunk}, uint64(p.truncatedAt))
+		for _, chunk := range garbage {
+			glog.V(3).Infof("garbage %s chunk truncated during the upload: %v [%d,%d)", pages.f.fullpath(), chunk.FileId, chunk.Offset, chunk.Offset+int64(chunk.Size))
+		}
+		chunks = append(chunks, kept...)
+	}
+	pages.pending = pending
+	return
+}
+
+// ReadPending copies the buffered data and the data of the pending uploads in [offset, offset+len(buf)),
+// over the data read from the chunks, and returns the end of the pending data.
+func (pages *StreamingPages) ReadPending(buf []byte, offset int64) (maxStop int64) {
+	pages.lock.Lock()
+	defer pages.lock.Unlock()
+	pages.resultLock.Lock()
+	defer pages.resultLock.Unlock()
+
+	for _, p := range pages.pending {
+		maxStop = max(maxStop, copyPending(buf, offset, p.data, p.offset, p.truncatedAt))
+	}
+	maxStop = max(maxStop, copyPending(buf, offset, pages.buf, pages.bufOffset, math.MaxInt64))
+	return
+}
+
+// PendingStop is the end of the buffered data and the pending uploads
+func (pages *StreamingPages) PendingStop() int64 {
+	return pages.ReadPending(nil, 0)
+}
+
+func copyPending(buf []byte, offset int64, data []byte, dataOffset int64, truncatedAt int64) (stop int64) {
+	stop = dataOffset + int64(len(data))
+	if stop > truncatedAt {
+		stop = truncatedAt
+	}
+	if stop <= dataOffset {
+		return 0
+	}
+	start := max(offset, dataOffset)
+	end := min(offset+int64(len(buf)), stop)
+	if start < end {
+		copy(buf[start-offset:end-offset], data[start-dataOffset:end-dataOffset])
+	}
+	return stop
+}
+
+// Truncate drops the buffered data beyond the size, and cuts the pending uploads to the size when they finish
+func (pages *StreamingPages) Truncate(size int64) {
+	pages.lock.Lock()
+	defer pages.lock.Unlock()
+
+	if pages.bufOffset >= size {
+		pages.buf = nil
+		pages.hasWritten = false
+	} else if pages.bufOffset+int64(len(pages.buf)) > size {
+		pages.buf = pages.buf[:size-pages.bufOffset]
+	}
+
+	pages.resultLock.Lock()
+	defer pages.resultLock.Unlock()
+	for _, p := range pages.pending {
+		if p.truncatedAt > size {
+			p.truncatedAt = size
+		}
+	}
+}
+
+func (pages *StreamingPages) takeError() (err error) {
+	pages.resultLock.Lock()
+	defer pages.resultLock.Unlock()
+	err, pages.err = pages.err, nil
+	return
+}
+
+// uploadBuffer starts to upload the buffered data, after waiting for a free upload slot
+func (pages *StreamingPages) uploadBuffer() {
+	if len(pages.buf) == 0 {
+		return
+	}
+	buf, offset := pages.buf, pages.bufOffset
+	pages.bufOffset += int64(len(buf))
+	pages.buf = nil
+	// timestamped before the upload, so the later writes to the same range overwrite it
+	mtime := time.Now().UnixNano()
+
+	p := &pendingUpload{offset: offset, data: buf, truncatedAt: math.MaxInt64}
+	pages.resultLock.Lock()
+	pages.pending = append(pages.pending, p)
+	pages.resultLock.Unlock()
+
+	pages.uploadSlots <- struct{}{}
+	pages.uploads.Add(1)
+	go func() {
+		defer func() {
+			<-pages.uploadSlots
+			pages.uploads.Done()
+		}()
+
+		chunk, err := pages.saveToStorage(context.Background(), buf, offset)
+
+		pages.resultLock.Lock()
+		defer pages.resultLock.Unlock()
+		p.isDone = true
+		if err != nil {
+			glog.Errorf("%s stream [%d,%d): %v", pages.f.fullpath(), offset, offset+int64(len(buf)), err)
+			pages.err = fmt.Errorf("upload [%d,%d): %v", offset, offset+int64(len(buf)), err)
+			return
+		}
+		chunk.Mtime = mtime
+		p.chunk = chunk
+	}()
+}
+
+func min(x, y int64) int64 {
+	if x < y {
+		return x
+	}
+	return y
+}
@@ -0,0 +1,106 @@
+package filesys
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+)
+
+// newTestStreamingPages uploads the chunks only after they are released
+func newTestStreamingPages(chunkSize int64) (pages *StreamingPages, release chan struct{}) {
+	release = make(chan struct{})
+	pages = &StreamingPages{
+		f:           &File{Name: "f", dir: &Dir{Path: "/d"}},
+		chunkSize:   chunkSize,
+		uploadSlots: make(chan struct{}, 4),
+		isActive:    true,
+	}
+	pages.saveToStorage = func(ctx context.Context, buf []byte, offset int64) (*filer_pb.FileChunk, error) {
+		<-release
+		return &filer_pb.FileChunk{FileId: fmt.Sprintf("3,%x", offset), Offset: offset, Size: uint64(len(buf)), ETag: "abc"}, nil
+	}
+	return
+}
+
+func TestStreamingPagesReadPending(t *testing.T) {
+	pages, release := newTestStreamingPages(4)
+
+	if accepted, err := pages.AddPage(0, []byte("0123456789")); !accepted || err != nil {
+		t.Fatalf("add page: %v %v", accepted, err)
+	}
+	if chunks := pages.TakeChunks(); len(chunks) != 0 {
+		t.Fatalf("took %d chunks still uploading", len(chunks))
+	}
+
+	// [0,8) is uploading, [8,10) is buffered
+	buf := []byte("xxxxxxxx")
+	if stop := pages.ReadPending(buf, 3); stop != 10 {
+		t.Errorf("pending stop %d", stop)
+	}
+	if string(buf) != "3456789x" {
+		t.Errorf("read pending %q", buf)
+	}
+
+	close(release)
+	chunks, err := pages.FlushToStorage()
+	if err != nil || len(chunks) != 3 {
+		t.Fatalf("flush: %d chunks, %v", len(chunks), err)
+	}
+	if stop := pages.PendingStop(); stop != 0 {
+		t.Errorf("pending stop %d after flush", stop)
+	}
+}
+
+func TestStreamingPagesTruncate(t *testing.T) {
+	pages, release := newTestStreamingPages(4)
+
+	if _, err := pages.AddPage(0, []byte("0123456789")); err != nil {
+		t.Fatalf("add page: %v", err)
+	}
+
+	pages.Truncate(5)
+	buf := make([]byte, 10)
+	if stop := pages.ReadPending(buf, 0); stop != 5 {
+		t.Errorf("pending stop %d after truncating", stop)
+	}
+	if string(buf[:5]) != "01234" || buf[5] != 0 {
+		t.Errorf("read pending %q after truncating", buf)
+	}
+
+	close(release)
+	chunks, err := pages.FlushToStorage()
+	if err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	// the upload of [4,8) is cut, and the buffered [8,10) is dropped
+	if len(chunks) != 2 {
+		t.Fatalf("kept %d chunks", len(chunks))
+	}
+	if chunks[1].Offset != 4 || chunks[1].Size != 1 || chunks[1].ETag != "" {
+		t.Errorf("cut chunk %+v", chunks[1])
+	}
+}
+
+func TestStreamingPagesStop(t *testing.T) {
+	pages, release := newTestStreamingPages(4)
+	close(release)
+
+	if _, err := pages.AddPage(0, []byte("012345")); err != nil {
+		t.Fatalf("add page: %v", err)
+	}
+	if accepted, _ := pages.AddPage(2, []byte("ab")); accepted {
+		t.Fatalf("accepted the write not continuing the previous writes")
+	}
+	// nothing is left pending to be read over the later writes
+	if stop := pages.PendingStop(); stop != 6 {
+		t.Errorf("pending stop %d", stop)
+	}
+	if chunks := pages.TakeChunks(); len(chunks) != 2 {
+		t.Errorf("took %d chunks", len(chunks))
+	}
+	if stop := pages.PendingStop(); stop != 0 {
+		t.Errorf("pending stop %d after taking the chunks", stop)
+	}
+}
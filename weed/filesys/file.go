@@ -41,11 +41,15 @@ func (file *File) Attr(ctx context.Context, attr *fuse.Attr) error {
 	}
 
 	attr.Mode = os.FileMode(file.entry.Attributes.FileMode)
-	attr.Size = filer2.TotalSize(file.entry.Chunks)
+	attr.Size = filer2.FileSize(file.entry)
 	attr.Mtime = time.Unix(file.entry.Attributes.Mtime, 0)
 	attr.Gid = file.entry.Attributes.Gid
 	attr.Uid = file.entry.Attributes.Uid
-	attr.Blocks = attr.Size/blockSize + 1
+	// the holes are not allocated
+	if file.entryViewCache == nil {
+		file.entryViewCache = filer2.NonOverlappingVisibleIntervals(file.entry.Chunks)
+	}
+	attr.Blocks = filer2.AllocatedSize(file.entryViewCache)/blockSize + 1
 	attr.BlockSize = uint32(file.wfs.option.ChunkSizeLimit)
 
 	return nil
@@ -70,6 +74,7 @@ func (file *File) Open(ctx context.Context, req *fuse.OpenRequest, resp *fuse.Op
 
 func (file *File) Setattr(ctx context.Context, req *fuse.SetattrRequest, resp *fuse.SetattrResponse) error {
 
+	ctx = withCaller(ctx, req.Header)
 	if err := file.maybeLoadAttributes(ctx); err != nil {
 		return err
 	}
@@ -78,9 +83,15 @@ func (file *File) Setattr(ctx context.Context, req *fuse.SetattrRequest, resp *f
 	if req.Valid.Size() {
 
 		glog.V(3).Infof("%v file setattr set size=%v", file.fullpath(), req.Size)
-		if req.Size == 0 {
-			// fmt.Printf("truncate %v \n", fullPath)
-			file.entry.Chunks = nil
+		if fh := file.wfs.findHandle(file.fullpath()); fh != nil && fh.streamingPages != nil {
+			// the data still uploading beyond the size is not added to the file
+			fh.streamingPages.Truncate(int64(req.Size))
+			fh.addStreamedChunks(fh.streamingPages.TakeChunks())
+		}
+		if req.Size < filer2.FileSize(file.entry) {
+			// the chunks beyond the size are deleted by the filer when the entry is updated,
+			// and truncating up only records the size, leaving a hole at the end
+			file.entry.Chunks, _ = filer2.TruncateChunks(file.entry.Chunks, req.Size)
 			file.entryViewCache = nil
 		}
 		file.entry.Attributes.FileSize = req.Size
@@ -105,7 +116,8 @@ func (file *File) Setattr(ctx context.Context, req *fuse.SetattrRequest, resp *f
 		file.entry.Attributes.Mtime = req.Mtime.Unix()
 	}
 
-	if file.isOpen {
+	// the size changes by ftruncate are saved right away, not waiting for the writes to flush the entry
+	if file.isOpen && !req.Valid.Size() {
 		return nil
 	}
 
@@ -120,7 +132,7 @@ func (file *File) Setattr(ctx context.Context, req *fuse.SetattrRequest, resp *f
 		_, err := client.UpdateEntry(ctx, request)
 		if err != nil {
 			glog.V(0).Infof("UpdateEntry file %s/%s: %v", file.dir.Path, file.Name, err)
-			return fuse.EIO
+			return toFuseError(err, fuse.EIO)
 		}
 
 		return nil
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"mime"
 	"path"
+	"sync/atomic"
 	"time"
 
 	"github.com/chrislusf/seaweedfs/weed/filer2"
@@ -16,11 +17,15 @@ import (
 )
 
 type FileHandle struct {
+	// where the last read stopped, to detect the sequential reads. First for the 64-bit alignment of atomic.
+	lastReadStop int64
+
 	// cache file has been written to
-	dirtyPages    *ContinuousDirtyPages
-	contentType   string
-	dirtyMetadata bool
-	handle        uint64
+	dirtyPages     *ContinuousDirtyPages
+	streamingPages *StreamingPages // nil if not streaming the sequential writes
+	contentType    string
+	dirtyMetadata  bool
+	handle         uint64
 
 	f         *File
 	RequestId fuse.RequestID // unique ID for request
@@ -31,10 +36,11 @@ type FileHandle struct {
 
 func newFileHandle(file *File, uid, gid uint32) *FileHandle {
 	return &FileHandle{
-		f:          file,
-		dirtyPages: newDirtyPages(file),
-		Uid:        uid,
-		Gid:        gid,
+		f:              file,
+		dirtyPages:     newDirtyPages(file),
+		streamingPages: newStreamingPages(file),
+		Uid:            uid,
+		Gid:            gid,
 	}
 }
 
@@ -50,23 +56,45 @@ func (fh *FileHandle) Read(ctx context.Context, req *fuse.ReadRequest, resp *fus
 
 	glog.V(4).Infof("%s read fh %d: [%d,%d)", fh.f.fullpath(), fh.handle, req.Offset, req.Offset+int64(req.Size))
 
+	if fh.streamingPages != nil {
+		fh.addStreamedChunks(fh.streamingPages.TakeChunks())
+	}
+
 	// this value should come from the filer instead of the old f
-	if len(fh.f.entry.Chunks) == 0 {
-		glog.V(1).Infof("empty fh %v/%v", fh.f.dir.Path, fh.f.Name)
+	fileSize := int64(filer2.FileSize(fh.f.entry))
+	if fh.streamingPages != nil {
+		fileSize = max(fileSize, fh.streamingPages.PendingStop())
+	}
+	if req.Offset >= fileSize {
+		glog.V(1).Infof("read fh %v/%v beyond the size %d", fh.f.dir.Path, fh.f.Name, fileSize)
 		return nil
 	}
 
-	buff := make([]byte, req.Size)
+	// the holes are read as zeros
+	readSize := int64(req.Size)
+	if req.Offset+readSize > fileSize {
+		readSize = fileSize - req.Offset
+	}
+	buff := make([]byte, readSize)
 
 	if fh.f.entryViewCache == nil {
 		fh.f.entryViewCache = filer2.NonOverlappingVisibleIntervals(fh.f.entry.Chunks)
 	}
 
-	chunkViews := filer2.ViewFromVisibleIntervals(fh.f.entryViewCache, req.Offset, req.Size)
+	chunkViews := filer2.ViewFromVisibleIntervals(fh.f.entryViewCache, req.Offset, int(readSize))
 
-	totalRead, err := filer2.ReadIntoBuffer(ctx, fh.f.wfs, fh.f.fullpath(), buff, chunkViews, req.Offset)
+	err := fh.f.wfs.readChunkViews(ctx, fh.f.fullpath(), buff, chunkViews, req.Offset)
+	if fh.streamingPages != nil {
+		// the data not yet added as chunks is newer than the chunks
+		fh.streamingPages.ReadPending(buff, req.Offset)
+	}
 
-	resp.Data = buff[:totalRead]
+	readStop := req.Offset + readSize
+	if lastReadStop := atomic.SwapInt64(&fh.lastReadStop, readStop); req.Offset >= lastReadStop-sequentialReadGap && req.Offset <= lastReadStop+sequentialReadGap {
+		fh.f.wfs.readAhead(fh.f.entryViewCache, readStop)
+	}
+
+	resp.Data = buff
 
 	if err != nil {
 		glog.Errorf("file handle read %s: %v", fh.f.fullpath(), err)
@@ -82,7 +110,16 @@ func (fh *FileHandle) Write(ctx context.Context, req *fuse.WriteRequest, resp *f
 
 	glog.V(4).Infof("%+v/%v write fh %d: [%d,%d)", fh.f.dir.Path, fh.f.Name, fh.handle, req.Offset, req.Offset+int64(len(req.Data)))
 
-	chunks, err := fh.dirtyPages.AddPage(ctx, req.Offset, req.Data)
+	var chunks []*filer_pb.FileChunk
+	var isStreamed bool
+	var err error
+	if fh.streamingPages != nil {
+		isStreamed, err = fh.streamingPages.AddPage(req.Offset, req.Data)
+		fh.addStreamedChunks(fh.streamingPages.TakeChunks())
+	}
+	if err == nil && !isStreamed {
+		chunks, err = fh.dirtyPages.AddPage(ctx, req.Offset, req.Data)
+	}
 	if err != nil {
 		glog.Errorf("%+v/%v write fh %d: [%d,%d): %v", fh.f.dir.Path, fh.f.Name, fh.handle, req.Offset, req.Offset+int64(len(req.Data)), err)
 		return fmt.Errorf("write %s/%s at [%d,%d): %v", fh.f.dir.Path, fh.f.Name, req.Offset, req.Offset+int64(len(req.Data)), err)
@@ -110,6 +147,17 @@ func (fh *FileHandle) Write(ctx context.Context, req *fuse.WriteRequest, resp *f
 	return nil
 }
 
+// addStreamedChunks adds the chunks finished uploading in the background.
+// They may be older than the chunks already added, so the visible intervals are computed again.
+func (fh *FileHandle) addStreamedChunks(chunks []*filer_pb.FileChunk) {
+	if len(chunks) == 0 {
+		return
+	}
+	fh.f.addChunks(chunks)
+	fh.f.entryViewCache = nil
+	fh.dirtyMetadata = true
+}
+
 func (fh *FileHandle) Release(ctx context.Context, req *fuse.ReleaseRequest) error {
 
 	glog.V(4).Infof("%v release fh %d", fh.f.fullpath(), fh.handle)
@@ -127,6 +175,16 @@ func (fh *FileHandle) Flush(ctx context.Context, req *fuse.FlushRequest) error {
 	// fflush works at fh level
 	// send the data to the OS
 	glog.V(4).Infof("%s fh %d flush %v", fh.f.fullpath(), fh.handle, req)
+	ctx = withCaller(ctx, req.Header)
+
+	if fh.streamingPages != nil {
+		chunks, err := fh.streamingPages.FlushToStorage()
+		fh.addStreamedChunks(chunks)
+		if err != nil {
+			glog.Errorf("flush %s/%s: %v", fh.f.dir.Path, fh.f.Name, err)
+			return fmt.Errorf("flush %s/%s: %v", fh.f.dir.Path, fh.f.Name, err)
+		}
+	}
 
 	chunk, err := fh.dirtyPages.FlushToStorage(ctx)
 	if err != nil {
@@ -142,13 +200,10 @@ func (fh *FileHandle) Flush(ctx context.Context, req *fuse.FlushRequest) error {
 
 	return fh.f.wfs.WithFilerClient(ctx, func(client filer_pb.SeaweedFilerClient) error {
 
+		// the owner and mode are set when the file is created, and only changed by setattr
 		if fh.f.entry.Attributes != nil {
 			fh.f.entry.Attributes.Mime = fh.contentType
-			fh.f.entry.Attributes.Uid = req.Uid
-			fh.f.entry.Attributes.Gid = req.Gid
 			fh.f.entry.Attributes.Mtime = time.Now().Unix()
-			fh.f.entry.Attributes.Crtime = time.Now().Unix()
-			fh.f.entry.Attributes.FileMode = uint32(0770)
 		}
 
 		request := &filer_pb.CreateEntryRequest{
@@ -161,16 +216,15 @@ func (fh *FileHandle) Flush(ctx context.Context, req *fuse.FlushRequest) error {
 			glog.V(3).Infof("%s/%s chunks %d: %v [%d,%d)", fh.f.dir.Path, fh.f.Name, i, chunk.FileId, chunk.Offset, chunk.Offset+int64(chunk.Size))
 		}
 
-		chunks, garbages := filer2.CompactFileChunks(fh.f.entry.Chunks)
-		fh.f.entry.Chunks = chunks
-		// fh.f.entryViewCache = nil
-
+		// the filer compacts the chunks, and deletes the overwritten ones unless referenced by the snapshots
+		// or the deduplicated files, so all the chunks are sent
 		if _, err := client.CreateEntry(ctx, request); err != nil {
 			glog.Errorf("update fh: %v", err)
-			return fmt.Errorf("update fh: %v", err)
+			return toFuseError(err, fuse.EIO)
 		}
 
-		fh.f.wfs.deleteFileChunks(ctx, garbages)
+		chunks, garbages := filer2.CompactFileChunks(fh.f.entry.Chunks)
+		fh.f.entry.Chunks = chunks
 		for i, chunk := range garbages {
 			glog.V(3).Infof("garbage %s/%s chunks %d: %v [%d,%d)", fh.f.dir.Path, fh.f.Name, i, chunk.FileId, chunk.Offset, chunk.Offset+int64(chunk.Size))
 		}
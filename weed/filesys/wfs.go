@@ -11,6 +11,7 @@ import (
 	"github.com/chrislusf/seaweedfs/weed/glog"
 	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
 	"github.com/chrislusf/seaweedfs/weed/util"
+	"github.com/chrislusf/seaweedfs/weed/util/chunk_cache"
 	"github.com/karlseguin/ccache"
 	"github.com/seaweedfs/fuse"
 	"github.com/seaweedfs/fuse/fs"
@@ -28,6 +29,13 @@ type Option struct {
 	DataCenter         string
 	DirListingLimit    int
 	EntryCacheTtl      time.Duration
+	CaseInsensitive    bool
+	ChunkLocality      bool // write the chunks of one file to the same volume when possible
+	ChunkCacheDir      string
+	ChunkCacheCapacity int64 // in bytes, 0 to disable the chunk cache
+	ReadAheadChunks    int   // the chunks read into the chunk cache ahead of the sequential reads
+	StreamChunkSize    int64 // the chunk size to upload the sequential writes in parallel, 0 to disable
+	StreamConcurrency  int   // the chunks uploaded in parallel for each file
 
 	MountUid   uint32
 	MountGid   uint32
@@ -42,6 +50,7 @@ var _ = fs.FSStatfser(&WFS{})
 type WFS struct {
 	option                    *Option
 	listDirectoryEntriesCache *ccache.Cache
+	nameIndexCache            *ccache.Cache
 
 	// contains all open handles
 	handles           []*FileHandle
@@ -50,6 +59,16 @@ type WFS struct {
 	bufPool           sync.Pool
 
 	stats statsCache
+
+	// the nodes known by the kernel, to invalidate by the metadata changes on the filer
+	fuseServer *fs.Server
+	nodes      map[string]fs.Node
+	nodesLock  sync.Mutex
+
+	// the recently read chunks on the local disk
+	chunkCache   *chunk_cache.ChunkCache
+	fetching     map[string]*chunkFetch
+	fetchingLock sync.Mutex
 }
 type statsCache struct {
 	filer_pb.StatisticsResponse
@@ -60,7 +79,10 @@ func NewSeaweedFileSystem(option *Option) *WFS {
 	wfs := &WFS{
 		option:                    option,
 		listDirectoryEntriesCache: ccache.New(ccache.Configure().MaxSize(1024 * 8).ItemsToPrune(100)),
+		nameIndexCache:            ccache.New(ccache.Configure().MaxSize(1024).ItemsToPrune(10)),
 		pathToHandleIndex:         make(map[string]int),
+		nodes:                     make(map[string]fs.Node),
+		fetching:                  make(map[string]*chunkFetch),
 		bufPool: sync.Pool{
 			New: func() interface{} {
 				return make([]byte, option.ChunkSizeLimit)
@@ -68,11 +90,21 @@ func NewSeaweedFileSystem(option *Option) *WFS {
 		},
 	}
 
+	if option.ChunkCacheCapacity > 0 {
+		chunkCache, err := chunk_cache.NewChunkCache(option.ChunkCacheDir, option.ChunkCacheCapacity)
+		if err != nil {
+			glog.Fatalf("chunk cache: %v", err)
+		}
+		wfs.chunkCache = chunkCache
+	}
+
 	return wfs
 }
 
 func (wfs *WFS) Root() (fs.Node, error) {
-	return &Dir{Path: wfs.option.FilerMountRootPath, wfs: wfs}, nil
+	root := &Dir{Path: wfs.option.FilerMountRootPath, wfs: wfs}
+	wfs.rememberNode(root.Path, root)
+	return root, nil
 }
 
 func (wfs *WFS) WithFilerClient(ctx context.Context, fn func(filer_pb.SeaweedFilerClient) error) error {
@@ -80,7 +112,7 @@ func (wfs *WFS) WithFilerClient(ctx context.Context, fn func(filer_pb.SeaweedFil
 	return util.WithCachedGrpcClient(ctx, func(grpcConnection *grpc.ClientConn) error {
 		client := filer_pb.NewSeaweedFilerClient(grpcConnection)
 		return fn(client)
-	}, wfs.option.FilerGrpcAddress, wfs.option.GrpcDialOption)
+	}, wfs.option.FilerGrpcAddress, wfs.option.GrpcDialOption, grpc.WithUnaryInterceptor(mountIdentityInterceptor))
 
 }
 
@@ -115,6 +147,17 @@ func (wfs *WFS) AcquireHandle(file *File, uid, gid uint32) (fileHandle *FileHand
 	return
 }
 
+// findHandle returns the handle of the open file, or nil
+func (wfs *WFS) findHandle(fullpath string) *FileHandle {
+	wfs.pathToHandleLock.Lock()
+	defer wfs.pathToHandleLock.Unlock()
+
+	if index, found := wfs.pathToHandleIndex[fullpath]; found {
+		return wfs.handles[index]
+	}
+	return nil
+}
+
 func (wfs *WFS) ReleaseHandle(fullpath string, handleId fuse.HandleID) {
 	wfs.pathToHandleLock.Lock()
 	defer wfs.pathToHandleLock.Unlock()
@@ -0,0 +1,172 @@
+package filesys
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+)
+
+// the reads starting this close to where the last read stopped are taken as sequential,
+// since the kernel may send the asynchronous reads slightly out of order
+const sequentialReadGap = 1024 * 1024
+
+// chunkFetch is one chunk being read from the volume servers, shared by the readers of the same chunk
+type chunkFetch struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
+
+// readChunkViews reads the chunk views into the buffer at the base offset.
+// With the chunk cache, the whole chunks are read and cached, so the later reads of them are served locally.
+func (wfs *WFS) readChunkViews(ctx context.Context, fullpath string, buff []byte, chunkViews []*filer2.ChunkView, baseOffset int64) error {
+	if wfs.chunkCache == nil {
+		_, err := filer2.ReadIntoBuffer(ctx, wfs, fullpath, buff, chunkViews, baseOffset)
+		return err
+	}
+
+	var missingViews []*filer2.ChunkView
+	for _, chunkView := range chunkViews {
+		if data := wfs.chunkCache.GetChunk(chunkView.FileId); data != nil {
+			if err := copyChunkView(buff, data, chunkView, baseOffset); err != nil {
+				return err
+			}
+			continue
+		}
+		missingViews = append(missingViews, chunkView)
+	}
+	if len(missingViews) == 0 {
+		return nil
+	}
+
+	vid2Locations, err := wfs.lookupChunkLocations(ctx, missingViews)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	var errLock sync.Mutex
+	for _, chunkView := range missingViews {
+		wg.Add(1)
+		go func(chunkView *filer2.ChunkView) {
+			defer wg.Done()
+			data, fetchErr := wfs.fetchWholeChunk(chunkView, vid2Locations[filer2.VolumeId(chunkView.FileId)])
+			if fetchErr == nil {
+				fetchErr = copyChunkView(buff, data, chunkView, baseOffset)
+			}
+			if fetchErr != nil {
+				glog.V(0).Infof("%s read chunk %s: %v", fullpath, chunkView.FileId, fetchErr)
+				errLock.Lock()
+				err = fetchErr
+				errLock.Unlock()
+			}
+		}(chunkView)
+	}
+	wg.Wait()
+
+	return err
+}
+
+// readAhead fetches the next chunks after the offset into the chunk cache in the background
+func (wfs *WFS) readAhead(visibles []filer2.VisibleInterval, offset int64) {
+	if wfs.chunkCache == nil || wfs.option.ReadAheadChunks <= 0 {
+		return
+	}
+
+	var nextViews []*filer2.ChunkView
+	seen := make(map[string]bool)
+	for _, chunkView := range filer2.ViewFromVisibleIntervals(visibles, offset, math.MaxInt32) {
+		if len(seen) >= wfs.option.ReadAheadChunks {
+			break
+		}
+		if seen[chunkView.FileId] {
+			continue
+		}
+		seen[chunkView.FileId] = true
+		if wfs.chunkCache.HasChunk(chunkView.FileId) || wfs.isFetching(chunkView.FileId) {
+			continue
+		}
+		nextViews = append(nextViews, chunkView)
+	}
+	if len(nextViews) == 0 {
+		return
+	}
+
+	go func() {
+		vid2Locations, err := wfs.lookupChunkLocations(context.Background(), nextViews)
+		if err != nil {
+			glog.V(1).Infof("read ahead: %v", err)
+			return
+		}
+		for _, chunkView := range nextViews {
+			go func(chunkView *filer2.ChunkView) {
+				if _, err := wfs.fetchWholeChunk(chunkView, vid2Locations[filer2.VolumeId(chunkView.FileId)]); err != nil {
+					glog.V(1).Infof("read ahead chunk %s: %v", chunkView.FileId, err)
+				}
+			}(chunkView)
+		}
+	}()
+}
+
+func (wfs *WFS) lookupChunkLocations(ctx context.Context, chunkViews []*filer2.ChunkView) (map[string]*filer_pb.Locations, error) {
+	var vids []string
+	for _, chunkView := range chunkViews {
+		vids = append(vids, filer2.VolumeId(chunkView.FileId))
+	}
+	return filer2.LookupVolumeLocations(ctx, wfs, vids)
+}
+
+// fetchWholeChunk reads the whole chunk once for all the concurrent readers, and caches it
+func (wfs *WFS) fetchWholeChunk(chunkView *filer2.ChunkView, locations *filer_pb.Locations) ([]byte, error) {
+	fileId := chunkView.FileId
+
+	wfs.fetchingLock.Lock()
+	fetch, found := wfs.fetching[fileId]
+	if !found {
+		fetch = &chunkFetch{done: make(chan struct{})}
+		wfs.fetching[fileId] = fetch
+	}
+	wfs.fetchingLock.Unlock()
+
+	if found {
+		<-fetch.done
+		return fetch.data, fetch.err
+	}
+
+	if locations == nil || len(locations.Locations) == 0 {
+		fetch.err = fmt.Errorf("failed to locate %s", fileId)
+	} else {
+		fetch.data, fetch.err = filer2.FetchWholeChunk(fmt.Sprintf("http://%s/%s", locations.Locations[0].Url, fileId), chunkView)
+	}
+	if fetch.err == nil {
+		wfs.chunkCache.SetChunk(fileId, fetch.data)
+	}
+
+	wfs.fetchingLock.Lock()
+	delete(wfs.fetching, fileId)
+	wfs.fetchingLock.Unlock()
+	close(fetch.done)
+
+	return fetch.data, fetch.err
+}
+
+func (wfs *WFS) isFetching(fileId string) bool {
+	wfs.fetchingLock.Lock()
+	defer wfs.fetchingLock.Unlock()
+	_, found := wfs.fetching[fileId]
+	return found
+}
+
+func copyChunkView(buff, data []byte, chunkView *filer2.ChunkView, baseOffset int64) error {
+	stop := chunkView.Offset + int64(chunkView.Size)
+	if chunkView.Offset < 0 || stop > int64(len(data)) {
+		return fmt.Errorf("chunk %s has %d bytes, shorter than the view [%d,%d)", chunkView.FileId, len(data), chunkView.Offset, stop)
+	}
+	copy(buff[chunkView.LogicOffset-baseOffset:], data[chunkView.Offset:stop])
+	return nil
+}
@@ -0,0 +1,77 @@
+package filesys
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/seaweedfs/fuse"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// the caller of each fuse request is sent to the filer in the grpc metadata,
+// to be checked when the filer enforces the permissions.
+// The filer trusts the metadata only over the mutual tls, with the [grpc.client] cert in security.toml.
+var (
+	uidMetadataKey  = strings.ToLower(filer2.IdentityUidHeader)
+	gidsMetadataKey = strings.ToLower(filer2.IdentityGidsHeader)
+)
+
+// withCaller sends the uid and gids of the process making the fuse request
+func withCaller(ctx context.Context, header fuse.Header) context.Context {
+	return metadata.AppendToOutgoingContext(ctx,
+		uidMetadataKey, strconv.FormatUint(uint64(header.Uid), 10),
+		gidsMetadataKey, callerGids(header))
+}
+
+// callerGids is the primary gid of the caller, followed by its supplementary groups if they can be read
+func callerGids(header fuse.Header) string {
+	gids := []string{strconv.FormatUint(uint64(header.Gid), 10)}
+	f, err := os.Open(fmt.Sprintf("/proc/%d/status", header.Pid))
+	if err != nil {
+		return gids[0]
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "Groups:") {
+			continue
+		}
+		for _, gid := range strings.Fields(strings.TrimPrefix(line, "Groups:")) {
+			if gid != gids[0] {
+				gids = append(gids, gid)
+			}
+		}
+		break
+	}
+	return strings.Join(gids, ",")
+}
+
+// mountIdentityInterceptor sends the mount process identity with the requests not made for any fuse caller,
+// like flushing the dirty pages in the background
+func mountIdentityInterceptor(ctx context.Context, method string, req, reply interface{},
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if md, _ := metadata.FromOutgoingContext(ctx); len(md.Get(uidMetadataKey)) == 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx,
+			uidMetadataKey, strconv.Itoa(os.Getuid()),
+			gidsMetadataKey, strconv.Itoa(os.Getgid()))
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// toFuseError tells the denied permissions from the other filer errors
+func toFuseError(err error, fallback fuse.Errno) error {
+	if status.Code(err) == codes.PermissionDenied {
+		return fuse.Errno(syscall.EACCES)
+	}
+	return fallback
+}
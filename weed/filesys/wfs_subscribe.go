@@ -0,0 +1,147 @@
+package filesys
+
+import (
+	"context"
+	"path"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/fuse/fs"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var _ = fs.NodeForgetter(&Dir{})
+var _ = fs.NodeForgetter(&File{})
+
+// StartCacheInvalidation follows the metadata changes on the filer, by this or the other clients,
+// and invalidates the cached entries of the changed paths, locally and in the kernel.
+func (wfs *WFS) StartCacheInvalidation(server *fs.Server) {
+	wfs.fuseServer = server
+	go wfs.loopSubscribingMetadata()
+}
+
+func (wfs *WFS) loopSubscribingMetadata() {
+	ctx := context.Background()
+	for {
+		err := wfs.WithFilerClient(ctx, func(client filer_pb.SeaweedFilerClient) error {
+			stream, err := client.SubscribeMetadata(ctx, &filer_pb.SubscribeMetadataRequest{
+				ClientName: "mount:" + wfs.option.FilerMountRootPath,
+				PathPrefix: wfs.option.FilerMountRootPath,
+			})
+			if err != nil {
+				return err
+			}
+			for {
+				resp, err := stream.Recv()
+				if err != nil {
+					return err
+				}
+				wfs.invalidateByEvent(resp)
+			}
+		})
+		if status.Code(err) == codes.Unimplemented {
+			glog.Warningf("the filer does not support metadata subscription, the cached entries are only expired by time")
+			return
+		}
+		glog.V(0).Infof("subscribe filer metadata: %v", err)
+		// the changes may be missed until subscribed again
+		wfs.invalidateAllCaches()
+		time.Sleep(3 * time.Second)
+	}
+}
+
+func (wfs *WFS) invalidateByEvent(resp *filer_pb.SubscribeMetadataResponse) {
+	event := resp.EventNotification
+	if event == nil {
+		return
+	}
+	if event.OldEntry != nil {
+		wfs.invalidateEntry(resp.Directory, event.OldEntry.Name)
+		wfs.applyToCachedNameIndex(resp.Directory, event.OldEntry.Name, "")
+	}
+	if event.NewEntry != nil {
+		newParentPath := event.NewParentPath
+		if newParentPath == "" {
+			newParentPath = resp.Directory
+		}
+		wfs.invalidateEntry(newParentPath, event.NewEntry.Name)
+		wfs.applyToCachedNameIndex(newParentPath, "", event.NewEntry.Name)
+	}
+}
+
+func (wfs *WFS) invalidateEntry(dir, name string) {
+	fullpath := path.Join(dir, name)
+	glog.V(4).Infof("invalidate %s", fullpath)
+
+	wfs.listDirectoryEntriesCache.Delete(fullpath)
+
+	if wfs.fuseServer == nil {
+		return
+	}
+
+	wfs.nodesLock.Lock()
+	node, parent := wfs.nodes[fullpath], wfs.nodes[dir]
+	wfs.nodesLock.Unlock()
+
+	// the errors are only for the nodes not cached by the kernel
+	if node != nil {
+		wfs.fuseServer.InvalidateNodeAttr(node)
+		if file, ok := node.(*File); ok && !file.isOpen {
+			wfs.fuseServer.InvalidateNodeData(node)
+		}
+	}
+	if parent != nil {
+		wfs.fuseServer.InvalidateEntry(parent, name)
+		wfs.fuseServer.InvalidateNodeData(parent)
+	}
+}
+
+func (wfs *WFS) invalidateAllCaches() {
+	wfs.listDirectoryEntriesCache.Clear()
+	wfs.nameIndexCache.Clear()
+
+	if wfs.fuseServer == nil {
+		return
+	}
+
+	wfs.nodesLock.Lock()
+	var nodes []fs.Node
+	for _, node := range wfs.nodes {
+		nodes = append(nodes, node)
+	}
+	wfs.nodesLock.Unlock()
+
+	for _, node := range nodes {
+		wfs.fuseServer.InvalidateNodeAttr(node)
+		if file, ok := node.(*File); !ok || !file.isOpen {
+			wfs.fuseServer.InvalidateNodeData(node)
+		}
+	}
+}
+
+// rememberNode keeps the latest node of the path known by the kernel, to invalidate it when the path is changed
+func (wfs *WFS) rememberNode(fullpath string, node fs.Node) {
+	wfs.nodesLock.Lock()
+	defer wfs.nodesLock.Unlock()
+	wfs.nodes[fullpath] = node
+}
+
+func (wfs *WFS) forgetNode(fullpath string, node fs.Node) {
+	wfs.nodesLock.Lock()
+	defer wfs.nodesLock.Unlock()
+	if wfs.nodes[fullpath] == node {
+		delete(wfs.nodes, fullpath)
+	}
+}
+
+// Forget is called when the kernel drops the node. Implements fs.NodeForgetter
+func (dir *Dir) Forget() {
+	dir.wfs.forgetNode(dir.Path, dir)
+}
+
+// Forget is called when the kernel drops the node. Implements fs.NodeForgetter
+func (file *File) Forget() {
+	file.wfs.forgetNode(file.fullpath(), file)
+}
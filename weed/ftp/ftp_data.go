@@ -0,0 +1,334 @@
+package ftp
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+)
+
+const dataConnectTimeout = 30 * time.Second
+
+func (s *session) closePassive() {
+	if s.passive != nil {
+		s.passive.Close()
+		s.passive = nil
+	}
+}
+
+func (s *session) listenPassive() (port int, ok bool) {
+	s.closePassive()
+	s.activeAddr = ""
+	listener, err := s.server.listenPassive()
+	if err != nil {
+		glog.Errorf("ftp passive listener: %v", err)
+		s.reply(425, "Can not open the data connection.")
+		return 0, false
+	}
+	s.passive = listener
+	return listener.Addr().(*net.TCPAddr).Port, true
+}
+
+func (s *session) handlePasv() {
+	ip := net.ParseIP(s.server.option.PublicIp)
+	if ip == nil {
+		ip = s.conn.LocalAddr().(*net.TCPAddr).IP
+	}
+	ip = ip.To4()
+	if ip == nil {
+		s.reply(425, "PASV is only for IPv4, use EPSV.")
+		return
+	}
+	port, ok := s.listenPassive()
+	if !ok {
+		return
+	}
+	s.reply(227, "Entering Passive Mode (%d,%d,%d,%d,%d,%d).", ip[0], ip[1], ip[2], ip[3], port>>8, port&0xff)
+}
+
+func (s *session) handleEpsv(arg string) {
+	if strings.EqualFold(arg, "ALL") {
+		s.reply(200, "EPSV ALL ok.")
+		return
+	}
+	port, ok := s.listenPassive()
+	if !ok {
+		return
+	}
+	s.reply(229, "Entering Extended Passive Mode (|||%d|).", port)
+}
+
+// handlePort accepts the active data connections only to the client itself, to avoid the bounce attacks
+func (s *session) handlePort(arg string) {
+	parts := strings.Split(arg, ",")
+	if len(parts) != 6 {
+		s.reply(501, "Invalid PORT.")
+		return
+	}
+	var b [6]byte
+	for i, part := range parts {
+		n, err := strconv.ParseUint(strings.TrimSpace(part), 10, 8)
+		if err != nil {
+			s.reply(501, "Invalid PORT.")
+			return
+		}
+		b[i] = byte(n)
+	}
+	s.setActive(net.IPv4(b[0], b[1], b[2], b[3]), int(b[4])<<8|int(b[5]))
+}
+
+func (s *session) handleEprt(arg string) {
+	// |protocol|address|port|, with any delimiter
+	if len(arg) < 2 {
+		s.reply(501, "Invalid EPRT.")
+		return
+	}
+	parts := strings.Split(arg, arg[:1])
+	if len(parts) != 5 {
+		s.reply(501, "Invalid EPRT.")
+		return
+	}
+	ip := net.ParseIP(parts[2])
+	port, err := strconv.Atoi(parts[3])
+	if ip == nil || err != nil || port <= 0 || port > 65535 {
+		s.reply(501, "Invalid EPRT.")
+		return
+	}
+	s.setActive(ip, port)
+}
+
+func (s *session) setActive(ip net.IP, port int) {
+	if !ip.Equal(s.conn.RemoteAddr().(*net.TCPAddr).IP) {
+		s.reply(500, "The data connection must be to the client address.")
+		return
+	}
+	s.closePassive()
+	s.activeAddr = net.JoinHostPort(ip.String(), strconv.Itoa(port))
+	s.reply(200, "PORT command successful.")
+}
+
+// openData replies 150 and opens the data connection prepared by PASV, EPSV, PORT or EPRT
+func (s *session) openData(message string) (net.Conn, bool) {
+	if s.passive == nil && s.activeAddr == "" {
+		s.reply(425, "Use PASV or PORT first.")
+		return nil, false
+	}
+	s.reply(150, "%s", message)
+
+	var conn net.Conn
+	var err error
+	if s.passive != nil {
+		listener := s.passive
+		s.passive = nil
+		listener.(*net.TCPListener).SetDeadline(time.Now().Add(dataConnectTimeout))
+		conn, err = listener.Accept()
+		listener.Close()
+		if err == nil && !conn.RemoteAddr().(*net.TCPAddr).IP.Equal(s.conn.RemoteAddr().(*net.TCPAddr).IP) {
+			conn.Close()
+			err = fmt.Errorf("data connection from %s", conn.RemoteAddr())
+		}
+	} else {
+		conn, err = net.DialTimeout("tcp", s.activeAddr, dataConnectTimeout)
+		s.activeAddr = ""
+	}
+	if err != nil {
+		glog.V(1).Infof("ftp data connection of %s: %v", s.userName, err)
+		s.reply(425, "Can not open the data connection.")
+		return nil, false
+	}
+
+	if s.protectData {
+		tlsConn := tls.Server(conn, s.server.option.TLSConfig)
+		tlsConn.SetDeadline(time.Now().Add(dataConnectTimeout))
+		if err = tlsConn.Handshake(); err != nil {
+			conn.Close()
+			glog.V(1).Infof("ftp data connection tls handshake of %s: %v", s.userName, err)
+			s.reply(425, "Can not open the data connection.")
+			return nil, false
+		}
+		tlsConn.SetDeadline(time.Time{})
+		conn = tlsConn
+	}
+	return conn, true
+}
+
+func (s *session) handleList(command, arg string) {
+	// the options of ls are ignored
+	for strings.HasPrefix(arg, "-") {
+		arg = strings.TrimLeft(arg, "-abcdfghiklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ1")
+		arg = strings.TrimLeft(arg, " ")
+	}
+	target := s.path(arg)
+	fi, err := s.fs.Stat(s.ctx, target)
+	if err != nil {
+		s.replyError(err)
+		return
+	}
+	entries := []os.FileInfo{fi}
+	if fi.IsDir() {
+		if entries, err = s.fs.readDir(s.ctx, target); err != nil {
+			s.replyError(err)
+			return
+		}
+	} else if command == "MLSD" {
+		s.reply(501, "%s is not a directory.", arg)
+		return
+	}
+
+	var b strings.Builder
+	now := time.Now()
+	for _, entry := range entries {
+		switch command {
+		case "LIST":
+			b.WriteString(listLine(entry, s.user.Name, now))
+		case "NLST":
+			if arg == "" || !fi.IsDir() {
+				b.WriteString(entry.Name())
+			} else {
+				b.WriteString(path.Join(arg, entry.Name()))
+			}
+		case "MLSD":
+			b.WriteString(factsLine(entry) + " " + entry.Name())
+		}
+		b.WriteString("\r\n")
+	}
+
+	conn, ok := s.openData("Opening the data connection for the directory listing.")
+	if !ok {
+		return
+	}
+	_, err = io.WriteString(conn, b.String())
+	conn.Close()
+	if err != nil {
+		s.reply(426, "Transfer aborted.")
+		return
+	}
+	s.reply(226, "Transfer complete.")
+}
+
+func (s *session) handleMlst(arg string) {
+	target := s.path(arg)
+	fi, err := s.fs.Stat(s.ctx, target)
+	if err != nil {
+		s.replyError(err)
+		return
+	}
+	s.replyLines(250, "Listing "+target, []string{factsLine(fi) + " " + target}, "End")
+}
+
+// listLine formats the entry like "ls -l"
+func listLine(fi os.FileInfo, owner string, now time.Time) string {
+	modTime := fi.ModTime()
+	timeFormat := "Jan _2 15:04"
+	if modTime.Before(now.AddDate(0, -6, 0)) || modTime.After(now.Add(time.Hour)) {
+		timeFormat = "Jan _2  2006"
+	}
+	mode := fi.Mode()
+	if fi.IsDir() {
+		mode |= os.ModeDir
+	}
+	return fmt.Sprintf("%s 1 %s %s %12d %s %s", mode.String(), owner, owner, fi.Size(), modTime.Format(timeFormat), fi.Name())
+}
+
+// factsLine formats the facts of RFC 3659 for MLSD and MLST
+func factsLine(fi os.FileInfo) string {
+	fileType := "file"
+	if fi.IsDir() {
+		fileType = "dir"
+	}
+	return fmt.Sprintf("type=%s;size=%d;modify=%s;", fileType, fi.Size(), fi.ModTime().UTC().Format("20060102150405"))
+}
+
+func (s *session) handleRetr(arg string) {
+	offset := s.restOffset
+	s.restOffset = 0
+	fi, err := s.fs.Stat(s.ctx, s.path(arg))
+	if err != nil {
+		s.replyError(err)
+		return
+	}
+	if fi.IsDir() {
+		s.reply(550, "%s is a directory.", arg)
+		return
+	}
+	f, err := s.fs.OpenFile(s.ctx, s.path(arg), os.O_RDONLY, 0)
+	if err != nil {
+		s.replyError(err)
+		return
+	}
+	defer f.Close()
+	if offset > 0 {
+		if _, err = f.Seek(offset, io.SeekStart); err != nil {
+			s.replyError(err)
+			return
+		}
+	}
+
+	conn, ok := s.openData(fmt.Sprintf("Opening the data connection for %s (%d bytes).", arg, fi.Size()-offset))
+	if !ok {
+		return
+	}
+	_, err = io.Copy(conn, f)
+	if closeErr := conn.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		glog.V(1).Infof("ftp %s retrieve %s: %v", s.user.Name, s.path(arg), err)
+		s.reply(426, "Transfer aborted.")
+		return
+	}
+	s.reply(226, "Transfer complete.")
+}
+
+func (s *session) handleStor(arg string, isAppend bool) {
+	offset := s.restOffset
+	s.restOffset = 0
+	flag := os.O_WRONLY | os.O_CREATE
+	switch {
+	case isAppend:
+		flag |= os.O_APPEND
+	case offset == 0:
+		flag |= os.O_TRUNC
+	}
+	f, err := s.fs.OpenFile(s.ctx, s.path(arg), flag, 0644)
+	if err != nil {
+		s.replyError(err)
+		return
+	}
+	defer f.Close()
+	if offset > 0 {
+		if _, err = f.Seek(offset, io.SeekStart); err != nil {
+			s.replyError(err)
+			return
+		}
+	}
+
+	conn, ok := s.openData(fmt.Sprintf("Opening the data connection for %s.", arg))
+	if !ok {
+		return
+	}
+	writer := bufio.NewWriterSize(f, uploadBufferSize)
+	_, err = io.Copy(writer, conn)
+	if err == nil {
+		err = writer.Flush()
+	}
+	conn.Close()
+	switch {
+	case err == errQuotaExceeded:
+		glog.V(0).Infof("ftp %s store %s: quota of %d bytes exceeded", s.user.Name, s.path(arg), s.user.QuotaBytes)
+		s.reply(552, "Exceeded storage allocation.")
+	case err != nil:
+		glog.V(1).Infof("ftp %s store %s: %v", s.user.Name, s.path(arg), err)
+		s.reply(426, "Transfer aborted.")
+	default:
+		s.reply(226, "Transfer complete.")
+	}
+}
@@ -0,0 +1,297 @@
+package ftp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/webdav"
+)
+
+var errQuotaExceeded = errors.New("quota exceeded")
+
+// homeFileSystem is the file system of one user, limited to the home directory of the user on the filer.
+// All the paths are relative to the home directory, and can not go above it.
+type homeFileSystem struct {
+	fs       webdav.FileSystem
+	home     string
+	readOnly bool
+	quota    *quota
+}
+
+func (h *homeFileSystem) fullPath(name string) string {
+	return path.Join(h.home, path.Clean("/"+name))
+}
+
+func (h *homeFileSystem) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	if h.readOnly {
+		return os.ErrPermission
+	}
+	return h.fs.Mkdir(ctx, h.fullPath(name), perm)
+}
+
+func (h *homeFileSystem) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	fullPath := h.fullPath(name)
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND) == 0 {
+		return h.fs.OpenFile(ctx, fullPath, flag, perm)
+	}
+	if h.readOnly {
+		return nil, os.ErrPermission
+	}
+
+	var size, truncated int64
+	fi, err := h.fs.Stat(ctx, fullPath)
+	if err == nil {
+		if fi.IsDir() {
+			return nil, os.ErrInvalid
+		}
+		if flag&os.O_CREATE != 0 && flag&os.O_EXCL != 0 {
+			return nil, os.ErrExist
+		}
+		size = fi.Size()
+		if flag&os.O_TRUNC != 0 {
+			// the file is created again, freeing the old content
+			truncated, size = size, 0
+			flag |= os.O_CREATE
+		} else {
+			// the filer file system recreates the existing files on O_CREATE
+			flag &^= os.O_CREATE
+		}
+	}
+
+	// the appends are written at the end by the offset
+	f, err := h.fs.OpenFile(ctx, fullPath, flag&^os.O_APPEND, perm)
+	if err != nil {
+		return nil, err
+	}
+	h.quota.release(truncated)
+	qf := &quotaFile{File: f, quota: h.quota, size: size}
+	if flag&os.O_APPEND != 0 {
+		qf.offset = size
+	}
+	return qf, nil
+}
+
+func (h *homeFileSystem) RemoveAll(ctx context.Context, name string) error {
+	if h.readOnly {
+		return os.ErrPermission
+	}
+	fullPath := h.fullPath(name)
+	if fullPath == h.home {
+		return os.ErrPermission
+	}
+	usage, _ := diskUsage(ctx, h.fs, fullPath)
+	if err := h.fs.RemoveAll(ctx, fullPath); err != nil {
+		return err
+	}
+	h.quota.release(usage)
+	return nil
+}
+
+func (h *homeFileSystem) Rename(ctx context.Context, oldName, newName string) error {
+	if h.readOnly {
+		return os.ErrPermission
+	}
+	oldPath, newPath := h.fullPath(oldName), h.fullPath(newName)
+	if oldPath == h.home || newPath == h.home {
+		return os.ErrPermission
+	}
+	return h.fs.Rename(ctx, oldPath, newPath)
+}
+
+func (h *homeFileSystem) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	fi, err := h.fs.Stat(ctx, h.fullPath(name))
+	if err != nil {
+		return nil, err
+	}
+	return newFileInfo(fi), nil
+}
+
+// readDir lists the directory, sorted by the names as on the filer
+func (h *homeFileSystem) readDir(ctx context.Context, name string) ([]os.FileInfo, error) {
+	f, err := h.fs.OpenFile(ctx, h.fullPath(name), os.O_RDONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	entries, err := f.Readdir(0)
+	if err != nil {
+		return nil, err
+	}
+	for i, fi := range entries {
+		entries[i] = newFileInfo(fi)
+	}
+	return entries, nil
+}
+
+// fileInfo drops the trailing slash of the directory names listed by the filer file system
+type fileInfo struct {
+	os.FileInfo
+	name string
+}
+
+func newFileInfo(fi os.FileInfo) os.FileInfo {
+	return &fileInfo{FileInfo: fi, name: strings.TrimSuffix(fi.Name(), "/")}
+}
+
+func (fi *fileInfo) Name() string {
+	return fi.name
+}
+
+func (fi *fileInfo) Mode() os.FileMode {
+	if fi.IsDir() {
+		return fi.FileInfo.Mode() | os.ModeDir
+	}
+	return fi.FileInfo.Mode()
+}
+
+// quotaFile counts the growth of the file against the quota of the user.
+// The filer file system writes each buffer at the current offset, so the offset is set before every write.
+type quotaFile struct {
+	webdav.File
+	quota  *quota
+	size   int64
+	offset int64
+}
+
+func (f *quotaFile) Seek(offset int64, whence int) (int64, error) {
+	if whence == io.SeekEnd {
+		offset, whence = f.size+offset, io.SeekStart
+	}
+	if whence == io.SeekCurrent {
+		offset, whence = f.offset+offset, io.SeekStart
+	}
+	n, err := f.File.Seek(offset, whence)
+	if err == nil {
+		f.offset = n
+	}
+	return n, err
+}
+
+func (f *quotaFile) Write(p []byte) (int, error) {
+	end := f.offset + int64(len(p))
+	var growth int64
+	if end > f.size {
+		growth = end - f.size
+		if err := f.quota.reserve(growth); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := f.File.Seek(f.offset, io.SeekStart); err != nil {
+		f.quota.release(growth)
+		return 0, err
+	}
+	if _, err := f.File.Write(p); err != nil {
+		f.quota.release(growth)
+		return 0, err
+	}
+	f.offset = end
+	if end > f.size {
+		f.size = end
+	}
+	return len(p), nil
+}
+
+// quota is the usage of the home directory of one user, shared by all the sessions of the user
+type quota struct {
+	limit int64 // in bytes, 0 means no limit
+	sync.Mutex
+	used    int64
+	counted time.Time
+}
+
+func (q *quota) reserve(n int64) error {
+	q.Lock()
+	defer q.Unlock()
+	if q.limit > 0 && q.used+n > q.limit {
+		return errQuotaExceeded
+	}
+	q.used += n
+	return nil
+}
+
+func (q *quota) release(n int64) {
+	q.Lock()
+	defer q.Unlock()
+	q.used -= n
+	if q.used < 0 {
+		q.used = 0
+	}
+}
+
+// recount walks the home directory again if the usage is older than maxAge,
+// correcting the drift from the changes not made through this server
+func (q *quota) recount(ctx context.Context, fs webdav.FileSystem, home string, maxAge time.Duration) error {
+	if q.limit <= 0 {
+		return nil
+	}
+	q.Lock()
+	fresh := time.Since(q.counted) < maxAge
+	q.Unlock()
+	if fresh {
+		return nil
+	}
+	used, err := diskUsage(ctx, fs, home)
+	if err != nil {
+		return err
+	}
+	q.Lock()
+	q.used, q.counted = used, time.Now()
+	q.Unlock()
+	return nil
+}
+
+// diskUsage sums the sizes of the files under the path
+func diskUsage(ctx context.Context, fs webdav.FileSystem, fullPath string) (int64, error) {
+	fi, err := fs.Stat(ctx, fullPath)
+	if err != nil {
+		return 0, err
+	}
+	if !fi.IsDir() {
+		return fi.Size(), nil
+	}
+	f, err := fs.OpenFile(ctx, fullPath, os.O_RDONLY, 0)
+	if err != nil {
+		return 0, err
+	}
+	entries, err := f.Readdir(0)
+	f.Close()
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, entry := range entries {
+		if entry.IsDir() {
+			n, err := diskUsage(ctx, fs, path.Join(fullPath, strings.TrimSuffix(entry.Name(), "/")))
+			if err != nil {
+				return 0, err
+			}
+			total += n
+		} else {
+			total += entry.Size()
+		}
+	}
+	return total, nil
+}
+
+// mkdirAll creates the directory and the missing parents
+func mkdirAll(ctx context.Context, fs webdav.FileSystem, dirPath string) error {
+	if dirPath == "/" {
+		return nil
+	}
+	if fi, err := fs.Stat(ctx, dirPath); err == nil {
+		if !fi.IsDir() {
+			return os.ErrExist
+		}
+		return nil
+	}
+	if err := mkdirAll(ctx, fs, path.Dir(dirPath)); err != nil {
+		return err
+	}
+	return fs.Mkdir(ctx, dirPath, 0755|os.ModeDir)
+}
@@ -0,0 +1,147 @@
+package ftp
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"fmt"
+	"math/rand"
+	"net"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/net/webdav"
+)
+
+// the usage of the home directories is counted again on login after this long
+const quotaRecountInterval = 10 * time.Minute
+
+type User struct {
+	Name     string
+	Password string
+	// PublicKeys are the keys allowed to log in by SFTP, in the authorized_keys format
+	PublicKeys []string
+	Uid        uint32
+	Gid        uint32
+	// HomeDir is the directory on the filer the user is limited to, created on the first login
+	HomeDir string
+	// QuotaBytes limits the total size of the files under the home directory, 0 means no limit
+	QuotaBytes int64
+	ReadOnly   bool
+
+	authorizedKeys []ssh.PublicKey
+}
+
+type Option struct {
+	Users []*User
+	// TLSConfig enables FTPS by AUTH TLS, optional
+	TLSConfig *tls.Config
+	// TLSRequired rejects the logins before AUTH TLS
+	TLSRequired bool
+	// PublicIp is the address in the replies to PASV, for the servers behind NAT, optional
+	PublicIp string
+	// the range of the ports for the passive data connections, 0 for any port
+	PassivePortStart int
+	PassivePortEnd   int
+	// HostKey is the key of the SFTP server
+	HostKey ssh.Signer
+	// NewFileSystem creates the file system to access the files as the user
+	NewFileSystem func(user *User) webdav.FileSystem
+}
+
+type Server struct {
+	option  *Option
+	users   map[string]*User
+	homes   map[string]*homeFileSystem
+	homesMu sync.Mutex
+}
+
+func NewServer(option *Option) *Server {
+	s := &Server{
+		option: option,
+		users:  make(map[string]*User),
+		homes:  make(map[string]*homeFileSystem),
+	}
+	for _, user := range option.Users {
+		for _, line := range user.PublicKeys {
+			key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(line))
+			if err != nil {
+				glog.Errorf("ftp user %s public key %q: %v", user.Name, line, err)
+				continue
+			}
+			user.authorizedKeys = append(user.authorizedKeys, key)
+		}
+		s.users[user.Name] = user
+	}
+	return s
+}
+
+// Serve serves FTP, and FTPS if the TLSConfig is set
+func (s *Server) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go newSession(s, conn).serve()
+	}
+}
+
+func (s *Server) authenticate(name, password string) *User {
+	user, found := s.users[name]
+	if !found || user.Password == "" {
+		return nil
+	}
+	if subtle.ConstantTimeCompare([]byte(user.Password), []byte(password)) != 1 {
+		return nil
+	}
+	return user
+}
+
+// homeFileSystem returns the file system of the user, creating the home directory if missing
+func (s *Server) homeFileSystem(user *User) (*homeFileSystem, error) {
+	s.homesMu.Lock()
+	h, found := s.homes[user.Name]
+	if !found {
+		h = &homeFileSystem{
+			fs:       s.option.NewFileSystem(user),
+			home:     path.Clean("/" + user.HomeDir),
+			readOnly: user.ReadOnly,
+			quota:    &quota{limit: user.QuotaBytes},
+		}
+		s.homes[user.Name] = h
+	}
+	s.homesMu.Unlock()
+
+	ctx := context.Background()
+	if err := mkdirAll(ctx, h.fs, h.home); err != nil {
+		return nil, fmt.Errorf("home directory %s: %v", h.home, err)
+	}
+	if err := h.quota.recount(ctx, h.fs, h.home, quotaRecountInterval); err != nil {
+		return nil, fmt.Errorf("usage of %s: %v", h.home, err)
+	}
+	return h, nil
+}
+
+// listenPassive listens on a free port in the passive port range
+func (s *Server) listenPassive() (net.Listener, error) {
+	start, end := s.option.PassivePortStart, s.option.PassivePortEnd
+	if start <= 0 || end < start {
+		return net.Listen("tcp", ":0")
+	}
+	count := end - start + 1
+	first := rand.Intn(count)
+	var lastErr error
+	for i := 0; i < count; i++ {
+		port := start + (first+i)%count
+		listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+		if err == nil {
+			return listener, nil
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("no free passive port in %d-%d: %v", start, end, lastErr)
+}
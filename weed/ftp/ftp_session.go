@@ -0,0 +1,456 @@
+package ftp
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+)
+
+const (
+	maxCommandLength = 4096
+	idleTimeout      = 5 * time.Minute
+	maxLoginFailures = 3
+	// each write to the filer is one chunk, so the uploads are buffered into large chunks
+	uploadBufferSize = 4 * 1024 * 1024
+)
+
+// session is one FTP control connection
+type session struct {
+	server *Server
+	conn   net.Conn
+	reader *bufio.Reader
+	ctx    context.Context
+
+	isTLS       bool
+	protectData bool
+
+	userName      string
+	user          *User
+	fs            *homeFileSystem
+	loginFailures int
+
+	cwd        string
+	passive    net.Listener
+	activeAddr string
+	restOffset int64
+	renameFrom string
+}
+
+func newSession(server *Server, conn net.Conn) *session {
+	return &session{
+		server: server,
+		conn:   conn,
+		reader: bufio.NewReaderSize(conn, maxCommandLength),
+		ctx:    context.Background(),
+		cwd:    "/",
+	}
+}
+
+func (s *session) serve() {
+	defer func() {
+		s.closePassive()
+		s.conn.Close()
+	}()
+
+	s.reply(220, "SeaweedFS FTP server ready.")
+	for {
+		s.conn.SetReadDeadline(time.Now().Add(idleTimeout))
+		line, isPrefix, err := s.reader.ReadLine()
+		if err != nil {
+			if err != io.EOF {
+				glog.V(1).Infof("ftp read from %s: %v", s.conn.RemoteAddr(), err)
+			}
+			return
+		}
+		if isPrefix {
+			s.reply(500, "Command too long.")
+			return
+		}
+
+		command, arg := string(line), ""
+		if i := strings.IndexByte(command, ' '); i >= 0 {
+			command, arg = command[:i], command[i+1:]
+		}
+		command = strings.ToUpper(command)
+		if command == "PASS" {
+			glog.V(2).Infof("ftp %s: PASS ***", s.conn.RemoteAddr())
+		} else {
+			glog.V(2).Infof("ftp %s: %s %s", s.conn.RemoteAddr(), command, arg)
+		}
+
+		if !s.handle(command, arg) {
+			return
+		}
+	}
+}
+
+func (s *session) reply(code int, format string, args ...interface{}) {
+	fmt.Fprintf(s.conn, "%d %s\r\n", code, fmt.Sprintf(format, args...))
+}
+
+// replyLines sends a multi-line reply
+func (s *session) replyLines(code int, first string, lines []string, last string) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d-%s\r\n", code, first)
+	for _, line := range lines {
+		fmt.Fprintf(&b, " %s\r\n", line)
+	}
+	fmt.Fprintf(&b, "%d %s\r\n", code, last)
+	io.WriteString(s.conn, b.String())
+}
+
+// replyError maps the file system errors to the FTP replies
+func (s *session) replyError(err error) {
+	switch {
+	case err == errQuotaExceeded:
+		s.reply(552, "Exceeded storage allocation.")
+	case os.IsPermission(err):
+		s.reply(550, "Permission denied.")
+	case os.IsNotExist(err):
+		s.reply(550, "No such file or directory.")
+	case os.IsExist(err):
+		s.reply(550, "File exists.")
+	default:
+		glog.V(1).Infof("ftp %s: %v", s.userName, err)
+		s.reply(550, "Requested action not taken.")
+	}
+}
+
+// handle runs one command, returning false to close the connection
+func (s *session) handle(command, arg string) bool {
+	switch command {
+	case "USER":
+		if s.server.option.TLSRequired && !s.isTLS {
+			s.reply(530, "TLS is required, use AUTH TLS.")
+			return true
+		}
+		s.userName, s.user, s.fs = arg, nil, nil
+		s.reply(331, "Password required for %s.", arg)
+		return true
+	case "PASS":
+		return s.handlePass(arg)
+	case "AUTH":
+		return s.handleAuth(arg)
+	case "PBSZ":
+		if !s.isTLS {
+			s.reply(503, "PBSZ requires AUTH TLS.")
+		} else {
+			s.reply(200, "PBSZ=0")
+		}
+		return true
+	case "PROT":
+		s.handleProt(arg)
+		return true
+	case "FEAT":
+		features := []string{"EPSV", "MDTM", "MLST type*;size*;modify*;", "PASV", "REST STREAM", "SIZE", "UTF8"}
+		if s.server.option.TLSConfig != nil {
+			features = append(features, "AUTH TLS", "PBSZ", "PROT")
+		}
+		s.replyLines(211, "Features:", features, "End")
+		return true
+	case "SYST":
+		s.reply(215, "UNIX Type: L8")
+		return true
+	case "OPTS":
+		if strings.EqualFold(arg, "UTF8 ON") {
+			s.reply(200, "UTF8 is always on.")
+		} else {
+			s.reply(501, "Option not understood.")
+		}
+		return true
+	case "NOOP":
+		s.reply(200, "OK.")
+		return true
+	case "QUIT":
+		s.reply(221, "Goodbye.")
+		return false
+	}
+
+	if s.user == nil {
+		s.reply(530, "Please login with USER and PASS.")
+		return true
+	}
+
+	// RNTO only follows RNFR immediately, while REST is kept until the next transfer
+	if command != "RNTO" {
+		s.renameFrom = ""
+	}
+
+	switch command {
+	case "PWD", "XPWD":
+		s.reply(257, "%s is the current directory.", quotePath(s.cwd))
+	case "CWD", "XCWD":
+		s.handleCwd(arg)
+	case "CDUP", "XCUP":
+		s.handleCwd("..")
+	case "TYPE":
+		// the ASCII type is served as binary, as most servers do for the modern clients
+		switch strings.ToUpper(arg) {
+		case "A", "A N", "I", "L 8":
+			s.reply(200, "Type set to %s.", arg)
+		default:
+			s.reply(504, "Type %s not supported.", arg)
+		}
+	case "MODE":
+		s.replyIf(strings.EqualFold(arg, "S"), 200, "Mode set to S.", 504, "Only the stream mode is supported.")
+	case "STRU":
+		s.replyIf(strings.EqualFold(arg, "F"), 200, "Structure set to F.", 504, "Only the file structure is supported.")
+	case "ALLO":
+		s.reply(202, "No storage allocation necessary.")
+	case "PASV":
+		s.handlePasv()
+	case "EPSV":
+		s.handleEpsv(arg)
+	case "PORT":
+		s.handlePort(arg)
+	case "EPRT":
+		s.handleEprt(arg)
+	case "LIST", "NLST", "MLSD":
+		s.handleList(command, arg)
+	case "MLST":
+		s.handleMlst(arg)
+	case "RETR":
+		s.handleRetr(arg)
+	case "STOR", "APPE":
+		s.handleStor(arg, command == "APPE")
+	case "REST":
+		offset, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil || offset < 0 {
+			s.reply(501, "Invalid offset.")
+		} else {
+			s.restOffset = offset
+			s.reply(350, "Restarting at %d.", offset)
+		}
+	case "SIZE":
+		if fi, err := s.fs.Stat(s.ctx, s.path(arg)); err != nil {
+			s.replyError(err)
+		} else if fi.IsDir() {
+			s.reply(550, "%s is a directory.", arg)
+		} else {
+			s.reply(213, "%d", fi.Size())
+		}
+	case "MDTM":
+		if fi, err := s.fs.Stat(s.ctx, s.path(arg)); err != nil {
+			s.replyError(err)
+		} else {
+			s.reply(213, "%s", fi.ModTime().UTC().Format("20060102150405"))
+		}
+	case "DELE":
+		s.handleDele(arg)
+	case "MKD", "XMKD":
+		if err := s.fs.Mkdir(s.ctx, s.path(arg), 0755|os.ModeDir); err != nil {
+			s.replyError(err)
+		} else {
+			s.reply(257, "%s created.", quotePath(s.path(arg)))
+		}
+	case "RMD", "XRMD":
+		s.handleRmd(arg)
+	case "RNFR":
+		if _, err := s.fs.Stat(s.ctx, s.path(arg)); err != nil {
+			s.replyError(err)
+		} else {
+			s.renameFrom = s.path(arg)
+			s.reply(350, "Ready for RNTO.")
+		}
+	case "RNTO":
+		s.handleRnto(arg)
+	case "ABOR":
+		// the transfers finish before the next command is read, so there is nothing to abort
+		s.reply(226, "No transfer to abort.")
+	case "STAT":
+		if arg != "" {
+			s.reply(504, "STAT of the files is not supported, use LIST.")
+		} else {
+			s.reply(211, "Logged in as %s.", s.user.Name)
+		}
+	default:
+		s.reply(502, "Command %s not implemented.", command)
+	}
+	return true
+}
+
+func (s *session) replyIf(ok bool, okCode int, okMessage string, failCode int, failMessage string) {
+	if ok {
+		s.reply(okCode, "%s", okMessage)
+	} else {
+		s.reply(failCode, "%s", failMessage)
+	}
+}
+
+func (s *session) handlePass(password string) bool {
+	if s.userName == "" {
+		s.reply(503, "Login with USER first.")
+		return true
+	}
+	user := s.server.authenticate(s.userName, password)
+	if user == nil {
+		s.loginFailures++
+		glog.V(0).Infof("ftp login of %s from %s failed", s.userName, s.conn.RemoteAddr())
+		time.Sleep(time.Second)
+		s.reply(530, "Login incorrect.")
+		return s.loginFailures < maxLoginFailures
+	}
+	fs, err := s.server.homeFileSystem(user)
+	if err != nil {
+		glog.Errorf("ftp login of %s: %v", user.Name, err)
+		s.reply(421, "Home directory not available.")
+		return false
+	}
+	s.user, s.fs, s.cwd = user, fs, "/"
+	glog.V(1).Infof("ftp login of %s from %s", user.Name, s.conn.RemoteAddr())
+	s.reply(230, "Login successful.")
+	return true
+}
+
+func (s *session) handleAuth(arg string) bool {
+	mechanism := strings.ToUpper(arg)
+	if s.server.option.TLSConfig == nil || (mechanism != "TLS" && mechanism != "TLS-C" && mechanism != "SSL") {
+		s.reply(504, "AUTH %s not supported.", arg)
+		return true
+	}
+	if s.isTLS {
+		s.reply(503, "Already using TLS.")
+		return true
+	}
+	s.reply(234, "AUTH %s successful.", mechanism)
+
+	tlsConn := tls.Server(s.conn, s.server.option.TLSConfig)
+	tlsConn.SetDeadline(time.Now().Add(idleTimeout))
+	if err := tlsConn.Handshake(); err != nil {
+		glog.V(1).Infof("ftp tls handshake with %s: %v", s.conn.RemoteAddr(), err)
+		return false
+	}
+	tlsConn.SetDeadline(time.Time{})
+	s.conn, s.reader, s.isTLS = tlsConn, bufio.NewReaderSize(tlsConn, maxCommandLength), true
+	// a new login is necessary after the security changes
+	s.userName, s.user, s.fs = "", nil, nil
+	return true
+}
+
+func (s *session) handleProt(arg string) {
+	if !s.isTLS {
+		s.reply(503, "PROT requires AUTH TLS.")
+		return
+	}
+	switch strings.ToUpper(arg) {
+	case "P":
+		s.protectData = true
+		s.reply(200, "Protection level set to P.")
+	case "C":
+		if s.server.option.TLSRequired {
+			s.reply(534, "The data connections must be protected.")
+			return
+		}
+		s.protectData = false
+		s.reply(200, "Protection level set to C.")
+	default:
+		s.reply(504, "Protection level %s not supported.", arg)
+	}
+}
+
+// path resolves the argument against the current directory, relative to the home directory
+func (s *session) path(arg string) string {
+	if strings.HasPrefix(arg, "/") {
+		return path.Clean(arg)
+	}
+	return path.Join(s.cwd, arg)
+}
+
+func quotePath(p string) string {
+	return `"` + strings.Replace(p, `"`, `""`, -1) + `"`
+}
+
+func (s *session) handleCwd(arg string) {
+	dir := s.path(arg)
+	fi, err := s.fs.Stat(s.ctx, dir)
+	if err != nil {
+		s.replyError(err)
+		return
+	}
+	if !fi.IsDir() {
+		s.reply(550, "%s is not a directory.", arg)
+		return
+	}
+	s.cwd = dir
+	s.reply(250, "Directory changed to %s.", quotePath(dir))
+}
+
+func (s *session) handleDele(arg string) {
+	fi, err := s.fs.Stat(s.ctx, s.path(arg))
+	if err != nil {
+		s.replyError(err)
+		return
+	}
+	if fi.IsDir() {
+		s.reply(550, "%s is a directory.", arg)
+		return
+	}
+	if err = s.fs.RemoveAll(s.ctx, s.path(arg)); err != nil {
+		s.replyError(err)
+		return
+	}
+	s.reply(250, "File deleted.")
+}
+
+func (s *session) handleRmd(arg string) {
+	dir := s.path(arg)
+	fi, err := s.fs.Stat(s.ctx, dir)
+	if err != nil {
+		s.replyError(err)
+		return
+	}
+	if !fi.IsDir() {
+		s.reply(550, "%s is not a directory.", arg)
+		return
+	}
+	entries, err := s.fs.readDir(s.ctx, dir)
+	if err != nil {
+		s.replyError(err)
+		return
+	}
+	if len(entries) > 0 {
+		s.reply(550, "Directory not empty.")
+		return
+	}
+	if err = s.fs.RemoveAll(s.ctx, dir); err != nil {
+		s.replyError(err)
+		return
+	}
+	if s.cwd == dir || strings.HasPrefix(s.cwd, dir+"/") {
+		s.cwd = path.Dir(dir)
+	}
+	s.reply(250, "Directory removed.")
+}
+
+func (s *session) handleRnto(arg string) {
+	from := s.renameFrom
+	s.renameFrom = ""
+	if from == "" {
+		s.reply(503, "RNFR required first.")
+		return
+	}
+	to := s.path(arg)
+	if to == from {
+		s.reply(250, "Renamed.")
+		return
+	}
+	if strings.HasPrefix(to, from+"/") {
+		s.reply(550, "Can not move a directory into itself.")
+		return
+	}
+	if err := s.fs.Rename(s.ctx, from, to); err != nil {
+		s.replyError(err)
+		return
+	}
+	s.reply(250, "Renamed.")
+}
@@ -0,0 +1,323 @@
+package ftp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/textproto"
+	"os"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/net/webdav"
+)
+
+func startTestServer(t *testing.T, fs webdav.FileSystem) (server *Server, ftpAddress, sftpAddress string, closeFn func()) {
+	hostKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(hostKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server = NewServer(&Option{
+		Users: []*User{
+			{Name: "alice", Password: "secret", HomeDir: "/home/alice", QuotaBytes: 16},
+			{Name: "bob", Password: "secret", HomeDir: "/home/bob", ReadOnly: true},
+		},
+		HostKey: signer,
+		NewFileSystem: func(user *User) webdav.FileSystem {
+			return fs
+		},
+	})
+	ftpListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sftpListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(ftpListener)
+	go server.ServeSftp(sftpListener)
+	return server, ftpListener.Addr().String(), sftpListener.Addr().String(), func() {
+		ftpListener.Close()
+		sftpListener.Close()
+	}
+}
+
+type testFtpClient struct {
+	t *testing.T
+	*textproto.Conn
+}
+
+func (c *testFtpClient) cmd(expectCode int, format string, args ...interface{}) string {
+	id, err := c.Cmd(format, args...)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	c.StartResponse(id)
+	defer c.EndResponse(id)
+	code, message, err := c.ReadResponse(0)
+	if err != nil && code == 0 {
+		c.t.Fatal(err)
+	}
+	if code != expectCode {
+		c.t.Fatalf("%s: %d %s, expected %d", fmt.Sprintf(format, args...), code, message, expectCode)
+	}
+	return message
+}
+
+// transfer runs the command over a passive data connection, sending the data or returning the received data
+func (c *testFtpClient) transfer(expectCode int, command string, data string) string {
+	message := c.cmd(229, "EPSV")
+	port := strings.Trim(message[strings.Index(message, "(")+1:strings.Index(message, ")")], "|")
+	dataConn, err := net.Dial("tcp", "127.0.0.1:"+port)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	c.cmd(150, "%s", command)
+	var received []byte
+	if data != "" {
+		io.WriteString(dataConn, data)
+		dataConn.Close()
+	} else {
+		received, _ = ioutil.ReadAll(dataConn)
+		dataConn.Close()
+	}
+	code, message, err := c.ReadResponse(0)
+	if code != expectCode {
+		c.t.Fatalf("%s: %d %s %v, expected %d", command, code, message, err, expectCode)
+	}
+	return string(received)
+}
+
+func TestFtp(t *testing.T) {
+	fs := webdav.NewMemFS()
+	_, ftpAddress, _, closeFn := startTestServer(t, fs)
+	defer closeFn()
+
+	conn, err := textproto.Dial("tcp", ftpAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	c := &testFtpClient{t: t, Conn: conn}
+	if _, _, err := c.ReadResponse(220); err != nil {
+		t.Fatal(err)
+	}
+
+	c.cmd(530, "PWD")
+	c.cmd(331, "USER alice")
+	c.cmd(530, "PASS wrong")
+	c.cmd(331, "USER alice")
+	c.cmd(230, "PASS secret")
+	c.cmd(257, "MKD docs")
+	c.cmd(250, "CWD docs")
+	c.transfer(226, "STOR a.txt", "hello world")
+	if message := c.cmd(213, "SIZE a.txt"); message != "11" {
+		t.Errorf("size %s", message)
+	}
+	if data := c.transfer(226, "RETR /docs/a.txt", ""); data != "hello world" {
+		t.Errorf("retrieved %q", data)
+	}
+	c.cmd(350, "REST 6")
+	if data := c.transfer(226, "RETR a.txt", ""); data != "world" {
+		t.Errorf("retrieved %q from offset 6", data)
+	}
+
+	// 11 + 8 bytes is over the quota of 16 bytes
+	c.transfer(552, "APPE a.txt", "12345678")
+	c.transfer(226, "APPE a.txt", "12345")
+	if listing := c.transfer(226, "NLST", ""); listing != "a.txt\r\n" {
+		t.Errorf("listed %q", listing)
+	}
+	if listing := c.transfer(226, "MLSD /", ""); !strings.HasPrefix(listing, "type=dir;") || !strings.HasSuffix(listing, " docs\r\n") {
+		t.Errorf("listed %q", listing)
+	}
+
+	// the user can not go above the home directory
+	c.cmd(250, "CWD ../../..")
+	if message := c.cmd(257, "PWD"); !strings.HasPrefix(message, `"/"`) {
+		t.Errorf("pwd %s", message)
+	}
+	if _, err := fs.Stat(context.Background(), "/home/alice/docs/a.txt"); err != nil {
+		t.Errorf("stat in the home directory: %v", err)
+	}
+
+	// deleting frees the quota
+	c.cmd(350, "RNFR docs/a.txt")
+	c.cmd(250, "RNTO b.txt")
+	c.cmd(550, "RMD docs/b.txt")
+	c.cmd(250, "DELE b.txt")
+	c.transfer(226, "STOR c.txt", "0123456789abcdef")
+	c.cmd(250, "RMD docs")
+	c.cmd(221, "QUIT")
+
+	// the read only user
+	conn, err = textproto.Dial("tcp", ftpAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	c = &testFtpClient{t: t, Conn: conn}
+	c.ReadResponse(220)
+	c.cmd(331, "USER bob")
+	c.cmd(230, "PASS secret")
+	c.cmd(550, "MKD docs")
+	c.cmd(550, "STOR a.txt")
+}
+
+type testSftpClient struct {
+	t      *testing.T
+	stdin  io.Writer
+	stdout io.Reader
+	id     uint32
+}
+
+func (c *testSftpClient) request(packetType byte, build func(e *sftpEncoder)) (byte, *sftpDecoder) {
+	c.id++
+	e := newSftpPacket(packetType)
+	e.uint32(c.id)
+	if build != nil {
+		build(e)
+	}
+	if _, err := c.stdin.Write(e.bytes()); err != nil {
+		c.t.Fatal(err)
+	}
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(c.stdout, header); err != nil {
+		c.t.Fatal(err)
+	}
+	packet := make([]byte, binary.BigEndian.Uint32(header))
+	if _, err := io.ReadFull(c.stdout, packet); err != nil {
+		c.t.Fatal(err)
+	}
+	d := &sftpDecoder{b: packet[1:]}
+	if id := d.uint32(); id != c.id {
+		c.t.Fatalf("reply id %d, expected %d", id, c.id)
+	}
+	return packet[0], d
+}
+
+func (c *testSftpClient) status(packetType byte, build func(e *sftpEncoder)) uint32 {
+	replyType, d := c.request(packetType, build)
+	if replyType != sshFxpStatus {
+		c.t.Fatalf("reply type %d", replyType)
+	}
+	return d.uint32()
+}
+
+func (c *testSftpClient) handle(packetType byte, build func(e *sftpEncoder)) string {
+	replyType, d := c.request(packetType, build)
+	if replyType != sshFxpHandle {
+		c.t.Fatalf("reply type %d, status %d", replyType, d.uint32())
+	}
+	return d.string()
+}
+
+func TestSftp(t *testing.T) {
+	fs := webdav.NewMemFS()
+	_, _, sftpAddress, closeFn := startTestServer(t, fs)
+	defer closeFn()
+
+	config := &ssh.ClientConfig{
+		User:            "alice",
+		Auth:            []ssh.AuthMethod{ssh.Password("secret")},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	}
+	client, err := ssh.Dial("tcp", sftpAddress, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	session, err := client.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+	stdin, _ := session.StdinPipe()
+	stdout, _ := session.StdoutPipe()
+	if err = session.RequestSubsystem("sftp"); err != nil {
+		t.Fatal(err)
+	}
+	c := &testSftpClient{t: t, stdin: stdin, stdout: stdout}
+
+	init := newSftpPacket(sshFxpInit)
+	init.uint32(sftpVersion)
+	stdin.Write(init.bytes())
+	header := make([]byte, 9)
+	if _, err = io.ReadFull(stdout, header); err != nil || header[4] != sshFxpVersion {
+		t.Fatalf("version %v %v", header, err)
+	}
+
+	if status := c.status(sshFxpMkdir, func(e *sftpEncoder) { e.string("/../up"); e.uint32(0) }); status != sshFxOk {
+		t.Fatalf("mkdir status %d", status)
+	}
+	handle := c.handle(sshFxpOpen, func(e *sftpEncoder) {
+		e.string("up/a.txt")
+		e.uint32(sshFxfWrite | sshFxfCreat | sshFxfTrunc)
+		e.uint32(0)
+	})
+	// the out of order write flushes the pending writes
+	for _, part := range []struct {
+		offset uint64
+		data   string
+	}{{0, "hello"}, {5, " "}, {7, "orld"}, {6, "w"}} {
+		status := c.status(sshFxpWrite, func(e *sftpEncoder) { e.string(handle); e.uint64(part.offset); e.string(part.data) })
+		if status != sshFxOk {
+			t.Fatalf("write status %d", status)
+		}
+	}
+	// past the quota, reported when the writes are flushed on close
+	c.status(sshFxpWrite, func(e *sftpEncoder) { e.string(handle); e.uint64(11); e.string("1234567890") })
+	if status := c.status(sshFxpClose, func(e *sftpEncoder) { e.string(handle) }); status != sshFxFailure {
+		t.Errorf("close over the quota: status %d", status)
+	}
+
+	if f, err := fs.OpenFile(context.Background(), "/home/alice/up/a.txt", os.O_RDONLY, 0); err != nil {
+		t.Fatal(err)
+	} else {
+		data, _ := ioutil.ReadAll(f)
+		f.Close()
+		if string(data) != "hello world" {
+			t.Errorf("written %q", data)
+		}
+	}
+
+	replyType, d := c.request(sshFxpStat, func(e *sftpEncoder) { e.string("/up/a.txt") })
+	if replyType != sshFxpAttrs {
+		t.Fatalf("stat reply type %d", replyType)
+	}
+	if attrs := d.attrs(); attrs.size != 11 {
+		t.Errorf("stat size %d", attrs.size)
+	}
+
+	handle = c.handle(sshFxpOpendir, func(e *sftpEncoder) { e.string("/up") })
+	replyType, d = c.request(sshFxpReaddir, func(e *sftpEncoder) { e.string(handle) })
+	if replyType != sshFxpName || d.uint32() != 1 || d.string() != "a.txt" {
+		t.Errorf("readdir reply type %d", replyType)
+	}
+	if status := c.status(sshFxpReaddir, func(e *sftpEncoder) { e.string(handle) }); status != sshFxEOF {
+		t.Errorf("readdir at the end: status %d", status)
+	}
+
+	if status := c.status(sshFxpRmdir, func(e *sftpEncoder) { e.string("/up") }); status != sshFxFailure {
+		t.Errorf("rmdir of the non-empty directory: status %d", status)
+	}
+	if status := c.status(sshFxpRemove, func(e *sftpEncoder) { e.string("/up/a.txt") }); status != sshFxOk {
+		t.Errorf("remove status %d", status)
+	}
+
+	config.Auth = []ssh.AuthMethod{ssh.Password("wrong")}
+	if _, err = ssh.Dial("tcp", sftpAddress, config); err == nil {
+		t.Errorf("logged in with the wrong password")
+	}
+}
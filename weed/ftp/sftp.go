@@ -0,0 +1,512 @@
+package ftp
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/net/webdav"
+)
+
+const sshHandshakeTimeout = 30 * time.Second
+
+// ServeSftp serves SFTP over SSH, with the users logging in by the passwords or the public keys
+func (s *Server) ServeSftp(listener net.Listener) error {
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if s.authenticate(c.User(), string(password)) == nil {
+				time.Sleep(time.Second)
+				return nil, fmt.Errorf("password of %s rejected", c.User())
+			}
+			return nil, nil
+		},
+		PublicKeyCallback: func(c ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if user, found := s.users[c.User()]; found {
+				for _, authorized := range user.authorizedKeys {
+					if bytes.Equal(authorized.Marshal(), key.Marshal()) {
+						return nil, nil
+					}
+				}
+			}
+			return nil, fmt.Errorf("public key of %s rejected", c.User())
+		},
+	}
+	config.AddHostKey(s.option.HostKey)
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go s.serveSsh(conn, config)
+	}
+}
+
+func (s *Server) serveSsh(conn net.Conn, config *ssh.ServerConfig) {
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(sshHandshakeTimeout))
+	sshConn, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		glog.V(1).Infof("sftp handshake with %s: %v", conn.RemoteAddr(), err)
+		return
+	}
+	conn.SetDeadline(time.Time{})
+	defer sshConn.Close()
+	go ssh.DiscardRequests(requests)
+
+	user := s.users[sshConn.User()]
+	fs, err := s.homeFileSystem(user)
+	if err != nil {
+		glog.Errorf("sftp login of %s: %v", user.Name, err)
+		return
+	}
+	glog.V(1).Infof("sftp login of %s from %s", user.Name, conn.RemoteAddr())
+
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "only the session channels are supported")
+			continue
+		}
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			glog.V(1).Infof("sftp channel of %s: %v", user.Name, err)
+			continue
+		}
+		go serveSftpChannel(user, fs, channel, channelRequests)
+	}
+}
+
+// serveSftpChannel starts the sftp subsystem, the only thing allowed on the channel
+func serveSftpChannel(user *User, fs *homeFileSystem, channel ssh.Channel, requests <-chan *ssh.Request) {
+	started := false
+	for req := range requests {
+		ok := !started && req.Type == "subsystem" && len(req.Payload) > 4 && string(req.Payload[4:]) == "sftp"
+		req.Reply(ok, nil)
+		if ok {
+			started = true
+			go func() {
+				newSftpSession(user, fs, channel).serve()
+				channel.Close()
+			}()
+		}
+	}
+}
+
+// sftpHandle is an opened file or directory
+type sftpHandle struct {
+	path    string
+	file    webdav.File
+	entries []os.FileInfo
+	listed  bool
+
+	// the sequential writes are combined into large chunks
+	pending       []byte
+	pendingOffset int64
+}
+
+func (h *sftpHandle) flush() error {
+	if len(h.pending) == 0 {
+		return nil
+	}
+	data := h.pending
+	h.pending = nil
+	if _, err := h.file.Seek(h.pendingOffset, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := h.file.Write(data)
+	return err
+}
+
+func (h *sftpHandle) writeAt(data []byte, offset int64) error {
+	if len(h.pending) > 0 && offset != h.pendingOffset+int64(len(h.pending)) {
+		if err := h.flush(); err != nil {
+			return err
+		}
+	}
+	if len(h.pending) == 0 {
+		h.pendingOffset = offset
+	}
+	h.pending = append(h.pending, data...)
+	if len(h.pending) >= uploadBufferSize {
+		return h.flush()
+	}
+	return nil
+}
+
+func (h *sftpHandle) close() error {
+	err := h.flush()
+	if h.file != nil {
+		if closeErr := h.file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+type sftpSession struct {
+	user    *User
+	fs      *homeFileSystem
+	rw      io.ReadWriter
+	ctx     context.Context
+	handles map[string]*sftpHandle
+	nextId  uint64
+}
+
+func newSftpSession(user *User, fs *homeFileSystem, rw io.ReadWriter) *sftpSession {
+	return &sftpSession{
+		user:    user,
+		fs:      fs,
+		rw:      rw,
+		ctx:     context.Background(),
+		handles: make(map[string]*sftpHandle),
+	}
+}
+
+// serve handles the requests one by one, so the writes to a file arrive in order
+func (s *sftpSession) serve() {
+	defer func() {
+		for _, h := range s.handles {
+			if err := h.close(); err != nil {
+				glog.V(0).Infof("sftp %s close %s: %v", s.user.Name, h.path, err)
+			}
+		}
+	}()
+
+	header := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(s.rw, header); err != nil {
+			if err != io.EOF {
+				glog.V(1).Infof("sftp %s read: %v", s.user.Name, err)
+			}
+			return
+		}
+		length := binary.BigEndian.Uint32(header)
+		if length < 1 || length > maxSftpPacket {
+			glog.V(0).Infof("sftp %s packet of %d bytes", s.user.Name, length)
+			return
+		}
+		packet := make([]byte, length)
+		if _, err := io.ReadFull(s.rw, packet); err != nil {
+			glog.V(1).Infof("sftp %s read: %v", s.user.Name, err)
+			return
+		}
+		reply := s.handle(packet[0], &sftpDecoder{b: packet[1:]})
+		if _, err := s.rw.Write(reply); err != nil {
+			glog.V(1).Infof("sftp %s write: %v", s.user.Name, err)
+			return
+		}
+	}
+}
+
+func (s *sftpSession) handle(packetType byte, d *sftpDecoder) []byte {
+	if packetType == sshFxpInit {
+		e := newSftpPacket(sshFxpVersion)
+		e.uint32(sftpVersion)
+		return e.bytes()
+	}
+
+	id := d.uint32()
+	if d.err != nil {
+		return statusReply(id, sshFxBadMessage, "bad message")
+	}
+	var reply []byte
+	switch packetType {
+	case sshFxpOpen:
+		reply = s.handleOpen(id, d)
+	case sshFxpClose:
+		handle := d.string()
+		h, found := s.handles[handle]
+		if !found {
+			return statusReply(id, sshFxFailure, "invalid handle")
+		}
+		delete(s.handles, handle)
+		reply = s.statusOf(id, h.close())
+	case sshFxpRead:
+		reply = s.handleRead(id, d)
+	case sshFxpWrite:
+		h, offset, data := s.handles[d.string()], d.uint64(), d.bytes()
+		if h == nil || h.file == nil {
+			return statusReply(id, sshFxFailure, "invalid handle")
+		}
+		reply = s.statusOf(id, h.writeAt(data, int64(offset)))
+	case sshFxpStat, sshFxpLstat:
+		fi, err := s.fs.Stat(s.ctx, cleanSftpPath(d.string()))
+		reply = s.attrsReply(id, fi, err)
+	case sshFxpFstat:
+		h := s.handles[d.string()]
+		if h == nil {
+			return statusReply(id, sshFxFailure, "invalid handle")
+		}
+		err := h.flush()
+		var fi os.FileInfo
+		if err == nil {
+			fi, err = s.fs.Stat(s.ctx, h.path)
+		}
+		reply = s.attrsReply(id, fi, err)
+	case sshFxpSetstat:
+		p := cleanSftpPath(d.string())
+		reply = s.statusOf(id, s.setSize(p, d.attrs()))
+	case sshFxpFsetstat:
+		h := s.handles[d.string()]
+		if h == nil {
+			return statusReply(id, sshFxFailure, "invalid handle")
+		}
+		attrs := d.attrs()
+		err := h.flush()
+		if err == nil {
+			err = s.setSize(h.path, attrs)
+		}
+		reply = s.statusOf(id, err)
+	case sshFxpOpendir:
+		reply = s.handleOpendir(id, d)
+	case sshFxpReaddir:
+		reply = s.handleReaddir(id, d)
+	case sshFxpRemove:
+		p := cleanSftpPath(d.string())
+		fi, err := s.fs.Stat(s.ctx, p)
+		if err == nil && fi.IsDir() {
+			return statusReply(id, sshFxFailure, "is a directory")
+		}
+		if err == nil {
+			err = s.fs.RemoveAll(s.ctx, p)
+		}
+		reply = s.statusOf(id, err)
+	case sshFxpMkdir:
+		reply = s.statusOf(id, s.fs.Mkdir(s.ctx, cleanSftpPath(d.string()), 0755|os.ModeDir))
+	case sshFxpRmdir:
+		reply = s.handleRmdir(id, cleanSftpPath(d.string()))
+	case sshFxpRealpath:
+		p := cleanSftpPath(d.string())
+		e := newSftpPacket(sshFxpName)
+		e.uint32(id)
+		e.uint32(1)
+		e.string(p)
+		e.string(p)
+		e.uint32(0)
+		reply = e.bytes()
+	case sshFxpRename:
+		from, to := cleanSftpPath(d.string()), cleanSftpPath(d.string())
+		var err error
+		if _, statErr := s.fs.Stat(s.ctx, to); statErr == nil {
+			// SFTP v3 does not overwrite on rename
+			err = os.ErrExist
+		} else {
+			err = s.fs.Rename(s.ctx, from, to)
+		}
+		reply = s.statusOf(id, err)
+	case sshFxpReadlink, sshFxpSymlink:
+		return statusReply(id, sshFxOpUnsupported, "links are not supported")
+	default:
+		return statusReply(id, sshFxOpUnsupported, "operation not supported")
+	}
+	if d.err != nil {
+		return statusReply(id, sshFxBadMessage, "bad message")
+	}
+	return reply
+}
+
+// cleanSftpPath resolves the path against the home directory, the working directory of SFTP
+func cleanSftpPath(p string) string {
+	return path.Clean("/" + p)
+}
+
+func statusReply(id uint32, code uint32, message string) []byte {
+	e := newSftpPacket(sshFxpStatus)
+	e.uint32(id)
+	e.uint32(code)
+	e.string(message)
+	e.string("")
+	return e.bytes()
+}
+
+func (s *sftpSession) statusOf(id uint32, err error) []byte {
+	switch {
+	case err == nil:
+		return statusReply(id, sshFxOk, "")
+	case err == errQuotaExceeded:
+		glog.V(0).Infof("sftp %s: quota of %d bytes exceeded", s.user.Name, s.user.QuotaBytes)
+		return statusReply(id, sshFxFailure, "quota exceeded")
+	case os.IsNotExist(err):
+		return statusReply(id, sshFxNoSuchFile, "no such file")
+	case os.IsPermission(err):
+		return statusReply(id, sshFxPermissionDenied, "permission denied")
+	case os.IsExist(err):
+		return statusReply(id, sshFxFailure, "file exists")
+	}
+	glog.V(1).Infof("sftp %s: %v", s.user.Name, err)
+	return statusReply(id, sshFxFailure, "failure")
+}
+
+func (s *sftpSession) attrsReply(id uint32, fi os.FileInfo, err error) []byte {
+	if err != nil {
+		return s.statusOf(id, err)
+	}
+	e := newSftpPacket(sshFxpAttrs)
+	e.uint32(id)
+	e.attrs(fi, s.user.Uid, s.user.Gid)
+	return e.bytes()
+}
+
+func (s *sftpSession) addHandle(h *sftpHandle, id uint32) []byte {
+	s.nextId++
+	handle := strconv.FormatUint(s.nextId, 16)
+	s.handles[handle] = h
+	e := newSftpPacket(sshFxpHandle)
+	e.uint32(id)
+	e.string(handle)
+	return e.bytes()
+}
+
+func (s *sftpSession) handleOpen(id uint32, d *sftpDecoder) []byte {
+	p, pflags := cleanSftpPath(d.string()), d.uint32()
+	d.attrs()
+	if d.err != nil {
+		return statusReply(id, sshFxBadMessage, "bad message")
+	}
+
+	flag := os.O_RDONLY
+	switch {
+	case pflags&sshFxfRead != 0 && pflags&sshFxfWrite != 0:
+		flag = os.O_RDWR
+	case pflags&sshFxfWrite != 0:
+		flag = os.O_WRONLY
+	}
+	if pflags&sshFxfAppend != 0 {
+		flag |= os.O_APPEND
+	}
+	if pflags&sshFxfCreat != 0 {
+		flag |= os.O_CREATE
+	}
+	if pflags&sshFxfTrunc != 0 {
+		flag |= os.O_TRUNC
+	}
+	if pflags&sshFxfExcl != 0 {
+		flag |= os.O_EXCL
+	}
+
+	if fi, err := s.fs.Stat(s.ctx, p); err == nil && fi.IsDir() {
+		return statusReply(id, sshFxFailure, "is a directory")
+	}
+	f, err := s.fs.OpenFile(s.ctx, p, flag, 0644)
+	if err != nil {
+		return s.statusOf(id, err)
+	}
+	return s.addHandle(&sftpHandle{path: p, file: f}, id)
+}
+
+func (s *sftpSession) handleRead(id uint32, d *sftpDecoder) []byte {
+	h, offset, length := s.handles[d.string()], d.uint64(), d.uint32()
+	if h == nil || h.file == nil {
+		return statusReply(id, sshFxFailure, "invalid handle")
+	}
+	if err := h.flush(); err != nil {
+		return s.statusOf(id, err)
+	}
+	if length > maxSftpReadBytes {
+		length = maxSftpReadBytes
+	}
+	if _, err := h.file.Seek(int64(offset), io.SeekStart); err != nil {
+		return s.statusOf(id, err)
+	}
+	data := make([]byte, length)
+	n, err := io.ReadFull(h.file, data)
+	if n == 0 && (err == io.EOF || err == io.ErrUnexpectedEOF) {
+		return statusReply(id, sshFxEOF, "end of file")
+	}
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return s.statusOf(id, err)
+	}
+	e := newSftpPacket(sshFxpData)
+	e.uint32(id)
+	e.string(string(data[:n]))
+	return e.bytes()
+}
+
+func (s *sftpSession) handleOpendir(id uint32, d *sftpDecoder) []byte {
+	p := cleanSftpPath(d.string())
+	fi, err := s.fs.Stat(s.ctx, p)
+	if err != nil {
+		return s.statusOf(id, err)
+	}
+	if !fi.IsDir() {
+		return statusReply(id, sshFxFailure, "not a directory")
+	}
+	return s.addHandle(&sftpHandle{path: p}, id)
+}
+
+// handleReaddir returns all the entries in the first reply, and EOF for the next
+func (s *sftpSession) handleReaddir(id uint32, d *sftpDecoder) []byte {
+	h := s.handles[d.string()]
+	if h == nil || h.file != nil {
+		return statusReply(id, sshFxFailure, "invalid handle")
+	}
+	if h.listed {
+		return statusReply(id, sshFxEOF, "end of directory")
+	}
+	entries, err := s.fs.readDir(s.ctx, h.path)
+	if err != nil {
+		return s.statusOf(id, err)
+	}
+	h.listed = true
+
+	e := newSftpPacket(sshFxpName)
+	e.uint32(id)
+	e.uint32(uint32(len(entries)))
+	now := time.Now()
+	for _, entry := range entries {
+		e.string(entry.Name())
+		e.string(listLine(entry, s.user.Name, now))
+		e.attrs(entry, s.user.Uid, s.user.Gid)
+	}
+	return e.bytes()
+}
+
+func (s *sftpSession) handleRmdir(id uint32, p string) []byte {
+	fi, err := s.fs.Stat(s.ctx, p)
+	if err != nil {
+		return s.statusOf(id, err)
+	}
+	if !fi.IsDir() {
+		return statusReply(id, sshFxFailure, "not a directory")
+	}
+	entries, err := s.fs.readDir(s.ctx, p)
+	if err != nil {
+		return s.statusOf(id, err)
+	}
+	if len(entries) > 0 {
+		return statusReply(id, sshFxFailure, "directory not empty")
+	}
+	return s.statusOf(id, s.fs.RemoveAll(s.ctx, p))
+}
+
+// setSize supports truncating the file to empty, and ignores the other attributes
+func (s *sftpSession) setSize(p string, attrs sftpAttrs) error {
+	if attrs.flags&sshFileXferAttrSize == 0 {
+		return nil
+	}
+	fi, err := s.fs.Stat(s.ctx, p)
+	if err != nil {
+		return err
+	}
+	if uint64(fi.Size()) == attrs.size {
+		return nil
+	}
+	if attrs.size != 0 {
+		return fmt.Errorf("resize %s to %d: not supported", p, attrs.size)
+	}
+	f, err := s.fs.OpenFile(s.ctx, p, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
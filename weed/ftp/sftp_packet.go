@@ -0,0 +1,191 @@
+package ftp
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+)
+
+// the packet types of SFTP version 3, draft-ietf-secsh-filexfer-02
+const (
+	sshFxpInit     = 1
+	sshFxpVersion  = 2
+	sshFxpOpen     = 3
+	sshFxpClose    = 4
+	sshFxpRead     = 5
+	sshFxpWrite    = 6
+	sshFxpLstat    = 7
+	sshFxpFstat    = 8
+	sshFxpSetstat  = 9
+	sshFxpFsetstat = 10
+	sshFxpOpendir  = 11
+	sshFxpReaddir  = 12
+	sshFxpRemove   = 13
+	sshFxpMkdir    = 14
+	sshFxpRmdir    = 15
+	sshFxpRealpath = 16
+	sshFxpStat     = 17
+	sshFxpRename   = 18
+	sshFxpReadlink = 19
+	sshFxpSymlink  = 20
+	sshFxpStatus   = 101
+	sshFxpHandle   = 102
+	sshFxpData     = 103
+	sshFxpName     = 104
+	sshFxpAttrs    = 105
+)
+
+// the status codes
+const (
+	sshFxOk               = 0
+	sshFxEOF              = 1
+	sshFxNoSuchFile       = 2
+	sshFxPermissionDenied = 3
+	sshFxFailure          = 4
+	sshFxBadMessage       = 5
+	sshFxOpUnsupported    = 8
+)
+
+// the open flags
+const (
+	sshFxfRead   = 0x01
+	sshFxfWrite  = 0x02
+	sshFxfAppend = 0x04
+	sshFxfCreat  = 0x08
+	sshFxfTrunc  = 0x10
+	sshFxfExcl   = 0x20
+)
+
+// the attribute flags
+const (
+	sshFileXferAttrSize        = 0x01
+	sshFileXferAttrUidGid      = 0x02
+	sshFileXferAttrPermissions = 0x04
+	sshFileXferAttrAcModTime   = 0x08
+	sshFileXferAttrExtended    = 0x80000000
+)
+
+const (
+	sftpVersion      = 3
+	maxSftpPacket    = 256 * 1024
+	maxSftpReadBytes = 64 * 1024
+)
+
+var errShortPacket = errors.New("short sftp packet")
+
+type sftpDecoder struct {
+	b   []byte
+	err error
+}
+
+func (d *sftpDecoder) uint32() uint32 {
+	if d.err != nil || len(d.b) < 4 {
+		d.err = errShortPacket
+		return 0
+	}
+	v := binary.BigEndian.Uint32(d.b)
+	d.b = d.b[4:]
+	return v
+}
+
+func (d *sftpDecoder) uint64() uint64 {
+	if d.err != nil || len(d.b) < 8 {
+		d.err = errShortPacket
+		return 0
+	}
+	v := binary.BigEndian.Uint64(d.b)
+	d.b = d.b[8:]
+	return v
+}
+
+func (d *sftpDecoder) bytes() []byte {
+	n := d.uint32()
+	if d.err != nil || uint32(len(d.b)) < n {
+		d.err = errShortPacket
+		return nil
+	}
+	v := d.b[:n]
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *sftpDecoder) string() string {
+	return string(d.bytes())
+}
+
+// sftpAttrs are the decoded attributes, only the size is used
+type sftpAttrs struct {
+	flags uint32
+	size  uint64
+}
+
+func (d *sftpDecoder) attrs() (a sftpAttrs) {
+	a.flags = d.uint32()
+	if a.flags&sshFileXferAttrSize != 0 {
+		a.size = d.uint64()
+	}
+	if a.flags&sshFileXferAttrUidGid != 0 {
+		d.uint32()
+		d.uint32()
+	}
+	if a.flags&sshFileXferAttrPermissions != 0 {
+		d.uint32()
+	}
+	if a.flags&sshFileXferAttrAcModTime != 0 {
+		d.uint32()
+		d.uint32()
+	}
+	if a.flags&sshFileXferAttrExtended != 0 {
+		for count := d.uint32(); count > 0 && d.err == nil; count-- {
+			d.bytes()
+			d.bytes()
+		}
+	}
+	return
+}
+
+type sftpEncoder struct {
+	b []byte
+}
+
+// newSftpPacket starts the packet, leaving the length to be filled by bytes()
+func newSftpPacket(packetType byte) *sftpEncoder {
+	return &sftpEncoder{b: []byte{0, 0, 0, 0, packetType}}
+}
+
+func (e *sftpEncoder) uint32(v uint32) {
+	e.b = append(e.b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+func (e *sftpEncoder) uint64(v uint64) {
+	e.uint32(uint32(v >> 32))
+	e.uint32(uint32(v))
+}
+
+func (e *sftpEncoder) string(s string) {
+	e.uint32(uint32(len(s)))
+	e.b = append(e.b, s...)
+}
+
+func (e *sftpEncoder) bytes() []byte {
+	binary.BigEndian.PutUint32(e.b, uint32(len(e.b)-4))
+	return e.b
+}
+
+// attrs encodes the file info, with the files owned by the user
+func (e *sftpEncoder) attrs(fi os.FileInfo, uid, gid uint32) {
+	e.uint32(sshFileXferAttrSize | sshFileXferAttrUidGid | sshFileXferAttrPermissions | sshFileXferAttrAcModTime)
+	e.uint64(uint64(fi.Size()))
+	e.uint32(uid)
+	e.uint32(gid)
+	permissions := uint32(fi.Mode().Perm())
+	if fi.IsDir() {
+		permissions |= 0040000
+	} else {
+		permissions |= 0100000
+	}
+	e.uint32(permissions)
+	mtime := uint32(fi.ModTime().Unix())
+	e.uint32(mtime)
+	e.uint32(mtime)
+}
@@ -0,0 +1,225 @@
+package images
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/disintegration/imaging"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	// MaxTransformSize is the largest width or height of the transformed images
+	MaxTransformSize = 4096
+	// the originals of more pixels are not decoded, to bound the memory
+	maxTransformSourcePixels = 64 * 1024 * 1024
+	defaultJpegQuality       = 85
+)
+
+var ErrImageTooLarge = errors.New("the image is too large to transform")
+
+// Transform resizes and converts an image, as asked by the query parameters
+//
+//	?width=200&height=200&fit=crop&format=webp&quality=80
+//
+// The fit is "contain" by default, to fit in the width and the height keeping the aspect ratio,
+// "crop" to fill the width and the height and cut the rest from the center, or "scale" to stretch to them.
+// The images are not enlarged, except by "crop" and "scale".
+// The format is jpeg, png, gif or webp, and the original format by default.
+// The quality applies to jpeg, and to webp as near lossless, with 0 for lossless.
+// The webp is only served if smaller than the image in the original format,
+// since the lossless webp of a photo is often larger than the jpeg.
+type Transform struct {
+	Width   int
+	Height  int
+	Fit     string
+	Format  string
+	Quality int
+}
+
+// ParseTransform reads the transform from the query parameters, and returns nil if none is asked.
+// The "mode" parameter of the volume servers is also accepted, "fit" as "contain", and "fill" as "crop".
+func ParseTransform(query url.Values) (t *Transform, err error) {
+	if query.Get("width") == "" && query.Get("height") == "" && query.Get("fit") == "" && query.Get("format") == "" {
+		return nil, nil
+	}
+	t = &Transform{
+		Fit:    strings.ToLower(query.Get("fit")),
+		Format: strings.ToLower(query.Get("format")),
+	}
+	if t.Width, err = parseDimension(query, "width", MaxTransformSize); err != nil {
+		return nil, err
+	}
+	if t.Height, err = parseDimension(query, "height", MaxTransformSize); err != nil {
+		return nil, err
+	}
+	if t.Quality, err = parseDimension(query, "quality", 100); err != nil {
+		return nil, err
+	}
+	if t.Fit == "" {
+		switch query.Get("mode") {
+		case "fill":
+			t.Fit = "crop"
+		default:
+			t.Fit = "contain"
+		}
+	}
+	switch t.Fit {
+	case "contain", "crop", "scale":
+	default:
+		return nil, fmt.Errorf("unknown fit %s, should be contain, crop or scale", t.Fit)
+	}
+	switch t.Format {
+	case "jpg":
+		t.Format = "jpeg"
+	case "", "jpeg", "png", "gif", "webp":
+	default:
+		return nil, fmt.Errorf("unknown format %s, should be jpeg, png, gif or webp", t.Format)
+	}
+	return t, nil
+}
+
+func parseDimension(query url.Values, name string, max int) (int, error) {
+	value := query.Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 || n > max {
+		return 0, fmt.Errorf("%s should be from 0 to %d", name, max)
+	}
+	return n, nil
+}
+
+// Key identifies the transform, to cache its results
+func (t *Transform) Key() string {
+	return fmt.Sprintf("width=%d&height=%d&fit=%s&format=%s&quality=%d", t.Width, t.Height, t.Fit, t.Format, t.Quality)
+}
+
+// IsTransformable tells whether the file is an image that can be transformed, by the extension or the mime type
+func IsTransformable(name, mimeType string) bool {
+	switch strings.ToLower(mimeType) {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+		return true
+	}
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".jpg", ".jpeg", ".png", ".gif", ".webp":
+		return true
+	}
+	return false
+}
+
+// Apply transforms the image data, and returns the result with its mime type.
+// Only the first frame of the animated images is kept.
+func (t *Transform) Apply(data []byte) (result []byte, mimeType string, err error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	if config.Width*config.Height > maxTransformSourcePixels {
+		return nil, "", ErrImageTooLarge
+	}
+	if format == "jpeg" {
+		data = FixJpgOrientation(data)
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+
+	dst := t.resize(src)
+
+	sourceFormat := format
+	if t.Format != "" {
+		format = t.Format
+	}
+	if result, err = t.encode(dst, format); err != nil {
+		return nil, "", err
+	}
+
+	if format == "webp" && sourceFormat != "webp" {
+		original := data
+		if dst != src {
+			original, err = t.encode(dst, sourceFormat)
+		}
+		if err == nil && len(original) < len(result) {
+			return original, "image/" + sourceFormat, nil
+		}
+	}
+	return result, "image/" + format, nil
+}
+
+func (t *Transform) encode(img image.Image, format string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "jpeg":
+		quality := t.Quality
+		if quality == 0 {
+			quality = defaultJpegQuality
+		}
+		err = jpeg.Encode(&buf, flatten(img), &jpeg.Options{Quality: quality})
+	case "png":
+		err = png.Encode(&buf, img)
+	case "gif":
+		err = gif.Encode(&buf, img, nil)
+	case "webp":
+		err = EncodeWebp(&buf, img, t.Quality)
+	default:
+		return nil, fmt.Errorf("can not encode %s images", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (t *Transform) resize(src image.Image) image.Image {
+	bounds := src.Bounds()
+	width, height := t.Width, t.Height
+	if width == 0 && height == 0 {
+		return src
+	}
+	switch t.Fit {
+	case "crop":
+		if width == 0 {
+			width = bounds.Dx()
+		}
+		if height == 0 {
+			height = bounds.Dy()
+		}
+		return imaging.Fill(src, width, height, imaging.Center, imaging.Lanczos)
+	case "scale":
+		return imaging.Resize(src, width, height, imaging.Lanczos)
+	}
+	if (width == 0 || bounds.Dx() <= width) && (height == 0 || bounds.Dy() <= height) {
+		return src
+	}
+	if width == 0 || height == 0 {
+		return imaging.Resize(src, width, height, imaging.Lanczos)
+	}
+	return imaging.Fit(src, width, height, imaging.Lanczos)
+}
+
+// flatten draws the transparent image on white, since jpeg has no transparency
+func flatten(img image.Image) image.Image {
+	if opaque, ok := img.(interface{ Opaque() bool }); ok && opaque.Opaque() {
+		return img
+	}
+	bounds := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Rect, image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Rect, img, bounds.Min, draw.Over)
+	return dst
+}
@@ -0,0 +1,84 @@
+package images
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"io/ioutil"
+	"net/url"
+	"testing"
+)
+
+func TestTransform(t *testing.T) {
+	var buf bytes.Buffer
+	jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 640, 480)), nil)
+	sample := buf.Bytes()
+
+	for _, c := range []struct {
+		query         string
+		mimeType      string
+		width, height int
+	}{
+		{"width=200&height=200", "image/jpeg", 200, 150},
+		{"width=200&height=200&fit=crop&format=webp", "image/webp", 200, 200},
+		{"width=100&height=50&fit=scale&format=png", "image/png", 100, 50},
+		{"height=30&mode=fill", "image/jpeg", 640, 30},
+		{"width=4000&format=gif", "image/gif", 640, 480},
+	} {
+		query, _ := url.ParseQuery(c.query)
+		transform, err := ParseTransform(query)
+		if err != nil || transform == nil {
+			t.Fatalf("parse %s: %v", c.query, err)
+		}
+		data, mimeType, err := transform.Apply(sample)
+		if err != nil {
+			t.Fatalf("apply %s: %v", c.query, err)
+		}
+		config, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("decode %s: %v", c.query, err)
+		}
+		if mimeType != c.mimeType || config.Width != c.width || config.Height != c.height {
+			t.Errorf("%s: %s %dx%d, expected %s %dx%d", c.query, mimeType, config.Width, config.Height, c.mimeType, c.width, c.height)
+		}
+	}
+
+	// the photo is turned upright by the exif orientation
+	photo, err := ioutil.ReadFile("sample1.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _, err := (&Transform{Width: 150, Fit: "contain"}).Apply(photo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config, _, _ := image.DecodeConfig(bytes.NewReader(data)); config.Width != 150 || config.Height != 201 {
+		t.Errorf("photo transformed to %dx%d", config.Width, config.Height)
+	}
+
+	// the lossless webp of the photo is larger than the jpeg
+	data, mimeType, err := (&Transform{Fit: "contain", Format: "webp"}).Apply(photo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mimeType != "image/jpeg" || len(data) > len(photo) {
+		t.Errorf("photo served as %s of %d bytes, the original is %d bytes", mimeType, len(data), len(photo))
+	}
+	data, mimeType, err = (&Transform{Width: 150, Fit: "contain", Format: "webp", Quality: 30}).Apply(photo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config, format, _ := image.DecodeConfig(bytes.NewReader(data)); config.Width != 150 || "image/"+format != mimeType {
+		t.Errorf("resized photo served as %s, decoded as %s %dx%d", mimeType, format, config.Width, config.Height)
+	}
+
+	for _, query := range []string{"width=-1", "width=5000", "fit=stretch", "format=tiff", "width=10&quality=101"} {
+		values, _ := url.ParseQuery(query)
+		if _, err := ParseTransform(values); err == nil {
+			t.Errorf("accepted %s", query)
+		}
+	}
+	if transform, _ := ParseTransform(url.Values{"mode": {"fit"}}); transform != nil {
+		t.Errorf("transform without the size or the format")
+	}
+}
@@ -0,0 +1,578 @@
+package images
+
+import (
+	"container/heap"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/draw"
+	"io"
+)
+
+// EncodeWebp writes the image as a lossless WebP (VP8L) image.
+// The quality below 100 makes it near lossless, rounding the colors to fewer bits for a smaller image,
+// like the near lossless mode of libwebp. The quality 0 is lossless.
+//
+// The encoder is kept simple rather than small: it uses the subtract green and the predictor transforms,
+// and the backward references only repeat the pixels on the left or above, without the color cache.
+// It still compresses the flat areas of the thumbnails and the graphics well, and needs no cgo.
+// The photos are better served as jpeg, see Transform.Apply.
+func EncodeWebp(w io.Writer, img image.Image, quality int) error {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width < 1 || height < 1 || width > 1<<14 || height > 1<<14 {
+		return fmt.Errorf("webp can not encode a %dx%d image", width, height)
+	}
+
+	nrgba, ok := img.(*image.NRGBA)
+	if !ok || nrgba.Rect.Min != (image.Point{}) {
+		nrgba = image.NewNRGBA(image.Rect(0, 0, width, height))
+		draw.Draw(nrgba, nrgba.Rect, img, bounds.Min, draw.Src)
+	}
+	argb := make([]uint32, width*height)
+	hasAlpha := false
+	droppedBits := nearLosslessBits(quality)
+	for y := 0; y < height; y++ {
+		row := nrgba.Pix[y*nrgba.Stride : y*nrgba.Stride+4*width]
+		for x := 0; x < width; x++ {
+			r, g, b, a := row[4*x], row[4*x+1], row[4*x+2], row[4*x+3]
+			if a != 0xff {
+				hasAlpha = true
+			}
+			if droppedBits > 0 {
+				r, g, b = roundColor(r, droppedBits), roundColor(g, droppedBits), roundColor(b, droppedBits)
+			}
+			// the subtract green transform
+			argb[y*width+x] = uint32(a)<<24 | uint32(r-g)<<16 | uint32(g)<<8 | uint32(b-g)
+		}
+	}
+
+	bw := &bitWriter{}
+	bw.write(0x2f, 8)
+	bw.write(uint32(width-1), 14)
+	bw.write(uint32(height-1), 14)
+	if hasAlpha {
+		bw.write(1, 1)
+	} else {
+		bw.write(0, 1)
+	}
+	bw.write(0, 3)
+
+	// the transforms are undone by the decoder in the reverse order
+	bw.write(1, 1)
+	bw.write(webpSubtractGreen, 2)
+	bw.write(1, 1)
+	bw.write(webpPredictor, 2)
+	bw.write(webpPredictorBits-2, 3)
+	modes, tilesWidth := choosePredictors(argb, width, height)
+	writeWebpImage(bw, modes, tilesWidth, false)
+	residuals := predictResiduals(argb, width, height, modes, tilesWidth)
+	bw.write(0, 1)
+
+	writeWebpImage(bw, residuals, width, true)
+	data := bw.flush()
+
+	header := make([]byte, 20)
+	copy(header[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:8], uint32(12+len(data)+len(data)%2))
+	copy(header[8:12], "WEBP")
+	copy(header[12:16], "VP8L")
+	binary.LittleEndian.PutUint32(header[16:20], uint32(len(data)))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if len(data)%2 == 1 {
+		data = append(data, 0)
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// nearLosslessBits is the number of the low color bits dropped for the quality, from 0 for lossless up to 4
+func nearLosslessBits(quality int) uint {
+	if quality <= 0 || quality >= 100 {
+		return 0
+	}
+	if bits := uint(100-quality+19) / 20; bits < 4 {
+		return bits
+	}
+	return 4
+}
+
+// roundColor rounds the color value to the nearest multiple of 1<<bits, or to 0xff
+func roundColor(v uint8, bits uint) uint8 {
+	rounded := (int(v) + 1<<(bits-1)) &^ (1<<bits - 1)
+	if rounded > 0xff {
+		return 0xff
+	}
+	return uint8(rounded)
+}
+
+const (
+	webpPredictor     = 0
+	webpSubtractGreen = 2
+	// the predictor is chosen for each tile of 32x32 pixels
+	webpPredictorBits = 5
+
+	webpLiteralCodes  = 256
+	webpLengthCodes   = 24
+	webpDistanceCodes = 40
+	webpMaxLength     = 4096
+	webpMinLength     = 3
+)
+
+// the predictor modes tried for each tile: L, T, Select and ClampAddSubtractFull
+var webpPredictorModes = []uint32{1, 2, 11, 12}
+
+func choosePredictors(argb []uint32, width, height int) (modes []uint32, tilesWidth int) {
+	tileSize := 1 << webpPredictorBits
+	tilesWidth = (width + tileSize - 1) / tileSize
+	tilesHeight := (height + tileSize - 1) / tileSize
+	modes = make([]uint32, tilesWidth*tilesHeight)
+	for ty := 0; ty < tilesHeight; ty++ {
+		for tx := 0; tx < tilesWidth; tx++ {
+			bestMode, bestCost := webpPredictorModes[0], -1
+			for _, mode := range webpPredictorModes {
+				cost := 0
+				for y := ty * tileSize; y < (ty+1)*tileSize && y < height; y++ {
+					for x := tx * tileSize; x < (tx+1)*tileSize && x < width; x++ {
+						cost += residualCost(argb[y*width+x], predict(argb, width, x, y, mode))
+					}
+				}
+				if bestCost < 0 || cost < bestCost {
+					bestMode, bestCost = mode, cost
+				}
+			}
+			// the mode is in the green channel
+			modes[ty*tilesWidth+tx] = 0xff000000 | bestMode<<8
+		}
+	}
+	return
+}
+
+func predictResiduals(argb []uint32, width, height int, modes []uint32, tilesWidth int) []uint32 {
+	residuals := make([]uint32, len(argb))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			mode := modes[(y>>webpPredictorBits)*tilesWidth+(x>>webpPredictorBits)] >> 8 & 0xf
+			residuals[y*width+x] = subPixels(argb[y*width+x], predict(argb, width, x, y, mode))
+		}
+	}
+	return residuals
+}
+
+// predict is the prediction of the pixel, the same as the decoder, including the first row and column
+func predict(argb []uint32, width, x, y int, mode uint32) uint32 {
+	switch {
+	case x == 0 && y == 0:
+		return 0xff000000
+	case y == 0:
+		return argb[x-1]
+	case x == 0:
+		return argb[(y-1)*width]
+	}
+	l, t, tl := argb[y*width+x-1], argb[(y-1)*width+x], argb[(y-1)*width+x-1]
+	switch mode {
+	case 1:
+		return l
+	case 2:
+		return t
+	case 11:
+		var pl, pt int
+		for shift := uint(0); shift < 32; shift += 8 {
+			c := int(tl >> shift & 0xff)
+			pl += abs(c - int(t>>shift&0xff))
+			pt += abs(c - int(l>>shift&0xff))
+		}
+		if pl < pt {
+			return l
+		}
+		return t
+	case 12:
+		var p uint32
+		for shift := uint(0); shift < 32; shift += 8 {
+			v := int(l>>shift&0xff) + int(t>>shift&0xff) - int(tl>>shift&0xff)
+			if v < 0 {
+				v = 0
+			} else if v > 0xff {
+				v = 0xff
+			}
+			p |= uint32(v) << shift
+		}
+		return p
+	}
+	return 0xff000000
+}
+
+func subPixels(a, b uint32) uint32 {
+	var d uint32
+	for shift := uint(0); shift < 32; shift += 8 {
+		d |= uint32(uint8(a>>shift)-uint8(b>>shift)) << shift
+	}
+	return d
+}
+
+func residualCost(pixel, prediction uint32) int {
+	cost := 0
+	for shift := uint(0); shift < 32; shift += 8 {
+		cost += abs(int(int8(uint8(pixel>>shift) - uint8(prediction>>shift))))
+	}
+	return cost
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// webpSymbol is a literal pixel, or a backward reference of the length and the distance code
+type webpSymbol struct {
+	argb     uint32
+	length   int
+	distance int
+}
+
+// writeWebpImage writes the pixels with their prefix codes, without the color cache and the meta prefix codes
+func writeWebpImage(bw *bitWriter, argb []uint32, width int, topLevel bool) {
+	// the backward references to the pixel on the left (distance code 2) or above (distance code 1)
+	var symbols []webpSymbol
+	for i := 0; i < len(argb); {
+		bestLength, bestDistance := 0, 0
+		for _, c := range []struct{ offset, distance int }{{1, 2}, {width, 1}} {
+			if i < c.offset {
+				continue
+			}
+			n := 0
+			for i+n < len(argb) && n < webpMaxLength && argb[i+n] == argb[i+n-c.offset] {
+				n++
+			}
+			if n > bestLength {
+				bestLength, bestDistance = n, c.distance
+			}
+		}
+		if bestLength >= webpMinLength {
+			symbols = append(symbols, webpSymbol{length: bestLength, distance: bestDistance})
+			i += bestLength
+			continue
+		}
+		symbols = append(symbols, webpSymbol{argb: argb[i]})
+		i++
+	}
+
+	green := make([]uint32, webpLiteralCodes+webpLengthCodes)
+	red := make([]uint32, 256)
+	blue := make([]uint32, 256)
+	alpha := make([]uint32, 256)
+	distance := make([]uint32, webpDistanceCodes)
+	for _, s := range symbols {
+		if s.length > 0 {
+			lengthCode, _, _ := prefixEncode(s.length)
+			green[webpLiteralCodes+lengthCode]++
+			distanceCode, _, _ := prefixEncode(s.distance)
+			distance[distanceCode]++
+			continue
+		}
+		green[s.argb>>8&0xff]++
+		red[s.argb>>16&0xff]++
+		blue[s.argb&0xff]++
+		alpha[s.argb>>24]++
+	}
+
+	bw.write(0, 1) // no color cache
+	if topLevel {
+		bw.write(0, 1) // no meta prefix codes
+	}
+	codes := []*prefixCode{
+		writePrefixCode(bw, green),
+		writePrefixCode(bw, red),
+		writePrefixCode(bw, blue),
+		writePrefixCode(bw, alpha),
+		writePrefixCode(bw, distance),
+	}
+	for _, s := range symbols {
+		if s.length > 0 {
+			lengthCode, extraBits, extra := prefixEncode(s.length)
+			codes[0].write(bw, webpLiteralCodes+lengthCode)
+			bw.write(extra, extraBits)
+			distanceCode, extraBits, extra := prefixEncode(s.distance)
+			codes[4].write(bw, distanceCode)
+			bw.write(extra, extraBits)
+			continue
+		}
+		codes[0].write(bw, int(s.argb>>8&0xff))
+		codes[1].write(bw, int(s.argb>>16&0xff))
+		codes[2].write(bw, int(s.argb&0xff))
+		codes[3].write(bw, int(s.argb>>24))
+	}
+}
+
+// prefixEncode splits the length or the distance code into the prefix symbol and the extra bits
+func prefixEncode(value int) (symbol int, extraBits uint, extra uint32) {
+	v := value - 1
+	if v < 4 {
+		return v, 0, 0
+	}
+	highBit := uint(0)
+	for v>>(highBit+1) != 0 {
+		highBit++
+	}
+	secondBit := v >> (highBit - 1) & 1
+	extraBits = highBit - 1
+	return int(2*highBit) + secondBit, extraBits, uint32(v & (1<<extraBits - 1))
+}
+
+// prefixCode is the canonical Huffman code of an alphabet, with the codes bit reversed for writing
+type prefixCode struct {
+	lengths []uint8
+	codes   []uint32
+	// the only symbol of the code is read with no bits
+	single bool
+}
+
+func (c *prefixCode) write(bw *bitWriter, symbol int) {
+	if !c.single {
+		bw.write(c.codes[symbol], uint(c.lengths[symbol]))
+	}
+}
+
+// the order of the code length code lengths in the stream
+var codeLengthCodeOrder = []int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// writePrefixCode writes the prefix code of the histogram, and returns it for writing the symbols
+func writePrefixCode(bw *bitWriter, histogram []uint32) *prefixCode {
+	var used []int
+	for symbol, count := range histogram {
+		if count > 0 {
+			used = append(used, symbol)
+		}
+	}
+
+	// the simple code of one or two symbols below 256, where a single symbol takes no bits
+	if len(used) <= 2 && (len(used) == 0 || used[len(used)-1] < 256) {
+		code := &prefixCode{lengths: make([]uint8, len(histogram)), codes: make([]uint32, len(histogram))}
+		if len(used) == 0 {
+			used = []int{0}
+		}
+		code.single = len(used) == 1
+		bw.write(1, 1)
+		bw.write(uint32(len(used)-1), 1)
+		if used[0] < 2 {
+			bw.write(0, 1)
+			bw.write(uint32(used[0]), 1)
+		} else {
+			bw.write(1, 1)
+			bw.write(uint32(used[0]), 8)
+		}
+		if len(used) == 2 {
+			bw.write(uint32(used[1]), 8)
+			code.lengths[used[0]], code.lengths[used[1]] = 1, 1
+			code.codes[used[1]] = 1
+		}
+		return code
+	}
+
+	code := newPrefixCode(histogram, 15)
+
+	// the code lengths, with the runs of zeros by the symbols 17 and 18
+	type codeLengthSymbol struct {
+		symbol int
+		extra  uint32
+	}
+	var lengthSymbols []codeLengthSymbol
+	codeLengthHistogram := make([]uint32, 19)
+	for i := 0; i < len(code.lengths); {
+		if code.lengths[i] != 0 {
+			lengthSymbols = append(lengthSymbols, codeLengthSymbol{symbol: int(code.lengths[i])})
+			i++
+			continue
+		}
+		run := 1
+		for i+run < len(code.lengths) && code.lengths[i+run] == 0 && run < 138 {
+			run++
+		}
+		switch {
+		case run < 3:
+			lengthSymbols = append(lengthSymbols, codeLengthSymbol{symbol: 0})
+			run = 1
+		case run <= 10:
+			lengthSymbols = append(lengthSymbols, codeLengthSymbol{symbol: 17, extra: uint32(run - 3)})
+		default:
+			lengthSymbols = append(lengthSymbols, codeLengthSymbol{symbol: 18, extra: uint32(run - 11)})
+		}
+		i += run
+	}
+	for _, s := range lengthSymbols {
+		codeLengthHistogram[s.symbol]++
+	}
+	codeLengthCode := newPrefixCode(codeLengthHistogram, 7)
+
+	bw.write(0, 1)
+	count := 4
+	for i, symbol := range codeLengthCodeOrder {
+		if codeLengthCode.lengths[symbol] != 0 && i+1 > count {
+			count = i + 1
+		}
+	}
+	bw.write(uint32(count-4), 4)
+	for _, symbol := range codeLengthCodeOrder[:count] {
+		bw.write(uint32(codeLengthCode.lengths[symbol]), 3)
+	}
+	bw.write(0, 1) // all the code lengths are written
+	for _, s := range lengthSymbols {
+		codeLengthCode.write(bw, s.symbol)
+		switch s.symbol {
+		case 17:
+			bw.write(s.extra, 3)
+		case 18:
+			bw.write(s.extra, 7)
+		}
+	}
+	return code
+}
+
+// newPrefixCode builds the Huffman code limited to the max code length.
+// A single used symbol has the length 1 in the stream, but is written with no bits as the decoder reads it.
+func newPrefixCode(histogram []uint32, maxLength int) *prefixCode {
+	code := &prefixCode{lengths: make([]uint8, len(histogram)), codes: make([]uint32, len(histogram))}
+	counts := make([]uint32, len(histogram))
+	copy(counts, histogram)
+	for {
+		if huffmanLengths(counts, code.lengths) <= maxLength {
+			break
+		}
+		// flatten the distribution until the code is short enough
+		for i, count := range counts {
+			if count > 0 {
+				counts[i] = count/2 + 1
+			}
+		}
+	}
+
+	var used, maxLen int
+	for _, length := range code.lengths {
+		if length > 0 {
+			used++
+		}
+		if int(length) > maxLen {
+			maxLen = int(length)
+		}
+	}
+	code.single = used == 1
+
+	// the canonical codes in the increasing order of the lengths, and then the symbols
+	lengthCounts := make([]uint32, maxLen+1)
+	for _, length := range code.lengths {
+		lengthCounts[length]++
+	}
+	lengthCounts[0] = 0
+	nextCodes := make([]uint32, maxLen+1)
+	var next uint32
+	for length := 1; length <= maxLen; length++ {
+		next = (next + lengthCounts[length-1]) << 1
+		nextCodes[length] = next
+	}
+	for symbol, length := range code.lengths {
+		if length == 0 {
+			continue
+		}
+		code.codes[symbol] = reverseBits(nextCodes[length], uint(length))
+		nextCodes[length]++
+	}
+	return code
+}
+
+func reverseBits(v uint32, n uint) uint32 {
+	var r uint32
+	for i := uint(0); i < n; i++ {
+		r = r<<1 | v>>i&1
+	}
+	return r
+}
+
+// huffmanLengths sets the Huffman code lengths of the counts, and returns the max length
+func huffmanLengths(counts []uint32, lengths []uint8) int {
+	for i := range lengths {
+		lengths[i] = 0
+	}
+	h := &huffmanHeap{}
+	for symbol, count := range counts {
+		if count > 0 {
+			*h = append(*h, &huffmanNode{count: count, symbol: symbol})
+		}
+	}
+	if h.Len() == 1 {
+		lengths[(*h)[0].symbol] = 1
+		return 1
+	}
+	heap.Init(h)
+	for h.Len() > 1 {
+		a, b := heap.Pop(h).(*huffmanNode), heap.Pop(h).(*huffmanNode)
+		heap.Push(h, &huffmanNode{count: a.count + b.count, symbol: -1, left: a, right: b})
+	}
+	maxLength := 0
+	var walk func(n *huffmanNode, depth int)
+	walk = func(n *huffmanNode, depth int) {
+		if n.left == nil {
+			lengths[n.symbol] = uint8(depth)
+			if depth > maxLength {
+				maxLength = depth
+			}
+			return
+		}
+		walk(n.left, depth+1)
+		walk(n.right, depth+1)
+	}
+	walk((*h)[0], 0)
+	return maxLength
+}
+
+type huffmanNode struct {
+	count       uint32
+	symbol      int
+	left, right *huffmanNode
+}
+
+type huffmanHeap []*huffmanNode
+
+func (h huffmanHeap) Len() int { return len(h) }
+func (h huffmanHeap) Less(i, j int) bool {
+	if h[i].count != h[j].count {
+		return h[i].count < h[j].count
+	}
+	return h[i].symbol < h[j].symbol
+}
+func (h huffmanHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *huffmanHeap) Push(x interface{}) { *h = append(*h, x.(*huffmanNode)) }
+func (h *huffmanHeap) Pop() interface{} {
+	old := *h
+	n := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return n
+}
+
+// bitWriter packs the bits from the least significant bit, as read by the VP8L decoder
+type bitWriter struct {
+	buf   []byte
+	acc   uint64
+	nBits uint
+}
+
+func (b *bitWriter) write(v uint32, n uint) {
+	b.acc |= uint64(v&(1<<n-1)) << b.nBits
+	b.nBits += n
+	for b.nBits >= 8 {
+		b.buf = append(b.buf, byte(b.acc))
+		b.acc >>= 8
+		b.nBits -= 8
+	}
+}
+
+func (b *bitWriter) flush() []byte {
+	if b.nBits > 0 {
+		b.buf = append(b.buf, byte(b.acc))
+		b.acc, b.nBits = 0, 0
+	}
+	return b.buf
+}
@@ -0,0 +1,137 @@
+package images
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"io/ioutil"
+	"math/rand"
+	"testing"
+
+	"github.com/disintegration/imaging"
+	"golang.org/x/image/webp"
+)
+
+func TestEncodeWebp(t *testing.T) {
+	sample, err := ioutil.ReadFile("sample1.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	original, _, err := image.Decode(bytes.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	photo := imaging.Resize(original, 320, 0, imaging.Lanczos)
+
+	solid := image.NewNRGBA(image.Rect(0, 0, 100, 40))
+	draw.Draw(solid, solid.Rect, image.NewUniform(color.NRGBA{200, 10, 30, 255}), image.Point{}, draw.Src)
+
+	gradient := image.NewNRGBA(image.Rect(0, 0, 300, 70))
+	for y := 0; y < 70; y++ {
+		for x := 0; x < 300; x++ {
+			gradient.SetNRGBA(x, y, color.NRGBA{uint8(x), uint8(y * 3), uint8(x + y), uint8(255 - y)})
+		}
+	}
+
+	noise := image.NewNRGBA(image.Rect(0, 0, 67, 33))
+	rand.New(rand.NewSource(1)).Read(noise.Pix)
+
+	for name, img := range map[string]image.Image{
+		"photo":    photo,
+		"solid":    solid,
+		"gradient": gradient,
+		"noise":    noise,
+		"pixel":    image.NewNRGBA(image.Rect(0, 0, 1, 1)),
+		"offset":   gradient.SubImage(image.Rect(10, 5, 50, 60)),
+	} {
+		var buf bytes.Buffer
+		if err := EncodeWebp(&buf, img, 0); err != nil {
+			t.Fatalf("encode %s: %v", name, err)
+		}
+		decoded, err := webp.Decode(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatalf("decode %s: %v", name, err)
+		}
+		bounds := img.Bounds()
+		if decoded.Bounds().Dx() != bounds.Dx() || decoded.Bounds().Dy() != bounds.Dy() {
+			t.Fatalf("decoded %s size %v, expected %v", name, decoded.Bounds(), bounds)
+		}
+		for y := 0; y < bounds.Dy(); y++ {
+			for x := 0; x < bounds.Dx(); x++ {
+				expected := color.NRGBAModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y))
+				if actual := color.NRGBAModel.Convert(decoded.At(x, y)); actual != expected {
+					t.Fatalf("decoded %s pixel (%d,%d) %v, expected %v", name, x, y, actual, expected)
+				}
+			}
+		}
+		t.Logf("%s %v: %d bytes", name, bounds.Size(), buf.Len())
+	}
+}
+
+func TestEncodeWebpQuality(t *testing.T) {
+	sample, err := ioutil.ReadFile("sample1.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	original, _, err := image.Decode(bytes.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	photo := imaging.Resize(original, 320, 0, imaging.Lanczos)
+
+	lastSize := 0
+	for _, quality := range []int{100, 90, 70, 50, 10} {
+		var buf bytes.Buffer
+		if err := EncodeWebp(&buf, photo, quality); err != nil {
+			t.Fatalf("encode quality %d: %v", quality, err)
+		}
+		decoded, err := webp.Decode(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatalf("decode quality %d: %v", quality, err)
+		}
+		// each color is rounded by at most half of the dropped bits
+		maxDiff := 0
+		if bits := nearLosslessBits(quality); bits > 0 {
+			maxDiff = 1 << (bits - 1)
+		}
+		bounds := photo.Bounds()
+		for y := 0; y < bounds.Dy(); y++ {
+			for x := 0; x < bounds.Dx(); x++ {
+				expected := color.NRGBAModel.Convert(photo.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA)
+				actual := color.NRGBAModel.Convert(decoded.At(x, y)).(color.NRGBA)
+				if abs(int(actual.R)-int(expected.R)) > maxDiff || abs(int(actual.G)-int(expected.G)) > maxDiff ||
+					abs(int(actual.B)-int(expected.B)) > maxDiff || actual.A != expected.A {
+					t.Fatalf("quality %d pixel (%d,%d) %v, expected %v", quality, x, y, actual, expected)
+				}
+			}
+		}
+		if lastSize > 0 && buf.Len() >= lastSize {
+			t.Errorf("quality %d: %d bytes, not smaller than %d bytes of the higher quality", quality, buf.Len(), lastSize)
+		}
+		lastSize = buf.Len()
+		t.Logf("quality %d: %d bytes", quality, buf.Len())
+	}
+}
+
+func TestRoundColor(t *testing.T) {
+	for _, c := range []struct {
+		v        uint8
+		bits     uint
+		expected uint8
+	}{
+		{0, 1, 0},
+		{1, 1, 2},
+		{5, 2, 4},
+		{6, 2, 8},
+		{253, 2, 252},
+		{255, 2, 255},
+		{254, 4, 255},
+		{7, 4, 0},
+		{8, 4, 16},
+	} {
+		if rounded := roundColor(c.v, c.bits); rounded != c.expected {
+			t.Errorf("round %d by %d bits: %d, expected %d", c.v, c.bits, rounded, c.expected)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package media
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+)
+
+func init() {
+	MediaHandlers = append(MediaHandlers, &DashHandler{})
+}
+
+// DashHandler serves a fragmented mp4 file as a static DASH manifest, with each moof and mdat fragment as a segment
+type DashHandler struct {
+}
+
+func (h *DashHandler) GetName() string {
+	return "dash"
+}
+
+func (h *DashHandler) Serve(w http.ResponseWriter, r *http.Request, file *File) error {
+
+	index, err := LoadFragmentIndex(file)
+	if err != nil {
+		return err
+	}
+
+	if segment := r.URL.Query().Get("segment"); segment != "" {
+		return serveSegment(w, file, index, segment)
+	}
+
+	manifest, err := h.manifest(file, index)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/dash+xml")
+	w.Write(manifest)
+	return nil
+}
+
+type mpd struct {
+	XMLName                   xml.Name  `xml:"urn:mpeg:dash:schema:mpd:2011 MPD"`
+	Type                      string    `xml:"type,attr"`
+	Profiles                  string    `xml:"profiles,attr"`
+	MinBufferTime             string    `xml:"minBufferTime,attr"`
+	MediaPresentationDuration string    `xml:"mediaPresentationDuration,attr"`
+	Period                    mpdPeriod `xml:"Period"`
+}
+
+type mpdPeriod struct {
+	AdaptationSet mpdAdaptationSet `xml:"AdaptationSet"`
+}
+
+type mpdAdaptationSet struct {
+	MimeType         string            `xml:"mimeType,attr"`
+	SegmentAlignment bool              `xml:"segmentAlignment,attr"`
+	Representation   mpdRepresentation `xml:"Representation"`
+}
+
+type mpdRepresentation struct {
+	Id          string         `xml:"id,attr"`
+	Bandwidth   int64          `xml:"bandwidth,attr"`
+	SegmentList mpdSegmentList `xml:"SegmentList"`
+}
+
+type mpdSegmentList struct {
+	Timescale       uint32            `xml:"timescale,attr"`
+	Initialization  mpdInitialization `xml:"Initialization"`
+	SegmentTimeline []mpdS            `xml:"SegmentTimeline>S"`
+	SegmentURLs     []mpdSegmentURL   `xml:"SegmentURL"`
+}
+
+type mpdInitialization struct {
+	SourceURL string `xml:"sourceURL,attr"`
+}
+
+type mpdS struct {
+	D uint64 `xml:"d,attr"`
+}
+
+type mpdSegmentURL struct {
+	Media string `xml:"media,attr"`
+}
+
+func (h *DashHandler) manifest(file *File, index *FragmentIndex) ([]byte, error) {
+
+	seconds := index.Seconds(index.TotalDuration())
+	var bandwidth int64
+	if seconds > 0 {
+		bandwidth = int64(float64(file.Size*8) / seconds)
+	}
+
+	segmentList := mpdSegmentList{
+		Timescale: index.Timescale,
+		Initialization: mpdInitialization{
+			SourceURL: segmentUrl(file, h.GetName(), "init"),
+		},
+	}
+	for i, f := range index.Fragments {
+		segmentList.SegmentTimeline = append(segmentList.SegmentTimeline, mpdS{D: f.Duration})
+		segmentList.SegmentURLs = append(segmentList.SegmentURLs, mpdSegmentURL{
+			Media: segmentUrl(file, h.GetName(), fmt.Sprintf("%d", i)),
+		})
+	}
+
+	manifest := &mpd{
+		Type:                      "static",
+		Profiles:                  "urn:mpeg:dash:profile:full:2011",
+		MinBufferTime:             "PT2S",
+		MediaPresentationDuration: fmt.Sprintf("PT%.3fS", seconds),
+		Period: mpdPeriod{
+			AdaptationSet: mpdAdaptationSet{
+				MimeType:         "video/mp4",
+				SegmentAlignment: true,
+				Representation: mpdRepresentation{
+					Id:          "1",
+					Bandwidth:   bandwidth,
+					SegmentList: segmentList,
+				},
+			},
+		},
+	}
+
+	data, err := xml.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
@@ -0,0 +1,60 @@
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"net/http"
+)
+
+func init() {
+	MediaHandlers = append(MediaHandlers, &HlsHandler{})
+}
+
+// HlsHandler serves a fragmented mp4 file as a HLS playlist, with each moof and mdat fragment as a segment
+type HlsHandler struct {
+}
+
+func (h *HlsHandler) GetName() string {
+	return "hls"
+}
+
+func (h *HlsHandler) Serve(w http.ResponseWriter, r *http.Request, file *File) error {
+
+	index, err := LoadFragmentIndex(file)
+	if err != nil {
+		return err
+	}
+
+	if segment := r.URL.Query().Get("segment"); segment != "" {
+		return serveSegment(w, file, index, segment)
+	}
+
+	playlist := h.playlist(file, index)
+	w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+	w.Write(playlist)
+	return nil
+}
+
+func (h *HlsHandler) playlist(file *File, index *FragmentIndex) []byte {
+
+	var maxSeconds float64
+	for _, f := range index.Fragments {
+		maxSeconds = math.Max(maxSeconds, index.Seconds(f.Duration))
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("#EXTM3U\n")
+	buf.WriteString("#EXT-X-VERSION:7\n")
+	fmt.Fprintf(&buf, "#EXT-X-TARGETDURATION:%d\n", int(math.Ceil(maxSeconds)))
+	buf.WriteString("#EXT-X-MEDIA-SEQUENCE:0\n")
+	buf.WriteString("#EXT-X-PLAYLIST-TYPE:VOD\n")
+	buf.WriteString("#EXT-X-INDEPENDENT-SEGMENTS\n")
+	fmt.Fprintf(&buf, "#EXT-X-MAP:URI=\"%s\"\n", segmentUrl(file, h.GetName(), "init"))
+	for i, f := range index.Fragments {
+		fmt.Fprintf(&buf, "#EXTINF:%.3f,\n", index.Seconds(f.Duration))
+		fmt.Fprintf(&buf, "%s\n", segmentUrl(file, h.GetName(), fmt.Sprintf("%d", i)))
+	}
+	buf.WriteString("#EXT-X-ENDLIST\n")
+	return buf.Bytes()
+}
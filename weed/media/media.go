@@ -0,0 +1,81 @@
+package media
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/karlseguin/ccache"
+)
+
+// MediaHandler serves a stored media file in a streaming friendly way,
+// selected by the "media" query parameter on the filer read path, e.g. /videos/a.mp4?media=hls
+type MediaHandler interface {
+	// GetName gets the value of the "media" query parameter to select the handler
+	GetName() string
+	Serve(w http.ResponseWriter, r *http.Request, file *File) error
+}
+
+var (
+	MediaHandlers []MediaHandler
+
+	indexCache = ccache.New(ccache.Configure().MaxSize(1000).ItemsToPrune(100))
+)
+
+// File is a stored file to stream from, read by byte ranges
+type File struct {
+	// Name is the base name of the file, the segments are referenced relative to it
+	Name string
+	// Key identifies the file content, to cache the parsed index
+	Key  string
+	Size int64
+	io.ReaderAt
+}
+
+func GetMediaHandler(name string) MediaHandler {
+	for _, handler := range MediaHandlers {
+		if handler.GetName() == name {
+			return handler
+		}
+	}
+	return nil
+}
+
+// LoadFragmentIndex parses the fragmented mp4 file, or gets the cached index
+func LoadFragmentIndex(file *File) (*FragmentIndex, error) {
+	if item := indexCache.Get(file.Key); item != nil && !item.Expired() {
+		return item.Value().(*FragmentIndex), nil
+	}
+	index, err := ParseFragmentedMp4(file, file.Size)
+	if err != nil {
+		return nil, err
+	}
+	indexCache.Set(file.Key, index, time.Hour)
+	return index, nil
+}
+
+// serveSegment writes the init segment for "init", or the n-th media fragment
+func serveSegment(w http.ResponseWriter, file *File, index *FragmentIndex, segment string) error {
+	var offset, size int64
+	if segment == "init" {
+		offset, size = 0, index.InitSize
+	} else {
+		n, err := strconv.Atoi(segment)
+		if err != nil || n < 0 || n >= len(index.Fragments) {
+			return fmt.Errorf("segment %s not found in %s", segment, file.Name)
+		}
+		offset, size = index.Fragments[n].Offset, index.Fragments[n].Size
+	}
+	w.Header().Set("Content-Type", "video/mp4")
+	w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	w.WriteHeader(http.StatusOK)
+	_, err := io.Copy(w, io.NewSectionReader(file, offset, size))
+	return err
+}
+
+func segmentUrl(file *File, handlerName string, segment string) string {
+	return url.PathEscape(file.Name) + "?media=" + handlerName + "&segment=" + segment
+}
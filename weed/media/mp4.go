@@ -0,0 +1,323 @@
+package media
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const maxMoovSize = 16 * 1024 * 1024
+
+var ErrNotFragmented = errors.New("not a fragmented mp4 file")
+
+// FragmentIndex locates the init segment and the media fragments of a fragmented mp4 file.
+// The init segment is everything up to the end of the moov box, i.e., ftyp and moov.
+// Each fragment starts at its moof box, or the styp box before it, and ends after its mdat boxes.
+type FragmentIndex struct {
+	InitSize  int64
+	TrackId   uint32
+	Timescale uint32
+	Fragments []*Fragment
+}
+
+type Fragment struct {
+	Offset   int64
+	Size     int64
+	Duration uint64 // in the track timescale
+}
+
+func (index *FragmentIndex) Seconds(duration uint64) float64 {
+	if index.Timescale == 0 {
+		return 0
+	}
+	return float64(duration) / float64(index.Timescale)
+}
+
+func (index *FragmentIndex) TotalDuration() (total uint64) {
+	for _, f := range index.Fragments {
+		total += f.Duration
+	}
+	return
+}
+
+type boxHeader struct {
+	boxType    string
+	offset     int64
+	headerSize int64
+	size       int64
+}
+
+// readBoxHeader reads the box header at offset, the size 0 box extends to the end
+func readBoxHeader(r io.ReaderAt, offset, end int64) (h boxHeader, err error) {
+	buf := make([]byte, 16)
+	if _, err = r.ReadAt(buf[:8], offset); err != nil {
+		return
+	}
+	h.offset = offset
+	h.boxType = string(buf[4:8])
+	h.size = int64(binary.BigEndian.Uint32(buf[:4]))
+	h.headerSize = 8
+	switch h.size {
+	case 0:
+		h.size = end - offset
+	case 1:
+		if _, err = r.ReadAt(buf[8:16], offset+8); err != nil {
+			return
+		}
+		h.size = int64(binary.BigEndian.Uint64(buf[8:16]))
+		h.headerSize = 16
+	}
+	if h.size < h.headerSize || offset+h.size > end {
+		err = fmt.Errorf("invalid %s box size %d at %d", h.boxType, h.size, offset)
+	}
+	return
+}
+
+// ParseFragmentedMp4 walks the top level boxes, and reads the moov and moof boxes for the fragment durations
+func ParseFragmentedMp4(r io.ReaderAt, fileSize int64) (*FragmentIndex, error) {
+
+	index := &FragmentIndex{}
+	var track *mp4Track
+	var current *Fragment
+	fragmentStart := int64(-1)
+
+	for offset := int64(0); offset < fileSize; {
+		h, err := readBoxHeader(r, offset, fileSize)
+		if err != nil {
+			return nil, err
+		}
+		if offset == 0 && h.boxType != "ftyp" {
+			return nil, fmt.Errorf("not a mp4 file, starting with %s box", h.boxType)
+		}
+
+		switch h.boxType {
+		case "moov":
+			if h.size > maxMoovSize {
+				return nil, fmt.Errorf("moov box size %d exceeds %d", h.size, maxMoovSize)
+			}
+			moov, err := readBoxBody(r, h)
+			if err != nil {
+				return nil, err
+			}
+			if track = pickTrack(parseMoov(moov)); track == nil {
+				return nil, errors.New("no track in the moov box")
+			}
+			index.InitSize = h.offset + h.size
+			index.TrackId, index.Timescale = track.id, track.timescale
+		case "styp":
+			fragmentStart = h.offset
+		case "moof":
+			if track == nil {
+				return nil, fmt.Errorf("moof box at %d before the moov box", h.offset)
+			}
+			if fragmentStart < 0 {
+				fragmentStart = h.offset
+			}
+			moof, err := readBoxBody(r, h)
+			if err != nil {
+				return nil, err
+			}
+			current = &Fragment{
+				Offset:   fragmentStart,
+				Size:     h.offset + h.size - fragmentStart,
+				Duration: parseMoofDuration(moof, track),
+			}
+			index.Fragments = append(index.Fragments, current)
+			fragmentStart = -1
+		case "mdat":
+			if current != nil {
+				current.Size = h.offset + h.size - current.Offset
+			}
+		}
+
+		offset += h.size
+	}
+
+	if index.InitSize == 0 {
+		return nil, errors.New("no moov box")
+	}
+	if len(index.Fragments) == 0 {
+		return nil, ErrNotFragmented
+	}
+	return index, nil
+}
+
+func readBoxBody(r io.ReaderAt, h boxHeader) ([]byte, error) {
+	body := make([]byte, h.size-h.headerSize)
+	if _, err := r.ReadAt(body, h.offset+h.headerSize); err != nil {
+		return nil, fmt.Errorf("read %s box at %d: %v", h.boxType, h.offset, err)
+	}
+	return body, nil
+}
+
+// visitBoxes calls fn for each child box in the box body, stopping at any malformed box
+func visitBoxes(data []byte, fn func(boxType string, body []byte)) {
+	for len(data) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(data[:4]))
+		boxType := string(data[4:8])
+		headerSize := uint64(8)
+		if size == 1 {
+			if len(data) < 16 {
+				return
+			}
+			size = binary.BigEndian.Uint64(data[8:16])
+			headerSize = 16
+		} else if size == 0 {
+			size = uint64(len(data))
+		}
+		if size < headerSize || size > uint64(len(data)) {
+			return
+		}
+		fn(boxType, data[headerSize:size])
+		data = data[size:]
+	}
+}
+
+type mp4Track struct {
+	id                    uint32
+	handlerType           string
+	timescale             uint32
+	defaultSampleDuration uint32
+}
+
+func parseMoov(moov []byte) (tracks []*mp4Track) {
+	trexDurations := make(map[uint32]uint32)
+
+	visitBoxes(moov, func(boxType string, body []byte) {
+		switch boxType {
+		case "trak":
+			t := parseTrak(body)
+			if t.id != 0 {
+				tracks = append(tracks, t)
+			}
+		case "mvex":
+			visitBoxes(body, func(boxType string, body []byte) {
+				// trex: version and flags, track_ID, default_sample_description_index, default_sample_duration
+				if boxType == "trex" && len(body) >= 16 {
+					trexDurations[binary.BigEndian.Uint32(body[4:8])] = binary.BigEndian.Uint32(body[12:16])
+				}
+			})
+		}
+	})
+
+	for _, t := range tracks {
+		t.defaultSampleDuration = trexDurations[t.id]
+	}
+	return tracks
+}
+
+func parseTrak(trak []byte) *mp4Track {
+	t := &mp4Track{}
+	visitBoxes(trak, func(boxType string, body []byte) {
+		switch boxType {
+		case "tkhd":
+			// version 1 has 64 bits creation and modification times
+			if len(body) >= 24 && body[0] == 1 {
+				t.id = binary.BigEndian.Uint32(body[20:24])
+			} else if len(body) >= 16 {
+				t.id = binary.BigEndian.Uint32(body[12:16])
+			}
+		case "mdia":
+			visitBoxes(body, func(boxType string, body []byte) {
+				switch boxType {
+				case "mdhd":
+					if len(body) >= 24 && body[0] == 1 {
+						t.timescale = binary.BigEndian.Uint32(body[20:24])
+					} else if len(body) >= 16 {
+						t.timescale = binary.BigEndian.Uint32(body[12:16])
+					}
+				case "hdlr":
+					if len(body) >= 12 {
+						t.handlerType = string(body[8:12])
+					}
+				}
+			})
+		}
+	})
+	return t
+}
+
+// pickTrack uses the first video track to time the fragments, or the first track if there is no video
+func pickTrack(tracks []*mp4Track) *mp4Track {
+	for _, t := range tracks {
+		if t.handlerType == "vide" {
+			return t
+		}
+	}
+	if len(tracks) > 0 {
+		return tracks[0]
+	}
+	return nil
+}
+
+// parseMoofDuration sums the sample durations of the track in the moof box
+func parseMoofDuration(moof []byte, track *mp4Track) (duration uint64) {
+	visitBoxes(moof, func(boxType string, body []byte) {
+		if boxType != "traf" {
+			return
+		}
+		var tfhdTrackId uint32
+		defaultSampleDuration := track.defaultSampleDuration
+		visitBoxes(body, func(boxType string, body []byte) {
+			switch boxType {
+			case "tfhd":
+				tfhdTrackId, defaultSampleDuration = parseTfhd(body, defaultSampleDuration)
+			case "trun":
+				if tfhdTrackId == track.id {
+					duration += parseTrunDuration(body, defaultSampleDuration)
+				}
+			}
+		})
+	})
+	return
+}
+
+func parseTfhd(tfhd []byte, defaultSampleDuration uint32) (trackId uint32, sampleDuration uint32) {
+	sampleDuration = defaultSampleDuration
+	if len(tfhd) < 8 {
+		return
+	}
+	flags := binary.BigEndian.Uint32(tfhd[0:4]) & 0xffffff
+	trackId = binary.BigEndian.Uint32(tfhd[4:8])
+	pos := 8
+	if flags&0x01 != 0 { // base_data_offset
+		pos += 8
+	}
+	if flags&0x02 != 0 { // sample_description_index
+		pos += 4
+	}
+	if flags&0x08 != 0 && len(tfhd) >= pos+4 {
+		sampleDuration = binary.BigEndian.Uint32(tfhd[pos : pos+4])
+	}
+	return
+}
+
+func parseTrunDuration(trun []byte, defaultSampleDuration uint32) (duration uint64) {
+	if len(trun) < 8 {
+		return
+	}
+	flags := binary.BigEndian.Uint32(trun[0:4]) & 0xffffff
+	sampleCount := binary.BigEndian.Uint32(trun[4:8])
+	if flags&0x100 == 0 {
+		return uint64(sampleCount) * uint64(defaultSampleDuration)
+	}
+	pos := 8
+	if flags&0x01 != 0 { // data_offset
+		pos += 4
+	}
+	if flags&0x04 != 0 { // first_sample_flags
+		pos += 4
+	}
+	sampleSize := 4
+	for _, flag := range []uint32{0x200, 0x400, 0x800} {
+		if flags&flag != 0 {
+			sampleSize += 4
+		}
+	}
+	for i := uint32(0); i < sampleCount && len(trun) >= pos+4; i++ {
+		duration += uint64(binary.BigEndian.Uint32(trun[pos : pos+4]))
+		pos += sampleSize
+	}
+	return
+}
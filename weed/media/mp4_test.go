@@ -0,0 +1,128 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+func box(boxType string, payloads ...[]byte) []byte {
+	var body []byte
+	for _, p := range payloads {
+		body = append(body, p...)
+	}
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint32(header, uint32(8+len(body)))
+	copy(header[4:], boxType)
+	return append(header, body...)
+}
+
+func uint32s(values ...uint32) []byte {
+	data := make([]byte, 4*len(values))
+	for i, v := range values {
+		binary.BigEndian.PutUint32(data[4*i:], v)
+	}
+	return data
+}
+
+func testTrak(trackId uint32, handlerType string, timescale uint32) []byte {
+	return box("trak",
+		box("tkhd", uint32s(0, 0, 0, trackId, 0)),
+		box("mdia",
+			box("mdhd", uint32s(0, 0, 0, timescale, 0)),
+			box("hdlr", uint32s(0, 0), []byte(handlerType)),
+		),
+	)
+}
+
+// testFragment has a video traf with per sample durations, and an audio traf with the default duration
+func testFragment(sampleDurations ...uint32) []byte {
+	videoTrun := uint32s(0x000100, uint32(len(sampleDurations)))
+	videoTrun = append(videoTrun, uint32s(sampleDurations...)...)
+	return append(
+		box("moof",
+			box("mfhd", uint32s(0, 1)),
+			box("traf", box("tfhd", uint32s(0, 2)), box("trun", uint32s(0, 5))),
+			box("traf", box("tfhd", uint32s(0, 1)), box("trun", videoTrun)),
+		),
+		box("mdat", make([]byte, 100))...)
+}
+
+func TestParseFragmentedMp4(t *testing.T) {
+
+	ftyp := box("ftyp", []byte("iso6"), uint32s(0))
+	moov := box("moov",
+		testTrak(2, "soun", 48000),
+		testTrak(1, "vide", 90000),
+		box("mvex",
+			box("trex", uint32s(0, 1, 1, 3000, 0, 0)),
+			box("trex", uint32s(0, 2, 1, 1024, 0, 0)),
+		),
+	)
+	fragment1 := testFragment(90000, 90000)
+	fragment2 := append(box("styp", []byte("msdh"), uint32s(0)), testFragment(90000, 45000)...)
+
+	var data []byte
+	data = append(data, ftyp...)
+	data = append(data, moov...)
+	data = append(data, fragment1...)
+	data = append(data, fragment2...)
+
+	index, err := ParseFragmentedMp4(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	if index.InitSize != int64(len(ftyp)+len(moov)) {
+		t.Errorf("init size %d, expected %d", index.InitSize, len(ftyp)+len(moov))
+	}
+	if index.TrackId != 1 || index.Timescale != 90000 {
+		t.Errorf("track %d timescale %d, expected the video track", index.TrackId, index.Timescale)
+	}
+	if len(index.Fragments) != 2 {
+		t.Fatalf("fragments %d, expected 2", len(index.Fragments))
+	}
+	f1, f2 := index.Fragments[0], index.Fragments[1]
+	if f1.Offset != index.InitSize || f1.Size != int64(len(fragment1)) || f1.Duration != 180000 {
+		t.Errorf("fragment 1: %+v", f1)
+	}
+	if f2.Offset != f1.Offset+f1.Size || f2.Size != int64(len(fragment2)) || f2.Duration != 135000 {
+		t.Errorf("fragment 2: %+v", f2)
+	}
+
+	playlist := string((&HlsHandler{}).playlist(&File{Name: "a b.mp4"}, index))
+	for _, expected := range []string{
+		"#EXT-X-TARGETDURATION:2\n",
+		"#EXT-X-MAP:URI=\"a%20b.mp4?media=hls&segment=init\"\n",
+		"#EXTINF:2.000,\na%20b.mp4?media=hls&segment=0\n",
+		"#EXTINF:1.500,\na%20b.mp4?media=hls&segment=1\n",
+		"#EXT-X-ENDLIST\n",
+	} {
+		if !strings.Contains(playlist, expected) {
+			t.Errorf("playlist misses %q:\n%s", expected, playlist)
+		}
+	}
+
+	manifest, err := (&DashHandler{}).manifest(&File{Name: "a.mp4", Size: int64(len(data))}, index)
+	if err != nil {
+		t.Fatalf("manifest: %v", err)
+	}
+	for _, expected := range []string{
+		`mediaPresentationDuration="PT3.500S"`,
+		`<Initialization sourceURL="a.mp4?media=dash&amp;segment=init"></Initialization>`,
+		`<S d="135000"></S>`,
+		`<SegmentURL media="a.mp4?media=dash&amp;segment=1"></SegmentURL>`,
+	} {
+		if !strings.Contains(string(manifest), expected) {
+			t.Errorf("manifest misses %q:\n%s", expected, manifest)
+		}
+	}
+
+	progressive := append(append([]byte{}, ftyp...), moov...)
+	progressive = append(progressive, box("mdat", make([]byte, 100))...)
+	if _, err = ParseFragmentedMp4(bytes.NewReader(progressive), int64(len(progressive))); err != ErrNotFragmented {
+		t.Errorf("parse progressive mp4: %v", err)
+	}
+
+}
@@ -0,0 +1,919 @@
+package nfs
+
+import (
+	"context"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/webdav"
+)
+
+// The NFS v3 protocol of RFC 1813, over the webdav.FileSystem of the filer.
+// The attributes are stored by the filer, but the owners are reported as the calling user,
+// and only the truncation to zero is supported by SETATTR, which ignores the other attributes.
+
+// the largest READ and WRITE
+const maxData = 1024 * 1024
+
+const (
+	maxNameLength = 255
+	maxPathLength = 1024
+)
+
+// procedures
+const (
+	nfsProcNull        = 0
+	nfsProcGetAttr     = 1
+	nfsProcSetAttr     = 2
+	nfsProcLookup      = 3
+	nfsProcAccess      = 4
+	nfsProcReadLink    = 5
+	nfsProcRead        = 6
+	nfsProcWrite       = 7
+	nfsProcCreate      = 8
+	nfsProcMkdir       = 9
+	nfsProcSymlink     = 10
+	nfsProcMknod       = 11
+	nfsProcRemove      = 12
+	nfsProcRmdir       = 13
+	nfsProcRename      = 14
+	nfsProcLink        = 15
+	nfsProcReadDir     = 16
+	nfsProcReadDirPlus = 17
+	nfsProcFsStat      = 18
+	nfsProcFsInfo      = 19
+	nfsProcPathConf    = 20
+	nfsProcCommit      = 21
+)
+
+// nfsstat3
+const (
+	nfs3Ok             = 0
+	nfs3ErrNoEnt       = 2
+	nfs3ErrIo          = 5
+	nfs3ErrAcces       = 13
+	nfs3ErrExist       = 17
+	nfs3ErrNotDir      = 20
+	nfs3ErrIsDir       = 21
+	nfs3ErrInval       = 22
+	nfs3ErrRoFs        = 30
+	nfs3ErrNameTooLong = 63
+	nfs3ErrNotEmpty    = 66
+	nfs3ErrStale       = 70
+	nfs3ErrBadCookie   = 10003
+	nfs3ErrNotSupp     = 10004
+	nfs3ErrTooSmall    = 10005
+)
+
+// file types
+const (
+	nf3Reg = 1
+	nf3Dir = 2
+)
+
+// access bits
+const (
+	access3Read    = 0x0001
+	access3Lookup  = 0x0002
+	access3Modify  = 0x0004
+	access3Extend  = 0x0008
+	access3Delete  = 0x0010
+	access3Execute = 0x0020
+)
+
+// create modes
+const (
+	createUnchecked = 0
+	createGuarded   = 1
+	createExclusive = 2
+)
+
+// the stability of the writes, all of them are persisted to the filer before the replies
+const fileSync = 2
+
+// the encoded size of fattr3
+const fattr3Size = 84
+
+// the properties of FSINFO
+const fsf3Homogeneous = 0x0008
+
+// nfsRequest is one NFS call as the calling user
+type nfsRequest struct {
+	*call
+	server *Server
+	fs     webdav.FileSystem
+	ctx    context.Context
+}
+
+func (s *Server) dispatchNfs(c *call) *xdrEncoder {
+	r := &nfsRequest{
+		call:   c,
+		server: s,
+		fs:     s.fileSystem(c.uid, c.gid),
+		ctx:    context.Background(),
+	}
+	reply := acceptedReply(c.xid, rpcSuccess)
+
+	switch c.proc {
+	case nfsProcNull:
+	case nfsProcGetAttr:
+		r.getAttr(reply)
+	case nfsProcSetAttr:
+		r.setAttr(reply)
+	case nfsProcLookup:
+		r.lookup(reply)
+	case nfsProcAccess:
+		r.access(reply)
+	case nfsProcRead:
+		r.read(reply)
+	case nfsProcWrite:
+		r.write(reply)
+	case nfsProcCreate:
+		r.create(reply)
+	case nfsProcMkdir:
+		r.mkdir(reply)
+	case nfsProcRemove:
+		r.remove(reply, false)
+	case nfsProcRmdir:
+		r.remove(reply, true)
+	case nfsProcRename:
+		r.rename(reply)
+	case nfsProcReadDir:
+		r.readDir(reply, false)
+	case nfsProcReadDirPlus:
+		r.readDir(reply, true)
+	case nfsProcFsStat:
+		r.fsStat(reply)
+	case nfsProcFsInfo:
+		r.fsInfo(reply)
+	case nfsProcPathConf:
+		r.pathConf(reply)
+	case nfsProcCommit:
+		r.commit(reply)
+	case nfsProcReadLink:
+		// no symbolic links, with the post_op_attr of the failure
+		reply.uint32(nfs3ErrNotSupp)
+		reply.bool(false)
+	case nfsProcSymlink, nfsProcMknod:
+		// no symbolic links or special files, with the empty wcc_data of the failure
+		reply.uint32(nfs3ErrNotSupp)
+		reply.bool(false)
+		reply.bool(false)
+	case nfsProcLink:
+		// no hard links, with the post_op_attr and the empty wcc_data of the failure
+		reply.uint32(nfs3ErrNotSupp)
+		reply.bool(false)
+		reply.bool(false)
+		reply.bool(false)
+	default:
+		return acceptedReply(c.xid, rpcProcUnavail)
+	}
+	return reply
+}
+
+// handle reads the file handle argument, with its full path
+func (r *nfsRequest) handle() (fullPath string, status uint32) {
+	handle := r.args.opaque(maxHandleSize)
+	if r.args.err != nil {
+		return "", nfs3ErrInval
+	}
+	fullPath, found := r.server.handles.toPath(handle, r.listNames)
+	if !found {
+		return "", nfs3ErrStale
+	}
+	return fullPath, nfs3Ok
+}
+
+// dirOpArgs reads the directory handle and the name in the directory
+func (r *nfsRequest) dirOpArgs() (dirPath, name string, status uint32) {
+	dirPath, status = r.handle()
+	name = r.args.string(maxPathLength)
+	return
+}
+
+// childPath is the full path of the name in the directory, which can not be "." or ".."
+func (r *nfsRequest) childPath(dirPath, name string) (string, uint32) {
+	switch {
+	case len(name) > maxNameLength:
+		return "", nfs3ErrNameTooLong
+	case name == "" || name == "." || name == ".." || strings.ContainsAny(name, "/\x00"):
+		return "", nfs3ErrInval
+	}
+	return path.Join(dirPath, name), nfs3Ok
+}
+
+func (r *nfsRequest) stat(fullPath string) (os.FileInfo, uint32) {
+	fi, err := r.fs.Stat(r.ctx, fullPath)
+	if err != nil {
+		return nil, statusOf(err)
+	}
+	return fi, nfs3Ok
+}
+
+func statusOf(err error) uint32 {
+	switch {
+	case err == nil:
+		return nfs3Ok
+	case os.IsNotExist(err):
+		return nfs3ErrNoEnt
+	case os.IsExist(err):
+		return nfs3ErrExist
+	case os.IsPermission(err):
+		return nfs3ErrAcces
+	}
+	return nfs3ErrIo
+}
+
+// putAttr encodes the fattr3
+func (r *nfsRequest) putAttr(e *xdrEncoder, fullPath string, fi os.FileInfo) {
+	fileType, mode, nlink, size := uint32(nf3Reg), uint32(fi.Mode().Perm()), uint32(1), uint64(fi.Size())
+	if fi.IsDir() {
+		fileType, nlink, size = nf3Dir, 2, 4096
+		if mode == 0 {
+			mode = 0755
+		}
+	} else if mode == 0 {
+		mode = 0644
+	}
+	e.uint32(fileType)
+	e.uint32(mode)
+	e.uint32(nlink)
+	e.uint32(r.uid)
+	e.uint32(r.gid)
+	e.uint64(size)
+	e.uint64((size + 4095) &^ 4095)
+	// rdev
+	e.uint32(0)
+	e.uint32(0)
+	// fsid
+	e.uint64(fileId(r.server.root))
+	e.uint64(fileId(fullPath))
+	mtime := fi.ModTime()
+	putTime(e, mtime)
+	putTime(e, mtime)
+	putTime(e, mtime)
+}
+
+func putTime(e *xdrEncoder, t time.Time) {
+	e.uint32(uint32(t.Unix()))
+	e.uint32(uint32(t.Nanosecond()))
+}
+
+// putPostOpAttr encodes the post_op_attr of the path, which is empty if not found
+func (r *nfsRequest) putPostOpAttr(e *xdrEncoder, fullPath string) {
+	if fullPath == "" {
+		e.bool(false)
+		return
+	}
+	fi, err := r.fs.Stat(r.ctx, fullPath)
+	if err != nil {
+		e.bool(false)
+		return
+	}
+	e.bool(true)
+	r.putAttr(e, fullPath, fi)
+}
+
+// putWccData encodes the wcc_data without the attributes before the operation
+func (r *nfsRequest) putWccData(e *xdrEncoder, fullPath string) {
+	e.bool(false)
+	r.putPostOpAttr(e, fullPath)
+}
+
+// sattr3 is the attributes to set, nil if not set
+type sattr3 struct {
+	mode *uint32
+	size *uint64
+}
+
+func (r *nfsRequest) sattr() (attr sattr3) {
+	d := r.args
+	if d.bool() {
+		mode := d.uint32()
+		attr.mode = &mode
+	}
+	// the owners are not changed
+	if d.bool() {
+		d.uint32()
+	}
+	if d.bool() {
+		d.uint32()
+	}
+	if d.bool() {
+		size := d.uint64()
+		attr.size = &size
+	}
+	// the times are not changed
+	for i := 0; i < 2; i++ {
+		if how := d.uint32(); how == 2 {
+			d.uint32()
+			d.uint32()
+		}
+	}
+	return
+}
+
+func (r *nfsRequest) getAttr(reply *xdrEncoder) {
+	fullPath, status := r.handle()
+	if r.args.err != nil {
+		return
+	}
+	var fi os.FileInfo
+	if status == nfs3Ok {
+		fi, status = r.stat(fullPath)
+	}
+	reply.uint32(status)
+	if status == nfs3Ok {
+		r.putAttr(reply, fullPath, fi)
+	}
+}
+
+func (r *nfsRequest) setAttr(reply *xdrEncoder) {
+	fullPath, status := r.handle()
+	attr := r.sattr()
+	if r.args.bool() {
+		// the ctime guard
+		r.args.uint32()
+		r.args.uint32()
+	}
+	if r.args.err != nil {
+		return
+	}
+
+	var fi os.FileInfo
+	if status == nfs3Ok {
+		fi, status = r.stat(fullPath)
+	}
+	if status == nfs3Ok && attr.size != nil && *attr.size != uint64(fi.Size()) {
+		switch {
+		case r.server.option.ReadOnly:
+			status = nfs3ErrRoFs
+		case fi.IsDir():
+			status = nfs3ErrIsDir
+		case *attr.size != 0:
+			status = nfs3ErrNotSupp
+		default:
+			status = r.truncate(fullPath, fi.Mode().Perm())
+		}
+	}
+	reply.uint32(status)
+	r.putWccData(reply, fullPath)
+}
+
+func (r *nfsRequest) truncate(fullPath string, perm os.FileMode) uint32 {
+	f, err := r.fs.OpenFile(r.ctx, fullPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return statusOf(err)
+	}
+	f.Close()
+	return nfs3Ok
+}
+
+func (r *nfsRequest) lookup(reply *xdrEncoder) {
+	dirPath, name, status := r.dirOpArgs()
+	if r.args.err != nil {
+		return
+	}
+
+	var fullPath string
+	var fi os.FileInfo
+	if status == nfs3Ok {
+		switch name {
+		case ".":
+			fullPath = dirPath
+		case "..":
+			// not going above the exported directory
+			if fullPath = path.Dir(dirPath); !isUnder(fullPath, r.server.root) {
+				fullPath = r.server.root
+			}
+		default:
+			fullPath, status = r.childPath(dirPath, name)
+		}
+	}
+	if status == nfs3Ok {
+		fi, status = r.stat(fullPath)
+	}
+
+	reply.uint32(status)
+	if status != nfs3Ok {
+		r.putPostOpAttr(reply, dirPath)
+		return
+	}
+	reply.opaque(r.server.handles.toHandle(fullPath))
+	reply.bool(true)
+	r.putAttr(reply, fullPath, fi)
+	r.putPostOpAttr(reply, dirPath)
+}
+
+func (r *nfsRequest) access(reply *xdrEncoder) {
+	fullPath, status := r.handle()
+	requested := r.args.uint32()
+	if r.args.err != nil {
+		return
+	}
+	var fi os.FileInfo
+	if status == nfs3Ok {
+		fi, status = r.stat(fullPath)
+	}
+	reply.uint32(status)
+	if status != nfs3Ok {
+		reply.bool(false)
+		return
+	}
+	reply.bool(true)
+	r.putAttr(reply, fullPath, fi)
+
+	// the permissions are checked by the filer
+	allowed := uint32(access3Read | access3Lookup | access3Modify | access3Extend | access3Delete | access3Execute)
+	if r.server.option.ReadOnly {
+		allowed &^= access3Modify | access3Extend | access3Delete
+	}
+	reply.uint32(requested & allowed)
+}
+
+func (r *nfsRequest) read(reply *xdrEncoder) {
+	fullPath, status := r.handle()
+	offset := r.args.uint64()
+	count := r.args.uint32()
+	if r.args.err != nil {
+		return
+	}
+	if count > maxData {
+		count = maxData
+	}
+
+	var fi os.FileInfo
+	if status == nfs3Ok {
+		fi, status = r.stat(fullPath)
+	}
+	if status == nfs3Ok && fi.IsDir() {
+		status = nfs3ErrIsDir
+	}
+	var data []byte
+	if status == nfs3Ok && offset < uint64(fi.Size()) {
+		data, status = r.readAt(fullPath, int64(offset), int(count))
+	}
+
+	reply.uint32(status)
+	if status != nfs3Ok {
+		r.putPostOpAttr(reply, fullPath)
+		return
+	}
+	reply.bool(true)
+	r.putAttr(reply, fullPath, fi)
+	reply.uint32(uint32(len(data)))
+	reply.bool(offset+uint64(len(data)) >= uint64(fi.Size()))
+	reply.opaque(data)
+}
+
+func (r *nfsRequest) readAt(fullPath string, offset int64, count int) ([]byte, uint32) {
+	f, err := r.fs.OpenFile(r.ctx, fullPath, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, statusOf(err)
+	}
+	defer f.Close()
+	if _, err = f.Seek(offset, 0); err != nil {
+		return nil, statusOf(err)
+	}
+	data := make([]byte, count)
+	n := 0
+	for n < count {
+		read, err := f.Read(data[n:])
+		n += read
+		if err != nil || read == 0 {
+			break
+		}
+	}
+	return data[:n], nfs3Ok
+}
+
+func (r *nfsRequest) write(reply *xdrEncoder) {
+	fullPath, status := r.handle()
+	offset := r.args.uint64()
+	r.args.uint32() // count, the same as the data length
+	r.args.uint32() // stable
+	data := r.args.opaque(maxData)
+	if r.args.err != nil {
+		return
+	}
+
+	var fi os.FileInfo
+	if status == nfs3Ok {
+		fi, status = r.stat(fullPath)
+	}
+	switch {
+	case status != nfs3Ok:
+	case r.server.option.ReadOnly:
+		status = nfs3ErrRoFs
+	case fi.IsDir():
+		status = nfs3ErrIsDir
+	case len(data) > 0:
+		status = r.writeAt(fullPath, int64(offset), data)
+	}
+
+	reply.uint32(status)
+	r.putWccData(reply, fullPath)
+	if status != nfs3Ok {
+		return
+	}
+	reply.uint32(uint32(len(data)))
+	reply.uint32(fileSync)
+	reply.fixedOpaque(r.server.writeVerf[:])
+}
+
+func (r *nfsRequest) writeAt(fullPath string, offset int64, data []byte) uint32 {
+	f, err := r.fs.OpenFile(r.ctx, fullPath, os.O_RDWR, 0)
+	if err != nil {
+		return statusOf(err)
+	}
+	defer f.Close()
+	if _, err = f.Seek(offset, 0); err != nil {
+		return statusOf(err)
+	}
+	if _, err = f.Write(data); err != nil {
+		return statusOf(err)
+	}
+	return nfs3Ok
+}
+
+func (r *nfsRequest) create(reply *xdrEncoder) {
+	dirPath, name, status := r.dirOpArgs()
+	mode := r.args.uint32()
+	var attr sattr3
+	if mode == createExclusive {
+		r.args.fixedOpaque(8)
+	} else {
+		attr = r.sattr()
+	}
+	if r.args.err != nil {
+		return
+	}
+
+	var fullPath string
+	if status == nfs3Ok {
+		fullPath, status = r.childPath(dirPath, name)
+	}
+	if status == nfs3Ok && r.server.option.ReadOnly {
+		status = nfs3ErrRoFs
+	}
+	if status == nfs3Ok {
+		perm := os.FileMode(0644)
+		if attr.mode != nil {
+			perm = os.FileMode(*attr.mode) & os.ModePerm
+		}
+		fi, err := r.fs.Stat(r.ctx, fullPath)
+		switch {
+		case err != nil:
+			status = r.createFile(fullPath, perm)
+		case mode != createUnchecked:
+			status = nfs3ErrExist
+		case fi.IsDir():
+			status = nfs3ErrIsDir
+		case attr.size != nil && *attr.size == 0 && fi.Size() != 0:
+			status = r.truncate(fullPath, fi.Mode().Perm())
+		}
+	}
+
+	r.putCreated(reply, status, dirPath, fullPath)
+}
+
+func (r *nfsRequest) createFile(fullPath string, perm os.FileMode) uint32 {
+	f, err := r.fs.OpenFile(r.ctx, fullPath, os.O_RDWR|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return statusOf(err)
+	}
+	f.Close()
+	return nfs3Ok
+}
+
+func (r *nfsRequest) mkdir(reply *xdrEncoder) {
+	dirPath, name, status := r.dirOpArgs()
+	attr := r.sattr()
+	if r.args.err != nil {
+		return
+	}
+
+	var fullPath string
+	if status == nfs3Ok {
+		fullPath, status = r.childPath(dirPath, name)
+	}
+	if status == nfs3Ok && r.server.option.ReadOnly {
+		status = nfs3ErrRoFs
+	}
+	if status == nfs3Ok {
+		perm := os.FileMode(0755)
+		if attr.mode != nil {
+			perm = os.FileMode(*attr.mode) & os.ModePerm
+		}
+		if _, err := r.fs.Stat(r.ctx, fullPath); err == nil {
+			status = nfs3ErrExist
+		} else {
+			status = statusOf(r.fs.Mkdir(r.ctx, fullPath, perm))
+		}
+	}
+
+	r.putCreated(reply, status, dirPath, fullPath)
+}
+
+// putCreated encodes the result of CREATE or MKDIR
+func (r *nfsRequest) putCreated(reply *xdrEncoder, status uint32, dirPath, fullPath string) {
+	reply.uint32(status)
+	if status == nfs3Ok {
+		reply.bool(true)
+		reply.opaque(r.server.handles.toHandle(fullPath))
+		r.putPostOpAttr(reply, fullPath)
+	}
+	r.putWccData(reply, dirPath)
+}
+
+func (r *nfsRequest) remove(reply *xdrEncoder, isRmdir bool) {
+	dirPath, name, status := r.dirOpArgs()
+	if r.args.err != nil {
+		return
+	}
+
+	var fullPath string
+	if status == nfs3Ok {
+		fullPath, status = r.childPath(dirPath, name)
+	}
+	var fi os.FileInfo
+	if status == nfs3Ok {
+		fi, status = r.stat(fullPath)
+	}
+	switch {
+	case status != nfs3Ok:
+	case r.server.option.ReadOnly:
+		status = nfs3ErrRoFs
+	case isRmdir && !fi.IsDir():
+		status = nfs3ErrNotDir
+	case !isRmdir && fi.IsDir():
+		status = nfs3ErrIsDir
+	case isRmdir && !r.isEmptyDir(fullPath):
+		status = nfs3ErrNotEmpty
+	default:
+		status = statusOf(r.fs.RemoveAll(r.ctx, fullPath))
+	}
+
+	reply.uint32(status)
+	r.putWccData(reply, dirPath)
+}
+
+func (r *nfsRequest) isEmptyDir(fullPath string) bool {
+	entries, status := r.listDir(fullPath)
+	return status == nfs3Ok && len(entries) == 0
+}
+
+func (r *nfsRequest) rename(reply *xdrEncoder) {
+	fromDir, fromName, status := r.dirOpArgs()
+	toDir, toName, toStatus := r.dirOpArgs()
+	if r.args.err != nil {
+		return
+	}
+
+	var fromPath, toPath string
+	if status == nfs3Ok {
+		status = toStatus
+	}
+	if status == nfs3Ok {
+		fromPath, status = r.childPath(fromDir, fromName)
+	}
+	if status == nfs3Ok {
+		toPath, status = r.childPath(toDir, toName)
+	}
+	if status == nfs3Ok && r.server.option.ReadOnly {
+		status = nfs3ErrRoFs
+	}
+	if status == nfs3Ok && fromPath != toPath {
+		status = r.renameEntry(fromPath, toPath)
+	}
+
+	reply.uint32(status)
+	r.putWccData(reply, fromDir)
+	r.putWccData(reply, toDir)
+}
+
+// renameEntry replaces the existing target, which should be a file for a file, or an empty directory for a directory
+func (r *nfsRequest) renameEntry(fromPath, toPath string) uint32 {
+	from, status := r.stat(fromPath)
+	if status != nfs3Ok {
+		return status
+	}
+	if from.IsDir() && strings.HasPrefix(toPath, fromPath+"/") {
+		return nfs3ErrInval
+	}
+	if to, err := r.fs.Stat(r.ctx, toPath); err == nil {
+		switch {
+		case from.IsDir() && !to.IsDir():
+			return nfs3ErrNotDir
+		case !from.IsDir() && to.IsDir():
+			return nfs3ErrIsDir
+		case to.IsDir() && !r.isEmptyDir(toPath):
+			return nfs3ErrNotEmpty
+		}
+		if err = r.fs.RemoveAll(r.ctx, toPath); err != nil {
+			return statusOf(err)
+		}
+	}
+	return statusOf(r.fs.Rename(r.ctx, fromPath, toPath))
+}
+
+// listDir lists the directory, sorted by the names
+func (r *nfsRequest) listDir(fullPath string) ([]os.FileInfo, uint32) {
+	f, err := r.fs.OpenFile(r.ctx, fullPath, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, statusOf(err)
+	}
+	defer f.Close()
+	entries, err := f.Readdir(-1)
+	if err != nil {
+		return nil, statusOf(err)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries, nfs3Ok
+}
+
+// listNames lists the names in the directory, to find the paths of the hashed handles
+func (r *nfsRequest) listNames(dirPath string) (names []string) {
+	entries, _ := r.listDir(dirPath)
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return
+}
+
+// readDir lists the directory from the cookie, which is the position in the sorted listing.
+// READDIRPLUS also replies the attributes and the handles of the entries.
+func (r *nfsRequest) readDir(reply *xdrEncoder, isPlus bool) {
+	dirPath, status := r.handle()
+	cookie := r.args.uint64()
+	r.args.fixedOpaque(8) // cookie verifier
+	if isPlus {
+		r.args.uint32() // dircount
+	}
+	maxCount := int(r.args.uint32())
+	if r.args.err != nil {
+		return
+	}
+	if maxCount > maxData {
+		maxCount = maxData
+	}
+
+	var dir os.FileInfo
+	var entries []os.FileInfo
+	if status == nfs3Ok {
+		dir, status = r.stat(dirPath)
+	}
+	if status == nfs3Ok && !dir.IsDir() {
+		status = nfs3ErrNotDir
+	}
+	if status == nfs3Ok {
+		entries, status = r.listDir(dirPath)
+	}
+	if status == nfs3Ok && cookie > uint64(len(entries)) {
+		status = nfs3ErrBadCookie
+	}
+
+	var listing xdrEncoder
+	eof := true
+	if status == nfs3Ok {
+		// without the status, the directory attributes and the cookie verifier before the entries,
+		// and the end of the list with the eof flag after them
+		limit := maxCount - 4 - (4 + fattr3Size) - 8 - 8
+		for i := int(cookie); i < len(entries); i++ {
+			var entry xdrEncoder
+			name := strings.TrimSuffix(entries[i].Name(), "/")
+			fullPath := path.Join(dirPath, name)
+			entry.bool(true)
+			entry.uint64(fileId(fullPath))
+			entry.string(name)
+			entry.uint64(uint64(i + 1))
+			if isPlus {
+				entry.bool(true)
+				r.putAttr(&entry, fullPath, entries[i])
+				entry.bool(true)
+				entry.opaque(r.server.handles.toHandle(fullPath))
+			}
+			if len(listing.b)+len(entry.b) > limit {
+				eof = false
+				break
+			}
+			listing.b = append(listing.b, entry.b...)
+		}
+		if !eof && len(listing.b) == 0 {
+			status = nfs3ErrTooSmall
+		}
+	}
+
+	reply.uint32(status)
+	if status != nfs3Ok {
+		r.putPostOpAttr(reply, dirPath)
+		return
+	}
+	reply.bool(true)
+	r.putAttr(reply, dirPath, dir)
+	reply.fixedOpaque(make([]byte, 8))
+	reply.b = append(reply.b, listing.b...)
+	reply.bool(false)
+	reply.bool(eof)
+}
+
+func (r *nfsRequest) fsStat(reply *xdrEncoder) {
+	fullPath, status := r.handle()
+	if r.args.err != nil {
+		return
+	}
+	var fi os.FileInfo
+	if status == nfs3Ok {
+		fi, status = r.stat(fullPath)
+	}
+	reply.uint32(status)
+	if status != nfs3Ok {
+		reply.bool(false)
+		return
+	}
+	reply.bool(true)
+	r.putAttr(reply, fullPath, fi)
+	total, free := r.server.diskUsage()
+	reply.uint64(total)
+	reply.uint64(free)
+	reply.uint64(free)
+	// the number of files is not limited
+	reply.uint64(1 << 40)
+	reply.uint64(1 << 40)
+	reply.uint64(1 << 40)
+	reply.uint32(0)
+}
+
+func (r *nfsRequest) fsInfo(reply *xdrEncoder) {
+	fullPath, status := r.handle()
+	if r.args.err != nil {
+		return
+	}
+	var fi os.FileInfo
+	if status == nfs3Ok {
+		fi, status = r.stat(fullPath)
+	}
+	reply.uint32(status)
+	if status != nfs3Ok {
+		reply.bool(false)
+		return
+	}
+	reply.bool(true)
+	r.putAttr(reply, fullPath, fi)
+	// rtmax, rtpref, rtmult, wtmax, wtpref, wtmult, dtpref
+	for _, size := range []uint32{maxData, maxData, 4096, maxData, maxData, 4096, 64 * 1024} {
+		reply.uint32(size)
+	}
+	reply.uint64(1 << 62)
+	// the times are in seconds
+	reply.uint32(1)
+	reply.uint32(0)
+	reply.uint32(fsf3Homogeneous)
+}
+
+func (r *nfsRequest) pathConf(reply *xdrEncoder) {
+	fullPath, status := r.handle()
+	if r.args.err != nil {
+		return
+	}
+	var fi os.FileInfo
+	if status == nfs3Ok {
+		fi, status = r.stat(fullPath)
+	}
+	reply.uint32(status)
+	if status != nfs3Ok {
+		reply.bool(false)
+		return
+	}
+	reply.bool(true)
+	r.putAttr(reply, fullPath, fi)
+	reply.uint32(1)
+	reply.uint32(maxNameLength)
+	reply.bool(true)  // no_trunc
+	reply.bool(true)  // chown_restricted
+	reply.bool(false) // case_insensitive
+	reply.bool(true)  // case_preserving
+}
+
+// commit has nothing to flush, since all writes are persisted to the filer
+func (r *nfsRequest) commit(reply *xdrEncoder) {
+	fullPath, status := r.handle()
+	r.args.uint64()
+	r.args.uint32()
+	if r.args.err != nil {
+		return
+	}
+	if status == nfs3Ok {
+		_, status = r.stat(fullPath)
+	}
+	reply.uint32(status)
+	r.putWccData(reply, fullPath)
+	if status == nfs3Ok {
+		reply.fixedOpaque(r.server.writeVerf[:])
+	}
+}
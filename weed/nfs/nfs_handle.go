@@ -0,0 +1,167 @@
+package nfs
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"hash/fnv"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/karlseguin/ccache"
+)
+
+// the largest file handle of NFS v3
+const maxHandleSize = 64
+
+// the kinds of the file handles
+const (
+	// the handle is the full path, so it is still valid after the gateway restarts
+	handleByPath byte = 1
+	// the handle of a longer path has the hash of the path, the hashes of its last names, and the path of the rest,
+	// so the path can be found again by listing the directories after the gateway restarts
+	handleByHash byte = 2
+	// the handle of a path too deep for the hashes of the names, only known until the gateway restarts
+	handleByCache byte = 3
+)
+
+const (
+	pathHashSize = 8
+	nameHashSize = 2
+	// the paths of the hashed handles are cached, up to this many
+	maxCachedHandles = 64 * 1024
+	cachedHandleTtl  = 24 * time.Hour
+)
+
+// fileHandles maps the file handles to the full paths.
+// The handles are by the paths, so the handles of the renamed entries become stale.
+type fileHandles struct {
+	root   string
+	hashed *ccache.Cache
+}
+
+func newFileHandles(root string) *fileHandles {
+	return &fileHandles{
+		root:   root,
+		hashed: ccache.New(ccache.Configure().MaxSize(maxCachedHandles).ItemsToPrune(100)),
+	}
+}
+
+func (h *fileHandles) toHandle(fullPath string) []byte {
+	if len(fullPath) < maxHandleSize {
+		return append([]byte{handleByPath}, fullPath...)
+	}
+	handle := hashedHandle(fullPath)
+	h.hashed.Set(string(handle), fullPath, cachedHandleTtl)
+	return handle
+}
+
+// hashedHandle keeps as much of the path as fits, along with the hashes of the names below it
+func hashedHandle(fullPath string) []byte {
+	names := strings.Split(fullPath[1:], "/")
+	for kept := len(names) - 1; kept >= 0; kept-- {
+		prefix := "/" + strings.Join(names[:kept], "/")
+		below := names[kept:]
+		if 1+pathHashSize+1+nameHashSize*len(below)+len(prefix) > maxHandleSize {
+			continue
+		}
+		handle := append([]byte{handleByHash}, pathHash(fullPath)...)
+		handle = append(handle, byte(len(below)))
+		for _, name := range below {
+			handle = append(handle, nameHash(name)...)
+		}
+		return append(handle, prefix...)
+	}
+	sum := sha256.Sum256([]byte(fullPath))
+	return append([]byte{handleByCache}, sum[:]...)
+}
+
+// toPath finds the full path of the handle, which should be under the exported root.
+// The paths of the hashed handles not cached are found by listing the directories with listNames.
+func (h *fileHandles) toPath(handle []byte, listNames func(dirPath string) []string) (fullPath string, found bool) {
+	if len(handle) == 0 {
+		return "", false
+	}
+	switch handle[0] {
+	case handleByPath:
+		fullPath = string(handle[1:])
+	case handleByHash, handleByCache:
+		if item := h.hashed.Get(string(handle)); item != nil {
+			fullPath = item.Value().(string)
+			break
+		}
+		if handle[0] == handleByCache {
+			return "", false
+		}
+		if fullPath, found = h.findHashed(handle[1:], listNames); !found {
+			return "", false
+		}
+		h.hashed.Set(string(handle), fullPath, cachedHandleTtl)
+	default:
+		return "", false
+	}
+	if fullPath != path.Clean(fullPath) || !isUnder(fullPath, h.root) {
+		return "", false
+	}
+	return fullPath, true
+}
+
+// findHashed walks down from the path in the handle, into the names matching the hashes, until the path matches its hash
+func (h *fileHandles) findHashed(hashes []byte, listNames func(dirPath string) []string) (string, bool) {
+	if len(hashes) < pathHashSize+1 {
+		return "", false
+	}
+	fullHash, count, hashes := hashes[:pathHashSize], int(hashes[pathHashSize]), hashes[pathHashSize+1:]
+	if len(hashes) < count*nameHashSize {
+		return "", false
+	}
+	nameHashes, prefix := hashes[:count*nameHashSize], string(hashes[count*nameHashSize:])
+	if prefix != path.Clean(prefix) || !strings.HasPrefix(prefix, "/") {
+		return "", false
+	}
+
+	var walk func(dirPath string, nameHashes []byte) (string, bool)
+	walk = func(dirPath string, nameHashes []byte) (string, bool) {
+		// only the paths leading to the exported root or under it
+		if !isUnder(dirPath, h.root) && !isUnder(h.root, dirPath) {
+			return "", false
+		}
+		if len(nameHashes) == 0 {
+			return dirPath, bytes.Equal(pathHash(dirPath), fullHash)
+		}
+		for _, name := range listNames(dirPath) {
+			if bytes.Equal(nameHash(name), nameHashes[:nameHashSize]) {
+				if fullPath, found := walk(path.Join(dirPath, name), nameHashes[nameHashSize:]); found {
+					return fullPath, true
+				}
+			}
+		}
+		return "", false
+	}
+	return walk(prefix, nameHashes)
+}
+
+func pathHash(fullPath string) []byte {
+	sum := sha256.Sum256([]byte(fullPath))
+	return sum[:pathHashSize]
+}
+
+func nameHash(name string) []byte {
+	hash := fnv.New32a()
+	hash.Write([]byte(name))
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, hash.Sum32())
+	return b[:nameHashSize]
+}
+
+func isUnder(fullPath, root string) bool {
+	return root == "/" || fullPath == root || strings.HasPrefix(fullPath, root+"/")
+}
+
+// fileId is a stable number to identify a file
+func fileId(fullPath string) uint64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(fullPath))
+	return hash.Sum64()
+}
@@ -0,0 +1,80 @@
+package nfs
+
+import (
+	"context"
+	"path"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+)
+
+// the MOUNT v3 procedures of RFC 1813 appendix I
+const (
+	mountProcNull    = 0
+	mountProcMnt     = 1
+	mountProcDump    = 2
+	mountProcUmnt    = 3
+	mountProcUmntAll = 4
+	mountProcExport  = 5
+)
+
+// mount status
+const (
+	mnt3Ok        = 0
+	mnt3ErrNoEnt  = 2
+	mnt3ErrNotDir = 20
+)
+
+const maxMountPathLength = 1024
+
+func (s *Server) dispatchMount(c *call) *xdrEncoder {
+	switch c.proc {
+	case mountProcNull, mountProcUmnt, mountProcUmntAll:
+		// the mounts are not tracked
+		return acceptedReply(c.xid, rpcSuccess)
+	case mountProcMnt:
+		return s.handleMount(c)
+	case mountProcDump:
+		reply := acceptedReply(c.xid, rpcSuccess)
+		reply.bool(false)
+		return reply
+	case mountProcExport:
+		reply := acceptedReply(c.xid, rpcSuccess)
+		reply.bool(true)
+		reply.string("/")
+		// the allowed clients as the groups, or no groups if exported to all clients
+		for _, client := range s.option.WhiteList {
+			reply.bool(true)
+			reply.string(client)
+		}
+		reply.bool(false)
+		reply.bool(false)
+		return reply
+	}
+	return acceptedReply(c.xid, rpcProcUnavail)
+}
+
+// handleMount replies the file handle of the mounted directory, relative to the exported directory
+func (s *Server) handleMount(c *call) *xdrEncoder {
+	dirPath := c.args.string(maxMountPathLength)
+	if c.args.err != nil {
+		return nil
+	}
+	reply := acceptedReply(c.xid, rpcSuccess)
+
+	fullPath := path.Join(s.root, path.Clean("/"+dirPath))
+	fi, err := s.fileSystem(c.uid, c.gid).Stat(context.Background(), fullPath)
+	switch {
+	case err != nil:
+		glog.V(1).Infof("nfs mount %s: %v", fullPath, err)
+		reply.uint32(mnt3ErrNoEnt)
+	case !fi.IsDir():
+		reply.uint32(mnt3ErrNotDir)
+	default:
+		glog.V(1).Infof("nfs mount %s by uid %d", fullPath, c.uid)
+		reply.uint32(mnt3Ok)
+		reply.opaque(s.handles.toHandle(fullPath))
+		reply.uint32(1)
+		reply.uint32(authUnix)
+	}
+	return reply
+}
@@ -0,0 +1,192 @@
+package nfs
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/security"
+	"golang.org/x/net/webdav"
+)
+
+const (
+	mountProgram = 100005
+	mountVersion = 3
+	nfsProgram   = 100003
+	nfsVersion   = 3
+)
+
+type Option struct {
+	// the directory on the filer exported to the clients
+	Path     string
+	ReadOnly bool
+	// NewFileSystem creates the file system to access the files as the uid and gid of the client
+	NewFileSystem func(uid, gid uint32) webdav.FileSystem
+	// DiskUsage reports the total and the free space in bytes, optional
+	DiskUsage func() (total, free uint64, err error)
+	// WhiteList has the ip addresses or the CIDR ranges of the clients allowed to connect, all clients if empty
+	WhiteList []string
+}
+
+type Server struct {
+	option      *Option
+	root        string
+	handles     *fileHandles
+	writeVerf   [8]byte
+	fileSystems map[[2]uint32]webdav.FileSystem
+	fsLock      sync.Mutex
+
+	diskUsageLock    sync.Mutex
+	diskTotal        uint64
+	diskFree         uint64
+	diskUsageChecked time.Time
+}
+
+func NewServer(option *Option) *Server {
+	s := &Server{
+		option:      option,
+		root:        path.Clean("/" + option.Path),
+		fileSystems: make(map[[2]uint32]webdav.FileSystem),
+	}
+	s.handles = newFileHandles(s.root)
+	// the clients resend the unstable writes if the verifier changes, which is never necessary here
+	binary.BigEndian.PutUint64(s.writeVerf[:], uint64(time.Now().UnixNano()))
+	return s
+}
+
+func (s *Server) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		if !s.isAllowed(conn.RemoteAddr()) {
+			glog.V(0).Infof("nfs client %s is not allowed", conn.RemoteAddr())
+			conn.Close()
+			continue
+		}
+		go s.serveConnection(conn)
+	}
+}
+
+func (s *Server) isAllowed(addr net.Addr) bool {
+	if len(s.option.WhiteList) == 0 {
+		return true
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return false
+	}
+	return security.MatchWhiteList(s.option.WhiteList, host)
+}
+
+func (s *Server) fileSystem(uid, gid uint32) webdav.FileSystem {
+	s.fsLock.Lock()
+	defer s.fsLock.Unlock()
+	key := [2]uint32{uid, gid}
+	fs, found := s.fileSystems[key]
+	if !found {
+		fs = s.option.NewFileSystem(uid, gid)
+		s.fileSystems[key] = fs
+	}
+	return fs
+}
+
+func (s *Server) diskUsage() (total, free uint64) {
+	s.diskUsageLock.Lock()
+	defer s.diskUsageLock.Unlock()
+
+	if s.diskUsageChecked.Add(20 * time.Second).Before(time.Now()) {
+		s.diskTotal, s.diskFree = 1<<50, 1<<50
+		if s.option.DiskUsage != nil {
+			if total, free, err := s.option.DiskUsage(); err != nil {
+				glog.V(1).Infof("nfs disk usage: %v", err)
+			} else {
+				s.diskTotal, s.diskFree = total, free
+			}
+		}
+		s.diskUsageChecked = time.Now()
+	}
+	return s.diskTotal, s.diskFree
+}
+
+// serveConnection serves the calls from one client one by one
+func (s *Server) serveConnection(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	for {
+		record, err := readRecord(reader)
+		if err != nil {
+			if err != io.EOF {
+				glog.V(1).Infof("nfs read from %s: %v", conn.RemoteAddr(), err)
+			}
+			return
+		}
+
+		reply := s.handleCall(record)
+		if reply == nil {
+			continue
+		}
+
+		if err = writeRecord(conn, reply); err != nil {
+			glog.V(1).Infof("nfs write to %s: %v", conn.RemoteAddr(), err)
+			return
+		}
+	}
+}
+
+// call is one decoded call, with the arguments left in the decoder
+type call struct {
+	rpcCallHeader
+	args *xdrDecoder
+}
+
+func (s *Server) handleCall(record []byte) []byte {
+	d := &xdrDecoder{b: record}
+	h, rpcVersionOk := decodeCall(d)
+	if d.err != nil {
+		glog.V(1).Infof("nfs invalid call: %v", d.err)
+		return nil
+	}
+	if !rpcVersionOk {
+		return deniedReply(h.xid, rpcMismatch).b
+	}
+	if h.badCred {
+		return deniedReply(h.xid, rpcAuthError).b
+	}
+
+	c := &call{rpcCallHeader: h, args: d}
+	var reply *xdrEncoder
+	switch h.prog {
+	case mountProgram:
+		if h.vers != mountVersion {
+			return progMismatchReply(h.xid, mountVersion)
+		}
+		reply = s.dispatchMount(c)
+	case nfsProgram:
+		if h.vers != nfsVersion {
+			return progMismatchReply(h.xid, nfsVersion)
+		}
+		reply = s.dispatchNfs(c)
+	default:
+		return acceptedReply(h.xid, rpcProgUnavail).b
+	}
+
+	if c.args.err != nil {
+		return acceptedReply(h.xid, rpcGarbageArgs).b
+	}
+	return reply.b
+}
+
+func progMismatchReply(xid uint32, version uint32) []byte {
+	e := acceptedReply(xid, rpcProgMismatch)
+	e.uint32(version)
+	e.uint32(version)
+	return e.b
+}
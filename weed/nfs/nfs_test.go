@@ -0,0 +1,328 @@
+package nfs
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"testing"
+
+	"golang.org/x/net/webdav"
+)
+
+type testClient struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	xid    uint32
+}
+
+// call sends the call with the unix credential of uid 1000, and returns the reply after the accept status
+func (c *testClient) call(t *testing.T, prog, vers, proc uint32, args *xdrEncoder) *xdrDecoder {
+	c.xid++
+	e := &xdrEncoder{}
+	e.uint32(c.xid)
+	e.uint32(rpcCall)
+	e.uint32(2)
+	e.uint32(prog)
+	e.uint32(vers)
+	e.uint32(proc)
+	credential := &xdrEncoder{}
+	credential.uint32(0)
+	credential.string("client")
+	credential.uint32(1000)
+	credential.uint32(1000)
+	credential.uint32(0)
+	e.uint32(authUnix)
+	e.opaque(credential.b)
+	e.uint32(authNone)
+	e.opaque(nil)
+	if args != nil {
+		e.b = append(e.b, args.b...)
+	}
+	if err := writeRecord(c.conn, e.b); err != nil {
+		t.Fatal(err)
+	}
+	record, err := readRecord(c.reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &xdrDecoder{b: record}
+	if xid := d.uint32(); xid != c.xid {
+		t.Fatalf("xid %d, expected %d", xid, c.xid)
+	}
+	if d.uint32() != rpcReply || d.uint32() != rpcMsgAccepted {
+		t.Fatalf("call %d.%d not accepted", prog, proc)
+	}
+	d.uint32()
+	d.opaque(400)
+	if acceptStatus := d.uint32(); acceptStatus != rpcSuccess {
+		t.Fatalf("call %d.%d accept status %d", prog, proc, acceptStatus)
+	}
+	return d
+}
+
+func (c *testClient) nfs(t *testing.T, proc uint32, args *xdrEncoder) (status uint32, d *xdrDecoder) {
+	d = c.call(t, nfsProgram, nfsVersion, proc, args)
+	return d.uint32(), d
+}
+
+func dirOp(handle []byte, name string) *xdrEncoder {
+	e := &xdrEncoder{}
+	e.opaque(handle)
+	e.string(name)
+	return e
+}
+
+// skipAttr skips the fattr3 after the post_op_attr flag, returning the size and the file id
+func skipAttr(d *xdrDecoder) (size, fileId uint64) {
+	if !d.bool() {
+		return
+	}
+	d.fixedOpaque(20)
+	size = d.uint64()
+	d.fixedOpaque(24)
+	fileId = d.uint64()
+	d.fixedOpaque(24)
+	return
+}
+
+func TestServer(t *testing.T) {
+	fs := webdav.NewMemFS()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	server := NewServer(&Option{
+		Path: "/",
+		NewFileSystem: func(uid, gid uint32) webdav.FileSystem {
+			return fs
+		},
+	})
+	go server.Serve(listener)
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	c := &testClient{conn: conn, reader: bufio.NewReader(conn)}
+
+	// mount
+	args := &xdrEncoder{}
+	args.string("/")
+	d := c.call(t, mountProgram, mountVersion, mountProcMnt, args)
+	if status := d.uint32(); status != mnt3Ok {
+		t.Fatalf("mount status %d", status)
+	}
+	root := d.opaque(maxHandleSize)
+
+	// mkdir and create
+	args = dirOp(root, "docs")
+	args.b = append(args.b, make([]byte, 6*4)...)
+	if status, _ := c.nfs(t, nfsProcMkdir, args); status != nfs3Ok {
+		t.Fatalf("mkdir status %d", status)
+	}
+	args = dirOp(root, "docs")
+	status, d := c.nfs(t, nfsProcLookup, args)
+	if status != nfs3Ok {
+		t.Fatalf("lookup status %d", status)
+	}
+	docs := d.opaque(maxHandleSize)
+
+	args = dirOp(docs, "a.txt")
+	args.uint32(createGuarded)
+	args.b = append(args.b, make([]byte, 6*4)...)
+	status, d = c.nfs(t, nfsProcCreate, args)
+	if status != nfs3Ok || !d.bool() {
+		t.Fatalf("create status %d", status)
+	}
+	file := d.opaque(maxHandleSize)
+	if status, _ = c.nfs(t, nfsProcCreate, args); status != nfs3ErrExist {
+		t.Errorf("guarded create of the existing file: status %d", status)
+	}
+
+	// write and read
+	args = &xdrEncoder{}
+	args.opaque(file)
+	args.uint64(0)
+	args.uint32(11)
+	args.uint32(fileSync)
+	args.opaque([]byte("hello world"))
+	status, d = c.nfs(t, nfsProcWrite, args)
+	if status != nfs3Ok {
+		t.Fatalf("write status %d", status)
+	}
+	d.bool()
+	skipAttr(d)
+	if count := d.uint32(); count != 11 {
+		t.Errorf("written %d bytes", count)
+	}
+
+	args = &xdrEncoder{}
+	args.opaque(file)
+	args.uint64(6)
+	args.uint32(100)
+	status, d = c.nfs(t, nfsProcRead, args)
+	if status != nfs3Ok {
+		t.Fatalf("read status %d", status)
+	}
+	if size, _ := skipAttr(d); size != 11 {
+		t.Errorf("file size %d", size)
+	}
+	count, eof, data := d.uint32(), d.bool(), d.opaque(maxData)
+	if count != 5 || !eof || string(data) != "world" {
+		t.Errorf("read %d bytes %q, eof %v", count, data, eof)
+	}
+
+	// readdirplus
+	args = dirOp(docs, "b.txt")
+	args.uint32(createUnchecked)
+	args.b = append(args.b, make([]byte, 6*4)...)
+	if status, _ = c.nfs(t, nfsProcCreate, args); status != nfs3Ok {
+		t.Fatalf("create status %d", status)
+	}
+	var names []string
+	var handles [][]byte
+	for cookie, eof := uint64(0), false; !eof; {
+		args = &xdrEncoder{}
+		args.opaque(docs)
+		args.uint64(cookie)
+		args.fixedOpaque(make([]byte, 8))
+		args.uint32(4096)
+		// only one entry fits in each reply
+		args.uint32(4 + 4 + fattr3Size + 8 + 8 + 200)
+		status, d = c.nfs(t, nfsProcReadDirPlus, args)
+		if status != nfs3Ok {
+			t.Fatalf("readdirplus status %d", status)
+		}
+		skipAttr(d)
+		d.fixedOpaque(8)
+		for d.bool() {
+			d.uint64()
+			names = append(names, d.string(maxNameLength))
+			cookie = d.uint64()
+			skipAttr(d)
+			d.bool()
+			handles = append(handles, d.opaque(maxHandleSize))
+		}
+		eof = d.bool()
+		if d.err != nil {
+			t.Fatal(d.err)
+		}
+	}
+	if len(names) != 2 || names[0] != "a.txt" || names[1] != "b.txt" || !bytes.Equal(handles[0], file) {
+		t.Errorf("listed %v", names)
+	}
+
+	// rename over the existing file, and remove
+	args = dirOp(docs, "a.txt")
+	args.b = append(args.b, dirOp(docs, "b.txt").b...)
+	if status, _ = c.nfs(t, nfsProcRename, args); status != nfs3Ok {
+		t.Fatalf("rename status %d", status)
+	}
+	if status, _ = c.nfs(t, nfsProcRmdir, dirOp(root, "docs")); status != nfs3ErrNotEmpty {
+		t.Errorf("rmdir of the non-empty directory: status %d", status)
+	}
+	if status, _ = c.nfs(t, nfsProcRemove, dirOp(docs, "b.txt")); status != nfs3Ok {
+		t.Errorf("remove status %d", status)
+	}
+	if status, _ = c.nfs(t, nfsProcRmdir, dirOp(root, "docs")); status != nfs3Ok {
+		t.Errorf("rmdir status %d", status)
+	}
+	args = &xdrEncoder{}
+	args.opaque(file)
+	if status, _ = c.nfs(t, nfsProcGetAttr, args); status != nfs3ErrNoEnt {
+		t.Errorf("getattr of the removed file: status %d", status)
+	}
+
+	// the handles outside of the exported directory are stale
+	args = &xdrEncoder{}
+	args.opaque([]byte{handleByPath, '/', '.', '.'})
+	if status, _ = c.nfs(t, nfsProcGetAttr, args); status != nfs3ErrStale {
+		t.Errorf("getattr of the forged handle: status %d", status)
+	}
+}
+
+func TestFileHandles(t *testing.T) {
+	h := newFileHandles("/export")
+	long := "/export/" + string(bytes.Repeat([]byte("a"), 100))
+	for _, fullPath := range []string{"/export", "/export/a/b", long} {
+		if p, found := h.toPath(h.toHandle(fullPath), nil); !found || p != fullPath {
+			t.Errorf("handle of %s: %s %v", fullPath, p, found)
+		}
+	}
+	if len(h.toHandle(long)) > maxHandleSize {
+		t.Errorf("handle longer than %d bytes", maxHandleSize)
+	}
+	for _, fullPath := range []string{"/", "/exported", "/export/../etc"} {
+		if _, found := h.toPath(append([]byte{handleByPath}, fullPath...), nil); found {
+			t.Errorf("found the handle of %s", fullPath)
+		}
+	}
+}
+
+func TestHashedHandlesAfterRestart(t *testing.T) {
+	long := string(bytes.Repeat([]byte("a"), 40))
+	dirs := map[string][]string{
+		"/":                                  {"export", "other"},
+		"/export":                            {long, "b"},
+		"/export/" + long:                    {long + "b", long + "c"},
+		"/export/" + long + "/" + long + "c": {"file"},
+		"/other":                             {long},
+	}
+	listNames := func(dirPath string) []string {
+		return dirs[dirPath]
+	}
+
+	handles := make(map[string][]byte)
+	for _, fullPath := range []string{
+		"/export/" + long + "/" + long + "b",
+		"/export/" + long + "/" + long + "c/file",
+	} {
+		handle := newFileHandles("/export").toHandle(fullPath)
+		if len(handle) > maxHandleSize || handle[0] != handleByHash {
+			t.Fatalf("handle of %s: %v", fullPath, handle)
+		}
+		handles[fullPath] = handle
+	}
+
+	// a new gateway finds the paths of the handles by listing the directories
+	h := newFileHandles("/export")
+	for fullPath, handle := range handles {
+		if p, found := h.toPath(handle, listNames); !found || p != fullPath {
+			t.Errorf("handle of %s after restart: %s %v", fullPath, p, found)
+		}
+	}
+
+	// the handles of the paths removed or outside of the export are stale
+	removed := newFileHandles("/export").toHandle("/export/" + long + "/" + long + "d")
+	outside := newFileHandles("/").toHandle("/other/" + long + "/" + long)
+	for _, handle := range [][]byte{removed, outside} {
+		if p, found := h.toPath(handle, listNames); found {
+			t.Errorf("found the stale handle of %s", p)
+		}
+	}
+}
+
+func TestWhiteList(t *testing.T) {
+	for _, c := range []struct {
+		whiteList []string
+		addr      string
+		allowed   bool
+	}{
+		{nil, "10.0.0.1:700", true},
+		{[]string{"10.0.0.1"}, "10.0.0.1:700", true},
+		{[]string{"10.0.0.1"}, "10.0.0.2:700", false},
+		{[]string{"192.168.0.0/16", "10.0.0.1"}, "192.168.3.4:700", true},
+		{[]string{"192.168.0.0/16"}, "192.169.3.4:700", false},
+	} {
+		s := NewServer(&Option{Path: "/", WhiteList: c.whiteList})
+		addr, err := net.ResolveTCPAddr("tcp", c.addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if allowed := s.isAllowed(addr); allowed != c.allowed {
+			t.Errorf("%v allows %s: %v", c.whiteList, c.addr, allowed)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package nfs
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// The ONC RPC version 2 of RFC 5531, over TCP with the record marking.
+
+const (
+	rpcCall  = 0
+	rpcReply = 1
+
+	rpcMsgAccepted = 0
+	rpcMsgDenied   = 1
+
+	// accept status
+	rpcSuccess      = 0
+	rpcProgUnavail  = 1
+	rpcProgMismatch = 2
+	rpcProcUnavail  = 3
+	rpcGarbageArgs  = 4
+	rpcSystemErr    = 5
+
+	// reject status
+	rpcMismatch  = 0
+	rpcAuthError = 1
+
+	// auth status
+	rpcAuthBadCred = 1
+
+	authNone = 0
+	authUnix = 1
+
+	// the nobody user, for the calls without the unix credential
+	nobodyId = 65534
+)
+
+// the largest record accepted, with a full READ or WRITE
+const maxRecordSize = maxData + 4096
+
+// rpcCallHeader is the call with the unix credential
+type rpcCallHeader struct {
+	xid     uint32
+	prog    uint32
+	vers    uint32
+	proc    uint32
+	uid     uint32
+	gid     uint32
+	badCred bool
+}
+
+// readRecord reads the fragments of one record
+func readRecord(reader io.Reader) ([]byte, error) {
+	var record []byte
+	for {
+		var header [4]byte
+		if _, err := io.ReadFull(reader, header[:]); err != nil {
+			return nil, err
+		}
+		marker := binary.BigEndian.Uint32(header[:])
+		length := int(marker & 0x7fffffff)
+		if len(record)+length > maxRecordSize {
+			return nil, fmt.Errorf("record too large: %d", len(record)+length)
+		}
+		fragment := make([]byte, length)
+		if _, err := io.ReadFull(reader, fragment); err != nil {
+			return nil, err
+		}
+		record = append(record, fragment...)
+		if marker&0x80000000 != 0 {
+			return record, nil
+		}
+	}
+}
+
+// writeRecord writes the record as one last fragment
+func writeRecord(writer io.Writer, record []byte) error {
+	buf := make([]byte, 4+len(record))
+	binary.BigEndian.PutUint32(buf, 0x80000000|uint32(len(record)))
+	copy(buf[4:], record)
+	_, err := writer.Write(buf)
+	return err
+}
+
+// decodeCall reads the call header, leaving the arguments in the decoder
+func decodeCall(d *xdrDecoder) (h rpcCallHeader, rpcVersionOk bool) {
+	h.xid = d.uint32()
+	if d.uint32() != rpcCall {
+		d.err = fmt.Errorf("not a call")
+		return
+	}
+	rpcVersionOk = d.uint32() == 2
+	h.prog = d.uint32()
+	h.vers = d.uint32()
+	h.proc = d.uint32()
+
+	h.uid, h.gid = nobodyId, nobodyId
+	flavor := d.uint32()
+	credential := d.opaque(400)
+	switch flavor {
+	case authNone:
+	case authUnix:
+		c := &xdrDecoder{b: credential}
+		c.uint32() // stamp
+		c.string(255)
+		h.uid = c.uint32()
+		h.gid = c.uint32()
+		c.opaque(16 * 4)
+		h.badCred = c.err != nil
+	default:
+		h.badCred = true
+	}
+	// the verifier is not checked
+	d.uint32()
+	d.opaque(400)
+	return
+}
+
+// acceptedReply starts the reply of the accepted call
+func acceptedReply(xid uint32, acceptStatus uint32) *xdrEncoder {
+	e := &xdrEncoder{}
+	e.uint32(xid)
+	e.uint32(rpcReply)
+	e.uint32(rpcMsgAccepted)
+	e.uint32(authNone)
+	e.opaque(nil)
+	e.uint32(acceptStatus)
+	return e
+}
+
+func deniedReply(xid uint32, rejectStatus uint32) *xdrEncoder {
+	e := &xdrEncoder{}
+	e.uint32(xid)
+	e.uint32(rpcReply)
+	e.uint32(rpcMsgDenied)
+	e.uint32(rejectStatus)
+	if rejectStatus == rpcMismatch {
+		e.uint32(2)
+		e.uint32(2)
+	} else {
+		e.uint32(rpcAuthBadCred)
+	}
+	return e
+}
@@ -0,0 +1,98 @@
+package nfs
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// The XDR encoding of RFC 4506, only the types used by the ONC RPC, MOUNT and NFS v3 messages.
+
+var errXdrTooShort = errors.New("xdr: message too short")
+
+type xdrDecoder struct {
+	b   []byte
+	err error
+}
+
+func (d *xdrDecoder) uint32() uint32 {
+	if d.err != nil || len(d.b) < 4 {
+		d.err = errXdrTooShort
+		return 0
+	}
+	v := binary.BigEndian.Uint32(d.b)
+	d.b = d.b[4:]
+	return v
+}
+
+func (d *xdrDecoder) uint64() uint64 {
+	return uint64(d.uint32())<<32 | uint64(d.uint32())
+}
+
+func (d *xdrDecoder) bool() bool {
+	return d.uint32() != 0
+}
+
+// fixedOpaque reads the n bytes, padded to the multiple of 4 bytes
+func (d *xdrDecoder) fixedOpaque(n int) []byte {
+	padded := (n + 3) &^ 3
+	if d.err != nil || n < 0 || len(d.b) < padded {
+		d.err = errXdrTooShort
+		return nil
+	}
+	v := d.b[:n]
+	d.b = d.b[padded:]
+	return v
+}
+
+// opaque reads the variable length bytes, which should be no longer than maxLength
+func (d *xdrDecoder) opaque(maxLength int) []byte {
+	n := d.uint32()
+	if d.err == nil && int64(n) > int64(maxLength) {
+		d.err = errors.New("xdr: opaque too long")
+	}
+	if d.err != nil {
+		return nil
+	}
+	return d.fixedOpaque(int(n))
+}
+
+func (d *xdrDecoder) string(maxLength int) string {
+	return string(d.opaque(maxLength))
+}
+
+type xdrEncoder struct {
+	b []byte
+}
+
+func (e *xdrEncoder) uint32(v uint32) {
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], v)
+	e.b = append(e.b, buf[:]...)
+}
+
+func (e *xdrEncoder) uint64(v uint64) {
+	e.uint32(uint32(v >> 32))
+	e.uint32(uint32(v))
+}
+
+func (e *xdrEncoder) bool(v bool) {
+	if v {
+		e.uint32(1)
+	} else {
+		e.uint32(0)
+	}
+}
+
+func (e *xdrEncoder) fixedOpaque(v []byte) {
+	e.b = append(e.b, v...)
+	e.b = append(e.b, make([]byte, (4-len(v)%4)%4)...)
+}
+
+func (e *xdrEncoder) opaque(v []byte) {
+	e.uint32(uint32(len(v)))
+	e.fixedOpaque(v)
+}
+
+func (e *xdrEncoder) string(v string) {
+	e.opaque([]byte(v))
+}
@@ -1,6 +1,8 @@
 package notification
 
 import (
+	"strings"
+
 	"github.com/chrislusf/seaweedfs/weed/glog"
 	"github.com/chrislusf/seaweedfs/weed/util"
 	"github.com/golang/protobuf/proto"
@@ -21,14 +23,14 @@ var (
 	Queue MessageQueue
 )
 
+// LoadConfiguration initializes the enabled message queues, and the events are sent to all of them
 func LoadConfiguration(config *viper.Viper) {
 
 	if config == nil {
 		return
 	}
 
-	validateOneEnabledQueue(config)
-
+	var queues []MessageQueue
 	for _, queue := range MessageQueues {
 		if config.GetBool(queue.GetName() + ".enabled") {
 			viperSub := config.Sub(queue.GetName())
@@ -36,23 +38,44 @@ func LoadConfiguration(config *viper.Viper) {
 				glog.Fatalf("Failed to initialize notification for %s: %+v",
 					queue.GetName(), err)
 			}
-			Queue = queue
+			queues = append(queues, queue)
 			glog.V(0).Infof("Configure notification message queue for %s", queue.GetName())
-			return
 		}
 	}
 
+	switch len(queues) {
+	case 0:
+	case 1:
+		Queue = queues[0]
+	default:
+		Queue = &multipleQueues{queues: queues}
+	}
+
 }
 
-func validateOneEnabledQueue(config *viper.Viper) {
-	enabledQueue := ""
-	for _, queue := range MessageQueues {
-		if config.GetBool(queue.GetName() + ".enabled") {
-			if enabledQueue == "" {
-				enabledQueue = queue.GetName()
-			} else {
-				glog.Fatalf("Notification message queue is enabled for both %s and %s", enabledQueue, queue.GetName())
-			}
+// multipleQueues sends the messages to all the enabled queues, e.g. a message queue and the webhooks
+type multipleQueues struct {
+	queues []MessageQueue
+}
+
+func (m *multipleQueues) GetName() string {
+	var names []string
+	for _, queue := range m.queues {
+		names = append(names, queue.GetName())
+	}
+	return strings.Join(names, ",")
+}
+
+func (m *multipleQueues) Initialize(configuration util.Configuration) error {
+	return nil
+}
+
+func (m *multipleQueues) SendMessage(key string, message proto.Message) (err error) {
+	for _, queue := range m.queues {
+		if sendErr := queue.SendMessage(key, message); sendErr != nil {
+			glog.V(0).Infof("notify %s by %s: %v", key, queue.GetName(), sendErr)
+			err = sendErr
 		}
 	}
+	return err
 }
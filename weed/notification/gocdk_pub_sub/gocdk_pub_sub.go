@@ -36,6 +36,7 @@ func init() {
 
 type GoCDKPubSub struct {
 	topicURL string
+	format   string
 	topic    *pubsub.Topic
 }
 
@@ -46,6 +47,10 @@ func (k *GoCDKPubSub) GetName() string {
 func (k *GoCDKPubSub) Initialize(config util.Configuration) error {
 	k.topicURL = config.GetString("topic_url")
 	glog.V(0).Infof("notification.gocdk_pub_sub.topic_url: %v", k.topicURL)
+	k.format = config.GetString("message_format")
+	if err := notification.CheckMessageFormat(k.format); err != nil {
+		return err
+	}
 	topic, err := pubsub.OpenTopic(context.Background(), k.topicURL)
 	if err != nil {
 		glog.Fatalf("Failed to open topic: %v", err)
@@ -55,7 +60,7 @@ func (k *GoCDKPubSub) Initialize(config util.Configuration) error {
 }
 
 func (k *GoCDKPubSub) SendMessage(key string, message proto.Message) error {
-	bytes, err := proto.Marshal(message)
+	bytes, err := notification.EncodeMessage(k.format, key, message)
 	if err != nil {
 		return err
 	}
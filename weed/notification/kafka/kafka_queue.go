@@ -14,6 +14,7 @@ func init() {
 
 type KafkaQueue struct {
 	topic    string
+	format   string
 	producer sarama.AsyncProducer
 }
 
@@ -24,6 +25,10 @@ func (k *KafkaQueue) GetName() string {
 func (k *KafkaQueue) Initialize(configuration util.Configuration) (err error) {
 	glog.V(0).Infof("filer.notification.kafka.hosts: %v\n", configuration.GetStringSlice("hosts"))
 	glog.V(0).Infof("filer.notification.kafka.topic: %v\n", configuration.GetString("topic"))
+	k.format = configuration.GetString("message_format")
+	if err = notification.CheckMessageFormat(k.format); err != nil {
+		return err
+	}
 	return k.initialize(
 		configuration.GetStringSlice("hosts"),
 		configuration.GetString("topic"),
@@ -47,7 +52,7 @@ func (k *KafkaQueue) initialize(hosts []string, topic string) (err error) {
 }
 
 func (k *KafkaQueue) SendMessage(key string, message proto.Message) (err error) {
-	bytes, err := proto.Marshal(message)
+	bytes, err := notification.EncodeMessage(k.format, key, message)
 	if err != nil {
 		return
 	}
@@ -0,0 +1,96 @@
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+)
+
+// the message formats of the events, set by "message_format" of each message queue
+const (
+	// the protobuf of filer_pb.EventNotification, read by "weed filer.replicate"
+	MessageFormatProtobuf = "protobuf"
+	// the json with the key and the event type, for the consumers without the protobuf definitions
+	MessageFormatJson = "json"
+)
+
+// the event types in the json messages
+const (
+	EventTypeCreate = "create"
+	EventTypeUpdate = "update"
+	EventTypeDelete = "delete"
+	EventTypeRename = "rename"
+)
+
+// JsonMessage is the schema of the json messages
+type JsonMessage struct {
+	Key       string          `json:"key"`
+	EventType string          `json:"event_type"`
+	TsNs      int64           `json:"ts_ns"`
+	Event     json.RawMessage `json:"event"`
+}
+
+func CheckMessageFormat(format string) error {
+	switch format {
+	case "", MessageFormatProtobuf, MessageFormatJson:
+		return nil
+	}
+	return fmt.Errorf("unknown message_format %q, expecting %s or %s", format, MessageFormatProtobuf, MessageFormatJson)
+}
+
+// EncodeMessage encodes the message in the format, protobuf by default
+func EncodeMessage(format string, key string, message proto.Message) ([]byte, error) {
+	if format != MessageFormatJson {
+		return proto.Marshal(message)
+	}
+
+	var event bytes.Buffer
+	marshaler := jsonpb.Marshaler{OrigName: true}
+	if err := marshaler.Marshal(&event, message); err != nil {
+		return nil, err
+	}
+	jsonMessage := &JsonMessage{
+		Key:   key,
+		TsNs:  time.Now().UnixNano(),
+		Event: event.Bytes(),
+	}
+	if eventNotification, ok := message.(*filer_pb.EventNotification); ok {
+		jsonMessage.EventType = EventType(key, eventNotification)
+	}
+	return json.Marshal(jsonMessage)
+}
+
+// DecodeMessage decodes the message encoded by EncodeMessage in the format
+func DecodeMessage(format string, data []byte) (key string, message *filer_pb.EventNotification, err error) {
+	message = &filer_pb.EventNotification{}
+	if format != MessageFormatJson {
+		err = proto.Unmarshal(data, message)
+		return
+	}
+
+	jsonMessage := &JsonMessage{}
+	if err = json.Unmarshal(data, jsonMessage); err != nil {
+		return
+	}
+	err = jsonpb.Unmarshal(bytes.NewReader(jsonMessage.Event), message)
+	return jsonMessage.Key, message, err
+}
+
+// EventType tells the change of the entry, with the key being the path of the old entry if any
+func EventType(key string, event *filer_pb.EventNotification) string {
+	switch {
+	case event.OldEntry == nil:
+		return EventTypeCreate
+	case event.NewEntry == nil:
+		return EventTypeDelete
+	case event.NewParentPath != "" && path.Join(event.NewParentPath, event.NewEntry.Name) != key:
+		return EventTypeRename
+	}
+	return EventTypeUpdate
+}
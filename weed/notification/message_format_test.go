@@ -0,0 +1,63 @@
+package notification
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+)
+
+func TestMessageFormats(t *testing.T) {
+	event := &filer_pb.EventNotification{
+		OldEntry:      &filer_pb.Entry{Name: "a.jpg"},
+		NewEntry:      &filer_pb.Entry{Name: "b.jpg", Chunks: []*filer_pb.FileChunk{{FileId: "3,01637037d6", Size: 1024}}},
+		NewParentPath: "/photos",
+	}
+
+	for _, format := range []string{"", MessageFormatProtobuf, MessageFormatJson} {
+		data, err := EncodeMessage(format, "/photos/a.jpg", event)
+		if err != nil {
+			t.Fatalf("encode %s: %v", format, err)
+		}
+		key, decoded, err := DecodeMessage(format, data)
+		if err != nil {
+			t.Fatalf("decode %s: %v", format, err)
+		}
+		if format == MessageFormatJson && key != "/photos/a.jpg" {
+			t.Errorf("decoded key %s", key)
+		}
+		if decoded.NewEntry.Name != "b.jpg" || decoded.NewEntry.Chunks[0].Size != 1024 || decoded.NewParentPath != "/photos" {
+			t.Errorf("decoded %s: %+v", format, decoded)
+		}
+	}
+
+	data, _ := EncodeMessage(MessageFormatJson, "/photos/a.jpg", event)
+	jsonMessage := &JsonMessage{}
+	if err := json.Unmarshal(data, jsonMessage); err != nil {
+		t.Fatal(err)
+	}
+	if jsonMessage.EventType != EventTypeRename {
+		t.Errorf("event type %s", jsonMessage.EventType)
+	}
+
+	if CheckMessageFormat("avro") == nil {
+		t.Errorf("accepted an unknown message format")
+	}
+}
+
+func TestEventType(t *testing.T) {
+	entry := &filer_pb.Entry{Name: "a.jpg"}
+	for _, c := range []struct {
+		event     *filer_pb.EventNotification
+		eventType string
+	}{
+		{&filer_pb.EventNotification{NewEntry: entry, NewParentPath: "/photos"}, EventTypeCreate},
+		{&filer_pb.EventNotification{OldEntry: entry}, EventTypeDelete},
+		{&filer_pb.EventNotification{OldEntry: entry, NewEntry: entry, NewParentPath: "/photos"}, EventTypeUpdate},
+		{&filer_pb.EventNotification{OldEntry: entry, NewEntry: entry, NewParentPath: "/archive"}, EventTypeRename},
+	} {
+		if eventType := EventType("/photos/a.jpg", c.event); eventType != c.eventType {
+			t.Errorf("event type %s, expected %s", eventType, c.eventType)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package nats
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/notification"
+	"github.com/chrislusf/seaweedfs/weed/util"
+	"github.com/golang/protobuf/proto"
+	nats "github.com/nats-io/go-nats"
+)
+
+func init() {
+	notification.MessageQueues = append(notification.MessageQueues, &NatsQueue{})
+}
+
+type NatsQueue struct {
+	subject string
+	format  string
+	conn    *nats.Conn
+}
+
+func (k *NatsQueue) GetName() string {
+	return "nats"
+}
+
+func (k *NatsQueue) Initialize(configuration util.Configuration) (err error) {
+	glog.V(0).Infof("filer.notification.nats.servers: %v", configuration.GetString("servers"))
+	glog.V(0).Infof("filer.notification.nats.subject: %v", configuration.GetString("subject"))
+	k.format = configuration.GetString("message_format")
+	if err = notification.CheckMessageFormat(k.format); err != nil {
+		return err
+	}
+	return k.initialize(
+		configuration.GetString("servers"),
+		configuration.GetString("subject"),
+		configuration.GetString("user"),
+		configuration.GetString("password"),
+		configuration.GetString("token"),
+	)
+}
+
+func (k *NatsQueue) initialize(servers, subject, user, password, token string) (err error) {
+	if subject == "" {
+		return fmt.Errorf("nats subject is not set")
+	}
+	options := []nats.Option{
+		nats.Name("seaweedfs filer"),
+		// keep reconnecting, with the messages buffered by the client meanwhile
+		nats.MaxReconnects(-1),
+		nats.ReconnectWait(2 * time.Second),
+		nats.DisconnectHandler(func(conn *nats.Conn) {
+			glog.Warningf("nats disconnected from %s", servers)
+		}),
+		nats.ReconnectHandler(func(conn *nats.Conn) {
+			glog.V(0).Infof("nats reconnected to %s", conn.ConnectedUrl())
+		}),
+	}
+	if user != "" {
+		options = append(options, nats.UserInfo(user, password))
+	}
+	if token != "" {
+		options = append(options, nats.Token(token))
+	}
+	k.conn, err = nats.Connect(servers, options...)
+	if err != nil {
+		return fmt.Errorf("connect to nats %s: %v", servers, err)
+	}
+	k.subject = subject
+	return nil
+}
+
+func (k *NatsQueue) SendMessage(key string, message proto.Message) (err error) {
+	bytes, err := notification.EncodeMessage(k.format, key, message)
+	if err != nil {
+		return
+	}
+	if err = k.conn.Publish(k.subject, bytes); err != nil {
+		return fmt.Errorf("publish to nats subject %s: %v", k.subject, err)
+	}
+	return nil
+}
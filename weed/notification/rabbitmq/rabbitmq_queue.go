@@ -0,0 +1,114 @@
+package rabbitmq
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/notification"
+	"github.com/chrislusf/seaweedfs/weed/util"
+	"github.com/golang/protobuf/proto"
+	"github.com/streadway/amqp"
+)
+
+func init() {
+	notification.MessageQueues = append(notification.MessageQueues, &RabbitMqQueue{})
+}
+
+type RabbitMqQueue struct {
+	url        string
+	exchange   string
+	routingKey string
+	format     string
+
+	sync.Mutex
+	conn    *amqp.Connection
+	channel *amqp.Channel
+}
+
+func (k *RabbitMqQueue) GetName() string {
+	return "rabbitmq"
+}
+
+func (k *RabbitMqQueue) Initialize(configuration util.Configuration) (err error) {
+	glog.V(0).Infof("filer.notification.rabbitmq.exchange: %v", configuration.GetString("exchange"))
+	glog.V(0).Infof("filer.notification.rabbitmq.routing_key: %v", configuration.GetString("routing_key"))
+	k.format = configuration.GetString("message_format")
+	if err = notification.CheckMessageFormat(k.format); err != nil {
+		return err
+	}
+	k.url = configuration.GetString("url")
+	k.exchange = configuration.GetString("exchange")
+	k.routingKey = configuration.GetString("routing_key")
+	if k.exchange == "" {
+		return fmt.Errorf("rabbitmq exchange is not set")
+	}
+
+	k.Lock()
+	defer k.Unlock()
+	if err = k.connect(); err != nil {
+		return err
+	}
+	// the topic exchange lets the consumers bind to the routing keys they need
+	if configuration.GetBool("declare_exchange") {
+		if err = k.channel.ExchangeDeclare(k.exchange, "topic", true, false, false, false, nil); err != nil {
+			return fmt.Errorf("declare rabbitmq exchange %s: %v", k.exchange, err)
+		}
+	}
+	return nil
+}
+
+// connect opens the connection and the channel, should be called with the lock held
+func (k *RabbitMqQueue) connect() (err error) {
+	if k.conn, err = amqp.Dial(k.url); err != nil {
+		return fmt.Errorf("connect to rabbitmq: %v", err)
+	}
+	if k.channel, err = k.conn.Channel(); err != nil {
+		k.conn.Close()
+		k.conn = nil
+		return fmt.Errorf("open rabbitmq channel: %v", err)
+	}
+	return nil
+}
+
+func (k *RabbitMqQueue) SendMessage(key string, message proto.Message) (err error) {
+	bytes, err := notification.EncodeMessage(k.format, key, message)
+	if err != nil {
+		return
+	}
+	contentType := "application/x-protobuf"
+	if k.format == notification.MessageFormatJson {
+		contentType = "application/json"
+	}
+	publishing := amqp.Publishing{
+		Headers:      amqp.Table{"key": key},
+		ContentType:  contentType,
+		DeliveryMode: amqp.Persistent,
+		Timestamp:    time.Now(),
+		Body:         bytes,
+	}
+
+	k.Lock()
+	defer k.Unlock()
+
+	// reconnect once if the connection was lost since the last message
+	for attempt := 0; ; attempt++ {
+		if k.channel == nil {
+			if err = k.connect(); err != nil {
+				return err
+			}
+		}
+		err = k.channel.Publish(k.exchange, k.routingKey, false, false, publishing)
+		if err == nil || attempt > 0 {
+			break
+		}
+		glog.Warningf("publish to rabbitmq exchange %s: %v, reconnecting", k.exchange, err)
+		k.conn.Close()
+		k.conn, k.channel = nil, nil
+	}
+	if err != nil {
+		return fmt.Errorf("publish to rabbitmq exchange %s: %v", k.exchange, err)
+	}
+	return nil
+}
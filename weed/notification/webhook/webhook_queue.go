@@ -0,0 +1,225 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/notification"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+	"github.com/chrislusf/seaweedfs/weed/util"
+	"github.com/golang/protobuf/proto"
+)
+
+const (
+	SignatureHeader = "X-Seaweedfs-Signature"
+	EventTypeHeader = "X-Seaweedfs-Event"
+	maxBackoff      = time.Minute
+)
+
+func init() {
+	notification.MessageQueues = append(notification.MessageQueues, &WebhookQueue{})
+}
+
+// WebhookQueue posts the events as json to the webhook urls.
+// Each url has its own queue and worker, so a slow or down endpoint does not delay the others,
+// and the events to one url are posted in order.
+type WebhookQueue struct {
+	secret         []byte
+	client         *http.Client
+	maxRetries     int
+	initialBackoff time.Duration
+	endpoints      []*endpoint
+
+	deadLetterFile string
+	deadLetterLock sync.Mutex
+}
+
+type event struct {
+	key       string
+	eventType string
+	body      []byte
+}
+
+type endpoint struct {
+	url    string
+	events chan *event
+}
+
+// deadLetter is one line of the dead letter file
+type deadLetter struct {
+	Url     string          `json:"url"`
+	Error   string          `json:"error"`
+	TsNs    int64           `json:"ts_ns"`
+	Message json.RawMessage `json:"message"`
+}
+
+func (k *WebhookQueue) GetName() string {
+	return "webhook"
+}
+
+func (k *WebhookQueue) Initialize(configuration util.Configuration) (err error) {
+	glog.V(0).Infof("filer.notification.webhook.urls: %v", configuration.GetStringSlice("urls"))
+	glog.V(0).Infof("filer.notification.webhook.dead_letter_file: %v", configuration.GetString("dead_letter_file"))
+	return k.initialize(
+		configuration.GetStringSlice("urls"),
+		configuration.GetString("secret"),
+		time.Duration(getInt(configuration, "timeout_seconds", 10))*time.Second,
+		getInt(configuration, "max_retries", 8),
+		time.Duration(getInt(configuration, "retry_backoff_ms", 1000))*time.Millisecond,
+		getInt(configuration, "queue_size", 10000),
+		configuration.GetString("dead_letter_file"),
+	)
+}
+
+func getInt(configuration util.Configuration, key string, defaultValue int) int {
+	if v := configuration.GetInt(key); v > 0 {
+		return v
+	}
+	return defaultValue
+}
+
+func (k *WebhookQueue) initialize(urls []string, secret string, timeout time.Duration, maxRetries int, initialBackoff time.Duration, queueSize int, deadLetterFile string) error {
+	if len(urls) == 0 {
+		return fmt.Errorf("no webhook urls")
+	}
+	k.secret = []byte(secret)
+	k.client = &http.Client{Timeout: timeout}
+	k.maxRetries = maxRetries
+	k.initialBackoff = initialBackoff
+	k.deadLetterFile = deadLetterFile
+	for _, url := range urls {
+		e := &endpoint{
+			url:    url,
+			events: make(chan *event, queueSize),
+		}
+		k.endpoints = append(k.endpoints, e)
+		go k.deliverLoop(e)
+	}
+	return nil
+}
+
+// SendMessage queues the event without waiting for the delivery, so the filer is not slowed down by the webhooks
+func (k *WebhookQueue) SendMessage(key string, message proto.Message) error {
+	body, err := notification.EncodeMessage(notification.MessageFormatJson, key, message)
+	if err != nil {
+		return err
+	}
+	ev := &event{key: key, body: body}
+	if eventNotification, ok := message.(*filer_pb.EventNotification); ok {
+		ev.eventType = notification.EventType(key, eventNotification)
+	}
+	for _, e := range k.endpoints {
+		select {
+		case e.events <- ev:
+		default:
+			k.saveDeadLetter(e.url, ev, fmt.Errorf("queue of %d events is full", cap(e.events)))
+		}
+	}
+	return nil
+}
+
+func (k *WebhookQueue) deliverLoop(e *endpoint) {
+	for ev := range e.events {
+		k.deliver(e.url, ev)
+	}
+}
+
+// deliver posts the event, retrying with exponential backoff on the network errors, 5xx and 429
+func (k *WebhookQueue) deliver(url string, ev *event) {
+	backoff := k.initialBackoff
+	for attempt := 0; ; attempt++ {
+		retryable, err := k.post(url, ev)
+		if err == nil {
+			glog.V(3).Infof("webhook %s delivered %s", url, ev.key)
+			return
+		}
+		if !retryable || attempt >= k.maxRetries {
+			k.saveDeadLetter(url, ev, err)
+			return
+		}
+		glog.V(1).Infof("webhook %s event %s attempt %d: %v, retry in %v", url, ev.key, attempt+1, err, backoff)
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+func (k *WebhookQueue) post(url string, ev *event) (retryable bool, err error) {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(ev.body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if ev.eventType != "" {
+		req.Header.Set(EventTypeHeader, ev.eventType)
+	}
+	if len(k.secret) > 0 {
+		req.Header.Set(SignatureHeader, Sign(k.secret, ev.body))
+	}
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64*1024))
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("status %s", resp.Status)
+	}
+	return false, fmt.Errorf("status %s", resp.Status)
+}
+
+// Sign is the value of the signature header, the hex of the HMAC-SHA256 of the body by the secret,
+// prefixed by "sha256=", for the receivers to verify the events are from the filer
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// saveDeadLetter appends the undelivered event to the dead letter file as one json line
+func (k *WebhookQueue) saveDeadLetter(url string, ev *event, cause error) {
+	glog.Errorf("webhook %s event %s not delivered: %v", url, ev.key, cause)
+	if k.deadLetterFile == "" {
+		return
+	}
+	line, err := json.Marshal(&deadLetter{
+		Url:     url,
+		Error:   cause.Error(),
+		TsNs:    time.Now().UnixNano(),
+		Message: ev.body,
+	})
+	if err != nil {
+		glog.Errorf("webhook dead letter of %s: %v", ev.key, err)
+		return
+	}
+
+	k.deadLetterLock.Lock()
+	defer k.deadLetterLock.Unlock()
+	f, err := os.OpenFile(k.deadLetterFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		glog.Errorf("open webhook dead letter file %s: %v", k.deadLetterFile, err)
+		return
+	}
+	defer f.Close()
+	if _, err = f.Write(append(line, '\n')); err != nil {
+		glog.Errorf("write webhook dead letter file %s: %v", k.deadLetterFile, err)
+	}
+}
@@ -0,0 +1,92 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/notification"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+)
+
+func TestWebhookDelivery(t *testing.T) {
+	dir, err := ioutil.TempDir("", "webhook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	deadLetterFile := filepath.Join(dir, "dead_letter.jsonl")
+
+	var attempts int32
+	delivered := make(chan *notification.JsonMessage, 1)
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Header.Get(SignatureHeader) != Sign([]byte("secret"), body) {
+			t.Errorf("signature %s", r.Header.Get(SignatureHeader))
+		}
+		if r.Header.Get(EventTypeHeader) != notification.EventTypeCreate {
+			t.Errorf("event type %s", r.Header.Get(EventTypeHeader))
+		}
+		// fails twice before the delivery
+		if atomic.AddInt32(&attempts, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		message := &notification.JsonMessage{}
+		if err := json.Unmarshal(body, message); err != nil {
+			t.Error(err)
+		}
+		delivered <- message
+	}))
+	defer flaky.Close()
+	rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer rejecting.Close()
+
+	queue := &WebhookQueue{}
+	if err = queue.initialize([]string{flaky.URL, rejecting.URL}, "secret", time.Second, 3, time.Millisecond, 10, deadLetterFile); err != nil {
+		t.Fatal(err)
+	}
+	err = queue.SendMessage("/photos/a.jpg", &filer_pb.EventNotification{
+		NewEntry:      &filer_pb.Entry{Name: "a.jpg"},
+		NewParentPath: "/photos",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case message := <-delivered:
+		if message.Key != "/photos/a.jpg" || message.EventType != notification.EventTypeCreate {
+			t.Errorf("delivered %+v", message)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("not delivered after %d attempts", atomic.LoadInt32(&attempts))
+	}
+
+	// the rejected event is not retried, and goes to the dead letter file
+	var data []byte
+	for i := 0; i < 100 && len(data) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+		data, _ = ioutil.ReadFile(deadLetterFile)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("dead letters: %q", data)
+	}
+	letter := &deadLetter{}
+	if err = json.Unmarshal([]byte(lines[0]), letter); err != nil {
+		t.Fatal(err)
+	}
+	if letter.Url != rejecting.URL || !strings.Contains(letter.Error, "400") || !strings.Contains(string(letter.Message), "a.jpg") {
+		t.Errorf("dead letter %+v", letter)
+	}
+}
@@ -5,19 +5,27 @@ import (
 	"fmt"
 	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
 	"github.com/chrislusf/seaweedfs/weed/security"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
 	"github.com/chrislusf/seaweedfs/weed/util"
 	"google.golang.org/grpc"
+	"net/url"
 	"strings"
 )
 
 type VolumeAssignRequest struct {
-	Count       uint64
-	Replication string
-	Collection  string
-	Ttl         string
-	DataCenter  string
-	Rack        string
-	DataNode    string
+	Count         uint64
+	Replication   string
+	Collection    string
+	Ttl           string
+	DataCenter    string
+	Rack          string
+	DataNode      string
+	LocalityGroup string
+	Fsync         bool   // sync the write to the disk on every copy before acknowledging
+	Ack           string // needle.AckAll by default, or needle.AckQuorum
+	LabelSelector string // place the new volumes on the volume servers with the matching labels, e.g. "zone=eu,class!=archive"
+	Session       string // keep assigning the same volume, or the same volume server, to the writer session
+	Batch         bool   // list all the Count file ids in AssignResult.Fids
 }
 
 type AssignResult struct {
@@ -27,6 +35,23 @@ type AssignResult struct {
 	Count     uint64              `json:"count,omitempty"`
 	Error     string              `json:"error,omitempty"`
 	Auth      security.EncodedJwt `json:"auth,omitempty"`
+	Fsync     bool                `json:"fsync,omitempty"`
+	Ack       string              `json:"ack,omitempty"`
+	Fids      []string            `json:"fids,omitempty"` // the consecutive file ids on the same volume, for the batch assign
+}
+
+func (ar *AssignResult) WriteFlags() needle.WriteFlags {
+	return needle.WriteFlags{Fsync: ar.Fsync, Ack: ar.Ack}
+}
+
+// UploadUrl is the url to upload the assigned fid, with the write flags for the volume servers to enforce
+func (ar *AssignResult) UploadUrl() string {
+	q := url.Values{}
+	ar.WriteFlags().SetQuery(q)
+	if len(q) == 0 {
+		return "http://" + ar.Url + "/" + ar.Fid
+	}
+	return "http://" + ar.Url + "/" + ar.Fid + "?" + q.Encode()
 }
 
 func Assign(server string, grpcDialOption grpc.DialOption, primaryRequest *VolumeAssignRequest, alternativeRequests ...*VolumeAssignRequest) (*AssignResult, error) {
@@ -46,13 +71,19 @@ func Assign(server string, grpcDialOption grpc.DialOption, primaryRequest *Volum
 		lastError = WithMasterServerClient(server, grpcDialOption, func(masterClient master_pb.SeaweedClient) error {
 
 			req := &master_pb.AssignRequest{
-				Count:       primaryRequest.Count,
-				Replication: primaryRequest.Replication,
-				Collection:  primaryRequest.Collection,
-				Ttl:         primaryRequest.Ttl,
-				DataCenter:  primaryRequest.DataCenter,
-				Rack:        primaryRequest.Rack,
-				DataNode:    primaryRequest.DataNode,
+				Count:         primaryRequest.Count,
+				Replication:   primaryRequest.Replication,
+				Collection:    primaryRequest.Collection,
+				Ttl:           primaryRequest.Ttl,
+				DataCenter:    primaryRequest.DataCenter,
+				Rack:          primaryRequest.Rack,
+				DataNode:      primaryRequest.DataNode,
+				LocalityGroup: primaryRequest.LocalityGroup,
+				Fsync:         primaryRequest.Fsync,
+				Ack:           primaryRequest.Ack,
+				LabelSelector: primaryRequest.LabelSelector,
+				Session:       primaryRequest.Session,
+				Batch:         primaryRequest.Batch,
 			}
 			resp, grpcErr := masterClient.Assign(context.Background(), req)
 			if grpcErr != nil {
@@ -65,6 +96,9 @@ func Assign(server string, grpcDialOption grpc.DialOption, primaryRequest *Volum
 			ret.PublicUrl = resp.PublicUrl
 			ret.Error = resp.Error
 			ret.Auth = security.EncodedJwt(resp.Auth)
+			ret.Fsync = resp.Fsync
+			ret.Ack = resp.Ack
+			ret.Fids = resp.Fids
 
 			return nil
 
@@ -0,0 +1,231 @@
+package operation
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/util"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
+)
+
+type ClientPoolOption struct {
+	// the requests in flight to each volume server, the others wait for their turns. 0 for no limit
+	MaxConcurrentPerServer int
+	// the circuit to a volume server opens after this many failures in a row. 0 to never open
+	FailureThreshold int
+	// the requests to a volume server with the open circuit fail fast for this long,
+	// and then one request probes whether the volume server is back
+	OpenDuration time.Duration
+}
+
+// ClientPool shares the http and grpc connections to the volume servers. The requests to each
+// volume server are limited to some in flight, and fail fast while the volume server keeps failing.
+type ClientPool struct {
+	option     ClientPoolOption
+	httpClient *http.Client
+
+	sync.Mutex
+	servers map[string]*poolServer
+}
+
+type poolServer struct {
+	slots               chan struct{} // nil for no limit
+	consecutiveFailures int
+	openUntil           time.Time
+	probing             bool
+	grpcConnection      *grpc.ClientConn
+}
+
+type CircuitOpenError struct {
+	Server    string
+	OpenUntil time.Time
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("volume server %s keeps failing, retry after %v", e.Server, e.OpenUntil.Format(time.RFC3339))
+}
+
+var volumeServerClientPool = NewClientPool(ClientPoolOption{
+	MaxConcurrentPerServer: 64,
+	FailureThreshold:       5,
+	OpenDuration:           10 * time.Second,
+})
+
+// ConfigureClientPool replaces the pool of the volume server clients, before any requests
+func ConfigureClientPool(option ClientPoolOption) {
+	volumeServerClientPool = NewClientPool(option)
+}
+
+func NewClientPool(option ClientPoolOption) *ClientPool {
+	maxIdleConnsPerHost := option.MaxConcurrentPerServer
+	if maxIdleConnsPerHost <= 0 {
+		maxIdleConnsPerHost = 1024
+	}
+	return &ClientPool{
+		option: option,
+		httpClient: &http.Client{Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   10 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			// keep the connections of all the requests in flight, instead of opening new ports for each burst
+			MaxIdleConnsPerHost: maxIdleConnsPerHost,
+			MaxConnsPerHost:     option.MaxConcurrentPerServer,
+			IdleConnTimeout:     90 * time.Second,
+		}},
+		servers: make(map[string]*poolServer),
+	}
+}
+
+func (p *ClientPool) getServer(server string) *poolServer {
+	p.Lock()
+	defer p.Unlock()
+	s, found := p.servers[server]
+	if !found {
+		s = &poolServer{}
+		if p.option.MaxConcurrentPerServer > 0 {
+			s.slots = make(chan struct{}, p.option.MaxConcurrentPerServer)
+		}
+		p.servers[server] = s
+	}
+	return s
+}
+
+// acquire waits for a turn to the server, or fails fast if the circuit is open
+func (p *ClientPool) acquire(ctx context.Context, server string) (*poolServer, error) {
+	s := p.getServer(server)
+
+	p.Lock()
+	if s.consecutiveFailures >= p.option.FailureThreshold && p.option.FailureThreshold > 0 {
+		if time.Now().Before(s.openUntil) || s.probing {
+			openUntil := s.openUntil
+			p.Unlock()
+			return nil, &CircuitOpenError{Server: server, OpenUntil: openUntil}
+		}
+		// half open, only this request probes the server
+		s.probing = true
+	}
+	p.Unlock()
+
+	if s.slots != nil {
+		select {
+		case s.slots <- struct{}{}:
+		case <-ctx.Done():
+			p.Lock()
+			s.probing = false
+			p.Unlock()
+			return nil, ctx.Err()
+		}
+	}
+	return s, nil
+}
+
+func (p *ClientPool) release(server string, s *poolServer, failed bool) {
+	if s.slots != nil {
+		<-s.slots
+	}
+	p.Lock()
+	defer p.Unlock()
+	s.probing = false
+	if !failed {
+		s.consecutiveFailures = 0
+		return
+	}
+	s.consecutiveFailures++
+	if s.consecutiveFailures >= p.option.FailureThreshold && p.option.FailureThreshold > 0 {
+		s.openUntil = time.Now().Add(p.option.OpenDuration)
+		glog.V(0).Infof("volume server %s failed %d times in a row, open the circuit until %v", server, s.consecutiveFailures, s.openUntil)
+	}
+}
+
+// Do sends the http request to the volume server in the url. The turn is held until the response body is closed.
+// The connection errors and the 5xx responses count as the failures of the volume server.
+func (p *ClientPool) Do(req *http.Request) (*http.Response, error) {
+	server := req.URL.Host
+	s, err := p.acquire(req.Context(), server)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		p.release(server, s, true)
+		return nil, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: func() {
+		p.release(server, s, resp.StatusCode >= 500)
+	}}
+	return resp, nil
+}
+
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// WithGrpcClient runs fn with the shared grpc connection to the volume server grpc address.
+// The connection is dialed again only after it is shut down or fails, not on the errors returned by fn.
+func (p *ClientPool) WithGrpcClient(ctx context.Context, grpcAddress string, grpcDialOption grpc.DialOption, fn func(*grpc.ClientConn) error) error {
+	s, err := p.acquire(ctx, grpcAddress)
+	if err != nil {
+		return err
+	}
+
+	grpcConnection, err := p.getGrpcConnection(ctx, s, grpcAddress, grpcDialOption)
+	if err != nil {
+		p.release(grpcAddress, s, true)
+		return err
+	}
+
+	err = fn(grpcConnection)
+	p.release(grpcAddress, s, isUnavailable(err))
+	return err
+}
+
+func (p *ClientPool) getGrpcConnection(ctx context.Context, s *poolServer, grpcAddress string, grpcDialOption grpc.DialOption) (*grpc.ClientConn, error) {
+	p.Lock()
+	defer p.Unlock()
+	if s.grpcConnection != nil {
+		state := s.grpcConnection.GetState()
+		if state != connectivity.Shutdown && state != connectivity.TransientFailure {
+			return s.grpcConnection, nil
+		}
+		glog.V(1).Infof("redial volume server %s in state %v", grpcAddress, state)
+		s.grpcConnection.Close()
+		s.grpcConnection = nil
+	}
+	grpcConnection, err := util.GrpcDial(ctx, grpcAddress, grpcDialOption)
+	if err != nil {
+		return nil, fmt.Errorf("fail to dial %s: %v", grpcAddress, err)
+	}
+	s.grpcConnection = grpcConnection
+	return grpcConnection, nil
+}
+
+// isUnavailable tells the errors of reaching the server, from the errors of the requests themselves
+func isUnavailable(err error) bool {
+	if err == nil {
+		return false
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package operation
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientPoolCircuitBreaker(t *testing.T) {
+	var failing int32 = 1
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if atomic.LoadInt32(&failing) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	p := NewClientPool(ClientPoolOption{FailureThreshold: 2, OpenDuration: 100 * time.Millisecond})
+	get := func() error {
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		resp, err := p.Do(req)
+		if err != nil {
+			return err
+		}
+		ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := get(); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+	}
+	if err := get(); err == nil {
+		t.Fatalf("the circuit should be open")
+	} else if _, ok := err.(*CircuitOpenError); !ok {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("%d requests reached the server with the open circuit", n)
+	}
+
+	// the probe after the open duration closes the circuit again
+	atomic.StoreInt32(&failing, 0)
+	time.Sleep(150 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		if err := get(); err != nil {
+			t.Fatalf("request %d after recovery: %v", i, err)
+		}
+	}
+}
+
+func TestClientPoolConcurrencyPerServer(t *testing.T) {
+	var running, maxRunning int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+	}))
+	defer server.Close()
+
+	p := NewClientPool(ClientPoolOption{MaxConcurrentPerServer: 2})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest("GET", server.URL, nil)
+			resp, err := p.Do(req)
+			if err != nil {
+				t.Errorf("request: %v", err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	if m := atomic.LoadInt32(&maxRunning); m > 2 {
+		t.Errorf("%d requests in flight, expecting at most 2", m)
+	}
+}
@@ -69,11 +69,13 @@ func DeleteFilesWithLookupVolumeId(grpcDialOption grpc.DialOption, fileIds []str
 	server_to_fileIds := make(map[string][]string)
 	for vid, result := range lookupResults {
 		if result.Error != "" {
-			ret = append(ret, &volume_server_pb.DeleteResult{
-				FileId: vid,
-				Status: http.StatusBadRequest,
-				Error:  err.Error()},
-			)
+			for _, fileId := range vid_to_fileIds[vid] {
+				ret = append(ret, &volume_server_pb.DeleteResult{
+					FileId: fileId,
+					Status: http.StatusBadRequest,
+					Error:  result.Error},
+				)
+			}
 			continue
 		}
 		for _, location := range result.Locations {
@@ -87,16 +89,19 @@ func DeleteFilesWithLookupVolumeId(grpcDialOption grpc.DialOption, fileIds []str
 
 	resultChan := make(chan []*volume_server_pb.DeleteResult, len(server_to_fileIds))
 	var wg sync.WaitGroup
+	var errLock sync.Mutex
 	for server, fidList := range server_to_fileIds {
 		wg.Add(1)
 		go func(server string, fidList []string) {
 			defer wg.Done()
 
-			if deleteResults, deleteErr := DeleteFilesAtOneVolumeServer(server, grpcDialOption, fidList); deleteErr != nil {
+			deleteResults, deleteErr := DeleteFilesAtOneVolumeServer(server, grpcDialOption, fidList)
+			if deleteErr != nil {
+				errLock.Lock()
 				err = deleteErr
-			} else {
-				resultChan <- deleteResults
+				errLock.Unlock()
 			}
+			resultChan <- deleteResults
 
 		}(server, fidList)
 	}
@@ -112,7 +117,8 @@ func DeleteFilesWithLookupVolumeId(grpcDialOption grpc.DialOption, fileIds []str
 	return ret, err
 }
 
-// DeleteFilesAtOneVolumeServer deletes a list of files that is on one volume server via gRpc
+// DeleteFilesAtOneVolumeServer deletes a list of files that is on one volume server via gRpc.
+// The results have the status of each file id, also when some of the file ids failed to delete.
 func DeleteFilesAtOneVolumeServer(volumeServer string, grpcDialOption grpc.DialOption, fileIds []string) (ret []*volume_server_pb.DeleteResult, err error) {
 
 	err = WithVolumeServerClient(volumeServer, grpcDialOption, func(volumeServerClient volume_server_pb.VolumeServerClient) error {
@@ -139,8 +145,8 @@ func DeleteFilesAtOneVolumeServer(volumeServer string, grpcDialOption grpc.DialO
 	}
 
 	for _, result := range ret {
-		if result.Error != "" && result.Error != "not found" {
-			return nil, fmt.Errorf("delete fileId %s: %v", result.FileId, result.Error)
+		if result.Error != "" && result.Status != http.StatusNotFound {
+			return ret, fmt.Errorf("delete fileId %s on %s: %v", result.FileId, volumeServer, result.Error)
 		}
 	}
 
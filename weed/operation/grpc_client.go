@@ -21,10 +21,10 @@ func WithVolumeServerClient(volumeServer string, grpcDialOption grpc.DialOption,
 		return err
 	}
 
-	return util.WithCachedGrpcClient(ctx, func(grpcConnection *grpc.ClientConn) error {
+	return volumeServerClientPool.WithGrpcClient(ctx, grpcAddress, grpcDialOption, func(grpcConnection *grpc.ClientConn) error {
 		client := volume_server_pb.NewVolumeServerClient(grpcConnection)
 		return fn(client)
-	}, grpcAddress, grpcDialOption)
+	})
 
 }
 
@@ -16,13 +16,17 @@ import (
 )
 
 type Location struct {
-	Url       string `json:"url,omitempty"`
-	PublicUrl string `json:"publicUrl,omitempty"`
+	Url        string `json:"url,omitempty"`
+	PublicUrl  string `json:"publicUrl,omitempty"`
+	DataCenter string `json:"dataCenter,omitempty"`
+	Rack       string `json:"rack,omitempty"`
 }
 type LookupResult struct {
-	VolumeId  string     `json:"volumeId,omitempty"`
-	Locations []Location `json:"locations,omitempty"`
-	Error     string     `json:"error,omitempty"`
+	VolumeId        string     `json:"volumeId,omitempty"`
+	Locations       []Location `json:"locations,omitempty"`
+	Error           string     `json:"error,omitempty"`
+	TopologyVersion uint64     `json:"topologyVersion,omitempty"`
+	CacheTtlSeconds uint32     `json:"cacheTtlSeconds,omitempty"`
 }
 
 func (lr *LookupResult) String() string {
@@ -30,14 +34,25 @@ func (lr *LookupResult) String() string {
 }
 
 var (
-	vc VidCache // caching of volume locations, re-check if after 10 minutes
+	vc VidCache // caching of volume locations, re-check if after 10 minutes, or when the topology version changes
 )
 
+const defaultLookupCacheTtl = 10 * time.Minute
+
+// lookupCacheTtl is the ttl from the master, or the default for the older masters
+func lookupCacheTtl(cacheTtlSeconds uint32) time.Duration {
+	if cacheTtlSeconds == 0 {
+		return defaultLookupCacheTtl
+	}
+	return time.Duration(cacheTtlSeconds) * time.Second
+}
+
 func Lookup(server string, vid string) (ret *LookupResult, err error) {
 	locations, cache_err := vc.Get(vid)
 	if cache_err != nil {
 		if ret, err = do_lookup(server, vid); err == nil {
-			vc.Set(vid, ret.Locations, 10*time.Minute)
+			vc.SetVersion(ret.TopologyVersion)
+			vc.Set(vid, ret.Locations, lookupCacheTtl(ret.CacheTtlSeconds))
 		}
 	} else {
 		ret = &LookupResult{VolumeId: vid, Locations: locations}
@@ -109,17 +124,20 @@ func LookupVolumeIds(server string, grpcDialOption grpc.DialOption, vids []strin
 			return grpcErr
 		}
 
-		//set newly checked vids to cache
+		//set newly checked vids to cache, dropping the cached ones if the topology has changed since
+		vc.SetVersion(resp.TopologyVersion)
 		for _, vidLocations := range resp.VolumeIdLocations {
 			var locations []Location
 			for _, loc := range vidLocations.Locations {
 				locations = append(locations, Location{
-					Url:       loc.Url,
-					PublicUrl: loc.PublicUrl,
+					Url:        loc.Url,
+					PublicUrl:  loc.PublicUrl,
+					DataCenter: loc.DataCenter,
+					Rack:       loc.Rack,
 				})
 			}
-			if vidLocations.Error != "" {
-				vc.Set(vidLocations.VolumeId, locations, 10*time.Minute)
+			if vidLocations.Error == "" {
+				vc.Set(vidLocations.VolumeId, locations, lookupCacheTtl(resp.CacheTtlSeconds))
 			}
 			ret[vidLocations.VolumeId] = LookupResult{
 				VolumeId:  vidLocations.VolumeId,
@@ -17,7 +17,8 @@ type VidInfo struct {
 }
 type VidCache struct {
 	sync.RWMutex
-	cache []VidInfo
+	cache   []VidInfo
+	version uint64
 }
 
 func (vc *VidCache) Get(vid string) ([]Location, error) {
@@ -57,3 +58,20 @@ func (vc *VidCache) Set(vid string, locations []Location, duration time.Duration
 		vc.cache[id-1].NextRefreshTime = time.Now().Add(duration)
 	}
 }
+
+// SetVersion drops all the cached locations if the topology version from the master has changed.
+// The version 0 is from the older masters, and changes nothing.
+func (vc *VidCache) SetVersion(version uint64) {
+	if version == 0 {
+		return
+	}
+	vc.Lock()
+	defer vc.Unlock()
+	if vc.version != version {
+		if vc.version != 0 {
+			glog.V(1).Infof("topology version changed from %d to %d, drop %d cached volume locations", vc.version, version, len(vc.cache))
+		}
+		vc.cache = nil
+		vc.version = version
+	}
+}
@@ -24,3 +24,23 @@ func TestCaching(t *testing.T) {
 		t.Fatal("Not found vid 123")
 	}
 }
+
+func TestCachingVersion(t *testing.T) {
+	var (
+		vc VidCache
+	)
+	locations := []Location{{Url: "a.com:8080"}}
+
+	vc.SetVersion(7)
+	vc.Set("3", locations, time.Minute)
+	vc.SetVersion(0)
+	vc.SetVersion(7)
+	if ret, _ := vc.Get("3"); ret == nil {
+		t.Fatal("vid 3 is dropped without the version change")
+	}
+
+	vc.SetVersion(8)
+	if ret, _ := vc.Get("3"); ret != nil {
+		t.Fatal("vid 3 is still cached after the version change")
+	}
+}
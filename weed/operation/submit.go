@@ -13,6 +13,7 @@ import (
 
 	"github.com/chrislusf/seaweedfs/weed/glog"
 	"github.com/chrislusf/seaweedfs/weed/security"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
 )
 
 type FilePart struct {
@@ -118,6 +119,9 @@ func (fi FilePart) Upload(maxMB int, master string, jwt security.EncodedJwt, grp
 		defer closer.Close()
 	}
 	baseName := path.Base(fi.FileName)
+	if maxMB <= 0 && fi.FileSize > needle.MaxNeedleDataSize {
+		return 0, needle.ErrNeedleTooLarge{Size: fi.FileSize, Limit: needle.MaxNeedleDataSize}
+	}
 	if maxMB > 0 && fi.FileSize > int64(maxMB*1024*1024) {
 		chunkSize := int64(maxMB * 1024 * 1024)
 		chunks := fi.FileSize/chunkSize + 1
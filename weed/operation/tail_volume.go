@@ -5,11 +5,17 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/chrislusf/seaweedfs/weed/glog"
 	"github.com/chrislusf/seaweedfs/weed/pb/volume_server_pb"
+	"github.com/chrislusf/seaweedfs/weed/stats"
 	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+	"github.com/chrislusf/seaweedfs/weed/storage/types"
 	"google.golang.org/grpc"
 )
 
+// the times to read a corrupted needle again from the source, before skipping it
+const corruptedNeedleRetries = 3
+
 func TailVolume(master string, grpcDialOption grpc.DialOption, vid needle.VolumeId, sinceNs uint64, timeoutSeconds int, fn func(n *needle.Needle) error) error {
 	// find volume location, replication, ttl info
 	lookup, err := Lookup(master, vid.String())
@@ -25,8 +31,11 @@ func TailVolume(master string, grpcDialOption grpc.DialOption, vid needle.Volume
 	return TailVolumeFromSource(volumeServer, grpcDialOption, vid, sinceNs, timeoutSeconds, fn)
 }
 
+// TailVolumeFromSource calls fn with the needles appended since sinceNs, verified by the crc.
+// The needles still corrupted after reading again are skipped, and reported in the error after the tailing.
 func TailVolumeFromSource(volumeServer string, grpcDialOption grpc.DialOption, vid needle.VolumeId, sinceNs uint64, idleTimeoutSeconds int, fn func(n *needle.Needle) error) error {
-	return WithVolumeServerClient(volumeServer, grpcDialOption, func(client volume_server_pb.VolumeServerClient) error {
+	var skippedNeedleIds []types.NeedleId
+	err := WithVolumeServerClient(volumeServer, grpcDialOption, func(client volume_server_pb.VolumeServerClient) error {
 
 		stream, err := client.VolumeTailSender(context.Background(), &volume_server_pb.VolumeTailSenderRequest{
 			VolumeId:           uint32(vid),
@@ -68,7 +77,17 @@ func TailVolumeFromSource(volumeServer string, grpcDialOption grpc.DialOption, v
 
 			n := new(needle.Needle)
 			n.ParseNeedleHeader(needleHeader)
-			n.ReadNeedleBodyBytes(needleBody, needle.CurrentVersion)
+			if verifyErr := n.VerifyNeedleBodyBytes(needleBody, needle.CurrentVersion); verifyErr != nil {
+				stats.VolumeServerNeedleCorruptionCounter.WithLabelValues("detected").Inc()
+				glog.Warningf("tail volume %d from %s: %v", vid, volumeServer, verifyErr)
+				needleId := n.Id
+				if n = readCorruptedNeedleAgain(client, vid, needleId); n == nil {
+					stats.VolumeServerNeedleCorruptionCounter.WithLabelValues("rejected").Inc()
+					skippedNeedleIds = append(skippedNeedleIds, needleId)
+					continue
+				}
+				stats.VolumeServerNeedleCorruptionCounter.WithLabelValues("repaired").Inc()
+			}
 
 			err = fn(n)
 
@@ -79,4 +98,81 @@ func TailVolumeFromSource(volumeServer string, grpcDialOption grpc.DialOption, v
 		}
 		return nil
 	})
+	if err == nil && len(skippedNeedleIds) > 0 {
+		err = fmt.Errorf("tail volume %d from %s: skipped %d corrupted needles %v", vid, volumeServer, len(skippedNeedleIds), skippedNeedleIds)
+	}
+	return err
+}
+
+// readCorruptedNeedleAgain reads the current version of the needle from the source, verified.
+// It returns nil if the needle is still corrupted, or not on the source any more.
+func readCorruptedNeedleAgain(client volume_server_pb.VolumeServerClient, vid needle.VolumeId, id types.NeedleId) *needle.Needle {
+	for i := 0; i < corruptedNeedleRetries; i++ {
+		needleHeader, needleBody, err := readNeedleBlob(client, vid, id)
+		if err != nil {
+			glog.Warningf("read needle %d,%v again: %v", vid, id, err)
+			continue
+		}
+		if len(needleHeader) == 0 {
+			glog.Errorf("skip the corrupted needle %d,%v: not found on the source any more", vid, id)
+			return nil
+		}
+		n := new(needle.Needle)
+		n.ParseNeedleHeader(needleHeader)
+		if err = n.VerifyNeedleBodyBytes(needleBody, needle.CurrentVersion); err != nil {
+			glog.Warningf("read needle %d,%v again: %v", vid, id, err)
+			continue
+		}
+		return n
+	}
+	glog.Errorf("skip the corrupted needle %d,%v after %d retries", vid, id, corruptedNeedleRetries)
+	return nil
+}
+
+// ReadNeedlesFromSource reads the current version of the needles from the source, verified,
+// and skips the needles not on the source any more.
+func ReadNeedlesFromSource(volumeServer string, grpcDialOption grpc.DialOption, vid needle.VolumeId, ids []types.NeedleId, fn func(n *needle.Needle) error) error {
+	return WithVolumeServerClient(volumeServer, grpcDialOption, func(client volume_server_pb.VolumeServerClient) error {
+		for _, id := range ids {
+			needleHeader, needleBody, err := readNeedleBlob(client, vid, id)
+			if err != nil {
+				return fmt.Errorf("read needle %d,%v: %v", vid, id, err)
+			}
+			if len(needleHeader) == 0 {
+				continue
+			}
+			n := new(needle.Needle)
+			n.ParseNeedleHeader(needleHeader)
+			if verifyErr := n.VerifyNeedleBodyBytes(needleBody, needle.CurrentVersion); verifyErr != nil {
+				if n = readCorruptedNeedleAgain(client, vid, id); n == nil {
+					return fmt.Errorf("read needle %d,%v: %v", vid, id, verifyErr)
+				}
+			}
+			if err = fn(n); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func readNeedleBlob(client volume_server_pb.VolumeServerClient, vid needle.VolumeId, id types.NeedleId) (needleHeader, needleBody []byte, err error) {
+	stream, err := client.ReadNeedleBlob(context.Background(), &volume_server_pb.ReadNeedleBlobRequest{
+		VolumeId: uint32(vid),
+		NeedleId: uint64(id),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	for {
+		resp, recvErr := stream.Recv()
+		if recvErr != nil {
+			return nil, nil, recvErr
+		}
+		needleHeader = resp.NeedleHeader
+		needleBody = append(needleBody, resp.NeedleBody...)
+		if resp.IsLastChunk {
+			return needleHeader, needleBody, nil
+		}
+	}
 }
@@ -23,6 +23,8 @@ It has these top-level messages:
 	CreateEntryResponse
 	UpdateEntryRequest
 	UpdateEntryResponse
+	PatchEntryRequest
+	PatchEntryResponse
 	DeleteEntryRequest
 	DeleteEntryResponse
 	AtomicRenameEntryRequest
@@ -39,6 +41,16 @@ It has these top-level messages:
 	StatisticsResponse
 	GetFilerConfigurationRequest
 	GetFilerConfigurationResponse
+	CreateSnapshotRequest
+	CreateSnapshotResponse
+	DeleteSnapshotRequest
+	DeleteSnapshotResponse
+	SubscribeMetadataRequest
+	SubscribeMetadataResponse
+	AcquireLeaseRequest
+	AcquireLeaseResponse
+	ReleaseLeaseRequest
+	ReleaseLeaseResponse
 */
 package filer_pb
 
@@ -63,8 +75,9 @@ var _ = math.Inf
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
 type LookupDirectoryEntryRequest struct {
-	Directory string `protobuf:"bytes,1,opt,name=directory" json:"directory,omitempty"`
-	Name      string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	Directory       string `protobuf:"bytes,1,opt,name=directory" json:"directory,omitempty"`
+	Name            string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	ReadConsistency string `protobuf:"bytes,3,opt,name=read_consistency,json=readConsistency" json:"read_consistency,omitempty"`
 }
 
 func (m *LookupDirectoryEntryRequest) Reset()                    { *m = LookupDirectoryEntryRequest{} }
@@ -86,6 +99,13 @@ func (m *LookupDirectoryEntryRequest) GetName() string {
 	return ""
 }
 
+func (m *LookupDirectoryEntryRequest) GetReadConsistency() string {
+	if m != nil {
+		return m.ReadConsistency
+	}
+	return ""
+}
+
 type LookupDirectoryEntryResponse struct {
 	Entry *Entry `protobuf:"bytes,1,opt,name=entry" json:"entry,omitempty"`
 }
@@ -103,11 +123,15 @@ func (m *LookupDirectoryEntryResponse) GetEntry() *Entry {
 }
 
 type ListEntriesRequest struct {
-	Directory          string `protobuf:"bytes,1,opt,name=directory" json:"directory,omitempty"`
-	Prefix             string `protobuf:"bytes,2,opt,name=prefix" json:"prefix,omitempty"`
-	StartFromFileName  string `protobuf:"bytes,3,opt,name=startFromFileName" json:"startFromFileName,omitempty"`
-	InclusiveStartFrom bool   `protobuf:"varint,4,opt,name=inclusiveStartFrom" json:"inclusiveStartFrom,omitempty"`
-	Limit              uint32 `protobuf:"varint,5,opt,name=limit" json:"limit,omitempty"`
+	Directory          string   `protobuf:"bytes,1,opt,name=directory" json:"directory,omitempty"`
+	Prefix             string   `protobuf:"bytes,2,opt,name=prefix" json:"prefix,omitempty"`
+	StartFromFileName  string   `protobuf:"bytes,3,opt,name=startFromFileName" json:"startFromFileName,omitempty"`
+	InclusiveStartFrom bool     `protobuf:"varint,4,opt,name=inclusiveStartFrom" json:"inclusiveStartFrom,omitempty"`
+	Limit              uint32   `protobuf:"varint,5,opt,name=limit" json:"limit,omitempty"`
+	ReadConsistency    string   `protobuf:"bytes,6,opt,name=read_consistency,json=readConsistency" json:"read_consistency,omitempty"`
+	SortBy             string   `protobuf:"bytes,7,opt,name=sort_by,json=sortBy" json:"sort_by,omitempty"`
+	SortDesc           bool     `protobuf:"varint,8,opt,name=sort_desc,json=sortDesc" json:"sort_desc,omitempty"`
+	Fields             []string `protobuf:"bytes,9,rep,name=fields" json:"fields,omitempty"`
 }
 
 func (m *ListEntriesRequest) Reset()                    { *m = ListEntriesRequest{} }
@@ -150,6 +174,34 @@ func (m *ListEntriesRequest) GetLimit() uint32 {
 	return 0
 }
 
+func (m *ListEntriesRequest) GetReadConsistency() string {
+	if m != nil {
+		return m.ReadConsistency
+	}
+	return ""
+}
+
+func (m *ListEntriesRequest) GetSortBy() string {
+	if m != nil {
+		return m.SortBy
+	}
+	return ""
+}
+
+func (m *ListEntriesRequest) GetSortDesc() bool {
+	if m != nil {
+		return m.SortDesc
+	}
+	return false
+}
+
+func (m *ListEntriesRequest) GetFields() []string {
+	if m != nil {
+		return m.Fields
+	}
+	return nil
+}
+
 type ListEntriesResponse struct {
 	Entries []*Entry `protobuf:"bytes,1,rep,name=entries" json:"entries,omitempty"`
 }
@@ -287,6 +339,8 @@ type FileChunk struct {
 	SourceFileId string  `protobuf:"bytes,6,opt,name=source_file_id,json=sourceFileId" json:"source_file_id,omitempty"`
 	Fid          *FileId `protobuf:"bytes,7,opt,name=fid" json:"fid,omitempty"`
 	SourceFid    *FileId `protobuf:"bytes,8,opt,name=source_fid,json=sourceFid" json:"source_fid,omitempty"`
+	Compression  string  `protobuf:"bytes,9,opt,name=compression" json:"compression,omitempty"`
+	CipherKey    []byte  `protobuf:"bytes,10,opt,name=cipher_key,json=cipherKey,proto3" json:"cipher_key,omitempty"`
 }
 
 func (m *FileChunk) Reset()                    { *m = FileChunk{} }
@@ -350,6 +404,20 @@ func (m *FileChunk) GetSourceFid() *FileId {
 	return nil
 }
 
+func (m *FileChunk) GetCompression() string {
+	if m != nil {
+		return m.Compression
+	}
+	return ""
+}
+
+func (m *FileChunk) GetCipherKey() []byte {
+	if m != nil {
+		return m.CipherKey
+	}
+	return nil
+}
+
 type FileId struct {
 	VolumeId uint32 `protobuf:"varint,1,opt,name=volume_id,json=volumeId" json:"volume_id,omitempty"`
 	FileKey  uint64 `protobuf:"varint,2,opt,name=file_key,json=fileKey" json:"file_key,omitempty"`
@@ -396,6 +464,10 @@ type FuseAttributes struct {
 	UserName      string   `protobuf:"bytes,11,opt,name=user_name,json=userName" json:"user_name,omitempty"`
 	GroupName     []string `protobuf:"bytes,12,rep,name=group_name,json=groupName" json:"group_name,omitempty"`
 	SymlinkTarget string   `protobuf:"bytes,13,opt,name=symlink_target,json=symlinkTarget" json:"symlink_target,omitempty"`
+	RetentionMode string   `protobuf:"bytes,14,opt,name=retention_mode,json=retentionMode" json:"retention_mode,omitempty"`
+	RetainUntil   int64    `protobuf:"varint,15,opt,name=retain_until,json=retainUntil" json:"retain_until,omitempty"`
+	LegalHold     bool     `protobuf:"varint,16,opt,name=legal_hold,json=legalHold" json:"legal_hold,omitempty"`
+	ExpireAt      int64    `protobuf:"varint,17,opt,name=expire_at,json=expireAt" json:"expire_at,omitempty"`
 }
 
 func (m *FuseAttributes) Reset()                    { *m = FuseAttributes{} }
@@ -494,9 +566,40 @@ func (m *FuseAttributes) GetSymlinkTarget() string {
 	return ""
 }
 
+func (m *FuseAttributes) GetRetentionMode() string {
+	if m != nil {
+		return m.RetentionMode
+	}
+	return ""
+}
+
+func (m *FuseAttributes) GetRetainUntil() int64 {
+	if m != nil {
+		return m.RetainUntil
+	}
+	return 0
+}
+
+func (m *FuseAttributes) GetLegalHold() bool {
+	if m != nil {
+		return m.LegalHold
+	}
+	return false
+}
+
+func (m *FuseAttributes) GetExpireAt() int64 {
+	if m != nil {
+		return m.ExpireAt
+	}
+	return 0
+}
+
 type CreateEntryRequest struct {
-	Directory string `protobuf:"bytes,1,opt,name=directory" json:"directory,omitempty"`
-	Entry     *Entry `protobuf:"bytes,2,opt,name=entry" json:"entry,omitempty"`
+	Directory    string `protobuf:"bytes,1,opt,name=directory" json:"directory,omitempty"`
+	Entry        *Entry `protobuf:"bytes,2,opt,name=entry" json:"entry,omitempty"`
+	OExcl        bool   `protobuf:"varint,3,opt,name=o_excl,json=oExcl" json:"o_excl,omitempty"`
+	LeaseHolder  string `protobuf:"bytes,4,opt,name=lease_holder,json=leaseHolder" json:"lease_holder,omitempty"`
+	IsReplicated bool   `protobuf:"varint,5,opt,name=is_replicated,json=isReplicated" json:"is_replicated,omitempty"`
 }
 
 func (m *CreateEntryRequest) Reset()                    { *m = CreateEntryRequest{} }
@@ -518,6 +621,27 @@ func (m *CreateEntryRequest) GetEntry() *Entry {
 	return nil
 }
 
+func (m *CreateEntryRequest) GetOExcl() bool {
+	if m != nil {
+		return m.OExcl
+	}
+	return false
+}
+
+func (m *CreateEntryRequest) GetLeaseHolder() string {
+	if m != nil {
+		return m.LeaseHolder
+	}
+	return ""
+}
+
+func (m *CreateEntryRequest) GetIsReplicated() bool {
+	if m != nil {
+		return m.IsReplicated
+	}
+	return false
+}
+
 type CreateEntryResponse struct {
 }
 
@@ -527,8 +651,11 @@ func (*CreateEntryResponse) ProtoMessage()               {}
 func (*CreateEntryResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
 
 type UpdateEntryRequest struct {
-	Directory string `protobuf:"bytes,1,opt,name=directory" json:"directory,omitempty"`
-	Entry     *Entry `protobuf:"bytes,2,opt,name=entry" json:"entry,omitempty"`
+	Directory                 string `protobuf:"bytes,1,opt,name=directory" json:"directory,omitempty"`
+	Entry                     *Entry `protobuf:"bytes,2,opt,name=entry" json:"entry,omitempty"`
+	BypassGovernanceRetention bool   `protobuf:"varint,3,opt,name=bypass_governance_retention,json=bypassGovernanceRetention" json:"bypass_governance_retention,omitempty"`
+	LeaseHolder               string `protobuf:"bytes,4,opt,name=lease_holder,json=leaseHolder" json:"lease_holder,omitempty"`
+	IsReplicated              bool   `protobuf:"varint,5,opt,name=is_replicated,json=isReplicated" json:"is_replicated,omitempty"`
 }
 
 func (m *UpdateEntryRequest) Reset()                    { *m = UpdateEntryRequest{} }
@@ -550,6 +677,27 @@ func (m *UpdateEntryRequest) GetEntry() *Entry {
 	return nil
 }
 
+func (m *UpdateEntryRequest) GetBypassGovernanceRetention() bool {
+	if m != nil {
+		return m.BypassGovernanceRetention
+	}
+	return false
+}
+
+func (m *UpdateEntryRequest) GetLeaseHolder() string {
+	if m != nil {
+		return m.LeaseHolder
+	}
+	return ""
+}
+
+func (m *UpdateEntryRequest) GetIsReplicated() bool {
+	if m != nil {
+		return m.IsReplicated
+	}
+	return false
+}
+
 type UpdateEntryResponse struct {
 }
 
@@ -558,18 +706,77 @@ func (m *UpdateEntryResponse) String() string            { return proto.CompactT
 func (*UpdateEntryResponse) ProtoMessage()               {}
 func (*UpdateEntryResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
 
+// the chunks overwrite the byte ranges of the existing file, in their order
+type PatchEntryRequest struct {
+	Directory                 string       `protobuf:"bytes,1,opt,name=directory" json:"directory,omitempty"`
+	Name                      string       `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	Chunks                    []*FileChunk `protobuf:"bytes,3,rep,name=chunks" json:"chunks,omitempty"`
+	BypassGovernanceRetention bool         `protobuf:"varint,4,opt,name=bypass_governance_retention,json=bypassGovernanceRetention" json:"bypass_governance_retention,omitempty"`
+	LeaseHolder               string       `protobuf:"bytes,5,opt,name=lease_holder,json=leaseHolder" json:"lease_holder,omitempty"`
+}
+
+func (m *PatchEntryRequest) Reset()                    { *m = PatchEntryRequest{} }
+func (m *PatchEntryRequest) String() string            { return proto.CompactTextString(m) }
+func (*PatchEntryRequest) ProtoMessage()               {}
+func (*PatchEntryRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+func (m *PatchEntryRequest) GetDirectory() string {
+	if m != nil {
+		return m.Directory
+	}
+	return ""
+}
+
+func (m *PatchEntryRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *PatchEntryRequest) GetChunks() []*FileChunk {
+	if m != nil {
+		return m.Chunks
+	}
+	return nil
+}
+
+func (m *PatchEntryRequest) GetBypassGovernanceRetention() bool {
+	if m != nil {
+		return m.BypassGovernanceRetention
+	}
+	return false
+}
+
+func (m *PatchEntryRequest) GetLeaseHolder() string {
+	if m != nil {
+		return m.LeaseHolder
+	}
+	return ""
+}
+
+type PatchEntryResponse struct {
+}
+
+func (m *PatchEntryResponse) Reset()                    { *m = PatchEntryResponse{} }
+func (m *PatchEntryResponse) String() string            { return proto.CompactTextString(m) }
+func (*PatchEntryResponse) ProtoMessage()               {}
+func (*PatchEntryResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
 type DeleteEntryRequest struct {
 	Directory string `protobuf:"bytes,1,opt,name=directory" json:"directory,omitempty"`
 	Name      string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
 	// bool is_directory = 3;
-	IsDeleteData bool `protobuf:"varint,4,opt,name=is_delete_data,json=isDeleteData" json:"is_delete_data,omitempty"`
-	IsRecursive  bool `protobuf:"varint,5,opt,name=is_recursive,json=isRecursive" json:"is_recursive,omitempty"`
+	IsDeleteData              bool `protobuf:"varint,4,opt,name=is_delete_data,json=isDeleteData" json:"is_delete_data,omitempty"`
+	IsRecursive               bool `protobuf:"varint,5,opt,name=is_recursive,json=isRecursive" json:"is_recursive,omitempty"`
+	BypassGovernanceRetention bool   `protobuf:"varint,6,opt,name=bypass_governance_retention,json=bypassGovernanceRetention" json:"bypass_governance_retention,omitempty"`
+	LeaseHolder               string `protobuf:"bytes,7,opt,name=lease_holder,json=leaseHolder" json:"lease_holder,omitempty"`
 }
 
 func (m *DeleteEntryRequest) Reset()                    { *m = DeleteEntryRequest{} }
 func (m *DeleteEntryRequest) String() string            { return proto.CompactTextString(m) }
 func (*DeleteEntryRequest) ProtoMessage()               {}
-func (*DeleteEntryRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+func (*DeleteEntryRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
 
 func (m *DeleteEntryRequest) GetDirectory() string {
 	if m != nil {
@@ -599,25 +806,40 @@ func (m *DeleteEntryRequest) GetIsRecursive() bool {
 	return false
 }
 
+func (m *DeleteEntryRequest) GetBypassGovernanceRetention() bool {
+	if m != nil {
+		return m.BypassGovernanceRetention
+	}
+	return false
+}
+
+func (m *DeleteEntryRequest) GetLeaseHolder() string {
+	if m != nil {
+		return m.LeaseHolder
+	}
+	return ""
+}
+
 type DeleteEntryResponse struct {
 }
 
 func (m *DeleteEntryResponse) Reset()                    { *m = DeleteEntryResponse{} }
 func (m *DeleteEntryResponse) String() string            { return proto.CompactTextString(m) }
 func (*DeleteEntryResponse) ProtoMessage()               {}
-func (*DeleteEntryResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+func (*DeleteEntryResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
 
 type AtomicRenameEntryRequest struct {
 	OldDirectory string `protobuf:"bytes,1,opt,name=old_directory,json=oldDirectory" json:"old_directory,omitempty"`
 	OldName      string `protobuf:"bytes,2,opt,name=old_name,json=oldName" json:"old_name,omitempty"`
 	NewDirectory string `protobuf:"bytes,3,opt,name=new_directory,json=newDirectory" json:"new_directory,omitempty"`
 	NewName      string `protobuf:"bytes,4,opt,name=new_name,json=newName" json:"new_name,omitempty"`
+	LeaseHolder  string `protobuf:"bytes,5,opt,name=lease_holder,json=leaseHolder" json:"lease_holder,omitempty"`
 }
 
 func (m *AtomicRenameEntryRequest) Reset()                    { *m = AtomicRenameEntryRequest{} }
 func (m *AtomicRenameEntryRequest) String() string            { return proto.CompactTextString(m) }
 func (*AtomicRenameEntryRequest) ProtoMessage()               {}
-func (*AtomicRenameEntryRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+func (*AtomicRenameEntryRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
 
 func (m *AtomicRenameEntryRequest) GetOldDirectory() string {
 	if m != nil {
@@ -647,26 +869,34 @@ func (m *AtomicRenameEntryRequest) GetNewName() string {
 	return ""
 }
 
+func (m *AtomicRenameEntryRequest) GetLeaseHolder() string {
+	if m != nil {
+		return m.LeaseHolder
+	}
+	return ""
+}
+
 type AtomicRenameEntryResponse struct {
 }
 
 func (m *AtomicRenameEntryResponse) Reset()                    { *m = AtomicRenameEntryResponse{} }
 func (m *AtomicRenameEntryResponse) String() string            { return proto.CompactTextString(m) }
 func (*AtomicRenameEntryResponse) ProtoMessage()               {}
-func (*AtomicRenameEntryResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+func (*AtomicRenameEntryResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
 
 type AssignVolumeRequest struct {
-	Count       int32  `protobuf:"varint,1,opt,name=count" json:"count,omitempty"`
-	Collection  string `protobuf:"bytes,2,opt,name=collection" json:"collection,omitempty"`
-	Replication string `protobuf:"bytes,3,opt,name=replication" json:"replication,omitempty"`
-	TtlSec      int32  `protobuf:"varint,4,opt,name=ttl_sec,json=ttlSec" json:"ttl_sec,omitempty"`
-	DataCenter  string `protobuf:"bytes,5,opt,name=data_center,json=dataCenter" json:"data_center,omitempty"`
+	Count         int32  `protobuf:"varint,1,opt,name=count" json:"count,omitempty"`
+	Collection    string `protobuf:"bytes,2,opt,name=collection" json:"collection,omitempty"`
+	Replication   string `protobuf:"bytes,3,opt,name=replication" json:"replication,omitempty"`
+	TtlSec        int32  `protobuf:"varint,4,opt,name=ttl_sec,json=ttlSec" json:"ttl_sec,omitempty"`
+	DataCenter    string `protobuf:"bytes,5,opt,name=data_center,json=dataCenter" json:"data_center,omitempty"`
+	LocalityGroup string `protobuf:"bytes,6,opt,name=locality_group,json=localityGroup" json:"locality_group,omitempty"`
 }
 
 func (m *AssignVolumeRequest) Reset()                    { *m = AssignVolumeRequest{} }
 func (m *AssignVolumeRequest) String() string            { return proto.CompactTextString(m) }
 func (*AssignVolumeRequest) ProtoMessage()               {}
-func (*AssignVolumeRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+func (*AssignVolumeRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
 
 func (m *AssignVolumeRequest) GetCount() int32 {
 	if m != nil {
@@ -703,6 +933,13 @@ func (m *AssignVolumeRequest) GetDataCenter() string {
 	return ""
 }
 
+func (m *AssignVolumeRequest) GetLocalityGroup() string {
+	if m != nil {
+		return m.LocalityGroup
+	}
+	return ""
+}
+
 type AssignVolumeResponse struct {
 	FileId    string `protobuf:"bytes,1,opt,name=file_id,json=fileId" json:"file_id,omitempty"`
 	Url       string `protobuf:"bytes,2,opt,name=url" json:"url,omitempty"`
@@ -714,7 +951,7 @@ type AssignVolumeResponse struct {
 func (m *AssignVolumeResponse) Reset()                    { *m = AssignVolumeResponse{} }
 func (m *AssignVolumeResponse) String() string            { return proto.CompactTextString(m) }
 func (*AssignVolumeResponse) ProtoMessage()               {}
-func (*AssignVolumeResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+func (*AssignVolumeResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
 
 func (m *AssignVolumeResponse) GetFileId() string {
 	if m != nil {
@@ -758,7 +995,7 @@ type LookupVolumeRequest struct {
 func (m *LookupVolumeRequest) Reset()                    { *m = LookupVolumeRequest{} }
 func (m *LookupVolumeRequest) String() string            { return proto.CompactTextString(m) }
 func (*LookupVolumeRequest) ProtoMessage()               {}
-func (*LookupVolumeRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+func (*LookupVolumeRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
 
 func (m *LookupVolumeRequest) GetVolumeIds() []string {
 	if m != nil {
@@ -774,7 +1011,7 @@ type Locations struct {
 func (m *Locations) Reset()                    { *m = Locations{} }
 func (m *Locations) String() string            { return proto.CompactTextString(m) }
 func (*Locations) ProtoMessage()               {}
-func (*Locations) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
+func (*Locations) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
 
 func (m *Locations) GetLocations() []*Location {
 	if m != nil {
@@ -786,12 +1023,15 @@ func (m *Locations) GetLocations() []*Location {
 type Location struct {
 	Url       string `protobuf:"bytes,1,opt,name=url" json:"url,omitempty"`
 	PublicUrl string `protobuf:"bytes,2,opt,name=public_url,json=publicUrl" json:"public_url,omitempty"`
+	// the topology of the volume server, for the clients to schedule the reads near the data
+	DataCenter string `protobuf:"bytes,3,opt,name=data_center,json=dataCenter" json:"data_center,omitempty"`
+	Rack       string `protobuf:"bytes,4,opt,name=rack" json:"rack,omitempty"`
 }
 
 func (m *Location) Reset()                    { *m = Location{} }
 func (m *Location) String() string            { return proto.CompactTextString(m) }
 func (*Location) ProtoMessage()               {}
-func (*Location) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+func (*Location) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
 
 func (m *Location) GetUrl() string {
 	if m != nil {
@@ -807,6 +1047,20 @@ func (m *Location) GetPublicUrl() string {
 	return ""
 }
 
+func (m *Location) GetDataCenter() string {
+	if m != nil {
+		return m.DataCenter
+	}
+	return ""
+}
+
+func (m *Location) GetRack() string {
+	if m != nil {
+		return m.Rack
+	}
+	return ""
+}
+
 type LookupVolumeResponse struct {
 	LocationsMap map[string]*Locations `protobuf:"bytes,1,rep,name=locations_map,json=locationsMap" json:"locations_map,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 }
@@ -814,7 +1068,7 @@ type LookupVolumeResponse struct {
 func (m *LookupVolumeResponse) Reset()                    { *m = LookupVolumeResponse{} }
 func (m *LookupVolumeResponse) String() string            { return proto.CompactTextString(m) }
 func (*LookupVolumeResponse) ProtoMessage()               {}
-func (*LookupVolumeResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
+func (*LookupVolumeResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
 
 func (m *LookupVolumeResponse) GetLocationsMap() map[string]*Locations {
 	if m != nil {
@@ -830,7 +1084,7 @@ type DeleteCollectionRequest struct {
 func (m *DeleteCollectionRequest) Reset()                    { *m = DeleteCollectionRequest{} }
 func (m *DeleteCollectionRequest) String() string            { return proto.CompactTextString(m) }
 func (*DeleteCollectionRequest) ProtoMessage()               {}
-func (*DeleteCollectionRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
+func (*DeleteCollectionRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
 
 func (m *DeleteCollectionRequest) GetCollection() string {
 	if m != nil {
@@ -845,7 +1099,7 @@ type DeleteCollectionResponse struct {
 func (m *DeleteCollectionResponse) Reset()                    { *m = DeleteCollectionResponse{} }
 func (m *DeleteCollectionResponse) String() string            { return proto.CompactTextString(m) }
 func (*DeleteCollectionResponse) ProtoMessage()               {}
-func (*DeleteCollectionResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
+func (*DeleteCollectionResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{27} }
 
 type StatisticsRequest struct {
 	Replication string `protobuf:"bytes,1,opt,name=replication" json:"replication,omitempty"`
@@ -856,7 +1110,7 @@ type StatisticsRequest struct {
 func (m *StatisticsRequest) Reset()                    { *m = StatisticsRequest{} }
 func (m *StatisticsRequest) String() string            { return proto.CompactTextString(m) }
 func (*StatisticsRequest) ProtoMessage()               {}
-func (*StatisticsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
+func (*StatisticsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{28} }
 
 func (m *StatisticsRequest) GetReplication() string {
 	if m != nil {
@@ -891,7 +1145,7 @@ type StatisticsResponse struct {
 func (m *StatisticsResponse) Reset()                    { *m = StatisticsResponse{} }
 func (m *StatisticsResponse) String() string            { return proto.CompactTextString(m) }
 func (*StatisticsResponse) ProtoMessage()               {}
-func (*StatisticsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{27} }
+func (*StatisticsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{29} }
 
 func (m *StatisticsResponse) GetReplication() string {
 	if m != nil {
@@ -941,7 +1195,7 @@ type GetFilerConfigurationRequest struct {
 func (m *GetFilerConfigurationRequest) Reset()                    { *m = GetFilerConfigurationRequest{} }
 func (m *GetFilerConfigurationRequest) String() string            { return proto.CompactTextString(m) }
 func (*GetFilerConfigurationRequest) ProtoMessage()               {}
-func (*GetFilerConfigurationRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{28} }
+func (*GetFilerConfigurationRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{30} }
 
 type GetFilerConfigurationResponse struct {
 	Masters     []string `protobuf:"bytes,1,rep,name=masters" json:"masters,omitempty"`
@@ -953,7 +1207,7 @@ type GetFilerConfigurationResponse struct {
 func (m *GetFilerConfigurationResponse) Reset()                    { *m = GetFilerConfigurationResponse{} }
 func (m *GetFilerConfigurationResponse) String() string            { return proto.CompactTextString(m) }
 func (*GetFilerConfigurationResponse) ProtoMessage()               {}
-func (*GetFilerConfigurationResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{29} }
+func (*GetFilerConfigurationResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{31} }
 
 func (m *GetFilerConfigurationResponse) GetMasters() []string {
 	if m != nil {
@@ -983,6 +1237,241 @@ func (m *GetFilerConfigurationResponse) GetMaxMb() uint32 {
 	return 0
 }
 
+type CreateSnapshotRequest struct {
+	Directory string `protobuf:"bytes,1,opt,name=directory" json:"directory,omitempty"`
+	Name      string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+}
+
+func (m *CreateSnapshotRequest) Reset()                    { *m = CreateSnapshotRequest{} }
+func (m *CreateSnapshotRequest) String() string            { return proto.CompactTextString(m) }
+func (*CreateSnapshotRequest) ProtoMessage()               {}
+func (*CreateSnapshotRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{32} }
+
+func (m *CreateSnapshotRequest) GetDirectory() string {
+	if m != nil {
+		return m.Directory
+	}
+	return ""
+}
+
+func (m *CreateSnapshotRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type CreateSnapshotResponse struct {
+	EntryCount uint64 `protobuf:"varint,1,opt,name=entry_count,json=entryCount" json:"entry_count,omitempty"`
+	ChunkCount uint64 `protobuf:"varint,2,opt,name=chunk_count,json=chunkCount" json:"chunk_count,omitempty"`
+}
+
+func (m *CreateSnapshotResponse) Reset()                    { *m = CreateSnapshotResponse{} }
+func (m *CreateSnapshotResponse) String() string            { return proto.CompactTextString(m) }
+func (*CreateSnapshotResponse) ProtoMessage()               {}
+func (*CreateSnapshotResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{33} }
+
+func (m *CreateSnapshotResponse) GetEntryCount() uint64 {
+	if m != nil {
+		return m.EntryCount
+	}
+	return 0
+}
+
+func (m *CreateSnapshotResponse) GetChunkCount() uint64 {
+	if m != nil {
+		return m.ChunkCount
+	}
+	return 0
+}
+
+type DeleteSnapshotRequest struct {
+	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+}
+
+func (m *DeleteSnapshotRequest) Reset()                    { *m = DeleteSnapshotRequest{} }
+func (m *DeleteSnapshotRequest) String() string            { return proto.CompactTextString(m) }
+func (*DeleteSnapshotRequest) ProtoMessage()               {}
+func (*DeleteSnapshotRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34} }
+
+func (m *DeleteSnapshotRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type DeleteSnapshotResponse struct {
+	DeletedChunkCount uint64 `protobuf:"varint,1,opt,name=deleted_chunk_count,json=deletedChunkCount" json:"deleted_chunk_count,omitempty"`
+}
+
+func (m *DeleteSnapshotResponse) Reset()                    { *m = DeleteSnapshotResponse{} }
+func (m *DeleteSnapshotResponse) String() string            { return proto.CompactTextString(m) }
+func (*DeleteSnapshotResponse) ProtoMessage()               {}
+func (*DeleteSnapshotResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{35} }
+
+func (m *DeleteSnapshotResponse) GetDeletedChunkCount() uint64 {
+	if m != nil {
+		return m.DeletedChunkCount
+	}
+	return 0
+}
+
+type SubscribeMetadataRequest struct {
+	ClientName string `protobuf:"bytes,1,opt,name=client_name,json=clientName" json:"client_name,omitempty"`
+	PathPrefix string `protobuf:"bytes,2,opt,name=path_prefix,json=pathPrefix" json:"path_prefix,omitempty"`
+}
+
+func (m *SubscribeMetadataRequest) Reset()                    { *m = SubscribeMetadataRequest{} }
+func (m *SubscribeMetadataRequest) String() string            { return proto.CompactTextString(m) }
+func (*SubscribeMetadataRequest) ProtoMessage()               {}
+func (*SubscribeMetadataRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{36} }
+
+func (m *SubscribeMetadataRequest) GetClientName() string {
+	if m != nil {
+		return m.ClientName
+	}
+	return ""
+}
+
+func (m *SubscribeMetadataRequest) GetPathPrefix() string {
+	if m != nil {
+		return m.PathPrefix
+	}
+	return ""
+}
+
+type SubscribeMetadataResponse struct {
+	Directory         string             `protobuf:"bytes,1,opt,name=directory" json:"directory,omitempty"`
+	EventNotification *EventNotification `protobuf:"bytes,2,opt,name=event_notification,json=eventNotification" json:"event_notification,omitempty"`
+	TsNs              int64              `protobuf:"varint,3,opt,name=ts_ns,json=tsNs" json:"ts_ns,omitempty"`
+}
+
+func (m *SubscribeMetadataResponse) Reset()                    { *m = SubscribeMetadataResponse{} }
+func (m *SubscribeMetadataResponse) String() string            { return proto.CompactTextString(m) }
+func (*SubscribeMetadataResponse) ProtoMessage()               {}
+func (*SubscribeMetadataResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{37} }
+
+func (m *SubscribeMetadataResponse) GetDirectory() string {
+	if m != nil {
+		return m.Directory
+	}
+	return ""
+}
+
+func (m *SubscribeMetadataResponse) GetEventNotification() *EventNotification {
+	if m != nil {
+		return m.EventNotification
+	}
+	return nil
+}
+
+func (m *SubscribeMetadataResponse) GetTsNs() int64 {
+	if m != nil {
+		return m.TsNs
+	}
+	return 0
+}
+
+// the lease lets only its holder write the file, e.g. to append to it, until released or expired.
+// The writes by the other holders, or without any holder, fail with FailedPrecondition.
+// The holder renews the lease by acquiring it again.
+type AcquireLeaseRequest struct {
+	Directory    string `protobuf:"bytes,1,opt,name=directory" json:"directory,omitempty"`
+	Name         string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	Holder       string `protobuf:"bytes,3,opt,name=holder" json:"holder,omitempty"`
+	LeaseSeconds int32  `protobuf:"varint,4,opt,name=lease_seconds,json=leaseSeconds" json:"lease_seconds,omitempty"`
+}
+
+func (m *AcquireLeaseRequest) Reset()                    { *m = AcquireLeaseRequest{} }
+func (m *AcquireLeaseRequest) String() string            { return proto.CompactTextString(m) }
+func (*AcquireLeaseRequest) ProtoMessage()               {}
+func (*AcquireLeaseRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{38} }
+
+func (m *AcquireLeaseRequest) GetDirectory() string {
+	if m != nil {
+		return m.Directory
+	}
+	return ""
+}
+
+func (m *AcquireLeaseRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *AcquireLeaseRequest) GetHolder() string {
+	if m != nil {
+		return m.Holder
+	}
+	return ""
+}
+
+func (m *AcquireLeaseRequest) GetLeaseSeconds() int32 {
+	if m != nil {
+		return m.LeaseSeconds
+	}
+	return 0
+}
+
+type AcquireLeaseResponse struct {
+	ExpireAtNs int64 `protobuf:"varint,1,opt,name=expire_at_ns,json=expireAtNs" json:"expire_at_ns,omitempty"`
+}
+
+func (m *AcquireLeaseResponse) Reset()                    { *m = AcquireLeaseResponse{} }
+func (m *AcquireLeaseResponse) String() string            { return proto.CompactTextString(m) }
+func (*AcquireLeaseResponse) ProtoMessage()               {}
+func (*AcquireLeaseResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{39} }
+
+func (m *AcquireLeaseResponse) GetExpireAtNs() int64 {
+	if m != nil {
+		return m.ExpireAtNs
+	}
+	return 0
+}
+
+type ReleaseLeaseRequest struct {
+	Directory string `protobuf:"bytes,1,opt,name=directory" json:"directory,omitempty"`
+	Name      string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	Holder    string `protobuf:"bytes,3,opt,name=holder" json:"holder,omitempty"`
+}
+
+func (m *ReleaseLeaseRequest) Reset()                    { *m = ReleaseLeaseRequest{} }
+func (m *ReleaseLeaseRequest) String() string            { return proto.CompactTextString(m) }
+func (*ReleaseLeaseRequest) ProtoMessage()               {}
+func (*ReleaseLeaseRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{40} }
+
+func (m *ReleaseLeaseRequest) GetDirectory() string {
+	if m != nil {
+		return m.Directory
+	}
+	return ""
+}
+
+func (m *ReleaseLeaseRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ReleaseLeaseRequest) GetHolder() string {
+	if m != nil {
+		return m.Holder
+	}
+	return ""
+}
+
+type ReleaseLeaseResponse struct {
+}
+
+func (m *ReleaseLeaseResponse) Reset()                    { *m = ReleaseLeaseResponse{} }
+func (m *ReleaseLeaseResponse) String() string            { return proto.CompactTextString(m) }
+func (*ReleaseLeaseResponse) ProtoMessage()               {}
+func (*ReleaseLeaseResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{41} }
+
 func init() {
 	proto.RegisterType((*LookupDirectoryEntryRequest)(nil), "filer_pb.LookupDirectoryEntryRequest")
 	proto.RegisterType((*LookupDirectoryEntryResponse)(nil), "filer_pb.LookupDirectoryEntryResponse")
@@ -998,6 +1487,8 @@ func init() {
 	proto.RegisterType((*CreateEntryResponse)(nil), "filer_pb.CreateEntryResponse")
 	proto.RegisterType((*UpdateEntryRequest)(nil), "filer_pb.UpdateEntryRequest")
 	proto.RegisterType((*UpdateEntryResponse)(nil), "filer_pb.UpdateEntryResponse")
+	proto.RegisterType((*PatchEntryRequest)(nil), "filer_pb.PatchEntryRequest")
+	proto.RegisterType((*PatchEntryResponse)(nil), "filer_pb.PatchEntryResponse")
 	proto.RegisterType((*DeleteEntryRequest)(nil), "filer_pb.DeleteEntryRequest")
 	proto.RegisterType((*DeleteEntryResponse)(nil), "filer_pb.DeleteEntryResponse")
 	proto.RegisterType((*AtomicRenameEntryRequest)(nil), "filer_pb.AtomicRenameEntryRequest")
@@ -1014,6 +1505,16 @@ func init() {
 	proto.RegisterType((*StatisticsResponse)(nil), "filer_pb.StatisticsResponse")
 	proto.RegisterType((*GetFilerConfigurationRequest)(nil), "filer_pb.GetFilerConfigurationRequest")
 	proto.RegisterType((*GetFilerConfigurationResponse)(nil), "filer_pb.GetFilerConfigurationResponse")
+	proto.RegisterType((*CreateSnapshotRequest)(nil), "filer_pb.CreateSnapshotRequest")
+	proto.RegisterType((*CreateSnapshotResponse)(nil), "filer_pb.CreateSnapshotResponse")
+	proto.RegisterType((*DeleteSnapshotRequest)(nil), "filer_pb.DeleteSnapshotRequest")
+	proto.RegisterType((*DeleteSnapshotResponse)(nil), "filer_pb.DeleteSnapshotResponse")
+	proto.RegisterType((*SubscribeMetadataRequest)(nil), "filer_pb.SubscribeMetadataRequest")
+	proto.RegisterType((*SubscribeMetadataResponse)(nil), "filer_pb.SubscribeMetadataResponse")
+	proto.RegisterType((*AcquireLeaseRequest)(nil), "filer_pb.AcquireLeaseRequest")
+	proto.RegisterType((*AcquireLeaseResponse)(nil), "filer_pb.AcquireLeaseResponse")
+	proto.RegisterType((*ReleaseLeaseRequest)(nil), "filer_pb.ReleaseLeaseRequest")
+	proto.RegisterType((*ReleaseLeaseResponse)(nil), "filer_pb.ReleaseLeaseResponse")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -1038,6 +1539,12 @@ type SeaweedFilerClient interface {
 	DeleteCollection(ctx context.Context, in *DeleteCollectionRequest, opts ...grpc.CallOption) (*DeleteCollectionResponse, error)
 	Statistics(ctx context.Context, in *StatisticsRequest, opts ...grpc.CallOption) (*StatisticsResponse, error)
 	GetFilerConfiguration(ctx context.Context, in *GetFilerConfigurationRequest, opts ...grpc.CallOption) (*GetFilerConfigurationResponse, error)
+	CreateSnapshot(ctx context.Context, in *CreateSnapshotRequest, opts ...grpc.CallOption) (*CreateSnapshotResponse, error)
+	DeleteSnapshot(ctx context.Context, in *DeleteSnapshotRequest, opts ...grpc.CallOption) (*DeleteSnapshotResponse, error)
+	PatchEntry(ctx context.Context, in *PatchEntryRequest, opts ...grpc.CallOption) (*PatchEntryResponse, error)
+	SubscribeMetadata(ctx context.Context, in *SubscribeMetadataRequest, opts ...grpc.CallOption) (SeaweedFiler_SubscribeMetadataClient, error)
+	AcquireLease(ctx context.Context, in *AcquireLeaseRequest, opts ...grpc.CallOption) (*AcquireLeaseResponse, error)
+	ReleaseLease(ctx context.Context, in *ReleaseLeaseRequest, opts ...grpc.CallOption) (*ReleaseLeaseResponse, error)
 }
 
 type seaweedFilerClient struct {
@@ -1147,6 +1654,83 @@ func (c *seaweedFilerClient) GetFilerConfiguration(ctx context.Context, in *GetF
 	return out, nil
 }
 
+func (c *seaweedFilerClient) CreateSnapshot(ctx context.Context, in *CreateSnapshotRequest, opts ...grpc.CallOption) (*CreateSnapshotResponse, error) {
+	out := new(CreateSnapshotResponse)
+	err := grpc.Invoke(ctx, "/filer_pb.SeaweedFiler/CreateSnapshot", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *seaweedFilerClient) DeleteSnapshot(ctx context.Context, in *DeleteSnapshotRequest, opts ...grpc.CallOption) (*DeleteSnapshotResponse, error) {
+	out := new(DeleteSnapshotResponse)
+	err := grpc.Invoke(ctx, "/filer_pb.SeaweedFiler/DeleteSnapshot", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *seaweedFilerClient) PatchEntry(ctx context.Context, in *PatchEntryRequest, opts ...grpc.CallOption) (*PatchEntryResponse, error) {
+	out := new(PatchEntryResponse)
+	err := grpc.Invoke(ctx, "/filer_pb.SeaweedFiler/PatchEntry", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *seaweedFilerClient) SubscribeMetadata(ctx context.Context, in *SubscribeMetadataRequest, opts ...grpc.CallOption) (SeaweedFiler_SubscribeMetadataClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_SeaweedFiler_serviceDesc.Streams[0], c.cc, "/filer_pb.SeaweedFiler/SubscribeMetadata", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &seaweedFilerSubscribeMetadataClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type SeaweedFiler_SubscribeMetadataClient interface {
+	Recv() (*SubscribeMetadataResponse, error)
+	grpc.ClientStream
+}
+
+type seaweedFilerSubscribeMetadataClient struct {
+	grpc.ClientStream
+}
+
+func (x *seaweedFilerSubscribeMetadataClient) Recv() (*SubscribeMetadataResponse, error) {
+	m := new(SubscribeMetadataResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *seaweedFilerClient) AcquireLease(ctx context.Context, in *AcquireLeaseRequest, opts ...grpc.CallOption) (*AcquireLeaseResponse, error) {
+	out := new(AcquireLeaseResponse)
+	err := grpc.Invoke(ctx, "/filer_pb.SeaweedFiler/AcquireLease", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *seaweedFilerClient) ReleaseLease(ctx context.Context, in *ReleaseLeaseRequest, opts ...grpc.CallOption) (*ReleaseLeaseResponse, error) {
+	out := new(ReleaseLeaseResponse)
+	err := grpc.Invoke(ctx, "/filer_pb.SeaweedFiler/ReleaseLease", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for SeaweedFiler service
 
 type SeaweedFilerServer interface {
@@ -1161,6 +1745,12 @@ type SeaweedFilerServer interface {
 	DeleteCollection(context.Context, *DeleteCollectionRequest) (*DeleteCollectionResponse, error)
 	Statistics(context.Context, *StatisticsRequest) (*StatisticsResponse, error)
 	GetFilerConfiguration(context.Context, *GetFilerConfigurationRequest) (*GetFilerConfigurationResponse, error)
+	CreateSnapshot(context.Context, *CreateSnapshotRequest) (*CreateSnapshotResponse, error)
+	DeleteSnapshot(context.Context, *DeleteSnapshotRequest) (*DeleteSnapshotResponse, error)
+	PatchEntry(context.Context, *PatchEntryRequest) (*PatchEntryResponse, error)
+	SubscribeMetadata(*SubscribeMetadataRequest, SeaweedFiler_SubscribeMetadataServer) error
+	AcquireLease(context.Context, *AcquireLeaseRequest) (*AcquireLeaseResponse, error)
+	ReleaseLease(context.Context, *ReleaseLeaseRequest) (*ReleaseLeaseResponse, error)
 }
 
 func RegisterSeaweedFilerServer(s *grpc.Server, srv SeaweedFilerServer) {
@@ -1365,6 +1955,117 @@ func _SeaweedFiler_GetFilerConfiguration_Handler(srv interface{}, ctx context.Co
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SeaweedFiler_CreateSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SeaweedFilerServer).CreateSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/filer_pb.SeaweedFiler/CreateSnapshot",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SeaweedFilerServer).CreateSnapshot(ctx, req.(*CreateSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SeaweedFiler_DeleteSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SeaweedFilerServer).DeleteSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/filer_pb.SeaweedFiler/DeleteSnapshot",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SeaweedFilerServer).DeleteSnapshot(ctx, req.(*DeleteSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SeaweedFiler_PatchEntry_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PatchEntryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SeaweedFilerServer).PatchEntry(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/filer_pb.SeaweedFiler/PatchEntry",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SeaweedFilerServer).PatchEntry(ctx, req.(*PatchEntryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SeaweedFiler_SubscribeMetadata_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeMetadataRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SeaweedFilerServer).SubscribeMetadata(m, &seaweedFilerSubscribeMetadataServer{stream})
+}
+
+type SeaweedFiler_SubscribeMetadataServer interface {
+	Send(*SubscribeMetadataResponse) error
+	grpc.ServerStream
+}
+
+type seaweedFilerSubscribeMetadataServer struct {
+	grpc.ServerStream
+}
+
+func (x *seaweedFilerSubscribeMetadataServer) Send(m *SubscribeMetadataResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _SeaweedFiler_AcquireLease_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AcquireLeaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SeaweedFilerServer).AcquireLease(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/filer_pb.SeaweedFiler/AcquireLease",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SeaweedFilerServer).AcquireLease(ctx, req.(*AcquireLeaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SeaweedFiler_ReleaseLease_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReleaseLeaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SeaweedFilerServer).ReleaseLease(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/filer_pb.SeaweedFiler/ReleaseLease",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SeaweedFilerServer).ReleaseLease(ctx, req.(*ReleaseLeaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _SeaweedFiler_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "filer_pb.SeaweedFiler",
 	HandlerType: (*SeaweedFilerServer)(nil),
@@ -1413,112 +2114,181 @@ var _SeaweedFiler_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetFilerConfiguration",
 			Handler:    _SeaweedFiler_GetFilerConfiguration_Handler,
 		},
+		{
+			MethodName: "CreateSnapshot",
+			Handler:    _SeaweedFiler_CreateSnapshot_Handler,
+		},
+		{
+			MethodName: "DeleteSnapshot",
+			Handler:    _SeaweedFiler_DeleteSnapshot_Handler,
+		},
+		{
+			MethodName: "PatchEntry",
+			Handler:    _SeaweedFiler_PatchEntry_Handler,
+		},
+		{
+			MethodName: "AcquireLease",
+			Handler:    _SeaweedFiler_AcquireLease_Handler,
+		},
+		{
+			MethodName: "ReleaseLease",
+			Handler:    _SeaweedFiler_ReleaseLease_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeMetadata",
+			Handler:       _SeaweedFiler_SubscribeMetadata_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "filer.proto",
 }
 
 func init() { proto.RegisterFile("filer.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 1583 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xb4, 0x58, 0xdb, 0x6f, 0xdc, 0x44,
-	0x17, 0xaf, 0xf7, 0xee, 0xb3, 0xbb, 0x6d, 0x32, 0x49, 0xbf, 0xba, 0x9b, 0xcb, 0x97, 0x3a, 0x5f,
-	0xfb, 0xa5, 0xa2, 0x0a, 0x55, 0xe1, 0xa1, 0xa5, 0x42, 0xa2, 0xcd, 0x05, 0x45, 0xa4, 0x17, 0x39,
-	0x2d, 0x02, 0x21, 0x61, 0x39, 0xf6, 0xec, 0x66, 0x88, 0xed, 0x59, 0xec, 0x71, 0x92, 0xf2, 0x27,
-	0xf0, 0x82, 0xc4, 0x23, 0x12, 0xcf, 0xfc, 0x13, 0x88, 0x17, 0xc4, 0xbf, 0xc3, 0x23, 0xcf, 0x68,
-	0x2e, 0xf6, 0x8e, 0xd7, 0x9b, 0xa4, 0x08, 0xf5, 0xcd, 0x73, 0xae, 0xbf, 0x73, 0xe6, 0x5c, 0x66,
-	0x17, 0xba, 0x43, 0x12, 0xe2, 0x64, 0x73, 0x9c, 0x50, 0x46, 0x51, 0x47, 0x1c, 0xdc, 0xf1, 0xa1,
-	0xfd, 0x02, 0x96, 0xf6, 0x29, 0x3d, 0xce, 0xc6, 0xdb, 0x24, 0xc1, 0x3e, 0xa3, 0xc9, 0x9b, 0x9d,
-	0x98, 0x25, 0x6f, 0x1c, 0xfc, 0x6d, 0x86, 0x53, 0x86, 0x96, 0xc1, 0x0c, 0x72, 0x86, 0x65, 0xac,
-	0x19, 0x1b, 0xa6, 0x33, 0x21, 0x20, 0x04, 0x8d, 0xd8, 0x8b, 0xb0, 0x55, 0x13, 0x0c, 0xf1, 0x6d,
-	0xef, 0xc0, 0xf2, 0x6c, 0x83, 0xe9, 0x98, 0xc6, 0x29, 0x46, 0xb7, 0xa1, 0x89, 0x39, 0x41, 0x58,
-	0xeb, 0x3e, 0xb8, 0xb6, 0x99, 0x43, 0xd9, 0x94, 0x72, 0x92, 0x6b, 0xff, 0x66, 0x00, 0xda, 0x27,
-	0x29, 0xe3, 0x44, 0x82, 0xd3, 0xb7, 0xc3, 0xf3, 0x1f, 0x68, 0x8d, 0x13, 0x3c, 0x24, 0x67, 0x0a,
-	0x91, 0x3a, 0xa1, 0x7b, 0x30, 0x9f, 0x32, 0x2f, 0x61, 0xbb, 0x09, 0x8d, 0x76, 0x49, 0x88, 0x9f,
-	0x73, 0xd0, 0x75, 0x21, 0x52, 0x65, 0xa0, 0x4d, 0x40, 0x24, 0xf6, 0xc3, 0x2c, 0x25, 0x27, 0xf8,
-	0x20, 0xe7, 0x5a, 0x8d, 0x35, 0x63, 0xa3, 0xe3, 0xcc, 0xe0, 0xa0, 0x45, 0x68, 0x86, 0x24, 0x22,
-	0xcc, 0x6a, 0xae, 0x19, 0x1b, 0x7d, 0x47, 0x1e, 0xec, 0x4f, 0x60, 0xa1, 0x84, 0x5f, 0x85, 0x7f,
-	0x17, 0xda, 0x58, 0x92, 0x2c, 0x63, 0xad, 0x3e, 0x2b, 0x01, 0x39, 0xdf, 0xfe, 0xb9, 0x06, 0x4d,
-	0x41, 0x2a, 0xf2, 0x6c, 0x4c, 0xf2, 0x8c, 0x6e, 0x41, 0x8f, 0xa4, 0xee, 0x24, 0x19, 0x35, 0x81,
-	0xaf, 0x4b, 0xd2, 0x22, 0xef, 0xe8, 0x3d, 0x68, 0xf9, 0x47, 0x59, 0x7c, 0x9c, 0x5a, 0x75, 0xe1,
-	0x6a, 0x61, 0xe2, 0x8a, 0x07, 0xbb, 0xc5, 0x79, 0x8e, 0x12, 0x41, 0x0f, 0x01, 0x3c, 0xc6, 0x12,
-	0x72, 0x98, 0x31, 0x9c, 0x8a, 0x68, 0xbb, 0x0f, 0x2c, 0x4d, 0x21, 0x4b, 0xf1, 0x93, 0x82, 0xef,
-	0x68, 0xb2, 0xe8, 0x11, 0x74, 0xf0, 0x19, 0xc3, 0x71, 0x80, 0x03, 0xab, 0x29, 0x1c, 0xad, 0x4c,
-	0xc5, 0xb4, 0xb9, 0xa3, 0xf8, 0x32, 0xc2, 0x42, 0x7c, 0xf0, 0x18, 0xfa, 0x25, 0x16, 0x9a, 0x83,
-	0xfa, 0x31, 0xce, 0x6f, 0x96, 0x7f, 0xf2, 0xec, 0x9e, 0x78, 0x61, 0x26, 0x8b, 0xac, 0xe7, 0xc8,
-	0xc3, 0x47, 0xb5, 0x87, 0x86, 0xbd, 0x0d, 0xe6, 0x6e, 0x16, 0x86, 0x85, 0x62, 0x40, 0x92, 0x5c,
-	0x31, 0x20, 0xc9, 0xa4, 0xd0, 0x6a, 0x17, 0x16, 0xda, 0xaf, 0x06, 0xcc, 0xef, 0x9c, 0xe0, 0x98,
-	0x3d, 0xa7, 0x8c, 0x0c, 0x89, 0xef, 0x31, 0x42, 0x63, 0x74, 0x0f, 0x4c, 0x1a, 0x06, 0xee, 0x85,
-	0x95, 0xda, 0xa1, 0xa1, 0x42, 0x7d, 0x0f, 0xcc, 0x18, 0x9f, 0xba, 0x17, 0xba, 0xeb, 0xc4, 0xf8,
-	0x54, 0x4a, 0xaf, 0x43, 0x3f, 0xc0, 0x21, 0x66, 0xd8, 0x2d, 0x6e, 0x87, 0x5f, 0x5d, 0x4f, 0x12,
-	0xb7, 0xe4, 0x75, 0xdc, 0x81, 0x6b, 0xdc, 0xe4, 0xd8, 0x4b, 0x70, 0xcc, 0xdc, 0xb1, 0xc7, 0x8e,
-	0xc4, 0x9d, 0x98, 0x4e, 0x3f, 0xc6, 0xa7, 0x2f, 0x05, 0xf5, 0xa5, 0xc7, 0x8e, 0xec, 0xbf, 0x0c,
-	0x30, 0x8b, 0xcb, 0x44, 0x37, 0xa0, 0xcd, 0xdd, 0xba, 0x24, 0x50, 0x99, 0x68, 0xf1, 0xe3, 0x5e,
-	0xc0, 0x3b, 0x83, 0x0e, 0x87, 0x29, 0x66, 0x02, 0x5e, 0xdd, 0x51, 0x27, 0x5e, 0x59, 0x29, 0xf9,
-	0x4e, 0x36, 0x43, 0xc3, 0x11, 0xdf, 0x3c, 0xe3, 0x11, 0x23, 0x11, 0x16, 0x0e, 0xeb, 0x8e, 0x3c,
-	0xa0, 0x05, 0x68, 0x62, 0x97, 0x79, 0x23, 0x51, 0xe5, 0xa6, 0xd3, 0xc0, 0xaf, 0xbc, 0x11, 0xfa,
-	0x1f, 0x5c, 0x4d, 0x69, 0x96, 0xf8, 0xd8, 0xcd, 0xdd, 0xb6, 0x04, 0xb7, 0x27, 0xa9, 0xbb, 0xd2,
-	0xb9, 0x0d, 0xf5, 0x21, 0x09, 0xac, 0xb6, 0x48, 0xcc, 0x5c, 0xb9, 0x08, 0xf7, 0x02, 0x87, 0x33,
-	0xd1, 0xfb, 0x00, 0x85, 0xa5, 0xc0, 0xea, 0x9c, 0x23, 0x6a, 0xe6, 0x76, 0x03, 0xfb, 0x0b, 0x68,
-	0x29, 0xf3, 0x4b, 0x60, 0x9e, 0xd0, 0x30, 0x8b, 0x8a, 0xb0, 0xfb, 0x4e, 0x47, 0x12, 0xf6, 0x02,
-	0x74, 0x13, 0xc4, 0xac, 0x73, 0x79, 0x55, 0xd5, 0x44, 0x90, 0x22, 0x43, 0x9f, 0x61, 0x31, 0x2d,
-	0x7c, 0x4a, 0x8f, 0x89, 0x8c, 0xbe, 0xed, 0xa8, 0x93, 0xfd, 0x67, 0x0d, 0xae, 0x96, 0xcb, 0x9d,
-	0xbb, 0x10, 0x56, 0x44, 0xae, 0x0c, 0x61, 0x46, 0x98, 0x3d, 0x28, 0xe5, 0xab, 0xa6, 0xe7, 0x2b,
-	0x57, 0x89, 0x68, 0x20, 0x1d, 0xf4, 0xa5, 0xca, 0x33, 0x1a, 0x60, 0x5e, 0xad, 0x19, 0x09, 0x44,
-	0x82, 0xfb, 0x0e, 0xff, 0xe4, 0x94, 0x11, 0x09, 0xd4, 0x08, 0xe1, 0x9f, 0x02, 0x5e, 0x22, 0xec,
-	0xb6, 0xe4, 0x95, 0xc9, 0x13, 0xbf, 0xb2, 0x88, 0x53, 0xdb, 0xf2, 0x1e, 0xf8, 0x37, 0x5a, 0x83,
-	0x6e, 0x82, 0xc7, 0xa1, 0xaa, 0x5e, 0x91, 0x3e, 0xd3, 0xd1, 0x49, 0x68, 0x15, 0xc0, 0xa7, 0x61,
-	0x88, 0x7d, 0x21, 0x60, 0x0a, 0x01, 0x8d, 0xc2, 0x2b, 0x87, 0xb1, 0xd0, 0x4d, 0xb1, 0x6f, 0xc1,
-	0x9a, 0xb1, 0xd1, 0x74, 0x5a, 0x8c, 0x85, 0x07, 0xd8, 0xe7, 0x71, 0x64, 0x29, 0x4e, 0x5c, 0x31,
-	0x80, 0xba, 0x42, 0xaf, 0xc3, 0x09, 0x62, 0x54, 0xae, 0x00, 0x8c, 0x12, 0x9a, 0x8d, 0x25, 0xb7,
-	0xb7, 0x56, 0xe7, 0xf3, 0x58, 0x50, 0x04, 0xfb, 0x36, 0x5c, 0x4d, 0xdf, 0x44, 0x21, 0x89, 0x8f,
-	0x5d, 0xe6, 0x25, 0x23, 0xcc, 0xac, 0xbe, 0xac, 0x61, 0x45, 0x7d, 0x25, 0x88, 0xf6, 0x97, 0x80,
-	0xb6, 0x12, 0xec, 0x31, 0xfc, 0x0f, 0x56, 0xcf, 0x5b, 0x76, 0xf7, 0x75, 0x58, 0x28, 0x99, 0x96,
-	0x53, 0x98, 0x7b, 0x7c, 0x3d, 0x0e, 0xde, 0x95, 0xc7, 0x92, 0x69, 0xe5, 0xf1, 0x07, 0x03, 0xd0,
-	0xb6, 0x68, 0xf0, 0x7f, 0xb7, 0x5f, 0x79, 0xcb, 0xf1, 0xb9, 0x2f, 0x07, 0x48, 0xe0, 0x31, 0x4f,
-	0x6d, 0xa6, 0x1e, 0x49, 0xa5, 0xfd, 0x6d, 0x8f, 0x79, 0x6a, 0x3b, 0x24, 0xd8, 0xcf, 0x12, 0xbe,
-	0xac, 0x44, 0x5d, 0x89, 0xed, 0xe0, 0xe4, 0x24, 0x0e, 0xb4, 0x04, 0x48, 0x01, 0xfd, 0xc9, 0x00,
-	0xeb, 0x09, 0xa3, 0x11, 0xf1, 0x1d, 0xcc, 0x1d, 0x96, 0xe0, 0xae, 0x43, 0x9f, 0x8f, 0xc5, 0x69,
-	0xc8, 0x3d, 0x1a, 0x06, 0x93, 0xb5, 0x73, 0x13, 0xf8, 0x64, 0x74, 0x35, 0xe4, 0x6d, 0x1a, 0x06,
-	0xa2, 0x20, 0xd6, 0x81, 0x8f, 0x2f, 0x4d, 0x5f, 0x2e, 0xe1, 0x5e, 0x8c, 0x4f, 0x4b, 0xfa, 0x5c,
-	0x48, 0xe8, 0xcb, 0x99, 0xd7, 0x8e, 0xf1, 0x29, 0xd7, 0xb7, 0x97, 0xe0, 0xe6, 0x0c, 0x6c, 0x0a,
-	0xf9, 0x2f, 0x06, 0x2c, 0x3c, 0x49, 0x53, 0x32, 0x8a, 0x3f, 0x17, 0xdd, 0x9f, 0x83, 0x5e, 0x84,
-	0xa6, 0x4f, 0xb3, 0x98, 0x09, 0xb0, 0x4d, 0x47, 0x1e, 0xa6, 0x1a, 0xa2, 0x56, 0x69, 0x88, 0xa9,
-	0x96, 0xaa, 0x57, 0x5b, 0x4a, 0x6b, 0x99, 0x46, 0xa9, 0x65, 0xfe, 0x0b, 0x5d, 0x7e, 0x31, 0xae,
-	0x8f, 0x63, 0x86, 0x13, 0x35, 0x30, 0x81, 0x93, 0xb6, 0x04, 0xc5, 0xfe, 0xde, 0x80, 0xc5, 0x32,
-	0x52, 0xf5, 0x3a, 0x38, 0x77, 0x7e, 0xf3, 0x81, 0x91, 0x84, 0x0a, 0x26, 0xff, 0xe4, 0xad, 0x37,
-	0xce, 0x0e, 0x43, 0xe2, 0xbb, 0x9c, 0x21, 0xe1, 0x99, 0x92, 0xf2, 0x3a, 0x09, 0x27, 0x41, 0x37,
-	0xf4, 0xa0, 0x11, 0x34, 0xbc, 0x8c, 0x1d, 0xe5, 0x33, 0x9c, 0x7f, 0xdb, 0x1f, 0xc2, 0x82, 0x7c,
-	0xb0, 0x95, 0xb3, 0xb6, 0x02, 0x50, 0x4c, 0x55, 0xf9, 0x56, 0x31, 0x1d, 0x33, 0x1f, 0xab, 0xa9,
-	0xfd, 0x31, 0x98, 0xfb, 0x54, 0x26, 0x22, 0x45, 0xf7, 0xc1, 0x0c, 0xf3, 0x83, 0x7a, 0xd6, 0xa0,
-	0x49, 0x7b, 0xe4, 0x72, 0xce, 0x44, 0xc8, 0x7e, 0x0c, 0x9d, 0x9c, 0x9c, 0xc7, 0x66, 0x9c, 0x17,
-	0x5b, 0x6d, 0x2a, 0x36, 0xfb, 0x77, 0x03, 0x16, 0xcb, 0x90, 0x55, 0xfa, 0x5e, 0x43, 0xbf, 0x70,
-	0xe1, 0x46, 0xde, 0x58, 0x61, 0xb9, 0xaf, 0x63, 0xa9, 0xaa, 0x15, 0x00, 0xd3, 0x67, 0xde, 0x58,
-	0x96, 0x54, 0x2f, 0xd4, 0x48, 0x83, 0x57, 0x30, 0x5f, 0x11, 0x99, 0xf1, 0x52, 0xb9, 0xab, 0xbf,
-	0x54, 0x4a, 0xaf, 0xad, 0x42, 0x5b, 0x7f, 0xbe, 0x3c, 0x82, 0x1b, 0xb2, 0xff, 0xb6, 0x8a, 0xa2,
-	0xcb, 0x73, 0x5f, 0xae, 0x4d, 0x63, 0xba, 0x36, 0xed, 0x01, 0x58, 0x55, 0x55, 0xd5, 0x05, 0x23,
-	0x98, 0x3f, 0x60, 0x1e, 0x23, 0x29, 0x23, 0x7e, 0xf1, 0x6c, 0x9e, 0x2a, 0x66, 0xe3, 0xb2, 0xfd,
-	0x50, 0x6d, 0x87, 0x39, 0xa8, 0x33, 0x96, 0xd7, 0x19, 0xff, 0xe4, 0xb7, 0x80, 0x74, 0x4f, 0xea,
-	0x0e, 0xde, 0x81, 0x2b, 0x5e, 0x0f, 0x8c, 0x32, 0x2f, 0x94, 0xfb, 0xb7, 0x21, 0xf6, 0xaf, 0x29,
-	0x28, 0x62, 0x01, 0xcb, 0x15, 0x15, 0x48, 0x6e, 0x53, 0x6e, 0x67, 0x4e, 0x10, 0xcc, 0x15, 0x00,
-	0xd1, 0x52, 0xb2, 0x1b, 0x5a, 0x52, 0x97, 0x53, 0xb6, 0x38, 0xc1, 0x5e, 0x85, 0xe5, 0x4f, 0x31,
-	0xe3, 0x2f, 0x89, 0x64, 0x8b, 0xc6, 0x43, 0x32, 0xca, 0x12, 0x4f, 0xbb, 0x0a, 0xfb, 0x47, 0x03,
-	0x56, 0xce, 0x11, 0x50, 0x01, 0x5b, 0xd0, 0x8e, 0xbc, 0x94, 0xe1, 0x24, 0xef, 0x92, 0xfc, 0x38,
-	0x9d, 0x8a, 0xda, 0x65, 0xa9, 0xa8, 0x57, 0x52, 0x71, 0x1d, 0x5a, 0x91, 0x77, 0xe6, 0x46, 0x87,
-	0xea, 0xa9, 0xd0, 0x8c, 0xbc, 0xb3, 0x67, 0x87, 0x0f, 0xfe, 0x68, 0x43, 0xef, 0x00, 0x7b, 0xa7,
-	0x18, 0x07, 0x02, 0x18, 0x1a, 0xe5, 0x0d, 0x51, 0xfe, 0xd1, 0x85, 0x6e, 0x4f, 0x57, 0xfe, 0xcc,
-	0x5f, 0x79, 0x83, 0x3b, 0x97, 0x89, 0xa9, 0xda, 0xba, 0x82, 0xf6, 0xa1, 0xab, 0xfd, 0xaa, 0x41,
-	0xcb, 0x9a, 0x62, 0xe5, 0xc7, 0xda, 0x60, 0xe5, 0x1c, 0xae, 0x6e, 0x4d, 0xdb, 0xce, 0xba, 0xb5,
-	0xea, 0x7b, 0x40, 0xb7, 0x36, 0x6b, 0xa5, 0x0b, 0x6b, 0xda, 0xe6, 0xd5, 0xad, 0x55, 0x77, 0xbd,
-	0x6e, 0x6d, 0xd6, 0xba, 0x16, 0xd6, 0xb4, 0xf5, 0xa8, 0x5b, 0xab, 0xae, 0x71, 0xdd, 0xda, 0xac,
-	0x9d, 0x7a, 0x05, 0x7d, 0x0d, 0xf3, 0x95, 0xc5, 0x85, 0xec, 0x89, 0xd6, 0x79, 0x1b, 0x77, 0xb0,
-	0x7e, 0xa1, 0x4c, 0x61, 0xff, 0x05, 0xf4, 0xf4, 0x85, 0x82, 0x34, 0x40, 0x33, 0x56, 0xe2, 0x60,
-	0xf5, 0x3c, 0xb6, 0x6e, 0x50, 0x9f, 0x95, 0xba, 0xc1, 0x19, 0xdb, 0x42, 0x37, 0x38, 0x6b, 0xc4,
-	0xda, 0x57, 0xd0, 0x57, 0x30, 0x37, 0x3d, 0xb3, 0xd0, 0xad, 0xe9, 0xb4, 0x55, 0x46, 0xe1, 0xc0,
-	0xbe, 0x48, 0xa4, 0x30, 0xbe, 0x07, 0x30, 0x19, 0x45, 0x68, 0x69, 0xa2, 0x53, 0x19, 0x85, 0x83,
-	0xe5, 0xd9, 0xcc, 0xc2, 0xd4, 0x37, 0x70, 0x7d, 0x66, 0xbf, 0x23, 0xad, 0x49, 0x2e, 0x9a, 0x18,
-	0x83, 0xff, 0x5f, 0x2a, 0x97, 0xfb, 0x7a, 0xba, 0x0a, 0x73, 0xa9, 0x6c, 0xe3, 0x61, 0xba, 0xe9,
-	0x87, 0x04, 0xc7, 0xec, 0x29, 0x08, 0x8d, 0x97, 0x09, 0x65, 0xf4, 0xb0, 0x25, 0xfe, 0xad, 0xf9,
-	0xe0, 0xef, 0x00, 0x00, 0x00, 0xff, 0xff, 0x2d, 0xec, 0xb0, 0x56, 0xbc, 0x11, 0x00, 0x00,
+	// 2259 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x59, 0x5b, 0x73, 0xdc, 0x48,
+	0x15, 0x46, 0x73, 0xf3, 0xe8, 0xcc, 0x8c, 0x63, 0xb7, 0xed, 0xac, 0x22, 0x5f, 0xe2, 0x28, 0x9b,
+	0x25, 0xa9, 0x4d, 0x99, 0x54, 0xe0, 0x21, 0x0b, 0x05, 0x45, 0xe2, 0x5c, 0xd9, 0x24, 0xa4, 0xe4,
+	0x84, 0xa2, 0x80, 0x42, 0x68, 0xa4, 0xf6, 0xb8, 0xb1, 0x46, 0x3d, 0xab, 0x6e, 0xf9, 0xb2, 0xcf,
+	0x3c, 0xf1, 0xc8, 0x23, 0x55, 0xfc, 0x0e, 0x78, 0x80, 0x17, 0x78, 0xe7, 0x8d, 0x1f, 0xc0, 0x5f,
+	0xa0, 0xf8, 0x01, 0x54, 0x5f, 0xa4, 0x69, 0x8d, 0x66, 0xec, 0xcd, 0x2e, 0x79, 0x53, 0x7f, 0xe7,
+	0xf4, 0xe9, 0x73, 0x4e, 0x9f, 0x5b, 0xcf, 0x40, 0xef, 0x90, 0x24, 0x38, 0xdb, 0x9b, 0x64, 0x94,
+	0x53, 0xd4, 0x95, 0x8b, 0x60, 0x32, 0xf4, 0xbe, 0x84, 0xcd, 0x97, 0x94, 0x1e, 0xe7, 0x93, 0xc7,
+	0x24, 0xc3, 0x11, 0xa7, 0xd9, 0xf9, 0x93, 0x94, 0x67, 0xe7, 0x3e, 0xfe, 0x22, 0xc7, 0x8c, 0xa3,
+	0x2d, 0xb0, 0xe3, 0x82, 0xe0, 0x58, 0xbb, 0xd6, 0x6d, 0xdb, 0x9f, 0x02, 0x08, 0x41, 0x2b, 0x0d,
+	0xc7, 0xd8, 0x69, 0x48, 0x82, 0xfc, 0x46, 0x77, 0x60, 0x25, 0xc3, 0x61, 0x1c, 0x44, 0x34, 0x65,
+	0x84, 0x71, 0x9c, 0x46, 0xe7, 0x4e, 0x53, 0xd2, 0xaf, 0x08, 0x7c, 0x7f, 0x0a, 0x7b, 0x4f, 0x60,
+	0x6b, 0xfe, 0xd9, 0x6c, 0x42, 0x53, 0x86, 0xd1, 0x2d, 0x68, 0xe3, 0x94, 0xeb, 0x83, 0x7b, 0xf7,
+	0xaf, 0xec, 0x15, 0x5a, 0xef, 0x29, 0x3e, 0x45, 0xf5, 0xfe, 0xdc, 0x00, 0xf4, 0x92, 0x30, 0x2e,
+	0x40, 0x82, 0xd9, 0x57, 0x53, 0xfd, 0x2a, 0x74, 0x26, 0x19, 0x3e, 0x24, 0x67, 0x5a, 0x79, 0xbd,
+	0x42, 0x77, 0x61, 0x95, 0xf1, 0x30, 0xe3, 0x4f, 0x33, 0x3a, 0x7e, 0x4a, 0x12, 0xfc, 0x5a, 0xd8,
+	0xa7, 0xf4, 0xaf, 0x13, 0xd0, 0x1e, 0x20, 0x92, 0x46, 0x49, 0xce, 0xc8, 0x09, 0x3e, 0x28, 0xa8,
+	0x4e, 0x6b, 0xd7, 0xba, 0xdd, 0xf5, 0xe7, 0x50, 0xd0, 0x3a, 0xb4, 0x13, 0x32, 0x26, 0xdc, 0x69,
+	0xef, 0x5a, 0xb7, 0x07, 0xbe, 0x5a, 0xcc, 0x75, 0x59, 0x67, 0xae, 0xcb, 0xd0, 0x47, 0xb0, 0xc4,
+	0x68, 0xc6, 0x83, 0xe1, 0xb9, 0xb3, 0xa4, 0xf4, 0x16, 0xcb, 0x47, 0xe7, 0x68, 0x13, 0x6c, 0x49,
+	0x88, 0x31, 0x8b, 0x9c, 0xae, 0x54, 0xa0, 0x2b, 0x80, 0xc7, 0x98, 0x45, 0xc2, 0xd8, 0x43, 0x82,
+	0x93, 0x98, 0x39, 0xf6, 0x6e, 0x53, 0x6c, 0x52, 0x2b, 0xef, 0xc7, 0xb0, 0x56, 0x71, 0x9c, 0xf6,
+	0xfb, 0x1d, 0x58, 0xc2, 0x0a, 0x72, 0xac, 0xdd, 0xe6, 0x3c, 0xcf, 0x17, 0x74, 0xef, 0x4f, 0x0d,
+	0x68, 0x4b, 0xa8, 0x8c, 0x05, 0xcb, 0x88, 0x85, 0x1b, 0xd0, 0x27, 0x2c, 0x98, 0xde, 0x42, 0x43,
+	0xea, 0xd5, 0x23, 0xac, 0xbc, 0x70, 0xf4, 0x29, 0x74, 0xa2, 0xa3, 0x3c, 0x3d, 0x66, 0x4e, 0x53,
+	0x1e, 0xb5, 0x36, 0x3d, 0x4a, 0x78, 0x79, 0x5f, 0xd0, 0x7c, 0xcd, 0x82, 0x1e, 0x00, 0x84, 0x9c,
+	0x67, 0x64, 0x98, 0x73, 0xcc, 0xa4, 0x9b, 0x7b, 0xf7, 0x1d, 0x63, 0x43, 0xce, 0xf0, 0xc3, 0x92,
+	0xee, 0x1b, 0xbc, 0xe8, 0x33, 0xe8, 0xe2, 0x33, 0x8e, 0xd3, 0x18, 0xc7, 0x4e, 0x5b, 0x1e, 0xb4,
+	0x3d, 0x63, 0xd3, 0xde, 0x13, 0x4d, 0x57, 0x16, 0x96, 0xec, 0xee, 0x0f, 0x60, 0x50, 0x21, 0xa1,
+	0x15, 0x68, 0x1e, 0xe3, 0x22, 0xa4, 0xc4, 0xa7, 0xb8, 0xd6, 0x93, 0x30, 0xc9, 0x55, 0x22, 0xf4,
+	0x7d, 0xb5, 0xf8, 0x7e, 0xe3, 0x81, 0xe5, 0x3d, 0x06, 0xfb, 0x69, 0x9e, 0x24, 0xe5, 0xc6, 0x98,
+	0x64, 0xc5, 0xc6, 0x98, 0x64, 0xd3, 0x08, 0x6f, 0x5c, 0x18, 0xe1, 0x7f, 0xb5, 0x60, 0xf5, 0xc9,
+	0x09, 0x4e, 0xf9, 0x6b, 0xca, 0xc9, 0x21, 0x89, 0x42, 0x4e, 0x68, 0x8a, 0xee, 0x82, 0x4d, 0x93,
+	0x38, 0xb8, 0x30, 0x45, 0xba, 0x34, 0xd1, 0x5a, 0xdf, 0x05, 0x3b, 0xc5, 0xa7, 0xc1, 0x85, 0xc7,
+	0x75, 0x53, 0x7c, 0xaa, 0xb8, 0x6f, 0xc2, 0x20, 0xc6, 0x09, 0xe6, 0x38, 0x28, 0x6f, 0x47, 0x5c,
+	0x5d, 0x5f, 0x81, 0xfb, 0xea, 0x3a, 0x3e, 0x81, 0x2b, 0x42, 0xe4, 0x24, 0xcc, 0x70, 0xca, 0x83,
+	0x49, 0xc8, 0x8f, 0xe4, 0x9d, 0xd8, 0xfe, 0x20, 0xc5, 0xa7, 0x6f, 0x24, 0xfa, 0x26, 0xe4, 0x47,
+	0x22, 0x41, 0xed, 0xf2, 0x32, 0x45, 0x08, 0x8b, 0x63, 0x03, 0x12, 0x6b, 0x4f, 0x74, 0xc4, 0xf2,
+	0x45, 0x2c, 0xa2, 0x94, 0x1e, 0x1e, 0x32, 0xcc, 0xa5, 0x7a, 0x4d, 0x5f, 0xaf, 0x44, 0x64, 0x31,
+	0xf2, 0xa5, 0xca, 0xc2, 0x96, 0x2f, 0xbf, 0x85, 0xc7, 0xc7, 0x9c, 0x8c, 0xb1, 0x3c, 0xb0, 0xe9,
+	0xab, 0x05, 0x5a, 0x83, 0x36, 0x0e, 0x78, 0x38, 0x92, 0xe9, 0x65, 0xfb, 0x2d, 0xfc, 0x36, 0x1c,
+	0xa1, 0x8f, 0x61, 0x99, 0xd1, 0x3c, 0x8b, 0x70, 0x50, 0x1c, 0xab, 0x72, 0xab, 0xaf, 0xd0, 0xa7,
+	0xea, 0x70, 0x0f, 0x9a, 0x87, 0x24, 0x96, 0x49, 0xd5, 0xbb, 0xbf, 0x52, 0x0d, 0xc2, 0x17, 0xb1,
+	0x2f, 0x88, 0xe8, 0x3b, 0x00, 0xa5, 0xa4, 0xd8, 0xe9, 0x2e, 0x60, 0xb5, 0x0b, 0xb9, 0x31, 0xda,
+	0x85, 0x5e, 0x44, 0xc7, 0x93, 0x0c, 0x33, 0x46, 0x68, 0xea, 0xd8, 0xf2, 0x5c, 0x13, 0x42, 0xdb,
+	0x00, 0x11, 0x99, 0x1c, 0xe1, 0x2c, 0x10, 0x21, 0x05, 0x32, 0x7c, 0x6c, 0x85, 0x7c, 0x8e, 0xcf,
+	0xbd, 0x9f, 0x43, 0x47, 0xeb, 0xb7, 0x09, 0xf6, 0x09, 0x4d, 0xf2, 0x71, 0xe9, 0xb7, 0x81, 0xdf,
+	0x55, 0xc0, 0x8b, 0x18, 0x5d, 0x03, 0x59, 0xd0, 0xa5, 0x8c, 0x86, 0xf4, 0x92, 0x74, 0xf1, 0xe7,
+	0x58, 0xd6, 0xb9, 0x88, 0xd2, 0x63, 0xa2, 0xdc, 0xb7, 0xe4, 0xeb, 0x95, 0xf7, 0xdf, 0x26, 0x2c,
+	0x57, 0xf3, 0x45, 0x1c, 0x21, 0xa5, 0x48, 0x67, 0x5b, 0x52, 0x8c, 0x14, 0x7b, 0x50, 0x71, 0x78,
+	0xc3, 0x74, 0x78, 0xb1, 0x65, 0x4c, 0x63, 0x75, 0xc0, 0x40, 0x6d, 0x79, 0x45, 0x63, 0x2c, 0xc2,
+	0x3d, 0x27, 0xb1, 0xbc, 0xa1, 0x81, 0x2f, 0x3e, 0x05, 0x32, 0x22, 0xb1, 0x2e, 0x7e, 0xe2, 0x53,
+	0xaa, 0x97, 0x49, 0xb9, 0x1d, 0x75, 0xe7, 0x6a, 0x25, 0xee, 0x7c, 0x2c, 0x50, 0x55, 0xe4, 0xe4,
+	0xb7, 0xf0, 0x66, 0x86, 0x27, 0x89, 0x0e, 0x7f, 0xe9, 0x7f, 0xdb, 0x37, 0x21, 0xb4, 0x03, 0x10,
+	0xd1, 0x24, 0xc1, 0x11, 0x9f, 0xba, 0xdb, 0x40, 0x44, 0xe8, 0x71, 0x9e, 0x04, 0x0c, 0x47, 0xd2,
+	0xd5, 0x6d, 0xbf, 0xc3, 0x79, 0x72, 0x80, 0x23, 0x61, 0x47, 0xce, 0x70, 0x16, 0xc8, 0x0a, 0xd6,
+	0x93, 0xfb, 0xba, 0x02, 0x90, 0x45, 0x7e, 0x1b, 0x60, 0x94, 0xd1, 0x7c, 0xa2, 0xa8, 0x7d, 0x59,
+	0x41, 0x6d, 0x89, 0x48, 0xf2, 0x2d, 0x58, 0x66, 0xe7, 0xe3, 0x84, 0xa4, 0xc7, 0x01, 0x0f, 0xb3,
+	0x11, 0xe6, 0xce, 0x40, 0x25, 0x81, 0x46, 0xdf, 0x4a, 0x50, 0xb0, 0x65, 0x98, 0xe3, 0x54, 0x28,
+	0xa2, 0xfc, 0xb5, 0xac, 0xd8, 0x4a, 0x54, 0x3a, 0xed, 0x06, 0xf4, 0x33, 0xcc, 0x43, 0x92, 0x06,
+	0x79, 0xca, 0x49, 0xe2, 0x5c, 0x91, 0x6e, 0xe9, 0x29, 0xec, 0x9d, 0x80, 0x84, 0x3e, 0x09, 0x1e,
+	0x85, 0x49, 0x70, 0x44, 0x93, 0xd8, 0x59, 0x91, 0x89, 0x69, 0x4b, 0xe4, 0x39, 0x4d, 0x64, 0xa4,
+	0xe0, 0xb3, 0x09, 0xc9, 0x70, 0x10, 0x72, 0x67, 0x55, 0x6e, 0xef, 0x2a, 0xe0, 0x21, 0xf7, 0xfe,
+	0x62, 0x01, 0xda, 0xcf, 0x70, 0xc8, 0xf1, 0x7b, 0xb4, 0xf9, 0xaf, 0x56, 0xa5, 0xd0, 0x06, 0x74,
+	0x68, 0x80, 0xcf, 0xa2, 0x44, 0x17, 0x8b, 0x36, 0x7d, 0x72, 0x16, 0x25, 0xc2, 0xa2, 0x04, 0x87,
+	0x0c, 0x4b, 0x75, 0x71, 0xa6, 0x4b, 0x44, 0x4f, 0x62, 0xcf, 0x25, 0x24, 0xaa, 0x0d, 0x61, 0x41,
+	0x71, 0x93, 0x58, 0x45, 0x48, 0xd7, 0xef, 0x13, 0xe6, 0x97, 0x98, 0xb7, 0x01, 0x6b, 0x15, 0xcd,
+	0x55, 0xb3, 0xf2, 0xfe, 0x6d, 0x01, 0x7a, 0x37, 0x89, 0x3f, 0x88, 0x45, 0x3f, 0x82, 0xcd, 0xe1,
+	0xf9, 0x24, 0x64, 0x2c, 0x18, 0xd1, 0x13, 0x9c, 0xa5, 0x61, 0x1a, 0xe1, 0xa0, 0xbc, 0x2f, 0x6d,
+	0xe6, 0x35, 0xc5, 0xf2, 0xac, 0xe4, 0xf0, 0x0b, 0x86, 0xff, 0xa7, 0xe9, 0x15, 0x13, 0xb5, 0xe9,
+	0xff, 0xb2, 0x60, 0xf5, 0x4d, 0xc8, 0xa3, 0xa3, 0x6f, 0x38, 0xb2, 0xbd, 0x57, 0x0f, 0xbe, 0xc4,
+	0x27, 0xad, 0xf7, 0xf5, 0x49, 0xbb, 0xe6, 0x13, 0x6f, 0x1d, 0x90, 0x69, 0x96, 0xb6, 0xf6, 0x3f,
+	0x16, 0xa0, 0xc7, 0xb2, 0xfd, 0x7c, 0x43, 0x73, 0x3f, 0x86, 0x65, 0x31, 0x95, 0xa8, 0xf6, 0x16,
+	0x87, 0x3c, 0xd4, 0x4a, 0xf7, 0x09, 0x53, 0xf2, 0x1f, 0x87, 0x3c, 0xd4, 0xb3, 0x4b, 0x86, 0xa3,
+	0x3c, 0x13, 0x33, 0x9c, 0xbe, 0x97, 0x9e, 0xb8, 0x17, 0x0d, 0x5d, 0xe6, 0x8a, 0xce, 0xfb, 0xba,
+	0x62, 0xa9, 0xee, 0x8a, 0x0d, 0x58, 0xab, 0xd8, 0xac, 0x7d, 0xf1, 0x37, 0x0b, 0x9c, 0x87, 0x9c,
+	0x8e, 0x49, 0xe4, 0x63, 0x61, 0x53, 0xc5, 0x23, 0x37, 0x61, 0x20, 0xe6, 0x82, 0x59, 0xaf, 0xf4,
+	0x69, 0x12, 0x4f, 0xe7, 0xae, 0x6b, 0x20, 0x46, 0x83, 0xc0, 0x70, 0xce, 0x12, 0x4d, 0x62, 0x59,
+	0xd0, 0x6e, 0x82, 0xe8, 0xdf, 0xc6, 0x7e, 0x35, 0xfe, 0xf6, 0x53, 0x7c, 0x5a, 0xd9, 0x2f, 0x98,
+	0xe4, 0x7e, 0x15, 0xd6, 0x4b, 0x29, 0x3e, 0x7d, 0xad, 0xa7, 0xbe, 0xcb, 0x6e, 0x78, 0x13, 0xae,
+	0xcd, 0x51, 0x5f, 0x1b, 0xf7, 0x4f, 0x0b, 0xd6, 0x1e, 0x32, 0x46, 0x46, 0xe9, 0xcf, 0x64, 0x83,
+	0x2b, 0xec, 0x5a, 0x87, 0x76, 0x44, 0xf3, 0x94, 0x4b, 0x7b, 0xda, 0xbe, 0x5a, 0xcc, 0xd4, 0xfc,
+	0x46, 0xad, 0xe6, 0xcf, 0x74, 0x8d, 0x66, 0xbd, 0x6b, 0x18, 0x5d, 0xa1, 0x55, 0xe9, 0x0a, 0xd7,
+	0xa1, 0x27, 0xc2, 0x23, 0x88, 0x70, 0xca, 0x4b, 0x3b, 0x40, 0x40, 0xfb, 0x12, 0x11, 0x35, 0x3d,
+	0xa1, 0x51, 0x98, 0x10, 0x7e, 0x1e, 0xc8, 0x86, 0xa0, 0x47, 0x8b, 0x41, 0x81, 0x3e, 0x13, 0xa0,
+	0xf7, 0x7b, 0x0b, 0xd6, 0xab, 0x06, 0xe9, 0x41, 0x7b, 0xe1, 0x28, 0x24, 0x5a, 0x67, 0x96, 0x68,
+	0x6b, 0xc4, 0xa7, 0x28, 0xfa, 0x93, 0x7c, 0x98, 0x90, 0x28, 0x10, 0x04, 0x65, 0x85, 0xad, 0x90,
+	0x77, 0x59, 0x32, 0xf5, 0x4d, 0xcb, 0xf4, 0x0d, 0x82, 0x56, 0x98, 0xf3, 0xa3, 0x62, 0x1c, 0x12,
+	0xdf, 0xde, 0xf7, 0x60, 0x4d, 0x3d, 0xba, 0xaa, 0xce, 0xdd, 0x06, 0x28, 0xe7, 0x0b, 0x35, 0xf6,
+	0xdb, 0xbe, 0x5d, 0x0c, 0x18, 0xcc, 0xfb, 0x21, 0xd8, 0x2f, 0xa9, 0xf2, 0x17, 0x43, 0xf7, 0xc0,
+	0x4e, 0x8a, 0x85, 0x7e, 0x21, 0xa0, 0x69, 0xc9, 0x28, 0xf8, 0xfc, 0x29, 0x93, 0x37, 0x81, 0x6e,
+	0x01, 0x17, 0xb6, 0x59, 0x8b, 0x6c, 0x6b, 0xcc, 0xda, 0x36, 0x73, 0x0d, 0xcd, 0xda, 0x35, 0x20,
+	0x68, 0x65, 0x61, 0x74, 0xac, 0xe3, 0x50, 0x7e, 0x7b, 0xff, 0xb0, 0x60, 0xbd, 0x6a, 0xa7, 0xf6,
+	0xf9, 0x3b, 0x18, 0x94, 0x7a, 0x05, 0xe3, 0x70, 0xa2, 0x0d, 0xb8, 0x67, 0x1a, 0x50, 0xdf, 0x56,
+	0x5a, 0xc5, 0x5e, 0x85, 0x13, 0x15, 0xae, 0xfd, 0xc4, 0x80, 0xdc, 0xb7, 0xb0, 0x5a, 0x63, 0x99,
+	0xf3, 0x52, 0xb8, 0x63, 0xbe, 0x14, 0x2a, 0x95, 0xb6, 0xdc, 0x6d, 0x3e, 0x1f, 0x3e, 0x83, 0x8f,
+	0x54, 0xfa, 0xef, 0x97, 0x01, 0x5d, 0x5c, 0x58, 0x35, 0xee, 0xad, 0xd9, 0xb8, 0xf7, 0x5c, 0x70,
+	0xea, 0x5b, 0x75, 0x86, 0x8d, 0x60, 0xf5, 0x80, 0x87, 0x9c, 0x30, 0x4e, 0xa2, 0xf2, 0xbd, 0x3c,
+	0x93, 0x28, 0xd6, 0x65, 0xe3, 0x55, 0x3d, 0xd5, 0x56, 0xa0, 0xc9, 0x79, 0x11, 0x9c, 0xe2, 0x53,
+	0xdc, 0x02, 0x32, 0x4f, 0xd2, 0x77, 0xf0, 0x01, 0x8e, 0x12, 0x41, 0xc4, 0x29, 0x0f, 0x13, 0x35,
+	0xbe, 0xb6, 0xe4, 0xf8, 0x6a, 0x4b, 0x44, 0xce, 0xaf, 0x6a, 0xc2, 0x8b, 0x15, 0xb5, 0xad, 0x86,
+	0x5b, 0x01, 0x48, 0xe2, 0x36, 0x80, 0xcc, 0x43, 0x95, 0x42, 0x1d, 0xb5, 0x57, 0x20, 0xfb, 0x02,
+	0xf0, 0x76, 0x60, 0xeb, 0x19, 0xe6, 0xa2, 0x15, 0x66, 0xfb, 0x34, 0x3d, 0x24, 0xa3, 0x3c, 0x0b,
+	0x8d, 0xab, 0xf0, 0xfe, 0x60, 0xc1, 0xf6, 0x02, 0x06, 0x6d, 0xb0, 0x03, 0x4b, 0xe3, 0x90, 0x71,
+	0x9c, 0x15, 0xa9, 0x55, 0x2c, 0x67, 0x5d, 0xd1, 0xb8, 0xcc, 0x15, 0xcd, 0x9a, 0x2b, 0x36, 0xa0,
+	0x33, 0x0e, 0xcf, 0x82, 0xf1, 0x50, 0x4f, 0xda, 0xed, 0x71, 0x78, 0xf6, 0x6a, 0xe8, 0xbd, 0x80,
+	0x0d, 0x35, 0x2e, 0x1d, 0xa4, 0xe1, 0x84, 0x1d, 0x51, 0xfe, 0xb5, 0x1b, 0xa6, 0xf7, 0x0b, 0xb8,
+	0x3a, 0x2b, 0x4a, 0xdb, 0x75, 0x1d, 0x7a, 0x72, 0x52, 0x0a, 0xa6, 0x85, 0xb9, 0xe5, 0x83, 0x84,
+	0xa4, 0xeb, 0x04, 0x83, 0x9c, 0x1b, 0x34, 0x83, 0x7a, 0x9c, 0x80, 0x84, 0x94, 0x6f, 0x3f, 0x85,
+	0x0d, 0x15, 0xa6, 0xb3, 0x6a, 0xce, 0xf9, 0x3d, 0xc1, 0x7b, 0x0e, 0x57, 0x67, 0x99, 0xb5, 0x22,
+	0x7b, 0xb0, 0xa6, 0x1a, 0x7a, 0x1c, 0x98, 0xe7, 0x29, 0x85, 0x56, 0x35, 0x69, 0x7f, 0x7a, 0xec,
+	0xaf, 0xc0, 0x39, 0xc8, 0x87, 0x2c, 0xca, 0xc8, 0x10, 0xbf, 0xc2, 0x3c, 0x14, 0xd5, 0xa4, 0x38,
+	0x59, 0xe8, 0x9c, 0x10, 0xf1, 0xa4, 0x35, 0x14, 0x00, 0x05, 0xc9, 0x06, 0x77, 0x1d, 0x7a, 0xe2,
+	0xb1, 0x1b, 0x54, 0x7e, 0x40, 0x02, 0x01, 0xbd, 0x91, 0x88, 0xf7, 0x47, 0x0b, 0xae, 0xcd, 0x11,
+	0xaf, 0x75, 0xbd, 0xf8, 0x02, 0x7e, 0x02, 0x08, 0x9f, 0xc8, 0xc3, 0x8d, 0xb7, 0xbe, 0x2e, 0x17,
+	0x9b, 0xc6, 0x9c, 0x3a, 0xfb, 0x73, 0x80, 0xbf, 0x8a, 0x67, 0x21, 0xf1, 0x1e, 0xe6, 0x2c, 0x48,
+	0xd5, 0xeb, 0xbd, 0xe9, 0xb7, 0x38, 0x7b, 0xcd, 0xbc, 0xdf, 0x89, 0xf6, 0x1a, 0x7d, 0x91, 0x93,
+	0x0c, 0xbf, 0xc4, 0x21, 0xc3, 0x5f, 0x7f, 0x90, 0xba, 0x0a, 0x1d, 0xdd, 0xe2, 0x55, 0x54, 0xea,
+	0x95, 0x18, 0x20, 0xd4, 0x00, 0xc0, 0x70, 0x44, 0xd3, 0x98, 0xe9, 0xa6, 0xa4, 0xa6, 0x82, 0x03,
+	0x85, 0x79, 0x0f, 0x60, 0xbd, 0xaa, 0x45, 0x59, 0x1b, 0xfa, 0xe5, 0xf3, 0x25, 0x90, 0xfd, 0x45,
+	0xa8, 0x0e, 0xc5, 0x0b, 0xe6, 0x35, 0xf3, 0x02, 0x58, 0xf3, 0xb1, 0x94, 0xf5, 0x61, 0xf4, 0xf7,
+	0xae, 0xc2, 0x7a, 0xf5, 0x00, 0xa5, 0xda, 0xfd, 0xbf, 0xf7, 0xa0, 0x7f, 0x80, 0xc3, 0x53, 0x8c,
+	0x63, 0x99, 0xeb, 0x68, 0x54, 0xf4, 0x98, 0xea, 0x0f, 0x98, 0xe8, 0xd6, 0x6c, 0x33, 0x99, 0xfb,
+	0xe3, 0xaa, 0xfb, 0xc9, 0x65, 0x6c, 0xba, 0x5c, 0x7f, 0x0b, 0xbd, 0x84, 0x9e, 0xf1, 0x43, 0x1d,
+	0xda, 0x32, 0x36, 0xd6, 0x7e, 0xf8, 0x74, 0xb7, 0x17, 0x50, 0x4d, 0x69, 0xc6, 0x4b, 0xca, 0x94,
+	0x56, 0x7f, 0x1a, 0xba, 0xdb, 0x0b, 0xa8, 0xa6, 0x34, 0xe3, 0x71, 0x62, 0x4a, 0xab, 0x3f, 0xcb,
+	0xdc, 0xed, 0x05, 0x54, 0x53, 0x9a, 0x31, 0xf0, 0x9a, 0xd2, 0xea, 0xb3, 0xbf, 0xbb, 0xbd, 0x80,
+	0x5a, 0x4a, 0xfb, 0x35, 0xac, 0xd6, 0xe6, 0x4c, 0xe4, 0x4d, 0x77, 0x2d, 0x9a, 0xa1, 0xdd, 0x9b,
+	0x17, 0xf2, 0x94, 0xf2, 0x7f, 0x0a, 0x7d, 0x73, 0xb0, 0x43, 0x86, 0x42, 0x73, 0x26, 0x58, 0x77,
+	0x67, 0x11, 0xd9, 0x14, 0x68, 0x8e, 0x1f, 0xa6, 0xc0, 0x39, 0x53, 0x9b, 0xbb, 0xb3, 0x88, 0x5c,
+	0x0a, 0xfc, 0x25, 0xac, 0xcc, 0x8e, 0x01, 0xe8, 0xc6, 0xac, 0xdb, 0x6a, 0xd3, 0x85, 0xeb, 0x5d,
+	0xc4, 0x52, 0x0a, 0x7f, 0x01, 0x30, 0xed, 0xee, 0xc8, 0xa8, 0x4e, 0xb5, 0xe9, 0xc2, 0xdd, 0x9a,
+	0x4f, 0x2c, 0x45, 0xfd, 0x16, 0x36, 0xe6, 0xb6, 0x50, 0x64, 0x24, 0xc9, 0x45, 0x4d, 0xd8, 0xfd,
+	0xf6, 0xa5, 0x7c, 0xe5, 0x59, 0xef, 0x60, 0xb9, 0xda, 0xcf, 0xd0, 0xf5, 0xd9, 0x20, 0x9f, 0xe9,
+	0x46, 0xee, 0xee, 0x62, 0x06, 0x53, 0x6c, 0xb5, 0x3b, 0x99, 0x62, 0xe7, 0x36, 0x39, 0x77, 0x77,
+	0x31, 0x83, 0xe9, 0xe4, 0xe9, 0x6b, 0xd8, 0x74, 0x72, 0xed, 0xe9, 0xef, 0x6e, 0xcd, 0x27, 0x96,
+	0xa2, 0x7e, 0x03, 0xab, 0xb5, 0xb6, 0x64, 0xa6, 0xc3, 0xa2, 0x96, 0xe8, 0xde, 0xbc, 0x90, 0xa7,
+	0x90, 0x7f, 0xcf, 0x92, 0x09, 0x61, 0x54, 0xf5, 0x4a, 0x42, 0xd4, 0x7b, 0x8e, 0xbb, 0xb3, 0x88,
+	0x6c, 0x26, 0x84, 0x59, 0x8b, 0x4d, 0x81, 0x73, 0x9a, 0x80, 0xbb, 0xb3, 0x88, 0x5c, 0x08, 0x7c,
+	0xb4, 0x03, 0x2b, 0x4c, 0xd5, 0xf0, 0x43, 0xb6, 0xa7, 0x9a, 0xfa, 0x23, 0x90, 0xe1, 0xf2, 0x26,
+	0xa3, 0x9c, 0x0e, 0x3b, 0xf2, 0x1f, 0xb2, 0xef, 0xfe, 0x6f, 0x00, 0xe0, 0xba, 0xff, 0xc2, 0x30,
+	0x1b, 0x00, 0x00,
 }
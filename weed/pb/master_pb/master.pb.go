@@ -31,6 +31,8 @@ It has these top-level messages:
 	CollectionListResponse
 	CollectionDeleteRequest
 	CollectionDeleteResponse
+	CollectionCreateRequest
+	CollectionCreateResponse
 	DataNodeInfo
 	RackInfo
 	DataCenterInfo
@@ -41,6 +43,16 @@ It has these top-level messages:
 	LookupEcVolumeResponse
 	GetMasterConfigurationRequest
 	GetMasterConfigurationResponse
+	ClusterStatusRequest
+	ClusterStatusResponse
+	RaftPeerStatus
+	CapacityStatus
+	DataCenterStatus
+	RackStatus
+	VolumeServerStatus
+	VolumeGrowthStrategy
+	ConfigureVolumeGrowthRequest
+	ConfigureVolumeGrowthResponse
 */
 package master_pb
 
@@ -84,6 +96,17 @@ type Heartbeat struct {
 	NewEcShards     []*VolumeEcShardInformationMessage `protobuf:"bytes,17,rep,name=new_ec_shards,json=newEcShards" json:"new_ec_shards,omitempty"`
 	DeletedEcShards []*VolumeEcShardInformationMessage `protobuf:"bytes,18,rep,name=deleted_ec_shards,json=deletedEcShards" json:"deleted_ec_shards,omitempty"`
 	HasNoEcShards   bool                               `protobuf:"varint,19,opt,name=has_no_ec_shards,json=hasNoEcShards" json:"has_no_ec_shards,omitempty"`
+	IsDraining      bool                               `protobuf:"varint,20,opt,name=is_draining,json=isDraining" json:"is_draining,omitempty"`
+	// serves only the reads, taking no writes or new volumes
+	IsReadOnly bool `protobuf:"varint,21,opt,name=is_read_only,json=isReadOnly" json:"is_read_only,omitempty"`
+	// the periodic delta heartbeats after the full one on connect, carrying the node states,
+	// the volumes changed since the last heartbeat, and the volumes gone in deleted_volumes
+	IsDelta        bool                        `protobuf:"varint,22,opt,name=is_delta,json=isDelta" json:"is_delta,omitempty"`
+	UpdatedVolumes []*VolumeInformationMessage `protobuf:"bytes,23,rep,name=updated_volumes,json=updatedVolumes" json:"updated_volumes,omitempty"`
+	// the labels of the volume server for the placement, e.g. zone=eu, class=archive
+	Labels map[string]string `protobuf:"bytes,24,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// the largest file accepted in one upload, for the filers to chunk the larger files
+	FileSizeLimit uint64 `protobuf:"varint,25,opt,name=file_size_limit,json=fileSizeLimit" json:"file_size_limit,omitempty"`
 }
 
 func (m *Heartbeat) Reset()                    { *m = Heartbeat{} }
@@ -203,11 +226,59 @@ func (m *Heartbeat) GetHasNoEcShards() bool {
 	return false
 }
 
+func (m *Heartbeat) GetIsDraining() bool {
+	if m != nil {
+		return m.IsDraining
+	}
+	return false
+}
+
+func (m *Heartbeat) GetIsReadOnly() bool {
+	if m != nil {
+		return m.IsReadOnly
+	}
+	return false
+}
+
+func (m *Heartbeat) GetIsDelta() bool {
+	if m != nil {
+		return m.IsDelta
+	}
+	return false
+}
+
+func (m *Heartbeat) GetUpdatedVolumes() []*VolumeInformationMessage {
+	if m != nil {
+		return m.UpdatedVolumes
+	}
+	return nil
+}
+
+func (m *Heartbeat) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *Heartbeat) GetFileSizeLimit() uint64 {
+	if m != nil {
+		return m.FileSizeLimit
+	}
+	return 0
+}
+
 type HeartbeatResponse struct {
 	VolumeSizeLimit        uint64 `protobuf:"varint,1,opt,name=volume_size_limit,json=volumeSizeLimit" json:"volume_size_limit,omitempty"`
 	Leader                 string `protobuf:"bytes,2,opt,name=leader" json:"leader,omitempty"`
 	MetricsAddress         string `protobuf:"bytes,3,opt,name=metrics_address,json=metricsAddress" json:"metrics_address,omitempty"`
 	MetricsIntervalSeconds uint32 `protobuf:"varint,4,opt,name=metrics_interval_seconds,json=metricsIntervalSeconds" json:"metrics_interval_seconds,omitempty"`
+	// set by the masters accepting the delta heartbeats, adapted to the heartbeat load
+	HeartbeatIntervalSeconds uint32 `protobuf:"varint,5,opt,name=heartbeat_interval_seconds,json=heartbeatIntervalSeconds" json:"heartbeat_interval_seconds,omitempty"`
+	// the ec volumes whose shard locations changed since the last heartbeat response
+	ChangedEcVids []uint32 `protobuf:"varint,6,rep,packed,name=changed_ec_vids,json=changedEcVids" json:"changed_ec_vids,omitempty"`
+	// the changes are no longer kept, so all the cached ec shard locations are outdated
+	AllEcLocationsChanged bool `protobuf:"varint,7,opt,name=all_ec_locations_changed,json=allEcLocationsChanged" json:"all_ec_locations_changed,omitempty"`
 }
 
 func (m *HeartbeatResponse) Reset()                    { *m = HeartbeatResponse{} }
@@ -243,6 +314,27 @@ func (m *HeartbeatResponse) GetMetricsIntervalSeconds() uint32 {
 	return 0
 }
 
+func (m *HeartbeatResponse) GetHeartbeatIntervalSeconds() uint32 {
+	if m != nil {
+		return m.HeartbeatIntervalSeconds
+	}
+	return 0
+}
+
+func (m *HeartbeatResponse) GetChangedEcVids() []uint32 {
+	if m != nil {
+		return m.ChangedEcVids
+	}
+	return nil
+}
+
+func (m *HeartbeatResponse) GetAllEcLocationsChanged() bool {
+	if m != nil {
+		return m.AllEcLocationsChanged
+	}
+	return false
+}
+
 type VolumeInformationMessage struct {
 	Id               uint32 `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
 	Size             uint64 `protobuf:"varint,2,opt,name=size" json:"size,omitempty"`
@@ -256,6 +348,7 @@ type VolumeInformationMessage struct {
 	Ttl              uint32 `protobuf:"varint,10,opt,name=ttl" json:"ttl,omitempty"`
 	CompactRevision  uint32 `protobuf:"varint,11,opt,name=compact_revision,json=compactRevision" json:"compact_revision,omitempty"`
 	ModifiedAtSecond int64  `protobuf:"varint,12,opt,name=modified_at_second,json=modifiedAtSecond" json:"modified_at_second,omitempty"`
+	FsyncPolicy      string `protobuf:"bytes,13,opt,name=fsync_policy,json=fsyncPolicy" json:"fsync_policy,omitempty"`
 }
 
 func (m *VolumeInformationMessage) Reset()                    { *m = VolumeInformationMessage{} }
@@ -347,6 +440,13 @@ func (m *VolumeInformationMessage) GetModifiedAtSecond() int64 {
 	return 0
 }
 
+func (m *VolumeInformationMessage) GetFsyncPolicy() string {
+	if m != nil {
+		return m.FsyncPolicy
+	}
+	return ""
+}
+
 type VolumeShortInformationMessage struct {
 	Id               uint32 `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
 	Collection       string `protobuf:"bytes,3,opt,name=collection" json:"collection,omitempty"`
@@ -398,7 +498,9 @@ func (m *VolumeShortInformationMessage) GetTtl() uint32 {
 type VolumeEcShardInformationMessage struct {
 	Id          uint32 `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
 	Collection  string `protobuf:"bytes,2,opt,name=collection" json:"collection,omitempty"`
-	EcIndexBits uint32 `protobuf:"varint,3,opt,name=ec_index_bits,json=ecIndexBits" json:"ec_index_bits,omitempty"`
+	EcIndexBits          uint32 `protobuf:"varint,3,opt,name=ec_index_bits,json=ecIndexBits" json:"ec_index_bits,omitempty"`
+	DeleteCount          uint64 `protobuf:"varint,4,opt,name=delete_count,json=deleteCount" json:"delete_count,omitempty"`
+	ReclaimableByteCount uint64 `protobuf:"varint,5,opt,name=reclaimable_byte_count,json=reclaimableByteCount" json:"reclaimable_byte_count,omitempty"`
 }
 
 func (m *VolumeEcShardInformationMessage) Reset()                    { *m = VolumeEcShardInformationMessage{} }
@@ -427,6 +529,20 @@ func (m *VolumeEcShardInformationMessage) GetEcIndexBits() uint32 {
 	return 0
 }
 
+func (m *VolumeEcShardInformationMessage) GetDeleteCount() uint64 {
+	if m != nil {
+		return m.DeleteCount
+	}
+	return 0
+}
+
+func (m *VolumeEcShardInformationMessage) GetReclaimableByteCount() uint64 {
+	if m != nil {
+		return m.ReclaimableByteCount
+	}
+	return 0
+}
+
 type Empty struct {
 }
 
@@ -506,6 +622,16 @@ type VolumeLocation struct {
 	PublicUrl   string   `protobuf:"bytes,2,opt,name=public_url,json=publicUrl" json:"public_url,omitempty"`
 	NewVids     []uint32 `protobuf:"varint,3,rep,packed,name=new_vids,json=newVids" json:"new_vids,omitempty"`
 	DeletedVids []uint32 `protobuf:"varint,4,rep,packed,name=deleted_vids,json=deletedVids" json:"deleted_vids,omitempty"`
+	// the ec volumes, also listed in new_vids and deleted_vids for the older clients
+	NewEcVids     []uint32 `protobuf:"varint,5,rep,packed,name=new_ec_vids,json=newEcVids" json:"new_ec_vids,omitempty"`
+	DeletedEcVids []uint32 `protobuf:"varint,6,rep,packed,name=deleted_ec_vids,json=deletedEcVids" json:"deleted_ec_vids,omitempty"`
+	// the topology of the volume server, for the clients to read from the nearby replicas
+	DataCenter string `protobuf:"bytes,7,opt,name=data_center,json=dataCenter" json:"data_center,omitempty"`
+	Rack       string `protobuf:"bytes,8,opt,name=rack" json:"rack,omitempty"`
+	// sent after all the locations are listed to a newly connected client
+	IsFullSyncDone bool `protobuf:"varint,9,opt,name=is_full_sync_done,json=isFullSyncDone" json:"is_full_sync_done,omitempty"`
+	// the largest file accepted by the volume server in one upload
+	FileSizeLimit uint64 `protobuf:"varint,10,opt,name=file_size_limit,json=fileSizeLimit" json:"file_size_limit,omitempty"`
 }
 
 func (m *VolumeLocation) Reset()                    { *m = VolumeLocation{} }
@@ -541,6 +667,48 @@ func (m *VolumeLocation) GetDeletedVids() []uint32 {
 	return nil
 }
 
+func (m *VolumeLocation) GetNewEcVids() []uint32 {
+	if m != nil {
+		return m.NewEcVids
+	}
+	return nil
+}
+
+func (m *VolumeLocation) GetDeletedEcVids() []uint32 {
+	if m != nil {
+		return m.DeletedEcVids
+	}
+	return nil
+}
+
+func (m *VolumeLocation) GetDataCenter() string {
+	if m != nil {
+		return m.DataCenter
+	}
+	return ""
+}
+
+func (m *VolumeLocation) GetRack() string {
+	if m != nil {
+		return m.Rack
+	}
+	return ""
+}
+
+func (m *VolumeLocation) GetIsFullSyncDone() bool {
+	if m != nil {
+		return m.IsFullSyncDone
+	}
+	return false
+}
+
+func (m *VolumeLocation) GetFileSizeLimit() uint64 {
+	if m != nil {
+		return m.FileSizeLimit
+	}
+	return 0
+}
+
 type LookupVolumeRequest struct {
 	VolumeIds  []string `protobuf:"bytes,1,rep,name=volume_ids,json=volumeIds" json:"volume_ids,omitempty"`
 	Collection string   `protobuf:"bytes,2,opt,name=collection" json:"collection,omitempty"`
@@ -567,6 +735,10 @@ func (m *LookupVolumeRequest) GetCollection() string {
 
 type LookupVolumeResponse struct {
 	VolumeIdLocations []*LookupVolumeResponse_VolumeIdLocation `protobuf:"bytes,1,rep,name=volume_id_locations,json=volumeIdLocations" json:"volume_id_locations,omitempty"`
+	// changed whenever the volume locations change, so the clients can cache the locations
+	// for up to the cache_ttl_seconds, and drop them once a different version is seen
+	TopologyVersion uint64 `protobuf:"varint,2,opt,name=topology_version,json=topologyVersion" json:"topology_version,omitempty"`
+	CacheTtlSeconds uint32 `protobuf:"varint,3,opt,name=cache_ttl_seconds,json=cacheTtlSeconds" json:"cache_ttl_seconds,omitempty"`
 }
 
 func (m *LookupVolumeResponse) Reset()                    { *m = LookupVolumeResponse{} }
@@ -581,6 +753,20 @@ func (m *LookupVolumeResponse) GetVolumeIdLocations() []*LookupVolumeResponse_Vo
 	return nil
 }
 
+func (m *LookupVolumeResponse) GetTopologyVersion() uint64 {
+	if m != nil {
+		return m.TopologyVersion
+	}
+	return 0
+}
+
+func (m *LookupVolumeResponse) GetCacheTtlSeconds() uint32 {
+	if m != nil {
+		return m.CacheTtlSeconds
+	}
+	return 0
+}
+
 type LookupVolumeResponse_VolumeIdLocation struct {
 	VolumeId  string      `protobuf:"bytes,1,opt,name=volume_id,json=volumeId" json:"volume_id,omitempty"`
 	Locations []*Location `protobuf:"bytes,2,rep,name=locations" json:"locations,omitempty"`
@@ -616,8 +802,10 @@ func (m *LookupVolumeResponse_VolumeIdLocation) GetError() string {
 }
 
 type Location struct {
-	Url       string `protobuf:"bytes,1,opt,name=url" json:"url,omitempty"`
-	PublicUrl string `protobuf:"bytes,2,opt,name=public_url,json=publicUrl" json:"public_url,omitempty"`
+	Url        string `protobuf:"bytes,1,opt,name=url" json:"url,omitempty"`
+	PublicUrl  string `protobuf:"bytes,2,opt,name=public_url,json=publicUrl" json:"public_url,omitempty"`
+	DataCenter string `protobuf:"bytes,3,opt,name=data_center,json=dataCenter" json:"data_center,omitempty"`
+	Rack       string `protobuf:"bytes,4,opt,name=rack" json:"rack,omitempty"`
 }
 
 func (m *Location) Reset()                    { *m = Location{} }
@@ -639,14 +827,37 @@ func (m *Location) GetPublicUrl() string {
 	return ""
 }
 
+func (m *Location) GetDataCenter() string {
+	if m != nil {
+		return m.DataCenter
+	}
+	return ""
+}
+
+func (m *Location) GetRack() string {
+	if m != nil {
+		return m.Rack
+	}
+	return ""
+}
+
 type AssignRequest struct {
-	Count       uint64 `protobuf:"varint,1,opt,name=count" json:"count,omitempty"`
-	Replication string `protobuf:"bytes,2,opt,name=replication" json:"replication,omitempty"`
-	Collection  string `protobuf:"bytes,3,opt,name=collection" json:"collection,omitempty"`
-	Ttl         string `protobuf:"bytes,4,opt,name=ttl" json:"ttl,omitempty"`
-	DataCenter  string `protobuf:"bytes,5,opt,name=data_center,json=dataCenter" json:"data_center,omitempty"`
-	Rack        string `protobuf:"bytes,6,opt,name=rack" json:"rack,omitempty"`
-	DataNode    string `protobuf:"bytes,7,opt,name=data_node,json=dataNode" json:"data_node,omitempty"`
+	Count         uint64 `protobuf:"varint,1,opt,name=count" json:"count,omitempty"`
+	Replication   string `protobuf:"bytes,2,opt,name=replication" json:"replication,omitempty"`
+	Collection    string `protobuf:"bytes,3,opt,name=collection" json:"collection,omitempty"`
+	Ttl           string `protobuf:"bytes,4,opt,name=ttl" json:"ttl,omitempty"`
+	DataCenter    string `protobuf:"bytes,5,opt,name=data_center,json=dataCenter" json:"data_center,omitempty"`
+	Rack          string `protobuf:"bytes,6,opt,name=rack" json:"rack,omitempty"`
+	DataNode      string `protobuf:"bytes,7,opt,name=data_node,json=dataNode" json:"data_node,omitempty"`
+	LocalityGroup string `protobuf:"bytes,8,opt,name=locality_group,json=localityGroup" json:"locality_group,omitempty"`
+	Fsync         bool   `protobuf:"varint,9,opt,name=fsync" json:"fsync,omitempty"`
+	Ack           string `protobuf:"bytes,10,opt,name=ack" json:"ack,omitempty"`
+	// place the new volumes only on the volume servers with the matching labels, e.g. "zone=eu,class!=archive"
+	LabelSelector string `protobuf:"bytes,11,opt,name=label_selector,json=labelSelector" json:"label_selector,omitempty"`
+	// keep assigning the same volume to the writer session, for reading back the recent writes together
+	Session string `protobuf:"bytes,12,opt,name=session" json:"session,omitempty"`
+	// list all the count file ids in the response, for the clients to pre-allocate the ids
+	Batch bool `protobuf:"varint,13,opt,name=batch" json:"batch,omitempty"`
 }
 
 func (m *AssignRequest) Reset()                    { *m = AssignRequest{} }
@@ -703,6 +914,48 @@ func (m *AssignRequest) GetDataNode() string {
 	return ""
 }
 
+func (m *AssignRequest) GetLocalityGroup() string {
+	if m != nil {
+		return m.LocalityGroup
+	}
+	return ""
+}
+
+func (m *AssignRequest) GetFsync() bool {
+	if m != nil {
+		return m.Fsync
+	}
+	return false
+}
+
+func (m *AssignRequest) GetAck() string {
+	if m != nil {
+		return m.Ack
+	}
+	return ""
+}
+
+func (m *AssignRequest) GetLabelSelector() string {
+	if m != nil {
+		return m.LabelSelector
+	}
+	return ""
+}
+
+func (m *AssignRequest) GetSession() string {
+	if m != nil {
+		return m.Session
+	}
+	return ""
+}
+
+func (m *AssignRequest) GetBatch() bool {
+	if m != nil {
+		return m.Batch
+	}
+	return false
+}
+
 type AssignResponse struct {
 	Fid       string `protobuf:"bytes,1,opt,name=fid" json:"fid,omitempty"`
 	Url       string `protobuf:"bytes,2,opt,name=url" json:"url,omitempty"`
@@ -710,6 +963,10 @@ type AssignResponse struct {
 	Count     uint64 `protobuf:"varint,4,opt,name=count" json:"count,omitempty"`
 	Error     string `protobuf:"bytes,5,opt,name=error" json:"error,omitempty"`
 	Auth      string `protobuf:"bytes,6,opt,name=auth" json:"auth,omitempty"`
+	Fsync     bool   `protobuf:"varint,7,opt,name=fsync" json:"fsync,omitempty"`
+	Ack       string `protobuf:"bytes,8,opt,name=ack" json:"ack,omitempty"`
+	// the consecutive file ids on the same volume, only listed for the batch assign
+	Fids []string `protobuf:"bytes,9,rep,name=fids" json:"fids,omitempty"`
 }
 
 func (m *AssignResponse) Reset()                    { *m = AssignResponse{} }
@@ -759,6 +1016,27 @@ func (m *AssignResponse) GetAuth() string {
 	return ""
 }
 
+func (m *AssignResponse) GetFsync() bool {
+	if m != nil {
+		return m.Fsync
+	}
+	return false
+}
+
+func (m *AssignResponse) GetAck() string {
+	if m != nil {
+		return m.Ack
+	}
+	return ""
+}
+
+func (m *AssignResponse) GetFids() []string {
+	if m != nil {
+		return m.Fids
+	}
+	return nil
+}
+
 type StatisticsRequest struct {
 	Replication string `protobuf:"bytes,1,opt,name=replication" json:"replication,omitempty"`
 	Collection  string `protobuf:"bytes,2,opt,name=collection" json:"collection,omitempty"`
@@ -873,6 +1151,11 @@ func (m *StorageType) GetTtl() string {
 
 type Collection struct {
 	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	// the defaults from collection.create
+	Replication       string `protobuf:"bytes,2,opt,name=replication" json:"replication,omitempty"`
+	Ttl               string `protobuf:"bytes,3,opt,name=ttl" json:"ttl,omitempty"`
+	VolumeSizeLimitMb uint64 `protobuf:"varint,4,opt,name=volume_size_limit_mb,json=volumeSizeLimitMb" json:"volume_size_limit_mb,omitempty"`
+	NeedleTtl         bool   `protobuf:"varint,5,opt,name=needle_ttl,json=needleTtl" json:"needle_ttl,omitempty"`
 }
 
 func (m *Collection) Reset()                    { *m = Collection{} }
@@ -887,6 +1170,34 @@ func (m *Collection) GetName() string {
 	return ""
 }
 
+func (m *Collection) GetReplication() string {
+	if m != nil {
+		return m.Replication
+	}
+	return ""
+}
+
+func (m *Collection) GetTtl() string {
+	if m != nil {
+		return m.Ttl
+	}
+	return ""
+}
+
+func (m *Collection) GetVolumeSizeLimitMb() uint64 {
+	if m != nil {
+		return m.VolumeSizeLimitMb
+	}
+	return 0
+}
+
+func (m *Collection) GetNeedleTtl() bool {
+	if m != nil {
+		return m.NeedleTtl
+	}
+	return false
+}
+
 type CollectionListRequest struct {
 	IncludeNormalVolumes bool `protobuf:"varint,1,opt,name=include_normal_volumes,json=includeNormalVolumes" json:"include_normal_volumes,omitempty"`
 	IncludeEcVolumes     bool `protobuf:"varint,2,opt,name=include_ec_volumes,json=includeEcVolumes" json:"include_ec_volumes,omitempty"`
@@ -951,6 +1262,62 @@ func (m *CollectionDeleteResponse) String() string            { return proto.Com
 func (*CollectionDeleteResponse) ProtoMessage()               {}
 func (*CollectionDeleteResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
 
+type CollectionCreateRequest struct {
+	Name              string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Replication       string `protobuf:"bytes,2,opt,name=replication" json:"replication,omitempty"`
+	Ttl               string `protobuf:"bytes,3,opt,name=ttl" json:"ttl,omitempty"`
+	VolumeSizeLimitMb uint64 `protobuf:"varint,4,opt,name=volume_size_limit_mb,json=volumeSizeLimitMb" json:"volume_size_limit_mb,omitempty"`
+	NeedleTtl         bool   `protobuf:"varint,5,opt,name=needle_ttl,json=needleTtl" json:"needle_ttl,omitempty"`
+}
+
+func (m *CollectionCreateRequest) Reset()                    { *m = CollectionCreateRequest{} }
+func (m *CollectionCreateRequest) String() string            { return proto.CompactTextString(m) }
+func (*CollectionCreateRequest) ProtoMessage()               {}
+func (*CollectionCreateRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+
+func (m *CollectionCreateRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *CollectionCreateRequest) GetReplication() string {
+	if m != nil {
+		return m.Replication
+	}
+	return ""
+}
+
+func (m *CollectionCreateRequest) GetTtl() string {
+	if m != nil {
+		return m.Ttl
+	}
+	return ""
+}
+
+func (m *CollectionCreateRequest) GetVolumeSizeLimitMb() uint64 {
+	if m != nil {
+		return m.VolumeSizeLimitMb
+	}
+	return 0
+}
+
+func (m *CollectionCreateRequest) GetNeedleTtl() bool {
+	if m != nil {
+		return m.NeedleTtl
+	}
+	return false
+}
+
+type CollectionCreateResponse struct {
+}
+
+func (m *CollectionCreateResponse) Reset()                    { *m = CollectionCreateResponse{} }
+func (m *CollectionCreateResponse) String() string            { return proto.CompactTextString(m) }
+func (*CollectionCreateResponse) ProtoMessage()               {}
+func (*CollectionCreateResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
+
 //
 // volume related
 //
@@ -962,12 +1329,19 @@ type DataNodeInfo struct {
 	ActiveVolumeCount uint64                             `protobuf:"varint,5,opt,name=active_volume_count,json=activeVolumeCount" json:"active_volume_count,omitempty"`
 	VolumeInfos       []*VolumeInformationMessage        `protobuf:"bytes,6,rep,name=volume_infos,json=volumeInfos" json:"volume_infos,omitempty"`
 	EcShardInfos      []*VolumeEcShardInformationMessage `protobuf:"bytes,7,rep,name=ec_shard_infos,json=ecShardInfos" json:"ec_shard_infos,omitempty"`
+	IsDraining        bool                               `protobuf:"varint,8,opt,name=is_draining,json=isDraining" json:"is_draining,omitempty"`
+	IsReadOnly        bool                               `protobuf:"varint,9,opt,name=is_read_only,json=isReadOnly" json:"is_read_only,omitempty"`
+	IsQuarantined     bool                               `protobuf:"varint,10,opt,name=is_quarantined,json=isQuarantined" json:"is_quarantined,omitempty"`
+	// the forecast of the master, the days_to_full is only set if growing
+	GrowthBytesPerDay float64           `protobuf:"fixed64,11,opt,name=growth_bytes_per_day,json=growthBytesPerDay" json:"growth_bytes_per_day,omitempty"`
+	DaysToFull        float64           `protobuf:"fixed64,12,opt,name=days_to_full,json=daysToFull" json:"days_to_full,omitempty"`
+	Labels            map[string]string `protobuf:"bytes,13,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 }
 
 func (m *DataNodeInfo) Reset()                    { *m = DataNodeInfo{} }
 func (m *DataNodeInfo) String() string            { return proto.CompactTextString(m) }
 func (*DataNodeInfo) ProtoMessage()               {}
-func (*DataNodeInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+func (*DataNodeInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
 
 func (m *DataNodeInfo) GetId() string {
 	if m != nil {
@@ -1018,6 +1392,48 @@ func (m *DataNodeInfo) GetEcShardInfos() []*VolumeEcShardInformationMessage {
 	return nil
 }
 
+func (m *DataNodeInfo) GetIsDraining() bool {
+	if m != nil {
+		return m.IsDraining
+	}
+	return false
+}
+
+func (m *DataNodeInfo) GetIsReadOnly() bool {
+	if m != nil {
+		return m.IsReadOnly
+	}
+	return false
+}
+
+func (m *DataNodeInfo) GetIsQuarantined() bool {
+	if m != nil {
+		return m.IsQuarantined
+	}
+	return false
+}
+
+func (m *DataNodeInfo) GetGrowthBytesPerDay() float64 {
+	if m != nil {
+		return m.GrowthBytesPerDay
+	}
+	return 0
+}
+
+func (m *DataNodeInfo) GetDaysToFull() float64 {
+	if m != nil {
+		return m.DaysToFull
+	}
+	return 0
+}
+
+func (m *DataNodeInfo) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
 type RackInfo struct {
 	Id                string          `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
 	VolumeCount       uint64          `protobuf:"varint,2,opt,name=volume_count,json=volumeCount" json:"volume_count,omitempty"`
@@ -1030,7 +1446,7 @@ type RackInfo struct {
 func (m *RackInfo) Reset()                    { *m = RackInfo{} }
 func (m *RackInfo) String() string            { return proto.CompactTextString(m) }
 func (*RackInfo) ProtoMessage()               {}
-func (*RackInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
+func (*RackInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
 
 func (m *RackInfo) GetId() string {
 	if m != nil {
@@ -1086,7 +1502,7 @@ type DataCenterInfo struct {
 func (m *DataCenterInfo) Reset()                    { *m = DataCenterInfo{} }
 func (m *DataCenterInfo) String() string            { return proto.CompactTextString(m) }
 func (*DataCenterInfo) ProtoMessage()               {}
-func (*DataCenterInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
+func (*DataCenterInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
 
 func (m *DataCenterInfo) GetId() string {
 	if m != nil {
@@ -1142,7 +1558,7 @@ type TopologyInfo struct {
 func (m *TopologyInfo) Reset()                    { *m = TopologyInfo{} }
 func (m *TopologyInfo) String() string            { return proto.CompactTextString(m) }
 func (*TopologyInfo) ProtoMessage()               {}
-func (*TopologyInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
+func (*TopologyInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{27} }
 
 func (m *TopologyInfo) GetId() string {
 	if m != nil {
@@ -1192,7 +1608,7 @@ type VolumeListRequest struct {
 func (m *VolumeListRequest) Reset()                    { *m = VolumeListRequest{} }
 func (m *VolumeListRequest) String() string            { return proto.CompactTextString(m) }
 func (*VolumeListRequest) ProtoMessage()               {}
-func (*VolumeListRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
+func (*VolumeListRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{28} }
 
 type VolumeListResponse struct {
 	TopologyInfo      *TopologyInfo `protobuf:"bytes,1,opt,name=topology_info,json=topologyInfo" json:"topology_info,omitempty"`
@@ -1202,7 +1618,7 @@ type VolumeListResponse struct {
 func (m *VolumeListResponse) Reset()                    { *m = VolumeListResponse{} }
 func (m *VolumeListResponse) String() string            { return proto.CompactTextString(m) }
 func (*VolumeListResponse) ProtoMessage()               {}
-func (*VolumeListResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{27} }
+func (*VolumeListResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{29} }
 
 func (m *VolumeListResponse) GetTopologyInfo() *TopologyInfo {
 	if m != nil {
@@ -1225,7 +1641,7 @@ type LookupEcVolumeRequest struct {
 func (m *LookupEcVolumeRequest) Reset()                    { *m = LookupEcVolumeRequest{} }
 func (m *LookupEcVolumeRequest) String() string            { return proto.CompactTextString(m) }
 func (*LookupEcVolumeRequest) ProtoMessage()               {}
-func (*LookupEcVolumeRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{28} }
+func (*LookupEcVolumeRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{30} }
 
 func (m *LookupEcVolumeRequest) GetVolumeId() uint32 {
 	if m != nil {
@@ -1242,7 +1658,7 @@ type LookupEcVolumeResponse struct {
 func (m *LookupEcVolumeResponse) Reset()                    { *m = LookupEcVolumeResponse{} }
 func (m *LookupEcVolumeResponse) String() string            { return proto.CompactTextString(m) }
 func (*LookupEcVolumeResponse) ProtoMessage()               {}
-func (*LookupEcVolumeResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{29} }
+func (*LookupEcVolumeResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{31} }
 
 func (m *LookupEcVolumeResponse) GetVolumeId() uint32 {
 	if m != nil {
@@ -1269,7 +1685,7 @@ func (m *LookupEcVolumeResponse_EcShardIdLocation) Reset() {
 func (m *LookupEcVolumeResponse_EcShardIdLocation) String() string { return proto.CompactTextString(m) }
 func (*LookupEcVolumeResponse_EcShardIdLocation) ProtoMessage()    {}
 func (*LookupEcVolumeResponse_EcShardIdLocation) Descriptor() ([]byte, []int) {
-	return fileDescriptor0, []int{29, 0}
+	return fileDescriptor0, []int{31, 0}
 }
 
 func (m *LookupEcVolumeResponse_EcShardIdLocation) GetShardId() uint32 {
@@ -1292,7 +1708,7 @@ type GetMasterConfigurationRequest struct {
 func (m *GetMasterConfigurationRequest) Reset()                    { *m = GetMasterConfigurationRequest{} }
 func (m *GetMasterConfigurationRequest) String() string            { return proto.CompactTextString(m) }
 func (*GetMasterConfigurationRequest) ProtoMessage()               {}
-func (*GetMasterConfigurationRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{30} }
+func (*GetMasterConfigurationRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{32} }
 
 type GetMasterConfigurationResponse struct {
 	MetricsAddress         string `protobuf:"bytes,1,opt,name=metrics_address,json=metricsAddress" json:"metrics_address,omitempty"`
@@ -1302,7 +1718,7 @@ type GetMasterConfigurationResponse struct {
 func (m *GetMasterConfigurationResponse) Reset()                    { *m = GetMasterConfigurationResponse{} }
 func (m *GetMasterConfigurationResponse) String() string            { return proto.CompactTextString(m) }
 func (*GetMasterConfigurationResponse) ProtoMessage()               {}
-func (*GetMasterConfigurationResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{31} }
+func (*GetMasterConfigurationResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{33} }
 
 func (m *GetMasterConfigurationResponse) GetMetricsAddress() string {
 	if m != nil {
@@ -1318,14 +1734,420 @@ func (m *GetMasterConfigurationResponse) GetMetricsIntervalSeconds() uint32 {
 	return 0
 }
 
-func init() {
-	proto.RegisterType((*Heartbeat)(nil), "master_pb.Heartbeat")
-	proto.RegisterType((*HeartbeatResponse)(nil), "master_pb.HeartbeatResponse")
-	proto.RegisterType((*VolumeInformationMessage)(nil), "master_pb.VolumeInformationMessage")
-	proto.RegisterType((*VolumeShortInformationMessage)(nil), "master_pb.VolumeShortInformationMessage")
-	proto.RegisterType((*VolumeEcShardInformationMessage)(nil), "master_pb.VolumeEcShardInformationMessage")
-	proto.RegisterType((*Empty)(nil), "master_pb.Empty")
-	proto.RegisterType((*SuperBlockExtra)(nil), "master_pb.SuperBlockExtra")
+type ClusterStatusRequest struct {
+}
+
+func (m *ClusterStatusRequest) Reset()                    { *m = ClusterStatusRequest{} }
+func (m *ClusterStatusRequest) String() string            { return proto.CompactTextString(m) }
+func (*ClusterStatusRequest) ProtoMessage()               {}
+func (*ClusterStatusRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34} }
+
+type ClusterStatusResponse struct {
+	IsLeader        bool              `protobuf:"varint,1,opt,name=is_leader,json=isLeader" json:"is_leader,omitempty"`
+	Leader          string            `protobuf:"bytes,2,opt,name=leader" json:"leader,omitempty"`
+	Peers           []*RaftPeerStatus `protobuf:"bytes,3,rep,name=peers" json:"peers,omitempty"`
+	RaftState       string            `protobuf:"bytes,4,opt,name=raft_state,json=raftState" json:"raft_state,omitempty"`
+	RaftTerm        uint64            `protobuf:"varint,5,opt,name=raft_term,json=raftTerm" json:"raft_term,omitempty"`
+	RaftCommitIndex uint64            `protobuf:"varint,6,opt,name=raft_commit_index,json=raftCommitIndex" json:"raft_commit_index,omitempty"`
+	// the capacity and the volume servers are only reported by the leader
+	Capacity          *CapacityStatus     `protobuf:"bytes,7,opt,name=capacity" json:"capacity,omitempty"`
+	DataCenters       []*DataCenterStatus `protobuf:"bytes,8,rep,name=data_centers,json=dataCenters" json:"data_centers,omitempty"`
+	VolumeSizeLimitMb uint64              `protobuf:"varint,9,opt,name=volume_size_limit_mb,json=volumeSizeLimitMb" json:"volume_size_limit_mb,omitempty"`
+}
+
+func (m *ClusterStatusResponse) Reset()                    { *m = ClusterStatusResponse{} }
+func (m *ClusterStatusResponse) String() string            { return proto.CompactTextString(m) }
+func (*ClusterStatusResponse) ProtoMessage()               {}
+func (*ClusterStatusResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{35} }
+
+func (m *ClusterStatusResponse) GetIsLeader() bool {
+	if m != nil {
+		return m.IsLeader
+	}
+	return false
+}
+
+func (m *ClusterStatusResponse) GetLeader() string {
+	if m != nil {
+		return m.Leader
+	}
+	return ""
+}
+
+func (m *ClusterStatusResponse) GetPeers() []*RaftPeerStatus {
+	if m != nil {
+		return m.Peers
+	}
+	return nil
+}
+
+func (m *ClusterStatusResponse) GetRaftState() string {
+	if m != nil {
+		return m.RaftState
+	}
+	return ""
+}
+
+func (m *ClusterStatusResponse) GetRaftTerm() uint64 {
+	if m != nil {
+		return m.RaftTerm
+	}
+	return 0
+}
+
+func (m *ClusterStatusResponse) GetRaftCommitIndex() uint64 {
+	if m != nil {
+		return m.RaftCommitIndex
+	}
+	return 0
+}
+
+func (m *ClusterStatusResponse) GetCapacity() *CapacityStatus {
+	if m != nil {
+		return m.Capacity
+	}
+	return nil
+}
+
+func (m *ClusterStatusResponse) GetDataCenters() []*DataCenterStatus {
+	if m != nil {
+		return m.DataCenters
+	}
+	return nil
+}
+
+func (m *ClusterStatusResponse) GetVolumeSizeLimitMb() uint64 {
+	if m != nil {
+		return m.VolumeSizeLimitMb
+	}
+	return 0
+}
+
+type RaftPeerStatus struct {
+	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	// only known by the leader, -1 if unknown
+	LastActivitySecondsAgo int64 `protobuf:"varint,2,opt,name=last_activity_seconds_ago,json=lastActivitySecondsAgo" json:"last_activity_seconds_ago,omitempty"`
+}
+
+func (m *RaftPeerStatus) Reset()                    { *m = RaftPeerStatus{} }
+func (m *RaftPeerStatus) String() string            { return proto.CompactTextString(m) }
+func (*RaftPeerStatus) ProtoMessage()               {}
+func (*RaftPeerStatus) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{36} }
+
+func (m *RaftPeerStatus) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *RaftPeerStatus) GetLastActivitySecondsAgo() int64 {
+	if m != nil {
+		return m.LastActivitySecondsAgo
+	}
+	return 0
+}
+
+type CapacityStatus struct {
+	MaxVolumeCount    uint64 `protobuf:"varint,1,opt,name=max_volume_count,json=maxVolumeCount" json:"max_volume_count,omitempty"`
+	VolumeCount       uint64 `protobuf:"varint,2,opt,name=volume_count,json=volumeCount" json:"volume_count,omitempty"`
+	ActiveVolumeCount uint64 `protobuf:"varint,3,opt,name=active_volume_count,json=activeVolumeCount" json:"active_volume_count,omitempty"`
+	FreeVolumeCount   uint64 `protobuf:"varint,4,opt,name=free_volume_count,json=freeVolumeCount" json:"free_volume_count,omitempty"`
+	EcShardCount      uint64 `protobuf:"varint,5,opt,name=ec_shard_count,json=ecShardCount" json:"ec_shard_count,omitempty"`
+	VolumeServerCount uint64 `protobuf:"varint,6,opt,name=volume_server_count,json=volumeServerCount" json:"volume_server_count,omitempty"`
+	// the draining, read only or quarantined volume servers, which take no new volumes
+	UnwritableVolumeServerCount uint64 `protobuf:"varint,7,opt,name=unwritable_volume_server_count,json=unwritableVolumeServerCount" json:"unwritable_volume_server_count,omitempty"`
+}
+
+func (m *CapacityStatus) Reset()                    { *m = CapacityStatus{} }
+func (m *CapacityStatus) String() string            { return proto.CompactTextString(m) }
+func (*CapacityStatus) ProtoMessage()               {}
+func (*CapacityStatus) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{37} }
+
+func (m *CapacityStatus) GetMaxVolumeCount() uint64 {
+	if m != nil {
+		return m.MaxVolumeCount
+	}
+	return 0
+}
+
+func (m *CapacityStatus) GetVolumeCount() uint64 {
+	if m != nil {
+		return m.VolumeCount
+	}
+	return 0
+}
+
+func (m *CapacityStatus) GetActiveVolumeCount() uint64 {
+	if m != nil {
+		return m.ActiveVolumeCount
+	}
+	return 0
+}
+
+func (m *CapacityStatus) GetFreeVolumeCount() uint64 {
+	if m != nil {
+		return m.FreeVolumeCount
+	}
+	return 0
+}
+
+func (m *CapacityStatus) GetEcShardCount() uint64 {
+	if m != nil {
+		return m.EcShardCount
+	}
+	return 0
+}
+
+func (m *CapacityStatus) GetVolumeServerCount() uint64 {
+	if m != nil {
+		return m.VolumeServerCount
+	}
+	return 0
+}
+
+func (m *CapacityStatus) GetUnwritableVolumeServerCount() uint64 {
+	if m != nil {
+		return m.UnwritableVolumeServerCount
+	}
+	return 0
+}
+
+type DataCenterStatus struct {
+	Id       string          `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Capacity *CapacityStatus `protobuf:"bytes,2,opt,name=capacity" json:"capacity,omitempty"`
+	Racks    []*RackStatus   `protobuf:"bytes,3,rep,name=racks" json:"racks,omitempty"`
+}
+
+func (m *DataCenterStatus) Reset()                    { *m = DataCenterStatus{} }
+func (m *DataCenterStatus) String() string            { return proto.CompactTextString(m) }
+func (*DataCenterStatus) ProtoMessage()               {}
+func (*DataCenterStatus) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{38} }
+
+func (m *DataCenterStatus) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *DataCenterStatus) GetCapacity() *CapacityStatus {
+	if m != nil {
+		return m.Capacity
+	}
+	return nil
+}
+
+func (m *DataCenterStatus) GetRacks() []*RackStatus {
+	if m != nil {
+		return m.Racks
+	}
+	return nil
+}
+
+type RackStatus struct {
+	Id            string                `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Capacity      *CapacityStatus       `protobuf:"bytes,2,opt,name=capacity" json:"capacity,omitempty"`
+	VolumeServers []*VolumeServerStatus `protobuf:"bytes,3,rep,name=volume_servers,json=volumeServers" json:"volume_servers,omitempty"`
+}
+
+func (m *RackStatus) Reset()                    { *m = RackStatus{} }
+func (m *RackStatus) String() string            { return proto.CompactTextString(m) }
+func (*RackStatus) ProtoMessage()               {}
+func (*RackStatus) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{39} }
+
+func (m *RackStatus) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *RackStatus) GetCapacity() *CapacityStatus {
+	if m != nil {
+		return m.Capacity
+	}
+	return nil
+}
+
+func (m *RackStatus) GetVolumeServers() []*VolumeServerStatus {
+	if m != nil {
+		return m.VolumeServers
+	}
+	return nil
+}
+
+type VolumeServerStatus struct {
+	Url                     string          `protobuf:"bytes,1,opt,name=url" json:"url,omitempty"`
+	PublicUrl               string          `protobuf:"bytes,2,opt,name=public_url,json=publicUrl" json:"public_url,omitempty"`
+	Capacity                *CapacityStatus `protobuf:"bytes,3,opt,name=capacity" json:"capacity,omitempty"`
+	LastHeartbeatSecondsAgo int64           `protobuf:"varint,4,opt,name=last_heartbeat_seconds_ago,json=lastHeartbeatSecondsAgo" json:"last_heartbeat_seconds_ago,omitempty"`
+	IsDraining              bool            `protobuf:"varint,5,opt,name=is_draining,json=isDraining" json:"is_draining,omitempty"`
+	IsReadOnly              bool            `protobuf:"varint,6,opt,name=is_read_only,json=isReadOnly" json:"is_read_only,omitempty"`
+	IsQuarantined           bool            `protobuf:"varint,7,opt,name=is_quarantined,json=isQuarantined" json:"is_quarantined,omitempty"`
+}
+
+func (m *VolumeServerStatus) Reset()                    { *m = VolumeServerStatus{} }
+func (m *VolumeServerStatus) String() string            { return proto.CompactTextString(m) }
+func (*VolumeServerStatus) ProtoMessage()               {}
+func (*VolumeServerStatus) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{40} }
+
+func (m *VolumeServerStatus) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *VolumeServerStatus) GetPublicUrl() string {
+	if m != nil {
+		return m.PublicUrl
+	}
+	return ""
+}
+
+func (m *VolumeServerStatus) GetCapacity() *CapacityStatus {
+	if m != nil {
+		return m.Capacity
+	}
+	return nil
+}
+
+func (m *VolumeServerStatus) GetLastHeartbeatSecondsAgo() int64 {
+	if m != nil {
+		return m.LastHeartbeatSecondsAgo
+	}
+	return 0
+}
+
+func (m *VolumeServerStatus) GetIsDraining() bool {
+	if m != nil {
+		return m.IsDraining
+	}
+	return false
+}
+
+func (m *VolumeServerStatus) GetIsReadOnly() bool {
+	if m != nil {
+		return m.IsReadOnly
+	}
+	return false
+}
+
+func (m *VolumeServerStatus) GetIsQuarantined() bool {
+	if m != nil {
+		return m.IsQuarantined
+	}
+	return false
+}
+
+type VolumeGrowthStrategy struct {
+	Collection string `protobuf:"bytes,1,opt,name=collection" json:"collection,omitempty"`
+	// fixed, target, or adaptive
+	Kind               string `protobuf:"bytes,2,opt,name=kind" json:"kind,omitempty"`
+	WritableTarget     uint32 `protobuf:"varint,3,opt,name=writable_target,json=writableTarget" json:"writable_target,omitempty"`
+	PeakWritableTarget uint32 `protobuf:"varint,4,opt,name=peak_writable_target,json=peakWritableTarget" json:"peak_writable_target,omitempty"`
+	PeakHours          string `protobuf:"bytes,5,opt,name=peak_hours,json=peakHours" json:"peak_hours,omitempty"`
+}
+
+func (m *VolumeGrowthStrategy) Reset()                    { *m = VolumeGrowthStrategy{} }
+func (m *VolumeGrowthStrategy) String() string            { return proto.CompactTextString(m) }
+func (*VolumeGrowthStrategy) ProtoMessage()               {}
+func (*VolumeGrowthStrategy) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{41} }
+
+func (m *VolumeGrowthStrategy) GetCollection() string {
+	if m != nil {
+		return m.Collection
+	}
+	return ""
+}
+
+func (m *VolumeGrowthStrategy) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func (m *VolumeGrowthStrategy) GetWritableTarget() uint32 {
+	if m != nil {
+		return m.WritableTarget
+	}
+	return 0
+}
+
+func (m *VolumeGrowthStrategy) GetPeakWritableTarget() uint32 {
+	if m != nil {
+		return m.PeakWritableTarget
+	}
+	return 0
+}
+
+func (m *VolumeGrowthStrategy) GetPeakHours() string {
+	if m != nil {
+		return m.PeakHours
+	}
+	return ""
+}
+
+type ConfigureVolumeGrowthRequest struct {
+	// only list the strategies if not set
+	Strategy *VolumeGrowthStrategy `protobuf:"bytes,1,opt,name=strategy" json:"strategy,omitempty"`
+	// remove the strategy of the collection, to grow as the fixed strategy
+	Remove     bool   `protobuf:"varint,2,opt,name=remove" json:"remove,omitempty"`
+	Collection string `protobuf:"bytes,3,opt,name=collection" json:"collection,omitempty"`
+}
+
+func (m *ConfigureVolumeGrowthRequest) Reset()                    { *m = ConfigureVolumeGrowthRequest{} }
+func (m *ConfigureVolumeGrowthRequest) String() string            { return proto.CompactTextString(m) }
+func (*ConfigureVolumeGrowthRequest) ProtoMessage()               {}
+func (*ConfigureVolumeGrowthRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{42} }
+
+func (m *ConfigureVolumeGrowthRequest) GetStrategy() *VolumeGrowthStrategy {
+	if m != nil {
+		return m.Strategy
+	}
+	return nil
+}
+
+func (m *ConfigureVolumeGrowthRequest) GetRemove() bool {
+	if m != nil {
+		return m.Remove
+	}
+	return false
+}
+
+func (m *ConfigureVolumeGrowthRequest) GetCollection() string {
+	if m != nil {
+		return m.Collection
+	}
+	return ""
+}
+
+type ConfigureVolumeGrowthResponse struct {
+	Strategies []*VolumeGrowthStrategy `protobuf:"bytes,1,rep,name=strategies" json:"strategies,omitempty"`
+}
+
+func (m *ConfigureVolumeGrowthResponse) Reset()                    { *m = ConfigureVolumeGrowthResponse{} }
+func (m *ConfigureVolumeGrowthResponse) String() string            { return proto.CompactTextString(m) }
+func (*ConfigureVolumeGrowthResponse) ProtoMessage()               {}
+func (*ConfigureVolumeGrowthResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{43} }
+
+func (m *ConfigureVolumeGrowthResponse) GetStrategies() []*VolumeGrowthStrategy {
+	if m != nil {
+		return m.Strategies
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*Heartbeat)(nil), "master_pb.Heartbeat")
+	proto.RegisterType((*HeartbeatResponse)(nil), "master_pb.HeartbeatResponse")
+	proto.RegisterType((*VolumeInformationMessage)(nil), "master_pb.VolumeInformationMessage")
+	proto.RegisterType((*VolumeShortInformationMessage)(nil), "master_pb.VolumeShortInformationMessage")
+	proto.RegisterType((*VolumeEcShardInformationMessage)(nil), "master_pb.VolumeEcShardInformationMessage")
+	proto.RegisterType((*Empty)(nil), "master_pb.Empty")
+	proto.RegisterType((*SuperBlockExtra)(nil), "master_pb.SuperBlockExtra")
 	proto.RegisterType((*SuperBlockExtra_ErasureCoding)(nil), "master_pb.SuperBlockExtra.ErasureCoding")
 	proto.RegisterType((*ClientListenRequest)(nil), "master_pb.ClientListenRequest")
 	proto.RegisterType((*VolumeLocation)(nil), "master_pb.VolumeLocation")
@@ -1343,6 +2165,8 @@ func init() {
 	proto.RegisterType((*CollectionListResponse)(nil), "master_pb.CollectionListResponse")
 	proto.RegisterType((*CollectionDeleteRequest)(nil), "master_pb.CollectionDeleteRequest")
 	proto.RegisterType((*CollectionDeleteResponse)(nil), "master_pb.CollectionDeleteResponse")
+	proto.RegisterType((*CollectionCreateRequest)(nil), "master_pb.CollectionCreateRequest")
+	proto.RegisterType((*CollectionCreateResponse)(nil), "master_pb.CollectionCreateResponse")
 	proto.RegisterType((*DataNodeInfo)(nil), "master_pb.DataNodeInfo")
 	proto.RegisterType((*RackInfo)(nil), "master_pb.RackInfo")
 	proto.RegisterType((*DataCenterInfo)(nil), "master_pb.DataCenterInfo")
@@ -1354,6 +2178,16 @@ func init() {
 	proto.RegisterType((*LookupEcVolumeResponse_EcShardIdLocation)(nil), "master_pb.LookupEcVolumeResponse.EcShardIdLocation")
 	proto.RegisterType((*GetMasterConfigurationRequest)(nil), "master_pb.GetMasterConfigurationRequest")
 	proto.RegisterType((*GetMasterConfigurationResponse)(nil), "master_pb.GetMasterConfigurationResponse")
+	proto.RegisterType((*ClusterStatusRequest)(nil), "master_pb.ClusterStatusRequest")
+	proto.RegisterType((*ClusterStatusResponse)(nil), "master_pb.ClusterStatusResponse")
+	proto.RegisterType((*RaftPeerStatus)(nil), "master_pb.RaftPeerStatus")
+	proto.RegisterType((*CapacityStatus)(nil), "master_pb.CapacityStatus")
+	proto.RegisterType((*DataCenterStatus)(nil), "master_pb.DataCenterStatus")
+	proto.RegisterType((*RackStatus)(nil), "master_pb.RackStatus")
+	proto.RegisterType((*VolumeServerStatus)(nil), "master_pb.VolumeServerStatus")
+	proto.RegisterType((*VolumeGrowthStrategy)(nil), "master_pb.VolumeGrowthStrategy")
+	proto.RegisterType((*ConfigureVolumeGrowthRequest)(nil), "master_pb.ConfigureVolumeGrowthRequest")
+	proto.RegisterType((*ConfigureVolumeGrowthResponse)(nil), "master_pb.ConfigureVolumeGrowthResponse")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -1374,9 +2208,12 @@ type SeaweedClient interface {
 	Statistics(ctx context.Context, in *StatisticsRequest, opts ...grpc.CallOption) (*StatisticsResponse, error)
 	CollectionList(ctx context.Context, in *CollectionListRequest, opts ...grpc.CallOption) (*CollectionListResponse, error)
 	CollectionDelete(ctx context.Context, in *CollectionDeleteRequest, opts ...grpc.CallOption) (*CollectionDeleteResponse, error)
+	CollectionCreate(ctx context.Context, in *CollectionCreateRequest, opts ...grpc.CallOption) (*CollectionCreateResponse, error)
 	VolumeList(ctx context.Context, in *VolumeListRequest, opts ...grpc.CallOption) (*VolumeListResponse, error)
 	LookupEcVolume(ctx context.Context, in *LookupEcVolumeRequest, opts ...grpc.CallOption) (*LookupEcVolumeResponse, error)
 	GetMasterConfiguration(ctx context.Context, in *GetMasterConfigurationRequest, opts ...grpc.CallOption) (*GetMasterConfigurationResponse, error)
+	ClusterStatus(ctx context.Context, in *ClusterStatusRequest, opts ...grpc.CallOption) (*ClusterStatusResponse, error)
+	ConfigureVolumeGrowth(ctx context.Context, in *ConfigureVolumeGrowthRequest, opts ...grpc.CallOption) (*ConfigureVolumeGrowthResponse, error)
 }
 
 type seaweedClient struct {
@@ -1494,6 +2331,15 @@ func (c *seaweedClient) CollectionDelete(ctx context.Context, in *CollectionDele
 	return out, nil
 }
 
+func (c *seaweedClient) CollectionCreate(ctx context.Context, in *CollectionCreateRequest, opts ...grpc.CallOption) (*CollectionCreateResponse, error) {
+	out := new(CollectionCreateResponse)
+	err := grpc.Invoke(ctx, "/master_pb.Seaweed/CollectionCreate", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *seaweedClient) VolumeList(ctx context.Context, in *VolumeListRequest, opts ...grpc.CallOption) (*VolumeListResponse, error) {
 	out := new(VolumeListResponse)
 	err := grpc.Invoke(ctx, "/master_pb.Seaweed/VolumeList", in, out, c.cc, opts...)
@@ -1521,6 +2367,24 @@ func (c *seaweedClient) GetMasterConfiguration(ctx context.Context, in *GetMaste
 	return out, nil
 }
 
+func (c *seaweedClient) ClusterStatus(ctx context.Context, in *ClusterStatusRequest, opts ...grpc.CallOption) (*ClusterStatusResponse, error) {
+	out := new(ClusterStatusResponse)
+	err := grpc.Invoke(ctx, "/master_pb.Seaweed/ClusterStatus", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *seaweedClient) ConfigureVolumeGrowth(ctx context.Context, in *ConfigureVolumeGrowthRequest, opts ...grpc.CallOption) (*ConfigureVolumeGrowthResponse, error) {
+	out := new(ConfigureVolumeGrowthResponse)
+	err := grpc.Invoke(ctx, "/master_pb.Seaweed/ConfigureVolumeGrowth", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Seaweed service
 
 type SeaweedServer interface {
@@ -1531,9 +2395,12 @@ type SeaweedServer interface {
 	Statistics(context.Context, *StatisticsRequest) (*StatisticsResponse, error)
 	CollectionList(context.Context, *CollectionListRequest) (*CollectionListResponse, error)
 	CollectionDelete(context.Context, *CollectionDeleteRequest) (*CollectionDeleteResponse, error)
+	CollectionCreate(context.Context, *CollectionCreateRequest) (*CollectionCreateResponse, error)
 	VolumeList(context.Context, *VolumeListRequest) (*VolumeListResponse, error)
 	LookupEcVolume(context.Context, *LookupEcVolumeRequest) (*LookupEcVolumeResponse, error)
 	GetMasterConfiguration(context.Context, *GetMasterConfigurationRequest) (*GetMasterConfigurationResponse, error)
+	ClusterStatus(context.Context, *ClusterStatusRequest) (*ClusterStatusResponse, error)
+	ConfigureVolumeGrowth(context.Context, *ConfigureVolumeGrowthRequest) (*ConfigureVolumeGrowthResponse, error)
 }
 
 func RegisterSeaweedServer(s *grpc.Server, srv SeaweedServer) {
@@ -1682,6 +2549,24 @@ func _Seaweed_CollectionDelete_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Seaweed_CollectionCreate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CollectionCreateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SeaweedServer).CollectionCreate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/master_pb.Seaweed/CollectionCreate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SeaweedServer).CollectionCreate(ctx, req.(*CollectionCreateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Seaweed_VolumeList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(VolumeListRequest)
 	if err := dec(in); err != nil {
@@ -1736,6 +2621,42 @@ func _Seaweed_GetMasterConfiguration_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Seaweed_ClusterStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClusterStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SeaweedServer).ClusterStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/master_pb.Seaweed/ClusterStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SeaweedServer).ClusterStatus(ctx, req.(*ClusterStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Seaweed_ConfigureVolumeGrowth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfigureVolumeGrowthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SeaweedServer).ConfigureVolumeGrowth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/master_pb.Seaweed/ConfigureVolumeGrowth",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SeaweedServer).ConfigureVolumeGrowth(ctx, req.(*ConfigureVolumeGrowthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Seaweed_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "master_pb.Seaweed",
 	HandlerType: (*SeaweedServer)(nil),
@@ -1760,6 +2681,10 @@ var _Seaweed_serviceDesc = grpc.ServiceDesc{
 			MethodName: "CollectionDelete",
 			Handler:    _Seaweed_CollectionDelete_Handler,
 		},
+		{
+			MethodName: "CollectionCreate",
+			Handler:    _Seaweed_CollectionCreate_Handler,
+		},
 		{
 			MethodName: "VolumeList",
 			Handler:    _Seaweed_VolumeList_Handler,
@@ -1772,6 +2697,14 @@ var _Seaweed_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetMasterConfiguration",
 			Handler:    _Seaweed_GetMasterConfiguration_Handler,
 		},
+		{
+			MethodName: "ClusterStatus",
+			Handler:    _Seaweed_ClusterStatus_Handler,
+		},
+		{
+			MethodName: "ConfigureVolumeGrowth",
+			Handler:    _Seaweed_ConfigureVolumeGrowth_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -1793,123 +2726,197 @@ var _Seaweed_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("master.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 1888 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xd4, 0x58, 0xcd, 0x6f, 0x1c, 0x49,
-	0x15, 0x4f, 0xcf, 0x8c, 0xc7, 0x33, 0x6f, 0x3e, 0x3c, 0x53, 0x76, 0xbc, 0x93, 0x59, 0x92, 0x4c,
-	0x7a, 0x91, 0xd6, 0x09, 0x8b, 0x59, 0xb2, 0x2b, 0x81, 0x04, 0x68, 0x95, 0x38, 0xde, 0xc5, 0xca,
-	0xc7, 0x3a, 0x3d, 0x21, 0x48, 0x48, 0xa8, 0xa9, 0xe9, 0x2e, 0xdb, 0x25, 0xf7, 0x74, 0x37, 0x5d,
-	0x35, 0x8e, 0x67, 0x39, 0x70, 0x80, 0x1b, 0x12, 0x17, 0xce, 0xdc, 0xf9, 0x1b, 0x38, 0x70, 0xe1,
-	0xc8, 0x9d, 0x3b, 0xff, 0x02, 0x57, 0x84, 0xb4, 0xaa, 0xaf, 0xee, 0xea, 0x99, 0xb1, 0x1d, 0xaf,
-	0xb4, 0x87, 0xdc, 0xaa, 0xdf, 0x7b, 0xf5, 0xde, 0xab, 0xdf, 0xab, 0xf7, 0x51, 0x0d, 0xed, 0x29,
-	0x66, 0x9c, 0x64, 0xbb, 0x69, 0x96, 0xf0, 0x04, 0x35, 0xd5, 0x97, 0x9f, 0x4e, 0xdc, 0x3f, 0xd5,
-	0xa1, 0xf9, 0x73, 0x82, 0x33, 0x3e, 0x21, 0x98, 0xa3, 0x2e, 0x54, 0x68, 0x3a, 0x70, 0x46, 0xce,
-	0x4e, 0xd3, 0xab, 0xd0, 0x14, 0x21, 0xa8, 0xa5, 0x49, 0xc6, 0x07, 0x95, 0x91, 0xb3, 0xd3, 0xf1,
-	0xe4, 0x1a, 0xdd, 0x06, 0x48, 0x67, 0x93, 0x88, 0x06, 0xfe, 0x2c, 0x8b, 0x06, 0x55, 0x29, 0xdb,
-	0x54, 0x94, 0x5f, 0x64, 0x11, 0xda, 0x81, 0xde, 0x14, 0x9f, 0xfb, 0x67, 0x49, 0x34, 0x9b, 0x12,
-	0x3f, 0x48, 0x66, 0x31, 0x1f, 0xd4, 0xe4, 0xf6, 0xee, 0x14, 0x9f, 0xbf, 0x96, 0xe4, 0x3d, 0x41,
-	0x45, 0x23, 0xe1, 0xd5, 0xb9, 0x7f, 0x44, 0x23, 0xe2, 0x9f, 0x92, 0xf9, 0x60, 0x6d, 0xe4, 0xec,
-	0xd4, 0x3c, 0x98, 0xe2, 0xf3, 0xcf, 0x69, 0x44, 0x9e, 0x92, 0x39, 0xba, 0x0b, 0xad, 0x10, 0x73,
-	0xec, 0x07, 0x24, 0xe6, 0x24, 0x1b, 0xd4, 0xa5, 0x2d, 0x10, 0xa4, 0x3d, 0x49, 0x11, 0xfe, 0x65,
-	0x38, 0x38, 0x1d, 0xac, 0x4b, 0x8e, 0x5c, 0x0b, 0xff, 0x70, 0x38, 0xa5, 0xb1, 0x2f, 0x3d, 0x6f,
-	0x48, 0xd3, 0x4d, 0x49, 0x39, 0x14, 0xee, 0xff, 0x0c, 0xd6, 0x95, 0x6f, 0x6c, 0xd0, 0x1c, 0x55,
-	0x77, 0x5a, 0x0f, 0x3f, 0xd8, 0xcd, 0xd1, 0xd8, 0x55, 0xee, 0x1d, 0xc4, 0x47, 0x49, 0x36, 0xc5,
-	0x9c, 0x26, 0xf1, 0x73, 0xc2, 0x18, 0x3e, 0x26, 0x9e, 0xd9, 0x83, 0x0e, 0xa0, 0x15, 0x93, 0x37,
-	0xbe, 0x51, 0x01, 0x52, 0xc5, 0xce, 0x92, 0x8a, 0xf1, 0x49, 0x92, 0xf1, 0x15, 0x7a, 0x20, 0x26,
-	0x6f, 0x5e, 0x6b, 0x55, 0x2f, 0x61, 0x23, 0x24, 0x11, 0xe1, 0x24, 0xcc, 0xd5, 0xb5, 0xae, 0xa9,
-	0xae, 0xab, 0x15, 0x18, 0x95, 0xdf, 0x85, 0xee, 0x09, 0x66, 0x7e, 0x9c, 0xe4, 0x1a, 0xdb, 0x23,
-	0x67, 0xa7, 0xe1, 0xb5, 0x4f, 0x30, 0x7b, 0x91, 0x18, 0xa9, 0x2f, 0xa0, 0x49, 0x02, 0x9f, 0x9d,
-	0xe0, 0x2c, 0x64, 0x83, 0x9e, 0x34, 0xf9, 0x60, 0xc9, 0xe4, 0x7e, 0x30, 0x16, 0x02, 0x2b, 0x8c,
-	0x36, 0x88, 0x62, 0x31, 0xf4, 0x02, 0x3a, 0x02, 0x8c, 0x42, 0x59, 0xff, 0xda, 0xca, 0x04, 0x9a,
-	0xfb, 0x46, 0xdf, 0x6b, 0xe8, 0x1b, 0x44, 0x0a, 0x9d, 0xe8, 0xda, 0x3a, 0x0d, 0xac, 0xb9, 0xde,
-	0x0f, 0xa1, 0xa7, 0x61, 0x29, 0xd4, 0x6e, 0x4a, 0x60, 0x3a, 0x12, 0x18, 0x23, 0xe8, 0xfe, 0xdd,
-	0x81, 0x7e, 0x9e, 0x0d, 0x1e, 0x61, 0x69, 0x12, 0x33, 0x82, 0x1e, 0x40, 0x5f, 0x5f, 0x67, 0x46,
-	0xbf, 0x22, 0x7e, 0x44, 0xa7, 0x94, 0xcb, 0x24, 0xa9, 0x79, 0x1b, 0x8a, 0x31, 0xa6, 0x5f, 0x91,
-	0x67, 0x82, 0x8c, 0xb6, 0xa1, 0x1e, 0x11, 0x1c, 0x92, 0x4c, 0xe6, 0x4c, 0xd3, 0xd3, 0x5f, 0xe8,
-	0x43, 0xd8, 0x98, 0x12, 0x9e, 0xd1, 0x80, 0xf9, 0x38, 0x0c, 0x33, 0xc2, 0x98, 0x4e, 0x9d, 0xae,
-	0x26, 0x3f, 0x52, 0x54, 0xf4, 0x63, 0x18, 0x18, 0x41, 0x2a, 0xee, 0xf8, 0x19, 0x8e, 0x7c, 0x46,
-	0x82, 0x24, 0x0e, 0x99, 0xce, 0xa3, 0x6d, 0xcd, 0x3f, 0xd0, 0xec, 0xb1, 0xe2, 0xba, 0x7f, 0xad,
-	0xc2, 0xe0, 0xa2, 0x0b, 0x2c, 0x33, 0x3b, 0x94, 0x4e, 0x77, 0xbc, 0x0a, 0x0d, 0x45, 0xe6, 0x88,
-	0xc3, 0x48, 0x2f, 0x6b, 0x9e, 0x5c, 0xa3, 0x3b, 0x00, 0x41, 0x12, 0x45, 0x24, 0x10, 0x1b, 0xb5,
-	0x7b, 0x16, 0x45, 0x64, 0x96, 0x4c, 0xd6, 0x22, 0xa9, 0x6b, 0x5e, 0x53, 0x50, 0x54, 0x3e, 0xdf,
-	0x83, 0xb6, 0x02, 0x5e, 0x0b, 0xa8, 0x7c, 0x6e, 0x29, 0x9a, 0x12, 0xf9, 0x08, 0x90, 0x09, 0xf0,
-	0x64, 0x9e, 0x0b, 0xd6, 0xa5, 0x60, 0x4f, 0x73, 0x1e, 0xcf, 0x8d, 0xf4, 0xfb, 0xd0, 0xcc, 0x08,
-	0x0e, 0xfd, 0x24, 0x8e, 0xe6, 0x32, 0xc5, 0x1b, 0x5e, 0x43, 0x10, 0xbe, 0x8c, 0xa3, 0x39, 0xfa,
-	0x1e, 0xf4, 0x33, 0x92, 0x46, 0x34, 0xc0, 0x7e, 0x1a, 0xe1, 0x80, 0x4c, 0x49, 0x6c, 0xb2, 0xbd,
-	0xa7, 0x19, 0x87, 0x86, 0x8e, 0x06, 0xb0, 0x7e, 0x46, 0x32, 0x26, 0x8e, 0xd5, 0x94, 0x22, 0xe6,
-	0x13, 0xf5, 0xa0, 0xca, 0x79, 0x34, 0x00, 0x49, 0x15, 0x4b, 0x74, 0x1f, 0x7a, 0x41, 0x32, 0x4d,
-	0x71, 0xc0, 0xfd, 0x8c, 0x9c, 0x51, 0xb9, 0xa9, 0x25, 0xd9, 0x1b, 0x9a, 0xee, 0x69, 0xb2, 0x38,
-	0xce, 0x34, 0x09, 0xe9, 0x11, 0x25, 0xa1, 0x8f, 0xb9, 0x0e, 0x93, 0x4c, 0xb9, 0xaa, 0xd7, 0x33,
-	0x9c, 0x47, 0x5c, 0x05, 0xc8, 0xfd, 0x9b, 0x03, 0xb7, 0x2f, 0x4d, 0xe7, 0xa5, 0x20, 0x5d, 0x15,
-	0x90, 0x6f, 0x0b, 0x03, 0x77, 0x06, 0x77, 0xaf, 0x48, 0xb2, 0x2b, 0x7c, 0xad, 0x2c, 0xf9, 0xea,
-	0x42, 0x87, 0x04, 0x3e, 0x8d, 0x43, 0x72, 0xee, 0x4f, 0x28, 0x57, 0xd7, 0xbf, 0xe3, 0xb5, 0x48,
-	0x70, 0x20, 0x68, 0x8f, 0x29, 0x67, 0xee, 0x3a, 0xac, 0xed, 0x4f, 0x53, 0x3e, 0x77, 0xff, 0xe1,
-	0xc0, 0xc6, 0x78, 0x96, 0x92, 0xec, 0x71, 0x94, 0x04, 0xa7, 0xfb, 0xe7, 0x3c, 0xc3, 0xe8, 0x4b,
-	0xe8, 0x92, 0x0c, 0xb3, 0x59, 0x26, 0xae, 0x4d, 0x48, 0xe3, 0x63, 0x69, 0xbc, 0x5c, 0x2d, 0x17,
-	0xf6, 0xec, 0xee, 0xab, 0x0d, 0x7b, 0x52, 0xde, 0xeb, 0x10, 0xfb, 0x73, 0xf8, 0x2b, 0xe8, 0x94,
-	0xf8, 0x22, 0x27, 0x44, 0x6f, 0xd1, 0x87, 0x92, 0x6b, 0x91, 0xcf, 0x29, 0xce, 0x28, 0x9f, 0xeb,
-	0x1e, 0xa8, 0xbf, 0x44, 0x2e, 0xe8, 0x9a, 0x40, 0x43, 0x71, 0x96, 0xaa, 0xe8, 0x32, 0x8a, 0x72,
-	0x10, 0x32, 0xf7, 0x3e, 0x6c, 0xee, 0x45, 0x94, 0xc4, 0xfc, 0x19, 0x65, 0x9c, 0xc4, 0x1e, 0xf9,
-	0xed, 0x8c, 0x30, 0x2e, 0x2c, 0xc4, 0x78, 0x4a, 0x74, 0x87, 0x95, 0x6b, 0xf7, 0xf7, 0xd0, 0x55,
-	0x58, 0x3f, 0x4b, 0x02, 0x89, 0xb0, 0x88, 0x87, 0x68, 0xad, 0x4a, 0x48, 0x2c, 0x17, 0x7a, 0x6e,
-	0x65, 0xb1, 0xe7, 0xde, 0x82, 0x86, 0x6c, 0x4a, 0x85, 0x2b, 0xeb, 0xa2, 0xcf, 0xd0, 0x90, 0x15,
-	0x49, 0x19, 0x2a, 0x76, 0x4d, 0xb2, 0x5b, 0xa6, 0x6f, 0xd0, 0x90, 0xb9, 0xaf, 0x60, 0xf3, 0x59,
-	0x92, 0x9c, 0xce, 0x52, 0xe5, 0x86, 0xf1, 0xb5, 0x7c, 0x42, 0x67, 0x54, 0x15, 0x36, 0xf3, 0x13,
-	0x5e, 0x15, 0x6f, 0xf7, 0xbf, 0x0e, 0x6c, 0x95, 0xd5, 0xea, 0x6a, 0xfa, 0x1b, 0xd8, 0xcc, 0xf5,
-	0xfa, 0x91, 0x3e, 0xb3, 0x32, 0xd0, 0x7a, 0xf8, 0xb1, 0x15, 0xcc, 0x55, 0xbb, 0x4d, 0x87, 0x0e,
-	0x0d, 0x58, 0x5e, 0xff, 0x6c, 0x81, 0xc2, 0x86, 0xe7, 0xd0, 0x5b, 0x14, 0x13, 0xb5, 0x24, 0xb7,
-	0xaa, 0x91, 0x6d, 0x98, 0x9d, 0xe8, 0x87, 0xd0, 0x2c, 0x1c, 0xa9, 0x48, 0x47, 0x36, 0x4b, 0x8e,
-	0x68, 0x5b, 0x85, 0x14, 0xda, 0x82, 0x35, 0x92, 0x65, 0x49, 0xa6, 0xb3, 0x52, 0x7d, 0xb8, 0x3f,
-	0x81, 0xc6, 0x37, 0x8e, 0xa2, 0xfb, 0x2f, 0x07, 0x3a, 0x8f, 0x18, 0xa3, 0xc7, 0xf9, 0x75, 0xd9,
-	0x82, 0x35, 0x55, 0x21, 0x55, 0xb3, 0x51, 0x1f, 0x68, 0x04, 0x2d, 0x9d, 0xdc, 0x16, 0xf4, 0x36,
-	0xe9, 0xca, 0xba, 0xa1, 0x13, 0xbe, 0xa6, 0x5c, 0x13, 0x45, 0x6f, 0x61, 0xd2, 0x5a, 0xbb, 0x70,
-	0xd2, 0xaa, 0x5b, 0x93, 0xd6, 0xfb, 0xd0, 0x94, 0x9b, 0xe2, 0x24, 0x24, 0x7a, 0x04, 0x6b, 0x08,
-	0xc2, 0x8b, 0x24, 0x24, 0xee, 0x5f, 0x1c, 0xe8, 0x9a, 0xd3, 0xe8, 0xc8, 0xf7, 0xa0, 0x7a, 0x94,
-	0xa3, 0x2f, 0x96, 0x06, 0xa3, 0xca, 0x45, 0x18, 0x2d, 0x4d, 0x97, 0x39, 0x22, 0x35, 0x1b, 0x91,
-	0x3c, 0x18, 0x6b, 0x56, 0x30, 0x84, 0xcb, 0x78, 0xc6, 0x4f, 0x8c, 0xcb, 0x62, 0xed, 0x1e, 0x43,
-	0x7f, 0xcc, 0x31, 0xa7, 0x8c, 0xd3, 0x80, 0x19, 0x98, 0x17, 0x00, 0x75, 0xae, 0x02, 0xb4, 0x72,
-	0x11, 0xa0, 0xd5, 0x1c, 0x50, 0xf7, 0x9f, 0x0e, 0x20, 0xdb, 0x92, 0x86, 0xe0, 0x5b, 0x30, 0x25,
-	0x20, 0xe3, 0x09, 0x17, 0x63, 0x82, 0x68, 0xe8, 0xba, 0x2d, 0x4b, 0x8a, 0x18, 0x4b, 0x44, 0x94,
-	0x66, 0x8c, 0x84, 0x8a, 0xab, 0x7a, 0x72, 0x43, 0x10, 0x24, 0xb3, 0xdc, 0xd2, 0xeb, 0x0b, 0x2d,
-	0xdd, 0x7d, 0x04, 0xad, 0x31, 0x4f, 0x32, 0x7c, 0x4c, 0x5e, 0xcd, 0xd3, 0xb7, 0xf1, 0x5e, 0x7b,
-	0x57, 0x29, 0x80, 0x18, 0x01, 0xec, 0x15, 0xde, 0xaf, 0x2a, 0x80, 0xbf, 0x83, 0x9b, 0x85, 0x84,
-	0xa8, 0x97, 0x26, 0x2e, 0x9f, 0xc2, 0x36, 0x8d, 0x83, 0x68, 0x16, 0x12, 0x3f, 0x16, 0xed, 0x27,
-	0xca, 0xa7, 0x5a, 0x47, 0x0e, 0x03, 0x5b, 0x9a, 0xfb, 0x42, 0x32, 0xcd, 0x74, 0xfb, 0x11, 0x20,
-	0xb3, 0x8b, 0x04, 0xf9, 0x8e, 0x8a, 0xdc, 0xd1, 0xd3, 0x9c, 0xfd, 0x40, 0x4b, 0xbb, 0x2f, 0x61,
-	0x7b, 0xd1, 0xb8, 0x0e, 0xd5, 0x8f, 0xa0, 0x55, 0xc0, 0x6e, 0xea, 0xd3, 0x4d, 0xab, 0x2c, 0x14,
-	0xfb, 0x3c, 0x5b, 0xd2, 0xfd, 0x3e, 0xbc, 0x57, 0xb0, 0x9e, 0xc8, 0x42, 0x7b, 0x59, 0xfd, 0x1f,
-	0xc2, 0x60, 0x59, 0x5c, 0xf9, 0xe0, 0xfe, 0xa7, 0x02, 0xed, 0x27, 0x3a, 0xa3, 0x44, 0x0f, 0xb6,
-	0xba, 0x6e, 0x53, 0x76, 0xdd, 0x7b, 0xd0, 0x2e, 0xbd, 0xb4, 0xd4, 0x38, 0xd7, 0x3a, 0xb3, 0x9e,
-	0x59, 0xab, 0x1e, 0x64, 0x55, 0x29, 0xb6, 0xf8, 0x20, 0x7b, 0x00, 0xfd, 0xa3, 0x8c, 0x90, 0xe5,
-	0xb7, 0x5b, 0xcd, 0xdb, 0x10, 0x0c, 0x5b, 0x76, 0x17, 0x36, 0x71, 0xc0, 0xe9, 0xd9, 0x82, 0xb4,
-	0xba, 0x5f, 0x7d, 0xc5, 0xb2, 0xe5, 0x3f, 0xcf, 0x1d, 0xa5, 0xf1, 0x51, 0xc2, 0x06, 0xf5, 0xb7,
-	0x7f, 0x7b, 0xe9, 0xd3, 0x08, 0x0e, 0x43, 0x87, 0xd0, 0x35, 0x33, 0xbc, 0xd6, 0xb4, 0x7e, 0xed,
-	0xf7, 0x41, 0x9b, 0x14, 0x2c, 0xe6, 0xfe, 0xb1, 0x02, 0x0d, 0x0f, 0x07, 0xa7, 0xef, 0x36, 0xbe,
-	0x9f, 0xc1, 0x46, 0x5e, 0x8b, 0x4b, 0x10, 0xbf, 0x67, 0x01, 0x63, 0x5f, 0x25, 0xaf, 0x13, 0x5a,
-	0x5f, 0xcc, 0xfd, 0xbf, 0x03, 0xdd, 0x27, 0x79, 0xbd, 0x7f, 0xb7, 0xc1, 0x78, 0x08, 0x20, 0x1a,
-	0x54, 0x09, 0x07, 0xbb, 0xa1, 0x9b, 0x70, 0x7b, 0xcd, 0x4c, 0xaf, 0x98, 0xfb, 0xe7, 0x0a, 0xb4,
-	0x5f, 0x25, 0x69, 0x12, 0x25, 0xc7, 0xf3, 0x77, 0xfb, 0xf4, 0xfb, 0xd0, 0xb7, 0x7a, 0x79, 0x09,
-	0x84, 0x5b, 0x0b, 0x97, 0xa1, 0x08, 0xb6, 0xb7, 0x11, 0x96, 0xbe, 0x99, 0xbb, 0x09, 0x7d, 0x3d,
-	0x97, 0x16, 0x25, 0xd9, 0xfd, 0x83, 0x03, 0xc8, 0xa6, 0xea, 0x5a, 0xf9, 0x53, 0xe8, 0x70, 0x8d,
-	0x9d, 0xb4, 0xa7, 0x47, 0x73, 0xfb, 0xee, 0xd9, 0xd8, 0x7a, 0x6d, 0x6e, 0x23, 0xfd, 0x03, 0xd8,
-	0x5a, 0x7a, 0x5f, 0xfb, 0xd3, 0x89, 0x46, 0xb8, 0xbf, 0xf0, 0xc4, 0x7e, 0x3e, 0x71, 0x3f, 0x85,
-	0x9b, 0x6a, 0x38, 0x34, 0x75, 0xdc, 0xd4, 0xd7, 0xa5, 0x29, 0xaf, 0x53, 0x4c, 0x79, 0xee, 0xff,
-	0x1c, 0xd8, 0x5e, 0xdc, 0xa6, 0xfd, 0xbf, 0x6c, 0x1f, 0xc2, 0x80, 0x74, 0xbd, 0xb1, 0xe7, 0x55,
-	0x35, 0x26, 0x7e, 0xb2, 0x34, 0xaf, 0x2e, 0xea, 0xde, 0x35, 0x75, 0xa8, 0x18, 0x59, 0x7b, 0xac,
-	0x4c, 0x60, 0x43, 0x0c, 0xfd, 0x25, 0x31, 0x31, 0xd5, 0x1b, 0xbb, 0xda, 0xa7, 0x75, 0xbd, 0xf1,
-	0x1b, 0x0c, 0xac, 0xee, 0x5d, 0xb8, 0xfd, 0x05, 0xe1, 0xcf, 0xa5, 0xcc, 0x5e, 0x12, 0x1f, 0xd1,
-	0xe3, 0x59, 0xa6, 0x84, 0x8a, 0xd0, 0xde, 0xb9, 0x48, 0x42, 0xc3, 0xb4, 0xe2, 0x27, 0x86, 0x73,
-	0xed, 0x9f, 0x18, 0x95, 0xcb, 0x7e, 0x62, 0x3c, 0xfc, 0x77, 0x1d, 0xd6, 0xc7, 0x04, 0xbf, 0x21,
-	0x24, 0x44, 0x07, 0xd0, 0x19, 0x93, 0x38, 0x2c, 0x7e, 0x4f, 0x6e, 0x59, 0x67, 0xcc, 0xa9, 0xc3,
-	0xef, 0xac, 0xa2, 0xe6, 0x2d, 0xf4, 0xc6, 0x8e, 0xf3, 0xb1, 0x83, 0x0e, 0xa1, 0xf3, 0x94, 0x90,
-	0x74, 0x2f, 0x89, 0x63, 0x12, 0x70, 0x12, 0xa2, 0x3b, 0x76, 0x23, 0x5f, 0x7e, 0xa9, 0x0d, 0x6f,
-	0x2d, 0xf5, 0x13, 0x03, 0xaa, 0xd6, 0xf8, 0x12, 0xda, 0xf6, 0x03, 0xa5, 0xa4, 0x70, 0xc5, 0x73,
-	0x6a, 0x78, 0xf7, 0x8a, 0x97, 0x8d, 0x7b, 0x03, 0x7d, 0x06, 0x75, 0x35, 0x31, 0xa3, 0x81, 0x25,
-	0x5c, 0x7a, 0x12, 0x94, 0xfc, 0x2a, 0x8f, 0xd7, 0xee, 0x0d, 0xf4, 0x14, 0xa0, 0x98, 0x39, 0x91,
-	0x8d, 0xcb, 0xd2, 0xd0, 0x3b, 0xbc, 0x7d, 0x01, 0x37, 0x57, 0xf6, 0x4b, 0xe8, 0x96, 0x27, 0x23,
-	0x34, 0x5a, 0x39, 0xfc, 0x58, 0xe5, 0x61, 0x78, 0xef, 0x12, 0x89, 0x5c, 0xf1, 0xaf, 0xa1, 0xb7,
-	0x38, 0xf0, 0x20, 0x77, 0xe5, 0xc6, 0xd2, 0xf0, 0x34, 0xfc, 0xe0, 0x52, 0x19, 0x1b, 0x84, 0xa2,
-	0x42, 0x95, 0x40, 0x58, 0x2a, 0x67, 0x25, 0x10, 0x96, 0xcb, 0x9a, 0x02, 0xa1, 0x9c, 0xd6, 0x25,
-	0x10, 0x56, 0x16, 0xa1, 0x12, 0x08, 0xab, 0x6b, 0x82, 0x7b, 0x03, 0x25, 0xb0, 0xbd, 0x3a, 0xd9,
-	0x90, 0xfd, 0x3f, 0xe3, 0xd2, 0x8c, 0x1d, 0xde, 0x7f, 0x0b, 0x49, 0x63, 0x70, 0x52, 0x97, 0xbf,
-	0xfe, 0x3f, 0xf9, 0x3a, 0x00, 0x00, 0xff, 0xff, 0x28, 0x1f, 0x4c, 0x0e, 0x0a, 0x18, 0x00, 0x00,
+	// 3061 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xd4, 0x3a, 0x4b, 0x6f, 0x23, 0xc7,
+	0xd1, 0x1e, 0x52, 0x12, 0xc9, 0xa2, 0x48, 0x91, 0xad, 0xc7, 0x52, 0x5c, 0x6b, 0xa5, 0x1d, 0xbf,
+	0xb4, 0xb6, 0x3f, 0xd9, 0xdf, 0xda, 0x81, 0xed, 0xd8, 0x89, 0xb1, 0x96, 0xe4, 0xf5, 0xc2, 0xf2,
+	0x5a, 0x3b, 0x52, 0xd6, 0x40, 0x80, 0x60, 0xdc, 0x9a, 0x69, 0x51, 0x0d, 0x0d, 0x67, 0xe8, 0xe9,
+	0xa6, 0x56, 0x74, 0x8e, 0x09, 0x72, 0x4b, 0x2e, 0x01, 0x02, 0xe4, 0x9c, 0x1c, 0x72, 0xcd, 0x1f,
+	0x08, 0x02, 0x24, 0x97, 0x5c, 0xe2, 0x8b, 0x2f, 0xf9, 0x19, 0x39, 0x06, 0x41, 0x80, 0xa0, 0x5f,
+	0xf3, 0x22, 0xa9, 0x87, 0x13, 0x03, 0xd9, 0xdb, 0x74, 0x55, 0x75, 0x75, 0x75, 0x75, 0xbd, 0x49,
+	0x98, 0xef, 0x63, 0xc6, 0x49, 0xbc, 0x35, 0x88, 0x23, 0x1e, 0xa1, 0x9a, 0x5a, 0xb9, 0x83, 0x23,
+	0xfb, 0xeb, 0x2a, 0xd4, 0x3e, 0x22, 0x38, 0xe6, 0x47, 0x04, 0x73, 0xd4, 0x84, 0x12, 0x1d, 0x74,
+	0xac, 0x0d, 0x6b, 0xb3, 0xe6, 0x94, 0xe8, 0x00, 0x21, 0x98, 0x19, 0x44, 0x31, 0xef, 0x94, 0x36,
+	0xac, 0xcd, 0x86, 0x23, 0xbf, 0xd1, 0x1a, 0xc0, 0x60, 0x78, 0x14, 0x50, 0xcf, 0x1d, 0xc6, 0x41,
+	0xa7, 0x2c, 0x69, 0x6b, 0x0a, 0xf2, 0x83, 0x38, 0x40, 0x9b, 0xd0, 0xea, 0xe3, 0x73, 0xf7, 0x2c,
+	0x0a, 0x86, 0x7d, 0xe2, 0x7a, 0xd1, 0x30, 0xe4, 0x9d, 0x19, 0xb9, 0xbd, 0xd9, 0xc7, 0xe7, 0x8f,
+	0x25, 0x78, 0x5b, 0x40, 0xd1, 0x86, 0x90, 0xea, 0xdc, 0x3d, 0xa6, 0x01, 0x71, 0x4f, 0xc9, 0xa8,
+	0x33, 0xbb, 0x61, 0x6d, 0xce, 0x38, 0xd0, 0xc7, 0xe7, 0x1f, 0xd2, 0x80, 0x7c, 0x4c, 0x46, 0x68,
+	0x1d, 0xea, 0x3e, 0xe6, 0xd8, 0xf5, 0x48, 0xc8, 0x49, 0xdc, 0x99, 0x93, 0x67, 0x81, 0x00, 0x6d,
+	0x4b, 0x88, 0x90, 0x2f, 0xc6, 0xde, 0x69, 0xa7, 0x22, 0x31, 0xf2, 0x5b, 0xc8, 0x87, 0xfd, 0x3e,
+	0x0d, 0x5d, 0x29, 0x79, 0x55, 0x1e, 0x5d, 0x93, 0x90, 0x7d, 0x21, 0xfe, 0xf7, 0xa0, 0xa2, 0x64,
+	0x63, 0x9d, 0xda, 0x46, 0x79, 0xb3, 0x7e, 0xf7, 0xb9, 0xad, 0x44, 0x1b, 0x5b, 0x4a, 0xbc, 0x07,
+	0xe1, 0x71, 0x14, 0xf7, 0x31, 0xa7, 0x51, 0xf8, 0x09, 0x61, 0x0c, 0xf7, 0x88, 0x63, 0xf6, 0xa0,
+	0x07, 0x50, 0x0f, 0xc9, 0x13, 0xd7, 0xb0, 0x00, 0xc9, 0x62, 0x73, 0x8c, 0xc5, 0xc1, 0x49, 0x14,
+	0xf3, 0x09, 0x7c, 0x20, 0x24, 0x4f, 0x1e, 0x6b, 0x56, 0x8f, 0x60, 0xc1, 0x27, 0x01, 0xe1, 0xc4,
+	0x4f, 0xd8, 0xd5, 0xaf, 0xc9, 0xae, 0xa9, 0x19, 0x18, 0x96, 0xcf, 0x43, 0xf3, 0x04, 0x33, 0x37,
+	0x8c, 0x12, 0x8e, 0xf3, 0x1b, 0xd6, 0x66, 0xd5, 0x99, 0x3f, 0xc1, 0xec, 0x61, 0x64, 0xa8, 0xee,
+	0x43, 0x8d, 0x78, 0x2e, 0x3b, 0xc1, 0xb1, 0xcf, 0x3a, 0x2d, 0x79, 0xe4, 0xcb, 0x63, 0x47, 0xee,
+	0x7a, 0x07, 0x82, 0x60, 0xc2, 0xa1, 0x55, 0xa2, 0x50, 0x0c, 0x3d, 0x84, 0x86, 0x50, 0x46, 0xca,
+	0xac, 0x7d, 0x6d, 0x66, 0x42, 0x9b, 0xbb, 0x86, 0xdf, 0x63, 0x68, 0x1b, 0x8d, 0xa4, 0x3c, 0xd1,
+	0xb5, 0x79, 0x1a, 0xb5, 0x26, 0x7c, 0x5f, 0x82, 0x96, 0x56, 0x4b, 0xca, 0x76, 0x51, 0x2a, 0xa6,
+	0x21, 0x15, 0x93, 0x10, 0xae, 0x43, 0x9d, 0x32, 0xd7, 0x8f, 0x31, 0x0d, 0x69, 0xd8, 0xeb, 0x2c,
+	0x49, 0x1a, 0xa0, 0x6c, 0x47, 0x43, 0x84, 0xcd, 0x52, 0xe6, 0xc6, 0x04, 0xfb, 0x6e, 0x14, 0x06,
+	0xa3, 0xce, 0xb2, 0xa1, 0x70, 0x08, 0xf6, 0x3f, 0x0d, 0x83, 0x11, 0x5a, 0x85, 0xaa, 0x60, 0x41,
+	0x02, 0x8e, 0x3b, 0x2b, 0x12, 0x5b, 0xa1, 0x6c, 0x47, 0x2c, 0xd1, 0x1e, 0x2c, 0x0c, 0x07, 0x3e,
+	0xce, 0x3e, 0xf8, 0x8d, 0xab, 0x9b, 0x60, 0x53, 0xef, 0x35, 0xaf, 0xf8, 0x36, 0xcc, 0x05, 0xf8,
+	0x88, 0x04, 0xac, 0xd3, 0x91, 0x4c, 0x36, 0x32, 0x4c, 0x12, 0x8f, 0xde, 0xda, 0x93, 0x24, 0xbb,
+	0x21, 0x8f, 0x47, 0x8e, 0xa6, 0x47, 0x2f, 0xc2, 0x82, 0x74, 0x3a, 0x46, 0xbf, 0x24, 0x6e, 0x40,
+	0xfb, 0x94, 0x77, 0x56, 0xa5, 0xef, 0x35, 0x04, 0xf8, 0x80, 0x7e, 0x49, 0xf6, 0x04, 0xb0, 0xfb,
+	0x0e, 0xd4, 0x33, 0xdb, 0x51, 0x0b, 0xca, 0xc2, 0x4d, 0x55, 0x74, 0x10, 0x9f, 0x68, 0x09, 0x66,
+	0xcf, 0x70, 0x30, 0x24, 0x32, 0x3e, 0xd4, 0x1c, 0xb5, 0xf8, 0x6e, 0xe9, 0x6d, 0xcb, 0xfe, 0x5b,
+	0x09, 0xda, 0x89, 0x10, 0x0e, 0x61, 0x83, 0x28, 0x64, 0x04, 0xbd, 0x0c, 0x6d, 0x1d, 0x17, 0x32,
+	0x47, 0x5b, 0xf2, 0xe8, 0x05, 0x85, 0x48, 0x0e, 0x47, 0x2b, 0x30, 0x17, 0x10, 0xec, 0x93, 0x58,
+	0x33, 0xd7, 0x2b, 0xf4, 0x12, 0x2c, 0xf4, 0x09, 0x8f, 0xa9, 0xc7, 0x5c, 0xec, 0xfb, 0x31, 0x61,
+	0x4c, 0xc7, 0xa0, 0xa6, 0x06, 0xdf, 0x53, 0x50, 0xf4, 0x36, 0x74, 0x0c, 0x21, 0x15, 0xc1, 0xe2,
+	0x0c, 0x07, 0x2e, 0x23, 0x5e, 0x14, 0xfa, 0x4c, 0x07, 0xa4, 0x15, 0x8d, 0x7f, 0xa0, 0xd1, 0x07,
+	0x0a, 0x8b, 0xde, 0x83, 0xee, 0x89, 0x91, 0x7d, 0x7c, 0xef, 0xac, 0xdc, 0xdb, 0x49, 0x28, 0x8a,
+	0xbb, 0x5f, 0x84, 0x05, 0xef, 0x04, 0x87, 0x3d, 0x65, 0xc4, 0x67, 0xd4, 0x67, 0x9d, 0xb9, 0x8d,
+	0xf2, 0x66, 0xc3, 0x69, 0x68, 0xf0, 0xae, 0xf7, 0x98, 0xfa, 0x0c, 0xbd, 0x05, 0x1d, 0x1c, 0x04,
+	0x82, 0x26, 0x88, 0x3c, 0xf9, 0xd2, 0xcc, 0xd5, 0x14, 0x32, 0x9e, 0x55, 0x9d, 0x65, 0x1c, 0x04,
+	0xbb, 0xde, 0x9e, 0xc1, 0x6e, 0x2b, 0xa4, 0xfd, 0xc7, 0x32, 0x74, 0xa6, 0x59, 0x89, 0x8c, 0xe0,
+	0xbe, 0xd4, 0x69, 0xc3, 0x29, 0x51, 0x5f, 0x44, 0x48, 0xa1, 0x6b, 0xa9, 0xc4, 0x19, 0x47, 0x7e,
+	0xa3, 0x5b, 0x00, 0x5e, 0x14, 0x04, 0xc4, 0x13, 0x1b, 0xb5, 0xf6, 0x32, 0x10, 0x11, 0x41, 0xa5,
+	0x7d, 0xa4, 0xc1, 0x7b, 0xc6, 0xa9, 0x09, 0x88, 0x8a, 0xdb, 0xb7, 0x61, 0x5e, 0x39, 0x98, 0x26,
+	0x50, 0x71, 0xbb, 0xae, 0x60, 0x8a, 0xe4, 0x55, 0x40, 0xc6, 0x91, 0x8f, 0x46, 0x09, 0xe1, 0x9c,
+	0x24, 0x6c, 0x69, 0xcc, 0x07, 0x23, 0x43, 0x7d, 0x13, 0x6a, 0xa9, 0x47, 0xa9, 0xab, 0x57, 0x63,
+	0xe3, 0x4f, 0xaf, 0x40, 0x3b, 0x26, 0x83, 0x80, 0x7a, 0xd8, 0x1d, 0x04, 0xd8, 0x23, 0x7d, 0x12,
+	0x9a, 0xa8, 0xde, 0xd2, 0x88, 0x7d, 0x03, 0x47, 0x1d, 0xa8, 0x9c, 0x91, 0x98, 0x89, 0x6b, 0xd5,
+	0x24, 0x89, 0x59, 0x0a, 0xe3, 0xe5, 0x3c, 0xe8, 0x80, 0x84, 0x8a, 0x4f, 0x74, 0x07, 0x5a, 0x5e,
+	0xd4, 0x1f, 0x60, 0x8f, 0xbb, 0x31, 0x39, 0xa3, 0x72, 0x53, 0x5d, 0xa2, 0x17, 0x34, 0xdc, 0xd1,
+	0x60, 0x71, 0x9d, 0x7e, 0xe4, 0xd3, 0x63, 0x4a, 0x7c, 0x17, 0x73, 0x6d, 0x09, 0x32, 0xb4, 0x96,
+	0x9d, 0x96, 0xc1, 0xdc, 0xe3, 0xca, 0x02, 0x84, 0x7e, 0x8e, 0xd9, 0x28, 0xf4, 0xdc, 0x41, 0x14,
+	0x50, 0x6f, 0xd4, 0x69, 0x48, 0x05, 0xd7, 0x25, 0x6c, 0x5f, 0x82, 0xec, 0xdf, 0x59, 0xb0, 0x76,
+	0x61, 0x64, 0x1f, 0x7b, 0xc7, 0xcb, 0xde, 0xec, 0xdb, 0x52, 0x93, 0xfd, 0x57, 0x0b, 0xd6, 0x2f,
+	0x09, 0xb8, 0x97, 0x08, 0x5b, 0x1a, 0x13, 0xd6, 0x86, 0x06, 0xf1, 0x5c, 0x1a, 0xfa, 0xe4, 0xdc,
+	0x3d, 0xa2, 0x5c, 0x79, 0x70, 0xc3, 0xa9, 0x13, 0xef, 0x81, 0x80, 0x7d, 0x40, 0x39, 0x1b, 0xb3,
+	0xb2, 0x99, 0x71, 0x2b, 0x7b, 0x13, 0x56, 0x62, 0xe2, 0x05, 0x98, 0xf6, 0xf1, 0x51, 0x40, 0xb2,
+	0x96, 0xa6, 0x4c, 0x72, 0x29, 0x83, 0x4d, 0xac, 0xcd, 0xae, 0xc0, 0xec, 0x6e, 0x7f, 0xc0, 0x47,
+	0xf6, 0x1f, 0x2c, 0x58, 0x38, 0x18, 0x0e, 0x48, 0xfc, 0x41, 0x10, 0x79, 0xa7, 0xbb, 0xe7, 0x3c,
+	0xc6, 0xe8, 0x53, 0x68, 0x92, 0x18, 0xb3, 0x61, 0x2c, 0x38, 0xf9, 0x22, 0x07, 0x88, 0x5b, 0xe5,
+	0x53, 0x72, 0x61, 0xcf, 0xd6, 0xae, 0xda, 0xb0, 0x2d, 0xe9, 0x9d, 0x06, 0xc9, 0x2e, 0xbb, 0x3f,
+	0x84, 0x46, 0x0e, 0x2f, 0x1c, 0x52, 0x14, 0x30, 0x5a, 0x5b, 0xf2, 0x5b, 0xc4, 0xba, 0x01, 0x8e,
+	0x29, 0x1f, 0xe9, 0x42, 0x4b, 0xaf, 0x84, 0x23, 0xea, 0x78, 0x29, 0xa2, 0x48, 0x59, 0x46, 0x91,
+	0x9a, 0x82, 0x3c, 0xf0, 0x99, 0x7d, 0x07, 0x16, 0xb7, 0x03, 0x4a, 0x42, 0xbe, 0x47, 0x19, 0x27,
+	0xa1, 0x43, 0xbe, 0x18, 0x12, 0xc6, 0xc5, 0x09, 0x21, 0xee, 0x13, 0x1d, 0xa8, 0xe5, 0xb7, 0xfd,
+	0x55, 0x09, 0x9a, 0xea, 0x15, 0x4d, 0x38, 0x11, 0x4f, 0x2d, 0x0a, 0x38, 0x1d, 0xce, 0x87, 0x71,
+	0x50, 0xa8, 0xec, 0x4a, 0xc5, 0xca, 0x6e, 0x15, 0xaa, 0xb2, 0xf4, 0x49, 0x65, 0xa9, 0x88, 0x6a,
+	0x86, 0xfa, 0x99, 0xc7, 0xf2, 0x15, 0x7a, 0x46, 0xa2, 0xf5, 0x63, 0xf9, 0x92, 0xe4, 0x96, 0x2a,
+	0x9c, 0x4c, 0x48, 0x9c, 0x55, 0x97, 0x91, 0xd9, 0x5f, 0xe2, 0x5f, 0x4c, 0xab, 0xa1, 0x42, 0xd8,
+	0x4c, 0xb2, 0xb9, 0xa4, 0x2b, 0xd4, 0x84, 0x95, 0xa9, 0x35, 0x61, 0x35, 0x53, 0x13, 0xde, 0x81,
+	0x36, 0x65, 0xee, 0xf1, 0x30, 0x08, 0x5c, 0xe9, 0x99, 0x7e, 0x14, 0x12, 0x69, 0xfa, 0x55, 0xa7,
+	0x49, 0xd9, 0x87, 0xc3, 0x20, 0x38, 0x18, 0x85, 0xde, 0x4e, 0x14, 0x92, 0x49, 0xc9, 0x11, 0x26,
+	0x24, 0x47, 0xfb, 0x10, 0x16, 0xf7, 0xa2, 0xe8, 0x74, 0x38, 0x50, 0x6a, 0x35, 0xca, 0xcf, 0x3f,
+	0x99, 0xb5, 0x51, 0x16, 0x3a, 0x4c, 0x9e, 0xec, 0x32, 0xcf, 0xb0, 0xff, 0x52, 0x82, 0xa5, 0x3c,
+	0x5b, 0x9d, 0x3a, 0x3f, 0x87, 0xc5, 0x84, 0x6f, 0x9a, 0x30, 0xe4, 0x01, 0xf5, 0xbb, 0xaf, 0x67,
+	0xac, 0x73, 0xd2, 0x6e, 0x53, 0x54, 0xf8, 0xe6, 0xf1, 0x9d, 0xf6, 0x59, 0x01, 0xc2, 0x44, 0x3c,
+	0xe4, 0xd1, 0x20, 0x0a, 0xa2, 0xde, 0xc8, 0x35, 0xd1, 0x41, 0x65, 0x8d, 0x05, 0x03, 0x7f, 0xac,
+	0xc0, 0x22, 0x8f, 0x7b, 0xd8, 0x3b, 0x21, 0x2e, 0xe7, 0x69, 0x5e, 0x2c, 0xeb, 0xd8, 0x29, 0x10,
+	0x87, 0xdc, 0xa4, 0xc3, 0xee, 0x39, 0xb4, 0x8a, 0xa7, 0x8b, 0x80, 0x9f, 0x5c, 0x46, 0x1b, 0x60,
+	0xd5, 0x08, 0x84, 0xfe, 0x1f, 0x6a, 0xe9, 0xfd, 0x4a, 0xf2, 0x7e, 0x8b, 0xb9, 0xfb, 0xe9, 0x2b,
+	0xa4, 0x54, 0xa2, 0x0e, 0x21, 0x71, 0x1c, 0xc5, 0x3a, 0x2e, 0xaa, 0x85, 0x3d, 0x80, 0xea, 0x37,
+	0x37, 0xf6, 0x82, 0x99, 0x95, 0xa7, 0x9a, 0xd9, 0x4c, 0x6a, 0x66, 0xf6, 0xdf, 0x4b, 0xd0, 0xb8,
+	0xc7, 0x18, 0xed, 0x25, 0xbe, 0xb8, 0x04, 0xb3, 0x2a, 0x22, 0xa9, 0x2a, 0x47, 0x2d, 0xd0, 0x06,
+	0xd4, 0x75, 0x4c, 0xce, 0x98, 0x41, 0x16, 0x74, 0x69, 0xb8, 0xd7, 0x71, 0x5a, 0x1d, 0x2e, 0x3e,
+	0x8b, 0x02, 0xcf, 0x4e, 0x15, 0x78, 0x2e, 0xe3, 0x17, 0x37, 0xa1, 0x26, 0x37, 0x85, 0x91, 0x4f,
+	0xb4, 0x2b, 0x55, 0x05, 0xe0, 0x61, 0xe4, 0x13, 0xf4, 0x02, 0x34, 0x85, 0x8a, 0x03, 0xca, 0x47,
+	0x6e, 0x2f, 0x8e, 0x86, 0x03, 0xed, 0x52, 0x0d, 0x03, 0xbd, 0x2f, 0x80, 0xe2, 0x8a, 0x32, 0xb5,
+	0x69, 0x7f, 0x52, 0x0b, 0x21, 0xa0, 0x38, 0x0c, 0x94, 0x80, 0xe2, 0x2c, 0xc1, 0x4e, 0x54, 0x93,
+	0x2e, 0x23, 0xe2, 0x16, 0x51, 0xdc, 0xa9, 0x6b, 0x76, 0x02, 0x7a, 0xa0, 0x81, 0x22, 0x37, 0x31,
+	0xc2, 0xa4, 0xf5, 0xcd, 0x4b, 0xbc, 0x59, 0x8a, 0x83, 0x8e, 0x30, 0xf7, 0x4e, 0x64, 0x42, 0xad,
+	0x3a, 0x6a, 0x61, 0x7f, 0x65, 0x41, 0xd3, 0xe8, 0x5c, 0xfb, 0x4a, 0x0b, 0xca, 0xc7, 0x89, 0x61,
+	0x89, 0x4f, 0xf3, 0xfc, 0xa5, 0x69, 0xcf, 0x3f, 0xd6, 0xc5, 0x26, 0xef, 0x36, 0x93, 0x7d, 0xb7,
+	0xc4, 0xce, 0x66, 0x33, 0x76, 0x26, 0x14, 0x8b, 0x87, 0xfc, 0xc4, 0x28, 0x56, 0x7c, 0xa7, 0x4a,
+	0xa9, 0x4c, 0x50, 0x4a, 0x35, 0x55, 0x0a, 0x82, 0x99, 0x63, 0xea, 0xab, 0x56, 0xb4, 0xe6, 0xc8,
+	0x6f, 0xbb, 0x07, 0xed, 0x03, 0x8e, 0x39, 0x65, 0x9c, 0x7a, 0xcc, 0x18, 0x52, 0xc1, 0x64, 0xac,
+	0xcb, 0x4c, 0xa6, 0x34, 0xcd, 0x64, 0xca, 0x89, 0xc9, 0xd8, 0x7f, 0xb2, 0x00, 0x65, 0x4f, 0xd2,
+	0xea, 0xfb, 0x16, 0x8e, 0x12, 0xea, 0xe6, 0x11, 0x17, 0x55, 0xb4, 0x28, 0x46, 0x75, 0x49, 0x29,
+	0x21, 0x22, 0xa2, 0x0a, 0x3b, 0x1c, 0x32, 0xe2, 0x2b, 0xac, 0x4a, 0xde, 0x55, 0x01, 0x90, 0xc8,
+	0x7c, 0x39, 0x3a, 0x57, 0x28, 0x47, 0xed, 0x7b, 0x50, 0x3f, 0xe0, 0x51, 0x8c, 0x7b, 0xe4, 0x70,
+	0x34, 0xb8, 0x8a, 0xf4, 0x5a, 0xba, 0x52, 0xaa, 0x88, 0xdf, 0x5a, 0x00, 0xdb, 0xa9, 0xf8, 0x13,
+	0x12, 0xe8, 0x15, 0x5c, 0x76, 0xfc, 0xd2, 0xaf, 0xc1, 0xd2, 0x58, 0xbb, 0xe3, 0xf6, 0x8f, 0xf4,
+	0xf5, 0xdb, 0x85, 0x8e, 0xe7, 0x93, 0x23, 0x71, 0xd3, 0x90, 0x10, 0x3f, 0x90, 0x81, 0x55, 0xea,
+	0xa1, 0xea, 0xd4, 0x14, 0xe4, 0x90, 0x07, 0xf6, 0x8f, 0x61, 0x39, 0x95, 0x52, 0xe4, 0x7c, 0x63,
+	0x1c, 0x6f, 0xc2, 0x0a, 0x0d, 0xbd, 0x60, 0xe8, 0x13, 0x37, 0x14, 0xb5, 0x59, 0x90, 0xf4, 0x97,
+	0x96, 0xe4, 0xb1, 0xa4, 0xb1, 0x0f, 0x25, 0xd2, 0x34, 0x90, 0xaf, 0x02, 0x32, 0xbb, 0x44, 0xc6,
+	0xd5, 0x3b, 0x4a, 0x72, 0x47, 0x4b, 0x63, 0x76, 0x3d, 0x4d, 0x6d, 0x3f, 0x82, 0x95, 0xe2, 0xe1,
+	0xda, 0x5e, 0xde, 0x82, 0x7a, 0xfa, 0xf6, 0x26, 0x25, 0x2d, 0x67, 0x42, 0x76, 0xba, 0xcf, 0xc9,
+	0x52, 0xda, 0xff, 0x07, 0x37, 0x52, 0xd4, 0x8e, 0xcc, 0xf2, 0x17, 0xd5, 0x30, 0x5d, 0xe8, 0x8c,
+	0x93, 0x2b, 0x19, 0xec, 0xdf, 0x5b, 0x59, 0x5e, 0xdb, 0x31, 0xc1, 0x17, 0xf2, 0xfa, 0x9f, 0x78,
+	0xce, 0xdc, 0x7d, 0x8c, 0xc8, 0xfa, 0x3e, 0xbf, 0x9a, 0x85, 0xf9, 0x1d, 0x1d, 0x88, 0x45, 0xc1,
+	0x9d, 0x29, 0xb1, 0x6b, 0xb2, 0xc4, 0xbe, 0x0d, 0xf3, 0xb9, 0x11, 0x9b, 0xca, 0xd4, 0xf5, 0xb3,
+	0xcc, 0x7c, 0x6d, 0xd2, 0x24, 0xae, 0x2c, 0xc9, 0x8a, 0x93, 0xb8, 0x97, 0xa1, 0x7d, 0x1c, 0x13,
+	0x32, 0x3e, 0xb4, 0x9b, 0x71, 0x16, 0x04, 0x22, 0x4b, 0xbb, 0x05, 0x8b, 0xd8, 0xe3, 0xf4, 0xac,
+	0x40, 0xad, 0x9c, 0xb6, 0xad, 0x50, 0x59, 0xfa, 0x0f, 0x13, 0x41, 0x69, 0x78, 0x1c, 0xa9, 0xa2,
+	0xee, 0x8a, 0x13, 0x8f, 0xfa, 0x59, 0x82, 0x61, 0x68, 0x1f, 0x9a, 0x66, 0x78, 0xa3, 0x39, 0x55,
+	0xae, 0x3d, 0x18, 0x9a, 0x27, 0x29, 0x6a, 0x6c, 0xd8, 0x53, 0xbd, 0x74, 0xd8, 0x53, 0x1b, 0x1b,
+	0xf6, 0xbc, 0x00, 0x4d, 0xca, 0xdc, 0x2f, 0x86, 0x38, 0xc6, 0x21, 0xa7, 0x21, 0xf1, 0x65, 0xc2,
+	0xab, 0x3a, 0x0d, 0xca, 0x1e, 0xa5, 0x40, 0x61, 0x39, 0xbd, 0x38, 0x7a, 0xc2, 0x4f, 0x64, 0x8f,
+	0xc2, 0xdc, 0x01, 0x89, 0x5d, 0x1f, 0x8f, 0x64, 0x02, 0xb4, 0x9c, 0xb6, 0xc2, 0x89, 0x0e, 0x85,
+	0xed, 0x93, 0x78, 0x07, 0x8f, 0xc4, 0xc9, 0x3e, 0x1e, 0x31, 0x97, 0x47, 0xb2, 0x68, 0x95, 0x99,
+	0xd0, 0x12, 0xd9, 0x7c, 0xc4, 0x0e, 0x23, 0x51, 0xae, 0xa2, 0x77, 0x93, 0xe9, 0x4f, 0x63, 0x4c,
+	0xa1, 0x59, 0xc3, 0x99, 0x34, 0x00, 0xfa, 0x4f, 0x06, 0x3b, 0x3f, 0x2d, 0x41, 0xd5, 0xc1, 0xde,
+	0xe9, 0xd3, 0x6d, 0x94, 0xef, 0xc3, 0x42, 0x52, 0xf7, 0xe4, 0xec, 0xf2, 0xc6, 0x14, 0x35, 0x3a,
+	0x0d, 0x3f, 0xb3, 0x62, 0xf6, 0xbf, 0x2c, 0x68, 0xee, 0x24, 0xb5, 0xd5, 0xd3, 0xad, 0x8c, 0xbb,
+	0x00, 0xa2, 0x18, 0xcc, 0xe9, 0x21, 0x5b, 0x71, 0x9b, 0xe7, 0x76, 0x6a, 0xb1, 0xfe, 0x62, 0xf6,
+	0x2f, 0x4a, 0x30, 0x7f, 0xa8, 0xbb, 0x82, 0xa7, 0xfb, 0xf6, 0xbb, 0xd0, 0xce, 0xd4, 0xcd, 0x39,
+	0x25, 0xac, 0x16, 0x8c, 0x21, 0x7d, 0x6c, 0x67, 0xc1, 0xcf, 0xad, 0x99, 0xbd, 0x08, 0x6d, 0xdd,
+	0x5f, 0xa7, 0x79, 0xd9, 0xfe, 0x89, 0x05, 0x28, 0x0b, 0xd5, 0x09, 0xf3, 0x3d, 0x68, 0x24, 0x9d,
+	0x96, 0x38, 0x4f, 0xcf, 0x18, 0xb2, 0xb6, 0x97, 0xd5, 0xad, 0x33, 0xcf, 0x33, 0xab, 0xa9, 0x69,
+	0xa8, 0x34, 0x25, 0x0d, 0xd9, 0x6f, 0xc2, 0xb2, 0x6a, 0x0a, 0x4d, 0x32, 0x37, 0x89, 0x71, 0xac,
+	0x0d, 0x6b, 0xa4, 0x6d, 0x98, 0xfd, 0x4f, 0x0b, 0x56, 0x8a, 0xdb, 0xb4, 0xfc, 0x17, 0xed, 0x43,
+	0x18, 0x90, 0x0e, 0xd2, 0xbe, 0x5b, 0xec, 0xe3, 0xde, 0x18, 0xeb, 0x53, 0x8b, 0xbc, 0xb7, 0x4c,
+	0xf0, 0x4e, 0x5b, 0xd5, 0x16, 0xcb, 0x03, 0x58, 0x17, 0x43, 0x7b, 0x8c, 0x4c, 0x4c, 0x27, 0xcc,
+	0xb9, 0x5a, 0xa6, 0x8a, 0xde, 0xf8, 0x0d, 0x3a, 0x4a, 0x7b, 0x1d, 0xd6, 0xee, 0x13, 0xfe, 0x89,
+	0xa4, 0xd9, 0x8e, 0xc2, 0x63, 0xda, 0x1b, 0xc6, 0x8a, 0x28, 0x7d, 0xda, 0x5b, 0xd3, 0x28, 0xb4,
+	0x9a, 0x26, 0x4c, 0xaa, 0xad, 0x6b, 0x4f, 0xaa, 0x4b, 0x17, 0x4d, 0xaa, 0xed, 0x15, 0x58, 0xda,
+	0x0e, 0x86, 0x42, 0x04, 0x51, 0xc7, 0x0f, 0x4d, 0xb7, 0x60, 0xff, 0xbc, 0x0c, 0xcb, 0x05, 0x44,
+	0xfa, 0x76, 0x94, 0xb9, 0x7a, 0xb2, 0xae, 0xaa, 0xc3, 0x2a, 0x65, 0x7b, 0x72, 0x3d, 0x75, 0xe6,
+	0xfe, 0x1a, 0xcc, 0x0e, 0x08, 0x89, 0xd5, 0xd8, 0x27, 0xef, 0x17, 0x0e, 0x3e, 0xe6, 0xfb, 0x24,
+	0x39, 0x46, 0xd1, 0x89, 0xca, 0x27, 0xc6, 0xc7, 0xdc, 0x65, 0x1c, 0x73, 0xa2, 0xbb, 0xd4, 0x9a,
+	0x80, 0x08, 0x32, 0x29, 0x84, 0x44, 0x73, 0x12, 0xf7, 0x4d, 0xb9, 0x2f, 0x00, 0x87, 0x24, 0xee,
+	0x0b, 0x67, 0x97, 0x48, 0x2f, 0xea, 0x0b, 0xcb, 0x96, 0x53, 0x42, 0x5d, 0xf5, 0x2f, 0x08, 0xc4,
+	0xb6, 0x84, 0xcb, 0x41, 0x21, 0xfa, 0x0e, 0x54, 0x3d, 0x3c, 0xc0, 0x9e, 0x18, 0x9d, 0x55, 0x36,
+	0xac, 0x82, 0x6c, 0xdb, 0x1a, 0xa5, 0x65, 0x4b, 0x48, 0xd1, 0xf7, 0x61, 0x3e, 0xe3, 0xf3, 0xac,
+	0x53, 0x95, 0xd7, 0xba, 0x39, 0xd1, 0xdd, 0xf5, 0xe6, 0x7a, 0xea, 0xf0, 0x6c, 0xaa, 0x0b, 0xd6,
+	0xa6, 0xb9, 0xa0, 0x0b, 0xcd, 0xbc, 0xa2, 0x26, 0x16, 0xa5, 0xef, 0xc0, 0x6a, 0x80, 0x19, 0x77,
+	0x65, 0x90, 0x12, 0x5d, 0xb7, 0x36, 0x02, 0x17, 0xf7, 0x22, 0xf9, 0x22, 0x65, 0x67, 0x45, 0x10,
+	0xdc, 0xd3, 0x78, 0x6d, 0x05, 0xf7, 0x7a, 0x91, 0xfd, 0x75, 0x09, 0x9a, 0xf9, 0xeb, 0x4e, 0x0c,
+	0xaf, 0xd6, 0xc4, 0xf0, 0x7a, 0x85, 0x58, 0x3d, 0x25, 0xaa, 0x96, 0xa7, 0x45, 0xd5, 0xeb, 0x44,
+	0xec, 0xe7, 0x33, 0x95, 0x5d, 0x36, 0x58, 0x9b, 0x6a, 0x2d, 0x91, 0xc0, 0xe8, 0x9c, 0xc4, 0x67,
+	0x24, 0xce, 0xb5, 0x83, 0x46, 0xe5, 0x12, 0xa3, 0xe8, 0xb7, 0xe1, 0xd6, 0x30, 0x7c, 0x12, 0x53,
+	0x2e, 0x67, 0xc3, 0x93, 0xb6, 0x56, 0xe4, 0xd6, 0x9b, 0x29, 0xd5, 0xe3, 0x22, 0x13, 0xfb, 0x67,
+	0x16, 0xb4, 0x8a, 0xa6, 0x30, 0x96, 0xea, 0xb2, 0x46, 0x58, 0xba, 0xba, 0x11, 0xbe, 0x02, 0xb3,
+	0x22, 0x9f, 0x1a, 0xa7, 0x5a, 0x2e, 0x64, 0x5c, 0xe3, 0x50, 0x92, 0xc6, 0xfe, 0xb5, 0x05, 0x90,
+	0x42, 0xff, 0x5b, 0x22, 0xec, 0x40, 0x33, 0xa7, 0x18, 0x23, 0xcb, 0xda, 0xf8, 0x0f, 0xd0, 0x12,
+	0xaf, 0x19, 0x34, 0xb2, 0xda, 0x66, 0xf6, 0x6f, 0x4a, 0x26, 0xcb, 0x65, 0xa9, 0xae, 0x3f, 0x72,
+	0xcb, 0x5e, 0xa2, 0x7c, 0xf5, 0x4b, 0xbc, 0x0b, 0x5d, 0xe9, 0x35, 0xe9, 0x4f, 0x76, 0x59, 0xb7,
+	0x99, 0x91, 0x6e, 0x73, 0x43, 0x50, 0x24, 0xbf, 0x47, 0xa6, 0x7e, 0x53, 0xec, 0x01, 0x66, 0x2f,
+	0xed, 0x01, 0xe6, 0xae, 0xd0, 0x03, 0x54, 0x26, 0xf4, 0x00, 0xf6, 0x9f, 0x2d, 0x58, 0x52, 0x5a,
+	0xba, 0x2f, 0xcb, 0xfd, 0x03, 0x1e, 0x63, 0x4e, 0x7a, 0xa3, 0xc2, 0x30, 0xc5, 0x1a, 0x1b, 0xa6,
+	0x20, 0x98, 0x39, 0xa5, 0xa1, 0xaf, 0xf5, 0x25, 0xbf, 0x45, 0x6a, 0x49, 0x4c, 0x9b, 0xe3, 0xb8,
+	0x47, 0xb8, 0x1e, 0xbf, 0x36, 0x0d, 0xf8, 0x50, 0x42, 0xd1, 0xeb, 0xb0, 0x34, 0x20, 0xf8, 0xd4,
+	0x2d, 0x52, 0xab, 0x1f, 0x40, 0x91, 0xc0, 0x7d, 0x96, 0xdf, 0x21, 0x1e, 0x49, 0xec, 0x38, 0x89,
+	0x86, 0x31, 0xd3, 0x83, 0xae, 0x9a, 0x80, 0x7c, 0x24, 0x00, 0xf6, 0x2f, 0x2d, 0x78, 0xd6, 0xa4,
+	0x3b, 0x92, 0xbd, 0x8f, 0x29, 0x2a, 0xde, 0x85, 0x2a, 0xd3, 0x57, 0xd3, 0x75, 0xcd, 0xfa, 0x98,
+	0x35, 0xe5, 0x35, 0xe0, 0x24, 0x1b, 0x44, 0x02, 0x8a, 0x49, 0x3f, 0x3a, 0x23, 0x7a, 0x0c, 0xa1,
+	0x57, 0x97, 0x8d, 0x43, 0xed, 0xcf, 0x61, 0x6d, 0x8a, 0x50, 0x3a, 0xed, 0xbd, 0x0f, 0xa0, 0x0f,
+	0xa1, 0xc4, 0x8c, 0x28, 0x2e, 0x95, 0x2b, 0xb3, 0xe5, 0xee, 0x3f, 0xaa, 0x50, 0x39, 0x20, 0xf8,
+	0x09, 0x21, 0x3e, 0x7a, 0x00, 0x8d, 0x03, 0x12, 0xfa, 0xe9, 0xdf, 0x66, 0x96, 0x26, 0xfd, 0xf4,
+	0xde, 0x7d, 0x76, 0x12, 0x34, 0xe9, 0xf0, 0x9f, 0xd9, 0xb4, 0x5e, 0xb7, 0xd0, 0x3e, 0x34, 0x3e,
+	0x26, 0x64, 0xb0, 0x1d, 0x85, 0x21, 0xf1, 0x38, 0xf1, 0xd1, 0xad, 0xac, 0xc9, 0x8f, 0xff, 0xb8,
+	0xd3, 0x5d, 0x1d, 0x13, 0xda, 0x94, 0x2f, 0x9a, 0xe3, 0x23, 0x98, 0xcf, 0xfe, 0x04, 0x90, 0x63,
+	0x38, 0xe1, 0x07, 0x8b, 0xee, 0xfa, 0x25, 0xbf, 0x1d, 0xd8, 0xcf, 0xa0, 0xf7, 0x61, 0x4e, 0x4d,
+	0x58, 0x51, 0x27, 0x43, 0x9c, 0x1b, 0x74, 0x77, 0x57, 0x27, 0x60, 0x12, 0x06, 0x1f, 0x03, 0xa4,
+	0x73, 0x46, 0x94, 0xd5, 0xcb, 0xd8, 0xa0, 0xb3, 0xbb, 0x36, 0x05, 0x9b, 0x30, 0xfb, 0x0c, 0x9a,
+	0xf9, 0x41, 0x14, 0xda, 0x98, 0x38, 0x6b, 0xca, 0x14, 0xe2, 0xdd, 0xdb, 0x17, 0x50, 0x24, 0x8c,
+	0x7f, 0x04, 0xad, 0xe2, 0x7c, 0x09, 0xd9, 0x13, 0x37, 0xe6, 0x66, 0x55, 0xdd, 0xe7, 0x2e, 0xa4,
+	0x99, 0xcc, 0x5e, 0x8d, 0x7b, 0xa6, 0xb0, 0xcf, 0x8d, 0xaf, 0xba, 0xcf, 0x5d, 0x48, 0x93, 0xd5,
+	0x71, 0xda, 0x6a, 0xe4, 0x74, 0x3c, 0xd6, 0x97, 0x74, 0xd7, 0xa6, 0x60, 0xb3, 0x3a, 0xce, 0xd7,
+	0xe7, 0x39, 0x1d, 0x4f, 0xec, 0x26, 0xba, 0xb7, 0x2f, 0xa0, 0x48, 0x18, 0x47, 0xb0, 0x32, 0xb9,
+	0x6a, 0x46, 0xd9, 0x5f, 0x58, 0x2f, 0x2c, 0xbd, 0xbb, 0x77, 0xae, 0x40, 0x99, 0x1c, 0x78, 0x08,
+	0x8d, 0x5c, 0x21, 0x8c, 0xd6, 0x73, 0x0e, 0x36, 0x5e, 0x3b, 0x77, 0x37, 0xa6, 0x13, 0x24, 0x5c,
+	0x03, 0x58, 0x36, 0x07, 0xe6, 0xe2, 0x0d, 0x7a, 0x29, 0xf7, 0x58, 0xd3, 0xc3, 0x64, 0x77, 0xf3,
+	0x72, 0x42, 0x73, 0xda, 0xd1, 0x9c, 0xfc, 0xd7, 0xde, 0x1b, 0xff, 0x1e, 0x00, 0x70, 0xaf, 0xd8,
+	0x19, 0xc5, 0x27, 0x00, 0x00,
 }
@@ -37,6 +37,10 @@ It has these top-level messages:
 	VolumeDeleteResponse
 	VolumeMarkReadonlyRequest
 	VolumeMarkReadonlyResponse
+	VolumeServerDrainRequest
+	VolumeServerDrainResponse
+	VolumeWarmupRequest
+	VolumeWarmupResponse
 	VolumeCopyRequest
 	VolumeCopyResponse
 	CopyFileRequest
@@ -45,6 +49,10 @@ It has these top-level messages:
 	VolumeTailSenderResponse
 	VolumeTailReceiverRequest
 	VolumeTailReceiverResponse
+	ReadNeedleBlobRequest
+	ReadNeedleBlobResponse
+	VolumeNeedlesCopyRequest
+	VolumeNeedlesCopyResponse
 	VolumeEcShardsGenerateRequest
 	VolumeEcShardsGenerateResponse
 	VolumeEcShardsRebuildRequest
@@ -65,6 +73,8 @@ It has these top-level messages:
 	ReadVolumeFileStatusResponse
 	DiskStatus
 	MemStatus
+	VolumeMarkWritableRequest
+	VolumeMarkWritableResponse
 */
 package volume_server_pb
 
@@ -89,7 +99,8 @@ var _ = math.Inf
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
 type BatchDeleteRequest struct {
-	FileIds []string `protobuf:"bytes,1,rep,name=file_ids,json=fileIds" json:"file_ids,omitempty"`
+	FileIds         []string `protobuf:"bytes,1,rep,name=file_ids,json=fileIds" json:"file_ids,omitempty"`
+	SkipCookieCheck bool     `protobuf:"varint,2,opt,name=skip_cookie_check,json=skipCookieCheck" json:"skip_cookie_check,omitempty"`
 }
 
 func (m *BatchDeleteRequest) Reset()                    { *m = BatchDeleteRequest{} }
@@ -104,6 +115,13 @@ func (m *BatchDeleteRequest) GetFileIds() []string {
 	return nil
 }
 
+func (m *BatchDeleteRequest) GetSkipCookieCheck() bool {
+	if m != nil {
+		return m.SkipCookieCheck
+	}
+	return false
+}
+
 type BatchDeleteResponse struct {
 	Results []*DeleteResult `protobuf:"bytes,1,rep,name=results" json:"results,omitempty"`
 }
@@ -537,7 +555,8 @@ func (*VolumeUnmountResponse) ProtoMessage()               {}
 func (*VolumeUnmountResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
 
 type VolumeDeleteRequest struct {
-	VolumeId uint32 `protobuf:"varint,1,opt,name=volume_id,json=volumeId" json:"volume_id,omitempty"`
+	VolumeId  uint32 `protobuf:"varint,1,opt,name=volume_id,json=volumeId" json:"volume_id,omitempty"`
+	OnlyEmpty bool   `protobuf:"varint,2,opt,name=only_empty,json=onlyEmpty" json:"only_empty,omitempty"`
 }
 
 func (m *VolumeDeleteRequest) Reset()                    { *m = VolumeDeleteRequest{} }
@@ -552,6 +571,13 @@ func (m *VolumeDeleteRequest) GetVolumeId() uint32 {
 	return 0
 }
 
+func (m *VolumeDeleteRequest) GetOnlyEmpty() bool {
+	if m != nil {
+		return m.OnlyEmpty
+	}
+	return false
+}
+
 type VolumeDeleteResponse struct {
 }
 
@@ -577,6 +603,7 @@ func (m *VolumeMarkReadonlyRequest) GetVolumeId() uint32 {
 }
 
 type VolumeMarkReadonlyResponse struct {
+	WasReadonly bool `protobuf:"varint,1,opt,name=was_readonly,json=wasReadonly" json:"was_readonly,omitempty"`
 }
 
 func (m *VolumeMarkReadonlyResponse) Reset()                    { *m = VolumeMarkReadonlyResponse{} }
@@ -584,6 +611,85 @@ func (m *VolumeMarkReadonlyResponse) String() string            { return proto.C
 func (*VolumeMarkReadonlyResponse) ProtoMessage()               {}
 func (*VolumeMarkReadonlyResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{27} }
 
+func (m *VolumeMarkReadonlyResponse) GetWasReadonly() bool {
+	if m != nil {
+		return m.WasReadonly
+	}
+	return false
+}
+
+type VolumeServerDrainRequest struct {
+}
+
+func (m *VolumeServerDrainRequest) Reset()                    { *m = VolumeServerDrainRequest{} }
+func (m *VolumeServerDrainRequest) String() string            { return proto.CompactTextString(m) }
+func (*VolumeServerDrainRequest) ProtoMessage()               {}
+func (*VolumeServerDrainRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{28} }
+
+type VolumeServerDrainResponse struct {
+	VolumeCount  uint32 `protobuf:"varint,1,opt,name=volume_count,json=volumeCount" json:"volume_count,omitempty"`
+	EcShardCount uint32 `protobuf:"varint,2,opt,name=ec_shard_count,json=ecShardCount" json:"ec_shard_count,omitempty"`
+}
+
+func (m *VolumeServerDrainResponse) Reset()                    { *m = VolumeServerDrainResponse{} }
+func (m *VolumeServerDrainResponse) String() string            { return proto.CompactTextString(m) }
+func (*VolumeServerDrainResponse) ProtoMessage()               {}
+func (*VolumeServerDrainResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{29} }
+
+func (m *VolumeServerDrainResponse) GetVolumeCount() uint32 {
+	if m != nil {
+		return m.VolumeCount
+	}
+	return 0
+}
+
+func (m *VolumeServerDrainResponse) GetEcShardCount() uint32 {
+	if m != nil {
+		return m.EcShardCount
+	}
+	return 0
+}
+
+type VolumeWarmupRequest struct {
+	VolumeId       uint32 `protobuf:"varint,1,opt,name=volume_id,json=volumeId" json:"volume_id,omitempty"`
+	BytesPerSecond int64  `protobuf:"varint,2,opt,name=bytes_per_second,json=bytesPerSecond" json:"bytes_per_second,omitempty"`
+}
+
+func (m *VolumeWarmupRequest) Reset()                    { *m = VolumeWarmupRequest{} }
+func (m *VolumeWarmupRequest) String() string            { return proto.CompactTextString(m) }
+func (*VolumeWarmupRequest) ProtoMessage()               {}
+func (*VolumeWarmupRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{30} }
+
+func (m *VolumeWarmupRequest) GetVolumeId() uint32 {
+	if m != nil {
+		return m.VolumeId
+	}
+	return 0
+}
+
+func (m *VolumeWarmupRequest) GetBytesPerSecond() int64 {
+	if m != nil {
+		return m.BytesPerSecond
+	}
+	return 0
+}
+
+type VolumeWarmupResponse struct {
+	WarmedBytes uint64 `protobuf:"varint,1,opt,name=warmed_bytes,json=warmedBytes" json:"warmed_bytes,omitempty"`
+}
+
+func (m *VolumeWarmupResponse) Reset()                    { *m = VolumeWarmupResponse{} }
+func (m *VolumeWarmupResponse) String() string            { return proto.CompactTextString(m) }
+func (*VolumeWarmupResponse) ProtoMessage()               {}
+func (*VolumeWarmupResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{31} }
+
+func (m *VolumeWarmupResponse) GetWarmedBytes() uint64 {
+	if m != nil {
+		return m.WarmedBytes
+	}
+	return 0
+}
+
 type VolumeCopyRequest struct {
 	VolumeId       uint32 `protobuf:"varint,1,opt,name=volume_id,json=volumeId" json:"volume_id,omitempty"`
 	Collection     string `protobuf:"bytes,2,opt,name=collection" json:"collection,omitempty"`
@@ -595,7 +701,7 @@ type VolumeCopyRequest struct {
 func (m *VolumeCopyRequest) Reset()                    { *m = VolumeCopyRequest{} }
 func (m *VolumeCopyRequest) String() string            { return proto.CompactTextString(m) }
 func (*VolumeCopyRequest) ProtoMessage()               {}
-func (*VolumeCopyRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{28} }
+func (*VolumeCopyRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{32} }
 
 func (m *VolumeCopyRequest) GetVolumeId() uint32 {
 	if m != nil {
@@ -639,7 +745,7 @@ type VolumeCopyResponse struct {
 func (m *VolumeCopyResponse) Reset()                    { *m = VolumeCopyResponse{} }
 func (m *VolumeCopyResponse) String() string            { return proto.CompactTextString(m) }
 func (*VolumeCopyResponse) ProtoMessage()               {}
-func (*VolumeCopyResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{29} }
+func (*VolumeCopyResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{33} }
 
 func (m *VolumeCopyResponse) GetLastAppendAtNs() uint64 {
 	if m != nil {
@@ -655,12 +761,14 @@ type CopyFileRequest struct {
 	StopOffset         uint64 `protobuf:"varint,4,opt,name=stop_offset,json=stopOffset" json:"stop_offset,omitempty"`
 	Collection         string `protobuf:"bytes,5,opt,name=collection" json:"collection,omitempty"`
 	IsEcVolume         bool   `protobuf:"varint,6,opt,name=is_ec_volume,json=isEcVolume" json:"is_ec_volume,omitempty"`
+	IoBytePerSecond    int64  `protobuf:"varint,7,opt,name=io_byte_per_second,json=ioBytePerSecond" json:"io_byte_per_second,omitempty"`
+	StartOffset        uint64 `protobuf:"varint,8,opt,name=start_offset,json=startOffset" json:"start_offset,omitempty"`
 }
 
 func (m *CopyFileRequest) Reset()                    { *m = CopyFileRequest{} }
 func (m *CopyFileRequest) String() string            { return proto.CompactTextString(m) }
 func (*CopyFileRequest) ProtoMessage()               {}
-func (*CopyFileRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{30} }
+func (*CopyFileRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34} }
 
 func (m *CopyFileRequest) GetVolumeId() uint32 {
 	if m != nil {
@@ -704,6 +812,20 @@ func (m *CopyFileRequest) GetIsEcVolume() bool {
 	return false
 }
 
+func (m *CopyFileRequest) GetIoBytePerSecond() int64 {
+	if m != nil {
+		return m.IoBytePerSecond
+	}
+	return 0
+}
+
+func (m *CopyFileRequest) GetStartOffset() uint64 {
+	if m != nil {
+		return m.StartOffset
+	}
+	return 0
+}
+
 type CopyFileResponse struct {
 	FileContent []byte `protobuf:"bytes,1,opt,name=file_content,json=fileContent,proto3" json:"file_content,omitempty"`
 }
@@ -711,7 +833,7 @@ type CopyFileResponse struct {
 func (m *CopyFileResponse) Reset()                    { *m = CopyFileResponse{} }
 func (m *CopyFileResponse) String() string            { return proto.CompactTextString(m) }
 func (*CopyFileResponse) ProtoMessage()               {}
-func (*CopyFileResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{31} }
+func (*CopyFileResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{35} }
 
 func (m *CopyFileResponse) GetFileContent() []byte {
 	if m != nil {
@@ -729,7 +851,7 @@ type VolumeTailSenderRequest struct {
 func (m *VolumeTailSenderRequest) Reset()                    { *m = VolumeTailSenderRequest{} }
 func (m *VolumeTailSenderRequest) String() string            { return proto.CompactTextString(m) }
 func (*VolumeTailSenderRequest) ProtoMessage()               {}
-func (*VolumeTailSenderRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{32} }
+func (*VolumeTailSenderRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{36} }
 
 func (m *VolumeTailSenderRequest) GetVolumeId() uint32 {
 	if m != nil {
@@ -761,7 +883,7 @@ type VolumeTailSenderResponse struct {
 func (m *VolumeTailSenderResponse) Reset()                    { *m = VolumeTailSenderResponse{} }
 func (m *VolumeTailSenderResponse) String() string            { return proto.CompactTextString(m) }
 func (*VolumeTailSenderResponse) ProtoMessage()               {}
-func (*VolumeTailSenderResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{33} }
+func (*VolumeTailSenderResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{37} }
 
 func (m *VolumeTailSenderResponse) GetNeedleHeader() []byte {
 	if m != nil {
@@ -794,7 +916,7 @@ type VolumeTailReceiverRequest struct {
 func (m *VolumeTailReceiverRequest) Reset()                    { *m = VolumeTailReceiverRequest{} }
 func (m *VolumeTailReceiverRequest) String() string            { return proto.CompactTextString(m) }
 func (*VolumeTailReceiverRequest) ProtoMessage()               {}
-func (*VolumeTailReceiverRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34} }
+func (*VolumeTailReceiverRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{38} }
 
 func (m *VolumeTailReceiverRequest) GetVolumeId() uint32 {
 	if m != nil {
@@ -825,12 +947,125 @@ func (m *VolumeTailReceiverRequest) GetSourceVolumeServer() string {
 }
 
 type VolumeTailReceiverResponse struct {
+	LastAppendAtNs uint64 `protobuf:"varint,1,opt,name=last_append_at_ns,json=lastAppendAtNs" json:"last_append_at_ns,omitempty"`
 }
 
 func (m *VolumeTailReceiverResponse) Reset()                    { *m = VolumeTailReceiverResponse{} }
 func (m *VolumeTailReceiverResponse) String() string            { return proto.CompactTextString(m) }
 func (*VolumeTailReceiverResponse) ProtoMessage()               {}
-func (*VolumeTailReceiverResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{35} }
+func (*VolumeTailReceiverResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{39} }
+
+func (m *VolumeTailReceiverResponse) GetLastAppendAtNs() uint64 {
+	if m != nil {
+		return m.LastAppendAtNs
+	}
+	return 0
+}
+
+type ReadNeedleBlobRequest struct {
+	VolumeId uint32 `protobuf:"varint,1,opt,name=volume_id,json=volumeId" json:"volume_id,omitempty"`
+	NeedleId uint64 `protobuf:"varint,2,opt,name=needle_id,json=needleId" json:"needle_id,omitempty"`
+}
+
+func (m *ReadNeedleBlobRequest) Reset()                    { *m = ReadNeedleBlobRequest{} }
+func (m *ReadNeedleBlobRequest) String() string            { return proto.CompactTextString(m) }
+func (*ReadNeedleBlobRequest) ProtoMessage()               {}
+func (*ReadNeedleBlobRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{40} }
+
+func (m *ReadNeedleBlobRequest) GetVolumeId() uint32 {
+	if m != nil {
+		return m.VolumeId
+	}
+	return 0
+}
+
+func (m *ReadNeedleBlobRequest) GetNeedleId() uint64 {
+	if m != nil {
+		return m.NeedleId
+	}
+	return 0
+}
+
+// the needle_header is empty if the needle is deleted or not found
+type ReadNeedleBlobResponse struct {
+	NeedleHeader []byte `protobuf:"bytes,1,opt,name=needle_header,json=needleHeader,proto3" json:"needle_header,omitempty"`
+	NeedleBody   []byte `protobuf:"bytes,2,opt,name=needle_body,json=needleBody,proto3" json:"needle_body,omitempty"`
+	IsLastChunk  bool   `protobuf:"varint,3,opt,name=is_last_chunk,json=isLastChunk" json:"is_last_chunk,omitempty"`
+}
+
+func (m *ReadNeedleBlobResponse) Reset()                    { *m = ReadNeedleBlobResponse{} }
+func (m *ReadNeedleBlobResponse) String() string            { return proto.CompactTextString(m) }
+func (*ReadNeedleBlobResponse) ProtoMessage()               {}
+func (*ReadNeedleBlobResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{41} }
+
+func (m *ReadNeedleBlobResponse) GetNeedleHeader() []byte {
+	if m != nil {
+		return m.NeedleHeader
+	}
+	return nil
+}
+
+func (m *ReadNeedleBlobResponse) GetNeedleBody() []byte {
+	if m != nil {
+		return m.NeedleBody
+	}
+	return nil
+}
+
+func (m *ReadNeedleBlobResponse) GetIsLastChunk() bool {
+	if m != nil {
+		return m.IsLastChunk
+	}
+	return false
+}
+
+type VolumeNeedlesCopyRequest struct {
+	VolumeId       uint32   `protobuf:"varint,1,opt,name=volume_id,json=volumeId" json:"volume_id,omitempty"`
+	SourceDataNode string   `protobuf:"bytes,2,opt,name=source_data_node,json=sourceDataNode" json:"source_data_node,omitempty"`
+	NeedleIds      []uint64 `protobuf:"varint,3,rep,packed,name=needle_ids,json=needleIds" json:"needle_ids,omitempty"`
+}
+
+func (m *VolumeNeedlesCopyRequest) Reset()                    { *m = VolumeNeedlesCopyRequest{} }
+func (m *VolumeNeedlesCopyRequest) String() string            { return proto.CompactTextString(m) }
+func (*VolumeNeedlesCopyRequest) ProtoMessage()               {}
+func (*VolumeNeedlesCopyRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{42} }
+
+func (m *VolumeNeedlesCopyRequest) GetVolumeId() uint32 {
+	if m != nil {
+		return m.VolumeId
+	}
+	return 0
+}
+
+func (m *VolumeNeedlesCopyRequest) GetSourceDataNode() string {
+	if m != nil {
+		return m.SourceDataNode
+	}
+	return ""
+}
+
+func (m *VolumeNeedlesCopyRequest) GetNeedleIds() []uint64 {
+	if m != nil {
+		return m.NeedleIds
+	}
+	return nil
+}
+
+type VolumeNeedlesCopyResponse struct {
+	CopiedCount uint32 `protobuf:"varint,1,opt,name=copied_count,json=copiedCount" json:"copied_count,omitempty"`
+}
+
+func (m *VolumeNeedlesCopyResponse) Reset()                    { *m = VolumeNeedlesCopyResponse{} }
+func (m *VolumeNeedlesCopyResponse) String() string            { return proto.CompactTextString(m) }
+func (*VolumeNeedlesCopyResponse) ProtoMessage()               {}
+func (*VolumeNeedlesCopyResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{43} }
+
+func (m *VolumeNeedlesCopyResponse) GetCopiedCount() uint32 {
+	if m != nil {
+		return m.CopiedCount
+	}
+	return 0
+}
 
 type VolumeEcShardsGenerateRequest struct {
 	VolumeId   uint32 `protobuf:"varint,1,opt,name=volume_id,json=volumeId" json:"volume_id,omitempty"`
@@ -840,7 +1075,7 @@ type VolumeEcShardsGenerateRequest struct {
 func (m *VolumeEcShardsGenerateRequest) Reset()                    { *m = VolumeEcShardsGenerateRequest{} }
 func (m *VolumeEcShardsGenerateRequest) String() string            { return proto.CompactTextString(m) }
 func (*VolumeEcShardsGenerateRequest) ProtoMessage()               {}
-func (*VolumeEcShardsGenerateRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{36} }
+func (*VolumeEcShardsGenerateRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{44} }
 
 func (m *VolumeEcShardsGenerateRequest) GetVolumeId() uint32 {
 	if m != nil {
@@ -862,7 +1097,7 @@ type VolumeEcShardsGenerateResponse struct {
 func (m *VolumeEcShardsGenerateResponse) Reset()                    { *m = VolumeEcShardsGenerateResponse{} }
 func (m *VolumeEcShardsGenerateResponse) String() string            { return proto.CompactTextString(m) }
 func (*VolumeEcShardsGenerateResponse) ProtoMessage()               {}
-func (*VolumeEcShardsGenerateResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{37} }
+func (*VolumeEcShardsGenerateResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{45} }
 
 type VolumeEcShardsRebuildRequest struct {
 	VolumeId   uint32 `protobuf:"varint,1,opt,name=volume_id,json=volumeId" json:"volume_id,omitempty"`
@@ -872,7 +1107,7 @@ type VolumeEcShardsRebuildRequest struct {
 func (m *VolumeEcShardsRebuildRequest) Reset()                    { *m = VolumeEcShardsRebuildRequest{} }
 func (m *VolumeEcShardsRebuildRequest) String() string            { return proto.CompactTextString(m) }
 func (*VolumeEcShardsRebuildRequest) ProtoMessage()               {}
-func (*VolumeEcShardsRebuildRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{38} }
+func (*VolumeEcShardsRebuildRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{46} }
 
 func (m *VolumeEcShardsRebuildRequest) GetVolumeId() uint32 {
 	if m != nil {
@@ -895,7 +1130,7 @@ type VolumeEcShardsRebuildResponse struct {
 func (m *VolumeEcShardsRebuildResponse) Reset()                    { *m = VolumeEcShardsRebuildResponse{} }
 func (m *VolumeEcShardsRebuildResponse) String() string            { return proto.CompactTextString(m) }
 func (*VolumeEcShardsRebuildResponse) ProtoMessage()               {}
-func (*VolumeEcShardsRebuildResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{39} }
+func (*VolumeEcShardsRebuildResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{47} }
 
 func (m *VolumeEcShardsRebuildResponse) GetRebuiltShardIds() []uint32 {
 	if m != nil {
@@ -905,17 +1140,18 @@ func (m *VolumeEcShardsRebuildResponse) GetRebuiltShardIds() []uint32 {
 }
 
 type VolumeEcShardsCopyRequest struct {
-	VolumeId       uint32   `protobuf:"varint,1,opt,name=volume_id,json=volumeId" json:"volume_id,omitempty"`
-	Collection     string   `protobuf:"bytes,2,opt,name=collection" json:"collection,omitempty"`
-	ShardIds       []uint32 `protobuf:"varint,3,rep,packed,name=shard_ids,json=shardIds" json:"shard_ids,omitempty"`
-	CopyEcxFile    bool     `protobuf:"varint,4,opt,name=copy_ecx_file,json=copyEcxFile" json:"copy_ecx_file,omitempty"`
-	SourceDataNode string   `protobuf:"bytes,5,opt,name=source_data_node,json=sourceDataNode" json:"source_data_node,omitempty"`
+	VolumeId        uint32   `protobuf:"varint,1,opt,name=volume_id,json=volumeId" json:"volume_id,omitempty"`
+	Collection      string   `protobuf:"bytes,2,opt,name=collection" json:"collection,omitempty"`
+	ShardIds        []uint32 `protobuf:"varint,3,rep,packed,name=shard_ids,json=shardIds" json:"shard_ids,omitempty"`
+	CopyEcxFile     bool     `protobuf:"varint,4,opt,name=copy_ecx_file,json=copyEcxFile" json:"copy_ecx_file,omitempty"`
+	SourceDataNode  string   `protobuf:"bytes,5,opt,name=source_data_node,json=sourceDataNode" json:"source_data_node,omitempty"`
+	IoBytePerSecond int64    `protobuf:"varint,6,opt,name=io_byte_per_second,json=ioBytePerSecond" json:"io_byte_per_second,omitempty"`
 }
 
 func (m *VolumeEcShardsCopyRequest) Reset()                    { *m = VolumeEcShardsCopyRequest{} }
 func (m *VolumeEcShardsCopyRequest) String() string            { return proto.CompactTextString(m) }
 func (*VolumeEcShardsCopyRequest) ProtoMessage()               {}
-func (*VolumeEcShardsCopyRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{40} }
+func (*VolumeEcShardsCopyRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{48} }
 
 func (m *VolumeEcShardsCopyRequest) GetVolumeId() uint32 {
 	if m != nil {
@@ -952,13 +1188,20 @@ func (m *VolumeEcShardsCopyRequest) GetSourceDataNode() string {
 	return ""
 }
 
+func (m *VolumeEcShardsCopyRequest) GetIoBytePerSecond() int64 {
+	if m != nil {
+		return m.IoBytePerSecond
+	}
+	return 0
+}
+
 type VolumeEcShardsCopyResponse struct {
 }
 
 func (m *VolumeEcShardsCopyResponse) Reset()                    { *m = VolumeEcShardsCopyResponse{} }
 func (m *VolumeEcShardsCopyResponse) String() string            { return proto.CompactTextString(m) }
 func (*VolumeEcShardsCopyResponse) ProtoMessage()               {}
-func (*VolumeEcShardsCopyResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{41} }
+func (*VolumeEcShardsCopyResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{49} }
 
 type VolumeEcShardsDeleteRequest struct {
 	VolumeId   uint32   `protobuf:"varint,1,opt,name=volume_id,json=volumeId" json:"volume_id,omitempty"`
@@ -969,7 +1212,7 @@ type VolumeEcShardsDeleteRequest struct {
 func (m *VolumeEcShardsDeleteRequest) Reset()                    { *m = VolumeEcShardsDeleteRequest{} }
 func (m *VolumeEcShardsDeleteRequest) String() string            { return proto.CompactTextString(m) }
 func (*VolumeEcShardsDeleteRequest) ProtoMessage()               {}
-func (*VolumeEcShardsDeleteRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{42} }
+func (*VolumeEcShardsDeleteRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{50} }
 
 func (m *VolumeEcShardsDeleteRequest) GetVolumeId() uint32 {
 	if m != nil {
@@ -998,7 +1241,7 @@ type VolumeEcShardsDeleteResponse struct {
 func (m *VolumeEcShardsDeleteResponse) Reset()                    { *m = VolumeEcShardsDeleteResponse{} }
 func (m *VolumeEcShardsDeleteResponse) String() string            { return proto.CompactTextString(m) }
 func (*VolumeEcShardsDeleteResponse) ProtoMessage()               {}
-func (*VolumeEcShardsDeleteResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{43} }
+func (*VolumeEcShardsDeleteResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{51} }
 
 type VolumeEcShardsMountRequest struct {
 	VolumeId   uint32   `protobuf:"varint,1,opt,name=volume_id,json=volumeId" json:"volume_id,omitempty"`
@@ -1009,7 +1252,7 @@ type VolumeEcShardsMountRequest struct {
 func (m *VolumeEcShardsMountRequest) Reset()                    { *m = VolumeEcShardsMountRequest{} }
 func (m *VolumeEcShardsMountRequest) String() string            { return proto.CompactTextString(m) }
 func (*VolumeEcShardsMountRequest) ProtoMessage()               {}
-func (*VolumeEcShardsMountRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{44} }
+func (*VolumeEcShardsMountRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{52} }
 
 func (m *VolumeEcShardsMountRequest) GetVolumeId() uint32 {
 	if m != nil {
@@ -1038,7 +1281,7 @@ type VolumeEcShardsMountResponse struct {
 func (m *VolumeEcShardsMountResponse) Reset()                    { *m = VolumeEcShardsMountResponse{} }
 func (m *VolumeEcShardsMountResponse) String() string            { return proto.CompactTextString(m) }
 func (*VolumeEcShardsMountResponse) ProtoMessage()               {}
-func (*VolumeEcShardsMountResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{45} }
+func (*VolumeEcShardsMountResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{53} }
 
 type VolumeEcShardsUnmountRequest struct {
 	VolumeId uint32   `protobuf:"varint,1,opt,name=volume_id,json=volumeId" json:"volume_id,omitempty"`
@@ -1048,7 +1291,7 @@ type VolumeEcShardsUnmountRequest struct {
 func (m *VolumeEcShardsUnmountRequest) Reset()                    { *m = VolumeEcShardsUnmountRequest{} }
 func (m *VolumeEcShardsUnmountRequest) String() string            { return proto.CompactTextString(m) }
 func (*VolumeEcShardsUnmountRequest) ProtoMessage()               {}
-func (*VolumeEcShardsUnmountRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{46} }
+func (*VolumeEcShardsUnmountRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{54} }
 
 func (m *VolumeEcShardsUnmountRequest) GetVolumeId() uint32 {
 	if m != nil {
@@ -1070,7 +1313,7 @@ type VolumeEcShardsUnmountResponse struct {
 func (m *VolumeEcShardsUnmountResponse) Reset()                    { *m = VolumeEcShardsUnmountResponse{} }
 func (m *VolumeEcShardsUnmountResponse) String() string            { return proto.CompactTextString(m) }
 func (*VolumeEcShardsUnmountResponse) ProtoMessage()               {}
-func (*VolumeEcShardsUnmountResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{47} }
+func (*VolumeEcShardsUnmountResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{55} }
 
 type VolumeEcShardReadRequest struct {
 	VolumeId uint32 `protobuf:"varint,1,opt,name=volume_id,json=volumeId" json:"volume_id,omitempty"`
@@ -1083,7 +1326,7 @@ type VolumeEcShardReadRequest struct {
 func (m *VolumeEcShardReadRequest) Reset()                    { *m = VolumeEcShardReadRequest{} }
 func (m *VolumeEcShardReadRequest) String() string            { return proto.CompactTextString(m) }
 func (*VolumeEcShardReadRequest) ProtoMessage()               {}
-func (*VolumeEcShardReadRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{48} }
+func (*VolumeEcShardReadRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{56} }
 
 func (m *VolumeEcShardReadRequest) GetVolumeId() uint32 {
 	if m != nil {
@@ -1128,7 +1371,7 @@ type VolumeEcShardReadResponse struct {
 func (m *VolumeEcShardReadResponse) Reset()                    { *m = VolumeEcShardReadResponse{} }
 func (m *VolumeEcShardReadResponse) String() string            { return proto.CompactTextString(m) }
 func (*VolumeEcShardReadResponse) ProtoMessage()               {}
-func (*VolumeEcShardReadResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{49} }
+func (*VolumeEcShardReadResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{57} }
 
 func (m *VolumeEcShardReadResponse) GetData() []byte {
 	if m != nil {
@@ -1154,7 +1397,7 @@ type VolumeEcBlobDeleteRequest struct {
 func (m *VolumeEcBlobDeleteRequest) Reset()                    { *m = VolumeEcBlobDeleteRequest{} }
 func (m *VolumeEcBlobDeleteRequest) String() string            { return proto.CompactTextString(m) }
 func (*VolumeEcBlobDeleteRequest) ProtoMessage()               {}
-func (*VolumeEcBlobDeleteRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{50} }
+func (*VolumeEcBlobDeleteRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{58} }
 
 func (m *VolumeEcBlobDeleteRequest) GetVolumeId() uint32 {
 	if m != nil {
@@ -1190,7 +1433,7 @@ type VolumeEcBlobDeleteResponse struct {
 func (m *VolumeEcBlobDeleteResponse) Reset()                    { *m = VolumeEcBlobDeleteResponse{} }
 func (m *VolumeEcBlobDeleteResponse) String() string            { return proto.CompactTextString(m) }
 func (*VolumeEcBlobDeleteResponse) ProtoMessage()               {}
-func (*VolumeEcBlobDeleteResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{51} }
+func (*VolumeEcBlobDeleteResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{59} }
 
 type ReadVolumeFileStatusRequest struct {
 	VolumeId uint32 `protobuf:"varint,1,opt,name=volume_id,json=volumeId" json:"volume_id,omitempty"`
@@ -1199,7 +1442,7 @@ type ReadVolumeFileStatusRequest struct {
 func (m *ReadVolumeFileStatusRequest) Reset()                    { *m = ReadVolumeFileStatusRequest{} }
 func (m *ReadVolumeFileStatusRequest) String() string            { return proto.CompactTextString(m) }
 func (*ReadVolumeFileStatusRequest) ProtoMessage()               {}
-func (*ReadVolumeFileStatusRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{52} }
+func (*ReadVolumeFileStatusRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{60} }
 
 func (m *ReadVolumeFileStatusRequest) GetVolumeId() uint32 {
 	if m != nil {
@@ -1222,7 +1465,7 @@ type ReadVolumeFileStatusResponse struct {
 func (m *ReadVolumeFileStatusResponse) Reset()                    { *m = ReadVolumeFileStatusResponse{} }
 func (m *ReadVolumeFileStatusResponse) String() string            { return proto.CompactTextString(m) }
 func (*ReadVolumeFileStatusResponse) ProtoMessage()               {}
-func (*ReadVolumeFileStatusResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{53} }
+func (*ReadVolumeFileStatusResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{61} }
 
 func (m *ReadVolumeFileStatusResponse) GetVolumeId() uint32 {
 	if m != nil {
@@ -1290,7 +1533,7 @@ type DiskStatus struct {
 func (m *DiskStatus) Reset()                    { *m = DiskStatus{} }
 func (m *DiskStatus) String() string            { return proto.CompactTextString(m) }
 func (*DiskStatus) ProtoMessage()               {}
-func (*DiskStatus) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{54} }
+func (*DiskStatus) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{62} }
 
 func (m *DiskStatus) GetDir() string {
 	if m != nil {
@@ -1333,7 +1576,7 @@ type MemStatus struct {
 func (m *MemStatus) Reset()                    { *m = MemStatus{} }
 func (m *MemStatus) String() string            { return proto.CompactTextString(m) }
 func (*MemStatus) ProtoMessage()               {}
-func (*MemStatus) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{55} }
+func (*MemStatus) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{63} }
 
 func (m *MemStatus) GetGoroutines() int32 {
 	if m != nil {
@@ -1384,6 +1627,30 @@ func (m *MemStatus) GetStack() uint64 {
 	return 0
 }
 
+type VolumeMarkWritableRequest struct {
+	VolumeId uint32 `protobuf:"varint,1,opt,name=volume_id,json=volumeId" json:"volume_id,omitempty"`
+}
+
+func (m *VolumeMarkWritableRequest) Reset()                    { *m = VolumeMarkWritableRequest{} }
+func (m *VolumeMarkWritableRequest) String() string            { return proto.CompactTextString(m) }
+func (*VolumeMarkWritableRequest) ProtoMessage()               {}
+func (*VolumeMarkWritableRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{64} }
+
+func (m *VolumeMarkWritableRequest) GetVolumeId() uint32 {
+	if m != nil {
+		return m.VolumeId
+	}
+	return 0
+}
+
+type VolumeMarkWritableResponse struct {
+}
+
+func (m *VolumeMarkWritableResponse) Reset()                    { *m = VolumeMarkWritableResponse{} }
+func (m *VolumeMarkWritableResponse) String() string            { return proto.CompactTextString(m) }
+func (*VolumeMarkWritableResponse) ProtoMessage()               {}
+func (*VolumeMarkWritableResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{65} }
+
 func init() {
 	proto.RegisterType((*BatchDeleteRequest)(nil), "volume_server_pb.BatchDeleteRequest")
 	proto.RegisterType((*BatchDeleteResponse)(nil), "volume_server_pb.BatchDeleteResponse")
@@ -1413,6 +1680,10 @@ func init() {
 	proto.RegisterType((*VolumeDeleteResponse)(nil), "volume_server_pb.VolumeDeleteResponse")
 	proto.RegisterType((*VolumeMarkReadonlyRequest)(nil), "volume_server_pb.VolumeMarkReadonlyRequest")
 	proto.RegisterType((*VolumeMarkReadonlyResponse)(nil), "volume_server_pb.VolumeMarkReadonlyResponse")
+	proto.RegisterType((*VolumeServerDrainRequest)(nil), "volume_server_pb.VolumeServerDrainRequest")
+	proto.RegisterType((*VolumeServerDrainResponse)(nil), "volume_server_pb.VolumeServerDrainResponse")
+	proto.RegisterType((*VolumeWarmupRequest)(nil), "volume_server_pb.VolumeWarmupRequest")
+	proto.RegisterType((*VolumeWarmupResponse)(nil), "volume_server_pb.VolumeWarmupResponse")
 	proto.RegisterType((*VolumeCopyRequest)(nil), "volume_server_pb.VolumeCopyRequest")
 	proto.RegisterType((*VolumeCopyResponse)(nil), "volume_server_pb.VolumeCopyResponse")
 	proto.RegisterType((*CopyFileRequest)(nil), "volume_server_pb.CopyFileRequest")
@@ -1421,6 +1692,10 @@ func init() {
 	proto.RegisterType((*VolumeTailSenderResponse)(nil), "volume_server_pb.VolumeTailSenderResponse")
 	proto.RegisterType((*VolumeTailReceiverRequest)(nil), "volume_server_pb.VolumeTailReceiverRequest")
 	proto.RegisterType((*VolumeTailReceiverResponse)(nil), "volume_server_pb.VolumeTailReceiverResponse")
+	proto.RegisterType((*ReadNeedleBlobRequest)(nil), "volume_server_pb.ReadNeedleBlobRequest")
+	proto.RegisterType((*ReadNeedleBlobResponse)(nil), "volume_server_pb.ReadNeedleBlobResponse")
+	proto.RegisterType((*VolumeNeedlesCopyRequest)(nil), "volume_server_pb.VolumeNeedlesCopyRequest")
+	proto.RegisterType((*VolumeNeedlesCopyResponse)(nil), "volume_server_pb.VolumeNeedlesCopyResponse")
 	proto.RegisterType((*VolumeEcShardsGenerateRequest)(nil), "volume_server_pb.VolumeEcShardsGenerateRequest")
 	proto.RegisterType((*VolumeEcShardsGenerateResponse)(nil), "volume_server_pb.VolumeEcShardsGenerateResponse")
 	proto.RegisterType((*VolumeEcShardsRebuildRequest)(nil), "volume_server_pb.VolumeEcShardsRebuildRequest")
@@ -1441,6 +1716,8 @@ func init() {
 	proto.RegisterType((*ReadVolumeFileStatusResponse)(nil), "volume_server_pb.ReadVolumeFileStatusResponse")
 	proto.RegisterType((*DiskStatus)(nil), "volume_server_pb.DiskStatus")
 	proto.RegisterType((*MemStatus)(nil), "volume_server_pb.MemStatus")
+	proto.RegisterType((*VolumeMarkWritableRequest)(nil), "volume_server_pb.VolumeMarkWritableRequest")
+	proto.RegisterType((*VolumeMarkWritableResponse)(nil), "volume_server_pb.VolumeMarkWritableResponse")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -1468,12 +1745,19 @@ type VolumeServerClient interface {
 	VolumeUnmount(ctx context.Context, in *VolumeUnmountRequest, opts ...grpc.CallOption) (*VolumeUnmountResponse, error)
 	VolumeDelete(ctx context.Context, in *VolumeDeleteRequest, opts ...grpc.CallOption) (*VolumeDeleteResponse, error)
 	VolumeMarkReadonly(ctx context.Context, in *VolumeMarkReadonlyRequest, opts ...grpc.CallOption) (*VolumeMarkReadonlyResponse, error)
+	VolumeMarkWritable(ctx context.Context, in *VolumeMarkWritableRequest, opts ...grpc.CallOption) (*VolumeMarkWritableResponse, error)
+	// stop taking new volumes and writes, before moving the data out
+	VolumeServerDrain(ctx context.Context, in *VolumeServerDrainRequest, opts ...grpc.CallOption) (*VolumeServerDrainResponse, error)
+	// read the volume files into the page cache ahead of the anticipated reads
+	VolumeWarmup(ctx context.Context, in *VolumeWarmupRequest, opts ...grpc.CallOption) (*VolumeWarmupResponse, error)
 	// copy the .idx .dat files, and mount this volume
 	VolumeCopy(ctx context.Context, in *VolumeCopyRequest, opts ...grpc.CallOption) (*VolumeCopyResponse, error)
 	ReadVolumeFileStatus(ctx context.Context, in *ReadVolumeFileStatusRequest, opts ...grpc.CallOption) (*ReadVolumeFileStatusResponse, error)
 	CopyFile(ctx context.Context, in *CopyFileRequest, opts ...grpc.CallOption) (VolumeServer_CopyFileClient, error)
 	VolumeTailSender(ctx context.Context, in *VolumeTailSenderRequest, opts ...grpc.CallOption) (VolumeServer_VolumeTailSenderClient, error)
 	VolumeTailReceiver(ctx context.Context, in *VolumeTailReceiverRequest, opts ...grpc.CallOption) (*VolumeTailReceiverResponse, error)
+	ReadNeedleBlob(ctx context.Context, in *ReadNeedleBlobRequest, opts ...grpc.CallOption) (VolumeServer_ReadNeedleBlobClient, error)
+	VolumeNeedlesCopy(ctx context.Context, in *VolumeNeedlesCopyRequest, opts ...grpc.CallOption) (*VolumeNeedlesCopyResponse, error)
 	// erasure coding
 	VolumeEcShardsGenerate(ctx context.Context, in *VolumeEcShardsGenerateRequest, opts ...grpc.CallOption) (*VolumeEcShardsGenerateResponse, error)
 	VolumeEcShardsRebuild(ctx context.Context, in *VolumeEcShardsRebuildRequest, opts ...grpc.CallOption) (*VolumeEcShardsRebuildResponse, error)
@@ -1633,6 +1917,33 @@ func (c *volumeServerClient) VolumeMarkReadonly(ctx context.Context, in *VolumeM
 	return out, nil
 }
 
+func (c *volumeServerClient) VolumeMarkWritable(ctx context.Context, in *VolumeMarkWritableRequest, opts ...grpc.CallOption) (*VolumeMarkWritableResponse, error) {
+	out := new(VolumeMarkWritableResponse)
+	err := grpc.Invoke(ctx, "/volume_server_pb.VolumeServer/VolumeMarkWritable", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *volumeServerClient) VolumeServerDrain(ctx context.Context, in *VolumeServerDrainRequest, opts ...grpc.CallOption) (*VolumeServerDrainResponse, error) {
+	out := new(VolumeServerDrainResponse)
+	err := grpc.Invoke(ctx, "/volume_server_pb.VolumeServer/VolumeServerDrain", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *volumeServerClient) VolumeWarmup(ctx context.Context, in *VolumeWarmupRequest, opts ...grpc.CallOption) (*VolumeWarmupResponse, error) {
+	out := new(VolumeWarmupResponse)
+	err := grpc.Invoke(ctx, "/volume_server_pb.VolumeServer/VolumeWarmup", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *volumeServerClient) VolumeCopy(ctx context.Context, in *VolumeCopyRequest, opts ...grpc.CallOption) (*VolumeCopyResponse, error) {
 	out := new(VolumeCopyResponse)
 	err := grpc.Invoke(ctx, "/volume_server_pb.VolumeServer/VolumeCopy", in, out, c.cc, opts...)
@@ -1724,6 +2035,47 @@ func (c *volumeServerClient) VolumeTailReceiver(ctx context.Context, in *VolumeT
 	return out, nil
 }
 
+func (c *volumeServerClient) ReadNeedleBlob(ctx context.Context, in *ReadNeedleBlobRequest, opts ...grpc.CallOption) (VolumeServer_ReadNeedleBlobClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_VolumeServer_serviceDesc.Streams[3], c.cc, "/volume_server_pb.VolumeServer/ReadNeedleBlob", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &volumeServerReadNeedleBlobClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type VolumeServer_ReadNeedleBlobClient interface {
+	Recv() (*ReadNeedleBlobResponse, error)
+	grpc.ClientStream
+}
+
+type volumeServerReadNeedleBlobClient struct {
+	grpc.ClientStream
+}
+
+func (x *volumeServerReadNeedleBlobClient) Recv() (*ReadNeedleBlobResponse, error) {
+	m := new(ReadNeedleBlobResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *volumeServerClient) VolumeNeedlesCopy(ctx context.Context, in *VolumeNeedlesCopyRequest, opts ...grpc.CallOption) (*VolumeNeedlesCopyResponse, error) {
+	out := new(VolumeNeedlesCopyResponse)
+	err := grpc.Invoke(ctx, "/volume_server_pb.VolumeServer/VolumeNeedlesCopy", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *volumeServerClient) VolumeEcShardsGenerate(ctx context.Context, in *VolumeEcShardsGenerateRequest, opts ...grpc.CallOption) (*VolumeEcShardsGenerateResponse, error) {
 	out := new(VolumeEcShardsGenerateResponse)
 	err := grpc.Invoke(ctx, "/volume_server_pb.VolumeServer/VolumeEcShardsGenerate", in, out, c.cc, opts...)
@@ -1779,7 +2131,7 @@ func (c *volumeServerClient) VolumeEcShardsUnmount(ctx context.Context, in *Volu
 }
 
 func (c *volumeServerClient) VolumeEcShardRead(ctx context.Context, in *VolumeEcShardReadRequest, opts ...grpc.CallOption) (VolumeServer_VolumeEcShardReadClient, error) {
-	stream, err := grpc.NewClientStream(ctx, &_VolumeServer_serviceDesc.Streams[3], c.cc, "/volume_server_pb.VolumeServer/VolumeEcShardRead", opts...)
+	stream, err := grpc.NewClientStream(ctx, &_VolumeServer_serviceDesc.Streams[4], c.cc, "/volume_server_pb.VolumeServer/VolumeEcShardRead", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -1836,12 +2188,19 @@ type VolumeServerServer interface {
 	VolumeUnmount(context.Context, *VolumeUnmountRequest) (*VolumeUnmountResponse, error)
 	VolumeDelete(context.Context, *VolumeDeleteRequest) (*VolumeDeleteResponse, error)
 	VolumeMarkReadonly(context.Context, *VolumeMarkReadonlyRequest) (*VolumeMarkReadonlyResponse, error)
+	VolumeMarkWritable(context.Context, *VolumeMarkWritableRequest) (*VolumeMarkWritableResponse, error)
+	// stop taking new volumes and writes, before moving the data out
+	VolumeServerDrain(context.Context, *VolumeServerDrainRequest) (*VolumeServerDrainResponse, error)
+	// read the volume files into the page cache ahead of the anticipated reads
+	VolumeWarmup(context.Context, *VolumeWarmupRequest) (*VolumeWarmupResponse, error)
 	// copy the .idx .dat files, and mount this volume
 	VolumeCopy(context.Context, *VolumeCopyRequest) (*VolumeCopyResponse, error)
 	ReadVolumeFileStatus(context.Context, *ReadVolumeFileStatusRequest) (*ReadVolumeFileStatusResponse, error)
 	CopyFile(*CopyFileRequest, VolumeServer_CopyFileServer) error
 	VolumeTailSender(*VolumeTailSenderRequest, VolumeServer_VolumeTailSenderServer) error
 	VolumeTailReceiver(context.Context, *VolumeTailReceiverRequest) (*VolumeTailReceiverResponse, error)
+	ReadNeedleBlob(*ReadNeedleBlobRequest, VolumeServer_ReadNeedleBlobServer) error
+	VolumeNeedlesCopy(context.Context, *VolumeNeedlesCopyRequest) (*VolumeNeedlesCopyResponse, error)
 	// erasure coding
 	VolumeEcShardsGenerate(context.Context, *VolumeEcShardsGenerateRequest) (*VolumeEcShardsGenerateResponse, error)
 	VolumeEcShardsRebuild(context.Context, *VolumeEcShardsRebuildRequest) (*VolumeEcShardsRebuildResponse, error)
@@ -2094,6 +2453,60 @@ func _VolumeServer_VolumeMarkReadonly_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _VolumeServer_VolumeMarkWritable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VolumeMarkWritableRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VolumeServerServer).VolumeMarkWritable(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/volume_server_pb.VolumeServer/VolumeMarkWritable",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VolumeServerServer).VolumeMarkWritable(ctx, req.(*VolumeMarkWritableRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VolumeServer_VolumeServerDrain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VolumeServerDrainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VolumeServerServer).VolumeServerDrain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/volume_server_pb.VolumeServer/VolumeServerDrain",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VolumeServerServer).VolumeServerDrain(ctx, req.(*VolumeServerDrainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VolumeServer_VolumeWarmup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VolumeWarmupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VolumeServerServer).VolumeWarmup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/volume_server_pb.VolumeServer/VolumeWarmup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VolumeServerServer).VolumeWarmup(ctx, req.(*VolumeWarmupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _VolumeServer_VolumeCopy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(VolumeCopyRequest)
 	if err := dec(in); err != nil {
@@ -2190,6 +2603,45 @@ func _VolumeServer_VolumeTailReceiver_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _VolumeServer_ReadNeedleBlob_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ReadNeedleBlobRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(VolumeServerServer).ReadNeedleBlob(m, &volumeServerReadNeedleBlobServer{stream})
+}
+
+type VolumeServer_ReadNeedleBlobServer interface {
+	Send(*ReadNeedleBlobResponse) error
+	grpc.ServerStream
+}
+
+type volumeServerReadNeedleBlobServer struct {
+	grpc.ServerStream
+}
+
+func (x *volumeServerReadNeedleBlobServer) Send(m *ReadNeedleBlobResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _VolumeServer_VolumeNeedlesCopy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VolumeNeedlesCopyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VolumeServerServer).VolumeNeedlesCopy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/volume_server_pb.VolumeServer/VolumeNeedlesCopy",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VolumeServerServer).VolumeNeedlesCopy(ctx, req.(*VolumeNeedlesCopyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _VolumeServer_VolumeEcShardsGenerate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(VolumeEcShardsGenerateRequest)
 	if err := dec(in); err != nil {
@@ -2389,6 +2841,18 @@ var _VolumeServer_serviceDesc = grpc.ServiceDesc{
 			MethodName: "VolumeMarkReadonly",
 			Handler:    _VolumeServer_VolumeMarkReadonly_Handler,
 		},
+		{
+			MethodName: "VolumeMarkWritable",
+			Handler:    _VolumeServer_VolumeMarkWritable_Handler,
+		},
+		{
+			MethodName: "VolumeServerDrain",
+			Handler:    _VolumeServer_VolumeServerDrain_Handler,
+		},
+		{
+			MethodName: "VolumeWarmup",
+			Handler:    _VolumeServer_VolumeWarmup_Handler,
+		},
 		{
 			MethodName: "VolumeCopy",
 			Handler:    _VolumeServer_VolumeCopy_Handler,
@@ -2401,6 +2865,10 @@ var _VolumeServer_serviceDesc = grpc.ServiceDesc{
 			MethodName: "VolumeTailReceiver",
 			Handler:    _VolumeServer_VolumeTailReceiver_Handler,
 		},
+		{
+			MethodName: "VolumeNeedlesCopy",
+			Handler:    _VolumeServer_VolumeNeedlesCopy_Handler,
+		},
 		{
 			MethodName: "VolumeEcShardsGenerate",
 			Handler:    _VolumeServer_VolumeEcShardsGenerate_Handler,
@@ -2446,6 +2914,11 @@ var _VolumeServer_serviceDesc = grpc.ServiceDesc{
 			Handler:       _VolumeServer_VolumeTailSender_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "ReadNeedleBlob",
+			Handler:       _VolumeServer_ReadNeedleBlob_Handler,
+			ServerStreams: true,
+		},
 		{
 			StreamName:    "VolumeEcShardRead",
 			Handler:       _VolumeServer_VolumeEcShardRead_Handler,
@@ -2458,126 +2931,148 @@ var _VolumeServer_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("volume_server.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 1924 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xc4, 0x19, 0xcb, 0x72, 0xdc, 0xc6,
-	0x91, 0xd0, 0x2e, 0xb9, 0xbb, 0xbd, 0x4b, 0x89, 0x1c, 0x52, 0xe4, 0x0a, 0x14, 0x29, 0x1a, 0x76,
-	0x6c, 0x8a, 0xb2, 0x49, 0x45, 0xae, 0x24, 0x4e, 0x72, 0x48, 0x44, 0x8a, 0x49, 0x54, 0x8e, 0xe9,
-	0x2a, 0x50, 0x56, 0x39, 0x65, 0x57, 0xa1, 0x86, 0xc0, 0x50, 0x44, 0x11, 0x0b, 0x40, 0x98, 0x01,
-	0xa5, 0x55, 0x25, 0x27, 0xe5, 0x9a, 0x0f, 0xc8, 0x39, 0xb7, 0x1c, 0x72, 0xcd, 0x07, 0xe4, 0x17,
-	0x72, 0xcb, 0x37, 0xe4, 0x0b, 0x72, 0x49, 0xcd, 0x03, 0x58, 0x3c, 0x77, 0xc1, 0x90, 0x55, 0xb9,
-	0x61, 0x7b, 0xfa, 0x3d, 0xdd, 0x3d, 0xdd, 0xbd, 0xb0, 0x72, 0x19, 0x78, 0xf1, 0x88, 0x58, 0x94,
-	0x44, 0x97, 0x24, 0xda, 0x0b, 0xa3, 0x80, 0x05, 0x68, 0x29, 0x07, 0xb4, 0xc2, 0x53, 0x63, 0x1f,
-	0xd0, 0x01, 0x66, 0xf6, 0xf9, 0x33, 0xe2, 0x11, 0x46, 0x4c, 0xf2, 0x3a, 0x26, 0x94, 0xa1, 0x7b,
-	0xd0, 0x3d, 0x73, 0x3d, 0x62, 0xb9, 0x0e, 0x1d, 0x6a, 0xdb, 0xad, 0x9d, 0x9e, 0xd9, 0xe1, 0xbf,
-	0x9f, 0x3b, 0xd4, 0xf8, 0x1a, 0x56, 0x72, 0x04, 0x34, 0x0c, 0x7c, 0x4a, 0xd0, 0x17, 0xd0, 0x89,
-	0x08, 0x8d, 0x3d, 0x26, 0x09, 0xfa, 0x4f, 0xb6, 0xf6, 0x8a, 0xb2, 0xf6, 0x52, 0x92, 0xd8, 0x63,
-	0x66, 0x82, 0x6e, 0xbc, 0xd7, 0x60, 0x90, 0x3d, 0x41, 0xeb, 0xd0, 0x51, 0xc2, 0x87, 0xda, 0xb6,
-	0xb6, 0xd3, 0x33, 0x17, 0xa4, 0x6c, 0xb4, 0x06, 0x0b, 0x94, 0x61, 0x16, 0xd3, 0xe1, 0xad, 0x6d,
-	0x6d, 0x67, 0xde, 0x54, 0xbf, 0xd0, 0x2a, 0xcc, 0x93, 0x28, 0x0a, 0xa2, 0x61, 0x4b, 0xa0, 0xcb,
-	0x1f, 0x08, 0x41, 0x9b, 0xba, 0xef, 0xc8, 0xb0, 0xbd, 0xad, 0xed, 0x2c, 0x9a, 0xe2, 0x1b, 0x0d,
-	0xa1, 0x73, 0x49, 0x22, 0xea, 0x06, 0xfe, 0x70, 0x5e, 0x80, 0x93, 0x9f, 0x46, 0x07, 0xe6, 0x8f,
-	0x46, 0x21, 0x1b, 0x1b, 0x3f, 0x81, 0xe1, 0x4b, 0x6c, 0xc7, 0xf1, 0xe8, 0xa5, 0x50, 0xff, 0xf0,
-	0x9c, 0xd8, 0x17, 0x89, 0x5b, 0x36, 0xa0, 0xa7, 0x8c, 0x52, 0xba, 0x2d, 0x9a, 0x5d, 0x09, 0x78,
-	0xee, 0x18, 0xbf, 0x84, 0x7b, 0x15, 0x84, 0xca, 0x3d, 0x1f, 0xc2, 0xe2, 0x2b, 0x1c, 0x9d, 0xe2,
-	0x57, 0xc4, 0x8a, 0x30, 0x73, 0x03, 0x41, 0xad, 0x99, 0x03, 0x05, 0x34, 0x39, 0xcc, 0xf8, 0x0e,
-	0xf4, 0x1c, 0x87, 0x60, 0x14, 0x62, 0x9b, 0x35, 0x11, 0x8e, 0xb6, 0xa1, 0x1f, 0x46, 0x04, 0x7b,
-	0x5e, 0x60, 0x63, 0x46, 0x84, 0x7f, 0x5a, 0x66, 0x16, 0x64, 0x6c, 0xc2, 0x46, 0x25, 0x73, 0xa9,
-	0xa0, 0xf1, 0x45, 0x41, 0xfb, 0x60, 0x34, 0x72, 0x1b, 0x89, 0x36, 0xee, 0x97, 0xb4, 0x16, 0x94,
-	0x8a, 0xef, 0x4f, 0x0b, 0xa7, 0x1e, 0xc1, 0x7e, 0x1c, 0x36, 0x62, 0x5c, 0xd4, 0x38, 0x21, 0x4d,
-	0x39, 0xaf, 0xcb, 0xb0, 0x39, 0x0c, 0x3c, 0x8f, 0xd8, 0xcc, 0x0d, 0xfc, 0x84, 0xed, 0x16, 0x80,
-	0x9d, 0x02, 0x55, 0x10, 0x65, 0x20, 0x86, 0x0e, 0xc3, 0x32, 0xa9, 0x62, 0xfb, 0x57, 0x0d, 0xee,
-	0x3e, 0x55, 0x4e, 0x93, 0x82, 0x1b, 0x5d, 0x40, 0x5e, 0xe4, 0xad, 0xa2, 0xc8, 0xe2, 0x05, 0xb5,
-	0x4a, 0x17, 0xc4, 0x31, 0x22, 0x12, 0x7a, 0xae, 0x8d, 0x05, 0x8b, 0xb6, 0x60, 0x91, 0x05, 0xa1,
-	0x25, 0x68, 0x31, 0xe6, 0x89, 0xc8, 0xed, 0x99, 0xfc, 0xd3, 0x18, 0xc2, 0x5a, 0x51, 0x57, 0x65,
-	0xc6, 0x8f, 0x61, 0x5d, 0x42, 0x4e, 0xc6, 0xbe, 0x7d, 0x22, 0xf2, 0xa4, 0x91, 0xd3, 0xff, 0xa3,
-	0xc1, 0xb0, 0x4c, 0xa8, 0xa2, 0xf8, 0xba, 0x1e, 0xb8, 0xaa, 0x7d, 0xe8, 0x01, 0xf4, 0x19, 0x76,
-	0x3d, 0x2b, 0x38, 0x3b, 0xa3, 0x84, 0x0d, 0x17, 0xb6, 0xb5, 0x9d, 0xb6, 0x09, 0x1c, 0xf4, 0xb5,
-	0x80, 0xa0, 0x87, 0xb0, 0x64, 0xcb, 0x48, 0xb6, 0x22, 0x72, 0xe9, 0x8a, 0xcc, 0xee, 0x08, 0xc5,
-	0xee, 0xd8, 0x49, 0x84, 0x4b, 0x30, 0x32, 0x60, 0xd1, 0x75, 0xde, 0x5a, 0xa2, 0xb4, 0x88, 0xc2,
-	0xd0, 0x15, 0xdc, 0xfa, 0xae, 0xf3, 0xf6, 0x57, 0xae, 0x47, 0x4e, 0xdc, 0x77, 0xc4, 0x78, 0x09,
-	0xf7, 0xa5, 0xf1, 0xcf, 0x7d, 0x3b, 0x22, 0x23, 0xe2, 0x33, 0xec, 0x1d, 0x06, 0xe1, 0xb8, 0x51,
-	0x08, 0xdc, 0x83, 0x2e, 0x75, 0x7d, 0x9b, 0x58, 0xbe, 0x2c, 0x50, 0x6d, 0xb3, 0x23, 0x7e, 0x1f,
-	0x53, 0xe3, 0x00, 0x36, 0x6b, 0xf8, 0x2a, 0xcf, 0x7e, 0x00, 0x03, 0xa1, 0x98, 0x1d, 0xf8, 0x8c,
-	0xf8, 0x4c, 0xf0, 0x1e, 0x98, 0x7d, 0x0e, 0x3b, 0x94, 0x20, 0xe3, 0x87, 0x80, 0x24, 0x8f, 0xaf,
-	0x82, 0xd8, 0x6f, 0x96, 0x9a, 0x77, 0x61, 0x25, 0x47, 0xa2, 0x62, 0xe3, 0x73, 0x58, 0x95, 0xe0,
-	0x6f, 0xfc, 0x51, 0x63, 0x5e, 0xeb, 0x70, 0xb7, 0x40, 0xa4, 0xb8, 0x3d, 0x49, 0x84, 0xe4, 0x9f,
-	0x90, 0xa9, 0xcc, 0xd6, 0x12, 0x0d, 0xf2, 0xaf, 0x88, 0xa8, 0x42, 0x52, 0x61, 0x1c, 0x5d, 0x98,
-	0x04, 0x3b, 0x81, 0xef, 0x8d, 0x1b, 0x57, 0xa1, 0x0a, 0x4a, 0xc5, 0xf7, 0x6f, 0x1a, 0x2c, 0x27,
-	0xe5, 0xa9, 0xe1, 0x6d, 0x5e, 0x31, 0x9c, 0x5b, 0xb5, 0xe1, 0xdc, 0x9e, 0x84, 0xf3, 0x0e, 0x2c,
-	0xd1, 0x20, 0x8e, 0x6c, 0x62, 0x39, 0x98, 0x61, 0xcb, 0x0f, 0x1c, 0xa2, 0xa2, 0xfd, 0xb6, 0x84,
-	0x3f, 0xc3, 0x0c, 0x1f, 0x07, 0x0e, 0x31, 0x7e, 0x91, 0x5c, 0x76, 0x2e, 0x4a, 0x1e, 0xc2, 0xb2,
-	0x87, 0x29, 0xb3, 0x70, 0x18, 0x12, 0xdf, 0xb1, 0x30, 0xe3, 0xa1, 0xa6, 0x89, 0x50, 0xbb, 0xcd,
-	0x0f, 0x9e, 0x0a, 0xf8, 0x53, 0x76, 0x4c, 0x8d, 0x7f, 0x6a, 0x70, 0x87, 0xd3, 0xf2, 0xd0, 0x6e,
-	0x64, 0xef, 0x12, 0xb4, 0xc8, 0x5b, 0xa6, 0x0c, 0xe5, 0x9f, 0x68, 0x1f, 0x56, 0x54, 0x0e, 0xb9,
-	0x81, 0x3f, 0x49, 0xaf, 0x96, 0x20, 0x44, 0x93, 0xa3, 0x34, 0xc3, 0x1e, 0x40, 0x9f, 0xb2, 0x20,
-	0x4c, 0xb2, 0xb5, 0x2d, 0xb3, 0x95, 0x83, 0x54, 0xb6, 0xe6, 0x7d, 0x3a, 0x5f, 0xe1, 0xd3, 0x81,
-	0x4b, 0x2d, 0x62, 0x5b, 0x52, 0x2b, 0x91, 0xef, 0x5d, 0x13, 0x5c, 0x7a, 0x64, 0x4b, 0x6f, 0x18,
-	0x3f, 0x82, 0xa5, 0x89, 0x55, 0xcd, 0x73, 0xe7, 0xbd, 0x96, 0x94, 0xc3, 0x17, 0xd8, 0xf5, 0x4e,
-	0x88, 0xef, 0x90, 0xe8, 0x9a, 0x39, 0x8d, 0x1e, 0xc3, 0xaa, 0xeb, 0x78, 0xc4, 0x62, 0xee, 0x88,
-	0x04, 0x31, 0xb3, 0x28, 0xb1, 0x03, 0xdf, 0xa1, 0x89, 0x7f, 0xf8, 0xd9, 0x0b, 0x79, 0x74, 0x22,
-	0x4f, 0x8c, 0x3f, 0xa6, 0xb5, 0x35, 0xab, 0xc5, 0xa4, 0x43, 0xf0, 0x09, 0xe1, 0x0c, 0xcf, 0x09,
-	0x76, 0x48, 0xa4, 0xcc, 0x18, 0x48, 0xe0, 0x6f, 0x04, 0x8c, 0x7b, 0x58, 0x21, 0x9d, 0x06, 0xce,
-	0x58, 0x68, 0x34, 0x30, 0x41, 0x82, 0x0e, 0x02, 0x67, 0x2c, 0x8a, 0x1c, 0xb5, 0x44, 0x90, 0xd8,
-	0xe7, 0xb1, 0x7f, 0x21, 0xb4, 0xe9, 0x9a, 0x7d, 0x97, 0xfe, 0x16, 0x53, 0x76, 0xc8, 0x41, 0xc6,
-	0xdf, 0xb5, 0x24, 0xcb, 0xb8, 0x1a, 0x26, 0xb1, 0x89, 0x7b, 0xf9, 0x7f, 0x70, 0x07, 0xa7, 0x50,
-	0xd9, 0x90, 0xeb, 0x14, 0x55, 0xc2, 0x20, 0x79, 0xa6, 0xde, 0x22, 0x71, 0x32, 0x49, 0xf2, 0xbc,
-	0xe2, 0x2a, 0xc9, 0xbf, 0x4f, 0x8a, 0xec, 0x91, 0x7d, 0x72, 0x8e, 0x23, 0x87, 0xfe, 0x9a, 0xf8,
-	0x24, 0xc2, 0xec, 0x46, 0x1e, 0x70, 0x63, 0x1b, 0xb6, 0xea, 0xb8, 0x2b, 0xf9, 0xdf, 0x25, 0x8f,
-	0x47, 0x82, 0x61, 0x92, 0xd3, 0xd8, 0xf5, 0x9c, 0x1b, 0x11, 0xff, 0x65, 0xd1, 0xb8, 0x94, 0xb9,
-	0x8a, 0x9f, 0x5d, 0x58, 0x8e, 0x04, 0x88, 0x59, 0x94, 0x23, 0xa4, 0xbd, 0xfb, 0xa2, 0x79, 0x47,
-	0x1d, 0x08, 0x42, 0xde, 0xc3, 0xff, 0x23, 0x8d, 0x80, 0x84, 0xdb, 0x8d, 0x95, 0xc5, 0x0d, 0xe8,
-	0x4d, 0xc4, 0xb7, 0x84, 0xf8, 0x2e, 0x55, 0x72, 0x79, 0x74, 0xda, 0x41, 0x38, 0xb6, 0x88, 0x2d,
-	0xdf, 0x61, 0x71, 0xd5, 0x5d, 0xb3, 0xcf, 0x81, 0x47, 0xb6, 0x78, 0x86, 0xaf, 0x50, 0x23, 0xd3,
-	0x68, 0xc8, 0x1b, 0xa1, 0x6e, 0xe3, 0x0d, 0x6c, 0xe4, 0x4f, 0x9b, 0x3f, 0x4f, 0xd7, 0x32, 0xd2,
-	0xd8, 0x2a, 0x86, 0x41, 0xe1, 0x8d, 0xbb, 0x2c, 0xaa, 0xdd, 0xf8, 0x3d, 0xbf, 0x9e, 0x5e, 0x9b,
-	0x45, 0x87, 0xe4, 0x9b, 0x82, 0x6f, 0x8b, 0x6a, 0x5f, 0xa1, 0x39, 0x98, 0x2e, 0xf8, 0x41, 0x31,
-	0x74, 0x8b, 0x1d, 0xc4, 0x9f, 0xd3, 0xba, 0xa8, 0x30, 0xf8, 0xfb, 0xdd, 0xb8, 0x1e, 0x29, 0xb9,
-	0xc2, 0x1d, 0x8b, 0x66, 0x47, 0x89, 0xe5, 0xc3, 0xa2, 0x7a, 0x87, 0x64, 0xaf, 0xad, 0x7e, 0xe5,
-	0xc6, 0xc2, 0x96, 0x1a, 0x0b, 0x93, 0x71, 0xf7, 0x82, 0x8c, 0x45, 0xac, 0xb5, 0xe5, 0xb8, 0xfb,
-	0x25, 0x19, 0x1b, 0xc7, 0x85, 0x4c, 0x91, 0xaa, 0xa9, 0x9c, 0x43, 0xd0, 0xe6, 0x41, 0xaa, 0x4a,
-	0xb5, 0xf8, 0x46, 0x9b, 0x00, 0x2e, 0xb5, 0x1c, 0x71, 0xe7, 0x52, 0xa9, 0xae, 0xd9, 0x73, 0x55,
-	0x10, 0x38, 0xc6, 0x9f, 0x32, 0xa9, 0x77, 0xe0, 0x05, 0xa7, 0x37, 0x18, 0x95, 0x59, 0x2b, 0x5a,
-	0x39, 0x2b, 0xb2, 0x73, 0x6f, 0x3b, 0x3f, 0xf7, 0x66, 0x92, 0x28, 0xab, 0x8e, 0xba, 0x99, 0x9f,
-	0xc1, 0x06, 0x37, 0x58, 0x62, 0x88, 0x2e, 0xb9, 0xf9, 0x24, 0xf1, 0xef, 0x5b, 0x70, 0xbf, 0x9a,
-	0xb8, 0xc9, 0x34, 0xf1, 0x73, 0xd0, 0xd3, 0x6e, 0x9d, 0x3f, 0x29, 0x94, 0xe1, 0x51, 0x98, 0x3e,
-	0x2a, 0xf2, 0xed, 0x59, 0x57, 0xad, 0xfb, 0x8b, 0xe4, 0x3c, 0x79, 0x59, 0x4a, 0xad, 0x7e, 0xab,
-	0xd4, 0xea, 0x73, 0x01, 0x0e, 0x66, 0x75, 0x02, 0x64, 0xef, 0xb2, 0xee, 0x60, 0x56, 0x27, 0x20,
-	0x25, 0x16, 0x02, 0x64, 0xd4, 0xf4, 0x15, 0xbe, 0x10, 0xb0, 0x09, 0xa0, 0xda, 0x92, 0xd8, 0x4f,
-	0x46, 0x97, 0x9e, 0x6c, 0x4a, 0x62, 0xbf, 0xb6, 0xbb, 0xea, 0xd4, 0x76, 0x57, 0xf9, 0xeb, 0xef,
-	0x96, 0x5e, 0x88, 0x6f, 0x01, 0x9e, 0xb9, 0xf4, 0x42, 0x3a, 0x99, 0xb7, 0x73, 0x8e, 0x1b, 0xa9,
-	0xd9, 0x97, 0x7f, 0x72, 0x08, 0xf6, 0x3c, 0xe5, 0x3a, 0xfe, 0xc9, 0xc3, 0x37, 0xa6, 0xc4, 0x51,
-	0xde, 0x11, 0xdf, 0x1c, 0x76, 0x16, 0x11, 0xa2, 0x1c, 0x20, 0xbe, 0x8d, 0xbf, 0x68, 0xd0, 0xfb,
-	0x8a, 0x8c, 0x14, 0xe7, 0x2d, 0x80, 0x57, 0x41, 0x14, 0xc4, 0xcc, 0xf5, 0x89, 0xec, 0x3e, 0xe7,
-	0xcd, 0x0c, 0xe4, 0x7f, 0x97, 0x23, 0x52, 0x93, 0x78, 0x67, 0xca, 0x99, 0xe2, 0x9b, 0xc3, 0xce,
-	0x09, 0x0e, 0x95, 0xff, 0xc4, 0x37, 0x5a, 0x85, 0x79, 0xca, 0xb0, 0x7d, 0x21, 0x9c, 0xd5, 0x36,
-	0xe5, 0x8f, 0x27, 0xff, 0x5a, 0x83, 0x41, 0xb6, 0x5b, 0x40, 0xdf, 0x43, 0x3f, 0xb3, 0xa9, 0x42,
-	0x1f, 0x95, 0x17, 0x52, 0xe5, 0xcd, 0x97, 0xfe, 0x83, 0x19, 0x58, 0x2a, 0x31, 0xe6, 0x90, 0x0f,
-	0xcb, 0xa5, 0x75, 0x0f, 0xda, 0x2d, 0x53, 0xd7, 0x2d, 0x93, 0xf4, 0x47, 0x8d, 0x70, 0x53, 0x79,
-	0x0c, 0x56, 0x2a, 0xf6, 0x37, 0xe8, 0xd3, 0x19, 0x5c, 0x72, 0x3b, 0x24, 0xfd, 0xb3, 0x86, 0xd8,
-	0xa9, 0xd4, 0xd7, 0x80, 0xca, 0xcb, 0x1d, 0xf4, 0x68, 0x26, 0x9b, 0xc9, 0xf2, 0x48, 0xff, 0xb4,
-	0x19, 0x72, 0xad, 0xa1, 0x72, 0xed, 0x33, 0xd3, 0xd0, 0xdc, 0x62, 0x69, 0xa6, 0xa1, 0x85, 0x5d,
-	0xd2, 0x1c, 0xba, 0x80, 0xa5, 0xe2, 0x4a, 0x08, 0x3d, 0xac, 0x5b, 0x61, 0x96, 0x36, 0x4e, 0xfa,
-	0x6e, 0x13, 0xd4, 0x54, 0x18, 0x81, 0xdb, 0xf9, 0xb5, 0x0d, 0xfa, 0xa4, 0x4c, 0x5f, 0xb9, 0x84,
-	0xd2, 0x77, 0x66, 0x23, 0x66, 0x6d, 0x2a, 0xae, 0x72, 0xaa, 0x6c, 0xaa, 0xd9, 0x13, 0x55, 0xd9,
-	0x54, 0xb7, 0x19, 0x32, 0xe6, 0xd0, 0xef, 0x93, 0xfd, 0x40, 0x61, 0xc5, 0x81, 0xf6, 0xea, 0xd8,
-	0x54, 0xef, 0x58, 0xf4, 0xfd, 0xc6, 0xf8, 0x89, 0xec, 0xc7, 0x1a, 0xcf, 0xf5, 0xcc, 0xa6, 0xa3,
-	0x2a, 0xd7, 0xcb, 0xbb, 0x93, 0xaa, 0x5c, 0xaf, 0x5a, 0x97, 0xcc, 0xa1, 0x53, 0x58, 0xcc, 0xed,
-	0x3e, 0xd0, 0xc7, 0x75, 0x94, 0xf9, 0xa6, 0x49, 0xff, 0x64, 0x26, 0x5e, 0x2a, 0xc3, 0x4a, 0xaa,
-	0x97, 0x2a, 0x57, 0xb5, 0xca, 0xe5, 0xeb, 0xd5, 0xc7, 0xb3, 0xd0, 0x72, 0xa9, 0x5c, 0xda, 0x90,
-	0x54, 0xa6, 0x72, 0xdd, 0x06, 0xa6, 0x32, 0x95, 0xeb, 0x97, 0x2e, 0x73, 0xe8, 0x77, 0x00, 0x93,
-	0x2d, 0x06, 0xfa, 0xb0, 0x8e, 0x3a, 0x7b, 0xfb, 0x1f, 0x4d, 0x47, 0x4a, 0x59, 0xbf, 0x81, 0xd5,
-	0xaa, 0xe6, 0x02, 0x55, 0x24, 0xfe, 0x94, 0x0e, 0x46, 0xdf, 0x6b, 0x8a, 0x9e, 0x0a, 0xfe, 0x06,
-	0xba, 0xc9, 0x06, 0x02, 0x7d, 0x50, 0xa6, 0x2e, 0xec, 0x5c, 0x74, 0x63, 0x1a, 0x4a, 0x26, 0x80,
-	0x47, 0x49, 0xae, 0x4e, 0x56, 0x03, 0xf5, 0xb9, 0x5a, 0x5a, 0x62, 0xd4, 0xe7, 0x6a, 0x79, 0xd3,
-	0x20, 0xc4, 0xa5, 0xc1, 0x90, 0x9d, 0xa4, 0xeb, 0x83, 0xa1, 0x62, 0x51, 0x50, 0x1f, 0x0c, 0x95,
-	0xc3, 0xf9, 0x1c, 0xfa, 0x03, 0xac, 0x55, 0x0f, 0xd0, 0xa8, 0x36, 0xe3, 0x6b, 0x06, 0x79, 0xfd,
-	0x71, 0x73, 0x82, 0x54, 0xfc, 0xbb, 0xa4, 0x3e, 0x15, 0x06, 0xe8, 0xfa, 0xfa, 0x54, 0x3d, 0xc6,
-	0xeb, 0xfb, 0x8d, 0xf1, 0xcb, 0xa9, 0x97, 0x9d, 0x54, 0xeb, 0xbd, 0x5d, 0x31, 0x94, 0xd7, 0x7b,
-	0xbb, 0x72, 0xf8, 0x15, 0xf9, 0x51, 0x35, 0x85, 0x56, 0xe5, 0xc7, 0x94, 0x31, 0x59, 0xdf, 0x6b,
-	0x8a, 0x9e, 0x7b, 0xbe, 0xcb, 0x63, 0x26, 0x9a, 0xa9, 0x7f, 0xae, 0x32, 0x7f, 0xd6, 0x10, 0xbb,
-	0xfe, 0x76, 0x93, 0x4a, 0x3d, 0xd3, 0x80, 0x42, 0xc5, 0xde, 0x6f, 0x8c, 0x9f, 0xca, 0x0e, 0x93,
-	0xdd, 0x72, 0x66, 0x44, 0x44, 0xbb, 0x33, 0xf8, 0x64, 0x46, 0x5c, 0xfd, 0x51, 0x23, 0xdc, 0xaa,
-	0xec, 0xcd, 0x0e, 0x6d, 0xd3, 0xe2, 0xa9, 0x34, 0x69, 0x4e, 0x8b, 0xa7, 0x8a, 0x39, 0x70, 0xee,
-	0x74, 0x41, 0xfc, 0x81, 0xfc, 0xf9, 0x7f, 0x03, 0x00, 0x00, 0xff, 0xff, 0xc8, 0x44, 0x1a, 0xc5,
-	0x57, 0x1e, 0x00, 0x00,
+	// 2282 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x5a, 0xcb, 0x6f, 0xdc, 0xc6,
+	0x19, 0xef, 0x6a, 0x57, 0xda, 0xdd, 0x6f, 0xf5, 0x1c, 0xbd, 0x56, 0xd4, 0xc3, 0x0a, 0xe3, 0x38,
+	0x6b, 0x49, 0x96, 0xdd, 0x04, 0x6d, 0x93, 0x16, 0x68, 0x6a, 0xcb, 0x6e, 0x6a, 0xa4, 0x51, 0x1a,
+	0xca, 0x71, 0x52, 0x38, 0x00, 0x31, 0x22, 0x47, 0x16, 0x21, 0x2e, 0xc9, 0x70, 0x66, 0x65, 0xaf,
+	0xd1, 0x02, 0x45, 0xdd, 0x6b, 0xff, 0x80, 0x9e, 0xdb, 0x53, 0x0f, 0xbd, 0xf6, 0xaf, 0x2a, 0xd0,
+	0x63, 0x81, 0x5e, 0x8a, 0x79, 0x90, 0x4b, 0x2e, 0x49, 0xed, 0xa8, 0x36, 0xda, 0x1b, 0xf7, 0x9b,
+	0x6f, 0xbe, 0xd7, 0x7c, 0x8f, 0x99, 0x9f, 0x04, 0xcb, 0x97, 0xa1, 0x3f, 0xe8, 0x13, 0x9b, 0x92,
+	0xf8, 0x92, 0xc4, 0x87, 0x51, 0x1c, 0xb2, 0x10, 0x2d, 0xe6, 0x88, 0x76, 0x74, 0x6a, 0x3e, 0x03,
+	0xf4, 0x00, 0x33, 0xe7, 0xfc, 0x21, 0xf1, 0x09, 0x23, 0x16, 0xf9, 0x6e, 0x40, 0x28, 0x43, 0x1b,
+	0xd0, 0x3a, 0xf3, 0x7c, 0x62, 0x7b, 0x2e, 0xed, 0xd6, 0x76, 0xeb, 0xbd, 0xb6, 0xd5, 0xe4, 0xbf,
+	0x1f, 0xbb, 0x14, 0xed, 0xc1, 0x12, 0xbd, 0xf0, 0x22, 0xdb, 0x09, 0xc3, 0x0b, 0x8f, 0xd8, 0xce,
+	0x39, 0x71, 0x2e, 0xba, 0x53, 0xbb, 0xb5, 0x5e, 0xcb, 0x5a, 0xe0, 0x0b, 0x47, 0x82, 0x7e, 0xc4,
+	0xc9, 0xe6, 0x17, 0xb0, 0x9c, 0x13, 0x4e, 0xa3, 0x30, 0xa0, 0x04, 0x7d, 0x04, 0xcd, 0x98, 0xd0,
+	0x81, 0xcf, 0xa4, 0xf0, 0xce, 0x07, 0x3b, 0x87, 0xe3, 0x76, 0x1d, 0xa6, 0x5b, 0x06, 0x3e, 0xb3,
+	0x12, 0x76, 0xf3, 0x75, 0x0d, 0x66, 0xb3, 0x2b, 0x68, 0x1d, 0x9a, 0xca, 0xd0, 0x6e, 0x6d, 0xb7,
+	0xd6, 0x6b, 0x5b, 0x33, 0xd2, 0x4e, 0xb4, 0x06, 0x33, 0x94, 0x61, 0x36, 0xa0, 0xc2, 0xb6, 0x69,
+	0x4b, 0xfd, 0x42, 0x2b, 0x30, 0x4d, 0xe2, 0x38, 0x8c, 0xbb, 0x75, 0xc1, 0x2e, 0x7f, 0x20, 0x04,
+	0x0d, 0xea, 0xbd, 0x22, 0xdd, 0xc6, 0x6e, 0xad, 0x37, 0x67, 0x89, 0x6f, 0xd4, 0x85, 0xe6, 0x25,
+	0x89, 0xa9, 0x17, 0x06, 0xdd, 0x69, 0x41, 0x4e, 0x7e, 0x9a, 0x4d, 0x98, 0x7e, 0xd4, 0x8f, 0xd8,
+	0xd0, 0xfc, 0x11, 0x74, 0x9f, 0x62, 0x67, 0x30, 0xe8, 0x3f, 0x15, 0xe6, 0x0b, 0xa7, 0x93, 0x10,
+	0x6e, 0x42, 0x5b, 0x39, 0xa5, 0x6c, 0x9b, 0xb3, 0x5a, 0x92, 0xf0, 0xd8, 0x35, 0x7f, 0x06, 0x1b,
+	0x25, 0x1b, 0x55, 0x78, 0xde, 0x85, 0xb9, 0xe7, 0x38, 0x3e, 0xc5, 0xcf, 0x89, 0x1d, 0x63, 0xe6,
+	0x85, 0x62, 0x77, 0xcd, 0x9a, 0x55, 0x44, 0x8b, 0xd3, 0xcc, 0x67, 0x60, 0xe4, 0x24, 0x84, 0xfd,
+	0x08, 0x3b, 0x4c, 0x47, 0x39, 0xda, 0x85, 0x4e, 0x14, 0x13, 0xec, 0xfb, 0xa1, 0x83, 0x19, 0x11,
+	0xf1, 0xa9, 0x5b, 0x59, 0x92, 0xb9, 0x0d, 0x9b, 0xa5, 0xc2, 0xa5, 0x81, 0xe6, 0x47, 0x63, 0xd6,
+	0x87, 0xfd, 0xbe, 0xa7, 0xa5, 0xda, 0xdc, 0x02, 0xa3, 0x6c, 0xa7, 0x92, 0xfb, 0xf1, 0xd8, 0xaa,
+	0x4f, 0x70, 0x30, 0x88, 0xb4, 0x04, 0x8f, 0x5b, 0x9c, 0x6c, 0x4d, 0x25, 0xaf, 0xcb, 0xb4, 0x39,
+	0x0a, 0x7d, 0x9f, 0x38, 0xcc, 0x0b, 0x83, 0x44, 0xec, 0x0e, 0x80, 0x93, 0x12, 0x55, 0x12, 0x65,
+	0x28, 0xa6, 0x01, 0xdd, 0xe2, 0x56, 0x25, 0xf6, 0xaf, 0x35, 0x58, 0xbd, 0xaf, 0x82, 0x26, 0x15,
+	0x6b, 0x1d, 0x40, 0x5e, 0xe5, 0xd4, 0xb8, 0xca, 0xf1, 0x03, 0xaa, 0x17, 0x0e, 0x88, 0x73, 0xc4,
+	0x24, 0xf2, 0x3d, 0x07, 0x0b, 0x11, 0x0d, 0x21, 0x22, 0x4b, 0x42, 0x8b, 0x50, 0x67, 0xcc, 0x17,
+	0x99, 0xdb, 0xb6, 0xf8, 0xa7, 0xd9, 0x85, 0xb5, 0x71, 0x5b, 0x95, 0x1b, 0x3f, 0x84, 0x75, 0x49,
+	0x39, 0x19, 0x06, 0xce, 0x89, 0xa8, 0x13, 0xad, 0xa0, 0xff, 0xbb, 0x06, 0xdd, 0xe2, 0x46, 0x95,
+	0xc5, 0x6f, 0x1a, 0x81, 0xeb, 0xfa, 0x87, 0x6e, 0x40, 0x87, 0x61, 0xcf, 0xb7, 0xc3, 0xb3, 0x33,
+	0x4a, 0x58, 0x77, 0x66, 0xb7, 0xd6, 0x6b, 0x58, 0xc0, 0x49, 0x5f, 0x08, 0x0a, 0xba, 0x0d, 0x8b,
+	0x8e, 0xcc, 0x64, 0x3b, 0x26, 0x97, 0x9e, 0xa8, 0xec, 0xa6, 0x30, 0x6c, 0xc1, 0x49, 0x32, 0x5c,
+	0x92, 0x91, 0x09, 0x73, 0x9e, 0xfb, 0xd2, 0x16, 0xad, 0x45, 0x34, 0x86, 0x96, 0x90, 0xd6, 0xf1,
+	0xdc, 0x97, 0x3f, 0xf7, 0x7c, 0x72, 0xe2, 0xbd, 0x22, 0xe6, 0x53, 0xd8, 0x92, 0xce, 0x3f, 0x0e,
+	0x9c, 0x98, 0xf4, 0x49, 0xc0, 0xb0, 0x7f, 0x14, 0x46, 0x43, 0xad, 0x14, 0xd8, 0x80, 0x16, 0xf5,
+	0x02, 0x87, 0xd8, 0x81, 0x6c, 0x50, 0x0d, 0xab, 0x29, 0x7e, 0x1f, 0x53, 0xf3, 0x01, 0x6c, 0x57,
+	0xc8, 0x55, 0x91, 0x7d, 0x07, 0x66, 0x85, 0x61, 0x4e, 0x18, 0x30, 0x12, 0x30, 0x21, 0x7b, 0xd6,
+	0xea, 0x70, 0xda, 0x91, 0x24, 0x99, 0xdf, 0x07, 0x24, 0x65, 0x7c, 0x1e, 0x0e, 0x02, 0xbd, 0xd2,
+	0x5c, 0x85, 0xe5, 0xdc, 0x16, 0x95, 0x1b, 0x1f, 0xc2, 0x8a, 0x24, 0x7f, 0x15, 0xf4, 0xb5, 0x65,
+	0xad, 0xc3, 0xea, 0xd8, 0x26, 0x25, 0xed, 0xcb, 0x44, 0x49, 0x7e, 0xdc, 0x5c, 0x19, 0xaa, 0x6d,
+	0x80, 0x30, 0xf0, 0x87, 0x36, 0xe1, 0x2d, 0x57, 0x4d, 0x9a, 0x36, 0xa7, 0xc8, 0x1e, 0xbc, 0x06,
+	0x2b, 0x79, 0x91, 0x99, 0x26, 0x25, 0xfd, 0xc1, 0xf1, 0x85, 0x45, 0xb0, 0xcb, 0xb7, 0x68, 0x59,
+	0xff, 0x09, 0x18, 0x65, 0x3b, 0x47, 0xd1, 0x7f, 0x81, 0xa9, 0x1d, 0x2b, 0xba, 0xd8, 0xdd, 0xb2,
+	0x3a, 0x2f, 0x30, 0x4d, 0x58, 0x79, 0xcb, 0x50, 0x65, 0x21, 0xc6, 0xd9, 0xc3, 0x18, 0x7b, 0x49,
+	0xbb, 0x31, 0x5d, 0xd8, 0x28, 0x59, 0x1b, 0xc9, 0x56, 0x66, 0x39, 0x3c, 0x6c, 0xca, 0xb2, 0xce,
+	0xa5, 0x6a, 0x96, 0x83, 0x80, 0xa1, 0x9b, 0x30, 0x4f, 0x1c, 0x9b, 0x9e, 0xe3, 0xd8, 0x55, 0x4c,
+	0x53, 0x82, 0x69, 0x96, 0x38, 0x27, 0x9c, 0x28, 0xb8, 0xcc, 0x6f, 0x93, 0x38, 0x7f, 0x8d, 0xe3,
+	0xbe, 0x5e, 0x0b, 0x45, 0x3d, 0x58, 0x3c, 0x1d, 0x32, 0x42, 0xed, 0x88, 0xc4, 0x36, 0x25, 0x4e,
+	0x18, 0xb8, 0x6a, 0x36, 0xcc, 0x0b, 0xfa, 0xaf, 0x48, 0x7c, 0x22, 0xa8, 0xe6, 0xc7, 0xb0, 0x92,
+	0x97, 0x9e, 0x0d, 0x4d, 0xdc, 0x27, 0xae, 0x2d, 0x36, 0x08, 0x0d, 0x0d, 0xab, 0x23, 0x69, 0x0f,
+	0x38, 0xc9, 0xfc, 0x5b, 0x0d, 0x96, 0x92, 0xde, 0xaf, 0x59, 0x2a, 0xd7, 0xec, 0x15, 0xf5, 0xca,
+	0x5e, 0xd1, 0x18, 0xf5, 0x8a, 0x1e, 0x2c, 0xd2, 0x70, 0x10, 0x3b, 0xc4, 0x76, 0x31, 0xc3, 0x76,
+	0x10, 0xba, 0x44, 0xb5, 0x92, 0x79, 0x49, 0x7f, 0x88, 0x19, 0x3e, 0x0e, 0x5d, 0x62, 0x7e, 0x02,
+	0x28, 0x6b, 0xaf, 0xf2, 0xf4, 0x36, 0x2c, 0xf9, 0x98, 0x32, 0x1b, 0x47, 0x11, 0x09, 0x5c, 0x1b,
+	0x33, 0x5e, 0xc7, 0xd2, 0xdd, 0x79, 0xbe, 0x70, 0x5f, 0xd0, 0xef, 0xb3, 0x63, 0x6a, 0xfe, 0x65,
+	0x0a, 0x16, 0xf8, 0x5e, 0xde, 0x37, 0xb4, 0xfc, 0x5d, 0x84, 0x3a, 0x79, 0xc9, 0x94, 0xa3, 0xfc,
+	0x13, 0xdd, 0x85, 0x65, 0xd5, 0xa0, 0xbc, 0x30, 0x18, 0xf5, 0xae, 0xba, 0xd8, 0x88, 0x46, 0x4b,
+	0x69, 0xfb, 0xba, 0x01, 0x1d, 0xca, 0xc2, 0x28, 0x69, 0x85, 0x0d, 0xd9, 0x0a, 0x39, 0x49, 0xb5,
+	0xc2, 0x7c, 0x4c, 0xa7, 0x4b, 0x62, 0x3a, 0xeb, 0x51, 0x9b, 0x38, 0xb6, 0xb4, 0x4a, 0x34, 0xd3,
+	0x96, 0x05, 0x1e, 0x7d, 0xe4, 0xc8, 0x68, 0xa0, 0x7d, 0x40, 0x5e, 0x28, 0xce, 0x39, 0x9b, 0x2f,
+	0x4d, 0x91, 0x2f, 0x0b, 0x5e, 0xc8, 0x4f, 0x3b, 0x4d, 0x18, 0x9e, 0x18, 0x94, 0xe1, 0x98, 0x25,
+	0x06, 0xa9, 0x6e, 0x2a, 0x68, 0xd2, 0x22, 0xf3, 0x07, 0xb0, 0x38, 0x8a, 0x92, 0x7e, 0xa3, 0x7b,
+	0x5d, 0x4b, 0x66, 0xd7, 0x13, 0xec, 0xf9, 0x27, 0x24, 0x70, 0x49, 0xfc, 0x86, 0x0d, 0x18, 0xdd,
+	0x83, 0x15, 0xcf, 0xf5, 0x89, 0xcd, 0xbc, 0x3e, 0x09, 0x07, 0x4c, 0xf9, 0x46, 0x93, 0x78, 0xf3,
+	0xb5, 0x27, 0x72, 0x49, 0xba, 0x47, 0xcd, 0x3f, 0xa4, 0x83, 0x30, 0x6b, 0xc5, 0xe8, 0x3a, 0x17,
+	0x10, 0xc2, 0x05, 0x9e, 0x13, 0xec, 0x92, 0x58, 0xb9, 0x31, 0x2b, 0x89, 0xbf, 0x10, 0x34, 0x7e,
+	0x62, 0x8a, 0xe9, 0x34, 0x74, 0x65, 0x97, 0x9b, 0xb5, 0x40, 0x92, 0x1e, 0x84, 0xee, 0x50, 0x4c,
+	0x24, 0x6a, 0x8b, 0xa4, 0x73, 0xce, 0x07, 0xc1, 0x85, 0xb0, 0xa6, 0x65, 0x75, 0x3c, 0xfa, 0x4b,
+	0x4c, 0xd9, 0x11, 0x27, 0x99, 0x7f, 0xaf, 0xc1, 0xc6, 0xc8, 0x0c, 0x8b, 0x38, 0xc4, 0xbb, 0xfc,
+	0x3f, 0x84, 0x83, 0xef, 0x50, 0xd5, 0x95, 0xbb, 0xd6, 0xab, 0x02, 0x44, 0x72, 0x2d, 0xdb, 0x05,
+	0xcd, 0x4f, 0xc1, 0x28, 0x33, 0xfc, 0xfa, 0xd5, 0xf6, 0x25, 0xac, 0xf2, 0x36, 0x7c, 0x2c, 0x03,
+	0xe7, 0x87, 0xa7, 0x5a, 0xde, 0x6f, 0x42, 0x5b, 0x45, 0xdf, 0x73, 0x95, 0xfb, 0x2d, 0x49, 0x78,
+	0xec, 0x9a, 0xbf, 0xaf, 0xc1, 0xda, 0xb8, 0xcc, 0xff, 0xf9, 0xd1, 0xfe, 0x2e, 0xcd, 0x30, 0x69,
+	0x06, 0xd5, 0x6e, 0x9f, 0x65, 0xad, 0x6e, 0xaa, 0xac, 0xd5, 0xf1, 0x41, 0x9b, 0x46, 0x81, 0x1f,
+	0x6f, 0xbd, 0xd7, 0xb0, 0xda, 0x49, 0x18, 0xa8, 0xf9, 0x53, 0xd8, 0x28, 0xb1, 0x60, 0x54, 0xaa,
+	0x4e, 0x18, 0x79, 0xc4, 0xcd, 0x4f, 0x2e, 0x49, 0x4b, 0x66, 0x92, 0xba, 0xd7, 0x3c, 0x92, 0x93,
+	0x8a, 0x7e, 0x4a, 0x02, 0x12, 0x63, 0xf6, 0x56, 0xee, 0xcc, 0xe6, 0x2e, 0xec, 0x54, 0x49, 0x57,
+	0x17, 0x82, 0x67, 0xb0, 0x95, 0xe7, 0xb0, 0xc8, 0xe9, 0xc0, 0xf3, 0xdd, 0xb7, 0xa2, 0xfe, 0x33,
+	0xd8, 0xae, 0x10, 0xae, 0x02, 0xb4, 0x07, 0x4b, 0xb1, 0x20, 0x31, 0x35, 0xbc, 0x93, 0xa7, 0xf5,
+	0x9c, 0xb5, 0xa0, 0x16, 0xc4, 0x46, 0x1e, 0xe9, 0x7f, 0xa6, 0x75, 0x9c, 0x48, 0x7b, 0x6b, 0xc3,
+	0x72, 0x13, 0xda, 0x23, 0xf5, 0x75, 0xa1, 0xbe, 0x45, 0x95, 0x5e, 0x9e, 0x88, 0x4e, 0x18, 0x0d,
+	0x6d, 0xe2, 0xc8, 0xab, 0xaf, 0x28, 0xd8, 0x96, 0x38, 0xc5, 0xe1, 0x23, 0x47, 0xdc, 0x7c, 0xf5,
+	0x27, 0x67, 0xc5, 0x84, 0x98, 0x29, 0x9d, 0x10, 0xe2, 0x61, 0x58, 0xe2, 0xb1, 0x3a, 0xba, 0x17,
+	0xb0, 0x99, 0x5f, 0xbd, 0xc6, 0xf5, 0xf1, 0x4d, 0x22, 0x62, 0xee, 0xc0, 0x56, 0xb9, 0x62, 0x65,
+	0xd8, 0xe5, 0xb8, 0xd9, 0xda, 0xf7, 0xed, 0x37, 0xb3, 0x6b, 0x1b, 0x36, 0x4b, 0xf5, 0x2a, 0xb3,
+	0xbe, 0x19, 0x37, 0xfb, 0x1a, 0x97, 0xf7, 0xab, 0x15, 0xdf, 0x80, 0xed, 0x0a, 0xc9, 0x4a, 0xf5,
+	0x9f, 0xd2, 0x46, 0xa5, 0x38, 0x78, 0xeb, 0xd4, 0x1e, 0x41, 0x4a, 0xaf, 0xba, 0xd3, 0x36, 0x95,
+	0x5a, 0x0e, 0xe6, 0xa8, 0x9b, 0x83, 0x7c, 0x0b, 0xab, 0x5f, 0x39, 0xd8, 0xa6, 0xae, 0x60, 0x9b,
+	0x04, 0xba, 0xba, 0x20, 0x43, 0x91, 0x98, 0x0d, 0x09, 0x5d, 0x7d, 0x46, 0x86, 0xe6, 0x31, 0x6c,
+	0x94, 0x98, 0xa6, 0x0a, 0x14, 0x41, 0x83, 0x67, 0xb4, 0x6a, 0xe1, 0xe2, 0x9b, 0x77, 0x44, 0x8f,
+	0xda, 0xae, 0x38, 0x73, 0x37, 0x79, 0x7a, 0x78, 0x2a, 0x09, 0x5c, 0xf3, 0x8f, 0x99, 0x3a, 0xe5,
+	0x73, 0xe1, 0x2d, 0x66, 0x65, 0xd6, 0x8b, 0x7a, 0xce, 0x8b, 0x2c, 0x2e, 0xd5, 0xc8, 0xe3, 0x52,
+	0x99, 0x22, 0xca, 0x9a, 0xa3, 0x4e, 0xe6, 0xc7, 0xb0, 0xc9, 0x1d, 0x96, 0x1c, 0xe2, 0x15, 0xab,
+	0xff, 0xd2, 0xff, 0xc7, 0x14, 0x6c, 0x95, 0x6f, 0xd6, 0x79, 0xed, 0xff, 0x04, 0x8c, 0xf4, 0x35,
+	0xcd, 0x6f, 0x11, 0x94, 0xe1, 0x7e, 0x94, 0xde, 0x23, 0xe4, 0xbc, 0x5d, 0x57, 0x4f, 0xeb, 0x27,
+	0xc9, 0x7a, 0x72, 0x99, 0x28, 0x3c, 0xc5, 0xeb, 0x85, 0xa7, 0x38, 0x57, 0xe0, 0x62, 0x56, 0xa5,
+	0x40, 0x5e, 0x7f, 0xd7, 0x5d, 0xcc, 0xaa, 0x14, 0xa4, 0x9b, 0x85, 0x02, 0x99, 0x35, 0x1d, 0xc5,
+	0x2f, 0x14, 0x6c, 0x03, 0xa8, 0x9b, 0x28, 0x1f, 0x6e, 0x12, 0x5a, 0x68, 0xcb, 0x7b, 0xe8, 0x20,
+	0xa8, 0xbc, 0xa0, 0x37, 0x2b, 0x2f, 0xe8, 0xf9, 0xe3, 0x6f, 0x15, 0xc6, 0xc9, 0x37, 0x00, 0x0f,
+	0x3d, 0x7a, 0x21, 0x83, 0xcc, 0x5f, 0x04, 0xae, 0x17, 0x2b, 0x6c, 0x8a, 0x7f, 0x72, 0x0a, 0xf6,
+	0x7d, 0x15, 0x3a, 0xfe, 0xc9, 0xd3, 0x77, 0x40, 0x89, 0xab, 0xa2, 0x23, 0xbe, 0x39, 0xed, 0x2c,
+	0x26, 0x44, 0x05, 0x40, 0x7c, 0x9b, 0x7f, 0xae, 0x41, 0xfb, 0x73, 0xd2, 0x57, 0x92, 0x77, 0x00,
+	0x9e, 0x87, 0x71, 0x38, 0x60, 0x5e, 0xa0, 0xde, 0x6b, 0xd3, 0x56, 0x86, 0xf2, 0xdf, 0xeb, 0xe1,
+	0x34, 0x4a, 0xfc, 0x33, 0x15, 0x4c, 0xf1, 0xcd, 0x69, 0xe7, 0x04, 0x47, 0x2a, 0x7e, 0xe2, 0x9b,
+	0xe3, 0xb1, 0x94, 0x61, 0xe7, 0x42, 0x04, 0xab, 0x61, 0xc9, 0x1f, 0xf9, 0xc7, 0xfb, 0xd7, 0xb1,
+	0xc7, 0xf0, 0xa9, 0xde, 0xeb, 0x69, 0x54, 0x03, 0xf9, 0x9d, 0x32, 0x4d, 0x3f, 0xf8, 0x97, 0x01,
+	0xb3, 0xd9, 0x8b, 0x27, 0xfa, 0x16, 0x3a, 0x19, 0x84, 0x1a, 0xdd, 0x2c, 0x02, 0xd1, 0x45, 0x74,
+	0xdc, 0x78, 0x6f, 0x02, 0x97, 0x2a, 0xb8, 0xef, 0xa1, 0x00, 0x96, 0x0a, 0x30, 0x2f, 0xda, 0x2b,
+	0xee, 0xae, 0x02, 0x91, 0x8d, 0x7d, 0x2d, 0xde, 0x54, 0x1f, 0x83, 0xe5, 0x12, 0xdc, 0x16, 0x1d,
+	0x4c, 0x90, 0x92, 0xc3, 0x8e, 0x8d, 0x3b, 0x9a, 0xdc, 0xa9, 0xd6, 0xef, 0x00, 0x15, 0x41, 0x5d,
+	0xb4, 0x3f, 0x51, 0xcc, 0x08, 0x34, 0x36, 0x0e, 0xf4, 0x98, 0x2b, 0x1d, 0x95, 0x70, 0xef, 0x44,
+	0x47, 0x73, 0x80, 0xb2, 0x71, 0x47, 0x93, 0x3b, 0xd5, 0x7a, 0x01, 0x8b, 0xe3, 0x50, 0x30, 0xba,
+	0x5d, 0xf5, 0xa7, 0x8b, 0x02, 0xd2, 0x6c, 0xec, 0xe9, 0xb0, 0xa6, 0xca, 0x08, 0xcc, 0xe7, 0xe1,
+	0x5a, 0xf4, 0x7e, 0x71, 0x7f, 0x29, 0xf8, 0x6c, 0xf4, 0x26, 0x33, 0x66, 0x7d, 0x1a, 0x87, 0x70,
+	0xcb, 0x7c, 0xaa, 0xc0, 0x87, 0x8d, 0x3d, 0x1d, 0xd6, 0x54, 0xd9, 0x6f, 0x60, 0xb5, 0x14, 0xda,
+	0x44, 0x87, 0x55, 0x62, 0xca, 0xb1, 0x55, 0xe3, 0xae, 0x36, 0x7f, 0xa2, 0xfb, 0x5e, 0x8d, 0xd7,
+	0x7a, 0x06, 0xe1, 0x2c, 0xab, 0xf5, 0x22, 0x66, 0x6a, 0xbc, 0x37, 0x81, 0x2b, 0xf5, 0xed, 0x14,
+	0xe6, 0x72, 0x98, 0x27, 0xba, 0x55, 0xb5, 0x33, 0x7f, 0x19, 0x33, 0xde, 0x9f, 0xc8, 0x97, 0xea,
+	0xb0, 0x93, 0xee, 0xa5, 0xda, 0x55, 0xa5, 0x71, 0xf9, 0x7e, 0x75, 0x6b, 0x12, 0x5b, 0xae, 0x94,
+	0x0b, 0xd0, 0x67, 0x69, 0x29, 0x57, 0x41, 0xab, 0xc6, 0x81, 0x1e, 0x73, 0xb9, 0xca, 0xa4, 0x61,
+	0x5f, 0xad, 0x72, 0x6c, 0x20, 0x18, 0x07, 0x7a, 0xcc, 0xb9, 0xb6, 0x3c, 0x8e, 0xc1, 0xa2, 0xea,
+	0x4c, 0x2e, 0x80, 0xb8, 0xc6, 0xbe, 0x16, 0x6f, 0xf1, 0xd8, 0x24, 0x5e, 0x5a, 0x7d, 0x6c, 0x39,
+	0xb4, 0xd6, 0xb8, 0x35, 0x89, 0x2d, 0x55, 0xf0, 0x6b, 0x80, 0x11, 0x48, 0x89, 0xde, 0xad, 0xda,
+	0x97, 0xad, 0xa0, 0x9b, 0x57, 0x33, 0xa5, 0xa2, 0x5f, 0xc0, 0x4a, 0xd9, 0xc5, 0x0f, 0x95, 0x34,
+	0xcf, 0x2b, 0x6e, 0x97, 0xc6, 0xa1, 0x2e, 0x7b, 0xaa, 0xf8, 0x2b, 0x68, 0x25, 0x80, 0x20, 0x7a,
+	0xa7, 0xb8, 0x7b, 0x0c, 0x52, 0x35, 0xcc, 0xab, 0x58, 0x32, 0x4d, 0xa0, 0x0f, 0x8b, 0x23, 0xa4,
+	0x49, 0x22, 0x75, 0xd5, 0xfd, 0xae, 0x80, 0x29, 0x1a, 0x7b, 0x3a, 0xac, 0x19, 0x75, 0x69, 0x76,
+	0x67, 0x81, 0xad, 0xea, 0xec, 0x2e, 0xc1, 0xed, 0x8c, 0x03, 0x3d, 0xe6, 0x34, 0x70, 0xcf, 0x61,
+	0x3e, 0x0f, 0x57, 0x95, 0x0d, 0x8e, 0x52, 0x90, 0xcc, 0xe8, 0x4d, 0x66, 0xcc, 0xf8, 0x96, 0x96,
+	0x51, 0x06, 0x10, 0xaa, 0x2e, 0xa3, 0x22, 0x6e, 0x65, 0xec, 0x6b, 0xf1, 0xa6, 0x8e, 0xfd, 0x16,
+	0xd6, 0xca, 0x21, 0x1e, 0x54, 0x39, 0x0e, 0x2a, 0xa0, 0x26, 0xe3, 0x9e, 0xfe, 0x86, 0x54, 0xfd,
+	0x2b, 0x58, 0xcd, 0xf3, 0x28, 0x88, 0xa7, 0x7a, 0x78, 0x95, 0x03, 0x4d, 0xc6, 0x5d, 0x6d, 0xfe,
+	0x62, 0x93, 0xcc, 0xc2, 0x23, 0xd5, 0x69, 0x54, 0x02, 0x1b, 0x19, 0x07, 0x7a, 0xcc, 0xd9, 0xc2,
+	0x2f, 0x83, 0x3e, 0xca, 0x0a, 0xff, 0x0a, 0x6c, 0xc6, 0x38, 0xd4, 0x65, 0xcf, 0xdd, 0xed, 0x8a,
+	0xd8, 0x06, 0x9a, 0x68, 0x7f, 0x6e, 0x6c, 0xdf, 0xd1, 0xe4, 0xae, 0x3e, 0xdd, 0x64, 0x8c, 0x4f,
+	0x74, 0x60, 0x6c, 0x9c, 0xdf, 0xd5, 0xe6, 0x4f, 0x75, 0x47, 0xb0, 0x94, 0x63, 0xe1, 0x35, 0x57,
+	0x5d, 0x48, 0x45, 0x5c, 0xc5, 0xd8, 0xd7, 0xe2, 0x2d, 0x6b, 0x4b, 0x59, 0xa4, 0xe0, 0xaa, 0x7c,
+	0x2a, 0xc0, 0x1b, 0xc6, 0x81, 0x1e, 0x73, 0xa2, 0xf4, 0x74, 0x46, 0xfc, 0x07, 0xd2, 0x87, 0xff,
+	0x19, 0x00, 0x11, 0x4d, 0x1b, 0xb5, 0x98, 0x24, 0x00, 0x00,
 }
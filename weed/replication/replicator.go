@@ -41,6 +41,9 @@ func (r *Replicator) Replicate(ctx context.Context, key string, message *filer_p
 	key = newKey
 	if message.OldEntry != nil && message.NewEntry == nil {
 		glog.V(4).Infof("deleting %v", key)
+		if resolver, ok := r.sink.(sink.ConflictResolvingSink); ok {
+			return resolver.DeleteEntryIfNotNewer(ctx, key, message.OldEntry, message.DeleteChunks)
+		}
 		return r.sink.DeleteEntry(ctx, key, message.OldEntry.IsDirectory, message.DeleteChunks)
 	}
 	if message.OldEntry == nil && message.NewEntry != nil {
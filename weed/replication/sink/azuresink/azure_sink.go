@@ -95,8 +95,8 @@ func (g *AzureSink) CreateEntry(ctx context.Context, key string, entry *filer_pb
 		return nil
 	}
 
-	totalSize := filer2.TotalSize(entry.Chunks)
-	chunkViews := filer2.ViewFromChunks(entry.Chunks, 0, int(totalSize))
+	totalSize := filer2.FileSize(entry)
+	chunkViews := filer2.FillHoles(filer2.ViewFromChunks(entry.Chunks, 0, int(totalSize)), 0, int64(totalSize))
 
 	// Create a URL that references a to-be-created blob in your
 	// Azure Storage account's container.
@@ -109,13 +109,15 @@ func (g *AzureSink) CreateEntry(ctx context.Context, key string, entry *filer_pb
 
 	for _, chunk := range chunkViews {
 
-		fileUrl, err := g.filerSource.LookupFileId(ctx, chunk.FileId)
-		if err != nil {
-			return err
+		var fileUrl string
+		if !chunk.IsHole() {
+			if fileUrl, err = g.filerSource.LookupFileId(ctx, chunk.FileId); err != nil {
+				return err
+			}
 		}
 
 		var writeErr error
-		_, readErr := util.ReadUrlAsStream(fileUrl, chunk.Offset, int(chunk.Size), func(data []byte) {
+		readErr := filer2.ReadChunkView(fileUrl, chunk, func(data []byte) {
 			_, writeErr = appendBlobURL.AppendBlock(ctx, bytes.NewReader(data), azblob.AppendBlobAccessConditions{}, nil)
 		})
 
@@ -85,8 +85,8 @@ func (g *B2Sink) CreateEntry(ctx context.Context, key string, entry *filer_pb.En
 		return nil
 	}
 
-	totalSize := filer2.TotalSize(entry.Chunks)
-	chunkViews := filer2.ViewFromChunks(entry.Chunks, 0, int(totalSize))
+	totalSize := filer2.FileSize(entry)
+	chunkViews := filer2.FillHoles(filer2.ViewFromChunks(entry.Chunks, 0, int(totalSize)), 0, int64(totalSize))
 
 	bucket, err := g.client.Bucket(ctx, g.bucket)
 	if err != nil {
@@ -98,13 +98,15 @@ func (g *B2Sink) CreateEntry(ctx context.Context, key string, entry *filer_pb.En
 
 	for _, chunk := range chunkViews {
 
-		fileUrl, err := g.filerSource.LookupFileId(ctx, chunk.FileId)
-		if err != nil {
-			return err
+		var fileUrl string
+		if !chunk.IsHole() {
+			if fileUrl, err = g.filerSource.LookupFileId(ctx, chunk.FileId); err != nil {
+				return err
+			}
 		}
 
 		var writeErr error
-		_, readErr := util.ReadUrlAsStream(fileUrl, chunk.Offset, int(chunk.Size), func(data []byte) {
+		readErr := filer2.ReadChunkView(fileUrl, chunk, func(data []byte) {
 			_, err := writer.Write(data)
 			if err != nil {
 				writeErr = err
@@ -49,6 +49,8 @@ func (fs *FilerSink) replicateOneChunk(ctx context.Context, sourceChunk *filer_p
 		Mtime:        sourceChunk.Mtime,
 		ETag:         sourceChunk.ETag,
 		SourceFileId: sourceChunk.GetFileIdString(),
+		Compression:  sourceChunk.Compression,
+		CipherKey:    sourceChunk.CipherKey,
 	}, nil
 }
 
@@ -106,6 +108,10 @@ func (fs *FilerSink) fetchAndWrite(ctx context.Context, sourceChunk *filer_pb.Fi
 
 func (fs *FilerSink) withFilerClient(ctx context.Context, fn func(filer_pb.SeaweedFilerClient) error) error {
 
+	if fs.filerClient != nil {
+		return fs.filerClient.WithFilerClient(ctx, fn)
+	}
+
 	return util.WithCachedGrpcClient(ctx, func(grpcConnection *grpc.ClientConn) error {
 		client := filer_pb.NewSeaweedFilerClient(grpcConnection)
 		return fn(client)
@@ -24,6 +24,7 @@ type FilerSink struct {
 	ttlSec         int32
 	dataCenter     string
 	grpcDialOption grpc.DialOption
+	filerClient    filer2.FilerClient // the sink filer, if not dialed at grpcAddress
 }
 
 func init() {
@@ -97,18 +98,40 @@ func (fs *FilerSink) CreateEntry(ctx context.Context, key string, entry *filer_p
 			Name:      name,
 		}
 		glog.V(1).Infof("lookup: %v", lookupRequest)
+		newStamp := filer2.GetWriteStamp(entry.Extended)
+		var existingChunks []*filer_pb.FileChunk
+		extended := entry.Extended
 		if resp, err := client.LookupDirectoryEntry(ctx, lookupRequest); err == nil {
-			if filer2.ETag(resp.Entry.Chunks) == filer2.ETag(entry.Chunks) {
+			if newStamp.IsZero() && filer2.ContentETag(resp.Entry.Chunks) == filer2.ContentETag(entry.Chunks) {
 				glog.V(0).Infof("already replicated %s", key)
 				return nil
 			}
+			existingStamp := filer2.GetWriteStamp(resp.Entry.Extended)
+			if !newStamp.IsZero() && existingStamp == newStamp {
+				glog.V(0).Infof("already replicated %s", key)
+				return nil
+			}
+			// created on both clusters
+			if isConcurrentWrite(existingStamp, filer2.WriteStamp{}, newStamp) {
+				if existingStamp.After(newStamp) {
+					glog.V(0).Infof("keep %s written at %v, over %v", key, existingStamp, newStamp)
+					return fs.recordConflict(ctx, client, dir, resp.Entry, conflictWrite, newStamp)
+				}
+				glog.V(0).Infof("overwrite %s written at %v, with %v", key, existingStamp, newStamp)
+				extended = withConflict(entry.Extended, conflictWrite, existingStamp)
+			}
+			if filer2.ContentETag(resp.Entry.Chunks) == filer2.ContentETag(entry.Chunks) {
+				existingChunks = resp.Entry.Chunks
+			}
 		}
 
-		replicatedChunks, err := fs.replicateChunks(ctx, entry.Chunks)
-
-		if err != nil {
-			glog.V(0).Infof("replicate entry chunks %s: %v", key, err)
-			return fmt.Errorf("replicate entry chunks %s: %v", key, err)
+		replicatedChunks := existingChunks
+		if replicatedChunks == nil {
+			var err error
+			if replicatedChunks, err = fs.replicateChunks(ctx, entry.Chunks); err != nil {
+				glog.V(0).Infof("replicate entry chunks %s: %v", key, err)
+				return fmt.Errorf("replicate entry chunks %s: %v", key, err)
+			}
 		}
 
 		glog.V(0).Infof("replicated %s %+v ===> %+v", key, entry.Chunks, replicatedChunks)
@@ -120,7 +143,9 @@ func (fs *FilerSink) CreateEntry(ctx context.Context, key string, entry *filer_p
 				IsDirectory: entry.IsDirectory,
 				Attributes:  entry.Attributes,
 				Chunks:      replicatedChunks,
+				Extended:    extended,
 			},
+			IsReplicated: true,
 		}
 
 		glog.V(1).Infof("create: %v", request)
@@ -164,14 +189,35 @@ func (fs *FilerSink) UpdateEntry(ctx context.Context, key string, oldEntry *file
 
 	glog.V(0).Infof("oldEntry %+v, newEntry %+v, existingEntry: %+v", oldEntry, newEntry, existingEntry)
 
-	if existingEntry.Attributes.Mtime > newEntry.Attributes.Mtime {
+	existingStamp, newStamp := filer2.GetWriteStamp(existingEntry.Extended), filer2.GetWriteStamp(newEntry.Extended)
+	isConcurrent := isConcurrentWrite(existingStamp, filer2.GetWriteStamp(oldEntry.Extended), newStamp)
+
+	if !newStamp.IsZero() && existingStamp == newStamp {
+		// skip without saving, so the replicated write does not bounce back
+		glog.V(0).Infof("already replicated %s", key)
+		return true, nil
+	} else if !newStamp.IsZero() && existingStamp.After(newStamp) {
+		if !isConcurrent {
+			glog.V(0).Infof("late updates %s", key)
+			return true, nil
+		}
+		glog.V(0).Infof("keep %s written at %v, over %v", key, existingStamp, newStamp)
+		existingEntry.Extended = withConflict(existingEntry.Extended, conflictWrite, newStamp)
+	} else if newStamp.IsZero() && existingEntry.Attributes.Mtime > newEntry.Attributes.Mtime {
 		// skip if already changed
 		// this usually happens when the messages are not ordered
 		glog.V(0).Infof("late updates %s", key)
-	} else if filer2.ETag(newEntry.Chunks) == filer2.ETag(existingEntry.Chunks) {
+	} else if filer2.ContentETag(newEntry.Chunks) == filer2.ContentETag(existingEntry.Chunks) {
 		// skip if no change
 		// this usually happens when retrying the replication
 		glog.V(0).Infof("already replicated %s", key)
+	} else if isConcurrent {
+		// the changes are not applicable to the entry written on the sink, so all the chunks are replicated
+		replicatedChunks, err := fs.replicateChunks(ctx, newEntry.Chunks)
+		if err != nil {
+			return true, fmt.Errorf("replicte %s chunks error: %v", key, err)
+		}
+		existingEntry.Chunks = replicatedChunks
 	} else {
 		// find out what changed
 		deletedChunks, newChunks := compareChunks(oldEntry, newEntry)
@@ -190,12 +236,23 @@ func (fs *FilerSink) UpdateEntry(ctx context.Context, key string, oldEntry *file
 		existingEntry.Chunks = append(existingEntry.Chunks, replicatedChunks...)
 	}
 
+	if !newStamp.IsZero() && newStamp.After(existingStamp) {
+		// the last writer wins, with its attributes and write stamp
+		existingEntry.Attributes = newEntry.Attributes
+		existingEntry.Extended = newEntry.Extended
+		if isConcurrent {
+			glog.V(0).Infof("overwrite %s written at %v, with %v", key, existingStamp, newStamp)
+			existingEntry.Extended = withConflict(newEntry.Extended, conflictWrite, existingStamp)
+		}
+	}
+
 	// save updated meta data
 	return true, fs.withFilerClient(ctx, func(client filer_pb.SeaweedFilerClient) error {
 
 		request := &filer_pb.UpdateEntryRequest{
-			Directory: newParentPath,
-			Entry:     existingEntry,
+			Directory:    newParentPath,
+			Entry:        existingEntry,
+			IsReplicated: true,
 		}
 
 		if _, err := client.UpdateEntry(ctx, request); err != nil {
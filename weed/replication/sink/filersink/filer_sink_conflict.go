@@ -0,0 +1,89 @@
+package filersink
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+)
+
+// Replicating both ways between the clusters, the entries written on both clusters concurrently
+// are resolved by the write stamps, where the last writer wins. The kept entry records the lost
+// write or deletion, listed by "fs.conflicts" until the entry is written again.
+
+const (
+	conflictWrite  = "write"
+	conflictDelete = "delete"
+)
+
+// isConcurrentWrite tells whether the existing entry on the sink was written on another cluster
+// than the new entry, and not seen by the source when writing the new entry over its old entry
+func isConcurrentWrite(existingStamp, oldStamp, newStamp filer2.WriteStamp) bool {
+	return !existingStamp.IsZero() && !newStamp.IsZero() &&
+		existingStamp.ClusterId != newStamp.ClusterId && existingStamp != oldStamp
+}
+
+// isConcurrentDelete tells whether the existing entry on the sink was written after the deleted entry,
+// on any cluster, so the source deleted it without seeing the existing entry
+func isConcurrentDelete(existingStamp, oldStamp filer2.WriteStamp) bool {
+	return existingStamp != oldStamp && existingStamp.After(oldStamp)
+}
+
+func withConflict(extended map[string][]byte, op string, lost filer2.WriteStamp) map[string][]byte {
+	withConflict := make(map[string][]byte, len(extended)+1)
+	for k, v := range extended {
+		withConflict[k] = v
+	}
+	withConflict[filer2.ExtendedWriteConflict] = []byte(fmt.Sprintf("%s %v", op, lost))
+	return withConflict
+}
+
+// recordConflict keeps the existing entry with its write stamp, recording the lost write
+func (fs *FilerSink) recordConflict(ctx context.Context, client filer_pb.SeaweedFilerClient, dir string, entry *filer_pb.Entry, op string, lost filer2.WriteStamp) error {
+	entry.Extended = withConflict(entry.Extended, op, lost)
+	if _, err := client.UpdateEntry(ctx, &filer_pb.UpdateEntryRequest{
+		Directory:    dir,
+		Entry:        entry,
+		IsReplicated: true,
+	}); err != nil {
+		return fmt.Errorf("record conflict on %s/%s: %v", dir, entry.Name, err)
+	}
+	return nil
+}
+
+// DeleteEntryIfNotNewer deletes the entry, unless written on the sink cluster without seeing the deleted entry
+func (fs *FilerSink) DeleteEntryIfNotNewer(ctx context.Context, key string, oldEntry *filer_pb.Entry, deleteIncludeChunks bool) error {
+
+	oldStamp := filer2.GetWriteStamp(oldEntry.Extended)
+	if oldStamp.IsZero() {
+		return fs.DeleteEntry(ctx, key, oldEntry.IsDirectory, deleteIncludeChunks)
+	}
+
+	dir, name := filer2.FullPath(key).DirAndName()
+	found, kept := false, false
+	err := fs.withFilerClient(ctx, func(client filer_pb.SeaweedFilerClient) error {
+		resp, lookupErr := client.LookupDirectoryEntry(ctx, &filer_pb.LookupDirectoryEntryRequest{
+			Directory: dir,
+			Name:      name,
+		})
+		if lookupErr != nil {
+			return nil
+		}
+		found = true
+		existingStamp := filer2.GetWriteStamp(resp.Entry.Extended)
+		if !isConcurrentDelete(existingStamp, oldStamp) {
+			return nil
+		}
+		kept = true
+		glog.V(0).Infof("keep %s written at %v, over the deletion of %v", key, existingStamp, oldStamp)
+		return fs.recordConflict(ctx, client, dir, resp.Entry, conflictDelete, oldStamp)
+	})
+	if err != nil || !found || kept {
+		// not found if already deleted
+		return err
+	}
+
+	return fs.DeleteEntry(ctx, key, oldEntry.IsDirectory, deleteIncludeChunks)
+}
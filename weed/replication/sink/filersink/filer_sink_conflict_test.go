@@ -0,0 +1,202 @@
+package filersink
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+)
+
+type fakeFiler struct {
+	filer_pb.SeaweedFilerClient
+	entries map[string]*filer_pb.Entry
+}
+
+func (f *fakeFiler) WithFilerClient(ctx context.Context, fn func(filer_pb.SeaweedFilerClient) error) error {
+	return fn(f)
+}
+
+func (f *fakeFiler) LookupDirectoryEntry(ctx context.Context, in *filer_pb.LookupDirectoryEntryRequest, opts ...grpc.CallOption) (*filer_pb.LookupDirectoryEntryResponse, error) {
+	entry, found := f.entries[string(filer2.NewFullPath(in.Directory, in.Name))]
+	if !found {
+		return nil, filer2.ErrNotFound
+	}
+	return &filer_pb.LookupDirectoryEntryResponse{Entry: entry}, nil
+}
+
+func (f *fakeFiler) CreateEntry(ctx context.Context, in *filer_pb.CreateEntryRequest, opts ...grpc.CallOption) (*filer_pb.CreateEntryResponse, error) {
+	f.entries[string(filer2.NewFullPath(in.Directory, in.Entry.Name))] = in.Entry
+	return &filer_pb.CreateEntryResponse{}, nil
+}
+
+func (f *fakeFiler) UpdateEntry(ctx context.Context, in *filer_pb.UpdateEntryRequest, opts ...grpc.CallOption) (*filer_pb.UpdateEntryResponse, error) {
+	f.entries[string(filer2.NewFullPath(in.Directory, in.Entry.Name))] = in.Entry
+	return &filer_pb.UpdateEntryResponse{}, nil
+}
+
+func (f *fakeFiler) DeleteEntry(ctx context.Context, in *filer_pb.DeleteEntryRequest, opts ...grpc.CallOption) (*filer_pb.DeleteEntryResponse, error) {
+	delete(f.entries, string(filer2.NewFullPath(in.Directory, in.Name)))
+	return &filer_pb.DeleteEntryResponse{}, nil
+}
+
+var testChunks = []*filer_pb.FileChunk{{FileId: "3,01637037d6", Size: 10, ETag: "abc"}}
+
+func stampedEntry(stamp filer2.WriteStamp, mtime int64) *filer_pb.Entry {
+	return &filer_pb.Entry{
+		Name:       "f",
+		Attributes: &filer_pb.FuseAttributes{Mtime: mtime},
+		Chunks:     testChunks,
+		Extended:   filer2.SetWriteStamp(nil, stamp),
+	}
+}
+
+func newTestSink(existing *filer_pb.Entry) (*FilerSink, *fakeFiler) {
+	f := &fakeFiler{entries: make(map[string]*filer_pb.Entry)}
+	if existing != nil {
+		f.entries["/d/f"] = existing
+	}
+	return &FilerSink{filerClient: f}, f
+}
+
+func conflictOf(t *testing.T, f *fakeFiler) string {
+	entry, found := f.entries["/d/f"]
+	if !found {
+		t.Fatalf("entry deleted")
+	}
+	return string(entry.Extended[filer2.ExtendedWriteConflict])
+}
+
+func TestCreateEntryConflict(t *testing.T) {
+	ctx := context.Background()
+	east := filer2.WriteStamp{TsNs: 100, ClusterId: "east"}
+	west := filer2.WriteStamp{TsNs: 200, ClusterId: "west"}
+
+	// the existing entry written later on the sink is kept
+	fs, f := newTestSink(stampedEntry(west, 2))
+	if err := fs.CreateEntry(ctx, "/d/f", stampedEntry(east, 1)); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if got := filer2.GetWriteStamp(f.entries["/d/f"].Extended); got != west {
+		t.Errorf("kept %v, expected %v", got, west)
+	}
+	if conflict := conflictOf(t, f); conflict != "write 100@east" {
+		t.Errorf("conflict %q", conflict)
+	}
+
+	// the replicated entry written later wins
+	fs, f = newTestSink(stampedEntry(east, 1))
+	if err := fs.CreateEntry(ctx, "/d/f", stampedEntry(west, 2)); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if got := filer2.GetWriteStamp(f.entries["/d/f"].Extended); got != west {
+		t.Errorf("kept %v, expected %v", got, west)
+	}
+	if conflict := conflictOf(t, f); conflict != "write 100@east" {
+		t.Errorf("conflict %q", conflict)
+	}
+
+	// the entry already replicated is not written again
+	fs, f = newTestSink(stampedEntry(west, 2))
+	if err := fs.CreateEntry(ctx, "/d/f", stampedEntry(west, 2)); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if conflict := conflictOf(t, f); conflict != "" {
+		t.Errorf("unexpected conflict %q", conflict)
+	}
+}
+
+func TestUpdateEntryConflict(t *testing.T) {
+	ctx := context.Background()
+	base := filer2.WriteStamp{TsNs: 100, ClusterId: "east"}
+	west := filer2.WriteStamp{TsNs: 200, ClusterId: "west"}
+	east := filer2.WriteStamp{TsNs: 300, ClusterId: "east"}
+
+	// updated on both clusters from the same entry, the later update on the source wins
+	fs, f := newTestSink(stampedEntry(west, 2))
+	if _, err := fs.UpdateEntry(ctx, "/d/f", stampedEntry(base, 1), "/d", stampedEntry(east, 3), true); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if got := filer2.GetWriteStamp(f.entries["/d/f"].Extended); got != east {
+		t.Errorf("kept %v, expected %v", got, east)
+	}
+	if conflict := conflictOf(t, f); conflict != "write 200@west" {
+		t.Errorf("conflict %q", conflict)
+	}
+
+	// the later update on the sink is kept
+	fs, f = newTestSink(stampedEntry(east, 3))
+	if _, err := fs.UpdateEntry(ctx, "/d/f", stampedEntry(base, 1), "/d", stampedEntry(west, 2), true); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if got := filer2.GetWriteStamp(f.entries["/d/f"].Extended); got != east {
+		t.Errorf("kept %v, expected %v", got, east)
+	}
+	if conflict := conflictOf(t, f); conflict != "write 200@west" {
+		t.Errorf("conflict %q", conflict)
+	}
+
+	// the source saw the existing entry, so it is a plain update
+	fs, f = newTestSink(stampedEntry(west, 2))
+	if _, err := fs.UpdateEntry(ctx, "/d/f", stampedEntry(west, 2), "/d", stampedEntry(east, 3), true); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if got := filer2.GetWriteStamp(f.entries["/d/f"].Extended); got != east {
+		t.Errorf("kept %v, expected %v", got, east)
+	}
+	if conflict := conflictOf(t, f); conflict != "" {
+		t.Errorf("unexpected conflict %q", conflict)
+	}
+}
+
+func TestDeleteEntryIfNotNewer(t *testing.T) {
+	ctx := context.Background()
+	old := filer2.WriteStamp{TsNs: 100, ClusterId: "east"}
+
+	// deleted without a conflict if not changed since
+	fs, f := newTestSink(stampedEntry(old, 1))
+	if err := fs.DeleteEntryIfNotNewer(ctx, "/d/f", stampedEntry(old, 1), true); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, found := f.entries["/d/f"]; found {
+		t.Errorf("entry not deleted")
+	}
+
+	// written later on the sink cluster
+	later := filer2.WriteStamp{TsNs: 200, ClusterId: "west"}
+	fs, f = newTestSink(stampedEntry(later, 2))
+	if err := fs.DeleteEntryIfNotNewer(ctx, "/d/f", stampedEntry(old, 1), true); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if conflict := conflictOf(t, f); conflict != "delete 100@east" {
+		t.Errorf("conflict %q", conflict)
+	}
+
+	// written later on the same cluster as the deleted entry, not yet seen by the source
+	sameCluster := filer2.WriteStamp{TsNs: 200, ClusterId: "east"}
+	fs, f = newTestSink(stampedEntry(sameCluster, 2))
+	if err := fs.DeleteEntryIfNotNewer(ctx, "/d/f", stampedEntry(old, 1), true); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if conflict := conflictOf(t, f); conflict != "delete 100@east" {
+		t.Errorf("conflict %q", conflict)
+	}
+
+	// written before the deleted entry
+	earlier := filer2.WriteStamp{TsNs: 50, ClusterId: "west"}
+	fs, f = newTestSink(stampedEntry(earlier, 0))
+	if err := fs.DeleteEntryIfNotNewer(ctx, "/d/f", stampedEntry(old, 1), true); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, found := f.entries["/d/f"]; found {
+		t.Errorf("earlier entry not deleted")
+	}
+
+	// already deleted
+	fs, _ = newTestSink(nil)
+	if err := fs.DeleteEntryIfNotNewer(ctx, "/d/f", stampedEntry(old, 1), true); err != nil {
+		t.Fatalf("delete missing entry: %v", err)
+	}
+}
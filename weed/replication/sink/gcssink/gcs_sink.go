@@ -89,19 +89,22 @@ func (g *GcsSink) CreateEntry(ctx context.Context, key string, entry *filer_pb.E
 		return nil
 	}
 
-	totalSize := filer2.TotalSize(entry.Chunks)
-	chunkViews := filer2.ViewFromChunks(entry.Chunks, 0, int(totalSize))
+	totalSize := filer2.FileSize(entry)
+	chunkViews := filer2.FillHoles(filer2.ViewFromChunks(entry.Chunks, 0, int(totalSize)), 0, int64(totalSize))
 
 	wc := g.client.Bucket(g.bucket).Object(key).NewWriter(ctx)
 
 	for _, chunk := range chunkViews {
 
-		fileUrl, err := g.filerSource.LookupFileId(ctx, chunk.FileId)
-		if err != nil {
-			return err
+		var fileUrl string
+		var err error
+		if !chunk.IsHole() {
+			if fileUrl, err = g.filerSource.LookupFileId(ctx, chunk.FileId); err != nil {
+				return err
+			}
 		}
 
-		_, err = util.ReadUrlAsStream(fileUrl, chunk.Offset, int(chunk.Size), func(data []byte) {
+		err = filer2.ReadChunkView(fileUrl, chunk, func(data []byte) {
 			wc.Write(data)
 		})
 
@@ -17,6 +17,12 @@ type ReplicationSink interface {
 	SetSourceFiler(s *source.FilerSource)
 }
 
+// ConflictResolvingSink deletes the entry only if not written on the sink without seeing the deleted entry,
+// for the sinks also replicated back to the source
+type ConflictResolvingSink interface {
+	DeleteEntryIfNotNewer(ctx context.Context, key string, oldEntry *filer_pb.Entry, deleteIncludeChunks bool) error
+}
+
 var (
 	Sinks []ReplicationSink
 )
@@ -102,8 +102,8 @@ func (s3sink *S3Sink) CreateEntry(ctx context.Context, key string, entry *filer_
 		return err
 	}
 
-	totalSize := filer2.TotalSize(entry.Chunks)
-	chunkViews := filer2.ViewFromChunks(entry.Chunks, 0, int(totalSize))
+	totalSize := filer2.FileSize(entry)
+	chunkViews := filer2.FillHoles(filer2.ViewFromChunks(entry.Chunks, 0, int(totalSize)), 0, int64(totalSize))
 
 	var parts []*s3.CompletedPart
 	var wg sync.WaitGroup
@@ -157,11 +157,23 @@ func (s3sink *S3Sink) uploadPartCopy(key, uploadId string, partId int64, copySou
 }
 
 func (s3sink *S3Sink) buildReadSeeker(ctx context.Context, chunk *filer2.ChunkView) (io.ReadSeeker, error) {
+	if chunk.IsHole() {
+		return bytes.NewReader(make([]byte, chunk.Size)), nil
+	}
 	fileUrl, err := s3sink.filerSource.LookupFileId(ctx, chunk.FileId)
 	if err != nil {
 		return nil, err
 	}
 	buf := make([]byte, chunk.Size)
+	if chunk.Compression != "" || len(chunk.CipherKey) > 0 {
+		err = filer2.ReadChunkView(fileUrl, chunk, func(data []byte) {
+			copy(buf, data)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return bytes.NewReader(buf), nil
+	}
 	util.ReadUrl(fileUrl, chunk.Offset, int(chunk.Size), buf, true)
 	return bytes.NewReader(buf), nil
 }
@@ -4,9 +4,9 @@ import (
 	"context"
 
 	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/notification"
 	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
 	"github.com/chrislusf/seaweedfs/weed/util"
-	"github.com/golang/protobuf/proto"
 	"gocloud.dev/pubsub"
 	_ "gocloud.dev/pubsub/awssnssqs"
 	_ "gocloud.dev/pubsub/azuresb"
@@ -20,7 +20,8 @@ func init() {
 }
 
 type GoCDKPubSubInput struct {
-	sub *pubsub.Subscription
+	sub    *pubsub.Subscription
+	format string
 }
 
 func (k *GoCDKPubSubInput) GetName() string {
@@ -30,6 +31,10 @@ func (k *GoCDKPubSubInput) GetName() string {
 func (k *GoCDKPubSubInput) Initialize(config util.Configuration) error {
 	subURL := config.GetString("sub_url")
 	glog.V(0).Infof("notification.gocdk_pub_sub.sub_url: %v", subURL)
+	k.format = config.GetString("message_format")
+	if err := notification.CheckMessageFormat(k.format); err != nil {
+		return err
+	}
 	sub, err := pubsub.OpenSubscription(context.Background(), subURL)
 	if err != nil {
 		return err
@@ -40,9 +45,11 @@ func (k *GoCDKPubSubInput) Initialize(config util.Configuration) error {
 
 func (k *GoCDKPubSubInput) ReceiveMessage() (key string, message *filer_pb.EventNotification, err error) {
 	msg, err := k.sub.Receive(context.Background())
+	if err != nil {
+		return "", nil, err
+	}
 	key = msg.Metadata["key"]
-	message = &filer_pb.EventNotification{}
-	err = proto.Unmarshal(msg.Body, message)
+	_, message, err = notification.DecodeMessage(k.format, msg.Body)
 	if err != nil {
 		return "", nil, err
 	}
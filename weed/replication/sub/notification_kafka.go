@@ -9,9 +9,9 @@ import (
 
 	"github.com/Shopify/sarama"
 	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/notification"
 	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
 	"github.com/chrislusf/seaweedfs/weed/util"
-	"github.com/golang/protobuf/proto"
 )
 
 func init() {
@@ -20,6 +20,7 @@ func init() {
 
 type KafkaInput struct {
 	topic       string
+	format      string
 	consumer    sarama.Consumer
 	messageChan chan *sarama.ConsumerMessage
 }
@@ -31,6 +32,10 @@ func (k *KafkaInput) GetName() string {
 func (k *KafkaInput) Initialize(configuration util.Configuration) error {
 	glog.V(0).Infof("replication.notification.kafka.hosts: %v\n", configuration.GetStringSlice("hosts"))
 	glog.V(0).Infof("replication.notification.kafka.topic: %v\n", configuration.GetString("topic"))
+	k.format = configuration.GetString("message_format")
+	if err := notification.CheckMessageFormat(k.format); err != nil {
+		return err
+	}
 	return k.initialize(
 		configuration.GetStringSlice("hosts"),
 		configuration.GetString("topic"),
@@ -101,11 +106,9 @@ func (k *KafkaInput) ReceiveMessage() (key string, message *filer_pb.EventNotifi
 
 	msg := <-k.messageChan
 
-	key = string(msg.Key)
-	message = &filer_pb.EventNotification{}
-	err = proto.Unmarshal(msg.Value, message)
+	_, message, err = notification.DecodeMessage(k.format, msg.Value)
 
-	return
+	return string(msg.Key), message, err
 }
 
 type KafkaProgress struct {
@@ -25,12 +25,20 @@ type InitiateMultipartUploadResult struct {
 func (s3a *S3ApiServer) createMultipartUpload(ctx context.Context, input *s3.CreateMultipartUploadInput) (output *InitiateMultipartUploadResult, code ErrorCode) {
 	uploadId, _ := uuid.NewV4()
 	uploadIdString := uploadId.String()
+	lifecycle := s3a.bucketLifecycle(ctx, *input.Bucket)
 
 	if err := s3a.mkdir(ctx, s3a.genUploadsFolder(*input.Bucket), uploadIdString, func(entry *filer_pb.Entry) {
+		// the incomplete upload is aborted by deleting the expired upload folder with its parts
+		if lifecycle != nil {
+			entry.Attributes.ExpireAt = expireAt(lifecycle.abortDays(*input.Key))
+		}
 		if entry.Extended == nil {
 			entry.Extended = make(map[string][]byte)
 		}
 		entry.Extended["key"] = []byte(*input.Key)
+		if input.ServerSideEncryption != nil {
+			entry.Extended[sseKey] = []byte(*input.ServerSideEncryption)
+		}
 	}); err != nil {
 		glog.Errorf("NewMultipartUpload error: %v", err)
 		return nil, ErrInternalError
@@ -56,6 +64,11 @@ func (s3a *S3ApiServer) completeMultipartUpload(ctx context.Context, input *s3.C
 
 	uploadDirectory := s3a.genUploadsFolder(*input.Bucket) + "/" + *input.UploadId
 
+	uploadEntry, code := s3a.lookupUploadEntry(ctx, *input.Bucket, *input.UploadId)
+	if code != ErrNone {
+		return nil, code
+	}
+
 	entries, err := s3a.list(ctx, uploadDirectory, "", "", false, 0)
 	if err != nil {
 		glog.Errorf("completeMultipartUpload %s %s error: %v", *input.Bucket, *input.UploadId, err)
@@ -69,11 +82,13 @@ func (s3a *S3ApiServer) completeMultipartUpload(ctx context.Context, input *s3.C
 		if strings.HasSuffix(entry.Name, ".part") && !entry.IsDirectory {
 			for _, chunk := range entry.Chunks {
 				p := &filer_pb.FileChunk{
-					FileId: chunk.GetFileIdString(),
-					Offset: offset,
-					Size:   chunk.Size,
-					Mtime:  chunk.Mtime,
-					ETag:   chunk.ETag,
+					FileId:      chunk.GetFileIdString(),
+					Offset:      offset,
+					Size:        chunk.Size,
+					Mtime:       chunk.Mtime,
+					ETag:        chunk.ETag,
+					Compression: chunk.Compression,
+					CipherKey:   chunk.CipherKey,
 				}
 				finalParts = append(finalParts, p)
 				offset += int64(chunk.Size)
@@ -91,7 +106,15 @@ func (s3a *S3ApiServer) completeMultipartUpload(ctx context.Context, input *s3.C
 	}
 	dirName = fmt.Sprintf("%s/%s/%s", s3a.option.BucketsPath, *input.Bucket, dirName)
 
-	err = s3a.mkFile(ctx, dirName, entryName, finalParts)
+	lifecycle := s3a.bucketLifecycle(ctx, *input.Bucket)
+	err = s3a.mkFile(ctx, dirName, entryName, finalParts, func(entry *filer_pb.Entry) {
+		if lifecycle != nil {
+			entry.Attributes.ExpireAt = expireAt(lifecycle.expirationDays(*input.Key))
+		}
+		if sse, found := uploadEntry.Extended[sseKey]; found {
+			entry.Extended = map[string][]byte{sseKey: sse}
+		}
+	})
 
 	if err != nil {
 		glog.Errorf("completeMultipartUpload %s/%s error: %v", dirName, entryName, err)
@@ -101,9 +124,9 @@ func (s3a *S3ApiServer) completeMultipartUpload(ctx context.Context, input *s3.C
 	output = &CompleteMultipartUploadResult{
 		CompleteMultipartUploadOutput: s3.CompleteMultipartUploadOutput{
 			Location: aws.String(fmt.Sprintf("http://%s%s/%s", s3a.option.Filer, dirName, entryName)),
-			Bucket: input.Bucket,
-			ETag:   aws.String("\"" + filer2.ETag(finalParts) + "\""),
-			Key:    objectKey(input.Key),
+			Bucket:   input.Bucket,
+			ETag:     aws.String("\"" + filer2.ETag(finalParts) + "\""),
+			Key:      objectKey(input.Key),
 		},
 	}
 
@@ -114,6 +137,26 @@ func (s3a *S3ApiServer) completeMultipartUpload(ctx context.Context, input *s3.C
 	return
 }
 
+// lookupUploadEntry finds the directory of the multipart upload, with the object key and encryption
+func (s3a *S3ApiServer) lookupUploadEntry(ctx context.Context, bucket, uploadID string) (entry *filer_pb.Entry, code ErrorCode) {
+	err := s3a.withFilerClient(ctx, func(client filer_pb.SeaweedFilerClient) error {
+		resp, err := client.LookupDirectoryEntry(ctx, &filer_pb.LookupDirectoryEntryRequest{
+			Directory: s3a.genUploadsFolder(bucket),
+			Name:      uploadID,
+		})
+		if err != nil {
+			return err
+		}
+		entry = resp.Entry
+		return nil
+	})
+	if err != nil || uploadID == "" || !entry.IsDirectory {
+		glog.V(3).Infof("lookup bucket %s upload %s: %v", bucket, uploadID, err)
+		return nil, ErrNoSuchUpload
+	}
+	return entry, ErrNone
+}
+
 func (s3a *S3ApiServer) abortMultipartUpload(ctx context.Context, input *s3.AbortMultipartUploadInput) (output *s3.AbortMultipartUploadOutput, code ErrorCode) {
 
 	exists, err := s3a.exists(ctx, s3a.genUploadsFolder(*input.Bucket), *input.UploadId, true)
@@ -44,7 +44,7 @@ func (s3a *S3ApiServer) mkdir(ctx context.Context, parentDirectoryPath string, d
 	})
 }
 
-func (s3a *S3ApiServer) mkFile(ctx context.Context, parentDirectoryPath string, fileName string, chunks []*filer_pb.FileChunk) error {
+func (s3a *S3ApiServer) mkFile(ctx context.Context, parentDirectoryPath string, fileName string, chunks []*filer_pb.FileChunk, fn func(entry *filer_pb.Entry)) error {
 	return s3a.withFilerClient(ctx, func(client filer_pb.SeaweedFilerClient) error {
 
 		entry := &filer_pb.Entry{
@@ -60,6 +60,10 @@ func (s3a *S3ApiServer) mkFile(ctx context.Context, parentDirectoryPath string,
 			Chunks: chunks,
 		}
 
+		if fn != nil {
+			fn(entry)
+		}
+
 		request := &filer_pb.CreateEntryRequest{
 			Directory: parentDirectoryPath,
 			Entry:     entry,
@@ -1,8 +1,11 @@
 package s3api
 
 import (
+	"context"
 	"net/http"
 	"strings"
+
+	"github.com/gorilla/mux"
 )
 
 // AWS Signature Version '4' constants.
@@ -88,3 +91,48 @@ func getRequestAuthType(r *http.Request) authType {
 	}
 	return authTypeUnknown
 }
+
+// authorize verifies the signed requests of the configured access keys,
+// and allows the anonymous requests only if granted by the bucket policy.
+// All requests are allowed if no access keys are configured.
+// The verified access keys are then limited by their rate limits.
+func (s3a *S3ApiServer) authorize(action string, f http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if errCode := s3a.authRequest(r, action); errCode != ErrNone {
+			writeErrorResponse(w, errCode, r.URL)
+			return
+		}
+		w, allowed := s3a.limitAccessKey(w, r, s3a.verifiedAccessKey(r))
+		if !allowed {
+			return
+		}
+		f(w, r)
+	}
+}
+
+// verifiedAccessKey is the access key of the request passing authRequest,
+// which is not verified if no access keys are configured
+func (s3a *S3ApiServer) verifiedAccessKey(r *http.Request) string {
+	if len(s3a.option.Credentials) == 0 {
+		return ""
+	}
+	return requestAccessKey(r)
+}
+
+func (s3a *S3ApiServer) authRequest(r *http.Request, action string) ErrorCode {
+	if len(s3a.option.Credentials) == 0 {
+		return ErrNone
+	}
+	switch getRequestAuthType(r) {
+	case authTypeSigned, authTypeStreamingSigned:
+		return s3a.verifySignatureV4(r)
+	case authTypePresigned:
+		return s3a.verifyPresignedSignatureV4(r)
+	case authTypeAnonymous:
+		vars := mux.Vars(r)
+		if vars["object"] != "" && s3a.isAnonymousAllowed(context.Background(), action, vars["bucket"], strings.TrimPrefix(getObject(vars), "/")) {
+			return ErrNone
+		}
+	}
+	return ErrAccessDenied
+}
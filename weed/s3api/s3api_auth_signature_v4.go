@@ -0,0 +1,252 @@
+package s3api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// the signature version 4 of the headers and the presigned urls, see
+// https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-authenticating-requests.html
+// The payload hash is signed as sent by the client, but the body and the chunk signatures are not verified.
+
+const (
+	iso8601Format          = "20060102T150405Z"
+	yyyymmdd               = "20060102"
+	unsignedPayload        = "UNSIGNED-PAYLOAD"
+	maxRequestTimeSkew     = 15 * time.Minute
+	maxPresignedExpiration = 7 * 24 * 60 * 60
+)
+
+type signValuesV4 struct {
+	accessKey     string
+	date          string
+	scope         string
+	signedHeaders []string
+	signature     string
+}
+
+// parseCredentialV4 parses "<access key>/<yyyymmdd>/<region>/<service>/aws4_request"
+func parseCredentialV4(credential string) (values signValuesV4, errCode ErrorCode) {
+	parts := strings.Split(strings.TrimSpace(credential), "/")
+	if len(parts) != 5 || parts[0] == "" || parts[4] != "aws4_request" {
+		return values, ErrAuthorizationHeaderMalformed
+	}
+	if _, err := time.Parse(yyyymmdd, parts[1]); err != nil {
+		return values, ErrAuthorizationHeaderMalformed
+	}
+	values.accessKey = parts[0]
+	values.date = parts[1]
+	values.scope = strings.Join(parts[1:], "/")
+	return values, ErrNone
+}
+
+// parseSignV4 parses "AWS4-HMAC-SHA256 Credential=..., SignedHeaders=..., Signature=..."
+func parseSignV4(authorization string) (values signValuesV4, errCode ErrorCode) {
+	if !strings.HasPrefix(authorization, signV4Algorithm) {
+		return values, ErrAuthorizationHeaderMalformed
+	}
+	fields := make(map[string]string)
+	for _, field := range strings.Split(strings.TrimPrefix(authorization, signV4Algorithm), ",") {
+		kv := strings.SplitN(strings.TrimSpace(field), "=", 2)
+		if len(kv) != 2 {
+			return values, ErrAuthorizationHeaderMalformed
+		}
+		fields[kv[0]] = kv[1]
+	}
+	if values, errCode = parseCredentialV4(fields["Credential"]); errCode != ErrNone {
+		return values, errCode
+	}
+	if fields["SignedHeaders"] == "" || fields["Signature"] == "" {
+		return values, ErrAuthorizationHeaderMalformed
+	}
+	values.signedHeaders = strings.Split(fields["SignedHeaders"], ";")
+	values.signature = fields["Signature"]
+	return values, ErrNone
+}
+
+// verifySignatureV4 verifies the signature in the Authorization header
+func (s3a *S3ApiServer) verifySignatureV4(r *http.Request) ErrorCode {
+	values, errCode := parseSignV4(r.Header.Get("Authorization"))
+	if errCode != ErrNone {
+		return errCode
+	}
+
+	amzDate := r.Header.Get("X-Amz-Date")
+	if amzDate == "" {
+		if t, err := http.ParseTime(r.Header.Get("Date")); err == nil {
+			amzDate = t.UTC().Format(iso8601Format)
+		}
+	}
+	t, err := time.Parse(iso8601Format, amzDate)
+	if err != nil {
+		return ErrAuthorizationHeaderMalformed
+	}
+	if skew := time.Since(t); skew > maxRequestTimeSkew || skew < -maxRequestTimeSkew {
+		return ErrRequestTimeTooSkewed
+	}
+
+	hashedPayload := r.Header.Get("X-Amz-Content-Sha256")
+	if hashedPayload == "" {
+		return ErrAuthorizationHeaderMalformed
+	}
+
+	return s3a.verifySignature(r, values, amzDate, r.URL.Query(), hashedPayload)
+}
+
+// verifyPresignedSignatureV4 verifies the signature in the query of a presigned url
+func (s3a *S3ApiServer) verifyPresignedSignatureV4(r *http.Request) ErrorCode {
+	query := r.URL.Query()
+	if query.Get("X-Amz-Algorithm") != signV4Algorithm {
+		return ErrAuthorizationHeaderMalformed
+	}
+	values, errCode := parseCredentialV4(query.Get("X-Amz-Credential"))
+	if errCode != ErrNone {
+		return errCode
+	}
+	if query.Get("X-Amz-SignedHeaders") == "" || query.Get("X-Amz-Signature") == "" {
+		return ErrAuthorizationHeaderMalformed
+	}
+	values.signedHeaders = strings.Split(query.Get("X-Amz-SignedHeaders"), ";")
+	values.signature = query.Get("X-Amz-Signature")
+
+	amzDate := query.Get("X-Amz-Date")
+	t, err := time.Parse(iso8601Format, amzDate)
+	if err != nil {
+		return ErrAuthorizationHeaderMalformed
+	}
+	expires, err := strconv.Atoi(query.Get("X-Amz-Expires"))
+	if err != nil || expires < 0 || expires > maxPresignedExpiration {
+		return ErrAuthorizationHeaderMalformed
+	}
+	if time.Until(t) > maxRequestTimeSkew {
+		return ErrRequestTimeTooSkewed
+	}
+	if time.Since(t) > time.Duration(expires)*time.Second {
+		return ErrExpiredPresignRequest
+	}
+
+	hashedPayload := unsignedPayload
+	if contentSha256 := query.Get("X-Amz-Content-Sha256"); contentSha256 != "" {
+		hashedPayload = contentSha256
+	}
+	query.Del("X-Amz-Signature")
+
+	return s3a.verifySignature(r, values, amzDate, query, hashedPayload)
+}
+
+func (s3a *S3ApiServer) verifySignature(r *http.Request, values signValuesV4, amzDate string, query url.Values, hashedPayload string) ErrorCode {
+	secretKey, found := s3a.option.Credentials[values.accessKey]
+	if !found {
+		return ErrInvalidAccessKeyID
+	}
+
+	canonicalRequest := getCanonicalRequest(r, values.signedHeaders, query, hashedPayload)
+	stringToSign := strings.Join([]string{
+		signV4Algorithm,
+		amzDate,
+		values.scope,
+		hex.EncodeToString(sum256([]byte(canonicalRequest))),
+	}, "\n")
+
+	scope := strings.Split(values.scope, "/")
+	signingKey := getSigningKey(secretKey, values.date, scope[1], scope[2])
+	signature := hex.EncodeToString(sumHMAC(signingKey, []byte(stringToSign)))
+
+	if !hmac.Equal([]byte(signature), []byte(values.signature)) {
+		return ErrSignatureDoesNotMatch
+	}
+	return ErrNone
+}
+
+func getCanonicalRequest(r *http.Request, signedHeaders []string, query url.Values, hashedPayload string) string {
+	return strings.Join([]string{
+		r.Method,
+		encodeAwsPath(r.URL.Path, false),
+		getCanonicalQuery(query),
+		getCanonicalHeaders(r, signedHeaders),
+		strings.Join(signedHeaders, ";"),
+		hashedPayload,
+	}, "\n")
+}
+
+func getCanonicalQuery(query url.Values) string {
+	var pairs []string
+	for key, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, encodeAwsPath(key, true)+"="+encodeAwsPath(value, true))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// getCanonicalHeaders lists the signed headers with the trimmed values, each ending with a new line
+func getCanonicalHeaders(r *http.Request, signedHeaders []string) string {
+	var buf strings.Builder
+	for _, name := range signedHeaders {
+		var value string
+		switch name {
+		case "host":
+			value = r.Host
+		case "content-length":
+			value = r.Header.Get("Content-Length")
+			if value == "" && r.ContentLength > 0 {
+				value = strconv.FormatInt(r.ContentLength, 10)
+			}
+		default:
+			var trimmed []string
+			for _, v := range r.Header[http.CanonicalHeaderKey(name)] {
+				trimmed = append(trimmed, strings.Join(strings.Fields(v), " "))
+			}
+			value = strings.Join(trimmed, ",")
+		}
+		buf.WriteString(name)
+		buf.WriteByte(':')
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+	}
+	return buf.String()
+}
+
+// encodeAwsPath escapes all bytes except the unreserved characters, and "/" unless in the query
+func encodeAwsPath(s string, encodeSlash bool) string {
+	const hexUpper = "0123456789ABCDEF"
+	var buf strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			buf.WriteByte(c)
+			continue
+		}
+		buf.WriteByte('%')
+		buf.WriteByte(hexUpper[c>>4])
+		buf.WriteByte(hexUpper[c&15])
+	}
+	return buf.String()
+}
+
+func getSigningKey(secretKey, date, region, service string) []byte {
+	kDate := sumHMAC([]byte("AWS4"+secretKey), []byte(date))
+	kRegion := sumHMAC(kDate, []byte(region))
+	kService := sumHMAC(kRegion, []byte(service))
+	return sumHMAC(kService, []byte("aws4_request"))
+}
+
+func sumHMAC(key []byte, data []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func sum256(data []byte) []byte {
+	h := sha256.Sum256(data)
+	return h[:]
+}
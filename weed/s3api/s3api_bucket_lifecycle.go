@@ -0,0 +1,237 @@
+package s3api
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/gorilla/mux"
+)
+
+// the lifecycle configuration is kept in the extended attributes of the bucket directory
+const bucketLifecycleKey = "s3-bucket-lifecycle"
+
+const (
+	actionGetLifecycleConfiguration = "s3:GetLifecycleConfiguration"
+	actionPutLifecycleConfiguration = "s3:PutLifecycleConfiguration"
+)
+
+// lifecycleConfiguration expires the objects, and aborts the incomplete multipart uploads, after some days.
+// The expiry is set on the objects when written, and the filer deletes them when expired.
+// So the rules apply to the objects written after the rules are configured.
+// The transitions, the noncurrent versions, the dates and the tag filters are not supported.
+type lifecycleConfiguration struct {
+	XMLName xml.Name        `xml:"LifecycleConfiguration"`
+	Rules   []lifecycleRule `xml:"Rule"`
+}
+
+type lifecycleRule struct {
+	ID                             string                          `xml:"ID,omitempty"`
+	Status                         string                          `xml:"Status"`
+	Prefix                         *string                         `xml:"Prefix,omitempty"`
+	Filter                         *lifecycleFilter                `xml:"Filter,omitempty"`
+	Expiration                     *lifecycleExpiration            `xml:"Expiration,omitempty"`
+	AbortIncompleteMultipartUpload *abortIncompleteMultipartUpload `xml:"AbortIncompleteMultipartUpload,omitempty"`
+	Transitions                    []struct{}                      `xml:"Transition,omitempty"`
+	NoncurrentVersionExpiration    *struct{}                       `xml:"NoncurrentVersionExpiration,omitempty"`
+}
+
+type lifecycleFilter struct {
+	Prefix string    `xml:"Prefix"`
+	Tag    *struct{} `xml:"Tag,omitempty"`
+	And    *struct{} `xml:"And,omitempty"`
+}
+
+type lifecycleExpiration struct {
+	Days int     `xml:"Days,omitempty"`
+	Date *string `xml:"Date,omitempty"`
+}
+
+type abortIncompleteMultipartUpload struct {
+	DaysAfterInitiation int `xml:"DaysAfterInitiation"`
+}
+
+func (config *lifecycleConfiguration) validate() ErrorCode {
+	if len(config.Rules) == 0 {
+		return ErrMalformedXML
+	}
+	for _, rule := range config.Rules {
+		if len(rule.Transitions) > 0 || rule.NoncurrentVersionExpiration != nil {
+			return ErrNotImplemented
+		}
+		if rule.Filter != nil && (rule.Filter.Tag != nil || rule.Filter.And != nil) {
+			return ErrNotImplemented
+		}
+		if rule.Expiration != nil && rule.Expiration.Date != nil {
+			return ErrNotImplemented
+		}
+		if rule.Status != "Enabled" && rule.Status != "Disabled" {
+			return ErrMalformedXML
+		}
+		if rule.Prefix != nil && rule.Filter != nil {
+			return ErrMalformedXML
+		}
+		if rule.Expiration == nil && rule.AbortIncompleteMultipartUpload == nil {
+			return ErrMalformedXML
+		}
+		if rule.Expiration != nil && rule.Expiration.Days <= 0 {
+			return ErrMalformedXML
+		}
+		if rule.AbortIncompleteMultipartUpload != nil && rule.AbortIncompleteMultipartUpload.DaysAfterInitiation <= 0 {
+			return ErrMalformedXML
+		}
+	}
+	return ErrNone
+}
+
+func (rule *lifecycleRule) matches(key string) bool {
+	if rule.Status != "Enabled" {
+		return false
+	}
+	prefix := ""
+	if rule.Prefix != nil {
+		prefix = *rule.Prefix
+	} else if rule.Filter != nil {
+		prefix = rule.Filter.Prefix
+	}
+	return strings.HasPrefix(strings.TrimPrefix(key, "/"), prefix)
+}
+
+// expirationDays is the fewest days to expire the object by the matching rules, or 0 if none
+func (config *lifecycleConfiguration) expirationDays(key string) (days int) {
+	for _, rule := range config.Rules {
+		if rule.Expiration != nil && rule.matches(key) && (days == 0 || rule.Expiration.Days < days) {
+			days = rule.Expiration.Days
+		}
+	}
+	return
+}
+
+// abortDays is the fewest days to abort the incomplete multipart upload by the matching rules, or 0 if none
+func (config *lifecycleConfiguration) abortDays(key string) (days int) {
+	for _, rule := range config.Rules {
+		abort := rule.AbortIncompleteMultipartUpload
+		if abort != nil && rule.matches(key) && (days == 0 || abort.DaysAfterInitiation < days) {
+			days = abort.DaysAfterInitiation
+		}
+	}
+	return
+}
+
+// expireQuery is the "expire" parameter of the filer upload, in the format of the ttl, which counts up to 255 units.
+// The longer expirations are rounded up to weeks or years.
+func expireQuery(days int) string {
+	switch {
+	case days <= 255:
+		return fmt.Sprintf("%dd", days)
+	case days <= 255*7:
+		return fmt.Sprintf("%dw", (days+6)/7)
+	default:
+		return fmt.Sprintf("%dy", (days+364)/365)
+	}
+}
+
+// expireAt is the expiry of the filer entry created now, to be deleted after the days
+func expireAt(days int) int64 {
+	if days <= 0 {
+		return 0
+	}
+	return time.Now().AddDate(0, 0, days).Unix()
+}
+
+// PutBucketLifecycleConfigurationHandler Put bucket Lifecycle configuration
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketLifecycleConfiguration.html
+func (s3a *S3ApiServer) PutBucketLifecycleConfigurationHandler(w http.ResponseWriter, r *http.Request) {
+
+	bucket := mux.Vars(r)["bucket"]
+
+	var config lifecycleConfiguration
+	if errCode := readXmlBody(r, &config); errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+	if errCode := config.validate(); errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+
+	if errCode := s3a.updateBucketLifecycle(context.Background(), bucket, encodeResponse(config)); errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+
+	writeSuccessResponseEmpty(w)
+}
+
+// GetBucketLifecycleConfigurationHandler Get bucket Lifecycle configuration
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketLifecycleConfiguration.html
+func (s3a *S3ApiServer) GetBucketLifecycleConfigurationHandler(w http.ResponseWriter, r *http.Request) {
+
+	bucket := mux.Vars(r)["bucket"]
+
+	entry, errCode := s3a.lookupBucketEntry(context.Background(), bucket)
+	if errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+	data, found := entry.Extended[bucketLifecycleKey]
+	if !found {
+		writeErrorResponse(w, ErrNoSuchLifecycleConfiguration, r.URL)
+		return
+	}
+
+	writeSuccessResponseXML(w, data)
+}
+
+// DeleteBucketLifecycleHandler Delete bucket Lifecycle
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_DeleteBucketLifecycle.html
+func (s3a *S3ApiServer) DeleteBucketLifecycleHandler(w http.ResponseWriter, r *http.Request) {
+
+	bucket := mux.Vars(r)["bucket"]
+
+	if errCode := s3a.updateBucketLifecycle(context.Background(), bucket, nil); errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+
+	writeResponse(w, http.StatusNoContent, nil, mimeNone)
+}
+
+// updateBucketLifecycle sets the lifecycle configuration of the bucket, or removes it if empty
+func (s3a *S3ApiServer) updateBucketLifecycle(ctx context.Context, bucket string, data []byte) ErrorCode {
+	entry, errCode := s3a.lookupBucketEntry(ctx, bucket)
+	if errCode != ErrNone {
+		return errCode
+	}
+	if len(data) == 0 {
+		delete(entry.Extended, bucketLifecycleKey)
+	} else {
+		if entry.Extended == nil {
+			entry.Extended = make(map[string][]byte)
+		}
+		entry.Extended[bucketLifecycleKey] = data
+	}
+	return s3a.updateObjectEntry(ctx, s3a.option.BucketsPath, entry, false)
+}
+
+// bucketLifecycle is the lifecycle configuration of the bucket, or nil if none
+func (s3a *S3ApiServer) bucketLifecycle(ctx context.Context, bucket string) *lifecycleConfiguration {
+	entry, errCode := s3a.lookupBucketEntry(ctx, bucket)
+	if errCode != ErrNone {
+		return nil
+	}
+	data, found := entry.Extended[bucketLifecycleKey]
+	if !found {
+		return nil
+	}
+	config := &lifecycleConfiguration{}
+	if err := xml.Unmarshal(data, config); err != nil {
+		glog.Errorf("bucket %s lifecycle configuration: %v", bucket, err)
+		return nil
+	}
+	return config
+}
@@ -0,0 +1,53 @@
+package s3api
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+func TestLifecycleConfiguration(t *testing.T) {
+	tests := []struct {
+		body    string
+		errCode ErrorCode
+	}{
+		{`<LifecycleConfiguration><Rule><Status>Enabled</Status><Filter><Prefix>logs/</Prefix></Filter><Expiration><Days>30</Days></Expiration></Rule></LifecycleConfiguration>`, ErrNone},
+		{`<LifecycleConfiguration><Rule><Status>Enabled</Status><Prefix></Prefix><AbortIncompleteMultipartUpload><DaysAfterInitiation>7</DaysAfterInitiation></AbortIncompleteMultipartUpload></Rule></LifecycleConfiguration>`, ErrNone},
+		{`<LifecycleConfiguration></LifecycleConfiguration>`, ErrMalformedXML},
+		{`<LifecycleConfiguration><Rule><Status>On</Status><Expiration><Days>30</Days></Expiration></Rule></LifecycleConfiguration>`, ErrMalformedXML},
+		{`<LifecycleConfiguration><Rule><Status>Enabled</Status><Expiration><Days>0</Days></Expiration></Rule></LifecycleConfiguration>`, ErrMalformedXML},
+		{`<LifecycleConfiguration><Rule><Status>Enabled</Status><Expiration><Date>2030-01-01T00:00:00Z</Date></Expiration></Rule></LifecycleConfiguration>`, ErrNotImplemented},
+		{`<LifecycleConfiguration><Rule><Status>Enabled</Status><Transition><Days>30</Days><StorageClass>GLACIER</StorageClass></Transition></Rule></LifecycleConfiguration>`, ErrNotImplemented},
+		{`<LifecycleConfiguration><Rule><Status>Enabled</Status><Filter><Tag><Key>a</Key><Value>b</Value></Tag></Filter><Expiration><Days>1</Days></Expiration></Rule></LifecycleConfiguration>`, ErrNotImplemented},
+	}
+	for _, test := range tests {
+		var config lifecycleConfiguration
+		if err := xml.Unmarshal([]byte(test.body), &config); err != nil {
+			t.Fatalf("unmarshal %s: %v", test.body, err)
+		}
+		if errCode := config.validate(); errCode != test.errCode {
+			t.Errorf("%s: error code %d, expected %d", test.body, errCode, test.errCode)
+		}
+	}
+
+	var config lifecycleConfiguration
+	xml.Unmarshal([]byte(`<LifecycleConfiguration>
+<Rule><Status>Enabled</Status><Filter><Prefix>logs/</Prefix></Filter><Expiration><Days>30</Days></Expiration></Rule>
+<Rule><Status>Enabled</Status><Filter><Prefix>logs/tmp/</Prefix></Filter><Expiration><Days>1</Days></Expiration></Rule>
+<Rule><Status>Disabled</Status><Filter><Prefix></Prefix></Filter><Expiration><Days>2</Days></Expiration></Rule>
+<Rule><Status>Enabled</Status><Filter><Prefix></Prefix></Filter><AbortIncompleteMultipartUpload><DaysAfterInitiation>7</DaysAfterInitiation></AbortIncompleteMultipartUpload></Rule>
+</LifecycleConfiguration>`), &config)
+	for key, days := range map[string]int{"/logs/a": 30, "/logs/tmp/a": 1, "/data/a": 0} {
+		if d := config.expirationDays(key); d != days {
+			t.Errorf("%s expires after %d days, expected %d", key, d, days)
+		}
+	}
+	if d := config.abortDays("data/a"); d != 7 {
+		t.Errorf("upload aborted after %d days", d)
+	}
+
+	for days, expire := range map[int]string{30: "30d", 255: "255d", 256: "37w", 3650: "10y"} {
+		if q := expireQuery(days); q != expire {
+			t.Errorf("expire %d days as %s, expected %s", days, q, expire)
+		}
+	}
+}
@@ -0,0 +1,462 @@
+package s3api
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/audit"
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/gorilla/mux"
+)
+
+// the bucket logging status is kept in the extended attributes of the bucket directory
+const bucketLoggingKey = "s3-bucket-logging"
+
+const (
+	actionGetBucketLogging = "s3:GetBucketLogging"
+	actionPutBucketLogging = "s3:PutBucketLogging"
+
+	// the logging status of the buckets is looked up again after this long
+	bucketLoggingCacheTtl = time.Minute
+	// the access logs of one target are flushed sooner if buffered this much
+	accessLogMaxBufferBytes = 4 * 1024 * 1024
+	// the logging status of at most this many buckets is cached
+	accessLogMaxCachedStatuses = 10000
+)
+
+type bucketLoggingStatus struct {
+	XMLName        xml.Name       `xml:"BucketLoggingStatus"`
+	LoggingEnabled *loggingTarget `xml:"LoggingEnabled,omitempty"`
+}
+
+type loggingTarget struct {
+	TargetBucket string `xml:"TargetBucket"`
+	TargetPrefix string `xml:"TargetPrefix"`
+}
+
+// PutBucketLoggingHandler Put bucket Logging
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketLogging.html
+func (s3a *S3ApiServer) PutBucketLoggingHandler(w http.ResponseWriter, r *http.Request) {
+
+	bucket := mux.Vars(r)["bucket"]
+	ctx := context.Background()
+
+	var status bucketLoggingStatus
+	if errCode := readXmlBody(r, &status); errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+
+	var data []byte
+	if status.LoggingEnabled != nil {
+		target := status.LoggingEnabled.TargetBucket
+		if target == "" {
+			writeErrorResponse(w, ErrInvalidTargetBucketForLogging, r.URL)
+			return
+		}
+		if _, errCode := s3a.lookupBucketEntry(ctx, target); errCode != ErrNone {
+			writeErrorResponse(w, ErrInvalidTargetBucketForLogging, r.URL)
+			return
+		}
+		data = encodeResponse(status)
+	}
+
+	entry, errCode := s3a.lookupBucketEntry(ctx, bucket)
+	if errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+	if data == nil {
+		delete(entry.Extended, bucketLoggingKey)
+	} else {
+		if entry.Extended == nil {
+			entry.Extended = make(map[string][]byte)
+		}
+		entry.Extended[bucketLoggingKey] = data
+	}
+	if errCode = s3a.updateObjectEntry(ctx, s3a.option.BucketsPath, entry, false); errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+	s3a.accessLog.forget(bucket)
+
+	writeSuccessResponseEmpty(w)
+}
+
+// GetBucketLoggingHandler Get bucket Logging
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketLogging.html
+func (s3a *S3ApiServer) GetBucketLoggingHandler(w http.ResponseWriter, r *http.Request) {
+
+	bucket := mux.Vars(r)["bucket"]
+
+	entry, errCode := s3a.lookupBucketEntry(context.Background(), bucket)
+	if errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+	if data, found := entry.Extended[bucketLoggingKey]; found {
+		writeSuccessResponseXML(w, data)
+		return
+	}
+
+	writeSuccessResponseXML(w, encodeResponse(bucketLoggingStatus{}))
+}
+
+// accessLogger buffers the access log lines of the requests to the buckets with the logging enabled,
+// and flushes them as new objects into the target buckets on every interval.
+// The buffered lines are lost if the server stops before the flush.
+type accessLogger struct {
+	sync.Mutex
+	s3a      *S3ApiServer
+	interval time.Duration
+	statuses map[string]*cachedLoggingStatus
+	buffers  map[loggingTarget]*bytes.Buffer
+	flushNow chan struct{}
+}
+
+type cachedLoggingStatus struct {
+	target   *loggingTarget
+	loadedAt time.Time
+}
+
+func newAccessLogger(s3a *S3ApiServer, interval time.Duration) *accessLogger {
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	l := &accessLogger{
+		s3a:      s3a,
+		interval: interval,
+		statuses: make(map[string]*cachedLoggingStatus),
+		buffers:  make(map[loggingTarget]*bytes.Buffer),
+		flushNow: make(chan struct{}, 1),
+	}
+	go l.loop()
+	return l
+}
+
+// target returns where the access logs of the bucket go, or nil if not logged
+func (l *accessLogger) target(bucket string) *loggingTarget {
+	l.Lock()
+	cached, found := l.statuses[bucket]
+	l.Unlock()
+	if found && time.Since(cached.loadedAt) < bucketLoggingCacheTtl {
+		return cached.target
+	}
+
+	cached = &cachedLoggingStatus{loadedAt: time.Now()}
+	if entry, errCode := l.s3a.lookupBucketEntry(context.Background(), bucket); errCode == ErrNone {
+		if data, found := entry.Extended[bucketLoggingKey]; found {
+			var status bucketLoggingStatus
+			if err := xml.Unmarshal(data, &status); err != nil {
+				glog.V(0).Infof("invalid logging status of bucket %s: %v", bucket, err)
+			}
+			cached.target = status.LoggingEnabled
+		}
+		l.cache(bucket, cached)
+	}
+	return cached.target
+}
+
+// cache keeps the logging status of an existing bucket, so that the requests to any bucket names do not grow the cache.
+// When full, the expired statuses are dropped, or the status is not cached.
+func (l *accessLogger) cache(bucket string, cached *cachedLoggingStatus) {
+	l.Lock()
+	defer l.Unlock()
+	if _, found := l.statuses[bucket]; !found && len(l.statuses) >= accessLogMaxCachedStatuses {
+		for b, c := range l.statuses {
+			if time.Since(c.loadedAt) >= bucketLoggingCacheTtl {
+				delete(l.statuses, b)
+			}
+		}
+		if len(l.statuses) >= accessLogMaxCachedStatuses {
+			return
+		}
+	}
+	l.statuses[bucket] = cached
+}
+
+func (l *accessLogger) forget(bucket string) {
+	l.Lock()
+	delete(l.statuses, bucket)
+	l.Unlock()
+}
+
+func (l *accessLogger) add(target loggingTarget, line string) {
+	l.Lock()
+	defer l.Unlock()
+	buffer, found := l.buffers[target]
+	if !found {
+		buffer = &bytes.Buffer{}
+		l.buffers[target] = buffer
+	}
+	buffer.WriteString(line)
+	if buffer.Len() >= accessLogMaxBufferBytes {
+		select {
+		case l.flushNow <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (l *accessLogger) loop() {
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-l.flushNow:
+		}
+		l.flush()
+	}
+}
+
+// flush writes each buffer as a new object "<TargetPrefix>YYYY-mm-DD-HH-MM-SS-<UniqueString>"
+func (l *accessLogger) flush() {
+	l.Lock()
+	buffers := l.buffers
+	l.buffers = make(map[loggingTarget]*bytes.Buffer)
+	l.Unlock()
+
+	for target, buffer := range buffers {
+		object := target.TargetPrefix + time.Now().UTC().Format("2006-01-02-15-04-05") + "-" + randomHex(8)
+		uploadUrl := fmt.Sprintf("http://%s%s/%s/%s?collection=%s", l.s3a.option.Filer, l.s3a.option.BucketsPath,
+			target.TargetBucket, (&url.URL{Path: object}).EscapedPath(), target.TargetBucket)
+		req, err := http.NewRequest("PUT", uploadUrl, bytes.NewReader(buffer.Bytes()))
+		if err != nil {
+			glog.Errorf("drop access logs to %s/%s: %v", target.TargetBucket, object, err)
+			continue
+		}
+		req.Header.Set("Content-Type", "text/plain")
+		resp, err := client.Do(req)
+		if err != nil {
+			glog.Errorf("drop access logs to %s/%s: %v", target.TargetBucket, object, err)
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= http.StatusBadRequest {
+			glog.Errorf("drop access logs to %s/%s: status %d", target.TargetBucket, object, resp.StatusCode)
+		}
+	}
+}
+
+// logAccess records the requests to the buckets with the logging enabled, and all the requests to the audit log if enabled
+func (s3a *S3ApiServer) logAccess(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bucket := mux.Vars(r)["bucket"]
+		var target *loggingTarget
+		if bucket != "" {
+			target = s3a.accessLog.target(bucket)
+		}
+		if target == nil && !audit.IsEnabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		lw := &accessLogResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(lw, r)
+
+		if target != nil {
+			s3a.accessLog.add(*target, formatAccessLog(r, lw, bucket, start))
+		}
+		if audit.IsEnabled() {
+			audit.Log(toAuditEvent(r, lw, bucket, start))
+		}
+	})
+}
+
+func toAuditEvent(r *http.Request, lw *accessLogResponseWriter, bucket string, start time.Time) *audit.Event {
+	vars := mux.Vars(r)
+	remoteIp, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remoteIp = r.RemoteAddr
+	}
+	who := requestAccessKey(r)
+	if who == "" {
+		who = "anonymous"
+	}
+	bytesIn := r.ContentLength
+	if bytesIn < 0 {
+		bytesIn = 0
+	}
+	return &audit.Event{
+		Time:      start.UTC(),
+		Server:    "s3",
+		Who:       who,
+		RemoteIp:  remoteIp,
+		Action:    accessLogOperation(r, vars),
+		Bucket:    bucket,
+		Key:       strings.TrimPrefix(getObject(vars), "/"),
+		BytesIn:   bytesIn,
+		BytesOut:  lw.bytesSent,
+		Status:    lw.status,
+		LatencyMs: time.Since(start).Nanoseconds() / int64(time.Millisecond),
+	}
+}
+
+// formatAccessLog formats the request in the AWS server access log format, see
+// https://docs.aws.amazon.com/AmazonS3/latest/dev/LogFormat.html
+// The bucket owner, the host id and the tls details are not known, and logged as "-".
+func formatAccessLog(r *http.Request, lw *accessLogResponseWriter, bucket string, start time.Time) string {
+	vars := mux.Vars(r)
+	key := "-"
+	if vars["object"] != "" {
+		key = (&url.URL{Path: strings.TrimPrefix(getObject(vars), "/")}).EscapedPath()
+	}
+	remoteIp, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remoteIp = r.RemoteAddr
+	}
+	requester, signatureVersion, authType := accessLogValue(requestAccessKey(r)), "-", "-"
+	switch getRequestAuthType(r) {
+	case authTypeSigned, authTypeStreamingSigned:
+		signatureVersion, authType = "SigV4", "AuthHeader"
+	case authTypePresigned:
+		signatureVersion, authType = "SigV4", "QueryString"
+	case authTypeSignedV2, authTypePresignedV2:
+		signatureVersion = "SigV2"
+	}
+	requestId := lw.Header().Get("x-amz-request-id")
+	if requestId == "" {
+		requestId = randomHex(8)
+	}
+	objectSize := "-"
+	if r.Method == "PUT" && r.ContentLength >= 0 {
+		objectSize = fmt.Sprintf("%d", r.ContentLength)
+	} else if r.Method == "GET" && lw.status < http.StatusMultipleChoices {
+		objectSize = fmt.Sprintf("%d", lw.bytesSent)
+	}
+
+	fields := []string{
+		"-",
+		bucket,
+		start.UTC().Format("[02/Jan/2006:15:04:05 -0700]"),
+		remoteIp,
+		requester,
+		requestId,
+		accessLogOperation(r, vars),
+		accessLogValue(key),
+		fmt.Sprintf("%q", r.Method+" "+r.RequestURI+" "+r.Proto),
+		fmt.Sprintf("%d", lw.status),
+		accessLogValue(lw.errorCode),
+		accessLogBytes(lw.bytesSent),
+		objectSize,
+		fmt.Sprintf("%d", time.Since(start).Nanoseconds()/int64(time.Millisecond)),
+		"-",
+		fmt.Sprintf("%q", accessLogValue(r.Referer())),
+		fmt.Sprintf("%q", accessLogValue(r.UserAgent())),
+		"-",
+		"-",
+		signatureVersion,
+		"-",
+		authType,
+		accessLogValue(r.Host),
+		"-",
+	}
+	return strings.Join(fields, " ") + "\n"
+}
+
+// requestAccessKey is the access key of the signed or presigned requests, or empty if not known
+func requestAccessKey(r *http.Request) string {
+	switch getRequestAuthType(r) {
+	case authTypeSignedV2:
+		// Authorization: AWS AccessKeyId:Signature
+		credential := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), signV2Algorithm))
+		if i := strings.LastIndex(credential, ":"); i > 0 {
+			return credential[:i]
+		}
+	case authTypePresignedV2:
+		return r.URL.Query().Get("AWSAccessKeyId")
+	case authTypeSigned, authTypeStreamingSigned:
+		if values, errCode := parseSignV4(r.Header.Get("Authorization")); errCode == ErrNone {
+			return values.accessKey
+		}
+	case authTypePresigned:
+		if values, errCode := parseCredentialV4(r.URL.Query().Get("X-Amz-Credential")); errCode == ErrNone {
+			return values.accessKey
+		}
+	}
+	return ""
+}
+
+// accessLogOperation is like REST.GET.OBJECT, or REST.PUT.BUCKETPOLICY for the sub resources
+func accessLogOperation(r *http.Request, vars map[string]string) string {
+	resource := "BUCKET"
+	if vars["object"] != "" {
+		resource = "OBJECT"
+	}
+	query := r.URL.Query()
+	for _, subResource := range []string{"policy", "logging", "uploads", "uploadId", "retention", "legal-hold", "delete", "select"} {
+		if _, found := query[subResource]; found {
+			resource = strings.ToUpper(strings.Replace(subResource, "-", "", -1))
+			if subResource == "policy" || subResource == "logging" {
+				resource = "BUCKET" + resource
+			}
+			break
+		}
+	}
+	if r.Method == "PUT" && resource == "OBJECT" && r.Header.Get("X-Amz-Copy-Source") != "" {
+		resource = "COPY"
+	}
+	return "REST." + r.Method + "." + resource
+}
+
+func accessLogValue(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func accessLogBytes(n int64) string {
+	if n == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d", n)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return strings.ToUpper(hex.EncodeToString(b))
+}
+
+// accessLogResponseWriter records the status, the error code and the bytes sent of the response
+type accessLogResponseWriter struct {
+	http.ResponseWriter
+	status    int
+	errorCode string
+	bytesSent int64
+}
+
+func (w *accessLogResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *accessLogResponseWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.bytesSent += int64(n)
+	return n, err
+}
+
+func (w *accessLogResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// setErrorCode is called by writeErrorResponse
+func (w *accessLogResponseWriter) setErrorCode(code string) {
+	w.errorCode = code
+}
@@ -0,0 +1,260 @@
+package s3api
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+	"github.com/gorilla/mux"
+)
+
+// the bucket policy is kept in the extended attributes of the bucket directory
+const bucketPolicyKey = "s3-bucket-policy"
+
+// the actions of the requests, as named in the bucket policies
+const (
+	actionGetObject                  = "s3:GetObject"
+	actionPutObject                  = "s3:PutObject"
+	actionDeleteObject               = "s3:DeleteObject"
+	actionAbortMultipartUpload       = "s3:AbortMultipartUpload"
+	actionListMultipartUploadParts   = "s3:ListMultipartUploadParts"
+	actionListBucketMultipartUploads = "s3:ListBucketMultipartUploads"
+	actionGetObjectRetention         = "s3:GetObjectRetention"
+	actionPutObjectRetention         = "s3:PutObjectRetention"
+	actionGetObjectLegalHold         = "s3:GetObjectLegalHold"
+	actionPutObjectLegalHold         = "s3:PutObjectLegalHold"
+	actionListBucket                 = "s3:ListBucket"
+	actionCreateBucket               = "s3:CreateBucket"
+	actionDeleteBucket               = "s3:DeleteBucket"
+	actionListAllMyBuckets           = "s3:ListAllMyBuckets"
+	actionGetBucketPolicy            = "s3:GetBucketPolicy"
+	actionPutBucketPolicy            = "s3:PutBucketPolicy"
+	actionDeleteBucketPolicy         = "s3:DeleteBucketPolicy"
+)
+
+// BucketPolicy is the subset of the AWS policy grammar granting the anonymous reads:
+// "Allow" statements for the principal "*", with the action "s3:GetObject",
+// on the resources "arn:aws:s3:::<bucket>/<key>", or all keys with a prefix by "<prefix>*".
+type BucketPolicy struct {
+	Version   string            `json:"Version,omitempty"`
+	Id        string            `json:"Id,omitempty"`
+	Statement []PolicyStatement `json:"Statement"`
+}
+
+type PolicyStatement struct {
+	Sid       string          `json:"Sid,omitempty"`
+	Effect    string          `json:"Effect"`
+	Principal json.RawMessage `json:"Principal"`
+	Action    stringOrSlice   `json:"Action"`
+	Resource  stringOrSlice   `json:"Resource"`
+}
+
+// stringOrSlice is a policy element of one string, or a list of strings
+type stringOrSlice []string
+
+func (s *stringOrSlice) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*s = []string{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*s = many
+	return nil
+}
+
+// parseBucketPolicy rejects the policies beyond the supported subset,
+// instead of ignoring the parts which would grant or deny more than expected
+func parseBucketPolicy(bucket string, data []byte) (*BucketPolicy, ErrorCode) {
+	var policy BucketPolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, ErrMalformedPolicy
+	}
+	if len(policy.Statement) == 0 {
+		return nil, ErrMalformedPolicy
+	}
+	for _, statement := range policy.Statement {
+		if statement.Effect != "Allow" || !isAnonymousPrincipal(statement.Principal) {
+			return nil, ErrMalformedPolicy
+		}
+		if len(statement.Action) == 0 || len(statement.Resource) == 0 {
+			return nil, ErrMalformedPolicy
+		}
+		for _, action := range statement.Action {
+			if action != actionGetObject {
+				return nil, ErrMalformedPolicy
+			}
+		}
+		for _, resource := range statement.Resource {
+			if !strings.HasPrefix(resource, "arn:aws:s3:::"+bucket+"/") {
+				return nil, ErrMalformedPolicy
+			}
+			if key := strings.TrimPrefix(resource, "arn:aws:s3:::"+bucket+"/"); strings.Contains(strings.TrimSuffix(key, "*"), "*") {
+				return nil, ErrMalformedPolicy
+			}
+		}
+	}
+	return &policy, ErrNone
+}
+
+// isAnonymousPrincipal accepts "*", {"AWS": "*"} and {"AWS": ["*"]}
+func isAnonymousPrincipal(principal json.RawMessage) bool {
+	var everyone string
+	if err := json.Unmarshal(principal, &everyone); err == nil {
+		return everyone == "*"
+	}
+	var aws struct {
+		AWS stringOrSlice `json:"AWS"`
+	}
+	if err := json.Unmarshal(principal, &aws); err != nil {
+		return false
+	}
+	return len(aws.AWS) == 1 && aws.AWS[0] == "*"
+}
+
+// allowsAnonymous checks whether any statement grants the action on the object to everyone
+func (policy *BucketPolicy) allowsAnonymous(action, bucket, object string) bool {
+	resource := "arn:aws:s3:::" + bucket + "/" + object
+	for _, statement := range policy.Statement {
+		if statement.Effect != "Allow" || !isAnonymousPrincipal(statement.Principal) {
+			continue
+		}
+		actionMatched := false
+		for _, a := range statement.Action {
+			if a == action {
+				actionMatched = true
+			}
+		}
+		if !actionMatched {
+			continue
+		}
+		for _, r := range statement.Resource {
+			if r == resource || strings.HasSuffix(r, "*") && strings.HasPrefix(resource, strings.TrimSuffix(r, "*")) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PutBucketPolicyHandler Put bucket Policy
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketPolicy.html
+func (s3a *S3ApiServer) PutBucketPolicyHandler(w http.ResponseWriter, r *http.Request) {
+
+	bucket := mux.Vars(r)["bucket"]
+
+	data, err := ioutil.ReadAll(http.MaxBytesReader(nil, r.Body, 20*1024))
+	if err != nil {
+		writeErrorResponse(w, ErrInvalidRequest, r.URL)
+		return
+	}
+	if _, errCode := parseBucketPolicy(bucket, data); errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+
+	if errCode := s3a.updateBucketPolicy(context.Background(), bucket, data); errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+
+	writeResponse(w, http.StatusNoContent, nil, mimeNone)
+}
+
+// GetBucketPolicyHandler Get bucket Policy
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketPolicy.html
+func (s3a *S3ApiServer) GetBucketPolicyHandler(w http.ResponseWriter, r *http.Request) {
+
+	bucket := mux.Vars(r)["bucket"]
+
+	entry, errCode := s3a.lookupBucketEntry(context.Background(), bucket)
+	if errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+	data, found := entry.Extended[bucketPolicyKey]
+	if !found {
+		writeErrorResponse(w, ErrNoSuchBucketPolicy, r.URL)
+		return
+	}
+
+	writeResponse(w, http.StatusOK, data, mimeJSON)
+}
+
+// DeleteBucketPolicyHandler Delete bucket Policy
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_DeleteBucketPolicy.html
+func (s3a *S3ApiServer) DeleteBucketPolicyHandler(w http.ResponseWriter, r *http.Request) {
+
+	bucket := mux.Vars(r)["bucket"]
+
+	if errCode := s3a.updateBucketPolicy(context.Background(), bucket, nil); errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+
+	writeResponse(w, http.StatusNoContent, nil, mimeNone)
+}
+
+// updateBucketPolicy sets the policy of the bucket, or removes it if empty
+func (s3a *S3ApiServer) updateBucketPolicy(ctx context.Context, bucket string, data []byte) ErrorCode {
+	entry, errCode := s3a.lookupBucketEntry(ctx, bucket)
+	if errCode != ErrNone {
+		return errCode
+	}
+	if len(data) == 0 {
+		delete(entry.Extended, bucketPolicyKey)
+	} else {
+		if entry.Extended == nil {
+			entry.Extended = make(map[string][]byte)
+		}
+		entry.Extended[bucketPolicyKey] = data
+	}
+	return s3a.updateObjectEntry(ctx, s3a.option.BucketsPath, entry, false)
+}
+
+func (s3a *S3ApiServer) lookupBucketEntry(ctx context.Context, bucket string) (entry *filer_pb.Entry, errCode ErrorCode) {
+	err := s3a.withFilerClient(ctx, func(client filer_pb.SeaweedFilerClient) error {
+		resp, err := client.LookupDirectoryEntry(ctx, &filer_pb.LookupDirectoryEntryRequest{
+			Directory: s3a.option.BucketsPath,
+			Name:      bucket,
+		})
+		if err != nil {
+			return err
+		}
+		entry = resp.Entry
+		return nil
+	})
+	if err != nil || !entry.IsDirectory {
+		glog.V(3).Infof("lookup bucket %s/%s: %v", s3a.option.BucketsPath, bucket, err)
+		return nil, ErrNoSuchBucket
+	}
+	return entry, ErrNone
+}
+
+// isAnonymousAllowed checks the bucket policy for the anonymous requests on the objects
+func (s3a *S3ApiServer) isAnonymousAllowed(ctx context.Context, action, bucket, object string) bool {
+	if bucket == "" || object == "" {
+		return false
+	}
+	entry, errCode := s3a.lookupBucketEntry(ctx, bucket)
+	if errCode != ErrNone {
+		return false
+	}
+	data, found := entry.Extended[bucketPolicyKey]
+	if !found {
+		return false
+	}
+	policy, errCode := parseBucketPolicy(bucket, data)
+	if errCode != ErrNone {
+		glog.V(0).Infof("invalid policy of bucket %s: %s", bucket, string(data))
+		return false
+	}
+	return policy.allowsAnonymous(action, bucket, object)
+}
@@ -0,0 +1,229 @@
+package s3api
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/gorilla/mux"
+)
+
+// the website configuration is kept in the extended attributes of the bucket directory
+const bucketWebsiteKey = "s3-bucket-website"
+
+const (
+	actionGetBucketWebsite    = "s3:GetBucketWebsite"
+	actionPutBucketWebsite    = "s3:PutBucketWebsite"
+	actionDeleteBucketWebsite = "s3:DeleteBucketWebsite"
+)
+
+// websiteConfiguration is the static website of the bucket. The routing rules are not supported.
+type websiteConfiguration struct {
+	XMLName               xml.Name       `xml:"WebsiteConfiguration"`
+	IndexDocument         *indexDocument `xml:"IndexDocument,omitempty"`
+	ErrorDocument         *errorDocument `xml:"ErrorDocument,omitempty"`
+	RedirectAllRequestsTo *redirectAllTo `xml:"RedirectAllRequestsTo,omitempty"`
+	RoutingRules          *struct{}      `xml:"RoutingRules,omitempty"`
+}
+
+type indexDocument struct {
+	Suffix string `xml:"Suffix"`
+}
+
+type errorDocument struct {
+	Key string `xml:"Key"`
+}
+
+type redirectAllTo struct {
+	HostName string `xml:"HostName"`
+	Protocol string `xml:"Protocol,omitempty"`
+}
+
+// validate requires either the index document, or the redirection of all requests
+func (config *websiteConfiguration) validate() ErrorCode {
+	if config.RoutingRules != nil {
+		return ErrNotImplemented
+	}
+	if config.RedirectAllRequestsTo != nil {
+		redirect := config.RedirectAllRequestsTo
+		if config.IndexDocument != nil || config.ErrorDocument != nil || redirect.HostName == "" {
+			return ErrMalformedXML
+		}
+		if redirect.Protocol != "" && redirect.Protocol != "http" && redirect.Protocol != "https" {
+			return ErrMalformedXML
+		}
+		return ErrNone
+	}
+	if config.IndexDocument == nil || config.IndexDocument.Suffix == "" || strings.Contains(config.IndexDocument.Suffix, "/") {
+		return ErrMalformedXML
+	}
+	if config.ErrorDocument != nil && config.ErrorDocument.Key == "" {
+		return ErrMalformedXML
+	}
+	return ErrNone
+}
+
+// PutBucketWebsiteHandler Put bucket Website
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketWebsite.html
+func (s3a *S3ApiServer) PutBucketWebsiteHandler(w http.ResponseWriter, r *http.Request) {
+
+	bucket := mux.Vars(r)["bucket"]
+
+	var config websiteConfiguration
+	if errCode := readXmlBody(r, &config); errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+	if errCode := config.validate(); errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+
+	if errCode := s3a.updateBucketWebsite(context.Background(), bucket, encodeResponse(config)); errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+
+	writeSuccessResponseEmpty(w)
+}
+
+// GetBucketWebsiteHandler Get bucket Website
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketWebsite.html
+func (s3a *S3ApiServer) GetBucketWebsiteHandler(w http.ResponseWriter, r *http.Request) {
+
+	bucket := mux.Vars(r)["bucket"]
+
+	entry, errCode := s3a.lookupBucketEntry(context.Background(), bucket)
+	if errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+	data, found := entry.Extended[bucketWebsiteKey]
+	if !found {
+		writeErrorResponse(w, ErrNoSuchWebsiteConfiguration, r.URL)
+		return
+	}
+
+	writeSuccessResponseXML(w, data)
+}
+
+// DeleteBucketWebsiteHandler Delete bucket Website
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_DeleteBucketWebsite.html
+func (s3a *S3ApiServer) DeleteBucketWebsiteHandler(w http.ResponseWriter, r *http.Request) {
+
+	bucket := mux.Vars(r)["bucket"]
+
+	if errCode := s3a.updateBucketWebsite(context.Background(), bucket, nil); errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+
+	writeResponse(w, http.StatusNoContent, nil, mimeNone)
+}
+
+// updateBucketWebsite sets the website configuration of the bucket, or removes it if empty
+func (s3a *S3ApiServer) updateBucketWebsite(ctx context.Context, bucket string, data []byte) ErrorCode {
+	entry, errCode := s3a.lookupBucketEntry(ctx, bucket)
+	if errCode != ErrNone {
+		return errCode
+	}
+	if len(data) == 0 {
+		delete(entry.Extended, bucketWebsiteKey)
+	} else {
+		if entry.Extended == nil {
+			entry.Extended = make(map[string][]byte)
+		}
+		entry.Extended[bucketWebsiteKey] = data
+	}
+	return s3a.updateObjectEntry(ctx, s3a.option.BucketsPath, entry, false)
+}
+
+// WebsiteHandler serves the bucket as a static website at "{bucket}.{website domain name}",
+// with the index document for the directories, and the error document for the missing objects.
+// If the requests are signed, the objects should be readable by everyone in the bucket policy.
+func (s3a *S3ApiServer) WebsiteHandler(w http.ResponseWriter, r *http.Request) {
+
+	bucket := mux.Vars(r)["bucket"]
+	ctx := context.Background()
+
+	entry, errCode := s3a.lookupBucketEntry(ctx, bucket)
+	if errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+	data, found := entry.Extended[bucketWebsiteKey]
+	if !found {
+		writeErrorResponse(w, ErrNoSuchWebsiteConfiguration, r.URL)
+		return
+	}
+	var config websiteConfiguration
+	if err := xml.Unmarshal(data, &config); err != nil {
+		glog.V(0).Infof("invalid website configuration of bucket %s: %v", bucket, err)
+		writeErrorResponse(w, ErrInternalError, r.URL)
+		return
+	}
+
+	if redirect := config.RedirectAllRequestsTo; redirect != nil {
+		protocol := redirect.Protocol
+		if protocol == "" {
+			protocol = "http"
+		}
+		http.Redirect(w, r, protocol+"://"+redirect.HostName+r.URL.RequestURI(), http.StatusMovedPermanently)
+		return
+	}
+
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	if key == "" || strings.HasSuffix(key, "/") {
+		key += config.IndexDocument.Suffix
+	}
+
+	if s3a.isWebsiteObject(ctx, bucket, key) {
+		s3a.proxyWebsiteObject(w, r, bucket, key, http.StatusOK)
+		return
+	}
+	// the directory without the trailing slash is redirected, so the relative links in its index document work
+	if !strings.HasSuffix(key, config.IndexDocument.Suffix) && s3a.isWebsiteObject(ctx, bucket, key+"/"+config.IndexDocument.Suffix) {
+		http.Redirect(w, r, "/"+key+"/", http.StatusFound)
+		return
+	}
+	if config.ErrorDocument != nil && s3a.isWebsiteObject(ctx, bucket, config.ErrorDocument.Key) {
+		for _, name := range []string{"Range", "If-Match", "If-None-Match", "If-Modified-Since", "If-Unmodified-Since"} {
+			r.Header.Del(name)
+		}
+		s3a.proxyWebsiteObject(w, r, bucket, config.ErrorDocument.Key, http.StatusNotFound)
+		return
+	}
+	if len(s3a.option.Credentials) > 0 && !s3a.isAnonymousAllowed(ctx, actionGetObject, bucket, key) {
+		// not telling whether the object exists
+		writeErrorResponse(w, ErrAccessDenied, r.URL)
+		return
+	}
+	writeErrorResponse(w, ErrNoSuchKey, r.URL)
+}
+
+// isWebsiteObject checks the object exists, and is readable by everyone if the requests are signed
+func (s3a *S3ApiServer) isWebsiteObject(ctx context.Context, bucket, key string) bool {
+	if len(s3a.option.Credentials) > 0 && !s3a.isAnonymousAllowed(ctx, actionGetObject, bucket, key) {
+		return false
+	}
+	_, _, errCode := s3a.lookupObjectEntry(ctx, map[string]string{"bucket": bucket, "object": key})
+	return errCode == ErrNone
+}
+
+// proxyWebsiteObject reads the object from the filer, responded with the status if found
+func (s3a *S3ApiServer) proxyWebsiteObject(w http.ResponseWriter, r *http.Request, bucket, key string, status int) {
+
+	destUrl := fmt.Sprintf("http://%s%s/%s/%s",
+		s3a.option.Filer, s3a.option.BucketsPath, bucket, key)
+
+	passThrough := passThroughDecryptedResponse(r, nil)
+	s3a.proxyToFiler(w, r, destUrl, func(proxyResonse *http.Response, w http.ResponseWriter) {
+		if proxyResonse.StatusCode == http.StatusOK {
+			proxyResonse.StatusCode = status
+		}
+		passThrough(proxyResonse, w)
+	})
+}
@@ -0,0 +1,30 @@
+package s3api
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+func TestWebsiteConfigurationValidate(t *testing.T) {
+	tests := []struct {
+		body    string
+		errCode ErrorCode
+	}{
+		{`<WebsiteConfiguration><IndexDocument><Suffix>index.html</Suffix></IndexDocument></WebsiteConfiguration>`, ErrNone},
+		{`<WebsiteConfiguration><IndexDocument><Suffix>index.html</Suffix></IndexDocument><ErrorDocument><Key>404.html</Key></ErrorDocument></WebsiteConfiguration>`, ErrNone},
+		{`<WebsiteConfiguration><RedirectAllRequestsTo><HostName>example.com</HostName><Protocol>https</Protocol></RedirectAllRequestsTo></WebsiteConfiguration>`, ErrNone},
+		{`<WebsiteConfiguration></WebsiteConfiguration>`, ErrMalformedXML},
+		{`<WebsiteConfiguration><IndexDocument><Suffix>a/index.html</Suffix></IndexDocument></WebsiteConfiguration>`, ErrMalformedXML},
+		{`<WebsiteConfiguration><RedirectAllRequestsTo><HostName>example.com</HostName><Protocol>ftp</Protocol></RedirectAllRequestsTo></WebsiteConfiguration>`, ErrMalformedXML},
+		{`<WebsiteConfiguration><IndexDocument><Suffix>index.html</Suffix></IndexDocument><RoutingRules><RoutingRule></RoutingRule></RoutingRules></WebsiteConfiguration>`, ErrNotImplemented},
+	}
+	for _, test := range tests {
+		var config websiteConfiguration
+		if err := xml.Unmarshal([]byte(test.body), &config); err != nil {
+			t.Fatalf("unmarshal %s: %v", test.body, err)
+		}
+		if errCode := config.validate(); errCode != test.errCode {
+			t.Errorf("%s: error code %d, expected %d", test.body, errCode, test.errCode)
+		}
+	}
+}
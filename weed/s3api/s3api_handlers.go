@@ -54,11 +54,28 @@ func notFoundHandler(w http.ResponseWriter, r *http.Request) {
 
 func writeErrorResponse(w http.ResponseWriter, errorCode ErrorCode, reqURL *url.URL) {
 	apiError := getAPIError(errorCode)
+	if recorder := findAccessLogRecorder(w); recorder != nil {
+		recorder.setErrorCode(apiError.Code)
+	}
 	errorResponse := getRESTErrorResponse(apiError, reqURL.Path)
 	encodedErrorResponse := encodeResponse(errorResponse)
 	writeResponse(w, apiError.HTTPStatusCode, encodedErrorResponse, mimeXML)
 }
 
+// findAccessLogRecorder looks through the rate limited response writers for the access log recorder
+func findAccessLogRecorder(w http.ResponseWriter) *accessLogResponseWriter {
+	for {
+		switch writer := w.(type) {
+		case *accessLogResponseWriter:
+			return writer
+		case *util.RateLimitedResponseWriter:
+			w = writer.ResponseWriter
+		default:
+			return nil
+		}
+	}
+}
+
 func getRESTErrorResponse(err APIError, resource string) RESTErrorResponse {
 	return RESTErrorResponse{
 		Code:      err.Code,
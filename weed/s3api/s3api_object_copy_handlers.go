@@ -0,0 +1,122 @@
+package s3api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+	"github.com/gorilla/mux"
+)
+
+// CopyObjectHandler copies the object in the filer, so the data is not sent through the client
+func (s3a *S3ApiServer) CopyObjectHandler(w http.ResponseWriter, r *http.Request) {
+
+	// https://docs.aws.amazon.com/AmazonS3/latest/API/API_CopyObject.html
+
+	vars := mux.Vars(r)
+	dstBucket := vars["bucket"]
+	dstObject := getObject(vars)
+
+	srcBucket, srcObject, ok := parseCopySource(r.Header.Get("X-Amz-Copy-Source"))
+	if !ok {
+		writeErrorResponse(w, ErrInvalidCopySource, r.URL)
+		return
+	}
+	if srcBucket == dstBucket && srcObject == dstObject {
+		// the metadata can not be replaced yet, so copying to itself changes nothing
+		writeErrorResponse(w, ErrInvalidCopyDest, r.URL)
+		return
+	}
+
+	_, srcEntry, errCode := s3a.lookupObjectEntry(context.Background(), map[string]string{"bucket": srcBucket, "object": srcObject})
+	if errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+
+	if errCode = checkCopyEncryption(r, srcEntry); errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+
+	srcPath := fmt.Sprintf("%s/%s%s", s3a.option.BucketsPath, srcBucket, srcObject)
+	copyUrl := fmt.Sprintf("http://%s%s/%s%s?op=copy&from=%s&collection=%s",
+		s3a.option.Filer, s3a.option.BucketsPath, dstBucket, dstObject, url.QueryEscape(srcPath), dstBucket)
+
+	setObjectLockHeaders(r)
+
+	s3a.proxyToFiler(w, r, copyUrl, func(proxyResonse *http.Response, w http.ResponseWriter) {
+		switch proxyResonse.StatusCode {
+		case http.StatusCreated, http.StatusOK:
+		case http.StatusNotFound:
+			writeErrorResponse(w, ErrNoSuchKey, r.URL)
+			return
+		case http.StatusForbidden:
+			writeErrorResponse(w, ErrAccessDenied, r.URL)
+			return
+		case http.StatusBadRequest:
+			writeErrorResponse(w, ErrInvalidRequest, r.URL)
+			return
+		default:
+			glog.Errorf("copy %s to %s/%s: %s", srcPath, dstBucket, dstObject, proxyResonse.Status)
+			writeErrorResponse(w, ErrInternalError, r.URL)
+			return
+		}
+		if _, found := srcEntry.Extended[sseKey]; found {
+			w.Header().Set(amzServerSideEncryption, sseAlgorithm)
+		}
+		writeSuccessResponseXML(w, encodeResponse(CopyObjectResult{
+			LastModified: time.Now().UTC(),
+			ETag:         proxyResonse.Header.Get("ETag"),
+		}))
+	})
+
+}
+
+// checkCopyEncryption only allows the copies keeping the encryption of the source,
+// since the chunks are shared or copied as stored, instead of encrypted again.
+// The copies of the SSE-S3 objects stay encrypted.
+func checkCopyEncryption(r *http.Request, srcEntry *filer_pb.Entry) ErrorCode {
+	removeExtendedHeaders(r.Header)
+	if _, found := srcEntry.Extended[sseCustomerKeyMD5Key]; found {
+		return ErrNotImplemented
+	}
+	customerKey, errCode := parseSSECustomerKey(r)
+	if errCode != ErrNone {
+		return errCode
+	}
+	if customerKey != nil {
+		return ErrNotImplemented
+	}
+	sse := r.Header.Get(amzServerSideEncryption)
+	r.Header.Del(amzServerSideEncryption)
+	if sse != "" && sse != sseAlgorithm {
+		return ErrInvalidEncryptionAlgorithm
+	}
+	if _, found := srcEntry.Extended[sseKey]; !found && sse != "" {
+		return ErrNotImplemented
+	}
+	return ErrNone
+}
+
+// parseCopySource parses the "bucket/key" of the X-Amz-Copy-Source header, which may be url encoded.
+// The versions are not supported.
+func parseCopySource(copySource string) (bucket, object string, ok bool) {
+	if unescaped, err := url.QueryUnescape(copySource); err == nil {
+		copySource = unescaped
+	}
+	if strings.Contains(copySource, "?versionId=") {
+		return "", "", false
+	}
+	copySource = strings.TrimPrefix(copySource, "/")
+	index := strings.Index(copySource, "/")
+	if index <= 0 || index == len(copySource)-1 {
+		return "", "", false
+	}
+	return copySource[:index], copySource[index:], true
+}
@@ -0,0 +1,193 @@
+package s3api
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/chrislusf/seaweedfs/weed/server"
+)
+
+// the headers of the server side encryption, with the keys managed by the filer (SSE-S3) or provided by the customer (SSE-C)
+const (
+	amzServerSideEncryption                  = "X-Amz-Server-Side-Encryption"
+	amzServerSideEncryptionCustomerAlgorithm = "X-Amz-Server-Side-Encryption-Customer-Algorithm"
+	amzServerSideEncryptionCustomerKey       = "X-Amz-Server-Side-Encryption-Customer-Key"
+	amzServerSideEncryptionCustomerKeyMD5    = "X-Amz-Server-Side-Encryption-Customer-Key-Md5"
+
+	sseAlgorithm = "AES256"
+)
+
+// the encryption of the objects, kept in the extended attributes of the entries.
+// The SSE-S3 objects are stored in the chunks encrypted by the filer.
+// The SSE-C objects are encrypted here by AES-CTR, so the customer keys are never sent to the filer,
+// and only the MD5 of the key is kept, to tell the wrong keys.
+const (
+	sseKey               = "s3-sse"
+	sseCustomerKeyMD5Key = "s3-sse-customer-key-md5"
+	sseCustomerIVKey     = "s3-sse-customer-iv"
+)
+
+type sseCustomerKey struct {
+	key    []byte
+	keyMD5 string
+}
+
+// parseSSECustomerKey reads the SSE-C headers, and removes them from the request to the filer.
+// The key is nil if the request has no SSE-C headers.
+func parseSSECustomerKey(r *http.Request) (*sseCustomerKey, ErrorCode) {
+	algorithm := r.Header.Get(amzServerSideEncryptionCustomerAlgorithm)
+	encodedKey := r.Header.Get(amzServerSideEncryptionCustomerKey)
+	keyMD5 := r.Header.Get(amzServerSideEncryptionCustomerKeyMD5)
+	r.Header.Del(amzServerSideEncryptionCustomerAlgorithm)
+	r.Header.Del(amzServerSideEncryptionCustomerKey)
+	r.Header.Del(amzServerSideEncryptionCustomerKeyMD5)
+
+	if algorithm == "" && encodedKey == "" && keyMD5 == "" {
+		return nil, ErrNone
+	}
+	if algorithm != sseAlgorithm {
+		return nil, ErrInvalidEncryptionAlgorithm
+	}
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil || len(key) != 32 {
+		return nil, ErrInvalidSSECustomerKey
+	}
+	sum := md5.Sum(key)
+	if base64.StdEncoding.EncodeToString(sum[:]) != keyMD5 {
+		return nil, ErrSSECustomerKeyMD5Mismatch
+	}
+	return &sseCustomerKey{key: key, keyMD5: keyMD5}, ErrNone
+}
+
+// encryptObject asks the filer to encrypt the SSE-S3 uploads, or encrypts the SSE-C uploads,
+// with the encryption recorded in the headers of the extended attributes
+func encryptObject(r *http.Request, dataReader io.ReadCloser) (io.ReadCloser, ErrorCode) {
+	removeExtendedHeaders(r.Header)
+	customerKey, errCode := parseSSECustomerKey(r)
+	if errCode != ErrNone {
+		return nil, errCode
+	}
+	sse := r.Header.Get(amzServerSideEncryption)
+	r.Header.Del(amzServerSideEncryption)
+
+	switch {
+	case sse != "" && customerKey != nil:
+		return nil, ErrInvalidRequest
+	case sse != "":
+		if sse != sseAlgorithm {
+			return nil, ErrInvalidEncryptionAlgorithm
+		}
+		r.Header.Set(weed_server.EncryptHeader, "true")
+		setExtendedHeader(r.Header, sseKey, []byte(sseAlgorithm))
+		return dataReader, ErrNone
+	case customerKey != nil:
+		iv := make([]byte, aes.BlockSize)
+		if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+			return nil, ErrInternalError
+		}
+		setExtendedHeader(r.Header, sseCustomerKeyMD5Key, []byte(customerKey.keyMD5))
+		setExtendedHeader(r.Header, sseCustomerIVKey, iv)
+		return struct {
+			io.Reader
+			io.Closer
+		}{cipher.StreamReader{S: newSSECustomerStream(customerKey.key, iv, 0), R: dataReader}, dataReader}, ErrNone
+	}
+	return dataReader, ErrNone
+}
+
+// decryptObject checks the SSE-C key of the request against the object in the filer response,
+// and returns the response body decrypted from the offset of the returned range
+func decryptObject(customerKey *sseCustomerKey, proxyResponse *http.Response) (io.Reader, ErrorCode) {
+	keyMD5, isCustomerEncrypted := getExtendedHeader(proxyResponse.Header, sseCustomerKeyMD5Key)
+	if !isCustomerEncrypted {
+		return proxyResponse.Body, ErrNone
+	}
+	if customerKey == nil {
+		return nil, ErrSSECustomerKeyMissing
+	}
+	if string(keyMD5) != customerKey.keyMD5 {
+		return nil, ErrSSECustomerKeyMismatch
+	}
+	iv, _ := getExtendedHeader(proxyResponse.Header, sseCustomerIVKey)
+	if len(iv) != aes.BlockSize {
+		return nil, ErrInternalError
+	}
+	if proxyResponse.StatusCode == http.StatusPartialContent && proxyResponse.Header.Get("Content-Range") == "" {
+		// the multiple ranges are not decrypted by their offsets
+		return nil, ErrNotImplemented
+	}
+	offset := contentRangeStart(proxyResponse.Header.Get("Content-Range"))
+	return cipher.StreamReader{S: newSSECustomerStream(customerKey.key, iv, offset), R: proxyResponse.Body}, ErrNone
+}
+
+// setEncryptionResponseHeaders replaces the extended attributes of the filer response by the encryption headers
+func setEncryptionResponseHeaders(w http.ResponseWriter, header http.Header) {
+	if _, found := getExtendedHeader(header, sseKey); found {
+		w.Header().Set(amzServerSideEncryption, sseAlgorithm)
+	}
+	if keyMD5, found := getExtendedHeader(header, sseCustomerKeyMD5Key); found {
+		w.Header().Set(amzServerSideEncryptionCustomerAlgorithm, sseAlgorithm)
+		w.Header().Set(amzServerSideEncryptionCustomerKeyMD5, string(keyMD5))
+	}
+	removeExtendedHeaders(w.Header())
+}
+
+// newSSECustomerStream is the AES-CTR key stream from the byte offset of the object
+func newSSECustomerStream(key, iv []byte, offset int64) cipher.Stream {
+	block, _ := aes.NewCipher(key)
+	counter := make([]byte, aes.BlockSize)
+	copy(counter, iv)
+	// add the block number to the big endian counter in the lower 8 bytes, carrying into the upper 8 bytes
+	low := binary.BigEndian.Uint64(counter[8:])
+	sum := low + uint64(offset/aes.BlockSize)
+	binary.BigEndian.PutUint64(counter[8:], sum)
+	if sum < low {
+		binary.BigEndian.PutUint64(counter[:8], binary.BigEndian.Uint64(counter[:8])+1)
+	}
+	stream := cipher.NewCTR(block, counter)
+	skip := make([]byte, offset%aes.BlockSize)
+	stream.XORKeyStream(skip, skip)
+	return stream
+}
+
+// contentRangeStart is the first byte of "bytes <start>-<end>/<size>", or 0 for the whole content
+func contentRangeStart(contentRange string) int64 {
+	if !strings.HasPrefix(contentRange, "bytes ") {
+		return 0
+	}
+	start, err := strconv.ParseInt(strings.SplitN(strings.TrimPrefix(contentRange, "bytes "), "-", 2)[0], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return start
+}
+
+// removeExtendedHeaders drops the extended attributes sent by the clients, which are only set by the S3 API
+func removeExtendedHeaders(header http.Header) {
+	for name := range header {
+		if strings.HasPrefix(name, weed_server.ExtendedHeaderPrefix) {
+			header.Del(name)
+		}
+	}
+}
+
+func setExtendedHeader(header http.Header, name string, value []byte) {
+	header.Set(weed_server.ExtendedHeaderPrefix+name, base64.StdEncoding.EncodeToString(value))
+}
+
+func getExtendedHeader(header http.Header, name string) ([]byte, bool) {
+	encoded := header.Get(weed_server.ExtendedHeaderPrefix + name)
+	if encoded == "" {
+		return nil, false
+	}
+	value, err := base64.StdEncoding.DecodeString(encoded)
+	return value, err == nil
+}
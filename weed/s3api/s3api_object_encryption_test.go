@@ -0,0 +1,76 @@
+package s3api
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"encoding/base64"
+	"net/http"
+	"testing"
+)
+
+func TestSSECustomerStream(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	// the counter in the lower 8 bytes overflows within the object
+	iv := []byte{0, 0, 0, 0, 0, 0, 0, 1, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfe}
+
+	plaintext := make([]byte, 1000)
+	for i := range plaintext {
+		plaintext[i] = byte(i)
+	}
+	ciphertext := make([]byte, len(plaintext))
+	newSSECustomerStream(key, iv, 0).XORKeyStream(ciphertext, plaintext)
+
+	block, _ := aes.NewCipher(key)
+	expected := make([]byte, len(plaintext))
+	cipher.NewCTR(block, iv).XORKeyStream(expected, plaintext)
+	if !bytes.Equal(ciphertext, expected) {
+		t.Fatalf("not encrypted by AES-CTR")
+	}
+
+	for _, offset := range []int{0, 1, 15, 16, 17, 31, 32, 33, 500, 999} {
+		decrypted := make([]byte, len(ciphertext)-offset)
+		newSSECustomerStream(key, iv, int64(offset)).XORKeyStream(decrypted, ciphertext[offset:])
+		if !bytes.Equal(decrypted, plaintext[offset:]) {
+			t.Errorf("decrypt from offset %d", offset)
+		}
+	}
+
+	if start := contentRangeStart("bytes 33-999/1000"); start != 33 {
+		t.Errorf("content range start %d", start)
+	}
+}
+
+func TestParseSSECustomerKey(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	sum := md5.Sum(key)
+	keyMD5 := base64.StdEncoding.EncodeToString(sum[:])
+
+	tests := []struct {
+		algorithm, key, keyMD5 string
+		errCode                ErrorCode
+	}{
+		{"AES256", base64.StdEncoding.EncodeToString(key), keyMD5, ErrNone},
+		{"aws:kms", base64.StdEncoding.EncodeToString(key), keyMD5, ErrInvalidEncryptionAlgorithm},
+		{"AES256", base64.StdEncoding.EncodeToString(key[:16]), keyMD5, ErrInvalidSSECustomerKey},
+		{"AES256", base64.StdEncoding.EncodeToString(key), base64.StdEncoding.EncodeToString(key[:16]), ErrSSECustomerKeyMD5Mismatch},
+	}
+	for i, test := range tests {
+		r, _ := http.NewRequest("GET", "http://localhost/bucket/object", nil)
+		r.Header.Set(amzServerSideEncryptionCustomerAlgorithm, test.algorithm)
+		r.Header.Set(amzServerSideEncryptionCustomerKey, test.key)
+		r.Header.Set(amzServerSideEncryptionCustomerKeyMD5, test.keyMD5)
+		customerKey, errCode := parseSSECustomerKey(r)
+		if errCode != test.errCode {
+			t.Errorf("test %d: %v", i, errCode)
+			continue
+		}
+		if errCode == ErrNone && !bytes.Equal(customerKey.key, key) {
+			t.Errorf("test %d: key %x", i, customerKey.key)
+		}
+		if r.Header.Get(amzServerSideEncryptionCustomerKey) != "" {
+			t.Errorf("test %d: customer key left in the request to the filer", i)
+		}
+	}
+}
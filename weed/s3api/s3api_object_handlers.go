@@ -1,6 +1,7 @@
 package s3api
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/json"
 	"fmt"
@@ -9,6 +10,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/chrislusf/seaweedfs/weed/filer2"
 	"github.com/chrislusf/seaweedfs/weed/glog"
 	"github.com/chrislusf/seaweedfs/weed/server"
 	"github.com/gorilla/mux"
@@ -18,6 +20,8 @@ var (
 	client *http.Client
 )
 
+const amzStorageClass = "X-Amz-Storage-Class"
+
 func init() {
 	client = &http.Client{Transport: &http.Transport{
 		MaxIdleConnsPerHost: 1024,
@@ -46,6 +50,23 @@ func (s3a *S3ApiServer) PutObjectHandler(w http.ResponseWriter, r *http.Request)
 
 	uploadUrl := fmt.Sprintf("http://%s%s/%s%s?collection=%s",
 		s3a.option.Filer, s3a.option.BucketsPath, bucket, object, bucket)
+	if lifecycle := s3a.bucketLifecycle(context.Background(), bucket); lifecycle != nil {
+		if days := lifecycle.expirationDays(object); days > 0 {
+			uploadUrl += "&expire=" + expireQuery(days)
+		}
+	}
+
+	setObjectLockHeaders(r)
+
+	dataReader, errCode := encryptObject(r, dataReader)
+	if errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+	// only kept for the inventory, all objects are stored the same way
+	if storageClass := r.Header.Get(amzStorageClass); storageClass != "" {
+		setExtendedHeader(r.Header, filer2.ExtendedStorageClass, []byte(storageClass))
+	}
 
 	etag, errCode := s3a.putToFiler(r, uploadUrl, dataReader)
 
@@ -55,6 +76,7 @@ func (s3a *S3ApiServer) PutObjectHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	setEtag(w, etag)
+	setEncryptionResponseHeaders(w, r.Header)
 
 	writeSuccessResponseEmpty(w)
 }
@@ -73,7 +95,13 @@ func (s3a *S3ApiServer) GetObjectHandler(w http.ResponseWriter, r *http.Request)
 	destUrl := fmt.Sprintf("http://%s%s/%s%s",
 		s3a.option.Filer, s3a.option.BucketsPath, bucket, object)
 
-	s3a.proxyToFiler(w, r, destUrl, passThroughResponse)
+	customerKey, errCode := parseSSECustomerKey(r)
+	if errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+
+	s3a.proxyToFiler(w, r, destUrl, passThroughDecryptedResponse(r, customerKey))
 
 }
 
@@ -86,7 +114,13 @@ func (s3a *S3ApiServer) HeadObjectHandler(w http.ResponseWriter, r *http.Request
 	destUrl := fmt.Sprintf("http://%s%s/%s%s",
 		s3a.option.Filer, s3a.option.BucketsPath, bucket, object)
 
-	s3a.proxyToFiler(w, r, destUrl, passThroughResponse)
+	customerKey, errCode := parseSSECustomerKey(r)
+	if errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+
+	s3a.proxyToFiler(w, r, destUrl, passThroughDecryptedResponse(r, customerKey))
 
 }
 
@@ -99,7 +133,13 @@ func (s3a *S3ApiServer) DeleteObjectHandler(w http.ResponseWriter, r *http.Reque
 	destUrl := fmt.Sprintf("http://%s%s/%s%s",
 		s3a.option.Filer, s3a.option.BucketsPath, bucket, object)
 
+	setObjectLockHeaders(r)
+
 	s3a.proxyToFiler(w, r, destUrl, func(proxyResonse *http.Response, w http.ResponseWriter) {
+		if proxyResonse.StatusCode == http.StatusForbidden {
+			writeErrorResponse(w, ErrAccessDenied, r.URL)
+			return
+		}
 		for k, v := range proxyResonse.Header {
 			w.Header()[k] = v
 		}
@@ -147,12 +187,22 @@ func (s3a *S3ApiServer) proxyToFiler(w http.ResponseWriter, r *http.Request, des
 
 	responseFn(resp, w)
 }
-func passThroughResponse(proxyResonse *http.Response, w http.ResponseWriter) {
-	for k, v := range proxyResonse.Header {
-		w.Header()[k] = v
+
+// passThroughDecryptedResponse passes the object through, decrypted by the customer key if SSE-C encrypted
+func passThroughDecryptedResponse(r *http.Request, customerKey *sseCustomerKey) func(proxyResonse *http.Response, w http.ResponseWriter) {
+	return func(proxyResonse *http.Response, w http.ResponseWriter) {
+		body, errCode := decryptObject(customerKey, proxyResonse)
+		if errCode != ErrNone {
+			writeErrorResponse(w, errCode, r.URL)
+			return
+		}
+		for k, v := range proxyResonse.Header {
+			w.Header()[k] = v
+		}
+		setEncryptionResponseHeaders(w, proxyResonse.Header)
+		w.WriteHeader(proxyResonse.StatusCode)
+		io.Copy(w, body)
 	}
-	w.WriteHeader(proxyResonse.StatusCode)
-	io.Copy(w, proxyResonse.Body)
 }
 
 func (s3a *S3ApiServer) putToFiler(r *http.Request, uploadUrl string, dataReader io.ReadCloser) (etag string, code ErrorCode) {
@@ -188,6 +238,16 @@ func (s3a *S3ApiServer) putToFiler(r *http.Request, uploadUrl string, dataReader
 
 	etag = fmt.Sprintf("%x", hash.Sum(nil))
 
+	switch resp.StatusCode {
+	case http.StatusForbidden:
+		return "", ErrAccessDenied
+	case http.StatusBadRequest:
+		return "", ErrInvalidRequest
+	case http.StatusNotImplemented:
+		// the filer has no key to encrypt the chunks
+		return "", ErrNotImplemented
+	}
+
 	resp_body, ra_err := ioutil.ReadAll(resp.Body)
 	if ra_err != nil {
 		glog.Errorf("upload to filer response read: %v", ra_err)
@@ -0,0 +1,218 @@
+package s3api
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+	weed_server "github.com/chrislusf/seaweedfs/weed/server"
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	amzObjectLockMode            = "X-Amz-Object-Lock-Mode"
+	amzObjectLockRetainUntilDate = "X-Amz-Object-Lock-Retain-Until-Date"
+	amzObjectLockLegalHold       = "X-Amz-Object-Lock-Legal-Hold"
+	amzBypassGovernanceRetention = "X-Amz-Bypass-Governance-Retention"
+)
+
+// ObjectRetention is the body of PutObjectRetention and GetObjectRetention
+type ObjectRetention struct {
+	XMLName         xml.Name   `xml:"Retention"`
+	Mode            string     `xml:"Mode,omitempty"`
+	RetainUntilDate *time.Time `xml:"RetainUntilDate,omitempty"`
+}
+
+// ObjectLegalHold is the body of PutObjectLegalHold and GetObjectLegalHold
+type ObjectLegalHold struct {
+	XMLName xml.Name `xml:"LegalHold"`
+	Status  string   `xml:"Status"`
+}
+
+// GetObjectRetentionHandler - GET /bucket/object?retention
+func (s3a *S3ApiServer) GetObjectRetentionHandler(w http.ResponseWriter, r *http.Request) {
+
+	_, entry, errCode := s3a.lookupObjectEntry(context.Background(), mux.Vars(r))
+	if errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+
+	if entry.Attributes.RetentionMode == "" {
+		writeErrorResponse(w, ErrNoSuchObjectLockConfiguration, r.URL)
+		return
+	}
+
+	retainUntil := time.Unix(entry.Attributes.RetainUntil, 0).UTC()
+	writeSuccessResponseXML(w, encodeResponse(&ObjectRetention{
+		Mode:            entry.Attributes.RetentionMode,
+		RetainUntilDate: &retainUntil,
+	}))
+
+}
+
+// PutObjectRetentionHandler - PUT /bucket/object?retention
+// The retention can be extended, or with "x-amz-bypass-governance-retention: true", shortened or removed in GOVERNANCE mode.
+func (s3a *S3ApiServer) PutObjectRetentionHandler(w http.ResponseWriter, r *http.Request) {
+
+	var retention ObjectRetention
+	if errCode := readXmlBody(r, &retention); errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+	if (retention.Mode == "") != (retention.RetainUntilDate == nil) || !filer2.ValidRetentionMode(retention.Mode) {
+		writeErrorResponse(w, ErrMalformedXML, r.URL)
+		return
+	}
+	if retention.RetainUntilDate != nil && retention.RetainUntilDate.Before(time.Now()) {
+		writeErrorResponse(w, ErrInvalidRequest, r.URL)
+		return
+	}
+
+	ctx := context.Background()
+	dir, entry, errCode := s3a.lookupObjectEntry(ctx, mux.Vars(r))
+	if errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+
+	entry.Attributes.RetentionMode, entry.Attributes.RetainUntil = retention.Mode, 0
+	if retention.RetainUntilDate != nil {
+		entry.Attributes.RetainUntil = retention.RetainUntilDate.Unix()
+	}
+
+	if errCode = s3a.updateObjectEntry(ctx, dir, entry, r.Header.Get(amzBypassGovernanceRetention) == "true"); errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+
+	writeSuccessResponseEmpty(w)
+
+}
+
+// GetObjectLegalHoldHandler - GET /bucket/object?legal-hold
+func (s3a *S3ApiServer) GetObjectLegalHoldHandler(w http.ResponseWriter, r *http.Request) {
+
+	_, entry, errCode := s3a.lookupObjectEntry(context.Background(), mux.Vars(r))
+	if errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+
+	legalHold := &ObjectLegalHold{Status: "OFF"}
+	if entry.Attributes.LegalHold {
+		legalHold.Status = "ON"
+	}
+	writeSuccessResponseXML(w, encodeResponse(legalHold))
+
+}
+
+// PutObjectLegalHoldHandler - PUT /bucket/object?legal-hold
+func (s3a *S3ApiServer) PutObjectLegalHoldHandler(w http.ResponseWriter, r *http.Request) {
+
+	var legalHold ObjectLegalHold
+	if errCode := readXmlBody(r, &legalHold); errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+	if legalHold.Status != "ON" && legalHold.Status != "OFF" {
+		writeErrorResponse(w, ErrMalformedXML, r.URL)
+		return
+	}
+
+	ctx := context.Background()
+	dir, entry, errCode := s3a.lookupObjectEntry(ctx, mux.Vars(r))
+	if errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+
+	entry.Attributes.LegalHold = legalHold.Status == "ON"
+
+	if errCode = s3a.updateObjectEntry(ctx, dir, entry, false); errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+
+	writeSuccessResponseEmpty(w)
+
+}
+
+func readXmlBody(r *http.Request, v interface{}) ErrorCode {
+	body, err := ioutil.ReadAll(http.MaxBytesReader(nil, r.Body, 64*1024))
+	if err != nil {
+		return ErrInvalidRequest
+	}
+	if err = xml.Unmarshal(body, v); err != nil {
+		return ErrMalformedXML
+	}
+	return ErrNone
+}
+
+func (s3a *S3ApiServer) lookupObjectEntry(ctx context.Context, vars map[string]string) (dir string, entry *filer_pb.Entry, errCode ErrorCode) {
+
+	dir, name := filer2.FullPath(fmt.Sprintf("%s/%s%s", s3a.option.BucketsPath, vars["bucket"], getObject(vars))).DirAndName()
+
+	err := s3a.withFilerClient(ctx, func(client filer_pb.SeaweedFilerClient) error {
+		resp, err := client.LookupDirectoryEntry(ctx, &filer_pb.LookupDirectoryEntryRequest{
+			Directory: dir,
+			Name:      name,
+		})
+		if err != nil {
+			return err
+		}
+		entry = resp.Entry
+		return nil
+	})
+	if err != nil || entry.IsDirectory || entry.Attributes == nil {
+		glog.V(3).Infof("lookup %s/%s: %v", dir, name, err)
+		return dir, nil, ErrNoSuchKey
+	}
+
+	return dir, entry, ErrNone
+}
+
+func (s3a *S3ApiServer) updateObjectEntry(ctx context.Context, dir string, entry *filer_pb.Entry, bypassGovernanceRetention bool) ErrorCode {
+
+	err := s3a.withFilerClient(ctx, func(client filer_pb.SeaweedFilerClient) error {
+		_, err := client.UpdateEntry(ctx, &filer_pb.UpdateEntryRequest{
+			Directory:                 dir,
+			Entry:                     entry,
+			BypassGovernanceRetention: bypassGovernanceRetention,
+		})
+		return err
+	})
+	if err != nil {
+		glog.V(1).Infof("update %s/%s: %v", dir, entry.Name, err)
+		if status.Code(err) == codes.PermissionDenied {
+			return ErrAccessDenied
+		}
+		return ErrInternalError
+	}
+
+	return ErrNone
+}
+
+// setObjectLockHeaders translates the object lock headers of PutObject and DeleteObject for the filer
+func setObjectLockHeaders(r *http.Request) {
+	if mode := r.Header.Get(amzObjectLockMode); mode != "" {
+		r.Header.Set(weed_server.RetentionModeHeader, mode)
+	}
+	if retainUntil := r.Header.Get(amzObjectLockRetainUntilDate); retainUntil != "" {
+		r.Header.Set(weed_server.RetainUntilHeader, retainUntil)
+	}
+	if legalHold := r.Header.Get(amzObjectLockLegalHold); legalHold != "" {
+		r.Header.Set(weed_server.LegalHoldHeader, legalHold)
+	}
+	if r.Header.Get(amzBypassGovernanceRetention) == "true" {
+		r.Header.Set(weed_server.BypassGovernanceRetentionHeader, "true")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/chrislusf/seaweedfs/weed/server"
 	"github.com/gorilla/mux"
 	"net/http"
 	"net/url"
@@ -26,16 +27,40 @@ func (s3a *S3ApiServer) NewMultipartUploadHandler(w http.ResponseWriter, r *http
 	bucket = vars["bucket"]
 	object = vars["object"]
 
-	response, errCode := s3a.createMultipartUpload(context.Background(), &s3.CreateMultipartUploadInput{
+	customerKey, errCode := parseSSECustomerKey(r)
+	if errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+	if customerKey != nil {
+		// the parts encrypted by the customer keys are not supported
+		writeErrorResponse(w, ErrNotImplemented, r.URL)
+		return
+	}
+
+	input := &s3.CreateMultipartUploadInput{
 		Bucket: aws.String(bucket),
 		Key:    objectKey(aws.String(object)),
-	})
+	}
+	if sse := r.Header.Get(amzServerSideEncryption); sse != "" {
+		if sse != sseAlgorithm {
+			writeErrorResponse(w, ErrInvalidEncryptionAlgorithm, r.URL)
+			return
+		}
+		input.ServerSideEncryption = aws.String(sse)
+	}
+
+	response, errCode := s3a.createMultipartUpload(context.Background(), input)
 
 	if errCode != ErrNone {
 		writeErrorResponse(w, errCode, r.URL)
 		return
 	}
 
+	if input.ServerSideEncryption != nil {
+		w.Header().Set(amzServerSideEncryption, sseAlgorithm)
+	}
+
 	// println("NewMultipartUploadHandler", string(encodeResponse(response)))
 
 	writeSuccessResponseXML(w, encodeResponse(response))
@@ -178,9 +203,9 @@ func (s3a *S3ApiServer) PutObjectPartHandler(w http.ResponseWriter, r *http.Requ
 	ctx := context.Background()
 
 	uploadID := r.URL.Query().Get("uploadId")
-	exists, err := s3a.exists(ctx, s3a.genUploadsFolder(bucket), uploadID, true)
-	if !exists {
-		writeErrorResponse(w, ErrNoSuchUpload, r.URL)
+	uploadEntry, errCode := s3a.lookupUploadEntry(ctx, bucket, uploadID)
+	if errCode != ErrNone {
+		writeErrorResponse(w, errCode, r.URL)
 		return
 	}
 
@@ -203,6 +228,20 @@ func (s3a *S3ApiServer) PutObjectPartHandler(w http.ResponseWriter, r *http.Requ
 	uploadUrl := fmt.Sprintf("http://%s%s/%s/%04d.part?collection=%s",
 		s3a.option.Filer, s3a.genUploadsFolder(bucket), uploadID, partID-1, bucket)
 
+	removeExtendedHeaders(r.Header)
+	if customerKey, errCode := parseSSECustomerKey(r); errCode != ErrNone || customerKey != nil {
+		if errCode == ErrNone {
+			errCode = ErrNotImplemented
+		}
+		writeErrorResponse(w, errCode, r.URL)
+		return
+	}
+	// the parts are encrypted as asked when the upload is created
+	_, isEncrypted := uploadEntry.Extended[sseKey]
+	if isEncrypted {
+		r.Header.Set(weed_server.EncryptHeader, "true")
+	}
+
 	etag, errCode := s3a.putToFiler(r, uploadUrl, dataReader)
 
 	if errCode != ErrNone {
@@ -211,6 +250,9 @@ func (s3a *S3ApiServer) PutObjectPartHandler(w http.ResponseWriter, r *http.Requ
 	}
 
 	setEtag(w, etag)
+	if isEncrypted {
+		w.Header().Set(amzServerSideEncryption, sseAlgorithm)
+	}
 
 	writeSuccessResponseEmpty(w)
 
@@ -0,0 +1,204 @@
+package s3api
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/s3api/s3select"
+	"github.com/gorilla/mux"
+)
+
+const (
+	maxSelectRequestSize   = 256 * 1024
+	selectRecordsBatchSize = 128 * 1024
+)
+
+// SelectObjectContentRequest https://docs.aws.amazon.com/AmazonS3/latest/API/API_SelectObjectContent.html
+type SelectObjectContentRequest struct {
+	XMLName             xml.Name                     `xml:"SelectObjectContentRequest"`
+	Expression          string                       `xml:"Expression"`
+	ExpressionType      string                       `xml:"ExpressionType"`
+	InputSerialization  s3select.InputSerialization  `xml:"InputSerialization"`
+	OutputSerialization s3select.OutputSerialization `xml:"OutputSerialization"`
+}
+
+type selectStats struct {
+	XMLName        xml.Name `xml:"Stats"`
+	BytesScanned   int64    `xml:"BytesScanned"`
+	BytesProcessed int64    `xml:"BytesProcessed"`
+	BytesReturned  int64    `xml:"BytesReturned"`
+}
+
+// SelectObjectContentHandler runs a limited SQL expression over a CSV or JSON object,
+// streaming the object from the filer and only returning the matched records.
+func (s3a *S3ApiServer) SelectObjectContentHandler(w http.ResponseWriter, r *http.Request) {
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object := getObject(vars)
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxSelectRequestSize))
+	if err != nil {
+		writeErrorResponse(w, ErrInternalError, r.URL)
+		return
+	}
+	var request SelectObjectContentRequest
+	if err = xml.Unmarshal(body, &request); err != nil {
+		writeErrorResponse(w, ErrMalformedXML, r.URL)
+		return
+	}
+	if !strings.EqualFold(request.ExpressionType, "SQL") {
+		writeErrorResponse(w, ErrInvalidExpressionType, r.URL)
+		return
+	}
+	query, err := s3select.ParseQuery(request.Expression)
+	if err != nil {
+		glog.V(1).Infof("select %s%s: %v", bucket, object, err)
+		writeErrorResponse(w, ErrUnsupportedSyntax, r.URL)
+		return
+	}
+
+	srcUrl := fmt.Sprintf("http://%s%s/%s%s",
+		s3a.option.Filer, s3a.option.BucketsPath, bucket, object)
+	resp, err := client.Get(srcUrl)
+	if err != nil {
+		glog.Errorf("select get %s: %v", srcUrl, err)
+		writeErrorResponse(w, ErrInternalError, r.URL)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		writeErrorResponse(w, ErrNoSuchKey, r.URL)
+		return
+	}
+	if resp.StatusCode != http.StatusOK {
+		glog.Errorf("select get %s: status %d", srcUrl, resp.StatusCode)
+		writeErrorResponse(w, ErrInternalError, r.URL)
+		return
+	}
+
+	scanned := &countingReader{reader: resp.Body}
+	reader, err := s3select.NewRecordReader(scanned, request.InputSerialization)
+	if err != nil {
+		glog.V(1).Infof("select %s%s input: %v", bucket, object, err)
+		writeErrorResponse(w, ErrUnsupportedSyntax, r.URL)
+		return
+	}
+	events := &selectEventWriter{writer: w}
+	writer, err := s3select.NewRecordWriter(events, request.OutputSerialization)
+	if err != nil {
+		glog.V(1).Infof("select %s%s output: %v", bucket, object, err)
+		writeErrorResponse(w, ErrUnsupportedSyntax, r.URL)
+		return
+	}
+
+	setCommonHeaders(w)
+	w.WriteHeader(http.StatusOK)
+
+	if _, err = query.Run(reader, writer); err == nil {
+		err = events.flushRecords()
+	}
+	if err != nil {
+		glog.V(1).Infof("select %s%s: %v", bucket, object, err)
+		writeSelectEvent(w, [][2]string{
+			{":message-type", "error"},
+			{":error-code", "InternalError"},
+			{":error-message", err.Error()},
+		}, nil)
+		return
+	}
+
+	stats, _ := xml.Marshal(selectStats{
+		BytesScanned:   scanned.count,
+		BytesProcessed: scanned.count,
+		BytesReturned:  events.returned,
+	})
+	writeSelectEvent(w, [][2]string{
+		{":event-type", "Stats"},
+		{":content-type", "text/xml"},
+		{":message-type", "event"},
+	}, stats)
+	writeSelectEvent(w, [][2]string{
+		{":event-type", "End"},
+		{":message-type", "event"},
+	}, nil)
+
+}
+
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (c *countingReader) Read(p []byte) (n int, err error) {
+	n, err = c.reader.Read(p)
+	c.count += int64(n)
+	return
+}
+
+// selectEventWriter batches serialized records into "Records" events
+type selectEventWriter struct {
+	writer   http.ResponseWriter
+	buffer   bytes.Buffer
+	returned int64
+}
+
+func (s *selectEventWriter) Write(p []byte) (n int, err error) {
+	n, _ = s.buffer.Write(p)
+	s.returned += int64(n)
+	if s.buffer.Len() >= selectRecordsBatchSize {
+		err = s.flushRecords()
+	}
+	return
+}
+
+func (s *selectEventWriter) flushRecords() error {
+	if s.buffer.Len() == 0 {
+		return nil
+	}
+	err := writeSelectEvent(s.writer, [][2]string{
+		{":event-type", "Records"},
+		{":content-type", "application/octet-stream"},
+		{":message-type", "event"},
+	}, s.buffer.Bytes())
+	s.buffer.Reset()
+	return err
+}
+
+// writeSelectEvent encodes one message of the AWS event stream format:
+// total length, headers length, prelude crc, headers, payload, message crc.
+func writeSelectEvent(w http.ResponseWriter, headers [][2]string, payload []byte) error {
+	var headerBuf bytes.Buffer
+	for _, header := range headers {
+		headerBuf.WriteByte(byte(len(header[0])))
+		headerBuf.WriteString(header[0])
+		headerBuf.WriteByte(7) // string value type
+		binary.Write(&headerBuf, binary.BigEndian, uint16(len(header[1])))
+		headerBuf.WriteString(header[1])
+	}
+
+	totalLength := 4 + 4 + 4 + headerBuf.Len() + len(payload) + 4
+	var message bytes.Buffer
+	binary.Write(&message, binary.BigEndian, uint32(totalLength))
+	binary.Write(&message, binary.BigEndian, uint32(headerBuf.Len()))
+	binary.Write(&message, binary.BigEndian, crc32.ChecksumIEEE(message.Bytes()))
+	message.Write(headerBuf.Bytes())
+	message.Write(payload)
+	binary.Write(&message, binary.BigEndian, crc32.ChecksumIEEE(message.Bytes()))
+
+	if _, err := w.Write(message.Bytes()); err != nil {
+		return err
+	}
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
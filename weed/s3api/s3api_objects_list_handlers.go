@@ -2,10 +2,11 @@ package s3api
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/xml"
 	"fmt"
 	"net/http"
 	"net/url"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -18,8 +19,24 @@ import (
 
 const (
 	maxObjectListSizeLimit = 1000 // Limit number of objects in a listObjectsResponse.
+	listEntriesPageSize    = 1024 // Number of filer entries read in one request when listing objects.
 )
 
+type ListBucketResultV2 struct {
+	XMLName               xml.Name      `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListBucketResult"`
+	Name                  string        `xml:"Name"`
+	Prefix                string        `xml:"Prefix"`
+	MaxKeys               int           `xml:"MaxKeys"`
+	Delimiter             string        `xml:"Delimiter,omitempty"`
+	IsTruncated           bool          `xml:"IsTruncated"`
+	Contents              []ListEntry   `xml:"Contents,omitempty"`
+	CommonPrefixes        []PrefixEntry `xml:"CommonPrefixes,omitempty"`
+	ContinuationToken     string        `xml:"ContinuationToken,omitempty"`
+	NextContinuationToken string        `xml:"NextContinuationToken,omitempty"`
+	KeyCount              int           `xml:"KeyCount"`
+	StartAfter            string        `xml:"StartAfter,omitempty"`
+}
+
 func (s3a *S3ApiServer) ListObjectsV2Handler(w http.ResponseWriter, r *http.Request) {
 
 	// https://docs.aws.amazon.com/AmazonS3/latest/API/v2-RESTBucketGET.html
@@ -30,30 +47,49 @@ func (s3a *S3ApiServer) ListObjectsV2Handler(w http.ResponseWriter, r *http.Requ
 
 	glog.V(4).Infof("read v2: %v", vars)
 
-	originalPrefix, marker, startAfter, delimiter, _, maxKeys := getListObjectsV2Args(r.URL.Query())
+	originalPrefix, continuationToken, startAfter, delimiter, _, maxKeys := getListObjectsV2Args(r.URL.Query())
 
 	if maxKeys < 0 {
 		writeErrorResponse(w, ErrInvalidMaxKeys, r.URL)
 		return
 	}
-	if delimiter != "" && delimiter != "/" {
-		writeErrorResponse(w, ErrNotImplemented, r.URL)
-		return
-	}
 
-	if marker == "" {
-		marker = startAfter
+	marker := startAfter
+	if continuationToken != "" {
+		decoded, err := decodeContinuationToken(continuationToken)
+		if err != nil {
+			writeErrorResponse(w, ErrInvalidContinuationToken, r.URL)
+			return
+		}
+		marker = decoded
 	}
 
 	ctx := context.Background()
 
-	response, err := s3a.listFilerEntries(ctx, bucket, originalPrefix, maxKeys, marker)
+	listing, err := s3a.listFilerEntries(ctx, bucket, originalPrefix, delimiter, maxKeys, marker)
 
 	if err != nil {
+		glog.Errorf("list %s/%s: %v", bucket, originalPrefix, err)
 		writeErrorResponse(w, ErrInternalError, r.URL)
 		return
 	}
 
+	response := ListBucketResultV2{
+		Name:              bucket,
+		Prefix:            originalPrefix,
+		MaxKeys:           maxKeys,
+		Delimiter:         delimiter,
+		IsTruncated:       listing.isTruncated,
+		Contents:          listing.contents,
+		CommonPrefixes:    listing.commonPrefixes,
+		ContinuationToken: continuationToken,
+		KeyCount:          listing.keyCount,
+		StartAfter:        startAfter,
+	}
+	if listing.isTruncated {
+		response.NextContinuationToken = encodeContinuationToken(listing.lastKey)
+	}
+
 	writeSuccessResponseXML(w, encodeResponse(response))
 }
 
@@ -73,94 +109,223 @@ func (s3a *S3ApiServer) ListObjectsV1Handler(w http.ResponseWriter, r *http.Requ
 		writeErrorResponse(w, ErrInvalidMaxKeys, r.URL)
 		return
 	}
-	if delimiter != "" && delimiter != "/" {
-		writeErrorResponse(w, ErrNotImplemented, r.URL)
-		return
-	}
 
-	response, err := s3a.listFilerEntries(ctx, bucket, originalPrefix, maxKeys, marker)
+	listing, err := s3a.listFilerEntries(ctx, bucket, originalPrefix, delimiter, maxKeys, marker)
 
 	if err != nil {
+		glog.Errorf("list %s/%s: %v", bucket, originalPrefix, err)
 		writeErrorResponse(w, ErrInternalError, r.URL)
 		return
 	}
 
+	response := ListBucketResult{
+		Name:           bucket,
+		Prefix:         originalPrefix,
+		Marker:         marker,
+		MaxKeys:        maxKeys,
+		Delimiter:      delimiter,
+		IsTruncated:    listing.isTruncated,
+		Contents:       listing.contents,
+		CommonPrefixes: listing.commonPrefixes,
+	}
+	if listing.isTruncated {
+		response.NextMarker = listing.lastKey
+	}
+
 	writeSuccessResponseXML(w, encodeResponse(response))
 }
 
-func (s3a *S3ApiServer) listFilerEntries(ctx context.Context, bucket, originalPrefix string, maxKeys int, marker string) (response ListBucketResult, err error) {
+func (s3a *S3ApiServer) listFilerEntries(ctx context.Context, bucket, originalPrefix, delimiter string, maxKeys int, marker string) (listing *objectListing, err error) {
 
 	// convert full path prefix into directory name and prefix for entry name
-	dir, prefix := filepath.Split(originalPrefix)
-	if strings.HasPrefix(dir, "/") {
-		dir = dir[1:]
+	originalPrefix = strings.TrimPrefix(originalPrefix, "/")
+	dir, prefix := "", originalPrefix
+	if i := strings.LastIndex(originalPrefix, "/"); i >= 0 {
+		dir, prefix = originalPrefix[:i+1], originalPrefix[i+1:]
 	}
 
+	bucketDir := fmt.Sprintf("%s/%s/", s3a.option.BucketsPath, bucket)
+
 	// check filer
 	err = s3a.withFilerClient(ctx, func(client filer_pb.SeaweedFilerClient) error {
 
-		request := &filer_pb.ListEntriesRequest{
-			Directory:          fmt.Sprintf("%s/%s/%s", s3a.option.BucketsPath, bucket, dir),
-			Prefix:             prefix,
-			Limit:              uint32(maxKeys + 1),
-			StartFromFileName:  marker,
-			InclusiveStartFrom: false,
+		listing = newObjectListing(originalPrefix, delimiter, maxKeys, marker,
+			func(dir, prefix, startFrom string, inclusive bool, limit int) ([]*filer_pb.Entry, error) {
+				request := &filer_pb.ListEntriesRequest{
+					Directory:          bucketDir + dir,
+					Prefix:             prefix,
+					Limit:              uint32(limit),
+					StartFromFileName:  startFrom,
+					InclusiveStartFrom: inclusive,
+				}
+				resp, err := client.ListEntries(ctx, request)
+				if err != nil {
+					return nil, fmt.Errorf("list %s: %v", request.Directory, err)
+				}
+				return resp.Entries, nil
+			})
+
+		// the marker before the directory starts from the beginning, and after it lists nothing
+		dirMarker := ""
+		if strings.HasPrefix(marker, dir) {
+			dirMarker = marker[len(dir):]
+		} else if marker > dir {
+			return nil
+		}
+		if err := listing.listDir(dir, prefix, dirMarker); err != nil {
+			return err
 		}
 
-		resp, err := client.ListEntries(ctx, request)
+		glog.V(4).Infof("read directory: %s%s, found: %d, truncated: %v", bucketDir, dir, listing.keyCount, listing.isTruncated)
+
+		return nil
+	})
+
+	return
+}
+
+// listEntriesFunc lists at most limit entries of the directory, relative to the bucket, in the name order
+type listEntriesFunc func(dir, prefix, startFrom string, inclusive bool, limit int) ([]*filer_pb.Entry, error)
+
+// objectListing walks the bucket directories in the name order, as the flat keys after the marker.
+// The keys having the delimiter after the prefix are rolled up into the common prefixes,
+// and the directories are only read if the keys inside may not be rolled up.
+type objectListing struct {
+	list      listEntriesFunc
+	prefix    string
+	delimiter string
+	maxKeys   int
+	marker    string
+
+	contents       []ListEntry
+	commonPrefixes []PrefixEntry
+	keyCount       int
+	lastKey        string
+	isTruncated    bool
+}
+
+func newObjectListing(prefix, delimiter string, maxKeys int, marker string, list listEntriesFunc) *objectListing {
+	if maxKeys > maxObjectListSizeLimit {
+		maxKeys = maxObjectListSizeLimit
+	}
+	return &objectListing{
+		list:      list,
+		prefix:    prefix,
+		delimiter: delimiter,
+		maxKeys:   maxKeys,
+		marker:    marker,
+	}
+}
+
+// listDir lists the entries of the directory with the name prefix, after the marker relative to the directory.
+// The directory is relative to the bucket, and ends with "/" unless it is the bucket itself.
+func (l *objectListing) listDir(dir, namePrefix, marker string) error {
+
+	// only the entries from the first marker component need to be read
+	markerName, markerRest, markerInside := marker, "", false
+	if i := strings.Index(marker, "/"); i >= 0 {
+		markerName, markerRest, markerInside = marker[:i], marker[i+1:], true
+	}
+
+	startFrom, inclusive := markerName, true
+	for {
+		entries, err := l.list(dir, namePrefix, startFrom, inclusive, listEntriesPageSize)
 		if err != nil {
-			return fmt.Errorf("list buckets: %v", err)
+			return err
 		}
+		for _, entry := range entries {
+			startFrom, inclusive = entry.Name, false
 
-		var contents []ListEntry
-		var commonPrefixes []PrefixEntry
-		var counter int
-		var lastEntryName string
-		var isTruncated bool
-		for _, entry := range resp.Entries {
-			counter++
-			if counter > maxKeys {
-				isTruncated = true
-				break
+			atMarker := marker != "" && entry.Name == markerName
+			key := dir + entry.Name
+			if entry.IsDirectory {
+				key += "/"
+			} else if atMarker {
+				// the marker itself, or the keys inside it if it was a directory
+				continue
 			}
-			lastEntryName = entry.Name
+
+			if commonPrefix := l.commonPrefix(key); commonPrefix != "" {
+				if strings.HasPrefix(l.marker, commonPrefix) {
+					continue
+				}
+				if len(l.commonPrefixes) > 0 && l.commonPrefixes[len(l.commonPrefixes)-1].Prefix == commonPrefix {
+					continue
+				}
+				if !l.add(commonPrefix) {
+					return nil
+				}
+				l.commonPrefixes = append(l.commonPrefixes, PrefixEntry{Prefix: commonPrefix})
+				continue
+			}
+
 			if entry.IsDirectory {
-				commonPrefixes = append(commonPrefixes, PrefixEntry{
-					Prefix: fmt.Sprintf("%s%s/", dir, entry.Name),
-				})
-			} else {
-				contents = append(contents, ListEntry{
-					Key:          fmt.Sprintf("%s%s", dir, entry.Name),
-					LastModified: time.Unix(entry.Attributes.Mtime, 0),
-					ETag:         "\"" + filer2.ETag(entry.Chunks) + "\"",
-					Size:         int64(filer2.TotalSize(entry.Chunks)),
-					Owner: CanonicalUser{
-						ID:          fmt.Sprintf("%x", entry.Attributes.Uid),
-						DisplayName: entry.Attributes.UserName,
-					},
-					StorageClass: "STANDARD",
-				})
+				subMarker := ""
+				if atMarker && markerInside {
+					subMarker = markerRest
+				}
+				if err = l.listDir(key, "", subMarker); err != nil || l.isTruncated {
+					return err
+				}
+				continue
 			}
-		}
 
-		response = ListBucketResult{
-			Name:           bucket,
-			Prefix:         originalPrefix,
-			Marker:         marker,
-			NextMarker:     lastEntryName,
-			MaxKeys:        maxKeys,
-			Delimiter:      "/",
-			IsTruncated:    isTruncated,
-			Contents:       contents,
-			CommonPrefixes: commonPrefixes,
+			if !l.add(key) {
+				return nil
+			}
+			l.contents = append(l.contents, ListEntry{
+				Key:          key,
+				LastModified: time.Unix(entry.Attributes.Mtime, 0),
+				ETag:         "\"" + filer2.ETag(entry.Chunks) + "\"",
+				Size:         int64(filer2.FileSize(entry)),
+				Owner: CanonicalUser{
+					ID:          fmt.Sprintf("%x", entry.Attributes.Uid),
+					DisplayName: entry.Attributes.UserName,
+				},
+				StorageClass: "STANDARD",
+			})
+		}
+		if len(entries) < listEntriesPageSize {
+			return nil
 		}
+	}
+}
 
-		glog.V(4).Infof("read directory: %v, found: %v, %+v", request, counter, response)
+// commonPrefix returns the key up to the first delimiter after the prefix, or empty if the key is not rolled up.
+// The directory keys end with "/", so they are rolled up with the "/" delimiter.
+func (l *objectListing) commonPrefix(key string) string {
+	if l.delimiter == "" {
+		return ""
+	}
+	i := strings.Index(key[len(l.prefix):], l.delimiter)
+	if i < 0 {
+		return ""
+	}
+	return key[:len(l.prefix)+i+len(l.delimiter)]
+}
 
-		return nil
-	})
+// add counts one more key or common prefix, and returns false if the listing is full
+func (l *objectListing) add(key string) bool {
+	if l.keyCount >= l.maxKeys {
+		l.isTruncated = true
+		return false
+	}
+	l.keyCount++
+	l.lastKey = key
+	return true
+}
 
-	return
+// the continuation tokens are opaque to the clients, and only resume from the last key
+func encodeContinuationToken(key string) string {
+	return base64.StdEncoding.EncodeToString([]byte(key))
+}
+
+func decodeContinuationToken(token string) (string, error) {
+	key, err := base64.StdEncoding.DecodeString(token)
+	if err != nil {
+		return "", err
+	}
+	return string(key), nil
 }
 
 func getListObjectsV2Args(values url.Values) (prefix, token, startAfter, delimiter string, fetchOwner bool, maxkeys int) {
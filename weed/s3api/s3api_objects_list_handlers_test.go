@@ -1,8 +1,12 @@
 package s3api
 
 import (
+	"sort"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
 )
 
 func TestListObjectsHandler(t *testing.T) {
@@ -36,3 +40,98 @@ func TestListObjectsHandler(t *testing.T) {
 		t.Errorf("unexpected output: %s\nexpecting:%s", encoded, expected)
 	}
 }
+
+// listKeys lists the bucket with the keys stored as the filer directories and files
+func listKeys(keys []string, prefix, delimiter string, maxKeys int, marker string) *objectListing {
+	dirs := make(map[string][]*filer_pb.Entry)
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		parts := strings.Split(key, "/")
+		dir := ""
+		for i, name := range parts {
+			if !seen[dir+name] {
+				seen[dir+name] = true
+				dirs[dir] = append(dirs[dir], &filer_pb.Entry{
+					Name:        name,
+					IsDirectory: i < len(parts)-1,
+					Attributes:  &filer_pb.FuseAttributes{},
+				})
+			}
+			dir += name + "/"
+		}
+	}
+	for _, entries := range dirs {
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	}
+
+	listing := newObjectListing(prefix, delimiter, maxKeys, marker,
+		func(dir, namePrefix, startFrom string, inclusive bool, limit int) (found []*filer_pb.Entry, err error) {
+			for _, entry := range dirs[dir] {
+				if entry.Name < startFrom || !inclusive && entry.Name == startFrom || !strings.HasPrefix(entry.Name, namePrefix) {
+					continue
+				}
+				if len(found) < limit {
+					found = append(found, entry)
+				}
+			}
+			return
+		})
+	dir := prefix[:strings.LastIndex(prefix, "/")+1]
+	listing.listDir(dir, prefix[len(dir):], strings.TrimPrefix(marker, dir))
+	return listing
+}
+
+func listedKeys(listing *objectListing) string {
+	var keys []string
+	for _, commonPrefix := range listing.commonPrefixes {
+		keys = append(keys, commonPrefix.Prefix)
+	}
+	for _, content := range listing.contents {
+		keys = append(keys, content.Key)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, " ")
+}
+
+func TestObjectListing(t *testing.T) {
+	keys := []string{"a.txt", "photos/2019/1.jpg", "photos/2019/2.jpg", "photos/2020/1.jpg", "photos/x.jpg", "z"}
+
+	tests := []struct {
+		prefix, delimiter string
+		expected          string
+	}{
+		{"", "/", "a.txt photos/ z"},
+		{"photos/", "/", "photos/2019/ photos/2020/ photos/x.jpg"},
+		{"photos/2", "", "photos/2019/1.jpg photos/2019/2.jpg photos/2020/1.jpg"},
+		{"photos/20", "1", "photos/201 photos/2020/1"},
+		{"", "", strings.Join(keys, " ")},
+		{"nothing", "/", ""},
+	}
+	for _, test := range tests {
+		listing := listKeys(keys, test.prefix, test.delimiter, 1000, "")
+		if got := listedKeys(listing); got != test.expected || listing.isTruncated {
+			t.Errorf("list %q by %q: %s, expecting %s", test.prefix, test.delimiter, got, test.expected)
+		}
+	}
+
+	// the pages resume from the last key or common prefix
+	for _, delimiter := range []string{"", "/"} {
+		var pages []string
+		marker := ""
+		for {
+			listing := listKeys(keys, "", delimiter, 2, marker)
+			pages = append(pages, listedKeys(listing))
+			if !listing.isTruncated {
+				break
+			}
+			marker = listing.lastKey
+		}
+		expected := "a.txt photos/2019/1.jpg|photos/2019/2.jpg photos/2020/1.jpg|photos/x.jpg z"
+		if delimiter == "/" {
+			expected = "a.txt photos/|z"
+		}
+		if got := strings.Join(pages, "|"); got != expected {
+			t.Errorf("pages by %q: %s, expecting %s", delimiter, got, expected)
+		}
+	}
+}
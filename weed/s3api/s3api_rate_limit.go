@@ -0,0 +1,100 @@
+package s3api
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/util"
+)
+
+// the key of the total limits, as the client ips and the access keys are never empty
+const totalRateLimitKey = ""
+
+// rateLimiter limits the requests and the bytes per second, in total, by each client ip, and by each access key
+type rateLimiter struct {
+	total        *util.RateLimiters
+	perClient    *util.RateLimiters
+	perAccessKey *util.RateLimiters
+}
+
+func newRateLimiter(option *S3ApiServerOption) *rateLimiter {
+	return &rateLimiter{
+		total:        util.NewRateLimiters(option.RateLimit, nil),
+		perClient:    util.NewRateLimiters(option.RateLimitPerClient, nil),
+		perAccessKey: util.NewRateLimiters(option.RateLimitPerAccessKey, option.AccessKeyRateLimits),
+	}
+}
+
+// allowRequest checks the narrower limit first, so the requests over it do not take from the total.
+// The access keys are not verified yet, so the requests are only limited by the client ip and in total here.
+func (l *rateLimiter) allowRequest(clientIp string) bool {
+	return l.perClient.AllowRequest(clientIp) && l.total.AllowRequest(totalRateLimitKey)
+}
+
+// allowAccessKey checks the limit of the access key, after the request signature is verified
+func (l *rateLimiter) allowAccessKey(accessKey string) bool {
+	return accessKey == "" || l.perAccessKey.AllowRequest(accessKey)
+}
+
+func (l *rateLimiter) waitBytes(clientIp string) func(ctx context.Context, n int) error {
+	return func(ctx context.Context, n int) error {
+		if err := l.perClient.WaitBytes(ctx, clientIp, n); err != nil {
+			return err
+		}
+		return l.total.WaitBytes(ctx, totalRateLimitKey, n)
+	}
+}
+
+func (l *rateLimiter) waitAccessKeyBytes(accessKey string) func(ctx context.Context, n int) error {
+	return func(ctx context.Context, n int) error {
+		return l.perAccessKey.WaitBytes(ctx, accessKey, n)
+	}
+}
+
+// limitRate rejects the requests over the limits of the client ip and in total with SlowDown,
+// and slows down the request and the response bodies to the bytes per second.
+// The limits of the access keys are applied by limitAccessKey after the signatures are verified,
+// so the requests can not take from the others' limits, or escape their own, by naming another access key.
+func (s3a *S3ApiServer) limitRate(next http.Handler) http.Handler {
+	if !s3a.rateLimiter.total.IsEnabled() && !s3a.rateLimiter.perClient.IsEnabled() {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientIp, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			clientIp = r.RemoteAddr
+		}
+		if !s3a.rateLimiter.allowRequest(clientIp) {
+			glog.V(1).Infof("slow down %s %s from %s", r.Method, r.URL.Path, clientIp)
+			w.Header().Set("Retry-After", "1")
+			writeErrorResponse(w, ErrSlowDown, r.URL)
+			return
+		}
+		wait := s3a.rateLimiter.waitBytes(clientIp)
+		if r.Body != nil {
+			r.Body = util.NewRateLimitedBody(r.Context(), r.Body, wait)
+		}
+		next.ServeHTTP(util.NewRateLimitedResponseWriter(r.Context(), w, wait), r)
+	})
+}
+
+// limitAccessKey applies the limits of the verified access key, and returns the response writer
+// slowed down to its bytes per second, or false if the request is rejected with SlowDown.
+func (s3a *S3ApiServer) limitAccessKey(w http.ResponseWriter, r *http.Request, accessKey string) (http.ResponseWriter, bool) {
+	if accessKey == "" || !s3a.rateLimiter.perAccessKey.IsEnabled() {
+		return w, true
+	}
+	if !s3a.rateLimiter.allowAccessKey(accessKey) {
+		glog.V(1).Infof("slow down %s %s by %s", r.Method, r.URL.Path, accessKey)
+		w.Header().Set("Retry-After", "1")
+		writeErrorResponse(w, ErrSlowDown, r.URL)
+		return w, false
+	}
+	wait := s3a.rateLimiter.waitAccessKeyBytes(accessKey)
+	if r.Body != nil {
+		r.Body = util.NewRateLimitedBody(r.Context(), r.Body, wait)
+	}
+	return util.NewRateLimitedResponseWriter(r.Context(), w, wait), true
+}
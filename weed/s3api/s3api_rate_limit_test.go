@@ -0,0 +1,101 @@
+package s3api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/util"
+)
+
+func TestRateLimiterAllowRequest(t *testing.T) {
+	l := newRateLimiter(&S3ApiServerOption{
+		RateLimit:             util.RateLimit{RequestsPerSecond: 3},
+		RateLimitPerClient:    util.RateLimit{RequestsPerSecond: 2},
+		RateLimitPerAccessKey: util.RateLimit{RequestsPerSecond: 1},
+		AccessKeyRateLimits:   map[string]util.RateLimit{"vip": {RequestsPerSecond: 3}},
+	})
+
+	if !l.allowRequest("10.0.0.1") || !l.allowRequest("10.0.0.1") {
+		t.Errorf("requests within the client limit rejected")
+	}
+	if l.allowRequest("10.0.0.1") {
+		t.Errorf("request over the client limit allowed")
+	}
+	if !l.allowRequest("10.0.0.2") {
+		t.Errorf("another client rejected")
+	}
+	if l.allowRequest("10.0.0.3") {
+		t.Errorf("request over the total limit allowed")
+	}
+
+	if !l.allowAccessKey("key1") || l.allowAccessKey("key1") {
+		t.Errorf("access key limit not applied")
+	}
+	for i := 0; i < 3; i++ {
+		if !l.allowAccessKey("vip") {
+			t.Errorf("request %d within the access key own limit rejected", i)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if !l.allowAccessKey("") {
+			t.Errorf("anonymous requests are not limited by the access keys")
+		}
+	}
+}
+
+func TestLimitRate(t *testing.T) {
+	s3a := &S3ApiServer{option: &S3ApiServerOption{
+		Credentials: map[string]string{"victim": "secret"},
+	}}
+	s3a.rateLimiter = newRateLimiter(&S3ApiServerOption{
+		RateLimitPerClient:    util.RateLimit{RequestsPerSecond: 1},
+		RateLimitPerAccessKey: util.RateLimit{RequestsPerSecond: 1},
+	})
+
+	var served int
+	handler := s3a.limitRate(s3a.authorize(actionGetObject, func(w http.ResponseWriter, r *http.Request) {
+		served++
+	}))
+	request := func(clientIp string, accessKey string) int {
+		r := httptest.NewRequest("GET", "/bucket/object", nil)
+		r.RemoteAddr = clientIp + ":1234"
+		if accessKey != "" {
+			// the signature can not be verified without the secret key
+			r.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+accessKey+"/20130524/us-east-1/s3/aws4_request,"+
+				"SignedHeaders=host;x-amz-date,Signature=0000")
+			r.Header.Set("X-Amz-Date", time.Now().UTC().Format(iso8601Format))
+			r.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	// the forged requests naming the victim are rejected before taking from the victim's limit
+	if code := request("10.0.0.1", "victim"); code != http.StatusForbidden {
+		t.Errorf("forged request: %d", code)
+	}
+	if code := request("10.0.0.1", "victim"); code != http.StatusServiceUnavailable {
+		t.Errorf("second request from the same ip should slow down: %d", code)
+	}
+	if !s3a.rateLimiter.allowAccessKey("victim") {
+		t.Errorf("forged requests took from the victim's limit")
+	}
+
+	// the verified access keys are limited on their own
+	for i, expected := range []bool{true, false} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/bucket/object", nil)
+		if _, allowed := s3a.limitAccessKey(w, r, "key1"); allowed != expected {
+			t.Errorf("request %d of key1 allowed: %v", i, allowed)
+		}
+		if !expected && w.Code != http.StatusServiceUnavailable {
+			t.Errorf("rejected request of key1: %d", w.Code)
+		}
+	}
+	if served != 0 {
+		t.Errorf("served %d unverified requests", served)
+	}
+}
@@ -1,15 +1,20 @@
 package s3api
 
 import (
+	_ "github.com/chrislusf/seaweedfs/weed/audit/file"
+	_ "github.com/chrislusf/seaweedfs/weed/audit/kafka"
+	_ "github.com/chrislusf/seaweedfs/weed/audit/syslog"
 	_ "github.com/chrislusf/seaweedfs/weed/filer2/cassandra"
 	_ "github.com/chrislusf/seaweedfs/weed/filer2/leveldb"
 	_ "github.com/chrislusf/seaweedfs/weed/filer2/memdb"
 	_ "github.com/chrislusf/seaweedfs/weed/filer2/mysql"
 	_ "github.com/chrislusf/seaweedfs/weed/filer2/postgres"
 	_ "github.com/chrislusf/seaweedfs/weed/filer2/redis"
+	"github.com/chrislusf/seaweedfs/weed/util"
 	"github.com/gorilla/mux"
 	"google.golang.org/grpc"
 	"net/http"
+	"time"
 )
 
 type S3ApiServerOption struct {
@@ -18,16 +23,32 @@ type S3ApiServerOption struct {
 	DomainName       string
 	BucketsPath      string
 	GrpcDialOption   grpc.DialOption
+	// the secret keys by the access keys, all requests are allowed if empty
+	Credentials map[string]string
+	// the interval to flush the server access logs into the target buckets
+	AccessLogFlushInterval time.Duration
+	// the buckets are served as static websites at "{bucket}.{WebsiteDomainName}"
+	WebsiteDomainName string
+	// the requests and the bytes per second, in total, by each client ip, and by each access key
+	RateLimit             util.RateLimit
+	RateLimitPerClient    util.RateLimit
+	RateLimitPerAccessKey util.RateLimit
+	// the limits of the access keys instead of the RateLimitPerAccessKey
+	AccessKeyRateLimits map[string]util.RateLimit
 }
 
 type S3ApiServer struct {
-	option *S3ApiServerOption
+	option      *S3ApiServerOption
+	accessLog   *accessLogger
+	rateLimiter *rateLimiter
 }
 
 func NewS3ApiServer(router *mux.Router, option *S3ApiServerOption) (s3ApiServer *S3ApiServer, err error) {
 	s3ApiServer = &S3ApiServer{
 		option: option,
 	}
+	s3ApiServer.accessLog = newAccessLogger(s3ApiServer, option.AccessLogFlushInterval)
+	s3ApiServer.rateLimiter = newRateLimiter(option)
 
 	s3ApiServer.registerRouter(router)
 
@@ -35,6 +56,16 @@ func NewS3ApiServer(router *mux.Router, option *S3ApiServerOption) (s3ApiServer
 }
 
 func (s3a *S3ApiServer) registerRouter(router *mux.Router) {
+	// Website Router, the buckets served as static websites, only reading the objects
+	if s3a.option.WebsiteDomainName != "" {
+		website := router.Host("{bucket:.+}." + s3a.option.WebsiteDomainName).Subrouter()
+		website.Use(s3a.logAccess, s3a.limitRate)
+		website.Methods("GET", "HEAD").HandlerFunc(s3a.WebsiteHandler)
+		website.PathPrefix("/").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			writeErrorResponse(w, ErrMethodNotAllowed, r.URL)
+		})
+	}
+
 	// API Router
 	apiRouter := router.PathPrefix("/").Subrouter()
 	var routers []*mux.Router
@@ -42,66 +73,96 @@ func (s3a *S3ApiServer) registerRouter(router *mux.Router) {
 		routers = append(routers, apiRouter.Host("{bucket:.+}."+s3a.option.DomainName).Subrouter())
 	}
 	routers = append(routers, apiRouter.PathPrefix("/{bucket}").Subrouter())
+	// the requests slowed down by the rate limits are also logged
+	apiRouter.Use(s3a.logAccess, s3a.limitRate)
 
 	for _, bucket := range routers {
 
 		// HeadObject
-		bucket.Methods("HEAD").Path("/{object:.+}").HandlerFunc(s3a.HeadObjectHandler)
+		bucket.Methods("HEAD").Path("/{object:.+}").HandlerFunc(s3a.authorize(actionGetObject, s3a.HeadObjectHandler))
 		// HeadBucket
-		bucket.Methods("HEAD").HandlerFunc(s3a.HeadBucketHandler)
+		bucket.Methods("HEAD").HandlerFunc(s3a.authorize(actionListBucket, s3a.HeadBucketHandler))
 
 		// PutObjectPart
-		bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(s3a.PutObjectPartHandler).Queries("partNumber", "{partNumber:[0-9]+}", "uploadId", "{uploadId:.*}")
+		bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(s3a.authorize(actionPutObject, s3a.PutObjectPartHandler)).Queries("partNumber", "{partNumber:[0-9]+}", "uploadId", "{uploadId:.*}")
 		// CompleteMultipartUpload
-		bucket.Methods("POST").Path("/{object:.+}").HandlerFunc(s3a.CompleteMultipartUploadHandler).Queries("uploadId", "{uploadId:.*}")
+		bucket.Methods("POST").Path("/{object:.+}").HandlerFunc(s3a.authorize(actionPutObject, s3a.CompleteMultipartUploadHandler)).Queries("uploadId", "{uploadId:.*}")
 		// NewMultipartUpload
-		bucket.Methods("POST").Path("/{object:.+}").HandlerFunc(s3a.NewMultipartUploadHandler).Queries("uploads", "")
+		bucket.Methods("POST").Path("/{object:.+}").HandlerFunc(s3a.authorize(actionPutObject, s3a.NewMultipartUploadHandler)).Queries("uploads", "")
 		// AbortMultipartUpload
-		bucket.Methods("DELETE").Path("/{object:.+}").HandlerFunc(s3a.AbortMultipartUploadHandler).Queries("uploadId", "{uploadId:.*}")
+		bucket.Methods("DELETE").Path("/{object:.+}").HandlerFunc(s3a.authorize(actionAbortMultipartUpload, s3a.AbortMultipartUploadHandler)).Queries("uploadId", "{uploadId:.*}")
 		// ListObjectParts
-		bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(s3a.ListObjectPartsHandler).Queries("uploadId", "{uploadId:.*}")
+		bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(s3a.authorize(actionListMultipartUploadParts, s3a.ListObjectPartsHandler)).Queries("uploadId", "{uploadId:.*}")
 		// ListMultipartUploads
-		bucket.Methods("GET").HandlerFunc(s3a.ListMultipartUploadsHandler).Queries("uploads", "")
-
+		bucket.Methods("GET").HandlerFunc(s3a.authorize(actionListBucketMultipartUploads, s3a.ListMultipartUploadsHandler)).Queries("uploads", "")
+
+		// GetObjectRetention
+		bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(s3a.authorize(actionGetObjectRetention, s3a.GetObjectRetentionHandler)).Queries("retention", "")
+		// PutObjectRetention
+		bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(s3a.authorize(actionPutObjectRetention, s3a.PutObjectRetentionHandler)).Queries("retention", "")
+		// GetObjectLegalHold
+		bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(s3a.authorize(actionGetObjectLegalHold, s3a.GetObjectLegalHoldHandler)).Queries("legal-hold", "")
+		// PutObjectLegalHold
+		bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(s3a.authorize(actionPutObjectLegalHold, s3a.PutObjectLegalHoldHandler)).Queries("legal-hold", "")
+
+		// GetBucketPolicy
+		bucket.Methods("GET").HandlerFunc(s3a.authorize(actionGetBucketPolicy, s3a.GetBucketPolicyHandler)).Queries("policy", "")
+		// PutBucketPolicy
+		bucket.Methods("PUT").HandlerFunc(s3a.authorize(actionPutBucketPolicy, s3a.PutBucketPolicyHandler)).Queries("policy", "")
+		// DeleteBucketPolicy
+		bucket.Methods("DELETE").HandlerFunc(s3a.authorize(actionDeleteBucketPolicy, s3a.DeleteBucketPolicyHandler)).Queries("policy", "")
+		// GetBucketLogging
+		bucket.Methods("GET").HandlerFunc(s3a.authorize(actionGetBucketLogging, s3a.GetBucketLoggingHandler)).Queries("logging", "")
+		// PutBucketLogging
+		bucket.Methods("PUT").HandlerFunc(s3a.authorize(actionPutBucketLogging, s3a.PutBucketLoggingHandler)).Queries("logging", "")
+		// GetBucketWebsite
+		bucket.Methods("GET").HandlerFunc(s3a.authorize(actionGetBucketWebsite, s3a.GetBucketWebsiteHandler)).Queries("website", "")
+		// PutBucketWebsite
+		bucket.Methods("PUT").HandlerFunc(s3a.authorize(actionPutBucketWebsite, s3a.PutBucketWebsiteHandler)).Queries("website", "")
+		// DeleteBucketWebsite
+		bucket.Methods("DELETE").HandlerFunc(s3a.authorize(actionDeleteBucketWebsite, s3a.DeleteBucketWebsiteHandler)).Queries("website", "")
+		// GetBucketLifecycleConfiguration
+		bucket.Methods("GET").HandlerFunc(s3a.authorize(actionGetLifecycleConfiguration, s3a.GetBucketLifecycleConfigurationHandler)).Queries("lifecycle", "")
+		// PutBucketLifecycleConfiguration
+		bucket.Methods("PUT").HandlerFunc(s3a.authorize(actionPutLifecycleConfiguration, s3a.PutBucketLifecycleConfigurationHandler)).Queries("lifecycle", "")
+		// DeleteBucketLifecycle
+		bucket.Methods("DELETE").HandlerFunc(s3a.authorize(actionPutLifecycleConfiguration, s3a.DeleteBucketLifecycleHandler)).Queries("lifecycle", "")
+
+		// CopyObject
+		bucket.Methods("PUT").Path("/{object:.+}").HeadersRegexp("X-Amz-Copy-Source", ".*?(\\/|%2F).*?").HandlerFunc(s3a.authorize(actionPutObject, s3a.CopyObjectHandler))
 		// PutObject
-		bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(s3a.PutObjectHandler)
+		bucket.Methods("PUT").Path("/{object:.+}").HandlerFunc(s3a.authorize(actionPutObject, s3a.PutObjectHandler))
 		// PutBucket
-		bucket.Methods("PUT").HandlerFunc(s3a.PutBucketHandler)
+		bucket.Methods("PUT").HandlerFunc(s3a.authorize(actionCreateBucket, s3a.PutBucketHandler))
 
 		// DeleteObject
-		bucket.Methods("DELETE").Path("/{object:.+}").HandlerFunc(s3a.DeleteObjectHandler)
+		bucket.Methods("DELETE").Path("/{object:.+}").HandlerFunc(s3a.authorize(actionDeleteObject, s3a.DeleteObjectHandler))
 		// DeleteBucket
-		bucket.Methods("DELETE").HandlerFunc(s3a.DeleteBucketHandler)
+		bucket.Methods("DELETE").HandlerFunc(s3a.authorize(actionDeleteBucket, s3a.DeleteBucketHandler))
 
 		// ListObjectsV2
-		bucket.Methods("GET").HandlerFunc(s3a.ListObjectsV2Handler).Queries("list-type", "2")
+		bucket.Methods("GET").HandlerFunc(s3a.authorize(actionListBucket, s3a.ListObjectsV2Handler)).Queries("list-type", "2")
 		// GetObject, but directory listing is not supported
-		bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(s3a.GetObjectHandler)
+		bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(s3a.authorize(actionGetObject, s3a.GetObjectHandler))
 		// ListObjectsV1 (Legacy)
-		bucket.Methods("GET").HandlerFunc(s3a.ListObjectsV1Handler)
+		bucket.Methods("GET").HandlerFunc(s3a.authorize(actionListBucket, s3a.ListObjectsV1Handler))
+
+		// SelectObjectContent
+		bucket.Methods("POST").Path("/{object:.+}").HandlerFunc(s3a.authorize(actionGetObject, s3a.SelectObjectContentHandler)).Queries("select", "", "select-type", "2")
 
 		// DeleteMultipleObjects
-		bucket.Methods("POST").HandlerFunc(s3a.DeleteMultipleObjectsHandler).Queries("delete", "")
+		bucket.Methods("POST").HandlerFunc(s3a.authorize(actionDeleteObject, s3a.DeleteMultipleObjectsHandler)).Queries("delete", "")
 		/*
-			// CopyObject
-			bucket.Methods("PUT").Path("/{object:.+}").HeadersRegexp("X-Amz-Copy-Source", ".*?(\\/|%2F).*?").HandlerFunc(s3a.CopyObjectHandler)
-
 			// CopyObjectPart
 			bucket.Methods("PUT").Path("/{object:.+}").HeadersRegexp("X-Amz-Copy-Source", ".*?(\\/|%2F).*?").HandlerFunc(s3a.CopyObjectPartHandler).Queries("partNumber", "{partNumber:[0-9]+}", "uploadId", "{uploadId:.*}")
 
 			// not implemented
 			// GetBucketLocation
 			bucket.Methods("GET").HandlerFunc(s3a.GetBucketLocationHandler).Queries("location", "")
-			// GetBucketPolicy
-			bucket.Methods("GET").HandlerFunc(s3a.GetBucketPolicyHandler).Queries("policy", "")
 			// GetObjectACL
 			bucket.Methods("GET").Path("/{object:.+}").HandlerFunc(s3a.GetObjectACLHandler).Queries("acl", "")
 			// GetBucketACL
 			bucket.Methods("GET").HandlerFunc(s3a.GetBucketACLHandler).Queries("acl", "")
-			// PutBucketPolicy
-			bucket.Methods("PUT").HandlerFunc(s3a.PutBucketPolicyHandler).Queries("policy", "")
-			// DeleteBucketPolicy
-			bucket.Methods("DELETE").HandlerFunc(s3a.DeleteBucketPolicyHandler).Queries("policy", "")
 			// PostPolicy
 			bucket.Methods("POST").HeadersRegexp("Content-Type", "multipart/form-data*").HandlerFunc(s3a.PostPolicyBucketHandler)
 		*/
@@ -109,7 +170,7 @@ func (s3a *S3ApiServer) registerRouter(router *mux.Router) {
 	}
 
 	// ListBuckets
-	apiRouter.Methods("GET").Path("/").HandlerFunc(s3a.ListBucketsHandler)
+	apiRouter.Methods("GET").Path("/").HandlerFunc(s3a.authorize(actionListAllMyBuckets, s3a.ListBucketsHandler))
 
 	// NotFound
 	apiRouter.NotFoundHandler = http.HandlerFunc(notFoundHandler)
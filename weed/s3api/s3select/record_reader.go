@@ -0,0 +1,218 @@
+package s3select
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Record is one CSV row or JSON object read from the object content.
+type Record interface {
+	// Get returns the value of a column, positional "_N" for CSV, or a dotted path for JSON
+	Get(column string) (interface{}, bool)
+	// All returns every column of the record in output order
+	All() (names []string, values []interface{})
+}
+
+type RecordReader interface {
+	Read() (Record, error)
+}
+
+// NewRecordReader wraps the raw object content according to the input serialization.
+func NewRecordReader(r io.Reader, input InputSerialization) (RecordReader, error) {
+	switch strings.ToUpper(input.CompressionType) {
+	case "", "NONE":
+	case "GZIP":
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		r = gr
+	case "BZIP2":
+		r = bzip2.NewReader(r)
+	default:
+		return nil, fmt.Errorf("unsupported compression type %s", input.CompressionType)
+	}
+
+	switch {
+	case input.CSV != nil:
+		return newCsvRecordReader(r, input.CSV)
+	case input.JSON != nil:
+		return newJsonRecordReader(r, input.JSON)
+	}
+	return nil, fmt.Errorf("only CSV and JSON input are supported")
+}
+
+type csvRecordReader struct {
+	reader  *csv.Reader
+	header  []string
+	columns map[string]int
+}
+
+func newCsvRecordReader(r io.Reader, input *CSVInput) (*csvRecordReader, error) {
+	if input.RecordDelimiter != "" && input.RecordDelimiter != "\n" && input.RecordDelimiter != "\r\n" {
+		r = &delimiterReplacingReader{reader: bufio.NewReader(r), delimiter: []byte(input.RecordDelimiter)}
+	}
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.ReuseRecord = false
+	if input.FieldDelimiter != "" {
+		reader.Comma = []rune(input.FieldDelimiter)[0]
+	}
+	if input.Comments != "" {
+		reader.Comment = []rune(input.Comments)[0]
+	}
+	if input.QuoteCharacter != "" && input.QuoteCharacter != "\"" {
+		return nil, fmt.Errorf("unsupported quote character %q", input.QuoteCharacter)
+	}
+
+	c := &csvRecordReader{reader: reader}
+
+	switch strings.ToUpper(input.FileHeaderInfo) {
+	case "", "NONE":
+	case "IGNORE", "USE":
+		header, err := reader.Read()
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if strings.ToUpper(input.FileHeaderInfo) == "USE" {
+			c.header = header
+			c.columns = make(map[string]int, len(header))
+			for i, name := range header {
+				c.columns[name] = i
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported FileHeaderInfo %s", input.FileHeaderInfo)
+	}
+
+	return c, nil
+}
+
+func (c *csvRecordReader) Read() (Record, error) {
+	fields, err := c.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	return &csvRecord{reader: c, fields: fields}, nil
+}
+
+type csvRecord struct {
+	reader *csvRecordReader
+	fields []string
+}
+
+func (r *csvRecord) Get(column string) (interface{}, bool) {
+	if i, ok := r.reader.columns[column]; ok {
+		if i < len(r.fields) {
+			return r.fields[i], true
+		}
+		return nil, false
+	}
+	if strings.HasPrefix(column, "_") {
+		if i, err := strconv.Atoi(column[1:]); err == nil && i >= 1 && i <= len(r.fields) {
+			return r.fields[i-1], true
+		}
+	}
+	return nil, false
+}
+
+func (r *csvRecord) All() (names []string, values []interface{}) {
+	for i, f := range r.fields {
+		if i < len(r.reader.header) {
+			names = append(names, r.reader.header[i])
+		} else {
+			names = append(names, fmt.Sprintf("_%d", i+1))
+		}
+		values = append(values, f)
+	}
+	return
+}
+
+type jsonRecordReader struct {
+	decoder *json.Decoder
+}
+
+func newJsonRecordReader(r io.Reader, input *JSONInput) (*jsonRecordReader, error) {
+	switch strings.ToUpper(input.Type) {
+	case "", "DOCUMENT", "LINES":
+	default:
+		return nil, fmt.Errorf("unsupported JSON type %s", input.Type)
+	}
+	// both DOCUMENT and LINES are a stream of concatenated JSON values
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	return &jsonRecordReader{decoder: decoder}, nil
+}
+
+func (j *jsonRecordReader) Read() (Record, error) {
+	var value interface{}
+	if err := j.decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if object, ok := value.(map[string]interface{}); ok {
+		return jsonRecord(object), nil
+	}
+	return jsonRecord(map[string]interface{}{"_1": value}), nil
+}
+
+type jsonRecord map[string]interface{}
+
+func (r jsonRecord) Get(column string) (interface{}, bool) {
+	var current interface{} = map[string]interface{}(r)
+	for _, name := range strings.Split(column, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = object[name]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func (r jsonRecord) All() (names []string, values []interface{}) {
+	for name := range r {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		values = append(values, r[name])
+	}
+	return
+}
+
+// delimiterReplacingReader translates a custom record delimiter into '\n' for encoding/csv
+type delimiterReplacingReader struct {
+	reader    *bufio.Reader
+	delimiter []byte
+	pending   []byte
+}
+
+func (d *delimiterReplacingReader) Read(p []byte) (n int, err error) {
+	if len(d.pending) == 0 {
+		line, readErr := d.reader.ReadSlice(d.delimiter[len(d.delimiter)-1])
+		if len(line) > 0 {
+			d.pending = append(d.pending[:0], line...)
+			if bytes.HasSuffix(d.pending, d.delimiter) {
+				d.pending = append(d.pending[:len(d.pending)-len(d.delimiter)], '\n')
+			}
+		}
+		if readErr != nil && readErr != bufio.ErrBufferFull && len(d.pending) == 0 {
+			return 0, readErr
+		}
+	}
+	n = copy(p, d.pending)
+	d.pending = d.pending[n:]
+	return n, nil
+}
@@ -0,0 +1,126 @@
+package s3select
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+type RecordWriter interface {
+	Write(record Record, projections []Projection) error
+	Flush() error
+}
+
+// NewRecordWriter serializes matched records according to the output serialization.
+func NewRecordWriter(w io.Writer, output OutputSerialization) (RecordWriter, error) {
+	switch {
+	case output.CSV != nil:
+		return newCsvRecordWriter(w, output.CSV), nil
+	case output.JSON != nil:
+		return newJsonRecordWriter(w, output.JSON), nil
+	}
+	return nil, fmt.Errorf("only CSV and JSON output are supported")
+}
+
+type csvRecordWriter struct {
+	writer          io.Writer
+	buffer          bytes.Buffer
+	csvWriter       *csv.Writer
+	recordDelimiter string
+}
+
+func newCsvRecordWriter(w io.Writer, output *CSVOutput) *csvRecordWriter {
+	c := &csvRecordWriter{writer: w, recordDelimiter: output.RecordDelimiter}
+	c.csvWriter = csv.NewWriter(&c.buffer)
+	if output.FieldDelimiter != "" {
+		c.csvWriter.Comma = []rune(output.FieldDelimiter)[0]
+	}
+	if c.recordDelimiter == "" {
+		c.recordDelimiter = "\n"
+	}
+	return c
+}
+
+func (c *csvRecordWriter) Write(record Record, projections []Projection) error {
+	var fields []string
+	if len(projections) == 0 {
+		_, values := record.All()
+		for _, v := range values {
+			fields = append(fields, toString(v))
+		}
+	} else {
+		for _, p := range projections {
+			v, _ := record.Get(p.Column)
+			fields = append(fields, toString(v))
+		}
+	}
+	c.buffer.Reset()
+	if err := c.csvWriter.Write(fields); err != nil {
+		return err
+	}
+	c.csvWriter.Flush()
+	line := bytes.TrimSuffix(c.buffer.Bytes(), []byte("\n"))
+	if _, err := c.writer.Write(line); err != nil {
+		return err
+	}
+	_, err := io.WriteString(c.writer, c.recordDelimiter)
+	return err
+}
+
+func (c *csvRecordWriter) Flush() error {
+	return nil
+}
+
+type jsonRecordWriter struct {
+	writer          io.Writer
+	recordDelimiter string
+}
+
+func newJsonRecordWriter(w io.Writer, output *JSONOutput) *jsonRecordWriter {
+	j := &jsonRecordWriter{writer: w, recordDelimiter: output.RecordDelimiter}
+	if j.recordDelimiter == "" {
+		j.recordDelimiter = "\n"
+	}
+	return j
+}
+
+func (j *jsonRecordWriter) Write(record Record, projections []Projection) error {
+	var names []string
+	var values []interface{}
+	if len(projections) == 0 {
+		names, values = record.All()
+	} else {
+		for _, p := range projections {
+			v, _ := record.Get(p.Column)
+			names = append(names, p.Name)
+			values = append(values, v)
+		}
+	}
+
+	// keep the projection order, which encoding/json does not do for maps
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(name)
+		buf.Write(key)
+		buf.WriteByte(':')
+		value, err := json.Marshal(values[i])
+		if err != nil {
+			return err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	buf.WriteString(j.recordDelimiter)
+	_, err := j.writer.Write(buf.Bytes())
+	return err
+}
+
+func (j *jsonRecordWriter) Flush() error {
+	return nil
+}
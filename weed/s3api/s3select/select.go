@@ -0,0 +1,33 @@
+package s3select
+
+import (
+	"io"
+)
+
+type Stats struct {
+	RecordsScanned  int64
+	RecordsReturned int64
+}
+
+// Run streams records from the reader, filters them with the WHERE clause,
+// and writes the projected columns until the input ends or LIMIT is reached.
+func (q *Query) Run(reader RecordReader, writer RecordWriter) (stats Stats, err error) {
+	for q.Limit == 0 || stats.RecordsReturned < q.Limit {
+		record, readErr := reader.Read()
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return stats, readErr
+		}
+		stats.RecordsScanned++
+		if q.Where != nil && !q.Where.Match(record) {
+			continue
+		}
+		if err = writer.Write(record, q.Projections); err != nil {
+			return stats, err
+		}
+		stats.RecordsReturned++
+	}
+	return stats, writer.Flush()
+}
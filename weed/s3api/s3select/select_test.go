@@ -0,0 +1,85 @@
+package s3select
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func runQuery(t *testing.T, sql string, data string, input InputSerialization, output OutputSerialization) string {
+	q, err := ParseQuery(sql)
+	if err != nil {
+		t.Fatalf("parse %s: %v", sql, err)
+	}
+	reader, err := NewRecordReader(strings.NewReader(data), input)
+	if err != nil {
+		t.Fatalf("reader: %v", err)
+	}
+	var buf bytes.Buffer
+	writer, err := NewRecordWriter(&buf, output)
+	if err != nil {
+		t.Fatalf("writer: %v", err)
+	}
+	if _, err = q.Run(reader, writer); err != nil {
+		t.Fatalf("run %s: %v", sql, err)
+	}
+	return buf.String()
+}
+
+func TestSelectCsv(t *testing.T) {
+
+	data := "name,city,age\nalice,paris,31\nbob,berlin,25\ncarol,paris,40\n"
+	input := InputSerialization{CSV: &CSVInput{FileHeaderInfo: "USE"}}
+	output := OutputSerialization{CSV: &CSVOutput{}}
+
+	tests := []struct {
+		sql      string
+		expected string
+	}{
+		{"SELECT * FROM S3Object", "alice,paris,31\nbob,berlin,25\ncarol,paris,40\n"},
+		{"select s.name from S3Object s where s.city = 'paris'", "alice\ncarol\n"},
+		{"SELECT name, age FROM S3Object WHERE age > 30 AND NOT city = 'berlin'", "alice,31\ncarol,40\n"},
+		{"SELECT _1 FROM S3Object WHERE age >= 25 LIMIT 2", "alice\nbob\n"},
+		{"SELECT name FROM S3Object WHERE name LIKE '%o%' OR (city <> 'paris' AND age < 30)", "bob\ncarol\n"},
+		{"SELECT name FROM S3Object WHERE missing IS NULL AND age IS NOT NULL LIMIT 1", "alice\n"},
+	}
+
+	for _, test := range tests {
+		actual := runQuery(t, test.sql, data, input, output)
+		if actual != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.sql, test.expected, actual)
+		}
+	}
+}
+
+func TestSelectJson(t *testing.T) {
+
+	data := `{"id":1,"user":{"name":"alice"},"ok":true}
+{"id":2,"user":{"name":"bob"},"ok":false}
+`
+	input := InputSerialization{JSON: &JSONInput{Type: "LINES"}}
+	output := OutputSerialization{JSON: &JSONOutput{}}
+
+	actual := runQuery(t, "SELECT s.user.name AS who, s.id FROM S3Object s WHERE s.ok = true", data, input, output)
+	expected := "{\"who\":\"alice\",\"id\":1}\n"
+	if actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	for _, sql := range []string{
+		"",
+		"SELECT FROM S3Object",
+		"SELECT * FROM other",
+		"SELECT * FROM S3Object WHERE a =",
+		"SELECT * FROM S3Object WHERE (a = 1",
+		"SELECT * FROM S3Object LIMIT x",
+		"SELECT * FROM S3Object WHERE a = 'unterminated",
+	} {
+		if _, err := ParseQuery(sql); err == nil {
+			t.Errorf("expected error for %q", sql)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package s3select
+
+// InputSerialization mirrors the S3 SelectObjectContent request element.
+type InputSerialization struct {
+	CompressionType string     `xml:"CompressionType"`
+	CSV             *CSVInput  `xml:"CSV"`
+	JSON            *JSONInput `xml:"JSON"`
+}
+
+type CSVInput struct {
+	FileHeaderInfo       string `xml:"FileHeaderInfo"`
+	RecordDelimiter      string `xml:"RecordDelimiter"`
+	FieldDelimiter       string `xml:"FieldDelimiter"`
+	QuoteCharacter       string `xml:"QuoteCharacter"`
+	QuoteEscapeCharacter string `xml:"QuoteEscapeCharacter"`
+	Comments             string `xml:"Comments"`
+}
+
+type JSONInput struct {
+	Type string `xml:"Type"`
+}
+
+// OutputSerialization mirrors the S3 SelectObjectContent request element.
+type OutputSerialization struct {
+	CSV  *CSVOutput  `xml:"CSV"`
+	JSON *JSONOutput `xml:"JSON"`
+}
+
+type CSVOutput struct {
+	RecordDelimiter string `xml:"RecordDelimiter"`
+	FieldDelimiter  string `xml:"FieldDelimiter"`
+}
+
+type JSONOutput struct {
+	RecordDelimiter string `xml:"RecordDelimiter"`
+}
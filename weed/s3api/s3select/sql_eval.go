@@ -0,0 +1,196 @@
+package s3select
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Condition is a WHERE clause predicate evaluated against one record.
+type Condition interface {
+	Match(record Record) bool
+}
+
+type operand struct {
+	column  string
+	literal interface{}
+}
+
+func (o *operand) value(record Record) (interface{}, bool) {
+	if o.column == "" {
+		return o.literal, o.literal != nil
+	}
+	return record.Get(o.column)
+}
+
+type andCondition struct {
+	left, right Condition
+}
+
+func (c *andCondition) Match(record Record) bool {
+	return c.left.Match(record) && c.right.Match(record)
+}
+
+type orCondition struct {
+	left, right Condition
+}
+
+func (c *orCondition) Match(record Record) bool {
+	return c.left.Match(record) || c.right.Match(record)
+}
+
+type notCondition struct {
+	inner Condition
+}
+
+func (c *notCondition) Match(record Record) bool {
+	return !c.inner.Match(record)
+}
+
+type isNullCondition struct {
+	operand *operand
+	negate  bool
+}
+
+func (c *isNullCondition) Match(record Record) bool {
+	v, found := c.operand.value(record)
+	isNull := !found || v == nil
+	return isNull != c.negate
+}
+
+type likeCondition struct {
+	operand *operand
+	pattern string
+	negate  bool
+	re      *regexp.Regexp
+}
+
+func (c *likeCondition) Match(record Record) bool {
+	v, found := c.operand.value(record)
+	if !found || v == nil {
+		return false
+	}
+	if c.re == nil {
+		c.re = likeToRegexp(c.pattern)
+	}
+	return c.re.MatchString(toString(v)) != c.negate
+}
+
+func likeToRegexp(pattern string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			sb.WriteString(".*")
+		case '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile("(?s)" + sb.String())
+}
+
+type compareCondition struct {
+	left  *operand
+	op    string
+	right *operand
+}
+
+func (c *compareCondition) Match(record Record) bool {
+	a, foundA := c.left.value(record)
+	b, foundB := c.right.value(record)
+	if !foundA || !foundB || a == nil || b == nil {
+		return false
+	}
+
+	var cmp int
+	fa, okA := toFloat(a)
+	fb, okB := toFloat(b)
+	if okA && okB {
+		switch {
+		case fa < fb:
+			cmp = -1
+		case fa > fb:
+			cmp = 1
+		}
+	} else {
+		cmp = strings.Compare(toString(a), toString(b))
+	}
+
+	switch c.op {
+	case "=", "==":
+		return cmp == 0
+	case "!=", "<>":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+// resolveColumns rewrites column references in a condition tree with fn
+func resolveColumns(c Condition, fn func(string) string) {
+	resolve := func(o *operand) {
+		if o.column != "" {
+			o.column = fn(o.column)
+		}
+	}
+	switch t := c.(type) {
+	case *andCondition:
+		resolveColumns(t.left, fn)
+		resolveColumns(t.right, fn)
+	case *orCondition:
+		resolveColumns(t.left, fn)
+		resolveColumns(t.right, fn)
+	case *notCondition:
+		resolveColumns(t.inner, fn)
+	case *isNullCondition:
+		resolve(t.operand)
+	case *likeCondition:
+		resolve(t.operand)
+	case *compareCondition:
+		resolve(t.left)
+		resolve(t.right)
+	}
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch t := v.(type) {
+	case float64:
+		return t, true
+	case json.Number:
+		f, err := t.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(t), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func toString(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case json.Number:
+		return t.String()
+	case bool:
+		return strconv.FormatBool(t)
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
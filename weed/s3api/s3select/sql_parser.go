@@ -0,0 +1,374 @@
+package s3select
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Query is a parsed S3 Select expression of the form
+//
+//	SELECT <* | col [AS name], ...> FROM S3Object [[AS] alias] [WHERE cond] [LIMIT n]
+type Query struct {
+	Projections []Projection // empty means "*"
+	Alias       string
+	Where       Condition
+	Limit       int64 // 0 means no limit
+}
+
+type Projection struct {
+	Column string
+	Name   string
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenQuotedIdent
+	tokenNumber
+	tokenString
+	tokenOperator
+	tokenComma
+	tokenLeftParen
+	tokenRightParen
+	tokenStar
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+func tokenize(sql string) (tokens []token, err error) {
+	runes := []rune(sql)
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == ',':
+			tokens = append(tokens, token{tokenComma, ","})
+			i++
+		case c == '(':
+			tokens = append(tokens, token{tokenLeftParen, "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{tokenRightParen, ")"})
+			i++
+		case c == '*':
+			tokens = append(tokens, token{tokenStar, "*"})
+			i++
+		case c == '\'' || c == '"':
+			j := i + 1
+			var sb strings.Builder
+			for ; j < len(runes); j++ {
+				if runes[j] == c {
+					// a doubled quote is an escaped quote
+					if j+1 < len(runes) && runes[j+1] == c {
+						sb.WriteRune(c)
+						j++
+						continue
+					}
+					break
+				}
+				sb.WriteRune(runes[j])
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("unterminated quote at position %d", i)
+			}
+			kind := tokenString
+			if c == '"' {
+				kind = tokenQuotedIdent
+			}
+			tokens = append(tokens, token{kind, sb.String()})
+			i = j + 1
+		case c == '=' || c == '<' || c == '>' || c == '!':
+			j := i + 1
+			if j < len(runes) && (runes[j] == '=' || (c == '<' && runes[j] == '>')) {
+				j++
+			}
+			op := string(runes[i:j])
+			if op == "!" {
+				return nil, fmt.Errorf("unexpected '!' at position %d", i)
+			}
+			tokens = append(tokens, token{tokenOperator, op})
+			i = j
+		case unicode.IsDigit(c) || ((c == '-' || c == '.') && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			j := i + 1
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.' || runes[j] == 'e' || runes[j] == 'E') {
+				j++
+			}
+			tokens = append(tokens, token{tokenNumber, string(runes[i:j])})
+			i = j
+		case unicode.IsLetter(c) || c == '_':
+			j := i + 1
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_' || runes[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{tokenIdent, string(runes[i:j])})
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+		}
+	}
+	tokens = append(tokens, token{tokenEOF, ""})
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isKeyword(keyword string) bool {
+	t := p.peek()
+	return t.kind == tokenIdent && strings.EqualFold(t.value, keyword)
+}
+
+func (p *parser) expectKeyword(keyword string) error {
+	if !p.isKeyword(keyword) {
+		return fmt.Errorf("expecting %s but found %q", keyword, p.peek().value)
+	}
+	p.next()
+	return nil
+}
+
+// ParseQuery parses the limited SQL dialect supported by S3 Select.
+func ParseQuery(sql string) (*Query, error) {
+	tokens, err := tokenize(sql)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	q := &Query{}
+
+	if err = p.expectKeyword("SELECT"); err != nil {
+		return nil, err
+	}
+	if p.peek().kind == tokenStar {
+		p.next()
+	} else {
+		for {
+			t := p.next()
+			if t.kind != tokenIdent && t.kind != tokenQuotedIdent {
+				return nil, fmt.Errorf("expecting column name but found %q", t.value)
+			}
+			projection := Projection{Column: t.value, Name: t.value}
+			if p.isKeyword("AS") {
+				p.next()
+				alias := p.next()
+				if alias.kind != tokenIdent && alias.kind != tokenQuotedIdent {
+					return nil, fmt.Errorf("expecting column alias but found %q", alias.value)
+				}
+				projection.Name = alias.value
+			}
+			q.Projections = append(q.Projections, projection)
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+	}
+
+	if err = p.expectKeyword("FROM"); err != nil {
+		return nil, err
+	}
+	from := p.next()
+	if from.kind != tokenIdent || !strings.EqualFold(from.value, "S3Object") {
+		return nil, fmt.Errorf("only FROM S3Object is supported, found %q", from.value)
+	}
+	if p.isKeyword("AS") {
+		p.next()
+	}
+	if p.peek().kind == tokenIdent && !p.isKeyword("WHERE") && !p.isKeyword("LIMIT") {
+		q.Alias = p.next().value
+	}
+
+	if p.isKeyword("WHERE") {
+		p.next()
+		if q.Where, err = p.parseOr(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.isKeyword("LIMIT") {
+		p.next()
+		t := p.next()
+		if t.kind != tokenNumber {
+			return nil, fmt.Errorf("expecting number after LIMIT but found %q", t.value)
+		}
+		if q.Limit, err = strconv.ParseInt(t.value, 10, 64); err != nil || q.Limit < 0 {
+			return nil, fmt.Errorf("invalid LIMIT %q", t.value)
+		}
+	}
+
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q after end of query", t.value)
+	}
+
+	for i := range q.Projections {
+		q.Projections[i].Column = q.columnName(q.Projections[i].Column)
+		if q.Projections[i].Name == q.Projections[i].Column || strings.HasSuffix(q.Projections[i].Name, "."+q.Projections[i].Column) {
+			q.Projections[i].Name = q.Projections[i].Column
+		}
+	}
+	resolveColumns(q.Where, q.columnName)
+
+	return q, nil
+}
+
+// columnName strips the table alias, e.g. "s.name" or "S3Object.name" to "name"
+func (q *Query) columnName(name string) string {
+	if dot := strings.Index(name, "."); dot > 0 {
+		prefix := name[:dot]
+		if strings.EqualFold(prefix, "S3Object") || (q.Alias != "" && prefix == q.Alias) {
+			return name[dot+1:]
+		}
+	}
+	return name
+}
+
+func (p *parser) parseOr() (Condition, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("OR") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orCondition{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Condition, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("AND") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &andCondition{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (Condition, error) {
+	if p.isKeyword("NOT") {
+		p.next()
+		c, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notCondition{c}, nil
+	}
+	return p.parsePredicate()
+}
+
+func (p *parser) parsePredicate() (Condition, error) {
+	if p.peek().kind == tokenLeftParen {
+		p.next()
+		c, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tokenRightParen {
+			return nil, fmt.Errorf("expecting ')' but found %q", t.value)
+		}
+		return c, nil
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.isKeyword("IS") {
+		p.next()
+		negate := false
+		if p.isKeyword("NOT") {
+			p.next()
+			negate = true
+		}
+		if err = p.expectKeyword("NULL"); err != nil {
+			return nil, err
+		}
+		return &isNullCondition{operand: left, negate: negate}, nil
+	}
+
+	negate := false
+	if p.isKeyword("NOT") {
+		p.next()
+		negate = true
+	}
+	if p.isKeyword("LIKE") {
+		p.next()
+		t := p.next()
+		if t.kind != tokenString {
+			return nil, fmt.Errorf("expecting pattern string after LIKE but found %q", t.value)
+		}
+		return &likeCondition{operand: left, pattern: t.value, negate: negate}, nil
+	}
+	if negate {
+		return nil, fmt.Errorf("expecting LIKE after NOT but found %q", p.peek().value)
+	}
+
+	op := p.next()
+	if op.kind != tokenOperator {
+		return nil, fmt.Errorf("expecting comparison operator but found %q", op.value)
+	}
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return &compareCondition{left: left, op: op.value, right: right}, nil
+}
+
+func (p *parser) parseOperand() (*operand, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenIdent:
+		switch {
+		case strings.EqualFold(t.value, "TRUE"):
+			return &operand{literal: true}, nil
+		case strings.EqualFold(t.value, "FALSE"):
+			return &operand{literal: false}, nil
+		case strings.EqualFold(t.value, "NULL"):
+			return &operand{}, nil
+		}
+		return &operand{column: t.value}, nil
+	case tokenQuotedIdent:
+		return &operand{column: t.value}, nil
+	case tokenString:
+		return &operand{literal: t.value}, nil
+	case tokenNumber:
+		f, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", t.value)
+		}
+		return &operand{literal: f}, nil
+	}
+	return nil, fmt.Errorf("expecting column or literal but found %q", t.value)
+}
@@ -0,0 +1,90 @@
+package search
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+)
+
+// Document is one filer entry in the index
+type Document struct {
+	Path        string            `json:"path"`
+	Directory   string            `json:"directory"`
+	Name        string            `json:"name"`
+	IsDirectory bool              `json:"is_directory"`
+	Size        uint64            `json:"size"`
+	Mime        string            `json:"mime,omitempty"`
+	Mtime       int64             `json:"mtime"`
+	Crtime      int64             `json:"crtime"`
+	Uid         uint32            `json:"uid"`
+	Gid         uint32            `json:"gid"`
+	Collection  string            `json:"collection,omitempty"`
+	Extended    map[string]string `json:"extended,omitempty"`
+	// IndexedAt is when the syncer indexed the entry, in nanoseconds, to tell the documents not seen by a crawl
+	IndexedAt int64 `json:"indexed_at"`
+}
+
+// the mapping of the index created by EnsureIndex, with the names analyzed for the full text search,
+// and the extended attributes as keywords
+const indexMapping = `{
+  "mappings": {
+    "dynamic_templates": [
+      {"extended": {"path_match": "extended.*", "mapping": {"type": "keyword"}}}
+    ],
+    "properties": {
+      "path":         {"type": "keyword", "fields": {"text": {"type": "text"}}},
+      "directory":    {"type": "keyword"},
+      "name":         {"type": "text", "fields": {"keyword": {"type": "keyword"}}},
+      "is_directory": {"type": "boolean"},
+      "size":         {"type": "long"},
+      "mime":         {"type": "keyword"},
+      "mtime":        {"type": "date", "format": "epoch_second"},
+      "crtime":       {"type": "date", "format": "epoch_second"},
+      "uid":          {"type": "long"},
+      "gid":          {"type": "long"},
+      "collection":   {"type": "keyword"},
+      "indexed_at":   {"type": "long"}
+    }
+  }
+}`
+
+// DocumentOf converts the entry in the directory
+func DocumentOf(dir string, entry *filer_pb.Entry) *Document {
+	doc := &Document{
+		Path:        path.Join(dir, entry.Name),
+		Directory:   dir,
+		Name:        entry.Name,
+		IsDirectory: entry.IsDirectory,
+		Size:        filer2.FileSize(entry),
+	}
+	if attr := entry.Attributes; attr != nil {
+		doc.Mime = attr.Mime
+		doc.Mtime = attr.Mtime
+		doc.Crtime = attr.Crtime
+		doc.Uid = attr.Uid
+		doc.Gid = attr.Gid
+		doc.Collection = attr.Collection
+	}
+	// only the text values of the extended attributes are searchable
+	for key, value := range entry.Extended {
+		if !utf8.Valid(value) || strings.ContainsAny(key, ".*") {
+			continue
+		}
+		if doc.Extended == nil {
+			doc.Extended = make(map[string]string)
+		}
+		doc.Extended[key] = string(value)
+	}
+	return doc
+}
+
+// DocumentId is the id of the entry in the index, short enough for any path
+func DocumentId(fullPath string) string {
+	sum := sha1.Sum([]byte(fullPath))
+	return hex.EncodeToString(sum[:])
+}
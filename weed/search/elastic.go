@@ -0,0 +1,314 @@
+package search
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ElasticClient talks to the REST api of Elasticsearch 7, failing over among the servers
+type ElasticClient struct {
+	servers  []string
+	index    string
+	username string
+	password string
+	client   *http.Client
+	next     uint32
+}
+
+func NewElasticClient(servers []string, index, username, password string) *ElasticClient {
+	var cleaned []string
+	for _, server := range servers {
+		server = strings.TrimSuffix(strings.TrimSpace(server), "/")
+		if server == "" {
+			continue
+		}
+		if !strings.HasPrefix(server, "http://") && !strings.HasPrefix(server, "https://") {
+			server = "http://" + server
+		}
+		cleaned = append(cleaned, server)
+	}
+	return &ElasticClient{
+		servers:  cleaned,
+		index:    index,
+		username: username,
+		password: password,
+		client:   &http.Client{Timeout: time.Minute},
+	}
+}
+
+// do sends the request to the servers in turn, until one answers
+func (c *ElasticClient) do(method, urlPath string, body []byte, contentType string) (status int, respBody []byte, err error) {
+	if len(c.servers) == 0 {
+		return 0, nil, fmt.Errorf("no elasticsearch servers")
+	}
+	start := atomic.AddUint32(&c.next, 1)
+	for i := 0; i < len(c.servers); i++ {
+		server := c.servers[(int(start)+i)%len(c.servers)]
+		var req *http.Request
+		req, err = http.NewRequest(method, server+urlPath, bytes.NewReader(body))
+		if err != nil {
+			return 0, nil, err
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		if c.username != "" {
+			req.SetBasicAuth(c.username, c.password)
+		}
+		var resp *http.Response
+		resp, err = c.client.Do(req)
+		if err != nil {
+			continue
+		}
+		respBody, err = ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			continue
+		}
+		return resp.StatusCode, respBody, nil
+	}
+	return 0, nil, fmt.Errorf("elasticsearch %s %s: %v", method, urlPath, err)
+}
+
+func (c *ElasticClient) doJson(method, urlPath string, body []byte, contentType string, out interface{}) error {
+	status, respBody, err := c.do(method, urlPath, body, contentType)
+	if err != nil {
+		return err
+	}
+	if status < 200 || status >= 300 {
+		return fmt.Errorf("elasticsearch %s %s: status %d %s", method, urlPath, status, truncate(respBody, 512))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(respBody, out)
+}
+
+func truncate(b []byte, n int) string {
+	if len(b) > n {
+		return string(b[:n]) + "..."
+	}
+	return string(b)
+}
+
+// EnsureIndex creates the index with the mapping if not exists
+func (c *ElasticClient) EnsureIndex() error {
+	status, _, err := c.do("HEAD", "/"+c.index, nil, "")
+	if err != nil {
+		return err
+	}
+	if status == http.StatusOK {
+		return nil
+	}
+	return c.doJson("PUT", "/"+c.index, []byte(indexMapping), "application/json", nil)
+}
+
+// Action indexes the document, or deletes the document of the path if Doc is nil
+type Action struct {
+	Path string
+	Doc  *Document
+}
+
+// Bulk runs the actions in one request
+func (c *ElasticClient) Bulk(actions []Action) error {
+	if len(actions) == 0 {
+		return nil
+	}
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, action := range actions {
+		meta := map[string]interface{}{"_index": c.index, "_id": DocumentId(action.Path)}
+		if action.Doc == nil {
+			encoder.Encode(map[string]interface{}{"delete": meta})
+			continue
+		}
+		encoder.Encode(map[string]interface{}{"index": meta})
+		if err := encoder.Encode(action.Doc); err != nil {
+			return err
+		}
+	}
+
+	var result struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Status int             `json:"status"`
+			Error  json.RawMessage `json:"error"`
+		} `json:"items"`
+	}
+	if err := c.doJson("POST", "/_bulk", body.Bytes(), "application/x-ndjson", &result); err != nil {
+		return err
+	}
+	if !result.Errors {
+		return nil
+	}
+	var failed int
+	var firstError string
+	for _, item := range result.Items {
+		for op, r := range item {
+			// deleting the documents not indexed is fine
+			if r.Status < 300 || (op == "delete" && r.Status == http.StatusNotFound) {
+				continue
+			}
+			if failed == 0 {
+				firstError = string(r.Error)
+			}
+			failed++
+		}
+	}
+	if failed == 0 {
+		return nil
+	}
+	return &BulkError{Failed: failed, Total: len(actions), FirstError: firstError}
+}
+
+// BulkError is for the actions rejected by Elasticsearch, which would fail again if retried
+type BulkError struct {
+	Failed     int
+	Total      int
+	FirstError string
+}
+
+func (e *BulkError) Error() string {
+	return fmt.Sprintf("elasticsearch bulk: %d of %d failed, first error %s", e.Failed, e.Total, e.FirstError)
+}
+
+// DeleteUnder deletes the documents under the directory
+func (c *ElasticClient) DeleteUnder(dir string) error {
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"prefix": map[string]interface{}{"path": strings.TrimSuffix(dir, "/") + "/"},
+		},
+	}
+	body, _ := json.Marshal(query)
+	return c.doJson("POST", "/"+c.index+"/_delete_by_query?conflicts=proceed", body, "application/json", nil)
+}
+
+// DeleteUnderIndexedBefore deletes the documents under the directory not indexed since the time in nanoseconds,
+// including the documents without the indexed time
+func (c *ElasticClient) DeleteUnderIndexedBefore(dir string, indexedAtNs int64) error {
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": map[string]interface{}{
+					"prefix": map[string]interface{}{"path": strings.TrimSuffix(dir, "/") + "/"},
+				},
+				"must_not": map[string]interface{}{
+					"range": map[string]interface{}{"indexed_at": map[string]interface{}{"gte": indexedAtNs}},
+				},
+			},
+		},
+	}
+	body, _ := json.Marshal(query)
+	return c.doJson("POST", "/"+c.index+"/_delete_by_query?conflicts=proceed", body, "application/json", nil)
+}
+
+// Query is the search over the documents, with the conditions combined by AND
+type Query struct {
+	// Text is matched against the names and the paths, all documents if empty
+	Text string
+	// Directory limits the search to the entries under the directory
+	Directory  string
+	MimePrefix string
+	MinSize    uint64
+	MaxSize    uint64 // 0 for no limit
+	FilesOnly  bool
+	// Extended are the extended attributes with the exact values
+	Extended map[string]string
+	From     int
+	Limit    int
+}
+
+type SearchResult struct {
+	Total int64       `json:"total"`
+	Hits  []*Document `json:"hits"`
+}
+
+func (q *Query) body() ([]byte, error) {
+	var must []interface{}
+	if q.Text != "" {
+		must = append(must, map[string]interface{}{
+			"multi_match": map[string]interface{}{
+				"query":  q.Text,
+				"fields": []string{"name^2", "path.text"},
+			},
+		})
+	} else {
+		must = append(must, map[string]interface{}{"match_all": map[string]interface{}{}})
+	}
+
+	var filter []interface{}
+	if dir := strings.TrimSuffix(q.Directory, "/"); dir != "" {
+		filter = append(filter, map[string]interface{}{"prefix": map[string]interface{}{"path": dir + "/"}})
+	}
+	if q.MimePrefix != "" {
+		filter = append(filter, map[string]interface{}{"prefix": map[string]interface{}{"mime": q.MimePrefix}})
+	}
+	if q.MinSize > 0 || q.MaxSize > 0 {
+		sizeRange := map[string]interface{}{"gte": q.MinSize}
+		if q.MaxSize > 0 {
+			sizeRange["lte"] = q.MaxSize
+		}
+		filter = append(filter, map[string]interface{}{"range": map[string]interface{}{"size": sizeRange}})
+	}
+	if q.FilesOnly {
+		filter = append(filter, map[string]interface{}{"term": map[string]interface{}{"is_directory": false}})
+	}
+	for key, value := range q.Extended {
+		filter = append(filter, map[string]interface{}{"term": map[string]interface{}{"extended." + key: value}})
+	}
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	return json.Marshal(map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{"must": must, "filter": filter},
+		},
+		"from":             q.From,
+		"size":             limit,
+		"sort":             []interface{}{"_score", map[string]interface{}{"path": "asc"}},
+		"track_total_hits": true,
+	})
+}
+
+func (c *ElasticClient) Search(q *Query) (*SearchResult, error) {
+	body, err := q.body()
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Hits struct {
+			// an object since Elasticsearch 7, a number before
+			Total json.RawMessage `json:"total"`
+			Hits  []struct {
+				Source *Document `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err = c.doJson("POST", "/"+c.index+"/_search", body, "application/json", &resp); err != nil {
+		return nil, err
+	}
+
+	result := &SearchResult{}
+	var total struct {
+		Value int64 `json:"value"`
+	}
+	if err = json.Unmarshal(resp.Hits.Total, &total); err == nil {
+		result.Total = total.Value
+	} else {
+		json.Unmarshal(resp.Hits.Total, &result.Total)
+	}
+	for _, hit := range resp.Hits.Hits {
+		if hit.Source != nil {
+			result.Hits = append(result.Hits, hit.Source)
+		}
+	}
+	return result, nil
+}
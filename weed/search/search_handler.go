@@ -0,0 +1,110 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+)
+
+// SearchHandler serves the searches as GET /search?q=<words>&path=<dir>&mime=<prefix>&minSize=<n>&maxSize=<n>&attr=<key>=<value>&files=true&from=<n>&limit=<n>
+func SearchHandler(elastic *ElasticClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" && r.Method != "POST" {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		r.ParseForm()
+		query, err := ParseQuery(r.Form)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result, err := elastic.Search(query)
+		if err != nil {
+			glog.V(0).Infof("search %s: %v", r.Form.Encode(), err)
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		if result.Hits == nil {
+			result.Hits = []*Document{}
+		}
+		writeJson(w, r, result)
+	}
+}
+
+func writeJson(w http.ResponseWriter, r *http.Request, obj interface{}) {
+	var bytes []byte
+	var err error
+	if r.FormValue("pretty") != "" {
+		bytes, err = json.MarshalIndent(obj, "", "  ")
+	} else {
+		bytes, err = json.Marshal(obj)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(bytes)
+}
+
+// ParseQuery reads the query from the url parameters
+func ParseQuery(values url.Values) (query *Query, err error) {
+	query = &Query{
+		Text:       values.Get("q"),
+		Directory:  values.Get("path"),
+		MimePrefix: values.Get("mime"),
+		FilesOnly:  values.Get("files") == "true",
+	}
+	if query.MinSize, err = parseUint(values, "minSize"); err != nil {
+		return nil, err
+	}
+	if query.MaxSize, err = parseUint(values, "maxSize"); err != nil {
+		return nil, err
+	}
+	var n uint64
+	if n, err = parseUint(values, "from"); err != nil {
+		return nil, err
+	}
+	query.From = int(n)
+	if n, err = parseUint(values, "limit"); err != nil {
+		return nil, err
+	}
+	query.Limit = int(n)
+	for _, attr := range values["attr"] {
+		if err = query.AddAttribute(attr); err != nil {
+			return nil, err
+		}
+	}
+	return query, nil
+}
+
+func parseUint(values url.Values, name string) (uint64, error) {
+	value := values.Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parameter %s: %v", name, err)
+	}
+	return n, nil
+}
+
+// AddAttribute adds the condition of the extended attribute as "<key>=<value>"
+func (q *Query) AddAttribute(attr string) error {
+	parts := strings.SplitN(attr, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return fmt.Errorf("attribute %q should be <key>=<value>", attr)
+	}
+	if q.Extended == nil {
+		q.Extended = make(map[string]string)
+	}
+	q.Extended[parts[0]] = parts[1]
+	return nil
+}
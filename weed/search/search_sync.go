@@ -0,0 +1,234 @@
+package search
+
+import (
+	"context"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+	"github.com/chrislusf/seaweedfs/weed/util"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type SyncOption struct {
+	FilerGrpcAddress string
+	GrpcDialOption   grpc.DialOption
+	// Path is the directory to mirror
+	Path string
+	// Crawl indexes all the entries under the path when subscribed, at the start and after reconnecting,
+	// since the changes are missed while not subscribed, and then deletes the documents of the entries not seen
+	Crawl         bool
+	BatchSize     int
+	FlushInterval time.Duration
+}
+
+// Syncer mirrors the filer entries into the index, following the metadata events of the filer
+type Syncer struct {
+	option  *SyncOption
+	elastic *ElasticClient
+
+	pendingLock sync.Mutex
+	pending     []Action
+}
+
+func NewSyncer(option *SyncOption, elastic *ElasticClient) *Syncer {
+	if option.BatchSize <= 0 {
+		option.BatchSize = 500
+	}
+	if option.FlushInterval <= 0 {
+		option.FlushInterval = time.Second
+	}
+	if option.Path == "" {
+		option.Path = "/"
+	}
+	return &Syncer{
+		option:  option,
+		elastic: elastic,
+	}
+}
+
+func (s *Syncer) WithFilerClient(ctx context.Context, fn func(filer_pb.SeaweedFilerClient) error) error {
+	return util.WithCachedGrpcClient(ctx, func(grpcConnection *grpc.ClientConn) error {
+		return fn(filer_pb.NewSeaweedFilerClient(grpcConnection))
+	}, s.option.FilerGrpcAddress, s.option.GrpcDialOption)
+}
+
+// Run keeps the index in sync until the filer does not support the metadata subscription
+func (s *Syncer) Run() error {
+	if err := s.elastic.EnsureIndex(); err != nil {
+		return err
+	}
+	go s.loopFlushing()
+
+	ctx := context.Background()
+	for {
+		err := s.WithFilerClient(ctx, func(client filer_pb.SeaweedFilerClient) error {
+			stream, err := client.SubscribeMetadata(ctx, &filer_pb.SubscribeMetadataRequest{
+				ClientName: "search:" + s.option.Path,
+				PathPrefix: s.option.Path,
+			})
+			if err != nil {
+				return err
+			}
+			// crawl after subscribed, so the changes during crawling are not missed
+			if s.option.Crawl {
+				go s.crawl(ctx)
+			}
+			for {
+				resp, err := stream.Recv()
+				if err != nil {
+					return err
+				}
+				s.applyEvent(resp)
+			}
+		})
+		if status.Code(err) == codes.Unimplemented {
+			return err
+		}
+		glog.V(0).Infof("subscribe filer metadata: %v", err)
+		time.Sleep(3 * time.Second)
+	}
+}
+
+func (s *Syncer) crawl(ctx context.Context) {
+	glog.V(0).Infof("crawling %s", s.option.Path)
+	crawlStartNs := time.Now().UnixNano()
+	dirCount, fileCount, err := s.indexUnder(ctx, s.option.Path)
+	if err != nil {
+		glog.Errorf("crawl %s: %v", s.option.Path, err)
+		return
+	}
+	glog.V(0).Infof("crawled %s: %d directories, %d files", s.option.Path, dirCount, fileCount)
+	s.deleteNotSeenSince(crawlStartNs)
+}
+
+// deleteNotSeenSince deletes the documents of the entries deleted while not subscribed,
+// which are neither crawled nor changed since the crawl started
+func (s *Syncer) deleteNotSeenSince(crawlStartNs int64) {
+	if !s.flush() {
+		glog.Errorf("keep the documents not crawled under %s, since the crawled ones are not all indexed", s.option.Path)
+		return
+	}
+	if err := s.elastic.DeleteUnderIndexedBefore(s.option.Path, crawlStartNs); err != nil {
+		glog.Errorf("delete the documents not crawled under %s: %v", s.option.Path, err)
+	}
+}
+
+// indexUnder indexes all the entries under the directory recursively
+func (s *Syncer) indexUnder(ctx context.Context, dir string) (dirCount, fileCount int, err error) {
+	var subDirs []string
+	err = filer2.ReadDirAllEntries(ctx, s, dir, func(entry *filer_pb.Entry) {
+		s.add(Action{Path: path.Join(dir, entry.Name), Doc: DocumentOf(dir, entry)})
+		if entry.IsDirectory {
+			dirCount++
+			subDirs = append(subDirs, path.Join(dir, entry.Name))
+		} else {
+			fileCount++
+		}
+	})
+	for _, subDir := range subDirs {
+		if err != nil {
+			return
+		}
+		var subDirCount, subFileCount int
+		subDirCount, subFileCount, err = s.indexUnder(ctx, subDir)
+		dirCount, fileCount = dirCount+subDirCount, fileCount+subFileCount
+	}
+	return
+}
+
+func (s *Syncer) applyEvent(resp *filer_pb.SubscribeMetadataResponse) {
+	event := resp.EventNotification
+	if event == nil {
+		return
+	}
+	var oldPath, newPath string
+	if event.OldEntry != nil {
+		oldPath = path.Join(resp.Directory, event.OldEntry.Name)
+	}
+	if event.NewEntry != nil {
+		newParentPath := event.NewParentPath
+		if newParentPath == "" {
+			newParentPath = resp.Directory
+		}
+		newPath = path.Join(newParentPath, event.NewEntry.Name)
+		if !s.under(newPath) {
+			// moved out of the mirrored directory
+			newPath = ""
+		}
+	}
+	if oldPath != "" && oldPath != newPath {
+		s.add(Action{Path: oldPath})
+		if event.OldEntry.IsDirectory {
+			s.deleteUnder(oldPath)
+		}
+	}
+	if newPath == "" {
+		return
+	}
+	s.add(Action{Path: newPath, Doc: DocumentOf(path.Dir(newPath), event.NewEntry)})
+	if oldPath != "" && oldPath != newPath && event.NewEntry.IsDirectory {
+		// the entries under the renamed directory have no events of their own
+		go func() {
+			if _, _, err := s.indexUnder(context.Background(), newPath); err != nil {
+				glog.Errorf("index under %s: %v", newPath, err)
+			}
+		}()
+	}
+}
+
+func (s *Syncer) under(fullPath string) bool {
+	dir := strings.TrimSuffix(s.option.Path, "/")
+	return fullPath == s.option.Path || strings.HasPrefix(fullPath, dir+"/")
+}
+
+// deleteUnder flushes the pending actions first, so the later actions are not deleted
+func (s *Syncer) deleteUnder(dir string) {
+	s.flush()
+	if err := s.elastic.DeleteUnder(dir); err != nil {
+		glog.Errorf("delete index under %s: %v", dir, err)
+	}
+}
+
+func (s *Syncer) add(action Action) {
+	if action.Doc != nil {
+		action.Doc.IndexedAt = time.Now().UnixNano()
+	}
+	s.pendingLock.Lock()
+	s.pending = append(s.pending, action)
+	full := len(s.pending) >= s.option.BatchSize
+	s.pendingLock.Unlock()
+	if full {
+		s.flush()
+	}
+}
+
+func (s *Syncer) loopFlushing() {
+	for range time.Tick(s.option.FlushInterval) {
+		s.flush()
+	}
+}
+
+// flush sends the pending actions, and keeps them to retry if not sent, returning whether all are sent
+func (s *Syncer) flush() bool {
+	s.pendingLock.Lock()
+	defer s.pendingLock.Unlock()
+	if len(s.pending) == 0 {
+		return true
+	}
+	if err := s.elastic.Bulk(s.pending); err != nil {
+		glog.Errorf("index %d entries: %v", len(s.pending), err)
+		if _, rejected := err.(*BulkError); !rejected {
+			return false
+		}
+	}
+	glog.V(3).Infof("indexed %d entries", len(s.pending))
+	s.pending = nil
+	return true
+}
@@ -0,0 +1,151 @@
+package search
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+)
+
+// fakeElastic keeps the bulk actions and answers the searches with the given total
+type fakeElastic struct {
+	sync.Mutex
+	indexCreated bool
+	bulkLines    []string
+	searchBody   map[string]interface{}
+	deleteBody   string
+	total        string
+}
+
+func (f *fakeElastic) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+	body, _ := ioutil.ReadAll(r.Body)
+	switch {
+	case r.Method == "HEAD" && r.URL.Path == "/files":
+		if !f.indexCreated {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case r.Method == "PUT" && r.URL.Path == "/files":
+		f.indexCreated = true
+	case r.URL.Path == "/_bulk":
+		f.bulkLines = append(f.bulkLines, strings.Split(strings.TrimSpace(string(body)), "\n")...)
+		w.Write([]byte(`{"errors":true,"items":[{"delete":{"status":404}}]}`))
+	case r.URL.Path == "/files/_delete_by_query":
+		f.deleteBody = string(body)
+	case r.URL.Path == "/files/_search":
+		json.Unmarshal(body, &f.searchBody)
+		w.Write([]byte(`{"hits":{"total":` + f.total + `,"hits":[{"_source":{"path":"/docs/a.pdf","name":"a.pdf","size":2048}}]}}`))
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func TestSyncAndSearch(t *testing.T) {
+	fake := &fakeElastic{total: `{"value":7,"relation":"eq"}`}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	elastic := NewElasticClient([]string{"http://127.0.0.1:1", server.URL}, "files", "", "")
+	if err := elastic.EnsureIndex(); err != nil {
+		t.Fatal(err)
+	}
+	if !fake.indexCreated {
+		t.Errorf("index not created")
+	}
+
+	syncer := NewSyncer(&SyncOption{Path: "/docs", BatchSize: 100}, elastic)
+	entry := &filer_pb.Entry{
+		Name:       "a.pdf",
+		Attributes: &filer_pb.FuseAttributes{Mime: "application/pdf", FileSize: 2048},
+		Extended:   map[string][]byte{"project": []byte("apollo"), "binary": {0xff, 0xfe}},
+	}
+	// created, and then moved out of /docs
+	syncer.applyEvent(&filer_pb.SubscribeMetadataResponse{
+		Directory:         "/docs",
+		EventNotification: &filer_pb.EventNotification{NewEntry: entry, NewParentPath: "/docs"},
+	})
+	syncer.applyEvent(&filer_pb.SubscribeMetadataResponse{
+		Directory:         "/docs",
+		EventNotification: &filer_pb.EventNotification{OldEntry: entry, NewEntry: entry, NewParentPath: "/archive"},
+	})
+	syncer.flush()
+
+	if len(fake.bulkLines) != 3 {
+		t.Fatalf("bulk lines %q", fake.bulkLines)
+	}
+	if fake.bulkLines[0] != `{"index":{"_id":"`+DocumentId("/docs/a.pdf")+`","_index":"files"}}` {
+		t.Errorf("index action %s", fake.bulkLines[0])
+	}
+	doc := &Document{}
+	json.Unmarshal([]byte(fake.bulkLines[1]), doc)
+	if doc.Path != "/docs/a.pdf" || doc.Directory != "/docs" || doc.Mime != "application/pdf" || doc.Size != 2048 ||
+		len(doc.Extended) != 1 || doc.Extended["project"] != "apollo" {
+		t.Errorf("indexed %+v", doc)
+	}
+	if !strings.HasPrefix(fake.bulkLines[2], `{"delete":`) {
+		t.Errorf("delete action %s", fake.bulkLines[2])
+	}
+	if len(syncer.pending) != 0 {
+		t.Errorf("pending %d actions", len(syncer.pending))
+	}
+
+	query, err := ParseQuery(url.Values{"q": {"annual report"}, "path": {"/docs/"}, "minSize": {"1024"}, "attr": {"project=apollo"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, total := range []string{`{"value":7,"relation":"eq"}`, `7`} {
+		fake.total = total
+		result, err := elastic.Search(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Total != 7 || len(result.Hits) != 1 || result.Hits[0].Path != "/docs/a.pdf" {
+			t.Errorf("searched %+v", result)
+		}
+	}
+	searchBody, _ := json.Marshal(fake.searchBody)
+	for _, expected := range []string{`"query":"annual report"`, `"prefix":{"path":"/docs/"}`, `"gte":1024`, `"extended.project":"apollo"`} {
+		if !strings.Contains(string(searchBody), expected) {
+			t.Errorf("search body %s, expected %s", searchBody, expected)
+		}
+	}
+
+	if _, err = ParseQuery(url.Values{"attr": {"project"}}); err == nil {
+		t.Errorf("accepted an attribute without the value")
+	}
+}
+
+func TestDeleteNotCrawled(t *testing.T) {
+	fake := &fakeElastic{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	syncer := NewSyncer(&SyncOption{Path: "/docs", BatchSize: 100}, NewElasticClient([]string{server.URL}, "files", "", ""))
+	crawlStartNs := time.Now().UnixNano()
+	syncer.add(Action{Path: "/docs/a.pdf", Doc: DocumentOf("/docs", &filer_pb.Entry{Name: "a.pdf"})})
+	syncer.deleteNotSeenSince(crawlStartNs)
+
+	// the crawled entries are indexed before deleting the ones not crawled
+	if len(fake.bulkLines) != 2 {
+		t.Fatalf("bulk lines %q", fake.bulkLines)
+	}
+	doc := &Document{}
+	json.Unmarshal([]byte(fake.bulkLines[1]), doc)
+	if doc.IndexedAt < crawlStartNs {
+		t.Errorf("indexed at %d before the crawl at %d", doc.IndexedAt, crawlStartNs)
+	}
+	for _, expected := range []string{`"prefix":{"path":"/docs/"}`, `"must_not":{"range":{"indexed_at":{"gte":` + strconv.FormatInt(crawlStartNs, 10) + `}}}`} {
+		if !strings.Contains(fake.deleteBody, expected) {
+			t.Errorf("delete body %s, expected %s", fake.deleteBody, expected)
+		}
+	}
+}
@@ -76,6 +76,14 @@ func (g *Guard) WhiteList(f func(w http.ResponseWriter, r *http.Request)) func(w
 	}
 }
 
+// IsWhiteListed tells whether the request could call the handlers wrapped by WhiteList
+func (g *Guard) IsWhiteListed(r *http.Request) bool {
+	if !g.isWriteActive {
+		return true
+	}
+	return g.checkWhiteList(nil, r) == nil
+}
+
 func GetActualRemoteHost(r *http.Request) (host string, err error) {
 	host = r.Header.Get("HTTP_X_FORWARDED_FOR")
 	if host == "" {
@@ -96,32 +104,38 @@ func (g *Guard) checkWhiteList(w http.ResponseWriter, r *http.Request) error {
 	}
 
 	host, err := GetActualRemoteHost(r)
-	if err == nil {
-		for _, ip := range g.whiteList {
-
-			// If the whitelist entry contains a "/" it
-			// is a CIDR range, and we should check the
-			// remote host is within it
-			if strings.Contains(ip, "/") {
-				_, cidrnet, err := net.ParseCIDR(ip)
-				if err != nil {
-					panic(err)
-				}
-				remote := net.ParseIP(host)
-				if cidrnet.Contains(remote) {
-					return nil
-				}
-			}
-
-			//
-			// Otherwise we're looking for a literal match.
-			//
-			if ip == host {
-				return nil
-			}
-		}
+	if err == nil && MatchWhiteList(g.whiteList, host) {
+		return nil
 	}
 
 	glog.V(0).Infof("Not in whitelist: %s", r.RemoteAddr)
 	return fmt.Errorf("Not in whitelis: %s", r.RemoteAddr)
 }
+
+// MatchWhiteList tells whether the host is one of the ip addresses or in one of the CIDR ranges
+func MatchWhiteList(whiteList []string, host string) bool {
+	for _, ip := range whiteList {
+
+		// If the whitelist entry contains a "/" it
+		// is a CIDR range, and we should check the
+		// remote host is within it
+		if strings.Contains(ip, "/") {
+			_, cidrnet, err := net.ParseCIDR(ip)
+			if err != nil {
+				panic(err)
+			}
+			remote := net.ParseIP(host)
+			if cidrnet.Contains(remote) {
+				return true
+			}
+		}
+
+		//
+		// Otherwise we're looking for a literal match.
+		//
+		if ip == host {
+			return true
+		}
+	}
+	return false
+}
@@ -15,17 +15,78 @@ type SigningKey []byte
 
 type SeaweedFileIdClaims struct {
 	Fid string `json:"fid"`
+	// the durability of the writes assigned with fsync=true or ack=quorum, enforced by the volume servers
+	Fsync bool   `json:"fsync,omitempty"`
+	Ack   string `json:"ack,omitempty"`
+	// the number of the consecutive file keys assigned from the fid
+	Count uint64 `json:"count,omitempty"`
 	jwt.StandardClaims
 }
 
+// SeaweedIdentityClaims is the caller identity checked by the filer enforcing the permissions
+type SeaweedIdentityClaims struct {
+	Uid  uint32   `json:"uid"`
+	Gids []uint32 `json:"gids,omitempty"`
+	jwt.StandardClaims
+}
+
+// GenIdentityJwt signs the uid and gids of the caller, for the filer trusting only the signed identities
+func GenIdentityJwt(signingKey SigningKey, expiresAfterSec int, uid uint32, gids []uint32) EncodedJwt {
+	if len(signingKey) == 0 {
+		return ""
+	}
+
+	claims := SeaweedIdentityClaims{
+		Uid:  uid,
+		Gids: gids,
+	}
+	if expiresAfterSec > 0 {
+		claims.ExpiresAt = time.Now().Add(time.Second * time.Duration(expiresAfterSec)).Unix()
+	}
+	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	encoded, e := t.SignedString([]byte(signingKey))
+	if e != nil {
+		glog.V(0).Infof("Failed to sign claims %+v: %v", t.Claims, e)
+		return ""
+	}
+	return EncodedJwt(encoded)
+}
+
+// DecodeIdentityJwt verifies the signature and the expiration of the identity
+func DecodeIdentityJwt(signingKey SigningKey, tokenString EncodedJwt) (*SeaweedIdentityClaims, error) {
+	if len(signingKey) == 0 {
+		return nil, fmt.Errorf("no signing key for the identity")
+	}
+	claims := &SeaweedIdentityClaims{}
+	_, err := jwt.ParseWithClaims(string(tokenString), claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unknown token method")
+		}
+		return []byte(signingKey), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
 func GenJwt(signingKey SigningKey, expiresAfterSec int, fileId string) EncodedJwt {
+	return GenWriteJwt(signingKey, expiresAfterSec, fileId, 1, false, "")
+}
+
+// GenWriteJwt signs the assigned range and the write flags of the fid into the jwt, so the uploads can not weaken them
+func GenWriteJwt(signingKey SigningKey, expiresAfterSec int, fileId string, count uint64, fsync bool, ack string) EncodedJwt {
 	if len(signingKey) == 0 {
 		return ""
 	}
 
 	claims := SeaweedFileIdClaims{
-		fileId,
-		jwt.StandardClaims{},
+		Fid:   fileId,
+		Fsync: fsync,
+		Ack:   ack,
+	}
+	if count > 1 {
+		claims.Count = count
 	}
 	if expiresAfterSec > 0 {
 		claims.ExpiresAt = time.Now().Add(time.Second * time.Duration(expiresAfterSec)).Unix()
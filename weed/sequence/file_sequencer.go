@@ -0,0 +1,104 @@
+package sequence
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+)
+
+// the file ids are reserved in steps, to persist the high-water mark once per step
+const fileSequencerStep = 10000
+
+// FileSequencer persists a high-water mark of the issued file ids in the meta data folder,
+// so that a restarted master never issues a file id lower than the ones issued before,
+// even before the volume servers report their max file keys.
+type FileSequencer struct {
+	fileName     string
+	counter      uint64
+	reserved     uint64 // the persisted high-water mark, all issued file ids are below it
+	sequenceLock sync.Mutex
+}
+
+func NewFileSequencer(fileName string) (*FileSequencer, error) {
+	m := &FileSequencer{fileName: fileName, counter: 1}
+	data, err := ioutil.ReadFile(fileName)
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read sequencer high-water mark %s: %v", fileName, err)
+	}
+	// refuse to start from 1 with an unreadable high-water mark, to avoid silent file id collisions
+	mark, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse sequencer high-water mark %s: %v", fileName, err)
+	}
+	m.counter, m.reserved = mark, mark
+	glog.V(0).Infof("sequencer starts from the high-water mark %d in %s", mark, fileName)
+	return m, nil
+}
+
+func (m *FileSequencer) NextFileId(count uint64) (uint64, uint64, error) {
+	m.sequenceLock.Lock()
+	defer m.sequenceLock.Unlock()
+	if m.counter+count > m.reserved {
+		if err := m.reserve(m.counter + count + fileSequencerStep); err != nil {
+			return 0, 0, err
+		}
+	}
+	ret := m.counter
+	m.counter += count
+	return ret, count, nil
+}
+
+// SetMax audits the max file key seen in the volume servers.
+// A file key at or above the next file id means the sequencer has regressed,
+// e.g., the meta data folder is restored or replaced, and the file ids could have collided.
+func (m *FileSequencer) SetMax(seenValue uint64) {
+	m.sequenceLock.Lock()
+	defer m.sequenceLock.Unlock()
+	if m.counter > seenValue {
+		return
+	}
+	glog.Warningf("sequencer regression: file key %d is seen in the volume servers, but the next file id is %d, skip ahead", seenValue, m.counter)
+	m.counter = seenValue + 1
+	if m.counter > m.reserved {
+		if err := m.reserve(m.counter + fileSequencerStep); err != nil {
+			glog.Errorf("sequencer: %v", err)
+		}
+	}
+}
+
+func (m *FileSequencer) Peek() uint64 {
+	m.sequenceLock.Lock()
+	defer m.sequenceLock.Unlock()
+	return m.counter
+}
+
+// reserve persists the new high-water mark, before any file id below it is issued
+func (m *FileSequencer) reserve(mark uint64) error {
+	tmpFileName := m.fileName + ".tmp"
+	f, err := os.OpenFile(tmpFileName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("persist sequencer high-water mark: %v", err)
+	}
+	if _, err = f.WriteString(strconv.FormatUint(mark, 10)); err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpFileName, m.fileName)
+	}
+	if err != nil {
+		return fmt.Errorf("persist sequencer high-water mark %d to %s: %v", mark, m.fileName, err)
+	}
+	m.reserved = mark
+	return nil
+}
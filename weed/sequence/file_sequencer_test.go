@@ -0,0 +1,50 @@
+package sequence
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileSequencerRestart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sequence")
+	if err != nil {
+		t.Fatalf("temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "sequence.hwm")
+
+	m, err := NewFileSequencer(fileName)
+	if err != nil {
+		t.Fatalf("new sequencer: %v", err)
+	}
+	var last uint64
+	for i := 0; i < 3; i++ {
+		if last, _, err = m.NextFileId(fileSequencerStep / 2); err != nil {
+			t.Fatalf("next file id: %v", err)
+		}
+	}
+
+	m, err = NewFileSequencer(fileName)
+	if err != nil {
+		t.Fatalf("restart sequencer: %v", err)
+	}
+	next, _, err := m.NextFileId(1)
+	if err != nil {
+		t.Fatalf("next file id after restart: %v", err)
+	}
+	if next <= last {
+		t.Errorf("file id %d after restart is not above %d issued before", next, last)
+	}
+
+	m.SetMax(next + 3*fileSequencerStep)
+	if next, _, _ = m.NextFileId(1); next != m.Peek()-1 || next <= 3*fileSequencerStep {
+		t.Errorf("file id %d after the regression is not skipped ahead", next)
+	}
+
+	ioutil.WriteFile(fileName, []byte("garbage"), 0644)
+	if _, err = NewFileSequencer(fileName); err == nil {
+		t.Errorf("expecting error for a corrupted high-water mark")
+	}
+}
@@ -107,11 +107,18 @@ func submitForClientHandler(w http.ResponseWriter, r *http.Request, masterUrl st
 			return
 		}
 	}
+	writeFlags, pe := needle.ParseWriteFlags(r.FormValue("fsync"), r.FormValue("ack"))
+	if pe != nil {
+		writeJsonError(w, r, http.StatusBadRequest, pe)
+		return
+	}
 	ar := &operation.VolumeAssignRequest{
 		Count:       count,
 		Replication: r.FormValue("replication"),
 		Collection:  r.FormValue("collection"),
 		Ttl:         r.FormValue("ttl"),
+		Fsync:       writeFlags.Fsync,
+		Ack:         writeFlags.Ack,
 	}
 	assignResult, ae := operation.Assign(masterUrl, grpcDialOption, ar)
 	if ae != nil {
@@ -119,9 +126,13 @@ func submitForClientHandler(w http.ResponseWriter, r *http.Request, masterUrl st
 		return
 	}
 
-	url := "http://" + assignResult.Url + "/" + assignResult.Fid
+	url := assignResult.UploadUrl()
 	if lastModified != 0 {
-		url = url + "?ts=" + strconv.FormatUint(lastModified, 10)
+		if strings.Contains(url, "?") {
+			url = url + "&ts=" + strconv.FormatUint(lastModified, 10)
+		} else {
+			url = url + "?ts=" + strconv.FormatUint(lastModified, 10)
+		}
 	}
 
 	debug("upload file to store", url)
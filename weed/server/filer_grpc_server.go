@@ -14,11 +14,28 @@ import (
 	"github.com/chrislusf/seaweedfs/weed/operation"
 	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
 	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func (fs *FilerServer) LookupDirectoryEntry(ctx context.Context, req *filer_pb.LookupDirectoryEntryRequest) (*filer_pb.LookupDirectoryEntryResponse, error) {
 
-	entry, err := fs.filer.FindEntry(ctx, filer2.FullPath(filepath.ToSlash(filepath.Join(req.Directory, req.Name))))
+	readConsistency, err := filer2.ParseReadConsistency(req.ReadConsistency)
+	if err != nil {
+		return nil, err
+	}
+	ctx = filer2.WithReadConsistency(ctx, readConsistency)
+
+	fullpath := filer2.FullPath(filepath.ToSlash(filepath.Join(req.Directory, req.Name)))
+	id, err := fs.grpcIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = fs.filer.CheckAccess(ctx, id, fullpath, 0); err != nil {
+		return nil, lockedToGrpcError(err)
+	}
+
+	entry, err := fs.filer.FindEntry(ctx, fullpath)
 	if err != nil {
 		return nil, fmt.Errorf("%s not found under %s: %v", req.Name, req.Directory, err)
 	}
@@ -28,13 +45,37 @@ func (fs *FilerServer) LookupDirectoryEntry(ctx context.Context, req *filer_pb.L
 			Name:        req.Name,
 			IsDirectory: entry.IsDirectory(),
 			Attributes:  filer2.EntryAttributeToPb(entry),
-			Chunks:      entry.Chunks,
+			Chunks:      filer2.UnwrapChunkKeys(entry.Chunks),
+			Extended:    entry.Extended,
 		},
 	}, nil
 }
 
 func (fs *FilerServer) ListEntries(ctx context.Context, req *filer_pb.ListEntriesRequest) (*filer_pb.ListEntriesResponse, error) {
 
+	readConsistency, err := filer2.ParseReadConsistency(req.ReadConsistency)
+	if err != nil {
+		return nil, err
+	}
+	ctx = filer2.WithReadConsistency(ctx, readConsistency)
+
+	order, err := filer2.ParseListingOrder(req.SortBy, req.SortDesc)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := filer2.ParseEntryFields(req.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := fs.grpcIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = fs.filer.CheckAccess(ctx, id, filer2.FullPath(req.Directory), filer2.PermissionRead); err != nil {
+		return nil, lockedToGrpcError(err)
+	}
+
 	limit := int(req.Limit)
 	if limit == 0 {
 		limit = fs.option.DirListingLimit
@@ -43,8 +84,17 @@ func (fs *FilerServer) ListEntries(ctx context.Context, req *filer_pb.ListEntrie
 	resp := &filer_pb.ListEntriesResponse{}
 	lastFileName := req.StartFromFileName
 	includeLastFile := req.InclusiveStartFrom
+	if req.Prefix != "" && lastFileName < req.Prefix && order.IsNameOrder() {
+		// the entries are listed in the name order, so the matched ones are not before the prefix
+		lastFileName, includeLastFile = req.Prefix, true
+	}
+	pageSize := 1024
+	if !order.IsNameOrder() {
+		// the whole directory is sorted anyway
+		pageSize = fs.option.DirListingLimit
+	}
 	for limit > 0 {
-		entries, err := fs.filer.ListDirectoryEntries(ctx, filer2.FullPath(req.Directory), lastFileName, includeLastFile, 1024)
+		entries, err := fs.filer.ListDirectorySortedEntries(ctx, filer2.FullPath(req.Directory), lastFileName, includeLastFile, pageSize, order, fs.option.DirListingLimit)
 		if err != nil {
 			return nil, err
 		}
@@ -64,16 +114,16 @@ func (fs *FilerServer) ListEntries(ctx context.Context, req *filer_pb.ListEntrie
 				}
 			}
 
-			resp.Entries = append(resp.Entries, &filer_pb.Entry{
-				Name:        entry.Name(),
-				IsDirectory: entry.IsDirectory(),
-				Chunks:      entry.Chunks,
-				Attributes:  filer2.EntryAttributeToPb(entry),
-			})
+			pbEntry := fields.ToProtoEntry(entry)
+			pbEntry.Chunks = filer2.UnwrapChunkKeys(pbEntry.Chunks)
+			resp.Entries = append(resp.Entries, pbEntry)
 			limit--
+			if limit == 0 {
+				break
+			}
 		}
 
-		if len(resp.Entries) < 1024 {
+		if len(entries) < pageSize {
 			break
 		}
 
@@ -95,10 +145,13 @@ func (fs *FilerServer) LookupVolume(ctx context.Context, req *filer_pb.LookupVol
 			return nil, err
 		}
 		var locs []*filer_pb.Location
-		for _, loc := range fs.filer.MasterClient.GetLocations(uint32(vid)) {
+		// in the order to read, the whole volume replicas before the ec shards
+		for _, loc := range fs.filer.MasterClient.ReadLocations(uint32(vid)) {
 			locs = append(locs, &filer_pb.Location{
-				Url:       loc.Url,
-				PublicUrl: loc.PublicUrl,
+				Url:        loc.Url,
+				PublicUrl:  loc.PublicUrl,
+				DataCenter: loc.DataCenter,
+				Rack:       loc.Rack,
 			})
 		}
 		resp.LocationsMap[vidString] = &filer_pb.Locations{
@@ -112,33 +165,79 @@ func (fs *FilerServer) LookupVolume(ctx context.Context, req *filer_pb.LookupVol
 func (fs *FilerServer) CreateEntry(ctx context.Context, req *filer_pb.CreateEntryRequest) (resp *filer_pb.CreateEntryResponse, err error) {
 
 	fullpath := filer2.FullPath(filepath.ToSlash(filepath.Join(req.Directory, req.Entry.Name)))
+	defer fs.auditGrpc(ctx, "CreateEntry", fullpath, "", time.Now(), &err)
+	var oldChunks []*filer_pb.FileChunk
+	if oldEntry, findErr := fs.filer.FindEntry(ctx, fullpath); findErr == nil {
+		oldChunks = oldEntry.Chunks
+	}
+	if err = filer2.WrapChunkKeys(req.Entry.Chunks, oldChunks); err != nil {
+		return nil, err
+	}
 	chunks, garbages := filer2.CompactFileChunks(req.Entry.Chunks)
 
 	if req.Entry.Attributes == nil {
 		return nil, fmt.Errorf("can not create entry with empty attributes")
 	}
+	if !filer2.ValidRetentionMode(req.Entry.Attributes.RetentionMode) {
+		return nil, fmt.Errorf("unknown retention mode %s", req.Entry.Attributes.RetentionMode)
+	}
 
-	err = fs.filer.CreateEntry(ctx, &filer2.Entry{
+	newEntry := &filer2.Entry{
 		FullPath: fullpath,
 		Attr:     filer2.PbToEntryAttribute(req.Entry.Attributes),
 		Chunks:   chunks,
-	})
+		Extended: req.Entry.Extended,
+	}
+
+	ctx = filer2.WithLeaseHolder(ctx, req.LeaseHolder)
+	if req.OExcl {
+		unlock := fs.conditionalLocks.lock(fullpath)
+		defer unlock()
+		if _, findErr := fs.filer.FindEntry(ctx, fullpath); findErr == nil {
+			return nil, status.Errorf(codes.AlreadyExists, "%s already exists", fullpath)
+		}
+	}
+
+	id, err := fs.grpcIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	existingEntry, err := fs.filer.CheckWrite(ctx, id, fullpath)
+	if err == nil && existingEntry != nil {
+		err = fs.filer.CheckUpdate(ctx, id, existingEntry, newEntry)
+	} else if err == nil {
+		err = filer2.CheckNewOwner(id, fullpath, newEntry.Attr)
+	}
+	if err != nil {
+		return nil, lockedToGrpcError(err)
+	}
+
+	if req.IsReplicated {
+		ctx = filer2.WithReplicatedWrite(ctx)
+	}
+	err = fs.filer.CreateEntry(ctx, newEntry)
 
 	if err == nil {
-		fs.filer.DeleteChunks(fullpath, garbages)
+		// the garbage chunks of the old entry are deleted by the filer when replacing it
+		fs.filer.DeleteChunks(fullpath, filer2.MinusChunks(garbages, oldChunks))
 	}
 
-	return &filer_pb.CreateEntryResponse{}, err
+	return &filer_pb.CreateEntryResponse{}, lockedToGrpcError(err)
 }
 
 func (fs *FilerServer) UpdateEntry(ctx context.Context, req *filer_pb.UpdateEntryRequest) (*filer_pb.UpdateEntryResponse, error) {
 
 	fullpath := filepath.ToSlash(filepath.Join(req.Directory, req.Entry.Name))
+	ctx = filer2.WithLeaseHolder(ctx, req.LeaseHolder)
 	entry, err := fs.filer.FindEntry(ctx, filer2.FullPath(fullpath))
 	if err != nil {
 		return &filer_pb.UpdateEntryResponse{}, fmt.Errorf("not found %s: %v", fullpath, err)
 	}
 
+	if err = filer2.WrapChunkKeys(req.Entry.Chunks, entry.Chunks); err != nil {
+		return &filer_pb.UpdateEntryResponse{}, err
+	}
+
 	// remove old chunks if not included in the new ones
 	unusedChunks := filer2.MinusChunks(entry.Chunks, req.Entry.Chunks)
 
@@ -148,6 +247,7 @@ func (fs *FilerServer) UpdateEntry(ctx context.Context, req *filer_pb.UpdateEntr
 		FullPath: filer2.FullPath(filepath.ToSlash(filepath.Join(req.Directory, req.Entry.Name))),
 		Attr:     entry.Attr,
 		Chunks:   chunks,
+		Extended: req.Entry.Extended,
 	}
 
 	glog.V(3).Infof("updating %s: %+v, chunks %d: %v => %+v, chunks %d: %v",
@@ -166,6 +266,17 @@ func (fs *FilerServer) UpdateEntry(ctx context.Context, req *filer_pb.UpdateEntr
 		newEntry.Attr.Mime = req.Entry.Attributes.Mime
 		newEntry.Attr.UserName = req.Entry.Attributes.UserName
 		newEntry.Attr.GroupNames = req.Entry.Attributes.GroupName
+		newEntry.Attr.RetentionMode = req.Entry.Attributes.RetentionMode
+		newEntry.Attr.RetainUntil = time.Time{}
+		if req.Entry.Attributes.RetainUntil > 0 {
+			newEntry.Attr.RetainUntil = time.Unix(req.Entry.Attributes.RetainUntil, 0)
+		}
+		newEntry.Attr.LegalHold = req.Entry.Attributes.LegalHold
+		newEntry.Attr.FileSize = req.Entry.Attributes.FileSize
+		newEntry.Attr.ExpireAt = time.Time{}
+		if req.Entry.Attributes.ExpireAt > 0 {
+			newEntry.Attr.ExpireAt = time.Unix(req.Entry.Attributes.ExpireAt, 0)
+		}
 
 	}
 
@@ -173,9 +284,30 @@ func (fs *FilerServer) UpdateEntry(ctx context.Context, req *filer_pb.UpdateEntr
 		return &filer_pb.UpdateEntryResponse{}, err
 	}
 
+	if !filer2.ValidRetentionMode(newEntry.RetentionMode) {
+		return &filer_pb.UpdateEntryResponse{}, fmt.Errorf("unknown retention mode %s", newEntry.RetentionMode)
+	}
+
+	id, err := fs.grpcIdentity(ctx)
+	if err != nil {
+		return &filer_pb.UpdateEntryResponse{}, err
+	}
+	if err = fs.filer.CheckUpdate(ctx, id, entry, newEntry); err != nil {
+		return &filer_pb.UpdateEntryResponse{}, lockedToGrpcError(err)
+	}
+
+	if req.BypassGovernanceRetention {
+		ctx = filer2.WithGovernanceBypass(ctx)
+	}
+	if req.IsReplicated {
+		ctx = filer2.WithReplicatedWrite(ctx)
+	}
+
 	if err = fs.filer.UpdateEntry(ctx, entry, newEntry); err == nil {
 		fs.filer.DeleteChunks(entry.FullPath, unusedChunks)
 		fs.filer.DeleteChunks(entry.FullPath, garbages)
+	} else {
+		return &filer_pb.UpdateEntryResponse{}, lockedToGrpcError(err)
 	}
 
 	fs.filer.NotifyUpdateEvent(entry, newEntry, true)
@@ -183,9 +315,39 @@ func (fs *FilerServer) UpdateEntry(ctx context.Context, req *filer_pb.UpdateEntr
 	return &filer_pb.UpdateEntryResponse{}, err
 }
 
+func (fs *FilerServer) PatchEntry(ctx context.Context, req *filer_pb.PatchEntryRequest) (*filer_pb.PatchEntryResponse, error) {
+	if req.BypassGovernanceRetention {
+		ctx = filer2.WithGovernanceBypass(ctx)
+	}
+	fullpath := filer2.FullPath(filepath.ToSlash(filepath.Join(req.Directory, req.Name)))
+	ctx = filer2.WithLeaseHolder(ctx, req.LeaseHolder)
+	id, err := fs.grpcIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = fs.filer.CheckAccess(ctx, id, fullpath, filer2.PermissionWrite); err == nil {
+		if err = filer2.WrapChunkKeys(req.Chunks, nil); err == nil {
+			err = fs.filer.PatchEntry(ctx, fullpath, req.Chunks)
+		}
+	}
+	return &filer_pb.PatchEntryResponse{}, lockedToGrpcError(err)
+}
+
 func (fs *FilerServer) DeleteEntry(ctx context.Context, req *filer_pb.DeleteEntryRequest) (resp *filer_pb.DeleteEntryResponse, err error) {
-	err = fs.filer.DeleteEntryMetaAndData(ctx, filer2.FullPath(filepath.ToSlash(filepath.Join(req.Directory, req.Name))), req.IsRecursive, req.IsDeleteData)
-	return &filer_pb.DeleteEntryResponse{}, err
+	if req.BypassGovernanceRetention {
+		ctx = filer2.WithGovernanceBypass(ctx)
+	}
+	ctx = filer2.WithLeaseHolder(ctx, req.LeaseHolder)
+	fullpath := filer2.FullPath(filepath.ToSlash(filepath.Join(req.Directory, req.Name)))
+	defer fs.auditGrpc(ctx, "DeleteEntry", fullpath, "", time.Now(), &err)
+	id, err := fs.grpcIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = fs.filer.CheckModify(ctx, id, fullpath); err == nil {
+		err = fs.filer.DeleteEntryMetaAndData(ctx, fullpath, req.IsRecursive, req.IsDeleteData)
+	}
+	return &filer_pb.DeleteEntryResponse{}, lockedToGrpcError(err)
 }
 
 func (fs *FilerServer) AssignVolume(ctx context.Context, req *filer_pb.AssignVolumeRequest) (resp *filer_pb.AssignVolumeResponse, err error) {
@@ -203,19 +365,21 @@ func (fs *FilerServer) AssignVolume(ctx context.Context, req *filer_pb.AssignVol
 	}
 
 	assignRequest := &operation.VolumeAssignRequest{
-		Count:       uint64(req.Count),
-		Replication: req.Replication,
-		Collection:  req.Collection,
-		Ttl:         ttlStr,
-		DataCenter:  dataCenter,
+		Count:         uint64(req.Count),
+		Replication:   req.Replication,
+		Collection:    req.Collection,
+		Ttl:           ttlStr,
+		DataCenter:    dataCenter,
+		LocalityGroup: req.LocalityGroup,
 	}
 	if dataCenter != "" {
 		altRequest = &operation.VolumeAssignRequest{
-			Count:       uint64(req.Count),
-			Replication: req.Replication,
-			Collection:  req.Collection,
-			Ttl:         ttlStr,
-			DataCenter:  "",
+			Count:         uint64(req.Count),
+			Replication:   req.Replication,
+			Collection:    req.Collection,
+			Ttl:           ttlStr,
+			DataCenter:    "",
+			LocalityGroup: req.LocalityGroup,
 		}
 	}
 	assignResult, err := operation.Assign(fs.filer.GetMaster(), fs.grpcDialOption, assignRequest, altRequest)
@@ -276,3 +440,15 @@ func (fs *FilerServer) GetFilerConfiguration(ctx context.Context, req *filer_pb.
 		MaxMb:       uint32(fs.option.MaxMB),
 	}, nil
 }
+
+// lockedToGrpcError lets the clients tell the entries under retention or legal hold,
+// and the denied permissions, from the other errors
+func lockedToGrpcError(err error) error {
+	if filer2.IsEntryLocked(err) || filer2.IsPermissionDenied(err) {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	if filer2.IsLeased(err) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,47 @@
+package weed_server
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// the lease period if not requested, as the soft limit of the hdfs leases
+const defaultLeaseSeconds = 60
+
+func (fs *FilerServer) AcquireLease(ctx context.Context, req *filer_pb.AcquireLeaseRequest) (*filer_pb.AcquireLeaseResponse, error) {
+	if req.Holder == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing lease holder")
+	}
+	fullpath := filer2.FullPath(filepath.ToSlash(filepath.Join(req.Directory, req.Name)))
+	id, err := fs.grpcIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = fs.filer.CheckWrite(ctx, id, fullpath); err != nil {
+		return nil, lockedToGrpcError(err)
+	}
+
+	leaseSeconds := req.LeaseSeconds
+	if leaseSeconds <= 0 {
+		leaseSeconds = defaultLeaseSeconds
+	}
+	expireAt, err := fs.filer.AcquireLease(fullpath, req.Holder, time.Duration(leaseSeconds)*time.Second)
+	if err != nil {
+		return nil, lockedToGrpcError(err)
+	}
+	return &filer_pb.AcquireLeaseResponse{ExpireAtNs: expireAt.UnixNano()}, nil
+}
+
+func (fs *FilerServer) ReleaseLease(ctx context.Context, req *filer_pb.ReleaseLeaseRequest) (*filer_pb.ReleaseLeaseResponse, error) {
+	fullpath := filer2.FullPath(filepath.ToSlash(filepath.Join(req.Directory, req.Name)))
+	if err := fs.filer.ReleaseLease(fullpath, req.Holder); err != nil {
+		return nil, lockedToGrpcError(err)
+	}
+	return &filer_pb.ReleaseLeaseResponse{}, nil
+}
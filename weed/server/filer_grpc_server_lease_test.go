@@ -0,0 +1,60 @@
+package weed_server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/filer2/memdb"
+)
+
+func TestFilerEnforcesLeases(t *testing.T) {
+	store := &memdb.MemDbStore{}
+	if err := store.Initialize(nil); err != nil {
+		t.Fatalf("initialize store: %v", err)
+	}
+	f := filer2.NewFiler(nil, nil)
+	f.SetStore(store)
+	fs := &FilerServer{filer: f}
+
+	ctx := context.Background()
+	holder := filer2.WithLeaseHolder(ctx, "client1")
+	file := &filer2.Entry{FullPath: "/a/b", Attr: filer2.Attr{Mode: 0644}}
+	if err := f.CreateEntry(ctx, file); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := f.AcquireLease("/a/b", "client1", time.Minute); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	if err := f.CreateEntry(ctx, &filer2.Entry{FullPath: "/a/b", Attr: filer2.Attr{Mode: 0600}}); !filer2.IsLeased(err) {
+		t.Errorf("overwritten without the lease: %v", err)
+	}
+	if err := f.UpdateEntry(ctx, file, &filer2.Entry{FullPath: "/a/b", Attr: filer2.Attr{Mode: 0600}}); !filer2.IsLeased(err) {
+		t.Errorf("updated without the lease: %v", err)
+	}
+	if err := fs.renameEntry(ctx, nil, "/a", "b", "/a", "c"); !filer2.IsLeased(err) {
+		t.Errorf("renamed without the lease: %v", err)
+	}
+	if _, err := f.FindEntry(ctx, "/a/c"); err != filer2.ErrNotFound {
+		t.Errorf("renamed without the lease: %v", err)
+	}
+	if err := f.DeleteEntryMetaAndData(ctx, "/a", true, false); err == nil {
+		t.Errorf("deleted the directory of the leased file")
+	}
+	if err := f.DeleteEntryMetaAndData(ctx, "/a/b", false, false); !filer2.IsLeased(err) {
+		t.Errorf("deleted without the lease: %v", err)
+	}
+
+	if err := f.UpdateEntry(holder, file, &filer2.Entry{FullPath: "/a/b", Attr: filer2.Attr{Mode: 0600}}); err != nil {
+		t.Errorf("update by the holder: %v", err)
+	}
+	if err := f.DeleteEntryMetaAndData(holder, "/a/b", false, false); err != nil {
+		t.Errorf("delete by the holder: %v", err)
+	}
+	// the lease of the deleted file is gone
+	if err := f.CreateEntry(ctx, file); err != nil {
+		t.Errorf("create after deleting the leased file: %v", err)
+	}
+}
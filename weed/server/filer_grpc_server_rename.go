@@ -7,34 +7,62 @@ import (
 	"github.com/chrislusf/seaweedfs/weed/glog"
 	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
 	"path/filepath"
+	"time"
 )
 
-func (fs *FilerServer) AtomicRenameEntry(ctx context.Context, req *filer_pb.AtomicRenameEntryRequest) (*filer_pb.AtomicRenameEntryResponse, error) {
+func (fs *FilerServer) AtomicRenameEntry(ctx context.Context, req *filer_pb.AtomicRenameEntryRequest) (resp *filer_pb.AtomicRenameEntryResponse, err error) {
 
 	glog.V(1).Infof("AtomicRenameEntry %v", req)
 
-	ctx, err := fs.filer.BeginTransaction(ctx)
+	oldParent := filer2.FullPath(filepath.ToSlash(req.OldDirectory))
+	newParent := filer2.FullPath(filepath.ToSlash(req.NewDirectory))
+	defer fs.auditGrpc(ctx, "AtomicRenameEntry", oldParent.Child(req.OldName), newParent.Child(req.NewName), time.Now(), &err)
+
+	ctx = filer2.WithLeaseHolder(ctx, req.LeaseHolder)
+	id, err := fs.grpcIdentity(ctx)
 	if err != nil {
 		return nil, err
 	}
+	if err = fs.renameEntry(ctx, id, oldParent, req.OldName, newParent, req.NewName); err != nil {
+		return nil, lockedToGrpcError(err)
+	}
 
-	oldParent := filer2.FullPath(filepath.ToSlash(req.OldDirectory))
+	return &filer_pb.AtomicRenameEntryResponse{}, nil
+}
+
+// renameEntry moves the entry, and all the sub entries of a directory, in one transaction
+func (fs *FilerServer) renameEntry(ctx context.Context, id *filer2.Identity, oldParent filer2.FullPath, oldName string, newParent filer2.FullPath, newName string) (err error) {
+
+	if err = fs.filer.CheckModify(ctx, id, oldParent.Child(oldName)); err == nil {
+		err = fs.filer.CheckModify(ctx, id, newParent.Child(newName))
+	}
+	if err != nil {
+		return err
+	}
 
-	oldEntry, err := fs.filer.FindEntry(ctx, oldParent.Child(req.OldName))
+	ctx, err = fs.filer.BeginTransaction(ctx)
+	if err != nil {
+		return err
+	}
+
+	oldEntry, err := fs.filer.FindEntry(ctx, oldParent.Child(oldName))
 	if err != nil {
 		fs.filer.RollbackTransaction(ctx)
-		return nil, fmt.Errorf("%s/%s not found: %v", req.OldDirectory, req.OldName, err)
+		return fmt.Errorf("%s not found: %v", oldParent.Child(oldName), err)
 	}
 
 	var events MoveEvents
-	moveErr := fs.moveEntry(ctx, oldParent, oldEntry, filer2.FullPath(filepath.ToSlash(req.NewDirectory)), req.NewName, &events)
+	moveErr := fs.moveEntry(ctx, oldParent, oldEntry, newParent, newName, &events)
 	if moveErr != nil {
 		fs.filer.RollbackTransaction(ctx)
-		return nil, fmt.Errorf("%s/%s move error: %v", req.OldDirectory, req.OldName, err)
+		if filer2.IsEntryLocked(moveErr) || filer2.IsLeased(moveErr) {
+			return moveErr
+		}
+		return fmt.Errorf("%s move error: %v", oldParent.Child(oldName), moveErr)
 	} else {
 		if commitError := fs.filer.CommitTransaction(ctx); commitError != nil {
 			fs.filer.RollbackTransaction(ctx)
-			return nil, fmt.Errorf("%s/%s move commit error: %v", req.OldDirectory, req.OldName, err)
+			return fmt.Errorf("%s move commit error: %v", oldParent.Child(oldName), commitError)
 		}
 	}
 
@@ -45,7 +73,7 @@ func (fs *FilerServer) AtomicRenameEntry(ctx context.Context, req *filer_pb.Atom
 		fs.filer.NotifyUpdateEvent(entry, nil, false)
 	}
 
-	return &filer_pb.AtomicRenameEntryResponse{}, nil
+	return nil
 }
 
 func (fs *FilerServer) moveEntry(ctx context.Context, oldParent filer2.FullPath, entry *filer2.Entry, newParent filer2.FullPath, newName string, events *MoveEvents) error {
@@ -101,11 +129,20 @@ func (fs *FilerServer) moveSelfEntry(ctx context.Context, oldParent filer2.FullP
 		return nil
 	}
 
+	// a locked entry can not be moved away, since the old entry would be deleted
+	if err := fs.filer.CheckDeletion(ctx, entry); err != nil {
+		return err
+	}
+	if err := fs.filer.CheckLease(ctx, oldPath); err != nil {
+		return err
+	}
+
 	// add to new directory
 	newEntry := &filer2.Entry{
 		FullPath: newPath,
 		Attr:     entry.Attr,
 		Chunks:   entry.Chunks,
+		Extended: entry.Extended,
 	}
 	createErr := fs.filer.CreateEntry(ctx, newEntry)
 	if createErr != nil {
@@ -0,0 +1,33 @@
+package weed_server
+
+import (
+	"context"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+)
+
+func (fs *FilerServer) CreateSnapshot(ctx context.Context, req *filer_pb.CreateSnapshotRequest) (*filer_pb.CreateSnapshotResponse, error) {
+
+	entryCount, chunkCount, err := fs.filer.CreateSnapshot(ctx, filer2.FullPath(req.Directory), req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &filer_pb.CreateSnapshotResponse{
+		EntryCount: uint64(entryCount),
+		ChunkCount: uint64(chunkCount),
+	}, nil
+}
+
+func (fs *FilerServer) DeleteSnapshot(ctx context.Context, req *filer_pb.DeleteSnapshotRequest) (*filer_pb.DeleteSnapshotResponse, error) {
+
+	deletedChunkCount, err := fs.filer.DeleteSnapshot(ctx, req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &filer_pb.DeleteSnapshotResponse{
+		DeletedChunkCount: uint64(deletedChunkCount),
+	}, nil
+}
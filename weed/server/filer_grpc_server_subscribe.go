@@ -0,0 +1,37 @@
+package weed_server
+
+import (
+	"fmt"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+	"google.golang.org/grpc/peer"
+)
+
+// SubscribeMetadata streams the metadata changes under the path prefix,
+// for the clients to keep their caches coherent.
+func (fs *FilerServer) SubscribeMetadata(req *filer_pb.SubscribeMetadataRequest, stream filer_pb.SeaweedFiler_SubscribeMetadataServer) error {
+
+	clientName := req.ClientName
+	if pr, ok := peer.FromContext(stream.Context()); ok && pr.Addr != nil {
+		clientName += "@" + pr.Addr.String()
+	}
+
+	events, unsubscribe := fs.filer.SubscribeMetadata(clientName, req.PathPrefix)
+	defer unsubscribe()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return fmt.Errorf("metadata subscriber %s fell behind", clientName)
+			}
+			if err := stream.Send(event); err != nil {
+				glog.V(0).Infof("=> metadata subscriber %s: %v", clientName, err)
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
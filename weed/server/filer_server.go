@@ -7,6 +7,10 @@ import (
 	"os"
 	"time"
 
+	"github.com/chrislusf/seaweedfs/weed/audit"
+	_ "github.com/chrislusf/seaweedfs/weed/audit/file"
+	_ "github.com/chrislusf/seaweedfs/weed/audit/kafka"
+	_ "github.com/chrislusf/seaweedfs/weed/audit/syslog"
 	"github.com/chrislusf/seaweedfs/weed/operation"
 	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
 	"github.com/chrislusf/seaweedfs/weed/stats"
@@ -28,6 +32,9 @@ import (
 	_ "github.com/chrislusf/seaweedfs/weed/notification/google_pub_sub"
 	_ "github.com/chrislusf/seaweedfs/weed/notification/kafka"
 	_ "github.com/chrislusf/seaweedfs/weed/notification/log"
+	_ "github.com/chrislusf/seaweedfs/weed/notification/nats"
+	_ "github.com/chrislusf/seaweedfs/weed/notification/rabbitmq"
+	_ "github.com/chrislusf/seaweedfs/weed/notification/webhook"
 	"github.com/chrislusf/seaweedfs/weed/security"
 	"github.com/spf13/viper"
 )
@@ -44,6 +51,26 @@ type FilerOption struct {
 	DefaultLevelDbDir  string
 	DisableHttp        bool
 	Port               int
+	VolumeServers      []string
+	Dedup              bool
+	// the concurrency limits of the http requests
+	ConcurrentRequests          int
+	ConcurrentRequestsPerClient int
+	RequestQueueSize            int
+	ClientIdHeader              string
+	// the requests and the bytes per second, in total and by each client
+	RateLimit          util.RateLimit
+	RateLimitPerClient util.RateLimit
+	EnforcePermissions bool
+	ReadFallback       bool
+	// the ingested records are appended to their files on every flush interval
+	IngestFlushInterval time.Duration
+	IngestMaxBufferMB   int
+	// the transformed images are cached in the collection, and not cached if empty
+	ImageCacheCollection string
+	ImageCacheTtl        string
+	// stamps the entries written locally, to resolve the conflicts with the other clusters
+	ClusterId string
 }
 
 type FilerServer struct {
@@ -51,6 +78,13 @@ type FilerServer struct {
 	secret         security.SigningKey
 	filer          *filer2.Filer
 	grpcDialOption grpc.DialOption
+	limiter        *requestLimiter
+	rateLimiter    *requestRateLimiter
+	// serializes the conditional writes of each path
+	conditionalLocks conditionalLocks
+	ingest           *ingestBatcher
+	imageTransforms  imageTransforms
+	identityVerifier *identityVerifier
 }
 
 func NewFilerServer(defaultMux, readonlyMux *http.ServeMux, option *FilerOption) (fs *FilerServer, err error) {
@@ -58,6 +92,8 @@ func NewFilerServer(defaultMux, readonlyMux *http.ServeMux, option *FilerOption)
 	fs = &FilerServer{
 		option:         option,
 		grpcDialOption: security.LoadClientTLS(viper.Sub("grpc"), "filer"),
+		limiter:        newRequestLimiter(option.ConcurrentRequests, option.ConcurrentRequestsPerClient, option.RequestQueueSize, option.ClientIdHeader),
+		rateLimiter:    newRequestRateLimiter(option.RateLimit, option.RateLimitPerClient),
 	}
 
 	if len(option.Masters) == 0 {
@@ -65,6 +101,10 @@ func NewFilerServer(defaultMux, readonlyMux *http.ServeMux, option *FilerOption)
 	}
 
 	fs.filer = filer2.NewFiler(option.Masters, fs.grpcDialOption)
+	fs.filer.MasterClient.SetVolumeServerSeeds(option.VolumeServers)
+	fs.filer.MasterClient.SetReadFallback(option.ReadFallback)
+	fs.filer.MasterClient.SetDataCenter(option.DataCenter)
+	fs.filer.SetClusterId(option.ClusterId)
 
 	go fs.filer.KeepConnectedToMaster()
 
@@ -78,17 +118,47 @@ func NewFilerServer(defaultMux, readonlyMux *http.ServeMux, option *FilerOption)
 		}
 	}
 	util.LoadConfiguration("notification", false)
+	util.LoadConfiguration("audit", false)
 
 	fs.filer.LoadConfiguration(v)
 
+	if err := fs.filer.LoadSnapshots(context.Background()); err != nil {
+		glog.Fatalf("snapshot chunks are not protected: %v", err)
+	}
+	if option.Dedup {
+		err = fs.filer.EnableDedup(context.Background())
+	} else {
+		err = fs.filer.LoadDedup(context.Background())
+	}
+	if err != nil {
+		glog.Fatalf("deduplicated chunks are not protected: %v", err)
+	}
+
+	fs.identityVerifier = &identityVerifier{
+		signingKey:     security.SigningKey(v.GetString("filer_identity.key")),
+		trustedProxies: v.GetStringSlice("filer_identity.trusted_proxies"),
+	}
+
+	if err := fs.filer.LoadInventoryConfiguration(v.Sub("inventory")); err != nil {
+		glog.Errorf("inventory is not generated: %v", err)
+	}
+
+	fs.filer.LoadCompressionConfiguration(v.Sub("compression"))
+	fs.filer.LoadEncryptionConfiguration(v.Sub("encryption"))
+
+	fs.filer.LoadMetaBackupConfiguration(v.Sub("meta_backup"))
+
+	fs.startIngestion()
+
 	notification.LoadConfiguration(v.Sub("notification"))
+	audit.LoadConfiguration(v.Sub("audit"))
 
 	handleStaticResources(defaultMux)
 	if !option.DisableHttp {
-		defaultMux.HandleFunc("/", fs.filerHandler)
+		defaultMux.HandleFunc("/", fs.auditRequests(fs.limitRate(fs.limitConcurrency(fs.filerHandler))))
 	}
 	if defaultMux != readonlyMux {
-		readonlyMux.HandleFunc("/", fs.readonlyFilerHandler)
+		readonlyMux.HandleFunc("/", fs.auditRequests(fs.limitRate(fs.limitConcurrency(fs.readonlyFilerHandler))))
 	}
 
 	maybeStartMetrics(fs, option)
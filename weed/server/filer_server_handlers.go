@@ -1,12 +1,17 @@
 package weed_server
 
 import (
+	"context"
 	"net/http"
 	"time"
 
+	"github.com/chrislusf/seaweedfs/weed/filer2"
 	"github.com/chrislusf/seaweedfs/weed/stats"
 )
 
+// ReadConsistencyHeader asks for the read consistency of the filer store, "eventual" or "strong"
+const ReadConsistencyHeader = "Seaweed-Read-Consistency"
+
 func (fs *FilerServer) filerHandler(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
 	switch r.Method {
@@ -46,3 +51,12 @@ func (fs *FilerServer) readonlyFilerHandler(w http.ResponseWriter, r *http.Reque
 		stats.FilerRequestHistogram.WithLabelValues("head").Observe(time.Since(start).Seconds())
 	}
 }
+
+// readContext has the read consistency asked by the request header
+func readContext(r *http.Request) (context.Context, error) {
+	readConsistency, err := filer2.ParseReadConsistency(r.Header.Get(ReadConsistencyHeader))
+	if err != nil {
+		return nil, err
+	}
+	return filer2.WithReadConsistency(context.Background(), readConsistency), nil
+}
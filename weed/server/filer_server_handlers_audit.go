@@ -0,0 +1,110 @@
+package weed_server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/audit"
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// auditRequests logs each http request to the audit log, if any audit sink is enabled.
+// Who sends the request is the verified identity of the caller, as checked for the permissions,
+// and the remote ip is the connected address, not any forwarded header.
+func (fs *FilerServer) auditRequests(f http.HandlerFunc) http.HandlerFunc {
+	if !audit.IsEnabled() {
+		return f
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		aw := audit.NewResponseWriter(w)
+		f(aw, r)
+
+		remoteIp, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			remoteIp = r.RemoteAddr
+		}
+		action := r.Method
+		if op := r.URL.Query().Get("op"); op != "" {
+			action += "." + op
+		}
+		bytesIn := r.ContentLength
+		if bytesIn < 0 {
+			bytesIn = 0
+		}
+		audit.Log(&audit.Event{
+			Time:      start.UTC(),
+			Server:    "filer",
+			Who:       auditIdentity(fs.identityVerifier.fromHttp(r)),
+			RemoteIp:  remoteIp,
+			Action:    action,
+			Path:      r.URL.Path,
+			BytesIn:   bytesIn,
+			BytesOut:  aw.BytesSent,
+			Status:    aw.Status,
+			LatencyMs: time.Since(start).Nanoseconds() / int64(time.Millisecond),
+		})
+	}
+}
+
+// auditGrpc logs the grpc request changing the entry, after it returns the error,
+// with the http status of the grpc code
+func (fs *FilerServer) auditGrpc(ctx context.Context, action string, p, newPath filer2.FullPath, start time.Time, err *error) {
+	if !audit.IsEnabled() {
+		return
+	}
+	var remoteIp string
+	if pr, ok := peer.FromContext(ctx); ok {
+		remoteIp = pr.Addr.String()
+		if host, _, splitErr := net.SplitHostPort(remoteIp); splitErr == nil {
+			remoteIp = host
+		}
+	}
+	audit.Log(&audit.Event{
+		Time:      start.UTC(),
+		Server:    "filer",
+		Who:       auditIdentity(fs.identityVerifier.fromGrpc(ctx)),
+		RemoteIp:  remoteIp,
+		Action:    "grpc." + action,
+		Path:      string(p),
+		NewPath:   string(newPath),
+		Status:    grpcHttpStatus(*err),
+		LatencyMs: time.Since(start).Nanoseconds() / int64(time.Millisecond),
+	})
+}
+
+// auditIdentity is like "uid 1000", "nobody" without any identity claims, or "unverified" if the claims are rejected
+func auditIdentity(id *filer2.Identity, err error) string {
+	if err != nil {
+		return "unverified"
+	}
+	if id.Uid == filer2.NobodyId {
+		return "nobody"
+	}
+	return fmt.Sprintf("uid %d", id.Uid)
+}
+
+func grpcHttpStatus(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	switch status.Code(lockedToGrpcError(err)) {
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists:
+		return http.StatusConflict
+	case codes.FailedPrecondition:
+		return http.StatusPreconditionFailed
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,138 @@
+package weed_server
+
+import (
+	"context"
+	"errors"
+	"hash/crc32"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+)
+
+// errPreconditionFailed is replied with 412 Precondition Failed
+var errPreconditionFailed = errors.New("precondition failed")
+
+// conditionalLocks serializes the conditional writes to the same path,
+// from checking the preconditions to saving the entry.
+// The exclusive creates through grpc are also serialized here.
+// The other writes without the conditional headers, or through grpc, are not serialized.
+type conditionalLocks [64]sync.Mutex
+
+func (locks *conditionalLocks) lock(p filer2.FullPath) (unlock func()) {
+	m := &locks[crc32.ChecksumIEEE([]byte(p))%uint32(len(locks))]
+	m.Lock()
+	return m.Unlock
+}
+
+func hasWritePreconditions(r *http.Request) bool {
+	return r.Header.Get("If-Match") != "" || r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Unmodified-Since") != ""
+}
+
+// checkReadPreconditions evaluates the conditional headers of GET and HEAD in the order of RFC 7232 section 6,
+// and replies 412 or 304 if the content should not be sent. It returns true if replied.
+func checkReadPreconditions(w http.ResponseWriter, r *http.Request, entry *filer2.Entry) (replied bool) {
+	etag := filer2.ETag(entry.Chunks)
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		if !etagListMatches(ifMatch, etag, true) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return true
+		}
+	} else if since, ok := parseHttpDate(r.Header.Get("If-Unmodified-Since")); ok && modifiedAfter(entry, since) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return true
+	}
+
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		if !etagListMatches(ifNoneMatch, etag, false) {
+			return false
+		}
+	} else if since, ok := parseHttpDate(r.Header.Get("If-Modified-Since")); !ok || modifiedAfter(entry, since) {
+		return false
+	}
+
+	w.Header().Set("Last-Modified", entry.Attr.Mtime.UTC().Format(http.TimeFormat))
+	setEtag(w, etag)
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// checkWritePreconditions evaluates the conditional headers of the writes and deletions on the current entry
+func (fs *FilerServer) checkWritePreconditions(ctx context.Context, r *http.Request, p filer2.FullPath) error {
+	entry, err := fs.filer.FindEntry(ctx, p)
+	if err == filer2.ErrNotFound {
+		entry, err = nil, nil
+	}
+	if err != nil {
+		return err
+	}
+
+	etag := ""
+	if entry != nil {
+		etag = filer2.ETag(entry.Chunks)
+	}
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		if entry == nil || !etagListMatches(ifMatch, etag, true) {
+			return errPreconditionFailed
+		}
+	} else if since, ok := parseHttpDate(r.Header.Get("If-Unmodified-Since")); ok && entry != nil && modifiedAfter(entry, since) {
+		return errPreconditionFailed
+	}
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		if entry != nil && etagListMatches(ifNoneMatch, etag, false) {
+			return errPreconditionFailed
+		}
+	}
+	return nil
+}
+
+// conditionalWrite runs the write to the path if the preconditions hold,
+// without other conditional writes to the same path in between.
+func (fs *FilerServer) conditionalWrite(ctx context.Context, r *http.Request, p filer2.FullPath, fn func() error) error {
+	if !hasWritePreconditions(r) {
+		return fn()
+	}
+	unlock := fs.conditionalLocks.lock(p)
+	defer unlock()
+	if err := fs.checkWritePreconditions(ctx, r, p); err != nil {
+		return err
+	}
+	return fn()
+}
+
+// etagListMatches compares the etag with the list of the entity tags in the header, or "*".
+// The strong comparison does not match the weak entity tags.
+func etagListMatches(header, etag string, strong bool) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if strings.HasPrefix(tag, "W/") {
+			if strong {
+				continue
+			}
+			tag = tag[2:]
+		}
+		if strings.Trim(tag, "\"") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// parseHttpDate parses the date of the conditional header, which is ignored if missing or invalid
+func parseHttpDate(httpDate string) (time.Time, bool) {
+	if httpDate == "" {
+		return time.Time{}, false
+	}
+	t, err := http.ParseTime(httpDate)
+	return t, err == nil
+}
+
+// modifiedAfter compares in the resolution of seconds, as the http dates are
+func modifiedAfter(entry *filer2.Entry, t time.Time) bool {
+	return entry.Attr.Mtime.Truncate(time.Second).After(t)
+}
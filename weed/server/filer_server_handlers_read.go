@@ -1,7 +1,7 @@
 package weed_server
 
 import (
-	"context"
+	"encoding/base64"
 	"io"
 	"io/ioutil"
 	"mime"
@@ -14,6 +14,7 @@ import (
 
 	"github.com/chrislusf/seaweedfs/weed/filer2"
 	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/images"
 	"github.com/chrislusf/seaweedfs/weed/stats"
 	"github.com/chrislusf/seaweedfs/weed/util"
 )
@@ -26,7 +27,23 @@ func (fs *FilerServer) GetOrHeadHandler(w http.ResponseWriter, r *http.Request,
 		path = path[:len(path)-1]
 	}
 
-	entry, err := fs.filer.FindEntry(context.Background(), filer2.FullPath(path))
+	ctx, err := readContext(r)
+	if err != nil {
+		writeJsonError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	id, err := fs.httpIdentity(r)
+	if err != nil {
+		writeJsonError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if err = fs.filer.CheckAccess(ctx, id, filer2.FullPath(path), filer2.PermissionRead); err != nil {
+		writeJsonError(w, r, filerErrorStatus(err), err)
+		return
+	}
+
+	entry, err := fs.filer.FindEntry(ctx, filer2.FullPath(path))
 	if err != nil {
 		if path == "/" {
 			fs.listDirectoryHandler(w, r)
@@ -53,22 +70,47 @@ func (fs *FilerServer) GetOrHeadHandler(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
-	if len(entry.Chunks) == 0 {
+	// the transformed images have their own etags
+	if entry.Size() > 0 && images.IsTransformable(entry.Name(), entry.Mime) {
+		transform, err := images.ParseTransform(r.URL.Query())
+		if err != nil {
+			writeJsonError(w, r, http.StatusBadRequest, err)
+			return
+		}
+		if transform != nil {
+			fs.handleImage(w, r, entry, transform)
+			return
+		}
+	}
+
+	if checkReadPreconditions(w, r, entry) {
+		return
+	}
+
+	if entry.Size() == 0 {
 		glog.V(1).Infof("no file chunks for %s, attr=%+v", path, entry.Attr)
 		stats.FilerRequestCounter.WithLabelValues("read.nocontent").Inc()
 		w.WriteHeader(http.StatusNoContent)
 		return
 	}
 
+	if mediaType := r.URL.Query().Get("media"); mediaType != "" && isGetMethod {
+		fs.handleMedia(w, r, entry, mediaType)
+		return
+	}
+
 	w.Header().Set("Accept-Ranges", "bytes")
+	w.Header().Set("Last-Modified", entry.Attr.Mtime.UTC().Format(http.TimeFormat))
+	for name, value := range entry.Extended {
+		w.Header().Set(ExtendedHeaderPrefix+name, base64.StdEncoding.EncodeToString(value))
+	}
 	if r.Method == "HEAD" {
-		w.Header().Set("Content-Length", strconv.FormatInt(int64(filer2.TotalSize(entry.Chunks)), 10))
-		w.Header().Set("Last-Modified", entry.Attr.Mtime.Format(http.TimeFormat))
+		w.Header().Set("Content-Length", strconv.FormatInt(int64(entry.Size()), 10))
 		setEtag(w, filer2.ETag(entry.Chunks))
 		return
 	}
 
-	if len(entry.Chunks) == 1 {
+	if isSingleWholeChunk(entry) {
 		fs.handleSingleChunk(w, r, entry)
 		return
 	}
@@ -77,11 +119,22 @@ func (fs *FilerServer) GetOrHeadHandler(w http.ResponseWriter, r *http.Request,
 
 }
 
+// isSingleWholeChunk tells the file content is the needle of its only chunk, as stored in the volume.
+// The chunks cut by truncating have no ETag, and are read by range.
+func isSingleWholeChunk(entry *filer2.Entry) bool {
+	if len(entry.Chunks) != 1 {
+		return false
+	}
+	chunk := entry.Chunks[0]
+	return chunk.Compression == "" && len(chunk.CipherKey) == 0 &&
+		chunk.Offset == 0 && chunk.Size == entry.Size() && chunk.ETag != ""
+}
+
 func (fs *FilerServer) handleSingleChunk(w http.ResponseWriter, r *http.Request, entry *filer2.Entry) {
 
 	fileId := entry.Chunks[0].GetFileIdString()
 
-	urlString, err := fs.filer.MasterClient.LookupFileId(fileId)
+	urlStrings, err := fs.filer.MasterClient.LookupFileIdUrls(fileId)
 	if err != nil {
 		glog.V(1).Infof("operation LookupFileId %s failed, err: %v", fileId, err)
 		w.WriteHeader(http.StatusNotFound)
@@ -90,35 +143,60 @@ func (fs *FilerServer) handleSingleChunk(w http.ResponseWriter, r *http.Request,
 
 	if fs.option.RedirectOnRead {
 		stats.FilerRequestCounter.WithLabelValues("redirect").Inc()
-		http.Redirect(w, r, urlString, http.StatusFound)
+		http.Redirect(w, r, urlStrings[0], http.StatusFound)
 		return
 	}
 
-	u, _ := url.Parse(urlString)
-	q := u.Query()
-	for key, values := range r.URL.Query() {
-		for _, value := range values {
-			q.Add(key, value)
-		}
+	// the preconditions are already evaluated on the entry, whose mtime may be newer than the needle
+	header := make(http.Header)
+	for k, v := range r.Header {
+		header[k] = v
 	}
-	u.RawQuery = q.Encode()
-	request := &http.Request{
-		Method:        r.Method,
-		URL:           u,
-		Proto:         r.Proto,
-		ProtoMajor:    r.ProtoMajor,
-		ProtoMinor:    r.ProtoMinor,
-		Header:        r.Header,
-		Body:          r.Body,
-		Host:          r.Host,
-		ContentLength: r.ContentLength,
-	}
-	glog.V(3).Infoln("retrieving from", u)
-	resp, do_err := util.Do(request)
-	if do_err != nil {
-		glog.V(0).Infoln("failing to connect to volume server", do_err.Error())
-		writeJsonError(w, r, http.StatusInternalServerError, do_err)
-		return
+	for _, name := range []string{"If-Match", "If-None-Match", "If-Modified-Since", "If-Unmodified-Since"} {
+		header.Del(name)
+	}
+
+	// fall back to the next location if the volume server is down, or fails to read the needle
+	var resp *http.Response
+	for i, urlString := range urlStrings {
+		u, _ := url.Parse(urlString)
+		q := u.Query()
+		for key, values := range r.URL.Query() {
+			for _, value := range values {
+				q.Add(key, value)
+			}
+		}
+		u.RawQuery = q.Encode()
+		request := &http.Request{
+			Method:        r.Method,
+			URL:           u,
+			Proto:         r.Proto,
+			ProtoMajor:    r.ProtoMajor,
+			ProtoMinor:    r.ProtoMinor,
+			Header:        header,
+			Body:          r.Body,
+			Host:          r.Host,
+			ContentLength: r.ContentLength,
+		}
+		glog.V(3).Infoln("retrieving from", u)
+		var doErr error
+		resp, doErr = util.Do(request)
+		isLast := i == len(urlStrings)-1
+		if doErr != nil {
+			glog.V(0).Infoln("failing to connect to volume server", doErr.Error())
+			if isLast {
+				writeJsonError(w, r, http.StatusInternalServerError, doErr)
+				return
+			}
+			continue
+		}
+		if !isLast && (resp.StatusCode == http.StatusNotFound || resp.StatusCode >= http.StatusInternalServerError) {
+			glog.V(0).Infof("read %s from %s: %s", fileId, u.Host, resp.Status)
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+			continue
+		}
+		break
 	}
 	defer func() {
 		io.Copy(ioutil.Discard, resp.Body)
@@ -147,7 +225,7 @@ func (fs *FilerServer) handleMultipleChunks(w http.ResponseWriter, r *http.Reque
 	}
 	setEtag(w, filer2.ETag(entry.Chunks))
 
-	totalSize := int64(filer2.TotalSize(entry.Chunks))
+	totalSize := int64(entry.Size())
 
 	rangeReq := r.Header.Get("Range")
 
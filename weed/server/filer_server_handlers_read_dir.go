@@ -1,8 +1,8 @@
 package weed_server
 
 import (
-	"context"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 
@@ -14,6 +14,8 @@ import (
 
 // listDirectoryHandler lists directories and folers under a directory
 // files are sorted by name and paginated via "lastFileName" and "limit".
+// "sortBy=mtime|size|name" and "desc=true" change the order, and for the json output,
+// "fields=attributes,chunks,extended" selects the fields besides the names.
 // sub directories are listed on the first page, when "lastFileName"
 // is empty.
 func (fs *FilerServer) listDirectoryHandler(w http.ResponseWriter, r *http.Request) {
@@ -32,7 +34,29 @@ func (fs *FilerServer) listDirectoryHandler(w http.ResponseWriter, r *http.Reque
 
 	lastFileName := r.FormValue("lastFileName")
 
-	entries, err := fs.filer.ListDirectoryEntries(context.Background(), filer2.FullPath(path), lastFileName, false, limit)
+	ctx, err := readContext(r)
+	if err != nil {
+		writeJsonError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	desc, _ := strconv.ParseBool(r.FormValue("desc"))
+	order, err := filer2.ParseListingOrder(r.FormValue("sortBy"), desc)
+	if err != nil {
+		writeJsonError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	var fieldNames []string
+	if r.FormValue("fields") != "" {
+		fieldNames = strings.Split(r.FormValue("fields"), ",")
+	}
+	fields, err := filer2.ParseEntryFields(fieldNames)
+	if err != nil {
+		writeJsonError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	entries, err := fs.filer.ListDirectorySortedEntries(ctx, filer2.FullPath(path), lastFileName, false, limit, order, fs.option.DirListingLimit)
 
 	if err != nil {
 		glog.V(0).Infof("listDirectory %s %s %d: %s", path, lastFileName, limit, err)
@@ -52,6 +76,15 @@ func (fs *FilerServer) listDirectoryHandler(w http.ResponseWriter, r *http.Reque
 	glog.V(4).Infof("listDirectory %s, last file %s, limit %d: %d items", path, lastFileName, limit, len(entries))
 
 	if r.Header.Get("Accept") == "application/json" {
+		listed := make([]listedEntry, len(entries))
+		for i, entry := range entries {
+			listed[i] = listedEntry{
+				Entry:       fields.Project(entry),
+				Name:        entry.Name(),
+				Size:        entry.Size(),
+				IsDirectory: entry.IsDirectory(),
+			}
+		}
 		writeJsonQuiet(w, r, http.StatusOK, struct {
 			Path                  string
 			Entries               interface{}
@@ -60,7 +93,7 @@ func (fs *FilerServer) listDirectoryHandler(w http.ResponseWriter, r *http.Reque
 			ShouldDisplayLoadMore bool
 		}{
 			path,
-			entries,
+			listed,
 			limit,
 			lastFileName,
 			shouldDisplayLoadMore,
@@ -73,6 +106,9 @@ func (fs *FilerServer) listDirectoryHandler(w http.ResponseWriter, r *http.Reque
 			Limit                 int
 			LastFileName          string
 			ShouldDisplayLoadMore bool
+			SortQuery             string
+			SortBy                string
+			Desc                  bool
 		}{
 			path,
 			ui.ToBreadcrumb(path),
@@ -80,6 +116,29 @@ func (fs *FilerServer) listDirectoryHandler(w http.ResponseWriter, r *http.Reque
 			limit,
 			lastFileName,
 			shouldDisplayLoadMore,
+			sortQuery(order),
+			order.SortBy,
+			order.Desc,
 		})
 	}
 }
+
+// listedEntry has the name, the size and whether it is a directory,
+// so the json listing can leave out the attributes and chunks
+type listedEntry struct {
+	*filer2.Entry
+	Name        string
+	Size        uint64
+	IsDirectory bool
+}
+
+// sortQuery keeps the order when loading more entries
+func sortQuery(order filer2.ListingOrder) string {
+	if order.IsNameOrder() {
+		return ""
+	}
+	q := url.Values{}
+	q.Set("sortBy", order.SortBy)
+	q.Set("desc", strconv.FormatBool(order.Desc))
+	return "&" + q.Encode()
+}
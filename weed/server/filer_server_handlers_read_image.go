@@ -0,0 +1,191 @@
+package weed_server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/images"
+	"github.com/chrislusf/seaweedfs/weed/operation"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+	"github.com/chrislusf/seaweedfs/weed/stats"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+)
+
+// the originals larger than this are not transformed
+const maxImageTransformSize = 64 * 1024 * 1024
+
+// imageTransforms lets the concurrent requests of the same transformed image wait for one transformation
+type imageTransforms struct {
+	sync.Mutex
+	running map[string]*imageTransform
+}
+
+type imageTransform struct {
+	done     sync.WaitGroup
+	data     []byte
+	mimeType string
+	err      error
+}
+
+// handleImage serves the image transformed as asked by the query, e.g. /photos/a.jpg?width=200&height=200&fit=crop&format=webp
+// The results are cached in the image cache collection, so the repeated requests only read the cached images.
+func (fs *FilerServer) handleImage(w http.ResponseWriter, r *http.Request, entry *filer2.Entry, transform *images.Transform) {
+
+	key := filer2.ImageCacheKey(entry, transform.Key())
+	etag := "\"" + key + "\""
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" && etagListMatches(ifNoneMatch, etag, false) {
+		w.Header().Set("ETag", etag)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	data, mimeType, err := fs.transformImage(entry, transform, key)
+	if err == images.ErrImageTooLarge {
+		writeJsonError(w, r, http.StatusRequestEntityTooLarge, err)
+		return
+	}
+	if err != nil {
+		glog.V(1).Infof("transform %s %s: %v", entry.FullPath, transform.Key(), err)
+		writeJsonError(w, r, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", mimeType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("Last-Modified", entry.Attr.Mtime.UTC().Format(http.TimeFormat))
+	w.Header().Set("ETag", etag)
+	if r.Method == "HEAD" {
+		return
+	}
+	w.Write(data)
+}
+
+func (fs *FilerServer) transformImage(entry *filer2.Entry, transform *images.Transform, key string) (data []byte, mimeType string, err error) {
+
+	fs.imageTransforms.Lock()
+	if fs.imageTransforms.running == nil {
+		fs.imageTransforms.running = make(map[string]*imageTransform)
+	}
+	if t, found := fs.imageTransforms.running[key]; found {
+		fs.imageTransforms.Unlock()
+		t.done.Wait()
+		return t.data, t.mimeType, t.err
+	}
+	t := &imageTransform{}
+	t.done.Add(1)
+	fs.imageTransforms.running[key] = t
+	fs.imageTransforms.Unlock()
+
+	defer func() {
+		t.data, t.mimeType, t.err = data, mimeType, err
+		t.done.Done()
+		fs.imageTransforms.Lock()
+		delete(fs.imageTransforms.running, key)
+		fs.imageTransforms.Unlock()
+	}()
+
+	ctx := context.Background()
+	caching := fs.option.ImageCacheCollection != ""
+
+	if caching {
+		if data, mimeType = fs.readImageCache(ctx, key); data != nil {
+			stats.FilerRequestCounter.WithLabelValues("read.image.cached").Inc()
+			return data, mimeType, nil
+		}
+	}
+
+	stats.FilerRequestCounter.WithLabelValues("read.image").Inc()
+	if entry.Size() > maxImageTransformSize {
+		return nil, "", images.ErrImageTooLarge
+	}
+	var original bytes.Buffer
+	if err = fs.writeContent(&original, entry, 0, int(entry.Size())); err != nil {
+		return nil, "", err
+	}
+	if data, mimeType, err = transform.Apply(original.Bytes()); err != nil {
+		return nil, "", err
+	}
+
+	if caching {
+		go fs.saveImageCache(key, mimeType, data)
+	}
+	return data, mimeType, nil
+}
+
+// readImageCache returns nil if the image is not cached, or the cached chunk is gone
+func (fs *FilerServer) readImageCache(ctx context.Context, key string) (data []byte, mimeType string) {
+	cached, err := fs.filer.FindImageCache(ctx, key)
+	if err != nil {
+		glog.V(0).Infof("image cache: %v", err)
+		return nil, ""
+	}
+	if cached == nil {
+		return nil, ""
+	}
+	var buf bytes.Buffer
+	if err = fs.writeContent(&buf, cached, 0, int(cached.Size())); err != nil {
+		glog.V(1).Infof("read image cache %s: %v", key, err)
+		fs.filer.DeleteImageCache(ctx, cached)
+		return nil, ""
+	}
+	return buf.Bytes(), cached.Mime
+}
+
+func (fs *FilerServer) saveImageCache(key string, mimeType string, data []byte) {
+
+	ttlSec, err := fs.imageCacheTtlSec()
+	if err != nil {
+		glog.Errorf("image cache: %v", err)
+		return
+	}
+
+	assignResult, err := operation.Assign(fs.filer.GetMaster(), fs.grpcDialOption, &operation.VolumeAssignRequest{
+		Count:       1,
+		Replication: fs.option.DefaultReplication,
+		Collection:  fs.option.ImageCacheCollection,
+		Ttl:         fs.option.ImageCacheTtl,
+		DataCenter:  fs.option.DataCenter,
+	})
+	if err != nil {
+		glog.V(0).Infof("assign image cache %s: %v", key, err)
+		return
+	}
+	uploadUrl := "http://" + assignResult.Url + "/" + assignResult.Fid
+	uploadResult, err := operation.Upload(uploadUrl, key, bytes.NewReader(data), false, mimeType, nil, assignResult.Auth)
+	if err == nil && uploadResult.Error != "" {
+		err = fmt.Errorf("%s", uploadResult.Error)
+	}
+	if err != nil {
+		glog.V(0).Infof("upload image cache %s: %v", key, err)
+		return
+	}
+
+	chunk := &filer_pb.FileChunk{
+		FileId: assignResult.Fid,
+		Size:   uint64(len(data)),
+		Mtime:  time.Now().UnixNano(),
+		ETag:   uploadResult.ETag,
+	}
+	if err = fs.filer.SaveImageCache(context.Background(), key, mimeType, ttlSec, chunk); err != nil {
+		glog.V(0).Infof("%v", err)
+		fs.filer.DeleteFileByFileId(assignResult.Fid)
+	}
+}
+
+func (fs *FilerServer) imageCacheTtlSec() (int32, error) {
+	if fs.option.ImageCacheTtl == "" {
+		return 0, nil
+	}
+	ttl, err := needle.ReadTTL(fs.option.ImageCacheTtl)
+	if err != nil {
+		return 0, fmt.Errorf("image cache ttl %s: %v", fs.option.ImageCacheTtl, err)
+	}
+	return int32(ttl.Minutes()) * 60, nil
+}
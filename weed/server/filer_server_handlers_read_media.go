@@ -0,0 +1,68 @@
+package weed_server
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/media"
+	"github.com/chrislusf/seaweedfs/weed/stats"
+)
+
+// handleMedia serves the file with the media handler selected by the "media" query parameter
+func (fs *FilerServer) handleMedia(w http.ResponseWriter, r *http.Request, entry *filer2.Entry, mediaType string) {
+
+	handler := media.GetMediaHandler(mediaType)
+	if handler == nil {
+		writeJsonError(w, r, http.StatusBadRequest, fmt.Errorf("unknown media type %s", mediaType))
+		return
+	}
+
+	stats.FilerRequestCounter.WithLabelValues("read.media." + mediaType).Inc()
+
+	file := &media.File{
+		Name:     entry.Name(),
+		Key:      string(entry.FullPath) + "@" + filer2.ETag(entry.Chunks),
+		Size:     int64(entry.Size()),
+		ReaderAt: &entryReaderAt{fs: fs, entry: entry},
+	}
+
+	if err := handler.Serve(w, r, file); err != nil {
+		glog.V(1).Infof("serve %s as %s: %v", entry.FullPath, mediaType, err)
+		if err == media.ErrNotFragmented {
+			writeJsonError(w, r, http.StatusUnsupportedMediaType, err)
+			return
+		}
+		writeJsonError(w, r, http.StatusBadRequest, err)
+	}
+
+}
+
+// entryReaderAt reads the file content by byte ranges from the volume servers
+type entryReaderAt struct {
+	fs    *FilerServer
+	entry *filer2.Entry
+}
+
+func (r *entryReaderAt) ReadAt(p []byte, off int64) (n int, err error) {
+	totalSize := int64(r.entry.Size())
+	if off >= totalSize {
+		return 0, io.EOF
+	}
+	size := int64(len(p))
+	if off+size > totalSize {
+		size = totalSize - off
+	}
+	buf := bytes.NewBuffer(p[:0])
+	if err = r.fs.writeContent(buf, r.entry, off, int(size)); err != nil {
+		return 0, err
+	}
+	n = copy(p, buf.Bytes())
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
@@ -2,9 +2,11 @@ package weed_server
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"io/ioutil"
 	"mime"
@@ -22,6 +24,7 @@ import (
 	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
 	"github.com/chrislusf/seaweedfs/weed/security"
 	"github.com/chrislusf/seaweedfs/weed/stats"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
 	"github.com/chrislusf/seaweedfs/weed/util"
 )
 
@@ -36,6 +39,8 @@ type FilerPostResult struct {
 	Error string `json:"error,omitempty"`
 	Fid   string `json:"fid,omitempty"`
 	Url   string `json:"url,omitempty"`
+	// no new data is stored, since all chunks are shared with the existing files of the same content
+	Deduplicated bool `json:"deduplicated,omitempty"`
 }
 
 func (fs *FilerServer) assignNewFileInfo(w http.ResponseWriter, r *http.Request, replication, collection string, dataCenter string) (fileId, urlLocation string, auth security.EncodedJwt, err error) {
@@ -45,20 +50,26 @@ func (fs *FilerServer) assignNewFileInfo(w http.ResponseWriter, r *http.Request,
 	defer func() { stats.FilerRequestHistogram.WithLabelValues("assign").Observe(time.Since(start).Seconds()) }()
 
 	ar := &operation.VolumeAssignRequest{
-		Count:       1,
-		Replication: replication,
-		Collection:  collection,
-		Ttl:         r.URL.Query().Get("ttl"),
-		DataCenter:  dataCenter,
+		Count:         1,
+		Replication:   replication,
+		Collection:    collection,
+		Ttl:           r.URL.Query().Get("ttl"),
+		DataCenter:    dataCenter,
+		LocalityGroup: r.URL.Query().Get("localityGroup"),
+		LabelSelector: r.URL.Query().Get("labelSelector"),
+		Session:       r.URL.Query().Get("session"),
 	}
 	var altRequest *operation.VolumeAssignRequest
 	if dataCenter != "" {
 		altRequest = &operation.VolumeAssignRequest{
-			Count:       1,
-			Replication: replication,
-			Collection:  collection,
-			Ttl:         r.URL.Query().Get("ttl"),
-			DataCenter:  "",
+			Count:         1,
+			Replication:   replication,
+			Collection:    collection,
+			Ttl:           r.URL.Query().Get("ttl"),
+			DataCenter:    "",
+			LocalityGroup: r.URL.Query().Get("localityGroup"),
+			LabelSelector: r.URL.Query().Get("labelSelector"),
+			Session:       r.URL.Query().Get("session"),
 		}
 	}
 
@@ -93,9 +104,65 @@ func (fs *FilerServer) PostHandler(w http.ResponseWriter, r *http.Request) {
 		dataCenter = fs.option.DataCenter
 	}
 
+	if err := setRetention(r, &filer2.Attr{}); err != nil {
+		writeJsonError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if err := setExpiry(r, &filer2.Attr{}); err != nil {
+		writeJsonError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if err := setExtended(r, &filer2.Entry{}); err != nil {
+		writeJsonError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	id, err := fs.httpIdentity(r)
+	if err != nil {
+		writeJsonError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if _, err = fs.filer.CheckWrite(ctx, id, filer2.FullPath(r.URL.Path)); err != nil {
+		writeJsonError(w, r, filerErrorStatus(err), err)
+		return
+	}
+
+	// fail early before uploading, and check again before saving the entry.
+	// The uploads into the directory are named later by the uploaded file names.
+	if hasWritePreconditions(r) && !strings.HasSuffix(r.URL.Path, "/") {
+		if err = fs.checkWritePreconditions(ctx, r, filer2.FullPath(r.URL.Path)); err != nil {
+			writeJsonError(w, r, filerErrorStatus(err), err)
+			return
+		}
+	}
+
+	switch query.Get("op") {
+	case "append":
+		fs.appendContent(ctx, w, r, replication, collection, dataCenter)
+		return
+	case "copy":
+		fs.copyContent(ctx, w, r, replication, collection, dataCenter)
+		return
+	case "move":
+		fs.moveEntryHandler(ctx, w, r)
+		return
+	case "ingest":
+		fs.ingestRecords(ctx, w, r, replication, collection, dataCenter)
+		return
+	}
+
+	if query.Get("offset") != "" {
+		fs.patchContent(ctx, w, r, replication, collection, dataCenter)
+		return
+	}
+
 	if autoChunked := fs.autoChunk(ctx, w, r, replication, collection, dataCenter); autoChunked {
 		return
 	}
+	if r.Header.Get(EncryptHeader) == "true" {
+		writeJsonError(w, r, http.StatusBadRequest, fmt.Errorf("the encrypted upload needs the Content-Length, and the chunking by -maxMB"))
+		return
+	}
 
 	fileId, urlLocation, auth, err := fs.assignNewFileInfo(w, r, replication, collection, dataCenter)
 
@@ -116,13 +183,28 @@ func (fs *FilerServer) PostHandler(w http.ResponseWriter, r *http.Request) {
 		q.Set("cm", "true")
 		u.RawQuery = q.Encode()
 	}
+	u.RawQuery = withNeedleTtl(u.RawQuery, r)
 	glog.V(4).Infoln("post to", u)
 
+	var dedupHash hash.Hash
+	if !cm && fs.isDedupUpload(ctx, r) {
+		dedupHash = filer2.NewDedupHash(collection, replication, query.Get("ttl"), r.Header.Get("Content-Type"), r.Header.Get("Content-Encoding"))
+		r.Body = ioutil.NopCloser(io.TeeReader(r.Body, dedupHash))
+	}
+
 	ret, err := fs.uploadToVolumeServer(r, u, auth, w, fileId)
 	if err != nil {
 		return
 	}
 
+	deduplicated := false
+	if dedupHash != nil {
+		if dedupFileId := fs.dedupUploadedFile(ctx, filer2.DedupHashString(dedupHash), fileId, ret); dedupFileId != fileId {
+			fileId, urlLocation, deduplicated = dedupFileId, "", true
+		}
+		ctx = filer2.WithDedupUpload(ctx)
+	}
+
 	if err = fs.updateFilerStore(ctx, r, w, replication, collection, ret, fileId); err != nil {
 		return
 	}
@@ -134,6 +216,8 @@ func (fs *FilerServer) PostHandler(w http.ResponseWriter, r *http.Request) {
 		Error: ret.Error,
 		Fid:   fileId,
 		Url:   urlLocation,
+
+		Deduplicated: deduplicated,
 	}
 	setEtag(w, ret.ETag)
 	writeJsonQuiet(w, r, http.StatusCreated, reply)
@@ -182,11 +266,20 @@ func (fs *FilerServer) updateFilerStore(ctx context.Context, r *http.Request, w
 	if ext := filenamePath.Ext(path); ext != "" {
 		entry.Attr.Mime = mime.TypeByExtension(ext)
 	}
+	setRetention(r, &entry.Attr)
+	setExpiry(r, &entry.Attr)
+	setExtended(r, entry)
 	// glog.V(4).Infof("saving %s => %+v", path, entry)
-	if dbErr := fs.filer.CreateEntry(ctx, entry); dbErr != nil {
+	dbErr := fs.checkUploadedEntry(ctx, r, entry)
+	if dbErr == nil {
+		dbErr = fs.conditionalWrite(ctx, r, entry.FullPath, func() error {
+			return fs.filer.CreateEntry(ctx, entry)
+		})
+	}
+	if dbErr != nil {
 		fs.filer.DeleteChunks(entry.FullPath, entry.Chunks)
 		glog.V(0).Infof("failing to write %s to filer server : %v", path, dbErr)
-		writeJsonError(w, r, http.StatusInternalServerError, dbErr)
+		writeJsonError(w, r, filerErrorStatus(dbErr), dbErr)
 		err = dbErr
 		return
 	}
@@ -269,16 +362,131 @@ func (fs *FilerServer) uploadToVolumeServer(r *http.Request, u *url.URL, auth se
 
 // curl -X DELETE http://localhost:8888/path/to
 // curl -X DELETE http://localhost:8888/path/to?recursive=true
+// curl -X DELETE -H "Seaweed-Bypass-Governance-Retention: true" http://localhost:8888/path/to
 func (fs *FilerServer) DeleteHandler(w http.ResponseWriter, r *http.Request) {
 
 	isRecursive := r.FormValue("recursive") == "true"
 
-	err := fs.filer.DeleteEntryMetaAndData(context.Background(), filer2.FullPath(r.URL.Path), isRecursive, true)
+	ctx := context.Background()
+	if r.Header.Get(BypassGovernanceRetentionHeader) == "true" {
+		ctx = filer2.WithGovernanceBypass(ctx)
+	}
+
+	id, err := fs.httpIdentity(r)
+	if err != nil {
+		writeJsonError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if err = fs.filer.CheckModify(ctx, id, filer2.FullPath(r.URL.Path)); err == nil {
+		err = fs.conditionalWrite(ctx, r, filer2.FullPath(r.URL.Path), func() error {
+			return fs.filer.DeleteEntryMetaAndData(ctx, filer2.FullPath(r.URL.Path), isRecursive, true)
+		})
+	}
 	if err != nil {
 		glog.V(1).Infoln("deleting", r.URL.Path, ":", err.Error())
-		writeJsonError(w, r, http.StatusInternalServerError, err)
+		writeJsonError(w, r, filerErrorStatus(err), err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// the headers to lock the written file, same as S3 Object Lock
+const (
+	RetentionModeHeader             = "Seaweed-Retention-Mode" // GOVERNANCE or COMPLIANCE
+	RetainUntilHeader               = "Seaweed-Retain-Until"   // RFC3339 time
+	LegalHoldHeader                 = "Seaweed-Legal-Hold"     // ON or OFF
+	BypassGovernanceRetentionHeader = "Seaweed-Bypass-Governance-Retention"
+)
+
+const (
+	// encrypt the chunks of the upload by the filer key, even if not configured for the collection
+	EncryptHeader = "Seaweed-Encrypt"
+	// the extended attributes as "Seaweed-Extended-<name>: <base64 value>", saved by the uploads and returned by the reads
+	ExtendedHeaderPrefix = "Seaweed-Extended-"
+)
+
+// setRetention sets the retention and legal hold from the request headers
+func setRetention(r *http.Request, attr *filer2.Attr) error {
+	mode, retainUntil := r.Header.Get(RetentionModeHeader), r.Header.Get(RetainUntilHeader)
+	if mode != "" || retainUntil != "" {
+		if mode == "" || retainUntil == "" || !filer2.ValidRetentionMode(mode) {
+			return fmt.Errorf("%s should be %s or %s, together with %s", RetentionModeHeader,
+				filer2.RetentionModeGovernance, filer2.RetentionModeCompliance, RetainUntilHeader)
+		}
+		t, err := time.Parse(time.RFC3339, retainUntil)
+		if err != nil {
+			return fmt.Errorf("%s %s: %v", RetainUntilHeader, retainUntil, err)
+		}
+		attr.RetentionMode, attr.RetainUntil = mode, t
+	}
+	switch legalHold := r.Header.Get(LegalHoldHeader); legalHold {
+	case "ON":
+		attr.LegalHold = true
+	case "", "OFF":
+	default:
+		return fmt.Errorf("%s %s should be ON or OFF", LegalHoldHeader, legalHold)
+	}
+	return nil
+}
+
+// setExpiry sets the entry to be deleted after the "expire" query parameter, in the same format as the ttl, e.g. 3d.
+// Unlike the ttl, the chunks are stored as usual, and deleted together with the entry.
+func setExpiry(r *http.Request, attr *filer2.Attr) error {
+	expire := r.URL.Query().Get("expire")
+	if expire == "" {
+		return nil
+	}
+	ttl, err := needle.ReadTTL(expire)
+	if err != nil || ttl.Minutes() == 0 {
+		return fmt.Errorf("expire=%s should be like 30m, 4h, 7d, 2w, 3M, or 1y", expire)
+	}
+	attr.ExpireAt = time.Now().Add(time.Duration(ttl.Minutes()) * time.Minute)
+	return nil
+}
+
+// setExtended sets the extended attributes from the request headers, named in lower case
+func setExtended(r *http.Request, entry *filer2.Entry) error {
+	for name, values := range r.Header {
+		if !strings.HasPrefix(name, ExtendedHeaderPrefix) || len(values) == 0 {
+			continue
+		}
+		value, err := base64.StdEncoding.DecodeString(values[0])
+		if err != nil {
+			return fmt.Errorf("%s should be in base64: %v", name, err)
+		}
+		if entry.Extended == nil {
+			entry.Extended = make(map[string][]byte)
+		}
+		entry.Extended[strings.ToLower(strings.TrimPrefix(name, ExtendedHeaderPrefix))] = value
+	}
+	return nil
+}
+
+// withNeedleTtl adds the ttl of the write to the upload, to be kept by the needle,
+// for the collections writing all ttls to the volumes without a ttl
+func withNeedleTtl(rawQuery string, r *http.Request) string {
+	ttl := r.URL.Query().Get("ttl")
+	if ttl == "" {
+		return rawQuery
+	}
+	q, _ := url.ParseQuery(rawQuery)
+	q.Set("ttl", ttl)
+	return q.Encode()
+}
+
+func filerErrorStatus(err error) int {
+	if filer2.IsEntryLocked(err) || filer2.IsPermissionDenied(err) {
+		return http.StatusForbidden
+	}
+	if filer2.IsLeased(err) {
+		return http.StatusLocked
+	}
+	if err == errPreconditionFailed {
+		return http.StatusPreconditionFailed
+	}
+	if err == filer2.ErrChunkKeyUnknown {
+		return http.StatusNotImplemented
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,145 @@
+package weed_server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	filenamePath "path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+	"github.com/chrislusf/seaweedfs/weed/stats"
+	"github.com/chrislusf/seaweedfs/weed/util"
+)
+
+type FilerAppendResult struct {
+	Name   string `json:"name"`
+	Offset int64  `json:"offset"`
+	Size   int64  `json:"size"`
+}
+
+// appendContent appends the request body to the end of the file as new chunks, creating the file if missing.
+// The reply has the file offset the body is appended at.
+// curl -X POST --data-binary @log.txt "http://localhost:8888/path/to/log?op=append"
+func (fs *FilerServer) appendContent(ctx context.Context, w http.ResponseWriter, r *http.Request,
+	replication string, collection string, dataCenter string) {
+
+	stats.FilerRequestCounter.WithLabelValues("append").Inc()
+	start := time.Now()
+	defer func() {
+		stats.FilerRequestHistogram.WithLabelValues("append").Observe(time.Since(start).Seconds())
+	}()
+
+	if isMultipartUpload(r) {
+		writeJsonError(w, r, http.StatusBadRequest, fmt.Errorf("append to %s with the raw body instead of multipart", r.URL.Path))
+		return
+	}
+	if strings.HasSuffix(r.URL.Path, "/") {
+		writeJsonError(w, r, http.StatusBadRequest, fmt.Errorf("can not append to folder %s", r.URL.Path))
+		return
+	}
+
+	path := filer2.FullPath(r.URL.Path)
+	template := &filer2.Entry{
+		FullPath: path,
+		Attr: filer2.Attr{
+			Mtime:       time.Now(),
+			Crtime:      time.Now(),
+			Mode:        0660,
+			Uid:         OS_UID,
+			Gid:         OS_GID,
+			Replication: replication,
+			Collection:  collection,
+			Mime:        r.Header.Get("Content-Type"),
+			TtlSec:      int32(util.ParseInt(r.URL.Query().Get("ttl"), 0)),
+		},
+	}
+	entry, err := fs.filer.FindEntry(ctx, path)
+	if err == nil {
+		if entry.IsDirectory() {
+			writeJsonError(w, r, http.StatusBadRequest, fmt.Errorf("%s is a directory", path))
+			return
+		}
+		// the new chunks are stored the same way as the file
+		if entry.Collection != "" {
+			collection = entry.Collection
+		}
+		if entry.Replication != "" {
+			replication = entry.Replication
+		}
+		template.Mime = entry.Mime
+	} else if err != filer2.ErrNotFound {
+		writeJsonError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	if template.Mime == "" {
+		template.Mime = mime.TypeByExtension(filenamePath.Ext(string(path)))
+	}
+	if err = setRetention(r, &template.Attr); err != nil {
+		writeJsonError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if err = fs.checkUploadedEntry(ctx, r, template); err != nil {
+		writeJsonError(w, r, filerErrorStatus(err), err)
+		return
+	}
+
+	chunkSize := int64(fs.option.MaxMB) * 1024 * 1024
+	if chunkSize <= 0 {
+		chunkSize = 4 * 1024 * 1024
+	}
+	compression := fs.filer.ChunkCompression(collection, template.Mime)
+	encrypted := fs.isEncryptedUpload(r, collection)
+
+	// the chunk offsets are relative to the appended data, until the file size is known when saving
+	var chunks []*filer_pb.FileChunk
+	buf := make([]byte, chunkSize)
+	size := int64(0)
+	for {
+		n, readErr := io.ReadFull(r.Body, buf)
+		if n > 0 {
+			chunkName := path.Name() + "_append_" + strconv.FormatInt(start.UnixNano(), 10) + "_" + strconv.Itoa(len(chunks)+1)
+			chunk, saveErr := fs.saveChunk(ctx, w, r, buf[:n], chunkName, replication, collection, dataCenter, compression, encrypted)
+			if saveErr != nil {
+				fs.filer.DeleteChunks(path, chunks)
+				writeJsonError(w, r, http.StatusInternalServerError, saveErr)
+				return
+			}
+			chunk.Offset = size
+			chunks = append(chunks, chunk)
+			size += int64(n)
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			fs.filer.DeleteChunks(path, chunks)
+			writeJsonError(w, r, http.StatusBadRequest, readErr)
+			return
+		}
+	}
+
+	var offset int64
+	err = fs.conditionalWrite(ctx, r, path, func() (appendErr error) {
+		offset, appendErr = fs.filer.AppendEntry(ctx, template, chunks)
+		return
+	})
+	if err != nil {
+		glog.V(0).Infof("append %s: %v", path, err)
+		fs.filer.DeleteChunks(path, chunks)
+		writeJsonError(w, r, filerErrorStatus(err), err)
+		return
+	}
+
+	writeJsonQuiet(w, r, http.StatusOK, &FilerAppendResult{
+		Name:   path.Name(),
+		Offset: offset,
+		Size:   size,
+	})
+}
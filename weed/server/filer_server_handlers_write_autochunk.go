@@ -3,8 +3,10 @@ package weed_server
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"io/ioutil"
+	"mime"
 	"net/http"
 	"path"
 	"strconv"
@@ -17,12 +19,22 @@ import (
 	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
 	"github.com/chrislusf/seaweedfs/weed/security"
 	"github.com/chrislusf/seaweedfs/weed/stats"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
 	"github.com/chrislusf/seaweedfs/weed/util"
 )
 
+// the chunk size of the files over the upload size limit, when the auto chunking is not enabled
+const oversizedFileChunkMB = 32
+
 func (fs *FilerServer) autoChunk(ctx context.Context, w http.ResponseWriter, r *http.Request,
 	replication string, collection string, dataCenter string) bool {
-	if r.Method != "POST" {
+
+	// the compressed or encrypted uploads are always chunked, since the chunks are transformed in memory
+	compression := fs.filer.ChunkCompression(collection, uploadMimeType(r))
+	encrypted := fs.isEncryptedUpload(r, collection)
+	transformed := compression != "" || encrypted
+
+	if r.Method != "POST" && !transformed {
 		glog.V(4).Infoln("AutoChunking not supported for method", r.Method)
 		return false
 	}
@@ -35,18 +47,17 @@ func (fs *FilerServer) autoChunk(ctx context.Context, w http.ResponseWriter, r *
 	if maxMB <= 0 && fs.option.MaxMB > 0 {
 		maxMB = int32(fs.option.MaxMB)
 	}
-	if maxMB <= 0 {
+	chunkSize := uploadChunkSize(maxMB, r.ContentLength, fs.fileSizeLimit())
+	if chunkSize <= 0 {
 		glog.V(4).Infoln("AutoChunking not enabled")
 		return false
 	}
-	glog.V(4).Infoln("AutoChunking level set to", maxMB, "(MB)")
-
-	chunkSize := 1024 * 1024 * maxMB
+	glog.V(4).Infoln("AutoChunking level set to", chunkSize, "bytes")
 
 	contentLength := int64(0)
 	if contentLengthHeader := r.Header["Content-Length"]; len(contentLengthHeader) == 1 {
 		contentLength, _ = strconv.ParseInt(contentLengthHeader[0], 10, 64)
-		if contentLength <= int64(chunkSize) {
+		if contentLength <= int64(chunkSize) && !transformed {
 			glog.V(4).Infoln("Content-Length of", contentLength, "is less than the chunk size of", chunkSize, "so autoChunking will be skipped.")
 			return false
 		}
@@ -57,17 +68,59 @@ func (fs *FilerServer) autoChunk(ctx context.Context, w http.ResponseWriter, r *
 		return false
 	}
 
-	reply, err := fs.doAutoChunk(ctx, w, r, contentLength, chunkSize, replication, collection, dataCenter)
+	reply, err := fs.doAutoChunk(ctx, w, r, contentLength, chunkSize, replication, collection, dataCenter, compression, encrypted)
 	if err != nil {
-		writeJsonError(w, r, http.StatusInternalServerError, err)
+		writeJsonError(w, r, filerErrorStatus(err), err)
 	} else if reply != nil {
 		writeJsonQuiet(w, r, http.StatusCreated, reply)
 	}
 	return true
 }
 
+// uploadChunkSize is the chunk size by the maxMB, at most the upload size limit of the volume servers.
+// Without the maxMB, only the uploads over the limit are chunked, or 0 is returned.
+func uploadChunkSize(maxMB int32, contentLength int64, fileSizeLimit int64) int32 {
+	if maxMB <= 0 {
+		if contentLength <= fileSizeLimit {
+			return 0
+		}
+		maxMB = oversizedFileChunkMB
+	}
+	chunkSize := int64(maxMB) * 1024 * 1024
+	if chunkSize > fileSizeLimit {
+		chunkSize = fileSizeLimit
+	}
+	return int32(chunkSize)
+}
+
+// fileSizeLimit is the smallest upload size limit advertised by the volume servers, at most the limit of a single needle
+func (fs *FilerServer) fileSizeLimit() int64 {
+	if limit := fs.filer.MasterClient.FileSizeLimit(); limit > 0 && limit < needle.MaxNeedleDataSize {
+		return limit
+	}
+	return needle.MaxNeedleDataSize
+}
+
+// isEncryptedUpload tells whether to encrypt the chunks, by the collection or as asked by the upload
+func (fs *FilerServer) isEncryptedUpload(r *http.Request, collection string) bool {
+	return fs.filer.IsChunkEncrypted(collection) || r.Header.Get(EncryptHeader) == "true"
+}
+
+func isMultipartUpload(r *http.Request) bool {
+	return strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data")
+}
+
+// uploadMimeType is the Content-Type of the upload, or by the file name extension for the multipart uploads
+func uploadMimeType(r *http.Request) string {
+	if isMultipartUpload(r) {
+		return mime.TypeByExtension(path.Ext(r.URL.Path))
+	}
+	return r.Header.Get("Content-Type")
+}
+
 func (fs *FilerServer) doAutoChunk(ctx context.Context, w http.ResponseWriter, r *http.Request,
-	contentLength int64, chunkSize int32, replication string, collection string, dataCenter string) (filerResult *FilerPostResult, replyerr error) {
+	contentLength int64, chunkSize int32, replication string, collection string, dataCenter string,
+	compression string, encrypted bool) (filerResult *FilerPostResult, replyerr error) {
 
 	stats.FilerRequestCounter.WithLabelValues("postAutoChunk").Inc()
 	start := time.Now()
@@ -75,19 +128,31 @@ func (fs *FilerServer) doAutoChunk(ctx context.Context, w http.ResponseWriter, r
 		stats.FilerRequestHistogram.WithLabelValues("postAutoChunk").Observe(time.Since(start).Seconds())
 	}()
 
-	multipartReader, multipartReaderErr := r.MultipartReader()
-	if multipartReaderErr != nil {
-		return nil, multipartReaderErr
-	}
+	var partReader io.Reader = r.Body
+	var fileName string
+	mimeType := uploadMimeType(r)
+	if isMultipartUpload(r) {
+		multipartReader, multipartReaderErr := r.MultipartReader()
+		if multipartReaderErr != nil {
+			return nil, multipartReaderErr
+		}
 
-	part1, part1Err := multipartReader.NextPart()
-	if part1Err != nil {
-		return nil, part1Err
-	}
+		part1, part1Err := multipartReader.NextPart()
+		if part1Err != nil {
+			return nil, part1Err
+		}
 
-	fileName := part1.FileName()
-	if fileName != "" {
-		fileName = path.Base(fileName)
+		partReader = part1
+		fileName = part1.FileName()
+		if fileName != "" {
+			fileName = path.Base(fileName)
+		}
+		if partMimeType := part1.Header.Get("Content-Type"); partMimeType != "" {
+			mimeType = partMimeType
+		}
+	}
+	if strings.HasSuffix(r.URL.Path, "/") && fileName == "" {
+		return nil, fmt.Errorf("can not to write to folder %s without a file name", r.URL.Path)
 	}
 
 	var fileChunks []*filer_pb.FileChunk
@@ -95,10 +160,15 @@ func (fs *FilerServer) doAutoChunk(ctx context.Context, w http.ResponseWriter, r
 	totalBytesRead := int64(0)
 	tmpBufferSize := int32(1024 * 1024)
 	tmpBuffer := bytes.NewBuffer(make([]byte, 0, tmpBufferSize))
-	chunkBuf := make([]byte, chunkSize+tmpBufferSize, chunkSize+tmpBufferSize) // chunk size plus a little overflow
+	chunkBufSize := int64(chunkSize)
+	if contentLength < chunkBufSize {
+		chunkBufSize = contentLength
+	}
+	chunkBuf := make([]byte, chunkBufSize+int64(tmpBufferSize)) // chunk size plus a little overflow
 	chunkBufOffset := int32(0)
 	chunkOffset := int64(0)
 	writtenChunks := 0
+	newChunks := 0
 
 	filerResult = &FilerPostResult{
 		Name: fileName,
@@ -106,7 +176,7 @@ func (fs *FilerServer) doAutoChunk(ctx context.Context, w http.ResponseWriter, r
 
 	for totalBytesRead < contentLength {
 		tmpBuffer.Reset()
-		bytesRead, readErr := io.CopyN(tmpBuffer, part1, int64(tmpBufferSize))
+		bytesRead, readErr := io.CopyN(tmpBuffer, partReader, int64(tmpBufferSize))
 		readFully := readErr != nil && readErr == io.EOF
 		tmpBuf := tmpBuffer.Bytes()
 		bytesToCopy := tmpBuf[0:int(bytesRead)]
@@ -114,29 +184,20 @@ func (fs *FilerServer) doAutoChunk(ctx context.Context, w http.ResponseWriter, r
 		copy(chunkBuf[chunkBufOffset:chunkBufOffset+int32(bytesRead)], bytesToCopy)
 		chunkBufOffset = chunkBufOffset + int32(bytesRead)
 
-		if chunkBufOffset >= chunkSize || readFully || (chunkBufOffset > 0 && bytesRead == 0) {
+		if int64(chunkBufOffset) >= chunkBufSize || readFully || (chunkBufOffset > 0 && bytesRead == 0) {
 			writtenChunks = writtenChunks + 1
-			fileId, urlLocation, auth, assignErr := fs.assignNewFileInfo(w, r, replication, collection, dataCenter)
-			if assignErr != nil {
-				return nil, assignErr
-			}
-
-			// upload the chunk to the volume server
 			chunkName := fileName + "_chunk_" + strconv.FormatInt(int64(len(fileChunks)+1), 10)
-			uploadErr := fs.doUpload(urlLocation, w, r, chunkBuf[0:chunkBufOffset], chunkName, "application/octet-stream", fileId, auth)
-			if uploadErr != nil {
-				return nil, uploadErr
+			chunk, isNew, saveErr := fs.uploadChunk(ctx, w, r, chunkBuf[0:chunkBufOffset], chunkName, replication, collection, dataCenter, compression, encrypted)
+			if saveErr != nil {
+				return nil, saveErr
+			}
+			if isNew {
+				newChunks++
 			}
 
 			// Save to chunk manifest structure
-			fileChunks = append(fileChunks,
-				&filer_pb.FileChunk{
-					FileId: fileId,
-					Offset: chunkOffset,
-					Size:   uint64(chunkBufOffset),
-					Mtime:  time.Now().UnixNano(),
-				},
-			)
+			chunk.Offset = chunkOffset
+			fileChunks = append(fileChunks, chunk)
 
 			// reset variables for the next chunk
 			chunkBufOffset = 0
@@ -176,17 +237,106 @@ func (fs *FilerServer) doAutoChunk(ctx context.Context, w http.ResponseWriter, r
 		},
 		Chunks: fileChunks,
 	}
-	if dbErr := fs.filer.CreateEntry(ctx, entry); dbErr != nil {
+	if compression != "" || encrypted {
+		// the chunks are stored as application/octet-stream
+		entry.Attr.Mime = mimeType
+	}
+	setRetention(r, &entry.Attr)
+	setExpiry(r, &entry.Attr)
+	setExtended(r, entry)
+	if fs.isDedupUpload(ctx, r) {
+		ctx = filer2.WithDedupUpload(ctx)
+		filerResult.Deduplicated = len(fileChunks) > 0 && newChunks == 0
+	}
+	dbErr := fs.checkUploadedEntry(ctx, r, entry)
+	if dbErr == nil {
+		dbErr = fs.conditionalWrite(ctx, r, entry.FullPath, func() error {
+			return fs.filer.CreateEntry(ctx, entry)
+		})
+	}
+	if dbErr != nil {
 		fs.filer.DeleteChunks(entry.FullPath, entry.Chunks)
 		replyerr = dbErr
 		filerResult.Error = dbErr.Error()
 		glog.V(0).Infof("failing to write %s to filer server : %v", path, dbErr)
 		return
 	}
+	setEtag(w, filer2.ETag(entry.Chunks))
 
 	return
 }
 
+// saveChunk uploads the chunk to the volume server, compressed if smaller, and then encrypted if asked,
+// or for the dedup uploads, reuses the existing chunk with the same content.
+func (fs *FilerServer) saveChunk(ctx context.Context, w http.ResponseWriter, r *http.Request,
+	data []byte, chunkName string, replication string, collection string, dataCenter string, compression string, encrypted bool) (*filer_pb.FileChunk, error) {
+	chunk, _, err := fs.uploadChunk(ctx, w, r, data, chunkName, replication, collection, dataCenter, compression, encrypted)
+	return chunk, err
+}
+
+// uploadChunk is saveChunk, also telling whether the chunk is newly stored instead of shared
+func (fs *FilerServer) uploadChunk(ctx context.Context, w http.ResponseWriter, r *http.Request,
+	data []byte, chunkName string, replication string, collection string, dataCenter string, compression string, encrypted bool) (chunk *filer_pb.FileChunk, isNew bool, err error) {
+
+	var contentHash string
+	if fs.isDedupUpload(ctx, r) {
+		contentEncoding := compression
+		if encrypted {
+			contentEncoding += "+encrypted"
+		}
+		h := filer2.NewDedupHash(collection, replication, r.URL.Query().Get("ttl"), "application/octet-stream", contentEncoding)
+		h.Write(data)
+		contentHash = filer2.DedupHashString(h)
+		if chunk = fs.referenceDedupChunk(ctx, contentHash); chunk != nil {
+			chunk.Mtime = time.Now().UnixNano()
+			return chunk, false, nil
+		}
+	}
+
+	storedData := data
+	if compression != "" {
+		compressed, compressErr := filer2.CompressChunk(compression, data)
+		if compressErr != nil {
+			return nil, false, compressErr
+		}
+		if len(compressed) < len(data) {
+			storedData = compressed
+		} else {
+			compression = ""
+		}
+	}
+	var cipherKey []byte
+	if encrypted {
+		var encryptErr error
+		if storedData, cipherKey, encryptErr = filer2.EncryptChunk(storedData); encryptErr != nil {
+			return nil, false, encryptErr
+		}
+	}
+
+	fileId, urlLocation, auth, assignErr := fs.assignNewFileInfo(w, r, replication, collection, dataCenter)
+	if assignErr != nil {
+		return nil, false, assignErr
+	}
+
+	// upload the chunk to the volume server
+	uploadErr := fs.doUpload(urlLocation, w, r, storedData, chunkName, "application/octet-stream", fileId, auth)
+	if uploadErr != nil {
+		return nil, false, uploadErr
+	}
+
+	chunk = &filer_pb.FileChunk{
+		FileId:      fileId,
+		Size:        uint64(len(data)),
+		Mtime:       time.Now().UnixNano(),
+		Compression: compression,
+		CipherKey:   cipherKey,
+	}
+	if contentHash != "" {
+		fs.addDedupChunk(ctx, contentHash, chunk)
+	}
+	return chunk, true, nil
+}
+
 func (fs *FilerServer) doUpload(urlLocation string, w http.ResponseWriter, r *http.Request,
 	chunkBuf []byte, fileName string, contentType string, fileId string, auth security.EncodedJwt) (err error) {
 
@@ -196,6 +346,9 @@ func (fs *FilerServer) doUpload(urlLocation string, w http.ResponseWriter, r *ht
 		stats.FilerRequestHistogram.WithLabelValues("postAutoChunkUpload").Observe(time.Since(start).Seconds())
 	}()
 
+	if ttlQuery := withNeedleTtl("", r); ttlQuery != "" {
+		urlLocation += "?" + ttlQuery
+	}
 	ioReader := ioutil.NopCloser(bytes.NewBuffer(chunkBuf))
 	uploadResult, uploadError := operation.Upload(urlLocation, fileName, ioReader, false, contentType, nil, auth)
 	if uploadResult != nil {
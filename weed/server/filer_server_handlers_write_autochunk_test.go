@@ -0,0 +1,34 @@
+package weed_server
+
+import (
+	"testing"
+
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+)
+
+func TestUploadChunkSize(t *testing.T) {
+	const MB = 1024 * 1024
+	tests := []struct {
+		maxMB         int32
+		contentLength int64
+		fileSizeLimit int64
+		chunkSize     int32
+	}{
+		// not chunked without the maxMB, if fitting in one upload
+		{0, 100 * MB, needle.MaxNeedleDataSize, 0},
+		{0, 10 * MB, 10 * MB, 0},
+		// chunked over the limit advertised by the volume servers
+		{0, 10*MB + 1, 10 * MB, 10 * MB},
+		{0, 100 * MB, 64 * MB, oversizedFileChunkMB * MB},
+		{0, needle.MaxNeedleDataSize + 1, needle.MaxNeedleDataSize, oversizedFileChunkMB * MB},
+		// the maxMB is capped by the limit
+		{4, 100 * MB, 64 * MB, 4 * MB},
+		{8, 100 * MB, 5 * MB, 5 * MB},
+	}
+	for _, test := range tests {
+		if chunkSize := uploadChunkSize(test.maxMB, test.contentLength, test.fileSizeLimit); chunkSize != test.chunkSize {
+			t.Errorf("maxMB %d, content length %d, limit %d: chunk size %d, expected %d",
+				test.maxMB, test.contentLength, test.fileSizeLimit, chunkSize, test.chunkSize)
+		}
+	}
+}
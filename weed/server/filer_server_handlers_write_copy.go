@@ -0,0 +1,180 @@
+package weed_server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+	"github.com/chrislusf/seaweedfs/weed/stats"
+	"github.com/chrislusf/seaweedfs/weed/util"
+	"github.com/golang/protobuf/proto"
+)
+
+// copyContent copies the file in the "from" query parameter to the request path, without the data sent by the client.
+// The copy shares the chunks if the chunk references are counted and the collection is the same,
+// or else the chunks are copied by the filer.
+// curl -X POST "http://localhost:8888/path/to/copy?op=copy&from=/path/to/file"
+func (fs *FilerServer) copyContent(ctx context.Context, w http.ResponseWriter, r *http.Request,
+	replication string, collection string, dataCenter string) {
+
+	stats.FilerRequestCounter.WithLabelValues("copy").Inc()
+	start := time.Now()
+	defer func() {
+		stats.FilerRequestHistogram.WithLabelValues("copy").Observe(time.Since(start).Seconds())
+	}()
+
+	src := filer2.FullPath(r.URL.Query().Get("from"))
+	if !strings.HasPrefix(string(src), "/") {
+		writeJsonError(w, r, http.StatusBadRequest, fmt.Errorf("invalid copy source %q", src))
+		return
+	}
+	id, err := fs.httpIdentity(r)
+	if err != nil {
+		writeJsonError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if err = fs.filer.CheckAccess(ctx, id, src, filer2.PermissionRead); err != nil {
+		writeJsonError(w, r, filerErrorStatus(err), err)
+		return
+	}
+	srcEntry, err := fs.filer.FindEntry(ctx, src)
+	if err == filer2.ErrNotFound {
+		writeJsonError(w, r, http.StatusNotFound, fmt.Errorf("%s: %v", src, err))
+		return
+	}
+	if err != nil {
+		writeJsonError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	if srcEntry.IsDirectory() {
+		writeJsonError(w, r, http.StatusBadRequest, fmt.Errorf("can not copy directory %s", src))
+		return
+	}
+
+	dst := r.URL.Path
+	if strings.HasSuffix(dst, "/") {
+		dst += src.Name()
+	}
+	if filer2.FullPath(dst) == src {
+		writeJsonError(w, r, http.StatusBadRequest, fmt.Errorf("can not copy %s to itself", src))
+		return
+	}
+
+	// the copy keeps the content and extended attributes, but not the retention or the owner
+	entry := &filer2.Entry{
+		FullPath: filer2.FullPath(dst),
+		Attr: filer2.Attr{
+			Mtime:       time.Now(),
+			Crtime:      time.Now(),
+			Mode:        srcEntry.Mode,
+			Uid:         OS_UID,
+			Gid:         OS_GID,
+			Mime:        srcEntry.Mime,
+			Replication: srcEntry.Replication,
+			Collection:  srcEntry.Collection,
+			TtlSec:      srcEntry.TtlSec,
+			FileSize:    srcEntry.FileSize,
+		},
+	}
+	if err = setRetention(r, &entry.Attr); err != nil {
+		writeJsonError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	for name, value := range srcEntry.Extended {
+		if entry.Extended == nil {
+			entry.Extended = make(map[string][]byte)
+		}
+		entry.Extended[name] = value
+	}
+	setExtended(r, entry)
+	if err = fs.checkUploadedEntry(ctx, r, entry); err != nil {
+		writeJsonError(w, r, filerErrorStatus(err), err)
+		return
+	}
+
+	if r.URL.Query().Get("collection") == "" {
+		collection = srcEntry.Collection
+	}
+	if fs.filer.CanShareChunks() && collection == srcEntry.Collection {
+		for _, chunk := range srcEntry.Chunks {
+			entry.Chunks = append(entry.Chunks, proto.Clone(chunk).(*filer_pb.FileChunk))
+		}
+		if err = fs.filer.ReferenceChunks(ctx, entry.Chunks); err != nil {
+			glog.V(0).Infof("copy %s to %s: %v", src, dst, err)
+			writeJsonError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+	} else {
+		if r.URL.Query().Get("replication") == "" && srcEntry.Replication != "" {
+			replication = srcEntry.Replication
+		}
+		entry.Collection, entry.Replication = collection, replication
+		for _, chunk := range srcEntry.Chunks {
+			newChunk, copyErr := fs.copyChunk(w, r, chunk, srcEntry.Mime, replication, collection, dataCenter)
+			if copyErr != nil {
+				glog.V(0).Infof("copy %s chunk %s: %v", src, chunk.GetFileIdString(), copyErr)
+				fs.filer.DeleteChunks(entry.FullPath, entry.Chunks)
+				writeJsonError(w, r, http.StatusInternalServerError, copyErr)
+				return
+			}
+			entry.Chunks = append(entry.Chunks, newChunk)
+		}
+	}
+
+	err = fs.conditionalWrite(ctx, r, entry.FullPath, func() error {
+		return fs.filer.CreateEntry(ctx, entry)
+	})
+	if err != nil {
+		glog.V(0).Infof("copy %s to %s: %v", src, dst, err)
+		fs.filer.DeleteChunks(entry.FullPath, entry.Chunks)
+		writeJsonError(w, r, filerErrorStatus(err), err)
+		return
+	}
+
+	setEtag(w, filer2.ETag(entry.Chunks))
+	writeJsonQuiet(w, r, http.StatusCreated, &FilerPostResult{
+		Name: entry.Name(),
+		Size: uint32(entry.Size()),
+	})
+}
+
+// copyChunk stores the chunk content again with a new file id, as stored, so compressed or encrypted the same way
+func (fs *FilerServer) copyChunk(w http.ResponseWriter, r *http.Request, chunk *filer_pb.FileChunk,
+	mimeType string, replication string, collection string, dataCenter string) (*filer_pb.FileChunk, error) {
+
+	urlStrings, err := fs.filer.MasterClient.LookupFileIdUrls(chunk.GetFileIdString())
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	for _, urlString := range urlStrings {
+		if data, err = util.Get(urlString); err == nil {
+			break
+		}
+		glog.V(1).Infof("read %s: %v", urlString, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := "application/octet-stream"
+	if chunk.Compression == "" && len(chunk.CipherKey) == 0 && mimeType != "" {
+		contentType = mimeType
+	}
+	fileId, urlLocation, auth, err := fs.assignNewFileInfo(w, r, replication, collection, dataCenter)
+	if err != nil {
+		return nil, err
+	}
+	if err = fs.doUpload(urlLocation, w, r, data, "", contentType, fileId, auth); err != nil {
+		return nil, err
+	}
+
+	newChunk := proto.Clone(chunk).(*filer_pb.FileChunk)
+	newChunk.FileId, newChunk.Fid = fileId, nil
+	return newChunk, nil
+}
@@ -0,0 +1,63 @@
+package weed_server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/operation"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+)
+
+// isDedupUpload checks whether the upload reuses the existing chunks with the same content,
+// in the dedup mode, or by "dedup=true" in the query.
+// curl -F file=@report.pdf "http://localhost:8888/docs/?dedup=true"
+func (fs *FilerServer) isDedupUpload(ctx context.Context, r *http.Request) bool {
+	if fs.filer.IsDedupEnabled() {
+		return true
+	}
+	if r.URL.Query().Get("dedup") != "true" {
+		return false
+	}
+	if err := fs.filer.StartDedupCounting(ctx); err != nil {
+		glog.V(0).Infof("dedup %s: %v", r.URL.Path, err)
+		return false
+	}
+	return true
+}
+
+// referenceDedupChunk returns the existing chunk with the same content, or nil to keep the upload.
+// The dedup index errors only lose the deduplication.
+func (fs *FilerServer) referenceDedupChunk(ctx context.Context, contentHash string) *filer_pb.FileChunk {
+	chunk, err := fs.filer.ReferenceDedupChunk(ctx, contentHash)
+	if err != nil {
+		glog.V(0).Infof("dedup %s: %v", contentHash, err)
+		return nil
+	}
+	if chunk != nil {
+		glog.V(4).Infof("dedup %s to %s", contentHash, chunk.GetFileIdString())
+	}
+	return chunk
+}
+
+func (fs *FilerServer) addDedupChunk(ctx context.Context, contentHash string, chunk *filer_pb.FileChunk) {
+	if err := fs.filer.AddDedupChunk(ctx, contentHash, chunk); err != nil {
+		glog.V(0).Infof("add dedup %s: %v", contentHash, err)
+	}
+}
+
+// dedupUploadedFile returns the file id of the existing chunk with the same content, deleting the uploaded one,
+// or records the uploaded file id.
+// The content can only be hashed while being uploaded, since the body is streamed to the volume server.
+func (fs *FilerServer) dedupUploadedFile(ctx context.Context, contentHash string, fileId string, ret operation.UploadResult) string {
+	if chunk := fs.referenceDedupChunk(ctx, contentHash); chunk != nil {
+		fs.filer.DeleteFileByFileId(fileId)
+		return chunk.GetFileIdString()
+	}
+	fs.addDedupChunk(ctx, contentHash, &filer_pb.FileChunk{
+		FileId: fileId,
+		Size:   uint64(ret.Size),
+		ETag:   ret.ETag,
+	})
+	return fileId
+}
@@ -0,0 +1,163 @@
+package weed_server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	filenamePath "path"
+	"strings"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/stats"
+	"github.com/chrislusf/seaweedfs/weed/util"
+)
+
+type FilerIngestResult struct {
+	Records int `json:"records"`
+	Files   int `json:"files"`
+}
+
+// ingestRecord is one json line of the ingested records
+type ingestRecord struct {
+	Path string            `json:"path"`
+	Tags map[string]string `json:"tags"`
+	Time int64             `json:"time"`
+	Data string            `json:"data"`
+}
+
+// ingestRecords buffers the records in the request body, to be appended to their files on the next flush.
+// The records are the lines of the body tagged by the query parameters,
+// or the "application/x-ndjson" lines with the "path" pattern, "tags", unix "time" and "data" of each record.
+// The file of each record is the path pattern, or else the request path, filled by expandIngestPath.
+// The request waits for its records to be appended, which fails if any file of the records still fails after retries.
+// curl -g -X POST --data-binary @readings.txt "http://localhost:8888/iot/{device}/{yyyy-mm-dd}.log?op=ingest&device=d1"
+func (fs *FilerServer) ingestRecords(ctx context.Context, w http.ResponseWriter, r *http.Request,
+	replication string, collection string, dataCenter string) {
+
+	stats.FilerRequestCounter.WithLabelValues("ingest").Inc()
+	start := time.Now()
+	defer func() {
+		stats.FilerRequestHistogram.WithLabelValues("ingest").Observe(time.Since(start).Seconds())
+	}()
+
+	query := r.URL.Query()
+	batch := make(map[filer2.FullPath]*ingestBuffer)
+	records := 0
+	addRecord := func(pattern string, tags map[string]string, t time.Time, data string) error {
+		p, err := expandIngestPath(pattern, tags, t)
+		if err != nil {
+			return err
+		}
+		buffer, found := batch[p]
+		if !found {
+			buffer = &ingestBuffer{
+				template: &filer2.Entry{
+					FullPath: p,
+					Attr: filer2.Attr{
+						Mtime:       time.Now(),
+						Crtime:      time.Now(),
+						Mode:        0660,
+						Uid:         OS_UID,
+						Gid:         OS_GID,
+						Replication: replication,
+						Collection:  collection,
+						Mime:        mime.TypeByExtension(filenamePath.Ext(string(p))),
+						TtlSec:      int32(util.ParseInt(query.Get("ttl"), 0)),
+					},
+				},
+				replication: replication,
+				collection:  collection,
+				dataCenter:  dataCenter,
+				ttl:         query.Get("ttl"),
+			}
+			batch[p] = buffer
+		}
+		buffer.data = append(buffer.data, data...)
+		if !strings.HasSuffix(data, "\n") {
+			buffer.data = append(buffer.data, '\n')
+		}
+		buffer.records++
+		records++
+		return nil
+	}
+
+	var err error
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-ndjson") {
+		decoder := json.NewDecoder(r.Body)
+		for {
+			var record ingestRecord
+			if err = decoder.Decode(&record); err == io.EOF {
+				err = nil
+				break
+			} else if err != nil {
+				break
+			}
+			if record.Path == "" {
+				record.Path = r.URL.Path
+			}
+			t := time.Now()
+			if record.Time > 0 {
+				t = time.Unix(record.Time, 0)
+			}
+			if err = addRecord(record.Path, record.Tags, t, record.Data); err != nil {
+				break
+			}
+		}
+	} else {
+		tags := make(map[string]string)
+		for name := range query {
+			tags[name] = query.Get(name)
+		}
+		scanner := bufio.NewScanner(r.Body)
+		scanner.Buffer(make([]byte, 64*1024), fs.ingest.chunkSize)
+		t := time.Now()
+		for scanner.Scan() {
+			if err = addRecord(r.URL.Path, tags, t, scanner.Text()); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			err = scanner.Err()
+		}
+	}
+	if err != nil {
+		writeJsonError(w, r, http.StatusBadRequest, fmt.Errorf("ingest record %d: %v", records+1, err))
+		return
+	}
+
+	// the new files are owned by the caller
+	for _, buffer := range batch {
+		if err = fs.checkUploadedEntry(ctx, r, buffer.template); err != nil {
+			writeJsonError(w, r, filerErrorStatus(err), err)
+			return
+		}
+	}
+
+	flushed, err := fs.ingest.add(batch)
+	if err != nil {
+		writeJsonError(w, r, http.StatusServiceUnavailable, err)
+		return
+	}
+	for range batch {
+		select {
+		case err = <-flushed:
+		case <-r.Context().Done():
+			// the records are still appended
+			return
+		}
+		if err != nil {
+			writeJsonError(w, r, http.StatusInternalServerError, fmt.Errorf("append ingested records: %v", err))
+			return
+		}
+	}
+
+	writeJsonQuiet(w, r, http.StatusAccepted, &FilerIngestResult{
+		Records: records,
+		Files:   len(batch),
+	})
+}
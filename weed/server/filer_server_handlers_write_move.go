@@ -0,0 +1,62 @@
+package weed_server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/stats"
+)
+
+// moveEntry moves the file or directory in the "from" query parameter to the request path, as renaming in the filer ui.
+// The destination should not exist, and a directory is moved with all its sub entries.
+// curl -X POST "http://localhost:8888/path/to/new_name?op=move&from=/path/to/old_name"
+func (fs *FilerServer) moveEntryHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+
+	stats.FilerRequestCounter.WithLabelValues("move").Inc()
+	start := time.Now()
+	defer func() {
+		stats.FilerRequestHistogram.WithLabelValues("move").Observe(time.Since(start).Seconds())
+	}()
+
+	src := filer2.FullPath(r.URL.Query().Get("from"))
+	if !strings.HasPrefix(string(src), "/") || src == "/" {
+		writeJsonError(w, r, http.StatusBadRequest, fmt.Errorf("invalid move source %q", src))
+		return
+	}
+	dst := r.URL.Path
+	if strings.HasSuffix(dst, "/") {
+		dst += src.Name()
+	}
+	if dst == string(src) || strings.HasPrefix(dst, string(src)+"/") {
+		writeJsonError(w, r, http.StatusBadRequest, fmt.Errorf("can not move %s into itself", src))
+		return
+	}
+
+	if _, err := fs.filer.FindEntry(ctx, src); err == filer2.ErrNotFound {
+		writeJsonError(w, r, http.StatusNotFound, fmt.Errorf("%s: %v", src, err))
+		return
+	}
+	if _, err := fs.filer.FindEntry(ctx, filer2.FullPath(dst)); err == nil {
+		writeJsonError(w, r, http.StatusConflict, fmt.Errorf("%s already exists", dst))
+		return
+	}
+
+	id, err := fs.httpIdentity(r)
+	if err != nil {
+		writeJsonError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	oldParent, oldName := src.DirAndName()
+	newParent, newName := filer2.FullPath(dst).DirAndName()
+	if err = fs.renameEntry(ctx, id, filer2.FullPath(oldParent), oldName, filer2.FullPath(newParent), newName); err != nil {
+		writeJsonError(w, r, filerErrorStatus(err), err)
+		return
+	}
+
+	writeJsonQuiet(w, r, http.StatusOK, map[string]string{"from": string(src), "to": dst})
+}
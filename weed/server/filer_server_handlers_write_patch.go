@@ -0,0 +1,111 @@
+package weed_server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+	"github.com/chrislusf/seaweedfs/weed/stats"
+)
+
+// patchContent overwrites the file content from the "offset" query parameter with the request body,
+// only uploading the new chunks.
+func (fs *FilerServer) patchContent(ctx context.Context, w http.ResponseWriter, r *http.Request,
+	replication string, collection string, dataCenter string) {
+
+	stats.FilerRequestCounter.WithLabelValues("patch").Inc()
+	start := time.Now()
+	defer func() {
+		stats.FilerRequestHistogram.WithLabelValues("patch").Observe(time.Since(start).Seconds())
+	}()
+
+	offset, err := strconv.ParseInt(r.URL.Query().Get("offset"), 10, 64)
+	if err != nil || offset < 0 {
+		writeJsonError(w, r, http.StatusBadRequest, fmt.Errorf("invalid offset %q", r.URL.Query().Get("offset")))
+		return
+	}
+	if isMultipartUpload(r) {
+		writeJsonError(w, r, http.StatusBadRequest, fmt.Errorf("patch %s with the raw body instead of multipart", r.URL.Path))
+		return
+	}
+
+	path := filer2.FullPath(r.URL.Path)
+	entry, err := fs.filer.FindEntry(ctx, path)
+	if err == filer2.ErrNotFound {
+		writeJsonError(w, r, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		writeJsonError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	if entry.IsDirectory() {
+		writeJsonError(w, r, http.StatusBadRequest, fmt.Errorf("%s is a directory", path))
+		return
+	}
+	// the new chunks are stored the same way as the file
+	if entry.Collection != "" {
+		collection = entry.Collection
+	}
+	if entry.Replication != "" {
+		replication = entry.Replication
+	}
+
+	chunkSize := int64(fs.option.MaxMB) * 1024 * 1024
+	if chunkSize <= 0 {
+		chunkSize = 4 * 1024 * 1024
+	}
+	compression := fs.filer.ChunkCompression(collection, entry.Mime)
+	encrypted := fs.isEncryptedUpload(r, collection)
+
+	var chunks []*filer_pb.FileChunk
+	buf := make([]byte, chunkSize)
+	chunkOffset := offset
+	for {
+		n, readErr := io.ReadFull(r.Body, buf)
+		if n > 0 {
+			chunkName := path.Name() + "_patch_" + strconv.FormatInt(chunkOffset, 10)
+			chunk, saveErr := fs.saveChunk(ctx, w, r, buf[:n], chunkName, replication, collection, dataCenter, compression, encrypted)
+			if saveErr != nil {
+				fs.filer.DeleteChunks(path, chunks)
+				writeJsonError(w, r, http.StatusInternalServerError, saveErr)
+				return
+			}
+			chunk.Offset = chunkOffset
+			chunks = append(chunks, chunk)
+			chunkOffset += int64(n)
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			fs.filer.DeleteChunks(path, chunks)
+			writeJsonError(w, r, http.StatusBadRequest, readErr)
+			return
+		}
+	}
+
+	if r.Header.Get(BypassGovernanceRetentionHeader) == "true" {
+		ctx = filer2.WithGovernanceBypass(ctx)
+	}
+	err = fs.conditionalWrite(ctx, r, path, func() error {
+		return fs.filer.PatchEntry(ctx, path, chunks)
+	})
+	if err != nil {
+		glog.V(0).Infof("patch %s at %d: %v", path, offset, err)
+		fs.filer.DeleteChunks(path, chunks)
+		writeJsonError(w, r, filerErrorStatus(err), err)
+		return
+	}
+
+	writeJsonQuiet(w, r, http.StatusOK, &FilerPostResult{
+		Name: path.Name(),
+		Size: uint32(chunkOffset - offset),
+	})
+}
@@ -0,0 +1,308 @@
+package weed_server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+	"github.com/chrislusf/seaweedfs/weed/stats"
+)
+
+var errIngestBufferFull = errors.New("too many ingested records waiting, the ingestion buffer is full")
+
+const (
+	ingestFlushConcurrency = 16
+	// the failed flushes are retried on the next flush intervals, before the records are rejected
+	ingestFlushAttempts = 3
+)
+
+// ingestBatcher buffers the small ingested records by their files,
+// and appends each buffer to its file on every flush interval, or sooner when a buffer has a full chunk.
+// The records of one file are appended in the order they are added, since the flushes do not overlap.
+// The adders are told when their records are appended, or finally failed.
+type ingestBatcher struct {
+	sync.Mutex
+	interval  time.Duration
+	maxBytes  int
+	chunkSize int
+	// flush appends the buffer to its file, and returns an error if the buffer should be flushed again
+	flush func(p filer2.FullPath, buffer *ingestBuffer) error
+
+	buffers       map[filer2.FullPath]*ingestBuffer
+	bufferedBytes int
+	flushNow      chan struct{}
+}
+
+// ingestBuffer is the records waiting for one file, which is created from the template if missing
+type ingestBuffer struct {
+	template    *filer2.Entry
+	replication string
+	collection  string
+	dataCenter  string
+	ttl         string
+	data        []byte
+	records     int
+	attempts    int
+	waiters     []chan error // told the result of the flush
+}
+
+func newIngestBatcher(interval time.Duration, maxBytes, chunkSize int, flush func(filer2.FullPath, *ingestBuffer) error) *ingestBatcher {
+	return &ingestBatcher{
+		interval:  interval,
+		maxBytes:  maxBytes,
+		chunkSize: chunkSize,
+		flush:     flush,
+		buffers:   make(map[filer2.FullPath]*ingestBuffer),
+		flushNow:  make(chan struct{}, 1),
+	}
+}
+
+// add buffers all records of one request, or none of them if the buffered records would be too many.
+// The flush result of each file of the batch is sent to the returned channel.
+func (b *ingestBatcher) add(batch map[filer2.FullPath]*ingestBuffer) (flushed <-chan error, err error) {
+	size := 0
+	for _, buffer := range batch {
+		size += len(buffer.data)
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	if b.bufferedBytes+size > b.maxBytes {
+		return nil, errIngestBufferFull
+	}
+	b.bufferedBytes += size
+
+	results := make(chan error, len(batch))
+	hasFullChunk := false
+	for p, buffer := range batch {
+		if existing, found := b.buffers[p]; found {
+			existing.data = append(existing.data, buffer.data...)
+			existing.records += buffer.records
+			existing.waiters = append(existing.waiters, results)
+			buffer = existing
+		} else {
+			buffer.waiters = []chan error{results}
+			b.buffers[p] = buffer
+		}
+		if len(buffer.data) >= b.chunkSize {
+			hasFullChunk = true
+		}
+	}
+	if hasFullChunk {
+		select {
+		case b.flushNow <- struct{}{}:
+		default:
+		}
+	}
+	return results, nil
+}
+
+func (fs *FilerServer) startIngestion() {
+	interval := fs.option.IngestFlushInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	maxBytes := fs.option.IngestMaxBufferMB * 1024 * 1024
+	if maxBytes <= 0 {
+		maxBytes = 256 * 1024 * 1024
+	}
+	chunkSize := fs.option.MaxMB * 1024 * 1024
+	if chunkSize <= 0 {
+		chunkSize = 4 * 1024 * 1024
+	}
+	fs.ingest = newIngestBatcher(interval, maxBytes, chunkSize, fs.flushIngested)
+	go fs.ingest.loop()
+}
+
+func (b *ingestBatcher) loop() {
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-b.flushNow:
+		}
+		b.flushAll()
+	}
+}
+
+// flushAll flushes the files in parallel, and puts the failed buffers back before the records added since,
+// until the buffers fail too many times
+func (b *ingestBatcher) flushAll() {
+	b.Lock()
+	buffers := b.buffers
+	b.buffers = make(map[filer2.FullPath]*ingestBuffer)
+	b.Unlock()
+
+	var wg sync.WaitGroup
+	concurrency := make(chan struct{}, ingestFlushConcurrency)
+	for p, buffer := range buffers {
+		wg.Add(1)
+		concurrency <- struct{}{}
+		go func(p filer2.FullPath, buffer *ingestBuffer) {
+			defer func() {
+				<-concurrency
+				wg.Done()
+			}()
+			err := b.flush(p, buffer)
+			if err != nil {
+				buffer.attempts++
+				if buffer.attempts < ingestFlushAttempts {
+					glog.Warningf("flush %d ingested records to %s: %v", buffer.records, p, err)
+					b.requeue(p, buffer)
+					return
+				}
+				glog.Errorf("drop %d ingested records to %s after %d attempts: %v", buffer.records, p, buffer.attempts, err)
+			}
+			b.Lock()
+			b.bufferedBytes -= len(buffer.data)
+			b.Unlock()
+			for _, waiter := range buffer.waiters {
+				waiter <- err
+			}
+		}(p, buffer)
+	}
+	wg.Wait()
+}
+
+func (b *ingestBatcher) requeue(p filer2.FullPath, buffer *ingestBuffer) {
+	b.Lock()
+	defer b.Unlock()
+	if newer, found := b.buffers[p]; found {
+		buffer.data = append(buffer.data, newer.data...)
+		buffer.records += newer.records
+		buffer.waiters = append(buffer.waiters, newer.waiters...)
+	}
+	b.buffers[p] = buffer
+}
+
+// flushIngested appends the buffered records to the file as new chunks.
+// The uploaded chunks are deleted if the file can not be appended.
+func (fs *FilerServer) flushIngested(p filer2.FullPath, buffer *ingestBuffer) error {
+
+	stats.FilerRequestCounter.WithLabelValues("ingestFlush").Inc()
+	start := time.Now()
+	defer func() {
+		stats.FilerRequestHistogram.WithLabelValues("ingestFlush").Observe(time.Since(start).Seconds())
+	}()
+
+	ctx := context.Background()
+
+	replication, collection := buffer.replication, buffer.collection
+	if entry, err := fs.filer.FindEntry(ctx, p); err == nil {
+		// the new chunks are stored the same way as the file
+		if entry.Collection != "" {
+			collection = entry.Collection
+		}
+		if entry.Replication != "" {
+			replication = entry.Replication
+		}
+	}
+	compression := fs.filer.ChunkCompression(collection, buffer.template.Mime)
+	encrypted := fs.filer.IsChunkEncrypted(collection)
+
+	// the chunks are saved like the uploads of a request with the same ttl, without replying to it
+	r, err := http.NewRequest("POST", string(p)+"?ttl="+url.QueryEscape(buffer.ttl), nil)
+	if err != nil {
+		return err
+	}
+	w := &discardResponseWriter{}
+
+	var chunks []*filer_pb.FileChunk
+	for offset := 0; offset < len(buffer.data); offset += fs.ingest.chunkSize {
+		end := offset + fs.ingest.chunkSize
+		if end > len(buffer.data) {
+			end = len(buffer.data)
+		}
+		chunkName := p.Name() + "_ingest_" + strconv.FormatInt(start.UnixNano(), 10) + "_" + strconv.Itoa(len(chunks)+1)
+		chunk, saveErr := fs.saveChunk(ctx, w, r, buffer.data[offset:end], chunkName,
+			replication, collection, buffer.dataCenter, compression, encrypted)
+		if saveErr != nil {
+			fs.filer.DeleteChunks(p, chunks)
+			return saveErr
+		}
+		chunk.Offset = int64(offset)
+		chunks = append(chunks, chunk)
+	}
+
+	if _, err = fs.filer.AppendEntry(ctx, buffer.template, chunks); err != nil {
+		fs.filer.DeleteChunks(p, chunks)
+		return err
+	}
+	return nil
+}
+
+// expandIngestPath fills the path pattern with the record tags and time.
+// The placeholder {name} is the tag value, or else the time in UTC if the name is made of
+// yyyy, mm, dd and hh, with "-", "_" or "." in between, like {yyyy-mm-dd}.
+func expandIngestPath(pattern string, tags map[string]string, t time.Time) (filer2.FullPath, error) {
+	var expanded []string
+	rest := pattern
+	for {
+		begin := strings.Index(rest, "{")
+		if begin < 0 {
+			break
+		}
+		end := strings.Index(rest[begin:], "}")
+		if end < 0 {
+			return "", fmt.Errorf("unclosed placeholder in %s", pattern)
+		}
+		name := rest[begin+1 : begin+end]
+		value, found := tags[name]
+		if found {
+			if value == "" || value == "." || value == ".." || strings.ContainsAny(value, "/\x00") {
+				return "", fmt.Errorf("invalid tag %s value %q", name, value)
+			}
+		} else if layout := ingestTimeLayout(name); layout != "" {
+			value = t.UTC().Format(layout)
+		} else {
+			return "", fmt.Errorf("missing tag %s for %s", name, pattern)
+		}
+		expanded = append(expanded, rest[:begin], value)
+		rest = rest[begin+end+1:]
+	}
+	p := strings.Join(expanded, "") + rest
+	if !strings.HasPrefix(p, "/") || strings.HasSuffix(p, "/") {
+		return "", fmt.Errorf("invalid file path %s", p)
+	}
+	return filer2.FullPath(p), nil
+}
+
+var ingestTimeReplacer = strings.NewReplacer("yyyy", "2006", "mm", "01", "dd", "02", "hh", "15")
+
+// ingestTimeLayout returns the time layout of the placeholder, or empty if it is not a time
+func ingestTimeLayout(name string) string {
+	if name == "" || strings.Trim(strings.NewReplacer("yyyy", "", "mm", "", "dd", "", "hh", "").Replace(name), "-_.") != "" {
+		return ""
+	}
+	return ingestTimeReplacer.Replace(name)
+}
+
+// discardResponseWriter drops the error replies of the request helpers used in the background
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w *discardResponseWriter) Header() http.Header {
+	if w.header == nil {
+		w.header = make(http.Header)
+	}
+	return w.header
+}
+
+func (w *discardResponseWriter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func (w *discardResponseWriter) WriteHeader(statusCode int) {
+}
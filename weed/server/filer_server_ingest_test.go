@@ -0,0 +1,105 @@
+package weed_server
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+)
+
+func TestExpandIngestPath(t *testing.T) {
+	at := time.Date(2019, 12, 3, 7, 30, 0, 0, time.UTC)
+	tags := map[string]string{"device": "d1", "bad": "../x"}
+
+	tests := []struct {
+		pattern  string
+		expected filer2.FullPath
+	}{
+		{"/iot/{device}/{yyyy-mm-dd}.log", "/iot/d1/2019-12-03.log"},
+		{"/iot/{yyyy}/{mm}/{dd}/{device}_{hh}.log", "/iot/2019/12/03/d1_07.log"},
+		{"/iot/all.log", "/iot/all.log"},
+		{"/iot/{unknown}.log", ""},
+		{"/iot/{bad}.log", ""},
+		{"/iot/{device", ""},
+		{"/iot/{device}/", ""},
+	}
+	for _, test := range tests {
+		p, err := expandIngestPath(test.pattern, tags, at)
+		if p != test.expected || (err == nil) != (test.expected != "") {
+			t.Errorf("expand %s: %s %v, expecting %s", test.pattern, p, err, test.expected)
+		}
+	}
+}
+
+func TestIngestBatcherRequeuesInOrder(t *testing.T) {
+	var flushed []string
+	failing := true
+	b := newIngestBatcher(time.Hour, 10, 4, func(p filer2.FullPath, buffer *ingestBuffer) error {
+		if failing {
+			return errors.New("volume servers unavailable")
+		}
+		flushed = append(flushed, string(buffer.data))
+		return nil
+	})
+
+	add := func(data string) (<-chan error, error) {
+		return b.add(map[filer2.FullPath]*ingestBuffer{"/a.log": {data: []byte(data), records: 1}})
+	}
+	flushed1, err := add("1\n")
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	b.flushAll()
+	flushed2, err := add("2\n")
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if _, err := add("3456789\n"); err != errIngestBufferFull {
+		t.Errorf("add beyond the buffer limit: %v", err)
+	}
+	select {
+	case err := <-flushed1:
+		t.Errorf("told %v before the failed flush is retried", err)
+	default:
+	}
+
+	failing = false
+	b.flushAll()
+	if len(flushed) != 1 || flushed[0] != "1\n2\n" {
+		t.Errorf("flushed %q", flushed)
+	}
+	if b.bufferedBytes != 0 {
+		t.Errorf("buffered %d bytes after flushing", b.bufferedBytes)
+	}
+	if err := <-flushed1; err != nil {
+		t.Errorf("flush of the requeued records: %v", err)
+	}
+	if err := <-flushed2; err != nil {
+		t.Errorf("flush: %v", err)
+	}
+}
+
+func TestIngestBatcherGivesUp(t *testing.T) {
+	b := newIngestBatcher(time.Hour, 10, 4, func(p filer2.FullPath, buffer *ingestBuffer) error {
+		return errors.New("is a directory")
+	})
+	flushed, err := b.add(map[filer2.FullPath]*ingestBuffer{"/a.log": {data: []byte("1\n"), records: 1}})
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	for i := 0; i < ingestFlushAttempts; i++ {
+		b.flushAll()
+	}
+	select {
+	case err = <-flushed:
+		if err == nil {
+			t.Errorf("told the failed records as flushed")
+		}
+	default:
+		t.Errorf("not told after %d failed flushes", ingestFlushAttempts)
+	}
+	if b.bufferedBytes != 0 || len(b.buffers) != 0 {
+		t.Errorf("buffered %d bytes after giving up", b.bufferedBytes)
+	}
+}
@@ -0,0 +1,205 @@
+package weed_server
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/stats"
+)
+
+var errRequestQueueFull = errors.New("too many requests, the request queue is full")
+
+// requestLimiter limits the http requests served at the same time, in total and by each client.
+// The requests beyond the limits wait in a bounded queue, and the clients waiting take turns
+// when a request finishes, so one busy client can not starve the others.
+type requestLimiter struct {
+	sync.Mutex
+	maxRequests          int // 0 for no limit
+	maxRequestsPerClient int // 0 for no limit
+	maxQueued            int
+	clientIdHeader       string
+
+	running int
+	queued  int
+	clients map[string]*clientRequests
+	// the clients with waiting requests, in the order to take turns
+	waitingClients []string
+}
+
+type clientRequests struct {
+	running int
+	waiting []chan struct{}
+}
+
+func newRequestLimiter(maxRequests, maxRequestsPerClient, maxQueued int, clientIdHeader string) *requestLimiter {
+	return &requestLimiter{
+		maxRequests:          maxRequests,
+		maxRequestsPerClient: maxRequestsPerClient,
+		maxQueued:            maxQueued,
+		clientIdHeader:       clientIdHeader,
+		clients:              make(map[string]*clientRequests),
+	}
+}
+
+func (l *requestLimiter) isEnabled() bool {
+	return l.maxRequests > 0 || l.maxRequestsPerClient > 0
+}
+
+// clientId is the trusted header if configured, or the remote ip.
+// The forwarded headers are not used by default, since they are set by the clients.
+func (l *requestLimiter) clientId(r *http.Request) string {
+	if l.clientIdHeader != "" {
+		if id := r.Header.Get(l.clientIdHeader); id != "" {
+			return id
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func (l *requestLimiter) canRun(c *clientRequests) bool {
+	return (l.maxRequests <= 0 || l.running < l.maxRequests) &&
+		(l.maxRequestsPerClient <= 0 || c.running < l.maxRequestsPerClient)
+}
+
+// acquire waits until the request can run, or the request is canceled
+func (l *requestLimiter) acquire(r *http.Request, client string) error {
+	l.Lock()
+	c, found := l.clients[client]
+	if !found {
+		c = &clientRequests{}
+		l.clients[client] = c
+	}
+	if len(c.waiting) == 0 && l.canRun(c) {
+		c.running++
+		l.running++
+		l.updateStats()
+		l.Unlock()
+		return nil
+	}
+	if l.queued >= l.maxQueued {
+		l.removeIdleClient(client, c)
+		l.Unlock()
+		return errRequestQueueFull
+	}
+	turn := make(chan struct{})
+	c.waiting = append(c.waiting, turn)
+	if len(c.waiting) == 1 {
+		l.waitingClients = append(l.waitingClients, client)
+	}
+	l.queued++
+	l.updateStats()
+	l.Unlock()
+
+	select {
+	case <-turn:
+		return nil
+	case <-r.Context().Done():
+	}
+
+	l.Lock()
+	defer l.Unlock()
+	for i, t := range c.waiting {
+		if t == turn {
+			c.waiting = append(c.waiting[:i], c.waiting[i+1:]...)
+			l.queued--
+			if len(c.waiting) == 0 {
+				l.removeWaitingClient(client)
+			}
+			l.removeIdleClient(client, c)
+			l.updateStats()
+			return r.Context().Err()
+		}
+	}
+	// the turn came at the same time, and is passed on
+	l.releaseLocked(client)
+	return r.Context().Err()
+}
+
+func (l *requestLimiter) release(client string) {
+	l.Lock()
+	defer l.Unlock()
+	l.releaseLocked(client)
+}
+
+func (l *requestLimiter) releaseLocked(client string) {
+	c := l.clients[client]
+	c.running--
+	l.running--
+	l.removeIdleClient(client, c)
+	l.dispatch()
+	l.updateStats()
+}
+
+// dispatch starts the waiting requests, one from each client in turn
+func (l *requestLimiter) dispatch() {
+	for i := 0; i < len(l.waitingClients); {
+		if l.maxRequests > 0 && l.running >= l.maxRequests {
+			return
+		}
+		client := l.waitingClients[i]
+		c := l.clients[client]
+		if !l.canRun(c) {
+			i++
+			continue
+		}
+		turn := c.waiting[0]
+		c.waiting = c.waiting[1:]
+		c.running++
+		l.running++
+		l.queued--
+		close(turn)
+		// the client goes to the end of the line
+		l.waitingClients = append(l.waitingClients[:i], l.waitingClients[i+1:]...)
+		if len(c.waiting) > 0 {
+			l.waitingClients = append(l.waitingClients, client)
+		}
+	}
+}
+
+func (l *requestLimiter) removeWaitingClient(client string) {
+	for i, waiting := range l.waitingClients {
+		if waiting == client {
+			l.waitingClients = append(l.waitingClients[:i], l.waitingClients[i+1:]...)
+			return
+		}
+	}
+}
+
+func (l *requestLimiter) removeIdleClient(client string, c *clientRequests) {
+	if c.running == 0 && len(c.waiting) == 0 {
+		delete(l.clients, client)
+	}
+}
+
+func (l *requestLimiter) updateStats() {
+	stats.FilerRequestConcurrencyGauge.WithLabelValues("running").Set(float64(l.running))
+	stats.FilerRequestConcurrencyGauge.WithLabelValues("queued").Set(float64(l.queued))
+}
+
+// limitConcurrency wraps the handler with the concurrency limits
+func (fs *FilerServer) limitConcurrency(f func(w http.ResponseWriter, r *http.Request)) func(w http.ResponseWriter, r *http.Request) {
+	if !fs.limiter.isEnabled() {
+		return f
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		client := fs.limiter.clientId(r)
+		if err := fs.limiter.acquire(r, client); err != nil {
+			if err == errRequestQueueFull {
+				glog.V(1).Infof("reject %s %s from %s: %v", r.Method, r.URL.Path, client, err)
+				stats.FilerRequestCounter.WithLabelValues("rejected").Inc()
+				w.Header().Set("Retry-After", "1")
+				writeJsonError(w, r, http.StatusServiceUnavailable, err)
+			}
+			return
+		}
+		defer fs.limiter.release(client)
+		f(w, r)
+	}
+}
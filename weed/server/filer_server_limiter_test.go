@@ -0,0 +1,89 @@
+package weed_server
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newLimiterTestRequest(ctx context.Context) *http.Request {
+	r, _ := http.NewRequest("GET", "http://localhost:8888/", nil)
+	return r.WithContext(ctx)
+}
+
+func waitForQueued(l *requestLimiter, count int) {
+	for {
+		l.Lock()
+		queued := l.queued
+		l.Unlock()
+		if queued >= count {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRequestLimiterTakesTurns(t *testing.T) {
+	l := newRequestLimiter(1, 0, 10, "")
+	ctx := context.Background()
+
+	if err := l.acquire(newLimiterTestRequest(ctx), "a"); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	// "a" queues 2 requests before "b" queues 1
+	started := make(chan string, 3)
+	for i, client := range []string{"a", "a", "b"} {
+		go func(client string) {
+			if err := l.acquire(newLimiterTestRequest(ctx), client); err == nil {
+				started <- client
+			}
+		}(client)
+		waitForQueued(l, i+1)
+	}
+
+	var order []string
+	client := "a"
+	for i := 0; i < 3; i++ {
+		l.release(client)
+		client = <-started
+		order = append(order, client)
+	}
+	if order[0] != "a" || order[1] != "b" || order[2] != "a" {
+		t.Errorf("clients started in order %v", order)
+	}
+}
+
+func TestRequestLimiterQueueFull(t *testing.T) {
+	l := newRequestLimiter(0, 1, 1, "")
+	ctx, cancel := context.WithCancel(context.Background())
+
+	if err := l.acquire(newLimiterTestRequest(ctx), "a"); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	// the other clients are not limited by "a"
+	if err := l.acquire(newLimiterTestRequest(ctx), "b"); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	waited := make(chan error)
+	go func() {
+		waited <- l.acquire(newLimiterTestRequest(ctx), "a")
+	}()
+	waitForQueued(l, 1)
+
+	if err := l.acquire(newLimiterTestRequest(ctx), "a"); err != errRequestQueueFull {
+		t.Errorf("acquire beyond the queue: %v", err)
+	}
+
+	cancel()
+	if err := <-waited; err == nil {
+		t.Errorf("the canceled request is started")
+	}
+	l.release("a")
+	l.release("b")
+	if len(l.clients) != 0 || l.running != 0 || l.queued != 0 {
+		t.Errorf("limiter is not idle: %+v", l)
+	}
+}
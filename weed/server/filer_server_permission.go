@@ -0,0 +1,121 @@
+package weed_server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/security"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// identityVerifier decides whose identity claims are believed, configured in security.toml
+type identityVerifier struct {
+	signingKey     security.SigningKey
+	trustedProxies []string
+}
+
+func (v *identityVerifier) decodeJwt(token string) (*filer2.Identity, error) {
+	claims, err := security.DecodeIdentityJwt(v.signingKey, security.EncodedJwt(token))
+	if err != nil {
+		return nil, &filer2.PermissionDeniedError{FullPath: "/", Reason: fmt.Sprintf("invalid %s: %v", filer2.IdentityJwtHeader, err)}
+	}
+	return filer2.NewIdentity(claims.Uid, claims.Gids), nil
+}
+
+// fromHttp trusts the uid and gids headers only from the trusted proxies, by the connected address
+// instead of any forwarded header, and requires the other callers to sign their identities.
+func (v *identityVerifier) fromHttp(r *http.Request) (*filer2.Identity, error) {
+	if token := r.Header.Get(filer2.IdentityJwtHeader); token != "" {
+		return v.decodeJwt(token)
+	}
+	uid, gids := r.Header.Get(filer2.IdentityUidHeader), r.Header.Get(filer2.IdentityGidsHeader)
+	if uid == "" && gids == "" {
+		return filer2.Nobody(), nil
+	}
+	host, _, _ := net.SplitHostPort(r.RemoteAddr)
+	if !security.MatchWhiteList(v.trustedProxies, host) {
+		return nil, &filer2.PermissionDeniedError{FullPath: "/", Reason: fmt.Sprintf("%s from untrusted %s", filer2.IdentityUidHeader, host)}
+	}
+	return filer2.ParseIdentity(uid, gids)
+}
+
+// fromGrpc trusts the uid and gids metadata only from the clients verified by the mutual tls
+func (v *identityVerifier) fromGrpc(ctx context.Context) (*filer2.Identity, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if token := firstMetadata(md, filer2.IdentityJwtHeader); token != "" {
+		return v.decodeJwt(token)
+	}
+	uid, gids := firstMetadata(md, filer2.IdentityUidHeader), firstMetadata(md, filer2.IdentityGidsHeader)
+	if uid == "" && gids == "" {
+		return filer2.Nobody(), nil
+	}
+	if !isVerifiedTlsPeer(ctx) {
+		return nil, &filer2.PermissionDeniedError{FullPath: "/", Reason: fmt.Sprintf("%s from a client without the mutual tls", filer2.IdentityUidHeader)}
+	}
+	return filer2.ParseIdentity(uid, gids)
+}
+
+func isVerifiedTlsPeer(ctx context.Context) bool {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return false
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	return ok && len(tlsInfo.State.VerifiedChains) > 0
+}
+
+// httpIdentity is the caller of the http request, or nil if the permissions are not enforced.
+func (fs *FilerServer) httpIdentity(r *http.Request) (*filer2.Identity, error) {
+	if !fs.option.EnforcePermissions {
+		return nil, nil
+	}
+	return fs.identityVerifier.fromHttp(r)
+}
+
+// grpcIdentity is the caller of the grpc request, from the metadata, or nil if the permissions are not enforced
+func (fs *FilerServer) grpcIdentity(ctx context.Context) (*filer2.Identity, error) {
+	if !fs.option.EnforcePermissions {
+		return nil, nil
+	}
+	return fs.identityVerifier.fromGrpc(ctx)
+}
+
+func firstMetadata(md metadata.MD, key string) string {
+	if values := md.Get(strings.ToLower(key)); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// checkUploadedEntry checks the permission to write the uploaded file, which may be named by the upload,
+// and sets its owner
+func (fs *FilerServer) checkUploadedEntry(ctx context.Context, r *http.Request, entry *filer2.Entry) error {
+	id, err := fs.httpIdentity(r)
+	if err != nil || id == nil {
+		return err
+	}
+	existingEntry, err := fs.filer.CheckWrite(ctx, id, entry.FullPath)
+	if err != nil {
+		return err
+	}
+	setNewEntryOwner(id, existingEntry, &entry.Attr)
+	return nil
+}
+
+// setNewEntryOwner makes the new entry owned by the caller, and keeps the owner and mode of the overwritten entry
+func setNewEntryOwner(id *filer2.Identity, existingEntry *filer2.Entry, attr *filer2.Attr) {
+	if id == nil {
+		return
+	}
+	if existingEntry != nil {
+		attr.Uid, attr.Gid, attr.Mode = existingEntry.Uid, existingEntry.Gid, existingEntry.Mode
+		return
+	}
+	attr.Uid, attr.Gid = id.Uid, id.Gid()
+}
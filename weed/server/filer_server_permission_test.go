@@ -0,0 +1,78 @@
+package weed_server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/security"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestHttpIdentity(t *testing.T) {
+	v := &identityVerifier{signingKey: security.SigningKey("secret"), trustedProxies: []string{"10.0.0.0/8"}}
+
+	request := func(remoteAddr string, headers ...string) *identityResult {
+		r := httptest.NewRequest("GET", "/dir/file", nil)
+		r.RemoteAddr = remoteAddr
+		for i := 0; i+1 < len(headers); i += 2 {
+			r.Header.Set(headers[i], headers[i+1])
+		}
+		id, err := v.fromHttp(r)
+		return &identityResult{id, err}
+	}
+
+	// the spoofed root is rejected, even claiming to be forwarded by the trusted proxy
+	if res := request("192.168.1.5:3456", filer2.IdentityUidHeader, "0", "X-Forwarded-For", "10.0.0.1"); !filer2.IsPermissionDenied(res.err) {
+		t.Errorf("spoofed uid header accepted: %+v", res)
+	}
+	if res := request("10.1.2.3:3456", filer2.IdentityUidHeader, "1000", filer2.IdentityGidsHeader, "100,20"); res.err != nil || res.id.Uid != 1000 || res.id.Gid() != 100 {
+		t.Errorf("uid header from the trusted proxy: %+v", res)
+	}
+	if res := request("192.168.1.5:3456"); res.err != nil || res.id.Uid != filer2.NobodyId {
+		t.Errorf("anonymous caller should be nobody: %+v", res)
+	}
+
+	signed := string(security.GenIdentityJwt(v.signingKey, 10, 1000, []uint32{100}))
+	if res := request("192.168.1.5:3456", filer2.IdentityJwtHeader, signed); res.err != nil || res.id.Uid != 1000 || res.id.Gid() != 100 {
+		t.Errorf("signed identity: %+v", res)
+	}
+	forged := string(security.GenIdentityJwt(security.SigningKey("other"), 10, 0, nil))
+	if res := request("10.1.2.3:3456", filer2.IdentityJwtHeader, forged); !filer2.IsPermissionDenied(res.err) {
+		t.Errorf("identity signed by another key accepted: %+v", res)
+	}
+}
+
+func TestGrpcIdentity(t *testing.T) {
+	v := &identityVerifier{}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("seaweed-uid", "0", "seaweed-gids", "0"))
+
+	if _, err := v.fromGrpc(ctx); !filer2.IsPermissionDenied(err) {
+		t.Errorf("spoofed uid metadata accepted without the mutual tls: %v", err)
+	}
+	insecurePeer := peer.NewContext(ctx, &peer.Peer{AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{}}})
+	if _, err := v.fromGrpc(insecurePeer); !filer2.IsPermissionDenied(err) {
+		t.Errorf("uid metadata accepted without a verified client certificate: %v", err)
+	}
+
+	verifiedPeer := peer.NewContext(ctx, &peer.Peer{AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+		VerifiedChains: [][]*x509.Certificate{{&x509.Certificate{}}},
+	}}})
+	if id, err := v.fromGrpc(verifiedPeer); err != nil || id.Uid != 0 {
+		t.Errorf("uid metadata from the mutual tls client: %+v %v", id, err)
+	}
+
+	if id, err := v.fromGrpc(context.Background()); err != nil || id.Uid != filer2.NobodyId {
+		t.Errorf("anonymous caller should be nobody: %+v %v", id, err)
+	}
+}
+
+type identityResult struct {
+	id  *filer2.Identity
+	err error
+}
@@ -0,0 +1,70 @@
+package weed_server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/stats"
+	"github.com/chrislusf/seaweedfs/weed/util"
+)
+
+var errRequestRateLimited = errors.New("too many requests, slow down")
+
+// the key of the total limits, as the client ids are never empty
+const totalRateLimitKey = ""
+
+// requestRateLimiter limits the http requests and the bytes per second, in total and by each client
+type requestRateLimiter struct {
+	total     *util.RateLimiters
+	perClient *util.RateLimiters
+}
+
+func newRequestRateLimiter(total, perClient util.RateLimit) *requestRateLimiter {
+	return &requestRateLimiter{
+		total:     util.NewRateLimiters(total, nil),
+		perClient: util.NewRateLimiters(perClient, nil),
+	}
+}
+
+func (l *requestRateLimiter) isEnabled() bool {
+	return l.total.IsEnabled() || l.perClient.IsEnabled()
+}
+
+// allowRequest checks the client limit first, so the requests over it do not take from the total
+func (l *requestRateLimiter) allowRequest(client string) bool {
+	return l.perClient.AllowRequest(client) && l.total.AllowRequest(totalRateLimitKey)
+}
+
+func (l *requestRateLimiter) waitBytes(client string) func(ctx context.Context, n int) error {
+	return func(ctx context.Context, n int) error {
+		if err := l.perClient.WaitBytes(ctx, client, n); err != nil {
+			return err
+		}
+		return l.total.WaitBytes(ctx, totalRateLimitKey, n)
+	}
+}
+
+// limitRate wraps the handler with the rate limits. The requests over the limit are rejected,
+// while the request and the response bodies are slowed down to the bytes per second.
+func (fs *FilerServer) limitRate(f func(w http.ResponseWriter, r *http.Request)) func(w http.ResponseWriter, r *http.Request) {
+	if !fs.rateLimiter.isEnabled() {
+		return f
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		client := fs.limiter.clientId(r)
+		if !fs.rateLimiter.allowRequest(client) {
+			glog.V(1).Infof("reject %s %s from %s: %v", r.Method, r.URL.Path, client, errRequestRateLimited)
+			stats.FilerRequestCounter.WithLabelValues("rateLimited").Inc()
+			w.Header().Set("Retry-After", "1")
+			writeJsonError(w, r, http.StatusTooManyRequests, errRequestRateLimited)
+			return
+		}
+		wait := fs.rateLimiter.waitBytes(client)
+		if r.Body != nil {
+			r.Body = util.NewRateLimitedBody(r.Context(), r.Body, wait)
+		}
+		f(util.NewRateLimitedResponseWriter(r.Context(), w, wait), r)
+	}
+}
@@ -0,0 +1,66 @@
+package weed_server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/util"
+)
+
+func TestRequestRateLimiterPerClient(t *testing.T) {
+	l := newRequestRateLimiter(util.RateLimit{RequestsPerSecond: 5}, util.RateLimit{RequestsPerSecond: 2})
+
+	// "a" is limited by its own limit, and does not starve "b"
+	for i := 0; i < 2; i++ {
+		if !l.allowRequest("a") {
+			t.Fatalf("request %d of a should be allowed", i)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if l.allowRequest("a") {
+			t.Fatalf("request %d of a should be over its limit", i+2)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if !l.allowRequest("b") {
+			t.Fatalf("request %d of b should be allowed", i)
+		}
+	}
+
+	// the total limit is shared by all clients
+	if !l.allowRequest("c") {
+		t.Fatalf("request of c should be allowed")
+	}
+	if l.allowRequest("d") {
+		t.Fatalf("request of d should be over the total limit")
+	}
+}
+
+func TestRequestRateLimiterWaitBytes(t *testing.T) {
+	l := newRequestRateLimiter(util.RateLimit{}, util.RateLimit{BytesPerSecond: 1000})
+	wait := l.waitBytes("a")
+	ctx := context.Background()
+
+	// the burst of one second is not waited for
+	start := time.Now()
+	if err := wait(ctx, 1000); err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("the burst waited %v", elapsed)
+	}
+
+	if err := wait(ctx, 200); err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("200 bytes over the limit waited only %v", elapsed)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := wait(canceled, 1000); err != context.Canceled {
+		t.Errorf("wait with the canceled context: %v", err)
+	}
+}
@@ -11,7 +11,7 @@ type Breadcrumb struct {
 }
 
 func ToBreadcrumb(fullpath string) (crumbs []Breadcrumb) {
-	parts := strings.Split(fullpath, "/")
+	parts := strings.Split(strings.TrimRight(fullpath, "/"), "/")
 
 	for i := 0; i < len(parts); i++ {
 		crumbs = append(crumbs, Breadcrumb{
@@ -1,12 +1,30 @@
 package master_ui
 
 import (
-	"github.com/dustin/go-humanize"
+	"fmt"
 	"html/template"
+	"strings"
+
+	"github.com/dustin/go-humanize"
 )
 
 var funcMap = template.FuncMap{
 	"humanizeBytes": humanize.Bytes,
+	"sortLink":      sortLink,
+	"joinPath":      joinPath,
+}
+
+// joinPath joins the directory and the name with one slash, also for the root directory
+func joinPath(dir, name string) string {
+	return strings.TrimRight(dir, "/") + "/" + name
+}
+
+// sortLink sorts by the column, and toggles the order if already sorted by it
+func sortLink(sortBy string, desc bool, column string) string {
+	if sortBy == "" {
+		sortBy = "name"
+	}
+	return fmt.Sprintf("?sortBy=%s&desc=%v", column, sortBy == column && !desc)
 }
 
 var StatusTpl = template.Must(template.New("status").Funcs(funcMap).Parse(`<!DOCTYPE html>
@@ -37,10 +55,33 @@ var StatusTpl = template.Must(template.New("status").Funcs(funcMap).Parse(`<!DOC
 #fileElem {
   display: none;
 }
+.actions a {
+  margin-left: 8px;
+  cursor: pointer;
+}
+#preview {
+  display: none;
+  position: fixed;
+  top: 5%;
+  left: 10%;
+  width: 80%;
+  max-height: 90%;
+  overflow: auto;
+  background: #fff;
+  border: 1px solid #ccc;
+  padding: 10px;
+  z-index: 10;
+}
+#preview img, #preview video {
+  max-width: 100%;
+}
+#preview pre {
+  max-height: 70vh;
+}
 </style>
 </head>
 <body>
-	<div class="container">
+	<div class="container" id="filer" data-path="{{ .Path }}" data-limit="{{ .Limit }}" data-sort-by="{{ .SortBy }}" data-desc="{{ .Desc }}">
 		<div class="page-header">
 			<h1>
 				<a href="https://github.com/chrislusf/seaweedfs"><img src="/seaweedfsstatic/seaweed50x50.png"></img></a>
@@ -56,6 +97,7 @@ var StatusTpl = template.Must(template.New("status").Funcs(funcMap).Parse(`<!DOC
 			{{ end }}
 				<label class="button" for="fileElem">Upload</label>
 			</div>
+			<div id="uploads"></div>
 		</div>
 
 		<div class="row" id="drop-area">
@@ -63,17 +105,27 @@ var StatusTpl = template.Must(template.New("status").Funcs(funcMap).Parse(`<!DOC
 				<input type="file" id="fileElem" multiple onchange="handleFiles(this.files)">
 
 			<table width="90%">
+				<thead>
+				<tr>
+					<th><a href="{{ sortLink .SortBy .Desc "name" }}">Name</a></th>
+					<th></th>
+					<th style="text-align: right"><a href="{{ sortLink .SortBy .Desc "size" }}">Size</a></th>
+					<th><a href="{{ sortLink .SortBy .Desc "mtime" }}">Modified</a></th>
+					<th></th>
+				</tr>
+				</thead>
+				<tbody id="entries">
 				{{$path := .Path }}
 				{{ range $entry_index, $entry := .Entries }}
-				<tr>
+				<tr data-name="{{ $entry.Name }}" data-directory="{{ $entry.IsDirectory }}" data-mime="{{ $entry.Mime }}">
 					<td>
 					{{if $entry.IsDirectory}}
 						<img src="/seaweedfsstatic/images/folder.gif" width="20" height="23">
-						<a href={{ print $path  "/" $entry.Name  "/"}} >
+						<a href={{ print (joinPath $path $entry.Name) "/" }} >
 							{{ $entry.Name }}
 						</a>
 					{{else}}
-						<a href={{ print $path  "/" $entry.Name }} >
+						<a href={{ joinPath $path $entry.Name }} onclick="return preview(this.closest('tr'))">
 							{{ $entry.Name }}
 						</a>
 					{{end}}
@@ -94,29 +146,197 @@ var StatusTpl = template.Must(template.New("status").Funcs(funcMap).Parse(`<!DOC
 					<td>
 						{{ $entry.Timestamp.Format "2006-01-02 15:04" }}
 					</td>
+					<td class="actions">
+						<a onclick="renameEntry(this.closest('tr'))">Rename</a>
+						<a onclick="deleteEntry(this.closest('tr'))">Delete</a>
+					</td>
 				</tr>
 				{{ end }}
-
+				</tbody>
 			</table>
 			</form>
 		</div>
 
-		{{if .ShouldDisplayLoadMore}}
-		<div class="row">
-		<a href={{ print .Path "?limit=" .Limit	"&lastFileName=" .LastFileName}} >
+		<div class="row" id="load-more" {{if not .ShouldDisplayLoadMore}}style="display: none"{{end}}>
+		<a href={{ print (joinPath .Path "") "?limit=" .Limit "&lastFileName=" .LastFileName .SortQuery}} data-last-file-name="{{ .LastFileName }}" onclick="return loadMore(this)">
 		Load more
 		</a>
 		</div>
-		{{end}}
+	</div>
+
+	<div id="preview">
+		<a class="button" onclick="closePreview()">Close</a>
+		<h4 id="preview-name"></h4>
+		<div id="preview-content"></div>
 	</div>
 </body>
 <script type="text/javascript">
+let filer = document.getElementById("filer")
+// without the trailing slash, so the root directory is ""
+let dirPath = filer.dataset.path.replace(/\/+$/, "")
+
+function entryPath(name) {
+  return dirPath + "/" + encodeURIComponent(name)
+}
+
+// ************************ Pagination ***************** //
+function loadMore(link) {
+  var params = new URLSearchParams()
+  params.set("limit", filer.dataset.limit)
+  params.set("lastFileName", link.dataset.lastFileName)
+  params.set("fields", "attributes")
+  if (filer.dataset.sortBy) {
+    params.set("sortBy", filer.dataset.sortBy)
+    params.set("desc", filer.dataset.desc)
+  }
+  fetch(dirPath + "/?" + params.toString(), {headers: {"Accept": "application/json"}})
+    .then(resp => resp.json())
+    .then(listing => {
+      let tbody = document.getElementById("entries")
+      ;(listing.Entries || []).forEach(entry => tbody.appendChild(entryRow(entry)))
+      link.dataset.lastFileName = listing.LastFileName
+      document.getElementById("load-more").style.display = listing.ShouldDisplayLoadMore ? "" : "none"
+    })
+    .catch(err => alert("load more: " + err))
+  return false
+}
+
+function entryRow(entry) {
+  let tr = document.createElement("tr")
+  tr.dataset.name = entry.Name
+  tr.dataset.directory = entry.IsDirectory
+  tr.dataset.mime = entry.Mime || ""
+
+  let nameCell = document.createElement("td")
+  let a = document.createElement("a")
+  a.textContent = entry.Name
+  if (entry.IsDirectory) {
+    let img = document.createElement("img")
+    img.src = "/seaweedfsstatic/images/folder.gif"
+    img.width = 20
+    img.height = 23
+    nameCell.appendChild(img)
+    a.href = entryPath(entry.Name) + "/"
+  } else {
+    a.href = entryPath(entry.Name)
+    a.onclick = () => preview(tr)
+  }
+  nameCell.appendChild(a)
+  tr.appendChild(nameCell)
+
+  let timestamp = new Date(entry.IsDirectory ? entry.Crtime : entry.Mtime)
+  ;[entry.IsDirectory ? "" : entry.Mime, entry.IsDirectory ? "" : humanizeBytes(entry.Size), timestamp.toISOString().substring(0, 16).replace("T", " ")].forEach((text, i) => {
+    let td = document.createElement("td")
+    td.textContent = text
+    if (i < 2) {
+      td.align = "right"
+    }
+    tr.appendChild(td)
+  })
+
+  let actions = document.createElement("td")
+  actions.className = "actions"
+  ;[["Rename", renameEntry], ["Delete", deleteEntry]].forEach(([text, fn]) => {
+    let action = document.createElement("a")
+    action.textContent = text
+    action.onclick = () => fn(tr)
+    actions.appendChild(action)
+  })
+  tr.appendChild(actions)
+  return tr
+}
+
+function humanizeBytes(size) {
+  let units = ["B", "kB", "MB", "GB", "TB", "PB"]
+  let i = 0
+  while (size >= 1000 && i < units.length - 1) {
+    size /= 1000
+    i++
+  }
+  return (i == 0 ? size : size.toFixed(1)) + " " + units[i]
+}
+
+// ************************ Rename and delete ***************** //
+function renameEntry(tr) {
+  let name = tr.dataset.name
+  let target = prompt("Rename or move " + name + " to", dirPath + "/" + name)
+  if (!target || target == dirPath + "/" + name) {
+    return
+  }
+  if (!target.startsWith("/")) {
+    target = dirPath + "/" + target
+  }
+  let url = target.split("/").map(encodeURIComponent).join("/") + "?op=move&from=" + encodeURIComponent(dirPath + "/" + name)
+  fetch(url, {method: "POST"}).then(checkResponse).then(() => window.location.reload()).catch(err => alert("rename " + name + ": " + err))
+}
+
+function deleteEntry(tr) {
+  let name = tr.dataset.name
+  let isDirectory = tr.dataset.directory == "true"
+  if (!confirm("Delete " + name + (isDirectory ? " and everything in it" : "") + "?")) {
+    return
+  }
+  fetch(entryPath(name) + (isDirectory ? "?recursive=true" : ""), {method: "DELETE"})
+    .then(checkResponse).then(() => tr.remove()).catch(err => alert("delete " + name + ": " + err))
+}
+
+function checkResponse(resp) {
+  if (!resp.ok) {
+    return resp.text().then(text => { throw new Error(resp.status + " " + text) })
+  }
+  return resp
+}
+
+// ************************ Preview ***************** //
+// the text preview reads only the beginning of the file, and the media elements read by ranges themselves
+const previewTextBytes = 64 * 1024
+
+function preview(tr) {
+  let name = tr.dataset.name
+  let mime = tr.dataset.mime || ""
+  let url = entryPath(name)
+  let content = document.getElementById("preview-content")
+  let element
+  if (mime.startsWith("image/")) {
+    element = document.createElement("img")
+    element.src = url
+  } else if (mime.startsWith("video/") || mime.startsWith("audio/")) {
+    element = document.createElement(mime.startsWith("video/") ? "video" : "audio")
+    element.controls = true
+    element.preload = "metadata"
+    element.src = url
+  } else if (mime.startsWith("text/") || mime == "application/json" || mime == "") {
+    element = document.createElement("pre")
+    fetch(url, {headers: {"Range": "bytes=0-" + (previewTextBytes - 1)}})
+      .then(checkResponse)
+      .then(resp => resp.text().then(text => {
+        element.textContent = text
+        if (resp.status == 206) {
+          element.textContent += "\n..."
+        }
+      }))
+      .catch(err => element.textContent = "preview: " + err)
+  } else {
+    return true
+  }
+  content.innerHTML = ""
+  content.appendChild(element)
+  document.getElementById("preview-name").textContent = name
+  document.getElementById("preview").style.display = "block"
+  return false
+}
+
+function closePreview() {
+  document.getElementById("preview").style.display = "none"
+  document.getElementById("preview-content").innerHTML = ""
+}
+
 // ************************ Drag and drop ***************** //
 let dropArea = document.getElementById("drop-area")
 
 // Prevent default drag behaviors
 ;['dragenter', 'dragover', 'dragleave', 'drop'].forEach(eventName => {
-  dropArea.addEventListener(eventName, preventDefaults, false)   
+  dropArea.addEventListener(eventName, preventDefaults, false)
   document.body.addEventListener(eventName, preventDefaults, false)
 })
 
@@ -154,18 +374,35 @@ function handleDrop(e) {
 
 function handleFiles(files) {
   files = [...files]
-  files.forEach(uploadFile)
-  window.location.reload()
+  Promise.all(files.map(uploadFile)).then(results => {
+    if (results.every(ok => ok)) {
+      window.location.reload()
+    }
+  })
 }
 
-function uploadFile(file, i) {
-  var url = window.location.href
-  var xhr = new XMLHttpRequest()
-  var formData = new FormData()
-  xhr.open('POST', url, false)
-
-  formData.append('file', file)
-  xhr.send(formData)
+// uploadFile uploads in the background, showing the progress
+function uploadFile(file) {
+  let progress = document.createElement("div")
+  progress.textContent = file.name + ": 0%"
+  document.getElementById("uploads").appendChild(progress)
+  return new Promise(resolve => {
+    var xhr = new XMLHttpRequest()
+    var formData = new FormData()
+    xhr.open('POST', dirPath + "/", true)
+    xhr.upload.onprogress = e => {
+      if (e.lengthComputable) {
+        progress.textContent = file.name + ": " + Math.round(e.loaded * 100 / e.total) + "%"
+      }
+    }
+    xhr.onloadend = () => {
+      let ok = xhr.status >= 200 && xhr.status < 300
+      progress.textContent = file.name + ": " + (ok ? "done" : "failed " + xhr.status + " " + xhr.responseText)
+      resolve(ok)
+    }
+    formData.append('file', file)
+    xhr.send(formData)
+  })
 }
 </script>
 </html>
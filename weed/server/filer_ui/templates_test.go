@@ -0,0 +1,74 @@
+package master_ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testEntry struct {
+	Name        string
+	IsDirectory bool
+	Mime        string
+	Size        uint64
+	Timestamp   time.Time
+}
+
+func TestStatusTplLinks(t *testing.T) {
+	for _, dir := range []string{"", "/", "/photos", "/photos/"} {
+		var buf bytes.Buffer
+		err := StatusTpl.Execute(&buf, map[string]interface{}{
+			"Path":        dir,
+			"Breadcrumbs": ToBreadcrumb(dir),
+			"Entries": []testEntry{
+				{Name: "cats", IsDirectory: true},
+				{Name: "cat.jpg", Mime: "image/jpeg", Size: 1024},
+			},
+			"Limit":                 100,
+			"LastFileName":          "cat.jpg",
+			"ShouldDisplayLoadMore": true,
+			"SortQuery":             "",
+			"SortBy":                "",
+			"Desc":                  false,
+		})
+		if err != nil {
+			t.Fatalf("execute %q: %v", dir, err)
+		}
+		page := buf.String()
+		prefix := strings.TrimRight(dir, "/")
+		for _, link := range []string{
+			"href=" + prefix + "/cats/ ",
+			"href=" + prefix + "/cat.jpg ",
+			"href=" + prefix + "/?limit&#61;100&amp;lastFileName&#61;cat.jpg ",
+		} {
+			if !strings.Contains(page, link) {
+				t.Errorf("directory %q: missing %s", dir, link)
+			}
+		}
+		if strings.Contains(page, "href=//") {
+			t.Errorf("directory %q: link with double slashes", dir)
+		}
+	}
+}
+
+func TestToBreadcrumb(t *testing.T) {
+	for dir, expected := range map[string][]Breadcrumb{
+		"":      {{Name: "/", Link: "/"}},
+		"/":     {{Name: "/", Link: "/"}},
+		"/a/b":  {{Name: "/", Link: "/"}, {Name: "a/", Link: "/a"}, {Name: "b/", Link: "/a/b"}},
+		"/a/b/": {{Name: "/", Link: "/"}, {Name: "a/", Link: "/a"}, {Name: "b/", Link: "/a/b"}},
+	} {
+		crumbs := ToBreadcrumb(dir)
+		if len(crumbs) != len(expected) {
+			t.Errorf("%q: %+v", dir, crumbs)
+			continue
+		}
+		for i := range crumbs {
+			if crumbs[i] != expected[i] {
+				t.Errorf("%q: %+v", dir, crumbs)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,366 @@
+package weed_server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/operation"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
+	"github.com/chrislusf/seaweedfs/weed/security"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+	"github.com/chrislusf/seaweedfs/weed/util"
+	"github.com/chrislusf/seaweedfs/weed/wdclient"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+)
+
+type KeyMapOption struct {
+	Masters           []string
+	Collection        string
+	Replication       string
+	DataCenter        string
+	RedirectOnRead    bool
+	DefaultLevelDbDir string
+}
+
+// KeyMapDirectory keeps the keys apart from the filer entries, if the filer store is shared with a filer
+const KeyMapDirectory = "/.keymap"
+
+// KeyMapServer maps the keys to the file ids of the blobs on the volume servers,
+// kept as one entry with one chunk for each key in a filer store, without the directories of the filer.
+// The blobs are kept in the collection of the key map server, not used by the filer,
+// so that neither deletes the blobs of the other.
+type KeyMapServer struct {
+	// serializes the mapping changes, so an overwritten blob is deleted only once
+	sync.Mutex
+	option         *KeyMapOption
+	store          filer2.FilerStore
+	masterClient   *wdclient.MasterClient
+	grpcDialOption grpc.DialOption
+	// checks the volume is in the collection, replaced in tests
+	lookupVolume func(vid string, collection string) error
+}
+
+type KeyMapResult struct {
+	Key   string    `json:"key"`
+	Fid   string    `json:"fid"`
+	Size  uint64    `json:"size,omitempty"`
+	Mtime time.Time `json:"mtime"`
+	Mime  string    `json:"mime,omitempty"`
+}
+
+// NewKeyMapServer loads the store from "keymap.toml", in the same format as "filer.toml",
+// or else keeps the keys in a local leveldb.
+func NewKeyMapServer(mux *http.ServeMux, option *KeyMapOption) (ks *KeyMapServer, err error) {
+
+	if len(option.Masters) == 0 {
+		glog.Fatal("master list is required!")
+	}
+	if option.Collection == "" {
+		return nil, fmt.Errorf("the key map server needs its own collection")
+	}
+
+	ks = &KeyMapServer{
+		option:         option,
+		grpcDialOption: security.LoadClientTLS(viper.Sub("grpc"), "client"),
+	}
+	ks.lookupVolume = ks.lookupCollectionVolume
+
+	v := viper.GetViper()
+	if !util.LoadConfiguration("keymap", false) {
+		v.Set("leveldb2.enabled", true)
+		v.Set("leveldb2.dir", option.DefaultLevelDbDir)
+		if _, err := os.Stat(option.DefaultLevelDbDir); os.IsNotExist(err) {
+			os.MkdirAll(option.DefaultLevelDbDir, 0755)
+		}
+	}
+	ks.store = filer2.NewFilerStoreWrapper(filer2.LoadStore(v))
+
+	ks.masterClient = wdclient.NewMasterClient(context.Background(), ks.grpcDialOption, "keymap", option.Masters)
+	go ks.masterClient.KeepConnectedToMaster()
+
+	mux.HandleFunc("/", ks.keyMapHandler)
+
+	return ks, nil
+}
+
+// keyMapHandler serves the blobs by the keys in the request path.
+// curl -X PUT --data-binary @photo.jpg "http://localhost:8555/photos/2019/cat.jpg"
+// curl -X PUT "http://localhost:8555/photos/2019/dog.jpg?fid=3,01637037d6"
+// curl "http://localhost:8555/photos/2019/cat.jpg?meta=true"
+// curl -X DELETE "http://localhost:8555/photos/2019/cat.jpg"
+func (ks *KeyMapServer) keyMapHandler(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	if key == "" || strings.HasSuffix(key, "/") {
+		writeJsonError(w, r, http.StatusBadRequest, fmt.Errorf("invalid key %q", key))
+		return
+	}
+	switch r.Method {
+	case "GET", "HEAD":
+		ks.getHandler(w, r, key)
+	case "PUT", "POST":
+		ks.putHandler(w, r, key)
+	case "DELETE":
+		ks.deleteHandler(w, r, key)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (ks *KeyMapServer) getHandler(w http.ResponseWriter, r *http.Request, key string) {
+	ctx := context.Background()
+	entry, err := ks.store.FindEntry(ctx, keyMapPath(key))
+	if err == filer2.ErrNotFound {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		writeJsonError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	if len(entry.Chunks) == 0 {
+		writeJsonError(w, r, http.StatusInternalServerError, fmt.Errorf("key %s has no file id", key))
+		return
+	}
+	fileId := entry.Chunks[0].GetFileIdString()
+
+	if r.URL.Query().Get("meta") == "true" {
+		writeJsonQuiet(w, r, http.StatusOK, keyMapResult(key, entry))
+		return
+	}
+
+	urlStrings, err := ks.masterClient.LookupFileIdUrls(fileId)
+	if err != nil {
+		glog.V(1).Infof("lookup %s of key %s: %v", fileId, key, err)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if ks.option.RedirectOnRead {
+		http.Redirect(w, r, urlStrings[0], http.StatusFound)
+		return
+	}
+
+	// fall back to the next location if the volume server is down, or fails to read the needle
+	var resp *http.Response
+	for i, urlString := range urlStrings {
+		request, _ := http.NewRequest(r.Method, urlString, nil)
+		for _, name := range []string{"Range", "If-Match", "If-None-Match", "If-Modified-Since", "If-Unmodified-Since"} {
+			if value := r.Header.Get(name); value != "" {
+				request.Header.Set(name, value)
+			}
+		}
+		var doErr error
+		resp, doErr = util.Do(request)
+		isLast := i == len(urlStrings)-1
+		if doErr != nil {
+			glog.V(0).Infof("read %s of key %s: %v", fileId, key, doErr)
+			if isLast {
+				writeJsonError(w, r, http.StatusInternalServerError, doErr)
+				return
+			}
+			continue
+		}
+		if !isLast && (resp.StatusCode == http.StatusNotFound || resp.StatusCode >= http.StatusInternalServerError) {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+			continue
+		}
+		break
+	}
+	defer func() {
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+	for k, v := range resp.Header {
+		w.Header()[k] = v
+	}
+	if entry.Mime != "" {
+		w.Header().Set("Content-Type", entry.Mime)
+	}
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
+}
+
+// putHandler uploads the request body as a new blob, or maps the key to an existing blob with "fid",
+// which must be in the collection of the key map server. The blob mapped by the key before is deleted.
+func (ks *KeyMapServer) putHandler(w http.ResponseWriter, r *http.Request, key string) {
+	ctx := context.Background()
+	query := r.URL.Query()
+
+	chunk := &filer_pb.FileChunk{
+		FileId: query.Get("fid"),
+		Mtime:  time.Now().UnixNano(),
+	}
+	mimeType := r.Header.Get("Content-Type")
+	if chunk.FileId != "" {
+		fid, err := needle.ParseFileIdFromString(chunk.FileId)
+		if err != nil {
+			writeJsonError(w, r, http.StatusBadRequest, fmt.Errorf("invalid fid %s: %v", chunk.FileId, err))
+			return
+		}
+		// the blobs of other collections could be referenced by the filer, and deleted with the key
+		if err = ks.lookupVolume(fid.VolumeId.String(), ks.option.Collection); err != nil {
+			writeJsonError(w, r, http.StatusForbidden, fmt.Errorf("fid %s is not in collection %s: %v", chunk.FileId, ks.option.Collection, err))
+			return
+		}
+		chunk.Size = uint64(util.ParseInt(query.Get("size"), 0))
+	} else {
+		replication, dataCenter := query.Get("replication"), query.Get("dataCenter")
+		if replication == "" {
+			replication = ks.option.Replication
+		}
+		if dataCenter == "" {
+			dataCenter = ks.option.DataCenter
+		}
+		assignResult, err := operation.Assign(ks.masterClient.GetMaster(), ks.grpcDialOption, &operation.VolumeAssignRequest{
+			Count:       1,
+			Replication: replication,
+			Collection:  ks.option.Collection,
+			Ttl:         query.Get("ttl"),
+			DataCenter:  dataCenter,
+		})
+		if err != nil {
+			writeJsonError(w, r, http.StatusInternalServerError, fmt.Errorf("assign file id: %v", err))
+			return
+		}
+		uploadUrl := "http://" + assignResult.Url + "/" + assignResult.Fid
+		name := key[strings.LastIndex(key, "/")+1:]
+		uploadResult, err := operation.Upload(uploadUrl, name, r.Body, false, mimeType, nil, assignResult.Auth)
+		if err == nil && uploadResult.Error != "" {
+			err = errors.New(uploadResult.Error)
+		}
+		if err != nil {
+			writeJsonError(w, r, http.StatusInternalServerError, fmt.Errorf("upload %s: %v", key, err))
+			return
+		}
+		chunk.FileId, chunk.Size = assignResult.Fid, uint64(uploadResult.Size)
+	}
+
+	entry := &filer2.Entry{
+		FullPath: keyMapPath(key),
+		Attr: filer2.Attr{
+			Mtime:  time.Now(),
+			Crtime: time.Now(),
+			Mode:   0660,
+			Mime:   mimeType,
+		},
+		Chunks: []*filer_pb.FileChunk{chunk},
+	}
+
+	ks.Lock()
+	oldEntry, err := ks.store.FindEntry(ctx, entry.FullPath)
+	if err == nil {
+		entry.Crtime = oldEntry.Crtime
+		err = ks.store.UpdateEntry(ctx, entry)
+	} else if err == filer2.ErrNotFound {
+		oldEntry, err = nil, ks.store.InsertEntry(ctx, entry)
+	}
+	ks.Unlock()
+	if err != nil {
+		writeJsonError(w, r, http.StatusInternalServerError, fmt.Errorf("map %s: %v", key, err))
+		return
+	}
+	if oldEntry != nil {
+		ks.deleteBlobs(key, oldEntry, chunk.FileId)
+	}
+
+	writeJsonQuiet(w, r, http.StatusCreated, keyMapResult(key, entry))
+}
+
+func (ks *KeyMapServer) deleteHandler(w http.ResponseWriter, r *http.Request, key string) {
+	ctx := context.Background()
+
+	ks.Lock()
+	entry, err := ks.store.FindEntry(ctx, keyMapPath(key))
+	if err == nil {
+		err = ks.store.DeleteEntry(ctx, entry.FullPath)
+	}
+	ks.Unlock()
+	if err == filer2.ErrNotFound {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		writeJsonError(w, r, http.StatusInternalServerError, fmt.Errorf("delete %s: %v", key, err))
+		return
+	}
+	if r.URL.Query().Get("skipChunkDeletion") != "true" {
+		ks.deleteBlobs(key, entry, "")
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deleteBlobs deletes the blobs no longer mapped by the key, except the one mapped again
+func (ks *KeyMapServer) deleteBlobs(key string, entry *filer2.Entry, keepFileId string) {
+	var fileIds []string
+	for _, chunk := range entry.Chunks {
+		if fileId := chunk.GetFileIdString(); fileId != keepFileId {
+			fileIds = append(fileIds, fileId)
+		}
+	}
+	if len(fileIds) == 0 {
+		return
+	}
+	results, err := operation.DeleteFiles(ks.masterClient.GetMaster(), ks.grpcDialOption, fileIds)
+	if err != nil {
+		glog.V(0).Infof("delete %v of key %s: %v", fileIds, key, err)
+	}
+	for _, result := range results {
+		if result.Error != "" && result.Status != http.StatusNotFound {
+			glog.V(0).Infof("delete %s of key %s: status %d %s", result.FileId, key, result.Status, result.Error)
+		}
+	}
+}
+
+// lookupCollectionVolume asks the master whether the volume is in the collection
+func (ks *KeyMapServer) lookupCollectionVolume(vid string, collection string) error {
+	return operation.WithMasterServerClient(ks.masterClient.GetMaster(), ks.grpcDialOption, func(client master_pb.SeaweedClient) error {
+		resp, err := client.LookupVolume(context.Background(), &master_pb.LookupVolumeRequest{
+			VolumeIds:  []string{vid},
+			Collection: collection,
+		})
+		if err != nil {
+			return err
+		}
+		for _, location := range resp.VolumeIdLocations {
+			if location.Error != "" {
+				return errors.New(location.Error)
+			}
+			if location.VolumeId == vid && len(location.Locations) > 0 {
+				return nil
+			}
+		}
+		return fmt.Errorf("volume %s not found", vid)
+	})
+}
+
+// keyMapPath keeps the key as the path under KeyMapDirectory,
+// so the stores sharding by the directory spread the keys the same way
+func keyMapPath(key string) filer2.FullPath {
+	return filer2.FullPath(KeyMapDirectory + "/" + key)
+}
+
+func keyMapResult(key string, entry *filer2.Entry) *KeyMapResult {
+	result := &KeyMapResult{
+		Key:   key,
+		Mtime: entry.Mtime,
+		Mime:  entry.Mime,
+	}
+	if len(entry.Chunks) > 0 {
+		result.Fid = entry.Chunks[0].GetFileIdString()
+		result.Size = entry.Chunks[0].Size
+	}
+	return result
+}
@@ -0,0 +1,69 @@
+package weed_server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/filer2/memdb"
+)
+
+func newTestKeyMapServer(t *testing.T, volumeCollections map[string]string) *KeyMapServer {
+	store := &memdb.MemDbStore{}
+	if err := store.Initialize(nil); err != nil {
+		t.Fatalf("initialize store: %v", err)
+	}
+	return &KeyMapServer{
+		option: &KeyMapOption{Collection: "keymap"},
+		store:  store,
+		lookupVolume: func(vid string, collection string) error {
+			if volumeCollections[vid] != collection {
+				return fmt.Errorf("volumeId %s not found.", vid)
+			}
+			return nil
+		},
+	}
+}
+
+func TestKeyMapPath(t *testing.T) {
+	if path := keyMapPath("photos/cat.jpg"); path != "/.keymap/photos/cat.jpg" {
+		t.Errorf("key kept at %s", path)
+	}
+}
+
+func TestKeyMapPutFid(t *testing.T) {
+	ks := newTestKeyMapServer(t, map[string]string{"3": "keymap", "4": ""})
+
+	// the blobs of the filer are not mapped, so deleting the key does not delete them
+	w := httptest.NewRecorder()
+	ks.keyMapHandler(w, httptest.NewRequest("PUT", "/photos/dog.jpg?fid=4,01637037d6", nil))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("mapping the fid of another collection: status %d", w.Code)
+	}
+	if _, err := ks.store.FindEntry(context.Background(), keyMapPath("photos/dog.jpg")); err != filer2.ErrNotFound {
+		t.Fatalf("rejected key is mapped: %v", err)
+	}
+
+	w = httptest.NewRecorder()
+	ks.keyMapHandler(w, httptest.NewRequest("PUT", "/photos/cat.jpg?fid=3,01637037d6&size=10", nil))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("mapping the fid of the collection: status %d %s", w.Code, w.Body.String())
+	}
+	if _, err := ks.store.FindEntry(context.Background(), filer2.FullPath("/photos/cat.jpg")); err != filer2.ErrNotFound {
+		t.Errorf("key kept outside of %s: %v", KeyMapDirectory, err)
+	}
+
+	w = httptest.NewRecorder()
+	ks.keyMapHandler(w, httptest.NewRequest("GET", "/photos/cat.jpg?meta=true", nil))
+	var result KeyMapResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("read mapping: %v %s", err, w.Body.String())
+	}
+	if result.Key != "photos/cat.jpg" || result.Fid != "3,01637037d6" || result.Size != 10 {
+		t.Errorf("mapping %+v", result)
+	}
+}
@@ -0,0 +1,139 @@
+package weed_server
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+	"github.com/spf13/viper"
+)
+
+// EcEncodingPolicy decides when a volume is sealed and idle long enough to be erasure coded.
+type EcEncodingPolicy struct {
+	Enabled     bool
+	QuietFor    time.Duration
+	FullPercent float64
+}
+
+func (ms *MasterServer) startEcEncodingPolicy() {
+	v := viper.GetViper()
+	v.SetDefault("master.ec_encoding.sleep_minutes", 60)
+	sleepMinutes := v.GetInt("master.ec_encoding.sleep_minutes")
+
+	defaultPolicy, collectionPolicies := loadEcEncodingPolicies(v)
+	if !defaultPolicy.Enabled && len(collectionPolicies) == 0 {
+		return
+	}
+
+	glog.V(0).Infof("ec encoding policy: default %+v, collections %+v", defaultPolicy, collectionPolicies)
+
+	commandEnv := ms.newShellCommandEnv()
+
+	go commandEnv.MasterClient.KeepConnectedToMaster()
+
+	go func() {
+		commandEnv.MasterClient.WaitUntilConnected()
+
+		c := time.Tick(time.Duration(sleepMinutes) * time.Minute)
+		for _ = range c {
+			if !ms.Topo.IsLeader() {
+				continue
+			}
+			collectionVolumes := selectVolumesToEcEncode(ms.Topo.ToTopologyInfo(),
+				uint64(ms.option.VolumeSizeLimitMB)*1024*1024, defaultPolicy, collectionPolicies, time.Now())
+			for _, collection := range sortedCollections(collectionVolumes) {
+				for _, vid := range collectionVolumes[collection] {
+					if err := runShellCommand(commandEnv, "ec.encode", []string{
+						"-collection=" + collection,
+						fmt.Sprintf("-volumeId=%d", vid),
+					}); err != nil {
+						glog.Errorf("ec encode volume %d in collection %s: %v", vid, collection, err)
+					}
+				}
+			}
+		}
+	}()
+}
+
+func loadEcEncodingPolicies(v *viper.Viper) (defaultPolicy EcEncodingPolicy, collectionPolicies map[string]EcEncodingPolicy) {
+	v.SetDefault("master.ec_encoding.quiet_days", 7)
+	v.SetDefault("master.ec_encoding.full_percent", 95)
+
+	defaultPolicy = EcEncodingPolicy{
+		Enabled:     v.GetBool("master.ec_encoding.enabled"),
+		QuietFor:    time.Duration(v.GetFloat64("master.ec_encoding.quiet_days") * float64(24*time.Hour)),
+		FullPercent: v.GetFloat64("master.ec_encoding.full_percent"),
+	}
+
+	collectionPolicies = make(map[string]EcEncodingPolicy)
+	for collection := range v.GetStringMap("master.ec_encoding.collections") {
+		sub := v.Sub("master.ec_encoding.collections." + collection)
+		if sub == nil {
+			continue
+		}
+		sub.SetDefault("enabled", true)
+		sub.SetDefault("quiet_days", defaultPolicy.QuietFor.Hours()/24)
+		sub.SetDefault("full_percent", defaultPolicy.FullPercent)
+		collectionPolicies[collection] = EcEncodingPolicy{
+			Enabled:     sub.GetBool("enabled"),
+			QuietFor:    time.Duration(sub.GetFloat64("quiet_days") * float64(24*time.Hour)),
+			FullPercent: sub.GetFloat64("full_percent"),
+		}
+	}
+
+	return
+}
+
+// selectVolumesToEcEncode finds volumes that are sealed, i.e., read only or nearly full, and have no writes
+// for the quiet period on all replicas. Volumes with TTL are skipped since they expire by themselves.
+func selectVolumesToEcEncode(topo *master_pb.TopologyInfo, volumeSizeLimit uint64,
+	defaultPolicy EcEncodingPolicy, collectionPolicies map[string]EcEncodingPolicy, now time.Time) map[string][]needle.VolumeId {
+
+	candidates := make(map[uint32]bool)
+	collections := make(map[uint32]string)
+	for _, dc := range topo.DataCenterInfos {
+		for _, rack := range dc.RackInfos {
+			for _, dn := range rack.DataNodeInfos {
+				for _, v := range dn.VolumeInfos {
+					policy, found := collectionPolicies[v.Collection]
+					if !found {
+						policy = defaultPolicy
+					}
+					isSealed := v.ReadOnly || float64(v.Size) >= policy.FullPercent/100*float64(volumeSizeLimit)
+					isQuiet := v.ModifiedAtSecond+int64(policy.QuietFor/time.Second) < now.Unix()
+					isCandidate := policy.Enabled && v.Ttl == 0 && isSealed && isQuiet
+					if previous, seen := candidates[v.Id]; seen {
+						isCandidate = isCandidate && previous
+					}
+					candidates[v.Id] = isCandidate
+					collections[v.Id] = v.Collection
+				}
+			}
+		}
+	}
+
+	collectionVolumes := make(map[string][]needle.VolumeId)
+	for vid, isCandidate := range candidates {
+		if isCandidate {
+			collectionVolumes[collections[vid]] = append(collectionVolumes[collections[vid]], needle.VolumeId(vid))
+		}
+	}
+	for _, vids := range collectionVolumes {
+		sort.Slice(vids, func(i, j int) bool {
+			return vids[i] < vids[j]
+		})
+	}
+
+	return collectionVolumes
+}
+
+func sortedCollections(collectionVolumes map[string][]needle.VolumeId) (collections []string) {
+	for collection := range collectionVolumes {
+		collections = append(collections, collection)
+	}
+	sort.Strings(collections)
+	return
+}
@@ -0,0 +1,59 @@
+package weed_server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
+)
+
+func TestSelectVolumesToEcEncode(t *testing.T) {
+
+	now := time.Now()
+	longAgo := now.Add(-10 * 24 * time.Hour).Unix()
+	recently := now.Add(-time.Hour).Unix()
+
+	topo := &master_pb.TopologyInfo{
+		DataCenterInfos: []*master_pb.DataCenterInfo{{
+			RackInfos: []*master_pb.RackInfo{{
+				DataNodeInfos: []*master_pb.DataNodeInfo{
+					{
+						VolumeInfos: []*master_pb.VolumeInformationMessage{
+							{Id: 1, Size: 100, ReadOnly: true, ModifiedAtSecond: longAgo},
+							{Id: 2, Size: 960, ModifiedAtSecond: longAgo},
+							{Id: 3, Size: 100, ModifiedAtSecond: longAgo},
+							{Id: 4, Size: 960, ModifiedAtSecond: recently},
+							{Id: 5, Size: 960, ModifiedAtSecond: longAgo, Ttl: 5},
+							{Id: 6, Size: 960, ModifiedAtSecond: longAgo},
+							{Id: 7, Size: 960, ModifiedAtSecond: longAgo, Collection: "logs"},
+							{Id: 8, Size: 960, ModifiedAtSecond: longAgo, Collection: "images"},
+						},
+					},
+					{
+						VolumeInfos: []*master_pb.VolumeInformationMessage{
+							{Id: 1, Size: 100, ReadOnly: true, ModifiedAtSecond: longAgo},
+							{Id: 6, Size: 960, ModifiedAtSecond: recently},
+						},
+					},
+				},
+			}},
+		}},
+	}
+
+	defaultPolicy := EcEncodingPolicy{Enabled: true, QuietFor: 7 * 24 * time.Hour, FullPercent: 95}
+	collectionPolicies := map[string]EcEncodingPolicy{
+		"logs":   {Enabled: true, QuietFor: 30 * 24 * time.Hour, FullPercent: 95},
+		"images": {Enabled: false},
+	}
+
+	selected := selectVolumesToEcEncode(topo, 1000, defaultPolicy, collectionPolicies, now)
+
+	if len(selected) != 1 {
+		t.Fatalf("expected only the default collection, got %+v", selected)
+	}
+	vids := selected[""]
+	if len(vids) != 2 || vids[0] != 1 || vids[1] != 2 {
+		t.Errorf("expected volumes [1 2], got %v", vids)
+	}
+
+}
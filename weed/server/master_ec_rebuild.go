@@ -0,0 +1,75 @@
+package weed_server
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/storage/erasure_coding"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+	"github.com/chrislusf/seaweedfs/weed/topology"
+	"github.com/spf13/viper"
+)
+
+func (ms *MasterServer) startEcRebuildScheduler() {
+	v := viper.GetViper()
+	if !v.GetBool("master.ec_rebuild.enabled") {
+		return
+	}
+	v.SetDefault("master.ec_rebuild.sleep_minutes", 10)
+	sleepMinutes := v.GetInt("master.ec_rebuild.sleep_minutes")
+	maxMBps := v.GetInt("master.ec_rebuild.max_mb_per_second")
+
+	glog.V(0).Infof("ec rebuild scheduler: every %d minutes, at most %d MB/s per source volume server", sleepMinutes, maxMBps)
+
+	commandEnv := ms.newShellCommandEnv()
+
+	go commandEnv.MasterClient.KeepConnectedToMaster()
+
+	go func() {
+		commandEnv.MasterClient.WaitUntilConnected()
+
+		var previous map[needle.VolumeId]topology.EcVolumeShardCount
+		c := time.Tick(time.Duration(sleepMinutes) * time.Minute)
+		for _ = range c {
+			if !ms.Topo.IsLeader() {
+				previous = nil
+				continue
+			}
+			current := ms.Topo.ListEcVolumesWithMissingShards()
+			for _, collection := range selectEcCollectionsToRebuild(previous, current) {
+				glog.V(0).Infof("rebuild missing ec shards in collection %s", collection)
+				if err := runShellCommand(commandEnv, "ec.rebuild", []string{
+					"-collection=" + collection,
+					"-force",
+					fmt.Sprintf("-maxMBps=%d", maxMBps),
+				}); err != nil {
+					glog.Errorf("ec rebuild collection %s: %v", collection, err)
+				}
+			}
+			previous = current
+		}
+	}()
+}
+
+// selectEcCollectionsToRebuild picks collections with ec volumes missing shards in both the previous and the current check,
+// so that a volume server restarting or a shard being moved does not trigger a rebuild.
+func selectEcCollectionsToRebuild(previous, current map[needle.VolumeId]topology.EcVolumeShardCount) (collections []string) {
+	collectionSet := make(map[string]bool)
+	for vid, c := range current {
+		if _, found := previous[vid]; !found {
+			continue
+		}
+		if c.ShardCount < erasure_coding.DataShardsCount {
+			glog.Errorf("ec volume %d in collection %s is unrepairable with %d shards", vid, c.Collection, c.ShardCount)
+			continue
+		}
+		collectionSet[c.Collection] = true
+	}
+	for collection := range collectionSet {
+		collections = append(collections, collection)
+	}
+	sort.Strings(collections)
+	return
+}
@@ -0,0 +1,158 @@
+package weed_server
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/operation"
+	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
+	"github.com/chrislusf/seaweedfs/weed/pb/volume_server_pb"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+	"github.com/spf13/viper"
+)
+
+// EmptyVolumeDeletionPolicy decides when a volume without live files, e.g., after vacuum or TTL expiry, is deleted.
+type EmptyVolumeDeletionPolicy struct {
+	Enabled bool
+	IdleFor time.Duration
+	// the volumes seen by the master for less than the grace period, e.g., the newly grown ones, are kept
+	GracePeriod time.Duration
+}
+
+func (ms *MasterServer) startEmptyVolumeDeletion() {
+	v := viper.GetViper()
+	v.SetDefault("master.empty_volume_deletion.sleep_minutes", 30)
+	sleepMinutes := v.GetInt("master.empty_volume_deletion.sleep_minutes")
+
+	defaultPolicy, collectionPolicies := loadEmptyVolumeDeletionPolicies(v)
+	if !defaultPolicy.Enabled && len(collectionPolicies) == 0 {
+		return
+	}
+
+	glog.V(0).Infof("empty volume deletion policy: default %+v, collections %+v", defaultPolicy, collectionPolicies)
+
+	go func() {
+		// the time each volume is first seen, which starts over on a new leader
+		firstSeen := make(map[needle.VolumeId]time.Time)
+		c := time.Tick(time.Duration(sleepMinutes) * time.Minute)
+		for _ = range c {
+			if !ms.Topo.IsLeader() {
+				firstSeen = make(map[needle.VolumeId]time.Time)
+				continue
+			}
+			collectionVolumes := selectEmptyVolumesToDelete(ms.Topo.ToTopologyInfo(), firstSeen, defaultPolicy, collectionPolicies, time.Now())
+			for _, collection := range sortedCollections(collectionVolumes) {
+				for _, vid := range collectionVolumes[collection] {
+					ms.deleteEmptyVolume(collection, vid)
+				}
+			}
+		}
+	}()
+}
+
+func loadEmptyVolumeDeletionPolicies(v *viper.Viper) (defaultPolicy EmptyVolumeDeletionPolicy, collectionPolicies map[string]EmptyVolumeDeletionPolicy) {
+	v.SetDefault("master.empty_volume_deletion.idle_minutes", 60)
+	v.SetDefault("master.empty_volume_deletion.grace_minutes", 24*60)
+
+	defaultPolicy = EmptyVolumeDeletionPolicy{
+		Enabled:     v.GetBool("master.empty_volume_deletion.enabled"),
+		IdleFor:     time.Duration(v.GetInt("master.empty_volume_deletion.idle_minutes")) * time.Minute,
+		GracePeriod: time.Duration(v.GetInt("master.empty_volume_deletion.grace_minutes")) * time.Minute,
+	}
+
+	collectionPolicies = make(map[string]EmptyVolumeDeletionPolicy)
+	for collection := range v.GetStringMap("master.empty_volume_deletion.collections") {
+		sub := v.Sub("master.empty_volume_deletion.collections." + collection)
+		if sub == nil {
+			continue
+		}
+		sub.SetDefault("enabled", true)
+		sub.SetDefault("idle_minutes", int(defaultPolicy.IdleFor/time.Minute))
+		sub.SetDefault("grace_minutes", int(defaultPolicy.GracePeriod/time.Minute))
+		collectionPolicies[collection] = EmptyVolumeDeletionPolicy{
+			Enabled:     sub.GetBool("enabled"),
+			IdleFor:     time.Duration(sub.GetInt("idle_minutes")) * time.Minute,
+			GracePeriod: time.Duration(sub.GetInt("grace_minutes")) * time.Minute,
+		}
+	}
+
+	return
+}
+
+// selectEmptyVolumesToDelete finds volumes without live files and without writes for the idle period on all replicas,
+// and seen for longer than the grace period. The first seen times are updated with the volumes in the topology.
+func selectEmptyVolumesToDelete(topo *master_pb.TopologyInfo, firstSeen map[needle.VolumeId]time.Time,
+	defaultPolicy EmptyVolumeDeletionPolicy, collectionPolicies map[string]EmptyVolumeDeletionPolicy, now time.Time) map[string][]needle.VolumeId {
+
+	candidates := make(map[uint32]bool)
+	collections := make(map[uint32]string)
+	seen := make(map[needle.VolumeId]bool)
+	for _, dc := range topo.DataCenterInfos {
+		for _, rack := range dc.RackInfos {
+			for _, dn := range rack.DataNodeInfos {
+				for _, v := range dn.VolumeInfos {
+					policy, found := collectionPolicies[v.Collection]
+					if !found {
+						policy = defaultPolicy
+					}
+					isEmpty := v.FileCount <= v.DeleteCount
+					isIdle := v.ModifiedAtSecond+int64(policy.IdleFor/time.Second) < now.Unix()
+					vid := needle.VolumeId(v.Id)
+					seen[vid] = true
+					if _, found := firstSeen[vid]; !found {
+						firstSeen[vid] = now
+					}
+					isYoung := now.Sub(firstSeen[vid]) < policy.GracePeriod
+					isCandidate := policy.Enabled && isEmpty && isIdle && !isYoung
+					if previous, seen := candidates[v.Id]; seen {
+						isCandidate = isCandidate && previous
+					}
+					candidates[v.Id] = isCandidate
+					collections[v.Id] = v.Collection
+				}
+			}
+		}
+	}
+
+	for vid := range firstSeen {
+		if !seen[vid] {
+			delete(firstSeen, vid)
+		}
+	}
+
+	collectionVolumes := make(map[string][]needle.VolumeId)
+	for vid, isCandidate := range candidates {
+		if isCandidate {
+			collectionVolumes[collections[vid]] = append(collectionVolumes[collections[vid]], needle.VolumeId(vid))
+		}
+	}
+	for _, vids := range collectionVolumes {
+		sort.Slice(vids, func(i, j int) bool {
+			return vids[i] < vids[j]
+		})
+	}
+
+	return collectionVolumes
+}
+
+// deleteEmptyVolume deletes all replicas of the volume. Each volume server refuses if the volume has got new files,
+// and the deleted replicas are unregistered when the volume servers report the deletion.
+// The replicas failed to delete are tried again on the next check.
+func (ms *MasterServer) deleteEmptyVolume(collection string, vid needle.VolumeId) {
+	for _, dn := range ms.Topo.Lookup(collection, vid) {
+		err := operation.WithVolumeServerClient(dn.Url(), ms.grpcDialOpiton, func(volumeServerClient volume_server_pb.VolumeServerClient) error {
+			_, deleteErr := volumeServerClient.VolumeDelete(context.Background(), &volume_server_pb.VolumeDeleteRequest{
+				VolumeId:  uint32(vid),
+				OnlyEmpty: true,
+			})
+			return deleteErr
+		})
+		if err != nil {
+			glog.V(0).Infof("delete empty volume %d in collection %s on %s: %v", vid, collection, dn.Url(), err)
+			continue
+		}
+		glog.V(0).Infof("deleted empty volume %d in collection %s on %s", vid, collection, dn.Url())
+	}
+}
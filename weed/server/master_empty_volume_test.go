@@ -0,0 +1,74 @@
+package weed_server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+)
+
+func TestSelectEmptyVolumesToDelete(t *testing.T) {
+
+	now := time.Now()
+	longAgo := now.Add(-2 * time.Hour).Unix()
+	recently := now.Add(-time.Minute).Unix()
+
+	topo := &master_pb.TopologyInfo{
+		DataCenterInfos: []*master_pb.DataCenterInfo{{
+			RackInfos: []*master_pb.RackInfo{{
+				DataNodeInfos: []*master_pb.DataNodeInfo{
+					{
+						VolumeInfos: []*master_pb.VolumeInformationMessage{
+							{Id: 1, ModifiedAtSecond: longAgo},
+							{Id: 2, FileCount: 3, DeleteCount: 3, ModifiedAtSecond: longAgo},
+							{Id: 3, FileCount: 3, DeleteCount: 2, ModifiedAtSecond: longAgo},
+							{Id: 4, ModifiedAtSecond: recently},
+							{Id: 5, ModifiedAtSecond: longAgo},
+							{Id: 6, ModifiedAtSecond: longAgo, Collection: "logs"},
+							{Id: 7, ModifiedAtSecond: longAgo, Collection: "images"},
+						},
+					},
+					{
+						VolumeInfos: []*master_pb.VolumeInformationMessage{
+							{Id: 1, ModifiedAtSecond: longAgo},
+							{Id: 5, FileCount: 1, ModifiedAtSecond: longAgo},
+						},
+					},
+				},
+			}},
+		}},
+	}
+
+	defaultPolicy := EmptyVolumeDeletionPolicy{Enabled: true, IdleFor: time.Hour, GracePeriod: time.Hour}
+	collectionPolicies := map[string]EmptyVolumeDeletionPolicy{
+		"logs":   {Enabled: true, IdleFor: 3 * time.Hour},
+		"images": {Enabled: false},
+	}
+
+	// the volumes just seen by the master are kept for the grace period
+	firstSeen := map[needle.VolumeId]time.Time{2: now.Add(-2 * time.Hour), 8: now.Add(-2 * time.Hour)}
+	if selected := selectEmptyVolumesToDelete(topo, firstSeen, defaultPolicy, collectionPolicies, now); len(selected[""]) != 1 || selected[""][0] != 2 {
+		t.Errorf("expected only volume 2 seen before the grace period, got %+v", selected)
+	}
+	if _, found := firstSeen[8]; found {
+		t.Errorf("volume 8 not in the topology should be forgotten")
+	}
+	if seenAt, found := firstSeen[1]; !found || !seenAt.Equal(now) {
+		t.Errorf("volume 1 first seen at %v", seenAt)
+	}
+
+	for vid := range firstSeen {
+		firstSeen[vid] = now.Add(-2 * time.Hour)
+	}
+	selected := selectEmptyVolumesToDelete(topo, firstSeen, defaultPolicy, collectionPolicies, now)
+
+	if len(selected) != 1 {
+		t.Fatalf("expected only the default collection, got %+v", selected)
+	}
+	vids := selected[""]
+	if len(vids) != 2 || vids[0] != 1 || vids[1] != 2 {
+		t.Errorf("expected volumes [1 2], got %v", vids)
+	}
+
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/chrislusf/raft"
@@ -17,12 +18,18 @@ import (
 func (ms *MasterServer) SendHeartbeat(stream master_pb.Seaweed_SendHeartbeatServer) error {
 	var dn *topology.DataNode
 	t := ms.Topo
+	// the version of the ec shard location changes already sent to the volume server
+	var ecLocationVersion uint64
+
+	atomic.AddInt64(&ms.heartbeatStreams, 1)
+	defer atomic.AddInt64(&ms.heartbeatStreams, -1)
 
 	defer func() {
 		if dn != nil {
 
 			glog.V(0).Infof("unregister disconnected volume server %s:%d", dn.Ip, dn.Port)
 			t.UnRegisterDataNode(dn)
+			t.RecordDataNodeLeft(dn, time.Now())
 
 			message := &master_pb.VolumeLocation{
 				Url:       dn.Url(),
@@ -33,6 +40,7 @@ func (ms *MasterServer) SendHeartbeat(stream master_pb.Seaweed_SendHeartbeatServ
 			}
 			for _, s := range dn.GetEcShards() {
 				message.DeletedVids = append(message.DeletedVids, uint32(s.VolumeId))
+				message.DeletedEcVids = append(message.DeletedEcVids, uint32(s.VolumeId))
 			}
 
 			if len(message.DeletedVids) > 0 {
@@ -52,8 +60,9 @@ func (ms *MasterServer) SendHeartbeat(stream master_pb.Seaweed_SendHeartbeatServ
 			return err
 		}
 
+		t.Sequence.SetMax(heartbeat.MaxFileKey)
+
 		if dn == nil {
-			t.Sequence.SetMax(heartbeat.MaxFileKey)
 			if heartbeat.Ip == "" {
 				if pr, ok := peer.FromContext(stream.Context()); ok {
 					if pr.Addr != net.Addr(nil) {
@@ -68,18 +77,26 @@ func (ms *MasterServer) SendHeartbeat(stream master_pb.Seaweed_SendHeartbeatServ
 			dn = rack.GetOrCreateDataNode(heartbeat.Ip,
 				int(heartbeat.Port), heartbeat.PublicUrl,
 				int64(heartbeat.MaxVolumeCount))
+			dn.SetQuarantined(t.IsQuarantined(dn.Url()))
 			glog.V(0).Infof("added volume server %v:%d", heartbeat.GetIp(), heartbeat.GetPort())
+			// the shard locations cached with the previous master could be outdated
+			ecLocationVersion = t.EcLocationVersion()
 			if err := stream.Send(&master_pb.HeartbeatResponse{
-				VolumeSizeLimit: uint64(ms.option.VolumeSizeLimitMB) * 1024 * 1024,
+				VolumeSizeLimit:          uint64(ms.option.VolumeSizeLimitMB) * 1024 * 1024,
+				HeartbeatIntervalSeconds: ms.heartbeatIntervalSeconds(),
+				AllEcLocationsChanged:    true,
 			}); err != nil {
 				return err
 			}
 		}
+		dn.Heartbeat()
 
 		glog.V(4).Infof("master received heartbeat %s", heartbeat.String())
 		message := &master_pb.VolumeLocation{
-			Url:       dn.Url(),
-			PublicUrl: dn.PublicUrl,
+			Url:        dn.Url(),
+			PublicUrl:  dn.PublicUrl,
+			DataCenter: string(dn.GetDataCenter().Id()),
+			Rack:       string(dn.GetRack().Id()),
 		}
 		if len(heartbeat.NewVolumes) > 0 || len(heartbeat.DeletedVolumes) > 0 {
 			// process delta volume ids if exists for fast volume id updates
@@ -94,6 +111,12 @@ func (ms *MasterServer) SendHeartbeat(stream master_pb.Seaweed_SendHeartbeatServ
 		}
 
 		if len(heartbeat.Volumes) > 0 || heartbeat.HasNoVolumes {
+			// the full heartbeats carry the node states and the labels
+			dn.SetDraining(heartbeat.IsDraining)
+			dn.SetReadOnly(heartbeat.IsReadOnly)
+			dn.SetLabels(heartbeat.Labels)
+			dn.SetFileSizeLimit(heartbeat.FileSizeLimit)
+
 			// process heartbeat.Volumes
 			newVolumes, deletedVolumes := t.SyncDataNodeRegistration(heartbeat.Volumes, dn)
 
@@ -107,6 +130,16 @@ func (ms *MasterServer) SendHeartbeat(stream master_pb.Seaweed_SendHeartbeatServ
 			}
 		}
 
+		if heartbeat.IsDelta {
+			dn.SetDraining(heartbeat.IsDraining)
+			dn.SetReadOnly(heartbeat.IsReadOnly)
+
+			for _, v := range t.DeltaSyncDataNodeRegistration(heartbeat.UpdatedVolumes, dn) {
+				glog.V(0).Infof("master see new volume %d from %s", uint32(v.Id), dn.Url())
+				message.NewVids = append(message.NewVids, uint32(v.Id))
+			}
+		}
+
 		if len(heartbeat.NewEcShards) > 0 || len(heartbeat.DeletedEcShards) > 0 {
 
 			// update master internal volume layouts
@@ -114,8 +147,12 @@ func (ms *MasterServer) SendHeartbeat(stream master_pb.Seaweed_SendHeartbeatServ
 
 			for _, s := range heartbeat.NewEcShards {
 				message.NewVids = append(message.NewVids, s.Id)
+				message.NewEcVids = append(message.NewEcVids, s.Id)
 			}
 			for _, s := range heartbeat.DeletedEcShards {
+				if !dn.HasEcShardsById(needle.VolumeId(s.Id)) {
+					message.DeletedEcVids = append(message.DeletedEcVids, s.Id)
+				}
 				if dn.HasVolumesById(needle.VolumeId(s.Id)) {
 					continue
 				}
@@ -131,8 +168,12 @@ func (ms *MasterServer) SendHeartbeat(stream master_pb.Seaweed_SendHeartbeatServ
 			// broadcast the ec vid changes to master clients
 			for _, s := range newShards {
 				message.NewVids = append(message.NewVids, uint32(s.VolumeId))
+				message.NewEcVids = append(message.NewEcVids, uint32(s.VolumeId))
 			}
 			for _, s := range deletedShards {
+				if !dn.HasEcShardsById(s.VolumeId) {
+					message.DeletedEcVids = append(message.DeletedEcVids, uint32(s.VolumeId))
+				}
 				if dn.HasVolumesById(s.VolumeId) {
 					continue
 				}
@@ -141,7 +182,8 @@ func (ms *MasterServer) SendHeartbeat(stream master_pb.Seaweed_SendHeartbeatServ
 
 		}
 
-		if len(message.NewVids) > 0 || len(message.DeletedVids) > 0 {
+		if len(message.NewVids) > 0 || len(message.DeletedVids) > 0 || len(message.DeletedEcVids) > 0 {
+			message.FileSizeLimit = dn.FileSizeLimit()
 			ms.clientChansLock.RLock()
 			for host, ch := range ms.clientChans {
 				glog.V(0).Infof("master send to %s: %s", host, message.String())
@@ -155,16 +197,34 @@ func (ms *MasterServer) SendHeartbeat(stream master_pb.Seaweed_SendHeartbeatServ
 		if err != nil {
 			return err
 		}
+		changedEcVids, latestEcLocationVersion, allEcLocationsChanged := t.EcLocationChangesSince(ecLocationVersion, dn)
+		ecLocationVersion = latestEcLocationVersion
 		if err := stream.Send(&master_pb.HeartbeatResponse{
-			Leader:                 newLeader,
-			MetricsAddress:         ms.option.MetricsAddress,
-			MetricsIntervalSeconds: uint32(ms.option.MetricsIntervalSec),
+			Leader:                   newLeader,
+			MetricsAddress:           ms.option.MetricsAddress,
+			MetricsIntervalSeconds:   uint32(ms.option.MetricsIntervalSec),
+			HeartbeatIntervalSeconds: ms.heartbeatIntervalSeconds(),
+			ChangedEcVids:            changedEcVids,
+			AllEcLocationsChanged:    allEcLocationsChanged,
 		}); err != nil {
 			return err
 		}
 	}
 }
 
+// heartbeatIntervalSeconds spreads the heartbeats of all the volume servers to at most HeartbeatMaxRate per second,
+// and never below the pulse seconds
+func (ms *MasterServer) heartbeatIntervalSeconds() uint32 {
+	interval := int64(ms.option.PulseSeconds)
+	if ms.option.HeartbeatMaxRate > 0 {
+		streams := atomic.LoadInt64(&ms.heartbeatStreams)
+		if spread := (streams + int64(ms.option.HeartbeatMaxRate) - 1) / int64(ms.option.HeartbeatMaxRate); spread > interval {
+			interval = spread
+		}
+	}
+	return uint32(interval)
+}
+
 // KeepConnected keep a stream gRPC call to the master. Used by clients to know the master is up.
 // And clients gets the up-to-date list of volume locations
 func (ms *MasterServer) KeepConnected(stream master_pb.Seaweed_KeepConnectedServer) error {
@@ -213,6 +273,10 @@ func (ms *MasterServer) KeepConnected(stream master_pb.Seaweed_KeepConnectedServ
 			return err
 		}
 	}
+	// the client replaces the locations followed before with the listed ones
+	if err := stream.Send(&master_pb.VolumeLocation{IsFullSyncDone: true}); err != nil {
+		return err
+	}
 
 	go func() {
 		for {
@@ -0,0 +1,52 @@
+package weed_server
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
+)
+
+// ClusterStatus reports the raft status of this master, and the capacity and the health of the volume servers if this is the leader.
+// It is also answered by the followers, so the scripts can find the leader without knowing it.
+func (ms *MasterServer) ClusterStatus(ctx context.Context, req *master_pb.ClusterStatusRequest) (*master_pb.ClusterStatusResponse, error) {
+	return ms.clusterStatus(), nil
+}
+
+func (ms *MasterServer) clusterStatus() *master_pb.ClusterStatusResponse {
+	isLeader := ms.Topo.IsLeader()
+
+	resp := &master_pb.ClusterStatusResponse{}
+	if isLeader {
+		resp = ms.Topo.ToClusterStatus()
+	}
+	resp.IsLeader = isLeader
+	if leader, err := ms.Topo.Leader(); err == nil {
+		resp.Leader = leader
+	}
+
+	raftServer := ms.Topo.RaftServer
+	if raftServer == nil {
+		return resp
+	}
+	resp.RaftState = raftServer.State()
+	resp.RaftTerm = raftServer.Term()
+	resp.RaftCommitIndex = raftServer.CommitIndex()
+	for _, peer := range raftServer.Peers() {
+		// the peers are only contacted by the leader
+		lastActivity := int64(-1)
+		if isLeader && !peer.LastActivity().IsZero() {
+			lastActivity = int64(time.Since(peer.LastActivity()).Seconds())
+		}
+		resp.Peers = append(resp.Peers, &master_pb.RaftPeerStatus{
+			Name:                   peer.Name,
+			LastActivitySecondsAgo: lastActivity,
+		})
+	}
+	sort.Slice(resp.Peers, func(i, j int) bool {
+		return resp.Peers[i].Name < resp.Peers[j].Name
+	})
+
+	return resp
+}
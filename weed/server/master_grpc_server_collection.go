@@ -2,11 +2,13 @@ package weed_server
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/chrislusf/raft"
 	"github.com/chrislusf/seaweedfs/weed/operation"
 	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
 	"github.com/chrislusf/seaweedfs/weed/pb/volume_server_pb"
+	"github.com/chrislusf/seaweedfs/weed/topology"
 )
 
 func (ms *MasterServer) CollectionList(ctx context.Context, req *master_pb.CollectionListRequest) (*master_pb.CollectionListResponse, error) {
@@ -18,8 +20,13 @@ func (ms *MasterServer) CollectionList(ctx context.Context, req *master_pb.Colle
 	resp := &master_pb.CollectionListResponse{}
 	collections := ms.Topo.ListCollections(req.IncludeNormalVolumes, req.IncludeEcVolumes)
 	for _, c := range collections {
+		config, _ := ms.Topo.GetCollectionConfig(c)
 		resp.Collections = append(resp.Collections, &master_pb.Collection{
-			Name: c,
+			Name:              c,
+			Replication:       config.Replication,
+			Ttl:               config.Ttl,
+			VolumeSizeLimitMb: config.VolumeSizeLimitMB,
+			NeedleTtl:         config.NeedleTtl,
 		})
 	}
 
@@ -46,9 +53,74 @@ func (ms *MasterServer) CollectionDelete(ctx context.Context, req *master_pb.Col
 		return nil, err
 	}
 
+	if err = ms.deleteCollectionConfig(req.Name); err != nil {
+		return nil, err
+	}
+
 	return resp, nil
 }
 
+// CollectionCreate sets the defaults of the writes to the collection through raft.
+// The volumes are still created on the first writes.
+func (ms *MasterServer) CollectionCreate(ctx context.Context, req *master_pb.CollectionCreateRequest) (*master_pb.CollectionCreateResponse, error) {
+
+	if !ms.Topo.IsLeader() {
+		return nil, raft.NotLeaderError
+	}
+
+	if req.Name == "" {
+		return nil, fmt.Errorf("collection name is required")
+	}
+	config := &topology.CollectionConfig{
+		Replication:       req.Replication,
+		Ttl:               req.Ttl,
+		VolumeSizeLimitMB: req.VolumeSizeLimitMb,
+		NeedleTtl:         req.NeedleTtl,
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	if _, err := ms.Topo.RaftServer.Do(&topology.CollectionConfigCommand{Collection: req.Name, Config: config}); err != nil {
+		return nil, err
+	}
+
+	return &master_pb.CollectionCreateResponse{}, nil
+}
+
+func (ms *MasterServer) deleteCollectionConfig(collectionName string) error {
+	if _, found := ms.Topo.GetCollectionConfig(collectionName); !found {
+		return nil
+	}
+	_, err := ms.Topo.RaftServer.Do(&topology.CollectionConfigCommand{Collection: collectionName})
+	return err
+}
+
+// collectionDefaults fills the replication and the ttl not given by the writes from the collection config,
+// and then the replication from the master default
+func (ms *MasterServer) collectionDefaults(collection, replication, ttl string) (string, string) {
+	if config, found := ms.Topo.GetCollectionConfig(collection); found {
+		if replication == "" {
+			replication = config.Replication
+		}
+		if ttl == "" {
+			ttl = config.Ttl
+		}
+	}
+	if replication == "" {
+		replication = ms.option.DefaultReplicaPlacement
+	}
+	return replication, ttl
+}
+
+// collectionPreallocate is the preallocated size of the new volumes, at most the size limit of the collection
+func (ms *MasterServer) collectionPreallocate(collection string, preallocate int64) int64 {
+	if limit := int64(ms.Topo.CollectionVolumeSizeLimit(collection, 0)); limit > 0 && preallocate > limit {
+		return limit
+	}
+	return preallocate
+}
+
 func (ms *MasterServer) doDeleteNormalCollection(collectionName string) error {
 
 	collection, ok := ms.Topo.FindCollection(collectionName)
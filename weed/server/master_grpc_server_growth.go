@@ -0,0 +1,57 @@
+package weed_server
+
+import (
+	"context"
+	"sort"
+
+	"github.com/chrislusf/raft"
+	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
+	"github.com/chrislusf/seaweedfs/weed/topology"
+)
+
+// ConfigureVolumeGrowth sets or resets the volume growth strategy of a collection through raft, and lists the strategies
+func (ms *MasterServer) ConfigureVolumeGrowth(ctx context.Context, req *master_pb.ConfigureVolumeGrowthRequest) (*master_pb.ConfigureVolumeGrowthResponse, error) {
+
+	if !ms.Topo.IsLeader() {
+		return nil, raft.NotLeaderError
+	}
+
+	if req.Remove {
+		if _, err := ms.Topo.RaftServer.Do(&topology.VolumeGrowthStrategyCommand{Collection: req.Collection}); err != nil {
+			return nil, err
+		}
+	} else if req.Strategy != nil {
+		strategy := &topology.VolumeGrowthStrategy{
+			Kind:               req.Strategy.Kind,
+			WritableTarget:     int(req.Strategy.WritableTarget),
+			PeakWritableTarget: int(req.Strategy.PeakWritableTarget),
+			PeakHours:          req.Strategy.PeakHours,
+		}
+		if err := strategy.Validate(); err != nil {
+			return nil, err
+		}
+		if _, err := ms.Topo.RaftServer.Do(&topology.VolumeGrowthStrategyCommand{Collection: req.Strategy.Collection, Strategy: strategy}); err != nil {
+			return nil, err
+		}
+	}
+
+	resp := &master_pb.ConfigureVolumeGrowthResponse{}
+	strategies := ms.Topo.VolumeGrowthStrategies()
+	var collections []string
+	for collection := range strategies {
+		collections = append(collections, collection)
+	}
+	sort.Strings(collections)
+	for _, collection := range collections {
+		s := strategies[collection]
+		resp.Strategies = append(resp.Strategies, &master_pb.VolumeGrowthStrategy{
+			Collection:         collection,
+			Kind:               s.Kind,
+			WritableTarget:     uint32(s.WritableTarget),
+			PeakWritableTarget: uint32(s.PeakWritableTarget),
+			PeakHours:          s.PeakHours,
+		})
+	}
+
+	return resp, nil
+}
@@ -15,18 +15,29 @@ import (
 func (ms *MasterServer) LookupVolume(ctx context.Context, req *master_pb.LookupVolumeRequest) (*master_pb.LookupVolumeResponse, error) {
 
 	if !ms.Topo.IsLeader() {
-		return nil, raft.NotLeaderError
+		// the standby masters only answer if all the volumes are known
+		if !ms.isStandbyServing() {
+			return nil, raft.NotLeaderError
+		}
+		if _, found := ms.standbyLookupVolumeId(req.VolumeIds); !found {
+			return nil, raft.NotLeaderError
+		}
 	}
 
-	resp := &master_pb.LookupVolumeResponse{}
+	resp := &master_pb.LookupVolumeResponse{
+		TopologyVersion: ms.lookupTopologyVersion(),
+		CacheTtlSeconds: uint32(ms.option.LookupCacheTtl.Seconds()),
+	}
 	volumeLocations := ms.lookupVolumeId(req.VolumeIds, req.Collection)
 
 	for _, result := range volumeLocations {
 		var locations []*master_pb.Location
 		for _, loc := range result.Locations {
 			locations = append(locations, &master_pb.Location{
-				Url:       loc.Url,
-				PublicUrl: loc.PublicUrl,
+				Url:        loc.Url,
+				PublicUrl:  loc.PublicUrl,
+				DataCenter: loc.DataCenter,
+				Rack:       loc.Rack,
 			})
 		}
 		resp.VolumeIdLocations = append(resp.VolumeIdLocations, &master_pb.LookupVolumeResponse_VolumeIdLocation{
@@ -48,10 +59,11 @@ func (ms *MasterServer) Assign(ctx context.Context, req *master_pb.AssignRequest
 	if req.Count == 0 {
 		req.Count = 1
 	}
-
-	if req.Replication == "" {
-		req.Replication = ms.option.DefaultReplicaPlacement
+	if req.Batch && req.Count > maxAssignBatchCount {
+		return nil, fmt.Errorf("batch count %d is over %d", req.Count, maxAssignBatchCount)
 	}
+
+	req.Replication, req.Ttl = ms.collectionDefaults(req.Collection, req.Replication, req.Ttl)
 	replicaPlacement, err := storage.NewReplicaPlacementFromString(req.Replication)
 	if err != nil {
 		return nil, err
@@ -60,15 +72,27 @@ func (ms *MasterServer) Assign(ctx context.Context, req *master_pb.AssignRequest
 	if err != nil {
 		return nil, err
 	}
+	writeFlags, err := needle.ParseWriteFlags("", req.Ack)
+	if err != nil {
+		return nil, err
+	}
+	writeFlags.Fsync = req.Fsync
+	labelSelector, err := storage.ParseLabelSelector(req.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
 
 	option := &topology.VolumeGrowOption{
 		Collection:       req.Collection,
 		ReplicaPlacement: replicaPlacement,
-		Ttl:              ttl,
-		Prealloacte:      ms.preallocateSize,
+		Ttl:              ms.Topo.LayoutTtl(req.Collection, ttl),
+		Prealloacte:      ms.collectionPreallocate(req.Collection, ms.preallocateSize),
 		DataCenter:       req.DataCenter,
 		Rack:             req.Rack,
 		DataNode:         req.DataNode,
+		LocalityGroup:    req.LocalityGroup,
+		Session:          req.Session,
+		LabelSelector:    labelSelector,
 	}
 
 	if !ms.Topo.HasWritableVolume(option) {
@@ -89,13 +113,21 @@ func (ms *MasterServer) Assign(ctx context.Context, req *master_pb.AssignRequest
 		return nil, fmt.Errorf("%v", err)
 	}
 
-	return &master_pb.AssignResponse{
+	resp := &master_pb.AssignResponse{
 		Fid:       fid,
 		Url:       dn.Url(),
 		PublicUrl: dn.PublicUrl,
 		Count:     count,
-		Auth:      string(security.GenJwt(ms.guard.SigningKey, ms.guard.ExpiresAfterSec, fid)),
-	}, nil
+		Auth:      string(security.GenWriteJwt(ms.guard.SigningKey, ms.guard.ExpiresAfterSec, fid, count, writeFlags.Fsync, writeFlags.Ack)),
+		Fsync:     writeFlags.Fsync,
+		Ack:       writeFlags.Ack,
+	}
+	if req.Batch {
+		if resp.Fids, err = batchFileIds(fid, count); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
 }
 
 func (ms *MasterServer) Statistics(ctx context.Context, req *master_pb.StatisticsRequest) (*master_pb.StatisticsResponse, error) {
@@ -104,9 +136,7 @@ func (ms *MasterServer) Statistics(ctx context.Context, req *master_pb.Statistic
 		return nil, raft.NotLeaderError
 	}
 
-	if req.Replication == "" {
-		req.Replication = ms.option.DefaultReplicaPlacement
-	}
+	req.Replication, req.Ttl = ms.collectionDefaults(req.Collection, req.Replication, req.Ttl)
 	replicaPlacement, err := storage.NewReplicaPlacementFromString(req.Replication)
 	if err != nil {
 		return nil, err
@@ -116,7 +146,7 @@ func (ms *MasterServer) Statistics(ctx context.Context, req *master_pb.Statistic
 		return nil, err
 	}
 
-	volumeLayout := ms.Topo.GetVolumeLayout(req.Collection, replicaPlacement, ttl)
+	volumeLayout := ms.Topo.GetVolumeLayout(req.Collection, replicaPlacement, ms.Topo.LayoutTtl(req.Collection, ttl))
 	stats := volumeLayout.Stats()
 
 	resp := &master_pb.StatisticsResponse{
@@ -162,8 +192,10 @@ func (ms *MasterServer) LookupEcVolume(ctx context.Context, req *master_pb.Looku
 		var locations []*master_pb.Location
 		for _, dn := range shardLocations {
 			locations = append(locations, &master_pb.Location{
-				Url:       string(dn.Id()),
-				PublicUrl: dn.PublicUrl,
+				Url:        string(dn.Id()),
+				PublicUrl:  dn.PublicUrl,
+				DataCenter: string(dn.GetDataCenter().Id()),
+				Rack:       string(dn.GetRack().Id()),
 			})
 		}
 		resp.ShardIdLocations = append(resp.ShardIdLocations, &master_pb.LookupEcVolumeResponse_EcShardIdLocation{
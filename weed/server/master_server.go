@@ -8,6 +8,7 @@ import (
 	"net/http/httputil"
 	"net/url"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -19,9 +20,12 @@ import (
 	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
 	"github.com/chrislusf/seaweedfs/weed/security"
 	"github.com/chrislusf/seaweedfs/weed/sequence"
+	"github.com/chrislusf/seaweedfs/weed/stats"
 	"github.com/chrislusf/seaweedfs/weed/topology"
 	"github.com/chrislusf/seaweedfs/weed/util"
+	"github.com/chrislusf/seaweedfs/weed/wdclient"
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/spf13/viper"
 )
 
@@ -37,9 +41,19 @@ type MasterOption struct {
 	DisableHttp             bool
 	MetricsAddress          string
 	MetricsIntervalSec      int
+	FlappingWindow          time.Duration
+	FlappingThreshold       int
+	ForecastInterval        time.Duration
+	ForecastRetention       time.Duration
+	LookupCacheTtl          time.Duration
+	StandbyMaxStaleness     time.Duration
+	HeartbeatMaxRate        int
 }
 
 type MasterServer struct {
+	// the connected volume servers, to adapt the heartbeat interval. first for the 64-bit alignment of atomic
+	heartbeatStreams int64
+
 	option *MasterOption
 	guard  *security.Guard
 
@@ -56,6 +70,10 @@ type MasterServer struct {
 	clientChans     map[string]chan *master_pb.VolumeLocation
 
 	grpcDialOpiton grpc.DialOption
+
+	// the volume locations followed from the leader, when not the leader
+	standby          *wdclient.MasterClient
+	standbyResponses standbyResponseCache
 }
 
 func NewMasterServer(r *mux.Router, option *MasterOption) *MasterServer {
@@ -80,8 +98,13 @@ func NewMasterServer(r *mux.Router, option *MasterOption) *MasterServer {
 		grpcDialOpiton:  security.LoadClientTLS(v.Sub("grpc"), "master"),
 	}
 	ms.bounedLeaderChan = make(chan int, 16)
-	seq := sequence.NewMemorySequencer()
+	seq, err := sequence.NewFileSequencer(filepath.Join(ms.option.MetaFolder, "sequence.hwm"))
+	if err != nil {
+		glog.Fatalf("master sequencer: %v", err)
+	}
 	ms.Topo = topology.NewTopology("topo", seq, uint64(ms.option.VolumeSizeLimitMB)*1024*1024, ms.option.PulseSeconds)
+	ms.Topo.ConfigureFlappingDetection(ms.option.FlappingWindow, ms.option.FlappingThreshold)
+	ms.Topo.ConfigureUtilizationHistory(ms.option.ForecastInterval, ms.option.ForecastRetention)
 	ms.vg = topology.NewDefaultVolumeGrowth()
 	glog.V(0).Infoln("Volume Size Limit is", ms.option.VolumeSizeLimitMB, "MB")
 
@@ -92,28 +115,39 @@ func NewMasterServer(r *mux.Router, option *MasterOption) *MasterServer {
 		r.HandleFunc("/", ms.proxyToLeader(ms.uiStatusHandler))
 		r.HandleFunc("/ui/index.html", ms.uiStatusHandler)
 		r.HandleFunc("/dir/assign", ms.proxyToLeader(ms.guard.WhiteList(ms.dirAssignHandler)))
-		r.HandleFunc("/dir/lookup", ms.proxyToLeader(ms.guard.WhiteList(ms.dirLookupHandler)))
-		r.HandleFunc("/dir/status", ms.proxyToLeader(ms.guard.WhiteList(ms.dirStatusHandler)))
+		r.HandleFunc("/dir/lookup", ms.proxyToLeaderUnlessStandby(lookupRequestVolumeId, ms.guard.WhiteList(ms.dirLookupHandler)))
+		r.HandleFunc("/dir/status", ms.proxyToLeaderCached(ms.guard.WhiteList(ms.dirStatusHandler)))
 		r.HandleFunc("/col/delete", ms.proxyToLeader(ms.guard.WhiteList(ms.collectionDeleteHandler)))
 		r.HandleFunc("/vol/grow", ms.proxyToLeader(ms.guard.WhiteList(ms.volumeGrowHandler)))
-		r.HandleFunc("/vol/status", ms.proxyToLeader(ms.guard.WhiteList(ms.volumeStatusHandler)))
+		r.HandleFunc("/vol/status", ms.proxyToLeaderCached(ms.guard.WhiteList(ms.volumeStatusHandler)))
 		r.HandleFunc("/vol/vacuum", ms.proxyToLeader(ms.guard.WhiteList(ms.volumeVacuumHandler)))
+		r.HandleFunc("/vol/quarantine", ms.proxyToLeader(ms.guard.WhiteList(ms.volumeServerQuarantineHandler)))
+		r.HandleFunc("/vol/forecast", ms.proxyToLeader(ms.guard.WhiteList(ms.volumeServerForecastHandler)))
 		r.HandleFunc("/submit", ms.guard.WhiteList(ms.submitFromMasterServerHandler))
 		r.HandleFunc("/stats/health", ms.guard.WhiteList(statsHealthHandler))
 		r.HandleFunc("/stats/counter", ms.guard.WhiteList(statsCounterHandler))
 		r.HandleFunc("/stats/memory", ms.guard.WhiteList(statsMemoryHandler))
-		r.HandleFunc("/{fileId}", ms.proxyToLeader(ms.redirectHandler))
+		r.Handle("/metrics", promhttp.HandlerFor(stats.MasterGather, promhttp.HandlerOpts{}))
+		r.HandleFunc("/{fileId}", ms.proxyToLeaderUnlessStandby(redirectRequestVolumeId, ms.redirectHandler))
 	}
 
 	ms.Topo.StartRefreshWritableVolumes(ms.grpcDialOpiton, ms.option.GarbageThreshold, ms.preallocateSize)
 
 	ms.startAdminScripts()
 
+	ms.startEcEncodingPolicy()
+	ms.startEcRebuildScheduler()
+	ms.startEmptyVolumeDeletion()
+	ms.startVolumeGrowthPreallocation()
+
 	return ms
 }
 
 func (ms *MasterServer) SetRaftServer(raftServer *RaftServer) {
 	ms.Topo.RaftServer = raftServer.raftServer
+	if ms.option.StandbyMaxStaleness > 0 && len(raftServer.peers) > 1 {
+		ms.startStandby(raftServer.serverAddr, raftServer.peers)
+	}
 	ms.Topo.RaftServer.AddEventListener(raft.LeaderChangeEventType, func(e raft.Event) {
 		glog.V(0).Infof("event: %+v", e)
 		if ms.Topo.RaftServer.Leader() != "" {
@@ -177,16 +211,7 @@ func (ms *MasterServer) startAdminScripts() {
 
 	scriptLines := strings.Split(adminScripts, "\n")
 
-	masterAddress := "localhost:" + strconv.Itoa(ms.option.Port)
-
-	var shellOptions shell.ShellOptions
-	shellOptions.GrpcDialOption = security.LoadClientTLS(viper.Sub("grpc"), "master")
-	shellOptions.Masters = &masterAddress
-	shellOptions.FilerHost = "localhost"
-	shellOptions.FilerPort = 8888
-	shellOptions.Directory = "/"
-
-	commandEnv := shell.NewCommandEnv(shellOptions)
+	commandEnv := ms.newShellCommandEnv()
 
 	reg, _ := regexp.Compile(`'.*?'|".*?"|\S+`)
 
@@ -208,18 +233,34 @@ func (ms *MasterServer) startAdminScripts() {
 					for i := range args {
 						args[i] = strings.Trim(string(cmds[1+i]), "\"'")
 					}
-					cmd := strings.ToLower(cmds[0])
-
-					for _, c := range shell.Commands {
-						if c.Name() == cmd {
-							glog.V(0).Infof("executing: %s %v", cmd, args)
-							if err := c.Do(args, commandEnv, os.Stdout); err != nil {
-								glog.V(0).Infof("error: %v", err)
-							}
-						}
-					}
+					runShellCommand(commandEnv, strings.ToLower(cmds[0]), args)
 				}
 			}
 		}
 	}()
 }
+
+func (ms *MasterServer) newShellCommandEnv() *shell.CommandEnv {
+	masterAddress := "localhost:" + strconv.Itoa(ms.option.Port)
+
+	var shellOptions shell.ShellOptions
+	shellOptions.GrpcDialOption = security.LoadClientTLS(viper.Sub("grpc"), "master")
+	shellOptions.Masters = &masterAddress
+	shellOptions.FilerHost = "localhost"
+	shellOptions.FilerPort = 8888
+	shellOptions.Directory = "/"
+
+	return shell.NewCommandEnv(shellOptions)
+}
+
+func runShellCommand(commandEnv *shell.CommandEnv, cmd string, args []string) (err error) {
+	for _, c := range shell.Commands {
+		if c.Name() == cmd {
+			glog.V(0).Infof("executing: %s %v", cmd, args)
+			if err = c.Do(args, commandEnv, os.Stdout); err != nil {
+				glog.V(0).Infof("error: %v", err)
+			}
+		}
+	}
+	return
+}
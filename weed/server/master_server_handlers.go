@@ -13,6 +13,11 @@ import (
 )
 
 func (ms *MasterServer) lookupVolumeId(vids []string, collection string) (volumeLocations map[string]operation.LookupResult) {
+	if ms.isStandbyServing() {
+		if standbyLocations, found := ms.standbyLookupVolumeId(vids); found {
+			return standbyLocations
+		}
+	}
 	volumeLocations = make(map[string]operation.LookupResult)
 	for _, vid := range vids {
 		commaSep := strings.Index(vid, ",")
@@ -28,7 +33,12 @@ func (ms *MasterServer) lookupVolumeId(vids []string, collection string) (volume
 			if machines != nil {
 				var ret []operation.Location
 				for _, dn := range machines {
-					ret = append(ret, operation.Location{Url: dn.Url(), PublicUrl: dn.PublicUrl})
+					ret = append(ret, operation.Location{
+						Url:        dn.Url(),
+						PublicUrl:  dn.PublicUrl,
+						DataCenter: string(dn.GetDataCenter().Id()),
+						Rack:       string(dn.GetRack().Id()),
+					})
 				}
 				volumeLocations[vid] = operation.LookupResult{VolumeId: vid, Locations: ret}
 			} else {
@@ -44,6 +54,26 @@ func (ms *MasterServer) lookupVolumeId(vids []string, collection string) (volume
 // If "fileId" is provided, this returns the fileId location and a JWT to update or delete the file.
 // If "volumeId" is provided, this only returns the volumeId location
 func (ms *MasterServer) dirLookupHandler(w http.ResponseWriter, r *http.Request) {
+	vid := lookupRequestVolumeId(r)
+	fileId := r.FormValue("fileId")
+	vids := []string{vid}
+	collection := r.FormValue("collection") //optional, but can be faster if too many collections
+	volumeLocations := ms.lookupVolumeId(vids, collection)
+	location := volumeLocations[vid]
+	httpStatus := http.StatusOK
+	if location.Error != "" {
+		httpStatus = http.StatusNotFound
+	} else {
+		forRead := r.FormValue("read")
+		isRead := forRead == "yes"
+		ms.maybeAddJwtAuthorization(w, fileId, !isRead)
+		location.TopologyVersion = ms.lookupTopologyVersion()
+		location.CacheTtlSeconds = uint32(ms.option.LookupCacheTtl.Seconds())
+	}
+	writeJsonQuiet(w, r, httpStatus, location)
+}
+
+func lookupRequestVolumeId(r *http.Request) string {
 	vid := r.FormValue("volumeId")
 	if vid != "" {
 		// backward compatible
@@ -59,19 +89,15 @@ func (ms *MasterServer) dirLookupHandler(w http.ResponseWriter, r *http.Request)
 			vid = fileId[0:commaSep]
 		}
 	}
-	vids := []string{vid}
-	collection := r.FormValue("collection") //optional, but can be faster if too many collections
-	volumeLocations := ms.lookupVolumeId(vids, collection)
-	location := volumeLocations[vid]
-	httpStatus := http.StatusOK
-	if location.Error != "" {
-		httpStatus = http.StatusNotFound
-	} else {
-		forRead := r.FormValue("read")
-		isRead := forRead == "yes"
-		ms.maybeAddJwtAuthorization(w, fileId, !isRead)
+	return vid
+}
+
+// lookupTopologyVersion is only known by the leader, and 0 from the standby masters
+func (ms *MasterServer) lookupTopologyVersion() uint64 {
+	if !ms.Topo.IsLeader() {
+		return 0
 	}
-	writeJsonQuiet(w, r, httpStatus, location)
+	return ms.Topo.Version()
 }
 
 func (ms *MasterServer) dirAssignHandler(w http.ResponseWriter, r *http.Request) {
@@ -80,12 +106,22 @@ func (ms *MasterServer) dirAssignHandler(w http.ResponseWriter, r *http.Request)
 	if e != nil || requestedCount == 0 {
 		requestedCount = 1
 	}
+	isBatch := r.FormValue("batch") == "true"
+	if isBatch && requestedCount > maxAssignBatchCount {
+		writeJsonQuiet(w, r, http.StatusNotAcceptable, operation.AssignResult{Error: fmt.Sprintf("batch count %d is over %d", requestedCount, maxAssignBatchCount)})
+		return
+	}
 
 	option, err := ms.getVolumeGrowOption(r)
 	if err != nil {
 		writeJsonQuiet(w, r, http.StatusNotAcceptable, operation.AssignResult{Error: err.Error()})
 		return
 	}
+	writeFlags, err := needle.ParseWriteFlags(r.FormValue("fsync"), r.FormValue("ack"))
+	if err != nil {
+		writeJsonQuiet(w, r, http.StatusNotAcceptable, operation.AssignResult{Error: err.Error()})
+		return
+	}
 
 	if !ms.Topo.HasWritableVolume(option) {
 		if ms.Topo.FreeSpace() <= 0 {
@@ -104,8 +140,16 @@ func (ms *MasterServer) dirAssignHandler(w http.ResponseWriter, r *http.Request)
 	}
 	fid, count, dn, err := ms.Topo.PickForWrite(requestedCount, option)
 	if err == nil {
-		ms.maybeAddJwtAuthorization(w, fid, true)
-		writeJsonQuiet(w, r, http.StatusOK, operation.AssignResult{Fid: fid, Url: dn.Url(), PublicUrl: dn.PublicUrl, Count: count})
+		ms.maybeAddWriteJwtAuthorization(w, fid, count, writeFlags)
+		result := operation.AssignResult{Fid: fid, Url: dn.Url(), PublicUrl: dn.PublicUrl, Count: count,
+			Fsync: writeFlags.Fsync, Ack: writeFlags.Ack}
+		if isBatch {
+			if result.Fids, err = batchFileIds(fid, count); err != nil {
+				writeJsonError(w, r, http.StatusInternalServerError, err)
+				return
+			}
+		}
+		writeJsonQuiet(w, r, http.StatusOK, result)
 	} else {
 		writeJsonQuiet(w, r, http.StatusNotAcceptable, operation.AssignResult{Error: err.Error()})
 	}
@@ -124,3 +168,24 @@ func (ms *MasterServer) maybeAddJwtAuthorization(w http.ResponseWriter, fileId s
 
 	w.Header().Set("Authorization", "BEARER "+string(encodedJwt))
 }
+
+func (ms *MasterServer) maybeAddWriteJwtAuthorization(w http.ResponseWriter, fileId string, count uint64, writeFlags needle.WriteFlags) {
+	encodedJwt := security.GenWriteJwt(ms.guard.SigningKey, ms.guard.ExpiresAfterSec, fileId, count, writeFlags.Fsync, writeFlags.Ack)
+	if encodedJwt == "" {
+		return
+	}
+
+	w.Header().Set("Authorization", "BEARER "+string(encodedJwt))
+}
+
+// the batch assign lists all the file ids, so the count is limited to keep the response small
+const maxAssignBatchCount = 10000
+
+// batchFileIds lists the count consecutive file ids from the assigned fid, all on the same volume
+func batchFileIds(fid string, count uint64) ([]string, error) {
+	fileId, err := needle.ParseFileIdFromString(fid)
+	if err != nil {
+		return nil, fmt.Errorf("parse assigned fid %s: %v", fid, err)
+	}
+	return fileId.Batch(count), nil
+}
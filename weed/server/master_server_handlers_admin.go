@@ -20,6 +20,13 @@ import (
 func (ms *MasterServer) collectionDeleteHandler(w http.ResponseWriter, r *http.Request) {
 	collection, ok := ms.Topo.FindCollection(r.FormValue("collection"))
 	if !ok {
+		if _, found := ms.Topo.GetCollectionConfig(r.FormValue("collection")); found {
+			// created without any volume yet
+			if err := ms.deleteCollectionConfig(r.FormValue("collection")); err != nil {
+				writeJsonError(w, r, http.StatusInternalServerError, err)
+			}
+			return
+		}
 		writeJsonError(w, r, http.StatusBadRequest, fmt.Errorf("collection %s does not exist", r.FormValue("collection")))
 		return
 	}
@@ -36,6 +43,9 @@ func (ms *MasterServer) collectionDeleteHandler(w http.ResponseWriter, r *http.R
 		}
 	}
 	ms.Topo.DeleteCollection(r.FormValue("collection"))
+	if err := ms.deleteCollectionConfig(r.FormValue("collection")); err != nil {
+		writeJsonError(w, r, http.StatusInternalServerError, err)
+	}
 }
 
 func (ms *MasterServer) dirStatusHandler(w http.ResponseWriter, r *http.Request) {
@@ -45,6 +55,43 @@ func (ms *MasterServer) dirStatusHandler(w http.ResponseWriter, r *http.Request)
 	writeJsonQuiet(w, r, http.StatusOK, m)
 }
 
+// volumeServerQuarantineHandler lists the quarantined flapping volume servers,
+// or clears the volume server in the "clear" parameter, as ip:port, to take the writes again.
+func (ms *MasterServer) volumeServerQuarantineHandler(w http.ResponseWriter, r *http.Request) {
+	if url := r.FormValue("clear"); url != "" {
+		found, err := ms.Topo.ClearQuarantine(url)
+		if err != nil {
+			writeJsonError(w, r, http.StatusInternalServerError, fmt.Errorf("clear volume server %s: %v", url, err))
+			return
+		}
+		if !found {
+			writeJsonError(w, r, http.StatusNotFound, fmt.Errorf("volume server %s is not quarantined", url))
+			return
+		}
+	}
+	writeJsonQuiet(w, r, http.StatusOK, map[string]interface{}{
+		"Quarantined": ms.Topo.ListQuarantined(),
+	})
+}
+
+// volumeServerForecastHandler forecasts the days until each volume server is full,
+// by the "linear" regression or the "ewma" of the sampled used bytes.
+// curl "http://localhost:9333/vol/forecast?method=ewma"
+func (ms *MasterServer) volumeServerForecastHandler(w http.ResponseWriter, r *http.Request) {
+	method := r.FormValue("method")
+	if method == "" {
+		method = topology.ForecastLinear
+	}
+	if method != topology.ForecastLinear && method != topology.ForecastEwma {
+		writeJsonError(w, r, http.StatusBadRequest, fmt.Errorf("unknown forecast method %s", method))
+		return
+	}
+	writeJsonQuiet(w, r, http.StatusOK, map[string]interface{}{
+		"Method":    method,
+		"Forecasts": ms.Topo.ForecastUtilization(method),
+	})
+}
+
 func (ms *MasterServer) volumeVacuumHandler(w http.ResponseWriter, r *http.Request) {
 	gcString := r.FormValue("garbageThreshold")
 	gcThreshold := ms.option.GarbageThreshold
@@ -101,8 +148,8 @@ func (ms *MasterServer) redirectHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	collection := r.FormValue("collection")
-	machines := ms.Topo.Lookup(collection, volumeId)
-	if machines != nil && len(machines) > 0 {
+	machines := ms.lookupVolumeId([]string{vid}, collection)[vid].Locations
+	if len(machines) > 0 {
 		var url string
 		if r.URL.RawQuery != "" {
 			url = util.NormalizeUrl(machines[rand.Intn(len(machines))].PublicUrl) + r.URL.Path + "?" + r.URL.RawQuery
@@ -115,6 +162,11 @@ func (ms *MasterServer) redirectHandler(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+func redirectRequestVolumeId(r *http.Request) string {
+	vid, _, _, _, _ := parseURLPath(r.URL.Path)
+	return vid
+}
+
 func (ms *MasterServer) selfUrl(r *http.Request) string {
 	if r.Host != "" {
 		return r.Host
@@ -140,15 +192,12 @@ func (ms *MasterServer) HasWritableVolume(option *topology.VolumeGrowOption) boo
 }
 
 func (ms *MasterServer) getVolumeGrowOption(r *http.Request) (*topology.VolumeGrowOption, error) {
-	replicationString := r.FormValue("replication")
-	if replicationString == "" {
-		replicationString = ms.option.DefaultReplicaPlacement
-	}
+	replicationString, ttlString := ms.collectionDefaults(r.FormValue("collection"), r.FormValue("replication"), r.FormValue("ttl"))
 	replicaPlacement, err := storage.NewReplicaPlacementFromString(replicationString)
 	if err != nil {
 		return nil, err
 	}
-	ttl, err := needle.ReadTTL(r.FormValue("ttl"))
+	ttl, err := needle.ReadTTL(ttlString)
 	if err != nil {
 		return nil, err
 	}
@@ -159,14 +208,21 @@ func (ms *MasterServer) getVolumeGrowOption(r *http.Request) (*topology.VolumeGr
 			return nil, fmt.Errorf("Failed to parse int64 preallocate = %s: %v", r.FormValue("preallocate"), err)
 		}
 	}
+	labelSelector, err := storage.ParseLabelSelector(r.FormValue("labelSelector"))
+	if err != nil {
+		return nil, err
+	}
 	volumeGrowOption := &topology.VolumeGrowOption{
 		Collection:       r.FormValue("collection"),
 		ReplicaPlacement: replicaPlacement,
-		Ttl:              ttl,
-		Prealloacte:      preallocate,
+		Ttl:              ms.Topo.LayoutTtl(r.FormValue("collection"), ttl),
+		Prealloacte:      ms.collectionPreallocate(r.FormValue("collection"), preallocate),
 		DataCenter:       r.FormValue("dataCenter"),
 		Rack:             r.FormValue("rack"),
 		DataNode:         r.FormValue("dataNode"),
+		LocalityGroup:    r.FormValue("localityGroup"),
+		Session:          r.FormValue("session"),
+		LabelSelector:    labelSelector,
 	}
 	return volumeGrowOption, nil
 }
@@ -0,0 +1,115 @@
+package weed_server
+
+import (
+	"net/http"
+
+	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
+)
+
+// ClusterStatusResult is the json of the ClusterStatus, keeping the IsLeader, Leader and Peers of the earlier versions.
+// The capacity and the data centers are only reported by the leader.
+type ClusterStatusResult struct {
+	IsLeader                   bool     `json:"IsLeader,omitempty"`
+	Leader                     string   `json:"Leader,omitempty"`
+	Peers                      []string `json:"Peers,omitempty"`
+	RaftState                  string
+	RaftTerm                   uint64
+	RaftCommitIndex            uint64
+	PeerLastActivitySecondsAgo map[string]int64    `json:",omitempty"`
+	Capacity                   *CapacityStatus     `json:",omitempty"`
+	DataCenters                []*DataCenterStatus `json:",omitempty"`
+	VolumeSizeLimitMB          uint64              `json:",omitempty"`
+}
+
+type CapacityStatus struct {
+	Max                     uint64
+	Volumes                 uint64
+	ActiveVolumes           uint64
+	Free                    uint64
+	EcShards                uint64
+	VolumeServers           uint64
+	UnwritableVolumeServers uint64
+}
+
+type DataCenterStatus struct {
+	Id       string
+	Capacity *CapacityStatus
+	Racks    []*RackStatus
+}
+
+type RackStatus struct {
+	Id            string
+	Capacity      *CapacityStatus
+	VolumeServers []*VolumeServerStatus
+}
+
+type VolumeServerStatus struct {
+	Url                     string
+	PublicUrl               string
+	Capacity                *CapacityStatus
+	LastHeartbeatSecondsAgo int64
+	Draining                bool
+	ReadOnly                bool
+	Quarantined             bool
+}
+
+// ClusterStatusHandler reports the raft status, and the capacity and the health of the volume servers by the data centers and the racks.
+// curl "http://localhost:9333/cluster/status?pretty=y"
+func (ms *MasterServer) ClusterStatusHandler(w http.ResponseWriter, r *http.Request) {
+	status := ms.clusterStatus()
+
+	ret := ClusterStatusResult{
+		IsLeader:          status.IsLeader,
+		Leader:            status.Leader,
+		RaftState:         status.RaftState,
+		RaftTerm:          status.RaftTerm,
+		RaftCommitIndex:   status.RaftCommitIndex,
+		Capacity:          toCapacityStatus(status.Capacity),
+		VolumeSizeLimitMB: status.VolumeSizeLimitMb,
+	}
+	for _, peer := range status.Peers {
+		ret.Peers = append(ret.Peers, peer.Name)
+		if peer.LastActivitySecondsAgo >= 0 {
+			if ret.PeerLastActivitySecondsAgo == nil {
+				ret.PeerLastActivitySecondsAgo = make(map[string]int64)
+			}
+			ret.PeerLastActivitySecondsAgo[peer.Name] = peer.LastActivitySecondsAgo
+		}
+	}
+	for _, dc := range status.DataCenters {
+		dcStatus := &DataCenterStatus{Id: dc.Id, Capacity: toCapacityStatus(dc.Capacity)}
+		for _, rack := range dc.Racks {
+			rackStatus := &RackStatus{Id: rack.Id, Capacity: toCapacityStatus(rack.Capacity)}
+			for _, server := range rack.VolumeServers {
+				rackStatus.VolumeServers = append(rackStatus.VolumeServers, &VolumeServerStatus{
+					Url:                     server.Url,
+					PublicUrl:               server.PublicUrl,
+					Capacity:                toCapacityStatus(server.Capacity),
+					LastHeartbeatSecondsAgo: server.LastHeartbeatSecondsAgo,
+					Draining:                server.IsDraining,
+					ReadOnly:                server.IsReadOnly,
+					Quarantined:             server.IsQuarantined,
+				})
+			}
+			dcStatus.Racks = append(dcStatus.Racks, rackStatus)
+		}
+		ret.DataCenters = append(ret.DataCenters, dcStatus)
+	}
+
+	writeJsonQuiet(w, r, http.StatusOK, ret)
+}
+
+func toCapacityStatus(c *master_pb.CapacityStatus) *CapacityStatus {
+	if c == nil {
+		return nil
+	}
+	return &CapacityStatus{
+		Max:                     c.MaxVolumeCount,
+		Volumes:                 c.VolumeCount,
+		ActiveVolumes:           c.ActiveVolumeCount,
+		Free:                    c.FreeVolumeCount,
+		EcShards:                c.EcShardCount,
+		VolumeServers:           c.VolumeServerCount,
+		UnwritableVolumeServers: c.UnwritableVolumeServerCount,
+	}
+}
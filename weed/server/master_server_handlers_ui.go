@@ -2,28 +2,127 @@ package weed_server
 
 import (
 	"net/http"
+	"sort"
 
 	"github.com/chrislusf/raft"
+	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
 	ui "github.com/chrislusf/seaweedfs/weed/server/master_ui"
 	"github.com/chrislusf/seaweedfs/weed/stats"
+	"github.com/chrislusf/seaweedfs/weed/storage"
+	"github.com/chrislusf/seaweedfs/weed/storage/erasure_coding"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
 	"github.com/chrislusf/seaweedfs/weed/util"
 )
 
 func (ms *MasterServer) uiStatusHandler(w http.ResponseWriter, r *http.Request) {
 	infos := make(map[string]interface{})
 	infos["Version"] = util.VERSION
+	topologyInfo := ms.Topo.ToTopologyInfo()
+	volumeSizeLimit := uint64(ms.option.VolumeSizeLimitMB) * 1024 * 1024
 	args := struct {
-		Version    string
-		Topology   interface{}
-		RaftServer raft.Server
-		Stats      map[string]interface{}
-		Counters   *stats.ServerStats
+		Version     string
+		Topology    interface{}
+		DataCenters []*ui.DataCenterView
+		EcVolumes   []*ui.EcVolumeView
+		IsAdmin     bool
+		RaftServer  raft.Server
+		Stats       map[string]interface{}
+		Counters    *stats.ServerStats
 	}{
 		util.VERSION,
 		ms.Topo.ToMap(),
+		toDataCenterViews(topologyInfo, volumeSizeLimit),
+		toEcVolumeViews(topologyInfo),
+		ms.guard.IsWhiteListed(r),
 		ms.Topo.RaftServer,
 		infos,
 		serverStats,
 	}
 	ui.StatusTpl.Execute(w, args)
 }
+
+func toDataCenterViews(t *master_pb.TopologyInfo, volumeSizeLimit uint64) (dcs []*ui.DataCenterView) {
+	for _, dcInfo := range t.DataCenterInfos {
+		dc := &ui.DataCenterView{Id: dcInfo.Id}
+		for _, rackInfo := range dcInfo.RackInfos {
+			rack := &ui.RackView{Id: rackInfo.Id}
+			for _, dnInfo := range rackInfo.DataNodeInfos {
+				dn := &ui.DataNodeView{DataNodeInfo: dnInfo}
+				for _, v := range dnInfo.VolumeInfos {
+					dn.Volumes = append(dn.Volumes, toVolumeView(v, volumeSizeLimit))
+				}
+				sort.Slice(dn.Volumes, func(i, j int) bool {
+					return dn.Volumes[i].Id < dn.Volumes[j].Id
+				})
+				for _, ecShardInfo := range dnInfo.EcShardInfos {
+					dn.EcShardCount += erasure_coding.ShardBits(ecShardInfo.EcIndexBits).ShardIdCount()
+				}
+				rack.VolumeCount += dnInfo.VolumeCount
+				rack.MaxVolumeCount += dnInfo.MaxVolumeCount
+				rack.DataNodes = append(rack.DataNodes, dn)
+			}
+			dc.VolumeCount += rack.VolumeCount
+			dc.MaxVolumeCount += rack.MaxVolumeCount
+			dc.Racks = append(dc.Racks, rack)
+		}
+		dcs = append(dcs, dc)
+	}
+	return
+}
+
+func toVolumeView(v *master_pb.VolumeInformationMessage, volumeSizeLimit uint64) *ui.VolumeView {
+	view := &ui.VolumeView{
+		VolumeInformationMessage: v,
+		Ttl:                      needle.LoadTTLFromUint32(v.Ttl).String(),
+	}
+	if rp, err := storage.NewReplicaPlacementFromByte(byte(v.ReplicaPlacement)); err == nil {
+		view.ReplicaPlacement = rp.String()
+	}
+	if volumeSizeLimit > 0 {
+		view.FillPercent = int(v.Size * 100 / volumeSizeLimit)
+		if view.FillPercent > 100 {
+			view.FillPercent = 100
+		}
+	}
+	return view
+}
+
+// toEcVolumeViews lists the shards of each ec volume by the volume servers
+func toEcVolumeViews(t *master_pb.TopologyInfo) (ecVolumes []*ui.EcVolumeView) {
+	vidToEcVolume := make(map[uint32]*ui.EcVolumeView)
+	vidToShardBits := make(map[uint32]erasure_coding.ShardBits)
+	for _, dcInfo := range t.DataCenterInfos {
+		for _, rackInfo := range dcInfo.RackInfos {
+			for _, dnInfo := range rackInfo.DataNodeInfos {
+				for _, ecShardInfo := range dnInfo.EcShardInfos {
+					ecVolume, found := vidToEcVolume[ecShardInfo.Id]
+					if !found {
+						ecVolume = &ui.EcVolumeView{Id: ecShardInfo.Id, Collection: ecShardInfo.Collection}
+						vidToEcVolume[ecShardInfo.Id] = ecVolume
+						ecVolumes = append(ecVolumes, ecVolume)
+					}
+					if ecShardInfo.DeleteCount > ecVolume.DeleteCount {
+						ecVolume.DeleteCount = ecShardInfo.DeleteCount
+						ecVolume.ReclaimableByteCount = ecShardInfo.ReclaimableByteCount
+					}
+					shardBits := erasure_coding.ShardBits(ecShardInfo.EcIndexBits)
+					vidToShardBits[ecShardInfo.Id] = vidToShardBits[ecShardInfo.Id].Plus(shardBits)
+					ecVolume.Locations = append(ecVolume.Locations, &ui.EcShardLocationView{
+						Url:      dnInfo.Id,
+						Rack:     rackInfo.Id,
+						ShardIds: shardBits.ShardIds(),
+					})
+				}
+			}
+		}
+	}
+	sort.Slice(ecVolumes, func(i, j int) bool {
+		return ecVolumes[i].Id < ecVolumes[j].Id
+	})
+	for _, ecVolume := range ecVolumes {
+		// the same shard could be on several volume servers, so only the distinct shards are counted
+		ecVolume.ShardCount = vidToShardBits[ecVolume.Id].ShardIdCount()
+		ecVolume.MissingShardCount = erasure_coding.TotalShardsCount - ecVolume.ShardCount
+	}
+	return
+}
@@ -0,0 +1,145 @@
+package weed_server
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/operation"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+	"github.com/chrislusf/seaweedfs/weed/wdclient"
+)
+
+// The volume servers only report to the leader, so the standby masters follow the volume locations
+// from the leader, as the other clients do, and answer the lookups of the known volumes by themselves,
+// as long as the locations are followed within the StandbyMaxStaleness.
+// The unknown volumes, the assigns and the other changes still go to the leader.
+
+const standbyResponseCacheLimit = 64
+
+type standbyResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+	time   time.Time
+}
+
+func (r *standbyResponse) Header() http.Header {
+	return r.header
+}
+
+func (r *standbyResponse) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.body.Write(p)
+}
+
+func (r *standbyResponse) WriteHeader(status int) {
+	r.status = status
+}
+
+type standbyResponseCache struct {
+	sync.Mutex
+	responses map[string]*standbyResponse
+}
+
+func (ms *MasterServer) startStandby(serverAddr string, peers []string) {
+	ms.standby = wdclient.NewMasterClient(context.Background(), ms.grpcDialOpiton, "standby@"+serverAddr, peers)
+	ms.standbyResponses.responses = make(map[string]*standbyResponse)
+	go ms.standby.KeepConnectedToMaster()
+	glog.V(0).Infof("standby master follows the volume locations from the leader, within %v", ms.option.StandbyMaxStaleness)
+}
+
+func (ms *MasterServer) isStandbyServing() bool {
+	return ms.standby != nil && !ms.Topo.IsLeader() && ms.standby.Staleness() <= ms.option.StandbyMaxStaleness
+}
+
+// standbyLookupVolumeId looks up the volumes in the locations followed from the leader.
+// It is not found if any volume is unknown, to be looked up on the leader, which may just have it.
+func (ms *MasterServer) standbyLookupVolumeId(vids []string) (volumeLocations map[string]operation.LookupResult, found bool) {
+	volumeLocations = make(map[string]operation.LookupResult)
+	for _, vid := range vids {
+		commaSep := strings.Index(vid, ",")
+		if commaSep > 0 {
+			vid = vid[0:commaSep]
+		}
+		volumeId, err := needle.NewVolumeId(vid)
+		if err != nil {
+			return nil, false
+		}
+		locations := ms.standby.GetLocations(uint32(volumeId))
+		if len(locations) == 0 {
+			return nil, false
+		}
+		var ret []operation.Location
+		for _, loc := range locations {
+			ret = append(ret, operation.Location{Url: loc.Url, PublicUrl: loc.PublicUrl, DataCenter: loc.DataCenter, Rack: loc.Rack})
+		}
+		volumeLocations[vid] = operation.LookupResult{VolumeId: vid, Locations: ret}
+	}
+	return volumeLocations, true
+}
+
+// proxyToLeaderUnlessStandby serves the lookups of the known volumes on the standby masters, and proxies the others to the leader
+func (ms *MasterServer) proxyToLeaderUnlessStandby(volumeIdOf func(r *http.Request) string, f func(w http.ResponseWriter, r *http.Request)) func(w http.ResponseWriter, r *http.Request) {
+	proxy := ms.proxyToLeader(f)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if ms.isStandbyServing() {
+			if _, found := ms.standbyLookupVolumeId([]string{volumeIdOf(r)}); found {
+				f(w, r)
+				return
+			}
+		}
+		proxy(w, r)
+	}
+}
+
+// proxyToLeaderCached serves the stats on the standby masters from the responses of the leader,
+// cached for up to the StandbyMaxStaleness
+func (ms *MasterServer) proxyToLeaderCached(f func(w http.ResponseWriter, r *http.Request)) func(w http.ResponseWriter, r *http.Request) {
+	proxy := ms.proxyToLeader(f)
+	cached := ms.guard.WhiteList(func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.RequestURI()
+		cache := &ms.standbyResponses
+
+		cache.Lock()
+		resp, found := cache.responses[key]
+		cache.Unlock()
+
+		if !found || time.Since(resp.time) > ms.option.StandbyMaxStaleness {
+			resp = &standbyResponse{header: make(http.Header)}
+			proxy(resp, r)
+			resp.time = time.Now()
+			if resp.status == http.StatusOK {
+				cache.Lock()
+				if len(cache.responses) >= standbyResponseCacheLimit {
+					cache.responses = make(map[string]*standbyResponse)
+				}
+				cache.responses[key] = resp
+				cache.Unlock()
+			}
+		}
+
+		if resp.status == 0 {
+			// no leader to proxy to
+			return
+		}
+		for k, v := range resp.header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(resp.status)
+		w.Write(resp.body.Bytes())
+	})
+	return func(w http.ResponseWriter, r *http.Request) {
+		if ms.standby == nil || ms.Topo.IsLeader() || r.Method != "GET" {
+			proxy(w, r)
+			return
+		}
+		cached(w, r)
+	}
+}
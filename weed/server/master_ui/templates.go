@@ -2,13 +2,26 @@ package master_ui
 
 import (
 	"html/template"
+
+	"github.com/dustin/go-humanize"
 )
 
-var StatusTpl = template.Must(template.New("status").Parse(`<!DOCTYPE html>
+var funcMap = template.FuncMap{
+	"humanizeBytes": humanize.Bytes,
+}
+
+var StatusTpl = template.Must(template.New("status").Funcs(funcMap).Parse(`<!DOCTYPE html>
 <html>
   <head>
     <title>SeaweedFS {{ .Version }}</title>
 	<link rel="stylesheet" href="/seaweedfsstatic/bootstrap/3.3.1/css/bootstrap.min.css">
+    <style>
+      details { margin-left: 20px; }
+      details > summary { cursor: pointer; padding: 4px 0; }
+      .topology-tree > details { margin-left: 0; }
+      .volumes .progress { margin-bottom: 0; min-width: 120px; }
+      .admin-form { margin-bottom: 10px; }
+    </style>
   </head>
   <body>
     <div class="container">
@@ -67,39 +80,175 @@ var StatusTpl = template.Must(template.New("status").Parse(`<!DOCTYPE html>
         </div>
       </div>
 
+      {{ if .IsAdmin }}
+      <div class="row">
+        <h2>Admin</h2>
+        <form class="form-inline admin-form" id="vacuum-form">
+          <div class="form-group">
+            <label for="garbageThreshold">Garbage threshold</label>
+            <input type="text" class="form-control" id="garbageThreshold" name="garbageThreshold" placeholder="0.3">
+          </div>
+          <button type="submit" class="btn btn-warning">Vacuum</button>
+        </form>
+        <form class="form-inline admin-form" id="grow-form">
+          <div class="form-group">
+            <label for="count">Count</label>
+            <input type="number" class="form-control" id="count" name="count" value="1" min="1">
+          </div>
+          <div class="form-group">
+            <label for="collection">Collection</label>
+            <input type="text" class="form-control" id="collection" name="collection">
+          </div>
+          <div class="form-group">
+            <label for="replication">Replication</label>
+            <input type="text" class="form-control" id="replication" name="replication" placeholder="000">
+          </div>
+          <div class="form-group">
+            <label for="ttl">TTL</label>
+            <input type="text" class="form-control" id="ttl" name="ttl" placeholder="3d">
+          </div>
+          <div class="form-group">
+            <label for="dataCenter">Data Center</label>
+            <input type="text" class="form-control" id="dataCenter" name="dataCenter">
+          </div>
+          <button type="submit" class="btn btn-primary">Grow</button>
+        </form>
+        <div class="alert" id="admin-result" style="display: none"></div>
+      </div>
+      {{ end }}
+
       <div class="row">
         <h2>Topology</h2>
+        <div class="topology-tree">
+        {{ range $dc := .DataCenters }}
+          <details open>
+            <summary><strong>Data Center <code>{{ $dc.Id }}</code></strong> {{ $dc.VolumeCount }}/{{ $dc.MaxVolumeCount }} volumes</summary>
+            {{ range $rack := $dc.Racks }}
+            <details open>
+              <summary><strong>Rack {{ $rack.Id }}</strong> {{ $rack.VolumeCount }}/{{ $rack.MaxVolumeCount }} volumes</summary>
+              {{ range $dn := $rack.DataNodes }}
+              <details>
+                <summary>
+                  <a href="http://{{ $dn.Id }}/ui/index.html">{{ $dn.Id }}</a>
+                  {{ $dn.VolumeCount }}/{{ $dn.MaxVolumeCount }} volumes, {{ $dn.EcShardCount }} ec shards{{ with $dn.DaysToFull }}, {{ printf "%.1f" . }} days to full{{ end }}
+                  {{ if $dn.IsDraining }}<span class="label label-warning">draining</span>{{ end }}
+                  {{ if $dn.IsReadOnly }}<span class="label label-default">read only</span>{{ end }}
+                  {{ if $dn.IsQuarantined }}<span class="label label-danger">quarantined</span>{{ end }}
+                  {{ range $k, $v := $dn.Labels }}<span class="label label-info">{{ $k }}={{ $v }}</span> {{ end }}
+                </summary>
+                {{ if $dn.Volumes }}
+                <table class="table table-condensed table-striped volumes">
+                  <thead>
+                    <tr>
+                      <th>Id</th>
+                      <th>Collection</th>
+                      <th>Size</th>
+                      <th>Fill</th>
+                      <th>Files</th>
+                      <th>Deleted</th>
+                      <th>Replication</th>
+                      <th>TTL</th>
+                      <th>ReadOnly</th>
+                    </tr>
+                  </thead>
+                  <tbody>
+                  {{ range $v := $dn.Volumes }}
+                    <tr>
+                      <td><code>{{ $v.Id }}</code></td>
+                      <td>{{ $v.Collection }}</td>
+                      <td>{{ $v.Size | humanizeBytes }}</td>
+                      <td>
+                        <div class="progress">
+                          <div class="progress-bar{{ if ge $v.FillPercent 90 }} progress-bar-danger{{ end }}" role="progressbar" style="width: {{ $v.FillPercent }}%">{{ $v.FillPercent }}%</div>
+                        </div>
+                      </td>
+                      <td>{{ $v.FileCount }}</td>
+                      <td>{{ $v.DeleteCount }} / {{ $v.DeletedByteCount | humanizeBytes }}</td>
+                      <td>{{ $v.ReplicaPlacement }}</td>
+                      <td>{{ $v.Ttl }}</td>
+                      <td>{{ if $v.ReadOnly }}<span class="label label-default">read only</span>{{ end }}</td>
+                    </tr>
+                  {{ end }}
+                  </tbody>
+                </table>
+                {{ end }}
+              </details>
+              {{ end }}
+            </details>
+            {{ end }}
+          </details>
+        {{ end }}
+        </div>
+      </div>
+
+      {{ if .EcVolumes }}
+      <div class="row">
+        <h2>Erasure Coding Shards</h2>
         <table class="table table-striped">
           <thead>
             <tr>
-              <th>Data Center</th>
-              <th>Rack</th>
-              <th>RemoteAddr</th>
-              <th>#Volumes</th>
-              <th>#ErasureCodingShards</th>
-              <th>Max</th>
+              <th>Volume</th>
+              <th>Collection</th>
+              <th>Shards</th>
+              <th>Deleted / Reclaimable</th>
+              <th>Distribution</th>
             </tr>
           </thead>
           <tbody>
-          {{ range $dc_index, $dc := .Topology.DataCenters }}
-            {{ range $rack_index, $rack := $dc.Racks }}
-              {{ range $dn_index, $dn := $rack.DataNodes }}
+          {{ range $ecv := .EcVolumes }}
             <tr>
-              <td><code>{{ $dc.Id }}</code></td>
-              <td>{{ $rack.Id }}</td>
-              <td><a href="http://{{ $dn.Url }}/ui/index.html">{{ $dn.Url }}</a></td>
-              <td>{{ $dn.Volumes }}</td>
-              <td>{{ $dn.EcShards }}</td>
-              <td>{{ $dn.Max }}</td>
-            </tr>
+              <td><code>{{ $ecv.Id }}</code></td>
+              <td>{{ $ecv.Collection }}</td>
+              <td>
+                {{ $ecv.ShardCount }}
+                {{ if gt $ecv.MissingShardCount 0 }}<span class="label label-danger">{{ $ecv.MissingShardCount }} missing</span>{{ end }}
+              </td>
+              <td>{{ $ecv.DeleteCount }} / {{ $ecv.ReclaimableByteCount | humanizeBytes }}</td>
+              <td><ul class="list-unstyled">
+              {{ range $loc := $ecv.Locations }}
+                <li><a href="http://{{ $loc.Url }}/ui/index.html">{{ $loc.Url }}</a> <small>rack {{ $loc.Rack }}</small>: {{ $loc.ShardIds }}</li>
               {{ end }}
-            {{ end }}
+              </ul></td>
+            </tr>
           {{ end }}
           </tbody>
         </table>
       </div>
+      {{ end }}
 
     </div>
+    {{ if .IsAdmin }}
+    <script>
+function submitAdminForm(form, path, confirmMessage) {
+  form.addEventListener("submit", function(e) {
+    e.preventDefault()
+    if (!confirm(confirmMessage)) {
+      return
+    }
+    let params = new URLSearchParams()
+    new FormData(form).forEach(function(value, key) {
+      if (value !== "") {
+        params.append(key, value)
+      }
+    })
+    let result = document.getElementById("admin-result")
+    fetch(path, {method: "POST", body: params}).then(function(response) {
+      return response.text().then(function(text) {
+        result.className = "alert " + (response.ok ? "alert-success" : "alert-danger")
+        result.textContent = response.status + " " + text
+        result.style.display = ""
+      })
+    }).catch(function(err) {
+      result.className = "alert alert-danger"
+      result.textContent = err
+      result.style.display = ""
+    })
+  })
+}
+submitAdminForm(document.getElementById("vacuum-form"), "/vol/vacuum", "Vacuum the volumes over the garbage threshold?")
+submitAdminForm(document.getElementById("grow-form"), "/vol/grow", "Grow the volumes?")
+    </script>
+    {{ end }}
   </body>
 </html>
 `))
@@ -0,0 +1,52 @@
+package master_ui
+
+import (
+	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
+	"github.com/chrislusf/seaweedfs/weed/storage/erasure_coding"
+)
+
+// the topology as the data center, rack and volume server tree, for the status page
+
+type DataCenterView struct {
+	Id             string
+	VolumeCount    uint64
+	MaxVolumeCount uint64
+	Racks          []*RackView
+}
+
+type RackView struct {
+	Id             string
+	VolumeCount    uint64
+	MaxVolumeCount uint64
+	DataNodes      []*DataNodeView
+}
+
+type DataNodeView struct {
+	*master_pb.DataNodeInfo
+	Volumes      []*VolumeView
+	EcShardCount int
+}
+
+type VolumeView struct {
+	*master_pb.VolumeInformationMessage
+	ReplicaPlacement string
+	Ttl              string
+	FillPercent      int // the size in percentage of the volume size limit
+}
+
+type EcVolumeView struct {
+	Id                uint32
+	Collection        string
+	ShardCount        int
+	MissingShardCount int
+	// the deletions are applied to the .ecx file on every shard server, so the largest stats are the most recent
+	DeleteCount          uint64
+	ReclaimableByteCount uint64
+	Locations            []*EcShardLocationView
+}
+
+type EcShardLocationView struct {
+	Url      string
+	Rack     string
+	ShardIds []erasure_coding.ShardId
+}
@@ -0,0 +1,50 @@
+package weed_server
+
+import (
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/storage"
+	"github.com/chrislusf/seaweedfs/weed/topology"
+)
+
+// startVolumeGrowthPreallocation grows the volumes of the collections with the "target" or the "adaptive" growth strategy
+// up to the writable targets in the background, so the writes do not wait for the volumes to grow
+func (ms *MasterServer) startVolumeGrowthPreallocation() {
+	go func() {
+		c := time.Tick(time.Minute)
+		for _ = range c {
+			if ms.Topo.IsLeader() {
+				ms.preallocateVolumes(time.Now())
+			}
+		}
+	}()
+}
+
+func (ms *MasterServer) preallocateVolumes(now time.Time) {
+	defaultReplicaPlacement, err := storage.NewReplicaPlacementFromString(ms.option.DefaultReplicaPlacement)
+	if err != nil {
+		glog.Errorf("volume growth: default replication %s: %v", ms.option.DefaultReplicaPlacement, err)
+		return
+	}
+	for _, deficit := range ms.Topo.VolumeGrowthDeficits(now, defaultReplicaPlacement) {
+		if ms.Topo.FreeSpace() <= 0 {
+			glog.V(0).Infof("volume growth: no free volume slots to pre-allocate")
+			return
+		}
+		option := &topology.VolumeGrowOption{
+			Collection:       deficit.Collection,
+			ReplicaPlacement: deficit.ReplicaPlacement,
+			Ttl:              deficit.Ttl,
+			Prealloacte:      ms.preallocateSize,
+		}
+		ms.vgLock.Lock()
+		count, err := ms.vg.GrowByCountAndType(ms.grpcDialOpiton, deficit.Count, option, ms.Topo)
+		ms.vgLock.Unlock()
+		if err != nil {
+			glog.Warningf("volume growth: pre-allocate %d volumes for %s: %v", deficit.Count, option, err)
+			continue
+		}
+		glog.V(0).Infof("volume growth: pre-allocated %d volume replicas for %s", count, option)
+	}
+}
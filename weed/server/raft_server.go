@@ -25,7 +25,24 @@ type RaftServer struct {
 	*raft.GrpcServer
 }
 
-func NewRaftServer(grpcDialOption grpc.DialOption, peers []string, serverAddr string, dataDir string, topo *topology.Topology, pulseSeconds int) *RaftServer {
+// StateMachine saves and recovers the topology state in the raft snapshots
+type StateMachine struct {
+	raft.StateMachine
+	topo *topology.Topology
+}
+
+func (s StateMachine) Save() ([]byte, error) {
+	return s.topo.SaveState()
+}
+
+func (s StateMachine) Recovery(data []byte) error {
+	return s.topo.RecoverState(data)
+}
+
+// NewRaftServer starts the raft server. Every snapshotInterval, the state is saved in a snapshot and the raft log
+// before it is dropped, if at least snapshotThreshold entries are committed since the last snapshot.
+func NewRaftServer(grpcDialOption grpc.DialOption, peers []string, serverAddr string, dataDir string, topo *topology.Topology, pulseSeconds int,
+	snapshotInterval time.Duration, snapshotThreshold uint64) *RaftServer {
 	s := &RaftServer{
 		peers:      peers,
 		serverAddr: serverAddr,
@@ -38,6 +55,9 @@ func NewRaftServer(grpcDialOption grpc.DialOption, peers []string, serverAddr st
 	}
 
 	raft.RegisterCommand(&topology.MaxVolumeIdCommand{})
+	raft.RegisterCommand(&topology.VolumeGrowthStrategyCommand{})
+	raft.RegisterCommand(&topology.CollectionConfigCommand{})
+	raft.RegisterCommand(&topology.QuarantineCommand{})
 
 	var err error
 	transporter := raft.NewGrpcTransporter(grpcDialOption)
@@ -51,13 +71,24 @@ func NewRaftServer(grpcDialOption grpc.DialOption, peers []string, serverAddr st
 		os.RemoveAll(path.Join(s.dataDir, "snapshot"))
 	}
 
-	s.raftServer, err = raft.NewServer(s.serverAddr, s.dataDir, transporter, nil, topo, "")
+	if err = os.MkdirAll(path.Join(s.dataDir, "snapshot"), 0700); err != nil {
+		glog.V(0).Infoln(err)
+		return nil
+	}
+
+	stateMachine := StateMachine{topo: topo}
+	s.raftServer, err = raft.NewServer(s.serverAddr, s.dataDir, transporter, stateMachine, topo, "")
 	if err != nil {
 		glog.V(0).Infoln(err)
 		return nil
 	}
 	s.raftServer.SetHeartbeatInterval(500 * time.Millisecond)
 	s.raftServer.SetElectionTimeout(time.Duration(pulseSeconds) * 500 * time.Millisecond)
+	// recover from the latest snapshot, so only the raft log after it is replayed
+	if err = s.raftServer.LoadSnapshot(); err != nil {
+		glog.V(0).Infof("load raft snapshot: %v", err)
+		return nil
+	}
 	s.raftServer.Start()
 
 	for _, peer := range s.peers {
@@ -83,9 +114,30 @@ func NewRaftServer(grpcDialOption grpc.DialOption, peers []string, serverAddr st
 
 	glog.V(0).Infof("current cluster leader: %v", s.raftServer.Leader())
 
+	if snapshotInterval > 0 {
+		go s.snapshotLoop(snapshotInterval, snapshotThreshold)
+	}
+
 	return s
 }
 
+// snapshotLoop takes the snapshots and compacts the raft log, which otherwise grows with every MaxVolumeId command
+func (s *RaftServer) snapshotLoop(interval time.Duration, threshold uint64) {
+	var snapshotIndex uint64
+	for range time.Tick(interval) {
+		commitIndex := s.raftServer.CommitIndex()
+		if commitIndex < snapshotIndex+threshold {
+			continue
+		}
+		if err := s.raftServer.TakeSnapshot(); err != nil {
+			glog.Warningf("take raft snapshot at %d: %v", commitIndex, err)
+			continue
+		}
+		glog.V(0).Infof("raft snapshot at %d, log compacted", commitIndex)
+		snapshotIndex = commitIndex
+	}
+}
+
 func (s *RaftServer) Peers() (members []string) {
 	peers := s.raftServer.Peers()
 
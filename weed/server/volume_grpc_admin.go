@@ -83,7 +83,12 @@ func (vs *VolumeServer) VolumeDelete(ctx context.Context, req *volume_server_pb.
 
 	resp := &volume_server_pb.VolumeDeleteResponse{}
 
-	err := vs.store.DeleteVolume(needle.VolumeId(req.VolumeId))
+	var err error
+	if req.OnlyEmpty {
+		err = vs.store.DeleteEmptyVolume(needle.VolumeId(req.VolumeId))
+	} else {
+		err = vs.store.DeleteVolume(needle.VolumeId(req.VolumeId))
+	}
 
 	if err != nil {
 		glog.Errorf("volume delete %v: %v", req, err)
@@ -99,7 +104,8 @@ func (vs *VolumeServer) VolumeMarkReadonly(ctx context.Context, req *volume_serv
 
 	resp := &volume_server_pb.VolumeMarkReadonlyResponse{}
 
-	err := vs.store.MarkVolumeReadonly(needle.VolumeId(req.VolumeId))
+	wasReadonly, err := vs.store.MarkVolumeReadonly(needle.VolumeId(req.VolumeId))
+	resp.WasReadonly = wasReadonly
 
 	if err != nil {
 		glog.Errorf("volume mark readonly %v: %v", req, err)
@@ -110,3 +116,50 @@ func (vs *VolumeServer) VolumeMarkReadonly(ctx context.Context, req *volume_serv
 	return resp, err
 
 }
+
+func (vs *VolumeServer) VolumeMarkWritable(ctx context.Context, req *volume_server_pb.VolumeMarkWritableRequest) (*volume_server_pb.VolumeMarkWritableResponse, error) {
+
+	resp := &volume_server_pb.VolumeMarkWritableResponse{}
+
+	err := vs.store.MarkVolumeWritable(needle.VolumeId(req.VolumeId))
+
+	if err != nil {
+		glog.Errorf("volume mark writable %v: %v", req, err)
+	} else {
+		glog.V(2).Infof("volume mark writable %v", req)
+	}
+
+	return resp, err
+
+}
+
+func (vs *VolumeServer) VolumeServerDrain(ctx context.Context, req *volume_server_pb.VolumeServerDrainRequest) (*volume_server_pb.VolumeServerDrainResponse, error) {
+
+	vs.store.MarkDraining()
+
+	volumeCount, ecShardCount := vs.store.CountVolumesAndEcShards()
+
+	glog.V(2).Infof("volume server drain: %d volumes and %d ec shards left", volumeCount, ecShardCount)
+
+	return &volume_server_pb.VolumeServerDrainResponse{
+		VolumeCount:  uint32(volumeCount),
+		EcShardCount: uint32(ecShardCount),
+	}, nil
+
+}
+
+func (vs *VolumeServer) VolumeWarmup(ctx context.Context, req *volume_server_pb.VolumeWarmupRequest) (*volume_server_pb.VolumeWarmupResponse, error) {
+
+	warmed, err := vs.store.WarmupVolume(needle.VolumeId(req.VolumeId), req.BytesPerSecond)
+
+	if err != nil {
+		glog.Errorf("volume warmup %v: %v", req, err)
+	} else {
+		glog.V(1).Infof("volume warmup %v: %d bytes", req, warmed)
+	}
+
+	return &volume_server_pb.VolumeWarmupResponse{
+		WarmedBytes: uint64(warmed),
+	}, err
+
+}
@@ -49,13 +49,13 @@ func (vs *VolumeServer) BatchDelete(ctx context.Context, req *volume_server_pb.B
 			continue
 		}
 
-		if n.Cookie != cookie {
+		if !req.SkipCookieCheck && n.Cookie != cookie {
 			resp.Results = append(resp.Results, &volume_server_pb.DeleteResult{
 				FileId: fid,
 				Status: http.StatusBadRequest,
 				Error:  "File Random Cookie does not match.",
 			})
-			break
+			continue
 		}
 		n.LastModified = now
 		if size, err := vs.store.Delete(volumeId, n); err != nil {
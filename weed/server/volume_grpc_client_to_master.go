@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/chrislusf/seaweedfs/weed/security"
+	"github.com/chrislusf/seaweedfs/weed/storage"
 	"github.com/chrislusf/seaweedfs/weed/storage/erasure_coding"
 	"github.com/spf13/viper"
 	"google.golang.org/grpc"
@@ -31,6 +32,12 @@ func (vs *VolumeServer) heartbeat() {
 	var newLeader string
 	for {
 		for _, master := range vs.SeedMasterNodes {
+			select {
+			case <-vs.stopChan:
+				glog.V(0).Infof("volume server %s:%d stops the heartbeats", vs.store.Ip, vs.store.Port)
+				return
+			default:
+			}
 			if newLeader != "" {
 				master = newLeader
 			}
@@ -69,6 +76,8 @@ func (vs *VolumeServer) doHeartbeat(ctx context.Context, masterNode, masterGrpcA
 	vs.currentMaster = masterNode
 
 	doneChan := make(chan error, 1)
+	// the masters accepting the delta heartbeats tell the heartbeat interval
+	intervalChan := make(chan time.Duration, 1)
 
 	go func() {
 		for {
@@ -86,25 +95,42 @@ func (vs *VolumeServer) doHeartbeat(ctx context.Context, masterNode, masterGrpcA
 				doneChan <- nil
 				return
 			}
+			if in.GetAllEcLocationsChanged() || len(in.GetChangedEcVids()) > 0 {
+				vs.store.InvalidateEcShardLocations(in.GetAllEcLocationsChanged(), in.GetChangedEcVids())
+			}
 			if in.GetMetricsAddress() != "" && vs.MetricsAddress != in.GetMetricsAddress() {
 				vs.MetricsAddress = in.GetMetricsAddress()
 				vs.MetricsIntervalSec = int(in.GetMetricsIntervalSeconds())
 			}
+			if in.GetHeartbeatIntervalSeconds() != 0 {
+				select {
+				case <-intervalChan:
+				default:
+				}
+				intervalChan <- time.Duration(in.GetHeartbeatIntervalSeconds()) * time.Second
+			}
 		}
 	}()
 
-	if err = stream.Send(vs.store.CollectHeartbeat()); err != nil {
+	fullBeat := vs.store.CollectHeartbeat()
+	if err = stream.Send(fullBeat); err != nil {
 		glog.V(0).Infof("Volume Server Failed to talk with master %s: %v", masterNode, err)
 		return "", err
 	}
+	// only sent after the master tells the heartbeat interval, since the older masters expect the full heartbeats
+	var heartbeatDelta *storage.HeartbeatDelta
 
 	if err = stream.Send(vs.store.CollectErasureCodingHeartbeat()); err != nil {
 		glog.V(0).Infof("Volume Server Failed to talk with master %s: %v", masterNode, err)
 		return "", err
 	}
 
-	volumeTickChan := time.Tick(sleepInterval)
+	volumeTicker := time.NewTicker(sleepInterval)
+	defer func() {
+		volumeTicker.Stop()
+	}()
 	ecShardTickChan := time.Tick(17 * sleepInterval)
+	heartbeatInterval := sleepInterval
 
 	for {
 		select {
@@ -154,9 +180,28 @@ func (vs *VolumeServer) doHeartbeat(ctx context.Context, masterNode, masterGrpcA
 				glog.V(0).Infof("Volume Server Failed to update to master %s: %v", masterNode, err)
 				return "", err
 			}
-		case <-volumeTickChan:
+		case interval := <-intervalChan:
+			if heartbeatDelta == nil {
+				heartbeatDelta = storage.NewHeartbeatDelta(fullBeat)
+			}
+			if interval < sleepInterval {
+				interval = sleepInterval
+			}
+			if interval != heartbeatInterval {
+				glog.V(0).Infof("volume server %s:%d heartbeat interval changes from %v to %v", vs.store.Ip, vs.store.Port, heartbeatInterval, interval)
+				heartbeatInterval = interval
+				volumeTicker.Stop()
+				volumeTicker = time.NewTicker(heartbeatInterval)
+			}
+		case <-volumeTicker.C:
 			glog.V(4).Infof("volume server %s:%d heartbeat", vs.store.Ip, vs.store.Port)
-			if err = stream.Send(vs.store.CollectHeartbeat()); err != nil {
+			beat := vs.store.CollectHeartbeat()
+			if heartbeatDelta != nil {
+				beat = heartbeatDelta.Delta(beat)
+			} else {
+				fullBeat = beat
+			}
+			if err = stream.Send(beat); err != nil {
 				glog.V(0).Infof("Volume Server Failed to talk with master %s: %v", masterNode, err)
 				return "", err
 			}
@@ -168,6 +213,9 @@ func (vs *VolumeServer) doHeartbeat(ctx context.Context, masterNode, masterGrpcA
 			}
 		case err = <-doneChan:
 			return
+		case <-vs.stopChan:
+			// closing the stream unregisters this volume server from the master
+			return "", nil
 		}
 	}
 }
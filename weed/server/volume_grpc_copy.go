@@ -12,6 +12,7 @@ import (
 	"github.com/chrislusf/seaweedfs/weed/glog"
 	"github.com/chrislusf/seaweedfs/weed/operation"
 	"github.com/chrislusf/seaweedfs/weed/pb/volume_server_pb"
+	"github.com/chrislusf/seaweedfs/weed/stats"
 	"github.com/chrislusf/seaweedfs/weed/storage"
 	"github.com/chrislusf/seaweedfs/weed/storage/erasure_coding"
 	"github.com/chrislusf/seaweedfs/weed/storage/needle"
@@ -55,11 +56,11 @@ func (vs *VolumeServer) VolumeCopy(ctx context.Context, req *volume_server_pb.Vo
 
 		// println("source:", volFileInfoResp.String())
 		// copy ecx file
-		if err := vs.doCopyFile(ctx, client, false, req.Collection, req.VolumeId, volFileInfoResp.CompactionRevision, volFileInfoResp.IdxFileSize, volumeFileName, ".idx", false); err != nil {
+		if err := vs.doCopyFile(ctx, client, false, req.Collection, req.VolumeId, volFileInfoResp.CompactionRevision, volFileInfoResp.IdxFileSize, volumeFileName, ".idx", false, 0); err != nil {
 			return err
 		}
 
-		if err := vs.doCopyFile(ctx, client, false, req.Collection, req.VolumeId, volFileInfoResp.CompactionRevision, volFileInfoResp.DatFileSize, volumeFileName, ".dat", false); err != nil {
+		if err := vs.doCopyFile(ctx, client, false, req.Collection, req.VolumeId, volFileInfoResp.CompactionRevision, volFileInfoResp.DatFileSize, volumeFileName, ".dat", false, 0); err != nil {
 			return err
 		}
 
@@ -83,6 +84,13 @@ func (vs *VolumeServer) VolumeCopy(ctx context.Context, req *volume_server_pb.Vo
 		return nil, err
 	}
 
+	if err = storage.VerifyVolumeFiles(datFileName, idxFileName); err != nil {
+		stats.VolumeServerNeedleCorruptionCounter.WithLabelValues("copied").Inc()
+		os.Remove(idxFileName)
+		os.Remove(datFileName)
+		return nil, fmt.Errorf("copy volume %d from %s: %v", req.VolumeId, req.SourceDataNode, err)
+	}
+
 	// mount the volume
 	err = vs.store.MountVolume(needle.VolumeId(req.VolumeId))
 	if err != nil {
@@ -95,7 +103,7 @@ func (vs *VolumeServer) VolumeCopy(ctx context.Context, req *volume_server_pb.Vo
 }
 
 func (vs *VolumeServer) doCopyFile(ctx context.Context, client volume_server_pb.VolumeServerClient, isEcVolume bool, collection string, vid uint32,
-	compactRevision uint32, stopOffset uint64, baseFileName, ext string, isAppend bool) error {
+	compactRevision uint32, stopOffset uint64, baseFileName, ext string, isAppend bool, ioBytePerSecond int64) error {
 
 	copyFileClient, err := client.CopyFile(ctx, &volume_server_pb.CopyFileRequest{
 		VolumeId:           vid,
@@ -104,12 +112,13 @@ func (vs *VolumeServer) doCopyFile(ctx context.Context, client volume_server_pb.
 		StopOffset:         stopOffset,
 		Collection:         collection,
 		IsEcVolume:         isEcVolume,
+		IoBytePerSecond:    ioBytePerSecond,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to start copying volume %d %s file: %v", vid, ext, err)
 	}
 
-	err = writeToFile(copyFileClient, baseFileName+ext, util.NewWriteThrottler(vs.compactionBytePerSecond), isAppend)
+	err = writeToFile(copyFileClient, baseFileName+ext, util.NewWriteThrottler(vs.compactionBytePerSecond), vs.maintenanceThrottler, isAppend)
 	if err != nil {
 		return fmt.Errorf("failed to copy %s file: %v", baseFileName+ext, err)
 	}
@@ -143,7 +152,7 @@ func checkCopyFiles(originFileInf *volume_server_pb.ReadVolumeFileStatusResponse
 	return nil
 }
 
-func writeToFile(client volume_server_pb.VolumeServer_CopyFileClient, fileName string, wt *util.WriteThrottler, isAppend bool) error {
+func writeToFile(client volume_server_pb.VolumeServer_CopyFileClient, fileName string, wt *util.WriteThrottler, bt *util.BandwidthThrottler, isAppend bool) error {
 	glog.V(4).Infof("writing to %s", fileName)
 	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
 	if isAppend {
@@ -165,6 +174,7 @@ func writeToFile(client volume_server_pb.VolumeServer_CopyFileClient, fileName s
 		}
 		dst.Write(resp.FileContent)
 		wt.MaybeSlowdown(int64(len(resp.FileContent)))
+		bt.Wait(len(resp.FileContent))
 	}
 	return nil
 }
@@ -191,6 +201,8 @@ func (vs *VolumeServer) ReadVolumeFileStatus(ctx context.Context, req *volume_se
 // CopyFile client pulls the volume related file from the source server.
 // if req.CompactionRevision != math.MaxUint32, it ensures the compact revision is as expected
 // The copying still stop at req.StopOffset, but you can set it to math.MaxUint64 in order to read all data.
+// The copying starts from req.StartOffset, to read only the data appended since the earlier copy.
+// If req.IoBytePerSecond > 0, the sending is throttled to this rate, besides the maintenance bandwidth of this volume server.
 func (vs *VolumeServer) CopyFile(req *volume_server_pb.CopyFileRequest, stream volume_server_pb.VolumeServer_CopyFileServer) error {
 
 	var fileName string
@@ -225,7 +237,15 @@ func (vs *VolumeServer) CopyFile(req *volume_server_pb.CopyFileRequest, stream v
 	}
 	defer file.Close()
 
+	if req.StartOffset > 0 {
+		if _, err = file.Seek(int64(req.StartOffset), io.SeekStart); err != nil {
+			return err
+		}
+		bytesToRead -= int64(req.StartOffset)
+	}
+
 	buffer := make([]byte, BufferSizeLimit)
+	throttler := util.NewWriteThrottler(req.IoBytePerSecond)
 
 	for bytesToRead > 0 {
 		bytesread, err := file.Read(buffer)
@@ -252,6 +272,8 @@ func (vs *VolumeServer) CopyFile(req *volume_server_pb.CopyFileRequest, stream v
 		}
 
 		bytesToRead -= int64(bytesread)
+		throttler.MaybeSlowdown(int64(bytesread))
+		vs.maintenanceThrottler.Wait(bytesread)
 
 	}
 
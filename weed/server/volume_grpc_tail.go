@@ -10,6 +10,8 @@ import (
 	"github.com/chrislusf/seaweedfs/weed/pb/volume_server_pb"
 	"github.com/chrislusf/seaweedfs/weed/storage"
 	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+	"github.com/chrislusf/seaweedfs/weed/storage/types"
+	"github.com/chrislusf/seaweedfs/weed/util"
 )
 
 func (vs *VolumeServer) VolumeTailSender(req *volume_server_pb.VolumeTailSenderRequest, stream volume_server_pb.VolumeServer_VolumeTailSenderServer) error {
@@ -25,7 +27,7 @@ func (vs *VolumeServer) VolumeTailSender(req *volume_server_pb.VolumeTailSenderR
 	drainingSeconds := req.IdleTimeoutSeconds
 
 	for {
-		lastProcessedTimestampNs, err := sendNeedlesSince(stream, v, lastTimestampNs)
+		lastProcessedTimestampNs, err := sendNeedlesSince(stream, v, lastTimestampNs, vs.maintenanceThrottler)
 		if err != nil {
 			glog.Infof("sendNeedlesSince: %v", err)
 			return fmt.Errorf("streamFollow: %v", err)
@@ -52,7 +54,7 @@ func (vs *VolumeServer) VolumeTailSender(req *volume_server_pb.VolumeTailSenderR
 
 }
 
-func sendNeedlesSince(stream volume_server_pb.VolumeServer_VolumeTailSenderServer, v *storage.Volume, lastTimestampNs uint64) (lastProcessedTimestampNs uint64, err error) {
+func sendNeedlesSince(stream volume_server_pb.VolumeServer_VolumeTailSenderServer, v *storage.Volume, lastTimestampNs uint64, throttler *util.BandwidthThrottler) (lastProcessedTimestampNs uint64, err error) {
 
 	foundOffset, isLastOne, err := v.BinarySearchByAppendAtNs(lastTimestampNs)
 	if err != nil {
@@ -87,6 +89,7 @@ func sendNeedlesSince(stream volume_server_pb.VolumeServer_VolumeTailSenderServe
 			if sendErr != nil {
 				return sendErr
 			}
+			throttler.Wait(stopOffset - i)
 		}
 
 		lastProcessedTimestampNs = needleAppendAtNs
@@ -109,9 +112,80 @@ func (vs *VolumeServer) VolumeTailReceiver(ctx context.Context, req *volume_serv
 
 	defer glog.V(1).Infof("receive tailing volume %d finished", v.Id)
 
-	return resp, operation.TailVolumeFromSource(req.SourceVolumeServer, vs.grpcDialOption, v.Id, req.SinceNs, int(req.IdleTimeoutSeconds), func(n *needle.Needle) error {
-		_, _, err := vs.store.Write(v.Id, n)
+	resp.LastAppendAtNs = req.SinceNs
+	err := operation.TailVolumeFromSource(req.SourceVolumeServer, vs.grpcDialOption, v.Id, req.SinceNs, int(req.IdleTimeoutSeconds), func(n *needle.Needle) error {
+		if _, _, err := vs.store.Write(v.Id, n); err != nil {
+			return err
+		}
+		vs.maintenanceThrottler.Wait(len(n.Data))
+		if n.AppendAtNs > resp.LastAppendAtNs {
+			resp.LastAppendAtNs = n.AppendAtNs
+		}
+		return nil
+	})
+	return resp, err
+
+}
+
+// ReadNeedleBlob sends the raw needle again, for the receivers to retry the needles found corrupted
+func (vs *VolumeServer) ReadNeedleBlob(req *volume_server_pb.ReadNeedleBlobRequest, stream volume_server_pb.VolumeServer_ReadNeedleBlobServer) error {
+
+	v := vs.store.GetVolume(needle.VolumeId(req.VolumeId))
+	if v == nil {
+		return fmt.Errorf("not found volume id %d", req.VolumeId)
+	}
+
+	needleHeader, needleBody, err := v.ReadNeedleBlob(types.NeedleId(req.NeedleId))
+	if err == storage.ErrorNotFound {
+		return stream.Send(&volume_server_pb.ReadNeedleBlobResponse{IsLastChunk: true})
+	}
+	if err != nil {
 		return err
+	}
+
+	for i := 0; i < len(needleBody) || i == 0; i += BufferSizeLimit {
+		stopOffset := i + BufferSizeLimit
+		if stopOffset > len(needleBody) {
+			stopOffset = len(needleBody)
+		}
+		sendErr := stream.Send(&volume_server_pb.ReadNeedleBlobResponse{
+			NeedleHeader: needleHeader,
+			NeedleBody:   needleBody[i:stopOffset],
+			IsLastChunk:  stopOffset == len(needleBody),
+		})
+		if sendErr != nil {
+			return sendErr
+		}
+	}
+
+	return nil
+}
+
+// VolumeNeedlesCopy copies the needles from the source volume server, to sync the differences between the replicas
+func (vs *VolumeServer) VolumeNeedlesCopy(ctx context.Context, req *volume_server_pb.VolumeNeedlesCopyRequest) (*volume_server_pb.VolumeNeedlesCopyResponse, error) {
+
+	resp := &volume_server_pb.VolumeNeedlesCopyResponse{}
+
+	v := vs.store.GetVolume(needle.VolumeId(req.VolumeId))
+	if v == nil {
+		return resp, fmt.Errorf("not found volume id %d", req.VolumeId)
+	}
+
+	ids := make([]types.NeedleId, len(req.NeedleIds))
+	for i, id := range req.NeedleIds {
+		ids[i] = types.NeedleId(id)
+	}
+
+	err := operation.ReadNeedlesFromSource(req.SourceDataNode, vs.grpcDialOption, v.Id, ids, func(n *needle.Needle) error {
+		if _, _, err := vs.store.Write(v.Id, n); err != nil {
+			return err
+		}
+		vs.maintenanceThrottler.Wait(len(n.Data))
+		resp.CopiedCount++
+		return nil
 	})
 
+	glog.V(1).Infof("volume %d copied %d needles of %d from %s: %v", v.Id, resp.CopiedCount, len(ids), req.SourceDataNode, err)
+
+	return resp, err
 }
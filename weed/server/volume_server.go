@@ -3,6 +3,7 @@ package weed_server
 import (
 	"fmt"
 	"net/http"
+	"sync"
 
 	"github.com/chrislusf/seaweedfs/weed/stats"
 	"google.golang.org/grpc"
@@ -10,6 +11,8 @@ import (
 	"github.com/chrislusf/seaweedfs/weed/glog"
 	"github.com/chrislusf/seaweedfs/weed/security"
 	"github.com/chrislusf/seaweedfs/weed/storage"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+	"github.com/chrislusf/seaweedfs/weed/util"
 	"github.com/spf13/viper"
 )
 
@@ -27,8 +30,14 @@ type VolumeServer struct {
 	FixJpgOrientation       bool
 	ReadRedirect            bool
 	compactionBytePerSecond int64
-	MetricsAddress          string
-	MetricsIntervalSec      int
+	// caps the replica repairs, the volume moves, and the ec encoding and rebuilding of this volume server
+	maintenanceThrottler *util.BandwidthThrottler
+	MetricsAddress       string
+	MetricsIntervalSec   int
+	fileSizeLimit        int64
+	// closed to stop the heartbeats, which unregisters this volume server from the master
+	stopChan chan struct{}
+	stopOnce sync.Once
 }
 
 func NewVolumeServer(adminMux, publicMux *http.ServeMux, ip string,
@@ -41,6 +50,11 @@ func NewVolumeServer(adminMux, publicMux *http.ServeMux, ip string,
 	fixJpgOrientation bool,
 	readRedirect bool,
 	compactionMBPerSecond int,
+	maintenanceMBPerSecond int,
+	fsyncPolicies *storage.FsyncPolicies,
+	readOnly bool,
+	fileSizeLimitMB int,
+	labels map[string]string,
 ) *VolumeServer {
 
 	v := viper.GetViper()
@@ -62,9 +76,21 @@ func NewVolumeServer(adminMux, publicMux *http.ServeMux, ip string,
 		ReadRedirect:            readRedirect,
 		grpcDialOption:          security.LoadClientTLS(viper.Sub("grpc"), "volume"),
 		compactionBytePerSecond: int64(compactionMBPerSecond) * 1024 * 1024,
+		maintenanceThrottler:    util.NewBandwidthThrottler(int64(maintenanceMBPerSecond) * 1024 * 1024),
+		fileSizeLimit:           needle.MaxNeedleDataSize,
+		stopChan:                make(chan struct{}),
+	}
+	if fileSizeLimitMB > 0 && int64(fileSizeLimitMB)*1024*1024 < vs.fileSizeLimit {
+		vs.fileSizeLimit = int64(fileSizeLimitMB) * 1024 * 1024
 	}
 	vs.SeedMasterNodes = masterNodes
 	vs.store = storage.NewStore(vs.grpcDialOption, port, ip, publicUrl, folders, maxCounts, vs.needleMapKind)
+	vs.store.SetFsyncPolicies(fsyncPolicies)
+	vs.store.SetLabels(labels)
+	vs.store.SetFileSizeLimit(vs.fileSizeLimit)
+	if readOnly {
+		vs.store.SetReadOnly()
+	}
 
 	vs.guard = security.NewGuard(whiteList, signingKey, expiresAfterSec, readSigningKey, readExpiresAfterSec)
 
@@ -77,7 +103,12 @@ func NewVolumeServer(adminMux, publicMux *http.ServeMux, ip string,
 		adminMux.HandleFunc("/stats/memory", vs.guard.WhiteList(statsMemoryHandler))
 		adminMux.HandleFunc("/stats/disk", vs.guard.WhiteList(vs.statsDiskHandler))
 	}
-	adminMux.HandleFunc("/", vs.privateStoreHandler)
+	adminMux.HandleFunc("/volumes", vs.guard.WhiteList(vs.volumesHandler))
+	if readOnly {
+		adminMux.HandleFunc("/", vs.publicReadOnlyHandler)
+	} else {
+		adminMux.HandleFunc("/", vs.privateStoreHandler)
+	}
 	if publicMux != adminMux {
 		// separated admin and public port
 		handleStaticResources(publicMux)
@@ -94,8 +125,27 @@ func NewVolumeServer(adminMux, publicMux *http.ServeMux, ip string,
 	return vs
 }
 
+// StopServing rejects the new writes, and unregisters this volume server from the master,
+// so the clients go to the other replicas while the requests in flight finish
+func (vs *VolumeServer) StopServing() {
+	vs.stopOnce.Do(func() {
+		glog.V(0).Infoln("Stop serving the new writes...")
+		vs.store.MarkDraining()
+		close(vs.stopChan)
+	})
+}
+
+// ReleaseVolumes stops serving the new writes, waits for the writes in flight, and syncs the volumes,
+// so another process can load the volumes while this one still serves the reads
+func (vs *VolumeServer) ReleaseVolumes() {
+	vs.StopServing()
+	vs.store.SyncAllVolumes()
+}
+
 func (vs *VolumeServer) Shutdown() {
 	glog.V(0).Infoln("Shutting down volume server...")
+	vs.StopServing()
+	vs.store.SyncAllVolumes()
 	vs.store.Close()
 	glog.V(0).Infoln("Shut down successfully!")
 }
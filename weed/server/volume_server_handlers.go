@@ -7,6 +7,7 @@ import (
 	"github.com/chrislusf/seaweedfs/weed/glog"
 	"github.com/chrislusf/seaweedfs/weed/security"
 	"github.com/chrislusf/seaweedfs/weed/stats"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
 )
 
 /*
@@ -46,6 +47,9 @@ func (vs *VolumeServer) publicReadOnlyHandler(w http.ResponseWriter, r *http.Req
 	case "HEAD":
 		stats.ReadRequest()
 		vs.GetOrHeadHandler(w, r)
+	default:
+		w.Header().Set("Allow", "GET, HEAD")
+		w.WriteHeader(http.StatusMethodNotAllowed)
 	}
 }
 
@@ -84,6 +88,9 @@ func (vs *VolumeServer) maybeCheckJwtAuthorization(r *http.Request, vid, fid str
 	}
 
 	if sc, ok := token.Claims.(*security.SeaweedFileIdClaims); ok {
+		if sc.Count > 1 {
+			return isInAssignedRange(vid, fid, sc.Fid, sc.Count)
+		}
 		if sepIndex := strings.LastIndex(fid, "_"); sepIndex > 0 {
 			fid = fid[:sepIndex]
 		}
@@ -92,3 +99,33 @@ func (vs *VolumeServer) maybeCheckJwtAuthorization(r *http.Request, vid, fid str
 	glog.V(1).Infof("unexpected jwt from %s: %v", r.RemoteAddr, tokenStr)
 	return false
 }
+
+// isInAssignedRange checks the fid, either with the "_delta" suffix or listed by the batch assign,
+// is one of the count consecutive keys assigned from the claimed fid.
+// The keys wrap around after the largest key, the same as FileId.Batch.
+func isInAssignedRange(vid, fid string, claimedFid string, count uint64) bool {
+	claimed, err := needle.ParseFileIdFromString(claimedFid)
+	if err != nil || claimed.VolumeId.String() != vid {
+		return false
+	}
+	n := new(needle.Needle)
+	if err = n.ParsePath(fid); err != nil {
+		return false
+	}
+	return n.Cookie == claimed.Cookie && uint64(n.Id-claimed.Key) < count
+}
+
+// jwtWriteFlags is the write flags signed into the write jwt, already verified by maybeCheckJwtAuthorization
+func (vs *VolumeServer) jwtWriteFlags(r *http.Request) (writeFlags needle.WriteFlags, found bool) {
+	if len(vs.guard.SigningKey) == 0 {
+		return
+	}
+	token, err := security.DecodeJwt(vs.guard.SigningKey, security.GetJwt(r))
+	if err != nil {
+		return
+	}
+	if sc, ok := token.Claims.(*security.SeaweedFileIdClaims); ok {
+		return needle.WriteFlags{Fsync: sc.Fsync, Ack: sc.Ack}, true
+	}
+	return
+}
@@ -1,6 +1,7 @@
 package weed_server
 
 import (
+	"fmt"
 	"net/http"
 	"path/filepath"
 
@@ -16,6 +17,20 @@ func (vs *VolumeServer) statusHandler(w http.ResponseWriter, r *http.Request) {
 	writeJsonQuiet(w, r, http.StatusOK, m)
 }
 
+// volumesHandler lists the volume ids, for the clients to find the volumes when the masters are unavailable
+func (vs *VolumeServer) volumesHandler(w http.ResponseWriter, r *http.Request) {
+	m := make(map[string]interface{})
+	m["Url"] = fmt.Sprintf("%s:%d", vs.store.Ip, vs.store.Port)
+	m["PublicUrl"] = vs.store.PublicUrl
+	m["Volumes"] = vs.store.VolumeIds()
+	var ecVolumes []uint32
+	for _, ecVolume := range vs.store.EcVolumes() {
+		ecVolumes = append(ecVolumes, uint32(ecVolume.VolumeId))
+	}
+	m["EcVolumes"] = ecVolumes
+	writeJsonQuiet(w, r, http.StatusOK, m)
+}
+
 func (vs *VolumeServer) statsDiskHandler(w http.ResponseWriter, r *http.Request) {
 	m := make(map[string]interface{})
 	m["Version"] = util.VERSION
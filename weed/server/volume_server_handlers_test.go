@@ -0,0 +1,61 @@
+package weed_server
+
+import (
+	"testing"
+
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+)
+
+func TestIsInAssignedRange(t *testing.T) {
+	tests := []struct {
+		vid, fid   string
+		claimedFid string
+		count      uint64
+		expected   bool
+	}{
+		{"3", "01637037d6", "3,01637037d6", 1, true},
+		{"3", "01637037d6", "3,01637037d6", 0, false},
+		// the last key of the range, and the one after it
+		{"3", "01637037d6_1", "3,01637037d6", 1, false},
+		{"3", "01637037d6_1", "3,01637037d6", 2, true},
+		{"3", "02637037d6", "3,01637037d6", 2, true},
+		{"3", "03637037d6", "3,01637037d6", 2, false},
+		// the key before the range
+		{"3", "00637037d6", "3,01637037d6", 2, false},
+		{"3", "02637037d6", "3,01637037d6", ^uint64(0), true},
+		// other volumes and cookies
+		{"4", "01637037d6", "3,01637037d6", 1, false},
+		{"3", "01637037d7", "3,01637037d6", 1, false},
+		// invalid fids
+		{"3", "01", "3,01637037d6", 1, false},
+		{"3", "01637037d6_x", "3,01637037d6", 2, false},
+		{"3", "01637037d6", "3,01", 1, false},
+		{"3", "01637037d6", "x,01637037d6", 1, false},
+		// the keys wrap around after the largest key
+		{"3", "ffffffffffffffff637037d6", "3,fffffffffffffffe637037d6", 2, true},
+		{"3", "00637037d6", "3,ffffffffffffffff637037d6", 2, true},
+		{"3", "ffffffffffffffff637037d6_1", "3,ffffffffffffffff637037d6", 2, true},
+		{"3", "01637037d6", "3,ffffffffffffffff637037d6", 2, false},
+	}
+	for _, tt := range tests {
+		if actual := isInAssignedRange(tt.vid, tt.fid, tt.claimedFid, tt.count); actual != tt.expected {
+			t.Errorf("%s,%s in %d from %s: %v", tt.vid, tt.fid, tt.count, tt.claimedFid, actual)
+		}
+	}
+}
+
+func TestBatchIsInAssignedRange(t *testing.T) {
+	for _, key := range []uint64{1, 0xff, ^uint64(0) - 1} {
+		claimed := needle.NewFileId(needle.VolumeId(3), key, 0x637037d6)
+		for _, fid := range claimed.Batch(3) {
+			vid, fid, _, _, _ := parseURLPath("/" + fid)
+			if !isInAssignedRange(vid, fid, claimed.String(), 3) {
+				t.Errorf("%s,%s not in the batch from %s", vid, fid, claimed)
+			}
+		}
+		after := needle.NewFileId(needle.VolumeId(3), key+3, 0x637037d6)
+		if isInAssignedRange("3", after.GetNeedleIdCookie(), claimed.String(), 3) {
+			t.Errorf("%s in the batch of 3 from %s", after, claimed)
+		}
+	}
+}
@@ -16,6 +16,12 @@ import (
 	"github.com/chrislusf/seaweedfs/weed/topology"
 )
 
+const fileSizeLimitSlack = 64 * 1024
+
+func (vs *VolumeServer) fileTooLarge(size int64) error {
+	return needle.ErrNeedleTooLarge{Size: size, Limit: vs.fileSizeLimit}
+}
+
 func (vs *VolumeServer) PostHandler(w http.ResponseWriter, r *http.Request) {
 
 	stats.VolumeServerRequestCounter.WithLabelValues("post").Inc()
@@ -43,14 +49,33 @@ func (vs *VolumeServer) PostHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// the multipart boundaries and headers come along with the file
+	if r.ContentLength > vs.fileSizeLimit+fileSizeLimitSlack {
+		writeJsonError(w, r, http.StatusRequestEntityTooLarge, vs.fileTooLarge(r.ContentLength))
+		return
+	}
+
+	writeFlags, fe := needle.ParseWriteFlagsFromQuery(r.URL.Query())
+	if fe != nil {
+		writeJsonError(w, r, http.StatusBadRequest, fe)
+		return
+	}
+	if jwtFlags, found := vs.jwtWriteFlags(r); found {
+		writeFlags = writeFlags.Merge(jwtFlags)
+	}
+
 	needle, originalSize, ne := needle.CreateNeedleFromRequest(r, vs.FixJpgOrientation)
 	if ne != nil {
 		writeJsonError(w, r, http.StatusBadRequest, ne)
 		return
 	}
+	if int64(len(needle.Data)) > vs.fileSizeLimit {
+		writeJsonError(w, r, http.StatusRequestEntityTooLarge, vs.fileTooLarge(int64(len(needle.Data))))
+		return
+	}
 
 	ret := operation.UploadResult{}
-	_, isUnchanged, writeError := topology.ReplicatedWrite(vs.GetMaster(), vs.store, volumeId, needle, r)
+	_, isUnchanged, writeError := topology.ReplicatedWrite(vs.GetMaster(), vs.store, volumeId, needle, r, writeFlags)
 	httpStatus := http.StatusCreated
 	if isUnchanged {
 		httpStatus = http.StatusNotModified
@@ -0,0 +1,49 @@
+package weed_server
+
+import (
+	"context"
+	"path"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// readOnlyGrpcMethods change no volumes, and are the only grpc methods served by a read only volume server.
+// The volumes can still be copied or tailed to other volume servers.
+var readOnlyGrpcMethods = map[string]bool{
+	"VacuumVolumeCheck":     true,
+	"VolumeSyncStatus":      true,
+	"VolumeIncrementalCopy": true,
+	"VolumeWarmup":          true,
+	"ReadVolumeFileStatus":  true,
+	"CopyFile":              true,
+	"VolumeTailSender":      true,
+	"ReadNeedleBlob":        true,
+	"VolumeEcShardRead":     true,
+}
+
+func checkReadOnlyGrpcMethod(fullMethod string) error {
+	if !readOnlyGrpcMethods[path.Base(fullMethod)] {
+		return status.Errorf(codes.PermissionDenied, "%s is not served by the read only volume server", path.Base(fullMethod))
+	}
+	return nil
+}
+
+// ReadOnlyGrpcOptions rejects the grpc methods changing the volumes, for the read only volume servers
+func ReadOnlyGrpcOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := checkReadOnlyGrpcMethod(info.FullMethod); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := checkReadOnlyGrpcMethod(info.FullMethod); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	}
+}
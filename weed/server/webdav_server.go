@@ -347,7 +347,7 @@ func (fs *WebDavFileSystem) stat(ctx context.Context, fullFilePath string) (os.F
 	if err != nil {
 		return nil, err
 	}
-	fi.size = int64(filer2.TotalSize(entry.GetChunks()))
+	fi.size = int64(filer2.FileSize(entry))
 	fi.name = fullFilePath
 	fi.mode = os.FileMode(entry.Attributes.FileMode)
 	fi.modifiledTime = time.Unix(entry.Attributes.Mtime, 0)
@@ -480,24 +480,29 @@ func (f *WebDavFile) Read(p []byte) (readSize int, err error) {
 	if err != nil {
 		return 0, err
 	}
-	if len(f.entry.Chunks) == 0 {
+	fileSize := int64(filer2.FileSize(f.entry))
+	if f.off >= fileSize {
 		return 0, io.EOF
 	}
 	if f.entryViewCache == nil {
 		f.entryViewCache = filer2.NonOverlappingVisibleIntervals(f.entry.Chunks)
 	}
-	chunkViews := filer2.ViewFromVisibleIntervals(f.entryViewCache, f.off, len(p))
+	readSize = len(p)
+	if f.off+int64(readSize) > fileSize {
+		readSize = int(fileSize - f.off)
+	}
+	// the holes are not read
+	buf := p[:readSize]
+	for i := range buf {
+		buf[i] = 0
+	}
+	chunkViews := filer2.ViewFromVisibleIntervals(f.entryViewCache, f.off, readSize)
 
-	totalRead, err := filer2.ReadIntoBuffer(ctx, f.fs, f.name, p, chunkViews, f.off)
-	if err != nil {
+	if _, err = filer2.ReadIntoBuffer(ctx, f.fs, f.name, buf, chunkViews, f.off); err != nil {
 		return 0, err
 	}
-	readSize = int(totalRead)
 
-	f.off += totalRead
-	if readSize == 0 {
-		return 0, io.EOF
-	}
+	f.off += int64(readSize)
 	return
 }
 
@@ -513,7 +518,7 @@ func (f *WebDavFile) Readdir(count int) (ret []os.FileInfo, err error) {
 
 	err = filer2.ReadDirAllEntries(ctx, f.fs, dir, func(entry *filer_pb.Entry) {
 		fi := FileInfo{
-			size:          int64(filer2.TotalSize(entry.GetChunks())),
+			size:          int64(filer2.FileSize(entry)),
 			name:          entry.Name,
 			mode:          os.FileMode(entry.Attributes.FileMode),
 			modifiledTime: time.Unix(entry.Attributes.Mtime, 0),
@@ -0,0 +1,72 @@
+package shell
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
+)
+
+func init() {
+	Commands = append(Commands, &commandCollectionCreate{})
+}
+
+type commandCollectionCreate struct {
+}
+
+func (c *commandCollectionCreate) Name() string {
+	return "collection.create"
+}
+
+func (c *commandCollectionCreate) Help() string {
+	return `create a collection, or change its defaults
+
+	collection.create [-replication=010] [-ttl=7d] [-volumeSizeLimitMB=2000] [-needleTtl] <collection_name>
+
+	The writes to the collection without the replication or the ttl inherit them,
+	and the volumes of the collection are full at the volumeSizeLimitMB, instead of the master -volumeSizeLimitMB.
+	With -needleTtl, the writes of different ttls share the same volumes without a ttl, each needle expiring
+	by its own ttl and vacuumed when expired, instead of growing the volumes for each ttl.
+	The defaults are kept by the masters through raft, and removed by collection.delete.
+	The volumes are created on the first writes.
+
+`
+}
+
+func (c *commandCollectionCreate) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+
+	createCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	replication := createCommand.String("replication", "", "the default replication of the writes")
+	ttl := createCommand.String("ttl", "", "the default ttl of the writes, as 3m, 4h, 5d, 6w, 7M, 8y")
+	volumeSizeLimitMB := createCommand.Uint64("volumeSizeLimitMB", 0, "the size limit of the volumes, 0 for the master default")
+	needleTtl := createCommand.Bool("needleTtl", false, "write the needles of all ttls to the volumes without a ttl")
+	if err = createCommand.Parse(args); err != nil {
+		return nil
+	}
+
+	if createCommand.NArg() != 1 {
+		return fmt.Errorf("need the collection name")
+	}
+	collectionName := createCommand.Arg(0)
+
+	ctx := context.Background()
+	err = commandEnv.MasterClient.WithClient(ctx, func(client master_pb.SeaweedClient) error {
+		_, err = client.CollectionCreate(ctx, &master_pb.CollectionCreateRequest{
+			Name:              collectionName,
+			Replication:       *replication,
+			Ttl:               *ttl,
+			VolumeSizeLimitMb: *volumeSizeLimitMB,
+			NeedleTtl:         *needleTtl,
+		})
+		return err
+	})
+	if err != nil {
+		return
+	}
+
+	fmt.Fprintf(writer, "collection %s is created\n", collectionName)
+
+	return nil
+}
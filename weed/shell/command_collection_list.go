@@ -24,22 +24,47 @@ func (c *commandCollectionList) Help() string {
 
 func (c *commandCollectionList) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
 
-	collections, err := ListCollectionNames(commandEnv, true, true)
+	collections, err := listCollections(commandEnv, true, true)
 
 	if err != nil {
 		return err
 	}
 
 	for _, c := range collections {
-		fmt.Fprintf(writer, "collection:\"%s\"\n", c)
+		fmt.Fprintf(writer, "collection:\"%s\"", c.Name)
+		if c.Replication != "" {
+			fmt.Fprintf(writer, " replication:%s", c.Replication)
+		}
+		if c.Ttl != "" {
+			fmt.Fprintf(writer, " ttl:%s", c.Ttl)
+		}
+		if c.VolumeSizeLimitMb > 0 {
+			fmt.Fprintf(writer, " volumeSizeLimitMB:%d", c.VolumeSizeLimitMb)
+		}
+		if c.NeedleTtl {
+			fmt.Fprintf(writer, " needleTtl")
+		}
+		fmt.Fprintf(writer, "\n")
 	}
 
 	fmt.Fprintf(writer, "Total %d collections.\n", len(collections))
+	if collections == nil {
+		collections = []*master_pb.Collection{}
+	}
+	commandEnv.setResult(collections)
 
 	return nil
 }
 
 func ListCollectionNames(commandEnv *CommandEnv, includeNormalVolumes, includeEcVolumes bool) (collections []string, err error) {
+	list, err := listCollections(commandEnv, includeNormalVolumes, includeEcVolumes)
+	for _, c := range list {
+		collections = append(collections, c.Name)
+	}
+	return
+}
+
+func listCollections(commandEnv *CommandEnv, includeNormalVolumes, includeEcVolumes bool) (collections []*master_pb.Collection, err error) {
 	var resp *master_pb.CollectionListResponse
 	ctx := context.Background()
 	err = commandEnv.MasterClient.WithClient(ctx, func(client master_pb.SeaweedClient) error {
@@ -52,8 +77,5 @@ func ListCollectionNames(commandEnv *CommandEnv, includeNormalVolumes, includeEc
 	if err != nil {
 		return
 	}
-	for _, c := range resp.Collections {
-		collections = append(collections, c.Name)
-	}
-	return
+	return resp.Collections, nil
 }
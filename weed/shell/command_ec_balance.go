@@ -7,6 +7,7 @@ import (
 	"io"
 	"sort"
 
+	"github.com/chrislusf/seaweedfs/weed/storage"
 	"github.com/chrislusf/seaweedfs/weed/storage/erasure_coding"
 	"github.com/chrislusf/seaweedfs/weed/storage/needle"
 )
@@ -25,7 +26,15 @@ func (c *commandEcBalance) Name() string {
 func (c *commandEcBalance) Help() string {
 	return `balance all ec shards among all racks and volume servers
 
-	ec.balance [-c EACH_COLLECTION|<collection_name>] [-force] [-dataCenter <data_center>]
+	ec.balance [-c EACH_COLLECTION|<collection_name>] [-applyBalancing] [-dataCenter <data_center>] [-labelSelector <labels>]
+
+	Without -applyBalancing, or with -applyBalancing=false, it only prints the plan. -force is the same as -applyBalancing.
+
+	The destination racks and volume servers are picked by a score, the lower the better:
+		score = 10 * (the number of the volume's shards already there) + fullness
+	where the fullness is the used ec shard slots ratio from 0 to 1, so the shards of one volume
+	spread to the most racks and volume servers first, and then to the emptier ones.
+	Only the shards over the average are moved, so the balancing moves the minimal shards.
 
 	Algorithm:
 
@@ -54,7 +63,7 @@ func (c *commandEcBalance) Help() string {
 		averageShardsPerEcRack = totalShardNumber / numRacks  // totalShardNumber is 14 for now, later could varies for each dc
 		ecShardsToMove = select overflown ec shards from racks with ec shard counts > averageShardsPerEcRack
 		for each ecShardsToMove {
-			destRack = pickOneRack(rack~shardCount, rack~volumeIdShardCount, averageShardsPerEcRack)
+			destRack = pickOneRack(rack~shardCount, rack~volumeIdShardCount, averageShardsPerEcRack) // the lowest score
 			destVolumeServers = volume servers on the destRack
 			pickOneEcNodeAndMoveOneShard(destVolumeServers)
 		}
@@ -73,7 +82,7 @@ func (c *commandEcBalance) Help() string {
 		volumeServersOverAverage = volume servers with volumeId's ec shard counts > averageShardsPerEcRack
 		ecShardsToMove = select overflown ec shards from volumeServersOverAverage
 		for each ecShardsToMove {
-			destVolumeServer = pickOneVolumeServer(volumeServer~shardCount, volumeServer~volumeIdShardCount, averageShardCount)
+			destVolumeServer = pickOneVolumeServer(volumeServer~shardCount, volumeServer~volumeIdShardCount, averageShardCount) // the lowest score
 			pickOneEcNodeAndMoveOneShard(destVolumeServers)
 		}
 	}
@@ -102,15 +111,23 @@ func (c *commandEcBalance) Do(args []string, commandEnv *CommandEnv, writer io.W
 	balanceCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
 	collection := balanceCommand.String("collection", "EACH_COLLECTION", "collection name, or \"EACH_COLLECTION\" for each collection")
 	dc := balanceCommand.String("dataCenter", "", "only apply the balancing for this dataCenter")
-	applyBalancing := balanceCommand.Bool("force", false, "apply the balancing plan")
+	labels := balanceCommand.String("labelSelector", "", "only apply the balancing for the volume servers with the matching labels, e.g. zone=eu")
+	applyBalancing := balanceCommand.Bool("applyBalancing", false, "apply the balancing plan, otherwise only print it")
+	force := balanceCommand.Bool("force", false, "apply the balancing plan, same as -applyBalancing")
 	if err = balanceCommand.Parse(args); err != nil {
 		return nil
 	}
+	*applyBalancing = *applyBalancing || *force
+
+	labelSelector, err := storage.ParseLabelSelector(*labels)
+	if err != nil {
+		return err
+	}
 
 	ctx := context.Background()
 
 	// collect all ec nodes
-	allEcNodes, totalFreeEcSlots, err := collectEcNodes(ctx, commandEnv, *dc)
+	allEcNodes, totalFreeEcSlots, err := collectEcNodes(ctx, commandEnv, *dc, labelSelector)
 	if err != nil {
 		return err
 	}
@@ -119,29 +136,37 @@ func (c *commandEcBalance) Do(args []string, commandEnv *CommandEnv, writer io.W
 	}
 
 	racks := collectRacks(allEcNodes)
+	before := snapshotEcShards(allEcNodes)
 
 	if *collection == "EACH_COLLECTION" {
 		collections, err := ListCollectionNames(commandEnv, false, true)
 		if err != nil {
 			return err
 		}
-		fmt.Printf("balanceEcVolumes collections %+v\n", len(collections))
+		fmt.Fprintf(writer, "balanceEcVolumes collections %+v\n", len(collections))
 		for _, c := range collections {
-			fmt.Printf("balanceEcVolumes collection %+v\n", c)
-			if err = balanceEcVolumes(commandEnv, c, allEcNodes, racks, *applyBalancing); err != nil {
+			fmt.Fprintf(writer, "balanceEcVolumes collection %+v\n", c)
+			if err = balanceEcVolumes(commandEnv, writer, c, allEcNodes, racks, *applyBalancing); err != nil {
 				return err
 			}
 		}
 	} else {
-		if err = balanceEcVolumes(commandEnv, *collection, allEcNodes, racks, *applyBalancing); err != nil {
+		if err = balanceEcVolumes(commandEnv, writer, *collection, allEcNodes, racks, *applyBalancing); err != nil {
 			return err
 		}
 	}
 
-	if err := balanceEcRacks(ctx, commandEnv, racks, *applyBalancing); err != nil {
+	if err := balanceEcRacks(ctx, commandEnv, writer, racks, *applyBalancing); err != nil {
 		return fmt.Errorf("balance ec racks: %v", err)
 	}
 
+	moved := countMovedEcShards(before, allEcNodes)
+	if *applyBalancing {
+		fmt.Fprintf(writer, "moved %d ec shards\n", moved)
+	} else {
+		fmt.Fprintf(writer, "planned to move %d ec shards, use -applyBalancing to apply the plan\n", moved)
+	}
+
 	return nil
 }
 
@@ -160,40 +185,40 @@ func collectRacks(allEcNodes []*EcNode) map[RackId]*EcRack {
 	return racks
 }
 
-func balanceEcVolumes(commandEnv *CommandEnv, collection string, allEcNodes []*EcNode, racks map[RackId]*EcRack, applyBalancing bool) error {
+func balanceEcVolumes(commandEnv *CommandEnv, writer io.Writer, collection string, allEcNodes []*EcNode, racks map[RackId]*EcRack, applyBalancing bool) error {
 
 	ctx := context.Background()
 
-	fmt.Printf("balanceEcVolumes %s\n", collection)
+	fmt.Fprintf(writer, "balanceEcVolumes %s\n", collection)
 
-	if err := deleteDuplicatedEcShards(ctx, commandEnv, allEcNodes, collection, applyBalancing); err != nil {
+	if err := deleteDuplicatedEcShards(ctx, commandEnv, writer, allEcNodes, collection, applyBalancing); err != nil {
 		return fmt.Errorf("delete duplicated collection %s ec shards: %v", collection, err)
 	}
 
-	if err := balanceEcShardsAcrossRacks(ctx, commandEnv, allEcNodes, racks, collection, applyBalancing); err != nil {
+	if err := balanceEcShardsAcrossRacks(ctx, commandEnv, writer, allEcNodes, racks, collection, applyBalancing); err != nil {
 		return fmt.Errorf("balance across racks collection %s ec shards: %v", collection, err)
 	}
 
-	if err := balanceEcShardsWithinRacks(ctx, commandEnv, allEcNodes, racks, collection, applyBalancing); err != nil {
+	if err := balanceEcShardsWithinRacks(ctx, commandEnv, writer, allEcNodes, racks, collection, applyBalancing); err != nil {
 		return fmt.Errorf("balance across racks collection %s ec shards: %v", collection, err)
 	}
 
 	return nil
 }
 
-func deleteDuplicatedEcShards(ctx context.Context, commandEnv *CommandEnv, allEcNodes []*EcNode, collection string, applyBalancing bool) error {
+func deleteDuplicatedEcShards(ctx context.Context, commandEnv *CommandEnv, writer io.Writer, allEcNodes []*EcNode, collection string, applyBalancing bool) error {
 	// vid => []ecNode
 	vidLocations := collectVolumeIdToEcNodes(allEcNodes)
 	// deduplicate ec shards
 	for vid, locations := range vidLocations {
-		if err := doDeduplicateEcShards(ctx, commandEnv, collection, vid, locations, applyBalancing); err != nil {
+		if err := doDeduplicateEcShards(ctx, commandEnv, writer, collection, vid, locations, applyBalancing); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func doDeduplicateEcShards(ctx context.Context, commandEnv *CommandEnv, collection string, vid needle.VolumeId, locations []*EcNode, applyBalancing bool) error {
+func doDeduplicateEcShards(ctx context.Context, commandEnv *CommandEnv, writer io.Writer, collection string, vid needle.VolumeId, locations []*EcNode, applyBalancing bool) error {
 
 	// check whether this volume has ecNodes that are over average
 	shardToLocations := make([][]*EcNode, erasure_coding.TotalShardsCount)
@@ -208,17 +233,17 @@ func doDeduplicateEcShards(ctx context.Context, commandEnv *CommandEnv, collecti
 			continue
 		}
 		sortEcNodes(ecNodes)
-		fmt.Printf("ec shard %d.%d has %d copies, keeping %v\n", vid, shardId, len(ecNodes), ecNodes[0].info.Id)
+		fmt.Fprintf(writer, "ec shard %d.%d has %d copies, keeping %v\n", vid, shardId, len(ecNodes), ecNodes[0].info.Id)
 		if !applyBalancing {
 			continue
 		}
 
 		duplicatedShardIds := []uint32{uint32(shardId)}
 		for _, ecNode := range ecNodes[1:] {
-			if err := unmountEcShards(ctx, commandEnv.option.GrpcDialOption, vid, ecNode.info.Id, duplicatedShardIds); err != nil {
+			if err := unmountEcShards(ctx, commandEnv.option.GrpcDialOption, writer, vid, ecNode.info.Id, duplicatedShardIds); err != nil {
 				return err
 			}
-			if err := sourceServerDeleteEcShards(ctx, commandEnv.option.GrpcDialOption, collection, vid, ecNode.info.Id, duplicatedShardIds); err != nil {
+			if err := sourceServerDeleteEcShards(ctx, commandEnv.option.GrpcDialOption, writer, collection, vid, ecNode.info.Id, duplicatedShardIds); err != nil {
 				return err
 			}
 			ecNode.deleteEcVolumeShards(vid, duplicatedShardIds)
@@ -227,19 +252,19 @@ func doDeduplicateEcShards(ctx context.Context, commandEnv *CommandEnv, collecti
 	return nil
 }
 
-func balanceEcShardsAcrossRacks(ctx context.Context, commandEnv *CommandEnv, allEcNodes []*EcNode, racks map[RackId]*EcRack, collection string, applyBalancing bool) error {
+func balanceEcShardsAcrossRacks(ctx context.Context, commandEnv *CommandEnv, writer io.Writer, allEcNodes []*EcNode, racks map[RackId]*EcRack, collection string, applyBalancing bool) error {
 	// collect vid => []ecNode, since previous steps can change the locations
 	vidLocations := collectVolumeIdToEcNodes(allEcNodes)
 	// spread the ec shards evenly
 	for vid, locations := range vidLocations {
-		if err := doBalanceEcShardsAcrossRacks(ctx, commandEnv, collection, vid, locations, racks, applyBalancing); err != nil {
+		if err := doBalanceEcShardsAcrossRacks(ctx, commandEnv, writer, collection, vid, locations, racks, applyBalancing); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func doBalanceEcShardsAcrossRacks(ctx context.Context, commandEnv *CommandEnv, collection string, vid needle.VolumeId, locations []*EcNode, racks map[RackId]*EcRack, applyBalancing bool) error {
+func doBalanceEcShardsAcrossRacks(ctx context.Context, commandEnv *CommandEnv, writer io.Writer, collection string, vid needle.VolumeId, locations []*EcNode, racks map[RackId]*EcRack, applyBalancing bool) error {
 
 	// calculate average number of shards an ec rack should have for one volume
 	averageShardsPerEcRack := ceilDivide(erasure_coding.TotalShardsCount, len(racks))
@@ -266,11 +291,16 @@ func doBalanceEcShardsAcrossRacks(ctx context.Context, commandEnv *CommandEnv, c
 
 	for shardId, ecNode := range ecShardsToMove {
 		rackId := pickOneRack(racks, rackToShardCount, averageShardsPerEcRack)
+		if rackId == "" {
+			fmt.Fprintf(writer, "no rack has free slots for ec shard %d.%d on %s\n", vid, shardId, ecNode.info.Id)
+			ecNode.addEcVolumeShards(vid, collection, []uint32{uint32(shardId)})
+			continue
+		}
 		var possibleDestinationEcNodes []*EcNode
 		for _, n := range racks[rackId].ecNodes {
 			possibleDestinationEcNodes = append(possibleDestinationEcNodes, n)
 		}
-		err := pickOneEcNodeAndMoveOneShard(ctx, commandEnv, averageShardsPerEcRack, ecNode, collection, vid, shardId, possibleDestinationEcNodes, applyBalancing)
+		err := pickOneEcNodeAndMoveOneShard(ctx, commandEnv, writer, averageShardsPerEcRack, ecNode, collection, vid, shardId, possibleDestinationEcNodes, applyBalancing)
 		if err != nil {
 			return err
 		}
@@ -285,8 +315,8 @@ func doBalanceEcShardsAcrossRacks(ctx context.Context, commandEnv *CommandEnv, c
 
 func pickOneRack(rackToEcNodes map[RackId]*EcRack, rackToShardCount map[string]int, averageShardsPerEcRack int) RackId {
 
-	// TODO later may need to add some randomness
-
+	var picked RackId
+	var pickedScore float64
 	for rackId, rack := range rackToEcNodes {
 		if rackToShardCount[string(rackId)] >= averageShardsPerEcRack {
 			continue
@@ -296,13 +326,16 @@ func pickOneRack(rackToEcNodes map[RackId]*EcRack, rackToShardCount map[string]i
 			continue
 		}
 
-		return rackId
+		score := scoreEcRack(rack, rackToShardCount[string(rackId)])
+		if picked == "" || score < pickedScore || score == pickedScore && rackId < picked {
+			picked, pickedScore = rackId, score
+		}
 	}
 
-	return ""
+	return picked
 }
 
-func balanceEcShardsWithinRacks(ctx context.Context, commandEnv *CommandEnv, allEcNodes []*EcNode, racks map[RackId]*EcRack, collection string, applyBalancing bool) error {
+func balanceEcShardsWithinRacks(ctx context.Context, commandEnv *CommandEnv, writer io.Writer, allEcNodes []*EcNode, racks map[RackId]*EcRack, collection string, applyBalancing bool) error {
 	// collect vid => []ecNode, since previous steps can change the locations
 	vidLocations := collectVolumeIdToEcNodes(allEcNodes)
 
@@ -326,7 +359,7 @@ func balanceEcShardsWithinRacks(ctx context.Context, commandEnv *CommandEnv, all
 			}
 			sourceEcNodes := rackEcNodesWithVid[rackId]
 			averageShardsPerEcNode := ceilDivide(rackToShardCount[rackId], len(possibleDestinationEcNodes))
-			if err := doBalanceEcShardsWithinOneRack(ctx, commandEnv, averageShardsPerEcNode, collection, vid, sourceEcNodes, possibleDestinationEcNodes, applyBalancing); err != nil {
+			if err := doBalanceEcShardsWithinOneRack(ctx, commandEnv, writer, averageShardsPerEcNode, collection, vid, sourceEcNodes, possibleDestinationEcNodes, applyBalancing); err != nil {
 				return err
 			}
 		}
@@ -334,7 +367,7 @@ func balanceEcShardsWithinRacks(ctx context.Context, commandEnv *CommandEnv, all
 	return nil
 }
 
-func doBalanceEcShardsWithinOneRack(ctx context.Context, commandEnv *CommandEnv, averageShardsPerEcNode int, collection string, vid needle.VolumeId, existingLocations, possibleDestinationEcNodes []*EcNode, applyBalancing bool) error {
+func doBalanceEcShardsWithinOneRack(ctx context.Context, commandEnv *CommandEnv, writer io.Writer, averageShardsPerEcNode int, collection string, vid needle.VolumeId, existingLocations, possibleDestinationEcNodes []*EcNode, applyBalancing bool) error {
 
 	for _, ecNode := range existingLocations {
 
@@ -347,9 +380,9 @@ func doBalanceEcShardsWithinOneRack(ctx context.Context, commandEnv *CommandEnv,
 				break
 			}
 
-			fmt.Printf("%s has %d overlimit, moving ec shard %d.%d\n", ecNode.info.Id, overLimitCount, vid, shardId)
+			fmt.Fprintf(writer, "%s has %d overlimit, moving ec shard %d.%d\n", ecNode.info.Id, overLimitCount, vid, shardId)
 
-			err := pickOneEcNodeAndMoveOneShard(ctx, commandEnv, averageShardsPerEcNode, ecNode, collection, vid, shardId, possibleDestinationEcNodes, applyBalancing)
+			err := pickOneEcNodeAndMoveOneShard(ctx, commandEnv, writer, averageShardsPerEcNode, ecNode, collection, vid, shardId, possibleDestinationEcNodes, applyBalancing)
 			if err != nil {
 				return err
 			}
@@ -361,18 +394,18 @@ func doBalanceEcShardsWithinOneRack(ctx context.Context, commandEnv *CommandEnv,
 	return nil
 }
 
-func balanceEcRacks(ctx context.Context, commandEnv *CommandEnv, racks map[RackId]*EcRack, applyBalancing bool) error {
+func balanceEcRacks(ctx context.Context, commandEnv *CommandEnv, writer io.Writer, racks map[RackId]*EcRack, applyBalancing bool) error {
 
 	// balance one rack for all ec shards
 	for _, ecRack := range racks {
-		if err := doBalanceEcRack(ctx, commandEnv, ecRack, applyBalancing); err != nil {
+		if err := doBalanceEcRack(ctx, commandEnv, writer, ecRack, applyBalancing); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func doBalanceEcRack(ctx context.Context, commandEnv *CommandEnv, ecRack *EcRack, applyBalancing bool) error {
+func doBalanceEcRack(ctx context.Context, commandEnv *CommandEnv, writer io.Writer, ecRack *EcRack, applyBalancing bool) error {
 
 	if len(ecRack.ecNodes) <= 1 {
 		return nil
@@ -415,9 +448,9 @@ func doBalanceEcRack(ctx context.Context, commandEnv *CommandEnv, ecRack *EcRack
 				if _, found := emptyNodeIds[shards.Id]; !found {
 					for _, shardId := range erasure_coding.ShardBits(shards.EcIndexBits).ShardIds() {
 
-						fmt.Printf("%s moves ec shards %d.%d to %s\n", fullNode.info.Id, shards.Id, shardId, emptyNode.info.Id)
+						fmt.Fprintf(writer, "%s moves ec shards %d.%d to %s\n", fullNode.info.Id, shards.Id, shardId, emptyNode.info.Id)
 
-						err := moveMountedShardToEcNode(ctx, commandEnv, fullNode, shards.Collection, needle.VolumeId(shards.Id), shardId, emptyNode, applyBalancing)
+						err := moveMountedShardToEcNode(ctx, commandEnv, writer, fullNode, shards.Collection, needle.VolumeId(shards.Id), shardId, emptyNode, applyBalancing)
 						if err != nil {
 							return err
 						}
@@ -436,9 +469,9 @@ func doBalanceEcRack(ctx context.Context, commandEnv *CommandEnv, ecRack *EcRack
 	return nil
 }
 
-func pickOneEcNodeAndMoveOneShard(ctx context.Context, commandEnv *CommandEnv, expectedTotalEcShards int, existingLocation *EcNode, collection string, vid needle.VolumeId, shardId erasure_coding.ShardId, possibleDestinationEcNodes []*EcNode, applyBalancing bool) error {
+func pickOneEcNodeAndMoveOneShard(ctx context.Context, commandEnv *CommandEnv, writer io.Writer, expectedTotalEcShards int, existingLocation *EcNode, collection string, vid needle.VolumeId, shardId erasure_coding.ShardId, possibleDestinationEcNodes []*EcNode, applyBalancing bool) error {
 
-	sortEcNodes(possibleDestinationEcNodes)
+	sortEcNodesByScore(possibleDestinationEcNodes, vid)
 	averageShardsPerEcNode := ceilDivide(expectedTotalEcShards, len(possibleDestinationEcNodes))
 
 	for _, destEcNode := range possibleDestinationEcNodes {
@@ -453,9 +486,10 @@ func pickOneEcNodeAndMoveOneShard(ctx context.Context, commandEnv *CommandEnv, e
 			continue
 		}
 
-		fmt.Printf("%s moves ec shard %d.%d to %s\n", existingLocation.info.Id, vid, shardId, destEcNode.info.Id)
+		fmt.Fprintf(writer, "%s moves ec shard %d.%d to %s, score %.2f => %.2f\n", existingLocation.info.Id, vid, shardId, destEcNode.info.Id,
+			scoreEcNode(existingLocation, vid), scoreEcNode(destEcNode, vid))
 
-		err := moveMountedShardToEcNode(ctx, commandEnv, existingLocation, collection, vid, shardId, destEcNode, applyBalancing)
+		err := moveMountedShardToEcNode(ctx, commandEnv, writer, existingLocation, collection, vid, shardId, destEcNode, applyBalancing)
 		if err != nil {
 			return err
 		}
@@ -506,6 +540,68 @@ func pickNEcShardsToMoveFrom(ecNodes []*EcNode, vid needle.VolumeId, n int) map[
 	return picked
 }
 
+// ecShardAntiAffinityWeight makes one more shard of the same volume outweigh any difference of the fullness
+const ecShardAntiAffinityWeight = 10
+
+func ecNodeFullness(ecNode *EcNode) float64 {
+	used := countShards(ecNode.info.EcShardInfos)
+	if used+ecNode.freeEcSlot <= 0 {
+		return 1
+	}
+	return float64(used) / float64(used+ecNode.freeEcSlot)
+}
+
+// scoreEcNode scores the volume server as the destination of one more shard of the volume, the lower the better
+func scoreEcNode(ecNode *EcNode, vid needle.VolumeId) float64 {
+	return ecShardAntiAffinityWeight*float64(findEcVolumeShards(ecNode, vid).ShardIdCount()) + ecNodeFullness(ecNode)
+}
+
+// scoreEcRack scores the rack as the destination of one more shard of the volume, the lower the better
+func scoreEcRack(rack *EcRack, volumeShardCount int) float64 {
+	var used int
+	for _, ecNode := range rack.ecNodes {
+		used += countShards(ecNode.info.EcShardInfos)
+	}
+	fullness := 1.0
+	if used+rack.freeEcSlot > 0 {
+		fullness = float64(used) / float64(used+rack.freeEcSlot)
+	}
+	return ecShardAntiAffinityWeight*float64(volumeShardCount) + fullness
+}
+
+func sortEcNodesByScore(ecNodes []*EcNode, vid needle.VolumeId) {
+	sort.Slice(ecNodes, func(i, j int) bool {
+		si, sj := scoreEcNode(ecNodes[i], vid), scoreEcNode(ecNodes[j], vid)
+		if si != sj {
+			return si < sj
+		}
+		return ecNodes[i].info.Id < ecNodes[j].info.Id
+	})
+}
+
+// snapshotEcShards remembers the shards on each volume server, to count the shards moved by the plan
+func snapshotEcShards(allEcNodes []*EcNode) map[string]map[uint32]erasure_coding.ShardBits {
+	snapshot := make(map[string]map[uint32]erasure_coding.ShardBits)
+	for _, ecNode := range allEcNodes {
+		shards := make(map[uint32]erasure_coding.ShardBits)
+		for _, shardInfo := range ecNode.info.EcShardInfos {
+			shards[shardInfo.Id] = erasure_coding.ShardBits(shardInfo.EcIndexBits)
+		}
+		snapshot[ecNode.info.Id] = shards
+	}
+	return snapshot
+}
+
+func countMovedEcShards(before map[string]map[uint32]erasure_coding.ShardBits, allEcNodes []*EcNode) (count int) {
+	for _, ecNode := range allEcNodes {
+		for _, shardInfo := range ecNode.info.EcShardInfos {
+			added := erasure_coding.ShardBits(shardInfo.EcIndexBits).Minus(before[ecNode.info.Id][shardInfo.Id])
+			count += added.ShardIdCount()
+		}
+	}
+	return
+}
+
 func collectVolumeIdToEcNodes(allEcNodes []*EcNode) map[needle.VolumeId][]*EcNode {
 	vidLocations := make(map[needle.VolumeId][]*EcNode)
 	for _, ecNode := range allEcNodes {
@@ -27,7 +27,9 @@ func (c *commandEcRebuild) Name() string {
 func (c *commandEcRebuild) Help() string {
 	return `find and rebuild missing ec shards among volume servers
 
-	ec.rebuild [-c EACH_COLLECTION|<collection_name>] [-force]
+	ec.rebuild [-c EACH_COLLECTION|<collection_name>] [-force] [-maxMBps=0]
+
+	With -maxMBps, copying the shards out of each source volume server is throttled to the given MB/s.
 
 	Algorithm:
 
@@ -59,12 +61,14 @@ func (c *commandEcRebuild) Do(args []string, commandEnv *CommandEnv, writer io.W
 	fixCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
 	collection := fixCommand.String("collection", "EACH_COLLECTION", "collection name, or \"EACH_COLLECTION\" for each collection")
 	applyChanges := fixCommand.Bool("force", false, "apply the changes")
+	maxMBps := fixCommand.Int("maxMBps", 0, "limit the copying speed from each source volume server in MB/s, 0 means no limit")
 	if err = fixCommand.Parse(args); err != nil {
 		return nil
 	}
+	ioBytePerSecond := int64(*maxMBps) * 1024 * 1024
 
 	// collect all ec nodes
-	allEcNodes, _, err := collectEcNodes(context.Background(), commandEnv, "")
+	allEcNodes, _, err := collectEcNodes(context.Background(), commandEnv, "", nil)
 	if err != nil {
 		return err
 	}
@@ -74,15 +78,15 @@ func (c *commandEcRebuild) Do(args []string, commandEnv *CommandEnv, writer io.W
 		if err != nil {
 			return err
 		}
-		fmt.Printf("rebuildEcVolumes collections %+v\n", len(collections))
+		fmt.Fprintf(writer, "rebuildEcVolumes collections %+v\n", len(collections))
 		for _, c := range collections {
-			fmt.Printf("rebuildEcVolumes collection %+v\n", c)
-			if err = rebuildEcVolumes(commandEnv, allEcNodes, c, writer, *applyChanges); err != nil {
+			fmt.Fprintf(writer, "rebuildEcVolumes collection %+v\n", c)
+			if err = rebuildEcVolumes(commandEnv, allEcNodes, c, writer, *applyChanges, ioBytePerSecond); err != nil {
 				return err
 			}
 		}
 	} else {
-		if err = rebuildEcVolumes(commandEnv, allEcNodes, *collection, writer, *applyChanges); err != nil {
+		if err = rebuildEcVolumes(commandEnv, allEcNodes, *collection, writer, *applyChanges, ioBytePerSecond); err != nil {
 			return err
 		}
 	}
@@ -90,11 +94,11 @@ func (c *commandEcRebuild) Do(args []string, commandEnv *CommandEnv, writer io.W
 	return nil
 }
 
-func rebuildEcVolumes(commandEnv *CommandEnv, allEcNodes []*EcNode, collection string, writer io.Writer, applyChanges bool) error {
+func rebuildEcVolumes(commandEnv *CommandEnv, allEcNodes []*EcNode, collection string, writer io.Writer, applyChanges bool, ioBytePerSecond int64) error {
 
 	ctx := context.Background()
 
-	fmt.Printf("rebuildEcVolumes %s\n", collection)
+	fmt.Fprintf(writer, "rebuildEcVolumes %s\n", collection)
 
 	// collect vid => each shard locations, similar to ecShardMap in topology.go
 	ecShardMap := make(EcShardMap)
@@ -117,7 +121,7 @@ func rebuildEcVolumes(commandEnv *CommandEnv, allEcNodes []*EcNode, collection s
 			return fmt.Errorf("disk space is not enough")
 		}
 
-		if err := rebuildOneEcVolume(ctx, commandEnv, allEcNodes[0], collection, vid, locations, writer, applyChanges); err != nil {
+		if err := rebuildOneEcVolume(ctx, commandEnv, allEcNodes[0], collection, vid, locations, writer, applyChanges, ioBytePerSecond); err != nil {
 			return err
 		}
 	}
@@ -125,13 +129,13 @@ func rebuildEcVolumes(commandEnv *CommandEnv, allEcNodes []*EcNode, collection s
 	return nil
 }
 
-func rebuildOneEcVolume(ctx context.Context, commandEnv *CommandEnv, rebuilder *EcNode, collection string, volumeId needle.VolumeId, locations EcShardLocations, writer io.Writer, applyChanges bool) error {
+func rebuildOneEcVolume(ctx context.Context, commandEnv *CommandEnv, rebuilder *EcNode, collection string, volumeId needle.VolumeId, locations EcShardLocations, writer io.Writer, applyChanges bool, ioBytePerSecond int64) error {
 
-	fmt.Printf("rebuildOneEcVolume %s %d\n", collection, volumeId)
+	fmt.Fprintf(writer, "rebuildOneEcVolume %s %d\n", collection, volumeId)
 
 	// collect shard files to rebuilder local disk
 	var generatedShardIds []uint32
-	copiedShardIds, _, err := prepareDataToRecover(ctx, commandEnv, rebuilder, collection, volumeId, locations, writer, applyChanges)
+	copiedShardIds, _, err := prepareDataToRecover(ctx, commandEnv, rebuilder, collection, volumeId, locations, writer, applyChanges, ioBytePerSecond)
 	if err != nil {
 		return err
 	}
@@ -139,7 +143,7 @@ func rebuildOneEcVolume(ctx context.Context, commandEnv *CommandEnv, rebuilder *
 		// clean up working files
 
 		// ask the rebuilder to delete the copied shards
-		err = sourceServerDeleteEcShards(ctx, commandEnv.option.GrpcDialOption, collection, volumeId, rebuilder.info.Id, copiedShardIds)
+		err = sourceServerDeleteEcShards(ctx, commandEnv.option.GrpcDialOption, writer, collection, volumeId, rebuilder.info.Id, copiedShardIds)
 		if err != nil {
 			fmt.Fprintf(writer, "%s delete copied ec shards %s %d.%v\n", rebuilder.info.Id, collection, volumeId, copiedShardIds)
 		}
@@ -157,7 +161,7 @@ func rebuildOneEcVolume(ctx context.Context, commandEnv *CommandEnv, rebuilder *
 	}
 
 	// mount the generated shards
-	err = mountEcShards(ctx, commandEnv.option.GrpcDialOption, collection, volumeId, rebuilder.info.Id, generatedShardIds)
+	err = mountEcShards(ctx, commandEnv.option.GrpcDialOption, writer, collection, volumeId, rebuilder.info.Id, generatedShardIds)
 	if err != nil {
 		return err
 	}
@@ -183,7 +187,7 @@ func generateMissingShards(ctx context.Context, grpcDialOption grpc.DialOption,
 	return
 }
 
-func prepareDataToRecover(ctx context.Context, commandEnv *CommandEnv, rebuilder *EcNode, collection string, volumeId needle.VolumeId, locations EcShardLocations, writer io.Writer, applyBalancing bool) (copiedShardIds []uint32, localShardIds []uint32, err error) {
+func prepareDataToRecover(ctx context.Context, commandEnv *CommandEnv, rebuilder *EcNode, collection string, volumeId needle.VolumeId, locations EcShardLocations, writer io.Writer, applyBalancing bool, ioBytePerSecond int64) (copiedShardIds []uint32, localShardIds []uint32, err error) {
 
 	needEcxFile := true
 	var localShardBits erasure_coding.ShardBits
@@ -211,11 +215,12 @@ func prepareDataToRecover(ctx context.Context, commandEnv *CommandEnv, rebuilder
 		if applyBalancing {
 			copyErr = operation.WithVolumeServerClient(rebuilder.info.Id, commandEnv.option.GrpcDialOption, func(volumeServerClient volume_server_pb.VolumeServerClient) error {
 				_, copyErr := volumeServerClient.VolumeEcShardsCopy(ctx, &volume_server_pb.VolumeEcShardsCopyRequest{
-					VolumeId:       uint32(volumeId),
-					Collection:     collection,
-					ShardIds:       []uint32{uint32(shardId)},
-					CopyEcxFile:    needEcxFile,
-					SourceDataNode: ecNodes[0].info.Id,
+					VolumeId:        uint32(volumeId),
+					Collection:      collection,
+					ShardIds:        []uint32{uint32(shardId)},
+					CopyEcxFile:     needEcxFile,
+					SourceDataNode:  ecNodes[0].info.Id,
+					IoBytePerSecond: ioBytePerSecond,
 				})
 				return copyErr
 			})
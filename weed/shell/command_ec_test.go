@@ -2,6 +2,7 @@ package shell
 
 import (
 	"context"
+	"io/ioutil"
 	"testing"
 
 	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
@@ -16,7 +17,7 @@ func TestCommandEcBalanceSmall(t *testing.T) {
 	}
 
 	racks := collectRacks(allEcNodes)
-	balanceEcVolumes(nil, "c1", allEcNodes, racks, false)
+	balanceEcVolumes(nil, ioutil.Discard, "c1", allEcNodes, racks, false)
 }
 
 func TestCommandEcBalanceNothingToMove(t *testing.T) {
@@ -31,7 +32,7 @@ func TestCommandEcBalanceNothingToMove(t *testing.T) {
 	}
 
 	racks := collectRacks(allEcNodes)
-	balanceEcVolumes(nil, "c1", allEcNodes, racks, false)
+	balanceEcVolumes(nil, ioutil.Discard, "c1", allEcNodes, racks, false)
 }
 
 func TestCommandEcBalanceAddNewServers(t *testing.T) {
@@ -48,7 +49,7 @@ func TestCommandEcBalanceAddNewServers(t *testing.T) {
 	}
 
 	racks := collectRacks(allEcNodes)
-	balanceEcVolumes(nil, "c1", allEcNodes, racks, false)
+	balanceEcVolumes(nil, ioutil.Discard, "c1", allEcNodes, racks, false)
 }
 
 func TestCommandEcBalanceAddNewRacks(t *testing.T) {
@@ -65,7 +66,7 @@ func TestCommandEcBalanceAddNewRacks(t *testing.T) {
 	}
 
 	racks := collectRacks(allEcNodes)
-	balanceEcVolumes(nil, "c1", allEcNodes, racks, false)
+	balanceEcVolumes(nil, ioutil.Discard, "c1", allEcNodes, racks, false)
 }
 
 func TestCommandEcBalanceVolumeEvenButRackUneven(t *testing.T) {
@@ -107,8 +108,36 @@ func TestCommandEcBalanceVolumeEvenButRackUneven(t *testing.T) {
 	}
 
 	racks := collectRacks(allEcNodes)
-	balanceEcVolumes(nil, "c1", allEcNodes, racks, false)
-	balanceEcRacks(context.Background(), nil, racks, false)
+	balanceEcVolumes(nil, ioutil.Discard, "c1", allEcNodes, racks, false)
+	balanceEcRacks(context.Background(), nil, ioutil.Discard, racks, false)
+}
+
+func TestCommandEcBalanceScoring(t *testing.T) {
+
+	allEcNodes := []*EcNode{
+		newEcNode("dc1", "rack1", "dn1", 100).addEcVolumeAndShardsForTest(1, "c1", []uint32{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13}),
+		newEcNode("dc1", "rack2", "dn2", 100),
+		newEcNode("dc1", "rack2", "dn3", 100),
+	}
+
+	racks := collectRacks(allEcNodes)
+	before := snapshotEcShards(allEcNodes)
+	balanceEcVolumes(nil, ioutil.Discard, "c1", allEcNodes, racks, false)
+
+	if moved := countMovedEcShards(before, allEcNodes); moved != 7 {
+		t.Errorf("moved %d ec shards, expected 7", moved)
+	}
+	for _, ecNode := range allEcNodes {
+		count := findEcVolumeShards(ecNode, 1).ShardIdCount()
+		if ecNode.info.Id == "dn1" && count != 7 || ecNode.info.Id != "dn1" && (count < 3 || count > 4) {
+			t.Errorf("%s has %d shards of volume 1", ecNode.info.Id, count)
+		}
+	}
+
+	sortEcNodesByScore(allEcNodes, 1)
+	if allEcNodes[len(allEcNodes)-1].info.Id != "dn1" {
+		t.Errorf("picked %s as the last destination of volume 1", allEcNodes[len(allEcNodes)-1].info.Id)
+	}
 }
 
 func newEcNode(dc string, rack string, dataNodeId string, freeEcSlot int) *EcNode {
@@ -0,0 +1,64 @@
+package shell
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+)
+
+func init() {
+	Commands = append(Commands, &commandFsConflicts{})
+}
+
+type commandFsConflicts struct {
+}
+
+func (c *commandFsConflicts) Name() string {
+	return "fs.conflicts"
+}
+
+func (c *commandFsConflicts) Help() string {
+	return `list the entries written concurrently on both clusters replicating to each other
+
+	fs.conflicts http://<filer_server>:<port>/dir/
+
+	Replicating both ways by "weed filer.replicate", with the filers started with -clusterId,
+	the last writer wins when an entry is written on both clusters before replicated.
+	This command lists the kept entries under the directory, with the lost write or deletion.
+	The conflict is cleared once the entry is written again.
+
+`
+}
+
+func (c *commandFsConflicts) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+
+	filerServer, filerPort, path, err := commandEnv.parseUrl(findInputDirectory(args))
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	var conflictCount int
+	err = commandEnv.withFilerClient(ctx, filerServer, filerPort, func(client filer_pb.SeaweedFilerClient) error {
+		return doTraverse(ctx, writer, client, filer2.FullPath(path), func(parentPath filer2.FullPath, entry *filer_pb.Entry) error {
+			lost, found := entry.Extended[filer2.ExtendedWriteConflict]
+			if !found {
+				return nil
+			}
+			conflictCount++
+			kept := filer2.GetWriteStamp(entry.Extended)
+			fmt.Fprintf(writer, "%s\tkept %s written on %s, lost %s\n",
+				parentPath.Child(entry.Name), time.Unix(0, kept.TsNs).UTC().Format(time.RFC3339Nano), kept.ClusterId, lost)
+			return nil
+		})
+	})
+
+	fmt.Fprintf(writer, "%d conflicts\n", conflictCount)
+
+	return err
+}
@@ -26,18 +26,22 @@ func (c *commandFsLs) Name() string {
 func (c *commandFsLs) Help() string {
 	return `list all files under a directory
 
-	fs.ls [-l] [-a] /dir/
-	fs.ls [-l] [-a] /dir/file_name
-	fs.ls [-l] [-a] /dir/file_prefix
-	fs.ls [-l] [-a] http://<filer_server>:<port>/dir/
-	fs.ls [-l] [-a] http://<filer_server>:<port>/dir/file_name
-	fs.ls [-l] [-a] http://<filer_server>:<port>/dir/file_prefix
+	fs.ls [-l] [-a] [-s] /dir/
+	fs.ls [-l] [-a] [-s] /dir/file_name
+	fs.ls [-l] [-a] [-s] /dir/file_prefix
+	fs.ls [-l] [-a] [-s] http://<filer_server>:<port>/dir/
+	fs.ls [-l] [-a] [-s] http://<filer_server>:<port>/dir/file_name
+	fs.ls [-l] [-a] [-s] http://<filer_server>:<port>/dir/file_prefix
+
+	With -s, the listing has strong read consistency, i.e., includes the entries just written via the other filers,
+	if the filers share an eventually consistent store, e.g. Cassandra.
 `
 }
 
 func (c *commandFsLs) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
 
 	var isLongFormat, showHidden bool
+	readConsistency := filer2.ReadConsistencyDefault
 	for _, arg := range args {
 		if !strings.HasPrefix(arg, "-") {
 			break
@@ -48,6 +52,8 @@ func (c *commandFsLs) Do(args []string, commandEnv *CommandEnv, writer io.Writer
 				showHidden = true
 			case 'l':
 				isLongFormat = true
+			case 's':
+				readConsistency = filer2.ReadConsistencyStrong
 			}
 		}
 	}
@@ -69,13 +75,13 @@ func (c *commandFsLs) Do(args []string, commandEnv *CommandEnv, writer io.Writer
 
 	return commandEnv.withFilerClient(ctx, filerServer, filerPort, func(client filer_pb.SeaweedFilerClient) error {
 
-		return paginateOneDirectory(ctx, writer, client, dir, name, 1000, isLongFormat, showHidden)
+		return paginateOneDirectory(ctx, writer, client, dir, name, 1000, isLongFormat, showHidden, readConsistency)
 
 	})
 
 }
 
-func paginateOneDirectory(ctx context.Context, writer io.Writer, client filer_pb.SeaweedFilerClient, dir, name string, paginateSize int, isLongFormat, showHidden bool) (err error) {
+func paginateOneDirectory(ctx context.Context, writer io.Writer, client filer_pb.SeaweedFilerClient, dir, name string, paginateSize int, isLongFormat, showHidden bool, readConsistency filer2.ReadConsistency) (err error) {
 
 	entryCount := 0
 	paginatedCount := -1
@@ -88,6 +94,7 @@ func paginateOneDirectory(ctx context.Context, writer io.Writer, client filer_pb
 			StartFromFileName:  startFromFileName,
 			InclusiveStartFrom: false,
 			Limit:              uint32(paginateSize),
+			ReadConsistency:    string(readConsistency),
 		})
 		if listErr != nil {
 			err = listErr
@@ -129,7 +136,7 @@ func paginateOneDirectory(ctx context.Context, writer io.Writer, client filer_pb
 				fmt.Fprintf(writer, "%s %3d %s %s %6d %s/%s\n",
 					fileMode, len(entry.Chunks),
 					userName, groupName,
-					filer2.TotalSize(entry.Chunks), dir, entry.Name)
+					filer2.FileSize(entry), dir, entry.Name)
 			} else {
 				fmt.Fprintf(writer, "%s\n", entry.Name)
 			}
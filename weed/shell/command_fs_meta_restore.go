@@ -0,0 +1,175 @@
+package shell
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/operation"
+	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+	"github.com/chrislusf/seaweedfs/weed/util"
+)
+
+func init() {
+	Commands = append(Commands, &commandFsMetaRestore{})
+}
+
+type commandFsMetaRestore struct {
+}
+
+func (c *commandFsMetaRestore) Name() string {
+	return "fs.meta.restore"
+}
+
+func (c *commandFsMetaRestore) Help() string {
+	return `restore the filer meta data snapshot, erasure coded in the cluster by the filer "meta_backup" option
+
+	fs.meta.restore -list                         # list the snapshots
+	fs.meta.restore                               # restore the latest snapshot
+	fs.meta.restore -snapshot=<unix_seconds>      # restore one snapshot
+	fs.meta.restore -collection=filer_meta_backup # the collection configured in filer.toml
+
+	The snapshots are found by scanning the volumes in the collection, so the filer store is not needed.
+	The missing or corrupted blocks are reconstructed from the parity blocks.
+	The meta data will be saved into a local filer-meta-<time>.meta file,
+	which can be loaded by fs.meta.load command.
+
+`
+}
+
+func (c *commandFsMetaRestore) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+
+	restoreCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	collection := restoreCommand.String("collection", "filer_meta_backup", "the collection of the meta data snapshots")
+	snapshot := restoreCommand.Int64("snapshot", 0, "the unix seconds of the snapshot to restore, default to the latest")
+	listOnly := restoreCommand.Bool("list", false, "only list the snapshots")
+	if err = restoreCommand.Parse(args); err != nil {
+		return nil
+	}
+
+	ctx := context.Background()
+
+	manifests, err := findMetaBackupManifests(ctx, commandEnv, writer, *collection)
+	if err != nil {
+		return err
+	}
+	if len(manifests) == 0 {
+		return fmt.Errorf("no meta data snapshot in collection %s", *collection)
+	}
+
+	if *listOnly {
+		for _, m := range manifests {
+			fmt.Fprintf(writer, "snapshot %d %v: %d directories, %d files, %d bytes, %d+%d shards\n",
+				m.CreatedAt/int64(time.Second), time.Unix(0, m.CreatedAt), m.DirCount, m.FileCount, m.Size, m.DataShards, m.ParityShards)
+		}
+		return nil
+	}
+
+	manifest := manifests[len(manifests)-1]
+	if *snapshot != 0 {
+		manifest = nil
+		for _, m := range manifests {
+			if m.CreatedAt/int64(time.Second) == *snapshot {
+				manifest = m
+			}
+		}
+		if manifest == nil {
+			return fmt.Errorf("snapshot %d not found", *snapshot)
+		}
+	}
+
+	fileName := fmt.Sprintf("filer-meta-%d.meta", manifest.CreatedAt/int64(time.Second))
+	dst, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	err = filer2.RestoreMetaBackup(manifest, func(fileId string) ([]byte, error) {
+		fileUrl, err := commandEnv.MasterClient.LookupFileId(fileId)
+		if err != nil {
+			return nil, err
+		}
+		return util.Get(fileUrl)
+	}, dst)
+	if err != nil {
+		os.Remove(fileName)
+		return err
+	}
+
+	fmt.Fprintf(writer, "snapshot of %v with %d directories, %d files is restored to %s\n",
+		time.Unix(0, manifest.CreatedAt), manifest.DirCount, manifest.FileCount, fileName)
+	fmt.Fprintf(writer, "load it by: fs.meta.load %s\n", fileName)
+
+	return nil
+}
+
+// findMetaBackupManifests tails every volume in the collection for the manifest blobs, sorted by the creation time
+func findMetaBackupManifests(ctx context.Context, commandEnv *CommandEnv, writer io.Writer, collection string) (manifests []*filer2.MetaBackupManifest, err error) {
+
+	var resp *master_pb.VolumeListResponse
+	err = commandEnv.MasterClient.WithClient(ctx, func(client master_pb.SeaweedClient) error {
+		resp, err = client.VolumeList(ctx, &master_pb.VolumeListRequest{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	volumeLocations := make(map[uint32][]string)
+	eachDataNode(resp.TopologyInfo, func(dc string, rack RackId, dn *master_pb.DataNodeInfo) {
+		for _, v := range dn.VolumeInfos {
+			if v.Collection == collection {
+				volumeLocations[v.Id] = append(volumeLocations[v.Id], dn.Id)
+			}
+		}
+	})
+
+	found := make(map[int64]*filer2.MetaBackupManifest)
+	for vid, locations := range volumeLocations {
+		var tailErr error
+		for _, location := range locations {
+			tailErr = operation.TailVolumeFromSource(location, commandEnv.option.GrpcDialOption, needle.VolumeId(vid), 0, 1, func(n *needle.Needle) error {
+				if !n.HasName() || !strings.HasSuffix(string(n.Name), filer2.MetaBackupManifestSuffix) {
+					return nil
+				}
+				data := n.Data
+				if n.IsGzipped() {
+					var unzipErr error
+					if data, unzipErr = util.UnGzipData(data); unzipErr != nil {
+						return nil
+					}
+				}
+				m := &filer2.MetaBackupManifest{}
+				if jsonErr := json.Unmarshal(data, m); jsonErr != nil {
+					fmt.Fprintf(writer, "skip bad manifest %s in volume %d: %v\n", n.Name, vid, jsonErr)
+					return nil
+				}
+				found[m.CreatedAt] = m
+				return nil
+			})
+			if tailErr == nil {
+				break
+			}
+		}
+		if tailErr != nil {
+			fmt.Fprintf(writer, "skip volume %d: %v\n", vid, tailErr)
+		}
+	}
+
+	for _, m := range found {
+		manifests = append(manifests, m)
+	}
+	sort.Slice(manifests, func(i, j int) bool {
+		return manifests[i].CreatedAt < manifests[j].CreatedAt
+	})
+	return manifests, nil
+}
@@ -0,0 +1,95 @@
+package shell
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/chrislusf/seaweedfs/weed/search"
+	"github.com/dustin/go-humanize"
+)
+
+func init() {
+	Commands = append(Commands, &commandFsSearch{})
+}
+
+type commandFsSearch struct {
+}
+
+func (c *commandFsSearch) Name() string {
+	return "fs.search"
+}
+
+func (c *commandFsSearch) Help() string {
+	return `search the entries indexed by "weed filer.search"
+
+	fs.search report                                 # the entries under the current directory named like "report"
+	fs.search -path=/docs -mime=application/pdf annual report
+	fs.search -path=/ -minSize=1048576 -files        # all the files of 1MB or more
+	fs.search -attr=project=apollo -attr=stage=final # the entries with the extended attributes
+
+	The words are matched against the names and the paths, and the conditions are combined by AND.
+	The elasticsearch servers, the index and the credentials are the same as of "weed filer.search".
+
+`
+}
+
+func (c *commandFsSearch) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+
+	searchCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	elastic := searchCommand.String("es", "http://localhost:9200", "comma separated elasticsearch servers")
+	index := searchCommand.String("index", "seaweedfs", "the elasticsearch index of the entries")
+	dir := searchCommand.String("path", ".", "search the entries under the directory")
+	mime := searchCommand.String("mime", "", "the prefix of the mime type")
+	minSize := searchCommand.Uint64("minSize", 0, "the minimum file size in bytes")
+	maxSize := searchCommand.Uint64("maxSize", 0, "the maximum file size in bytes, 0 for no limit")
+	filesOnly := searchCommand.Bool("files", false, "the files only, without the directories")
+	limit := searchCommand.Int("limit", 100, "the maximum number of entries to list")
+	query := &search.Query{}
+	searchCommand.Var(attributeFlag{query}, "attr", "<key>=<value> of the extended attribute, repeatable")
+	if err = searchCommand.Parse(args); err != nil {
+		return nil
+	}
+
+	_, _, query.Directory, err = commandEnv.parseUrl(*dir)
+	if err != nil {
+		return err
+	}
+	query.Text = strings.Join(searchCommand.Args(), " ")
+	query.MimePrefix = *mime
+	query.MinSize = *minSize
+	query.MaxSize = *maxSize
+	query.FilesOnly = *filesOnly
+	query.Limit = *limit
+
+	client := search.NewElasticClient(strings.Split(*elastic, ","), *index, os.Getenv("ES_USERNAME"), os.Getenv("ES_PASSWORD"))
+	result, err := client.Search(query)
+	if err != nil {
+		return err
+	}
+
+	for _, doc := range result.Hits {
+		if doc.IsDirectory {
+			fmt.Fprintf(writer, "%10s %s/\n", "-", doc.Path)
+		} else {
+			fmt.Fprintf(writer, "%10s %s\n", humanize.IBytes(doc.Size), doc.Path)
+		}
+	}
+	fmt.Fprintf(writer, "listed %d of %d entries\n", len(result.Hits), result.Total)
+
+	return nil
+}
+
+type attributeFlag struct {
+	query *search.Query
+}
+
+func (f attributeFlag) String() string {
+	return ""
+}
+
+func (f attributeFlag) Set(value string) error {
+	return f.query.AddAttribute(value)
+}
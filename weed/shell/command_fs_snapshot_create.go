@@ -0,0 +1,69 @@
+package shell
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+)
+
+func init() {
+	Commands = append(Commands, &commandFsSnapshotCreate{})
+}
+
+type commandFsSnapshotCreate struct {
+}
+
+func (c *commandFsSnapshotCreate) Name() string {
+	return "fs.snapshot.create"
+}
+
+func (c *commandFsSnapshotCreate) Help() string {
+	return `create a read only snapshot of a directory
+
+	fs.snapshot.create <directory> <snapshot name>
+
+	fs.snapshot.create /buckets/images images-2019-06-01
+	fs.snapshot.create http://<filer_server>:<port>/buckets/images images-2019-06-01
+
+	The snapshot copies the entries of the directory tree to /.snapshots/<snapshot name>/,
+	sharing the file chunks with the directory. It does not copy the file content.
+	The chunks are kept until the files are deleted in both the directory and all the snapshots.
+
+	The snapshots can be listed by "fs.ls /.snapshots/", and deleted by "fs.snapshot.delete".
+
+`
+}
+
+func (c *commandFsSnapshotCreate) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+
+	if len(args) != 2 {
+		return fmt.Errorf("need the directory and the snapshot name")
+	}
+
+	filerServer, filerPort, path, err := commandEnv.parseUrl(args[0])
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	return commandEnv.withFilerClient(ctx, filerServer, filerPort, func(client filer_pb.SeaweedFilerClient) error {
+
+		resp, err := client.CreateSnapshot(ctx, &filer_pb.CreateSnapshotRequest{
+			Directory: path,
+			Name:      args[1],
+		})
+		if err != nil {
+			return fmt.Errorf("create snapshot %s of %s: %v", args[1], path, err)
+		}
+
+		fmt.Fprintf(writer, "created %s with %d entries and %d chunks\n",
+			filer2.SnapshotsDir.Child(args[1]), resp.EntryCount, resp.ChunkCount)
+
+		return nil
+	})
+
+}
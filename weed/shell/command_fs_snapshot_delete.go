@@ -0,0 +1,67 @@
+package shell
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+)
+
+func init() {
+	Commands = append(Commands, &commandFsSnapshotDelete{})
+}
+
+type commandFsSnapshotDelete struct {
+}
+
+func (c *commandFsSnapshotDelete) Name() string {
+	return "fs.snapshot.delete"
+}
+
+func (c *commandFsSnapshotDelete) Help() string {
+	return `delete a snapshot created by "fs.snapshot.create"
+
+	fs.snapshot.delete /.snapshots/<snapshot name>
+	fs.snapshot.delete http://<filer_server>:<port>/.snapshots/<snapshot name>
+
+	The file chunks only used by this snapshot are deleted.
+	The chunks still used by the live files or the other snapshots are kept.
+
+`
+}
+
+func (c *commandFsSnapshotDelete) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+
+	if len(args) != 1 {
+		return fmt.Errorf("need the snapshot path, e.g. %s/<snapshot name>", filer2.SnapshotsDir)
+	}
+
+	filerServer, filerPort, path, err := commandEnv.parseUrl(args[0])
+	if err != nil {
+		return err
+	}
+
+	dir, name := filer2.FullPath(path).DirAndName()
+	if filer2.FullPath(dir) != filer2.SnapshotsDir || name == "" {
+		return fmt.Errorf("%s is not a snapshot under %s", path, filer2.SnapshotsDir)
+	}
+
+	ctx := context.Background()
+
+	return commandEnv.withFilerClient(ctx, filerServer, filerPort, func(client filer_pb.SeaweedFilerClient) error {
+
+		resp, err := client.DeleteSnapshot(ctx, &filer_pb.DeleteSnapshotRequest{
+			Name: name,
+		})
+		if err != nil {
+			return fmt.Errorf("delete snapshot %s: %v", name, err)
+		}
+
+		fmt.Fprintf(writer, "deleted %s and %d chunks only used by it\n", path, resp.DeletedChunkCount)
+
+		return nil
+	})
+
+}
@@ -0,0 +1,92 @@
+package shell
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+	"github.com/chrislusf/seaweedfs/weed/storage/types"
+)
+
+func init() {
+	Commands = append(Commands, &commandFsVerify{})
+}
+
+type commandFsVerify struct {
+}
+
+func (c *commandFsVerify) Name() string {
+	return "fs.verify"
+}
+
+func (c *commandFsVerify) Help() string {
+	return `verify the file chunks refer to existing needles in the volumes
+
+	fs.verify http://<filer_server>:<port>/dir/
+	fs.verify -v http://<filer_server>:<port>/dir/
+
+	This command checks each chunk of the files under the directory,
+	and reports the dangling chunks, whose volume or needle is missing or deleted.
+	To find the needles not referenced by the filer, use volume.fsck.
+
+`
+}
+
+func (c *commandFsVerify) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+
+	verifyCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	verbose := verifyCommand.Bool("v", false, "list each dangling chunk")
+	if err = verifyCommand.Parse(args); err != nil {
+		return nil
+	}
+
+	filerServer, filerPort, path, err := commandEnv.parseUrl(findInputDirectory(verifyCommand.Args()))
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	volumeLocations, volumeCollections, ecVolumes, err := collectVolumeLocations(ctx, commandEnv)
+	if err != nil {
+		return err
+	}
+
+	// the volume index files are read when a chunk refers to the volume for the first time
+	volumeNeedles := make(map[uint32]map[types.NeedleId]bool)
+	var chunkCount, danglingCount int64
+	err = traverseFileChunks(ctx, commandEnv, filerServer, filerPort, path, func(fullPath filer2.FullPath, fid *needle.FileId) {
+		vid := uint32(fid.VolumeId)
+		if ecVolumes[vid] {
+			// the erasure coded volumes are not checked
+			return
+		}
+		chunkCount++
+		needles, found := volumeNeedles[vid]
+		if !found {
+			if locations := volumeLocations[vid]; len(locations) > 0 {
+				var readErr error
+				if needles, readErr = readVolumeNeedles(ctx, commandEnv, vid, volumeCollections[vid], locations[0]); readErr != nil {
+					fmt.Fprintf(writer, "read volume %d index from %s: %v\n", vid, locations[0], readErr)
+				}
+			}
+			volumeNeedles[vid] = needles
+		}
+		if !needles[fid.Key] {
+			danglingCount++
+			if *verbose {
+				fmt.Fprintf(writer, "dangling chunk %s in %s\n", fid, fullPath)
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(writer, "verified %d chunks in %d volumes: %d dangling chunks\n", chunkCount, len(volumeNeedles), danglingCount)
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package shell
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/operation"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+	"github.com/chrislusf/seaweedfs/weed/util"
+)
+
+func init() {
+	Commands = append(Commands, &commandFsWarmup{})
+}
+
+type commandFsWarmup struct {
+}
+
+func (c *commandFsWarmup) Name() string {
+	return "fs.warmup"
+}
+
+func (c *commandFsWarmup) Help() string {
+	return `read the file chunks into the page cache of the volume servers
+
+	fs.warmup http://<filer_server>:<port>/dir/
+	fs.warmup http://<filer_server>:<port>/dir/file_name
+	fs.warmup -allReplicas -concurrency=8 http://<filer_server>:<port>/dir/
+
+	This command reads all chunks of the files, recursively for a directory, and discards the content.
+	It is useful ahead of an anticipated load, e.g., before a product launch or a batch job.
+	By default one replica of each chunk is read. With -allReplicas, each replica is read.
+
+`
+}
+
+func (c *commandFsWarmup) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+
+	warmupCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	allReplicas := warmupCommand.Bool("allReplicas", false, "read every replica of the chunks")
+	concurrency := warmupCommand.Int("concurrency", 4, "number of chunks to read concurrently")
+	if err = warmupCommand.Parse(args); err != nil {
+		return nil
+	}
+	if *concurrency <= 0 {
+		*concurrency = 1
+	}
+
+	filerServer, filerPort, path, err := commandEnv.parseUrl(findInputDirectory(warmupCommand.Args()))
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	var chunkCount, byteCount, failedCount int64
+	chunks := make(chan *filer_pb.FileChunk, *concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < *concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for chunk := range chunks {
+				n, warmupErr := warmupChunk(commandEnv, chunk, *allReplicas)
+				atomic.AddInt64(&byteCount, n)
+				if warmupErr != nil {
+					atomic.AddInt64(&failedCount, 1)
+					fmt.Fprintf(writer, "warmup chunk %s: %v\n", chunk.FileId, warmupErr)
+					continue
+				}
+				atomic.AddInt64(&chunkCount, 1)
+			}
+		}()
+	}
+
+	var fileCount int64
+	err = commandEnv.withFilerClient(ctx, filerServer, filerPort, func(client filer_pb.SeaweedFilerClient) error {
+
+		if !commandEnv.isDirectory(ctx, filerServer, filerPort, path) {
+			dir, name := filer2.FullPath(path).DirAndName()
+			resp, lookupErr := client.LookupDirectoryEntry(ctx, &filer_pb.LookupDirectoryEntryRequest{
+				Directory: dir,
+				Name:      name,
+			})
+			if lookupErr != nil {
+				return lookupErr
+			}
+			fileCount++
+			for _, chunk := range resp.Entry.Chunks {
+				chunks <- chunk
+			}
+			return nil
+		}
+
+		return doTraverse(ctx, writer, client, filer2.FullPath(path), func(parentPath filer2.FullPath, entry *filer_pb.Entry) error {
+			if entry.IsDirectory {
+				return nil
+			}
+			fileCount++
+			for _, chunk := range entry.Chunks {
+				chunks <- chunk
+			}
+			return nil
+		})
+
+	})
+	close(chunks)
+	wg.Wait()
+
+	fmt.Fprintf(writer, "warmed up %d files, %d chunks, %d bytes, %d chunks failed\n", fileCount, chunkCount, byteCount, failedCount)
+
+	return err
+
+}
+
+// warmupChunk reads the chunk from one or all of its locations, discarding the content
+func warmupChunk(commandEnv *CommandEnv, chunk *filer_pb.FileChunk, allReplicas bool) (warmed int64, err error) {
+
+	vid, _, err := operation.ParseFileId(chunk.FileId)
+	if err != nil {
+		return 0, err
+	}
+	locations := commandEnv.MasterClient.GetVidLocations(vid)
+	if len(locations) == 0 {
+		return 0, fmt.Errorf("volume %s not found", vid)
+	}
+
+	for _, location := range locations {
+		n, readErr := util.ReadUrlAsStream(fmt.Sprintf("http://%s/%s", location.Url, chunk.FileId), 0, int(chunk.Size), func(data []byte) {})
+		warmed += n
+		if readErr != nil {
+			err = fmt.Errorf("read from %s: %v", location.Url, readErr)
+			continue
+		}
+		if !allReplicas {
+			return warmed, nil
+		}
+	}
+
+	return warmed, err
+}
@@ -0,0 +1,81 @@
+package shell
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+const (
+	OutputText = "text"
+	OutputJson = "json"
+)
+
+// commandOutput is one line of the json output, one per command
+type commandOutput struct {
+	Command string      `json:"command"`
+	Args    []string    `json:"args,omitempty"`
+	Result  interface{} `json:"result,omitempty"`
+	Output  []string    `json:"output,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+func CheckOutputFormat(format string) error {
+	switch format {
+	case "", OutputText, OutputJson:
+		return nil
+	}
+	return fmt.Errorf("unknown output format %s, expecting %s or %s", format, OutputText, OutputJson)
+}
+
+// setResult gives the structured result of the command for the json output,
+// otherwise the text lines written by the command are the json output.
+func (ce *CommandEnv) setResult(result interface{}) {
+	ce.result = result
+}
+
+func (ce *CommandEnv) runCommand(c command, args []string, writer io.Writer) error {
+	if ce.option.OutputFormat != OutputJson {
+		return c.Do(args, ce, writer)
+	}
+
+	var buf bytes.Buffer
+	ce.result = nil
+	err := c.Do(args, ce, &syncWriter{w: &buf})
+
+	output := commandOutput{
+		Command: c.Name(),
+		Args:    args,
+		Result:  ce.result,
+	}
+	ce.result = nil
+	if output.Result == nil {
+		for _, line := range strings.Split(strings.TrimRight(buf.String(), "\n"), "\n") {
+			if line = strings.TrimRight(line, " "); line != "" {
+				output.Output = append(output.Output, line)
+			}
+		}
+	}
+	if err != nil {
+		output.Error = err.Error()
+	}
+	if encodeErr := json.NewEncoder(writer).Encode(output); encodeErr != nil {
+		return encodeErr
+	}
+	return err
+}
+
+// syncWriter serializes the writes of the commands writing from several goroutines, e.g. when copying the ec shards
+type syncWriter struct {
+	sync.Mutex
+	w io.Writer
+}
+
+func (sw *syncWriter) Write(p []byte) (int, error) {
+	sw.Lock()
+	defer sw.Unlock()
+	return sw.w.Write(p)
+}
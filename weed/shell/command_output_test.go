@@ -0,0 +1,99 @@
+package shell
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"testing"
+)
+
+type fakeCommand struct {
+	name   string
+	result interface{}
+	err    error
+	runs   int
+}
+
+func (c *fakeCommand) Name() string { return c.name }
+func (c *fakeCommand) Help() string { return "a fake command" }
+func (c *fakeCommand) Do(args []string, commandEnv *CommandEnv, writer io.Writer) error {
+	c.runs++
+	fmt.Fprintf(writer, "line 1 of %s\n\nline 2 of %s  \n", c.name, c.name)
+	if c.result != nil {
+		commandEnv.setResult(c.result)
+	}
+	return c.err
+}
+
+// withFakeCommands replaces the commands, and returns the function to restore them
+func withFakeCommands(commands ...command) (restore func()) {
+	saved := Commands
+	Commands = commands
+	return func() { Commands = saved }
+}
+
+func TestRunCommandJson(t *testing.T) {
+	commandEnv := &CommandEnv{option: ShellOptions{OutputFormat: OutputJson}}
+
+	var buf bytes.Buffer
+	c := &fakeCommand{name: "fake.text", err: fmt.Errorf("failed")}
+	if err := commandEnv.runCommand(c, []string{"-a"}, &buf); err == nil {
+		t.Errorf("the command error is lost")
+	}
+	var output commandOutput
+	if err := json.Unmarshal(buf.Bytes(), &output); err != nil {
+		t.Fatalf("parse %s: %v", buf.String(), err)
+	}
+	if output.Command != "fake.text" || len(output.Args) != 1 || output.Error != "failed" || output.Result != nil {
+		t.Errorf("unexpected output %+v", output)
+	}
+	if len(output.Output) != 2 || output.Output[0] != "line 1 of fake.text" || output.Output[1] != "line 2 of fake.text" {
+		t.Errorf("unexpected output lines %q", output.Output)
+	}
+
+	buf.Reset()
+	c = &fakeCommand{name: "fake.result", result: map[string]int{"count": 3}}
+	if err := commandEnv.runCommand(c, nil, &buf); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	output = commandOutput{}
+	if err := json.Unmarshal(buf.Bytes(), &output); err != nil {
+		t.Fatalf("parse %s: %v", buf.String(), err)
+	}
+	if output.Output != nil || fmt.Sprint(output.Result) != "map[count:3]" {
+		t.Errorf("unexpected output %+v", output)
+	}
+	if commandEnv.result != nil {
+		t.Errorf("the result is kept for the next command")
+	}
+}
+
+func TestRunCommandsUntilExit(t *testing.T) {
+	first := &fakeCommand{name: "fake.first", err: fmt.Errorf("first failed")}
+	second := &fakeCommand{name: "fake.second", err: fmt.Errorf("second failed")}
+	defer withFakeCommands(first, second)()
+	reg, _ := regexp.Compile(`'.*?'|".*?"|\S+`)
+	commandEnv := &CommandEnv{}
+
+	var buf bytes.Buffer
+	err := runCommands(reg, "fake.first; ; fake.second x; exit; fake.first", commandEnv, &buf)
+	if err == nil || err.Error() != "first failed" {
+		t.Errorf("expecting the first error, got %v", err)
+	}
+	if first.runs != 1 || second.runs != 1 {
+		t.Errorf("ran the commands after exit: %d, %d", first.runs, second.runs)
+	}
+
+	if err = runCommands(reg, "help", commandEnv, &buf); err != nil {
+		t.Errorf("help: %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("a fake command")) {
+		t.Errorf("help is not written to the writer: %s", buf.String())
+	}
+
+	if err = runCommands(reg, "fake.unknown", commandEnv, &buf); err == nil {
+		t.Errorf("ran an unknown command")
+	}
+}
@@ -0,0 +1,132 @@
+package shell
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
+	"github.com/chrislusf/seaweedfs/weed/storage/erasure_coding"
+)
+
+func init() {
+	Commands = append(Commands, &commandVolumeCapacity{})
+}
+
+type commandVolumeCapacity struct {
+}
+
+func (c *commandVolumeCapacity) Name() string {
+	return "volume.capacity"
+}
+
+func (c *commandVolumeCapacity) Help() string {
+	return `report the utilization of each volume server, and the expected days until full
+
+	volume.capacity [-dataCenter=<data_center_name>] [-days=<n>]
+
+	The volume servers expected to be full soonest are listed first.
+	The growth is forecast by the master from the utilization samples, see "weed master -forecast.sampleInterval".
+	The used bytes count each ec shard as its share of a full volume.
+
+`
+}
+
+type volumeServerCapacity struct {
+	dc, rack, id   string
+	used, capacity uint64
+	growthPerDay   float64
+	daysToFull     float64
+	hasForecast    bool
+}
+
+func (c *commandVolumeCapacity) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+
+	capacityCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	dc := capacityCommand.String("dataCenter", "", "only report the volume servers in this dataCenter")
+	days := capacityCommand.Float64("days", 0, "only report the volume servers expected to be full within the days")
+	if err = capacityCommand.Parse(args); err != nil {
+		return nil
+	}
+
+	var resp *master_pb.VolumeListResponse
+	ctx := context.Background()
+	err = commandEnv.MasterClient.WithClient(ctx, func(client master_pb.SeaweedClient) error {
+		resp, err = client.VolumeList(ctx, &master_pb.VolumeListRequest{})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	volumeSizeLimit := resp.VolumeSizeLimitMb * 1024 * 1024
+	var servers []volumeServerCapacity
+	for _, dcInfo := range resp.TopologyInfo.DataCenterInfos {
+		if *dc != "" && dcInfo.Id != *dc {
+			continue
+		}
+		for _, rack := range dcInfo.RackInfos {
+			for _, dn := range rack.DataNodeInfos {
+				s := volumeServerCapacity{
+					dc:           dcInfo.Id,
+					rack:         rack.Id,
+					id:           dn.Id,
+					capacity:     dn.MaxVolumeCount * volumeSizeLimit,
+					growthPerDay: dn.GrowthBytesPerDay,
+					daysToFull:   dn.DaysToFull,
+					hasForecast:  dn.DaysToFull > 0,
+				}
+				for _, v := range dn.VolumeInfos {
+					s.used += v.Size
+				}
+				for _, ecShardInfo := range dn.EcShardInfos {
+					shardCount := erasure_coding.ShardBits(ecShardInfo.EcIndexBits).ShardIdCount()
+					s.used += uint64(shardCount) * volumeSizeLimit / erasure_coding.DataShardsCount
+				}
+				if *days > 0 && (!s.hasForecast || s.daysToFull > *days) {
+					continue
+				}
+				servers = append(servers, s)
+			}
+		}
+	}
+
+	sort.Slice(servers, func(i, j int) bool {
+		if servers[i].hasForecast != servers[j].hasForecast {
+			return servers[i].hasForecast
+		}
+		if servers[i].hasForecast && servers[i].daysToFull != servers[j].daysToFull {
+			return servers[i].daysToFull < servers[j].daysToFull
+		}
+		return servers[i].id < servers[j].id
+	})
+
+	var totalUsed, totalCapacity uint64
+	var totalGrowth float64
+	for _, s := range servers {
+		fmt.Fprintf(writer, "%s %s %s used:%d/%d %s growth:%.0f/day", s.dc, s.rack, s.id, s.used, s.capacity, percentage(s.used, s.capacity), s.growthPerDay)
+		if s.hasForecast {
+			fmt.Fprintf(writer, " daysToFull:%.1f", s.daysToFull)
+		}
+		fmt.Fprintln(writer)
+		totalUsed += s.used
+		totalCapacity += s.capacity
+		totalGrowth += s.growthPerDay
+	}
+	fmt.Fprintf(writer, "Total %d volume servers used:%d/%d %s growth:%.0f/day", len(servers), totalUsed, totalCapacity, percentage(totalUsed, totalCapacity), totalGrowth)
+	if totalGrowth > 0 && totalCapacity > totalUsed {
+		fmt.Fprintf(writer, " daysToFull:%.1f", float64(totalCapacity-totalUsed)/totalGrowth)
+	}
+	fmt.Fprintln(writer)
+
+	return nil
+}
+
+func percentage(used, capacity uint64) string {
+	if capacity == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", float64(used)*100/float64(capacity))
+}
@@ -0,0 +1,269 @@
+package shell
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/chrislusf/seaweedfs/weed/operation"
+	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
+	"github.com/chrislusf/seaweedfs/weed/pb/volume_server_pb"
+	"github.com/chrislusf/seaweedfs/weed/storage/idx"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+	"github.com/chrislusf/seaweedfs/weed/storage/types"
+)
+
+func init() {
+	Commands = append(Commands, &commandVolumeCheckDisk{})
+}
+
+type commandVolumeCheckDisk struct {
+}
+
+func (c *commandVolumeCheckDisk) Name() string {
+	return "volume.check.disk"
+}
+
+func (c *commandVolumeCheckDisk) Help() string {
+	return `compare the needles of all the replicas of the volumes
+
+	volume.check.disk [-volumeId=<volume id>] [-collection=<collection name>] [-v] [-sync]
+
+	This command reads the index files of all the replicas of each volume, and reports the needles
+		missing: on some replicas, but not on this replica
+		divergent: on this replica with a different size from the newest replica
+		deleted: deleted on some replicas, but still on this replica
+	The newest replica is the one modified last, and the needles are copied from it,
+	or from the next newest replica having the needle.
+	With -sync, the missing and divergent needles are copied to the replica, and the deleted ones are deleted from it.
+
+	The needles written or deleted during the check could be reported as different falsely.
+	Run it when there are no writes in flight, especially with -sync.
+
+`
+}
+
+func (c *commandVolumeCheckDisk) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+
+	checkCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	volumeId := checkCommand.Int("volumeId", 0, "only check this volume")
+	collection := checkCommand.String("collection", "", "only check the volumes in this collection")
+	verbose := checkCommand.Bool("v", false, "list each different needle")
+	applySync := checkCommand.Bool("sync", false, "sync the different needles from the newest replica")
+	if err = checkCommand.Parse(args); err != nil {
+		return nil
+	}
+
+	ctx := context.Background()
+
+	var resp *master_pb.VolumeListResponse
+	err = commandEnv.MasterClient.WithClient(ctx, func(client master_pb.SeaweedClient) error {
+		resp, err = client.VolumeList(ctx, &master_pb.VolumeListRequest{})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// vid => replicas, the newest first
+	volumeReplicas := make(map[uint32][]*volumeReplica)
+	eachDataNode(resp.TopologyInfo, func(dc string, rack RackId, dn *master_pb.DataNodeInfo) {
+		for _, v := range dn.VolumeInfos {
+			if *volumeId != 0 && v.Id != uint32(*volumeId) || *collection != "" && v.Collection != *collection {
+				continue
+			}
+			volumeReplicas[v.Id] = append(volumeReplicas[v.Id], &volumeReplica{server: dn.Id, info: v})
+		}
+	})
+
+	var vids []uint32
+	for vid, replicas := range volumeReplicas {
+		if len(replicas) > 1 {
+			vids = append(vids, vid)
+		}
+	}
+	sort.Slice(vids, func(i, j int) bool {
+		return vids[i] < vids[j]
+	})
+
+	var differentCount int
+	for _, vid := range vids {
+		replicas := volumeReplicas[vid]
+		sortNewestReplicasFirst(replicas)
+		for _, r := range replicas {
+			data, readErr := copyVolumeIndex(ctx, commandEnv, vid, r.info.Collection, r.server)
+			if readErr != nil {
+				return fmt.Errorf("read volume %d index from %s: %v", vid, r.server, readErr)
+			}
+			r.parseIndex(data)
+		}
+
+		syncs := diffVolumeReplicas(replicas)
+		differentCount += len(syncs)
+		reportNeedleSyncs(writer, needle.VolumeId(vid), syncs, *verbose)
+		if *applySync && len(syncs) > 0 {
+			if err = applyNeedleSyncs(ctx, commandEnv, needle.VolumeId(vid), syncs); err != nil {
+				return fmt.Errorf("sync volume %d: %v", vid, err)
+			}
+			fmt.Fprintf(writer, "volume %d: synced %d needles\n", vid, len(syncs))
+		}
+	}
+
+	fmt.Fprintf(writer, "checked %d replicated volumes: %d different needles\n", len(vids), differentCount)
+
+	return nil
+}
+
+type volumeReplica struct {
+	server  string
+	info    *master_pb.VolumeInformationMessage
+	live    map[types.NeedleId]uint32 // needle id => size
+	deleted map[types.NeedleId]bool
+}
+
+func (r *volumeReplica) parseIndex(data []byte) {
+	r.live = make(map[types.NeedleId]uint32)
+	r.deleted = make(map[types.NeedleId]bool)
+	for i := 0; i+types.NeedleMapEntrySize <= len(data); i += types.NeedleMapEntrySize {
+		key, offset, size := idx.IdxFileEntry(data[i : i+types.NeedleMapEntrySize])
+		if offset.IsZero() || size == types.TombstoneFileSize {
+			delete(r.live, key)
+			r.deleted[key] = true
+		} else {
+			r.live[key] = size
+			delete(r.deleted, key)
+		}
+	}
+}
+
+func sortNewestReplicasFirst(replicas []*volumeReplica) {
+	sort.SliceStable(replicas, func(i, j int) bool {
+		if replicas[i].info.ModifiedAtSecond != replicas[j].info.ModifiedAtSecond {
+			return replicas[i].info.ModifiedAtSecond > replicas[j].info.ModifiedAtSecond
+		}
+		return replicas[i].info.FileCount > replicas[j].info.FileCount
+	})
+}
+
+const (
+	needleMissing   = "missing"
+	needleDivergent = "divergent"
+	needleDeleted   = "deleted"
+)
+
+// needleSync is one different needle on the target replica, copied from the source replica, or deleted if deleted elsewhere
+type needleSync struct {
+	id     types.NeedleId
+	reason string
+	source string
+	target string
+}
+
+// diffVolumeReplicas compares the replicas, ordered the newest first.
+// A needle deleted on any replica is deleted on all replicas, and the other needles are synced from the newest replica having them.
+func diffVolumeReplicas(replicas []*volumeReplica) (syncs []needleSync) {
+	ids := make(map[types.NeedleId]bool)
+	for _, r := range replicas {
+		for id := range r.live {
+			ids[id] = true
+		}
+	}
+	for id := range ids {
+		var deleted bool
+		var source *volumeReplica
+		for _, r := range replicas {
+			if r.deleted[id] {
+				deleted = true
+			}
+			if _, found := r.live[id]; found && source == nil {
+				source = r
+			}
+		}
+		for _, r := range replicas {
+			size, found := r.live[id]
+			switch {
+			case deleted && found:
+				syncs = append(syncs, needleSync{id: id, reason: needleDeleted, target: r.server})
+			case deleted:
+			case !found:
+				syncs = append(syncs, needleSync{id: id, reason: needleMissing, source: source.server, target: r.server})
+			case size != source.live[id]:
+				syncs = append(syncs, needleSync{id: id, reason: needleDivergent, source: source.server, target: r.server})
+			}
+		}
+	}
+	sort.Slice(syncs, func(i, j int) bool {
+		if syncs[i].target != syncs[j].target {
+			return syncs[i].target < syncs[j].target
+		}
+		return syncs[i].id < syncs[j].id
+	})
+	return
+}
+
+func reportNeedleSyncs(writer io.Writer, vid needle.VolumeId, syncs []needleSync, verbose bool) {
+	counts := make(map[string]map[string]int)
+	var targets []string
+	for _, s := range syncs {
+		if counts[s.target] == nil {
+			counts[s.target] = make(map[string]int)
+			targets = append(targets, s.target)
+		}
+		counts[s.target][s.reason]++
+		if verbose {
+			fid := needle.NewFileId(vid, uint64(s.id), 0)
+			if s.source == "" {
+				fmt.Fprintf(writer, "  %s needle %s on %s\n", s.reason, fid, s.target)
+			} else {
+				fmt.Fprintf(writer, "  %s needle %s on %s, from %s\n", s.reason, fid, s.target, s.source)
+			}
+		}
+	}
+	for _, target := range targets {
+		fmt.Fprintf(writer, "volume %d on %s: %d missing, %d divergent, %d deleted needles\n", vid, target,
+			counts[target][needleMissing], counts[target][needleDivergent], counts[target][needleDeleted])
+	}
+}
+
+func applyNeedleSyncs(ctx context.Context, commandEnv *CommandEnv, vid needle.VolumeId, syncs []needleSync) error {
+
+	// target => source => needle ids to copy, and target => file ids to delete
+	copies := make(map[string]map[string][]uint64)
+	deletes := make(map[string][]string)
+	for _, s := range syncs {
+		if s.source == "" {
+			deletes[s.target] = append(deletes[s.target], needle.NewFileId(vid, uint64(s.id), 0).String())
+			continue
+		}
+		if copies[s.target] == nil {
+			copies[s.target] = make(map[string][]uint64)
+		}
+		copies[s.target][s.source] = append(copies[s.target][s.source], uint64(s.id))
+	}
+
+	for target, sources := range copies {
+		for source, needleIds := range sources {
+			err := operation.WithVolumeServerClient(target, commandEnv.option.GrpcDialOption, func(volumeServerClient volume_server_pb.VolumeServerClient) error {
+				_, copyErr := volumeServerClient.VolumeNeedlesCopy(ctx, &volume_server_pb.VolumeNeedlesCopyRequest{
+					VolumeId:       uint32(vid),
+					SourceDataNode: source,
+					NeedleIds:      needleIds,
+				})
+				return copyErr
+			})
+			if err != nil {
+				return fmt.Errorf("copy %d needles from %s to %s: %v", len(needleIds), source, target, err)
+			}
+		}
+	}
+
+	for target, fileIds := range deletes {
+		if err := purgeOrphanedNeedles(commandEnv, []string{target}, fileIds); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
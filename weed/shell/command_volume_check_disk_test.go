@@ -0,0 +1,37 @@
+package shell
+
+import (
+	"testing"
+
+	"github.com/chrislusf/seaweedfs/weed/storage/types"
+)
+
+func TestDiffVolumeReplicas(t *testing.T) {
+
+	newest := &volumeReplica{
+		server:  "dn1",
+		live:    map[types.NeedleId]uint32{1: 100, 2: 200, 3: 300},
+		deleted: map[types.NeedleId]bool{},
+	}
+	older := &volumeReplica{
+		server:  "dn2",
+		live:    map[types.NeedleId]uint32{1: 100, 2: 250, 4: 400},
+		deleted: map[types.NeedleId]bool{3: true},
+	}
+
+	syncs := diffVolumeReplicas([]*volumeReplica{newest, older})
+
+	expected := []needleSync{
+		{id: 3, reason: needleDeleted, target: "dn1"},
+		{id: 4, reason: needleMissing, source: "dn2", target: "dn1"},
+		{id: 2, reason: needleDivergent, source: "dn1", target: "dn2"},
+	}
+	if len(syncs) != len(expected) {
+		t.Fatalf("syncs %+v, expected %+v", syncs, expected)
+	}
+	for i := range expected {
+		if syncs[i] != expected[i] {
+			t.Errorf("sync %d: %+v, expected %+v", i, syncs[i], expected[i])
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package shell
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
+)
+
+func init() {
+	Commands = append(Commands, &commandVolumeConfigure{})
+}
+
+type commandVolumeConfigure struct {
+}
+
+func (c *commandVolumeConfigure) Name() string {
+	return "volume.configure"
+}
+
+func (c *commandVolumeConfigure) Help() string {
+	return `configure how the volumes of a collection grow
+
+	volume.configure                                                      # list the growth strategies
+	volume.configure -collection=pictures -growth=target -writable=20 -peakWritable=50 -peakHours=8-20
+	volume.configure -collection=logs -growth=adaptive -writable=5
+	volume.configure -collection=logs -remove                             # back to the fixed growth
+
+	The growth strategies:
+	  fixed:     grow a fixed number of volumes by the replication, only when no volume is writable. The default.
+	  target:    keep the -writable volumes writable, or the -peakWritable volumes during the -peakHours,
+	             in the local time of the master. The missing writable volumes are pre-allocated every minute.
+	  adaptive:  as the target strategy, and also pre-allocate as many volumes as were filled
+	             in the next hour on the previous days.
+
+	The strategies are kept by the masters through raft. The volumes filled in each hour are only counted
+	in the memory of the leader, so the adaptive strategy learns again from scratch after the master restarts
+	or the leader changes, pre-allocating only the -writable or -peakWritable volumes until then.
+
+`
+}
+
+func (c *commandVolumeConfigure) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+
+	configureCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	collection := configureCommand.String("collection", "", "the collection name")
+	growth := configureCommand.String("growth", "", "the growth strategy: fixed, target, or adaptive")
+	writable := configureCommand.Int("writable", 0, "the number of the writable volumes to keep")
+	peakWritable := configureCommand.Int("peakWritable", 0, "the number of the writable volumes to keep during the peak hours")
+	peakHours := configureCommand.String("peakHours", "", "the peak hours, as 8-20, or 20-6")
+	remove := configureCommand.Bool("remove", false, "remove the growth strategy of the collection")
+	if err = configureCommand.Parse(args); err != nil {
+		return nil
+	}
+
+	req := &master_pb.ConfigureVolumeGrowthRequest{}
+	if *remove {
+		req.Remove = true
+		req.Collection = *collection
+	} else if *growth != "" {
+		if *writable < 0 || *peakWritable < 0 {
+			return fmt.Errorf("negative writable volume count")
+		}
+		req.Strategy = &master_pb.VolumeGrowthStrategy{
+			Collection:         *collection,
+			Kind:               *growth,
+			WritableTarget:     uint32(*writable),
+			PeakWritableTarget: uint32(*peakWritable),
+			PeakHours:          *peakHours,
+		}
+	}
+
+	var resp *master_pb.ConfigureVolumeGrowthResponse
+	ctx := context.Background()
+	err = commandEnv.MasterClient.WithClient(ctx, func(client master_pb.SeaweedClient) error {
+		resp, err = client.ConfigureVolumeGrowth(ctx, req)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(resp.Strategies) == 0 {
+		fmt.Fprintf(writer, "all collections grow by the fixed strategy\n")
+		return nil
+	}
+	for _, s := range resp.Strategies {
+		fmt.Fprintf(writer, "collection %q: %s", s.Collection, s.Kind)
+		if s.Kind != "fixed" {
+			fmt.Fprintf(writer, " writable:%d", s.WritableTarget)
+			if s.PeakHours != "" {
+				fmt.Fprintf(writer, " peak:%d at %s", s.PeakWritableTarget, s.PeakHours)
+			}
+		}
+		fmt.Fprintf(writer, "\n")
+	}
+
+	return nil
+}
@@ -0,0 +1,293 @@
+package shell
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+
+	"github.com/chrislusf/seaweedfs/weed/filer2"
+	"github.com/chrislusf/seaweedfs/weed/operation"
+	"github.com/chrislusf/seaweedfs/weed/pb/filer_pb"
+	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
+	"github.com/chrislusf/seaweedfs/weed/pb/volume_server_pb"
+	"github.com/chrislusf/seaweedfs/weed/storage/idx"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+	"github.com/chrislusf/seaweedfs/weed/storage/types"
+)
+
+func init() {
+	Commands = append(Commands, &commandVolumeFsck{})
+}
+
+type commandVolumeFsck struct {
+}
+
+func (c *commandVolumeFsck) Name() string {
+	return "volume.fsck"
+}
+
+func (c *commandVolumeFsck) Help() string {
+	return `check the needles in the volumes against the chunks referenced by the filer
+
+	volume.fsck http://<filer_server>:<port>/
+	volume.fsck -collection=<collection name> -v http://<filer_server>:<port>/
+	volume.fsck -purge http://<filer_server>:<port>/
+
+	This command reads the index files of the volumes, except the erasure coded ones, and then all the file chunks in the filer.
+	It reports the orphaned needles, which no filer entry refers to,
+	and the dangling chunks, which refer to a missing or deleted needle.
+	Without -collection, only the volumes referenced by the filer are checked,
+	since the volumes could also be written without the filer.
+	With -purge, the orphaned needles are deleted from all replicas.
+	A collection not referenced by the filer at all, e.g. the one of "weed keymap", is not purged.
+
+	The needles written during the check could be reported as orphaned falsely.
+	Run it when there are no uploads in flight, especially with -purge.
+
+`
+}
+
+func (c *commandVolumeFsck) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+
+	fsckCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	collection := fsckCommand.String("collection", "", "check all volumes in the collection, not only the ones referenced by the filer")
+	verbose := fsckCommand.Bool("v", false, "list each orphaned needle and dangling chunk")
+	purge := fsckCommand.Bool("purge", false, "delete the orphaned needles")
+	if err = fsckCommand.Parse(args); err != nil {
+		return nil
+	}
+
+	filerServer, filerPort, path, err := commandEnv.parseUrl(findInputDirectory(fsckCommand.Args()))
+	if err != nil {
+		return err
+	}
+	if path != "/" {
+		// the needles referenced only outside of the path would be taken as orphaned
+		return fmt.Errorf("volume.fsck checks all the filer entries, expecting the root directory instead of %s", path)
+	}
+
+	ctx := context.Background()
+
+	volumeLocations, volumeCollections, ecVolumes, err := collectVolumeLocations(ctx, commandEnv)
+	if err != nil {
+		return err
+	}
+
+	// read the volume needles before the filer chunks, so that a chunk added meanwhile is not reported as dangling
+	volumeNeedles := make(map[uint32]map[types.NeedleId]bool)
+	for vid, locations := range volumeLocations {
+		if *collection != "" && volumeCollections[vid] != *collection {
+			continue
+		}
+		needles, readErr := readVolumeNeedles(ctx, commandEnv, vid, volumeCollections[vid], locations[0])
+		if readErr != nil {
+			return fmt.Errorf("read volume %d index from %s: %v", vid, locations[0], readErr)
+		}
+		volumeNeedles[vid] = needles
+	}
+
+	referenced := make(map[uint32]map[types.NeedleId]bool)
+	var danglingCount int64
+	err = traverseFileChunks(ctx, commandEnv, filerServer, filerPort, path, func(fullPath filer2.FullPath, fid *needle.FileId) {
+		vid := uint32(fid.VolumeId)
+		if referenced[vid] == nil {
+			referenced[vid] = make(map[types.NeedleId]bool)
+		}
+		referenced[vid][fid.Key] = true
+		if *collection != "" && volumeCollections[vid] != *collection || ecVolumes[vid] {
+			return
+		}
+		if needles, found := volumeNeedles[vid]; !found || !needles[fid.Key] {
+			danglingCount++
+			if *verbose {
+				fmt.Fprintf(writer, "dangling chunk %s in %s\n", fid, fullPath)
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if *purge && *collection != "" && !isCollectionReferenced(referenced, volumeCollections, *collection) {
+		// the needles are written by other clients than the filer
+		return fmt.Errorf("collection %s is not referenced by the filer, not purging it", *collection)
+	}
+
+	var orphanCount int64
+	for _, vid := range sortedVolumeIds(volumeNeedles) {
+		if *collection == "" && referenced[vid] == nil {
+			continue
+		}
+		var orphans []string
+		for key := range volumeNeedles[vid] {
+			if !referenced[vid][key] {
+				orphans = append(orphans, needle.NewFileId(needle.VolumeId(vid), uint64(key), 0).String())
+			}
+		}
+		if len(orphans) == 0 {
+			continue
+		}
+		sort.Strings(orphans)
+		orphanCount += int64(len(orphans))
+		fmt.Fprintf(writer, "volume %d: %d orphaned needles of %d\n", vid, len(orphans), len(volumeNeedles[vid]))
+		if *verbose {
+			for _, orphan := range orphans {
+				fmt.Fprintf(writer, "  orphaned needle %s\n", orphan)
+			}
+		}
+		if *purge {
+			if err = purgeOrphanedNeedles(commandEnv, volumeLocations[vid], orphans); err != nil {
+				return fmt.Errorf("purge volume %d: %v", vid, err)
+			}
+			fmt.Fprintf(writer, "volume %d: purged %d orphaned needles\n", vid, len(orphans))
+		}
+	}
+
+	fmt.Fprintf(writer, "checked %d volumes: %d orphaned needles, %d dangling chunks\n", len(volumeNeedles), orphanCount, danglingCount)
+
+	return nil
+}
+
+// collectVolumeLocations lists the volume servers and the collection of each volume, and the erasure coded volumes
+func collectVolumeLocations(ctx context.Context, commandEnv *CommandEnv) (volumeLocations map[uint32][]string, volumeCollections map[uint32]string, ecVolumes map[uint32]bool, err error) {
+
+	var resp *master_pb.VolumeListResponse
+	err = commandEnv.MasterClient.WithClient(ctx, func(client master_pb.SeaweedClient) error {
+		resp, err = client.VolumeList(ctx, &master_pb.VolumeListRequest{})
+		return err
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	volumeLocations = make(map[uint32][]string)
+	volumeCollections = make(map[uint32]string)
+	ecVolumes = make(map[uint32]bool)
+	eachDataNode(resp.TopologyInfo, func(dc string, rack RackId, dn *master_pb.DataNodeInfo) {
+		for _, v := range dn.VolumeInfos {
+			volumeLocations[v.Id] = append(volumeLocations[v.Id], dn.Id)
+			volumeCollections[v.Id] = v.Collection
+		}
+		for _, ecShardInfo := range dn.EcShardInfos {
+			ecVolumes[ecShardInfo.Id] = true
+		}
+	})
+	return
+}
+
+// readVolumeNeedles copies the index file of the volume, and collects the needles not deleted
+func readVolumeNeedles(ctx context.Context, commandEnv *CommandEnv, vid uint32, collection string, volumeServer string) (needles map[types.NeedleId]bool, err error) {
+
+	data, err := copyVolumeIndex(ctx, commandEnv, vid, collection, volumeServer)
+	if err != nil {
+		return nil, err
+	}
+
+	needles = make(map[types.NeedleId]bool)
+	for i := 0; i+types.NeedleMapEntrySize <= len(data); i += types.NeedleMapEntrySize {
+		key, offset, size := idx.IdxFileEntry(data[i : i+types.NeedleMapEntrySize])
+		if offset.IsZero() || size == types.TombstoneFileSize {
+			delete(needles, key)
+		} else {
+			needles[key] = true
+		}
+	}
+	return needles, nil
+}
+
+// copyVolumeIndex copies the whole index file of the volume
+func copyVolumeIndex(ctx context.Context, commandEnv *CommandEnv, vid uint32, collection string, volumeServer string) ([]byte, error) {
+
+	var buf bytes.Buffer
+	err := operation.WithVolumeServerClient(volumeServer, commandEnv.option.GrpcDialOption, func(volumeServerClient volume_server_pb.VolumeServerClient) error {
+		copyFileClient, err := volumeServerClient.CopyFile(ctx, &volume_server_pb.CopyFileRequest{
+			VolumeId:           vid,
+			Ext:                ".idx",
+			CompactionRevision: math.MaxUint32,
+			StopOffset:         math.MaxInt64,
+			Collection:         collection,
+		})
+		if err != nil {
+			return err
+		}
+		for {
+			resp, receiveErr := copyFileClient.Recv()
+			if receiveErr == io.EOF {
+				return nil
+			}
+			if receiveErr != nil {
+				return receiveErr
+			}
+			buf.Write(resp.FileContent)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// traverseFileChunks calls fn for each chunk of the files under the path
+func traverseFileChunks(ctx context.Context, commandEnv *CommandEnv, filerServer string, filerPort int64, path string, fn func(fullPath filer2.FullPath, fid *needle.FileId)) error {
+	return commandEnv.withFilerClient(ctx, filerServer, filerPort, func(client filer_pb.SeaweedFilerClient) error {
+		return doTraverse(ctx, nil, client, filer2.FullPath(path), func(parentPath filer2.FullPath, entry *filer_pb.Entry) error {
+			for _, chunk := range entry.Chunks {
+				fid, err := needle.ParseFileIdFromString(chunk.GetFileIdString())
+				if err != nil {
+					return fmt.Errorf("%s chunk %s: %v", parentPath.Child(entry.Name), chunk.GetFileIdString(), err)
+				}
+				fn(parentPath.Child(entry.Name), fid)
+			}
+			return nil
+		})
+	})
+}
+
+func isCollectionReferenced(referenced map[uint32]map[types.NeedleId]bool, volumeCollections map[uint32]string, collection string) bool {
+	for vid := range referenced {
+		if volumeCollections[vid] == collection {
+			return true
+		}
+	}
+	return false
+}
+
+// purgeOrphanedNeedles deletes the needles from all replicas, without the cookies
+func purgeOrphanedNeedles(commandEnv *CommandEnv, locations []string, fileIds []string) error {
+	for _, location := range locations {
+		err := operation.WithVolumeServerClient(location, commandEnv.option.GrpcDialOption, func(volumeServerClient volume_server_pb.VolumeServerClient) error {
+			resp, deleteErr := volumeServerClient.BatchDelete(context.Background(), &volume_server_pb.BatchDeleteRequest{
+				FileIds:         fileIds,
+				SkipCookieCheck: true,
+			})
+			if deleteErr != nil {
+				return deleteErr
+			}
+			for _, result := range resp.Results {
+				if result.Error != "" && result.Status != http.StatusNotFound {
+					return fmt.Errorf("delete %s on %s: %s", result.FileId, location, result.Error)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortedVolumeIds(volumeNeedles map[uint32]map[types.NeedleId]bool) (vids []uint32) {
+	for vid := range volumeNeedles {
+		vids = append(vids, vid)
+	}
+	sort.Slice(vids, func(i, j int) bool {
+		return vids[i] < vids[j]
+	})
+	return
+}
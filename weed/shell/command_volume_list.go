@@ -42,6 +42,7 @@ func (c *commandVolumeList) Do(args []string, commandEnv *CommandEnv, writer io.
 	}
 
 	writeTopologyInfo(writer, resp.TopologyInfo, resp.VolumeSizeLimitMb)
+	commandEnv.setResult(resp)
 	return nil
 }
 
@@ -82,7 +83,7 @@ func writeRackInfo(writer io.Writer, t *master_pb.RackInfo) statistics {
 	return s
 }
 func writeDataNodeInfo(writer io.Writer, t *master_pb.DataNodeInfo) statistics {
-	fmt.Fprintf(writer, "      DataNode %s volume:%d/%d active:%d free:%d\n", t.Id, t.VolumeCount, t.MaxVolumeCount, t.ActiveVolumeCount, t.FreeVolumeCount)
+	fmt.Fprintf(writer, "      DataNode %s volume:%d/%d active:%d free:%d%s\n", t.Id, t.VolumeCount, t.MaxVolumeCount, t.ActiveVolumeCount, t.FreeVolumeCount, daysToFullString(t))
 	var s statistics
 	sort.Slice(t.VolumeInfos, func(i, j int) bool {
 		return t.VolumeInfos[i].Id < t.VolumeInfos[j].Id
@@ -132,3 +133,10 @@ func (s statistics) String() string {
 	}
 	return fmt.Sprintf("total size:%d file_count:%d", s.Size, s.FileCount)
 }
+
+func daysToFullString(t *master_pb.DataNodeInfo) string {
+	if t.DaysToFull <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" daysToFull:%.1f", t.DaysToFull)
+}
@@ -2,12 +2,14 @@ package shell
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"io"
 	"log"
 	"time"
 
 	"github.com/chrislusf/seaweedfs/weed/operation"
+	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
 	"github.com/chrislusf/seaweedfs/weed/pb/volume_server_pb"
 	"github.com/chrislusf/seaweedfs/weed/storage/needle"
 	"google.golang.org/grpc"
@@ -27,6 +29,7 @@ func (c *commandVolumeMove) Name() string {
 func (c *commandVolumeMove) Help() string {
 	return `<experimental> move a live volume from one volume server to another volume server
 
+	volume.move -volumeId=<volume id> -from=<source volume server host:port> -to=<target volume server host:port> [-idleTimeout=5s]
 	volume.move <source volume server host:port> <target volume server host:port> <volume id>
 
 	This command move a live volume from one volume server to another volume server. Here are the steps:
@@ -34,58 +37,179 @@ func (c *commandVolumeMove) Help() string {
 	1. This command asks the target volume server to copy the source volume from source volume server, remember the last entry's timestamp.
 	2. This command asks the target volume server to mount the new volume
 		Now the master will mark this volume id as readonly.
-	3. This command asks the target volume server to tail the source volume for updates after the timestamp, for 1 minutes to drain the requests.
-	4. This command asks the source volume server to unmount the source volume
+	3. This command asks the target volume server to tail the source volume for updates after the timestamp, until idle for the idleTimeout.
+	4. This command asks the source volume server to mark the source volume readonly,
+		and the target volume server to tail the last updates, so no writes are lost.
+	5. This command waits until the master sees the volume on the target volume server, to commit the switch in the topology.
+	6. This command asks the source volume server to delete the source volume
 		Now the master will mark this volume id as writable.
-	5. This command asks the source volume server to delete the source volume
+
+	If any step before the switch fails, the copied volume is deleted from the target volume server,
+	and the source volume is marked writable again.
+
+	This is the building block of volume.balance and volumeServer.evacuate.
 
 `
 }
 
 func (c *commandVolumeMove) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
 
-	if len(args) != 3 {
-		fmt.Fprintf(writer, "received args: %+v\n", args)
-		return fmt.Errorf("need 3 args of <source volume server host:port> <target volume server host:port> <volume id>")
+	moveCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	volumeIdInt := moveCommand.Int("volumeId", 0, "the volume id")
+	sourceVolumeServer := moveCommand.String("from", "", "the source volume server <host>:<port>")
+	targetVolumeServer := moveCommand.String("to", "", "the target volume server <host>:<port>")
+	idleTimeout := moveCommand.Duration("idleTimeout", 5*time.Second, "wait time for the incoming writes to stop when tailing the source volume")
+	if err = moveCommand.Parse(args); err != nil {
+		return nil
 	}
-	sourceVolumeServer, targetVolumeServer, volumeIdString := args[0], args[1], args[2]
 
-	volumeId, err := needle.NewVolumeId(volumeIdString)
-	if err != nil {
-		return fmt.Errorf("wrong volume id format %s: %v", volumeId, err)
+	volumeId := needle.VolumeId(*volumeIdInt)
+	if moveCommand.NArg() == 3 {
+		// the positional form: <source> <target> <volume id>
+		*sourceVolumeServer, *targetVolumeServer = moveCommand.Arg(0), moveCommand.Arg(1)
+		if volumeId, err = needle.NewVolumeId(moveCommand.Arg(2)); err != nil {
+			return fmt.Errorf("wrong volume id format %s: %v", moveCommand.Arg(2), err)
+		}
+	}
+	if volumeId == 0 || *sourceVolumeServer == "" || *targetVolumeServer == "" {
+		fmt.Fprintf(writer, "received args: %+v\n", args)
+		return fmt.Errorf("need -volumeId, -from and -to")
 	}
 
-	if sourceVolumeServer == targetVolumeServer {
+	if *sourceVolumeServer == *targetVolumeServer {
 		return fmt.Errorf("source and target volume servers are the same!")
 	}
 
 	ctx := context.Background()
-	return LiveMoveVolume(ctx, commandEnv.option.GrpcDialOption, volumeId, sourceVolumeServer, targetVolumeServer, 5*time.Second)
+	return LiveMoveVolume(ctx, commandEnv, volumeId, *sourceVolumeServer, *targetVolumeServer, *idleTimeout)
 }
 
 // LiveMoveVolume moves one volume from one source volume server to one target volume server, with idleTimeout to drain the incoming requests.
-func LiveMoveVolume(ctx context.Context, grpcDialOption grpc.DialOption, volumeId needle.VolumeId, sourceVolumeServer, targetVolumeServer string, idleTimeout time.Duration) (err error) {
+// If the move fails before the switch, the copied target volume is deleted, and the source volume is marked writable again.
+func LiveMoveVolume(ctx context.Context, commandEnv *CommandEnv, volumeId needle.VolumeId, sourceVolumeServer, targetVolumeServer string, idleTimeout time.Duration) (err error) {
+
+	grpcDialOption := commandEnv.option.GrpcDialOption
 
 	log.Printf("copying volume %d from %s to %s", volumeId, sourceVolumeServer, targetVolumeServer)
 	lastAppendAtNs, err := copyVolume(ctx, grpcDialOption, volumeId, sourceVolumeServer, targetVolumeServer)
 	if err != nil {
+		// the failed copy removes its own files on the target
 		return fmt.Errorf("copy volume %d from %s to %s: %v", volumeId, sourceVolumeServer, targetVolumeServer, err)
 	}
 
+	isMarkedReadonly := false // the source volume is marked readonly by this move
+	isSwitched := false
+	defer func() {
+		if err == nil || isSwitched {
+			return
+		}
+		rollbackVolumeMove(ctx, grpcDialOption, volumeId, sourceVolumeServer, targetVolumeServer, isMarkedReadonly)
+	}()
+
 	log.Printf("tailing volume %d from %s to %s", volumeId, sourceVolumeServer, targetVolumeServer)
-	if err = tailVolume(ctx, grpcDialOption, volumeId, sourceVolumeServer, targetVolumeServer, lastAppendAtNs, idleTimeout); err != nil {
+	if lastAppendAtNs, err = tailVolume(ctx, grpcDialOption, volumeId, sourceVolumeServer, targetVolumeServer, lastAppendAtNs, idleTimeout); err != nil {
 		return fmt.Errorf("tail volume %d from %s to %s: %v", volumeId, sourceVolumeServer, targetVolumeServer, err)
 	}
 
+	log.Printf("marking volume %d readonly on %s", volumeId, sourceVolumeServer)
+	wasReadonly, err := markSourceVolumeReadonly(ctx, grpcDialOption, volumeId, sourceVolumeServer)
+	// marked readonly even if the call failed, since the server may have done it before the error
+	isMarkedReadonly = !wasReadonly
+	if err != nil {
+		return fmt.Errorf("mark volume %d readonly on %s: %v", volumeId, sourceVolumeServer, err)
+	}
+	if _, err = tailVolume(ctx, grpcDialOption, volumeId, sourceVolumeServer, targetVolumeServer, lastAppendAtNs, time.Second); err != nil {
+		return fmt.Errorf("tail the last updates of volume %d from %s to %s: %v", volumeId, sourceVolumeServer, targetVolumeServer, err)
+	}
+
+	log.Printf("waiting for the master to see volume %d on %s", volumeId, targetVolumeServer)
+	if err = waitForVolumeLocation(ctx, commandEnv, volumeId, targetVolumeServer, volumeMoveSwitchTimeout); err != nil {
+		return fmt.Errorf("switch volume %d to %s: %v", volumeId, targetVolumeServer, err)
+	}
+	// the master may assign writes to the target volume from now on, so it is kept
+	isSwitched = true
+
 	log.Printf("deleting volume %d from %s", volumeId, sourceVolumeServer)
 	if err = deleteVolume(ctx, grpcDialOption, volumeId, sourceVolumeServer); err != nil {
-		return fmt.Errorf("delete volume %d from %s: %v", volumeId, sourceVolumeServer, err)
+		return fmt.Errorf("moved volume %d to %s, but failed to delete the readonly volume from %s: %v", volumeId, targetVolumeServer, sourceVolumeServer, err)
 	}
 
 	log.Printf("moved volume %d from %s to %s", volumeId, sourceVolumeServer, targetVolumeServer)
 	return nil
 }
 
+// rollbackVolumeMove deletes the copied target volume, and marks the source volume writable again if this move marked it readonly
+func rollbackVolumeMove(ctx context.Context, grpcDialOption grpc.DialOption, volumeId needle.VolumeId, sourceVolumeServer, targetVolumeServer string, isMarkedReadonly bool) {
+	log.Printf("rolling back the move of volume %d from %s to %s", volumeId, sourceVolumeServer, targetVolumeServer)
+	if err := deleteVolume(ctx, grpcDialOption, volumeId, targetVolumeServer); err != nil {
+		log.Printf("delete the copied volume %d from %s: %v", volumeId, targetVolumeServer, err)
+	}
+	if !isMarkedReadonly {
+		return
+	}
+	if err := markVolumeWritable(ctx, grpcDialOption, volumeId, sourceVolumeServer); err != nil {
+		log.Printf("mark volume %d writable on %s: %v", volumeId, sourceVolumeServer, err)
+	}
+}
+
+func markSourceVolumeReadonly(ctx context.Context, grpcDialOption grpc.DialOption, volumeId needle.VolumeId, volumeServer string) (wasReadonly bool, err error) {
+	err = operation.WithVolumeServerClient(volumeServer, grpcDialOption, func(volumeServerClient volume_server_pb.VolumeServerClient) error {
+		resp, markErr := volumeServerClient.VolumeMarkReadonly(ctx, &volume_server_pb.VolumeMarkReadonlyRequest{
+			VolumeId: uint32(volumeId),
+		})
+		if markErr == nil {
+			wasReadonly = resp.WasReadonly
+		}
+		return markErr
+	})
+	return
+}
+
+func markVolumeWritable(ctx context.Context, grpcDialOption grpc.DialOption, volumeId needle.VolumeId, volumeServer string) error {
+	return operation.WithVolumeServerClient(volumeServer, grpcDialOption, func(volumeServerClient volume_server_pb.VolumeServerClient) error {
+		_, markErr := volumeServerClient.VolumeMarkWritable(ctx, &volume_server_pb.VolumeMarkWritableRequest{
+			VolumeId: uint32(volumeId),
+		})
+		return markErr
+	})
+}
+
+// the master learns the new volume location from the next heartbeat of the target volume server
+const volumeMoveSwitchTimeout = time.Minute
+
+func waitForVolumeLocation(ctx context.Context, commandEnv *CommandEnv, volumeId needle.VolumeId, volumeServer string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		var found bool
+		err := commandEnv.MasterClient.WithClient(ctx, func(client master_pb.SeaweedClient) error {
+			resp, lookupErr := client.LookupVolume(ctx, &master_pb.LookupVolumeRequest{
+				VolumeIds: []string{volumeId.String()},
+			})
+			if lookupErr != nil {
+				return lookupErr
+			}
+			for _, vidLocation := range resp.VolumeIdLocations {
+				for _, location := range vidLocation.Locations {
+					if location.Url == volumeServer {
+						found = true
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if found {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("the master does not see the volume on %s after %v", volumeServer, timeout)
+		}
+		time.Sleep(time.Second)
+	}
+}
+
 func copyVolume(ctx context.Context, grpcDialOption grpc.DialOption, volumeId needle.VolumeId, sourceVolumeServer, targetVolumeServer string) (lastAppendAtNs uint64, err error) {
 
 	err = operation.WithVolumeServerClient(targetVolumeServer, grpcDialOption, func(volumeServerClient volume_server_pb.VolumeServerClient) error {
@@ -102,18 +226,24 @@ func copyVolume(ctx context.Context, grpcDialOption grpc.DialOption, volumeId ne
 	return
 }
 
-func tailVolume(ctx context.Context, grpcDialOption grpc.DialOption, volumeId needle.VolumeId, sourceVolumeServer, targetVolumeServer string, lastAppendAtNs uint64, idleTimeout time.Duration) (err error) {
+// tailVolume returns the timestamp of the last entry tailed, to continue tailing from
+func tailVolume(ctx context.Context, grpcDialOption grpc.DialOption, volumeId needle.VolumeId, sourceVolumeServer, targetVolumeServer string, sinceNs uint64, idleTimeout time.Duration) (lastAppendAtNs uint64, err error) {
 
-	return operation.WithVolumeServerClient(targetVolumeServer, grpcDialOption, func(volumeServerClient volume_server_pb.VolumeServerClient) error {
-		_, replicateErr := volumeServerClient.VolumeTailReceiver(ctx, &volume_server_pb.VolumeTailReceiverRequest{
+	lastAppendAtNs = sinceNs
+	err = operation.WithVolumeServerClient(targetVolumeServer, grpcDialOption, func(volumeServerClient volume_server_pb.VolumeServerClient) error {
+		resp, replicateErr := volumeServerClient.VolumeTailReceiver(ctx, &volume_server_pb.VolumeTailReceiverRequest{
 			VolumeId:           uint32(volumeId),
-			SinceNs:            lastAppendAtNs,
+			SinceNs:            sinceNs,
 			IdleTimeoutSeconds: uint32(idleTimeout.Seconds()),
 			SourceVolumeServer: sourceVolumeServer,
 		})
+		if replicateErr == nil && resp.LastAppendAtNs > lastAppendAtNs {
+			lastAppendAtNs = resp.LastAppendAtNs
+		}
 		return replicateErr
 	})
 
+	return
 }
 
 func deleteVolume(ctx context.Context, grpcDialOption grpc.DialOption, volumeId needle.VolumeId, sourceVolumeServer string) (err error) {
@@ -0,0 +1,248 @@
+package shell
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/operation"
+	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
+	"github.com/chrislusf/seaweedfs/weed/pb/volume_server_pb"
+	"github.com/chrislusf/seaweedfs/weed/storage/erasure_coding"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+)
+
+func init() {
+	Commands = append(Commands, &commandVolumeServerEvacuate{})
+}
+
+type commandVolumeServerEvacuate struct {
+}
+
+func (c *commandVolumeServerEvacuate) Name() string {
+	return "volumeServer.evacuate"
+}
+
+func (c *commandVolumeServerEvacuate) Help() string {
+	return `move out all data on a volume server, so that it can be decommissioned
+
+	volumeServer.evacuate -node=<host>:<port> [-force]
+
+	This command moves all volumes and ec shards away from a volume server. Here are the steps:
+
+	1. ask the volume server to drain, i.e., mark all its volumes as readonly and stop taking new volumes.
+		The master will stop assigning writes and new volumes to it after the next heartbeat.
+	2. move each volume to another volume server with free slots, preferring the same rack and then the same data center.
+	3. move each ec shard to another volume server with free ec shard slots.
+	4. check with the volume server that no volumes or ec shards are left.
+		Then it is safe to stop the volume server and remove its data.
+
+	Without -force, only the plan is printed. The draining state is kept until the volume server restarts.
+
+`
+}
+
+func (c *commandVolumeServerEvacuate) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+
+	evacuateCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	volumeServer := evacuateCommand.String("node", "", "<host>:<port> of the volume server to evacuate")
+	applyChange := evacuateCommand.Bool("force", false, "drain the volume server and move out its data")
+	if err = evacuateCommand.Parse(args); err != nil {
+		return nil
+	}
+
+	if *volumeServer == "" {
+		return fmt.Errorf("need to specify the volume server by -node=<host>:<port>")
+	}
+
+	ctx := context.Background()
+
+	if *applyChange {
+		if _, err = drainVolumeServer(ctx, commandEnv, *volumeServer); err != nil {
+			return fmt.Errorf("drain volume server %s: %v", *volumeServer, err)
+		}
+		fmt.Fprintf(writer, "volume server %s is draining\n", *volumeServer)
+	}
+
+	// collect the topology
+	var resp *master_pb.VolumeListResponse
+	err = commandEnv.MasterClient.WithClient(ctx, func(client master_pb.SeaweedClient) error {
+		resp, err = client.VolumeList(ctx, &master_pb.VolumeListRequest{})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	var thisNode *EvacuateNode
+	var otherNodes []*EvacuateNode
+	eachDataNode(resp.TopologyInfo, func(dc string, rack RackId, dn *master_pb.DataNodeInfo) {
+		node := &EvacuateNode{
+			info:            dn,
+			dc:              dc,
+			rack:            rack,
+			freeVolumeSlots: int(dn.FreeVolumeCount),
+			volumes:         make(map[uint32]bool),
+		}
+		for _, v := range dn.VolumeInfos {
+			node.volumes[v.Id] = true
+		}
+		if dn.Id == *volumeServer {
+			thisNode = node
+		} else if !dn.IsDraining {
+			otherNodes = append(otherNodes, node)
+		}
+	})
+	if thisNode == nil {
+		return fmt.Errorf("volume server %s is not found", *volumeServer)
+	}
+
+	if err = evacuateVolumes(ctx, commandEnv, thisNode, otherNodes, *applyChange, writer); err != nil {
+		return err
+	}
+
+	if err = evacuateEcShards(ctx, commandEnv, thisNode, otherNodes, *applyChange, writer); err != nil {
+		return err
+	}
+
+	if !*applyChange {
+		return nil
+	}
+
+	// confirm with the volume server itself, since the master only learns about changes with heartbeats
+	left, err := drainVolumeServer(ctx, commandEnv, *volumeServer)
+	if err != nil {
+		return fmt.Errorf("check volume server %s: %v", *volumeServer, err)
+	}
+	if left.VolumeCount > 0 || left.EcShardCount > 0 {
+		return fmt.Errorf("volume server %s still has %d volumes and %d ec shards", *volumeServer, left.VolumeCount, left.EcShardCount)
+	}
+	fmt.Fprintf(writer, "volume server %s has no volumes or ec shards left, and is safe to decommission\n", *volumeServer)
+
+	return nil
+}
+
+type EvacuateNode struct {
+	info            *master_pb.DataNodeInfo
+	dc              string
+	rack            RackId
+	freeVolumeSlots int
+	volumes         map[uint32]bool
+}
+
+func evacuateVolumes(ctx context.Context, commandEnv *CommandEnv, thisNode *EvacuateNode, otherNodes []*EvacuateNode, applyChange bool, writer io.Writer) error {
+
+	volumes := thisNode.info.VolumeInfos
+	sort.Slice(volumes, func(i, j int) bool {
+		return volumes[i].Id < volumes[j].Id
+	})
+
+	for i, v := range volumes {
+		targetNode := pickVolumeEvacuationTarget(thisNode, otherNodes, v.Id)
+		if targetNode == nil {
+			return fmt.Errorf("no volume server with free slots found for volume %d", v.Id)
+		}
+		fmt.Fprintf(writer, "[%d/%d] moving volume %d %s => %s\n", i+1, len(volumes), v.Id, thisNode.info.Id, targetNode.info.Id)
+		if applyChange {
+			if err := LiveMoveVolume(ctx, commandEnv, needle.VolumeId(v.Id), thisNode.info.Id, targetNode.info.Id, 5*time.Second); err != nil {
+				return fmt.Errorf("move volume %d %s => %s: %v", v.Id, thisNode.info.Id, targetNode.info.Id, err)
+			}
+		}
+		targetNode.freeVolumeSlots--
+		targetNode.volumes[v.Id] = true
+	}
+
+	return nil
+}
+
+// pickVolumeEvacuationTarget picks the volume server with the most free slots, preferring the same rack
+// and then the same data center to keep the replica placement.
+func pickVolumeEvacuationTarget(thisNode *EvacuateNode, otherNodes []*EvacuateNode, vid uint32) (targetNode *EvacuateNode) {
+	rank := func(n *EvacuateNode) int {
+		if n.dc != thisNode.dc {
+			return 0
+		}
+		if n.rack != thisNode.rack {
+			return 1
+		}
+		return 2
+	}
+	for _, n := range otherNodes {
+		if n.freeVolumeSlots <= 0 || n.volumes[vid] {
+			continue
+		}
+		if targetNode == nil || rank(n) > rank(targetNode) ||
+			rank(n) == rank(targetNode) && n.freeVolumeSlots > targetNode.freeVolumeSlots {
+			targetNode = n
+		}
+	}
+	return
+}
+
+func evacuateEcShards(ctx context.Context, commandEnv *CommandEnv, thisNode *EvacuateNode, otherNodes []*EvacuateNode, applyChange bool, writer io.Writer) error {
+
+	existingLocation := &EcNode{
+		info: thisNode.info,
+		dc:   thisNode.dc,
+		rack: thisNode.rack,
+	}
+
+	var ecNodes []*EcNode
+	for _, n := range otherNodes {
+		if freeEcSlots := countFreeShardSlots(n.info); freeEcSlots > 0 {
+			ecNodes = append(ecNodes, &EcNode{
+				info:       n.info,
+				dc:         n.dc,
+				rack:       n.rack,
+				freeEcSlot: freeEcSlots,
+			})
+		}
+	}
+
+	totalShards := countShards(thisNode.info.EcShardInfos)
+	movedShards := 0
+
+	for _, ecShardInfo := range thisNode.info.EcShardInfos {
+		vid := needle.VolumeId(ecShardInfo.Id)
+		for _, shardId := range erasure_coding.ShardBits(ecShardInfo.EcIndexBits).ShardIds() {
+
+			// prefer the volume server with the fewest shards of this volume, then with the most free slots
+			sort.Slice(ecNodes, func(i, j int) bool {
+				ci, cj := findEcVolumeShards(ecNodes[i], vid).ShardIdCount(), findEcVolumeShards(ecNodes[j], vid).ShardIdCount()
+				if ci != cj {
+					return ci < cj
+				}
+				return ecNodes[i].freeEcSlot > ecNodes[j].freeEcSlot
+			})
+			var destination *EcNode
+			for _, n := range ecNodes {
+				if n.freeEcSlot > 0 {
+					destination = n
+					break
+				}
+			}
+			if destination == nil {
+				return fmt.Errorf("no volume server with free ec shard slots found for ec shard %d.%d", vid, shardId)
+			}
+
+			movedShards++
+			fmt.Fprintf(writer, "[%d/%d] moving ec shard %d.%d %s => %s\n", movedShards, totalShards, vid, shardId, thisNode.info.Id, destination.info.Id)
+			if err := moveMountedShardToEcNode(ctx, commandEnv, writer, existingLocation, ecShardInfo.Collection, vid, shardId, destination, applyChange); err != nil {
+				return fmt.Errorf("move ec shard %d.%d %s => %s: %v", vid, shardId, thisNode.info.Id, destination.info.Id, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func drainVolumeServer(ctx context.Context, commandEnv *CommandEnv, volumeServer string) (resp *volume_server_pb.VolumeServerDrainResponse, err error) {
+	err = operation.WithVolumeServerClient(volumeServer, commandEnv.option.GrpcDialOption, func(volumeServerClient volume_server_pb.VolumeServerClient) error {
+		resp, err = volumeServerClient.VolumeServerDrain(ctx, &volume_server_pb.VolumeServerDrainRequest{})
+		return err
+	})
+	return
+}
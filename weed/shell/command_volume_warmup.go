@@ -0,0 +1,86 @@
+package shell
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/chrislusf/seaweedfs/weed/operation"
+	"github.com/chrislusf/seaweedfs/weed/pb/volume_server_pb"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+)
+
+func init() {
+	Commands = append(Commands, &commandVolumeWarmup{})
+}
+
+type commandVolumeWarmup struct {
+}
+
+func (c *commandVolumeWarmup) Name() string {
+	return "volume.warmup"
+}
+
+func (c *commandVolumeWarmup) Help() string {
+	return `read the volume files into the page cache of the volume servers
+
+	volume.warmup [-throttleMBps=0] <volume id> [<volume id> ...]
+
+	This command asks each volume server having a replica of the volume
+	to read the .idx and .dat files sequentially, ahead of an anticipated load.
+	The reading speed is limited by -throttleMBps, default to no limit.
+
+`
+}
+
+func (c *commandVolumeWarmup) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+
+	warmupCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	throttleMBps := warmupCommand.Int64("throttleMBps", 0, "limit the reading speed in mega bytes per second")
+	if err = warmupCommand.Parse(args); err != nil {
+		return nil
+	}
+	if warmupCommand.NArg() == 0 {
+		return fmt.Errorf("need at least one <volume id>")
+	}
+
+	ctx := context.Background()
+
+	for _, volumeIdString := range warmupCommand.Args() {
+		volumeId, err := needle.NewVolumeId(volumeIdString)
+		if err != nil {
+			return fmt.Errorf("wrong volume id format %s: %v", volumeIdString, err)
+		}
+
+		locations := commandEnv.MasterClient.GetLocations(uint32(volumeId))
+		if len(locations) == 0 {
+			return fmt.Errorf("volume %d not found", volumeId)
+		}
+
+		for _, location := range locations {
+			warmed, err := warmupVolume(ctx, commandEnv, volumeId, location.Url, *throttleMBps*1024*1024)
+			if err != nil {
+				return fmt.Errorf("warmup volume %d on %s: %v", volumeId, location.Url, err)
+			}
+			fmt.Fprintf(writer, "volume %d on %s: warmed up %d bytes\n", volumeId, location.Url, warmed)
+		}
+	}
+
+	return nil
+}
+
+func warmupVolume(ctx context.Context, commandEnv *CommandEnv, volumeId needle.VolumeId, sourceVolumeServer string, bytesPerSecond int64) (warmed uint64, err error) {
+	err = operation.WithVolumeServerClient(sourceVolumeServer, commandEnv.option.GrpcDialOption, func(volumeServerClient volume_server_pb.VolumeServerClient) error {
+		resp, warmupErr := volumeServerClient.VolumeWarmup(ctx, &volume_server_pb.VolumeWarmupRequest{
+			VolumeId:       uint32(volumeId),
+			BytesPerSecond: bytesPerSecond,
+		})
+		if warmupErr != nil {
+			return warmupErr
+		}
+		warmed = resp.WarmedBytes
+		return nil
+	})
+	return
+}
@@ -22,12 +22,15 @@ type ShellOptions struct {
 	FilerHost string
 	FilerPort int64
 	Directory string
+	// OutputFormat is "text" by default, or "json" for the automation
+	OutputFormat string
 }
 
 type CommandEnv struct {
 	env          map[string]string
 	MasterClient *wdclient.MasterClient
 	option       ShellOptions
+	result       interface{}
 }
 
 type command interface {
@@ -46,63 +46,110 @@ func RunShell(options ShellOptions) {
 			return
 		}
 
-		cmds := reg.FindAllString(cmd, -1)
-		if len(cmds) == 0 {
-			continue
-		} else {
-			line.AppendHistory(cmd)
+		if processEachCmd(reg, cmd, commandEnv, os.Stdout) {
+			return
+		}
+	}
+}
 
-			args := make([]string, len(cmds[1:]))
+// RunShellCommands runs the semicolon-separated commands without the interactive prompt,
+// and returns the first error, so the shell can be used from cron and the other automation
+func RunShellCommands(options ShellOptions, commands string) (err error) {
 
-			for i := range args {
-				args[i] = strings.Trim(string(cmds[1+i]), "\"'")
-			}
+	reg, _ := regexp.Compile(`'.*?'|".*?"|\S+`)
 
-			cmd := strings.ToLower(cmds[0])
-			if cmd == "help" || cmd == "?" {
-				printHelp(cmds)
-			} else if cmd == "exit" || cmd == "quit" {
-				return
-			} else {
-				foundCommand := false
-				for _, c := range Commands {
-					if c.Name() == cmd {
-						if err := c.Do(args, commandEnv, os.Stdout); err != nil {
-							fmt.Fprintf(os.Stderr, "error: %v\n", err)
-						}
-						foundCommand = true
-					}
-				}
-				if !foundCommand {
-					fmt.Fprintf(os.Stderr, "unknown command: %v\n", cmd)
-				}
-			}
+	commandEnv := NewCommandEnv(options)
 
+	go commandEnv.MasterClient.KeepConnectedToMaster()
+	commandEnv.MasterClient.WaitUntilConnected()
+
+	return runCommands(reg, commands, commandEnv, os.Stdout)
+}
+
+// runCommands runs the semicolon-separated commands until "exit" or "quit", and returns the first error
+func runCommands(reg *regexp.Regexp, commands string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+	for _, cmd := range strings.Split(commands, ";") {
+		if isExitCommand(reg, cmd) {
+			break
+		}
+		if cmdErr := runEachCmd(reg, cmd, commandEnv, writer); cmdErr != nil && err == nil {
+			err = cmdErr
+		}
+	}
+	return err
+}
+
+func isExitCommand(reg *regexp.Regexp, input string) bool {
+	cmds := reg.FindAllString(input, -1)
+	if len(cmds) == 0 {
+		return false
+	}
+	cmd := strings.ToLower(cmds[0])
+	return cmd == "exit" || cmd == "quit"
+}
+
+// processEachCmd runs one line of the interactive prompt, and returns true to exit the shell
+func processEachCmd(reg *regexp.Regexp, input string, commandEnv *CommandEnv, writer io.Writer) bool {
+	cmds := reg.FindAllString(input, -1)
+	if len(cmds) == 0 {
+		return false
+	}
+	line.AppendHistory(input)
+
+	if isExitCommand(reg, input) {
+		return true
+	}
+	if err := runEachCmd(reg, input, commandEnv, writer); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+	}
+	return false
+}
+
+func runEachCmd(reg *regexp.Regexp, input string, commandEnv *CommandEnv, writer io.Writer) error {
+	cmds := reg.FindAllString(input, -1)
+	if len(cmds) == 0 {
+		return nil
+	}
+
+	args := make([]string, len(cmds[1:]))
+	for i := range args {
+		args[i] = strings.Trim(string(cmds[1+i]), "\"'")
+	}
+
+	cmd := strings.ToLower(cmds[0])
+	if cmd == "help" || cmd == "?" {
+		printHelp(cmds, writer)
+		return nil
+	}
+	for _, c := range Commands {
+		if c.Name() == cmd {
+			return commandEnv.runCommand(c, args, writer)
 		}
 	}
+	return fmt.Errorf("unknown command: %v", cmd)
 }
 
-func printGenericHelp() {
+func printGenericHelp(writer io.Writer) {
 	msg :=
 		`Type:	"help <command>" for help on <command>
 `
-	fmt.Print(msg)
+	fmt.Fprint(writer, msg)
 
 	sort.Slice(Commands, func(i, j int) bool {
 		return strings.Compare(Commands[i].Name(), Commands[j].Name()) < 0
 	})
 	for _, c := range Commands {
 		helpTexts := strings.SplitN(c.Help(), "\n", 2)
-		fmt.Printf("  %-30s\t# %s \n", c.Name(), helpTexts[0])
+		fmt.Fprintf(writer, "  %-30s\t# %s \n", c.Name(), helpTexts[0])
 	}
 }
 
-func printHelp(cmds []string) {
+func printHelp(cmds []string, writer io.Writer) {
 	args := cmds[1:]
 	if len(args) == 0 {
-		printGenericHelp()
+		printGenericHelp(writer)
 	} else if len(args) > 1 {
-		fmt.Println()
+		fmt.Fprintln(writer)
 	} else {
 		cmd := strings.ToLower(args[0])
 
@@ -112,7 +159,7 @@ func printHelp(cmds []string) {
 
 		for _, c := range Commands {
 			if c.Name() == cmd {
-				fmt.Printf("  %s\t# %s\n", c.Name(), c.Help())
+				fmt.Fprintf(writer, "  %s\t# %s\n", c.Name(), c.Help())
 			}
 		}
 	}
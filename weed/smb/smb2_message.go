@@ -0,0 +1,183 @@
+package smb
+
+import (
+	"encoding/binary"
+	"time"
+	"unicode/utf16"
+)
+
+// The SMB2 wire format, as described in [MS-SMB2]. Only the parts used by this server are defined.
+
+const (
+	smb2HeaderSize = 64
+
+	smb2ProtocolId = "\xfeSMB"
+	smb1ProtocolId = "\xffSMB"
+)
+
+// commands
+const (
+	smb2Negotiate      uint16 = 0x00
+	smb2SessionSetup   uint16 = 0x01
+	smb2Logoff         uint16 = 0x02
+	smb2TreeConnect    uint16 = 0x03
+	smb2TreeDisconnect uint16 = 0x04
+	smb2Create         uint16 = 0x05
+	smb2Close          uint16 = 0x06
+	smb2Flush          uint16 = 0x07
+	smb2Read           uint16 = 0x08
+	smb2Write          uint16 = 0x09
+	smb2Lock           uint16 = 0x0A
+	smb2Ioctl          uint16 = 0x0B
+	smb2Cancel         uint16 = 0x0C
+	smb2Echo           uint16 = 0x0D
+	smb2QueryDirectory uint16 = 0x0E
+	smb2ChangeNotify   uint16 = 0x0F
+	smb2QueryInfo      uint16 = 0x10
+	smb2SetInfo        uint16 = 0x11
+)
+
+// header flags
+const (
+	smb2FlagsServerToRedir     uint32 = 0x00000001
+	smb2FlagsAsyncCommand      uint32 = 0x00000002
+	smb2FlagsRelatedOperations uint32 = 0x00000004
+	smb2FlagsSigned            uint32 = 0x00000008
+)
+
+// dialects
+const (
+	smb2Dialect202      uint16 = 0x0202
+	smb2Dialect210      uint16 = 0x0210
+	smb2DialectWildcard uint16 = 0x02FF
+)
+
+// security modes
+const (
+	smb2NegotiateSigningEnabled  uint16 = 0x0001
+	smb2NegotiateSigningRequired uint16 = 0x0002
+)
+
+// NT status codes
+const (
+	statusSuccess                uint32 = 0x00000000
+	statusNoMoreFiles            uint32 = 0x80000006
+	statusBufferOverflow         uint32 = 0x80000005
+	statusInvalidInfoClass       uint32 = 0xC0000003
+	statusInfoLengthMismatch     uint32 = 0xC0000004
+	statusInvalidParameter       uint32 = 0xC000000D
+	statusNoSuchFile             uint32 = 0xC000000F
+	statusInvalidDeviceRequest   uint32 = 0xC0000010
+	statusEndOfFile              uint32 = 0xC0000011
+	statusMoreProcessingRequired uint32 = 0xC0000016
+	statusAccessDenied           uint32 = 0xC0000022
+	statusBufferTooSmall         uint32 = 0xC0000023
+	statusObjectNameInvalid      uint32 = 0xC0000033
+	statusObjectNameNotFound     uint32 = 0xC0000034
+	statusObjectNameCollision    uint32 = 0xC0000035
+	statusObjectPathNotFound     uint32 = 0xC000003A
+	statusLogonFailure           uint32 = 0xC000006D
+	statusNotSupported           uint32 = 0xC00000BB
+	statusFileIsADirectory       uint32 = 0xC00000BA
+	statusNetworkNameDeleted     uint32 = 0xC00000C9
+	statusBadNetworkName         uint32 = 0xC00000CC
+	statusUnexpectedIoError      uint32 = 0xC00000E9
+	statusDirectoryNotEmpty      uint32 = 0xC0000101
+	statusNotADirectory          uint32 = 0xC0000103
+	statusFileClosed             uint32 = 0xC0000128
+	statusUserSessionDeleted     uint32 = 0xC0000203
+)
+
+type smb2Header struct {
+	CreditCharge uint16
+	Status       uint32
+	Command      uint16
+	Credits      uint16
+	Flags        uint32
+	NextCommand  uint32
+	MessageId    uint64
+	AsyncId      uint64
+	TreeId       uint32
+	SessionId    uint64
+	Signature    [16]byte
+}
+
+func decodeSmb2Header(b []byte) (h smb2Header, ok bool) {
+	if len(b) < smb2HeaderSize || string(b[0:4]) != smb2ProtocolId {
+		return h, false
+	}
+	h.CreditCharge = le.Uint16(b[6:])
+	h.Status = le.Uint32(b[8:])
+	h.Command = le.Uint16(b[12:])
+	h.Credits = le.Uint16(b[14:])
+	h.Flags = le.Uint32(b[16:])
+	h.NextCommand = le.Uint32(b[20:])
+	h.MessageId = le.Uint64(b[24:])
+	if h.Flags&smb2FlagsAsyncCommand != 0 {
+		h.AsyncId = le.Uint64(b[32:])
+	} else {
+		h.TreeId = le.Uint32(b[36:])
+	}
+	h.SessionId = le.Uint64(b[40:])
+	copy(h.Signature[:], b[48:64])
+	return h, true
+}
+
+func (h *smb2Header) encode(b []byte) {
+	copy(b[0:4], smb2ProtocolId)
+	le.PutUint16(b[4:], smb2HeaderSize)
+	le.PutUint16(b[6:], h.CreditCharge)
+	le.PutUint32(b[8:], h.Status)
+	le.PutUint16(b[12:], h.Command)
+	le.PutUint16(b[14:], h.Credits)
+	le.PutUint32(b[16:], h.Flags)
+	le.PutUint32(b[20:], h.NextCommand)
+	le.PutUint64(b[24:], h.MessageId)
+	if h.Flags&smb2FlagsAsyncCommand != 0 {
+		le.PutUint64(b[32:], h.AsyncId)
+	} else {
+		le.PutUint32(b[32:], 0)
+		le.PutUint32(b[36:], h.TreeId)
+	}
+	le.PutUint64(b[40:], h.SessionId)
+	copy(b[48:64], h.Signature[:])
+}
+
+var le = binary.LittleEndian
+
+// fileTime converts to the number of 100-nanosecond intervals since January 1, 1601
+func fileTime(t time.Time) uint64 {
+	if t.IsZero() {
+		return 0
+	}
+	return uint64(t.UnixNano()/100) + 116444736000000000
+}
+
+func encodeUtf16(s string) []byte {
+	codes := utf16.Encode([]rune(s))
+	b := make([]byte, len(codes)*2)
+	for i, c := range codes {
+		le.PutUint16(b[i*2:], c)
+	}
+	return b
+}
+
+func decodeUtf16(b []byte) string {
+	codes := make([]uint16, len(b)/2)
+	for i := range codes {
+		codes[i] = le.Uint16(b[i*2:])
+	}
+	return string(utf16.Decode(codes))
+}
+
+// slice returns b[offset:offset+length], or nil if out of range
+func slice(b []byte, offset, length int) []byte {
+	if offset < 0 || length < 0 || offset+length > len(b) {
+		return nil
+	}
+	return b[offset : offset+length]
+}
+
+func align8(n int) int {
+	return (n + 7) &^ 7
+}
@@ -0,0 +1,318 @@
+package smb
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/rc4"
+	"errors"
+	"strings"
+	"time"
+)
+
+// NTLMv2 authentication, as described in [MS-NLMP], wrapped in SPNEGO, as described in RFC 4178.
+// Only the server side needed by the SMB2 session setup is implemented.
+
+const ntlmSignature = "NTLMSSP\x00"
+
+const (
+	ntlmNegotiateMessage    = 1
+	ntlmChallengeMessage    = 2
+	ntlmAuthenticateMessage = 3
+)
+
+// negotiate flags
+const (
+	ntlmNegotiateUnicode                 uint32 = 0x00000001
+	ntlmRequestTarget                    uint32 = 0x00000004
+	ntlmNegotiateSign                    uint32 = 0x00000010
+	ntlmNegotiateSeal                    uint32 = 0x00000020
+	ntlmNegotiateNtlm                    uint32 = 0x00000200
+	ntlmNegotiateAlwaysSign              uint32 = 0x00008000
+	ntlmTargetTypeServer                 uint32 = 0x00020000
+	ntlmNegotiateExtendedSessionSecurity uint32 = 0x00080000
+	ntlmNegotiateTargetInfo              uint32 = 0x00800000
+	ntlmNegotiateVersion                 uint32 = 0x02000000
+	ntlmNegotiate128                     uint32 = 0x20000000
+	ntlmNegotiateKeyExch                 uint32 = 0x40000000
+	ntlmNegotiate56                      uint32 = 0x80000000
+)
+
+// AV_PAIR ids in the target info
+const (
+	ntlmAvEOL             = 0
+	ntlmAvNbComputerName  = 1
+	ntlmAvNbDomainName    = 2
+	ntlmAvDnsComputerName = 3
+	ntlmAvDnsDomainName   = 4
+	ntlmAvTimestamp       = 7
+)
+
+var (
+	errNtlmMessage   = errors.New("malformed ntlm message")
+	errLogonFailure  = errors.New("unknown user or wrong password")
+	errAnonymousUser = errors.New("anonymous logon is not allowed")
+)
+
+// ntlmServer keeps the state of one authentication exchange
+type ntlmServer struct {
+	serverName      string
+	domain          string
+	serverChallenge [8]byte
+	negotiateFlags  uint32
+}
+
+func newNtlmServer(serverName, domain string) *ntlmServer {
+	s := &ntlmServer{
+		serverName: serverName,
+		domain:     domain,
+	}
+	rand.Read(s.serverChallenge[:])
+	return s
+}
+
+// challenge answers the NEGOTIATE_MESSAGE with a CHALLENGE_MESSAGE
+func (s *ntlmServer) challenge(negotiateMessage []byte) ([]byte, error) {
+	if len(negotiateMessage) < 16 || string(negotiateMessage[0:8]) != ntlmSignature || le.Uint32(negotiateMessage[8:]) != ntlmNegotiateMessage {
+		return nil, errNtlmMessage
+	}
+	clientFlags := le.Uint32(negotiateMessage[12:])
+
+	s.negotiateFlags = clientFlags&(ntlmNegotiateUnicode|ntlmNegotiateSign|ntlmNegotiateSeal|ntlmNegotiateAlwaysSign|
+		ntlmNegotiateExtendedSessionSecurity|ntlmNegotiateVersion|ntlmNegotiate128|ntlmNegotiateKeyExch|ntlmNegotiate56) |
+		ntlmRequestTarget | ntlmNegotiateNtlm | ntlmTargetTypeServer | ntlmNegotiateTargetInfo
+
+	targetName := encodeUtf16(s.serverName)
+
+	var targetInfo []byte
+	addAvPair := func(id uint16, value []byte) {
+		var pair [4]byte
+		le.PutUint16(pair[0:], id)
+		le.PutUint16(pair[2:], uint16(len(value)))
+		targetInfo = append(targetInfo, pair[:]...)
+		targetInfo = append(targetInfo, value...)
+	}
+	addAvPair(ntlmAvNbDomainName, encodeUtf16(s.domain))
+	addAvPair(ntlmAvNbComputerName, encodeUtf16(s.serverName))
+	addAvPair(ntlmAvDnsDomainName, encodeUtf16(strings.ToLower(s.domain)))
+	addAvPair(ntlmAvDnsComputerName, encodeUtf16(strings.ToLower(s.serverName)))
+	var timestamp [8]byte
+	le.PutUint64(timestamp[:], fileTime(time.Now()))
+	addAvPair(ntlmAvTimestamp, timestamp[:])
+	addAvPair(ntlmAvEOL, nil)
+
+	const headerSize = 56
+	m := make([]byte, headerSize, headerSize+len(targetName)+len(targetInfo))
+	copy(m[0:8], ntlmSignature)
+	le.PutUint32(m[8:], ntlmChallengeMessage)
+	putNtlmField(m[12:], len(targetName), headerSize)
+	le.PutUint32(m[20:], s.negotiateFlags)
+	copy(m[24:32], s.serverChallenge[:])
+	putNtlmField(m[40:], len(targetInfo), headerSize+len(targetName))
+	if s.negotiateFlags&ntlmNegotiateVersion != 0 {
+		// Windows 7, build 7601, NTLM revision 15
+		copy(m[48:56], []byte{6, 1, 0xb1, 0x1d, 0, 0, 0, 15})
+	}
+	m = append(m, targetName...)
+	m = append(m, targetInfo...)
+
+	return m, nil
+}
+
+// authenticate verifies the AUTHENTICATE_MESSAGE, and returns the user name and the exported session key
+func (s *ntlmServer) authenticate(authenticateMessage []byte, lookupPassword func(user string) (password string, found bool)) (user string, sessionKey []byte, err error) {
+	m := authenticateMessage
+	if len(m) < 64 || string(m[0:8]) != ntlmSignature || le.Uint32(m[8:]) != ntlmAuthenticateMessage {
+		return "", nil, errNtlmMessage
+	}
+	ntResponse := getNtlmField(m, 20)
+	domain := decodeUtf16(getNtlmField(m, 28))
+	user = decodeUtf16(getNtlmField(m, 36))
+	encryptedRandomSessionKey := getNtlmField(m, 52)
+	flags := le.Uint32(m[60:])
+
+	if user == "" && len(ntResponse) == 0 {
+		return "", nil, errAnonymousUser
+	}
+	// NTLMv1 responses are exactly 24 bytes, and are not accepted
+	if len(ntResponse) <= 24 {
+		return user, nil, errLogonFailure
+	}
+
+	password, found := lookupPassword(user)
+	if !found {
+		return user, nil, errLogonFailure
+	}
+
+	responseKeyNt := ntowfv2(password, user, domain)
+	ntProofStr, sessionBaseKey := ntlmv2Response(responseKeyNt, s.serverChallenge[:], ntResponse[16:])
+	if !hmac.Equal(ntProofStr, ntResponse[:16]) {
+		return user, nil, errLogonFailure
+	}
+
+	sessionKey = sessionBaseKey
+	if flags&ntlmNegotiateKeyExch != 0 && len(encryptedRandomSessionKey) == 16 {
+		cipher, _ := rc4.NewCipher(sessionBaseKey)
+		sessionKey = make([]byte, 16)
+		cipher.XORKeyStream(sessionKey, encryptedRandomSessionKey)
+	}
+
+	return user, sessionKey, nil
+}
+
+// ntowfv2 is the NTLMv2 response key derived from the password
+func ntowfv2(password, user, domain string) []byte {
+	ntHash := md4Sum(encodeUtf16(password))
+	mac := hmac.New(md5.New, ntHash[:])
+	mac.Write(encodeUtf16(strings.ToUpper(user) + domain))
+	return mac.Sum(nil)
+}
+
+func ntlmv2Response(responseKeyNt, serverChallenge, clientBlob []byte) (ntProofStr, sessionBaseKey []byte) {
+	mac := hmac.New(md5.New, responseKeyNt)
+	mac.Write(serverChallenge)
+	mac.Write(clientBlob)
+	ntProofStr = mac.Sum(nil)
+
+	mac = hmac.New(md5.New, responseKeyNt)
+	mac.Write(ntProofStr)
+	sessionBaseKey = mac.Sum(nil)
+	return
+}
+
+func putNtlmField(b []byte, length, offset int) {
+	le.PutUint16(b[0:], uint16(length))
+	le.PutUint16(b[2:], uint16(length))
+	le.PutUint32(b[4:], uint32(offset))
+}
+
+func getNtlmField(m []byte, fieldOffset int) []byte {
+	length := int(le.Uint16(m[fieldOffset:]))
+	offset := int(le.Uint32(m[fieldOffset+4:]))
+	return slice(m, offset, length)
+}
+
+// SPNEGO tokens are DER encoded. Only the few forms exchanged with SMB clients are handled here.
+
+var (
+	oidSpnego  = []byte{0x2b, 0x06, 0x01, 0x05, 0x05, 0x02}
+	oidNtlmssp = []byte{0x2b, 0x06, 0x01, 0x04, 0x01, 0x82, 0x37, 0x02, 0x02, 0x0a}
+)
+
+const (
+	spnegoAcceptCompleted  = 0
+	spnegoAcceptIncomplete = 1
+)
+
+func derEncode(tag byte, content ...[]byte) []byte {
+	var body []byte
+	for _, c := range content {
+		body = append(body, c...)
+	}
+	var length []byte
+	switch n := len(body); {
+	case n < 0x80:
+		length = []byte{byte(n)}
+	case n < 0x100:
+		length = []byte{0x81, byte(n)}
+	default:
+		length = []byte{0x82, byte(n >> 8), byte(n)}
+	}
+	out := append([]byte{tag}, length...)
+	return append(out, body...)
+}
+
+// derDecode reads one element, and returns its tag, content and the remaining bytes
+func derDecode(b []byte) (tag byte, content, rest []byte, ok bool) {
+	if len(b) < 2 {
+		return 0, nil, nil, false
+	}
+	tag, length, b := b[0], int(b[1]), b[2:]
+	if length&0x80 != 0 {
+		n := length & 0x7f
+		if n == 0 || n > 3 || len(b) < n {
+			return 0, nil, nil, false
+		}
+		length = 0
+		for _, x := range b[:n] {
+			length = length<<8 | int(x)
+		}
+		b = b[n:]
+	}
+	if length > len(b) {
+		return 0, nil, nil, false
+	}
+	return tag, b[:length], b[length:], true
+}
+
+// spnegoInitToken is sent in the NEGOTIATE response to advertise NTLMSSP as the only mechanism
+func spnegoInitToken() []byte {
+	return derEncode(0x60,
+		derEncode(0x06, oidSpnego),
+		derEncode(0xa0,
+			derEncode(0x30,
+				derEncode(0xa0,
+					derEncode(0x30,
+						derEncode(0x06, oidNtlmssp))))))
+}
+
+// spnegoResponseToken wraps the NTLM message into a NegTokenResp
+func spnegoResponseToken(negState byte, responseToken []byte) []byte {
+	fields := [][]byte{derEncode(0xa0, derEncode(0x0a, []byte{negState}))}
+	if responseToken != nil {
+		fields = append(fields,
+			derEncode(0xa1, derEncode(0x06, oidNtlmssp)),
+			derEncode(0xa2, derEncode(0x04, responseToken)))
+	}
+	return derEncode(0xa1, derEncode(0x30, fields...))
+}
+
+// unwrapSecurityToken extracts the NTLM message from a NegTokenInit or a NegTokenResp.
+// A raw NTLM message is returned as is.
+func unwrapSecurityToken(token []byte) (ntlmMessage []byte, isSpnego bool, err error) {
+	if bytes.HasPrefix(token, []byte(ntlmSignature)) {
+		return token, false, nil
+	}
+
+	tag, content, _, ok := derDecode(token)
+	if !ok {
+		return nil, true, errNtlmMessage
+	}
+	var mechTokenTag byte
+	switch tag {
+	case 0x60: // InitialContextToken: OID, then [0] NegTokenInit
+		_, _, content, ok = derDecode(content)
+		if !ok {
+			return nil, true, errNtlmMessage
+		}
+		if tag, content, _, ok = derDecode(content); !ok || tag != 0xa0 {
+			return nil, true, errNtlmMessage
+		}
+		mechTokenTag = 0xa2
+	case 0xa1: // NegTokenResp
+		mechTokenTag = 0xa2
+	default:
+		return nil, true, errNtlmMessage
+	}
+
+	// the SEQUENCE of the token fields
+	if tag, content, _, ok = derDecode(content); !ok || tag != 0x30 {
+		return nil, true, errNtlmMessage
+	}
+	for len(content) > 0 {
+		var field []byte
+		if tag, field, content, ok = derDecode(content); !ok {
+			return nil, true, errNtlmMessage
+		}
+		if tag != mechTokenTag {
+			continue
+		}
+		if tag, field, _, ok = derDecode(field); !ok || tag != 0x04 {
+			return nil, true, errNtlmMessage
+		}
+		return field, true, nil
+	}
+
+	return nil, true, errNtlmMessage
+}
@@ -0,0 +1,459 @@
+package smb
+
+import (
+	"context"
+	"hash/fnv"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"golang.org/x/net/webdav"
+)
+
+// create dispositions
+const (
+	fileSupersede   = 0
+	fileOpen        = 1
+	fileCreate      = 2
+	fileOpenIf      = 3
+	fileOverwrite   = 4
+	fileOverwriteIf = 5
+)
+
+// create actions
+const (
+	fileSuperseded  = 0
+	fileOpened      = 1
+	fileCreated     = 2
+	fileOverwritten = 3
+)
+
+// create options
+const (
+	fileDirectoryFile    = 0x00000001
+	fileNonDirectoryFile = 0x00000040
+	fileDeleteOnClose    = 0x00001000
+)
+
+// access masks that need write permission
+const (
+	fileWriteData   = 0x00000002
+	fileAppendData  = 0x00000004
+	accessDelete    = 0x00010000
+	genericAll      = 0x10000000
+	genericWrite    = 0x40000000
+	writeAccessMask = fileWriteData | fileAppendData | accessDelete | genericAll | genericWrite
+)
+
+// file attributes
+const (
+	fileAttributeReadonly  = 0x00000001
+	fileAttributeDirectory = 0x00000010
+	fileAttributeArchive   = 0x00000020
+)
+
+// open is a file or a directory opened by CREATE, until CLOSE
+type open struct {
+	id            uint64
+	tree          *tree
+	name          string // the full path in the file system
+	isDir         bool
+	deleteOnClose bool
+
+	reader webdav.File
+	writer webdav.File
+
+	// the directory listing for QUERY_DIRECTORY
+	dirEntries []os.FileInfo
+	dirPattern string
+	dirOffset  int
+}
+
+func (o *open) close() {
+	o.resetFiles()
+	if o.deleteOnClose {
+		if err := o.tree.fs.RemoveAll(context.Background(), o.name); err != nil {
+			glog.V(0).Infof("smb delete %s: %v", o.name, err)
+		}
+	}
+}
+
+// resetFiles closes the opened files, so that the next read or write sees the latest content
+func (o *open) resetFiles() {
+	if o.reader != nil {
+		o.reader.Close()
+		o.reader = nil
+	}
+	if o.writer != nil {
+		o.writer.Close()
+		o.writer = nil
+	}
+}
+
+func (o *open) stat(ctx context.Context) (os.FileInfo, error) {
+	return o.tree.fs.Stat(ctx, o.name)
+}
+
+// sharePath maps the name relative to the share to the full path in the file system
+func sharePath(share *Share, name string) string {
+	name = strings.Replace(name, `\`, "/", -1)
+	return path.Join(share.Path, path.Clean("/"+name))
+}
+
+// fileIndex is a stable number to identify a file
+func fileIndex(fullPath string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(fullPath))
+	return h.Sum64()
+}
+
+func fileAttributes(fi os.FileInfo, readOnly bool) (attributes uint32) {
+	if fi.IsDir() {
+		return fileAttributeDirectory
+	}
+	attributes = fileAttributeArchive
+	if readOnly {
+		attributes |= fileAttributeReadonly
+	}
+	return
+}
+
+func allocationSize(fi os.FileInfo) uint64 {
+	return uint64(fi.Size()+4095) &^ 4095
+}
+
+func statusOf(err error) uint32 {
+	switch {
+	case err == nil:
+		return statusSuccess
+	case os.IsNotExist(err):
+		return statusObjectNameNotFound
+	case os.IsExist(err):
+		return statusObjectNameCollision
+	case os.IsPermission(err):
+		return statusAccessDenied
+	}
+	return statusUnexpectedIoError
+}
+
+func (c *connection) getOpen(r *request, fileId []byte) *open {
+	id := readFileId(fileId)
+	if id == 0xFFFFFFFFFFFFFFFF {
+		// the file opened by the previous command in the compounded chain
+		id = r.fileId
+	}
+	o := r.session.opens[id]
+	if o == nil || o.tree != r.tree {
+		return nil
+	}
+	r.fileId = id
+	return o
+}
+
+func (c *connection) handleCreate(r *request) (status uint32, body []byte) {
+	b := r.body
+	if len(b) < 56 {
+		return statusInvalidParameter, nil
+	}
+	if r.tree.share == nil {
+		// no named pipes on IPC$
+		return statusObjectNameNotFound, nil
+	}
+	desiredAccess := le.Uint32(b[24:])
+	createDisposition := le.Uint32(b[36:])
+	createOptions := le.Uint32(b[40:])
+	name := ""
+	if nameLength := int(le.Uint16(b[46:])); nameLength > 0 {
+		nameBytes := slice(r.message, int(le.Uint16(b[44:])), nameLength)
+		if nameBytes == nil {
+			return statusInvalidParameter, nil
+		}
+		name = decodeUtf16(nameBytes)
+	}
+
+	// only the default data stream is supported
+	name = strings.TrimSuffix(name, "::$DATA")
+	if strings.Contains(name, ":") {
+		return statusObjectNameInvalid, nil
+	}
+
+	share, fs := r.tree.share, r.tree.fs
+	fullPath := sharePath(share, name)
+	isRoot := fullPath == path.Clean(share.Path)
+	wantsDir := createOptions&fileDirectoryFile != 0
+	wantsFile := createOptions&fileNonDirectoryFile != 0
+	deleteOnClose := createOptions&fileDeleteOnClose != 0
+
+	if share.ReadOnly && (desiredAccess&writeAccessMask != 0 || deleteOnClose) {
+		return statusAccessDenied, nil
+	}
+	if isRoot && deleteOnClose {
+		return statusAccessDenied, nil
+	}
+
+	ctx := context.Background()
+	fi, statErr := fs.Stat(ctx, fullPath)
+	exists := statErr == nil
+
+	if exists {
+		if fi.IsDir() && wantsFile {
+			return statusFileIsADirectory, nil
+		}
+		if !fi.IsDir() && wantsDir {
+			return statusNotADirectory, nil
+		}
+	} else {
+		if parent, err := fs.Stat(ctx, path.Dir(fullPath)); err != nil || !parent.IsDir() {
+			return statusObjectPathNotFound, nil
+		}
+	}
+
+	action := uint32(fileOpened)
+	switch createDisposition {
+	case fileOpen:
+		if !exists {
+			return statusObjectNameNotFound, nil
+		}
+	case fileCreate:
+		if exists {
+			return statusObjectNameCollision, nil
+		}
+		action = fileCreated
+	case fileOpenIf:
+		if !exists {
+			action = fileCreated
+		}
+	case fileOverwrite, fileOverwriteIf, fileSupersede:
+		if !exists {
+			if createDisposition == fileOverwrite {
+				return statusObjectNameNotFound, nil
+			}
+			action = fileCreated
+		} else if fi.IsDir() {
+			return statusInvalidParameter, nil
+		} else if createDisposition == fileSupersede {
+			action = fileSuperseded
+		} else {
+			action = fileOverwritten
+		}
+	default:
+		return statusInvalidParameter, nil
+	}
+
+	if action != fileOpened {
+		if share.ReadOnly {
+			return statusAccessDenied, nil
+		}
+		var err error
+		if action == fileCreated && wantsDir {
+			err = fs.Mkdir(ctx, fullPath, 0755)
+		} else {
+			// creating a file replaces the existing one
+			err = createEmptyFile(ctx, fs, fullPath)
+		}
+		if err != nil {
+			glog.V(0).Infof("smb create %s: %v", fullPath, err)
+			return statusOf(err), nil
+		}
+		if fi, statErr = fs.Stat(ctx, fullPath); statErr != nil {
+			return statusOf(statErr), nil
+		}
+	}
+
+	r.session.nextFileId++
+	o := &open{
+		id:            r.session.nextFileId,
+		tree:          r.tree,
+		name:          fullPath,
+		isDir:         fi.IsDir(),
+		deleteOnClose: deleteOnClose,
+	}
+	r.session.opens[o.id] = o
+	r.fileId = o.id
+
+	body = make([]byte, 89)
+	le.PutUint16(body[0:], 89)
+	le.PutUint32(body[4:], action)
+	putFileTimes(body[8:], fi)
+	le.PutUint64(body[40:], allocationSize(fi))
+	le.PutUint64(body[48:], uint64(fi.Size()))
+	le.PutUint32(body[56:], fileAttributes(fi, share.ReadOnly))
+	putFileId(body[64:], o.id)
+	return statusSuccess, body
+}
+
+func createEmptyFile(ctx context.Context, fs webdav.FileSystem, fullPath string) error {
+	f, err := fs.OpenFile(ctx, fullPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// putFileTimes fills creation, last access, last write and change time, which are all the modification time
+func putFileTimes(b []byte, fi os.FileInfo) {
+	t := fileTime(fi.ModTime())
+	le.PutUint64(b[0:], t)
+	le.PutUint64(b[8:], t)
+	le.PutUint64(b[16:], t)
+	le.PutUint64(b[24:], t)
+}
+
+func (c *connection) handleClose(r *request) (status uint32, body []byte) {
+	b := r.body
+	if len(b) < 24 {
+		return statusInvalidParameter, nil
+	}
+	o := c.getOpen(r, b[8:24])
+	if o == nil {
+		return statusFileClosed, nil
+	}
+	flags := le.Uint16(b[2:])
+
+	body = make([]byte, 60)
+	le.PutUint16(body[0:], 60)
+	if flags&0x0001 != 0 {
+		// SMB2_CLOSE_FLAG_POSTQUERY_ATTRIB
+		o.resetFiles()
+		if fi, err := o.stat(context.Background()); err == nil {
+			le.PutUint16(body[2:], flags)
+			putFileTimes(body[8:], fi)
+			le.PutUint64(body[40:], allocationSize(fi))
+			le.PutUint64(body[48:], uint64(fi.Size()))
+			le.PutUint32(body[56:], fileAttributes(fi, o.tree.share.ReadOnly))
+		}
+	}
+
+	o.close()
+	delete(r.session.opens, o.id)
+	return statusSuccess, body
+}
+
+func (c *connection) handleFlush(r *request) (status uint32, body []byte) {
+	if len(r.body) < 24 {
+		return statusInvalidParameter, nil
+	}
+	if o := c.getOpen(r, r.body[8:24]); o == nil {
+		return statusFileClosed, nil
+	}
+	// each write is already persisted to the filer
+	return statusSuccess, []byte{4, 0, 0, 0}
+}
+
+func (c *connection) handleRead(r *request) (status uint32, body []byte) {
+	b := r.body
+	if len(b) < 48 {
+		return statusInvalidParameter, nil
+	}
+	length := int(le.Uint32(b[4:]))
+	offset := int64(le.Uint64(b[8:]))
+	o := c.getOpen(r, b[16:32])
+	if o == nil {
+		return statusFileClosed, nil
+	}
+	if o.isDir {
+		return statusInvalidDeviceRequest, nil
+	}
+	if length > maxTransactSize {
+		length = maxTransactSize
+	}
+
+	ctx := context.Background()
+	if o.reader == nil {
+		f, err := o.tree.fs.OpenFile(ctx, o.name, os.O_RDONLY, 0)
+		if err != nil {
+			return statusOf(err), nil
+		}
+		o.reader = f
+	}
+	if _, err := o.reader.Seek(offset, io.SeekStart); err != nil {
+		return statusOf(err), nil
+	}
+
+	const headerSize = 16
+	body = make([]byte, headerSize+length)
+	n := 0
+	for n < length {
+		count, err := o.reader.Read(body[headerSize+n:])
+		n += count
+		if err == io.EOF || count == 0 {
+			break
+		}
+		if err != nil {
+			glog.V(0).Infof("smb read %s: %v", o.name, err)
+			return statusUnexpectedIoError, nil
+		}
+	}
+	if n == 0 && length > 0 {
+		return statusEndOfFile, nil
+	}
+
+	body = body[:headerSize+n]
+	le.PutUint16(body[0:], 17)
+	body[2] = smb2HeaderSize + headerSize
+	le.PutUint32(body[4:], uint32(n))
+	return statusSuccess, body
+}
+
+func (c *connection) handleWrite(r *request) (status uint32, body []byte) {
+	b := r.body
+	if len(b) < 48 {
+		return statusInvalidParameter, nil
+	}
+	data := slice(r.message, int(le.Uint16(b[2:])), int(le.Uint32(b[4:])))
+	offset := int64(le.Uint64(b[8:]))
+	o := c.getOpen(r, b[16:32])
+	if o == nil {
+		return statusFileClosed, nil
+	}
+	if data == nil {
+		return statusInvalidParameter, nil
+	}
+	if o.isDir {
+		return statusInvalidDeviceRequest, nil
+	}
+	if o.tree.share.ReadOnly {
+		return statusAccessDenied, nil
+	}
+
+	ctx := context.Background()
+	if o.writer == nil {
+		f, err := o.tree.fs.OpenFile(ctx, o.name, os.O_RDWR, 0)
+		if err != nil {
+			return statusOf(err), nil
+		}
+		o.writer = f
+	}
+	if o.reader != nil {
+		o.reader.Close()
+		o.reader = nil
+	}
+
+	if len(data) > 0 {
+		if _, err := o.writer.Seek(offset, io.SeekStart); err != nil {
+			return statusOf(err), nil
+		}
+		if _, err := o.writer.Write(data); err != nil {
+			glog.V(0).Infof("smb write %s: %v", o.name, err)
+			return statusUnexpectedIoError, nil
+		}
+	}
+
+	body = make([]byte, 17)
+	le.PutUint16(body[0:], 17)
+	le.PutUint32(body[4:], uint32(len(data)))
+	return statusSuccess, body
+}
+
+// handleLock always succeeds, since byte range locks are not enforced
+func (c *connection) handleLock(r *request) (status uint32, body []byte) {
+	if len(r.body) < 24 {
+		return statusInvalidParameter, nil
+	}
+	if o := c.getOpen(r, r.body[8:24]); o == nil {
+		return statusFileClosed, nil
+	}
+	return statusSuccess, []byte{4, 0, 0, 0}
+}
@@ -0,0 +1,561 @@
+package smb
+
+import (
+	"context"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+)
+
+// info types
+const (
+	infoFile       = 0x01
+	infoFilesystem = 0x02
+	infoSecurity   = 0x03
+)
+
+// file information classes
+const (
+	fileDirectoryInformation       = 1
+	fileFullDirectoryInformation   = 2
+	fileBothDirectoryInformation   = 3
+	fileBasicInformation           = 4
+	fileStandardInformation        = 5
+	fileInternalInformation        = 6
+	fileEaInformation              = 7
+	fileAccessInformation          = 8
+	fileRenameInformation          = 10
+	fileNamesInformation           = 12
+	fileDispositionInformation     = 13
+	filePositionInformation        = 14
+	fileModeInformation            = 16
+	fileAlignmentInformation       = 17
+	fileAllInformation             = 18
+	fileAllocationInformation      = 19
+	fileEndOfFileInformation       = 20
+	fileStreamInformation          = 22
+	fileNetworkOpenInformation     = 34
+	fileAttributeTagInformation    = 35
+	fileIdBothDirectoryInformation = 37
+	fileIdFullDirectoryInformation = 38
+	fileDispositionInformationEx   = 64
+)
+
+// file system information classes
+const (
+	fileFsVolumeInformation     = 1
+	fileFsSizeInformation       = 3
+	fileFsDeviceInformation     = 4
+	fileFsAttributeInformation  = 5
+	fileFsFullSizeInformation   = 7
+	fileFsSectorSizeInformation = 11
+)
+
+// query directory flags
+const (
+	queryDirectoryRestartScans      = 0x01
+	queryDirectoryReturnSingleEntry = 0x02
+	queryDirectoryReopen            = 0x10
+)
+
+const (
+	bytesPerSector    = 512
+	sectorsPerUnit    = 8
+	bytesPerAllocUnit = bytesPerSector * sectorsPerUnit
+)
+
+// dirEntry is one entry in the directory listing, with the name as shown to the client
+type dirEntry struct {
+	os.FileInfo
+	name string
+}
+
+func (e *dirEntry) Name() string { return e.name }
+
+func (c *connection) handleQueryDirectory(r *request) (status uint32, body []byte) {
+	b := r.body
+	if len(b) < 32 {
+		return statusInvalidParameter, nil
+	}
+	infoClass := b[2]
+	flags := b[3]
+	o := c.getOpen(r, b[8:24])
+	if o == nil {
+		return statusFileClosed, nil
+	}
+	if !o.isDir {
+		return statusInvalidParameter, nil
+	}
+	pattern := decodeUtf16(slice(r.message, int(le.Uint16(b[24:])), int(le.Uint16(b[26:]))))
+	outputBufferLength := int(le.Uint32(b[28:]))
+	if outputBufferLength > maxTransactSize {
+		outputBufferLength = maxTransactSize
+	}
+
+	ctx := context.Background()
+	if o.dirEntries == nil || flags&(queryDirectoryRestartScans|queryDirectoryReopen) != 0 {
+		entries, err := c.listDirectory(ctx, o)
+		if err != nil {
+			glog.V(0).Infof("smb list %s: %v", o.name, err)
+			return statusOf(err), nil
+		}
+		o.dirEntries, o.dirOffset, o.dirPattern = entries, 0, pattern
+	}
+	isFirstScan := o.dirOffset == 0
+
+	var output []byte
+	lastEntryOffset := -1
+	for o.dirOffset < len(o.dirEntries) {
+		entry := o.dirEntries[o.dirOffset]
+		if !matchPattern(o.dirPattern, entry.Name()) {
+			o.dirOffset++
+			continue
+		}
+		encoded := encodeDirectoryEntry(infoClass, entry, path.Join(o.name, entry.Name()), o.tree.share.ReadOnly)
+		if encoded == nil {
+			return statusInvalidInfoClass, nil
+		}
+		entryOffset := align8(len(output))
+		if entryOffset+len(encoded) > outputBufferLength {
+			if lastEntryOffset < 0 {
+				return statusInfoLengthMismatch, nil
+			}
+			break
+		}
+		output = append(output, make([]byte, entryOffset-len(output))...)
+		if lastEntryOffset >= 0 {
+			le.PutUint32(output[lastEntryOffset:], uint32(entryOffset-lastEntryOffset))
+		}
+		output = append(output, encoded...)
+		lastEntryOffset = entryOffset
+		o.dirOffset++
+		if flags&queryDirectoryReturnSingleEntry != 0 {
+			break
+		}
+	}
+
+	if len(output) == 0 {
+		if isFirstScan {
+			return statusNoSuchFile, nil
+		}
+		return statusNoMoreFiles, nil
+	}
+
+	return statusSuccess, queryResponse(output)
+}
+
+// listDirectory lists the directory with "." and ".." in front
+func (c *connection) listDirectory(ctx context.Context, o *open) (entries []os.FileInfo, err error) {
+	fs := o.tree.fs
+	dir, err := fs.OpenFile(ctx, o.name, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+	children, err := dir.Readdir(0)
+	if err != nil {
+		return nil, err
+	}
+
+	self, err := fs.Stat(ctx, o.name)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := fs.Stat(ctx, path.Dir(o.name))
+	if err != nil {
+		parent = self
+	}
+	entries = append(entries, &dirEntry{self, "."}, &dirEntry{parent, ".."})
+	for _, child := range children {
+		// the directory names may end with "/"
+		entries = append(entries, &dirEntry{child, strings.TrimSuffix(child.Name(), "/")})
+	}
+	return entries, nil
+}
+
+// matchPattern matches the name with the wildcards "*" and "?", case insensitively
+func matchPattern(pattern, name string) bool {
+	if pattern == "" || pattern == "*" {
+		return true
+	}
+	matched, err := path.Match(strings.ToLower(pattern), strings.ToLower(name))
+	return err == nil && matched
+}
+
+func encodeDirectoryEntry(infoClass byte, fi os.FileInfo, fullPath string, readOnly bool) []byte {
+	name := encodeUtf16(fi.Name())
+
+	if infoClass == fileNamesInformation {
+		b := make([]byte, 12+len(name))
+		le.PutUint32(b[8:], uint32(len(name)))
+		copy(b[12:], name)
+		return b
+	}
+
+	var nameOffset int
+	switch infoClass {
+	case fileDirectoryInformation:
+		nameOffset = 64
+	case fileFullDirectoryInformation:
+		nameOffset = 68
+	case fileIdFullDirectoryInformation:
+		nameOffset = 80
+	case fileBothDirectoryInformation:
+		nameOffset = 94
+	case fileIdBothDirectoryInformation:
+		nameOffset = 104
+	default:
+		return nil
+	}
+
+	b := make([]byte, nameOffset+len(name))
+	putFileTimes(b[8:], fi)
+	le.PutUint64(b[40:], uint64(fi.Size()))
+	le.PutUint64(b[48:], allocationSize(fi))
+	le.PutUint32(b[56:], fileAttributes(fi, readOnly))
+	le.PutUint32(b[60:], uint32(len(name)))
+	switch infoClass {
+	case fileIdFullDirectoryInformation:
+		le.PutUint64(b[72:], fileIndex(fullPath))
+	case fileIdBothDirectoryInformation:
+		le.PutUint64(b[96:], fileIndex(fullPath))
+	}
+	copy(b[nameOffset:], name)
+	return b
+}
+
+// queryResponse is the response to QUERY_DIRECTORY and QUERY_INFO
+func queryResponse(output []byte) []byte {
+	body := make([]byte, 8+len(output), 9+len(output))
+	le.PutUint16(body[0:], 9)
+	le.PutUint16(body[2:], smb2HeaderSize+8)
+	le.PutUint32(body[4:], uint32(len(output)))
+	copy(body[8:], output)
+	if len(output) == 0 {
+		body = append(body, 0)
+	}
+	return body
+}
+
+func (c *connection) handleQueryInfo(r *request) (status uint32, body []byte) {
+	b := r.body
+	if len(b) < 40 {
+		return statusInvalidParameter, nil
+	}
+	infoType, infoClass := b[2], b[3]
+	outputBufferLength := int(le.Uint32(b[4:]))
+	o := c.getOpen(r, b[24:40])
+	if o == nil {
+		return statusFileClosed, nil
+	}
+
+	fi, err := o.stat(context.Background())
+	if err != nil {
+		return statusOf(err), nil
+	}
+
+	var output []byte
+	switch infoType {
+	case infoFile:
+		output = c.fileInfo(infoClass, o, fi)
+	case infoFilesystem:
+		output = c.filesystemInfo(infoClass)
+	case infoSecurity:
+		output = securityDescriptor()
+	}
+	if output == nil {
+		return statusInvalidInfoClass, nil
+	}
+
+	if len(output) > outputBufferLength {
+		if infoType == infoSecurity {
+			// the error response tells the size needed
+			body = make([]byte, 12)
+			le.PutUint16(body[0:], 9)
+			le.PutUint32(body[4:], 4)
+			le.PutUint32(body[8:], uint32(len(output)))
+			return statusBufferTooSmall, body
+		}
+		return statusBufferOverflow, queryResponse(output[:outputBufferLength])
+	}
+	return statusSuccess, queryResponse(output)
+}
+
+func (c *connection) fileInfo(infoClass byte, o *open, fi os.FileInfo) []byte {
+	readOnly := o.tree.share.ReadOnly
+
+	basic := func() []byte {
+		b := make([]byte, 40)
+		putFileTimes(b, fi)
+		le.PutUint32(b[32:], fileAttributes(fi, readOnly))
+		return b
+	}
+	standard := func() []byte {
+		b := make([]byte, 24)
+		le.PutUint64(b[0:], allocationSize(fi))
+		le.PutUint64(b[8:], uint64(fi.Size()))
+		le.PutUint32(b[16:], 1)
+		if o.deleteOnClose {
+			b[20] = 1
+		}
+		if fi.IsDir() {
+			b[21] = 1
+		}
+		return b
+	}
+	uint64Info := func(v uint64) []byte {
+		b := make([]byte, 8)
+		le.PutUint64(b, v)
+		return b
+	}
+	uint32Info := func(v uint32) []byte {
+		b := make([]byte, 4)
+		le.PutUint32(b, v)
+		return b
+	}
+
+	switch infoClass {
+	case fileBasicInformation:
+		return basic()
+	case fileStandardInformation:
+		return standard()
+	case fileInternalInformation:
+		return uint64Info(fileIndex(o.name))
+	case fileEaInformation:
+		return uint32Info(0)
+	case fileAccessInformation:
+		return uint32Info(accessMaskFull)
+	case filePositionInformation:
+		return uint64Info(0)
+	case fileModeInformation, fileAlignmentInformation:
+		return uint32Info(0)
+	case fileAllInformation:
+		var b []byte
+		b = append(b, basic()...)
+		b = append(b, standard()...)
+		b = append(b, uint64Info(fileIndex(o.name))...)
+		b = append(b, uint32Info(0)...) // ea
+		b = append(b, uint32Info(accessMaskFull)...)
+		b = append(b, uint64Info(0)...) // position
+		b = append(b, uint32Info(0)...) // mode
+		b = append(b, uint32Info(0)...) // alignment
+		relativePath := strings.TrimPrefix(o.name, path.Clean(o.tree.share.Path))
+		if !strings.HasPrefix(relativePath, "/") {
+			relativePath = "/" + relativePath
+		}
+		name := encodeUtf16(strings.Replace(relativePath, "/", `\`, -1))
+		b = append(b, uint32Info(uint32(len(name)))...)
+		return append(b, name...)
+	case fileNetworkOpenInformation:
+		b := make([]byte, 56)
+		putFileTimes(b, fi)
+		le.PutUint64(b[32:], allocationSize(fi))
+		le.PutUint64(b[40:], uint64(fi.Size()))
+		le.PutUint32(b[48:], fileAttributes(fi, readOnly))
+		return b
+	case fileAttributeTagInformation:
+		b := make([]byte, 8)
+		le.PutUint32(b, fileAttributes(fi, readOnly))
+		return b
+	case fileStreamInformation:
+		if fi.IsDir() {
+			return []byte{}
+		}
+		name := encodeUtf16("::$DATA")
+		b := make([]byte, 24+len(name))
+		le.PutUint32(b[4:], uint32(len(name)))
+		le.PutUint64(b[8:], uint64(fi.Size()))
+		le.PutUint64(b[16:], allocationSize(fi))
+		copy(b[24:], name)
+		return b
+	}
+	return nil
+}
+
+func (c *connection) filesystemInfo(infoClass byte) []byte {
+	total, free := c.server.diskUsage()
+	totalUnits, freeUnits := total/bytesPerAllocUnit, free/bytesPerAllocUnit
+
+	switch infoClass {
+	case fileFsVolumeInformation:
+		label := encodeUtf16("SeaweedFS")
+		b := make([]byte, 18+len(label))
+		le.PutUint64(b[0:], fileTime(c.server.startTime))
+		le.PutUint32(b[8:], le.Uint32(c.server.serverGuid[:]))
+		le.PutUint32(b[12:], uint32(len(label)))
+		copy(b[18:], label)
+		return b
+	case fileFsSizeInformation:
+		b := make([]byte, 24)
+		le.PutUint64(b[0:], totalUnits)
+		le.PutUint64(b[8:], freeUnits)
+		le.PutUint32(b[16:], sectorsPerUnit)
+		le.PutUint32(b[20:], bytesPerSector)
+		return b
+	case fileFsFullSizeInformation:
+		b := make([]byte, 32)
+		le.PutUint64(b[0:], totalUnits)
+		le.PutUint64(b[8:], freeUnits)
+		le.PutUint64(b[16:], freeUnits)
+		le.PutUint32(b[24:], sectorsPerUnit)
+		le.PutUint32(b[28:], bytesPerSector)
+		return b
+	case fileFsDeviceInformation:
+		b := make([]byte, 8)
+		le.PutUint32(b[0:], 0x07) // FILE_DEVICE_DISK
+		return b
+	case fileFsAttributeInformation:
+		// report NTFS like other SMB servers, since some clients only enable features for known file systems
+		name := encodeUtf16("NTFS")
+		b := make([]byte, 12+len(name))
+		le.PutUint32(b[0:], 0x00000003) // FILE_CASE_SENSITIVE_SEARCH | FILE_CASE_PRESERVED_NAMES
+		le.PutUint32(b[4:], 255)
+		le.PutUint32(b[8:], uint32(len(name)))
+		copy(b[12:], name)
+		return b
+	case fileFsSectorSizeInformation:
+		b := make([]byte, 28)
+		for i := 0; i < 16; i += 4 {
+			le.PutUint32(b[i:], bytesPerSector)
+		}
+		return b
+	}
+	return nil
+}
+
+// securityDescriptor grants everyone full access, with a NULL DACL
+func securityDescriptor() []byte {
+	everyone := []byte{1, 1, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0} // S-1-1-0
+	b := make([]byte, 20, 20+2*len(everyone))
+	b[0] = 1                        // revision
+	le.PutUint16(b[2:], 0x8000|0x4) // SE_SELF_RELATIVE | SE_DACL_PRESENT
+	le.PutUint32(b[4:], 20)
+	le.PutUint32(b[8:], uint32(20+len(everyone)))
+	b = append(b, everyone...)
+	return append(b, everyone...)
+}
+
+func (c *connection) handleSetInfo(r *request) (status uint32, body []byte) {
+	b := r.body
+	if len(b) < 32 {
+		return statusInvalidParameter, nil
+	}
+	infoType, infoClass := b[2], b[3]
+	buffer := slice(r.message, int(le.Uint16(b[8:])), int(le.Uint32(b[4:])))
+	o := c.getOpen(r, b[16:32])
+	if o == nil {
+		return statusFileClosed, nil
+	}
+	if buffer == nil {
+		return statusInvalidParameter, nil
+	}
+	if infoType != infoFile {
+		// security descriptors and quotas are not stored, and changing them is silently ignored
+		return statusSuccess, []byte{2, 0}
+	}
+
+	ctx := context.Background()
+	fs := o.tree.fs
+
+	switch infoClass {
+	case fileBasicInformation, fileAllocationInformation:
+		// the times and attributes are not stored, and the space is allocated with the writes
+		return statusSuccess, []byte{2, 0}
+
+	case fileDispositionInformation, fileDispositionInformationEx:
+		if len(buffer) < 1 {
+			return statusInfoLengthMismatch, nil
+		}
+		deletePending := buffer[0]&0x01 != 0
+		if deletePending {
+			if o.tree.share.ReadOnly || o.name == path.Clean(o.tree.share.Path) {
+				return statusAccessDenied, nil
+			}
+			if o.isDir {
+				if isEmpty, err := isEmptyDirectory(ctx, o); err != nil {
+					return statusOf(err), nil
+				} else if !isEmpty {
+					return statusDirectoryNotEmpty, nil
+				}
+			}
+		}
+		o.deleteOnClose = deletePending
+		return statusSuccess, []byte{2, 0}
+
+	case fileRenameInformation:
+		if len(buffer) < 20 {
+			return statusInfoLengthMismatch, nil
+		}
+		if o.tree.share.ReadOnly {
+			return statusAccessDenied, nil
+		}
+		replaceIfExists := buffer[0] != 0
+		newName := slice(buffer, 20, int(le.Uint32(buffer[16:])))
+		if newName == nil {
+			return statusInvalidParameter, nil
+		}
+		newPath := sharePath(o.tree.share, decodeUtf16(newName))
+		if newPath == o.name {
+			return statusSuccess, []byte{2, 0}
+		}
+		if _, err := fs.Stat(ctx, newPath); err == nil {
+			if !replaceIfExists {
+				return statusObjectNameCollision, nil
+			}
+			if err = fs.RemoveAll(ctx, newPath); err != nil {
+				return statusOf(err), nil
+			}
+		}
+		o.resetFiles()
+		if err := fs.Rename(ctx, o.name, newPath); err != nil {
+			glog.V(0).Infof("smb rename %s => %s: %v", o.name, newPath, err)
+			return statusOf(err), nil
+		}
+		o.name = newPath
+		return statusSuccess, []byte{2, 0}
+
+	case fileEndOfFileInformation:
+		if len(buffer) < 8 {
+			return statusInfoLengthMismatch, nil
+		}
+		if o.tree.share.ReadOnly {
+			return statusAccessDenied, nil
+		}
+		endOfFile := int64(le.Uint64(buffer))
+		fi, err := o.stat(ctx)
+		if err != nil {
+			return statusOf(err), nil
+		}
+		switch {
+		case endOfFile == fi.Size():
+		case endOfFile == 0:
+			o.resetFiles()
+			if err = createEmptyFile(ctx, fs, o.name); err != nil {
+				return statusOf(err), nil
+			}
+		case endOfFile > fi.Size():
+			// clients set the final size before writing, and the file grows with the writes
+		default:
+			// truncating to a smaller non-zero size is not supported
+			return statusNotSupported, nil
+		}
+		return statusSuccess, []byte{2, 0}
+	}
+
+	return statusInvalidInfoClass, nil
+}
+
+func isEmptyDirectory(ctx context.Context, o *open) (bool, error) {
+	dir, err := o.tree.fs.OpenFile(ctx, o.name, os.O_RDONLY, 0)
+	if err != nil {
+		return false, err
+	}
+	defer dir.Close()
+	children, err := dir.Readdir(0)
+	if err != nil {
+		return false, err
+	}
+	return len(children) == 0, nil
+}
@@ -0,0 +1,501 @@
+package smb
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"golang.org/x/net/webdav"
+)
+
+const (
+	maxTransactSize = 64 * 1024
+	maxMessageSize  = maxTransactSize + 1024
+)
+
+type User struct {
+	Name     string
+	Password string
+	Uid      uint32
+	Gid      uint32
+}
+
+type Share struct {
+	Name     string
+	Path     string   // the directory on the filer
+	ReadOnly bool     // no writes, deletes or renames
+	Users    []string // the users allowed to connect, empty means all users
+}
+
+func (share *Share) isAllowed(user *User) bool {
+	if len(share.Users) == 0 {
+		return true
+	}
+	for _, name := range share.Users {
+		if strings.EqualFold(name, user.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+type Option struct {
+	ServerName string
+	Domain     string
+	Users      []*User
+	Shares     []*Share
+	// NewFileSystem creates the file system to access shares as the user
+	NewFileSystem func(user *User) webdav.FileSystem
+	// DiskUsage reports the total and the free space in bytes, optional
+	DiskUsage func() (total, free uint64, err error)
+}
+
+type Server struct {
+	option      *Option
+	serverGuid  [16]byte
+	startTime   time.Time
+	users       map[string]*User
+	shares      map[string]*Share
+	fileSystems map[string]webdav.FileSystem
+	fsLock      sync.Mutex
+
+	nextSessionId uint64
+
+	diskUsageLock    sync.Mutex
+	diskTotal        uint64
+	diskFree         uint64
+	diskUsageChecked time.Time
+}
+
+func NewServer(option *Option) *Server {
+	s := &Server{
+		option:      option,
+		startTime:   time.Now(),
+		users:       make(map[string]*User),
+		shares:      make(map[string]*Share),
+		fileSystems: make(map[string]webdav.FileSystem),
+	}
+	rand.Read(s.serverGuid[:])
+	// user names and share names are case insensitive
+	for _, user := range option.Users {
+		s.users[strings.ToLower(user.Name)] = user
+	}
+	for _, share := range option.Shares {
+		s.shares[strings.ToLower(share.Name)] = share
+	}
+	return s
+}
+
+func (s *Server) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go s.serveConnection(conn)
+	}
+}
+
+func (s *Server) lookupPassword(name string) (string, bool) {
+	user, found := s.users[strings.ToLower(name)]
+	if !found {
+		return "", false
+	}
+	return user.Password, true
+}
+
+func (s *Server) fileSystem(user *User) webdav.FileSystem {
+	s.fsLock.Lock()
+	defer s.fsLock.Unlock()
+	fs, found := s.fileSystems[user.Name]
+	if !found {
+		fs = s.option.NewFileSystem(user)
+		s.fileSystems[user.Name] = fs
+	}
+	return fs
+}
+
+func (s *Server) diskUsage() (total, free uint64) {
+	s.diskUsageLock.Lock()
+	defer s.diskUsageLock.Unlock()
+
+	if s.diskUsageChecked.Add(20 * time.Second).Before(time.Now()) {
+		s.diskTotal, s.diskFree = 1<<50, 1<<50
+		if s.option.DiskUsage != nil {
+			if total, free, err := s.option.DiskUsage(); err != nil {
+				glog.V(1).Infof("smb disk usage: %v", err)
+			} else {
+				s.diskTotal, s.diskFree = total, free
+			}
+		}
+		s.diskUsageChecked = time.Now()
+	}
+	return s.diskTotal, s.diskFree
+}
+
+// connection serves the requests from one client one by one
+type connection struct {
+	server                *Server
+	conn                  net.Conn
+	dialect               uint16
+	clientRequiresSigning bool
+	sessions              map[uint64]*session
+}
+
+type session struct {
+	id              uint64
+	ntlm            *ntlmServer
+	user            *User
+	sessionKey      []byte
+	signingRequired bool
+	trees           map[uint32]*tree
+	nextTreeId      uint32
+	opens           map[uint64]*open
+	nextFileId      uint64
+}
+
+type tree struct {
+	id    uint32
+	share *Share // nil for the IPC$ share
+	fs    webdav.FileSystem
+}
+
+// request is one SMB2 command, possibly one of a compounded chain
+type request struct {
+	header  smb2Header
+	message []byte // starting with the header
+	body    []byte
+	session *session
+	tree    *tree
+
+	// set by the handlers
+	responseSessionId uint64
+	responseTreeId    uint32
+	fileId            uint64
+}
+
+func (s *Server) serveConnection(conn net.Conn) {
+	defer conn.Close()
+
+	c := &connection{
+		server:   s,
+		conn:     conn,
+		sessions: make(map[uint64]*session),
+	}
+	defer c.closeAll()
+
+	reader := bufio.NewReader(conn)
+	for {
+		message, err := readTransportMessage(reader)
+		if err != nil {
+			if err != io.EOF {
+				glog.V(1).Infof("smb read from %s: %v", conn.RemoteAddr(), err)
+			}
+			return
+		}
+
+		var response []byte
+		switch {
+		case strings.HasPrefix(string(message), smb1ProtocolId):
+			response, err = c.handleSmb1Negotiate(message)
+		case strings.HasPrefix(string(message), smb2ProtocolId):
+			response = c.handleCompound(message)
+		default:
+			err = fmt.Errorf("unknown protocol")
+		}
+		if err != nil {
+			glog.V(1).Infof("smb from %s: %v", conn.RemoteAddr(), err)
+			return
+		}
+		if response == nil {
+			continue
+		}
+
+		if err = writeTransportMessage(conn, response); err != nil {
+			glog.V(1).Infof("smb write to %s: %v", conn.RemoteAddr(), err)
+			return
+		}
+	}
+}
+
+// readTransportMessage reads one message with the 4-byte direct TCP transport header
+func readTransportMessage(reader io.Reader) ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		return nil, err
+	}
+	length := binary.BigEndian.Uint32(header[:]) & 0xffffff
+	if header[0] != 0 || length > maxMessageSize {
+		return nil, fmt.Errorf("invalid message length %d", length)
+	}
+	message := make([]byte, length)
+	if _, err := io.ReadFull(reader, message); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+func writeTransportMessage(writer io.Writer, message []byte) error {
+	buf := make([]byte, 4+len(message))
+	binary.BigEndian.PutUint32(buf, uint32(len(message)))
+	copy(buf[4:], message)
+	_, err := writer.Write(buf)
+	return err
+}
+
+func (c *connection) closeAll() {
+	for _, session := range c.sessions {
+		session.closeAll()
+	}
+}
+
+func (s *session) closeAll() {
+	for _, o := range s.opens {
+		o.close()
+	}
+	s.opens = make(map[uint64]*open)
+}
+
+// handleSmb1Negotiate only answers the SMB1 negotiate sent by older clients to find out whether SMB2 is supported
+func (c *connection) handleSmb1Negotiate(message []byte) ([]byte, error) {
+	const smb1HeaderSize = 32
+	if len(message) < smb1HeaderSize+3 || message[4] != 0x72 {
+		return nil, fmt.Errorf("smb1 is not supported")
+	}
+
+	dialect := uint16(0)
+	for _, name := range strings.Split(string(message[smb1HeaderSize+3:]), "\x00") {
+		switch strings.TrimPrefix(name, "\x02") {
+		case "SMB 2.???":
+			dialect = smb2DialectWildcard
+		case "SMB 2.002":
+			if dialect == 0 {
+				dialect = smb2Dialect202
+			}
+		}
+	}
+	if dialect == 0 {
+		return nil, fmt.Errorf("smb1 is not supported")
+	}
+
+	r := &request{header: smb2Header{Command: smb2Negotiate}}
+	return c.buildResponse(r, statusSuccess, c.negotiateResponse(dialect), false), nil
+}
+
+func (c *connection) handleCompound(message []byte) []byte {
+
+	var response []byte
+	var previous *request
+	var previousStatus uint32
+
+	for len(message) > 0 {
+		header, ok := decodeSmb2Header(message)
+		if !ok {
+			break
+		}
+		current := message
+		if header.NextCommand > 0 && int(header.NextCommand) < len(message) {
+			current, message = message[:header.NextCommand], message[header.NextCommand:]
+		} else {
+			message = nil
+		}
+
+		r := &request{
+			header:            header,
+			message:           current,
+			body:              current[smb2HeaderSize:],
+			responseSessionId: header.SessionId,
+			responseTreeId:    header.TreeId,
+		}
+		isRelated := header.Flags&smb2FlagsRelatedOperations != 0 && previous != nil
+		if isRelated {
+			r.responseSessionId = previous.responseSessionId
+			r.responseTreeId = previous.responseTreeId
+			r.fileId = previous.fileId
+		}
+
+		var status uint32
+		var body []byte
+		if isRelated && previousStatus != statusSuccess {
+			// the failure of a command fails the related commands after it
+			status = previousStatus
+		} else {
+			status, body = c.dispatch(r)
+		}
+		if header.Command == smb2Cancel {
+			continue
+		}
+
+		sign := r.session != nil && r.session.sessionKey != nil &&
+			(r.session.signingRequired || header.Flags&smb2FlagsSigned != 0) &&
+			header.Command != smb2Negotiate
+		one := c.buildResponse(r, status, body, sign)
+
+		if response != nil {
+			// the previous response in the chain is padded to 8-byte alignment, and is re-signed
+			last := response[previousResponseOffset(response):]
+			padding := align8(len(last)) - len(last)
+			response = append(response, make([]byte, padding)...)
+			last = response[previousResponseOffset(response):]
+			le.PutUint32(last[20:], uint32(len(last)))
+			if le.Uint32(last[16:])&smb2FlagsSigned != 0 && previous.session != nil {
+				signMessage(last, previous.session.sessionKey)
+			}
+		}
+		response = append(response, one...)
+
+		previous, previousStatus = r, status
+	}
+
+	return response
+}
+
+// previousResponseOffset finds the last response in a chain of responses
+func previousResponseOffset(response []byte) int {
+	offset := 0
+	for {
+		next := le.Uint32(response[offset+20:])
+		if next == 0 {
+			return offset
+		}
+		offset += int(next)
+	}
+}
+
+func (c *connection) dispatch(r *request) (status uint32, body []byte) {
+
+	h := r.header
+
+	switch h.Command {
+	case smb2Negotiate:
+		return c.handleNegotiate(r)
+	case smb2SessionSetup:
+		return c.handleSessionSetup(r)
+	case smb2Echo:
+		return statusSuccess, []byte{4, 0, 0, 0}
+	case smb2Cancel:
+		return statusSuccess, nil
+	}
+
+	// the commands below require an authenticated session
+	r.session = c.sessions[r.responseSessionId]
+	if r.session == nil || r.session.user == nil {
+		return statusUserSessionDeleted, nil
+	}
+	if h.Flags&smb2FlagsSigned != 0 && !verifySignature(r.message, r.session.sessionKey) {
+		return statusAccessDenied, nil
+	}
+
+	switch h.Command {
+	case smb2Logoff:
+		return c.handleLogoff(r)
+	case smb2TreeConnect:
+		return c.handleTreeConnect(r)
+	}
+
+	// the commands below require a connected tree
+	r.tree = r.session.trees[r.responseTreeId]
+	if r.tree == nil {
+		return statusNetworkNameDeleted, nil
+	}
+
+	switch h.Command {
+	case smb2TreeDisconnect:
+		return c.handleTreeDisconnect(r)
+	case smb2Create:
+		return c.handleCreate(r)
+	case smb2Close:
+		return c.handleClose(r)
+	case smb2Flush:
+		return c.handleFlush(r)
+	case smb2Read:
+		return c.handleRead(r)
+	case smb2Write:
+		return c.handleWrite(r)
+	case smb2Lock:
+		return c.handleLock(r)
+	case smb2QueryDirectory:
+		return c.handleQueryDirectory(r)
+	case smb2QueryInfo:
+		return c.handleQueryInfo(r)
+	case smb2SetInfo:
+		return c.handleSetInfo(r)
+	}
+
+	// ioctl, change notify and oplock break are not supported
+	return statusNotSupported, nil
+}
+
+func (c *connection) buildResponse(r *request, status uint32, body []byte, sign bool) []byte {
+
+	if body == nil {
+		// the error response
+		body = make([]byte, 9)
+		le.PutUint16(body, 9)
+	}
+
+	credits := r.header.Credits
+	if credits == 0 {
+		credits = 1
+	}
+	header := smb2Header{
+		CreditCharge: r.header.CreditCharge,
+		Status:       status,
+		Command:      r.header.Command,
+		Credits:      credits,
+		Flags:        smb2FlagsServerToRedir | r.header.Flags&smb2FlagsRelatedOperations,
+		MessageId:    r.header.MessageId,
+		TreeId:       r.responseTreeId,
+		SessionId:    r.responseSessionId,
+	}
+	if sign {
+		header.Flags |= smb2FlagsSigned
+	}
+
+	response := make([]byte, smb2HeaderSize+len(body))
+	header.encode(response)
+	copy(response[smb2HeaderSize:], body)
+
+	if sign {
+		signMessage(response, r.session.sessionKey)
+	}
+
+	return response
+}
+
+// signMessage signs with HMAC-SHA256 as in SMB 2.0.2 and 2.1
+func signMessage(message, sessionKey []byte) {
+	copy(message[48:64], make([]byte, 16))
+	mac := hmac.New(sha256.New, sessionKey)
+	mac.Write(message)
+	copy(message[48:64], mac.Sum(nil))
+}
+
+func verifySignature(message, sessionKey []byte) bool {
+	if sessionKey == nil || len(message) < smb2HeaderSize {
+		return false
+	}
+	signed := make([]byte, len(message))
+	copy(signed, message)
+	signMessage(signed, sessionKey)
+	return hmac.Equal(signed[48:64], message[48:64])
+}
+
+func readFileId(b []byte) uint64 {
+	// the persistent and the volatile parts are the same
+	return le.Uint64(b[8:])
+}
+
+func putFileId(b []byte, id uint64) {
+	le.PutUint64(b[0:], id)
+	le.PutUint64(b[8:], id)
+}
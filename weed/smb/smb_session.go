@@ -0,0 +1,222 @@
+package smb
+
+import (
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+)
+
+const (
+	shareTypeDisk = 0x01
+	shareTypePipe = 0x02
+
+	shareFlagNoCaching = 0x00000030
+
+	accessMaskFull     = 0x001F01FF
+	accessMaskReadOnly = 0x001200A9
+)
+
+func (c *connection) handleNegotiate(r *request) (status uint32, body []byte) {
+	b := r.body
+	if len(b) < 36 {
+		return statusInvalidParameter, nil
+	}
+	dialectCount := int(le.Uint16(b[2:]))
+	securityMode := le.Uint16(b[4:])
+	dialects := slice(b, 36, dialectCount*2)
+	if dialects == nil {
+		return statusInvalidParameter, nil
+	}
+
+	// SMB 3.x is not supported yet, and the clients fall back to SMB 2.1 or 2.0.2
+	dialect := uint16(0)
+	for i := 0; i < dialectCount; i++ {
+		switch d := le.Uint16(dialects[i*2:]); d {
+		case smb2Dialect210:
+			dialect = d
+		case smb2Dialect202:
+			if dialect == 0 {
+				dialect = d
+			}
+		}
+	}
+	if dialect == 0 {
+		return statusNotSupported, nil
+	}
+
+	c.dialect = dialect
+	c.clientRequiresSigning = securityMode&smb2NegotiateSigningRequired != 0
+
+	return statusSuccess, c.negotiateResponse(dialect)
+}
+
+func (c *connection) negotiateResponse(dialect uint16) []byte {
+	token := spnegoInitToken()
+	b := make([]byte, 64+len(token))
+	le.PutUint16(b[0:], 65)
+	le.PutUint16(b[2:], smb2NegotiateSigningEnabled)
+	le.PutUint16(b[4:], dialect)
+	copy(b[8:24], c.server.serverGuid[:])
+	le.PutUint32(b[24:], 0) // no DFS, leasing or large MTU
+	le.PutUint32(b[28:], maxTransactSize)
+	le.PutUint32(b[32:], maxTransactSize)
+	le.PutUint32(b[36:], maxTransactSize)
+	le.PutUint64(b[40:], fileTime(time.Now()))
+	le.PutUint64(b[48:], fileTime(c.server.startTime))
+	le.PutUint16(b[56:], smb2HeaderSize+64)
+	le.PutUint16(b[58:], uint16(len(token)))
+	copy(b[64:], token)
+	return b
+}
+
+func (c *connection) handleSessionSetup(r *request) (status uint32, body []byte) {
+	b := r.body
+	if len(b) < 24 {
+		return statusInvalidParameter, nil
+	}
+	securityMode := b[3]
+	token := slice(r.message, int(le.Uint16(b[12:])), int(le.Uint16(b[14:])))
+	if token == nil {
+		return statusInvalidParameter, nil
+	}
+
+	s := c.sessions[r.header.SessionId]
+	if r.header.SessionId == 0 {
+		s = &session{
+			id:    atomic.AddUint64(&c.server.nextSessionId, 1),
+			trees: make(map[uint32]*tree),
+			opens: make(map[uint64]*open),
+		}
+		c.sessions[s.id] = s
+	}
+	if s == nil {
+		return statusUserSessionDeleted, nil
+	}
+	r.responseSessionId = s.id
+
+	ntlmMessage, isSpnego, err := unwrapSecurityToken(token)
+	if err != nil || len(ntlmMessage) < 12 {
+		delete(c.sessions, s.id)
+		return statusLogonFailure, nil
+	}
+
+	switch le.Uint32(ntlmMessage[8:]) {
+	case ntlmNegotiateMessage:
+		s.ntlm = newNtlmServer(c.server.option.ServerName, c.server.option.Domain)
+		challenge, err := s.ntlm.challenge(ntlmMessage)
+		if err != nil {
+			delete(c.sessions, s.id)
+			return statusLogonFailure, nil
+		}
+		if isSpnego {
+			challenge = spnegoResponseToken(spnegoAcceptIncomplete, challenge)
+		}
+		return statusMoreProcessingRequired, sessionSetupResponse(challenge)
+
+	case ntlmAuthenticateMessage:
+		if s.ntlm == nil {
+			delete(c.sessions, s.id)
+			return statusLogonFailure, nil
+		}
+		name, sessionKey, err := s.ntlm.authenticate(ntlmMessage, c.server.lookupPassword)
+		s.ntlm = nil
+		if err != nil {
+			glog.V(0).Infof("smb logon %s from %s: %v", name, c.conn.RemoteAddr(), err)
+			delete(c.sessions, s.id)
+			return statusLogonFailure, nil
+		}
+		s.user = c.server.users[strings.ToLower(name)]
+		s.sessionKey = sessionKey
+		s.signingRequired = c.clientRequiresSigning || securityMode&byte(smb2NegotiateSigningRequired) != 0
+		r.session = s
+		glog.V(1).Infof("smb user %s logged on from %s", s.user.Name, c.conn.RemoteAddr())
+
+		var response []byte
+		if isSpnego {
+			response = spnegoResponseToken(spnegoAcceptCompleted, nil)
+		}
+		return statusSuccess, sessionSetupResponse(response)
+	}
+
+	delete(c.sessions, s.id)
+	return statusLogonFailure, nil
+}
+
+func sessionSetupResponse(token []byte) []byte {
+	b := make([]byte, 8+len(token), 9+len(token))
+	le.PutUint16(b[0:], 9)
+	le.PutUint16(b[2:], 0) // session flags
+	le.PutUint16(b[4:], smb2HeaderSize+8)
+	le.PutUint16(b[6:], uint16(len(token)))
+	copy(b[8:], token)
+	if len(token) == 0 {
+		b = append(b, 0)
+	}
+	return b
+}
+
+func (c *connection) handleLogoff(r *request) (status uint32, body []byte) {
+	r.session.closeAll()
+	delete(c.sessions, r.session.id)
+	return statusSuccess, []byte{4, 0, 0, 0}
+}
+
+func (c *connection) handleTreeConnect(r *request) (status uint32, body []byte) {
+	b := r.body
+	if len(b) < 8 {
+		return statusInvalidParameter, nil
+	}
+	uncPath := slice(r.message, int(le.Uint16(b[4:])), int(le.Uint16(b[6:])))
+	if uncPath == nil {
+		return statusInvalidParameter, nil
+	}
+	// \\server\share
+	shareName := decodeUtf16(uncPath)
+	if i := strings.LastIndex(shareName, `\`); i >= 0 {
+		shareName = shareName[i+1:]
+	}
+
+	t := &tree{}
+	shareType, maximalAccess := byte(shareTypeDisk), uint32(accessMaskFull)
+	if strings.EqualFold(shareName, "IPC$") {
+		shareType = shareTypePipe
+	} else {
+		share, found := c.server.shares[strings.ToLower(shareName)]
+		if !found {
+			return statusBadNetworkName, nil
+		}
+		if !share.isAllowed(r.session.user) {
+			return statusAccessDenied, nil
+		}
+		t.share = share
+		t.fs = c.server.fileSystem(r.session.user)
+		if share.ReadOnly {
+			maximalAccess = accessMaskReadOnly
+		}
+	}
+
+	r.session.nextTreeId++
+	t.id = r.session.nextTreeId
+	r.session.trees[t.id] = t
+	r.responseTreeId = t.id
+
+	body = make([]byte, 16)
+	le.PutUint16(body[0:], 16)
+	body[2] = shareType
+	le.PutUint32(body[4:], shareFlagNoCaching)
+	le.PutUint32(body[12:], maximalAccess)
+	return statusSuccess, body
+}
+
+func (c *connection) handleTreeDisconnect(r *request) (status uint32, body []byte) {
+	for id, o := range r.session.opens {
+		if o.tree == r.tree {
+			o.close()
+			delete(r.session.opens, id)
+		}
+	}
+	delete(r.session.trees, r.tree.id)
+	return statusSuccess, []byte{4, 0, 0, 0}
+}
@@ -0,0 +1,463 @@
+package smb
+
+import (
+	"bytes"
+	"encoding/hex"
+	"net"
+	"testing"
+
+	"golang.org/x/net/webdav"
+)
+
+func TestMd4(t *testing.T) {
+	for input, expected := range map[string]string{
+		"":    "31d6cfe0d16ae931b73c59d7e0c089c0",
+		"abc": "a448017aaf21d8525fc10ae87aa6729d",
+		"12345678901234567890123456789012345678901234567890123456789012345678901234567890": "e33b4ddc9c38f2199c3e7b164fcc0536",
+	} {
+		sum := md4Sum([]byte(input))
+		if actual := hex.EncodeToString(sum[:]); actual != expected {
+			t.Errorf("md4(%q) = %s, expected %s", input, actual, expected)
+		}
+	}
+}
+
+// the test vectors in [MS-NLMP] 4.2.4
+func TestNtlmv2(t *testing.T) {
+	responseKeyNt := ntowfv2("Password", "User", "Domain")
+	if actual := hex.EncodeToString(responseKeyNt); actual != "0c868a403bfd7a93a3001ef22ef02e3f" {
+		t.Fatalf("unexpected NTOWFv2 %s", actual)
+	}
+
+	serverChallenge, _ := hex.DecodeString("0123456789abcdef")
+	blob, _ := hex.DecodeString("0101000000000000" + "0000000000000000" + "aaaaaaaaaaaaaaaa" + "00000000" +
+		"02000c0044006f006d00610069006e00" + "01000c0053006500720076006500720000000000" + "00000000")
+	ntProofStr, sessionBaseKey := ntlmv2Response(responseKeyNt, serverChallenge, blob)
+	if actual := hex.EncodeToString(ntProofStr); actual != "68cd0ab851e51c96aabc927bebef6a1c" {
+		t.Errorf("unexpected NTProofStr %s", actual)
+	}
+	if actual := hex.EncodeToString(sessionBaseKey); actual != "8de40ccadbc14a82f15cb0ad0de95ca3" {
+		t.Errorf("unexpected session base key %s", actual)
+	}
+}
+
+func TestServer(t *testing.T) {
+
+	fs := webdav.NewMemFS()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	server := NewServer(&Option{
+		ServerName: "SEAWEEDFS",
+		Domain:     "WORKGROUP",
+		Users:      []*User{{Name: "alice", Password: "secret"}},
+		Shares: []*Share{
+			{Name: "data", Path: "/"},
+			{Name: "readonly", Path: "/", ReadOnly: true},
+		},
+		NewFileSystem: func(user *User) webdav.FileSystem {
+			return fs
+		},
+	})
+	go server.Serve(listener)
+
+	c, err := dialTestClient(listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.conn.Close()
+
+	// negotiate
+	negotiate := make([]byte, 40)
+	le.PutUint16(negotiate[0:], 36)
+	le.PutUint16(negotiate[2:], 2)
+	le.PutUint16(negotiate[36:], smb2Dialect202)
+	le.PutUint16(negotiate[38:], smb2Dialect210)
+	status, body := c.call(smb2Negotiate, negotiate)
+	if status != statusSuccess || le.Uint16(body[4:]) != smb2Dialect210 {
+		t.Fatalf("negotiate status %x dialect %x", status, le.Uint16(body[4:]))
+	}
+
+	if status = c.logon("alice", "wrong"); status != statusLogonFailure {
+		t.Fatalf("logon with wrong password: %x", status)
+	}
+	if status = c.logon("Alice", "secret"); status != statusSuccess {
+		t.Fatalf("logon: %x", status)
+	}
+
+	if status = c.treeConnect(`\\127.0.0.1\nothing`); status != statusBadNetworkName {
+		t.Fatalf("connect to unknown share: %x", status)
+	}
+	if status = c.treeConnect(`\\127.0.0.1\readonly`); status != statusSuccess {
+		t.Fatalf("connect to readonly: %x", status)
+	}
+	if status, _ = c.create(`hello.txt`, genericWrite, fileOverwriteIf, 0); status != statusAccessDenied {
+		t.Fatalf("create in readonly share: %x", status)
+	}
+	if status = c.treeConnect(`\\127.0.0.1\DATA`); status != statusSuccess {
+		t.Fatalf("connect to data: %x", status)
+	}
+
+	// write a file
+	status, fileId := c.create(`hello.txt`, genericWrite, fileOverwriteIf, fileNonDirectoryFile)
+	if status != statusSuccess {
+		t.Fatalf("create: %x", status)
+	}
+	if status = c.write(fileId, 0, []byte("hello world")); status != statusSuccess {
+		t.Fatalf("write: %x", status)
+	}
+	if status = c.write(fileId, 6, []byte("there")); status != statusSuccess {
+		t.Fatalf("write: %x", status)
+	}
+	c.close(fileId)
+
+	// read it back
+	status, fileId = c.create(`hello.txt`, 0x1, fileOpen, 0)
+	if status != statusSuccess {
+		t.Fatalf("open: %x", status)
+	}
+	status, data := c.read(fileId, 0, 100)
+	if status != statusSuccess || string(data) != "hello there" {
+		t.Fatalf("read: %x %q", status, data)
+	}
+	if status, _ = c.read(fileId, 11, 100); status != statusEndOfFile {
+		t.Fatalf("read after the end: %x", status)
+	}
+	c.close(fileId)
+
+	if status, _ = c.create(`missing\hello.txt`, 0x1, fileOpenIf, 0); status != statusObjectPathNotFound {
+		t.Fatalf("create in missing directory: %x", status)
+	}
+
+	// make a directory, and move the file into it
+	status, fileId = c.create(`sub`, 0x1, fileCreate, fileDirectoryFile)
+	if status != statusSuccess {
+		t.Fatalf("mkdir: %x", status)
+	}
+	c.close(fileId)
+	status, fileId = c.create(`hello.txt`, accessDelete, fileOpen, 0)
+	if status != statusSuccess {
+		t.Fatalf("open: %x", status)
+	}
+	newName := encodeUtf16(`sub\hi.txt`)
+	rename := make([]byte, 20+len(newName))
+	le.PutUint32(rename[16:], uint32(len(newName)))
+	copy(rename[20:], newName)
+	if status = c.setInfo(fileId, fileRenameInformation, rename); status != statusSuccess {
+		t.Fatalf("rename: %x", status)
+	}
+	c.close(fileId)
+
+	// list the root directory
+	status, fileId = c.create(``, 0x1, fileOpen, fileDirectoryFile)
+	if status != statusSuccess {
+		t.Fatalf("open root: %x", status)
+	}
+	status, names := c.queryDirectory(fileId, "*")
+	if status != statusSuccess || len(names) != 3 || names[0] != "." || names[1] != ".." || names[2] != "sub" {
+		t.Fatalf("list: %x %v", status, names)
+	}
+	if status, _ = c.queryDirectory(fileId, "*"); status != statusNoMoreFiles {
+		t.Fatalf("list again: %x", status)
+	}
+	c.close(fileId)
+
+	// a non-empty directory can not be deleted
+	status, fileId = c.create(`sub`, accessDelete, fileOpen, fileDirectoryFile)
+	if status != statusSuccess {
+		t.Fatalf("open sub: %x", status)
+	}
+	if status = c.setInfo(fileId, fileDispositionInformation, []byte{1}); status != statusDirectoryNotEmpty {
+		t.Fatalf("delete non-empty directory: %x", status)
+	}
+	c.close(fileId)
+
+	// compounded create, query info and close, as sent by Windows
+	statuses, bodies := c.compound(
+		testCommand{smb2Create, createRequest(`sub\hi.txt`, 0x1, fileOpen, 0)},
+		testCommand{smb2QueryInfo, queryInfoRequest(0xFFFFFFFFFFFFFFFF, infoFile, fileStandardInformation)},
+		testCommand{smb2Close, closeRequest(0xFFFFFFFFFFFFFFFF)},
+	)
+	for i, s := range statuses {
+		if s != statusSuccess {
+			t.Fatalf("compounded command %d: %x", i, s)
+		}
+	}
+	if size := le.Uint64(bodies[1][8+8:]); size != 11 {
+		t.Fatalf("file size %d", size)
+	}
+
+	// delete on close
+	status, fileId = c.create(`sub\hi.txt`, accessDelete, fileOpen, fileDeleteOnClose)
+	if status != statusSuccess {
+		t.Fatalf("open to delete: %x", status)
+	}
+	c.close(fileId)
+	if status, _ = c.create(`sub\hi.txt`, 0x1, fileOpen, 0); status != statusObjectNameNotFound {
+		t.Fatalf("open deleted file: %x", status)
+	}
+}
+
+type testClient struct {
+	conn      net.Conn
+	messageId uint64
+	sessionId uint64
+	treeId    uint32
+}
+
+type testCommand struct {
+	command uint16
+	body    []byte
+}
+
+func dialTestClient(address string) (*testClient, error) {
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	return &testClient{conn: conn}, nil
+}
+
+func (c *testClient) call(command uint16, body []byte) (status uint32, responseBody []byte) {
+	statuses, bodies := c.compound(testCommand{command, body})
+	return statuses[0], bodies[0]
+}
+
+func (c *testClient) compound(commands ...testCommand) (statuses []uint32, bodies [][]byte) {
+	var message []byte
+	for i, command := range commands {
+		h := smb2Header{
+			Command:   command.command,
+			Credits:   1,
+			MessageId: c.messageId,
+			TreeId:    c.treeId,
+			SessionId: c.sessionId,
+		}
+		if i > 0 {
+			h.Flags = smb2FlagsRelatedOperations
+		}
+		c.messageId++
+		one := make([]byte, smb2HeaderSize+len(command.body))
+		h.encode(one)
+		copy(one[smb2HeaderSize:], command.body)
+		if i < len(commands)-1 {
+			one = append(one, make([]byte, align8(len(one))-len(one))...)
+			le.PutUint32(one[20:], uint32(len(one)))
+		}
+		message = append(message, one...)
+	}
+	if err := writeTransportMessage(c.conn, message); err != nil {
+		panic(err)
+	}
+	response, err := readTransportMessage(c.conn)
+	if err != nil {
+		panic(err)
+	}
+	for len(response) > 0 {
+		h, _ := decodeSmb2Header(response)
+		one := response
+		if h.NextCommand > 0 {
+			one, response = response[:h.NextCommand], response[h.NextCommand:]
+		} else {
+			response = nil
+		}
+		statuses = append(statuses, h.Status)
+		bodies = append(bodies, one[smb2HeaderSize:])
+		if h.Command == smb2SessionSetup {
+			c.sessionId = h.SessionId
+		}
+		if h.Command == smb2TreeConnect && h.Status == statusSuccess {
+			c.treeId = h.TreeId
+		}
+	}
+	return
+}
+
+func (c *testClient) logon(user, password string) uint32 {
+	c.sessionId = 0
+
+	negotiateMessage := make([]byte, 32)
+	copy(negotiateMessage, ntlmSignature)
+	le.PutUint32(negotiateMessage[8:], ntlmNegotiateMessage)
+	le.PutUint32(negotiateMessage[12:], ntlmNegotiateUnicode|ntlmNegotiateNtlm|ntlmNegotiateExtendedSessionSecurity)
+	token := derEncode(0x60,
+		derEncode(0x06, oidSpnego),
+		derEncode(0xa0,
+			derEncode(0x30,
+				derEncode(0xa0, derEncode(0x30, derEncode(0x06, oidNtlmssp))),
+				derEncode(0xa2, derEncode(0x04, negotiateMessage)))))
+	status, body := c.call(smb2SessionSetup, sessionSetupRequest(token))
+	if status != statusMoreProcessingRequired {
+		return status
+	}
+	challengeMessage, _, err := unwrapSecurityToken(slice(body, int(le.Uint16(body[4:]))-smb2HeaderSize, int(le.Uint16(body[6:]))))
+	if err != nil {
+		panic(err)
+	}
+	serverChallenge := challengeMessage[24:32]
+
+	blob := append([]byte{1, 1, 0, 0, 0, 0, 0, 0}, make([]byte, 8+8+4)...)
+	blob = append(blob, getNtlmField(challengeMessage, 40)...)
+	blob = append(blob, 0, 0, 0, 0)
+	responseKeyNt := ntowfv2(password, user, "WORKGROUP")
+	ntProofStr, _ := ntlmv2Response(responseKeyNt, serverChallenge, blob)
+	ntResponse := append(ntProofStr, blob...)
+
+	userName, domain := encodeUtf16(user), encodeUtf16("WORKGROUP")
+	authenticateMessage := make([]byte, 64)
+	copy(authenticateMessage, ntlmSignature)
+	le.PutUint32(authenticateMessage[8:], ntlmAuthenticateMessage)
+	putNtlmField(authenticateMessage[20:], len(ntResponse), 64)
+	putNtlmField(authenticateMessage[28:], len(domain), 64+len(ntResponse))
+	putNtlmField(authenticateMessage[36:], len(userName), 64+len(ntResponse)+len(domain))
+	authenticateMessage = append(authenticateMessage, ntResponse...)
+	authenticateMessage = append(authenticateMessage, domain...)
+	authenticateMessage = append(authenticateMessage, userName...)
+
+	status, _ = c.call(smb2SessionSetup, sessionSetupRequest(derEncode(0xa1, derEncode(0x30,
+		derEncode(0xa2, derEncode(0x04, authenticateMessage))))))
+	return status
+}
+
+func sessionSetupRequest(token []byte) []byte {
+	b := make([]byte, 24+len(token))
+	le.PutUint16(b[0:], 25)
+	le.PutUint16(b[12:], smb2HeaderSize+24)
+	le.PutUint16(b[14:], uint16(len(token)))
+	copy(b[24:], token)
+	return b
+}
+
+func (c *testClient) treeConnect(uncPath string) uint32 {
+	p := encodeUtf16(uncPath)
+	b := make([]byte, 8+len(p))
+	le.PutUint16(b[0:], 9)
+	le.PutUint16(b[4:], smb2HeaderSize+8)
+	le.PutUint16(b[6:], uint16(len(p)))
+	copy(b[8:], p)
+	status, _ := c.call(smb2TreeConnect, b)
+	return status
+}
+
+func createRequest(name string, desiredAccess, disposition, options uint32) []byte {
+	n := encodeUtf16(name)
+	b := make([]byte, 56+len(n))
+	le.PutUint16(b[0:], 57)
+	le.PutUint32(b[24:], desiredAccess)
+	le.PutUint32(b[36:], disposition)
+	le.PutUint32(b[40:], options)
+	le.PutUint16(b[44:], smb2HeaderSize+56)
+	le.PutUint16(b[46:], uint16(len(n)))
+	copy(b[56:], n)
+	return b
+}
+
+func (c *testClient) create(name string, desiredAccess, disposition, options uint32) (status uint32, fileId uint64) {
+	status, body := c.call(smb2Create, createRequest(name, desiredAccess, disposition, options))
+	if status == statusSuccess {
+		fileId = readFileId(body[64:])
+	}
+	return
+}
+
+func closeRequest(fileId uint64) []byte {
+	b := make([]byte, 24)
+	le.PutUint16(b[0:], 24)
+	putFileId(b[8:], fileId)
+	return b
+}
+
+func (c *testClient) close(fileId uint64) {
+	c.call(smb2Close, closeRequest(fileId))
+}
+
+func (c *testClient) write(fileId uint64, offset uint64, data []byte) uint32 {
+	b := make([]byte, 48+len(data))
+	le.PutUint16(b[0:], 49)
+	le.PutUint16(b[2:], smb2HeaderSize+48)
+	le.PutUint32(b[4:], uint32(len(data)))
+	le.PutUint64(b[8:], offset)
+	putFileId(b[16:], fileId)
+	copy(b[48:], data)
+	status, _ := c.call(smb2Write, b)
+	return status
+}
+
+func (c *testClient) read(fileId uint64, offset uint64, length uint32) (uint32, []byte) {
+	b := make([]byte, 49)
+	le.PutUint16(b[0:], 49)
+	le.PutUint32(b[4:], length)
+	le.PutUint64(b[8:], offset)
+	putFileId(b[16:], fileId)
+	status, body := c.call(smb2Read, b)
+	if status != statusSuccess {
+		return status, nil
+	}
+	return status, slice(body, int(body[2])-smb2HeaderSize, int(le.Uint32(body[4:])))
+}
+
+func (c *testClient) setInfo(fileId uint64, infoClass byte, buffer []byte) uint32 {
+	b := make([]byte, 32+len(buffer))
+	le.PutUint16(b[0:], 33)
+	b[2] = infoFile
+	b[3] = infoClass
+	le.PutUint32(b[4:], uint32(len(buffer)))
+	le.PutUint16(b[8:], smb2HeaderSize+32)
+	putFileId(b[16:], fileId)
+	copy(b[32:], buffer)
+	status, _ := c.call(smb2SetInfo, b)
+	return status
+}
+
+func queryInfoRequest(fileId uint64, infoType, infoClass byte) []byte {
+	b := make([]byte, 40)
+	le.PutUint16(b[0:], 41)
+	b[2] = infoType
+	b[3] = infoClass
+	le.PutUint32(b[4:], 4096)
+	putFileId(b[24:], fileId)
+	return b
+}
+
+func (c *testClient) queryDirectory(fileId uint64, pattern string) (status uint32, names []string) {
+	p := encodeUtf16(pattern)
+	b := make([]byte, 32+len(p))
+	le.PutUint16(b[0:], 33)
+	b[2] = fileIdBothDirectoryInformation
+	putFileId(b[8:], fileId)
+	le.PutUint16(b[24:], smb2HeaderSize+32)
+	le.PutUint16(b[26:], uint16(len(p)))
+	le.PutUint32(b[28:], 65536)
+	copy(b[32:], p)
+	status, body := c.call(smb2QueryDirectory, b)
+	if status != statusSuccess {
+		return
+	}
+	output := slice(body, 8, int(le.Uint32(body[4:])))
+	for {
+		nameLength := int(le.Uint32(output[60:]))
+		names = append(names, decodeUtf16(output[104:104+nameLength]))
+		next := le.Uint32(output[0:])
+		if next == 0 {
+			break
+		}
+		output = output[next:]
+	}
+	return
+}
+
+func TestSigning(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 16)
+	message := make([]byte, smb2HeaderSize+4)
+	(&smb2Header{Command: smb2Echo, Flags: smb2FlagsSigned}).encode(message)
+	signMessage(message, key)
+	if !verifySignature(message, key) {
+		t.Fatalf("signature is not verified")
+	}
+	message[smb2HeaderSize] = 1
+	if verifySignature(message, key) {
+		t.Fatalf("changed message is verified")
+	}
+}
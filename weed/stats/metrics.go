@@ -13,6 +13,7 @@ import (
 var (
 	FilerGather        = prometheus.NewRegistry()
 	VolumeServerGather = prometheus.NewRegistry()
+	MasterGather       = prometheus.NewRegistry()
 
 	FilerRequestCounter = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -48,6 +49,30 @@ var (
 			Buckets:   prometheus.ExponentialBuckets(0.0001, 2, 24),
 		}, []string{"store", "type"})
 
+	FilerStoreErrorCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "SeaweedFS",
+			Subsystem: "filerStore",
+			Name:      "error_total",
+			Help:      "Counter of failed filer store requests, not counting the entries not found.",
+		}, []string{"store", "type"})
+
+	FilerStoreBackendGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "SeaweedFS",
+			Subsystem: "filerStore",
+			Name:      "backend",
+			Help:      "Backend specific stats of the filer store.",
+		}, []string{"store", "name"})
+
+	FilerRequestConcurrencyGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "SeaweedFS",
+			Subsystem: "filer",
+			Name:      "request_concurrency",
+			Help:      "Number of filer http requests running or queued by the concurrency limits.",
+		}, []string{"type"})
+
 	VolumeServerRequestCounter = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: "SeaweedFS",
@@ -88,6 +113,38 @@ var (
 			Name:      "total_disk_size",
 			Help:      "Actual disk size used by volumes.",
 		}, []string{"collection", "type"})
+
+	VolumeServerNeedleCorruptionCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "SeaweedFS",
+			Subsystem: "volumeServer",
+			Name:      "replicated_needle_corruption_total",
+			Help:      "Counter of corrupted needles detected, repaired by reading again, or rejected when tailing volumes, and of the volume copies rejected for corrupted needles.",
+		}, []string{"type"})
+
+	MasterVolumeServerQuarantineCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "SeaweedFS",
+			Subsystem: "master",
+			Name:      "volume_server_quarantine_total",
+			Help:      "Counter of the flapping volume servers quarantined, or cleared by the operators.",
+		}, []string{"type"})
+
+	MasterVolumeServerDaysToFullGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "SeaweedFS",
+			Subsystem: "master",
+			Name:      "volume_server_days_to_full",
+			Help:      "Forecasted days until the volume server is full, or 0 if not growing.",
+		}, []string{"server"})
+
+	MasterVolumeServerGrowthGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "SeaweedFS",
+			Subsystem: "master",
+			Name:      "volume_server_growth_bytes_per_day",
+			Help:      "Forecasted growth of the used bytes of the volume server per day.",
+		}, []string{"server"})
 )
 
 func init() {
@@ -96,6 +153,9 @@ func init() {
 	FilerGather.MustRegister(FilerRequestHistogram)
 	FilerGather.MustRegister(FilerStoreCounter)
 	FilerGather.MustRegister(FilerStoreHistogram)
+	FilerGather.MustRegister(FilerStoreErrorCounter)
+	FilerGather.MustRegister(FilerStoreBackendGauge)
+	FilerGather.MustRegister(FilerRequestConcurrencyGauge)
 	FilerGather.MustRegister(prometheus.NewGoCollector())
 
 	VolumeServerGather.MustRegister(VolumeServerRequestCounter)
@@ -103,6 +163,11 @@ func init() {
 	VolumeServerGather.MustRegister(VolumeServerVolumeCounter)
 	VolumeServerGather.MustRegister(VolumeServerMaxVolumeCounter)
 	VolumeServerGather.MustRegister(VolumeServerDiskSizeGauge)
+	VolumeServerGather.MustRegister(VolumeServerNeedleCorruptionCounter)
+
+	MasterGather.MustRegister(MasterVolumeServerQuarantineCounter)
+	MasterGather.MustRegister(MasterVolumeServerDaysToFullGauge)
+	MasterGather.MustRegister(MasterVolumeServerGrowthGauge)
 
 }
 
@@ -0,0 +1,128 @@
+package cloud_backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/operation"
+	"github.com/chrislusf/seaweedfs/weed/pb/volume_server_pb"
+	"google.golang.org/grpc"
+)
+
+// BackupVolume uploads the .dat and .idx data appended to the volume since its last backup,
+// or the full volume files when starting a new backup generation, and then updates the manifest.
+// The volume keeps being backed up from the same server among its replicas, since the replicas
+// are not identical byte by byte.
+func BackupVolume(ctx context.Context, store ObjectStore, prefix string, servers []string, grpcDialOption grpc.DialOption, collection string, vid uint32) (m *Manifest, segment *Segment, err error) {
+
+	if len(servers) == 0 {
+		return nil, nil, fmt.Errorf("volume %d not found on any server", vid)
+	}
+
+	last, err := ReadManifest(ctx, store, prefix, collection, vid)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read volume %d manifest: %v", vid, err)
+	}
+
+	server := servers[0]
+	if last != nil {
+		for _, s := range servers {
+			if s == last.Server {
+				server = s
+			}
+		}
+	}
+
+	status, err := operation.GetVolumeSyncStatus(server, grpcDialOption, vid)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get volume %d status from %s: %v", vid, server, err)
+	}
+
+	m, next, hasNewData := planBackup(last, server, status)
+	if !hasNewData {
+		return m, nil, nil
+	}
+
+	index := len(m.Segments)
+	if err = copyToStore(ctx, store, segmentKey(prefix, m, index, ".dat"), server, grpcDialOption, m, ".dat", next.DatOffset, next.DatSize); err != nil {
+		return nil, nil, err
+	}
+	if err = copyToStore(ctx, store, segmentKey(prefix, m, index, ".idx"), server, grpcDialOption, m, ".idx", next.IdxOffset, next.IdxSize); err != nil {
+		return nil, nil, err
+	}
+
+	m.Segments = append(m.Segments, next)
+	if err = writeManifest(ctx, store, prefix, m); err != nil {
+		return nil, nil, fmt.Errorf("write volume %d manifest: %v", vid, err)
+	}
+
+	// the earlier generation is no longer referenced once the new manifest is written
+	if last != nil && last.Generation != m.Generation {
+		deleteGeneration(ctx, store, prefix, last)
+	}
+
+	return m, &next, nil
+}
+
+// copyToStore streams the volume file from the offset on the volume server to the object
+func copyToStore(ctx context.Context, store ObjectStore, key string, server string, grpcDialOption grpc.DialOption, m *Manifest, ext string, offset, size uint64) error {
+
+	if size == 0 {
+		return nil
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(operation.WithVolumeServerClient(server, grpcDialOption, func(client volume_server_pb.VolumeServerClient) error {
+			stream, err := client.CopyFile(ctx, &volume_server_pb.CopyFileRequest{
+				VolumeId:           m.VolumeId,
+				Ext:                ext,
+				CompactionRevision: m.CompactRevision,
+				StartOffset:        offset,
+				StopOffset:         offset + size,
+				Collection:         m.Collection,
+			})
+			if err != nil {
+				return err
+			}
+			var copied uint64
+			for {
+				resp, receiveErr := stream.Recv()
+				if receiveErr == io.EOF {
+					break
+				}
+				if receiveErr != nil {
+					return receiveErr
+				}
+				if _, err = writer.Write(resp.FileContent); err != nil {
+					return err
+				}
+				copied += uint64(len(resp.FileContent))
+			}
+			if copied != size {
+				return fmt.Errorf("copied %d bytes, expected %d bytes", copied, size)
+			}
+			return nil
+		}))
+	}()
+
+	err := store.PutObject(ctx, key, reader)
+	// stop the copying if the upload failed
+	reader.CloseWithError(err)
+	if err != nil {
+		return fmt.Errorf("backup volume %d%s from %s: %v", m.VolumeId, ext, server, err)
+	}
+	return nil
+}
+
+func deleteGeneration(ctx context.Context, store ObjectStore, prefix string, m *Manifest) {
+	for index := range m.Segments {
+		for _, ext := range []string{".dat", ".idx"} {
+			if err := store.DeleteObject(ctx, segmentKey(prefix, m, index, ext)); err != nil {
+				glog.Warningf("delete volume %d backup generation %d: %v", m.VolumeId, m.Generation, err)
+			}
+		}
+	}
+}
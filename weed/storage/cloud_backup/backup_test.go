@@ -0,0 +1,191 @@
+package cloud_backup
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/chrislusf/seaweedfs/weed/pb/volume_server_pb"
+	"github.com/chrislusf/seaweedfs/weed/storage"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+	"github.com/chrislusf/seaweedfs/weed/storage/types"
+)
+
+type memStore map[string][]byte
+
+func (s memStore) PutObject(ctx context.Context, key string, reader io.Reader) error {
+	data, err := ioutil.ReadAll(reader)
+	s[key] = data
+	return err
+}
+
+func (s memStore) GetObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	data, found := s[key]
+	if !found {
+		return nil, ErrObjectNotFound
+	}
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s memStore) DeleteObject(ctx context.Context, key string) error {
+	delete(s, key)
+	return nil
+}
+
+func (s memStore) ListObjects(ctx context.Context, prefix string, fn func(key string) error) error {
+	var keys []string
+	for key := range s {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := fn(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestPlanBackup(t *testing.T) {
+	status := &volume_server_pb.VolumeSyncStatusResponse{VolumeId: 3, Collection: "pics", Replication: "001", TailOffset: 100, IdxFileSize: 32, CompactRevision: 1}
+
+	m, segment, hasNewData := planBackup(nil, "s1:8080", status)
+	if !hasNewData || m.Generation != 1 || segment.DatOffset != 0 || segment.DatSize != 100 || segment.IdxSize != 32 {
+		t.Fatalf("first backup: %+v %+v", m, segment)
+	}
+	m.Segments = append(m.Segments, segment)
+
+	if _, _, hasNewData = planBackup(m, "s1:8080", status); hasNewData {
+		t.Errorf("unchanged volume should not be backed up")
+	}
+
+	status.TailOffset, status.IdxFileSize = 150, 48
+	next, segment, hasNewData := planBackup(m, "s1:8080", status)
+	if !hasNewData || next != m || segment.DatOffset != 100 || segment.DatSize != 50 || segment.IdxOffset != 32 || segment.IdxSize != 16 {
+		t.Errorf("incremental backup: %+v", segment)
+	}
+
+	for name, server := range map[string]string{"moved": "s2:8080", "same": "s1:8080"} {
+		if name == "same" {
+			status.CompactRevision = 2
+		}
+		next, segment, _ = planBackup(m, server, status)
+		if next == m || next.Generation != 2 || next.Server != server || segment.DatOffset != 0 || segment.DatSize != 150 {
+			t.Errorf("%s: expected a new generation, got %+v %+v", name, next, segment)
+		}
+	}
+}
+
+func TestRestoreVolume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cloud_backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	datData, idxData := newTestVolumeFiles(t)
+
+	ctx := context.Background()
+	store := make(memStore)
+	m := &Manifest{VolumeId: 3, Collection: "pics", Generation: 2}
+	split := len(datData) / 2
+	store[segmentKey("backup", m, 0, ".dat")] = datData[:split]
+	store[segmentKey("backup", m, 0, ".idx")] = idxData
+	store[segmentKey("backup", m, 1, ".dat")] = datData[split:]
+	m.Segments = []Segment{
+		{DatOffset: 0, DatSize: uint64(split), IdxOffset: 0, IdxSize: uint64(len(idxData))},
+		{DatOffset: uint64(split), DatSize: uint64(len(datData) - split), IdxOffset: uint64(len(idxData)), IdxSize: 0},
+	}
+	if err = writeManifest(ctx, store, "backup", m); err != nil {
+		t.Fatal(err)
+	}
+	if _, found := store["backup/pics_3/manifest.json"]; !found {
+		t.Fatalf("manifest keys: %v", store)
+	}
+	store["other/4/manifest.json"] = store["backup/pics_3/manifest.json"]
+
+	var manifests []*Manifest
+	err = ListManifests(ctx, store, "/backup/", func(m *Manifest) error {
+		manifests = append(manifests, m)
+		return nil
+	})
+	if err != nil || len(manifests) != 1 || manifests[0].Generation != 2 {
+		t.Fatalf("list manifests: %+v %v", manifests, err)
+	}
+
+	if err = RestoreVolume(ctx, store, "backup", manifests[0], dir); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	for ext, expected := range map[string][]byte{".dat": datData, ".idx": idxData} {
+		data, readErr := ioutil.ReadFile(filepath.Join(dir, "pics_3"+ext))
+		if readErr != nil || !bytes.Equal(data, expected) {
+			t.Errorf("restored %s: %d bytes %v", ext, len(data), readErr)
+		}
+	}
+	if err = RestoreVolume(ctx, store, "backup", manifests[0], dir); err == nil {
+		t.Errorf("should not overwrite the existing volume")
+	}
+	os.Remove(filepath.Join(dir, "pics_3.dat"))
+	os.Remove(filepath.Join(dir, "pics_3.idx"))
+
+	// a corrupted needle leaves no volume files
+	corrupted := append([]byte(nil), datData[split:]...)
+	corrupted[bytes.Index(corrupted, bytes.Repeat([]byte{3}, 300))] ^= 0xff
+	store[segmentKey("backup", m, 1, ".dat")] = corrupted
+	if err = RestoreVolume(ctx, store, "backup", m, dir); err == nil {
+		t.Errorf("restored the corrupted needle")
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+		t.Errorf("files left of the corrupted volume: %d", len(files))
+	}
+
+	// a missing segment leaves no partial volume files
+	delete(store, segmentKey("backup", m, 1, ".dat"))
+	if err = RestoreVolume(ctx, store, "backup", m, dir); err == nil {
+		t.Fatalf("restore should fail with a missing segment")
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+		t.Errorf("partial files left: %d", len(files))
+	}
+}
+
+// newTestVolumeFiles writes a few needles to a volume, and returns the .dat and .idx files
+func newTestVolumeFiles(t *testing.T) (datData, idxData []byte) {
+	dir, err := ioutil.TempDir("", "cloud_backup_source")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := storage.NewStore(nil, 8080, "localhost", "localhost:8080", []string{dir}, []int{10}, storage.NeedleMapInMemory)
+	if err = s.AddVolume(3, "pics", storage.NeedleMapInMemory, "000", "", 0); err != nil {
+		t.Fatalf("add volume: %v", err)
+	}
+	for i := 1; i <= 3; i++ {
+		n := new(needle.Needle)
+		n.Id = types.Uint64ToNeedleId(uint64(i))
+		n.Data = bytes.Repeat([]byte{byte(i)}, 100*i)
+		n.Checksum = needle.NewCRC(n.Data)
+		if _, _, err = s.Write(3, n); err != nil {
+			t.Fatalf("write needle %d: %v", i, err)
+		}
+	}
+	s.Close()
+
+	baseFileName := storage.VolumeFileName(dir, "pics", 3)
+	if datData, err = ioutil.ReadFile(baseFileName + ".dat"); err != nil {
+		t.Fatal(err)
+	}
+	if idxData, err = ioutil.ReadFile(baseFileName + ".idx"); err != nil {
+		t.Fatal(err)
+	}
+	return
+}
@@ -0,0 +1,73 @@
+package cloud_backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+// gcsStore finds the credentials by the GOOGLE_APPLICATION_CREDENTIALS env variable
+type gcsStore struct {
+	bucket string
+	client *storage.Client
+}
+
+func newGcsStore(bucket string) (*gcsStore, error) {
+	client, err := storage.NewClient(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("create google cloud storage client: %v", err)
+	}
+	return &gcsStore{
+		bucket: bucket,
+		client: client,
+	}, nil
+}
+
+func (g *gcsStore) PutObject(ctx context.Context, key string, reader io.Reader) error {
+	wc := g.client.Bucket(g.bucket).Object(key).NewWriter(ctx)
+	if _, err := io.Copy(wc, reader); err != nil {
+		wc.Close()
+		return fmt.Errorf("gcs put %s/%s: %v", g.bucket, key, err)
+	}
+	if err := wc.Close(); err != nil {
+		return fmt.Errorf("gcs put %s/%s: %v", g.bucket, key, err)
+	}
+	return nil
+}
+
+func (g *gcsStore) GetObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	reader, err := g.client.Bucket(g.bucket).Object(key).NewReader(ctx)
+	if err == storage.ErrObjectNotExist {
+		return nil, ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("gcs get %s/%s: %v", g.bucket, key, err)
+	}
+	return reader, nil
+}
+
+func (g *gcsStore) DeleteObject(ctx context.Context, key string) error {
+	if err := g.client.Bucket(g.bucket).Object(key).Delete(ctx); err != nil && err != storage.ErrObjectNotExist {
+		return fmt.Errorf("gcs delete %s/%s: %v", g.bucket, key, err)
+	}
+	return nil
+}
+
+func (g *gcsStore) ListObjects(ctx context.Context, prefix string, fn func(key string) error) error {
+	it := g.client.Bucket(g.bucket).Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("gcs list %s/%s: %v", g.bucket, prefix, err)
+		}
+		if err = fn(attrs.Name); err != nil {
+			return err
+		}
+	}
+}
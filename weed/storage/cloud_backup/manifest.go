@@ -0,0 +1,154 @@
+package cloud_backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/pb/volume_server_pb"
+	"github.com/chrislusf/seaweedfs/weed/storage"
+)
+
+const manifestFileName = "manifest.json"
+
+// Manifest lists the segments of the current backup generation of a volume.
+// A generation starts with the full volume files copied from one volume server at one compaction revision,
+// and each later backup appends a segment with the .dat and .idx data appended since.
+type Manifest struct {
+	VolumeId        uint32    `json:"volumeId"`
+	Collection      string    `json:"collection"`
+	Replication     string    `json:"replication"`
+	Ttl             string    `json:"ttl"`
+	Server          string    `json:"server"`
+	CompactRevision uint32    `json:"compactRevision"`
+	Generation      uint32    `json:"generation"`
+	Segments        []Segment `json:"segments"`
+}
+
+type Segment struct {
+	DatOffset  uint64    `json:"datOffset"`
+	DatSize    uint64    `json:"datSize"`
+	IdxOffset  uint64    `json:"idxOffset"`
+	IdxSize    uint64    `json:"idxSize"`
+	BackedUpAt time.Time `json:"backedUpAt"`
+}
+
+func (m *Manifest) DatFileSize() uint64 {
+	if len(m.Segments) == 0 {
+		return 0
+	}
+	last := m.Segments[len(m.Segments)-1]
+	return last.DatOffset + last.DatSize
+}
+
+func (m *Manifest) IdxFileSize() uint64 {
+	if len(m.Segments) == 0 {
+		return 0
+	}
+	last := m.Segments[len(m.Segments)-1]
+	return last.IdxOffset + last.IdxSize
+}
+
+// planBackup continues the generation of the manifest with a segment of the newly appended data,
+// or starts a new generation if the volume is compacted, backed up from another server, or reconfigured.
+// hasNewData is false if nothing is appended since the last backup.
+func planBackup(m *Manifest, server string, status *volume_server_pb.VolumeSyncStatusResponse) (next *Manifest, segment Segment, hasNewData bool) {
+	if m != nil && m.Server == server &&
+		m.CompactRevision == status.CompactRevision &&
+		m.Replication == status.Replication && m.Ttl == status.Ttl &&
+		m.DatFileSize() <= status.TailOffset && m.IdxFileSize() <= status.IdxFileSize {
+		next = m
+	} else {
+		next = &Manifest{
+			VolumeId:        status.VolumeId,
+			Collection:      status.Collection,
+			Replication:     status.Replication,
+			Ttl:             status.Ttl,
+			Server:          server,
+			CompactRevision: status.CompactRevision,
+			Generation:      1,
+		}
+		if m != nil {
+			next.Generation = m.Generation + 1
+		}
+	}
+	segment = Segment{
+		DatOffset:  next.DatFileSize(),
+		DatSize:    status.TailOffset - next.DatFileSize(),
+		IdxOffset:  next.IdxFileSize(),
+		IdxSize:    status.IdxFileSize - next.IdxFileSize(),
+		BackedUpAt: time.Now(),
+	}
+	hasNewData = next != m || segment.DatSize > 0 || segment.IdxSize > 0
+	return
+}
+
+// volumeKeyPrefix is like "prefix/collection_vid/", the same as the volume file names
+func volumeKeyPrefix(prefix, collection string, vid uint32) string {
+	return path.Join(strings.Trim(prefix, "/"), storage.VolumeFileName("", collection, int(vid))) + "/"
+}
+
+func manifestKey(prefix, collection string, vid uint32) string {
+	return volumeKeyPrefix(prefix, collection, vid) + manifestFileName
+}
+
+// segmentKey is like "prefix/collection_vid/generation/index.dat"
+func segmentKey(prefix string, m *Manifest, index int, ext string) string {
+	return fmt.Sprintf("%s%d/%d%s", volumeKeyPrefix(prefix, m.Collection, m.VolumeId), m.Generation, index, ext)
+}
+
+// ReadManifest returns nil if the volume is not backed up yet
+func ReadManifest(ctx context.Context, store ObjectStore, prefix, collection string, vid uint32) (*Manifest, error) {
+	return readManifest(ctx, store, manifestKey(prefix, collection, vid))
+}
+
+func readManifest(ctx context.Context, store ObjectStore, key string) (*Manifest, error) {
+	reader, err := store.GetObject(ctx, key)
+	if err == ErrObjectNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %v", key, err)
+	}
+	m := &Manifest{}
+	if err = json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("parse %s: %v", key, err)
+	}
+	return m, nil
+}
+
+func writeManifest(ctx context.Context, store ObjectStore, prefix string, m *Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return store.PutObject(ctx, manifestKey(prefix, m.Collection, m.VolumeId), strings.NewReader(string(data)))
+}
+
+// ListManifests visits the manifests of all the volumes backed up under the prefix
+func ListManifests(ctx context.Context, store ObjectStore, prefix string, fn func(m *Manifest) error) error {
+	listPrefix, parentDir := "", "."
+	if prefix = strings.Trim(prefix, "/"); prefix != "" {
+		listPrefix, parentDir = prefix+"/", prefix
+	}
+	return store.ListObjects(ctx, listPrefix, func(key string) error {
+		// only the manifests directly under the volume folders
+		if path.Base(key) != manifestFileName || path.Dir(path.Dir(key)) != parentDir {
+			return nil
+		}
+		m, err := readManifest(ctx, store, key)
+		if err != nil || m == nil {
+			return err
+		}
+		return fn(m)
+	})
+}
@@ -0,0 +1,30 @@
+package cloud_backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// ObjectStore keeps the backed up volume files and the manifests as objects in a cloud bucket
+type ObjectStore interface {
+	PutObject(ctx context.Context, key string, reader io.Reader) error
+	// GetObject returns ErrObjectNotFound if the key does not exist
+	GetObject(ctx context.Context, key string) (io.ReadCloser, error)
+	DeleteObject(ctx context.Context, key string) error
+	ListObjects(ctx context.Context, prefix string, fn func(key string) error) error
+}
+
+var ErrObjectNotFound = fmt.Errorf("object not found")
+
+// NewObjectStore connects to the bucket on "s3", or "gcs" for google cloud storage.
+// The s3 endpoint is only needed for the s3 compatible services other than aws.
+func NewObjectStore(storageType, bucket, region, endpoint string) (ObjectStore, error) {
+	switch storageType {
+	case "s3":
+		return newS3Store(bucket, region, endpoint)
+	case "gcs":
+		return newGcsStore(bucket)
+	}
+	return nil, fmt.Errorf("unknown cloud storage type %q, expecting s3 or gcs", storageType)
+}
@@ -0,0 +1,102 @@
+package cloud_backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/chrislusf/seaweedfs/weed/storage"
+	"github.com/chrislusf/seaweedfs/weed/util"
+)
+
+// RestoreVolume rebuilds the .dat and .idx files of the volume in the directory,
+// by concatenating the segments of the backup generation in the manifest.
+// The crc of the restored needles is verified before the files are shown.
+// The volume server loads the restored volume when started on the directory.
+func RestoreVolume(ctx context.Context, store ObjectStore, prefix string, m *Manifest, dir string) error {
+
+	baseFileName := storage.VolumeFileName(dir, m.Collection, int(m.VolumeId))
+	for _, ext := range []string{".dat", ".idx"} {
+		if util.FileExists(baseFileName + ext) {
+			return fmt.Errorf("restore volume %d: %s%s already exists", m.VolumeId, baseFileName, ext)
+		}
+	}
+
+	// only show the volume files after both are fully restored
+	var restored []string
+	for _, ext := range []string{".dat", ".idx"} {
+		tmpFileName := baseFileName + ext + ".restoring"
+		restored = append(restored, tmpFileName)
+		if err := restoreFile(ctx, store, prefix, m, ext, tmpFileName); err != nil {
+			for _, fileName := range restored {
+				os.Remove(fileName)
+			}
+			return fmt.Errorf("restore volume %d%s: %v", m.VolumeId, ext, err)
+		}
+	}
+	if err := storage.VerifyVolumeFiles(restored[0], restored[1]); err != nil {
+		for _, fileName := range restored {
+			os.Remove(fileName)
+		}
+		return fmt.Errorf("restore volume %d: %v", m.VolumeId, err)
+	}
+	for i, ext := range []string{".dat", ".idx"} {
+		if err := os.Rename(restored[i], baseFileName+ext); err != nil {
+			return fmt.Errorf("restore volume %d%s: %v", m.VolumeId, ext, err)
+		}
+	}
+
+	return nil
+}
+
+func restoreFile(ctx context.Context, store ObjectStore, prefix string, m *Manifest, ext string, fileName string) error {
+
+	dst, err := os.OpenFile(fileName, os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	var written uint64
+	for index, segment := range m.Segments {
+		offset, size := segment.DatOffset, segment.DatSize
+		if ext == ".idx" {
+			offset, size = segment.IdxOffset, segment.IdxSize
+		}
+		if offset != written {
+			err = fmt.Errorf("segment %d starts at %d, expected %d", index, offset, written)
+			break
+		}
+		if size == 0 {
+			continue
+		}
+		if err = restoreSegment(ctx, store, segmentKey(prefix, m, index, ext), dst, size); err != nil {
+			break
+		}
+		written += size
+	}
+
+	if err == nil {
+		err = dst.Sync()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func restoreSegment(ctx context.Context, store ObjectStore, key string, dst io.Writer, size uint64) error {
+	reader, err := store.GetObject(ctx, key)
+	if err != nil {
+		return fmt.Errorf("get %s: %v", key, err)
+	}
+	defer reader.Close()
+	n, err := io.Copy(dst, reader)
+	if err != nil {
+		return fmt.Errorf("download %s: %v", key, err)
+	}
+	if uint64(n) != size {
+		return fmt.Errorf("downloaded %d bytes from %s, expected %d bytes", n, key, size)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package cloud_backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+type s3Store struct {
+	bucket   string
+	conn     *s3.S3
+	uploader *s3manager.Uploader
+}
+
+func newS3Store(bucket, region, endpoint string) (*s3Store, error) {
+	config := &aws.Config{
+		Region: aws.String(region),
+	}
+	if endpoint != "" {
+		config.Endpoint = aws.String(endpoint)
+		config.S3ForcePathStyle = aws.Bool(true)
+	}
+	if accessKey, secretKey := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); accessKey != "" && secretKey != "" {
+		config.Credentials = credentials.NewStaticCredentials(accessKey, secretKey, "")
+	}
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, fmt.Errorf("create aws session: %v", err)
+	}
+	return &s3Store{
+		bucket:   bucket,
+		conn:     s3.New(sess),
+		uploader: s3manager.NewUploader(sess),
+	}, nil
+}
+
+func (s *s3Store) PutObject(ctx context.Context, key string, reader io.Reader) error {
+	// the multipart uploader streams the volume files without knowing the size ahead
+	_, err := s.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+		Body:   reader,
+	})
+	if err != nil {
+		return fmt.Errorf("s3 put %s/%s: %v", s.bucket, key, err)
+	}
+	return nil
+}
+
+func (s *s3Store) GetObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	out, err := s.conn.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchKey {
+			return nil, ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("s3 get %s/%s: %v", s.bucket, key, err)
+	}
+	return out.Body, nil
+}
+
+func (s *s3Store) DeleteObject(ctx context.Context, key string) error {
+	_, err := s.conn.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("s3 delete %s/%s: %v", s.bucket, key, err)
+	}
+	return nil
+}
+
+func (s *s3Store) ListObjects(ctx context.Context, prefix string, fn func(key string) error) error {
+	var fnErr error
+	err := s.conn.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			if fnErr = fn(aws.StringValue(object.Key)); fnErr != nil {
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("s3 list %s/%s: %v", s.bucket, prefix, err)
+	}
+	return fnErr
+}
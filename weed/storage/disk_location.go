@@ -35,14 +35,15 @@ func (l *DiskLocation) volumeIdFromPath(dir os.FileInfo) (needle.VolumeId, strin
 	name := dir.Name()
 	if !dir.IsDir() && strings.HasSuffix(name, ".dat") {
 		base := name[:len(name)-len(".dat")]
-		collection, volumeId, err := parseCollectionVolumeId(base)
+		collection, volumeId, err := ParseCollectionVolumeId(base)
 		return volumeId, collection, err
 	}
 
 	return 0, "", fmt.Errorf("Path is not a volume: %s", name)
 }
 
-func parseCollectionVolumeId(base string) (collection string, vid needle.VolumeId, err error) {
+// ParseCollectionVolumeId parses the base name of the volume files, like "collection_123" or "123"
+func ParseCollectionVolumeId(base string) (collection string, vid needle.VolumeId, err error) {
 	i := strings.LastIndex(base, "_")
 	if i > 0 {
 		collection, base = base[0:i], base[i+1:]
@@ -155,6 +156,19 @@ func (l *DiskLocation) deleteVolumeById(vid needle.VolumeId) (e error) {
 	return
 }
 
+// deleteEmptyVolumeById deletes the volume made read only by Store.DeleteEmptyVolume
+func (l *DiskLocation) deleteEmptyVolumeById(vid needle.VolumeId) error {
+	l.Lock()
+	defer l.Unlock()
+	v, ok := l.volumes[vid]
+	if !ok {
+		return fmt.Errorf("Volume not found, VolumeId: %d", vid)
+	}
+	v.destroy()
+	delete(l.volumes, vid)
+	return nil
+}
+
 func (l *DiskLocation) LoadVolume(vid needle.VolumeId, needleMapKind NeedleMapType) bool {
 	if fileInfos, err := ioutil.ReadDir(l.Directory); err == nil {
 		for _, fileInfo := range fileInfos {
@@ -133,7 +133,7 @@ func (l *DiskLocation) loadAllEcShards() (err error) {
 		name := fileInfo.Name()
 		baseName := name[:len(name)-len(ext)]
 
-		collection, volumeId, err := parseCollectionVolumeId(baseName)
+		collection, volumeId, err := ParseCollectionVolumeId(baseName)
 		if err != nil {
 			continue
 		}
@@ -1,11 +1,12 @@
 package erasure_coding
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"sync"
 
-	"github.com/chrislusf/seaweedfs/weed/glog"
 	"github.com/chrislusf/seaweedfs/weed/storage/idx"
 	"github.com/chrislusf/seaweedfs/weed/storage/needle_map"
 	"github.com/chrislusf/seaweedfs/weed/storage/types"
@@ -49,20 +50,21 @@ func WriteSortedEcxFile(baseFileName string) (e error) {
 	return nil
 }
 
-// WriteEcFiles generates .ec01 ~ .ec14 files
-func WriteEcFiles(baseFileName string) error {
-	return generateEcFiles(baseFileName, 256*1024, ErasureCodingLargeBlockSize, ErasureCodingSmallBlockSize)
+// WriteEcFiles generates .ec01 ~ .ec14 files, reading the .dat file no faster than the throttler allows
+func WriteEcFiles(baseFileName string, throttler *util.BandwidthThrottler) error {
+	return generateEcFiles(baseFileName, ErasureCodingSmallBlockSize, ErasureCodingLargeBlockSize, ErasureCodingSmallBlockSize, throttler)
 }
 
-func RebuildEcFiles(baseFileName string) ([]uint32, error) {
-	return generateMissingEcFiles(baseFileName, 256*1024, ErasureCodingLargeBlockSize, ErasureCodingSmallBlockSize)
+// RebuildEcFiles generates the missing .ec01 ~ .ec14 files, reading the existing ones no faster than the throttler allows
+func RebuildEcFiles(baseFileName string, throttler *util.BandwidthThrottler) ([]uint32, error) {
+	return generateMissingEcFiles(baseFileName, 256*1024, ErasureCodingLargeBlockSize, ErasureCodingSmallBlockSize, throttler)
 }
 
 func ToExt(ecIndex int) string {
 	return fmt.Sprintf(".ec%02d", ecIndex)
 }
 
-func generateEcFiles(baseFileName string, bufferSize int, largeBlockSize int64, smallBlockSize int64) error {
+func generateEcFiles(baseFileName string, bufferSize int, largeBlockSize int64, smallBlockSize int64, throttler *util.BandwidthThrottler) error {
 	file, err := os.OpenFile(baseFileName+".dat", os.O_RDONLY, 0)
 	if err != nil {
 		return fmt.Errorf("failed to open dat file: %v", err)
@@ -73,14 +75,14 @@ func generateEcFiles(baseFileName string, bufferSize int, largeBlockSize int64,
 	if err != nil {
 		return fmt.Errorf("failed to stat dat file: %v", err)
 	}
-	err = encodeDatFile(fi.Size(), err, baseFileName, bufferSize, largeBlockSize, file, smallBlockSize)
+	err = encodeDatFile(fi.Size(), baseFileName, bufferSize, largeBlockSize, file, smallBlockSize, throttler)
 	if err != nil {
 		return fmt.Errorf("encodeDatFile: %v", err)
 	}
 	return nil
 }
 
-func generateMissingEcFiles(baseFileName string, bufferSize int, largeBlockSize int64, smallBlockSize int64) (generatedShardIds []uint32, err error) {
+func generateMissingEcFiles(baseFileName string, bufferSize int, largeBlockSize int64, smallBlockSize int64, throttler *util.BandwidthThrottler) (generatedShardIds []uint32, err error) {
 
 	shardHasData := make([]bool, TotalShardsCount)
 	inputFiles := make([]*os.File, TotalShardsCount)
@@ -104,31 +106,13 @@ func generateMissingEcFiles(baseFileName string, bufferSize int, largeBlockSize
 		}
 	}
 
-	err = rebuildEcFiles(shardHasData, inputFiles, outputFiles)
+	err = rebuildEcFiles(shardHasData, inputFiles, outputFiles, throttler)
 	if err != nil {
 		return nil, fmt.Errorf("rebuildEcFiles: %v", err)
 	}
 	return
 }
 
-func encodeData(file *os.File, enc reedsolomon.Encoder, startOffset, blockSize int64, buffers [][]byte, outputs []*os.File) error {
-
-	bufferSize := int64(len(buffers[0]))
-	batchCount := blockSize / bufferSize
-	if blockSize%bufferSize != 0 {
-		glog.Fatalf("unexpected block size %d buffer size %d", blockSize, bufferSize)
-	}
-
-	for b := int64(0); b < batchCount; b++ {
-		err := encodeDataOneBatch(file, enc, startOffset+b*bufferSize, blockSize, buffers, outputs)
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
 func openEcFiles(baseFileName string, forRead bool) (files []*os.File, err error) {
 	for i := 0; i < TotalShardsCount; i++ {
 		fname := baseFileName + ToExt(i)
@@ -153,78 +137,135 @@ func closeEcFiles(files []*os.File) {
 	}
 }
 
-func encodeDataOneBatch(file *os.File, enc reedsolomon.Encoder, startOffset, blockSize int64, buffers [][]byte, outputs []*os.File) error {
+// the number of batches being read, encoded, or written at the same time, each of TotalShardsCount buffers
+const ecEncodePipelineDepth = 4
 
-	// read data into buffers
-	for i := 0; i < DataShardsCount; i++ {
-		n, err := file.ReadAt(buffers[i], startOffset+blockSize*int64(i))
-		if err != nil {
-			if err != io.EOF {
-				return err
-			}
-		}
-		if n < len(buffers[i]) {
-			for t := len(buffers[i]) - 1; t >= n; t-- {
-				buffers[i][t] = 0
-			}
-		}
-	}
-
-	err := enc.Encode(buffers)
-	if err != nil {
-		return err
-	}
-
-	for i := 0; i < TotalShardsCount; i++ {
-		_, err := outputs[i].Write(buffers[i])
-		if err != nil {
-			return err
-		}
-	}
+var errEcEncodeStopped = errors.New("ec encoding stopped")
 
-	return nil
-}
-
-func encodeDatFile(remainingSize int64, err error, baseFileName string, bufferSize int, largeBlockSize int64, file *os.File, smallBlockSize int64) error {
-
-	var processedSize int64
+// encodeDatFile streams the .dat file through the encoder: the batches are read ahead while the previous ones
+// are encoded and written to the shards concurrently, with the memory bounded by the buffers of the pipeline
+func encodeDatFile(datSize int64, baseFileName string, bufferSize int, largeBlockSize int64, file *os.File, smallBlockSize int64, throttler *util.BandwidthThrottler) error {
 
 	enc, err := reedsolomon.New(DataShardsCount, ParityShardsCount)
 	if err != nil {
 		return fmt.Errorf("failed to create encoder: %v", err)
 	}
 
-	buffers := make([][]byte, TotalShardsCount)
-	for i, _ := range buffers {
-		buffers[i] = make([]byte, bufferSize)
-	}
-
 	outputs, err := openEcFiles(baseFileName, false)
 	defer closeEcFiles(outputs)
 	if err != nil {
 		return fmt.Errorf("failed to open ec files %s: %v", baseFileName, err)
 	}
 
-	for remainingSize > largeBlockSize*DataShardsCount {
-		err = encodeData(file, enc, processedSize, largeBlockSize, buffers, outputs)
-		if err != nil {
-			return fmt.Errorf("failed to encode large chunk data: %v", err)
+	free := make(chan [][]byte, ecEncodePipelineDepth)
+	for b := 0; b < ecEncodePipelineDepth; b++ {
+		buffers := make([][]byte, TotalShardsCount)
+		for i := range buffers {
+			buffers[i] = make([]byte, bufferSize)
+		}
+		free <- buffers
+	}
+	read := make(chan [][]byte, ecEncodePipelineDepth)
+	stop := make(chan struct{})
+
+	var readErr error
+	go func() {
+		defer close(read)
+		readErr = forEachEcBatch(datSize, int64(bufferSize), largeBlockSize, smallBlockSize, func(startOffset, blockSize int64) error {
+			var buffers [][]byte
+			select {
+			case buffers = <-free:
+			case <-stop:
+				return errEcEncodeStopped
+			}
+			if err := readDataShards(file, startOffset, blockSize, buffers); err != nil {
+				return err
+			}
+			throttler.Wait(bufferSize * DataShardsCount)
+			read <- buffers
+			return nil
+		})
+	}()
+
+	var writeErr error
+	for buffers := range read {
+		if writeErr == nil {
+			if writeErr = enc.Encode(buffers); writeErr == nil {
+				writeErr = writeShards(outputs, buffers)
+			}
+			if writeErr != nil {
+				close(stop)
+			}
 		}
-		remainingSize -= largeBlockSize * DataShardsCount
-		processedSize += largeBlockSize * DataShardsCount
+		free <- buffers
 	}
+	if writeErr != nil {
+		return writeErr
+	}
+	return readErr
+}
+
+// forEachEcBatch lays out the .dat file in the rows of the large blocks, and then the rows of the small blocks,
+// and calls fn with each batch of the buffer size, read from the DataShardsCount blocks of the row
+func forEachEcBatch(datSize int64, bufferSize int64, largeBlockSize int64, smallBlockSize int64, fn func(startOffset, blockSize int64) error) error {
+	var processedSize int64
+	remainingSize := datSize
 	for remainingSize > 0 {
-		encodeData(file, enc, processedSize, smallBlockSize, buffers, outputs)
+		blockSize := smallBlockSize
+		if remainingSize > largeBlockSize*DataShardsCount {
+			blockSize = largeBlockSize
+		}
+		if blockSize%bufferSize != 0 {
+			return fmt.Errorf("unexpected block size %d buffer size %d", blockSize, bufferSize)
+		}
+		for b := int64(0); b < blockSize/bufferSize; b++ {
+			if err := fn(processedSize+b*bufferSize, blockSize); err != nil {
+				return err
+			}
+		}
+		remainingSize -= blockSize * DataShardsCount
+		processedSize += blockSize * DataShardsCount
+	}
+	return nil
+}
+
+// readDataShards reads the data buffers of the batch, padding with zeros after the end of the .dat file
+func readDataShards(file *os.File, startOffset, blockSize int64, buffers [][]byte) error {
+	for i := 0; i < DataShardsCount; i++ {
+		n, err := file.ReadAt(buffers[i], startOffset+blockSize*int64(i))
+		if err != nil && err != io.EOF {
+			return err
+		}
+		for t := n; t < len(buffers[i]); t++ {
+			buffers[i][t] = 0
+		}
+	}
+	return nil
+}
+
+// writeShards appends the encoded batch to all the shard files concurrently
+func writeShards(outputs []*os.File, buffers [][]byte) error {
+	var wg sync.WaitGroup
+	errs := make([]error, TotalShardsCount)
+	for i := 0; i < TotalShardsCount; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := outputs[i].Write(buffers[i]); err != nil {
+				errs[i] = fmt.Errorf("write %s: %v", outputs[i].Name(), err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
 		if err != nil {
-			return fmt.Errorf("failed to encode small chunk data: %v", err)
+			return err
 		}
-		remainingSize -= smallBlockSize * DataShardsCount
-		processedSize += smallBlockSize * DataShardsCount
 	}
 	return nil
 }
 
-func rebuildEcFiles(shardHasData []bool, inputFiles []*os.File, outputFiles []*os.File) error {
+func rebuildEcFiles(shardHasData []bool, inputFiles []*os.File, outputFiles []*os.File, throttler *util.BandwidthThrottler) error {
 
 	enc, err := reedsolomon.New(DataShardsCount, ParityShardsCount)
 	if err != nil {
@@ -241,6 +282,7 @@ func rebuildEcFiles(shardHasData []bool, inputFiles []*os.File, outputFiles []*o
 	var startOffset int64
 	var inputBufferDataSize int
 	for {
+		inputShardsCount := 0
 
 		// read the input data from files
 		for i := 0; i < TotalShardsCount; i++ {
@@ -255,11 +297,14 @@ func rebuildEcFiles(shardHasData []bool, inputFiles []*os.File, outputFiles []*o
 				if inputBufferDataSize != n {
 					return fmt.Errorf("ec shard size expected %d actual %d", inputBufferDataSize, n)
 				}
+				inputShardsCount++
 			} else {
 				buffers[i] = nil
 			}
 		}
 
+		throttler.Wait(inputBufferDataSize * inputShardsCount)
+
 		// encode the data
 		err = enc.Reconstruct(buffers)
 		if err != nil {
@@ -3,8 +3,10 @@ package erasure_coding
 import (
 	"bytes"
 	"fmt"
+	"io/ioutil"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/chrislusf/seaweedfs/weed/storage/needle_map"
@@ -21,7 +23,7 @@ func TestEncodingDecoding(t *testing.T) {
 	bufferSize := 50
 	baseFileName := "1"
 
-	err := generateEcFiles(baseFileName, bufferSize, largeBlockSize, smallBlockSize)
+	err := generateEcFiles(baseFileName, bufferSize, largeBlockSize, smallBlockSize, nil)
 	if err != nil {
 		t.Logf("generateEcFiles: %v", err)
 	}
@@ -203,3 +205,82 @@ func (this Interval) sameAs(that Interval) bool {
 		this.BlockIndex == that.BlockIndex &&
 		this.Size == that.Size
 }
+
+func TestDeleteNeedleFromEcx(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ec_volume_delete")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	idxContent, err := ioutil.ReadFile("1.idx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	baseFileName := filepath.Join(dir, "1")
+	if err = ioutil.WriteFile(baseFileName+".idx", idxContent, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = WriteSortedEcxFile(baseFileName); err != nil {
+		t.Fatalf("WriteSortedEcxFile: %v", err)
+	}
+	cm, err := readCompactMap(baseFileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []types.NeedleId
+	cm.AscendingVisit(func(value needle_map.NeedleValue) error {
+		if len(keys) < 3 {
+			keys = append(keys, value.Key)
+		}
+		return nil
+	})
+
+	ev, err := NewEcVolume(dir, "", 1)
+	if err != nil {
+		t.Fatalf("NewEcVolume: %v", err)
+	}
+	defer func() { ev.Close() }()
+
+	if deleteCount, reclaimable := ev.DeletionStats(); deleteCount != 0 || reclaimable != 0 {
+		t.Errorf("unexpected stats %d, %d before deleting", deleteCount, reclaimable)
+	}
+
+	for _, key := range keys {
+		if err = ev.DeleteNeedleFromEcx(key); err != nil {
+			t.Fatalf("delete %d: %v", key, err)
+		}
+		// deleting again is neither counted nor journaled
+		if err = ev.DeleteNeedleFromEcx(key); err != nil {
+			t.Fatalf("delete %d again: %v", key, err)
+		}
+		if _, size, err := ev.FindNeedleFromEcx(key); err != nil || size != types.TombstoneFileSize {
+			t.Errorf("key %d expected deleted, got size %d: %v", key, size, err)
+		}
+	}
+
+	deleteCount, reclaimable := ev.DeletionStats()
+	if deleteCount != uint64(len(keys)) || reclaimable == 0 {
+		t.Errorf("unexpected stats %d, %d after deleting %d needles", deleteCount, reclaimable, len(keys))
+	}
+	if fi, _ := os.Stat(baseFileName + ".ecj"); fi == nil || fi.Size() != int64(len(keys)*types.NeedleIdSize) {
+		t.Errorf("unexpected .ecj file %+v", fi)
+	}
+
+	if err = ev.CompactJournal(); err != nil {
+		t.Fatalf("CompactJournal: %v", err)
+	}
+	if fi, _ := os.Stat(baseFileName + ".ecj"); fi == nil || fi.Size() != 0 {
+		t.Errorf("unexpected .ecj file %+v after compacting", fi)
+	}
+
+	// the stats are loaded again from the .ecx file
+	ev.Close()
+	ev, err = NewEcVolume(dir, "", 1)
+	if err != nil {
+		t.Fatalf("NewEcVolume: %v", err)
+	}
+	if reloadedCount, reloaded := ev.DeletionStats(); reloadedCount != deleteCount || reloaded == 0 {
+		t.Errorf("unexpected stats %d, %d after reloading", reloadedCount, reloaded)
+	}
+}
@@ -30,9 +30,17 @@ type EcVolume struct {
 	ShardLocations            map[ShardId][]string
 	ShardLocationsRefreshTime time.Time
 	ShardLocationsLock        sync.RWMutex
-	Version                   needle.Version
-	ecjFile                   *os.File
-	ecjFileAccessLock         sync.Mutex
+	// only one lookup of the shard locations at a time, the concurrent reads wait for its result
+	ShardLocationsLookupLock sync.Mutex
+	Version                  needle.Version
+	ecjFile                  *os.File
+	ecjFileAccessLock        sync.Mutex
+	// the deletion stats loaded from the .ecx file, guarded by the ecjFileAccessLock
+	deleteCount     uint64
+	liveByteCount   int64
+	dataStartOffset int64
+	dataEndOffset   int64
+	journaledCount  int64
 }
 
 func NewEcVolume(dir string, collection string, vid needle.VolumeId) (ev *EcVolume, err error) {
@@ -56,11 +64,48 @@ func NewEcVolume(dir string, collection string, vid needle.VolumeId) (ev *EcVolu
 		return nil, fmt.Errorf("cannot open ec volume journal %s.ecj: %v", baseFileName, err)
 	}
 
+	if ecjFi, statErr := ev.ecjFile.Stat(); statErr == nil {
+		ev.journaledCount = ecjFi.Size() / types.NeedleIdSize
+	}
+
+	if err = ev.loadDeletionStats(); err != nil {
+		return nil, fmt.Errorf("cannot load ec volume index %s.ecx: %v", baseFileName, err)
+	}
+
 	ev.ShardLocations = make(map[ShardId][]string)
 
 	return
 }
 
+// loadDeletionStats counts the live and the deleted needles in the .ecx file.
+// The data range spans from the first needle after the super block to the end of the last needle.
+func (ev *EcVolume) loadDeletionStats() error {
+	ev.dataStartOffset = -1
+	err := idx.WalkIndexFile(ev.ecxFile, func(key types.NeedleId, offset types.Offset, size uint32) error {
+		actualOffset := offset.ToAcutalOffset()
+		if ev.dataStartOffset < 0 || actualOffset < ev.dataStartOffset {
+			ev.dataStartOffset = actualOffset
+		}
+		if size == types.TombstoneFileSize {
+			ev.deleteCount++
+			if actualOffset > ev.dataEndOffset {
+				ev.dataEndOffset = actualOffset
+			}
+			return nil
+		}
+		actualSize := needle.GetActualSize(size, needle.CurrentVersion)
+		ev.liveByteCount += actualSize
+		if actualOffset+actualSize > ev.dataEndOffset {
+			ev.dataEndOffset = actualOffset + actualSize
+		}
+		return nil
+	})
+	if ev.dataStartOffset < 0 {
+		ev.dataStartOffset = 0
+	}
+	return err
+}
+
 func (ev *EcVolume) AddEcVolumeShard(ecVolumeShard *EcVolumeShard) bool {
 	for _, s := range ev.Shards {
 		if s.ShardId == ecVolumeShard.ShardId {
@@ -112,8 +157,10 @@ func (ev *EcVolume) Close() {
 		ev.ecjFileAccessLock.Unlock()
 	}
 	if ev.ecxFile != nil {
+		ev.ecjFileAccessLock.Lock()
 		_ = ev.ecxFile.Close()
 		ev.ecxFile = nil
+		ev.ecjFileAccessLock.Unlock()
 	}
 }
 
@@ -145,6 +192,30 @@ func (ev *EcVolume) CreatedAt() time.Time {
 	return ev.ecxCreatedAt
 }
 
+// DeletionStats returns the number of the deleted needles, and the estimated bytes to reclaim
+// by decoding and vacuuming the volume. The deleted needle sizes are not kept in the .ecx file,
+// so the reclaimable bytes are the data range minus the live needles.
+func (ev *EcVolume) DeletionStats() (deleteCount uint64, reclaimableByteCount uint64) {
+	ev.ecjFileAccessLock.Lock()
+	defer ev.ecjFileAccessLock.Unlock()
+
+	if ev.deleteCount == 0 {
+		return 0, 0
+	}
+	if reclaimable := ev.dataEndOffset - ev.dataStartOffset - ev.liveByteCount; reclaimable > 0 {
+		reclaimableByteCount = uint64(reclaimable)
+	}
+	return ev.deleteCount, reclaimableByteCount
+}
+
+// InvalidateShardLocations makes the cached shard locations stale, so that they are looked up again
+// before the next remote read
+func (ev *EcVolume) InvalidateShardLocations() {
+	ev.ShardLocationsLock.Lock()
+	ev.ShardLocationsRefreshTime = time.Time{}
+	ev.ShardLocationsLock.Unlock()
+}
+
 func (ev *EcVolume) ShardIdList() (shardIds []ShardId) {
 	for _, s := range ev.Shards {
 		shardIds = append(shardIds, s.ShardId)
@@ -154,12 +225,15 @@ func (ev *EcVolume) ShardIdList() (shardIds []ShardId) {
 
 func (ev *EcVolume) ToVolumeEcShardInformationMessage() (messages []*master_pb.VolumeEcShardInformationMessage) {
 	prevVolumeId := needle.VolumeId(math.MaxUint32)
+	deleteCount, reclaimableByteCount := ev.DeletionStats()
 	var m *master_pb.VolumeEcShardInformationMessage
 	for _, s := range ev.Shards {
 		if s.VolumeId != prevVolumeId {
 			m = &master_pb.VolumeEcShardInformationMessage{
-				Id:         uint32(s.VolumeId),
-				Collection: s.Collection,
+				Id:                   uint32(s.VolumeId),
+				Collection:           s.Collection,
+				DeleteCount:          deleteCount,
+				ReclaimableByteCount: reclaimableByteCount,
 			}
 			messages = append(messages, m)
 		}
@@ -201,7 +275,7 @@ func searchNeedleFromEcx(ecxFile *os.File, ecxFileSize int64, needleId types.Nee
 		key, offset, size = idx.IdxFileEntry(buf)
 		if key == needleId {
 			if processNeedleFn != nil {
-				err = processNeedleFn(ecxFile, m*types.NeedleHeaderSize)
+				err = processNeedleFn(ecxFile, m*types.NeedleMapEntrySize)
 			}
 			return
 		}
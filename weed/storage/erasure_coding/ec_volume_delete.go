@@ -5,6 +5,7 @@ import (
 	"io"
 	"os"
 
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
 	"github.com/chrislusf/seaweedfs/weed/storage/types"
 	"github.com/chrislusf/seaweedfs/weed/util"
 )
@@ -26,7 +27,22 @@ var (
 
 func (ev *EcVolume) DeleteNeedleFromEcx(needleId types.NeedleId) (err error) {
 
-	_, _, err = searchNeedleFromEcx(ev.ecxFile, ev.ecxFileSize, needleId, markNeedleDeleted)
+	ev.ecjFileAccessLock.Lock()
+	defer ev.ecjFileAccessLock.Unlock()
+
+	if ev.ecxFile == nil || ev.ecjFile == nil {
+		return fmt.Errorf("ec volume %d is closed", ev.VolumeId)
+	}
+
+	var offset types.Offset
+	var size uint32
+	var alreadyDeleted bool
+	offset, size, err = searchNeedleFromEcx(ev.ecxFile, ev.ecxFileSize, needleId, func(file *os.File, entryOffset int64) error {
+		if alreadyDeleted = isTombstoneEntry(file, entryOffset); alreadyDeleted {
+			return nil
+		}
+		return markNeedleDeleted(file, entryOffset)
+	})
 
 	if err != nil {
 		if err == NotFoundError {
@@ -34,18 +50,66 @@ func (ev *EcVolume) DeleteNeedleFromEcx(needleId types.NeedleId) (err error) {
 		}
 		return err
 	}
+	if alreadyDeleted {
+		return nil
+	}
+
+	ev.deleteCount++
+	if size != types.TombstoneFileSize {
+		actualSize := needle.GetActualSize(size, needle.CurrentVersion)
+		ev.liveByteCount -= actualSize
+		if end := offset.ToAcutalOffset() + actualSize; end > ev.dataEndOffset {
+			ev.dataEndOffset = end
+		}
+	}
 
 	b := make([]byte, types.NeedleIdSize)
 	types.NeedleIdToBytes(b, needleId)
 
+	if _, err = ev.ecjFile.Seek(0, io.SeekEnd); err != nil {
+		return fmt.Errorf("ecj seek error: %v", err)
+	}
+	if _, err = ev.ecjFile.Write(b); err != nil {
+		return fmt.Errorf("ecj write error: %v", err)
+	}
+	ev.journaledCount++
+
+	return
+}
+
+// isTombstoneEntry checks whether the .ecx entry at the offset is already marked deleted,
+// so that deleting the same needle again is not journaled or counted twice
+func isTombstoneEntry(file *os.File, offset int64) bool {
+	b := make([]byte, types.SizeSize)
+	if _, err := file.ReadAt(b, offset+types.NeedleIdSize+types.OffsetSize); err != nil {
+		return false
+	}
+	return util.BytesToUint32(b) == types.TombstoneFileSize
+}
+
+// CompactJournal syncs the deletions marked in the .ecx file, and then empties the .ecj file.
+// The .ecj file is only needed until the .ecx file is synced, and would otherwise grow with every deletion.
+func (ev *EcVolume) CompactJournal() error {
+
 	ev.ecjFileAccessLock.Lock()
+	defer ev.ecjFileAccessLock.Unlock()
 
-	ev.ecjFile.Seek(0, io.SeekEnd)
-	ev.ecjFile.Write(b)
+	if ev.journaledCount == 0 || ev.ecxFile == nil || ev.ecjFile == nil {
+		return nil
+	}
 
-	ev.ecjFileAccessLock.Unlock()
+	if err := ev.ecxFile.Sync(); err != nil {
+		return fmt.Errorf("sync %s.ecx: %v", ev.FileName(), err)
+	}
+	if err := ev.ecjFile.Truncate(0); err != nil {
+		return fmt.Errorf("truncate %s.ecj: %v", ev.FileName(), err)
+	}
+	if err := ev.ecjFile.Sync(); err != nil {
+		return fmt.Errorf("sync %s.ecj: %v", ev.FileName(), err)
+	}
+	ev.journaledCount = 0
 
-	return
+	return nil
 }
 
 func RebuildEcxFile(baseFileName string) error {
@@ -10,6 +10,9 @@ type EcVolumeInfo struct {
 	VolumeId   needle.VolumeId
 	Collection string
 	ShardBits  ShardBits
+	// the deletion stats reported by the volume server, not changed by adding or removing shards
+	DeleteCount          uint64
+	ReclaimableByteCount uint64
 }
 
 func NewEcVolumeInfo(collection string, vid needle.VolumeId, shardBits ShardBits) *EcVolumeInfo {
@@ -42,9 +45,11 @@ func (ecInfo *EcVolumeInfo) ShardIdCount() (count int) {
 
 func (ecInfo *EcVolumeInfo) Minus(other *EcVolumeInfo) *EcVolumeInfo {
 	ret := &EcVolumeInfo{
-		VolumeId:   ecInfo.VolumeId,
-		Collection: ecInfo.Collection,
-		ShardBits:  ecInfo.ShardBits.Minus(other.ShardBits),
+		VolumeId:             ecInfo.VolumeId,
+		Collection:           ecInfo.Collection,
+		ShardBits:            ecInfo.ShardBits.Minus(other.ShardBits),
+		DeleteCount:          ecInfo.DeleteCount,
+		ReclaimableByteCount: ecInfo.ReclaimableByteCount,
 	}
 
 	return ret
@@ -52,9 +57,11 @@ func (ecInfo *EcVolumeInfo) Minus(other *EcVolumeInfo) *EcVolumeInfo {
 
 func (ecInfo *EcVolumeInfo) ToVolumeEcShardInformationMessage() (ret *master_pb.VolumeEcShardInformationMessage) {
 	return &master_pb.VolumeEcShardInformationMessage{
-		Id:          uint32(ecInfo.VolumeId),
-		EcIndexBits: uint32(ecInfo.ShardBits),
-		Collection:  ecInfo.Collection,
+		Id:                   uint32(ecInfo.VolumeId),
+		EcIndexBits:          uint32(ecInfo.ShardBits),
+		Collection:           ecInfo.Collection,
+		DeleteCount:          ecInfo.DeleteCount,
+		ReclaimableByteCount: ecInfo.ReclaimableByteCount,
 	}
 }
 
@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ParseLabels parses the labels of a volume server, as "zone=eu,class=archive"
+func ParseLabels(s string) (map[string]string, error) {
+	labels := make(map[string]string)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		key := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || key == "" {
+			return nil, fmt.Errorf("label %q should be as key=value", entry)
+		}
+		labels[key] = strings.TrimSpace(parts[1])
+	}
+	return labels, nil
+}
+
+// FormatLabels formats the labels sorted by the keys
+func FormatLabels(labels map[string]string) string {
+	var entries []string
+	for k, v := range labels {
+		entries = append(entries, k+"="+v)
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ",")
+}
+
+type labelRequirement struct {
+	key    string
+	value  string
+	negate bool // "key!=value", or "!key" without the value
+	exists bool // "key" or "!key", regardless of the value
+}
+
+func (r labelRequirement) matches(labels map[string]string) bool {
+	value, found := labels[r.key]
+	if r.exists {
+		return found != r.negate
+	}
+	return (found && value == r.value) != r.negate
+}
+
+// LabelSelector selects the volume servers by the labels, as "zone=eu,class!=archive,ssd,!deprecated".
+// All the requirements should match, and the empty selector matches any volume server.
+type LabelSelector []labelRequirement
+
+func ParseLabelSelector(s string) (selector LabelSelector, err error) {
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		var r labelRequirement
+		switch {
+		case strings.Contains(entry, "!="):
+			parts := strings.SplitN(entry, "!=", 2)
+			r = labelRequirement{key: strings.TrimSpace(parts[0]), value: strings.TrimSpace(parts[1]), negate: true}
+		case strings.Contains(entry, "="):
+			parts := strings.SplitN(entry, "=", 2)
+			r = labelRequirement{key: strings.TrimSpace(parts[0]), value: strings.TrimSpace(parts[1])}
+		case strings.HasPrefix(entry, "!"):
+			r = labelRequirement{key: strings.TrimSpace(entry[1:]), negate: true, exists: true}
+		default:
+			r = labelRequirement{key: entry, exists: true}
+		}
+		if r.key == "" {
+			return nil, fmt.Errorf("label selector %q has no key", entry)
+		}
+		selector = append(selector, r)
+	}
+	return selector, nil
+}
+
+func (s LabelSelector) Matches(labels map[string]string) bool {
+	for _, r := range s {
+		if !r.matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s LabelSelector) String() string {
+	var entries []string
+	for _, r := range s {
+		switch {
+		case r.exists && r.negate:
+			entries = append(entries, "!"+r.key)
+		case r.exists:
+			entries = append(entries, r.key)
+		case r.negate:
+			entries = append(entries, r.key+"!="+r.value)
+		default:
+			entries = append(entries, r.key+"="+r.value)
+		}
+	}
+	return strings.Join(entries, ",")
+}
@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"testing"
+)
+
+func TestParseLabels(t *testing.T) {
+	labels, err := ParseLabels("zone=eu, class=archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if labels["zone"] != "eu" || labels["class"] != "archive" {
+		t.Errorf("parsed %v", labels)
+	}
+	if s := FormatLabels(labels); s != "class=archive,zone=eu" {
+		t.Errorf("formatted %s", s)
+	}
+	if _, err = ParseLabels("zone"); err == nil {
+		t.Errorf("parsed a label without the value")
+	}
+}
+
+func TestLabelSelector(t *testing.T) {
+	labels := map[string]string{"zone": "eu", "class": "archive"}
+	for selector, expected := range map[string]bool{
+		"":                        true,
+		"zone=eu":                 true,
+		"zone=us":                 false,
+		"zone=eu,class!=archive":  false,
+		"zone=eu,class!=hot":      true,
+		"class":                   true,
+		"ssd":                     false,
+		"!ssd":                    true,
+		"!zone":                   false,
+		"zone=eu, class=archive ": true,
+	} {
+		s, err := ParseLabelSelector(selector)
+		if err != nil {
+			t.Fatalf("parse %q: %v", selector, err)
+		}
+		if s.Matches(labels) != expected {
+			t.Errorf("selector %q matches %v, expected %v", selector, !expected, expected)
+		}
+	}
+	if s, _ := ParseLabelSelector("zone=eu,class!=hot,ssd,!old"); s.String() != "zone=eu,class!=hot,ssd,!old" {
+		t.Errorf("formatted %s", s)
+	}
+	if _, err := ParseLabelSelector("=eu"); err == nil {
+		t.Errorf("parsed a selector without the key")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"crypto/md5"
 	"fmt"
 
+	. "github.com/chrislusf/seaweedfs/weed/storage/types"
 	"github.com/chrislusf/seaweedfs/weed/util"
 	"github.com/klauspost/crc32"
 )
@@ -24,6 +25,29 @@ func (c CRC) Value() uint32 {
 	return uint32(c>>15|c<<17) + 0xa282ead8
 }
 
+// VerifyNeedleBodyBytes parses the needle body like ReadNeedleBodyBytes,
+// and checks the data against the CRC stored after it, to detect the corrupted needles.
+func (n *Needle) VerifyNeedleBodyBytes(needleBody []byte, version Version) error {
+	expectedLength := int64(n.Size) + NeedleChecksumSize
+	if version == Version3 {
+		expectedLength += TimestampSize
+	}
+	if int64(len(needleBody)) < expectedLength {
+		return fmt.Errorf("needle %v body has %d bytes, expected %d", n.Id, len(needleBody), expectedLength)
+	}
+	if err := n.ReadNeedleBodyBytes(needleBody, version); err != nil {
+		return fmt.Errorf("needle %v body: %v", n.Id, err)
+	}
+	if n.Size == 0 {
+		return nil
+	}
+	storedChecksum := util.BytesToUint32(needleBody[n.Size : n.Size+NeedleChecksumSize])
+	if storedChecksum != n.Checksum.Value() {
+		return fmt.Errorf("needle %v crc %x, stored %x", n.Id, n.Checksum.Value(), storedChecksum)
+	}
+	return nil
+}
+
 func (n *Needle) Etag() string {
 	bits := make([]byte, 4)
 	util.Uint32toBytes(bits, uint32(n.Checksum))
@@ -0,0 +1,63 @@
+package needle
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/chrislusf/seaweedfs/weed/storage/types"
+)
+
+func TestVerifyNeedleBodyBytes(t *testing.T) {
+	data := []byte("some needle data")
+	n := &Needle{
+		Cookie:   types.Cookie(123),
+		Id:       types.NeedleId(456),
+		DataSize: uint32(len(data)),
+		Data:     data,
+		Checksum: NewCRC(data),
+	}
+
+	tempFile, err := ioutil.TempFile("", ".dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		tempFile.Close()
+		os.Remove(tempFile.Name())
+	}()
+	if _, _, _, err = n.Append(tempFile, CurrentVersion); err != nil {
+		t.Fatal(err)
+	}
+
+	_, header, bodyLength, err := ReadNeedleHeader(tempFile, CurrentVersion, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := make([]byte, bodyLength)
+	if _, err = tempFile.ReadAt(body, types.NeedleHeaderSize); err != nil {
+		t.Fatal(err)
+	}
+
+	read := new(Needle)
+	read.ParseNeedleHeader(header)
+	if err = read.VerifyNeedleBodyBytes(body, CurrentVersion); err != nil {
+		t.Fatalf("verify the needle: %v", err)
+	}
+	if string(read.Data) != string(data) {
+		t.Errorf("unexpected data %q", read.Data)
+	}
+
+	// one flipped bit in the data
+	body[6] ^= 0x01
+	corrupted := new(Needle)
+	corrupted.ParseNeedleHeader(header)
+	if err = corrupted.VerifyNeedleBodyBytes(body, CurrentVersion); err == nil {
+		t.Errorf("the corrupted needle should not be verified")
+	}
+
+	// truncated
+	if err = corrupted.VerifyNeedleBodyBytes(body[:8], CurrentVersion); err == nil {
+		t.Errorf("the truncated needle should not be verified")
+	}
+}
@@ -57,6 +57,14 @@ func (n *FileId) GetNeedleIdCookie() string {
 	return formatNeedleIdCookie(n.Key, n.Cookie)
 }
 
+// Batch lists the file ids of the count consecutive keys from this file id, on the same volume with the same cookie
+func (n *FileId) Batch(count uint64) (fids []string) {
+	for i := uint64(0); i < count; i++ {
+		fids = append(fids, n.VolumeId.String()+","+formatNeedleIdCookie(n.Key+Uint64ToNeedleId(i), n.Cookie))
+	}
+	return
+}
+
 func (n *FileId) String() string {
 	return n.VolumeId.String() + "," + formatNeedleIdCookie(n.Key, n.Cookie)
 }
@@ -65,8 +73,9 @@ func formatNeedleIdCookie(key NeedleId, cookie Cookie) string {
 	bytes := make([]byte, NeedleIdSize+CookieSize)
 	NeedleIdToBytes(bytes[0:NeedleIdSize], key)
 	CookieToBytes(bytes[NeedleIdSize:NeedleIdSize+CookieSize], cookie)
+	// keep the last byte of the key, so the key 0 of a wrapped batch is still parsed apart from the cookie
 	nonzero_index := 0
-	for ; bytes[nonzero_index] == 0; nonzero_index++ {
+	for ; nonzero_index < NeedleIdSize-1 && bytes[nonzero_index] == 0; nonzero_index++ {
 	}
 	return hex.EncodeToString(bytes[nonzero_index:])
 }
@@ -53,3 +53,32 @@ func TestParseFileIdFromString(t *testing.T) {
 		t.Errorf("%s : needleId is too long", fidStr1)
 	}
 }
+
+func TestFileIdBatch(t *testing.T) {
+	tests := []struct {
+		key      uint64
+		count    uint64
+		expected []string
+	}{
+		{0x1, 0, nil},
+		{0x1, 1, []string{"3,01637037d6"}},
+		{0x1, 3, []string{"3,01637037d6", "3,02637037d6", "3,03637037d6"}},
+		// the key carries into the next byte
+		{0xff, 2, []string{"3,ff637037d6", "3,0100637037d6"}},
+		// the keys wrap around after the largest key
+		{0xfffffffffffffffe, 3, []string{"3,fffffffffffffffe637037d6", "3,ffffffffffffffff637037d6", "3,00637037d6"}},
+	}
+	for _, tt := range tests {
+		fids := NewFileId(VolumeId(3), tt.key, 0x637037d6).Batch(tt.count)
+		if len(fids) != len(tt.expected) {
+			t.Errorf("batch of %d from %x: %v", tt.count, tt.key, fids)
+			continue
+		}
+		for i, fid := range fids {
+			if fid != tt.expected[i] {
+				t.Errorf("batch of %d from %x: %v", tt.count, tt.key, fids)
+				break
+			}
+		}
+	}
+}
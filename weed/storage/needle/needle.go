@@ -3,6 +3,7 @@ package needle
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
@@ -17,8 +18,21 @@ import (
 const (
 	NeedleChecksumSize = 4
 	PairNamePrefix     = "Seaweed-"
+	// the needle size is a uint32, with 1MB left for the name, the mime, the pairs and the other metadata.
+	// The larger files should be split into chunks, as the filer does by -maxMB.
+	MaxNeedleDataSize = math.MaxUint32 - 1024*1024
 )
 
+// ErrNeedleTooLarge is the error of the data over the needle size limit, telling how to upload the large files
+type ErrNeedleTooLarge struct {
+	Size  int64
+	Limit int64
+}
+
+func (e ErrNeedleTooLarge) Error() string {
+	return fmt.Sprintf("file size %d exceeds the limit %d of a single needle: upload through the filer, which splits the large files into chunks by -maxMB, or by \"weed upload -maxMB\"", e.Size, e.Limit)
+}
+
 /*
 * A Needle means a uploaded and stored file.
 * Needle file size is limited to 4GB for now.
@@ -188,3 +202,21 @@ func ParseNeedleIdCookie(key_hash_string string) (NeedleId, Cookie, error) {
 func (n *Needle) LastModifiedString() string {
 	return time.Unix(int64(n.LastModified), 0).Format("2006-01-02T15:04:05")
 }
+
+// ExpireAtSeconds is when the needle is past its own ttl, or 0 if the needle does not expire
+func (n *Needle) ExpireAtSeconds() uint64 {
+	if !n.HasTtl() || n.Ttl == nil || !n.HasLastModifiedDate() {
+		return 0
+	}
+	ttlMinutes := n.Ttl.Minutes()
+	if ttlMinutes == 0 {
+		return 0
+	}
+	return n.LastModified + uint64(ttlMinutes)*60
+}
+
+// IsExpired tells whether the needle is past its own ttl, which can be shorter than the ttl of its volume
+func (n *Needle) IsExpired(nowSeconds uint64) bool {
+	expireAt := n.ExpireAtSeconds()
+	return expireAt > 0 && nowSeconds >= expireAt
+}
@@ -30,6 +30,10 @@ func (n *Needle) DiskSize(version Version) int64 {
 }
 
 func (n *Needle) Append(w *os.File, version Version) (offset uint64, size uint32, actualSize int64, err error) {
+	if int64(len(n.Data)) > MaxNeedleDataSize {
+		err = ErrNeedleTooLarge{Size: int64(len(n.Data)), Limit: MaxNeedleDataSize}
+		return
+	}
 	if end, e := w.Seek(0, io.SeekEnd); e == nil {
 		defer func(w *os.File, off int64) {
 			if err != nil {
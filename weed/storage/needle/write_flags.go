@@ -0,0 +1,74 @@
+package needle
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+const (
+	// acknowledge the write after all the replicas have written it
+	AckAll = "all"
+	// acknowledge the write after a majority of the copies, including the local one, have written it
+	AckQuorum = "quorum"
+)
+
+// WriteFlags is the durability requested when assigning a fid, returned with the fid,
+// signed into its write jwt, and enforced by the volume servers on the upload.
+type WriteFlags struct {
+	Fsync bool   `json:"fsync,omitempty"`
+	Ack   string `json:"ack,omitempty"`
+}
+
+// ParseWriteFlags parses the "fsync" and "ack" request parameters, the empty values for the defaults
+func ParseWriteFlags(fsync, ack string) (f WriteFlags, err error) {
+	if fsync != "" {
+		if f.Fsync, err = strconv.ParseBool(fsync); err != nil {
+			return f, fmt.Errorf("fsync %s: %v", fsync, err)
+		}
+	}
+	switch ack {
+	case "", AckAll:
+	case AckQuorum:
+		f.Ack = ack
+	default:
+		return f, fmt.Errorf("unknown ack %s, expecting %s or %s", ack, AckAll, AckQuorum)
+	}
+	return f, nil
+}
+
+// ParseWriteFlagsFromQuery parses the write flags from the query of an upload
+func ParseWriteFlagsFromQuery(q url.Values) (WriteFlags, error) {
+	return ParseWriteFlags(q.Get("fsync"), q.Get("ack"))
+}
+
+func (f WriteFlags) IsEmpty() bool {
+	return !f.Fsync && f.Ack == ""
+}
+
+// Merge keeps the stronger durability of both flags
+func (f WriteFlags) Merge(other WriteFlags) WriteFlags {
+	f.Fsync = f.Fsync || other.Fsync
+	if f.Ack == "" || other.Ack == "" {
+		f.Ack = ""
+	}
+	return f
+}
+
+// SetQuery adds the flags to the query of an upload
+func (f WriteFlags) SetQuery(q url.Values) {
+	if f.Fsync {
+		q.Set("fsync", "true")
+	}
+	if f.Ack != "" {
+		q.Set("ack", f.Ack)
+	}
+}
+
+// RequiredAcks is the number of the copies, including the local one, to write before acknowledging, 0 for all
+func (f WriteFlags) RequiredAcks(copyCount int) int {
+	if f.Ack == AckQuorum {
+		return copyCount/2 + 1
+	}
+	return 0
+}
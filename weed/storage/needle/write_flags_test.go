@@ -0,0 +1,61 @@
+package needle
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestParseWriteFlags(t *testing.T) {
+	f, err := ParseWriteFlags("true", "quorum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.Fsync || f.Ack != AckQuorum {
+		t.Errorf("parsed %+v", f)
+	}
+
+	if f, err = ParseWriteFlags("", "all"); err != nil || !f.IsEmpty() {
+		t.Errorf("parsed %+v: %v", f, err)
+	}
+
+	if _, err = ParseWriteFlags("maybe", ""); err == nil {
+		t.Errorf("parsed an invalid fsync")
+	}
+	if _, err = ParseWriteFlags("", "some"); err == nil {
+		t.Errorf("parsed an invalid ack")
+	}
+}
+
+func TestWriteFlagsQuery(t *testing.T) {
+	q := url.Values{}
+	WriteFlags{Fsync: true, Ack: AckQuorum}.SetQuery(q)
+	f, err := ParseWriteFlagsFromQuery(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.Fsync || f.Ack != AckQuorum {
+		t.Errorf("parsed %+v from %s", f, q.Encode())
+	}
+}
+
+func TestWriteFlagsMerge(t *testing.T) {
+	quorum := WriteFlags{Ack: AckQuorum}
+	if m := quorum.Merge(WriteFlags{Fsync: true}); !m.Fsync || m.Ack != "" {
+		t.Errorf("merged %+v, expecting fsync and all acks", m)
+	}
+	if m := quorum.Merge(quorum); m.Ack != AckQuorum {
+		t.Errorf("merged %+v, expecting quorum acks", m)
+	}
+}
+
+func TestRequiredAcks(t *testing.T) {
+	quorum := WriteFlags{Ack: AckQuorum}
+	for copyCount, expected := range map[int]int{1: 1, 2: 2, 3: 2, 4: 3, 5: 3} {
+		if acks := quorum.RequiredAcks(copyCount); acks != expected {
+			t.Errorf("%d copies require %d acks, expected %d", copyCount, acks, expected)
+		}
+	}
+	if acks := (WriteFlags{}).RequiredAcks(3); acks != 0 {
+		t.Errorf("all acks required %d, expected 0", acks)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"sync"
 
+	"github.com/chrislusf/seaweedfs/weed/glog"
 	"github.com/chrislusf/seaweedfs/weed/storage/needle_map"
 	. "github.com/chrislusf/seaweedfs/weed/storage/types"
 )
@@ -17,6 +18,7 @@ const (
 	NeedleMapLevelDb                     // small memory footprint, 4MB total, 1 write buffer, 3 block buffer
 	NeedleMapLevelDbMedium               // medium memory footprint, 8MB total, 3 write buffer, 5 block buffer
 	NeedleMapLevelDbLarge                // large memory footprint, 12MB total, 4write buffer, 8 block buffer
+	NeedleMapMmap                        // the sorted index is memory mapped, only the recent writes are in memory
 )
 
 type NeedleMapper interface {
@@ -33,6 +35,8 @@ type NeedleMapper interface {
 	IndexFileSize() uint64
 	IndexFileContent() ([]byte, error)
 	IndexFileName() string
+	Sync() error
+	SetIndexBatching(batching bool) error
 }
 
 type baseNeedleMapper struct {
@@ -40,9 +44,17 @@ type baseNeedleMapper struct {
 
 	indexFile           *os.File
 	indexFileAccessLock sync.Mutex
+	// the batched entries are only written to the index file when synced, for the group commit
+	indexBatching bool
+	indexBuffer   []byte
 }
 
 func (nm *baseNeedleMapper) IndexFileSize() uint64 {
+	nm.indexFileAccessLock.Lock()
+	defer nm.indexFileAccessLock.Unlock()
+	if err := nm.flushIndexBuffer(); err != nil {
+		glog.V(0).Infof("flush %s: %v", nm.indexFile.Name(), err)
+	}
 	stat, err := nm.indexFile.Stat()
 	if err == nil {
 		return uint64(stat.Size())
@@ -59,6 +71,15 @@ func (nm *baseNeedleMapper) appendToIndexFile(key NeedleId, offset Offset, size
 
 	nm.indexFileAccessLock.Lock()
 	defer nm.indexFileAccessLock.Unlock()
+	if nm.indexBatching {
+		nm.indexBuffer = append(nm.indexBuffer, bytes...)
+		return nil
+	}
+	return nm.writeToIndexFile(bytes)
+}
+
+// writeToIndexFile requires the indexFileAccessLock
+func (nm *baseNeedleMapper) writeToIndexFile(bytes []byte) error {
 	if _, err := nm.indexFile.Seek(0, 2); err != nil {
 		return fmt.Errorf("cannot seek end of indexfile %s: %v",
 			nm.indexFile.Name(), err)
@@ -67,8 +88,51 @@ func (nm *baseNeedleMapper) appendToIndexFile(key NeedleId, offset Offset, size
 	return err
 }
 
+// flushIndexBuffer writes the batched entries in one write, and requires the indexFileAccessLock
+func (nm *baseNeedleMapper) flushIndexBuffer() error {
+	if len(nm.indexBuffer) == 0 {
+		return nil
+	}
+	err := nm.writeToIndexFile(nm.indexBuffer)
+	nm.indexBuffer = nm.indexBuffer[:0]
+	return err
+}
+
+// SetIndexBatching keeps the index entries in memory until synced, or writes the batched entries when turned off
+func (nm *baseNeedleMapper) SetIndexBatching(batching bool) error {
+	nm.indexFileAccessLock.Lock()
+	defer nm.indexFileAccessLock.Unlock()
+	nm.indexBatching = batching
+	if batching {
+		return nil
+	}
+	return nm.flushIndexBuffer()
+}
+
+func (nm *baseNeedleMapper) Sync() error {
+	nm.indexFileAccessLock.Lock()
+	defer nm.indexFileAccessLock.Unlock()
+	if err := nm.flushIndexBuffer(); err != nil {
+		return err
+	}
+	return nm.indexFile.Sync()
+}
+
 func (nm *baseNeedleMapper) IndexFileContent() ([]byte, error) {
 	nm.indexFileAccessLock.Lock()
 	defer nm.indexFileAccessLock.Unlock()
+	if err := nm.flushIndexBuffer(); err != nil {
+		return nil, err
+	}
 	return ioutil.ReadFile(nm.indexFile.Name())
 }
+
+// closeIndexFile writes the batched entries before closing the index file
+func (nm *baseNeedleMapper) closeIndexFile() error {
+	nm.indexFileAccessLock.Lock()
+	defer nm.indexFileAccessLock.Unlock()
+	if err := nm.flushIndexBuffer(); err != nil {
+		glog.V(0).Infof("flush %s: %v", nm.indexFile.Name(), err)
+	}
+	return nm.indexFile.Close()
+}
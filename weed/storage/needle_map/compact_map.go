@@ -9,6 +9,8 @@ import (
 
 const (
 	batch = 100000
+	// the overflow entries are merged back into the sorted values when reaching this size
+	overflowCompactionSize = batch / 100
 )
 
 type SectionalNeedleId uint32
@@ -29,6 +31,7 @@ type CompactSection struct {
 	sync.RWMutex
 	values        []SectionalNeedleValue
 	valuesExtra   []SectionalNeedleValueExtra
+	deleted       *roaringBitmap // deleted keys in values, kept as tombstones
 	overflow      Overflow
 	overflowExtra OverflowExtra
 	start         NeedleId
@@ -41,8 +44,7 @@ type OverflowExtra []SectionalNeedleValueExtra
 
 func NewCompactSection(start NeedleId) *CompactSection {
 	return &CompactSection{
-		values:        make([]SectionalNeedleValue, batch),
-		valuesExtra:   make([]SectionalNeedleValueExtra, batch),
+		deleted:       newRoaringBitmap(),
 		overflow:      Overflow(make([]SectionalNeedleValue, 0)),
 		overflowExtra: OverflowExtra(make([]SectionalNeedleValueExtra, 0)),
 		start:         start,
@@ -58,6 +60,9 @@ func (cs *CompactSection) Set(key NeedleId, offset Offset, size uint32) (oldOffs
 	skey := SectionalNeedleId(key - cs.start)
 	if i := cs.binarySearchValues(skey); i >= 0 {
 		oldOffset.OffsetHigher, oldOffset.OffsetLower, oldSize = cs.valuesExtra[i].OffsetHigher, cs.values[i].OffsetLower, cs.values[i].Size
+		if cs.deleted.Remove(uint32(skey)) {
+			oldSize = TombstoneFileSize
+		}
 		//println("key", key, "old size", ret)
 		cs.valuesExtra[i].OffsetHigher, cs.values[i].OffsetLower, cs.values[i].Size = offset.OffsetHigher, offset.OffsetLower, size
 	} else {
@@ -69,9 +74,15 @@ func (cs *CompactSection) Set(key NeedleId, offset Offset, size uint32) (oldOffs
 				oldOffset.OffsetHigher, oldOffset.OffsetLower, oldSize = oldValueExtra.OffsetHigher, oldValue.OffsetLower, oldValue.Size
 			}
 			cs.setOverflowEntry(skey, offset, size)
+			if len(cs.overflow) >= overflowCompactionSize {
+				cs.compact()
+			}
 		} else {
-			p := &cs.values[cs.counter]
-			p.Key, cs.valuesExtra[cs.counter].OffsetHigher, p.OffsetLower, p.Size = skey, offset.OffsetHigher, offset.OffsetLower, size
+			if cs.counter == cap(cs.values) {
+				cs.grow()
+			}
+			cs.values = append(cs.values, SectionalNeedleValue{Key: skey, OffsetLower: offset.OffsetLower, Size: size})
+			cs.valuesExtra = append(cs.valuesExtra, SectionalNeedleValueExtra{OffsetHigher: offset.OffsetHigher})
 			//println("added index", cs.counter, "key", key, cs.values[cs.counter].Key)
 			cs.counter++
 		}
@@ -80,6 +91,22 @@ func (cs *CompactSection) Set(key NeedleId, offset Offset, size uint32) (oldOffs
 	return
 }
 
+// grow doubles the capacity of values, but not beyond batch, to avoid wasting memory on the last section
+func (cs *CompactSection) grow() {
+	capacity := 2 * cap(cs.values)
+	if capacity < 64 {
+		capacity = 64
+	}
+	if capacity > batch && cs.counter < batch {
+		capacity = batch
+	}
+	values := make([]SectionalNeedleValue, cs.counter, capacity)
+	valuesExtra := make([]SectionalNeedleValueExtra, cs.counter, capacity)
+	copy(values, cs.values)
+	copy(valuesExtra, cs.valuesExtra)
+	cs.values, cs.valuesExtra = values, valuesExtra
+}
+
 func (cs *CompactSection) setOverflowEntry(skey SectionalNeedleId, offset Offset, size uint32) {
 	needleValue := SectionalNeedleValue{Key: skey, OffsetLower: offset.OffsetLower, Size: size}
 	needleValueExtra := SectionalNeedleValueExtra{OffsetHigher: offset.OffsetHigher}
@@ -130,9 +157,8 @@ func (cs *CompactSection) Delete(key NeedleId) uint32 {
 	cs.Lock()
 	ret := uint32(0)
 	if i := cs.binarySearchValues(skey); i >= 0 {
-		if cs.values[i].Size > 0 && cs.values[i].Size != TombstoneFileSize {
+		if cs.deleted.Add(uint32(skey)) {
 			ret = cs.values[i].Size
-			cs.values[i].Size = TombstoneFileSize
 		}
 	}
 	if _, v, found := cs.findOverflowEntry(skey); found {
@@ -151,13 +177,49 @@ func (cs *CompactSection) Get(key NeedleId) (*NeedleValue, bool) {
 		return &nv, true
 	}
 	if i := cs.binarySearchValues(skey); i >= 0 {
+		nv := cs.valueAt(i)
 		cs.RUnlock()
-		nv := toNeedleValue(cs.valuesExtra[i], cs.values[i], cs)
 		return &nv, true
 	}
 	cs.RUnlock()
 	return nil, false
 }
+
+// valueAt returns values[i], with the tombstone size if deleted
+func (cs *CompactSection) valueAt(i int) NeedleValue {
+	nv := toNeedleValue(cs.valuesExtra[i], cs.values[i], cs)
+	if cs.deleted.Contains(uint32(cs.values[i].Key)) {
+		nv.Size = TombstoneFileSize
+	}
+	return nv
+}
+
+// compact merges the overflow entries back, so that the values are again one sorted array.
+// The deleted entries are kept, so they are still visited and found as tombstones.
+func (cs *CompactSection) compact() {
+	length := cs.counter + len(cs.overflow)
+	values := make([]SectionalNeedleValue, 0, length)
+	valuesExtra := make([]SectionalNeedleValueExtra, 0, length)
+	var i, j int
+	for i < len(cs.overflow) || j < cs.counter {
+		if j == cs.counter || i < len(cs.overflow) && cs.overflow[i].Key <= cs.values[j].Key {
+			if j < cs.counter && cs.overflow[i].Key == cs.values[j].Key {
+				cs.deleted.Remove(uint32(cs.values[j].Key))
+				j++
+			}
+			values = append(values, cs.overflow[i])
+			valuesExtra = append(valuesExtra, cs.overflowExtra[i])
+			i++
+		} else {
+			values = append(values, cs.values[j])
+			valuesExtra = append(valuesExtra, cs.valuesExtra[j])
+			j++
+		}
+	}
+	cs.values, cs.valuesExtra, cs.counter = values, valuesExtra, len(values)
+	cs.overflow = cs.overflow[:0]
+	cs.overflowExtra = cs.overflowExtra[:0]
+}
 func (cs *CompactSection) binarySearchValues(key SectionalNeedleId) int {
 	x := sort.Search(cs.counter, func(i int) bool {
 		return cs.values[i].Key >= key
@@ -173,7 +235,9 @@ func (cs *CompactSection) binarySearchValues(key SectionalNeedleId) int {
 
 //This map assumes mostly inserting increasing keys
 //This map assumes mostly inserting increasing keys
+// CompactMap can be read while being written, e.g., by the compaction during the writes.
 type CompactMap struct {
+	sync.RWMutex
 	list []*CompactSection
 }
 
@@ -182,6 +246,8 @@ func NewCompactMap() *CompactMap {
 }
 
 func (cm *CompactMap) Set(key NeedleId, offset Offset, size uint32) (oldOffset Offset, oldSize uint32) {
+	cm.Lock()
+	defer cm.Unlock()
 	x := cm.binarySearchCompactSection(key)
 	if x < 0 || (key-cm.list[x].start) > SectionalNeedleIdLimit {
 		// println(x, "adding to existing", len(cm.list), "sections, starting", key)
@@ -205,6 +271,8 @@ func (cm *CompactMap) Set(key NeedleId, offset Offset, size uint32) (oldOffset O
 	return cm.list[x].Set(key, offset, size)
 }
 func (cm *CompactMap) Delete(key NeedleId) uint32 {
+	cm.Lock()
+	defer cm.Unlock()
 	x := cm.binarySearchCompactSection(key)
 	if x < 0 {
 		return uint32(0)
@@ -212,6 +280,8 @@ func (cm *CompactMap) Delete(key NeedleId) uint32 {
 	return cm.list[x].Delete(key)
 }
 func (cm *CompactMap) Get(key NeedleId) (*NeedleValue, bool) {
+	cm.RLock()
+	defer cm.RUnlock()
 	x := cm.binarySearchCompactSection(key)
 	if x < 0 {
 		return nil, false
@@ -246,10 +316,12 @@ func (cm *CompactMap) binarySearchCompactSection(key NeedleId) int {
 
 // Visit visits all entries or stop if any error when visiting
 func (cm *CompactMap) AscendingVisit(visit func(NeedleValue) error) error {
+	cm.RLock()
+	defer cm.RUnlock()
 	for _, cs := range cm.list {
 		cs.RLock()
 		var i, j int
-		for i, j = 0, 0; i < len(cs.overflow) && j < len(cs.values) && j < cs.counter; {
+		for i, j = 0, 0; i < len(cs.overflow) && j < cs.counter; {
 			if cs.overflow[i].Key < cs.values[j].Key {
 				if err := visit(toNeedleValue(cs.overflowExtra[i], cs.overflow[i], cs)); err != nil {
 					cs.RUnlock()
@@ -259,7 +331,7 @@ func (cm *CompactMap) AscendingVisit(visit func(NeedleValue) error) error {
 			} else if cs.overflow[i].Key == cs.values[j].Key {
 				j++
 			} else {
-				if err := visit(toNeedleValue(cs.valuesExtra[j], cs.values[j], cs)); err != nil {
+				if err := visit(cs.valueAt(j)); err != nil {
 					cs.RUnlock()
 					return err
 				}
@@ -272,8 +344,8 @@ func (cm *CompactMap) AscendingVisit(visit func(NeedleValue) error) error {
 				return err
 			}
 		}
-		for ; j < len(cs.values) && j < cs.counter; j++ {
-			if err := visit(toNeedleValue(cs.valuesExtra[j], cs.values[j], cs)); err != nil {
+		for ; j < cs.counter; j++ {
+			if err := visit(cs.valueAt(j)); err != nil {
 				cs.RUnlock()
 				return err
 			}
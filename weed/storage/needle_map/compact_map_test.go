@@ -157,3 +157,136 @@ func TestOverflow(t *testing.T) {
 	println()
 
 }
+
+func TestCompactMapDeletion(t *testing.T) {
+	m := NewCompactMap()
+	for i := uint32(1); i <= 2*batch; i++ {
+		m.Set(NeedleId(i), ToOffset(int64(i)), i)
+	}
+	// out of order keys go to the overflow
+	m.Set(NeedleId(7), ToOffset(7), 77)
+
+	for i := uint32(1); i <= 2*batch; i += 2 {
+		if size := m.Delete(NeedleId(i)); size != i && i != 7 {
+			t.Fatalf("key %d deleted size %d", i, size)
+		}
+	}
+	if size := m.Delete(NeedleId(3)); size != 0 {
+		t.Fatalf("key 3 deleted twice, size %d", size)
+	}
+
+	// re-create some deleted keys
+	for i := uint32(1); i <= 2*batch; i += 10 {
+		m.Set(NeedleId(i), ToOffset(int64(i+1)), i+1)
+	}
+
+	var previous NeedleId
+	err := m.AscendingVisit(func(nv NeedleValue) error {
+		if nv.Key <= previous {
+			return fmt.Errorf("key %d after %d", nv.Key, previous)
+		}
+		previous = nv.Key
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := uint32(1); i <= 2*batch; i++ {
+		v, ok := m.Get(NeedleId(i))
+		switch {
+		case i%10 == 1:
+			if !ok || v.Size != i+1 || v.Offset != ToOffset(int64(i+1)) {
+				t.Fatalf("key %d re-created: %+v", i, v)
+			}
+		case i%2 == 1:
+			if !ok || v.Size != TombstoneFileSize {
+				t.Fatalf("key %d should be a tombstone: %+v", i, v)
+			}
+		default:
+			if !ok || v.Size != i {
+				t.Fatalf("key %d: %+v", i, v)
+			}
+		}
+	}
+}
+
+func TestCompactMapOverflowKeepsTombstones(t *testing.T) {
+	m := NewCompactMap()
+	// the even keys first, then the odd keys out of order go to the overflow until merged back
+	for i := uint32(2); i <= 4*overflowCompactionSize; i += 2 {
+		m.Set(NeedleId(i), ToOffset(int64(i)), i)
+	}
+	for i := uint32(2); i <= 4*overflowCompactionSize; i += 4 {
+		m.Delete(NeedleId(i))
+	}
+	for i := uint32(3); i < 4*overflowCompactionSize; i += 2 {
+		m.Set(NeedleId(i), ToOffset(int64(i)), i)
+	}
+	if cs := m.list[0]; len(cs.overflow) >= overflowCompactionSize || cs.counter <= 2*overflowCompactionSize {
+		t.Fatalf("overflow not merged: %d values, %d overflow", cs.counter, len(cs.overflow))
+	}
+
+	tombstones, previous := 0, NeedleId(1)
+	err := m.AscendingVisit(func(nv NeedleValue) error {
+		if nv.Key != previous+1 {
+			return fmt.Errorf("key %d after %d", nv.Key, previous)
+		}
+		previous = nv.Key
+		if nv.Size == TombstoneFileSize {
+			tombstones++
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tombstones != overflowCompactionSize {
+		t.Errorf("visited %d tombstones, expected %d", tombstones, overflowCompactionSize)
+	}
+	if v, ok := m.Get(NeedleId(6)); !ok || v.Size != TombstoneFileSize {
+		t.Errorf("deleted key 6: %+v", v)
+	}
+	if size := m.Delete(NeedleId(6)); size != 0 {
+		t.Errorf("tombstone deleted again, size %d", size)
+	}
+	if _, oldSize := m.Set(NeedleId(6), ToOffset(60), 60); oldSize != TombstoneFileSize {
+		t.Errorf("re-created tombstone had size %d", oldSize)
+	}
+}
+
+func TestRoaringBitmap(t *testing.T) {
+	rb := newRoaringBitmap()
+	expected := make(map[uint32]bool)
+	// sparse values in one container, dense values in another, and some far away
+	for i := uint32(0); i < 10000; i++ {
+		for _, x := range []uint32{i * 7, 1<<16 + i, 1<<31 + i*65537} {
+			if rb.Add(x) == expected[x] {
+				t.Fatalf("add %d returned %v", x, expected[x])
+			}
+			expected[x] = true
+		}
+	}
+	if rb.Cardinality() != len(expected) {
+		t.Fatalf("cardinality %d, expected %d", rb.Cardinality(), len(expected))
+	}
+	for x := range expected {
+		if x%5 != 0 {
+			if !rb.Remove(x) {
+				t.Fatalf("remove %d", x)
+			}
+			delete(expected, x)
+		}
+	}
+	if rb.Remove(7) {
+		t.Fatalf("remove 7 twice")
+	}
+	for i := uint32(0); i < 80000; i++ {
+		if rb.Contains(i) != expected[i] {
+			t.Fatalf("contains %d: %v", i, expected[i])
+		}
+	}
+	if rb.Cardinality() != len(expected) {
+		t.Fatalf("cardinality %d, expected %d", rb.Cardinality(), len(expected))
+	}
+}
@@ -0,0 +1,165 @@
+package needle_map
+
+import (
+	"math/bits"
+	"sort"
+)
+
+// roaringBitmap is a compressed set of uint32, as described in https://roaringbitmap.org/
+// The values are grouped into containers by the higher 16 bits.
+// A sparse container keeps a sorted array of the lower 16 bits,
+// and a dense container with more than arrayContainerMax values switches to a bitmap.
+type roaringBitmap struct {
+	keys       []uint16
+	containers []*roaringContainer
+	count      int
+}
+
+const (
+	arrayContainerMax = 4096
+	bitmapWords       = 1 << 16 / 64
+)
+
+type roaringContainer struct {
+	array  []uint16
+	bitmap []uint64
+	count  int
+}
+
+func newRoaringBitmap() *roaringBitmap {
+	return &roaringBitmap{}
+}
+
+func (rb *roaringBitmap) Add(x uint32) bool {
+	hi, lo := uint16(x>>16), uint16(x)
+	i := rb.search(hi)
+	if i == len(rb.keys) || rb.keys[i] != hi {
+		rb.keys = append(rb.keys, 0)
+		rb.containers = append(rb.containers, nil)
+		copy(rb.keys[i+1:], rb.keys[i:])
+		copy(rb.containers[i+1:], rb.containers[i:])
+		rb.keys[i], rb.containers[i] = hi, &roaringContainer{}
+	}
+	if rb.containers[i].add(lo) {
+		rb.count++
+		return true
+	}
+	return false
+}
+
+func (rb *roaringBitmap) Remove(x uint32) bool {
+	hi, lo := uint16(x>>16), uint16(x)
+	i := rb.search(hi)
+	if i == len(rb.keys) || rb.keys[i] != hi {
+		return false
+	}
+	if !rb.containers[i].remove(lo) {
+		return false
+	}
+	rb.count--
+	if rb.containers[i].count == 0 {
+		rb.keys = append(rb.keys[:i], rb.keys[i+1:]...)
+		rb.containers = append(rb.containers[:i], rb.containers[i+1:]...)
+	}
+	return true
+}
+
+func (rb *roaringBitmap) Contains(x uint32) bool {
+	hi, lo := uint16(x>>16), uint16(x)
+	i := rb.search(hi)
+	if i == len(rb.keys) || rb.keys[i] != hi {
+		return false
+	}
+	return rb.containers[i].contains(lo)
+}
+
+func (rb *roaringBitmap) Cardinality() int {
+	return rb.count
+}
+
+func (rb *roaringBitmap) search(hi uint16) int {
+	return sort.Search(len(rb.keys), func(i int) bool {
+		return rb.keys[i] >= hi
+	})
+}
+
+func (c *roaringContainer) searchArray(lo uint16) int {
+	return sort.Search(len(c.array), func(i int) bool {
+		return c.array[i] >= lo
+	})
+}
+
+func (c *roaringContainer) add(lo uint16) bool {
+	if c.bitmap != nil {
+		w, mask := lo/64, uint64(1)<<(lo%64)
+		if c.bitmap[w]&mask != 0 {
+			return false
+		}
+		c.bitmap[w] |= mask
+		c.count++
+		return true
+	}
+	i := c.searchArray(lo)
+	if i < len(c.array) && c.array[i] == lo {
+		return false
+	}
+	if len(c.array) >= arrayContainerMax {
+		c.toBitmap()
+		return c.add(lo)
+	}
+	c.array = append(c.array, 0)
+	copy(c.array[i+1:], c.array[i:])
+	c.array[i] = lo
+	c.count++
+	return true
+}
+
+func (c *roaringContainer) remove(lo uint16) bool {
+	if c.bitmap != nil {
+		w, mask := lo/64, uint64(1)<<(lo%64)
+		if c.bitmap[w]&mask == 0 {
+			return false
+		}
+		c.bitmap[w] &^= mask
+		c.count--
+		if c.count <= arrayContainerMax/2 {
+			c.toArray()
+		}
+		return true
+	}
+	i := c.searchArray(lo)
+	if i == len(c.array) || c.array[i] != lo {
+		return false
+	}
+	c.array = append(c.array[:i], c.array[i+1:]...)
+	c.count--
+	return true
+}
+
+func (c *roaringContainer) contains(lo uint16) bool {
+	if c.bitmap != nil {
+		return c.bitmap[lo/64]&(uint64(1)<<(lo%64)) != 0
+	}
+	i := c.searchArray(lo)
+	return i < len(c.array) && c.array[i] == lo
+}
+
+func (c *roaringContainer) toBitmap() {
+	c.bitmap = make([]uint64, bitmapWords)
+	for _, lo := range c.array {
+		c.bitmap[lo/64] |= uint64(1) << (lo % 64)
+	}
+	c.array = nil
+}
+
+func (c *roaringContainer) toArray() {
+	c.array = make([]uint16, 0, c.count)
+	for w, word := range c.bitmap {
+		for word != 0 {
+			t := bits.TrailingZeros64(word)
+			c.array = append(c.array, uint16(w*64+t))
+			word &= word - 1
+		}
+	}
+	c.bitmap = nil
+}
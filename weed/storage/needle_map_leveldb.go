@@ -2,8 +2,10 @@ package storage
 
 import (
 	"fmt"
+	"io"
 	"os"
-	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/chrislusf/seaweedfs/weed/storage/idx"
 	"github.com/syndtr/goleveldb/leveldb/opt"
@@ -15,68 +17,131 @@ import (
 	"github.com/syndtr/goleveldb/leveldb"
 )
 
+const (
+	// the puts and deletes are batched, until so many are pending, or for so long, or the needle map is synced
+	levelDbBatchSize  = 1024
+	levelDbBatchDelay = time.Second
+	// about 1% false positives, to skip reading the tables without the key
+	levelDbBloomFilterBitsPerKey = 10
+)
+
+// levelDbWatermarkKey keeps the size of the .idx file covered by leveldb, longer than the needle ids.
+// The .idx entries after it are written to leveldb when loading, e.g. after a crash.
+var levelDbWatermarkKey = []byte("idx.watermark")
+
 type LevelDbNeedleMap struct {
 	baseNeedleMapper
 	dbFileName string
 	db         *leveldb.DB
+	// the pending puts and deletes, also looked up before leveldb
+	batchLock  sync.Mutex
+	batch      *leveldb.Batch
+	pending    map[NeedleId]needle_map.NeedleValue
+	batchTimer *time.Timer
+	// the size of the .idx file with the pending entries, saved as the watermark with the batch
+	indexOffset int64
 }
 
 func NewLevelDbNeedleMap(dbFileName string, indexFile *os.File, opts *opt.Options) (m *LevelDbNeedleMap, err error) {
-	m = &LevelDbNeedleMap{dbFileName: dbFileName}
+	m = &LevelDbNeedleMap{dbFileName: dbFileName, batch: new(leveldb.Batch), pending: make(map[NeedleId]needle_map.NeedleValue)}
 	m.indexFile = indexFile
-	if !isLevelDbFresh(dbFileName, indexFile) {
-		glog.V(0).Infof("Start to Generate %s from %s", dbFileName, indexFile.Name())
-		generateLevelDbFile(dbFileName, indexFile)
-		glog.V(0).Infof("Finished Generating %s from %s", dbFileName, indexFile.Name())
+	indexStat, err := indexFile.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("stat %s: %v", indexFile.Name(), err)
 	}
-	glog.V(1).Infof("Opening %s...", dbFileName)
+	m.indexOffset = indexStat.Size()
 
+	glog.V(1).Infof("Opening %s...", dbFileName)
 	if m.db, err = leveldb.OpenFile(dbFileName, opts); err != nil {
 		return
 	}
+	watermark, found := readLevelDbWatermark(m.db)
+	if !found || watermark > m.indexOffset {
+		// not generated yet, or covering the entries lost from the .idx file
+		glog.V(0).Infof("Start to Generate %s from %s", dbFileName, indexFile.Name())
+		m.db.Close()
+		if err = os.RemoveAll(dbFileName); err != nil {
+			return nil, err
+		}
+		if m.db, err = leveldb.OpenFile(dbFileName, opts); err != nil {
+			return
+		}
+		watermark = 0
+	}
+	if watermark < m.indexOffset {
+		glog.V(0).Infof("Update %s from %s since %d", dbFileName, indexFile.Name(), watermark)
+		if err = m.catchUpIndexFile(watermark); err != nil {
+			m.db.Close()
+			return nil, fmt.Errorf("update %s from %s: %v", dbFileName, indexFile.Name(), err)
+		}
+		glog.V(0).Infof("Finished Updating %s from %s", dbFileName, indexFile.Name())
+	}
+
 	glog.V(1).Infof("Loading %s...", indexFile.Name())
 	mm, indexLoadError := newNeedleMapMetricFromIndexFile(indexFile)
 	if indexLoadError != nil {
+		m.db.Close()
 		return nil, indexLoadError
 	}
 	m.mapMetric = *mm
 	return
 }
 
-func isLevelDbFresh(dbFileName string, indexFile *os.File) bool {
-	// normally we always write to index file first
-	dbLogFile, err := os.Open(filepath.Join(dbFileName, "LOG"))
-	if err != nil {
-		return false
-	}
-	defer dbLogFile.Close()
-	dbStat, dbStatErr := dbLogFile.Stat()
-	indexStat, indexStatErr := indexFile.Stat()
-	if dbStatErr != nil || indexStatErr != nil {
-		glog.V(0).Infof("Can not stat file: %v and %v", dbStatErr, indexStatErr)
-		return false
+func readLevelDbWatermark(db *leveldb.DB) (watermark int64, found bool) {
+	data, err := db.Get(levelDbWatermarkKey, nil)
+	if err != nil || len(data) != 8 {
+		return 0, false
 	}
+	return int64(util.BytesToUint64(data)), true
+}
 
-	return dbStat.ModTime().After(indexStat.ModTime())
+func levelDbBatchWatermark(batch *leveldb.Batch, watermark int64) {
+	bytes := make([]byte, 8)
+	util.Uint64toBytes(bytes, uint64(watermark))
+	batch.Put(levelDbWatermarkKey, bytes)
 }
 
-func generateLevelDbFile(dbFileName string, indexFile *os.File) error {
-	db, err := leveldb.OpenFile(dbFileName, nil)
-	if err != nil {
-		return err
-	}
-	defer db.Close()
-	return idx.WalkIndexFile(indexFile, func(key NeedleId, offset Offset, size uint32) error {
-		if !offset.IsZero() && size != TombstoneFileSize {
-			levelDbWrite(db, key, offset, size)
-		} else {
-			levelDbDelete(db, key)
+// catchUpIndexFile writes the .idx entries since the watermark to leveldb
+func (m *LevelDbNeedleMap) catchUpIndexFile(watermark int64) error {
+	batch := new(leveldb.Batch)
+	bytes := make([]byte, NeedleMapEntrySize*idx.RowsToRead)
+	for offset := watermark; offset < m.indexOffset; {
+		count, err := m.indexFile.ReadAt(bytes, offset)
+		if count < NeedleMapEntrySize {
+			if err == nil || err == io.EOF {
+				break
+			}
+			return err
 		}
-		return nil
-	})
+		for i := 0; i+NeedleMapEntrySize <= count; i += NeedleMapEntrySize {
+			key, needleOffset, size := idx.IdxFileEntry(bytes[i : i+NeedleMapEntrySize])
+			if !needleOffset.IsZero() && size != TombstoneFileSize {
+				levelDbBatchPut(batch, key, needleOffset, size)
+			} else {
+				levelDbBatchDelete(batch, key)
+			}
+			offset += NeedleMapEntrySize
+		}
+		levelDbBatchWatermark(batch, offset)
+		if err := m.db.Write(batch, nil); err != nil {
+			return err
+		}
+		batch.Reset()
+	}
+	return nil
 }
 
 func (m *LevelDbNeedleMap) Get(key NeedleId) (element *needle_map.NeedleValue, ok bool) {
+	m.batchLock.Lock()
+	if nv, found := m.pending[key]; found {
+		m.batchLock.Unlock()
+		if nv.Size == TombstoneFileSize {
+			return nil, false
+		}
+		return &nv, true
+	}
+	m.batchLock.Unlock()
+
 	bytes := make([]byte, NeedleIdSize)
 	NeedleIdToBytes(bytes[0:NeedleIdSize], key)
 	data, err := m.db.Get(bytes, nil)
@@ -98,26 +163,13 @@ func (m *LevelDbNeedleMap) Put(key NeedleId, offset Offset, size uint32) error {
 	if err := m.appendToIndexFile(key, offset, size); err != nil {
 		return fmt.Errorf("cannot write to indexfile %s: %v", m.indexFile.Name(), err)
 	}
-	return levelDbWrite(m.db, key, offset, size)
-}
-
-func levelDbWrite(db *leveldb.DB,
-	key NeedleId, offset Offset, size uint32) error {
 
-	bytes := make([]byte, NeedleIdSize+OffsetSize+SizeSize)
-	NeedleIdToBytes(bytes[0:NeedleIdSize], key)
-	OffsetToBytes(bytes[NeedleIdSize:NeedleIdSize+OffsetSize], offset)
-	util.Uint32toBytes(bytes[NeedleIdSize+OffsetSize:NeedleIdSize+OffsetSize+SizeSize], size)
-
-	if err := db.Put(bytes[0:NeedleIdSize], bytes[NeedleIdSize:NeedleIdSize+OffsetSize+SizeSize], nil); err != nil {
-		return fmt.Errorf("failed to write leveldb: %v", err)
-	}
-	return nil
-}
-func levelDbDelete(db *leveldb.DB, key NeedleId) error {
-	bytes := make([]byte, NeedleIdSize)
-	NeedleIdToBytes(bytes, key)
-	return db.Delete(bytes, nil)
+	m.batchLock.Lock()
+	defer m.batchLock.Unlock()
+	levelDbBatchPut(m.batch, key, offset, size)
+	m.pending[key] = needle_map.NeedleValue{Key: key, Offset: offset, Size: size}
+	m.indexOffset += NeedleMapEntrySize
+	return m.maybeWriteBatch()
 }
 
 func (m *LevelDbNeedleMap) Delete(key NeedleId, offset Offset) error {
@@ -128,11 +180,85 @@ func (m *LevelDbNeedleMap) Delete(key NeedleId, offset Offset) error {
 	if err := m.appendToIndexFile(key, offset, TombstoneFileSize); err != nil {
 		return err
 	}
-	return levelDbDelete(m.db, key)
+
+	m.batchLock.Lock()
+	defer m.batchLock.Unlock()
+	levelDbBatchDelete(m.batch, key)
+	m.pending[key] = needle_map.NeedleValue{Key: key, Offset: offset, Size: TombstoneFileSize}
+	m.indexOffset += NeedleMapEntrySize
+	return m.maybeWriteBatch()
+}
+
+// maybeWriteBatch requires the batchLock, and writes the pending batch later if not full
+func (m *LevelDbNeedleMap) maybeWriteBatch() error {
+	if m.batch.Len() < levelDbBatchSize {
+		if m.batchTimer == nil {
+			m.batchTimer = time.AfterFunc(levelDbBatchDelay, m.writeDelayedBatch)
+		}
+		return nil
+	}
+	return m.writeBatch()
+}
+
+func (m *LevelDbNeedleMap) writeDelayedBatch() {
+	m.batchLock.Lock()
+	defer m.batchLock.Unlock()
+	if err := m.writeBatch(); err != nil {
+		glog.V(0).Infof("write delayed batch: %v", err)
+	}
+}
+
+// writeBatch requires the batchLock
+func (m *LevelDbNeedleMap) writeBatch() error {
+	if m.batchTimer != nil {
+		m.batchTimer.Stop()
+		m.batchTimer = nil
+	}
+	if m.batch.Len() == 0 {
+		return nil
+	}
+	levelDbBatchWatermark(m.batch, m.indexOffset)
+	err := m.db.Write(m.batch, nil)
+	m.batch.Reset()
+	m.pending = make(map[NeedleId]needle_map.NeedleValue)
+	if err != nil {
+		return fmt.Errorf("failed to write leveldb %s: %v", m.dbFileName, err)
+	}
+	return nil
+}
+
+// Sync writes the pending batch to leveldb, and syncs the index file
+func (m *LevelDbNeedleMap) Sync() error {
+	m.batchLock.Lock()
+	err := m.writeBatch()
+	m.batchLock.Unlock()
+	if err != nil {
+		return err
+	}
+	return m.baseNeedleMapper.Sync()
+}
+
+func levelDbBatchPut(batch *leveldb.Batch, key NeedleId, offset Offset, size uint32) {
+	bytes := make([]byte, NeedleIdSize+OffsetSize+SizeSize)
+	NeedleIdToBytes(bytes[0:NeedleIdSize], key)
+	OffsetToBytes(bytes[NeedleIdSize:NeedleIdSize+OffsetSize], offset)
+	util.Uint32toBytes(bytes[NeedleIdSize+OffsetSize:NeedleIdSize+OffsetSize+SizeSize], size)
+	batch.Put(bytes[0:NeedleIdSize], bytes[NeedleIdSize:NeedleIdSize+OffsetSize+SizeSize])
+}
+
+func levelDbBatchDelete(batch *leveldb.Batch, key NeedleId) {
+	bytes := make([]byte, NeedleIdSize)
+	NeedleIdToBytes(bytes, key)
+	batch.Delete(bytes)
 }
 
 func (m *LevelDbNeedleMap) Close() {
-	m.indexFile.Close()
+	m.batchLock.Lock()
+	if err := m.writeBatch(); err != nil {
+		glog.V(0).Infof("close %s: %v", m.dbFileName, err)
+	}
+	m.batchLock.Unlock()
+	m.closeIndexFile()
 	m.db.Close()
 }
 
@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/storage/needle_map"
+	. "github.com/chrislusf/seaweedfs/weed/storage/types"
+	"github.com/syndtr/goleveldb/leveldb/filter"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+)
+
+func openLevelDbNeedleMap(t *testing.T, dir string) *LevelDbNeedleMap {
+	indexFile, err := os.OpenFile(filepath.Join(dir, "1.idx"), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewLevelDbNeedleMap(filepath.Join(dir, "1.ldb"), indexFile, &opt.Options{Filter: filter.NewBloomFilter(levelDbBloomFilterBitsPerKey)})
+	if err != nil {
+		t.Fatalf("NewLevelDbNeedleMap: %v", err)
+	}
+	return m
+}
+
+func TestLevelDbNeedleMapBatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "leveldb_needle_map")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	m := openLevelDbNeedleMap(t, dir)
+	count := levelDbBatchSize + levelDbBatchSize/2
+	for i := 1; i <= count; i++ {
+		m.Put(NeedleId(i), Uint32ToOffset(uint32(i)), uint32(i*10))
+	}
+	m.Delete(NeedleId(1), Uint32ToOffset(uint32(count+1)))
+	m.Put(NeedleId(2), Uint32ToOffset(uint32(count+2)), 1000)
+
+	// the pending entries are found before written to leveldb
+	check := func(key NeedleId, expectedSize uint32) {
+		nv, ok := m.Get(key)
+		if expectedSize == 0 {
+			if ok {
+				t.Errorf("key %d should not be found, got size %d", key, nv.Size)
+			}
+			return
+		}
+		if !ok || nv.Size != expectedSize {
+			t.Errorf("key %d expected size %d, got %+v", key, expectedSize, nv)
+		}
+	}
+	check(1, 0)
+	check(2, 1000)
+	check(NeedleId(count), uint32(count*10))
+	if len(m.pending) == 0 || len(m.pending) >= levelDbBatchSize {
+		t.Errorf("unexpected %d pending entries", len(m.pending))
+	}
+
+	if err = m.Sync(); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if len(m.pending) != 0 {
+		t.Errorf("%d pending entries after sync", len(m.pending))
+	}
+	check(1, 0)
+	check(NeedleId(count), uint32(count*10))
+	m.Put(NeedleId(count+10), Uint32ToOffset(uint32(count+10)), 3000)
+	m.Close()
+
+	m = openLevelDbNeedleMap(t, dir)
+	check(1, 0)
+	check(2, 1000)
+	check(NeedleId(count+10), 3000)
+	check(NeedleId(count+11), 0)
+	m.Close()
+}
+
+func TestLevelDbNeedleMapWatermark(t *testing.T) {
+	dir, err := ioutil.TempDir("", "leveldb_needle_map")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	m := openLevelDbNeedleMap(t, dir)
+	for i := 1; i <= 10; i++ {
+		m.Put(NeedleId(i), Uint32ToOffset(uint32(i)), uint32(i*10))
+	}
+	if err = m.Sync(); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if watermark, found := readLevelDbWatermark(m.db); !found || watermark != 10*NeedleMapEntrySize {
+		t.Fatalf("watermark %d %v after sync", watermark, found)
+	}
+
+	// the pending batch is written after a while, without syncing
+	m.Put(NeedleId(11), Uint32ToOffset(11), 110)
+	deadline := time.Now().Add(levelDbBatchDelay * 5)
+	for {
+		m.batchLock.Lock()
+		pendingCount := len(m.pending)
+		m.batchLock.Unlock()
+		if pendingCount == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d entries still pending", pendingCount)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	m.Close()
+
+	// the .idx entries written after the batch, e.g. before a crash, are added when loading
+	indexFile, err := os.OpenFile(filepath.Join(dir, "1.idx"), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexFile.Write(needle_map.ToBytes(NeedleId(100), Uint32ToOffset(100), 1000))
+	indexFile.Write(needle_map.ToBytes(NeedleId(1), Uint32ToOffset(101), TombstoneFileSize))
+	indexFile.Close()
+
+	m = openLevelDbNeedleMap(t, dir)
+	if nv, ok := m.Get(NeedleId(100)); !ok || nv.Size != 1000 {
+		t.Errorf("entry after the watermark is not loaded: %+v", nv)
+	}
+	if _, ok := m.Get(NeedleId(1)); ok {
+		t.Errorf("deletion after the watermark is not loaded")
+	}
+	if watermark, _ := readLevelDbWatermark(m.db); watermark != 13*NeedleMapEntrySize {
+		t.Errorf("watermark %d after loading", watermark)
+	}
+	m.Close()
+
+	// leveldb covering more than the .idx file is generated again
+	if err = os.Truncate(filepath.Join(dir, "1.idx"), 5*NeedleMapEntrySize); err != nil {
+		t.Fatal(err)
+	}
+	m = openLevelDbNeedleMap(t, dir)
+	if nv, ok := m.Get(NeedleId(3)); !ok || nv.Size != 30 {
+		t.Errorf("entry in the .idx file is lost: %+v", nv)
+	}
+	if _, ok := m.Get(NeedleId(7)); ok {
+		t.Errorf("entry not in the .idx file is kept")
+	}
+	m.Close()
+}
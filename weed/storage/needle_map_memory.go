@@ -79,7 +79,7 @@ func (nm *NeedleMap) Delete(key NeedleId, offset Offset) error {
 	return nm.appendToIndexFile(key, offset, TombstoneFileSize)
 }
 func (nm *NeedleMap) Close() {
-	_ = nm.indexFile.Close()
+	_ = nm.closeIndexFile()
 }
 func (nm *NeedleMap) Destroy() error {
 	nm.Close()
@@ -0,0 +1,317 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/storage/idx"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle_map"
+	. "github.com/chrislusf/seaweedfs/weed/storage/types"
+	"github.com/chrislusf/seaweedfs/weed/util"
+)
+
+/*
+The .sdx file is the sorted live entries of the .idx file, followed by a trailer:
+
+	idx file size covered          8 bytes
+	last covered .idx entry        NeedleMapEntrySize bytes, to tell whether the .idx file is replaced
+	FileCounter                    4 bytes
+	DeletionCounter                4 bytes
+	FileByteCounter                8 bytes
+	DeletionByteCounter            8 bytes
+	MaximumFileKey                 8 bytes
+	magic                          4 bytes
+
+The .sdx file is memory mapped, so the pages are read from disk only when looked up. The entries
+appended to the .idx file after the .sdx file are kept in memory, until the .sdx file is generated again.
+*/
+
+const (
+	sortedIndexTrailerSize = 8 + NeedleMapEntrySize + 4 + 4 + 8 + 8 + 8 + 4
+	sortedIndexMagic       = 0x53445831 // "SDX1"
+	// generate the .sdx file again when the .idx file has this many entries not sorted, or a quarter of the sorted ones
+	minUnsortedEntriesToResort = 64 * 1024
+)
+
+type MmapNeedleMap struct {
+	baseNeedleMapper
+	sortedFileName string
+	// guards the sortedData from being unmapped while read
+	sortedLock sync.RWMutex
+	sortedData []byte // the mapped .sdx file
+	sorted     []byte // the sorted entries in the sortedData
+	// the entries appended to the .idx file after the .sdx file, with the tombstone size for the deletions
+	unsorted *needle_map.CompactMap
+}
+
+func NewMmapNeedleMap(sortedFileName string, indexFile *os.File) (m *MmapNeedleMap, err error) {
+	m = &MmapNeedleMap{sortedFileName: sortedFileName, unsorted: needle_map.NewCompactMap()}
+	m.indexFile = indexFile
+
+	stat, err := indexFile.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("stat %s: %v", indexFile.Name(), err)
+	}
+	indexFileSize := stat.Size()
+
+	coveredSize, loadErr := m.loadSortedFile(indexFileSize)
+	if loadErr == nil {
+		unsortedCount := (indexFileSize - coveredSize) / NeedleMapEntrySize
+		sortedCount := int64(len(m.sorted) / NeedleMapEntrySize)
+		if unsortedCount >= minUnsortedEntriesToResort && unsortedCount > sortedCount/4 {
+			loadErr = fmt.Errorf("%d entries not sorted", unsortedCount)
+		}
+	}
+	if loadErr != nil {
+		glog.V(0).Infof("generate %s from %s: %v", sortedFileName, indexFile.Name(), loadErr)
+		m.unmap()
+		if err = generateSortedIndexFile(sortedFileName, indexFile, indexFileSize); err != nil {
+			return nil, fmt.Errorf("generate %s: %v", sortedFileName, err)
+		}
+		if coveredSize, err = m.loadSortedFile(indexFileSize); err != nil {
+			m.unmap()
+			return nil, fmt.Errorf("load %s: %v", sortedFileName, err)
+		}
+	}
+
+	err = m.loadUnsortedEntries(coveredSize, indexFileSize)
+	return m, err
+}
+
+// loadSortedFile maps the .sdx file, and returns the size of the .idx file it covers
+func (m *MmapNeedleMap) loadSortedFile(indexFileSize int64) (coveredSize int64, err error) {
+	if m.sortedData, err = mmapFile(m.sortedFileName); err != nil {
+		return 0, err
+	}
+	if len(m.sortedData) < sortedIndexTrailerSize || (len(m.sortedData)-sortedIndexTrailerSize)%NeedleMapEntrySize != 0 {
+		return 0, fmt.Errorf("unexpected size %d", len(m.sortedData))
+	}
+	sortedSize := len(m.sortedData) - sortedIndexTrailerSize
+	trailer := m.sortedData[sortedSize:]
+	if util.BytesToUint32(trailer[sortedIndexTrailerSize-4:]) != sortedIndexMagic {
+		return 0, fmt.Errorf("unexpected magic")
+	}
+
+	coveredSize = int64(util.BytesToUint64(trailer[0:8]))
+	if coveredSize > indexFileSize || coveredSize%NeedleMapEntrySize != 0 {
+		return 0, fmt.Errorf("covers %d bytes of the %d bytes index", coveredSize, indexFileSize)
+	}
+	if coveredSize > 0 {
+		lastEntry := make([]byte, NeedleMapEntrySize)
+		if _, err = m.indexFile.ReadAt(lastEntry, coveredSize-NeedleMapEntrySize); err != nil {
+			return 0, err
+		}
+		if !bytes.Equal(lastEntry, trailer[8:8+NeedleMapEntrySize]) {
+			return 0, fmt.Errorf("the index is replaced")
+		}
+	}
+
+	t := trailer[8+NeedleMapEntrySize:]
+	m.mapMetric = mapMetric{
+		FileCounter:         util.BytesToUint32(t[0:4]),
+		DeletionCounter:     util.BytesToUint32(t[4:8]),
+		FileByteCounter:     util.BytesToUint64(t[8:16]),
+		DeletionByteCounter: util.BytesToUint64(t[16:24]),
+		MaximumFileKey:      util.BytesToUint64(t[24:32]),
+	}
+	m.sorted = m.sortedData[:sortedSize]
+	return coveredSize, nil
+}
+
+// loadUnsortedEntries replays the .idx entries after the .sdx file
+func (m *MmapNeedleMap) loadUnsortedEntries(coveredSize, indexFileSize int64) error {
+	if coveredSize >= indexFileSize {
+		return nil
+	}
+	data := make([]byte, indexFileSize-coveredSize)
+	if _, err := m.indexFile.ReadAt(data, coveredSize); err != nil {
+		return fmt.Errorf("read %s: %v", m.indexFile.Name(), err)
+	}
+	for i := 0; i+NeedleMapEntrySize <= len(data); i += NeedleMapEntrySize {
+		key, offset, size := idx.IdxFileEntry(data[i : i+NeedleMapEntrySize])
+		if !offset.IsZero() && size != TombstoneFileSize {
+			m.set(key, offset, size)
+		} else {
+			m.remove(key, offset)
+		}
+	}
+	return nil
+}
+
+func (m *MmapNeedleMap) set(key NeedleId, offset Offset, size uint32) {
+	var oldSize uint32
+	if oldNeedle, ok := m.Get(key); ok {
+		oldSize = oldNeedle.Size
+	}
+	m.unsorted.Set(key, offset, size)
+	m.logPut(key, oldSize, size)
+}
+
+func (m *MmapNeedleMap) remove(key NeedleId, offset Offset) {
+	if oldNeedle, ok := m.Get(key); ok && oldNeedle.Size != TombstoneFileSize {
+		m.logDelete(oldNeedle.Size)
+	}
+	m.unsorted.Set(key, offset, TombstoneFileSize)
+}
+
+// Get returns the deleted entries with the tombstone size, like the in memory needle map
+func (m *MmapNeedleMap) Get(key NeedleId) (element *needle_map.NeedleValue, ok bool) {
+	if element, ok = m.unsorted.Get(key); ok {
+		return element, true
+	}
+	m.sortedLock.RLock()
+	defer m.sortedLock.RUnlock()
+	count := len(m.sorted) / NeedleMapEntrySize
+	i := sort.Search(count, func(i int) bool {
+		return BytesToNeedleId(m.sorted[i*NeedleMapEntrySize:i*NeedleMapEntrySize+NeedleIdSize]) >= key
+	})
+	if i >= count {
+		return nil, false
+	}
+	foundKey, offset, size := idx.IdxFileEntry(m.sorted[i*NeedleMapEntrySize : (i+1)*NeedleMapEntrySize])
+	if foundKey != key {
+		return nil, false
+	}
+	return &needle_map.NeedleValue{Key: key, Offset: offset, Size: size}, true
+}
+
+func (m *MmapNeedleMap) Put(key NeedleId, offset Offset, size uint32) error {
+	// write to index file first
+	if err := m.appendToIndexFile(key, offset, size); err != nil {
+		return fmt.Errorf("cannot write to indexfile %s: %v", m.indexFile.Name(), err)
+	}
+	m.set(key, offset, size)
+	return nil
+}
+
+func (m *MmapNeedleMap) Delete(key NeedleId, offset Offset) error {
+	// write to index file first
+	if err := m.appendToIndexFile(key, offset, TombstoneFileSize); err != nil {
+		return err
+	}
+	m.remove(key, offset)
+	return nil
+}
+
+// AscendingVisit visits the live entries in the ascending order of the keys,
+// merging the .sdx file with the recent entries, without loading the .sdx file into memory
+func (m *MmapNeedleMap) AscendingVisit(visit func(needle_map.NeedleValue) error) error {
+	m.sortedLock.RLock()
+	defer m.sortedLock.RUnlock()
+
+	count := len(m.sorted) / NeedleMapEntrySize
+	sortedAt := func(i int) needle_map.NeedleValue {
+		key, offset, size := idx.IdxFileEntry(m.sorted[i*NeedleMapEntrySize : (i+1)*NeedleMapEntrySize])
+		return needle_map.NeedleValue{Key: key, Offset: offset, Size: size}
+	}
+	visitLive := func(nv needle_map.NeedleValue) error {
+		if nv.Offset.IsZero() || nv.Size == TombstoneFileSize {
+			return nil
+		}
+		return visit(nv)
+	}
+
+	i := 0
+	err := m.unsorted.AscendingVisit(func(recent needle_map.NeedleValue) error {
+		for ; i < count; i++ {
+			nv := sortedAt(i)
+			if nv.Key >= recent.Key {
+				if nv.Key == recent.Key {
+					// replaced or deleted by the recent entry
+					i++
+				}
+				break
+			}
+			if err := visitLive(nv); err != nil {
+				return err
+			}
+		}
+		return visitLive(recent)
+	})
+	if err != nil {
+		return err
+	}
+	for ; i < count; i++ {
+		if err = visitLive(sortedAt(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MmapNeedleMap) unmap() {
+	m.sortedLock.Lock()
+	defer m.sortedLock.Unlock()
+	if m.sortedData != nil {
+		if err := munmapFile(m.sortedData); err != nil {
+			glog.V(0).Infof("unmap %s: %v", m.sortedFileName, err)
+		}
+	}
+	m.sortedData, m.sorted = nil, nil
+}
+
+func (m *MmapNeedleMap) Close() {
+	m.closeIndexFile()
+	m.unmap()
+}
+
+func (m *MmapNeedleMap) Destroy() error {
+	m.Close()
+	os.Remove(m.indexFile.Name())
+	return os.Remove(m.sortedFileName)
+}
+
+// generateSortedIndexFile writes the live entries of the .idx file sorted by the keys, and then the trailer
+func generateSortedIndexFile(sortedFileName string, indexFile *os.File, indexFileSize int64) error {
+	nm := NewCompactNeedleMap(indexFile)
+	if _, err := doLoading(indexFile, nm); err != nil {
+		return err
+	}
+
+	tempFileName := sortedFileName + ".tmp"
+	dst, err := os.OpenFile(tempFileName, os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFileName)
+
+	err = nm.m.AscendingVisit(func(value needle_map.NeedleValue) error {
+		if value.Offset.IsZero() || value.Size == TombstoneFileSize {
+			return nil
+		}
+		_, writeErr := dst.Write(value.ToBytes())
+		return writeErr
+	})
+	if err != nil {
+		dst.Close()
+		return err
+	}
+
+	trailer := make([]byte, sortedIndexTrailerSize)
+	util.Uint64toBytes(trailer[0:8], uint64(indexFileSize))
+	if indexFileSize > 0 {
+		if _, err = indexFile.ReadAt(trailer[8:8+NeedleMapEntrySize], indexFileSize-NeedleMapEntrySize); err != nil {
+			dst.Close()
+			return err
+		}
+	}
+	t := trailer[8+NeedleMapEntrySize:]
+	util.Uint32toBytes(t[0:4], nm.FileCounter)
+	util.Uint32toBytes(t[4:8], nm.DeletionCounter)
+	util.Uint64toBytes(t[8:16], nm.FileByteCounter)
+	util.Uint64toBytes(t[16:24], nm.DeletionByteCounter)
+	util.Uint64toBytes(t[24:32], nm.MaximumFileKey)
+	util.Uint32toBytes(t[32:36], sortedIndexMagic)
+	if _, err = dst.Write(trailer); err != nil {
+		dst.Close()
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tempFileName, sortedFileName)
+}
@@ -0,0 +1,16 @@
+// +build windows plan9
+
+package storage
+
+import (
+	"io/ioutil"
+)
+
+// mmapFile reads the whole file into memory, as the memory mapping is not supported here
+func mmapFile(fileName string) ([]byte, error) {
+	return ioutil.ReadFile(fileName)
+}
+
+func munmapFile(data []byte) error {
+	return nil
+}
@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chrislusf/seaweedfs/weed/storage/needle_map"
+	. "github.com/chrislusf/seaweedfs/weed/storage/types"
+	"github.com/chrislusf/seaweedfs/weed/util"
+)
+
+func openMmapNeedleMap(t *testing.T, dir string) *MmapNeedleMap {
+	indexFile, err := os.OpenFile(filepath.Join(dir, "1.idx"), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewMmapNeedleMap(filepath.Join(dir, "1.sdx"), indexFile)
+	if err != nil {
+		t.Fatalf("NewMmapNeedleMap: %v", err)
+	}
+	return m
+}
+
+func checkNeedle(t *testing.T, m *MmapNeedleMap, key NeedleId, expectedSize uint32) {
+	nv, ok := m.Get(key)
+	if expectedSize == 0 {
+		if ok && nv.Size != TombstoneFileSize {
+			t.Errorf("key %d should not be found, got size %d", key, nv.Size)
+		}
+		return
+	}
+	if !ok || nv.Size != expectedSize {
+		t.Errorf("key %d expected size %d, got %+v", key, expectedSize, nv)
+	}
+}
+
+func TestMmapNeedleMapReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mmap_needle_map")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	m := openMmapNeedleMap(t, dir)
+	for i := 1; i <= 100; i++ {
+		m.Put(NeedleId(i), Uint32ToOffset(uint32(i)), uint32(i*10))
+	}
+	m.Delete(NeedleId(50), Uint32ToOffset(200))
+	m.Put(NeedleId(60), Uint32ToOffset(201), 1000)
+	m.Close()
+
+	// the entries are loaded from the sorted file
+	m = openMmapNeedleMap(t, dir)
+	checkNeedle(t, m, 1, 10)
+	checkNeedle(t, m, 50, 0)
+	checkNeedle(t, m, 60, 1000)
+	checkNeedle(t, m, 101, 0)
+	if m.FileCount() != 101 || m.DeletedCount() != 2 || m.MaxFileKey() != 100 {
+		t.Errorf("unexpected metrics: files %d deleted %d max key %d", m.FileCount(), m.DeletedCount(), m.MaxFileKey())
+	}
+
+	// the entries written after the sorted file are replayed from the index file
+	m.Put(NeedleId(200), Uint32ToOffset(300), 2000)
+	m.Delete(NeedleId(1), Uint32ToOffset(301))
+	m.Close()
+
+	m = openMmapNeedleMap(t, dir)
+	checkNeedle(t, m, 1, 0)
+	checkNeedle(t, m, 2, 20)
+	checkNeedle(t, m, 200, 2000)
+	if m.FileCount() != 102 || m.DeletedCount() != 3 || m.MaxFileKey() != 200 {
+		t.Errorf("unexpected metrics after replay: files %d deleted %d max key %d", m.FileCount(), m.DeletedCount(), m.MaxFileKey())
+	}
+	m.Close()
+}
+
+func TestMmapNeedleMapReplacedIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mmap_needle_map")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	m := openMmapNeedleMap(t, dir)
+	m.Put(NeedleId(1), Uint32ToOffset(1), 10)
+	m.Close()
+	m = openMmapNeedleMap(t, dir)
+	m.Close()
+
+	// the index is replaced by another one of the same size, like after a compaction
+	indexFileName := filepath.Join(dir, "1.idx")
+	if err = ioutil.WriteFile(indexFileName, needle_map.ToBytes(NeedleId(2), Uint32ToOffset(2), 20), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m = openMmapNeedleMap(t, dir)
+	checkNeedle(t, m, 1, 0)
+	checkNeedle(t, m, 2, 20)
+	m.Close()
+}
+
+func TestMmapNeedleMapAscendingVisit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mmap_needle_map")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	m := openMmapNeedleMap(t, dir)
+	for i := 2; i <= 20; i += 2 {
+		m.Put(NeedleId(i), Uint32ToOffset(uint32(i)), uint32(i*10))
+	}
+	m.Close()
+
+	// the recent entries are merged with the sorted ones
+	m = openMmapNeedleMap(t, dir)
+	m.Put(NeedleId(5), Uint32ToOffset(100), 50)
+	m.Put(NeedleId(4), Uint32ToOffset(101), 4000)
+	m.Delete(NeedleId(6), Uint32ToOffset(102))
+	m.Put(NeedleId(30), Uint32ToOffset(103), 300)
+
+	ecxFileName := filepath.Join(dir, "1.ecx")
+	if err = writeSortedIndexFile(ecxFileName, m); err != nil {
+		t.Fatalf("write sorted index: %v", err)
+	}
+	m.Close()
+
+	data, err := ioutil.ReadFile(ecxFileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []NeedleId
+	sizes := make(map[NeedleId]uint32)
+	for i := 0; i+NeedleMapEntrySize <= len(data); i += NeedleMapEntrySize {
+		key := BytesToNeedleId(data[i : i+NeedleIdSize])
+		keys = append(keys, key)
+		sizes[key] = util.BytesToUint32(data[i+NeedleIdSize+OffsetSize : i+NeedleMapEntrySize])
+	}
+	expected := []NeedleId{2, 4, 5, 8, 10, 12, 14, 16, 18, 20, 30}
+	if len(keys) != len(expected) {
+		t.Fatalf("keys %v, expected %v", keys, expected)
+	}
+	for i, key := range expected {
+		if keys[i] != key {
+			t.Fatalf("keys %v, expected %v", keys, expected)
+		}
+	}
+	if sizes[4] != 4000 || sizes[5] != 50 || sizes[30] != 300 || sizes[8] != 80 {
+		t.Errorf("unexpected sizes %v", sizes)
+	}
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"fmt"
+	"sync"
 	"sync/atomic"
 
 	"github.com/chrislusf/seaweedfs/weed/glog"
@@ -35,6 +36,16 @@ type Store struct {
 	DeletedVolumesChan  chan master_pb.VolumeShortInformationMessage
 	NewEcShardsChan     chan master_pb.VolumeEcShardInformationMessage
 	DeletedEcShardsChan chan master_pb.VolumeEcShardInformationMessage
+	draining            int32
+	fsyncPolicies       *FsyncPolicies
+	// readOnly is set before the volume server starts serving
+	readOnly bool
+	// labels are set before the volume server starts serving, reported in the full heartbeats for the placement
+	labels map[string]string
+	// fileSizeLimit is set before the volume server starts serving, reported in the heartbeats for the filers to chunk the larger files
+	fileSizeLimit int64
+	// the writes in flight hold the read lock, so draining waits for them to finish
+	writesInFlight sync.RWMutex
 }
 
 func (s *Store) String() (str string) {
@@ -47,10 +58,19 @@ func NewStore(grpcDialOption grpc.DialOption, port int, ip, publicUrl string, di
 	s.Locations = make([]*DiskLocation, 0)
 	for i := 0; i < len(dirnames); i++ {
 		location := NewDiskLocation(dirnames[i], maxVolumeCounts[i])
-		location.loadExistingVolumes(needleMapKind)
 		s.Locations = append(s.Locations, location)
 		stats.VolumeServerMaxVolumeCounter.Add(float64(maxVolumeCounts[i]))
 	}
+	// the disks are loaded concurrently, each with its own workers
+	var wg sync.WaitGroup
+	for _, location := range s.Locations {
+		wg.Add(1)
+		go func(location *DiskLocation) {
+			defer wg.Done()
+			location.loadExistingVolumes(needleMapKind)
+		}(location)
+	}
+	wg.Wait()
 	s.NewVolumesChan = make(chan master_pb.VolumeShortInformationMessage, 3)
 	s.DeletedVolumesChan = make(chan master_pb.VolumeShortInformationMessage, 3)
 
@@ -60,6 +80,9 @@ func NewStore(grpcDialOption grpc.DialOption, port int, ip, publicUrl string, di
 	return
 }
 func (s *Store) AddVolume(volumeId needle.VolumeId, collection string, needleMapKind NeedleMapType, replicaPlacement string, ttlString string, preallocate int64) error {
+	if s.readOnly {
+		return fmt.Errorf("volume server %s:%d is read only", s.Ip, s.Port)
+	}
 	rt, e := NewReplicaPlacementFromString(replicaPlacement)
 	if e != nil {
 		return e
@@ -105,10 +128,14 @@ func (s *Store) addVolume(vid needle.VolumeId, collection string, needleMapKind
 	if s.findVolume(vid) != nil {
 		return fmt.Errorf("Volume Id %d already exists!", vid)
 	}
+	if s.IsDraining() {
+		return fmt.Errorf("volume server %s:%d is draining", s.Ip, s.Port)
+	}
 	if location := s.FindFreeLocation(); location != nil {
 		glog.V(0).Infof("In dir %s adds volume:%v collection:%s replicaPlacement:%v ttl:%v",
 			location.Directory, vid, collection, replicaPlacement, ttl)
 		if volume, err := NewVolume(location.Directory, collection, vid, needleMapKind, replicaPlacement, ttl, preallocate); err == nil {
+			s.applyFsyncPolicy(volume)
 			location.SetVolume(vid, volume)
 			glog.V(0).Infof("add volume %d", vid)
 			s.NewVolumesChan <- master_pb.VolumeShortInformationMessage{
@@ -143,6 +170,7 @@ func (s *Store) Status() []*VolumeInfo {
 				ReadOnly:         v.readOnly,
 				Ttl:              v.Ttl,
 				CompactRevision:  uint32(v.CompactionRevision),
+				FsyncPolicy:      v.fsyncPolicy.String(),
 			}
 			stats = append(stats, s)
 		}
@@ -152,6 +180,17 @@ func (s *Store) Status() []*VolumeInfo {
 	return stats
 }
 
+func (s *Store) VolumeIds() (vids []uint32) {
+	for _, location := range s.Locations {
+		location.RLock()
+		for vid := range location.volumes {
+			vids = append(vids, uint32(vid))
+		}
+		location.RUnlock()
+	}
+	return
+}
+
 func (s *Store) SetDataCenter(dataCenter string) {
 	s.dataCenter = dataCenter
 }
@@ -159,6 +198,14 @@ func (s *Store) SetRack(rack string) {
 	s.rack = rack
 }
 
+func (s *Store) SetLabels(labels map[string]string) {
+	s.labels = labels
+}
+
+func (s *Store) SetFileSizeLimit(fileSizeLimit int64) {
+	s.fileSizeLimit = fileSizeLimit
+}
+
 func (s *Store) CollectHeartbeat() *master_pb.Heartbeat {
 	var volumeMessages []*master_pb.VolumeInformationMessage
 	maxVolumeCount := 0
@@ -171,7 +218,7 @@ func (s *Store) CollectHeartbeat() *master_pb.Heartbeat {
 			if maxFileKey < v.nm.MaxFileKey() {
 				maxFileKey = v.nm.MaxFileKey()
 			}
-			if !v.expired(s.GetVolumeSizeLimit()) {
+			if !v.expired(s.GetVolumeSizeLimit()) || s.readOnly {
 				volumeMessages = append(volumeMessages, v.ToVolumeInformationMessage())
 			} else {
 				if v.expiredLongEnough(MAX_TTL_VOLUME_REMOVAL_DELAY) {
@@ -201,6 +248,10 @@ func (s *Store) CollectHeartbeat() *master_pb.Heartbeat {
 		Rack:           s.rack,
 		Volumes:        volumeMessages,
 		HasNoVolumes:   len(volumeMessages) == 0,
+		IsDraining:     s.IsDraining(),
+		IsReadOnly:     s.readOnly,
+		Labels:         s.labels,
+		FileSizeLimit:  uint64(s.fileSizeLimit),
 	}
 
 }
@@ -212,6 +263,8 @@ func (s *Store) Close() {
 }
 
 func (s *Store) Write(i needle.VolumeId, n *needle.Needle) (size uint32, isUnchanged bool, err error) {
+	s.writesInFlight.RLock()
+	defer s.writesInFlight.RUnlock()
 	if v := s.findVolume(i); v != nil {
 		if v.readOnly {
 			err = fmt.Errorf("volume %d is read only", i)
@@ -231,6 +284,8 @@ func (s *Store) Write(i needle.VolumeId, n *needle.Needle) (size uint32, isUncha
 }
 
 func (s *Store) Delete(i needle.VolumeId, n *needle.Needle) (uint32, error) {
+	s.writesInFlight.RLock()
+	defer s.writesInFlight.RUnlock()
 	if v := s.findVolume(i); v != nil && !v.readOnly {
 		return v.deleteNeedle(n)
 	}
@@ -252,12 +307,34 @@ func (s *Store) HasVolume(i needle.VolumeId) bool {
 	return v != nil
 }
 
-func (s *Store) MarkVolumeReadonly(i needle.VolumeId) error {
+// MarkVolumeReadonly returns whether the volume was readonly already
+func (s *Store) MarkVolumeReadonly(i needle.VolumeId) (wasReadonly bool, err error) {
 	v := s.findVolume(i)
 	if v == nil {
-		return fmt.Errorf("volume %d not found", i)
+		return false, fmt.Errorf("volume %d not found", i)
 	}
+	v.dataFileAccessLock.Lock()
+	defer v.dataFileAccessLock.Unlock()
+	wasReadonly = v.readOnly
 	v.readOnly = true
+	return wasReadonly, nil
+}
+
+// MarkVolumeWritable undoes MarkVolumeReadonly, unless the volume cannot be written
+func (s *Store) MarkVolumeWritable(i needle.VolumeId) error {
+	v := s.findVolume(i)
+	if v == nil {
+		return fmt.Errorf("volume %d not found", i)
+	}
+	v.dataFileAccessLock.Lock()
+	defer v.dataFileAccessLock.Unlock()
+	if s.IsDraining() {
+		return fmt.Errorf("volume %d: volume server is draining", i)
+	}
+	if v.loadedReadOnly {
+		return fmt.Errorf("volume %d is loaded read only", i)
+	}
+	v.readOnly = false
 	return nil
 }
 
@@ -266,6 +343,10 @@ func (s *Store) MountVolume(i needle.VolumeId) error {
 		if found := location.LoadVolume(i, s.NeedleMapType); found == true {
 			glog.V(0).Infof("mount volume %d", i)
 			v := s.findVolume(i)
+			s.applyFsyncPolicy(v)
+			if s.IsDraining() {
+				v.readOnly = true
+			}
 			s.NewVolumesChan <- master_pb.VolumeShortInformationMessage{
 				Id:               uint32(v.Id),
 				Collection:       v.Collection,
@@ -309,6 +390,10 @@ func (s *Store) DeleteVolume(i needle.VolumeId) error {
 	if v == nil {
 		return nil
 	}
+	return s.deleteVolume(v, (*DiskLocation).deleteVolumeById)
+}
+
+func (s *Store) deleteVolume(v *Volume, deleteVolumeById func(*DiskLocation, needle.VolumeId) error) error {
 	message := master_pb.VolumeShortInformationMessage{
 		Id:               uint32(v.Id),
 		Collection:       v.Collection,
@@ -317,14 +402,40 @@ func (s *Store) DeleteVolume(i needle.VolumeId) error {
 		Ttl:              v.Ttl.ToUint32(),
 	}
 	for _, location := range s.Locations {
-		if error := location.deleteVolumeById(i); error == nil {
-			glog.V(0).Infof("DeleteVolume %d", i)
+		if error := deleteVolumeById(location, v.Id); error == nil {
+			glog.V(0).Infof("DeleteVolume %d", v.Id)
 			s.DeletedVolumesChan <- message
 			return nil
 		}
 	}
 
-	return fmt.Errorf("volume %d not found on disk", i)
+	return fmt.Errorf("volume %d not found on disk", v.Id)
+}
+
+// DeleteEmptyVolume deletes the volume only if it has no live files, also if read only.
+// The volume is made read only with the live files checked under the volume lock,
+// so that no write slips in before the deletion.
+func (s *Store) DeleteEmptyVolume(i needle.VolumeId) error {
+	v := s.findVolume(i)
+	if v == nil {
+		return nil
+	}
+	v.dataFileAccessLock.Lock()
+	if liveFileCount := v.nm.FileCount() - v.nm.DeletedCount(); liveFileCount > 0 {
+		v.dataFileAccessLock.Unlock()
+		return fmt.Errorf("volume %d still has %d files", i, liveFileCount)
+	}
+	wasReadOnly := v.readOnly
+	v.readOnly = true
+	v.dataFileAccessLock.Unlock()
+
+	if err := s.deleteVolume(v, (*DiskLocation).deleteEmptyVolumeById); err != nil {
+		v.dataFileAccessLock.Lock()
+		v.readOnly = wasReadOnly
+		v.dataFileAccessLock.Unlock()
+		return err
+	}
+	return nil
 }
 
 func (s *Store) SetVolumeSizeLimit(x uint64) {
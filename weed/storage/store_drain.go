@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"sync/atomic"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+)
+
+// MarkDraining stops this store from taking new volumes, marks all existing volumes read only,
+// and waits for the writes in flight to finish, so the volume files are not changed any more.
+// The draining state is reported to the master with the next heartbeat, and is kept until the volume server restarts.
+func (s *Store) MarkDraining() {
+	if atomic.SwapInt32(&s.draining, 1) == 0 {
+		glog.V(0).Infof("volume server %s:%d starts draining", s.Ip, s.Port)
+	}
+	for _, location := range s.Locations {
+		location.Lock()
+		for _, v := range location.volumes {
+			// the writes check the read only flag again under the volume lock
+			v.dataFileAccessLock.Lock()
+			v.readOnly = true
+			v.dataFileAccessLock.Unlock()
+		}
+		location.Unlock()
+	}
+	s.writesInFlight.Lock()
+	s.writesInFlight.Unlock()
+}
+
+func (s *Store) IsDraining() bool {
+	return atomic.LoadInt32(&s.draining) == 1
+}
+
+// CountVolumesAndEcShards returns the number of volumes and ec shards still stored locally.
+func (s *Store) CountVolumesAndEcShards() (volumeCount, ecShardCount int) {
+	for _, location := range s.Locations {
+		volumeCount += location.VolumesLen()
+		location.ecVolumesLock.RLock()
+		for _, ecVolume := range location.ecVolumes {
+			ecShardCount += len(ecVolume.Shards)
+		}
+		location.ecVolumesLock.RUnlock()
+	}
+	return
+}
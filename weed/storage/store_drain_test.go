@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMarkDraining(t *testing.T) {
+	dir, err := ioutil.TempDir("", "drain")
+	if err != nil {
+		t.Fatalf("temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := NewStore(nil, 8080, "localhost", "localhost:8080", []string{dir}, []int{10}, NeedleMapInMemory)
+	defer s.Close()
+	if err = s.AddVolume(1, "", NeedleMapInMemory, "000", "", 0); err != nil {
+		t.Fatalf("add volume: %v", err)
+	}
+	if _, _, err = s.Write(1, newRandomNeedle(1)); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	// a write in flight
+	s.writesInFlight.RLock()
+	drained := make(chan struct{})
+	go func() {
+		s.MarkDraining()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		t.Errorf("drained before the write in flight finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+	s.writesInFlight.RUnlock()
+	<-drained
+
+	if !s.IsDraining() {
+		t.Errorf("not draining")
+	}
+	if _, _, err = s.Write(1, newRandomNeedle(2)); err == nil {
+		t.Errorf("written after draining")
+	}
+	if _, err = s.GetVolume(1).deleteNeedle(newEmptyNeedle(1)); err == nil {
+		t.Errorf("deleted after draining")
+	}
+	if err = s.AddVolume(2, "", NeedleMapInMemory, "000", "", 0); err == nil {
+		t.Errorf("added volume after draining")
+	}
+}
+
+func TestMarkVolumeWritable(t *testing.T) {
+	dir, err := ioutil.TempDir("", "writable")
+	if err != nil {
+		t.Fatalf("temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := NewStore(nil, 8080, "localhost", "localhost:8080", []string{dir}, []int{10}, NeedleMapInMemory)
+	defer s.Close()
+	if err = s.AddVolume(1, "", NeedleMapInMemory, "000", "", 0); err != nil {
+		t.Fatalf("add volume: %v", err)
+	}
+
+	if wasReadonly, err := s.MarkVolumeReadonly(1); err != nil || wasReadonly {
+		t.Fatalf("mark readonly: %v, was readonly %v", err, wasReadonly)
+	}
+	if wasReadonly, err := s.MarkVolumeReadonly(1); err != nil || !wasReadonly {
+		t.Errorf("mark readonly again: %v, was readonly %v", err, wasReadonly)
+	}
+	if _, _, err = s.Write(1, newRandomNeedle(1)); err == nil {
+		t.Errorf("written to the readonly volume")
+	}
+
+	if err = s.MarkVolumeWritable(1); err != nil {
+		t.Fatalf("mark writable: %v", err)
+	}
+	if _, _, err = s.Write(1, newRandomNeedle(2)); err != nil {
+		t.Errorf("write to the writable volume: %v", err)
+	}
+
+	s.GetVolume(1).loadedReadOnly = true
+	if err = s.MarkVolumeWritable(1); err == nil {
+		t.Errorf("marked writable the volume loaded read only")
+	}
+	s.GetVolume(1).loadedReadOnly = false
+
+	s.MarkDraining()
+	if err = s.MarkVolumeWritable(1); err == nil {
+		t.Errorf("marked writable while draining")
+	}
+}
@@ -25,6 +25,10 @@ func (s *Store) CollectErasureCodingHeartbeat() *master_pb.Heartbeat {
 	for _, location := range s.Locations {
 		location.ecVolumesLock.RLock()
 		for _, ecShards := range location.ecVolumes {
+			// the deletions journaled since the last heartbeat are synced into the .ecx file
+			if err := ecShards.CompactJournal(); err != nil {
+				glog.V(0).Infof("compact ec volume %d journal: %v", ecShards.VolumeId, err)
+			}
 			ecShardMessages = append(ecShardMessages, ecShards.ToVolumeEcShardInformationMessage()...)
 
 			for _, ecShard := range ecShards.Shards {
@@ -109,6 +113,27 @@ func (s *Store) FindEcVolume(vid needle.VolumeId) (*erasure_coding.EcVolume, boo
 	return nil, false
 }
 
+// InvalidateEcShardLocations makes the cached shard locations of the ec volumes stale,
+// as told by the master once the shard locations changed
+func (s *Store) InvalidateEcShardLocations(all bool, vids []uint32) {
+	if all {
+		for _, location := range s.Locations {
+			location.ecVolumesLock.RLock()
+			for _, ecVolume := range location.ecVolumes {
+				ecVolume.InvalidateShardLocations()
+			}
+			location.ecVolumesLock.RUnlock()
+		}
+		return
+	}
+	for _, vid := range vids {
+		if ecVolume, found := s.FindEcVolume(needle.VolumeId(vid)); found {
+			glog.V(3).Infof("invalidate ec volume %d cached locations", vid)
+			ecVolume.InvalidateShardLocations()
+		}
+	}
+}
+
 func (s *Store) DestroyEcVolume(vid needle.VolumeId) {
 	for _, location := range s.Locations {
 		location.DestroyEcVolume(vid)
@@ -238,16 +263,37 @@ func forgetShardId(ecVolume *erasure_coding.EcVolume, shardId erasure_coding.Sha
 	ecVolume.ShardLocationsLock.Unlock()
 }
 
+// the cached shard locations are refreshed after these durations, or once the master tells they changed.
+// The fewer shards located, the sooner the missing shards are looked up again.
+const (
+	ecShardLocationsIncompleteTtl = 11 * time.Second
+	ecShardLocationsReadableTtl   = 7 * time.Minute
+	ecShardLocationsCompleteTtl   = 37 * time.Minute
+)
+
+func isEcShardLocationsFresh(ecVolume *erasure_coding.EcVolume) bool {
+	ecVolume.ShardLocationsLock.RLock()
+	defer ecVolume.ShardLocationsLock.RUnlock()
+
+	ttl := ecShardLocationsIncompleteTtl
+	if shardCount := len(ecVolume.ShardLocations); shardCount == erasure_coding.TotalShardsCount {
+		ttl = ecShardLocationsCompleteTtl
+	} else if shardCount >= erasure_coding.DataShardsCount {
+		ttl = ecShardLocationsReadableTtl
+	}
+	return ecVolume.ShardLocationsRefreshTime.Add(ttl).After(time.Now())
+}
+
 func (s *Store) cachedLookupEcShardLocations(ctx context.Context, ecVolume *erasure_coding.EcVolume) (err error) {
 
-	shardCount := len(ecVolume.ShardLocations)
-	if shardCount < erasure_coding.DataShardsCount &&
-		ecVolume.ShardLocationsRefreshTime.Add(11*time.Second).After(time.Now()) ||
-		shardCount == erasure_coding.TotalShardsCount &&
-			ecVolume.ShardLocationsRefreshTime.Add(37*time.Minute).After(time.Now()) ||
-		shardCount >= erasure_coding.DataShardsCount &&
-			ecVolume.ShardLocationsRefreshTime.Add(7*time.Minute).After(time.Now()) {
-		// still fresh
+	if isEcShardLocationsFresh(ecVolume) {
+		return nil
+	}
+
+	// the concurrent degraded reads share one lookup
+	ecVolume.ShardLocationsLookupLock.Lock()
+	defer ecVolume.ShardLocationsLookupLock.Unlock()
+	if isEcShardLocationsFresh(ecVolume) {
 		return nil
 	}
 
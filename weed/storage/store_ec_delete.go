@@ -32,6 +32,9 @@ func (s *Store) DeleteEcShardNeedle(ctx context.Context, ecVolume *erasure_codin
 
 }
 
+// doDeleteNeedleFromAtLeastOneRemoteEcShards marks the needle deleted in the .ecx file on every server
+// holding any shard of the ec volume, so that the deletion is consistent whichever shard is read later.
+// It fails only if no server has deleted the needle.
 func (s *Store) doDeleteNeedleFromAtLeastOneRemoteEcShards(ctx context.Context, ecVolume *erasure_coding.EcVolume, needleId types.NeedleId) error {
 
 	_, _, intervals, err := ecVolume.LocateEcShardNeedle(needleId, ecVolume.Version)
@@ -40,18 +43,31 @@ func (s *Store) doDeleteNeedleFromAtLeastOneRemoteEcShards(ctx context.Context,
 		return erasure_coding.NotFoundError
 	}
 
-	shardId, _ := intervals[0].ToShardIdAndOffset(erasure_coding.ErasureCodingLargeBlockSize, erasure_coding.ErasureCodingSmallBlockSize)
+	ecVolume.ShardLocationsLock.RLock()
+	var sourceDataNodes []string
+	visited := make(map[string]bool)
+	for shardId := erasure_coding.ShardId(0); shardId < erasure_coding.TotalShardsCount; shardId++ {
+		for _, sourceDataNode := range ecVolume.ShardLocations[shardId] {
+			if !visited[sourceDataNode] {
+				visited[sourceDataNode] = true
+				sourceDataNodes = append(sourceDataNodes, sourceDataNode)
+			}
+		}
+	}
+	ecVolume.ShardLocationsLock.RUnlock()
 
-	hasDeletionSuccess := false
-	err = s.doDeleteNeedleFromRemoteEcShardServers(ctx, shardId, ecVolume, needleId)
-	if err == nil {
-		hasDeletionSuccess = true
+	if len(sourceDataNodes) == 0 {
+		return fmt.Errorf("ec volume %d shards not located", ecVolume.VolumeId)
 	}
 
-	for shardId = erasure_coding.DataShardsCount; shardId < erasure_coding.TotalShardsCount; shardId++ {
-		if parityDeletionError := s.doDeleteNeedleFromRemoteEcShardServers(ctx, shardId, ecVolume, needleId); parityDeletionError == nil {
-			hasDeletionSuccess = true
+	hasDeletionSuccess := false
+	for _, sourceDataNode := range sourceDataNodes {
+		glog.V(4).Infof("delete from remote ec volume %d on %s", ecVolume.VolumeId, sourceDataNode)
+		if err = s.doDeleteNeedleFromRemoteEcShard(ctx, sourceDataNode, ecVolume.VolumeId, ecVolume.Collection, ecVolume.Version, needleId); err != nil {
+			glog.V(0).Infof("delete from remote ec volume %d on %s: %v", ecVolume.VolumeId, sourceDataNode, err)
+			continue
 		}
+		hasDeletionSuccess = true
 	}
 
 	if hasDeletionSuccess {
@@ -62,29 +78,6 @@ func (s *Store) doDeleteNeedleFromAtLeastOneRemoteEcShards(ctx context.Context,
 
 }
 
-func (s *Store) doDeleteNeedleFromRemoteEcShardServers(ctx context.Context, shardId erasure_coding.ShardId, ecVolume *erasure_coding.EcVolume, needleId types.NeedleId) error {
-
-	ecVolume.ShardLocationsLock.RLock()
-	sourceDataNodes, hasShardLocations := ecVolume.ShardLocations[shardId]
-	ecVolume.ShardLocationsLock.RUnlock()
-
-	if !hasShardLocations {
-		return fmt.Errorf("ec shard %d.%d not located", ecVolume.VolumeId, shardId)
-	}
-
-	for _, sourceDataNode := range sourceDataNodes {
-		glog.V(4).Infof("delete from remote ec shard %d.%d from %s", ecVolume.VolumeId, shardId, sourceDataNode)
-		err := s.doDeleteNeedleFromRemoteEcShard(ctx, sourceDataNode, ecVolume.VolumeId, ecVolume.Collection, ecVolume.Version, needleId)
-		if err != nil {
-			return err
-		}
-		glog.V(1).Infof("delete from remote ec shard %d.%d from %s: %v", ecVolume.VolumeId, shardId, sourceDataNode, err)
-	}
-
-	return nil
-
-}
-
 func (s *Store) doDeleteNeedleFromRemoteEcShard(ctx context.Context, sourceDataNode string, vid needle.VolumeId, collection string, version needle.Version, needleId types.NeedleId) error {
 
 	return operation.WithVolumeServerClient(sourceDataNode, s.grpcDialOption, func(client volume_server_pb.VolumeServerClient) error {
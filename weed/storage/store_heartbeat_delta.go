@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
+	"github.com/golang/protobuf/proto"
+)
+
+// HeartbeatDelta remembers the volumes last sent to the master, so after the full heartbeat on connect
+// the periodic heartbeats only carry the changed volumes, and the master does not go through all the volumes every time.
+type HeartbeatDelta struct {
+	sent map[uint32]*master_pb.VolumeInformationMessage
+}
+
+// NewHeartbeatDelta starts from the full heartbeat sent on connect
+func NewHeartbeatDelta(full *master_pb.Heartbeat) *HeartbeatDelta {
+	d := &HeartbeatDelta{sent: make(map[uint32]*master_pb.VolumeInformationMessage, len(full.Volumes))}
+	for _, v := range full.Volumes {
+		d.sent[v.Id] = v
+	}
+	return d
+}
+
+// Delta turns a full heartbeat into a delta one, with the volumes changed or gone since the last heartbeat
+func (d *HeartbeatDelta) Delta(full *master_pb.Heartbeat) *master_pb.Heartbeat {
+	delta := &master_pb.Heartbeat{
+		Ip:             full.Ip,
+		Port:           full.Port,
+		PublicUrl:      full.PublicUrl,
+		MaxVolumeCount: full.MaxVolumeCount,
+		MaxFileKey:     full.MaxFileKey,
+		DataCenter:     full.DataCenter,
+		Rack:           full.Rack,
+		IsDraining:     full.IsDraining,
+		IsReadOnly:     full.IsReadOnly,
+		IsDelta:        true,
+	}
+	current := make(map[uint32]*master_pb.VolumeInformationMessage, len(full.Volumes))
+	for _, v := range full.Volumes {
+		current[v.Id] = v
+		if sent, found := d.sent[v.Id]; !found || !proto.Equal(sent, v) {
+			delta.UpdatedVolumes = append(delta.UpdatedVolumes, v)
+		}
+	}
+	for id, v := range d.sent {
+		if _, found := current[id]; !found {
+			delta.DeletedVolumes = append(delta.DeletedVolumes, &master_pb.VolumeShortInformationMessage{
+				Id:               v.Id,
+				Collection:       v.Collection,
+				ReplicaPlacement: v.ReplicaPlacement,
+				Version:          v.Version,
+				Ttl:              v.Ttl,
+			})
+		}
+	}
+	d.sent = current
+	return delta
+}
@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
+)
+
+func TestHeartbeatDelta(t *testing.T) {
+	full := &master_pb.Heartbeat{
+		Ip: "localhost",
+		Volumes: []*master_pb.VolumeInformationMessage{
+			{Id: 1, Size: 100},
+			{Id: 2, Size: 200},
+		},
+	}
+	d := NewHeartbeatDelta(full)
+
+	delta := d.Delta(&master_pb.Heartbeat{
+		Ip: "localhost",
+		Volumes: []*master_pb.VolumeInformationMessage{
+			{Id: 1, Size: 100},
+			{Id: 2, Size: 250},
+			{Id: 3, Size: 0},
+		},
+		IsReadOnly: true,
+	})
+	if !delta.IsDelta || !delta.IsReadOnly || len(delta.Volumes) != 0 {
+		t.Errorf("unexpected delta heartbeat %v", delta)
+	}
+	if len(delta.UpdatedVolumes) != 2 || delta.UpdatedVolumes[0].Id != 2 || delta.UpdatedVolumes[1].Id != 3 {
+		t.Errorf("updated volumes %v, expected 2 and 3", delta.UpdatedVolumes)
+	}
+	if len(delta.DeletedVolumes) != 0 {
+		t.Errorf("deleted volumes %v, expected none", delta.DeletedVolumes)
+	}
+
+	delta = d.Delta(&master_pb.Heartbeat{
+		Ip: "localhost",
+		Volumes: []*master_pb.VolumeInformationMessage{
+			{Id: 2, Size: 250},
+			{Id: 3, Size: 0},
+		},
+	})
+	if len(delta.UpdatedVolumes) != 0 {
+		t.Errorf("updated volumes %v, expected none", delta.UpdatedVolumes)
+	}
+	if len(delta.DeletedVolumes) != 1 || delta.DeletedVolumes[0].Id != 1 {
+		t.Errorf("deleted volumes %v, expected 1", delta.DeletedVolumes)
+	}
+}
@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"github.com/chrislusf/seaweedfs/weed/glog"
+)
+
+// SetReadOnly makes this store only serve the reads, for the archival volume servers.
+// All volumes are read only, no new volumes are taken, and the expired volumes are kept.
+// It is reported to the master with the heartbeats, and is kept until the volume server restarts.
+func (s *Store) SetReadOnly() {
+	s.readOnly = true
+	glog.V(0).Infof("volume server %s:%d is read only", s.Ip, s.Port)
+	for _, location := range s.Locations {
+		location.Lock()
+		for _, v := range location.volumes {
+			v.readOnly = true
+		}
+		location.Unlock()
+	}
+}
+
+func (s *Store) IsReadOnly() bool {
+	return s.readOnly
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/stats"
 	"github.com/chrislusf/seaweedfs/weed/storage/needle"
 )
 
@@ -16,6 +17,10 @@ func (s *Store) CheckCompactVolume(volumeId needle.VolumeId) (float64, error) {
 }
 func (s *Store) CompactVolume(vid needle.VolumeId, preallocate int64, compactionBytePerSecond int64) error {
 	if v := s.findVolume(vid); v != nil {
+		if !hasSpaceToCopy(v) {
+			glog.V(0).Infof("not enough free disk space to copy volume %d, compact it in place", vid)
+			return v.CompactInPlace(compactionBytePerSecond)
+		}
 		return v.Compact(preallocate, compactionBytePerSecond)
 	}
 	return fmt.Errorf("volume id %d is not found during compact", vid)
@@ -32,3 +37,18 @@ func (s *Store) CommitCleanupVolume(vid needle.VolumeId) error {
 	}
 	return fmt.Errorf("volume id %d is not found during cleaning up", vid)
 }
+
+// hasSpaceToCopy checks whether the disk can hold the copy of the live needles during compaction
+func hasSpaceToCopy(v *Volume) bool {
+	disk := stats.NewDiskStatus(v.dir)
+	if disk.All == 0 {
+		// unknown disk status
+		return true
+	}
+	datSize, idxSize, _ := v.FileStat()
+	liveSize := datSize + idxSize
+	if deletedSize := v.nm.DeletedSize(); deletedSize < liveSize {
+		liveSize -= deletedSize
+	}
+	return disk.Free > liveSize+liveSize/10
+}
@@ -32,8 +32,16 @@ type Volume struct {
 	lastModifiedTsSeconds uint64 //unix time in seconds
 	lastAppendAtNs        uint64 //unix time in nanoseconds
 
-	lastCompactIndexOffset uint64
-	lastCompactRevision    uint16
+	compactionLog         *os.File // the needles appended during the compaction, to replay at commit
+	compactionTailOffset  int64    // the .dat size when the compaction log started, the compaction copies only the needles before it
+	fsyncPolicy           FsyncPolicy
+	groupSyncer           *groupSyncer
+	inPlaceCompactionLock sync.RWMutex // blocks the reads while the needles are moved in place
+	compactedInPlace      bool         // nothing to commit or clean up after CompactInPlace
+	dataIntegrityChecked  bool         // write the checkpoint when closed, to skip the checking next time
+	expiries              needleExpiries
+	compactedAtSeconds    uint64 // the needles expired by then are dropped by the vacuum
+	loadedReadOnly        bool   // the file is not writable, or failed the integrity check, when loaded
 }
 
 func NewVolume(dirname string, collection string, id needle.VolumeId, needleMapKind NeedleMapType, replicaPlacement *ReplicaPlacement, ttl *needle.TTL, preallocate int64) (v *Volume, e error) {
@@ -96,6 +104,10 @@ func (v *Volume) FileCount() uint64 {
 func (v *Volume) Close() {
 	v.dataFileAccessLock.Lock()
 	defer v.dataFileAccessLock.Unlock()
+	if v.groupSyncer != nil {
+		v.groupSyncer.stop(v.syncFiles)
+		v.groupSyncer = nil
+	}
 	if v.nm != nil {
 		v.nm.Close()
 		v.nm = nil
@@ -104,6 +116,11 @@ func (v *Volume) Close() {
 		_ = v.dataFile.Close()
 		v.dataFile = nil
 		stats.VolumeServerVolumeCounter.WithLabelValues(v.Collection, "volume").Dec()
+		if v.dataIntegrityChecked {
+			if err := writeIndexCheckpoint(v.FileName(), v.CompactionRevision, v.lastAppendAtNs); err != nil {
+				glog.V(1).Infof("write checkpoint of volume %s: %v", v.FileName(), err)
+			}
+		}
 	}
 }
 
@@ -170,5 +187,6 @@ func (v *Volume) ToVolumeInformationMessage() *master_pb.VolumeInformationMessag
 		Ttl:              v.Ttl.ToUint32(),
 		CompactRevision:  uint32(v.SuperBlock.CompactionRevision),
 		ModifiedAtSecond: modTime.Unix(),
+		FsyncPolicy:      v.fsyncPolicy.String(),
 	}
 }
@@ -16,11 +16,12 @@ import (
 
 func (v *Volume) GetVolumeSyncStatus() *volume_server_pb.VolumeSyncStatusResponse {
 	var syncStatus = &volume_server_pb.VolumeSyncStatusResponse{}
+	// the index entries are appended after the data, so the .dat tail covers all the entries up to the .idx size
+	syncStatus.IdxFileSize = v.nm.IndexFileSize()
 	if stat, err := v.dataFile.Stat(); err == nil {
 		syncStatus.TailOffset = uint64(stat.Size())
 	}
 	syncStatus.Collection = v.Collection
-	syncStatus.IdxFileSize = v.nm.IndexFileSize()
 	syncStatus.CompactRevision = uint32(v.SuperBlock.CompactionRevision)
 	syncStatus.Ttl = v.SuperBlock.Ttl.String()
 	syncStatus.Replication = v.SuperBlock.ReplicaPlacement.String()
@@ -64,3 +64,45 @@ func verifyNeedleIntegrity(datFile *os.File, v needle.Version, offset int64, key
 	}
 	return n.AppendAtNs, err
 }
+
+// VerifyVolumeFiles checks the crc of the needles in the .dat file indexed by the .idx file,
+// to detect the data corrupted when the volume files are copied.
+func VerifyVolumeFiles(datFileName, idxFileName string) error {
+	datFile, err := os.Open(datFileName)
+	if err != nil {
+		return err
+	}
+	defer datFile.Close()
+	indexFile, err := os.Open(idxFileName)
+	if err != nil {
+		return err
+	}
+	defer indexFile.Close()
+
+	superBlock, err := ReadSuperBlock(datFile)
+	if err != nil {
+		return err
+	}
+
+	var corrupted int
+	var firstErr error
+	err = idx.WalkIndexFile(indexFile, func(key NeedleId, offset Offset, size uint32) error {
+		if offset.IsZero() || size == TombstoneFileSize {
+			return nil
+		}
+		if _, verifyErr := verifyNeedleIntegrity(datFile, superBlock.Version(), offset.ToAcutalOffset(), key, size); verifyErr != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("needle %v at %d: %v", key, offset.ToAcutalOffset(), verifyErr)
+			}
+			corrupted++
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("walk index file %s: %v", idxFileName, err)
+	}
+	if corrupted > 0 {
+		return fmt.Errorf("%s has %d corrupted needles, the first %v", datFileName, corrupted, firstErr)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+	. "github.com/chrislusf/seaweedfs/weed/storage/types"
+)
+
+func TestVerifyVolumeFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "verify")
+	if err != nil {
+		t.Fatalf("temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	v, err := NewVolume(dir, "", 1, NeedleMapInMemory, &ReplicaPlacement{}, &needle.TTL{}, 0)
+	if err != nil {
+		t.Fatalf("volume creation: %v", err)
+	}
+	for i := 1; i <= 3; i++ {
+		n := newRandomNeedle(uint64(i))
+		n.Data = append(n.Data, 'x')
+		n.Checksum = needle.NewCRC(n.Data)
+		if _, _, _, err = v.writeNeedle(n); err != nil {
+			t.Fatalf("write needle %d: %v", i, err)
+		}
+	}
+	if _, err = v.deleteNeedle(newEmptyNeedle(2)); err != nil {
+		t.Fatalf("delete needle: %v", err)
+	}
+	nv, _ := v.nm.Get(NeedleId(3))
+	v.Close()
+
+	datFileName, idxFileName := v.FileName()+".dat", v.FileName()+".idx"
+	if err = VerifyVolumeFiles(datFileName, idxFileName); err != nil {
+		t.Fatalf("verify the volume files: %v", err)
+	}
+
+	// flip the first data byte of needle 3, after the header and the data size
+	datFile, err := os.OpenFile(datFileName, os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataOffset := nv.Offset.ToAcutalOffset() + NeedleHeaderSize + 4
+	b := make([]byte, 1)
+	datFile.ReadAt(b, dataOffset)
+	b[0] ^= 0xff
+	datFile.WriteAt(b, dataOffset)
+	datFile.Close()
+
+	if err = VerifyVolumeFiles(datFileName, idxFileName); err == nil {
+		t.Errorf("verified the corrupted needle")
+	}
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/util"
+)
+
+/*
+The .ckp file is written when the volume is closed cleanly, to skip the integrity checking when loaded again:
+
+	magic                   4 bytes
+	compaction revision     2 bytes, the generation of the .dat and .idx files
+	.dat file size          8 bytes
+	.dat modified time      8 bytes, in nanoseconds
+	.idx file size          8 bytes
+	.idx modified time      8 bytes, in nanoseconds
+	last append time        8 bytes, in nanoseconds
+	crc32 of the above      4 bytes
+
+The writable volumes remove the .ckp file once loaded, so a crash before the next clean close checks the volume again.
+
+The checkpoint is not kept in the super block extra: the needles start right after the extra,
+so its size can not change once the volume has data, and rewriting the head of the .dat file
+on every close would change the very file the checkpoint vouches for.
+*/
+
+const (
+	indexCheckpointMagic = 0x434b5031 // "CKP1"
+	indexCheckpointSize  = 4 + 2 + 8 + 8 + 8 + 8 + 8 + 4
+)
+
+var indexCheckpointTable = crc32.MakeTable(crc32.Castagnoli)
+
+func indexCheckpointBytes(fileName string, compactionRevision uint16, lastAppendAtNs uint64) ([]byte, error) {
+	datStat, err := os.Stat(fileName + ".dat")
+	if err != nil {
+		return nil, err
+	}
+	idxStat, err := os.Stat(fileName + ".idx")
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, indexCheckpointSize)
+	util.Uint32toBytes(b[0:4], indexCheckpointMagic)
+	util.Uint16toBytes(b[4:6], compactionRevision)
+	util.Uint64toBytes(b[6:14], uint64(datStat.Size()))
+	util.Uint64toBytes(b[14:22], uint64(datStat.ModTime().UnixNano()))
+	util.Uint64toBytes(b[22:30], uint64(idxStat.Size()))
+	util.Uint64toBytes(b[30:38], uint64(idxStat.ModTime().UnixNano()))
+	util.Uint64toBytes(b[38:46], lastAppendAtNs)
+	util.Uint32toBytes(b[46:50], crc32.Checksum(b[0:46], indexCheckpointTable))
+	return b, nil
+}
+
+// writeIndexCheckpoint records the .dat and .idx files as checked, after they are closed
+func writeIndexCheckpoint(fileName string, compactionRevision uint16, lastAppendAtNs uint64) error {
+	b, err := indexCheckpointBytes(fileName, compactionRevision, lastAppendAtNs)
+	if err != nil {
+		return err
+	}
+	tempFileName := fileName + ".ckp.tmp"
+	if err = ioutil.WriteFile(tempFileName, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tempFileName, fileName+".ckp")
+}
+
+// readIndexCheckpoint returns the last append time, if the .dat and .idx files are not changed since the checkpoint
+func readIndexCheckpoint(fileName string, compactionRevision uint16) (lastAppendAtNs uint64, err error) {
+	b, err := ioutil.ReadFile(fileName + ".ckp")
+	if err != nil {
+		return 0, err
+	}
+	if len(b) != indexCheckpointSize || util.BytesToUint32(b[0:4]) != indexCheckpointMagic {
+		return 0, fmt.Errorf("unexpected %s.ckp", fileName)
+	}
+	if util.BytesToUint32(b[46:50]) != crc32.Checksum(b[0:46], indexCheckpointTable) {
+		return 0, fmt.Errorf("checksum mismatch in %s.ckp", fileName)
+	}
+	lastAppendAtNs = util.BytesToUint64(b[38:46])
+	current, err := indexCheckpointBytes(fileName, compactionRevision, lastAppendAtNs)
+	if err != nil {
+		return 0, err
+	}
+	if string(current) != string(b) {
+		return 0, fmt.Errorf("%s.dat or %s.idx changed since the checkpoint", fileName, fileName)
+	}
+	return lastAppendAtNs, nil
+}
+
+// checkVolumeDataIntegrity skips the checking if the volume is not changed since it was closed cleanly
+func (v *Volume) checkVolumeDataIntegrity(indexFile *os.File) (lastAppendAtNs uint64, err error) {
+	fileName := v.FileName()
+	if lastAppendAtNs, err = readIndexCheckpoint(fileName, v.CompactionRevision); err == nil {
+		glog.V(1).Infof("volume %s is not changed since the checkpoint", fileName)
+	} else {
+		if !os.IsNotExist(err) {
+			glog.V(0).Infof("check volume %s: %v", fileName, err)
+		}
+		if lastAppendAtNs, err = CheckVolumeDataIntegrity(v, indexFile); err != nil {
+			return
+		}
+	}
+	if !v.readOnly {
+		os.Remove(fileName + ".ckp")
+	}
+	return
+}
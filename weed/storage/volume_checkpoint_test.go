@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIndexCheckpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "volume_checkpoint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fileName := filepath.Join(dir, "1")
+	if err = ioutil.WriteFile(fileName+".dat", make([]byte, 64), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(fileName+".idx", make([]byte, 16), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = readIndexCheckpoint(fileName, 0); !os.IsNotExist(err) {
+		t.Fatalf("no checkpoint yet: %v", err)
+	}
+	if err = writeIndexCheckpoint(fileName, 3, 12345); err != nil {
+		t.Fatalf("write checkpoint: %v", err)
+	}
+	lastAppendAtNs, err := readIndexCheckpoint(fileName, 3)
+	if err != nil || lastAppendAtNs != 12345 {
+		t.Fatalf("read checkpoint: %d %v", lastAppendAtNs, err)
+	}
+
+	// another generation of the volume
+	if _, err = readIndexCheckpoint(fileName, 4); err == nil {
+		t.Errorf("the checkpoint of another compaction revision should not be used")
+	}
+
+	// the index is appended after the checkpoint
+	f, err := os.OpenFile(fileName+".idx", os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write(make([]byte, 16))
+	f.Close()
+	if _, err = readIndexCheckpoint(fileName, 3); err == nil {
+		t.Errorf("the checkpoint of the changed index should not be used")
+	}
+}
@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"sync"
+
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+	. "github.com/chrislusf/seaweedfs/weed/storage/types"
+)
+
+type needleExpiry struct {
+	expireAt uint64 // unix time in seconds
+	size     uint32
+}
+
+// needleExpiries are the live needles expiring earlier than their volume, to count the expired ones as garbage.
+// The needles written before the volume is loaded are tracked only after the next vacuum copies them.
+type needleExpiries struct {
+	sync.Mutex
+	needles map[NeedleId]needleExpiry
+}
+
+// needleExpireAt is when the needle expires by its own ttl, or 0 if it lives as long as the volume
+func (v *Volume) needleExpireAt(n *needle.Needle) uint64 {
+	expireAt := n.ExpireAtSeconds()
+	if expireAt == 0 {
+		return 0
+	}
+	if v.Ttl != nil && v.Ttl.Minutes() > 0 && n.Ttl.Minutes() >= v.Ttl.Minutes() {
+		return 0
+	}
+	return expireAt
+}
+
+func (e *needleExpiries) put(key NeedleId, expireAt uint64, size uint32, overwrite bool) {
+	e.Lock()
+	defer e.Unlock()
+	if e.needles == nil {
+		e.needles = make(map[NeedleId]needleExpiry)
+	}
+	if _, found := e.needles[key]; found && !overwrite {
+		return
+	}
+	e.needles[key] = needleExpiry{expireAt: expireAt, size: size}
+}
+
+func (e *needleExpiries) remove(key NeedleId) {
+	e.Lock()
+	defer e.Unlock()
+	delete(e.needles, key)
+}
+
+// removeExpired forgets the needles expired by the time, which the vacuum has dropped
+func (e *needleExpiries) removeExpired(nowSeconds uint64) {
+	e.Lock()
+	defer e.Unlock()
+	for key, x := range e.needles {
+		if nowSeconds >= x.expireAt {
+			delete(e.needles, key)
+		}
+	}
+}
+
+func (e *needleExpiries) expiredSize(nowSeconds uint64) (size uint64) {
+	e.Lock()
+	defer e.Unlock()
+	for _, x := range e.needles {
+		if nowSeconds >= x.expireAt {
+			size += uint64(x.size)
+		}
+	}
+	return
+}
@@ -0,0 +1,329 @@
+package storage
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+)
+
+type FsyncLevel int
+
+// the levels are ordered by durability
+const (
+	FsyncNever    FsyncLevel = iota // leave it to the OS to flush the .dat and .idx files
+	FsyncInterval                   // group commit, the writes wait for the next sync
+	FsyncGroup                      // group commit, the .idx entries of the writes within a short window are written and synced together
+	FsyncPerWrite                   // sync the .dat and .idx files before acknowledging each write
+)
+
+// the group commit syncs right away once so many writes are waiting, if not set in the policy
+const defaultGroupCommitMaxWrites = 128
+
+// FsyncPolicy controls when the .dat and .idx writes are synced to the disk
+type FsyncPolicy struct {
+	Level     FsyncLevel
+	Interval  time.Duration // the sync interval, or the group commit window
+	MaxWrites int           // the group commit syncs once so many writes are waiting
+}
+
+// ParseFsyncPolicy parses "never", "per-write", "interval=<duration>", or "group=<window>[/<max writes>]",
+// e.g. "interval=100ms" or "group=2ms/64"
+func ParseFsyncPolicy(s string) (p FsyncPolicy, err error) {
+	switch {
+	case s == "" || s == "never":
+		p.Level = FsyncNever
+	case s == "per-write":
+		p.Level = FsyncPerWrite
+	case strings.HasPrefix(s, "interval="):
+		p.Level = FsyncInterval
+		if p.Interval, err = time.ParseDuration(strings.TrimPrefix(s, "interval=")); err != nil {
+			return p, fmt.Errorf("fsync policy %s: %v", s, err)
+		}
+		if p.Interval <= 0 {
+			return p, fmt.Errorf("fsync policy %s: the interval should be positive", s)
+		}
+	case strings.HasPrefix(s, "group="):
+		p.Level, p.MaxWrites = FsyncGroup, defaultGroupCommitMaxWrites
+		window := strings.TrimPrefix(s, "group=")
+		if i := strings.Index(window, "/"); i >= 0 {
+			if p.MaxWrites, err = strconv.Atoi(window[i+1:]); err != nil || p.MaxWrites <= 0 {
+				return p, fmt.Errorf("fsync policy %s: the max writes should be a positive number", s)
+			}
+			window = window[:i]
+		}
+		if p.Interval, err = time.ParseDuration(window); err != nil {
+			return p, fmt.Errorf("fsync policy %s: %v", s, err)
+		}
+		if p.Interval <= 0 {
+			return p, fmt.Errorf("fsync policy %s: the window should be positive", s)
+		}
+	default:
+		return p, fmt.Errorf("unknown fsync policy %s, expecting never, per-write, interval=<duration>, or group=<window>[/<max writes>]", s)
+	}
+	return p, nil
+}
+
+func (p FsyncPolicy) String() string {
+	switch p.Level {
+	case FsyncPerWrite:
+		return "per-write"
+	case FsyncInterval:
+		return "interval=" + p.Interval.String()
+	case FsyncGroup:
+		return fmt.Sprintf("group=%v/%d", p.Interval, p.MaxWrites)
+	}
+	return "never"
+}
+
+// FsyncPolicies has the fsync policy for each collection, and the default one for the other collections
+type FsyncPolicies struct {
+	Default     FsyncPolicy
+	Collections map[string]FsyncPolicy
+}
+
+// ParseFsyncPolicies parses comma separated policies, each optionally prefixed by a collection,
+// e.g. "interval=100ms,logs:never,pictures:per-write"
+func ParseFsyncPolicies(s string) (*FsyncPolicies, error) {
+	policies := &FsyncPolicies{Collections: make(map[string]FsyncPolicy)}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		collection, isDefault := "", true
+		if i := strings.Index(entry, ":"); i >= 0 {
+			collection, isDefault, entry = entry[:i], false, entry[i+1:]
+		}
+		p, err := ParseFsyncPolicy(entry)
+		if err != nil {
+			return nil, err
+		}
+		if isDefault {
+			policies.Default = p
+		} else {
+			policies.Collections[collection] = p
+		}
+	}
+	return policies, nil
+}
+
+func (ps *FsyncPolicies) Get(collection string) FsyncPolicy {
+	if p, found := ps.Collections[collection]; found {
+		return p
+	}
+	return ps.Default
+}
+
+// SetFsyncPolicy changes the fsync policy, syncing the pending group commit if any
+func (v *Volume) SetFsyncPolicy(p FsyncPolicy) {
+	v.dataFileAccessLock.Lock()
+	defer v.dataFileAccessLock.Unlock()
+	if v.groupSyncer != nil {
+		v.groupSyncer.stop(v.syncFiles)
+		v.groupSyncer = nil
+	}
+	v.fsyncPolicy = p
+	syncFn := func() error {
+		v.dataFileAccessLock.Lock()
+		defer v.dataFileAccessLock.Unlock()
+		return v.syncFiles()
+	}
+	switch p.Level {
+	case FsyncInterval:
+		v.groupSyncer = newGroupSyncer(p.Interval, syncFn)
+	case FsyncGroup:
+		v.groupSyncer = newWindowGroupSyncer(p.Interval, p.MaxWrites, syncFn)
+	}
+	if v.nm != nil {
+		if err := v.nm.SetIndexBatching(p.Level == FsyncGroup); err != nil {
+			glog.Errorf("write the batched index entries of volume %d: %v", v.Id, err)
+		}
+	}
+}
+
+// syncAfterWrite requires the dataFileAccessLock, and returns the channel to wait for the group commit if any
+func (v *Volume) syncAfterWrite() (groupCommitted <-chan error, err error) {
+	switch v.fsyncPolicy.Level {
+	case FsyncPerWrite:
+		return nil, v.syncFiles()
+	case FsyncInterval, FsyncGroup:
+		if v.groupSyncer == nil {
+			return nil, v.syncFiles()
+		}
+		return v.groupSyncer.enqueue(), nil
+	}
+	return nil, nil
+}
+
+// Sync syncs the .dat and .idx files for the writes requesting fsync,
+// unless the fsync policy has synced the writes already
+func (v *Volume) Sync() error {
+	v.dataFileAccessLock.Lock()
+	defer v.dataFileAccessLock.Unlock()
+	if v.fsyncPolicy.Level != FsyncNever {
+		return nil
+	}
+	return v.syncFiles()
+}
+
+// syncFiles requires the dataFileAccessLock
+func (v *Volume) syncFiles() error {
+	if v.dataFile == nil || v.nm == nil {
+		return fmt.Errorf("volume %d is closed", v.Id)
+	}
+	if err := v.dataFile.Sync(); err != nil {
+		return fmt.Errorf("sync %s: %v", v.dataFile.Name(), err)
+	}
+	if err := v.nm.Sync(); err != nil {
+		return fmt.Errorf("sync %s: %v", v.nm.IndexFileName(), err)
+	}
+	return nil
+}
+
+// groupSyncer syncs the volume files for all the writes since the last sync, at most once per interval,
+// or within the window since the first waiting write
+type groupSyncer struct {
+	sync.Mutex
+	waiters   []chan error
+	done      chan struct{}
+	maxWrites int           // only for the window, to sync right away once so many writes are waiting
+	arrived   chan struct{} // only for the window, signaled by the first waiting write
+	full      chan struct{} // only for the window, signaled by the max writes
+}
+
+func newGroupSyncer(interval time.Duration, syncFn func() error) *groupSyncer {
+	g := &groupSyncer{done: make(chan struct{})}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				g.flush(syncFn)
+			case <-g.done:
+				return
+			}
+		}
+	}()
+	return g
+}
+
+// newWindowGroupSyncer syncs the writes arriving within the window together, or earlier once maxWrites are waiting
+func newWindowGroupSyncer(window time.Duration, maxWrites int, syncFn func() error) *groupSyncer {
+	g := &groupSyncer{
+		done:      make(chan struct{}),
+		maxWrites: maxWrites,
+		arrived:   make(chan struct{}, 1),
+		full:      make(chan struct{}, 1),
+	}
+	go func() {
+		for {
+			select {
+			case <-g.arrived:
+			case <-g.done:
+				return
+			}
+			timer := time.NewTimer(window)
+			select {
+			case <-timer.C:
+			case <-g.full:
+				timer.Stop()
+			case <-g.done:
+				timer.Stop()
+				return
+			}
+			g.flush(syncFn)
+		}
+	}()
+	return g
+}
+
+func (g *groupSyncer) enqueue() <-chan error {
+	ch := make(chan error, 1)
+	g.Lock()
+	g.waiters = append(g.waiters, ch)
+	waiting := len(g.waiters)
+	g.Unlock()
+	if waiting == 1 {
+		notifyGroupSyncer(g.arrived)
+	}
+	if g.maxWrites > 0 && waiting >= g.maxWrites {
+		notifyGroupSyncer(g.full)
+	}
+	return ch
+}
+
+// notifyGroupSyncer does not block, and does nothing on a nil channel
+func notifyGroupSyncer(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+func (g *groupSyncer) flush(syncFn func() error) {
+	g.Lock()
+	waiters := g.waiters
+	g.waiters = nil
+	g.Unlock()
+	if len(waiters) == 0 {
+		return
+	}
+	err := syncFn()
+	if err != nil {
+		glog.Errorf("group commit %d writes: %v", len(waiters), err)
+	}
+	for _, ch := range waiters {
+		ch <- err
+	}
+}
+
+// stop flushes the pending writes with syncFn, which is called with the dataFileAccessLock already held
+func (g *groupSyncer) stop(syncFn func() error) {
+	close(g.done)
+	g.flush(syncFn)
+}
+
+// SetFsyncPolicies sets the fsync policies for the new volumes, and changes the loaded volumes
+func (s *Store) SetFsyncPolicies(policies *FsyncPolicies) {
+	s.fsyncPolicies = policies
+	for _, location := range s.Locations {
+		location.RLock()
+		for _, v := range location.volumes {
+			s.applyFsyncPolicy(v)
+		}
+		location.RUnlock()
+	}
+}
+
+// SyncVolume syncs the volume files for the writes requesting fsync
+func (s *Store) SyncVolume(i needle.VolumeId) error {
+	if v := s.findVolume(i); v != nil {
+		return v.Sync()
+	}
+	return fmt.Errorf("volume %d not found on %s:%d", i, s.Ip, s.Port)
+}
+
+// SyncAllVolumes syncs the files of all the volumes whatever the fsync policies, before the volume server shuts down
+func (s *Store) SyncAllVolumes() {
+	for _, location := range s.Locations {
+		location.RLock()
+		for _, v := range location.volumes {
+			v.dataFileAccessLock.Lock()
+			if err := v.syncFiles(); err != nil {
+				glog.Errorf("sync volume %d: %v", v.Id, err)
+			}
+			v.dataFileAccessLock.Unlock()
+		}
+		location.RUnlock()
+	}
+}
+
+func (s *Store) applyFsyncPolicy(v *Volume) {
+	if s.fsyncPolicies == nil || v == nil {
+		return
+	}
+	v.SetFsyncPolicy(s.fsyncPolicies.Get(v.Collection))
+}
@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+	. "github.com/chrislusf/seaweedfs/weed/storage/types"
+)
+
+func TestParseFsyncPolicies(t *testing.T) {
+	ps, err := ParseFsyncPolicies("interval=100ms,logs:never,pictures:per-write")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if p := ps.Get(""); p.Level != FsyncInterval || p.Interval != 100*time.Millisecond {
+		t.Errorf("default policy %v", p)
+	}
+	if p := ps.Get("logs"); p.Level != FsyncNever {
+		t.Errorf("logs policy %v", p)
+	}
+	if p := ps.Get("pictures"); p.String() != "per-write" {
+		t.Errorf("pictures policy %v", p)
+	}
+	if p := ps.Get("other"); p.String() != "interval=100ms" {
+		t.Errorf("other policy %v", p)
+	}
+
+	if p, err := ParseFsyncPolicy("group=2ms/64"); err != nil || p.Level != FsyncGroup || p.Interval != 2*time.Millisecond || p.MaxWrites != 64 {
+		t.Errorf("group policy %v: %v", p, err)
+	}
+	if p, err := ParseFsyncPolicy("group=5ms"); err != nil || p.String() != "group=5ms/128" {
+		t.Errorf("group policy with the default max writes %v: %v", p, err)
+	}
+
+	for _, bad := range []string{"always", "interval=", "interval=-1s", "logs:sometimes", "group=", "group=2ms/0", "group=2ms/x"} {
+		if _, err := ParseFsyncPolicies(bad); err == nil {
+			t.Errorf("expecting error for %s", bad)
+		}
+	}
+}
+
+func TestGroupCommit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fsync")
+	if err != nil {
+		t.Fatalf("temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	v, err := NewVolume(dir, "", 1, NeedleMapInMemory, &ReplicaPlacement{}, &needle.TTL{}, 0)
+	if err != nil {
+		t.Fatalf("volume creation: %v", err)
+	}
+	v.SetFsyncPolicy(FsyncPolicy{Level: FsyncInterval, Interval: 10 * time.Millisecond})
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, _, _, err := v.writeNeedle(newRandomNeedle(uint64(i))); err != nil {
+				t.Errorf("write needle %d: %v", i, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if _, err = v.deleteNeedle(newEmptyNeedle(1)); err != nil {
+		t.Errorf("delete needle 1: %v", err)
+	}
+
+	v.SetFsyncPolicy(FsyncPolicy{Level: FsyncPerWrite})
+	if _, _, _, err = v.writeNeedle(newRandomNeedle(21)); err != nil {
+		t.Errorf("write needle 21: %v", err)
+	}
+	if v.nm.FileCount() != 21 || v.nm.DeletedCount() != 1 {
+		t.Errorf("file count %d, deleted count %d", v.nm.FileCount(), v.nm.DeletedCount())
+	}
+	v.Close()
+}
+
+func TestWindowGroupCommit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fsync")
+	if err != nil {
+		t.Fatalf("temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	v, err := NewVolume(dir, "", 1, NeedleMapInMemory, &ReplicaPlacement{}, &needle.TTL{}, 0)
+	if err != nil {
+		t.Fatalf("volume creation: %v", err)
+	}
+	v.SetFsyncPolicy(FsyncPolicy{Level: FsyncGroup, Interval: 5 * time.Millisecond, MaxWrites: 8})
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, _, _, err := v.writeNeedle(newRandomNeedle(uint64(i))); err != nil {
+				t.Errorf("write needle %d: %v", i, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// the acknowledged writes are in the index file
+	stat, err := os.Stat(v.FileName() + ".idx")
+	if err != nil {
+		t.Fatalf("stat index file: %v", err)
+	}
+	if stat.Size() != 20*NeedleMapEntrySize {
+		t.Errorf("index file size %d, expected %d", stat.Size(), 20*NeedleMapEntrySize)
+	}
+
+	v.SetFsyncPolicy(FsyncPolicy{Level: FsyncNever})
+	if _, _, _, err = v.writeNeedle(newRandomNeedle(21)); err != nil {
+		t.Errorf("write needle 21: %v", err)
+	}
+	if size := v.IndexFileSize(); size != 21*NeedleMapEntrySize {
+		t.Errorf("index file size %d after turning off the group commit", size)
+	}
+	v.Close()
+}
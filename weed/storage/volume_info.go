@@ -21,6 +21,7 @@ type VolumeInfo struct {
 	ReadOnly         bool
 	CompactRevision  uint32
 	ModifiedAtSecond int64
+	FsyncPolicy      string
 }
 
 func NewVolumeInfo(m *master_pb.VolumeInformationMessage) (vi VolumeInfo, err error) {
@@ -35,6 +36,7 @@ func NewVolumeInfo(m *master_pb.VolumeInformationMessage) (vi VolumeInfo, err er
 		Version:          needle.Version(m.Version),
 		CompactRevision:  m.CompactRevision,
 		ModifiedAtSecond: m.ModifiedAtSecond,
+		FsyncPolicy:      m.FsyncPolicy,
 	}
 	rp, e := NewReplicaPlacementFromByte(byte(m.ReplicaPlacement))
 	if e != nil {
@@ -78,6 +80,7 @@ func (vi VolumeInfo) ToVolumeInformationMessage() *master_pb.VolumeInformationMe
 		Version:          uint32(vi.Version),
 		Ttl:              vi.Ttl.ToUint32(),
 		CompactRevision:  vi.CompactRevision,
+		FsyncPolicy:      vi.FsyncPolicy,
 		ModifiedAtSecond: vi.ModifiedAtSecond,
 	}
 }
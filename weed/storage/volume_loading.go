@@ -7,6 +7,7 @@ import (
 
 	"github.com/chrislusf/seaweedfs/weed/stats"
 	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+	"github.com/syndtr/goleveldb/leveldb/filter"
 	"github.com/syndtr/goleveldb/leveldb/opt"
 
 	"github.com/chrislusf/seaweedfs/weed/glog"
@@ -36,6 +37,7 @@ func (v *Volume) load(alsoLoadIndex bool, createDatIfMissing bool, needleMapKind
 			glog.V(0).Infoln("opening " + fileName + ".dat in READONLY mode")
 			v.dataFile, e = os.Open(fileName + ".dat")
 			v.readOnly = true
+			v.loadedReadOnly = true
 		}
 		if fileSize >= _SuperBlockSize {
 			alreadyHasSuperBlock = true
@@ -61,6 +63,11 @@ func (v *Volume) load(alsoLoadIndex bool, createDatIfMissing bool, needleMapKind
 	} else {
 		e = v.maybeWriteSuperBlock()
 	}
+	if e == nil && alsoLoadIndex {
+		if e = v.maybeReplayMoveJournal(); e != nil {
+			return fmt.Errorf("replay %s.cpj: %v", fileName, e)
+		}
+	}
 	if e == nil && alsoLoadIndex {
 		var indexFile *os.File
 		if v.readOnly {
@@ -74,9 +81,12 @@ func (v *Volume) load(alsoLoadIndex bool, createDatIfMissing bool, needleMapKind
 				return fmt.Errorf("cannot write Volume Index %s.idx: %v", fileName, e)
 			}
 		}
-		if v.lastAppendAtNs, e = CheckVolumeDataIntegrity(v, indexFile); e != nil {
+		if v.lastAppendAtNs, e = v.checkVolumeDataIntegrity(indexFile); e != nil {
 			v.readOnly = true
+			v.loadedReadOnly = true
 			glog.V(0).Infof("volumeDataIntegrityChecking failed %v", e)
+		} else {
+			v.dataIntegrityChecked = true
 		}
 		switch needleMapKind {
 		case NeedleMapInMemory:
@@ -90,6 +100,7 @@ func (v *Volume) load(alsoLoadIndex bool, createDatIfMissing bool, needleMapKind
 				BlockCacheCapacity:            2 * 1024 * 1024, // default value is 8MiB
 				WriteBuffer:                   1 * 1024 * 1024, // default value is 4MiB
 				CompactionTableSizeMultiplier: 10,              // default value is 1
+				Filter:                        filter.NewBloomFilter(levelDbBloomFilterBitsPerKey),
 			}
 			if v.nm, e = NewLevelDbNeedleMap(fileName+".ldb", indexFile, opts); e != nil {
 				glog.V(0).Infof("loading leveldb %s error: %v", fileName+".ldb", e)
@@ -100,6 +111,7 @@ func (v *Volume) load(alsoLoadIndex bool, createDatIfMissing bool, needleMapKind
 				BlockCacheCapacity:            4 * 1024 * 1024, // default value is 8MiB
 				WriteBuffer:                   2 * 1024 * 1024, // default value is 4MiB
 				CompactionTableSizeMultiplier: 10,              // default value is 1
+				Filter:                        filter.NewBloomFilter(levelDbBloomFilterBitsPerKey),
 			}
 			if v.nm, e = NewLevelDbNeedleMap(fileName+".ldb", indexFile, opts); e != nil {
 				glog.V(0).Infof("loading leveldb %s error: %v", fileName+".ldb", e)
@@ -110,10 +122,19 @@ func (v *Volume) load(alsoLoadIndex bool, createDatIfMissing bool, needleMapKind
 				BlockCacheCapacity:            8 * 1024 * 1024, // default value is 8MiB
 				WriteBuffer:                   4 * 1024 * 1024, // default value is 4MiB
 				CompactionTableSizeMultiplier: 10,              // default value is 1
+				Filter:                        filter.NewBloomFilter(levelDbBloomFilterBitsPerKey),
 			}
 			if v.nm, e = NewLevelDbNeedleMap(fileName+".ldb", indexFile, opts); e != nil {
 				glog.V(0).Infof("loading leveldb %s error: %v", fileName+".ldb", e)
 			}
+		case NeedleMapMmap:
+			glog.V(0).Infoln("loading sorted index", fileName+".sdx")
+			if v.nm, e = NewMmapNeedleMap(fileName+".sdx", indexFile); e != nil {
+				glog.V(0).Infof("loading sorted index %s error: %v", fileName+".sdx", e)
+			}
+		}
+		if v.nm != nil && v.fsyncPolicy.Level == FsyncGroup {
+			v.nm.SetIndexBatching(true)
 		}
 	}
 
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"time"
 
@@ -43,14 +44,23 @@ func (v *Volume) Destroy() (err error) {
 		err = fmt.Errorf("%s is read-only", v.dataFile.Name())
 		return
 	}
+	v.destroy()
+	return
+}
+
+// destroy removes the volume files, also of the volume made read only to be deleted
+func (v *Volume) destroy() {
 	v.Close()
 	os.Remove(v.FileName() + ".dat")
 	os.Remove(v.FileName() + ".idx")
 	os.Remove(v.FileName() + ".cpd")
 	os.Remove(v.FileName() + ".cpx")
+	os.Remove(v.FileName() + ".cpj")
+	os.Remove(v.FileName() + ".cpl")
 	os.Remove(v.FileName() + ".ldb")
 	os.Remove(v.FileName() + ".bdb")
-	return
+	os.Remove(v.FileName() + ".sdx")
+	os.Remove(v.FileName() + ".ckp")
 }
 
 // AppendBlob append a blob to end of the data file, used in replication
@@ -83,8 +93,25 @@ func (v *Volume) writeNeedle(n *needle.Needle) (offset uint64, size uint32, isUn
 		err = fmt.Errorf("%s is read-only", v.dataFile.Name())
 		return
 	}
+	// the needles can expire earlier than the volume, but not later, since the whole volume is deleted when expired
+	if v.Ttl != nil && v.Ttl.Minutes() > 0 && n.Ttl != nil && n.Ttl.Minutes() > v.Ttl.Minutes() {
+		err = fmt.Errorf("needle ttl %s is longer than the ttl %s of volume %d", n.Ttl.String(), v.Ttl.String(), v.Id)
+		return
+	}
+	var groupCommitted <-chan error
+	defer func() {
+		// wait for the group commit after releasing the lock
+		if groupCommitted != nil && err == nil {
+			err = <-groupCommitted
+		}
+	}()
 	v.dataFileAccessLock.Lock()
 	defer v.dataFileAccessLock.Unlock()
+	// made read only while waiting for the lock, e.g., the empty volume to be deleted
+	if v.readOnly {
+		err = fmt.Errorf("%s is read-only", v.dataFile.Name())
+		return
+	}
 	if v.isFileUnchanged(n) {
 		size = n.DataSize
 		isUnchanged = true
@@ -101,26 +128,44 @@ func (v *Volume) writeNeedle(n *needle.Needle) (offset uint64, size uint32, isUn
 		return
 	}
 	v.lastAppendAtNs = n.AppendAtNs
+	v.appendToCompactionLog(n)
 
 	nv, ok := v.nm.Get(n.Id)
 	if !ok || uint64(nv.Offset.ToAcutalOffset()) < offset {
 		if err = v.nm.Put(n.Id, ToOffset(int64(offset)), n.Size); err != nil {
 			glog.V(4).Infof("failed to save in needle map %d: %v", n.Id, err)
 		}
+		if expireAt := v.needleExpireAt(n); expireAt > 0 {
+			v.expiries.put(n.Id, expireAt, n.Size, true)
+		} else {
+			v.expiries.remove(n.Id)
+		}
 	}
 	if v.lastModifiedTsSeconds < n.LastModified {
 		v.lastModifiedTsSeconds = n.LastModified
 	}
+	groupCommitted, err = v.syncAfterWrite()
 	return
 }
 
-func (v *Volume) deleteNeedle(n *needle.Needle) (uint32, error) {
+func (v *Volume) deleteNeedle(n *needle.Needle) (deletedSize uint32, err error) {
 	glog.V(4).Infof("delete needle %s", needle.NewFileIdFromNeedle(v.Id, n).String())
 	if v.readOnly {
 		return 0, fmt.Errorf("%s is read-only", v.dataFile.Name())
 	}
+	var groupCommitted <-chan error
+	defer func() {
+		// wait for the group commit after releasing the lock
+		if groupCommitted != nil && err == nil {
+			err = <-groupCommitted
+		}
+	}()
 	v.dataFileAccessLock.Lock()
 	defer v.dataFileAccessLock.Unlock()
+	// made read only while waiting for the lock, e.g., the volume server draining
+	if v.readOnly {
+		return 0, fmt.Errorf("%s is read-only", v.dataFile.Name())
+	}
 	nv, ok := v.nm.Get(n.Id)
 	//fmt.Println("key", n.Id, "volume offset", nv.Offset, "data_size", n.Size, "cached size", nv.Size)
 	if ok && nv.Size != TombstoneFileSize {
@@ -132,9 +177,12 @@ func (v *Volume) deleteNeedle(n *needle.Needle) (uint32, error) {
 			return size, err
 		}
 		v.lastAppendAtNs = n.AppendAtNs
+		v.appendToCompactionLog(n)
 		if err = v.nm.Delete(n.Id, ToOffset(int64(offset))); err != nil {
 			return size, err
 		}
+		v.expiries.remove(n.Id)
+		groupCommitted, err = v.syncAfterWrite()
 		return size, err
 	}
 	return 0, nil
@@ -142,6 +190,8 @@ func (v *Volume) deleteNeedle(n *needle.Needle) (uint32, error) {
 
 // read fills in Needle content by looking up n.Id from NeedleMapper
 func (v *Volume) readNeedle(n *needle.Needle) (int, error) {
+	v.inPlaceCompactionLock.RLock()
+	defer v.inPlaceCompactionLock.RUnlock()
 	nv, ok := v.nm.Get(n.Id)
 	if !ok || nv.Offset.IsZero() {
 		v.compactingWg.Wait()
@@ -161,20 +211,30 @@ func (v *Volume) readNeedle(n *needle.Needle) (int, error) {
 		return 0, err
 	}
 	bytesRead := len(n.Data)
-	if !n.HasTtl() {
-		return bytesRead, nil
+	if n.IsExpired(uint64(time.Now().Unix())) {
+		return -1, ErrorNotFound
 	}
-	ttlMinutes := n.Ttl.Minutes()
-	if ttlMinutes == 0 {
-		return bytesRead, nil
+	return bytesRead, nil
+}
+
+// ReadNeedleBlob reads the raw header and body of the current version of the needle, as stored in the data file
+func (v *Volume) ReadNeedleBlob(id NeedleId) (needleHeader, needleBody []byte, err error) {
+	v.inPlaceCompactionLock.RLock()
+	defer v.inPlaceCompactionLock.RUnlock()
+	nv, ok := v.nm.Get(id)
+	if !ok || nv.Offset.IsZero() || nv.Size == TombstoneFileSize {
+		return nil, nil, ErrorNotFound
 	}
-	if !n.HasLastModifiedDate() {
-		return bytesRead, nil
+	offset := nv.Offset.ToAcutalOffset()
+	_, needleHeader, bodyLength, err := needle.ReadNeedleHeader(v.dataFile, v.Version(), offset)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read needle %v header at %d: %v", id, offset, err)
 	}
-	if uint64(time.Now().Unix()) < n.LastModified+uint64(ttlMinutes*60) {
-		return bytesRead, nil
+	needleBody = make([]byte, bodyLength)
+	if _, err = v.dataFile.ReadAt(needleBody, offset+NeedleHeaderSize); err != nil {
+		return nil, nil, fmt.Errorf("read needle %v body at %d: %v", id, offset, err)
 	}
-	return -1, ErrorNotFound
+	return needleHeader, needleBody, nil
 }
 
 type VolumeFileScanner interface {
@@ -186,6 +246,14 @@ type VolumeFileScanner interface {
 func ScanVolumeFile(dirname string, collection string, id needle.VolumeId,
 	needleMapKind NeedleMapType,
 	volumeFileScanner VolumeFileScanner) (err error) {
+	return scanVolumeFileUntil(dirname, collection, id, needleMapKind, math.MaxInt64, volumeFileScanner)
+}
+
+// scanVolumeFileUntil visits only the needles starting before the stopOffset,
+// leaving out the ones appended since the stopOffset was taken, which may be partly written.
+func scanVolumeFileUntil(dirname string, collection string, id needle.VolumeId,
+	needleMapKind NeedleMapType, stopOffset int64,
+	volumeFileScanner VolumeFileScanner) (err error) {
 	var v *Volume
 	if v, err = loadVolumeWithoutIndex(dirname, collection, id, needleMapKind); err != nil {
 		return fmt.Errorf("failed to load volume %d: %v", id, err)
@@ -199,10 +267,17 @@ func ScanVolumeFile(dirname string, collection string, id needle.VolumeId,
 
 	offset := int64(v.SuperBlock.BlockSize())
 
-	return ScanVolumeFileFrom(version, v.dataFile, offset, volumeFileScanner)
+	return scanVolumeFileRange(version, v.dataFile, offset, stopOffset, volumeFileScanner)
 }
 
 func ScanVolumeFileFrom(version needle.Version, dataFile *os.File, offset int64, volumeFileScanner VolumeFileScanner) (err error) {
+	return scanVolumeFileRange(version, dataFile, offset, math.MaxInt64, volumeFileScanner)
+}
+
+func scanVolumeFileRange(version needle.Version, dataFile *os.File, offset, stopOffset int64, volumeFileScanner VolumeFileScanner) (err error) {
+	if offset >= stopOffset {
+		return nil
+	}
 	n, _, rest, e := needle.ReadNeedleHeader(dataFile, version, offset)
 	if e != nil {
 		if e == io.EOF {
@@ -227,6 +302,9 @@ func ScanVolumeFileFrom(version needle.Version, dataFile *os.File, offset int64,
 		}
 		offset += NeedleHeaderSize + rest
 		glog.V(4).Infof("==> new entry offset %d", offset)
+		if offset >= stopOffset {
+			return nil
+		}
 		if n, _, rest, err = needle.ReadNeedleHeader(dataFile, version, offset); err != nil {
 			if err == io.EOF {
 				return nil
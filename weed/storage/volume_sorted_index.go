@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/chrislusf/seaweedfs/weed/storage/erasure_coding"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle_map"
+)
+
+/*
+The sorted needle map kept on disk is the .sdx file of the MmapNeedleMap, looked up by binary search
+without loading the entries into memory. The sorted export here writes the .ecx file from it, without sorting the .idx file again.
+
+The .idx file is not regenerated from the sorted entries: they only have the live needles, while the
+.idx file also keeps the deletions, which count the garbage for the vacuum until the .dat file is compacted.
+*/
+
+// sortedNeedleMapper visits the live entries in the ascending order of the keys, without loading all of them into memory
+type sortedNeedleMapper interface {
+	AscendingVisit(visit func(needle_map.NeedleValue) error) error
+}
+
+// WriteSortedEcxFile writes the .ecx file from the sorted needle map if any, or else from the .idx file
+func (v *Volume) WriteSortedEcxFile() error {
+	if sorted, ok := v.nm.(sortedNeedleMapper); ok {
+		return writeSortedIndexFile(v.FileName()+".ecx", sorted)
+	}
+	return erasure_coding.WriteSortedEcxFile(v.FileName())
+}
+
+// writeSortedIndexFile writes the live entries in the .idx format sorted by the keys, usable as the .ecx file
+func writeSortedIndexFile(fileName string, sorted sortedNeedleMapper) error {
+	dst, err := os.OpenFile(fileName, os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("open %s: %v", fileName, err)
+	}
+	writer := bufio.NewWriter(dst)
+	err = sorted.AscendingVisit(func(value needle_map.NeedleValue) error {
+		_, writeErr := writer.Write(value.ToBytes())
+		return writeErr
+	})
+	if err == nil {
+		err = writer.Flush()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("write %s: %v", fileName, err)
+	}
+	return nil
+}
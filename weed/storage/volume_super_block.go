@@ -2,6 +2,7 @@ package storage
 
 import (
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/chrislusf/seaweedfs/weed/glog"
@@ -118,6 +119,10 @@ func ReadSuperBlock(dataFile *os.File) (superBlock SuperBlock, err error) {
 	if superBlock.extraSize > 0 {
 		// read more
 		extraData := make([]byte, int(superBlock.extraSize))
+		if _, e := io.ReadFull(dataFile, extraData); e != nil {
+			err = fmt.Errorf("cannot read volume %s super block extra: %v", dataFile.Name(), e)
+			return
+		}
 		superBlock.Extra = &master_pb.SuperBlockExtra{}
 		err = proto.Unmarshal(extraData, superBlock.Extra)
 		if err != nil {
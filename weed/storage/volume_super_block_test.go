@@ -1,8 +1,11 @@
 package storage
 
 import (
+	"io/ioutil"
+	"os"
 	"testing"
 
+	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
 	"github.com/chrislusf/seaweedfs/weed/storage/needle"
 )
 
@@ -23,3 +26,33 @@ func TestSuperBlockReadWrite(t *testing.T) {
 	}
 
 }
+
+func TestSuperBlockExtraReadWrite(t *testing.T) {
+	dataFile, err := ioutil.TempFile("", "super_block")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(dataFile.Name())
+	defer dataFile.Close()
+
+	rp, _ := NewReplicaPlacementFromByte(byte(001))
+	s := &SuperBlock{
+		version:          needle.CurrentVersion,
+		ReplicaPlacement: rp,
+		Ttl:              needle.EMPTY_TTL,
+		Extra: &master_pb.SuperBlockExtra{
+			ErasureCoding: &master_pb.SuperBlockExtra_ErasureCoding{Data: 10, Parity: 4},
+		},
+	}
+	if _, err = dataFile.Write(s.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := ReadSuperBlock(dataFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ec := read.Extra.GetErasureCoding(); ec.GetData() != 10 || ec.GetParity() != 4 {
+		t.Errorf("super block extra: %+v", read.Extra)
+	}
+}
@@ -0,0 +1,307 @@
+package storage
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle_map"
+	. "github.com/chrislusf/seaweedfs/weed/storage/types"
+	"github.com/chrislusf/seaweedfs/weed/util"
+)
+
+const inPlaceCompactionBatch = 10000
+
+/*
+CompactInPlace vacuums the volume without a full copy, for disks without space for the .cpd file.
+
+The live needles slide towards the beginning of the .dat file, into the holes left by the deleted ones,
+keeping their order, and the tail of the .dat file is truncated.
+
+The moved needles are synced in batches, before their new locations are appended to the .idx file,
+and before any of their old locations are overwritten. A needle overlapping its own old location
+is first saved to the .cpj journal file, which is replayed when loading the volume after a crash.
+At last, the .idx file is replaced with the compacted .cpx file.
+
+The needles are moved in batches, each holding the dataFileAccessLock, so the writes and deletes go on between the batches.
+The needles appended meanwhile are also moved, and the deleted ones are recorded in the .cpx file.
+The reads are blocked only while the needles are written to the holes, or their new locations are put.
+*/
+func (v *Volume) CompactInPlace(compactionBytePerSecond int64) (err error) {
+	glog.V(0).Infof("Compacting volume %d in place ...", v.Id)
+
+	v.dataFileAccessLock.Lock()
+	if v.readOnly {
+		v.dataFileAccessLock.Unlock()
+		return fmt.Errorf("%s is read-only", v.dataFile.Name())
+	}
+	filePath := v.FileName()
+	version := v.Version()
+	// the needles appended since then could be deleted after moved, so their deletions are recorded in the .cpx file
+	appendedSince, err := v.dataFile.Seek(0, 2)
+	v.dataFileAccessLock.Unlock()
+	if err != nil {
+		return fmt.Errorf("cannot get the size of %s.dat: %v", filePath, err)
+	}
+
+	cpx, err := os.OpenFile(filePath+".cpx", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("create %s.cpx: %v", filePath, err)
+	}
+	defer func() {
+		cpx.Close()
+		if err != nil {
+			os.Remove(filePath + ".cpx")
+		}
+	}()
+	cpxWriter := bufio.NewWriter(cpx)
+
+	writeThrottler := util.NewWriteThrottler(compactionBytePerSecond)
+
+	// the needles moved without syncing yet, their old locations are still intact
+	type movedNeedle struct {
+		key    NeedleId
+		offset int64
+		size   uint32
+	}
+	var pending []movedNeedle
+	var pendingSince int64
+	syncPending := func() error {
+		if len(pending) == 0 {
+			return nil
+		}
+		if err := v.dataFile.Sync(); err != nil {
+			return err
+		}
+		// the reads of the old locations finish before the new locations are put
+		v.inPlaceCompactionLock.Lock()
+		defer v.inPlaceCompactionLock.Unlock()
+		for _, m := range pending {
+			if err := v.nm.Put(m.key, ToOffset(m.offset), m.size); err != nil {
+				return fmt.Errorf("put needle %d: %v", m.key, err)
+			}
+		}
+		if err := v.nm.Sync(); err != nil {
+			return err
+		}
+		pending = pending[:0]
+		return nil
+	}
+
+	// moveBatch moves the next batch of needles, and tells whether the end of the .dat file is reached
+	writeOffset := int64(v.SuperBlock.BlockSize())
+	offset := writeOffset
+	moveBatch := func() (isEnd bool, movedBytes int64, err error) {
+		for count := 0; count < inPlaceCompactionBatch; count++ {
+			n, _, bodyLength, readErr := needle.ReadNeedleHeader(v.dataFile, version, offset)
+			if readErr == io.EOF || n == nil {
+				return true, movedBytes, syncPending()
+			}
+			if readErr != nil {
+				return false, movedBytes, fmt.Errorf("read needle header %s at %d: %v", v.dataFile.Name(), offset, readErr)
+			}
+			diskSize := NeedleHeaderSize + bodyLength
+
+			nv, ok := v.nm.Get(n.Id)
+			if !ok || nv.Size == TombstoneFileSize {
+				if offset >= appendedSince {
+					// deleted after moved by an earlier batch
+					if _, err = cpxWriter.Write(needle_map.ToBytes(n.Id, Offset{}, TombstoneFileSize)); err != nil {
+						return false, movedBytes, fmt.Errorf("write %s: %v", cpx.Name(), err)
+					}
+				}
+				offset += diskSize
+				continue
+			}
+			if nv.Offset.ToAcutalOffset() != offset || nv.Size == 0 {
+				offset += diskSize
+				continue
+			}
+
+			if offset != writeOffset {
+				blob, readErr := needle.ReadNeedleBlob(v.dataFile, offset, n.Size, version)
+				if readErr != nil {
+					return false, movedBytes, fmt.Errorf("read needle %d at %d: %v", n.Id, offset, readErr)
+				}
+				// sync before overwriting the old locations of the moved needles
+				if len(pending) > 0 && writeOffset+diskSize > pendingSince {
+					if err = syncPending(); err != nil {
+						return false, movedBytes, fmt.Errorf("sync moved needles: %v", err)
+					}
+				}
+				if writeOffset+diskSize > offset {
+					if err = v.moveOverlappingNeedle(n.Id, n.Size, blob, offset, writeOffset); err != nil {
+						return false, movedBytes, err
+					}
+				} else {
+					v.inPlaceCompactionLock.Lock()
+					_, err = v.dataFile.WriteAt(blob, writeOffset)
+					v.inPlaceCompactionLock.Unlock()
+					if err != nil {
+						return false, movedBytes, fmt.Errorf("move needle %d from %d to %d: %v", n.Id, offset, writeOffset, err)
+					}
+					if len(pending) == 0 {
+						pendingSince = offset
+					}
+					pending = append(pending, movedNeedle{key: n.Id, offset: writeOffset, size: n.Size})
+				}
+				movedBytes += diskSize
+			}
+
+			if _, err = cpxWriter.Write(needle_map.ToBytes(n.Id, ToOffset(writeOffset), n.Size)); err != nil {
+				return false, movedBytes, fmt.Errorf("write %s: %v", cpx.Name(), err)
+			}
+			writeOffset += diskSize
+			offset += diskSize
+		}
+		// the old locations are overwritten by the next batch
+		return false, movedBytes, syncPending()
+	}
+
+	for {
+		v.dataFileAccessLock.Lock()
+		isEnd, movedBytes, batchErr := moveBatch()
+		if isEnd || batchErr != nil {
+			// keep the lock, so no needle is appended before truncating
+			err = batchErr
+			break
+		}
+		v.dataFileAccessLock.Unlock()
+		writeThrottler.MaybeSlowdown(movedBytes)
+	}
+	defer v.dataFileAccessLock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err = cpxWriter.Flush(); err != nil {
+		return fmt.Errorf("flush %s: %v", cpx.Name(), err)
+	}
+	if err = cpx.Sync(); err != nil {
+		return fmt.Errorf("sync %s: %v", cpx.Name(), err)
+	}
+
+	v.SuperBlock.CompactionRevision++
+	if _, err = v.dataFile.WriteAt(v.SuperBlock.Bytes(), 0); err != nil {
+		return fmt.Errorf("write super block %s: %v", v.dataFile.Name(), err)
+	}
+	if err = v.dataFile.Truncate(writeOffset); err != nil {
+		return fmt.Errorf("truncate %s to %d: %v", v.dataFile.Name(), writeOffset, err)
+	}
+	if err = v.dataFile.Sync(); err != nil {
+		return fmt.Errorf("sync %s: %v", v.dataFile.Name(), err)
+	}
+	glog.V(0).Infof("volume %d compacted in place from %d to %d bytes", v.Id, offset, writeOffset)
+
+	v.compactingWg.Add(1)
+	defer v.compactingWg.Done()
+	v.nm.Close()
+	if err = v.dataFile.Close(); err != nil {
+		glog.V(0).Infof("fail to close volume %d", v.Id)
+	}
+	v.dataFile = nil
+	if err = os.Rename(filePath+".cpx", filePath+".idx"); err != nil {
+		return fmt.Errorf("rename %s.cpx: %v", filePath, err)
+	}
+	os.RemoveAll(filePath + ".ldb")
+	os.RemoveAll(filePath + ".bdb")
+	os.RemoveAll(filePath + ".sdx")
+	os.RemoveAll(filePath + ".ckp")
+
+	v.compactedInPlace = true
+	return v.load(true, false, v.needleMapKind, 0)
+}
+
+// moveOverlappingNeedle moves the needle partly over its old location, saving it to the journal first
+func (v *Volume) moveOverlappingNeedle(key NeedleId, size uint32, blob []byte, oldOffset, newOffset int64) (err error) {
+
+	journalFileName := v.FileName() + ".cpj"
+	if err = writeMoveJournal(journalFileName, blob, newOffset); err != nil {
+		return fmt.Errorf("journal needle %d: %v", key, err)
+	}
+
+	// the old location is being overwritten, so block the reads until the new location is put
+	v.inPlaceCompactionLock.Lock()
+	if _, err = v.dataFile.WriteAt(blob, newOffset); err == nil {
+		if err = v.dataFile.Sync(); err == nil {
+			err = v.nm.Put(key, ToOffset(newOffset), size)
+		}
+	}
+	v.inPlaceCompactionLock.Unlock()
+	if err != nil {
+		return fmt.Errorf("move needle %d from %d to %d: %v", key, oldOffset, newOffset, err)
+	}
+
+	if err = v.nm.Sync(); err != nil {
+		return fmt.Errorf("sync %s: %v", v.nm.IndexFileName(), err)
+	}
+	return os.Remove(journalFileName)
+}
+
+// the journal has the 8 bytes new offset, followed by the needle blob
+func writeMoveJournal(journalFileName string, blob []byte, newOffset int64) error {
+	journal, err := os.OpenFile(journalFileName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer journal.Close()
+	header := make([]byte, 8)
+	util.Uint64toBytes(header, uint64(newOffset))
+	if _, err = journal.Write(header); err != nil {
+		return err
+	}
+	if _, err = journal.Write(blob); err != nil {
+		return err
+	}
+	return journal.Sync()
+}
+
+// maybeReplayMoveJournal finishes the needle move interrupted by a crash during CompactInPlace
+func (v *Volume) maybeReplayMoveJournal() error {
+	journalFileName := v.FileName() + ".cpj"
+	data, err := ioutil.ReadFile(journalFileName)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(data) >= 8+NeedleHeaderSize {
+		newOffset := int64(util.BytesToUint64(data[:8]))
+		blob := data[8:]
+		n := new(needle.Needle)
+		n.ParseNeedleHeader(blob)
+		// an incomplete journal means the needle was not moved yet
+		if int64(len(blob)) == needle.GetActualSize(n.Size, v.Version()) {
+			glog.V(0).Infof("volume %d replays moving needle %d to %d", v.Id, n.Id, newOffset)
+			// replayed even if the volume is read only, since the needle could be partly overwritten
+			dataFile, err := os.OpenFile(v.FileName()+".dat", os.O_RDWR, 0644)
+			if err != nil {
+				return err
+			}
+			defer dataFile.Close()
+			if _, err = dataFile.WriteAt(blob, newOffset); err != nil {
+				return err
+			}
+			if err = dataFile.Sync(); err != nil {
+				return err
+			}
+			indexFile, err := os.OpenFile(v.FileName()+".idx", os.O_WRONLY|os.O_APPEND, 0644)
+			if err != nil {
+				return err
+			}
+			defer indexFile.Close()
+			if _, err = indexFile.Write(needle_map.ToBytes(n.Id, ToOffset(newOffset), n.Size)); err != nil {
+				return err
+			}
+			if err = indexFile.Sync(); err != nil {
+				return err
+			}
+		}
+	}
+	return os.Remove(journalFileName)
+}
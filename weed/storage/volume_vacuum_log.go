@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle_map"
+	. "github.com/chrislusf/seaweedfs/weed/storage/types"
+)
+
+/*
+The volume keeps accepting writes and deletes while it is being compacted.
+Since the compaction starts, each appended needle is also appended to the .cpl side log.
+The compaction copies only the needles before the .dat tail taken when the log starts.
+When committing, the logged needles are replayed in order onto the compacted .cpd and .cpx files,
+before they replace the .dat and .idx files.
+A needle updated or deleted since then is left out by the copy, and comes from the log.
+*/
+
+// startCompactionLog starts logging the appended needles, discarding any earlier log,
+// and takes the tail of the .dat file, where the needles left to the log start.
+func (v *Volume) startCompactionLog() error {
+	v.dataFileAccessLock.Lock()
+	defer v.dataFileAccessLock.Unlock()
+	v.stopCompactionLog()
+	stat, err := v.dataFile.Stat()
+	if err != nil {
+		return fmt.Errorf("stat %s.dat: %v", v.FileName(), err)
+	}
+	v.compactionTailOffset = stat.Size()
+	logFile, err := os.OpenFile(v.FileName()+".cpl", os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("create %s.cpl: %v", v.FileName(), err)
+	}
+	v.compactionLog = logFile
+	return nil
+}
+
+// appendToCompactionLog requires the dataFileAccessLock.
+// A failed log is dropped, so that the commit fails instead of losing the needle.
+func (v *Volume) appendToCompactionLog(n *needle.Needle) {
+	if v.compactionLog == nil {
+		return
+	}
+	if _, _, _, err := n.Append(v.compactionLog, v.Version()); err != nil {
+		glog.Errorf("volume %d compaction log: %v", v.Id, err)
+		v.stopCompactionLog()
+	}
+}
+
+// stopCompactionLog requires the dataFileAccessLock
+func (v *Volume) stopCompactionLog() {
+	if v.compactionLog == nil {
+		return
+	}
+	v.compactionLog.Close()
+	os.Remove(v.compactionLog.Name())
+	v.compactionLog = nil
+}
+
+// replayCompactionLog appends the logged needles to the compacted data file, and their entries to the compacted index file
+func (v *Volume) replayCompactionLog(newDatFileName, newIdxFileName string) (err error) {
+	if v.compactionLog == nil {
+		return fmt.Errorf("volume %d has no compaction log for the writes since the compaction", v.Id)
+	}
+
+	dst, err := os.OpenFile(newDatFileName, os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("open dat file %s failed: %v", newDatFileName, err)
+	}
+	defer dst.Close()
+
+	idx, err := os.OpenFile(newIdxFileName, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("open idx file %s failed: %v", newIdxFileName, err)
+	}
+	defer idx.Close()
+
+	dstOffset, err := dst.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("seek %s: %v", newDatFileName, err)
+	}
+
+	version := v.Version()
+	count := 0
+	for logOffset := int64(0); ; {
+		n, _, bodyLength, readErr := needle.ReadNeedleHeader(v.compactionLog, version, logOffset)
+		if readErr == io.EOF || n == nil {
+			break
+		}
+		if readErr != nil {
+			return fmt.Errorf("read needle header %s at %d: %v", v.compactionLog.Name(), logOffset, readErr)
+		}
+		blob, readErr := needle.ReadNeedleBlob(v.compactionLog, logOffset, n.Size, version)
+		if readErr != nil {
+			return fmt.Errorf("read needle %d from %s at %d: %v", n.Id, v.compactionLog.Name(), logOffset, readErr)
+		}
+		if _, err = dst.WriteAt(blob, dstOffset); err != nil {
+			return fmt.Errorf("replay needle %d to %s: %v", n.Id, newDatFileName, err)
+		}
+
+		// the deleted needles are logged without data
+		size := n.Size
+		if size == 0 {
+			size = TombstoneFileSize
+		}
+		if _, err = idx.Write(needle_map.ToBytes(n.Id, ToOffset(dstOffset), size)); err != nil {
+			return fmt.Errorf("replay needle %d to %s: %v", n.Id, newIdxFileName, err)
+		}
+
+		dstOffset += int64(len(blob))
+		logOffset += NeedleHeaderSize + bodyLength
+		count++
+	}
+
+	glog.V(0).Infof("volume %d replayed %d needles written during the compaction", v.Id, count)
+	return nil
+}
@@ -11,56 +11,6 @@ import (
 	"github.com/chrislusf/seaweedfs/weed/storage/types"
 )
 
-/*
-makediff test steps
-1. launch weed server at your local/dev environment, (option
-"garbageThreshold" for master and option "max" for volume should be set with specific value which would let
-preparing test prerequisite easier )
-   a) ./weed master -garbageThreshold=0.99 -mdir=./m
-   b) ./weed volume -dir=./data -max=1 -mserver=localhost:9333 -port=8080
-2. upload 4 different files, you could call dir/assign to get 4 different fids
-   a)  upload file A with fid a
-   b)  upload file B with fid b
-   c)  upload file C with fid c
-   d)  upload file D with fid d
-3. update file A and C
-   a)  modify file A and upload file A with fid a
-   b)  modify file C and upload file C with fid c
-   c)  record the current 1.idx's file size(lastCompactIndexOffset value)
-4. Compacting the data file
-   a)  run curl http://localhost:8080/admin/vacuum/compact?volumeId=1
-   b)  verify the 1.cpd and 1.cpx is created under volume directory
-5. update file B and delete file D
-   a)  modify file B and upload file B with fid b
-   d)  delete file B with fid b
-6. Now you could run the following UT case, the case should be run successfully
-7. Compact commit manually
-   a)  mv 1.cpd 1.dat
-   b)  mv 1.cpx 1.idx
-8. Restart Volume Server
-9. Now you should get updated file A,B,C
-*/
-
-func TestMakeDiff(t *testing.T) {
-
-	v := new(Volume)
-	//lastCompactIndexOffset value is the index file size before step 4
-	v.lastCompactIndexOffset = 96
-	v.SuperBlock.version = 0x2
-	/*
-		err := v.makeupDiff(
-			"/yourpath/1.cpd",
-			"/yourpath/1.cpx",
-			"/yourpath/1.dat",
-			"/yourpath/1.idx")
-		if err != nil {
-			t.Errorf("makeupDiff err is %v", err)
-		} else {
-			t.Log("makeupDiff Succeeded")
-		}
-	*/
-}
-
 func TestCompaction(t *testing.T) {
 	dir, err := ioutil.TempDir("", "example")
 	if err != nil {
@@ -126,6 +76,277 @@ func TestCompaction(t *testing.T) {
 	}
 
 }
+func TestCompactionWithConcurrentWrites(t *testing.T) {
+	dir, err := ioutil.TempDir("", "example")
+	if err != nil {
+		t.Fatalf("temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir) // clean up
+
+	v, err := NewVolume(dir, "", 1, NeedleMapInMemory, &ReplicaPlacement{}, &needle.TTL{}, 0)
+	if err != nil {
+		t.Fatalf("volume creation: %v", err)
+	}
+
+	fileCount := 3000
+	infos := make([]*needleInfo, 2*fileCount)
+	for i := 1; i <= fileCount; i++ {
+		doSomeWritesDeletes(i, v, t, infos)
+	}
+
+	// the throttled compaction runs while the writes and deletes keep going
+	compacted := make(chan error)
+	go func() {
+		compacted <- v.Compact(0, 1024*1024)
+	}()
+	for i := fileCount + 1; i <= 2*fileCount; i++ {
+		doSomeWritesDeletes(i, v, t, infos)
+	}
+	if err = <-compacted; err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	if err = v.CommitCompact(); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if _, err = os.Stat(v.FileName() + ".cpl"); !os.IsNotExist(err) {
+		t.Errorf("compaction log is not removed: %v", err)
+	}
+	verifyNeedles(t, v, infos)
+
+	v.Close()
+	v, err = NewVolume(dir, "", 1, NeedleMapInMemory, nil, nil, 0)
+	if err != nil {
+		t.Fatalf("volume reloading: %v", err)
+	}
+	verifyNeedles(t, v, infos)
+	v.Close()
+}
+
+func TestCompactionCopiesUntilLogStart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "example")
+	if err != nil {
+		t.Fatalf("temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir) // clean up
+
+	v, err := NewVolume(dir, "", 1, NeedleMapInMemory, &ReplicaPlacement{}, &needle.TTL{}, 0)
+	if err != nil {
+		t.Fatalf("volume creation: %v", err)
+	}
+	defer v.Close()
+	for i := uint64(1); i <= 3; i++ {
+		if _, _, _, err = v.writeNeedle(newRandomNeedle(i)); err != nil {
+			t.Fatalf("write needle %d: %v", i, err)
+		}
+	}
+
+	if err = v.startCompactionLog(); err != nil {
+		t.Fatalf("start compaction log: %v", err)
+	}
+	// appended after the tail is taken, so left to the log
+	if _, _, _, err = v.writeNeedle(newRandomNeedle(4)); err != nil {
+		t.Fatalf("write needle 4: %v", err)
+	}
+	if err = v.copyDataAndGenerateIndexFile(v.FileName()+".cpd", v.FileName()+".cpx", 0, 0); err != nil {
+		t.Fatalf("copy: %v", err)
+	}
+	stat, err := os.Stat(v.FileName() + ".cpx")
+	if err != nil {
+		t.Fatalf("stat cpx: %v", err)
+	}
+	if stat.Size() != 3*types.NeedleMapEntrySize {
+		t.Errorf("copied %d index entries, expected 3", stat.Size()/types.NeedleMapEntrySize)
+	}
+
+	if err = v.CommitCompact(); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if v.FileCount() != 4 {
+		t.Errorf("%d files after the commit, expected 4", v.FileCount())
+	}
+}
+
+func TestCompactionInPlace(t *testing.T) {
+	dir, err := ioutil.TempDir("", "example")
+	if err != nil {
+		t.Fatalf("temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir) // clean up
+
+	v, err := NewVolume(dir, "", 1, NeedleMapInMemory, &ReplicaPlacement{}, &needle.TTL{}, 0)
+	if err != nil {
+		t.Fatalf("volume creation: %v", err)
+	}
+
+	fileCount := 10000
+	infos := make([]*needleInfo, fileCount)
+	for i := 1; i <= fileCount; i++ {
+		doSomeWritesDeletes(i, v, t, infos)
+		if i%3 == 0 {
+			v.deleteNeedle(newEmptyNeedle(uint64(i)))
+			infos[i-1].size = 0
+		}
+	}
+	datSizeBefore, _, _ := v.FileStat()
+
+	if err = v.CompactInPlace(0); err != nil {
+		t.Fatalf("compact in place: %v", err)
+	}
+	if err = v.CommitCompact(); err != nil {
+		t.Fatalf("commit compact: %v", err)
+	}
+	datSizeAfter, _, _ := v.FileStat()
+	if datSizeAfter >= datSizeBefore*3/4 {
+		t.Fatalf("volume size %d => %d after compaction", datSizeBefore, datSizeAfter)
+	}
+	verifyNeedles(t, v, infos)
+
+	// replay the journal of a moved needle after a crash
+	liveId := 1
+	for infos[liveId-1].size == 0 {
+		liveId++
+	}
+	nv, _ := v.nm.Get(types.Uint64ToNeedleId(uint64(liveId)))
+	blob, err := needle.ReadNeedleBlob(v.dataFile, nv.Offset.ToAcutalOffset(), nv.Size, v.Version())
+	if err != nil {
+		t.Fatalf("read needle blob: %v", err)
+	}
+	if err = writeMoveJournal(v.FileName()+".cpj", blob, nv.Offset.ToAcutalOffset()); err != nil {
+		t.Fatalf("write journal: %v", err)
+	}
+	v.Close()
+
+	v, err = NewVolume(dir, "", 1, NeedleMapInMemory, nil, nil, 0)
+	if err != nil {
+		t.Fatalf("volume reloading: %v", err)
+	}
+	if _, err = os.Stat(v.FileName() + ".cpj"); !os.IsNotExist(err) {
+		t.Fatalf("journal is not removed: %v", err)
+	}
+	verifyNeedles(t, v, infos)
+	v.Close()
+}
+
+func TestCompactionInPlaceWithConcurrentWrites(t *testing.T) {
+	dir, err := ioutil.TempDir("", "example")
+	if err != nil {
+		t.Fatalf("temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir) // clean up
+
+	v, err := NewVolume(dir, "", 1, NeedleMapInMemory, &ReplicaPlacement{}, &needle.TTL{}, 0)
+	if err != nil {
+		t.Fatalf("volume creation: %v", err)
+	}
+
+	fileCount := 3000
+	infos := make([]*needleInfo, 2*fileCount)
+	for i := 1; i <= fileCount; i++ {
+		doSomeWritesDeletes(i, v, t, infos)
+		if i%3 == 0 {
+			v.deleteNeedle(newEmptyNeedle(uint64(i)))
+			infos[i-1].size = 0
+		}
+	}
+
+	// the throttled compaction moves the needles in batches, while the writes and deletes keep going
+	compacted := make(chan error)
+	go func() {
+		compacted <- v.CompactInPlace(1024 * 1024)
+	}()
+	for i := fileCount + 1; i <= 2*fileCount; i++ {
+		doSomeWritesDeletes(i, v, t, infos)
+	}
+	if err = <-compacted; err != nil {
+		t.Fatalf("compact in place: %v", err)
+	}
+	if err = v.CommitCompact(); err != nil {
+		t.Fatalf("commit compact: %v", err)
+	}
+	verifyNeedles(t, v, infos)
+
+	v.Close()
+	v, err = NewVolume(dir, "", 1, NeedleMapInMemory, nil, nil, 0)
+	if err != nil {
+		t.Fatalf("volume reloading: %v", err)
+	}
+	verifyNeedles(t, v, infos)
+	v.Close()
+}
+
+func TestGarbageLevelCountsExpiredNeedles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "example")
+	if err != nil {
+		t.Fatalf("temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir) // clean up
+
+	v, err := NewVolume(dir, "", 1, NeedleMapInMemory, &ReplicaPlacement{}, &needle.TTL{}, 0)
+	if err != nil {
+		t.Fatalf("volume creation: %v", err)
+	}
+	defer v.Close()
+
+	ttl, _ := needle.ReadTTL("1m")
+	expired := newRandomNeedle(1)
+	expired.Ttl = ttl
+	expired.SetHasTtl()
+	expired.LastModified = uint64(time.Now().Unix()) - 120
+	expired.SetHasLastModifiedDate()
+	if _, _, _, err = v.writeNeedle(expired); err != nil {
+		t.Fatalf("write expired needle: %v", err)
+	}
+	live := newRandomNeedle(2)
+	live.Ttl = ttl
+	live.SetHasTtl()
+	live.LastModified = uint64(time.Now().Unix())
+	live.SetHasLastModifiedDate()
+	if _, _, _, err = v.writeNeedle(live); err != nil {
+		t.Fatalf("write live needle: %v", err)
+	}
+
+	if level := v.garbageLevel(); level <= 0 {
+		t.Errorf("garbage level %f with an expired needle", level)
+	}
+
+	if err = v.Compact(0, 0); err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	if err = v.CommitCompact(); err != nil {
+		t.Fatalf("commit compact: %v", err)
+	}
+	if level := v.garbageLevel(); level != 0 {
+		t.Errorf("garbage level %f after vacuum", level)
+	}
+	if _, err = v.readNeedle(newEmptyNeedle(1)); err == nil {
+		t.Errorf("expired needle kept by vacuum")
+	}
+
+	v.deleteNeedle(newEmptyNeedle(2))
+	if len(v.expiries.needles) != 0 {
+		t.Errorf("deleted needle still tracked")
+	}
+}
+
+func verifyNeedles(t *testing.T, v *Volume, infos []*needleInfo) {
+	for i, info := range infos {
+		n := newEmptyNeedle(uint64(i + 1))
+		size, err := v.readNeedle(n)
+		if info.size == 0 {
+			if err == nil {
+				t.Fatalf("read deleted file %d", i+1)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("read file %d: %v", i+1, err)
+		}
+		if info.size != uint32(size) || info.crc != n.Checksum {
+			t.Fatalf("read file %d size %d checksum %d, expected %d %d", i+1, size, n.Checksum, info.size, info.crc)
+		}
+	}
+}
+
 func doSomeWritesDeletes(i int, v *Volume, t *testing.T, infos []*needleInfo) {
 	n := newRandomNeedle(uint64(i))
 	_, size, _, err := v.writeNeedle(n)
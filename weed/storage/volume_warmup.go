@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+	"github.com/chrislusf/seaweedfs/weed/util"
+)
+
+const warmupBufferSize = 1024 * 1024
+
+func (s *Store) WarmupVolume(vid needle.VolumeId, bytesPerSecond int64) (int64, error) {
+	if v := s.findVolume(vid); v != nil {
+		return v.Warmup(bytesPerSecond)
+	}
+	return 0, fmt.Errorf("volume id %d is not found during warmup", vid)
+}
+
+// Warmup reads the .idx and .dat files sequentially, to load them into the OS page cache ahead of the reads
+func (v *Volume) Warmup(bytesPerSecond int64) (warmed int64, err error) {
+	throttler := util.NewWriteThrottler(bytesPerSecond)
+	buf := make([]byte, warmupBufferSize)
+	for _, ext := range []string{".idx", ".dat"} {
+		n, err := warmupFile(v.FileName()+ext, buf, throttler)
+		warmed += n
+		if err != nil {
+			return warmed, err
+		}
+	}
+	return warmed, nil
+}
+
+func warmupFile(fileName string, buf []byte, throttler *util.WriteThrottler) (warmed int64, err error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	for {
+		n, readErr := f.Read(buf)
+		warmed += int64(n)
+		throttler.MaybeSlowdown(int64(n))
+		if readErr == io.EOF {
+			return warmed, nil
+		}
+		if readErr != nil {
+			return warmed, fmt.Errorf("read %s: %v", fileName, readErr)
+		}
+	}
+}
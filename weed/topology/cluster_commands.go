@@ -29,3 +29,60 @@ func (c *MaxVolumeIdCommand) Apply(server raft.Server) (interface{}, error) {
 
 	return nil, nil
 }
+
+// VolumeGrowthStrategyCommand sets the growth strategy of a collection, or removes it if the strategy is nil
+type VolumeGrowthStrategyCommand struct {
+	Collection string                `json:"collection"`
+	Strategy   *VolumeGrowthStrategy `json:"strategy,omitempty"`
+}
+
+func (c *VolumeGrowthStrategyCommand) CommandName() string {
+	return "VolumeGrowthStrategy"
+}
+
+func (c *VolumeGrowthStrategyCommand) Apply(server raft.Server) (interface{}, error) {
+	topo := server.Context().(*Topology)
+	topo.SetVolumeGrowthStrategy(c.Collection, c.Strategy)
+
+	glog.V(0).Infof("volume growth strategy of collection %q: %v", c.Collection, c.Strategy)
+
+	return nil, nil
+}
+
+// QuarantineCommand quarantines a flapping volume server, or clears it if the quarantine is nil
+type QuarantineCommand struct {
+	Url        string               `json:"url"`
+	Quarantine *QuarantinedDataNode `json:"quarantine,omitempty"`
+}
+
+func (c *QuarantineCommand) CommandName() string {
+	return "Quarantine"
+}
+
+func (c *QuarantineCommand) Apply(server raft.Server) (interface{}, error) {
+	topo := server.Context().(*Topology)
+	topo.SetQuarantine(c.Url, c.Quarantine)
+
+	glog.V(1).Infof("volume server %s quarantine: %+v", c.Url, c.Quarantine)
+
+	return nil, nil
+}
+
+// CollectionConfigCommand sets the defaults of a collection, or removes them if the config is nil
+type CollectionConfigCommand struct {
+	Collection string            `json:"collection"`
+	Config     *CollectionConfig `json:"config,omitempty"`
+}
+
+func (c *CollectionConfigCommand) CommandName() string {
+	return "CollectionConfig"
+}
+
+func (c *CollectionConfigCommand) Apply(server raft.Server) (interface{}, error) {
+	topo := server.Context().(*Topology)
+	topo.SetCollectionConfig(c.Collection, c.Config)
+
+	glog.V(0).Infof("collection %q config: %+v", c.Collection, c.Config)
+
+	return nil, nil
+}
@@ -0,0 +1,56 @@
+package topology
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+)
+
+// MasterStateVersion is the format version of the master state saved in the raft snapshots.
+// Increase it when the MasterState changes, and keep recovering the older versions.
+// The version 2 adds the volume growth strategies, the version 3 the collection configs,
+// and the version 4 the quarantined volume servers.
+const MasterStateVersion = 4
+
+// MasterState is the topology state replicated by the raft commands, saved in the raft snapshots
+// so the raft log before the snapshots can be dropped.
+type MasterState struct {
+	Version           int                             `json:"version"`
+	MaxVolumeId       needle.VolumeId                 `json:"maxVolumeId"`
+	GrowthStrategies  map[string]VolumeGrowthStrategy `json:"growthStrategies,omitempty"`
+	CollectionConfigs map[string]CollectionConfig     `json:"collectionConfigs,omitempty"`
+	Quarantined       map[string]QuarantinedDataNode  `json:"quarantined,omitempty"`
+}
+
+// SaveState encodes the replicated topology state for a raft snapshot
+func (t *Topology) SaveState() ([]byte, error) {
+	state := MasterState{
+		Version:           MasterStateVersion,
+		MaxVolumeId:       t.GetMaxVolumeId(),
+		GrowthStrategies:  t.VolumeGrowthStrategies(),
+		CollectionConfigs: t.CollectionConfigs(),
+		Quarantined:       t.quarantinedDataNodes(),
+	}
+	glog.V(1).Infof("save master state %+v", state)
+	return json.Marshal(state)
+}
+
+// RecoverState restores the replicated topology state from a raft snapshot.
+// The state without a version is the same as the version 1.
+func (t *Topology) RecoverState(data []byte) error {
+	state := MasterState{}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("decode master state: %v", err)
+	}
+	if state.Version > MasterStateVersion {
+		return fmt.Errorf("master state version %d is newer than the supported version %d", state.Version, MasterStateVersion)
+	}
+	glog.V(0).Infof("recover master state %+v", state)
+	t.UpAdjustMaxVolumeId(state.MaxVolumeId)
+	t.setVolumeGrowthStrategies(state.GrowthStrategies)
+	t.setCollectionConfigs(state.CollectionConfigs)
+	t.setQuarantinedDataNodes(state.Quarantined)
+	return nil
+}
@@ -0,0 +1,44 @@
+package topology
+
+import (
+	"testing"
+
+	"github.com/chrislusf/seaweedfs/weed/sequence"
+)
+
+func TestMasterStateRecovery(t *testing.T) {
+	topo := NewTopology("weedfs", sequence.NewMemorySequencer(), 32*1024, 5)
+	topo.UpAdjustMaxVolumeId(37)
+	topo.SetVolumeGrowthStrategy("pictures", &VolumeGrowthStrategy{Kind: GrowthStrategyTarget, WritableTarget: 20})
+
+	data, err := topo.SaveState()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recovered := NewTopology("weedfs", sequence.NewMemorySequencer(), 32*1024, 5)
+	if err := recovered.RecoverState(data); err != nil {
+		t.Fatal(err)
+	}
+	if recovered.GetMaxVolumeId() != 37 {
+		t.Errorf("recovered max volume id %d, expected 37", recovered.GetMaxVolumeId())
+	}
+	if s := recovered.VolumeGrowthStrategies()["pictures"]; s.Kind != GrowthStrategyTarget || s.WritableTarget != 20 {
+		t.Errorf("recovered growth strategy %+v", s)
+	}
+
+	// the state saved without a version
+	if err := recovered.RecoverState([]byte(`{"maxVolumeId":41}`)); err != nil {
+		t.Fatal(err)
+	}
+	if recovered.GetMaxVolumeId() != 41 {
+		t.Errorf("recovered max volume id %d, expected 41", recovered.GetMaxVolumeId())
+	}
+
+	if err := recovered.RecoverState([]byte(`{"version":99,"maxVolumeId":43}`)); err == nil {
+		t.Errorf("recovered the state of a newer version")
+	}
+	if recovered.GetMaxVolumeId() != 41 {
+		t.Errorf("max volume id changed to %d by the state of a newer version", recovered.GetMaxVolumeId())
+	}
+}
@@ -2,6 +2,7 @@ package topology
 
 import (
 	"fmt"
+	"sync/atomic"
 
 	"github.com/chrislusf/seaweedfs/weed/storage"
 	"github.com/chrislusf/seaweedfs/weed/storage/needle"
@@ -30,11 +31,22 @@ func (c *Collection) GetOrCreateVolumeLayout(rp *storage.ReplicaPlacement, ttl *
 		keyString += ttl.String()
 	}
 	vl := c.storageType2VolumeLayout.Get(keyString, func() interface{} {
-		return NewVolumeLayout(rp, ttl, c.volumeSizeLimit)
+		return NewVolumeLayout(rp, ttl, atomic.LoadUint64(&c.volumeSizeLimit))
 	})
 	return vl.(*VolumeLayout)
 }
 
+// setVolumeSizeLimit changes the size limit of the volumes. The volumes over the lowered limit become full
+// on the next check, while the volumes already full stay full under a raised limit.
+func (c *Collection) setVolumeSizeLimit(volumeSizeLimit uint64) {
+	atomic.StoreUint64(&c.volumeSizeLimit, volumeSizeLimit)
+	for _, vl := range c.storageType2VolumeLayout.Items() {
+		if vl != nil {
+			vl.(*VolumeLayout).setVolumeSizeLimit(volumeSizeLimit)
+		}
+	}
+}
+
 func (c *Collection) Lookup(vid needle.VolumeId) []*DataNode {
 	for _, vl := range c.storageType2VolumeLayout.Items() {
 		if vl != nil {
@@ -0,0 +1,111 @@
+package topology
+
+import (
+	"fmt"
+
+	"github.com/chrislusf/seaweedfs/weed/storage"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+	"github.com/chrislusf/seaweedfs/weed/util"
+)
+
+// CollectionConfig is the defaults of the writes to a collection, set by "collection.create" and replicated by raft.
+// The writes without the replication or the ttl inherit them, and the volumes of the collection are full at the size limit.
+// With NeedleTtl, the writes of all ttls share the volumes without a ttl, each needle expiring by its own ttl,
+// instead of one volume layout for each ttl.
+type CollectionConfig struct {
+	Replication       string `json:"replication,omitempty"`
+	Ttl               string `json:"ttl,omitempty"`
+	VolumeSizeLimitMB uint64 `json:"volumeSizeLimitMB,omitempty"`
+	NeedleTtl         bool   `json:"needleTtl,omitempty"`
+}
+
+func (c CollectionConfig) Validate() error {
+	if c.Replication != "" {
+		if _, err := storage.NewReplicaPlacementFromString(c.Replication); err != nil {
+			return fmt.Errorf("replication %s: %v", c.Replication, err)
+		}
+	}
+	if c.Ttl != "" {
+		if _, err := needle.ReadTTL(c.Ttl); err != nil {
+			return fmt.Errorf("ttl %s: %v", c.Ttl, err)
+		}
+	}
+	if c.VolumeSizeLimitMB > util.VolumeSizeLimitGB*1000 {
+		return fmt.Errorf("volumeSizeLimitMB should be smaller than %d", util.VolumeSizeLimitGB*1000)
+	}
+	return nil
+}
+
+// SetCollectionConfig sets the defaults of the collection, or removes them if nil.
+// The size limit applies to the existing volumes of the collection too.
+func (t *Topology) SetCollectionConfig(collection string, config *CollectionConfig) {
+	t.collectionConfigLock.Lock()
+	if config == nil {
+		delete(t.collectionConfigs, collection)
+	} else {
+		t.collectionConfigs[collection] = *config
+	}
+	t.collectionConfigLock.Unlock()
+
+	t.applyCollectionVolumeSizeLimit(collection)
+}
+
+// GetCollectionConfig returns the defaults of the collection, if set by "collection.create"
+func (t *Topology) GetCollectionConfig(collection string) (config CollectionConfig, found bool) {
+	t.collectionConfigLock.RLock()
+	defer t.collectionConfigLock.RUnlock()
+	config, found = t.collectionConfigs[collection]
+	return
+}
+
+// CollectionConfigs lists the defaults of the collections
+func (t *Topology) CollectionConfigs() map[string]CollectionConfig {
+	t.collectionConfigLock.RLock()
+	defer t.collectionConfigLock.RUnlock()
+	ret := make(map[string]CollectionConfig, len(t.collectionConfigs))
+	for collection, config := range t.collectionConfigs {
+		ret[collection] = config
+	}
+	return ret
+}
+
+func (t *Topology) setCollectionConfigs(configs map[string]CollectionConfig) {
+	t.collectionConfigLock.Lock()
+	previous := t.collectionConfigs
+	t.collectionConfigs = make(map[string]CollectionConfig, len(configs))
+	for collection, config := range configs {
+		t.collectionConfigs[collection] = config
+	}
+	t.collectionConfigLock.Unlock()
+
+	for collection := range previous {
+		t.applyCollectionVolumeSizeLimit(collection)
+	}
+	for collection := range configs {
+		t.applyCollectionVolumeSizeLimit(collection)
+	}
+}
+
+// LayoutTtl is the ttl of the volumes to write the needles of the ttl to, which is no ttl if the needles keep their own ttls
+func (t *Topology) LayoutTtl(collection string, ttl *needle.TTL) *needle.TTL {
+	if config, found := t.GetCollectionConfig(collection); found && config.NeedleTtl {
+		return needle.EMPTY_TTL
+	}
+	return ttl
+}
+
+// CollectionVolumeSizeLimit is the size limit of the volumes of the collection, or the default limit
+func (t *Topology) CollectionVolumeSizeLimit(collection string, defaultLimit uint64) uint64 {
+	if config, found := t.GetCollectionConfig(collection); found && config.VolumeSizeLimitMB > 0 {
+		return config.VolumeSizeLimitMB * 1024 * 1024
+	}
+	return defaultLimit
+}
+
+func (t *Topology) applyCollectionVolumeSizeLimit(collection string) {
+	c, found := t.FindCollection(collection)
+	if !found {
+		return
+	}
+	c.setVolumeSizeLimit(t.CollectionVolumeSizeLimit(collection, t.volumeSizeLimit))
+}
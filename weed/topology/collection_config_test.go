@@ -0,0 +1,58 @@
+package topology
+
+import (
+	"testing"
+
+	"github.com/chrislusf/seaweedfs/weed/sequence"
+	"github.com/chrislusf/seaweedfs/weed/storage"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+)
+
+func TestCollectionConfig(t *testing.T) {
+	topo := NewTopology("weedfs", sequence.NewMemorySequencer(), 32*1024*1024, 5)
+	rp, _ := storage.NewReplicaPlacementFromString("000")
+
+	existing := topo.GetVolumeLayout("logs", rp, needle.EMPTY_TTL)
+	topo.SetCollectionConfig("logs", &CollectionConfig{Ttl: "7d", VolumeSizeLimitMB: 2})
+	topo.SetCollectionConfig("pictures", &CollectionConfig{Replication: "010"})
+
+	if existing.volumeSizeLimit != 2*1024*1024 {
+		t.Errorf("existing volume layout limit %d", existing.volumeSizeLimit)
+	}
+	if vl := topo.GetVolumeLayout("pictures", rp, needle.EMPTY_TTL); vl.volumeSizeLimit != 32*1024*1024 {
+		t.Errorf("volume layout limit %d without the configured limit", vl.volumeSizeLimit)
+	}
+
+	collections := topo.ListCollections(true, false)
+	if len(collections) != 2 {
+		t.Errorf("collections %v", collections)
+	}
+
+	topo.SetCollectionConfig("logs", nil)
+	if existing.volumeSizeLimit != 32*1024*1024 {
+		t.Errorf("volume layout limit %d after the config is removed", existing.volumeSizeLimit)
+	}
+
+	for _, c := range []CollectionConfig{
+		{Replication: "019"},
+		{Ttl: "xd"},
+		{VolumeSizeLimitMB: 100 * 1000},
+	} {
+		if err := c.Validate(); err == nil {
+			t.Errorf("%+v is valid", c)
+		}
+	}
+}
+
+func TestCollectionNeedleTtl(t *testing.T) {
+	topo := NewTopology("weedfs", sequence.NewMemorySequencer(), 32*1024*1024, 5)
+	topo.SetCollectionConfig("logs", &CollectionConfig{NeedleTtl: true})
+
+	ttl, _ := needle.ReadTTL("3d")
+	if layoutTtl := topo.LayoutTtl("logs", ttl); layoutTtl != needle.EMPTY_TTL {
+		t.Errorf("layout ttl %s for the needle ttl collection", layoutTtl)
+	}
+	if layoutTtl := topo.LayoutTtl("pictures", ttl); layoutTtl != ttl {
+		t.Errorf("layout ttl %s for the collection without a config", layoutTtl)
+	}
+}
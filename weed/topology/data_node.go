@@ -3,6 +3,7 @@ package topology
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
 	"github.com/chrislusf/seaweedfs/weed/storage/erasure_coding"
@@ -16,13 +17,21 @@ import (
 
 type DataNode struct {
 	NodeImpl
-	volumes      map[needle.VolumeId]storage.VolumeInfo
-	Ip           string
-	Port         int
-	PublicUrl    string
-	LastSeen     int64 // unix time in seconds
-	ecShards     map[needle.VolumeId]*erasure_coding.EcVolumeInfo
-	ecShardsLock sync.RWMutex
+	volumes       map[needle.VolumeId]storage.VolumeInfo
+	Ip            string
+	Port          int
+	PublicUrl     string
+	LastSeen      int64 // unix time in seconds
+	ecShards      map[needle.VolumeId]*erasure_coding.EcVolumeInfo
+	ecShardsLock  sync.RWMutex
+	isDraining    bool
+	isReadOnly    bool
+	isQuarantined bool
+	lastHeartbeat int64 // unix time in seconds
+	labels        map[string]string
+	fileSizeLimit uint64 // the largest file accepted in one upload, 0 if not reported
+	// the free slots taken off the rack, data center and topology while draining, read only or quarantined
+	withheldVolumeCount int64
 }
 
 func NewDataNode(id string) *DataNode {
@@ -86,6 +95,10 @@ func (dn *DataNode) UpdateVolumes(actualVolumes []storage.VolumeInfo) (newVolume
 func (dn *DataNode) DeltaUpdateVolumes(newlVolumes, deletedVolumes []storage.VolumeInfo) {
 	dn.Lock()
 	for _, v := range deletedVolumes {
+		// the delta heartbeats may report a volume already deleted by the volume server
+		if _, found := dn.volumes[v.Id]; !found {
+			continue
+		}
 		delete(dn.volumes, v.Id)
 		dn.UpAdjustVolumeCountDelta(-1)
 		dn.UpAdjustActiveVolumeCountDelta(-1)
@@ -125,15 +138,27 @@ func (dn *DataNode) GetRack() *Rack {
 	return dn.Parent().(*NodeImpl).value.(*Rack)
 }
 
+// GetTopology returns nil if the data node is not linked to a topology
 func (dn *DataNode) GetTopology() *Topology {
 	p := dn.Parent()
+	if p == nil {
+		return nil
+	}
 	for p.Parent() != nil {
 		p = p.Parent()
 	}
-	t := p.(*Topology)
+	t, _ := p.(*Topology)
 	return t
 }
 
+func (dn *DataNode) latestUtilizationForecast() (forecast UtilizationForecast, found bool) {
+	t := dn.GetTopology()
+	if t == nil {
+		return
+	}
+	return t.LatestUtilizationForecast(dn.Url())
+}
+
 func (dn *DataNode) MatchLocation(ip string, port int) bool {
 	return dn.Ip == ip && dn.Port == port
 }
@@ -142,6 +167,131 @@ func (dn *DataNode) Url() string {
 	return dn.Ip + ":" + strconv.Itoa(dn.Port)
 }
 
+func (dn *DataNode) SetDraining(isDraining bool) {
+	dn.Lock()
+	defer dn.Unlock()
+	if isDraining && !dn.isDraining {
+		glog.V(0).Infof("volume server %s is draining", dn.Url())
+	}
+	dn.isDraining = isDraining
+	dn.withholdFreeSlots()
+}
+
+func (dn *DataNode) IsDraining() bool {
+	dn.RLock()
+	defer dn.RUnlock()
+	return dn.isDraining
+}
+
+func (dn *DataNode) SetReadOnly(isReadOnly bool) {
+	dn.Lock()
+	defer dn.Unlock()
+	if isReadOnly && !dn.isReadOnly {
+		glog.V(0).Infof("volume server %s is read only", dn.Url())
+	}
+	dn.isReadOnly = isReadOnly
+	dn.withholdFreeSlots()
+}
+
+func (dn *DataNode) IsReadOnly() bool {
+	dn.RLock()
+	defer dn.RUnlock()
+	return dn.isReadOnly
+}
+
+func (dn *DataNode) SetQuarantined(isQuarantined bool) {
+	dn.Lock()
+	defer dn.Unlock()
+	if isQuarantined && !dn.isQuarantined {
+		glog.V(0).Infof("volume server %s is quarantined", dn.Url())
+	}
+	dn.isQuarantined = isQuarantined
+	dn.withholdFreeSlots()
+}
+
+func (dn *DataNode) IsQuarantined() bool {
+	dn.RLock()
+	defer dn.RUnlock()
+	return dn.isQuarantined
+}
+
+// SetLabels sets the labels reported by the volume server, for the label selectors of the placement
+func (dn *DataNode) SetLabels(labels map[string]string) {
+	dn.Lock()
+	defer dn.Unlock()
+	dn.labels = labels
+}
+
+func (dn *DataNode) Labels() map[string]string {
+	dn.RLock()
+	defer dn.RUnlock()
+	return dn.labels
+}
+
+// SetFileSizeLimit sets the upload size limit reported by the volume server, passed on to the clients with the volume locations
+func (dn *DataNode) SetFileSizeLimit(fileSizeLimit uint64) {
+	dn.Lock()
+	defer dn.Unlock()
+	dn.fileSizeLimit = fileSizeLimit
+}
+
+func (dn *DataNode) FileSizeLimit() uint64 {
+	dn.RLock()
+	defer dn.RUnlock()
+	return dn.fileSizeLimit
+}
+
+// Heartbeat records the time of the latest heartbeat.
+// LastSeen is not updated here, since it is only set when the volume server registers.
+func (dn *DataNode) Heartbeat() {
+	dn.Lock()
+	defer dn.Unlock()
+	dn.lastHeartbeat = time.Now().Unix()
+}
+
+func (dn *DataNode) LastHeartbeat() int64 {
+	dn.RLock()
+	defer dn.RUnlock()
+	return dn.lastHeartbeat
+}
+
+// withholdFreeSlots takes the free slots of a draining, read only or quarantined data node off the counts of its parents,
+// so the volume growth does not pick its rack or data center for them, and gives them back when the data node is writable again.
+// It is called on each heartbeat, to follow the volumes added or deleted meanwhile.
+func (dn *DataNode) withholdFreeSlots() {
+	var withheld int64
+	if dn.isDraining || dn.isReadOnly || dn.isQuarantined {
+		if withheld = dn.NodeImpl.FreeSpace(); withheld < 0 {
+			withheld = 0
+		}
+	}
+	dn.setWithheldVolumeCount(withheld)
+}
+
+func (dn *DataNode) setWithheldVolumeCount(withheld int64) {
+	parent := dn.Parent()
+	if parent == nil || withheld == dn.withheldVolumeCount {
+		return
+	}
+	parent.UpAdjustMaxVolumeCountDelta(dn.withheldVolumeCount - withheld)
+	dn.withheldVolumeCount = withheld
+}
+
+// releaseFreeSlots gives the withheld free slots back to the parents, before the data node is unlinked
+func (dn *DataNode) releaseFreeSlots() {
+	dn.Lock()
+	defer dn.Unlock()
+	dn.setWithheldVolumeCount(0)
+}
+
+// FreeSpace reports no free slots for a draining, read only or quarantined volume server, so no new volumes are placed on it.
+func (dn *DataNode) FreeSpace() int64 {
+	if dn.IsDraining() || dn.IsReadOnly() || dn.IsQuarantined() {
+		return 0
+	}
+	return dn.NodeImpl.FreeSpace()
+}
+
 func (dn *DataNode) ToMap() interface{} {
 	ret := make(map[string]interface{})
 	ret["Url"] = dn.Url()
@@ -150,6 +300,15 @@ func (dn *DataNode) ToMap() interface{} {
 	ret["Max"] = dn.GetMaxVolumeCount()
 	ret["Free"] = dn.FreeSpace()
 	ret["PublicUrl"] = dn.PublicUrl
+	ret["Draining"] = dn.IsDraining()
+	ret["ReadOnly"] = dn.IsReadOnly()
+	ret["Quarantined"] = dn.IsQuarantined()
+	if labels := dn.Labels(); len(labels) > 0 {
+		ret["Labels"] = storage.FormatLabels(labels)
+	}
+	if forecast, found := dn.latestUtilizationForecast(); found && forecast.DaysToFull > 0 {
+		ret["DaysToFull"] = int(forecast.DaysToFull)
+	}
 	return ret
 }
 
@@ -160,6 +319,13 @@ func (dn *DataNode) ToDataNodeInfo() *master_pb.DataNodeInfo {
 		MaxVolumeCount:    uint64(dn.GetMaxVolumeCount()),
 		FreeVolumeCount:   uint64(dn.FreeSpace()),
 		ActiveVolumeCount: uint64(dn.GetActiveVolumeCount()),
+		IsDraining:        dn.IsDraining(),
+		IsReadOnly:        dn.IsReadOnly(),
+		IsQuarantined:     dn.IsQuarantined(),
+		Labels:            dn.Labels(),
+	}
+	if forecast, found := dn.latestUtilizationForecast(); found {
+		m.GrowthBytesPerDay, m.DaysToFull = forecast.GrowthBytesPerDay, forecast.DaysToFull
 	}
 	for _, v := range dn.GetVolumes() {
 		m.VolumeInfos = append(m.VolumeInfos, v.ToVolumeInformationMessage())
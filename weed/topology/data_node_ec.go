@@ -51,13 +51,13 @@ func (dn *DataNode) UpdateEcShards(actualShards []*erasure_coding.EcVolumeInfo)
 	}
 	dn.ecShardsLock.RUnlock()
 
+	// always set to the new ec shard map, to refresh the deletion stats
+	dn.ecShardsLock.Lock()
+	dn.ecShards = actualEcShardMap
 	if len(newShards) > 0 || len(deletedShards) > 0 {
-		// if changed, set to the new ec shard map
-		dn.ecShardsLock.Lock()
-		dn.ecShards = actualEcShardMap
 		dn.UpAdjustEcShardCountDelta(int64(newShardCount - deletedShardCount))
-		dn.ecShardsLock.Unlock()
 	}
+	dn.ecShardsLock.Unlock()
 
 	return
 }
@@ -108,6 +108,14 @@ func (dn *DataNode) DeleteEcShard(s *erasure_coding.EcVolumeInfo) {
 
 }
 
+// HasEcShardsById tells whether any ec shard of the volume is still on the volume server
+func (dn *DataNode) HasEcShardsById(id needle.VolumeId) bool {
+	dn.ecShardsLock.RLock()
+	defer dn.ecShardsLock.RUnlock()
+	_, ok := dn.ecShards[id]
+	return ok
+}
+
 func (dn *DataNode) HasVolumesById(id needle.VolumeId) (hasVolumeId bool) {
 
 	// check whether normal volumes has this volume id
@@ -18,6 +18,7 @@ type Node interface {
 	String() string
 	FreeSpace() int64
 	ReserveOneVolume(r int64) (*DataNode, error)
+	reserveOneVolume(r int64, freeSpace func(Node) int64) (*DataNode, error)
 	UpAdjustMaxVolumeCountDelta(maxVolumeCountDelta int64)
 	UpAdjustVolumeCountDelta(volumeCountDelta int64)
 	UpAdjustEcShardCountDelta(ecShardCountDelta int64)
@@ -60,6 +61,11 @@ type NodeImpl struct {
 
 // the first node must satisfy filterFirstNodeFn(), the rest nodes must have one free slot
 func (n *NodeImpl) RandomlyPickNodes(numberOfNodes int, filterFirstNodeFn func(dn Node) error) (firstNode Node, restNodes []Node, err error) {
+	return n.randomlyPickNodes(numberOfNodes, Node.FreeSpace, filterFirstNodeFn)
+}
+
+// randomlyPickNodes picks the rest nodes by the free space counted by the freeSpace function
+func (n *NodeImpl) randomlyPickNodes(numberOfNodes int, freeSpace func(Node) int64, filterFirstNodeFn func(dn Node) error) (firstNode Node, restNodes []Node, err error) {
 	candidates := make([]Node, 0, len(n.children))
 	var errs []string
 	n.RLock()
@@ -84,7 +90,7 @@ func (n *NodeImpl) RandomlyPickNodes(numberOfNodes int, filterFirstNodeFn func(d
 		if node.Id() == firstNode.Id() {
 			continue
 		}
-		if node.FreeSpace() <= 0 {
+		if freeSpace(node) <= 0 {
 			continue
 		}
 		glog.V(2).Infoln("select rest node candidate:", node.Id())
@@ -155,10 +161,15 @@ func (n *NodeImpl) GetValue() interface{} {
 	return n.value
 }
 func (n *NodeImpl) ReserveOneVolume(r int64) (assignedNode *DataNode, err error) {
+	return n.reserveOneVolume(r, Node.FreeSpace)
+}
+
+// reserveOneVolume picks the r-th free slot counted by the freeSpace function
+func (n *NodeImpl) reserveOneVolume(r int64, freeSpaceFn func(Node) int64) (assignedNode *DataNode, err error) {
 	n.RLock()
 	defer n.RUnlock()
 	for _, node := range n.children {
-		freeSpace := node.FreeSpace()
+		freeSpace := freeSpaceFn(node)
 		// fmt.Println("r =", r, ", node =", node, ", freeSpace =", freeSpace)
 		if freeSpace <= 0 {
 			continue
@@ -166,11 +177,11 @@ func (n *NodeImpl) ReserveOneVolume(r int64) (assignedNode *DataNode, err error)
 		if r >= freeSpace {
 			r -= freeSpace
 		} else {
-			if node.IsDataNode() && node.FreeSpace() > 0 {
+			if node.IsDataNode() && freeSpace > 0 {
 				// fmt.Println("vid =", vid, " assigned to node =", node, ", freeSpace =", node.FreeSpace())
 				return node.(*DataNode), nil
 			}
-			assignedNode, err = node.ReserveOneVolume(r)
+			assignedNode, err = node.reserveOneVolume(r, freeSpaceFn)
 			if err == nil {
 				return
 			}
@@ -262,7 +273,7 @@ func (n *NodeImpl) CollectDeadNodeAndFullVolumes(freshThreshHold int64, volumeSi
 		for _, c := range n.Children() {
 			dn := c.(*DataNode) //can not cast n to DataNode
 			for _, v := range dn.GetVolumes() {
-				if uint64(v.Size) >= volumeSizeLimit {
+				if uint64(v.Size) >= n.GetTopology().CollectionVolumeSizeLimit(v.Collection, volumeSizeLimit) {
 					//fmt.Println("volume",v.Id,"size",v.Size,">",volumeSizeLimit)
 					n.GetTopology().chanFullVolumes <- v
 				}
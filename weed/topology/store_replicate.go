@@ -18,9 +18,12 @@ import (
 	"github.com/chrislusf/seaweedfs/weed/util"
 )
 
+// ReplicatedWrite writes the needle locally and to the other replicas.
+// With the fsync flag every copy is synced to the disk before acknowledging,
+// and with the quorum ack the write returns once a majority of the copies have written it.
 func ReplicatedWrite(masterNode string, s *storage.Store,
 	volumeId needle.VolumeId, n *needle.Needle,
-	r *http.Request) (size uint32, isUnchanged bool, err error) {
+	r *http.Request, writeFlags needle.WriteFlags) (size uint32, isUnchanged bool, err error) {
 
 	//check JWT
 	jwt := security.GetJwt(r)
@@ -30,6 +33,13 @@ func ReplicatedWrite(masterNode string, s *storage.Store,
 		err = fmt.Errorf("failed to write to local disk: %v", err)
 		return
 	}
+	if writeFlags.Fsync {
+		if err = s.SyncVolume(volumeId); err != nil {
+			size = 0
+			err = fmt.Errorf("failed to sync to local disk: %v", err)
+			return
+		}
+	}
 
 	needToReplicate := !s.HasVolume(volumeId)
 	needToReplicate = needToReplicate || s.GetVolume(volumeId).NeedToReplicate()
@@ -39,7 +49,11 @@ func ReplicatedWrite(masterNode string, s *storage.Store,
 	if needToReplicate { //send to other replica locations
 		if r.FormValue("type") != "replicate" {
 
-			if err = distributedOperation(masterNode, s, volumeId, func(location operation.Location) error {
+			requiredAcks := 0
+			if v := s.GetVolume(volumeId); v != nil {
+				requiredAcks = writeFlags.RequiredAcks(v.ReplicaPlacement.GetCopyCount())
+			}
+			if err = distributedOperationAcked(masterNode, s, volumeId, requiredAcks, func(location operation.Location) error {
 				u := url.URL{
 					Scheme: "http",
 					Host:   location.Url,
@@ -55,6 +69,9 @@ func ReplicatedWrite(masterNode string, s *storage.Store,
 				if n.IsChunkedManifest() {
 					q.Set("cm", "true")
 				}
+				if writeFlags.Fsync {
+					q.Set("fsync", "true")
+				}
 				u.RawQuery = q.Encode()
 
 				pairMap := make(map[string]string)
@@ -132,10 +149,16 @@ type RemoteResult struct {
 }
 
 func distributedOperation(masterNode string, store *storage.Store, volumeId needle.VolumeId, op func(location operation.Location) error) error {
+	return distributedOperationAcked(masterNode, store, volumeId, 0, op)
+}
+
+// distributedOperationAcked returns once the operation succeeds on the requiredAcks copies, including the local one, or on all the copies if 0.
+// The operations on the other replicas continue in the background.
+func distributedOperationAcked(masterNode string, store *storage.Store, volumeId needle.VolumeId, requiredAcks int, op func(location operation.Location) error) error {
 	if lookupResult, lookupErr := operation.Lookup(masterNode, volumeId.String()); lookupErr == nil {
 		length := 0
 		selfUrl := (store.Ip + ":" + strconv.Itoa(store.Port))
-		results := make(chan RemoteResult)
+		results := make(chan RemoteResult, len(lookupResult.Locations))
 		for _, location := range lookupResult.Locations {
 			if location.Url != selfUrl {
 				length++
@@ -144,6 +167,12 @@ func distributedOperation(masterNode string, store *storage.Store, volumeId need
 				}(location, results)
 			}
 		}
+		if requiredAcks > 0 {
+			if length+1 < requiredAcks {
+				return fmt.Errorf("replicating opetations [%d] is less than the required acks [%d]", length+1, requiredAcks)
+			}
+			return waitForAcks(volumeId, results, length, requiredAcks)
+		}
 		ret := DistributedOperationResult(make(map[string]error))
 		for i := 0; i < length; i++ {
 			result := <-results
@@ -160,3 +189,30 @@ func distributedOperation(masterNode string, store *storage.Store, volumeId need
 		return fmt.Errorf("Failed to lookup for %d: %v", volumeId, lookupErr)
 	}
 }
+
+// waitForAcks counts the local copy as acknowledged, and logs the failures of the replicas after the required acks
+func waitForAcks(volumeId needle.VolumeId, results chan RemoteResult, length, requiredAcks int) error {
+	acks := 1
+	ret := DistributedOperationResult(make(map[string]error))
+	for i := 0; i < length; i++ {
+		if acks >= requiredAcks {
+			go func(remaining int) {
+				for j := 0; j < remaining; j++ {
+					if result := <-results; result.Error != nil {
+						glog.V(0).Infof("volume %d replica %s after %d acks: %v", volumeId, result.Host, requiredAcks, result.Error)
+					}
+				}
+			}(length - i)
+			return nil
+		}
+		result := <-results
+		ret[result.Host] = result.Error
+		if result.Error == nil {
+			acks++
+		}
+	}
+	if acks >= requiredAcks {
+		return nil
+	}
+	return ret.Error()
+}
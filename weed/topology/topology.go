@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"math/rand"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/chrislusf/raft"
 	"github.com/chrislusf/seaweedfs/weed/glog"
@@ -22,6 +24,8 @@ type Topology struct {
 	ecShardMap     map[needle.VolumeId]*EcShardLocations
 	ecShardMapLock sync.RWMutex
 
+	ecLocationChanges ecLocationChangeLog
+
 	pulse int64
 
 	volumeSizeLimit uint64
@@ -33,6 +37,20 @@ type Topology struct {
 	Configuration *Configuration
 
 	RaftServer raft.Server
+
+	flapping *flappingDetector
+
+	utilization *utilizationHistory
+
+	// version changes whenever the volume servers or the volume locations change.
+	// It starts from the time, so it also changes after the master restarts or the leader changes.
+	version uint64
+
+	growthLock       sync.RWMutex
+	growthStrategies map[string]VolumeGrowthStrategy
+
+	collectionConfigLock sync.RWMutex
+	collectionConfigs    map[string]CollectionConfig
 }
 
 func NewTopology(id string, seq sequence.Sequencer, volumeSizeLimit uint64, pulse int) *Topology {
@@ -52,9 +70,27 @@ func NewTopology(id string, seq sequence.Sequencer, volumeSizeLimit uint64, puls
 
 	t.Configuration = &Configuration{}
 
+	t.flapping = newFlappingDetector()
+
+	t.utilization = newUtilizationHistory()
+
+	t.version = uint64(time.Now().UnixNano())
+
+	t.growthStrategies = make(map[string]VolumeGrowthStrategy)
+	t.collectionConfigs = make(map[string]CollectionConfig)
+
 	return t
 }
 
+// Version changes whenever the volume servers or the volume locations change, for the clients to refresh the cached locations
+func (t *Topology) Version() uint64 {
+	return atomic.LoadUint64(&t.version)
+}
+
+func (t *Topology) increaseVersion() {
+	atomic.AddUint64(&t.version, 1)
+}
+
 func (t *Topology) IsLeader() bool {
 	if t.RaftServer != nil {
 		return t.RaftServer.State() == raft.Leader
@@ -124,13 +160,16 @@ func (t *Topology) PickForWrite(count uint64, option *VolumeGrowOption) (string,
 	if datanodes.Length() == 0 {
 		return "", 0, nil, fmt.Errorf("no writable volumes available for for collectio:%s replication:%s ttl:%s", option.Collection, option.ReplicaPlacement.String(), option.Ttl.String())
 	}
-	fileId, count := t.Sequence.NextFileId(count)
-	return needle.NewFileId(*vid, fileId, rand.Uint32()).String(), count, datanodes.Head(), nil
+	fileId, count, err := t.Sequence.NextFileId(count)
+	if err != nil {
+		return "", 0, nil, fmt.Errorf("failed to issue file ids: %v", err)
+	}
+	return needle.NewFileId(*vid, fileId, rand.Uint32()).String(), count, datanodes.MostDurable(*vid), nil
 }
 
 func (t *Topology) GetVolumeLayout(collectionName string, rp *storage.ReplicaPlacement, ttl *needle.TTL) *VolumeLayout {
 	return t.collectionMap.Get(collectionName, func() interface{} {
-		return NewCollection(collectionName, t.volumeSizeLimit)
+		return NewCollection(collectionName, t.CollectionVolumeSizeLimit(collectionName, t.volumeSizeLimit))
 	}).(*Collection).GetOrCreateVolumeLayout(rp, ttl)
 }
 
@@ -140,6 +179,12 @@ func (t *Topology) ListCollections(includeNormalVolumes, includeEcVolumes bool)
 	for _, c := range t.collectionMap.Items() {
 		mapOfCollections[c.(*Collection).Name] = true
 	}
+	if includeNormalVolumes {
+		// the collections created without any volume yet
+		for collection := range t.CollectionConfigs() {
+			mapOfCollections[collection] = true
+		}
+	}
 
 	if includeEcVolumes {
 		t.ecShardMapLock.RLock()
@@ -169,6 +214,7 @@ func (t *Topology) DeleteCollection(collectionName string) {
 
 func (t *Topology) RegisterVolumeLayout(v storage.VolumeInfo, dn *DataNode) {
 	t.GetVolumeLayout(v.Collection, v.ReplicaPlacement, v.Ttl).RegisterVolume(&v, dn)
+	t.increaseVersion()
 }
 func (t *Topology) UnRegisterVolumeLayout(v storage.VolumeInfo, dn *DataNode) {
 	glog.Infof("removing volume info:%+v", v)
@@ -177,6 +223,7 @@ func (t *Topology) UnRegisterVolumeLayout(v storage.VolumeInfo, dn *DataNode) {
 	if volumeLayout.isEmpty() {
 		t.DeleteCollection(v.Collection)
 	}
+	t.increaseVersion()
 }
 
 func (t *Topology) GetOrCreateDataCenter(dcName string) *DataCenter {
@@ -212,6 +259,22 @@ func (t *Topology) SyncDataNodeRegistration(volumes []*master_pb.VolumeInformati
 	return
 }
 
+// DeltaSyncDataNodeRegistration updates the volumes changed since the last heartbeat, leaving the other volumes as they are
+func (t *Topology) DeltaSyncDataNodeRegistration(updatedVolumes []*master_pb.VolumeInformationMessage, dn *DataNode) (newVolumes []storage.VolumeInfo) {
+	for _, v := range updatedVolumes {
+		vi, err := storage.NewVolumeInfo(v)
+		if err != nil {
+			glog.V(0).Infof("Fail to convert updated volume information: %v", err)
+			continue
+		}
+		if dn.AddOrUpdateVolume(vi) {
+			newVolumes = append(newVolumes, vi)
+			t.RegisterVolumeLayout(vi, dn)
+		}
+	}
+	return
+}
+
 func (t *Topology) IncrementalSyncDataNodeRegistration(newVolumes, deletedVolumes []*master_pb.VolumeShortInformationMessage, dn *DataNode) {
 	var newVis, oldVis []storage.VolumeInfo
 	for _, v := range newVolumes {
@@ -16,11 +16,13 @@ func (t *Topology) SyncDataNodeEcShards(shardInfos []*master_pb.VolumeEcShardInf
 	// convert into in memory struct storage.VolumeInfo
 	var shards []*erasure_coding.EcVolumeInfo
 	for _, shardInfo := range shardInfos {
-		shards = append(shards,
-			erasure_coding.NewEcVolumeInfo(
-				shardInfo.Collection,
-				needle.VolumeId(shardInfo.Id),
-				erasure_coding.ShardBits(shardInfo.EcIndexBits)))
+		ecVolumeInfo := erasure_coding.NewEcVolumeInfo(
+			shardInfo.Collection,
+			needle.VolumeId(shardInfo.Id),
+			erasure_coding.ShardBits(shardInfo.EcIndexBits))
+		ecVolumeInfo.DeleteCount = shardInfo.DeleteCount
+		ecVolumeInfo.ReclaimableByteCount = shardInfo.ReclaimableByteCount
+		shards = append(shards, ecVolumeInfo)
 	}
 	// find out the delta volumes
 	newShards, deletedShards = dn.UpdateEcShards(shards)
@@ -94,6 +96,15 @@ func (loc *EcShardLocations) DeleteShard(shardId erasure_coding.ShardId, dn *Dat
 	return true
 }
 
+func (loc *EcShardLocations) ShardCount() (count int) {
+	for _, dataNodes := range loc.Locations {
+		if len(dataNodes) > 0 {
+			count++
+		}
+	}
+	return
+}
+
 func (t *Topology) RegisterEcShards(ecShardInfos *erasure_coding.EcVolumeInfo, dn *DataNode) {
 
 	t.ecShardMapLock.Lock()
@@ -107,6 +118,8 @@ func (t *Topology) RegisterEcShards(ecShardInfos *erasure_coding.EcVolumeInfo, d
 	for _, shardId := range ecShardInfos.ShardIds() {
 		locations.AddShard(shardId, dn)
 	}
+	t.ecLocationChanges.record(ecShardInfos.VolumeId)
+	t.increaseVersion()
 }
 
 func (t *Topology) UnRegisterEcShards(ecShardInfos *erasure_coding.EcVolumeInfo, dn *DataNode) {
@@ -121,6 +134,8 @@ func (t *Topology) UnRegisterEcShards(ecShardInfos *erasure_coding.EcVolumeInfo,
 	for _, shardId := range ecShardInfos.ShardIds() {
 		locations.DeleteShard(shardId, dn)
 	}
+	t.ecLocationChanges.record(ecShardInfos.VolumeId)
+	t.increaseVersion()
 }
 
 func (t *Topology) LookupEcShards(vid needle.VolumeId) (locations *EcShardLocations, found bool) {
@@ -171,3 +186,27 @@ func (t *Topology) DeleteEcCollection(collection string) {
 
 	return
 }
+
+type EcVolumeShardCount struct {
+	Collection string
+	ShardCount int
+}
+
+// ListEcVolumesWithMissingShards finds ec volumes that have lost some, but not all, of their shards.
+func (t *Topology) ListEcVolumesWithMissingShards() map[needle.VolumeId]EcVolumeShardCount {
+	t.ecShardMapLock.RLock()
+	defer t.ecShardMapLock.RUnlock()
+
+	missing := make(map[needle.VolumeId]EcVolumeShardCount)
+	for vid, ecVolumeLocation := range t.ecShardMap {
+		shardCount := ecVolumeLocation.ShardCount()
+		if shardCount > 0 && shardCount < erasure_coding.TotalShardsCount {
+			missing[vid] = EcVolumeShardCount{
+				Collection: ecVolumeLocation.Collection,
+				ShardCount: shardCount,
+			}
+		}
+	}
+
+	return missing
+}
@@ -0,0 +1,87 @@
+package topology
+
+import (
+	"sync"
+
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+)
+
+// only the recent ec shard location changes are kept,
+// the volume servers missing older changes invalidate all of their cached shard locations
+const ecLocationChangeLogSize = 4096
+
+// ecLocationChangeLog records the ec volumes whose shard locations changed,
+// so that the heartbeat responses tell each volume server which cached shard locations to invalidate
+type ecLocationChangeLog struct {
+	sync.Mutex
+	version uint64
+	changes []ecLocationChange
+}
+
+type ecLocationChange struct {
+	version uint64
+	vid     needle.VolumeId
+}
+
+func (l *ecLocationChangeLog) record(vid needle.VolumeId) {
+	l.Lock()
+	defer l.Unlock()
+
+	l.version++
+	if len(l.changes) >= ecLocationChangeLogSize {
+		l.changes = append(l.changes[:0], l.changes[len(l.changes)-ecLocationChangeLogSize/2:]...)
+	}
+	l.changes = append(l.changes, ecLocationChange{version: l.version, vid: vid})
+}
+
+func (l *ecLocationChangeLog) latest() uint64 {
+	l.Lock()
+	defer l.Unlock()
+	return l.version
+}
+
+// since returns the distinct volume ids changed after the version, and the latest version.
+// It is incomplete if some changes after the version are no longer kept.
+func (l *ecLocationChangeLog) since(version uint64) (vids []needle.VolumeId, latest uint64, complete bool) {
+	l.Lock()
+	defer l.Unlock()
+
+	latest = l.version
+	if version >= latest {
+		return nil, latest, true
+	}
+	if len(l.changes) == 0 || l.changes[0].version > version+1 {
+		return nil, latest, false
+	}
+	seen := make(map[needle.VolumeId]bool)
+	for i := len(l.changes) - 1; i >= 0 && l.changes[i].version > version; i-- {
+		vid := l.changes[i].vid
+		if !seen[vid] {
+			seen[vid] = true
+			vids = append(vids, vid)
+		}
+	}
+	return vids, latest, true
+}
+
+// EcLocationVersion returns the version of the latest ec shard location change
+func (t *Topology) EcLocationVersion() uint64 {
+	return t.ecLocationChanges.latest()
+}
+
+// EcLocationChangesSince returns the ec volumes with the shards on the data node whose shard locations
+// changed after the version, and the latest version. If allChanged, the changes are no longer kept,
+// and all the cached shard locations should be invalidated.
+func (t *Topology) EcLocationChangesSince(version uint64, dn *DataNode) (vids []uint32, latest uint64, allChanged bool) {
+	changed, latest, complete := t.ecLocationChanges.since(version)
+	if !complete {
+		return nil, latest, true
+	}
+	for _, vid := range changed {
+		// only the volume servers with the ec shards cache the shard locations
+		if dn.HasEcShardsById(vid) {
+			vids = append(vids, uint32(vid))
+		}
+	}
+	return vids, latest, false
+}
@@ -0,0 +1,39 @@
+package topology
+
+import (
+	"testing"
+
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+)
+
+func TestEcLocationChangeLog(t *testing.T) {
+	var l ecLocationChangeLog
+
+	if vids, latest, complete := l.since(0); len(vids) != 0 || latest != 0 || !complete {
+		t.Errorf("empty log: %v %d %v", vids, latest, complete)
+	}
+
+	l.record(1)
+	l.record(2)
+	l.record(1)
+	vids, latest, complete := l.since(0)
+	if len(vids) != 2 || latest != 3 || !complete {
+		t.Errorf("expected volumes 1 and 2 at version 3, got %v %d %v", vids, latest, complete)
+	}
+	if vids, _, _ = l.since(2); len(vids) != 1 || vids[0] != 1 {
+		t.Errorf("expected volume 1 since version 2, got %v", vids)
+	}
+	if vids, _, complete = l.since(3); len(vids) != 0 || !complete {
+		t.Errorf("expected no changes since the latest version, got %v %v", vids, complete)
+	}
+
+	for i := 0; i < ecLocationChangeLogSize; i++ {
+		l.record(needle.VolumeId(100 + i))
+	}
+	if _, latest, complete = l.since(3); complete || latest != uint64(3+ecLocationChangeLogSize) {
+		t.Errorf("expected the old changes dropped, got %d %v", latest, complete)
+	}
+	if vids, _, complete = l.since(latest - 1); len(vids) != 1 || !complete {
+		t.Errorf("expected the last change, got %v %v", vids, complete)
+	}
+}
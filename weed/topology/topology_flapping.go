@@ -0,0 +1,204 @@
+package topology
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/stats"
+)
+
+// flappingDetector counts the disconnections of each volume server in a sliding window.
+// A volume server disconnected too many times is quarantined until an operator clears it,
+// so its volumes do not keep joining and leaving the writables and triggering re-replications.
+// The quarantine is kept by the url, since the data node is removed from the topology when disconnected.
+type flappingDetector struct {
+	sync.Mutex
+	window      time.Duration
+	threshold   int // 0 disables the detection
+	leaves      map[string][]time.Time
+	quarantined map[string]*QuarantinedDataNode
+}
+
+type QuarantinedDataNode struct {
+	Url       string
+	Since     time.Time
+	Leaves    int
+	Window    time.Duration
+	Connected bool
+}
+
+func newFlappingDetector() *flappingDetector {
+	return &flappingDetector{
+		leaves:      make(map[string][]time.Time),
+		quarantined: make(map[string]*QuarantinedDataNode),
+	}
+}
+
+// ConfigureFlappingDetection quarantines the volume servers disconnected threshold times within the window.
+// A threshold of 0 disables the detection.
+func (t *Topology) ConfigureFlappingDetection(window time.Duration, threshold int) {
+	t.flapping.Lock()
+	defer t.flapping.Unlock()
+	t.flapping.window = window
+	t.flapping.threshold = threshold
+}
+
+// RecordDataNodeLeft counts the disconnection of the volume server, and quarantines it if flapping.
+// The quarantine is replicated by raft, to survive the master restarts and the leader changes.
+func (t *Topology) RecordDataNodeLeft(dn *DataNode, now time.Time) (quarantined bool) {
+	url := dn.Url()
+	q := t.flapping.recordLeave(url, now)
+	if q == nil {
+		return false
+	}
+	if err := t.commitQuarantine(url, q); err != nil {
+		glog.Errorf("quarantine volume server %s: %v", url, err)
+		return false
+	}
+	glog.Warningf("quarantine volume server %s: disconnected %d times within %v", url, q.Leaves, q.Window)
+	stats.MasterVolumeServerQuarantineCounter.WithLabelValues("quarantine").Inc()
+	return true
+}
+
+// recordLeave returns the quarantine if the volume server is disconnected too many times
+func (f *flappingDetector) recordLeave(url string, now time.Time) *QuarantinedDataNode {
+	f.Lock()
+	defer f.Unlock()
+
+	if f.threshold <= 0 || f.window <= 0 {
+		return nil
+	}
+	if _, found := f.quarantined[url]; found {
+		return nil
+	}
+
+	leaves := append(f.leaves[url], now)
+	for len(leaves) > 0 && now.Sub(leaves[0]) > f.window {
+		leaves = leaves[1:]
+	}
+	if len(leaves) < f.threshold {
+		f.leaves[url] = leaves
+		return nil
+	}
+
+	delete(f.leaves, url)
+	return &QuarantinedDataNode{
+		Url:    url,
+		Since:  now,
+		Leaves: len(leaves),
+		Window: f.window,
+	}
+}
+
+// commitQuarantine replicates the quarantine of the volume server, or the clearing if nil
+func (t *Topology) commitQuarantine(url string, q *QuarantinedDataNode) error {
+	if t.RaftServer == nil {
+		t.SetQuarantine(url, q)
+		return nil
+	}
+	_, err := t.RaftServer.Do(&QuarantineCommand{Url: url, Quarantine: q})
+	return err
+}
+
+// SetQuarantine quarantines the volume server, or clears it if nil, as committed by raft.
+// The volumes of the cleared volume server, if connected, are registered again to become writable.
+func (t *Topology) SetQuarantine(url string, q *QuarantinedDataNode) {
+	f := t.flapping
+	f.Lock()
+	delete(f.leaves, url)
+	if q != nil {
+		f.quarantined[url] = q
+	} else {
+		delete(f.quarantined, url)
+	}
+	f.Unlock()
+
+	if q != nil {
+		return
+	}
+	if dn := t.findDataNode(url); dn != nil {
+		dn.SetQuarantined(false)
+		for _, v := range dn.GetVolumes() {
+			t.RegisterVolumeLayout(v, dn)
+		}
+	}
+}
+
+// IsQuarantined tells whether the volume server should be kept out of the writes.
+func (t *Topology) IsQuarantined(url string) bool {
+	t.flapping.Lock()
+	defer t.flapping.Unlock()
+	_, found := t.flapping.quarantined[url]
+	return found
+}
+
+// ListQuarantined returns the quarantined volume servers, ordered by the quarantine time.
+func (t *Topology) ListQuarantined() (list []QuarantinedDataNode) {
+	t.flapping.Lock()
+	for _, q := range t.flapping.quarantined {
+		list = append(list, *q)
+	}
+	t.flapping.Unlock()
+
+	for i := range list {
+		list[i].Connected = t.findDataNode(list[i].Url) != nil
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Since.Before(list[j].Since)
+	})
+	return
+}
+
+// ClearQuarantine lets the volume server take the writes again, with its disconnections forgotten.
+func (t *Topology) ClearQuarantine(url string) (found bool, err error) {
+	if !t.IsQuarantined(url) {
+		return false, nil
+	}
+	if err = t.commitQuarantine(url, nil); err != nil {
+		return true, err
+	}
+	glog.V(0).Infof("volume server %s is cleared from the quarantine", url)
+	stats.MasterVolumeServerQuarantineCounter.WithLabelValues("clear").Inc()
+	return true, nil
+}
+
+// quarantinedDataNodes returns a copy of the quarantine, to save in the master state
+func (t *Topology) quarantinedDataNodes() map[string]QuarantinedDataNode {
+	t.flapping.Lock()
+	defer t.flapping.Unlock()
+	if len(t.flapping.quarantined) == 0 {
+		return nil
+	}
+	quarantined := make(map[string]QuarantinedDataNode)
+	for url, q := range t.flapping.quarantined {
+		quarantined[url] = *q
+	}
+	return quarantined
+}
+
+// setQuarantinedDataNodes replaces the quarantine, when recovered from the master state
+func (t *Topology) setQuarantinedDataNodes(quarantined map[string]QuarantinedDataNode) {
+	t.flapping.Lock()
+	defer t.flapping.Unlock()
+	t.flapping.quarantined = make(map[string]*QuarantinedDataNode)
+	for url, q := range quarantined {
+		q := q
+		q.Connected = false
+		t.flapping.quarantined[url] = &q
+	}
+}
+
+func (t *Topology) findDataNode(url string) *DataNode {
+	for _, c := range t.Children() {
+		for _, r := range c.Children() {
+			for _, n := range r.Children() {
+				if dn := n.(*DataNode); dn.Url() == url {
+					return dn
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package topology
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/sequence"
+)
+
+func TestFlappingVolumeServerQuarantine(t *testing.T) {
+	topo := NewTopology("weedfs", sequence.NewMemorySequencer(), 32*1024, 5)
+	topo.ConfigureFlappingDetection(time.Minute, 3)
+
+	dn := NewDataNode("127.0.0.1:8080")
+	dn.Ip, dn.Port = "127.0.0.1", 8080
+
+	now := time.Now()
+	// the disconnections out of the window are forgotten
+	for _, ago := range []time.Duration{5 * time.Minute, 30 * time.Second, 20 * time.Second} {
+		if topo.RecordDataNodeLeft(dn, now.Add(-ago)) {
+			t.Fatalf("quarantined too early")
+		}
+	}
+	if !topo.RecordDataNodeLeft(dn, now) {
+		t.Fatalf("flapping volume server should be quarantined")
+	}
+	if !topo.IsQuarantined(dn.Url()) || len(topo.ListQuarantined()) != 1 {
+		t.Fatalf("quarantined volume server is not listed")
+	}
+
+	// the quarantine survives the master restarts
+	state, err := topo.SaveState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	recovered := NewTopology("weedfs", sequence.NewMemorySequencer(), 32*1024, 5)
+	if err = recovered.RecoverState(state); err != nil {
+		t.Fatal(err)
+	}
+	if !recovered.IsQuarantined(dn.Url()) {
+		t.Errorf("quarantine is not recovered")
+	}
+
+	if found, err := topo.ClearQuarantine(dn.Url()); !found || err != nil || topo.IsQuarantined(dn.Url()) {
+		t.Fatalf("quarantine should be cleared: %v", err)
+	}
+	if topo.RecordDataNodeLeft(dn, now) {
+		t.Fatalf("disconnections before clearing should be forgotten")
+	}
+}
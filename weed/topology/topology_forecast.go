@@ -0,0 +1,245 @@
+package topology
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+	"github.com/chrislusf/seaweedfs/weed/stats"
+	"github.com/chrislusf/seaweedfs/weed/storage/erasure_coding"
+)
+
+const (
+	ForecastLinear = "linear"
+	ForecastEwma   = "ewma"
+
+	// the weight of the latest growth rate in the exponentially weighted moving average
+	forecastEwmaAlpha = 0.1
+	// the forecasts are only made with the samples of at least this long
+	forecastMinSpan = time.Hour
+)
+
+// utilizationHistory samples the used bytes of each volume server, to forecast when it becomes full.
+// The samples are kept by the url, so a volume server reconnecting keeps its history.
+// The history is only in the memory of the leader, and starts over on a new leader.
+type utilizationHistory struct {
+	sync.Mutex
+	interval  time.Duration
+	retention time.Duration
+	samples   map[string][]utilizationSample
+	// the forecasts of the latest samples, for the topology listings
+	forecasts map[string]UtilizationForecast
+}
+
+type utilizationSample struct {
+	time      time.Time
+	usedBytes uint64
+}
+
+type UtilizationForecast struct {
+	Url           string
+	DataCenter    string
+	Rack          string
+	UsedBytes     uint64
+	CapacityBytes uint64
+	Samples       int
+	// the growth of the used bytes per day, and the days until the capacity is used up if growing
+	GrowthBytesPerDay float64
+	DaysToFull        float64
+}
+
+func newUtilizationHistory() *utilizationHistory {
+	return &utilizationHistory{
+		interval:  10 * time.Minute,
+		retention: 7 * 24 * time.Hour,
+		samples:   make(map[string][]utilizationSample),
+		forecasts: make(map[string]UtilizationForecast),
+	}
+}
+
+// ConfigureUtilizationHistory samples the utilization of the volume servers on the interval,
+// and forecasts by the samples within the retention.
+func (t *Topology) ConfigureUtilizationHistory(interval, retention time.Duration) {
+	t.utilization.Lock()
+	defer t.utilization.Unlock()
+	if interval > 0 {
+		t.utilization.interval = interval
+	}
+	if retention > 0 {
+		t.utilization.retention = retention
+	}
+}
+
+func (t *Topology) startSamplingUtilization() {
+	t.utilization.Lock()
+	interval := t.utilization.interval
+	t.utilization.Unlock()
+	for range time.Tick(interval) {
+		if t.IsLeader() {
+			t.SampleUtilization(time.Now())
+		}
+	}
+}
+
+// SampleUtilization records the used bytes of each volume server, and updates the forecasts.
+func (t *Topology) SampleUtilization(now time.Time) {
+	h := t.utilization
+	nodes := t.listDataNodes()
+
+	h.Lock()
+	defer h.Unlock()
+
+	for _, dn := range nodes {
+		url := dn.Url()
+		samples := append(h.samples[url], utilizationSample{time: now, usedBytes: t.dataNodeUsedBytes(dn)})
+		for len(samples) > 0 && now.Sub(samples[0].time) > h.retention {
+			samples = samples[1:]
+		}
+		h.samples[url] = samples
+	}
+	// forget the volume servers gone for longer than the retention
+	for url, samples := range h.samples {
+		if len(samples) == 0 || now.Sub(samples[len(samples)-1].time) > h.retention {
+			delete(h.samples, url)
+		}
+	}
+
+	forecasts := make(map[string]UtilizationForecast)
+	for _, dn := range nodes {
+		forecast := t.forecastDataNode(dn, h.samples[dn.Url()], ForecastLinear)
+		forecasts[dn.Url()] = forecast
+		stats.MasterVolumeServerDaysToFullGauge.WithLabelValues(forecast.Url).Set(forecast.DaysToFull)
+		stats.MasterVolumeServerGrowthGauge.WithLabelValues(forecast.Url).Set(forecast.GrowthBytesPerDay)
+	}
+	// the gauges of the disconnected volume servers are removed
+	for url := range h.forecasts {
+		if _, found := forecasts[url]; !found {
+			stats.MasterVolumeServerDaysToFullGauge.DeleteLabelValues(url)
+			stats.MasterVolumeServerGrowthGauge.DeleteLabelValues(url)
+		}
+	}
+	h.forecasts = forecasts
+	glog.V(3).Infof("sampled the utilization of %d volume servers", len(nodes))
+}
+
+// ForecastUtilization projects the growth of the connected volume servers by the linear regression
+// or the exponentially weighted moving average of the sampled used bytes.
+func (t *Topology) ForecastUtilization(method string) (forecasts []UtilizationForecast) {
+	nodes := t.listDataNodes()
+
+	t.utilization.Lock()
+	for _, dn := range nodes {
+		forecasts = append(forecasts, t.forecastDataNode(dn, t.utilization.samples[dn.Url()], method))
+	}
+	t.utilization.Unlock()
+
+	sort.Slice(forecasts, func(i, j int) bool {
+		return forecasts[i].Url < forecasts[j].Url
+	})
+	return
+}
+
+// LatestUtilizationForecast returns the forecast made at the latest sampling
+func (t *Topology) LatestUtilizationForecast(url string) (forecast UtilizationForecast, found bool) {
+	t.utilization.Lock()
+	defer t.utilization.Unlock()
+	forecast, found = t.utilization.forecasts[url]
+	return
+}
+
+func (t *Topology) forecastDataNode(dn *DataNode, samples []utilizationSample, method string) UtilizationForecast {
+	forecast := UtilizationForecast{
+		Url:           dn.Url(),
+		UsedBytes:     t.dataNodeUsedBytes(dn),
+		CapacityBytes: uint64(dn.GetMaxVolumeCount()) * t.volumeSizeLimit,
+		Samples:       len(samples),
+	}
+	if rack := dn.Parent(); rack != nil {
+		forecast.Rack = string(rack.Id())
+		if dc := rack.Parent(); dc != nil {
+			forecast.DataCenter = string(dc.Id())
+		}
+	}
+	if len(samples) < 2 || samples[len(samples)-1].time.Sub(samples[0].time) < forecastMinSpan {
+		return forecast
+	}
+
+	var bytesPerSecond float64
+	if method == ForecastEwma {
+		bytesPerSecond = ewmaGrowthRate(samples, forecastEwmaAlpha)
+	} else {
+		bytesPerSecond = linearGrowthRate(samples)
+	}
+	forecast.GrowthBytesPerDay = bytesPerSecond * 24 * 60 * 60
+	forecast.DaysToFull = daysToFull(forecast.UsedBytes, forecast.CapacityBytes, forecast.GrowthBytesPerDay)
+	return forecast
+}
+
+// dataNodeUsedBytes adds up the volume sizes, with each ec shard taking its share of a full volume
+func (t *Topology) dataNodeUsedBytes(dn *DataNode) (used uint64) {
+	for _, v := range dn.GetVolumes() {
+		used += v.Size
+	}
+	for _, ecShards := range dn.GetEcShards() {
+		used += uint64(ecShards.ShardBits.ShardIdCount()) * t.volumeSizeLimit / erasure_coding.DataShardsCount
+	}
+	return
+}
+
+func (t *Topology) listDataNodes() (nodes []*DataNode) {
+	for _, dc := range t.Children() {
+		for _, rack := range dc.Children() {
+			for _, n := range rack.Children() {
+				nodes = append(nodes, n.(*DataNode))
+			}
+		}
+	}
+	return
+}
+
+// linearGrowthRate is the slope of the least squares line through the samples, in bytes per second
+func linearGrowthRate(samples []utilizationSample) float64 {
+	n := float64(len(samples))
+	var sumX, sumY, sumXY, sumXX float64
+	for _, s := range samples {
+		x := s.time.Sub(samples[0].time).Seconds()
+		y := float64(s.usedBytes)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denominator
+}
+
+// ewmaGrowthRate averages the growth rates between the samples, weighting the recent ones more
+func ewmaGrowthRate(samples []utilizationSample, alpha float64) float64 {
+	var rate float64
+	initialized := false
+	for i := 1; i < len(samples); i++ {
+		seconds := samples[i].time.Sub(samples[i-1].time).Seconds()
+		if seconds <= 0 {
+			continue
+		}
+		r := (float64(samples[i].usedBytes) - float64(samples[i-1].usedBytes)) / seconds
+		if !initialized {
+			rate, initialized = r, true
+		} else {
+			rate = alpha*r + (1-alpha)*rate
+		}
+	}
+	return rate
+}
+
+// daysToFull is the days until the capacity is used up, or 0 if not growing or already full
+func daysToFull(used, capacity uint64, growthPerDay float64) float64 {
+	if growthPerDay <= 0 || used >= capacity {
+		return 0
+	}
+	return float64(capacity-used) / growthPerDay
+}
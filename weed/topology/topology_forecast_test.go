@@ -0,0 +1,70 @@
+package topology
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/sequence"
+	"github.com/chrislusf/seaweedfs/weed/storage"
+)
+
+func TestUtilizationGrowthRate(t *testing.T) {
+	start := time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC)
+	var samples []utilizationSample
+	for i := 0; i <= 24; i++ {
+		// 1000 bytes more every hour, with a burst of 10000 bytes in the last hour
+		used := uint64(i * 1000)
+		if i == 24 {
+			used += 10000
+		}
+		samples = append(samples, utilizationSample{time: start.Add(time.Duration(i) * time.Hour), usedBytes: used})
+	}
+
+	linear := linearGrowthRate(samples) * 3600
+	if linear < 1000 || linear > 1200 {
+		t.Errorf("linear growth %.1f bytes per hour", linear)
+	}
+	ewma := ewmaGrowthRate(samples, 0.5) * 3600
+	if math.Abs(ewma-6000) > 0.001 {
+		t.Errorf("ewma growth %.1f bytes per hour", ewma)
+	}
+
+	if days := daysToFull(1000, 25000, 1000*24); days != 1 {
+		t.Errorf("days to full %.1f", days)
+	}
+	if days := daysToFull(1000, 25000, -10); days != 0 {
+		t.Errorf("days to full of shrinking %.1f", days)
+	}
+	if days := daysToFull(30000, 25000, 10); days != 0 {
+		t.Errorf("days to full of full %.1f", days)
+	}
+}
+
+func TestDataNodeDaysToFull(t *testing.T) {
+	topo := NewTopology("weedfs", sequence.NewMemorySequencer(), 32*1024, 5)
+	rack := topo.GetOrCreateDataCenter("dc1").GetOrCreateRack("rack1")
+	dn := rack.GetOrCreateDataNode("127.0.0.1", 34534, "127.0.0.1", 2)
+
+	start := time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC)
+	dn.AddOrUpdateVolume(storage.VolumeInfo{Id: 1, Size: 1024})
+	topo.SampleUtilization(start)
+	dn.AddOrUpdateVolume(storage.VolumeInfo{Id: 1, Size: 2048})
+	topo.SampleUtilization(start.Add(2 * time.Hour))
+
+	if days, found := dn.ToMap().(map[string]interface{})["DaysToFull"]; !found || days.(int) <= 0 {
+		t.Errorf("days to full %v", days)
+	}
+	if info := dn.ToDataNodeInfo(); info.DaysToFull <= 0 || info.GrowthBytesPerDay != 1024*12 {
+		t.Errorf("forecast %.1f days, %.1f bytes per day", info.DaysToFull, info.GrowthBytesPerDay)
+	}
+
+	// the data node removed from the topology is still listed without a forecast
+	rack.UnlinkChildNode(dn.Id())
+	if _, found := dn.ToMap().(map[string]interface{})["DaysToFull"]; found {
+		t.Errorf("unlinked data node with a forecast")
+	}
+	if info := NewDataNode("unlinked").ToDataNodeInfo(); info.DaysToFull != 0 {
+		t.Errorf("unlinked data node forecast %.1f days", info.DaysToFull)
+	}
+}
@@ -62,14 +62,18 @@ func (t *Topology) ToVolumeLocations() (volumeLocations []*master_pb.VolumeLocat
 			for _, d := range rack.Children() {
 				dn := d.(*DataNode)
 				volumeLocation := &master_pb.VolumeLocation{
-					Url:       dn.Url(),
-					PublicUrl: dn.PublicUrl,
+					Url:           dn.Url(),
+					PublicUrl:     dn.PublicUrl,
+					DataCenter:    string(dc.Id()),
+					Rack:          string(rack.Id()),
+					FileSizeLimit: dn.FileSizeLimit(),
 				}
 				for _, v := range dn.GetVolumes() {
 					volumeLocation.NewVids = append(volumeLocation.NewVids, uint32(v.Id))
 				}
 				for _, s := range dn.GetEcShards() {
 					volumeLocation.NewVids = append(volumeLocation.NewVids, uint32(s.VolumeId))
+					volumeLocation.NewEcVids = append(volumeLocation.NewEcVids, uint32(s.VolumeId))
 				}
 				volumeLocations = append(volumeLocations, volumeLocation)
 			}
@@ -0,0 +1,81 @@
+package topology
+
+import (
+	"sort"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
+)
+
+// ToClusterStatus reports the capacity and the health of the volume servers, summed up by the racks and the data centers.
+// The free slots are summed from the volume servers, so the draining, read only or quarantined ones count none.
+func (t *Topology) ToClusterStatus() *master_pb.ClusterStatusResponse {
+	m := &master_pb.ClusterStatusResponse{
+		Capacity:          &master_pb.CapacityStatus{},
+		VolumeSizeLimitMb: t.volumeSizeLimit / 1024 / 1024,
+	}
+	now := time.Now().Unix()
+	for _, c := range sortedChildren(t) {
+		dc := c.(*DataCenter)
+		dcStatus := &master_pb.DataCenterStatus{Id: string(dc.Id()), Capacity: &master_pb.CapacityStatus{}}
+		for _, r := range sortedChildren(dc) {
+			rack := r.(*Rack)
+			rackStatus := &master_pb.RackStatus{Id: string(rack.Id()), Capacity: &master_pb.CapacityStatus{}}
+			for _, n := range sortedChildren(rack) {
+				dn := n.(*DataNode)
+				serverStatus := dn.toVolumeServerStatus(now)
+				rackStatus.VolumeServers = append(rackStatus.VolumeServers, serverStatus)
+				addCapacity(rackStatus.Capacity, serverStatus.Capacity)
+			}
+			dcStatus.Racks = append(dcStatus.Racks, rackStatus)
+			addCapacity(dcStatus.Capacity, rackStatus.Capacity)
+		}
+		m.DataCenters = append(m.DataCenters, dcStatus)
+		addCapacity(m.Capacity, dcStatus.Capacity)
+	}
+	return m
+}
+
+func (dn *DataNode) toVolumeServerStatus(now int64) *master_pb.VolumeServerStatus {
+	m := &master_pb.VolumeServerStatus{
+		Url:       dn.Url(),
+		PublicUrl: dn.PublicUrl,
+		Capacity: &master_pb.CapacityStatus{
+			MaxVolumeCount:    uint64(dn.GetMaxVolumeCount()),
+			VolumeCount:       uint64(dn.GetVolumeCount()),
+			ActiveVolumeCount: uint64(dn.GetActiveVolumeCount()),
+			EcShardCount:      uint64(dn.GetEcShardCount()),
+			VolumeServerCount: 1,
+		},
+		LastHeartbeatSecondsAgo: now - dn.LastHeartbeat(),
+		IsDraining:              dn.IsDraining(),
+		IsReadOnly:              dn.IsReadOnly(),
+		IsQuarantined:           dn.IsQuarantined(),
+	}
+	if free := dn.FreeSpace(); free > 0 {
+		m.Capacity.FreeVolumeCount = uint64(free)
+	}
+	if m.IsDraining || m.IsReadOnly || m.IsQuarantined {
+		m.Capacity.UnwritableVolumeServerCount = 1
+	}
+	return m
+}
+
+func addCapacity(sum, c *master_pb.CapacityStatus) {
+	sum.MaxVolumeCount += c.MaxVolumeCount
+	sum.VolumeCount += c.VolumeCount
+	sum.ActiveVolumeCount += c.ActiveVolumeCount
+	sum.FreeVolumeCount += c.FreeVolumeCount
+	sum.EcShardCount += c.EcShardCount
+	sum.VolumeServerCount += c.VolumeServerCount
+	sum.UnwritableVolumeServerCount += c.UnwritableVolumeServerCount
+}
+
+// sortedChildren lists the children by id, for a stable report
+func sortedChildren(n Node) []Node {
+	children := n.Children()
+	sort.Slice(children, func(i, j int) bool {
+		return children[i].Id() < children[j].Id()
+	})
+	return children
+}
@@ -0,0 +1,70 @@
+package topology
+
+import (
+	"testing"
+
+	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
+	"github.com/chrislusf/seaweedfs/weed/sequence"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+)
+
+func TestToClusterStatus(t *testing.T) {
+	topo := NewTopology("weedfs", sequence.NewMemorySequencer(), 32*1024*1024, 5)
+
+	addDataNode := func(dcId, rackId, ip string, maxVolumeCount int64, volumeCount int) *DataNode {
+		dn := topo.GetOrCreateDataCenter(dcId).GetOrCreateRack(rackId).GetOrCreateDataNode(ip, 8080, ip, maxVolumeCount)
+		var volumeMessages []*master_pb.VolumeInformationMessage
+		for k := 1; k <= volumeCount; k++ {
+			volumeMessages = append(volumeMessages, &master_pb.VolumeInformationMessage{
+				Id:      uint32(k),
+				Size:    uint64(1024),
+				Version: uint32(needle.CurrentVersion),
+			})
+		}
+		topo.SyncDataNodeRegistration(volumeMessages, dn)
+		dn.Heartbeat()
+		return dn
+	}
+	addDataNode("dc2", "rack1", "127.0.0.3", 10, 1)
+	addDataNode("dc1", "rack1", "127.0.0.1", 10, 3)
+	addDataNode("dc1", "rack1", "127.0.0.2", 5, 2).SetDraining(true)
+
+	status := topo.ToClusterStatus()
+
+	if status.VolumeSizeLimitMb != 32 {
+		t.Errorf("volume size limit %d MB", status.VolumeSizeLimitMb)
+	}
+	c := status.Capacity
+	if c.MaxVolumeCount != 25 || c.VolumeCount != 6 || c.VolumeServerCount != 3 || c.UnwritableVolumeServerCount != 1 {
+		t.Errorf("cluster capacity %+v", c)
+	}
+	// the draining volume server has no free slots
+	if c.FreeVolumeCount != 7+9 {
+		t.Errorf("cluster free %d", c.FreeVolumeCount)
+	}
+
+	// sorted by the ids
+	if len(status.DataCenters) != 2 || status.DataCenters[0].Id != "dc1" || status.DataCenters[1].Id != "dc2" {
+		t.Fatalf("data centers %+v", status.DataCenters)
+	}
+	dc1 := status.DataCenters[0]
+	if dc1.Capacity.VolumeCount != 5 || dc1.Capacity.FreeVolumeCount != 7 || dc1.Capacity.VolumeServerCount != 2 {
+		t.Errorf("dc1 capacity %+v", dc1.Capacity)
+	}
+	if len(dc1.Racks) != 1 || len(dc1.Racks[0].VolumeServers) != 2 {
+		t.Fatalf("dc1 racks %+v", dc1.Racks)
+	}
+	servers := dc1.Racks[0].VolumeServers
+	if servers[0].Url != "127.0.0.1:8080" || servers[0].IsDraining || servers[0].Capacity.FreeVolumeCount != 7 {
+		t.Errorf("volume server %+v", servers[0])
+	}
+	if servers[1].Url != "127.0.0.2:8080" || !servers[1].IsDraining || servers[1].Capacity.FreeVolumeCount != 0 ||
+		servers[1].Capacity.UnwritableVolumeServerCount != 1 {
+		t.Errorf("draining volume server %+v", servers[1])
+	}
+	for _, server := range servers {
+		if server.LastHeartbeatSecondsAgo < 0 || server.LastHeartbeatSecondsAgo > 1 {
+			t.Errorf("volume server %s last heartbeat %d seconds ago", server.Url, server.LastHeartbeatSecondsAgo)
+		}
+	}
+}
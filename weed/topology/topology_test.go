@@ -1,9 +1,12 @@
 package topology
 
 import (
+	"fmt"
+
 	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
 	"github.com/chrislusf/seaweedfs/weed/sequence"
 	"github.com/chrislusf/seaweedfs/weed/storage"
+	"github.com/chrislusf/seaweedfs/weed/storage/erasure_coding"
 	"github.com/chrislusf/seaweedfs/weed/storage/needle"
 
 	"testing"
@@ -173,3 +176,165 @@ func TestAddRemoveVolume(t *testing.T) {
 	}
 
 }
+
+func TestListEcVolumesWithMissingShards(t *testing.T) {
+
+	topo := NewTopology("weedfs", sequence.NewMemorySequencer(), 32*1024, 5)
+
+	dc := topo.GetOrCreateDataCenter("dc1")
+	rack := dc.GetOrCreateRack("rack1")
+	dn1 := rack.GetOrCreateDataNode("127.0.0.1", 34534, "127.0.0.1", 25)
+	dn2 := rack.GetOrCreateDataNode("127.0.0.1", 34535, "127.0.0.1", 25)
+
+	var bits1, bits2 erasure_coding.ShardBits
+	for i := 0; i < erasure_coding.TotalShardsCount; i++ {
+		if i < 7 {
+			bits1 = bits1.AddShardId(erasure_coding.ShardId(i))
+		} else {
+			bits2 = bits2.AddShardId(erasure_coding.ShardId(i))
+		}
+	}
+	topo.SyncDataNodeEcShards([]*master_pb.VolumeEcShardInformationMessage{
+		{Id: 1, Collection: "x", EcIndexBits: uint32(bits1)},
+	}, dn1)
+	topo.SyncDataNodeEcShards([]*master_pb.VolumeEcShardInformationMessage{
+		{Id: 1, Collection: "x", EcIndexBits: uint32(bits2)},
+	}, dn2)
+
+	assert(t, "missing with all shards", len(topo.ListEcVolumesWithMissingShards()), 0)
+
+	// lose one shard on dn2
+	topo.SyncDataNodeEcShards([]*master_pb.VolumeEcShardInformationMessage{
+		{Id: 1, Collection: "x", EcIndexBits: uint32(bits2.RemoveShardId(13))},
+	}, dn2)
+	missing := topo.ListEcVolumesWithMissingShards()
+	assert(t, "missing after losing one shard", len(missing), 1)
+	assert(t, "shard count after losing one shard", missing[1].ShardCount, erasure_coding.TotalShardsCount-1)
+
+	// dn1 is gone
+	topo.UnRegisterDataNode(dn1)
+	missing = topo.ListEcVolumesWithMissingShards()
+	assert(t, "shard count after losing dn1", missing[1].ShardCount, 6)
+
+	// all shards are gone, e.g., the ec volume is deleted
+	topo.UnRegisterDataNode(dn2)
+	assert(t, "missing after losing all shards", len(topo.ListEcVolumesWithMissingShards()), 0)
+
+}
+
+func TestPickByLocalityGroup(t *testing.T) {
+
+	writables := []needle.VolumeId{1, 2, 3, 4, 5, 6, 7, 8}
+
+	picked := pickByLocalityGroup(writables, "/videos/a.mp4")
+	if again := pickByLocalityGroup(writables, "/videos/a.mp4"); again != picked {
+		t.Errorf("same locality group picked volume %d, expected %d", again, picked)
+	}
+
+	var others []needle.VolumeId
+	for _, vid := range writables {
+		if vid != picked {
+			others = append(others, vid)
+		}
+	}
+	if vid := pickByLocalityGroup([]needle.VolumeId{others[0], picked}, "/videos/a.mp4"); vid != picked {
+		t.Errorf("picked volume %d after other volumes are gone, expected %d", vid, picked)
+	}
+	if vid := pickByLocalityGroup(others, "/videos/a.mp4"); vid == picked {
+		t.Errorf("picked volume %d after it is gone", vid)
+	}
+
+	seen := make(map[needle.VolumeId]bool)
+	for i := 0; i < 100; i++ {
+		seen[pickByLocalityGroup(writables, fmt.Sprintf("/videos/%d.mp4", i))] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("locality groups should spread over volumes, but only %d used", len(seen))
+	}
+
+}
+
+func TestPickForWriterSession(t *testing.T) {
+
+	rp, _ := storage.NewReplicaPlacementFromString("000")
+	vl := NewVolumeLayout(rp, needle.EMPTY_TTL, 32*1024*1024*1024)
+	dn1, dn2 := NewDataNode("dn1"), NewDataNode("dn2")
+	for vid := needle.VolumeId(1); vid <= 8; vid++ {
+		vl.vid2location[vid] = NewVolumeLocationList()
+		if vid%2 == 1 {
+			vl.vid2location[vid].Set(dn1)
+		} else {
+			vl.vid2location[vid].Set(dn2)
+		}
+		vl.writables = append(vl.writables, vid)
+	}
+
+	option := &VolumeGrowOption{Session: "writer-1"}
+	picked, _, _, err := vl.PickForWrite(1, option)
+	if err != nil {
+		t.Fatalf("pick for write: %v", err)
+	}
+	firstVid := *picked
+	for i := 0; i < 10; i++ {
+		if vid, _, _, _ := vl.PickForWrite(1, option); *vid != firstVid {
+			t.Fatalf("session picked volume %d, expected %d", *vid, firstVid)
+		}
+	}
+
+	vl.removeFromWritable(firstVid)
+	vid, _, locationList, err := vl.PickForWrite(1, option)
+	if err != nil {
+		t.Fatalf("pick for write after volume %d is full: %v", firstVid, err)
+	}
+	if *vid == firstVid {
+		t.Errorf("session picked the full volume %d", firstVid)
+	}
+	if locationList.Head() != vl.vid2location[firstVid].Head() {
+		t.Errorf("session moved from %s to %s", vl.vid2location[firstVid].Head().Id(), locationList.Head().Id())
+	}
+
+}
+
+func TestWithholdFreeSlots(t *testing.T) {
+
+	topo := NewTopology("weedfs", sequence.NewMemorySequencer(), 32*1024, 5)
+
+	dc := topo.GetOrCreateDataCenter("dc1")
+	rack := dc.GetOrCreateRack("rack1")
+	dn1 := rack.GetOrCreateDataNode("127.0.0.1", 34534, "127.0.0.1", 25)
+	dn2 := rack.GetOrCreateDataNode("127.0.0.1", 34535, "127.0.0.1", 10)
+	dn1.AddOrUpdateVolume(storage.VolumeInfo{Id: 1})
+
+	expectFreeSpace := func(expected int64) {
+		t.Helper()
+		for _, n := range []Node{rack, dc, topo} {
+			if free := n.FreeSpace(); free != expected {
+				t.Errorf("%s free space %d, expected %d", n.Id(), free, expected)
+			}
+		}
+	}
+	expectFreeSpace(24 + 10)
+
+	dn1.SetDraining(true)
+	expectFreeSpace(10)
+	dn1.SetReadOnly(true)
+	expectFreeSpace(10)
+
+	// the volumes deleted meanwhile are followed on the next heartbeat
+	dn1.UpdateVolumes(nil)
+	dn1.SetDraining(true)
+	expectFreeSpace(10)
+
+	dn1.SetDraining(false)
+	expectFreeSpace(10)
+	dn1.SetReadOnly(false)
+	expectFreeSpace(25 + 10)
+
+	dn2.SetQuarantined(true)
+	expectFreeSpace(25)
+	topo.UnRegisterDataNode(dn2)
+	expectFreeSpace(25)
+	if max := topo.GetMaxVolumeCount(); max != 25 {
+		t.Errorf("max volume count %d after unregistering the quarantined data node", max)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math/rand"
 	"sync"
+	"time"
 
 	"github.com/chrislusf/seaweedfs/weed/storage/needle"
 	"google.golang.org/grpc"
@@ -28,6 +29,11 @@ type VolumeGrowOption struct {
 	DataCenter       string
 	Rack             string
 	DataNode         string
+	LocalityGroup    string
+	// keep assigning the same volume, or the same data node, to the writer session
+	Session string
+	// only place on the volume servers with the matching labels
+	LabelSelector storage.LabelSelector
 }
 
 type VolumeGrowth struct {
@@ -35,7 +41,62 @@ type VolumeGrowth struct {
 }
 
 func (o *VolumeGrowOption) String() string {
-	return fmt.Sprintf("Collection:%s, ReplicaPlacement:%v, Ttl:%v, DataCenter:%s, Rack:%s, DataNode:%s", o.Collection, o.ReplicaPlacement, o.Ttl, o.DataCenter, o.Rack, o.DataNode)
+	return fmt.Sprintf("Collection:%s, ReplicaPlacement:%v, Ttl:%v, DataCenter:%s, Rack:%s, DataNode:%s, LabelSelector:%s", o.Collection, o.ReplicaPlacement, o.Ttl, o.DataCenter, o.Rack, o.DataNode, o.LabelSelector)
+}
+
+func (o *VolumeGrowOption) placementString() string {
+	return fmt.Sprintf("DataCenter:%s, Rack:%s, DataNode:%s, LabelSelector:%s", o.DataCenter, o.Rack, o.DataNode, o.LabelSelector)
+}
+
+// matchesLocation checks the preferred data center, and within it the preferred rack and data node
+func (o *VolumeGrowOption) matchesLocation(dn *DataNode) bool {
+	if o.DataCenter == "" {
+		return true
+	}
+	if dn.GetDataCenter().Id() != NodeId(o.DataCenter) {
+		return false
+	}
+	if o.Rack != "" && dn.GetRack().Id() != NodeId(o.Rack) {
+		return false
+	}
+	if o.DataNode != "" && dn.Id() != NodeId(o.DataNode) {
+		return false
+	}
+	return true
+}
+
+// matchesLabels checks all the replicas of a volume are on the volume servers matching the label selector
+func (o *VolumeGrowOption) matchesLabels(locations *VolumeLocationList) bool {
+	if len(o.LabelSelector) == 0 {
+		return true
+	}
+	if locations == nil {
+		return false
+	}
+	for _, dn := range locations.list {
+		if !o.LabelSelector.Matches(dn.Labels()) {
+			return false
+		}
+	}
+	return true
+}
+
+// freeSpace only counts the free slots of the volume servers matching the label selector
+func (o *VolumeGrowOption) freeSpace(n Node) int64 {
+	if len(o.LabelSelector) == 0 {
+		return n.FreeSpace()
+	}
+	if n.IsDataNode() {
+		if dn := n.(*DataNode); o.LabelSelector.Matches(dn.Labels()) {
+			return dn.FreeSpace()
+		}
+		return 0
+	}
+	var free int64
+	for _, c := range n.Children() {
+		free += o.freeSpace(c)
+	}
+	return free
 }
 
 func NewDefaultVolumeGrowth() *VolumeGrowth {
@@ -58,8 +119,15 @@ func (vg *VolumeGrowth) findVolumeCount(copyCount int) (count int) {
 	return
 }
 
+// AutomaticGrowByType grows the volumes when none is writable, up to the writable target of the growth strategy of the collection,
+// or by the fixed number by the copy count
 func (vg *VolumeGrowth) AutomaticGrowByType(option *VolumeGrowOption, grpcDialOption grpc.DialOption, topo *Topology) (count int, err error) {
-	count, err = vg.GrowByCountAndType(grpcDialOption, vg.findVolumeCount(option.ReplicaPlacement.GetCopyCount()), option, topo)
+	targetCount := vg.findVolumeCount(option.ReplicaPlacement.GetCopyCount())
+	vl := topo.GetVolumeLayout(option.Collection, option.ReplicaPlacement, option.Ttl)
+	if writableTarget := topo.WritableTarget(option.Collection, vl, time.Now()); writableTarget > 0 {
+		targetCount = writableTarget
+	}
+	count, err = vg.GrowByCountAndType(grpcDialOption, targetCount, option, topo)
 	if count > 0 && count%option.ReplicaPlacement.GetCopyCount() == 0 {
 		return count, nil
 	}
@@ -100,21 +168,21 @@ func (vg *VolumeGrowth) findAndGrow(grpcDialOption grpc.DialOption, topo *Topolo
 func (vg *VolumeGrowth) findEmptySlotsForOneVolume(topo *Topology, option *VolumeGrowOption) (servers []*DataNode, err error) {
 	//find main datacenter and other data centers
 	rp := option.ReplicaPlacement
-	mainDataCenter, otherDataCenters, dc_err := topo.RandomlyPickNodes(rp.DiffDataCenterCount+1, func(node Node) error {
+	mainDataCenter, otherDataCenters, dc_err := topo.randomlyPickNodes(rp.DiffDataCenterCount+1, option.freeSpace, func(node Node) error {
 		if option.DataCenter != "" && node.IsDataCenter() && node.Id() != NodeId(option.DataCenter) {
 			return fmt.Errorf("Not matching preferred data center:%s", option.DataCenter)
 		}
 		if len(node.Children()) < rp.DiffRackCount+1 {
 			return fmt.Errorf("Only has %d racks, not enough for %d.", len(node.Children()), rp.DiffRackCount+1)
 		}
-		if node.FreeSpace() < int64(rp.DiffRackCount+rp.SameRackCount+1) {
-			return fmt.Errorf("Free:%d < Expected:%d", node.FreeSpace(), rp.DiffRackCount+rp.SameRackCount+1)
+		if option.freeSpace(node) < int64(rp.DiffRackCount+rp.SameRackCount+1) {
+			return fmt.Errorf("Free:%d < Expected:%d", option.freeSpace(node), rp.DiffRackCount+rp.SameRackCount+1)
 		}
 		possibleRacksCount := 0
 		for _, rack := range node.Children() {
 			possibleDataNodesCount := 0
 			for _, n := range rack.Children() {
-				if n.FreeSpace() >= 1 {
+				if option.freeSpace(n) >= 1 {
 					possibleDataNodesCount++
 				}
 			}
@@ -132,12 +200,12 @@ func (vg *VolumeGrowth) findEmptySlotsForOneVolume(topo *Topology, option *Volum
 	}
 
 	//find main rack and other racks
-	mainRack, otherRacks, rackErr := mainDataCenter.(*DataCenter).RandomlyPickNodes(rp.DiffRackCount+1, func(node Node) error {
+	mainRack, otherRacks, rackErr := mainDataCenter.(*DataCenter).randomlyPickNodes(rp.DiffRackCount+1, option.freeSpace, func(node Node) error {
 		if option.Rack != "" && node.IsRack() && node.Id() != NodeId(option.Rack) {
 			return fmt.Errorf("Not matching preferred rack:%s", option.Rack)
 		}
-		if node.FreeSpace() < int64(rp.SameRackCount+1) {
-			return fmt.Errorf("Free:%d < Expected:%d", node.FreeSpace(), rp.SameRackCount+1)
+		if option.freeSpace(node) < int64(rp.SameRackCount+1) {
+			return fmt.Errorf("Free:%d < Expected:%d", option.freeSpace(node), rp.SameRackCount+1)
 		}
 		if len(node.Children()) < rp.SameRackCount+1 {
 			// a bit faster way to test free racks
@@ -145,7 +213,7 @@ func (vg *VolumeGrowth) findEmptySlotsForOneVolume(topo *Topology, option *Volum
 		}
 		possibleDataNodesCount := 0
 		for _, n := range node.Children() {
-			if n.FreeSpace() >= 1 {
+			if option.freeSpace(n) >= 1 {
 				possibleDataNodesCount++
 			}
 		}
@@ -159,12 +227,12 @@ func (vg *VolumeGrowth) findEmptySlotsForOneVolume(topo *Topology, option *Volum
 	}
 
 	//find main rack and other racks
-	mainServer, otherServers, serverErr := mainRack.(*Rack).RandomlyPickNodes(rp.SameRackCount+1, func(node Node) error {
+	mainServer, otherServers, serverErr := mainRack.(*Rack).randomlyPickNodes(rp.SameRackCount+1, option.freeSpace, func(node Node) error {
 		if option.DataNode != "" && node.IsDataNode() && node.Id() != NodeId(option.DataNode) {
 			return fmt.Errorf("Not matching preferred data node:%s", option.DataNode)
 		}
-		if node.FreeSpace() < 1 {
-			return fmt.Errorf("Free:%d < Expected:%d", node.FreeSpace(), 1)
+		if option.freeSpace(node) < 1 {
+			return fmt.Errorf("Free:%d < Expected:%d", option.freeSpace(node), 1)
 		}
 		return nil
 	})
@@ -177,16 +245,16 @@ func (vg *VolumeGrowth) findEmptySlotsForOneVolume(topo *Topology, option *Volum
 		servers = append(servers, server.(*DataNode))
 	}
 	for _, rack := range otherRacks {
-		r := rand.Int63n(rack.FreeSpace())
-		if server, e := rack.ReserveOneVolume(r); e == nil {
+		r := rand.Int63n(option.freeSpace(rack))
+		if server, e := rack.reserveOneVolume(r, option.freeSpace); e == nil {
 			servers = append(servers, server)
 		} else {
 			return servers, e
 		}
 	}
 	for _, datacenter := range otherDataCenters {
-		r := rand.Int63n(datacenter.FreeSpace())
-		if server, e := datacenter.ReserveOneVolume(r); e == nil {
+		r := rand.Int63n(option.freeSpace(datacenter))
+		if server, e := datacenter.reserveOneVolume(r, option.freeSpace); e == nil {
 			servers = append(servers, server)
 		} else {
 			return servers, e
@@ -0,0 +1,263 @@
+package topology
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/storage"
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+)
+
+const (
+	// grow a fixed number of volumes by the copy count, only when no volume is writable
+	GrowthStrategyFixed = "fixed"
+	// keep a number of writable volumes, more during the peak hours
+	GrowthStrategyTarget = "target"
+	// as the target strategy, and also pre-allocate for the volumes filled in the next hour on the previous days
+	GrowthStrategyAdaptive = "adaptive"
+)
+
+// VolumeGrowthStrategy is how the volumes of a collection grow, configured by "volume.configure" and replicated by raft.
+type VolumeGrowthStrategy struct {
+	Kind               string `json:"kind"`
+	WritableTarget     int    `json:"writableTarget,omitempty"`
+	PeakWritableTarget int    `json:"peakWritableTarget,omitempty"`
+	// the peak hours in the local time of the master, as "8-20", or "20-6" over the midnight
+	PeakHours string `json:"peakHours,omitempty"`
+}
+
+// growthStrategy decides the number of the writable volumes to keep in a volume layout,
+// 0 to only grow when no volume is writable
+type growthStrategy interface {
+	writableTarget(now time.Time, fills *fillHistory) int
+}
+
+var growthStrategies = map[string]func(s VolumeGrowthStrategy) growthStrategy{
+	GrowthStrategyFixed: func(s VolumeGrowthStrategy) growthStrategy {
+		return fixedGrowth{}
+	},
+	GrowthStrategyTarget: func(s VolumeGrowthStrategy) growthStrategy {
+		return targetGrowth{s}
+	},
+	GrowthStrategyAdaptive: func(s VolumeGrowthStrategy) growthStrategy {
+		return adaptiveGrowth{targetGrowth{s}}
+	},
+}
+
+func (s VolumeGrowthStrategy) Validate() error {
+	if _, found := growthStrategies[s.Kind]; !found {
+		return fmt.Errorf("unknown volume growth strategy %q", s.Kind)
+	}
+	if s.WritableTarget < 0 || s.PeakWritableTarget < 0 {
+		return fmt.Errorf("negative writable volume target")
+	}
+	if s.PeakHours != "" {
+		if _, _, err := parsePeakHours(s.PeakHours); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s VolumeGrowthStrategy) String() string {
+	switch s.Kind {
+	case GrowthStrategyTarget, GrowthStrategyAdaptive:
+		if s.PeakHours != "" {
+			return fmt.Sprintf("%s writable:%d peak:%d at %s", s.Kind, s.WritableTarget, s.PeakWritableTarget, s.PeakHours)
+		}
+		return fmt.Sprintf("%s writable:%d", s.Kind, s.WritableTarget)
+	}
+	return s.Kind
+}
+
+func parsePeakHours(peakHours string) (start, end int, err error) {
+	parts := strings.Split(peakHours, "-")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("peak hours %q should be as 8-20", peakHours)
+	}
+	if start, err = strconv.Atoi(strings.TrimSpace(parts[0])); err != nil || start < 0 || start > 23 {
+		return 0, 0, fmt.Errorf("peak hours %q should start from 0 to 23", peakHours)
+	}
+	if end, err = strconv.Atoi(strings.TrimSpace(parts[1])); err != nil || end < 0 || end > 24 {
+		return 0, 0, fmt.Errorf("peak hours %q should end from 0 to 24", peakHours)
+	}
+	return start, end, nil
+}
+
+func (s VolumeGrowthStrategy) isPeakHour(now time.Time) bool {
+	start, end, err := parsePeakHours(s.PeakHours)
+	if err != nil {
+		return false
+	}
+	hour := now.Hour()
+	if start <= end {
+		return start <= hour && hour < end
+	}
+	return start <= hour || hour < end
+}
+
+type fixedGrowth struct {
+}
+
+func (g fixedGrowth) writableTarget(now time.Time, fills *fillHistory) int {
+	return 0
+}
+
+type targetGrowth struct {
+	VolumeGrowthStrategy
+}
+
+func (g targetGrowth) writableTarget(now time.Time, fills *fillHistory) int {
+	if g.PeakHours != "" && g.isPeakHour(now) {
+		return g.PeakWritableTarget
+	}
+	return g.WritableTarget
+}
+
+type adaptiveGrowth struct {
+	targetGrowth
+}
+
+func (g adaptiveGrowth) writableTarget(now time.Time, fills *fillHistory) int {
+	target := g.targetGrowth.writableTarget(now, fills)
+	if expected := int(math.Ceil(fills.expected(now.Add(time.Hour)))); expected > target {
+		target = expected
+	}
+	return target
+}
+
+// fillHistoryAlpha weights the fills of the latest day against the previous days of the same hour
+const fillHistoryAlpha = 0.3
+
+// fillHistory learns the number of the volumes filled in each hour of the day, averaged over the days.
+// It is only kept in memory, and starts empty on the new leader.
+type fillHistory struct {
+	hour    int64 // the unix hour being counted
+	count   int
+	average [24]float64
+}
+
+func (h *fillHistory) record(now time.Time, n int) {
+	h.advance(now)
+	h.count += n
+}
+
+// expected is the number of the volumes filled on average in the hour of the day of the time
+func (h *fillHistory) expected(t time.Time) float64 {
+	return h.average[t.Hour()]
+}
+
+func (h *fillHistory) advance(now time.Time) {
+	current := now.Unix() / 3600
+	if h.hour == 0 || current-h.hour > 24*14 {
+		// the averages without any recent fill would have decayed anyway
+		if h.hour != 0 {
+			h.average = [24]float64{}
+		}
+		h.hour, h.count = current, 0
+		return
+	}
+	for ; h.hour < current; h.hour++ {
+		hourOfDay := time.Unix(h.hour*3600, 0).Hour()
+		h.average[hourOfDay] = h.average[hourOfDay]*(1-fillHistoryAlpha) + float64(h.count)*fillHistoryAlpha
+		h.count = 0
+	}
+}
+
+// SetVolumeGrowthStrategy sets the growth strategy of the collection, or removes it if nil
+func (t *Topology) SetVolumeGrowthStrategy(collection string, s *VolumeGrowthStrategy) {
+	t.growthLock.Lock()
+	defer t.growthLock.Unlock()
+	if s == nil {
+		delete(t.growthStrategies, collection)
+		return
+	}
+	t.growthStrategies[collection] = *s
+}
+
+// VolumeGrowthStrategies lists the configured growth strategies by the collections
+func (t *Topology) VolumeGrowthStrategies() map[string]VolumeGrowthStrategy {
+	t.growthLock.RLock()
+	defer t.growthLock.RUnlock()
+	ret := make(map[string]VolumeGrowthStrategy, len(t.growthStrategies))
+	for collection, s := range t.growthStrategies {
+		ret[collection] = s
+	}
+	return ret
+}
+
+func (t *Topology) setVolumeGrowthStrategies(strategies map[string]VolumeGrowthStrategy) {
+	t.growthLock.Lock()
+	defer t.growthLock.Unlock()
+	t.growthStrategies = make(map[string]VolumeGrowthStrategy, len(strategies))
+	for collection, s := range strategies {
+		t.growthStrategies[collection] = s
+	}
+}
+
+// WritableTarget is the number of the writable volumes to keep in the volume layout,
+// 0 to only grow when no volume is writable
+func (t *Topology) WritableTarget(collection string, vl *VolumeLayout, now time.Time) int {
+	t.growthLock.RLock()
+	s, found := t.growthStrategies[collection]
+	t.growthLock.RUnlock()
+	if !found {
+		return 0
+	}
+	newStrategy, found := growthStrategies[s.Kind]
+	if !found {
+		return 0
+	}
+	vl.accessLock.Lock()
+	defer vl.accessLock.Unlock()
+	vl.fills.advance(now)
+	return newStrategy(s).writableTarget(now, &vl.fills)
+}
+
+// VolumeGrowthDeficit is the number of the writable volumes missing from the target of a volume layout
+type VolumeGrowthDeficit struct {
+	Collection       string
+	ReplicaPlacement *storage.ReplicaPlacement
+	Ttl              *needle.TTL
+	Count            int
+}
+
+// VolumeGrowthDeficits lists the volume layouts with less writable volumes than the targets of the growth strategies.
+// The collections without any volume yet use the replication and the ttl of the collection config, or the default replica placement.
+func (t *Topology) VolumeGrowthDeficits(now time.Time, defaultReplicaPlacement *storage.ReplicaPlacement) (deficits []VolumeGrowthDeficit) {
+	for collection := range t.VolumeGrowthStrategies() {
+		var layouts []*VolumeLayout
+		if c, found := t.FindCollection(collection); found {
+			for _, vl := range c.storageType2VolumeLayout.Items() {
+				layouts = append(layouts, vl.(*VolumeLayout))
+			}
+		}
+		if len(layouts) == 0 {
+			rp, ttl := defaultReplicaPlacement, needle.EMPTY_TTL
+			if config, found := t.GetCollectionConfig(collection); found {
+				if config.Replication != "" {
+					rp, _ = storage.NewReplicaPlacementFromString(config.Replication)
+				}
+				if config.Ttl != "" {
+					ttl, _ = needle.ReadTTL(config.Ttl)
+				}
+			}
+			layouts = append(layouts, t.GetVolumeLayout(collection, rp, ttl))
+		}
+		for _, vl := range layouts {
+			target := t.WritableTarget(collection, vl, now)
+			if writables := vl.GetActiveVolumeCount(&VolumeGrowOption{}); writables < target {
+				deficits = append(deficits, VolumeGrowthDeficit{
+					Collection:       collection,
+					ReplicaPlacement: vl.rp,
+					Ttl:              vl.ttl,
+					Count:            target - writables,
+				})
+			}
+		}
+	}
+	return
+}
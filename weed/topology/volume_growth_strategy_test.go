@@ -0,0 +1,71 @@
+package topology
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVolumeGrowthStrategyTargets(t *testing.T) {
+	morning := time.Date(2019, 12, 2, 9, 30, 0, 0, time.Local)
+	night := time.Date(2019, 12, 2, 23, 30, 0, 0, time.Local)
+	var fills fillHistory
+
+	for _, c := range []struct {
+		strategy       VolumeGrowthStrategy
+		morning, night int
+	}{
+		{VolumeGrowthStrategy{Kind: GrowthStrategyFixed, WritableTarget: 10}, 0, 0},
+		{VolumeGrowthStrategy{Kind: GrowthStrategyTarget, WritableTarget: 10}, 10, 10},
+		{VolumeGrowthStrategy{Kind: GrowthStrategyTarget, WritableTarget: 10, PeakWritableTarget: 30, PeakHours: "8-20"}, 30, 10},
+		{VolumeGrowthStrategy{Kind: GrowthStrategyTarget, WritableTarget: 10, PeakWritableTarget: 30, PeakHours: "22-6"}, 10, 30},
+	} {
+		if err := c.strategy.Validate(); err != nil {
+			t.Fatalf("%v: %v", c.strategy, err)
+		}
+		s := growthStrategies[c.strategy.Kind](c.strategy)
+		if target := s.writableTarget(morning, &fills); target != c.morning {
+			t.Errorf("%v: %d writable in the morning, expected %d", c.strategy, target, c.morning)
+		}
+		if target := s.writableTarget(night, &fills); target != c.night {
+			t.Errorf("%v: %d writable in the night, expected %d", c.strategy, target, c.night)
+		}
+	}
+
+	for _, s := range []VolumeGrowthStrategy{
+		{Kind: "weekly"},
+		{Kind: GrowthStrategyTarget, WritableTarget: -1},
+		{Kind: GrowthStrategyTarget, PeakHours: "8"},
+		{Kind: GrowthStrategyTarget, PeakHours: "8-25"},
+	} {
+		if err := s.Validate(); err == nil {
+			t.Errorf("%+v is valid", s)
+		}
+	}
+}
+
+func TestAdaptiveVolumeGrowth(t *testing.T) {
+	start := time.Date(2019, 12, 1, 0, 30, 0, 0, time.Local)
+	var fills fillHistory
+
+	// 10 volumes filled from 9 to 10 every day, and 1 volume in the other hours
+	for day := 0; day < 7; day++ {
+		for hour := 0; hour < 24; hour++ {
+			n := 1
+			if hour == 9 {
+				n = 10
+			}
+			fills.record(start.Add(time.Duration(day*24+hour)*time.Hour), n)
+		}
+	}
+	fills.advance(start.Add(7 * 24 * time.Hour))
+
+	adaptive := growthStrategies[GrowthStrategyAdaptive](VolumeGrowthStrategy{Kind: GrowthStrategyAdaptive, WritableTarget: 3})
+	eightOClock := time.Date(2019, 12, 8, 8, 15, 0, 0, time.Local)
+	if target := adaptive.writableTarget(eightOClock, &fills); target != 10 {
+		t.Errorf("%d writable before the busy hour, expected 10", target)
+	}
+	noon := time.Date(2019, 12, 8, 12, 15, 0, 0, time.Local)
+	if target := adaptive.writableTarget(noon, &fills); target != 3 {
+		t.Errorf("%d writable before a quiet hour, expected 3", target)
+	}
+}
@@ -130,3 +130,49 @@ func TestFindEmptySlotsForOneVolume(t *testing.T) {
 		fmt.Println("assigned node :", server.Id())
 	}
 }
+
+func TestFindEmptySlotsWithLabelSelector(t *testing.T) {
+	topo := setup(topologyLayout)
+	for _, id := range []string{"server112", "server122", "server123"} {
+		dn, _ := findDataNode(topo, id)
+		dn.SetLabels(map[string]string{"class": "archive"})
+	}
+	vg := NewDefaultVolumeGrowth()
+	rp, _ := storage.NewReplicaPlacementFromString("010")
+	labelSelector, _ := storage.ParseLabelSelector("class=archive")
+	for i := 0; i < 10; i++ {
+		servers, err := vg.findEmptySlotsForOneVolume(topo, &VolumeGrowOption{
+			ReplicaPlacement: rp,
+			LabelSelector:    labelSelector,
+		})
+		if err != nil {
+			t.Fatalf("finding empty slots: %v", err)
+		}
+		for _, server := range servers {
+			if server.Labels()["class"] != "archive" {
+				t.Errorf("placed on %s without the label", server.Id())
+			}
+		}
+	}
+
+	labelSelector, _ = storage.ParseLabelSelector("class=ssd")
+	if _, err := vg.findEmptySlotsForOneVolume(topo, &VolumeGrowOption{
+		ReplicaPlacement: rp,
+		LabelSelector:    labelSelector,
+	}); err == nil {
+		t.Errorf("placed on the volume servers without the labels")
+	}
+}
+
+func findDataNode(topo *Topology, id string) (*DataNode, bool) {
+	for _, dc := range topo.Children() {
+		for _, rack := range dc.Children() {
+			for _, dn := range rack.Children() {
+				if string(dn.Id()) == id {
+					return dn.(*DataNode), true
+				}
+			}
+		}
+	}
+	return nil, false
+}
@@ -3,6 +3,7 @@ package topology
 import (
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"math/rand"
 	"sync"
 	"time"
@@ -21,6 +22,8 @@ type VolumeLayout struct {
 	readonlyVolumes  map[needle.VolumeId]bool // transient set of readonly volumes
 	oversizedVolumes map[needle.VolumeId]bool // set of oversized volumes
 	volumeSizeLimit  uint64
+	fills            fillHistory // the volumes filled by the hours, for the adaptive growth
+	sessions         writerSessions
 	accessLock       sync.RWMutex
 }
 
@@ -56,6 +59,11 @@ func (vl *VolumeLayout) RegisterVolume(v *storage.VolumeInfo, dn *DataNode) {
 	vl.vid2location[v.Id].Set(dn)
 	// glog.V(4).Infof("volume %d added to %s len %d copy %d", v.Id, dn.Id(), vl.vid2location[v.Id].Length(), v.ReplicaPlacement.GetCopyCount())
 	for _, dn := range vl.vid2location[v.Id].list {
+		if dn.IsQuarantined() {
+			glog.V(1).Infof("vid %d removed from writable on quarantined %s", v.Id, dn.Url())
+			vl.removeFromWritable(v.Id)
+			return
+		}
 		if vInfo, err := dn.GetVolumesById(v.Id); err == nil {
 			if vInfo.ReadOnly {
 				glog.V(1).Infof("vid %d removed from writable", v.Id)
@@ -106,7 +114,7 @@ func (vl *VolumeLayout) UnRegisterVolume(v *storage.VolumeInfo, dn *DataNode) {
 }
 
 func (vl *VolumeLayout) ensureCorrectWritables(v *storage.VolumeInfo) {
-	if vl.vid2location[v.Id].Length() == vl.rp.GetCopyCount() && vl.isWritable(v) {
+	if vl.vid2location[v.Id].Length() == vl.rp.GetCopyCount() && vl.isWritable(v) && !vl.hasQuarantinedLocation(v.Id) {
 		if _, ok := vl.oversizedVolumes[v.Id]; !ok {
 			vl.addToWritable(v.Id)
 		}
@@ -124,6 +132,12 @@ func (vl *VolumeLayout) addToWritable(vid needle.VolumeId) {
 	vl.writables = append(vl.writables, vid)
 }
 
+func (vl *VolumeLayout) setVolumeSizeLimit(volumeSizeLimit uint64) {
+	vl.accessLock.Lock()
+	defer vl.accessLock.Unlock()
+	vl.volumeSizeLimit = volumeSizeLimit
+}
+
 func (vl *VolumeLayout) isOversized(v *storage.VolumeInfo) bool {
 	return uint64(v.Size) >= vl.volumeSizeLimit
 }
@@ -134,6 +148,16 @@ func (vl *VolumeLayout) isWritable(v *storage.VolumeInfo) bool {
 		!v.ReadOnly
 }
 
+// hasQuarantinedLocation keeps the volumes with any replica on a quarantined volume server out of the writables
+func (vl *VolumeLayout) hasQuarantinedLocation(vid needle.VolumeId) bool {
+	for _, dn := range vl.vid2location[vid].list {
+		if dn.IsQuarantined() {
+			return true
+		}
+	}
+	return false
+}
+
 func (vl *VolumeLayout) isEmpty() bool {
 	vl.accessLock.RLock()
 	defer vl.accessLock.RUnlock()
@@ -165,13 +189,30 @@ func (vl *VolumeLayout) PickForWrite(count uint64, option *VolumeGrowOption) (*n
 	vl.accessLock.RLock()
 	defer vl.accessLock.RUnlock()
 
+	if option.Session == "" {
+		return vl.pickForWrite(count, option)
+	}
+	if vid, locationList, found := vl.pickForSession(option); found {
+		return &vid, count, locationList, nil
+	}
+	vid, count, locationList, err := vl.pickForWrite(count, option)
+	if err == nil {
+		vl.sessions.remember(option.Session, *vid, locationList.MostDurable(*vid).Id())
+	}
+	return vid, count, locationList, err
+}
+
+func (vl *VolumeLayout) pickForWrite(count uint64, option *VolumeGrowOption) (*needle.VolumeId, uint64, *VolumeLocationList, error) {
 	lenWriters := len(vl.writables)
 	if lenWriters <= 0 {
 		glog.V(0).Infoln("No more writable volumes!")
 		return nil, 0, nil, errors.New("No more writable volumes!")
 	}
-	if option.DataCenter == "" {
+	if option.DataCenter == "" && len(option.LabelSelector) == 0 {
 		vid := vl.writables[rand.Intn(lenWriters)]
+		if option.LocalityGroup != "" {
+			vid = pickByLocalityGroup(vl.writables, option.LocalityGroup)
+		}
 		locationList := vl.vid2location[vid]
 		if locationList != nil {
 			return &vid, count, locationList, nil
@@ -183,41 +224,67 @@ func (vl *VolumeLayout) PickForWrite(count uint64, option *VolumeGrowOption) (*n
 	counter := 0
 	for _, v := range vl.writables {
 		volumeLocationList := vl.vid2location[v]
+		if !option.matchesLabels(volumeLocationList) {
+			continue
+		}
 		for _, dn := range volumeLocationList.list {
-			if dn.GetDataCenter().Id() == NodeId(option.DataCenter) {
-				if option.Rack != "" && dn.GetRack().Id() != NodeId(option.Rack) {
-					continue
-				}
-				if option.DataNode != "" && dn.Id() != NodeId(option.DataNode) {
-					continue
-				}
+			if option.matchesLocation(dn) {
 				counter++
-				if rand.Intn(counter) < 1 {
+				if option.LocalityGroup != "" {
+					if counter == 1 || localityScore(option.LocalityGroup, v) > localityScore(option.LocalityGroup, vid) {
+						vid, locationList = v, volumeLocationList
+					}
+				} else if rand.Intn(counter) < 1 {
 					vid, locationList = v, volumeLocationList
 				}
 			}
 		}
 	}
+	if counter == 0 {
+		return nil, 0, nil, fmt.Errorf("No writable volumes on the volume servers of %s", option.placementString())
+	}
 	return &vid, count, locationList, nil
 }
 
+// pickByLocalityGroup uses rendezvous hashing, so that the same locality group
+// keeps writing to the same volume until the volume is no longer writable.
+func pickByLocalityGroup(writables []needle.VolumeId, localityGroup string) (picked needle.VolumeId) {
+	var maxScore uint64
+	for i, vid := range writables {
+		if score := localityScore(localityGroup, vid); i == 0 || score > maxScore {
+			picked, maxScore = vid, score
+		}
+	}
+	return
+}
+
+func localityScore(localityGroup string, vid needle.VolumeId) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(localityGroup))
+	// mix in the volume id with the murmur3 finalizer for an even spread
+	x := h.Sum64() ^ uint64(vid)*0x9e3779b97f4a7c15
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
 func (vl *VolumeLayout) GetActiveVolumeCount(option *VolumeGrowOption) int {
 	vl.accessLock.RLock()
 	defer vl.accessLock.RUnlock()
 
-	if option.DataCenter == "" {
+	if option.DataCenter == "" && len(option.LabelSelector) == 0 {
 		return len(vl.writables)
 	}
 	counter := 0
 	for _, v := range vl.writables {
+		if !option.matchesLabels(vl.vid2location[v]) {
+			continue
+		}
 		for _, dn := range vl.vid2location[v].list {
-			if dn.GetDataCenter().Id() == NodeId(option.DataCenter) {
-				if option.Rack != "" && dn.GetRack().Id() != NodeId(option.Rack) {
-					continue
-				}
-				if option.DataNode != "" && dn.Id() != NodeId(option.DataNode) {
-					continue
-				}
+			if option.matchesLocation(dn) {
 				counter++
 			}
 		}
@@ -270,7 +337,7 @@ func (vl *VolumeLayout) SetVolumeAvailable(dn *DataNode, vid needle.VolumeId) bo
 	defer vl.accessLock.Unlock()
 
 	vl.vid2location[vid].Set(dn)
-	if vl.vid2location[vid].Length() == vl.rp.GetCopyCount() {
+	if vl.vid2location[vid].Length() == vl.rp.GetCopyCount() && !vl.hasQuarantinedLocation(vid) {
 		return vl.setVolumeWritable(vid)
 	}
 	return false
@@ -281,7 +348,11 @@ func (vl *VolumeLayout) SetVolumeCapacityFull(vid needle.VolumeId) bool {
 	defer vl.accessLock.Unlock()
 
 	// glog.V(0).Infoln("Volume", vid, "reaches full capacity.")
-	return vl.removeFromWritable(vid)
+	if !vl.removeFromWritable(vid) {
+		return false
+	}
+	vl.fills.record(time.Now(), 1)
+	return true
 }
 
 func (vl *VolumeLayout) ToMap() map[string]interface{} {
@@ -0,0 +1,107 @@
+package topology
+
+import (
+	"sync"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/storage/needle"
+)
+
+// the writer sessions not assigning any file id for this long are forgotten
+const writerSessionIdleTimeout = 10 * time.Minute
+
+// writerSessions remembers the volume last assigned to each writer session, so that the session
+// keeps writing to the same volume, or to another volume on the same data node once the volume is full.
+// The sessions are only kept in the memory of the leader, and a new leader starts with no sessions.
+type writerSessions struct {
+	sync.Mutex
+	sessions  map[string]*writerSession
+	lastSweep time.Time
+}
+
+type writerSession struct {
+	vid      needle.VolumeId
+	dataNode NodeId
+	lastUsed time.Time
+}
+
+func (ws *writerSessions) get(session string) (vid needle.VolumeId, dataNode NodeId, found bool) {
+	ws.Lock()
+	defer ws.Unlock()
+
+	s, found := ws.sessions[session]
+	if !found {
+		return
+	}
+	s.lastUsed = time.Now()
+	return s.vid, s.dataNode, true
+}
+
+func (ws *writerSessions) remember(session string, vid needle.VolumeId, dataNode NodeId) {
+	ws.Lock()
+	defer ws.Unlock()
+
+	now := time.Now()
+	if ws.sessions == nil {
+		ws.sessions = make(map[string]*writerSession)
+	}
+	ws.sessions[session] = &writerSession{vid: vid, dataNode: dataNode, lastUsed: now}
+
+	if now.Sub(ws.lastSweep) < writerSessionIdleTimeout {
+		return
+	}
+	ws.lastSweep = now
+	for key, s := range ws.sessions {
+		if now.Sub(s.lastUsed) >= writerSessionIdleTimeout {
+			delete(ws.sessions, key)
+		}
+	}
+}
+
+// pickForSession picks the volume of the session if still writable, or else a writable volume on the same data node
+func (vl *VolumeLayout) pickForSession(option *VolumeGrowOption) (vid needle.VolumeId, locationList *VolumeLocationList, found bool) {
+	vid, dataNode, found := vl.sessions.get(option.Session)
+	if !found {
+		return
+	}
+	if locationList = vl.writableFor(vid, option); locationList != nil {
+		return vid, locationList, true
+	}
+	for _, v := range vl.writables {
+		candidate := vl.writableFor(v, option)
+		if candidate == nil {
+			continue
+		}
+		for _, dn := range candidate.list {
+			if dn.Id() == dataNode {
+				vl.sessions.remember(option.Session, v, dataNode)
+				return v, candidate, true
+			}
+		}
+	}
+	return 0, nil, false
+}
+
+// writableFor returns the locations of the volume if it is writable and matches the placement of the option
+func (vl *VolumeLayout) writableFor(vid needle.VolumeId, option *VolumeGrowOption) *VolumeLocationList {
+	isWritable := false
+	for _, v := range vl.writables {
+		if v == vid {
+			isWritable = true
+			break
+		}
+	}
+	locationList := vl.vid2location[vid]
+	if !isWritable || locationList == nil || !option.matchesLabels(locationList) {
+		return nil
+	}
+	if option.DataCenter == "" {
+		return locationList
+	}
+	for _, dn := range locationList.list {
+		if option.matchesLocation(dn) {
+			return locationList
+		}
+	}
+	return nil
+}
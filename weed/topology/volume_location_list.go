@@ -3,6 +3,7 @@ package topology
 import (
 	"fmt"
 
+	"github.com/chrislusf/seaweedfs/weed/storage"
 	"github.com/chrislusf/seaweedfs/weed/storage/needle"
 )
 
@@ -23,6 +24,23 @@ func (dnll *VolumeLocationList) Head() *DataNode {
 	return dnll.list[0]
 }
 
+// MostDurable picks the location fsyncing the volume writes most often, preferring the earlier ones on ties
+func (dnll *VolumeLocationList) MostDurable(vid needle.VolumeId) *DataNode {
+	picked, pickedLevel := dnll.Head(), storage.FsyncLevel(-1)
+	for _, dnl := range dnll.list {
+		level := storage.FsyncNever
+		if vinfo, err := dnl.GetVolumesById(vid); err == nil {
+			if policy, err := storage.ParseFsyncPolicy(vinfo.FsyncPolicy); err == nil {
+				level = policy.Level
+			}
+		}
+		if level > pickedLevel {
+			picked, pickedLevel = dnl, level
+		}
+	}
+	return picked
+}
+
 func (dnll *VolumeLocationList) Length() int {
 	return len(dnll.list)
 }
@@ -0,0 +1,170 @@
+package chunk_cache
+
+import (
+	"container/list"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+)
+
+// ChunkCache keeps the content of the recently read chunks as files under a local directory,
+// evicting the least recently used ones when the total size is over the capacity.
+// The chunks never change once written, so the content is keyed by the file id only.
+type ChunkCache struct {
+	dir      string
+	capacity int64
+
+	sync.Mutex
+	size  int64
+	lru   *list.List // of *cachedChunk, the most recently used at the front
+	items map[string]*list.Element
+}
+
+type cachedChunk struct {
+	fileId string
+	size   int64
+}
+
+// NewChunkCache uses the chunks left in the directory by the previous runs, oldest evicted first
+func NewChunkCache(dir string, capacity int64) (*ChunkCache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("create chunk cache dir %s: %v", dir, err)
+	}
+	c := &ChunkCache{
+		dir:      dir,
+		capacity: capacity,
+		lru:      list.New(),
+		items:    make(map[string]*list.Element),
+	}
+
+	fileInfos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("list chunk cache dir %s: %v", dir, err)
+	}
+	sort.Slice(fileInfos, func(i, j int) bool {
+		return fileInfos[i].ModTime().Before(fileInfos[j].ModTime())
+	})
+	for _, fileInfo := range fileInfos {
+		if fileInfo.IsDir() {
+			continue
+		}
+		if strings.HasSuffix(fileInfo.Name(), ".tmp") {
+			os.Remove(filepath.Join(dir, fileInfo.Name()))
+			continue
+		}
+		c.add(fileIdOf(fileInfo.Name()), fileInfo.Size())
+	}
+	c.evict()
+
+	glog.V(0).Infof("chunk cache %s: %d chunks, %d of %d bytes", dir, c.lru.Len(), c.size, c.capacity)
+	return c, nil
+}
+
+// GetChunk returns the cached content, or nil if the chunk is not cached
+func (c *ChunkCache) GetChunk(fileId string) []byte {
+	c.Lock()
+	element, found := c.items[fileId]
+	if found {
+		c.lru.MoveToFront(element)
+	}
+	c.Unlock()
+	if !found {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(c.chunkPath(fileId))
+	if err != nil || int64(len(data)) != element.Value.(*cachedChunk).size {
+		// evicted meanwhile, or removed by someone else
+		glog.V(1).Infof("read cached chunk %s: %v", fileId, err)
+		c.Lock()
+		if c.items[fileId] == element {
+			c.remove(element)
+		}
+		c.Unlock()
+		return nil
+	}
+	return data
+}
+
+func (c *ChunkCache) HasChunk(fileId string) bool {
+	c.Lock()
+	defer c.Unlock()
+	_, found := c.items[fileId]
+	return found
+}
+
+// SetChunk caches the chunk content, unless the chunk is larger than the whole capacity
+func (c *ChunkCache) SetChunk(fileId string, data []byte) {
+	if int64(len(data)) > c.capacity {
+		return
+	}
+	if c.HasChunk(fileId) {
+		return
+	}
+
+	// write to a temporary file first, so a partial chunk is never read
+	tmpFile, err := ioutil.TempFile(c.dir, "chunk-*.tmp")
+	if err != nil {
+		glog.Warningf("cache chunk %s: %v", fileId, err)
+		return
+	}
+	_, err = tmpFile.Write(data)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpFile.Name(), c.chunkPath(fileId))
+	}
+	if err != nil {
+		glog.Warningf("cache chunk %s: %v", fileId, err)
+		os.Remove(tmpFile.Name())
+		return
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	if element, found := c.items[fileId]; found {
+		// cached by another reader meanwhile, with the same content
+		c.lru.MoveToFront(element)
+		return
+	}
+	c.add(fileId, int64(len(data)))
+	c.evict()
+}
+
+func (c *ChunkCache) add(fileId string, size int64) {
+	c.items[fileId] = c.lru.PushFront(&cachedChunk{fileId: fileId, size: size})
+	c.size += size
+}
+
+func (c *ChunkCache) remove(element *list.Element) {
+	chunk := c.lru.Remove(element).(*cachedChunk)
+	delete(c.items, chunk.fileId)
+	c.size -= chunk.size
+}
+
+func (c *ChunkCache) evict() {
+	for c.size > c.capacity {
+		element := c.lru.Back()
+		fileId := element.Value.(*cachedChunk).fileId
+		c.remove(element)
+		if err := os.Remove(c.chunkPath(fileId)); err != nil && !os.IsNotExist(err) {
+			glog.Warningf("evict cached chunk %s: %v", fileId, err)
+		}
+	}
+}
+
+// the file id "3,01637037d6" is cached as the file "3_01637037d6"
+func (c *ChunkCache) chunkPath(fileId string) string {
+	return filepath.Join(c.dir, strings.Replace(fileId, ",", "_", -1))
+}
+
+func fileIdOf(name string) string {
+	return strings.Replace(name, "_", ",", -1)
+}
@@ -0,0 +1,59 @@
+package chunk_cache
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestChunkCacheEviction(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chunk_cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c, err := NewChunkCache(dir, 300)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		c.SetChunk(fmt.Sprintf("%d,01", i), bytes.Repeat([]byte{byte(i)}, 100))
+	}
+	// touch the first chunk, so the second one is the least recently used
+	if data := c.GetChunk("0,01"); !bytes.Equal(data, bytes.Repeat([]byte{0}, 100)) {
+		t.Fatalf("unexpected chunk 0,01: %v", data)
+	}
+	c.SetChunk("3,01", bytes.Repeat([]byte{3}, 100))
+
+	if data := c.GetChunk("1,01"); data != nil {
+		t.Errorf("chunk 1,01 should be evicted")
+	}
+	for _, fileId := range []string{"0,01", "2,01", "3,01"} {
+		if data := c.GetChunk(fileId); len(data) != 100 {
+			t.Errorf("chunk %s should be cached", fileId)
+		}
+	}
+
+	// larger than the whole cache
+	c.SetChunk("4,01", make([]byte, 301))
+	if data := c.GetChunk("4,01"); data != nil {
+		t.Errorf("chunk 4,01 should not be cached")
+	}
+
+	// reloaded from the disk, with less capacity
+	c, err = NewChunkCache(dir, 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.lru.Len() != 2 || c.size != 200 {
+		t.Errorf("reloaded %d chunks, %d bytes", c.lru.Len(), c.size)
+	}
+	for fileId := range c.items {
+		if data := c.GetChunk(fileId); len(data) != 100 {
+			t.Errorf("reloaded chunk %s should be cached", fileId)
+		}
+	}
+}
@@ -26,7 +26,11 @@ func GzipData(input []byte) ([]byte, error) {
 }
 func UnGzipData(input []byte) ([]byte, error) {
 	buf := bytes.NewBuffer(input)
-	r, _ := gzip.NewReader(buf)
+	r, err := gzip.NewReader(buf)
+	if err != nil {
+		glog.V(2).Infoln("error uncompressing data:", err)
+		return nil, err
+	}
 	defer r.Close()
 	output, err := ioutil.ReadAll(r)
 	if err != nil {
@@ -0,0 +1,19 @@
+package util
+
+import (
+	"github.com/klauspost/compress/zstd"
+)
+
+// the zstd encoder and decoder are safe for concurrent EncodeAll and DecodeAll
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+func ZstdData(input []byte) ([]byte, error) {
+	return zstdEncoder.EncodeAll(input, nil), nil
+}
+
+func UnZstdData(input []byte) ([]byte, error) {
+	return zstdDecoder.DecodeAll(input, nil)
+}
@@ -0,0 +1,211 @@
+// +build !windows,!plan9
+
+package util
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+)
+
+/*
+On SIGUSR2, the process starts a new process of the same command line, and hands off the listening sockets to it.
+The old process releases its files, e.g. stops changing the volumes, and tells the new process to load them,
+while still serving on the shared sockets. The new process loads the files, takes over the listeners,
+and tells the old process, which then shuts down gracefully.
+If the new process fails before taking over, the old process keeps serving if its files are still open.
+*/
+
+const (
+	handoffListenersEnv = "WEED_HANDOFF_LISTENERS" // name=fd,name=fd
+	handoffWaitEnv      = "WEED_HANDOFF_WAIT_FD"   // written when the old process has released its files
+	handoffReadyEnv     = "WEED_HANDOFF_READY_FD"  // written when the new process has taken over the listeners
+)
+
+var (
+	handoffLock      sync.Mutex
+	handoffFiles     = make(map[string]*os.File)
+	handoffFileNames []string
+)
+
+// NewHandoffListener listens on the address, or takes over the listener of the same name from the old process
+func NewHandoffListener(name, addr string, timeout time.Duration) (net.Listener, error) {
+	handoffLock.Lock()
+	defer handoffLock.Unlock()
+
+	file, found := inheritedFile(handoffListenersEnv, name)
+	if found {
+		l, err := net.FileListener(file)
+		if err != nil {
+			return nil, fmt.Errorf("take over listener %s: %v", name, err)
+		}
+		glog.V(0).Infof("take over listener %s at %s", name, l.Addr())
+		handoffFiles[name] = file
+		handoffFileNames = append(handoffFileNames, name)
+		return &Listener{Listener: l, ReadTimeout: timeout, WriteTimeout: timeout}, nil
+	}
+
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if tcpListener, ok := l.(*net.TCPListener); ok {
+		if file, err = tcpListener.File(); err != nil {
+			l.Close()
+			return nil, err
+		}
+		handoffFiles[name] = file
+		handoffFileNames = append(handoffFileNames, name)
+	}
+	return &Listener{Listener: l, ReadTimeout: timeout, WriteTimeout: timeout}, nil
+}
+
+// WaitForHandoff blocks until the old process handing off the listeners has released its files, or exited
+func WaitForHandoff() {
+	file, found := inheritedFile(handoffWaitEnv, "")
+	if !found {
+		return
+	}
+	glog.V(0).Infof("wait for the old process to release its files")
+	file.Read(make([]byte, 1))
+	file.Close()
+	glog.V(0).Infof("the old process released its files")
+}
+
+// HandoffReady tells the old process that the listeners are taken over, after its files are loaded
+func HandoffReady() {
+	file, found := inheritedFile(handoffReadyEnv, "")
+	if !found {
+		return
+	}
+	if _, err := file.Write([]byte{1}); err != nil {
+		glog.Errorf("tell the old process the listeners are taken over: %v", err)
+	}
+	file.Close()
+}
+
+// OnHandoff hands off the listeners to a new process on SIGUSR2.
+// The release func stops changing the files of this process, and tells whether this process can still serve.
+// Once the new process takes over the listeners, shutdown runs and this process exits.
+func OnHandoff(release func() (keepServing bool), shutdown func()) {
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, syscall.SIGUSR2)
+	go func() {
+		for range signalChan {
+			waitWriter, readyReader, err := startHandoffProcess()
+			if err != nil {
+				glog.Errorf("hand off the listeners: %v", err)
+				continue
+			}
+			keepServing := release()
+			isReady := handoff(waitWriter, readyReader)
+			if isReady {
+				shutdown()
+				os.Exit(0)
+			}
+			glog.Errorf("the new process failed to take over the listeners")
+			if !keepServing {
+				os.Exit(1)
+			}
+		}
+	}()
+}
+
+// handoff tells the new process the files are released, and waits for it to take over the listeners
+func handoff(waitWriter, readyReader *os.File) (isReady bool) {
+	defer readyReader.Close()
+	_, err := waitWriter.Write([]byte{1})
+	waitWriter.Close()
+	if err != nil {
+		glog.Errorf("tell the new process the files are released: %v", err)
+		return false
+	}
+	// the new process exiting closes the pipe without writing
+	n, _ := readyReader.Read(make([]byte, 1))
+	return n == 1
+}
+
+func startHandoffProcess() (waitWriter, readyReader *os.File, err error) {
+	handoffLock.Lock()
+	defer handoffLock.Unlock()
+
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, nil, err
+	}
+	waitReader, waitWriter, err := os.Pipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer waitReader.Close()
+	readyReader, readyWriter, err := os.Pipe()
+	if err != nil {
+		waitWriter.Close()
+		return nil, nil, err
+	}
+	defer readyWriter.Close()
+
+	// the extra files start from fd 3 in the new process
+	extraFiles := []*os.File{waitReader, readyWriter}
+	var listeners []string
+	for _, name := range handoffFileNames {
+		listeners = append(listeners, fmt.Sprintf("%s=%d", name, 3+len(extraFiles)))
+		extraFiles = append(extraFiles, handoffFiles[name])
+	}
+
+	var env []string
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, handoffListenersEnv+"=") && !strings.HasPrefix(kv, handoffWaitEnv+"=") && !strings.HasPrefix(kv, handoffReadyEnv+"=") {
+			env = append(env, kv)
+		}
+	}
+	env = append(env, handoffListenersEnv+"="+strings.Join(listeners, ","), handoffWaitEnv+"=3", handoffReadyEnv+"=4")
+
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.Env = env
+	cmd.ExtraFiles = extraFiles
+	if err = cmd.Start(); err != nil {
+		waitWriter.Close()
+		readyReader.Close()
+		return nil, nil, fmt.Errorf("start %s: %v", executable, err)
+	}
+	// reap the new process if it fails
+	go cmd.Wait()
+	glog.V(0).Infof("handed off listeners %v to the new process %d", handoffFileNames, cmd.Process.Pid)
+	return waitWriter, readyReader, nil
+}
+
+// inheritedFile finds the file descriptor passed by the old process. An empty name takes the whole value as the fd.
+func inheritedFile(env, name string) (*os.File, bool) {
+	value := os.Getenv(env)
+	if value == "" {
+		return nil, false
+	}
+	for _, kv := range strings.Split(value, ",") {
+		fdString := kv
+		if name != "" {
+			parts := strings.SplitN(kv, "=", 2)
+			if len(parts) != 2 || parts[0] != name {
+				continue
+			}
+			fdString = parts[1]
+		}
+		fd, err := strconv.Atoi(fdString)
+		if err != nil {
+			glog.Errorf("%s=%s: %v", env, value, err)
+			return nil, false
+		}
+		return os.NewFile(uintptr(fd), name), true
+	}
+	return nil, false
+}
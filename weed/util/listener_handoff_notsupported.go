@@ -0,0 +1,21 @@
+// +build windows plan9
+
+package util
+
+import (
+	"net"
+	"time"
+)
+
+func NewHandoffListener(name, addr string, timeout time.Duration) (net.Listener, error) {
+	return NewListener(addr, timeout)
+}
+
+func WaitForHandoff() {
+}
+
+func HandoffReady() {
+}
+
+func OnHandoff(release func() (keepServing bool), shutdown func()) {
+}
@@ -0,0 +1,105 @@
+// +build !windows,!plan9
+
+package util
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestHandoffListener(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer l.Close()
+	file, err := l.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatalf("listener file: %v", err)
+	}
+	// the inherited fd is owned by the taken over listener
+	fd, err := syscall.Dup(int(file.Fd()))
+	file.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv(handoffListenersEnv, fmt.Sprintf("other=999,test.http=%d", fd))
+	defer os.Unsetenv(handoffListenersEnv)
+
+	taken, err := NewHandoffListener("test.http", "127.0.0.1:1", time.Second)
+	if err != nil {
+		t.Fatalf("take over the listener: %v", err)
+	}
+	defer taken.Close()
+	if taken.Addr().String() != l.Addr().String() {
+		t.Errorf("took over %s, expected %s", taken.Addr(), l.Addr())
+	}
+}
+
+func TestHandoff(t *testing.T) {
+	waitReader, waitWriter, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	readyReader, readyWriter, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv(handoffWaitEnv, fmt.Sprint(inheritFd(t, waitReader)))
+	os.Setenv(handoffReadyEnv, fmt.Sprint(inheritFd(t, readyWriter)))
+	defer os.Unsetenv(handoffWaitEnv)
+	defer os.Unsetenv(handoffReadyEnv)
+
+	// the new process loads the files only after the old process released them
+	released := make(chan struct{})
+	loaded := make(chan struct{})
+	go func() {
+		WaitForHandoff()
+		select {
+		case <-released:
+		default:
+			t.Errorf("loaded before the old process released the files")
+		}
+		close(loaded)
+		HandoffReady()
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	close(released)
+	if isReady := handoff(waitWriter, readyReader); !isReady {
+		t.Errorf("the new process is not ready")
+	}
+	<-loaded
+}
+
+func TestHandoffFailed(t *testing.T) {
+	waitReader, waitWriter, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer waitReader.Close()
+	readyReader, readyWriter, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the new process exits without taking over the listeners
+	readyWriter.Close()
+	if isReady := handoff(waitWriter, readyReader); isReady {
+		t.Errorf("ready without the new process")
+	}
+}
+
+// inheritFd duplicates the fd as if inherited from another process, and closes the file
+func inheritFd(t *testing.T, file *os.File) int {
+	fd, err := syscall.Dup(int(file.Fd()))
+	file.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fd
+}
@@ -0,0 +1,216 @@
+package util
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimit is the requests and the bytes per second allowed, 0 for no limit
+type RateLimit struct {
+	RequestsPerSecond float64
+	BytesPerSecond    float64
+}
+
+func (l RateLimit) IsEnabled() bool {
+	return l.RequestsPerSecond > 0 || l.BytesPerSecond > 0
+}
+
+// tokenBucket is refilled at the rate per second, up to the burst of one second.
+// The bytes are taken even beyond the tokens left, and the debt is waited for.
+type tokenBucket struct {
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, now time.Time) *tokenBucket {
+	return &tokenBucket{rate: rate, tokens: rate, last: now}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.rate {
+		b.tokens = b.rate
+	}
+	b.last = now
+}
+
+func (b *tokenBucket) isFull(now time.Time) bool {
+	b.refill(now)
+	return b.tokens >= b.rate
+}
+
+type rateLimitBuckets struct {
+	requests *tokenBucket // nil if no limit
+	bytes    *tokenBucket // nil if no limit
+}
+
+// RateLimiters are the token buckets of the requests and the bytes, for each key like the client ip or the access key.
+// The keys may have their own limits, instead of the default one. The buckets of the idle keys are dropped.
+type RateLimiters struct {
+	sync.Mutex
+	limit     RateLimit
+	overrides map[string]RateLimit
+	buckets   map[string]*rateLimitBuckets
+	lastSweep time.Time
+}
+
+func NewRateLimiters(limit RateLimit, overrides map[string]RateLimit) *RateLimiters {
+	return &RateLimiters{
+		limit:     limit,
+		overrides: overrides,
+		buckets:   make(map[string]*rateLimitBuckets),
+		lastSweep: time.Now(),
+	}
+}
+
+// IsEnabled tells whether any key is limited
+func (rl *RateLimiters) IsEnabled() bool {
+	if rl == nil {
+		return false
+	}
+	if rl.limit.IsEnabled() {
+		return true
+	}
+	for _, limit := range rl.overrides {
+		if limit.IsEnabled() {
+			return true
+		}
+	}
+	return false
+}
+
+func (rl *RateLimiters) getBuckets(key string, now time.Time) *rateLimitBuckets {
+	if now.Sub(rl.lastSweep) > time.Minute {
+		rl.sweep(now)
+	}
+	b, found := rl.buckets[key]
+	if found {
+		return b
+	}
+	limit, found := rl.overrides[key]
+	if !found {
+		limit = rl.limit
+	}
+	b = &rateLimitBuckets{}
+	if limit.RequestsPerSecond > 0 {
+		b.requests = newTokenBucket(limit.RequestsPerSecond, now)
+	}
+	if limit.BytesPerSecond > 0 {
+		b.bytes = newTokenBucket(limit.BytesPerSecond, now)
+	}
+	rl.buckets[key] = b
+	return b
+}
+
+// sweep drops the full buckets, which are the same as the new ones
+func (rl *RateLimiters) sweep(now time.Time) {
+	for key, b := range rl.buckets {
+		if (b.requests == nil || b.requests.isFull(now)) && (b.bytes == nil || b.bytes.isFull(now)) {
+			delete(rl.buckets, key)
+		}
+	}
+	rl.lastSweep = now
+}
+
+// AllowRequest takes one request token of the key, or tells the request is over the limit
+func (rl *RateLimiters) AllowRequest(key string) bool {
+	if rl == nil {
+		return true
+	}
+	rl.Lock()
+	defer rl.Unlock()
+	now := time.Now()
+	b := rl.getBuckets(key, now).requests
+	if b == nil {
+		return true
+	}
+	b.refill(now)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// WaitBytes takes n byte tokens of the key, and waits until the tokens taken are refilled
+func (rl *RateLimiters) WaitBytes(ctx context.Context, key string, n int) error {
+	if rl == nil || n <= 0 {
+		return nil
+	}
+	rl.Lock()
+	now := time.Now()
+	b := rl.getBuckets(key, now).bytes
+	if b == nil {
+		rl.Unlock()
+		return nil
+	}
+	b.refill(now)
+	b.tokens -= float64(n)
+	var delay time.Duration
+	if b.tokens < 0 {
+		delay = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	rl.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RateLimitedBody waits for the byte tokens after reading the request body
+type RateLimitedBody struct {
+	io.ReadCloser
+	ctx  context.Context
+	wait func(ctx context.Context, n int) error
+}
+
+func NewRateLimitedBody(ctx context.Context, body io.ReadCloser, wait func(ctx context.Context, n int) error) *RateLimitedBody {
+	return &RateLimitedBody{ReadCloser: body, ctx: ctx, wait: wait}
+}
+
+func (b *RateLimitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		if waitErr := b.wait(b.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+// RateLimitedResponseWriter waits for the byte tokens after writing the response body
+type RateLimitedResponseWriter struct {
+	http.ResponseWriter
+	ctx  context.Context
+	wait func(ctx context.Context, n int) error
+}
+
+func NewRateLimitedResponseWriter(ctx context.Context, w http.ResponseWriter, wait func(ctx context.Context, n int) error) *RateLimitedResponseWriter {
+	return &RateLimitedResponseWriter{ResponseWriter: w, ctx: ctx, wait: wait}
+}
+
+func (w *RateLimitedResponseWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	if n > 0 && err == nil {
+		err = w.wait(w.ctx, n)
+	}
+	return n, err
+}
+
+func (w *RateLimitedResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -1,6 +1,9 @@
 package util
 
-import "time"
+import (
+	"sync"
+	"time"
+)
 
 type WriteThrottler struct {
 	compactionBytePerSecond int64
@@ -32,3 +35,36 @@ func (wt *WriteThrottler) MaybeSlowdown(delta int64) {
 		}
 	}
 }
+
+// BandwidthThrottler caps the bytes per second shared by all the concurrent transfers, like the
+// background data movements of a volume server. A nil BandwidthThrottler has no limit.
+type BandwidthThrottler struct {
+	sync.Mutex
+	bucket *tokenBucket
+}
+
+func NewBandwidthThrottler(bytesPerSecond int64) *BandwidthThrottler {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+	return &BandwidthThrottler{bucket: newTokenBucket(float64(bytesPerSecond), time.Now())}
+}
+
+// Wait takes n bytes, and waits until the bytes taken beyond the limit are refilled
+func (t *BandwidthThrottler) Wait(n int) {
+	if t == nil || n <= 0 {
+		return
+	}
+	t.Lock()
+	now := time.Now()
+	t.bucket.refill(now)
+	t.bucket.tokens -= float64(n)
+	var delay time.Duration
+	if t.bucket.tokens < 0 {
+		delay = time.Duration(-t.bucket.tokens / t.bucket.rate * float64(time.Second))
+	}
+	t.Unlock()
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+}
@@ -3,7 +3,9 @@ package wdclient
 import (
 	"context"
 	"fmt"
+	"math"
 	"math/rand"
+	"sync/atomic"
 	"time"
 
 	"github.com/chrislusf/seaweedfs/weed/glog"
@@ -15,29 +17,50 @@ import (
 type MasterClient struct {
 	ctx            context.Context
 	name           string
-	currentMaster  string
+	currentMaster  atomic.Value // the connected master, or ""
 	masters        []string
 	grpcDialOption grpc.DialOption
+	prober         volumeServerProber
+	// 1 if the volume locations are fully synced from the connected master, and followed since
+	isSynced int32
+	// unix nano time when the master with the fully synced locations is disconnected
+	disconnectedAt int64
 
 	vidMap
 }
 
 func NewMasterClient(ctx context.Context, grpcDialOption grpc.DialOption, clientName string, masters []string) *MasterClient {
-	return &MasterClient{
+	mc := &MasterClient{
 		ctx:            ctx,
 		name:           clientName,
 		masters:        masters,
 		grpcDialOption: grpcDialOption,
 		vidMap:         newVidMap(),
 	}
+	mc.currentMaster.Store("")
+	mc.vidMap.onMiss = mc.probeVolumeServers
+	return mc
 }
 
 func (mc *MasterClient) GetMaster() string {
-	return mc.currentMaster
+	return mc.currentMaster.Load().(string)
+}
+
+// Staleness is how long the volume locations are not followed from a master since the last full sync,
+// 0 if followed now, or math.MaxInt64 if never fully synced
+func (mc *MasterClient) Staleness() time.Duration {
+	if atomic.LoadInt32(&mc.isSynced) == 1 {
+		return 0
+	}
+	disconnectedAt := atomic.LoadInt64(&mc.disconnectedAt)
+	if disconnectedAt == 0 {
+		return math.MaxInt64
+	}
+	return time.Since(time.Unix(0, disconnectedAt))
 }
 
 func (mc *MasterClient) WaitUntilConnected() {
-	for mc.currentMaster == "" {
+	for mc.GetMaster() == "" {
 		time.Sleep(time.Duration(rand.Int31n(200)) * time.Millisecond)
 	}
 }
@@ -66,38 +89,78 @@ func (mc *MasterClient) tryAllMasters() {
 				return err
 			}
 
-			if mc.currentMaster == "" {
-				glog.V(1).Infof("%s Connected to %v", mc.name, master)
-				mc.currentMaster = master
-			}
-
-			for {
-				if volumeLocation, err := stream.Recv(); err != nil {
-					glog.V(0).Infof("%s failed to receive from %s: %v", mc.name, master, err)
-					return err
-				} else {
-					loc := Location{
-						Url:       volumeLocation.Url,
-						PublicUrl: volumeLocation.PublicUrl,
-					}
-					for _, newVid := range volumeLocation.NewVids {
-						glog.V(1).Infof("%s: %s adds volume %d", mc.name, loc.Url, newVid)
-						mc.addLocation(newVid, loc)
-					}
-					for _, deletedVid := range volumeLocation.DeletedVids {
-						glog.V(1).Infof("%s: %s removes volume %d", mc.name, loc.Url, deletedVid)
-						mc.deleteLocation(deletedVid, loc)
-					}
-				}
-			}
+			glog.V(1).Infof("%s Connected to %v", mc.name, master)
+			mc.currentMaster.Store(master)
 
+			err = mc.followVolumeLocations(stream.Recv)
+			glog.V(0).Infof("%s failed to receive from %s: %v", mc.name, master, err)
+			return err
 		})
 
 		if gprcErr != nil {
 			glog.V(0).Infof("%s failed to connect with master %v: %v", mc.name, master, gprcErr)
 		}
 
-		mc.currentMaster = ""
+		mc.disconnect()
+	}
+}
+
+// followVolumeLocations applies the volume locations received from the master, until failed to receive.
+// The locations listed first replace the ones followed before, which could be outdated.
+// Meanwhile, the lookups are still served from the ones followed before.
+func (mc *MasterClient) followVolumeLocations(recv func() (*master_pb.VolumeLocation, error)) error {
+	syncing := newVidMap()
+	isSyncing := true
+	for {
+		volumeLocation, err := recv()
+		if err != nil {
+			return err
+		}
+		if volumeLocation.IsFullSyncDone {
+			if isSyncing {
+				mc.replaceLocations(&syncing)
+				isSyncing = false
+				atomic.StoreInt32(&mc.isSynced, 1)
+			}
+			continue
+		}
+		mc.applyVolumeLocation(mc.name, volumeLocation)
+		if isSyncing {
+			syncing.applyVolumeLocation(mc.name, volumeLocation)
+		}
+	}
+}
+
+func (mc *MasterClient) disconnect() {
+	if atomic.SwapInt32(&mc.isSynced, 0) == 1 {
+		atomic.StoreInt64(&mc.disconnectedAt, time.Now().UnixNano())
+	}
+	mc.currentMaster.Store("")
+}
+
+func (vc *vidMap) applyVolumeLocation(name string, volumeLocation *master_pb.VolumeLocation) {
+	loc := Location{
+		Url:        volumeLocation.Url,
+		PublicUrl:  volumeLocation.PublicUrl,
+		DataCenter: volumeLocation.DataCenter,
+		Rack:       volumeLocation.Rack,
+	}
+	if volumeLocation.FileSizeLimit > 0 {
+		vc.setFileSizeLimit(loc.Url, volumeLocation.FileSizeLimit)
+	}
+	for _, newVid := range volumeLocation.NewVids {
+		glog.V(1).Infof("%s: %s adds volume %d", name, loc.Url, newVid)
+		vc.addLocation(newVid, loc)
+	}
+	for _, deletedVid := range volumeLocation.DeletedVids {
+		glog.V(1).Infof("%s: %s removes volume %d", name, loc.Url, deletedVid)
+		vc.deleteLocation(deletedVid, loc)
+	}
+	for _, newEcVid := range volumeLocation.NewEcVids {
+		vc.addEcLocation(newEcVid, loc)
+	}
+	for _, deletedEcVid := range volumeLocation.DeletedEcVids {
+		vc.deleteEcLocation(deletedEcVid, loc)
 	}
 }
 
@@ -116,7 +179,7 @@ func withMasterClient(ctx context.Context, master string, grpcDialOption grpc.Di
 }
 
 func (mc *MasterClient) WithClient(ctx context.Context, fn func(client master_pb.SeaweedClient) error) error {
-	return withMasterClient(ctx, mc.currentMaster, mc.grpcDialOption, func(ctx context.Context, client master_pb.SeaweedClient) error {
+	return withMasterClient(ctx, mc.GetMaster(), mc.grpcDialOption, func(ctx context.Context, client master_pb.SeaweedClient) error {
 		return fn(client)
 	})
 }
@@ -0,0 +1,87 @@
+package wdclient
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
+)
+
+func TestFollowVolumeLocations(t *testing.T) {
+	mc := NewMasterClient(context.Background(), nil, "test", []string{"master1"})
+	if staleness := mc.Staleness(); staleness != math.MaxInt64 {
+		t.Errorf("never synced staleness %v", staleness)
+	}
+
+	// followed from the previous master, and deleted while disconnected
+	mc.addLocation(9, Location{Url: "gone"})
+	mc.currentMaster.Store("master1")
+
+	disconnected := errors.New("disconnected")
+	messages := []*master_pb.VolumeLocation{
+		{Url: "server1", NewVids: []uint32{1, 2}},
+		{Url: "server2", NewVids: []uint32{2}, NewEcVids: []uint32{3}},
+		{IsFullSyncDone: true},
+		{Url: "server1", NewVids: []uint32{4}, DeletedVids: []uint32{1}},
+	}
+	err := mc.followVolumeLocations(func() (*master_pb.VolumeLocation, error) {
+		if len(messages) == 0 {
+			return nil, disconnected
+		}
+		if messages[0].IsFullSyncDone {
+			// the lookups are served from the previous locations until fully synced
+			if len(mc.GetLocations(9)) != 1 || mc.Staleness() == 0 {
+				t.Errorf("previous locations replaced before the full sync")
+			}
+		}
+		message := messages[0]
+		messages = messages[1:]
+		return message, nil
+	})
+	if err != disconnected {
+		t.Fatalf("follow: %v", err)
+	}
+
+	if mc.Staleness() != 0 {
+		t.Errorf("fully synced staleness %v", mc.Staleness())
+	}
+	for vid, count := range map[uint32]int{1: 0, 2: 2, 4: 1, 9: 0} {
+		if locations := mc.GetLocations(vid); len(locations) != count {
+			t.Errorf("volume %d locations %+v", vid, locations)
+		}
+	}
+	if locations := mc.ecVid2Locations[3]; len(locations) != 1 || locations[0].Url != "server2" {
+		t.Errorf("ec volume 3 locations %+v", locations)
+	}
+
+	mc.disconnect()
+	if mc.GetMaster() != "" {
+		t.Errorf("still connected to %s", mc.GetMaster())
+	}
+	time.Sleep(time.Millisecond)
+	if staleness := mc.Staleness(); staleness <= 0 || staleness > time.Minute {
+		t.Errorf("disconnected staleness %v", staleness)
+	}
+	// the locations are kept for the lookups within the staleness bound
+	if len(mc.GetLocations(2)) != 2 {
+		t.Errorf("locations dropped when disconnected")
+	}
+
+	// reconnected, but not fully synced yet
+	mc.currentMaster.Store("master1")
+	messages = []*master_pb.VolumeLocation{{Url: "server1", NewVids: []uint32{5}}}
+	mc.followVolumeLocations(func() (*master_pb.VolumeLocation, error) {
+		if len(messages) == 0 {
+			return nil, disconnected
+		}
+		message := messages[0]
+		messages = messages[1:]
+		return message, nil
+	})
+	if staleness := mc.Staleness(); staleness <= 0 || staleness > time.Minute {
+		t.Errorf("staleness %v before the full sync", staleness)
+	}
+}
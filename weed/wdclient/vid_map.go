@@ -15,18 +15,33 @@ import (
 type Location struct {
 	Url       string `json:"url,omitempty"`
 	PublicUrl string `json:"publicUrl,omitempty"`
+	// unknown for the volume servers found by probing
+	DataCenter string `json:"dataCenter,omitempty"`
+	Rack       string `json:"rack,omitempty"`
 }
 
 type vidMap struct {
 	sync.RWMutex
 	vid2Locations map[uint32][]Location
-	r             *rand.Rand
+	// the volume servers with the ec shards, which are also in vid2Locations
+	ecVid2Locations map[uint32][]Location
+	// readFallback lists all the locations to read from in LookupFileIdUrls, instead of a random one
+	readFallback bool
+	// the locations in this data center are read before the ones in the other data centers
+	dataCenter string
+	r          *rand.Rand
+	// onMiss tries to find the missing volume elsewhere, returning true if the volume is added
+	onMiss func(vid uint32) bool
+	// the upload size limits advertised by the volume servers, by the url
+	fileSizeLimits map[string]uint64
 }
 
 func newVidMap() vidMap {
 	return vidMap{
-		vid2Locations: make(map[uint32][]Location),
-		r:             rand.New(rand.NewSource(time.Now().UnixNano())),
+		vid2Locations:   make(map[uint32][]Location),
+		ecVid2Locations: make(map[uint32][]Location),
+		r:               rand.New(rand.NewSource(time.Now().UnixNano())),
+		fileSizeLimits:  make(map[string]uint64),
 	}
 }
 
@@ -52,6 +67,87 @@ func (vc *vidMap) LookupFileId(fileId string) (fullUrl string, err error) {
 	return "http://" + serverUrl + "/" + fileId, nil
 }
 
+// SetReadFallback lets the readers fall back to the other locations, when the one read first fails.
+// The whole volume replicas are read first, and then the volume servers with the ec shards,
+// which read the needle by reconstructing from the ec shards if necessary.
+func (vc *vidMap) SetReadFallback(readFallback bool) {
+	vc.Lock()
+	defer vc.Unlock()
+	vc.readFallback = readFallback
+}
+
+// SetDataCenter lets the readers prefer the locations in the data center, to avoid reading across data centers.
+// The locations found by probing are of unknown data centers, and read after the ones in the data center.
+func (vc *vidMap) SetDataCenter(dataCenter string) {
+	vc.Lock()
+	defer vc.Unlock()
+	vc.dataCenter = dataCenter
+}
+
+// LookupFileIdUrls lists the urls to read the file id from, in the order to try.
+// Without the read fallback, only a random location is listed, as LookupFileId.
+func (vc *vidMap) LookupFileIdUrls(fileId string) (fullUrls []string, err error) {
+	vc.RLock()
+	readFallback := vc.readFallback
+	vc.RUnlock()
+	if !readFallback {
+		fullUrl, err := vc.LookupFileId(fileId)
+		if err != nil {
+			return nil, err
+		}
+		return []string{fullUrl}, nil
+	}
+
+	parts := strings.Split(fileId, ",")
+	if len(parts) != 2 {
+		return nil, errors.New("Invalid fileId " + fileId)
+	}
+	id, err := strconv.Atoi(parts[0])
+	if err != nil {
+		glog.V(1).Infof("Unknown volume id %s", parts[0])
+		return nil, err
+	}
+	for _, loc := range vc.ReadLocations(uint32(id)) {
+		fullUrls = append(fullUrls, "http://"+loc.Url+"/"+fileId)
+	}
+	if len(fullUrls) == 0 {
+		return nil, fmt.Errorf("volume %d not found", id)
+	}
+	return
+}
+
+// ReadLocations orders the whole volume replicas randomly, followed by the volume servers with the ec shards randomly.
+// With the data center set, the replicas and the ec shards in the data center are read before the ones in the other data centers.
+func (vc *vidMap) ReadLocations(vid uint32) (readLocations []Location) {
+	locations := vc.GetLocations(vid)
+
+	vc.Lock()
+	defer vc.Unlock()
+
+	ecLocations := vc.ecVid2Locations[vid]
+	isEc := make(map[string]bool)
+	for _, loc := range ecLocations {
+		isEc[loc.Url] = true
+	}
+	var remoteLocations []Location
+	add := func(loc Location) {
+		if vc.dataCenter == "" || loc.DataCenter == vc.dataCenter {
+			readLocations = append(readLocations, loc)
+		} else {
+			remoteLocations = append(remoteLocations, loc)
+		}
+	}
+	for _, i := range vc.r.Perm(len(locations)) {
+		if !isEc[locations[i].Url] {
+			add(locations[i])
+		}
+	}
+	for _, i := range vc.r.Perm(len(ecLocations)) {
+		add(ecLocations[i])
+	}
+	return append(readLocations, remoteLocations...)
+}
+
 func (vc *vidMap) LookupVolumeServer(fileId string) (volumeServer string, err error) {
 	parts := strings.Split(fileId, ",")
 	if len(parts) != 2 {
@@ -74,6 +170,13 @@ func (vc *vidMap) GetVidLocations(vid string) (locations []Location) {
 }
 
 func (vc *vidMap) GetLocations(vid uint32) (locations []Location) {
+	if locations = vc.getLocations(vid); len(locations) == 0 && vc.onMiss != nil && vc.onMiss(vid) {
+		locations = vc.getLocations(vid)
+	}
+	return
+}
+
+func (vc *vidMap) getLocations(vid uint32) (locations []Location) {
 	vc.RLock()
 	defer vc.RUnlock()
 
@@ -81,51 +184,140 @@ func (vc *vidMap) GetLocations(vid uint32) (locations []Location) {
 }
 
 func (vc *vidMap) GetRandomLocation(vid uint32) (serverUrl string, err error) {
-	vc.RLock()
-	defer vc.RUnlock()
-
-	locations := vc.vid2Locations[vid]
+	locations := vc.GetLocations(vid)
 	if len(locations) == 0 {
 		return "", fmt.Errorf("volume %d not found", vid)
 	}
 
+	vc.Lock()
+	defer vc.Unlock()
+
+	if vc.dataCenter != "" {
+		var localLocations []Location
+		for _, loc := range locations {
+			if loc.DataCenter == vc.dataCenter {
+				localLocations = append(localLocations, loc)
+			}
+		}
+		if len(localLocations) > 0 {
+			locations = localLocations
+		}
+	}
+
 	return locations[vc.r.Intn(len(locations))].Url, nil
 }
 
+// knownServers lists the volume servers of all the volumes
+func (vc *vidMap) knownServers() (servers []string) {
+	vc.RLock()
+	defer vc.RUnlock()
+
+	seen := make(map[string]bool)
+	for _, locations := range vc.vid2Locations {
+		for _, loc := range locations {
+			if !seen[loc.Url] {
+				seen[loc.Url] = true
+				servers = append(servers, loc.Url)
+			}
+		}
+	}
+	return
+}
+
+// replaceLocations replaces all the locations with the ones fully synced from the master
+func (vc *vidMap) replaceLocations(synced *vidMap) {
+	synced.RLock()
+	defer synced.RUnlock()
+	vc.Lock()
+	defer vc.Unlock()
+
+	vc.vid2Locations, vc.ecVid2Locations = synced.vid2Locations, synced.ecVid2Locations
+	vc.fileSizeLimits = synced.fileSizeLimits
+}
+
+func (vc *vidMap) setFileSizeLimit(url string, fileSizeLimit uint64) {
+	vc.Lock()
+	defer vc.Unlock()
+
+	vc.fileSizeLimits[url] = fileSizeLimit
+}
+
+// FileSizeLimit is the smallest upload size limit advertised by the volume servers, for the uploads to fit on any of them,
+// or 0 if none is advertised
+func (vc *vidMap) FileSizeLimit() int64 {
+	vc.RLock()
+	defer vc.RUnlock()
+
+	var limit uint64
+	for _, fileSizeLimit := range vc.fileSizeLimits {
+		if limit == 0 || fileSizeLimit < limit {
+			limit = fileSizeLimit
+		}
+	}
+	return int64(limit)
+}
+
 func (vc *vidMap) addLocation(vid uint32, location Location) {
 	vc.Lock()
 	defer vc.Unlock()
 
-	locations, found := vc.vid2Locations[vid]
+	addToLocations(vc.vid2Locations, vid, location)
+}
+
+func (vc *vidMap) deleteLocation(vid uint32, location Location) {
+	vc.Lock()
+	defer vc.Unlock()
+
+	deleteFromLocations(vc.vid2Locations, vid, location)
+}
+
+func (vc *vidMap) addEcLocation(vid uint32, location Location) {
+	vc.Lock()
+	defer vc.Unlock()
+
+	addToLocations(vc.ecVid2Locations, vid, location)
+}
+
+func (vc *vidMap) deleteEcLocation(vid uint32, location Location) {
+	vc.Lock()
+	defer vc.Unlock()
+
+	deleteFromLocations(vc.ecVid2Locations, vid, location)
+}
+
+func addToLocations(vid2Locations map[uint32][]Location, vid uint32, location Location) {
+	locations, found := vid2Locations[vid]
 	if !found {
-		vc.vid2Locations[vid] = []Location{location}
+		vid2Locations[vid] = []Location{location}
 		return
 	}
 
-	for _, loc := range locations {
+	for i, loc := range locations {
 		if loc.Url == location.Url {
+			// the probed locations learn their topology from the master
+			if location.DataCenter != "" {
+				locations[i] = location
+			}
 			return
 		}
 	}
 
-	vc.vid2Locations[vid] = append(locations, location)
-
+	vid2Locations[vid] = append(locations, location)
 }
 
-func (vc *vidMap) deleteLocation(vid uint32, location Location) {
-	vc.Lock()
-	defer vc.Unlock()
-
-	locations, found := vc.vid2Locations[vid]
+func deleteFromLocations(vid2Locations map[uint32][]Location, vid uint32, location Location) {
+	locations, found := vid2Locations[vid]
 	if !found {
 		return
 	}
 
 	for i, loc := range locations {
 		if loc.Url == location.Url {
-			vc.vid2Locations[vid] = append(locations[0:i], locations[i+1:]...)
+			vid2Locations[vid] = append(locations[0:i], locations[i+1:]...)
 			break
 		}
 	}
-
+	if len(vid2Locations[vid]) == 0 {
+		delete(vid2Locations, vid)
+	}
 }
@@ -0,0 +1,92 @@
+package wdclient
+
+import (
+	"testing"
+
+	"github.com/chrislusf/seaweedfs/weed/pb/master_pb"
+)
+
+func TestReadLocations(t *testing.T) {
+	vc := newVidMap()
+	vc.addLocation(1, Location{Url: "replica1"})
+	vc.addLocation(1, Location{Url: "replica2"})
+	// the ec shard locations are also listed as the volume locations
+	vc.addLocation(1, Location{Url: "ec1"})
+	vc.addEcLocation(1, Location{Url: "ec1"})
+	vc.addEcLocation(1, Location{Url: "ec2"})
+
+	locations := vc.ReadLocations(1)
+	if len(locations) != 4 {
+		t.Fatalf("read locations: %+v", locations)
+	}
+	for i, loc := range locations {
+		isReplica := loc.Url == "replica1" || loc.Url == "replica2"
+		if isReplica != (i < 2) {
+			t.Errorf("replicas should be read before the ec shards: %+v", locations)
+		}
+	}
+
+	urls, err := vc.LookupFileIdUrls("1,0102")
+	if err != nil || len(urls) != 1 {
+		t.Errorf("without fallback, only one url: %v %v", urls, err)
+	}
+	vc.SetReadFallback(true)
+	urls, err = vc.LookupFileIdUrls("1,0102")
+	if err != nil || len(urls) != 4 {
+		t.Errorf("with fallback, all urls: %v %v", urls, err)
+	}
+
+	vc.deleteEcLocation(1, Location{Url: "ec1"})
+	vc.deleteEcLocation(1, Location{Url: "ec2"})
+	if _, found := vc.ecVid2Locations[1]; found {
+		t.Errorf("empty ec locations are kept")
+	}
+}
+
+func TestReadLocationsInDataCenter(t *testing.T) {
+	vc := newVidMap()
+	vc.addLocation(1, Location{Url: "remote_replica", DataCenter: "dc2"})
+	vc.addLocation(1, Location{Url: "local_replica", DataCenter: "dc1"})
+	vc.addLocation(1, Location{Url: "local_ec", DataCenter: "dc1"})
+	vc.addEcLocation(1, Location{Url: "local_ec", DataCenter: "dc1"})
+	vc.addEcLocation(1, Location{Url: "remote_ec", DataCenter: "dc2"})
+	vc.SetDataCenter("dc1")
+
+	expected := []string{"local_replica", "local_ec", "remote_replica", "remote_ec"}
+	locations := vc.ReadLocations(1)
+	if len(locations) != len(expected) {
+		t.Fatalf("read locations: %+v", locations)
+	}
+	for i, loc := range locations {
+		if loc.Url != expected[i] {
+			t.Errorf("expected %v, got %+v", expected, locations)
+			break
+		}
+	}
+
+	for i := 0; i < 10; i++ {
+		if url, err := vc.GetRandomLocation(1); err != nil || url == "remote_replica" {
+			t.Errorf("the location in the data center should be preferred: %s %v", url, err)
+		}
+	}
+}
+
+func TestFileSizeLimit(t *testing.T) {
+	vc := newVidMap()
+	if limit := vc.FileSizeLimit(); limit != 0 {
+		t.Errorf("limit %d without any advertised", limit)
+	}
+
+	vc.applyVolumeLocation("test", &master_pb.VolumeLocation{Url: "server1", NewVids: []uint32{1}, FileSizeLimit: 64 << 20})
+	vc.applyVolumeLocation("test", &master_pb.VolumeLocation{Url: "server2", NewVids: []uint32{1}, FileSizeLimit: 8 << 20})
+	// the older volume servers advertise no limit
+	vc.applyVolumeLocation("test", &master_pb.VolumeLocation{Url: "server3", NewVids: []uint32{2}})
+	if limit := vc.FileSizeLimit(); limit != 8<<20 {
+		t.Errorf("limit %d, expected the smallest one", limit)
+	}
+
+	vc.applyVolumeLocation("test", &master_pb.VolumeLocation{Url: "server2", NewVids: []uint32{3}, FileSizeLimit: 128 << 20})
+	if limit := vc.FileSizeLimit(); limit != 64<<20 {
+		t.Errorf("limit %d after server2 raised its limit", limit)
+	}
+}
@@ -0,0 +1,117 @@
+package wdclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/chrislusf/seaweedfs/weed/glog"
+)
+
+const (
+	volumeServerProbeInterval = 10 * time.Second
+	volumeServerProbeTimeout  = 3 * time.Second
+)
+
+// volumeServerProber finds the volumes by asking the volume servers directly, when no master is connected.
+// The volume servers are the ones seen before, plus the seeds, which help a client started during a master outage.
+type volumeServerProber struct {
+	sync.Mutex
+	seeds     []string
+	lastProbe time.Time
+	client    *http.Client
+}
+
+type volumeServerVolumes struct {
+	Url       string
+	PublicUrl string
+	Volumes   []uint32
+	EcVolumes []uint32
+}
+
+// SetVolumeServerSeeds sets the volume servers to probe for the volume locations when the masters are unavailable
+func (mc *MasterClient) SetVolumeServerSeeds(seeds []string) {
+	mc.prober.Lock()
+	defer mc.prober.Unlock()
+	mc.prober.seeds = seeds
+}
+
+// probeVolumeServers refreshes the cached volume locations from the volume servers, at most once per interval,
+// and only if no master is connected, since the master knows better
+func (mc *MasterClient) probeVolumeServers(vid uint32) bool {
+	if mc.GetMaster() != "" {
+		return false
+	}
+
+	mc.prober.Lock()
+	defer mc.prober.Unlock()
+	if time.Since(mc.prober.lastProbe) < volumeServerProbeInterval {
+		return false
+	}
+	mc.prober.lastProbe = time.Now()
+
+	servers := mc.knownServers()
+	for _, seed := range mc.prober.seeds {
+		servers = appendIfMissing(servers, seed)
+	}
+
+	found := false
+	for _, server := range servers {
+		vols, err := mc.prober.listVolumes(server)
+		if err != nil {
+			glog.V(0).Infof("%s probe volume server %s: %v", mc.name, server, err)
+			continue
+		}
+		loc := Location{Url: vols.Url, PublicUrl: vols.PublicUrl}
+		if loc.Url == "" {
+			loc.Url = server
+		}
+		if loc.PublicUrl == "" {
+			loc.PublicUrl = loc.Url
+		}
+		for _, v := range append(vols.Volumes, vols.EcVolumes...) {
+			mc.addLocation(v, loc)
+			found = found || v == vid
+		}
+		for _, v := range vols.EcVolumes {
+			mc.addEcLocation(v, loc)
+		}
+	}
+	glog.V(0).Infof("%s probed %d volume servers without master, volume %d found: %v", mc.name, len(servers), vid, found)
+	return found
+}
+
+func (p *volumeServerProber) listVolumes(server string) (*volumeServerVolumes, error) {
+	if p.client == nil {
+		p.client = &http.Client{Timeout: volumeServerProbeTimeout}
+	}
+	resp, err := p.client.Get(fmt.Sprintf("http://%s/volumes", server))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s", resp.Status)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	vols := &volumeServerVolumes{}
+	if err = json.Unmarshal(data, vols); err != nil {
+		return nil, err
+	}
+	return vols, nil
+}
+
+func appendIfMissing(servers []string, server string) []string {
+	for _, s := range servers {
+		if s == server {
+			return servers
+		}
+	}
+	return append(servers, server)
+}